@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/pkg/client"
+	"github.com/Q-YZX0/oxy-blockchain/pkg/txsign"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const benchUsage = `Uso:
+  oxy-blockchain bench -key-file archivo [-node URL] [-rate N] [-duration D] [-accounts A] [-seed S]
+                       [-fund WEI] [-gas-price WEI] [-drain D]
+  oxy-blockchain bench -embedded [-rate N] [-duration D] [-accounts A] [-block-interval D] [-max-block-txs N]
+
+Envía transferencias firmadas de 1 wei a N tx/s durante D y mide el throughput: transacciones incluidas
+por segundo, latencia envío → inclusión (p50, p90, p99 y máxima) y backlog del mempool. Muestra el
+resultado en JSON.
+
+Contra un nodo (-node, por defecto http://localhost:8080) las transacciones salen de A cuentas derivadas
+de la semilla S, fondeadas con -fund wei desde la cuenta de -key-file si les falta saldo. Cada cuenta
+envía en modo sync de a una transacción por vez, así que A limita la tasa alcanzable; usar varias cuentas
+también evita el rate limit por dirección del nodo. La inclusión se detecta consultando los bloques
+nuevos (la latencia tiene la resolución del sondeo, 200ms) y, terminado el envío, se espera hasta -drain
+a que se incluya lo pendiente.
+
+Con -embedded no hace falta un nodo: las transacciones pasan por CheckTx y se ejecutan con FinalizeBlock
+y Commit en una ABCIApp y un EVM en proceso, sin red ni CometBFT, produciendo un bloque cada
+-block-interval con hasta -max-block-txs transacciones (mide el executor y el costo de CheckTx).
+`
+
+// runBenchCommand ejecuta el subcomando bench y retorna el código de salida
+func runBenchCommand(args []string) int {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, benchUsage) }
+	embedded := flags.Bool("embedded", false, "medir con un nodo en proceso en lugar de uno remoto")
+	node := flags.String("node", "http://localhost:8080", "URL del API REST del nodo")
+	keyFile := flags.String("key-file", "", "clave privada en hex de la cuenta que fondea a las demás")
+	rate := flags.Int("rate", 100, "transacciones por segundo a enviar")
+	duration := flags.Duration("duration", 30*time.Second, "tiempo de envío")
+	accounts := flags.Int("accounts", 50, "cuentas que envían las transacciones")
+	seed := flags.Int64("seed", 1, "semilla de las cuentas")
+	fund := flags.String("fund", "1000000000000000000", "wei con que se fondea cada cuenta")
+	gasPrice := flags.String("gas-price", "1", "precio del gas en wei")
+	drain := flags.Duration("drain", 30*time.Second, "espera máxima de la inclusión de lo pendiente")
+	blockInterval := flags.Duration("block-interval", consensus.DefaultBenchBlockInterval, "intervalo entre bloques (-embedded)")
+	maxBlockTxs := flags.Int("max-block-txs", consensus.DefaultBenchMaxBlockTxs, "transacciones por bloque (-embedded)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var (
+		result *consensus.BenchResult
+		err    error
+	)
+	if *embedded {
+		workDir, mkErr := os.MkdirTemp("", "oxy-bench-*")
+		if mkErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", mkErr)
+			return 1
+		}
+		result, err = consensus.RunEmbeddedBench(ctx, workDir, consensus.BenchOptions{
+			Rate:          *rate,
+			Duration:      *duration,
+			Accounts:      *accounts,
+			Seed:          *seed,
+			BlockInterval: *blockInterval,
+			MaxBlockTxs:   *maxBlockTxs,
+		})
+	} else {
+		bench := &remoteBench{
+			client:   client.NewClient(*node),
+			rate:     *rate,
+			duration: *duration,
+			drain:    *drain,
+			pending:  make(map[string]time.Time),
+		}
+		var ok bool
+		if bench.fund, ok = new(big.Int).SetString(*fund, 10); !ok || bench.fund.Sign() <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: -fund inválido: %s\n", *fund)
+			return 2
+		}
+		if bench.gasPrice, ok = new(big.Int).SetString(*gasPrice, 10); !ok || bench.gasPrice.Sign() < 0 {
+			fmt.Fprintf(os.Stderr, "Error: -gas-price inválido: %s\n", *gasPrice)
+			return 2
+		}
+		result, err = bench.run(ctx, *keyFile, *accounts, *seed)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	report, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(report))
+	fmt.Fprintf(os.Stderr, "%.1f tx/s incluidas (objetivo %d tx/s), latencia p50 %.0fms p99 %.0fms, backlog máximo %d\n",
+		result.TPS, result.TargetRate, result.Latency.P50, result.Latency.P99, result.MempoolMax)
+	return 0
+}
+
+// benchAccount es una cuenta que envía transacciones del benchmark
+type benchAccount struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+	nonce   uint64
+}
+
+// remoteBench mide el throughput de un nodo a través del API REST
+type remoteBench struct {
+	client   *client.Client
+	rate     int
+	duration time.Duration
+	drain    time.Duration
+	fund     *big.Int
+	gasPrice *big.Int
+
+	mu        sync.Mutex
+	result    consensus.BenchResult
+	pending   map[string]time.Time // Hash → momento del envío
+	latencies []time.Duration
+	lastBlock time.Time
+}
+
+// run fondea las cuentas, envía las transacciones a la tasa objetivo y espera su inclusión
+func (b *remoteBench) run(ctx context.Context, keyFile string, count int, seed int64) (*consensus.BenchResult, error) {
+	if b.rate <= 0 || b.duration <= 0 || count < 2 {
+		return nil, fmt.Errorf("el benchmark necesita una tasa y una duración positivas y al menos 2 cuentas")
+	}
+	if keyFile == "" {
+		return nil, fmt.Errorf("falta -key-file (cuenta que fondea a las demás) o -embedded")
+	}
+	funder, err := crypto.LoadECDSA(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo la clave de %s: %w", keyFile, err)
+	}
+
+	accounts := make([]*benchAccount, count)
+	for i := range accounts {
+		key, err := crypto.ToECDSA(crypto.Keccak256([]byte("oxy-bench"), binary.BigEndian.AppendUint64(nil, uint64(seed)), binary.BigEndian.AppendUint64(nil, uint64(i))))
+		if err != nil {
+			return nil, err
+		}
+		accounts[i] = &benchAccount{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+	}
+	if err := b.fundAccounts(ctx, funder, accounts); err != nil {
+		return nil, err
+	}
+
+	latest, err := b.client.GetLatestBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando el último bloque: %w", err)
+	}
+	b.result = consensus.BenchResult{Mode: "remote", TargetRate: b.rate}
+
+	// Observación de bloques y del mempool durante todo el benchmark
+	start := time.Now()
+	b.lastBlock = start
+	pollCtx, stopPolling := context.WithCancel(ctx)
+	defer stopPolling()
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		b.pollBlocks(pollCtx, latest.Header.Height)
+	}()
+
+	// Envío: cada cuenta toma un turno a la vez; el ritmo lo marca tokens
+	tokens := make(chan struct{})
+	var workers sync.WaitGroup
+	for i, account := range accounts {
+		workers.Add(1)
+		go func(account *benchAccount, to common.Address) {
+			defer workers.Done()
+			for range tokens {
+				b.send(ctx, account, to)
+			}
+		}(account, accounts[(i+1)%len(accounts)].address)
+	}
+	sendCtx, stopSending := context.WithTimeout(ctx, b.duration)
+	paceBench(sendCtx, start, b.rate, tokens)
+	stopSending()
+	close(tokens)
+	workers.Wait()
+
+	if size, err := b.client.GetMempoolSize(ctx); err == nil {
+		b.mu.Lock()
+		b.result.MempoolFinal = size
+		b.mu.Unlock()
+	}
+
+	// Esperar la inclusión de lo pendiente
+	deadline := time.Now().Add(b.drain)
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		b.mu.Lock()
+		pending := len(b.pending)
+		b.mu.Unlock()
+		if pending == 0 {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	stopPolling()
+	<-polled
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result := b.result
+	result.Pending = len(b.pending)
+	result.Elapsed = b.lastBlock.Sub(start).Seconds()
+	if result.Elapsed > 0 {
+		result.TPS = float64(result.Included) / result.Elapsed
+	}
+	result.Latency = consensus.NewBenchLatency(b.latencies)
+	return &result, ctx.Err()
+}
+
+// paceBench entrega turnos de envío a rate por segundo hasta que vence ctx
+func paceBench(ctx context.Context, start time.Time, rate int, tokens chan<- struct{}) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for sent := 0; ; {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for due := int(time.Since(start).Seconds() * float64(rate)); sent < due; sent++ {
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// send firma y envía una transferencia de 1 wei en modo sync
+func (b *remoteBench) send(ctx context.Context, account *benchAccount, to common.Address) {
+	tx := txsign.NewTransfer(account.address.Hex(), to.Hex(), big.NewInt(1), account.nonce, b.gasPrice)
+	err := txsign.Sign(tx, account.key)
+	submitted := time.Now()
+	if err == nil {
+		_, err = b.client.SubmitTransaction(ctx, tx, client.ModeSync)
+	}
+
+	b.mu.Lock()
+	b.result.Submitted++
+	if err != nil {
+		b.result.Rejected++
+	} else {
+		b.pending[tx.Hash] = submitted
+	}
+	b.mu.Unlock()
+
+	if err == nil {
+		account.nonce++
+		return
+	}
+	// El nonce puede no haberse consumido (o haberse consumido sin respuesta): releerlo
+	if state, stateErr := b.client.GetAccount(ctx, account.address.Hex()); stateErr == nil {
+		account.nonce = max(account.nonce, state.Nonce)
+	}
+}
+
+// pollBlocks consulta los bloques nuevos desde from+1 y registra la inclusión de las transacciones enviadas
+func (b *remoteBench) pollBlocks(ctx context.Context, from uint64) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if size, err := b.client.GetMempoolSize(ctx); err == nil {
+			b.mu.Lock()
+			b.result.MempoolMax = max(b.result.MempoolMax, size)
+			b.mu.Unlock()
+		}
+		latest, err := b.client.GetLatestBlock(ctx)
+		if err != nil {
+			continue
+		}
+		for ; from < latest.Header.Height; from++ {
+			block, err := b.client.GetBlock(ctx, from+1)
+			if err != nil {
+				break
+			}
+			seen := time.Now()
+			b.mu.Lock()
+			b.result.Blocks++
+			for _, tx := range block.Transactions {
+				if submitted, ok := b.pending[tx.Hash]; ok {
+					delete(b.pending, tx.Hash)
+					b.result.Included++
+					b.latencies = append(b.latencies, seen.Sub(submitted))
+					b.lastBlock = seen
+				}
+			}
+			b.mu.Unlock()
+		}
+	}
+}
+
+// fundAccounts transfiere b.fund a las cuentas con menos de la mitad y espera la inclusión
+func (b *remoteBench) fundAccounts(ctx context.Context, funder *ecdsa.PrivateKey, accounts []*benchAccount) error {
+	funderAddress := crypto.PubkeyToAddress(funder.PublicKey).Hex()
+	state, err := b.client.GetAccount(ctx, funderAddress)
+	if err != nil {
+		return fmt.Errorf("error consultando la cuenta %s: %w", funderAddress, err)
+	}
+	nonce := state.Nonce
+
+	threshold := new(big.Int).Div(b.fund, big.NewInt(2))
+	var last string
+	funded := 0
+	for _, account := range accounts {
+		accountState, err := b.client.GetAccount(ctx, account.address.Hex())
+		if err != nil && !errors.Is(err, client.ErrNotFound) {
+			return fmt.Errorf("error consultando la cuenta %s: %w", account.address.Hex(), err)
+		}
+		if accountState != nil {
+			account.nonce = accountState.Nonce
+			if balance, ok := new(big.Int).SetString(accountState.Balance, 10); ok && balance.Cmp(threshold) >= 0 {
+				continue
+			}
+		}
+
+		tx := txsign.NewTransfer(funderAddress, account.address.Hex(), b.fund, nonce, b.gasPrice)
+		if err := txsign.Sign(tx, funder); err != nil {
+			return err
+		}
+		if _, err := b.client.SubmitTransaction(ctx, tx, client.ModeSync); err != nil {
+			return fmt.Errorf("error fondeando %s: %w", account.address.Hex(), err)
+		}
+		nonce++
+		funded++
+		last = tx.Hash
+	}
+	if last == "" {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Fondeando %d cuentas desde %s...\n", funded, funderAddress)
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	if _, err := b.client.WaitForTransaction(waitCtx, last, 0); err != nil {
+		return fmt.Errorf("error esperando el fondeo de las cuentas: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/config"
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const devUsage = `Uso:
+  oxy-blockchain dev [-accounts N] [-balance OXG] [-chain-id ID] [-data-dir DIR]
+                     [-fork-url URL [-fork-api oxy|eth] [-fork-height N]]
+
+Inicia un nodo de desarrollo de un solo validador: los bloques se producen en cuanto llegan
+transacciones (sin bloques vacíos periódicos ni espera entre bloques), N cuentas de desarrollo quedan
+fondeadas en el genesis con -balance OXG cada una y el API REST acepta CORS de cualquier origen.
+Las claves privadas de las cuentas se muestran al iniciar.
+
+Sin -data-dir la cadena vive en memoria (tmpfs en /dev/shm si existe, si no el directorio temporal) y se
+borra al detener el nodo; con -data-dir persiste entre reinicios. El resto de la configuración se toma de
+las variables de entorno como en un nodo normal (puerto del API, RPC, logs).
+
+Con -fork-url la cadena parte del estado de una red remota en la altura -fork-height (0 = la última): las
+cuentas, el code y el storage que no existen localmente se leen de la red remota la primera vez y quedan
+guardados en el directorio de datos. -fork-api indica la API del nodo remoto: oxy (API REST de un nodo
+Oxy•gen, por defecto) o eth (JSON-RPC de Ethereum; alturas antiguas requieren un nodo de archivo).
+Un directorio de datos persistente queda fijado a la red y altura de su primer fork.
+
+Las claves de desarrollo son fijas y conocidas por cualquiera: nunca usarlas fuera de una cadena local.
+`
+
+// devNode es la configuración del nodo de desarrollo
+type devNode struct {
+	dataDir   string
+	ephemeral bool // Directorio de datos temporal: se borra al detener el nodo
+	chainID   string
+	balance   *big.Int // Balance inicial de cada cuenta en wei
+	accounts  []*ecdsa.PrivateKey
+
+	forkURL    string // Red remota de la que se hace fork (vacío = sin fork)
+	forkAPI    string
+	forkHeight uint64
+	fork       *execution.Fork
+}
+
+// parseDevCommand prepara el nodo de desarrollo; si retorna nil el proceso debe salir con el código retornado
+func parseDevCommand(args []string) (*devNode, int) {
+	flags := flag.NewFlagSet("dev", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, devUsage) }
+	accounts := flags.Int("accounts", 10, "cuentas de desarrollo fondeadas en el genesis")
+	balance := flags.String("balance", "10000", "balance inicial de cada cuenta en OXG")
+	chainID := flags.String("chain-id", "oxy-dev", "chain ID de la cadena de desarrollo")
+	dataDir := flags.String("data-dir", "", "directorio de datos persistente (vacío = en memoria, se borra al salir)")
+	forkURL := flags.String("fork-url", "", "URL de la red remota de la que se hace fork")
+	forkAPI := flags.String("fork-api", "oxy", "API de la red remota: oxy (REST) o eth (JSON-RPC)")
+	forkHeight := flags.Uint64("fork-height", 0, "altura fijada de la red remota (0 = la última)")
+	if err := flags.Parse(args); err != nil {
+		return nil, 2
+	}
+
+	oxg, ok := new(big.Int).SetString(*balance, 10)
+	if !ok || oxg.Sign() <= 0 || *accounts < 1 {
+		fmt.Fprintf(os.Stderr, "Error: -accounts y -balance deben ser positivos\n")
+		return nil, 2
+	}
+
+	if *forkAPI != "oxy" && *forkAPI != "eth" {
+		fmt.Fprintf(os.Stderr, "Error: -fork-api debe ser oxy o eth\n")
+		return nil, 2
+	}
+
+	dev := &devNode{
+		dataDir:    *dataDir,
+		chainID:    *chainID,
+		balance:    new(big.Int).Mul(oxg, big.NewInt(1e18)),
+		forkURL:    *forkURL,
+		forkAPI:    *forkAPI,
+		forkHeight: *forkHeight,
+	}
+	for i := 0; i < *accounts; i++ {
+		key, err := crypto.ToECDSA(crypto.Keccak256([]byte("oxy-dev"), binary.BigEndian.AppendUint64(nil, uint64(i))))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return nil, 1
+		}
+		dev.accounts = append(dev.accounts, key)
+	}
+
+	if dev.dataDir == "" {
+		base := os.TempDir()
+		if info, err := os.Stat("/dev/shm"); err == nil && info.IsDir() {
+			base = "/dev/shm"
+		}
+		dir, err := os.MkdirTemp(base, "oxy-dev-*")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creando directorio de datos: %v\n", err)
+			return nil, 1
+		}
+		dev.dataDir = dir
+		dev.ephemeral = true
+	}
+	return dev, 0
+}
+
+// apply ajusta la configuración del nodo al modo desarrollo
+func (d *devNode) apply(cfg *config.Config) {
+	cfg.DataDir = d.dataDir
+	cfg.ChainID = d.chainID
+	// Los scripts de desarrollo envían ráfagas desde una misma cuenta
+	cfg.RateLimitPerAddress = max(cfg.RateLimitPerAddress, 1000)
+	// Los balances remotos no están en la emisión registrada localmente: la auditoría de invariantes fallaría
+	if d.forkURL != "" {
+		cfg.InvariantCheckInterval = 0
+	}
+	os.Setenv("OXY_REST_CORS_ORIGINS", "*")
+}
+
+// setupFork habilita el fork de la red remota en el ejecutor (antes de Start)
+func (d *devNode) setupFork(ctx context.Context, evm *execution.EVMExecutor) error {
+	if d.forkURL == "" {
+		return nil
+	}
+	backend, err := newForkBackend(d.forkAPI, d.forkURL)
+	if err != nil {
+		return err
+	}
+	d.fork = execution.NewFork(backend, d.forkURL, d.forkHeight)
+	return evm.SetFork(ctx, d.fork)
+}
+
+// genesisAccounts retorna las cuentas de desarrollo para el app_state del genesis
+func (d *devNode) genesisAccounts() []consensus.GenesisAccount {
+	accounts := make([]consensus.GenesisAccount, len(d.accounts))
+	for i, key := range d.accounts {
+		accounts[i] = consensus.GenesisAccount{
+			Address: crypto.PubkeyToAddress(key.PublicKey).Hex(),
+			Balance: d.balance.String(),
+		}
+	}
+	return accounts
+}
+
+// printAccounts muestra las cuentas de desarrollo con sus claves privadas y los endpoints del nodo
+func (d *devNode) printAccounts(cfg *config.Config) {
+	var out strings.Builder
+	fmt.Fprintf(&out, "\nNodo de desarrollo listo (chain ID %s, datos en %s", d.chainID, d.dataDir)
+	if d.ephemeral {
+		out.WriteString(", se borran al salir")
+	}
+	fmt.Fprintf(&out, ")\nAPI REST: http://%s:%s   RPC CometBFT: %s\n", cfg.APIHost, cfg.APIPort, cfg.RPCListenAddr)
+	if d.fork != nil {
+		info := d.fork.Info()
+		fmt.Fprintf(&out, "Fork de %s (%s) en la altura %d\n", info.Source, d.forkAPI, info.Height)
+	}
+	out.WriteString("\n")
+
+	oxg := new(big.Int).Div(d.balance, big.NewInt(1e18))
+	fmt.Fprintf(&out, "Cuentas de desarrollo (%s OXG cada una):\n", oxg)
+	for i, key := range d.accounts {
+		fmt.Fprintf(&out, "(%d) %s\n", i, crypto.PubkeyToAddress(key.PublicKey).Hex())
+	}
+	out.WriteString("\nClaves privadas:\n")
+	for i, key := range d.accounts {
+		fmt.Fprintf(&out, "(%d) %s\n", i, hexutil.Encode(crypto.FromECDSA(key)))
+	}
+	out.WriteString("\nADVERTENCIA: claves fijas y conocidas por cualquiera, solo para desarrollo local\n\n")
+	fmt.Fprint(os.Stdout, out.String())
+}
+
+// cleanup borra el directorio de datos en memoria
+func (d *devNode) cleanup() {
+	if d.ephemeral {
+		os.RemoveAll(d.dataDir)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/pkg/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// newForkBackend crea el lector de la red remota según su API ("oxy": API REST de un nodo Oxy•gen,
+// "eth": JSON-RPC de Ethereum)
+func newForkBackend(api, url string) (execution.ForkBackend, error) {
+	switch api {
+	case "oxy":
+		return &oxyForkBackend{client: client.NewClient(url)}, nil
+	case "eth":
+		return &ethForkBackend{url: url}, nil
+	default:
+		return nil, fmt.Errorf("API de fork desconocida: %s (oxy o eth)", api)
+	}
+}
+
+// oxyForkBackend lee el estado de un nodo Oxy•gen por su API REST
+// La altura fijada debe seguir disponible en el nodo remoto (no podada)
+type oxyForkBackend struct {
+	client *client.Client
+}
+
+func (b *oxyForkBackend) LatestHeight(ctx context.Context) (uint64, error) {
+	block, err := b.client.GetLatestBlock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return block.Header.Height, nil
+}
+
+func (b *oxyForkBackend) Account(ctx context.Context, address common.Address, height uint64) (*execution.ForkAccount, error) {
+	account, err := b.client.GetAccountAtHeight(ctx, address.Hex(), height)
+	if err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(account.Balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("balance inválido: %q", account.Balance)
+	}
+	remote := &execution.ForkAccount{Balance: balance, Nonce: account.Nonce}
+	if codeHash := common.HexToHash(account.CodeHash); codeHash != (common.Hash{}) && codeHash != types.EmptyCodeHash {
+		if remote.Code, err = b.client.GetCode(ctx, address.Hex(), height); err != nil {
+			return nil, err
+		}
+	}
+	return remote, nil
+}
+
+func (b *oxyForkBackend) Storage(ctx context.Context, address common.Address, slot common.Hash, height uint64) (common.Hash, error) {
+	value, err := b.client.GetStorageAt(ctx, address.Hex(), slot.Hex(), height)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.HexToHash(value), nil
+}
+
+// ethForkBackend lee el estado de un nodo compatible con Ethereum por JSON-RPC
+// La altura fijada requiere un nodo de archivo si es anterior a las últimas que el nodo conserva
+type ethForkBackend struct {
+	url string
+}
+
+// call hace una llamada JSON-RPC y decodifica su resultado en result
+func (b *ethForkBackend) call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("%s: respuesta inválida (HTTP %d): %w", method, resp.StatusCode, err)
+	}
+	if reply.Error != nil {
+		return fmt.Errorf("%s: %s (código %d)", method, reply.Error.Message, reply.Error.Code)
+	}
+	return json.Unmarshal(reply.Result, result)
+}
+
+func (b *ethForkBackend) LatestHeight(ctx context.Context) (uint64, error) {
+	var height hexutil.Uint64
+	err := b.call(ctx, &height, "eth_blockNumber")
+	return uint64(height), err
+}
+
+func (b *ethForkBackend) Account(ctx context.Context, address common.Address, height uint64) (*execution.ForkAccount, error) {
+	block := hexutil.EncodeUint64(height)
+	var balance hexutil.Big
+	if err := b.call(ctx, &balance, "eth_getBalance", address, block); err != nil {
+		return nil, err
+	}
+	var nonce hexutil.Uint64
+	if err := b.call(ctx, &nonce, "eth_getTransactionCount", address, block); err != nil {
+		return nil, err
+	}
+	var code hexutil.Bytes
+	if err := b.call(ctx, &code, "eth_getCode", address, block); err != nil {
+		return nil, err
+	}
+	return &execution.ForkAccount{Balance: balance.ToInt(), Nonce: uint64(nonce), Code: code}, nil
+}
+
+func (b *ethForkBackend) Storage(ctx context.Context, address common.Address, slot common.Hash, height uint64) (common.Hash, error) {
+	var value common.Hash
+	err := b.call(ctx, &value, "eth_getStorageAt", address, slot, hexutil.EncodeUint64(height))
+	return value, err
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/config"
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+const checkInvariantsUsage = `Uso:
+  oxy-blockchain check-invariants   (con el nodo detenido)
+
+Verifica las invariantes contables sobre el último estado confirmado:
+  - cuentas de módulo (bonded pool, unbonded pool, recompensas, fee collector, community pool)
+    más balances libres igual al supply total (emitido - quemado), que no es negativo
+  - ninguna cuenta con más que el supply total (balance pasado de cero)
+  - bonded y unbonded pool iguales al stake de los validadores fuera y dentro de jail
+  - la custodia de recompensas cubre las recompensas y comisiones pendientes
+  - el fee collector vacío entre bloques
+  - stakes, recompensas y comisiones no negativos y poder de voto coherente con el stake
+  - un receipt por transacción en el último bloque
+Muestra un reporte JSON y sale con código 3 si alguna invariante no se cumple.
+El nodo corre la misma verificación en segundo plano cada OXY_INVARIANT_CHECK_INTERVAL bloques.
+`
+
+// runCheckInvariantsCommand ejecuta el subcomando check-invariants y retorna el código de salida
+func runCheckInvariantsCommand(args []string) int {
+	flags := flag.NewFlagSet("check-invariants", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, checkInvariantsUsage) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := config.LoadConfig()
+
+	// Las bases de datos están bloqueadas mientras el nodo corre
+	db, err := storage.NewBlockchainDB(cfg.DataDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (¿el nodo está corriendo?)\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer evm.Stop()
+
+	minStake, _, err := minStakeFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	validators := consensus.NewValidatorSet(db, evm, minStake, 100)
+	if err := validators.LoadValidators(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	report, err := consensus.CheckInvariants(evm, validators, db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+
+	if len(report.Violations) > 0 {
+		fmt.Fprintf(os.Stderr, "%d invariantes incumplidas\n", len(report.Violations))
+		return 3
+	}
+	fmt.Fprintf(os.Stderr, "Invariantes verificadas: supply %s, %d cuentas, root %s\n", report.TotalSupply, report.Accounts, report.Root)
+	return 0
+}
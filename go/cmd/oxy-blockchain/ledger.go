@@ -0,0 +1,69 @@
+//go:build ledger
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Q-YZX0/oxy-blockchain/pkg/txsign"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/usbwallet"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ledgerSigner firma transacciones con la app de Ethereum de un Ledger (la clave nunca sale del dispositivo)
+// Firma el mensaje EIP-712 del hash de la transacción: requiere habilitar "blind signing" en la app
+type ledgerSigner struct {
+	wallet  accounts.Wallet
+	account accounts.Account
+}
+
+// openLedger abre el primer Ledger conectado y deriva la cuenta de path (ej: m/44'/60'/0'/0/0)
+func openLedger(path string) (*ledgerSigner, error) {
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("ruta de derivación inválida %q: %w", path, err)
+	}
+
+	hub, err := usbwallet.NewLedgerHub()
+	if err != nil {
+		return nil, fmt.Errorf("error accediendo a los dispositivos USB: %w", err)
+	}
+	wallets := hub.Wallets()
+	if len(wallets) == 0 {
+		return nil, fmt.Errorf("no se encontró un Ledger conectado")
+	}
+
+	wallet := wallets[0]
+	if err := wallet.Open(""); err != nil {
+		return nil, fmt.Errorf("error abriendo el Ledger (¿está desbloqueado con la app de Ethereum abierta?): %w", err)
+	}
+	account, err := wallet.Derive(derivationPath, true)
+	if err != nil {
+		wallet.Close()
+		return nil, fmt.Errorf("error derivando la cuenta %s: %w", path, err)
+	}
+	return &ledgerSigner{wallet: wallet, account: account}, nil
+}
+
+// Address implementa txsign.Signer
+func (s *ledgerSigner) Address() common.Address {
+	return s.account.Address
+}
+
+// SignTransactionHash implementa txsign.Signer: el dispositivo muestra el hash del dominio y del mensaje
+func (s *ledgerSigner) SignTransactionHash(hash common.Hash) ([]byte, error) {
+	data := txsign.TypedData(hash)
+	fmt.Fprintf(os.Stderr, "Confirmar en el Ledger: domain hash 0x%x, message hash 0x%x\n", data[2:34], data[34:66])
+	signature, err := s.wallet.SignData(s.account, accounts.MimetypeTypedData, data)
+	if err != nil {
+		return nil, fmt.Errorf("el Ledger no firmó la transacción: %w", err)
+	}
+	return signature, nil
+}
+
+// Close libera el dispositivo
+func (s *ledgerSigner) Close() error {
+	return s.wallet.Close()
+}
@@ -0,0 +1,32 @@
+//go:build !ledger
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ledgerSigner no está disponible sin el build tag ledger (requiere cgo y hidapi)
+type ledgerSigner struct{}
+
+// openLedger retorna un error: el binario se compiló sin soporte para Ledger
+func openLedger(path string) (*ledgerSigner, error) {
+	return nil, fmt.Errorf("binario compilado sin soporte para Ledger (compilar con -tags ledger)")
+}
+
+// Address implementa txsign.Signer
+func (s *ledgerSigner) Address() common.Address {
+	return common.Address{}
+}
+
+// SignTransactionHash implementa txsign.Signer
+func (s *ledgerSigner) SignTransactionHash(hash common.Hash) ([]byte, error) {
+	return nil, fmt.Errorf("binario compilado sin soporte para Ledger")
+}
+
+// Close implementa io.Closer
+func (s *ledgerSigner) Close() error {
+	return nil
+}
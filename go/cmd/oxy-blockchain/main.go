@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/config"
+	"github.com/Q-YZX0/oxy-blockchain/internal/logger"
+	"github.com/Q-YZX0/oxy-blockchain/internal/node"
+	"github.com/Q-YZX0/oxy-blockchain/internal/tracing"
+)
+
+func main() {
+	// Nodo de desarrollo (oxy-blockchain dev): el mismo nodo con la configuración ajustada
+	var dev *devNode
+
+	// Subcomandos de administración (no inician el nodo)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "snapshot":
+			os.Exit(runSnapshotCommand(os.Args[2:]))
+		case "replay":
+			os.Exit(runReplayCommand(os.Args[2:]))
+		case "verify-state":
+			os.Exit(runVerifyStateCommand(os.Args[2:]))
+		case "check-invariants":
+			os.Exit(runCheckInvariantsCommand(os.Args[2:]))
+		case "dump-state":
+			os.Exit(runDumpStateCommand(os.Args[2:]))
+		case "export":
+			os.Exit(runExportCommand(os.Args[2:]))
+		case "tx":
+			os.Exit(runTxCommand(os.Args[2:]))
+		case "simulate":
+			os.Exit(runSimulateCommand(os.Args[2:]))
+		case "bench":
+			os.Exit(runBenchCommand(os.Args[2:]))
+		case "dev":
+			var code int
+			if dev, code = parseDevCommand(os.Args[2:]); dev == nil {
+				os.Exit(code)
+			}
+			defer dev.cleanup()
+		}
+	}
+
+	// Flags del nodo
+	nodeFlags := flag.NewFlagSet("oxy-blockchain", flag.ExitOnError)
+	allowRollback := nodeFlags.Bool("allow-rollback", false, "iniciar aunque la cadena guardada haya retrocedido (avisa chain_reorg a los suscriptores del watchlist)")
+	if dev == nil {
+		nodeFlags.Parse(os.Args[1:])
+	}
+
+	// Log inmediato para verificar que el proceso inicia
+	fmt.Fprintf(os.Stdout, "[MAIN] Proceso testnet iniciado\n")
+	os.Stdout.Sync()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Configuración
+	fmt.Fprintf(os.Stdout, "[MAIN] Cargando configuración...\n")
+	os.Stdout.Sync()
+	cfg := config.LoadConfig()
+	if dev != nil {
+		dev.apply(cfg)
+	}
+
+	// Validar toda la configuración antes de iniciar cualquier componente: se reportan todos los errores juntos
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "[MAIN] Configuración inválida:\n%v\n", err)
+		os.Stderr.Sync()
+		if dev != nil {
+			dev.cleanup()
+		}
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stdout, "[MAIN] Configuración cargada: APIEnabled=%v, APIPort=%s\n", cfg.APIEnabled, cfg.APIPort)
+	os.Stdout.Sync()
+
+	// Inicializar logger estructurado
+	useJSON := os.Getenv("OXY_LOG_JSON") == "true"
+	fmt.Fprintf(os.Stdout, "[MAIN] Inicializando logger (Level=%s, JSON=%v)...\n", cfg.LogLevel, useJSON)
+	os.Stdout.Sync()
+	logger.Init(cfg.LogLevel, useJSON)
+	if err := reloadLogLevels(cfg); err != nil {
+		logger.Warnf("Niveles de log por módulo ignorados: %v", err)
+	}
+
+	// Tracing OpenTelemetry (REST → consenso → ejecución) si hay un collector OTLP configurado
+	tracer, err := tracing.Init(tracing.Config{
+		Endpoint:    cfg.TracingEndpoint,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[MAIN] ADVERTENCIA: tracing deshabilitado: %v\n", err)
+		os.Stderr.Sync()
+	} else if tracer != nil {
+		fmt.Fprintf(os.Stdout, "[MAIN] Tracing habilitado: exportando a %s\n", cfg.TracingEndpoint)
+		os.Stdout.Sync()
+		defer func() {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer shutdownCancel()
+			tracer.Shutdown(shutdownCtx)
+		}()
+	}
+
+	// Nodo: storage, EVM, validadores, consenso, red mesh, módulos y servidores del API
+	oxyNode, err := node.NewNode(cfg)
+	if err != nil {
+		logger.Fatalf("Error creando el nodo: %v", err)
+	}
+	oxyNode.SetAllowRollback(*allowRollback)
+	if dev != nil {
+		oxyNode.SetDevMode(dev.genesisAccounts())
+		oxyNode.SetForkSetup(dev.setupFork)
+	}
+	if err := oxyNode.Start(ctx); err != nil {
+		logger.Fatalf("Error iniciando el nodo: %v", err)
+	}
+	defer oxyNode.Stop()
+
+	if dev != nil {
+		dev.printAccounts(cfg)
+	}
+
+	// SIGHUP recarga los niveles de log (OXY_LOG_LEVEL_FILE o la configuración de inicio) sin reiniciar
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			if err := reloadLogLevels(cfg); err != nil {
+				logger.Errorf("Error recargando niveles de log: %v", err)
+				continue
+			}
+			logger.Warnf("Niveles de log recargados: %s", logger.GetLevels())
+		}
+	}()
+
+	// Manejar señales de terminación
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	<-sigChan
+}
+
+// reloadLogLevels aplica los niveles de log de OXY_LOG_LEVEL_FILE si existe, o si no los de
+// OXY_LOG_LEVEL y OXY_LOG_MODULES. Los filtros por módulo se reemplazan por completo
+// (descartando los cambios hechos por el API de administración)
+func reloadLogLevels(cfg *config.Config) error {
+	spec := cfg.LogLevel + "," + cfg.LogModules
+	if cfg.LogLevelFile != "" {
+		data, err := os.ReadFile(cfg.LogLevelFile)
+		if err == nil {
+			spec = string(data)
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("error leyendo %s: %w", cfg.LogLevelFile, err)
+		}
+	}
+
+	levels, err := logger.ParseLevels(strings.ReplaceAll(spec, "\n", ","))
+	if err != nil {
+		return err
+	}
+	if levels.Level == "" {
+		levels.Level = cfg.LogLevel
+	}
+	return logger.SetLevels(levels)
+}
+
+// minStakeFromEnv retorna el stake mínimo de validador (OXY_MIN_STAKE, en OXG) en wei y el valor configurado
+func minStakeFromEnv() (*big.Int, string, error) {
+	minStakeValue := os.Getenv("OXY_MIN_STAKE")
+	if minStakeValue == "" {
+		// Default para testnet: 10 OXG (1000 OXG para producción)
+		minStakeValue = config.DefaultMinStake
+	}
+	minStake, err := config.ParseMinStake(minStakeValue)
+	if err != nil {
+		return nil, minStakeValue, fmt.Errorf("OXY_MIN_STAKE: %w", err)
+	}
+	return minStake, minStakeValue, nil
+}
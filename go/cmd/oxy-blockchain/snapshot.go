@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/config"
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/snapshot"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+const snapshotUsage = `Uso:
+  oxy-blockchain snapshot list
+  oxy-blockchain snapshot export [-height N] -out archivo   (height 0 = el más reciente)
+  oxy-blockchain snapshot restore -in archivo               (con el nodo detenido)
+`
+
+// runSnapshotCommand ejecuta los subcomandos de snapshots y retorna el código de salida
+func runSnapshotCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, snapshotUsage)
+		return 2
+	}
+
+	cfg := config.LoadConfig()
+	store, err := snapshot.NewStore(consensus.SnapshotDir(cfg.DataDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		err = listSnapshots(store)
+	case "export":
+		err = exportSnapshot(store, args[1:])
+	case "restore":
+		err = restoreSnapshot(cfg, store, args[1:])
+	default:
+		fmt.Fprint(os.Stderr, snapshotUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listSnapshots muestra los snapshots locales
+func listSnapshots(store *snapshot.Store) error {
+	snapshots, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Println("No hay snapshots (OXY_SNAPSHOT_INTERVAL=0 los deshabilita)")
+		return nil
+	}
+	for _, meta := range snapshots {
+		fmt.Printf("altura=%d formato=%d chunks=%d tamaño=%d creado=%s\n",
+			meta.Height, meta.Format, meta.Chunks, meta.Size, meta.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+// exportSnapshot escribe un snapshot local a un archivo portable (puede correr con el nodo en marcha)
+func exportSnapshot(store *snapshot.Store, args []string) error {
+	flags := flag.NewFlagSet("snapshot export", flag.ContinueOnError)
+	height := flags.Uint64("height", 0, "altura del snapshot (0 = el más reciente)")
+	out := flags.String("out", "", "archivo de destino")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return fmt.Errorf("falta -out")
+	}
+
+	if *height == 0 {
+		snapshots, err := store.List()
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			return fmt.Errorf("no hay snapshots para exportar")
+		}
+		*height = snapshots[0].Height
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("error creando %s: %w", *out, err)
+	}
+	meta, err := store.Export(*height, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*out)
+		return err
+	}
+
+	fmt.Printf("Snapshot %d exportado a %s (%d bytes, app hash %X)\n", meta.Height, *out, meta.Size, meta.AppHash)
+	return nil
+}
+
+// restoreSnapshot importa un archivo de snapshot y deja el nodo listo para continuar desde esa altura
+func restoreSnapshot(cfg *config.Config, store *snapshot.Store, args []string) error {
+	flags := flag.NewFlagSet("snapshot restore", flag.ContinueOnError)
+	in := flags.String("in", "", "archivo de snapshot exportado")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		return fmt.Errorf("falta -in")
+	}
+
+	file, err := os.Open(*in)
+	if err != nil {
+		return fmt.Errorf("error abriendo %s: %w", *in, err)
+	}
+	meta, err := store.Import(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Snapshot %d verificado e importado al store local\n", meta.Height)
+
+	// Las bases de datos están bloqueadas mientras el nodo corre
+	db, err := storage.NewBlockchainDB(cfg.DataDir)
+	if err != nil {
+		return fmt.Errorf("%w (¿el nodo está corriendo?)", err)
+	}
+	defer db.Close()
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		return err
+	}
+	defer evm.Stop()
+
+	consensusConfig := &consensus.Config{
+		DataDir:              cfg.DataDir,
+		ChainID:              cfg.ChainID,
+		StateSyncRPCServers:  cfg.StateSyncRPCServers,
+		StateSyncTrustHeight: cfg.StateSyncTrustHeight,
+		StateSyncTrustHash:   cfg.StateSyncTrustHash,
+	}
+	if err := consensus.RestoreSnapshot(context.Background(), consensusConfig, db, evm, store, meta.Height); err != nil {
+		return err
+	}
+
+	fmt.Printf("Nodo restaurado en altura %d; al iniciar continuará desde el bloque %d\n", meta.Height, meta.Height+1)
+	return nil
+}
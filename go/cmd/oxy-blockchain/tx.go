@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/pkg/client"
+	"github.com/Q-YZX0/oxy-blockchain/pkg/txsign"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const txUsage = `Uso:
+  oxy-blockchain tx sign (-key-file archivo | -ledger [-ledger-path RUTA]) [-in archivo | -to DIR -value WEI
+                         -nonce N -gas-price WEI [-gas-limit N] [-data 0x...]] [-fee-payer DIR] [-out archivo]
+  oxy-blockchain tx sponsor (-key-file archivo | -ledger [-ledger-path RUTA]) [-in archivo] [-out archivo]
+  oxy-blockchain tx send [-node URL] [-mode async|sync|commit] [-wait] [-timeout D] [-in archivo]
+  oxy-blockchain tx address (-key-file archivo | -ledger [-ledger-path RUTA])
+
+sign firma una transacción sin conectarse al nodo. La transacción se lee en JSON (esquema de submit-tx, sin
+hash ni signature y con data en base64) de -in ("-" = stdin) o se arma con los flags; from se completa con
+la dirección de la clave. La clave es un archivo con la clave privada secp256k1 en hex o, con -ledger, la
+cuenta de la app de Ethereum de un Ledger (ruta por defecto m/44'/60'/0'/0/0; requiere un binario compilado
+con -tags ledger y "blind signing" habilitado en la app). Escribe la transacción firmada en JSON. Con
+-fee-payer, el gas lo paga esa cuenta (relayer), que agrega su firma con sponsor.
+sponsor firma como fee payer una transacción ya firmada por el remitente (de -in o stdin).
+send envía una transacción firmada (de -in o stdin) a /api/v1/submit-tx y muestra la respuesta; -wait
+espera a que se incluya en un bloque.
+address muestra la dirección de la clave (para fondearla o registrarla como operador antes de firmar).
+`
+
+// runTxCommand ejecuta los subcomandos de transacciones y retorna el código de salida
+func runTxCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, txUsage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "sign":
+		err = signTx(args[1:])
+	case "sponsor":
+		err = sponsorTx(args[1:])
+	case "send":
+		err = sendTx(args[1:])
+	case "address":
+		err = showTxAddress(args[1:])
+	default:
+		fmt.Fprint(os.Stderr, txUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// signTx firma una transacción con una clave local
+func signTx(args []string) error {
+	flags := flag.NewFlagSet("tx sign", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, txUsage) }
+	keyFile, ledger, ledgerPath := signerFlags(flags)
+	in := flags.String("in", "", "transacción sin firmar en JSON (\"-\" = stdin)")
+	out := flags.String("out", "", "archivo de destino (por defecto, stdout)")
+	to := flags.String("to", "", "dirección destino (vacía = creación de contrato)")
+	value := flags.String("value", "0", "valor en wei")
+	nonce := flags.Uint64("nonce", 0, "nonce de la cuenta")
+	gasLimit := flags.Uint64("gas-limit", txsign.TransferGasLimit, "límite de gas")
+	gasPrice := flags.String("gas-price", "", "precio del gas en wei")
+	data := flags.String("data", "", "data de la transacción en hex (0x...)")
+	feePayer := flags.String("fee-payer", "", "cuenta que paga el gas (transacción patrocinada)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	signer, closeSigner, err := openSigner(*keyFile, *ledger, *ledgerPath)
+	if err != nil {
+		return err
+	}
+	defer closeSigner()
+
+	var tx *client.Transaction
+	if *in != "" {
+		if tx, err = readTx(*in); err != nil {
+			return err
+		}
+	} else {
+		amount, ok := new(big.Int).SetString(*value, 10)
+		if !ok || amount.Sign() < 0 {
+			return fmt.Errorf("-value inválido: %s", *value)
+		}
+		price, ok := new(big.Int).SetString(*gasPrice, 10)
+		if !ok || price.Sign() < 0 {
+			return fmt.Errorf("-gas-price inválido o faltante: %q", *gasPrice)
+		}
+		tx = txsign.NewTransfer("", *to, amount, *nonce, price)
+		tx.GasLimit = *gasLimit
+		if *data != "" {
+			if tx.Data, err = hexutil.Decode(*data); err != nil {
+				return fmt.Errorf("-data inválido: %w", err)
+			}
+		}
+	}
+
+	if *feePayer != "" {
+		if !common.IsHexAddress(*feePayer) {
+			return fmt.Errorf("-fee-payer inválido: %s", *feePayer)
+		}
+		tx.FeePayer = &client.FeePayer{Address: common.HexToAddress(*feePayer).Hex()}
+	}
+
+	if err := txsign.SignWith(tx, signer); err != nil {
+		return err
+	}
+	return writeTx(tx, *out, fmt.Sprintf("firmada por %s", tx.From))
+}
+
+// sponsorTx agrega la firma del fee payer a una transacción patrocinada
+func sponsorTx(args []string) error {
+	flags := flag.NewFlagSet("tx sponsor", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, txUsage) }
+	keyFile, ledger, ledgerPath := signerFlags(flags)
+	in := flags.String("in", "-", "transacción firmada por el remitente en JSON (\"-\" = stdin)")
+	out := flags.String("out", "", "archivo de destino (por defecto, stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	tx, err := readTx(*in)
+	if err != nil {
+		return err
+	}
+	signer, closeSigner, err := openSigner(*keyFile, *ledger, *ledgerPath)
+	if err != nil {
+		return err
+	}
+	defer closeSigner()
+
+	if err := txsign.Sponsor(tx, signer); err != nil {
+		return err
+	}
+	return writeTx(tx, *out, fmt.Sprintf("patrocinada por %s", tx.FeePayer.Address))
+}
+
+// writeTx escribe una transacción en JSON en out (vacío = stdout)
+func writeTx(tx *client.Transaction, out, action string) error {
+	encoded, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+	if out == "" {
+		_, err = os.Stdout.Write(encoded)
+		return err
+	}
+	if err := os.WriteFile(out, encoded, 0o644); err != nil {
+		return fmt.Errorf("error escribiendo %s: %w", out, err)
+	}
+	fmt.Fprintf(os.Stderr, "Transacción %s %s en %s\n", tx.Hash, action, out)
+	return nil
+}
+
+// showTxAddress muestra la dirección de una clave local o de un Ledger
+func showTxAddress(args []string) error {
+	flags := flag.NewFlagSet("tx address", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, txUsage) }
+	keyFile, ledger, ledgerPath := signerFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	signer, closeSigner, err := openSigner(*keyFile, *ledger, *ledgerPath)
+	if err != nil {
+		return err
+	}
+	defer closeSigner()
+	fmt.Println(signer.Address().Hex())
+	return nil
+}
+
+// signerFlags registra los flags que eligen la clave que firma
+func signerFlags(flags *flag.FlagSet) (keyFile *string, ledger *bool, ledgerPath *string) {
+	keyFile = flags.String("key-file", "", "archivo con la clave privada en hex")
+	ledger = flags.Bool("ledger", false, "firmar con un Ledger (app de Ethereum)")
+	ledgerPath = flags.String("ledger-path", accounts.DefaultBaseDerivationPath.String(), "ruta de derivación de la cuenta del Ledger")
+	return keyFile, ledger, ledgerPath
+}
+
+// openSigner abre la clave indicada por -key-file o -ledger; la función retornada libera el dispositivo
+func openSigner(keyFile string, ledger bool, ledgerPath string) (txsign.Signer, func(), error) {
+	switch {
+	case ledger && keyFile != "":
+		return nil, nil, fmt.Errorf("-key-file y -ledger son excluyentes")
+	case ledger:
+		signer, err := openLedger(ledgerPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return signer, func() { signer.Close() }, nil
+	case keyFile != "":
+		key, err := crypto.LoadECDSA(keyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error leyendo la clave de %s: %w", keyFile, err)
+		}
+		return txsign.NewKeySigner(key), func() {}, nil
+	}
+	return nil, nil, fmt.Errorf("falta -key-file o -ledger")
+}
+
+// sendTx envía una transacción firmada al nodo
+func sendTx(args []string) error {
+	flags := flag.NewFlagSet("tx send", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, txUsage) }
+	node := flags.String("node", "http://localhost:8080", "URL del API REST del nodo")
+	mode := flags.String("mode", client.ModeSync, "modo de envío (async, sync o commit)")
+	wait := flags.Bool("wait", false, "esperar a que la transacción se incluya en un bloque")
+	timeout := flags.Duration("timeout", 2*time.Minute, "tiempo máximo de espera")
+	in := flags.String("in", "-", "transacción firmada en JSON (\"-\" = stdin)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	tx, err := readTx(*in)
+	if err != nil {
+		return err
+	}
+	// Detectar localmente una transacción modificada después de firmarla
+	if _, err := txsign.Verify(tx); err != nil {
+		return fmt.Errorf("transacción inválida: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	c := client.NewClient(*node)
+	result, err := c.SubmitTransaction(ctx, tx, *mode)
+	if result != nil {
+		printJSON(result)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *wait && *mode != client.ModeCommit {
+		included, err := c.WaitForTransaction(ctx, tx.Hash, 0)
+		if err != nil {
+			return err
+		}
+		printJSON(included)
+	}
+	return nil
+}
+
+// readTx lee una transacción en JSON de un archivo o de stdin ("-")
+func readTx(path string) (*client.Transaction, error) {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("error abriendo %s: %w", path, err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	var tx client.Transaction
+	if err := decoder.Decode(&tx); err != nil {
+		return nil, fmt.Errorf("transacción JSON inválida: %w", err)
+	}
+	return &tx, nil
+}
+
+// printJSON muestra un valor en JSON indentado
+func printJSON(value interface{}) {
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	fmt.Println(string(encoded))
+}
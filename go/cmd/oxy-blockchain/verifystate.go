@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/config"
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const verifyStateUsage = `Uso:
+  oxy-blockchain verify-state [-max-errors N]   (con el nodo detenido)
+
+Verifica las bases de datos locales antes de volver a unirse al consenso:
+  - recorre el trie de estado EVM en el último root comprobando el hash de cada nodo y los tries de storage
+  - recalcula el total de balances y lo contrasta con los contadores de emisión y con el balance de cada
+    dirección que aparece en los bloques
+  - valida la consistencia bloque→receipt→transacción de BlockchainDB, los roots de transacciones y receipts
+    de cada header y que el root coincida con el último app hash
+Muestra un reporte JSON y sale con código 3 si encuentra corrupción.
+`
+
+// verifyStateReport es el reporte del subcomando verify-state
+type verifyStateReport struct {
+	Chain  *consensus.ChainVerification `json:"chain"`
+	State  *execution.StateVerification `json:"state"`
+	Errors []string                     `json:"errors,omitempty"` // Inconsistencias entre BlockchainDB y el estado EVM
+}
+
+// runVerifyStateCommand ejecuta el subcomando verify-state y retorna el código de salida
+func runVerifyStateCommand(args []string) int {
+	flags := flag.NewFlagSet("verify-state", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, verifyStateUsage) }
+	maxErrors := flags.Int("max-errors", 100, "detener cada verificación tras N problemas (0 = sin límite)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := config.LoadConfig()
+
+	// Las bases de datos están bloqueadas mientras el nodo corre
+	db, err := storage.NewBlockchainDB(cfg.DataDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (¿el nodo está corriendo?)\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	report := &verifyStateReport{}
+	report.Chain, err = consensus.VerifyChain(db, *maxErrors)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer evm.Stop()
+
+	var addresses []common.Address
+	for _, address := range report.Chain.Addresses() {
+		if common.IsHexAddress(address) {
+			addresses = append(addresses, common.HexToAddress(address))
+		}
+	}
+	report.State, err = evm.GetStateManager().VerifyState(addresses, *maxErrors)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// El app hash de cada bloque es el root del estado EVM tras ejecutarlo
+	if report.Chain.LatestAppHash != "" && report.State.Root != types.EmptyRootHash.Hex() &&
+		report.Chain.LatestAppHash != report.State.Root {
+		report.Errors = append(report.Errors, fmt.Sprintf("el root del estado EVM (%s) no coincide con el app hash del bloque %d (%s)",
+			report.State.Root, report.Chain.LatestHeight, report.Chain.LatestAppHash))
+	}
+
+	// Los contadores de emisión deben coincidir con la suma de todos los balances
+	// (una cadena anterior a los contadores no tiene nada emitido registrado)
+	if supply, err := evm.GetSupply(); err == nil && supply.Minted != "0" && supply.Total != report.State.TotalSupply.String() {
+		report.Errors = append(report.Errors, fmt.Sprintf("la emisión registrada (%s) no coincide con la suma de los balances (%s)",
+			supply.Total, report.State.TotalSupply))
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+
+	problems := len(report.Chain.Errors) + len(report.State.Errors) + len(report.Errors)
+	if problems > 0 {
+		fmt.Fprintf(os.Stderr, "Se encontraron %d problemas; no inicies el nodo hasta resolverlos (p. ej. con snapshot restore)\n", problems)
+		return 3
+	}
+	fmt.Fprintf(os.Stderr, "Estado verificado: %d bloques, %d transacciones, %d cuentas, root %s\n",
+		report.Chain.Blocks, report.Chain.Transactions, report.State.Accounts, report.State.Root)
+	return 0
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// handleAccountState maneja GET /api/v1/accounts/{address}/code y GET /api/v1/accounts/{address}/storage/{slot}
+// Ambos aceptan ?height=N para leer el estado tras ese bloque (por defecto el último confirmado)
+func (s *RestServer) handleAccountState(w http.ResponseWriter, r *http.Request, address, resource string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !common.IsHexAddress(address) {
+		http.Error(w, "Invalid Ethereum address", http.StatusBadRequest)
+		return
+	}
+	if s.executor == nil {
+		http.Error(w, "EVM executor not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var height uint64
+	if value := r.URL.Query().Get("height"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || parsed == 0 {
+			http.Error(w, "Invalid height", http.StatusBadRequest)
+			return
+		}
+		height = parsed
+	}
+
+	result := map[string]interface{}{
+		"address": common.HexToAddress(address).Hex(),
+		"height":  height,
+	}
+	switch {
+	case resource == "code":
+		code, err := s.executor.GetCodeAt(address, height)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("State not available: %v", err), http.StatusNotFound)
+			return
+		}
+		result["code"] = hexutil.Encode(code)
+	case strings.HasPrefix(resource, "storage/"):
+		slotHex := strings.TrimPrefix(resource, "storage/")
+		slotBytes, err := hexutil.Decode(slotHex)
+		if err != nil || len(slotBytes) > common.HashLength {
+			http.Error(w, "Invalid storage slot", http.StatusBadRequest)
+			return
+		}
+		slot := common.BytesToHash(slotBytes)
+		value, err := s.executor.GetStorageAt(address, slot, height)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("State not available: %v", err), http.StatusNotFound)
+			return
+		}
+		result["slot"] = slot.Hex()
+		result["value"] = value.Hex()
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
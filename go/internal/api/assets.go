@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/assets"
+)
+
+// SetAssetRegistry configura el registro de activos expuesto en /api/v1/assets (por defecto solo OXG)
+func (s *RestServer) SetAssetRegistry(registry *assets.Registry) {
+	s.assets = registry
+}
+
+// handleAssets maneja GET /api/v1/assets y GET /api/v1/assets/{denom}
+// Retorna los metadatos (decimales, símbolo, nombre) de OXG y de los tokens bridged registrados
+func (s *RestServer) handleAssets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var response interface{}
+	denom := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/assets"), "/")
+	if denom == "" {
+		list := s.assets.List()
+		response = map[string]interface{}{
+			"assets": list,
+			"count":  len(list),
+		}
+	} else {
+		asset, ok := s.assets.Get(denom)
+		if !ok {
+			http.Error(w, "Asset not found", http.StatusNotFound)
+			return
+		}
+		response = asset
+	}
+
+	// Los metadatos cambian poco: las wallets los revalidan con If-None-Match
+	body, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeCacheableJSON(w, r, body, false)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxBatchGetItems es el máximo de bloques o transacciones por petición batch-get
+const maxBatchGetItems = 100
+
+// decodeBatchRequest decodifica el body de un batch-get y valida la cantidad de elementos
+func decodeBatchRequest(w http.ResponseWriter, r *http.Request, req interface{}, count func() int) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+		return false
+	}
+	n := count()
+	if n == 0 {
+		http.Error(w, "At least one item is required", http.StatusBadRequest)
+		return false
+	}
+	if n > maxBatchGetItems {
+		http.Error(w, fmt.Sprintf("Too many items (max %d)", maxBatchGetItems), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// handleBlocksBatchGet maneja POST /api/v1/blocks/batch-get
+// Body: {"heights": [1, 2, ...]} o {"hashes": ["0x...", ...]}
+// Retorna los bloques en el orden pedido, con null en los que este nodo no tiene (no se piden a la mesh)
+func (s *RestServer) handleBlocksBatchGet(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Heights []uint64 `json:"heights"`
+		Hashes  []string `json:"hashes"`
+	}
+	if !decodeBatchRequest(w, r, &req, func() int { return len(req.Heights) + len(req.Hashes) }) {
+		return
+	}
+	if len(req.Heights) > 0 && len(req.Hashes) > 0 {
+		http.Error(w, "Use either heights or hashes", http.StatusBadRequest)
+		return
+	}
+	for _, hash := range req.Hashes {
+		if len(common.FromHex(hash)) != common.HashLength {
+			http.Error(w, fmt.Sprintf("Invalid block hash: %s", hash), http.StatusBadRequest)
+			return
+		}
+	}
+
+	blocks := make([]*blockResponse, 0, len(req.Heights)+len(req.Hashes))
+	add := func(blockData []byte, err error) error {
+		if err != nil {
+			blocks = append(blocks, nil)
+			return nil
+		}
+		block, err := consensus.DecodeBlock(blockData)
+		if err != nil {
+			return err
+		}
+		// Sin el resumen del commit: una consulta al consenso por bloque anularía la ventaja del batch
+		blocks = append(blocks, &blockResponse{Block: block, Finalized: true})
+		return nil
+	}
+	for _, height := range req.Heights {
+		if err := add(s.storage.GetBlock(height)); err != nil {
+			http.Error(w, "Error decoding block", http.StatusInternalServerError)
+			return
+		}
+	}
+	for _, hash := range req.Hashes {
+		if err := add(s.storage.GetBlockByHash(hash)); err != nil {
+			http.Error(w, "Error decoding block", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"blocks": blocks,
+	})
+}
+
+// handleTransactionsBatchGet maneja POST /api/v1/transactions/batch-get
+// Body: {"hashes": ["0x...", ...]}
+// Retorna las transacciones en el orden pedido, con null en las que no se encuentran
+func (s *RestServer) handleTransactionsBatchGet(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Hashes []string `json:"hashes"`
+	}
+	if !decodeBatchRequest(w, r, &req, func() int { return len(req.Hashes) }) {
+		return
+	}
+
+	transactions := make([]json.RawMessage, len(req.Hashes))
+	for i, hash := range req.Hashes {
+		txData, err := s.storage.GetTransaction(hash)
+		if err != nil {
+			continue
+		}
+		if transactions[i], err = consensus.TransactionJSON(txData); err != nil {
+			http.Error(w, "Error decoding transaction", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transactions": transactions,
+	})
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestRestServer_BatchGet prueba que batch-get responda en el orden pedido, con null en los faltantes
+func TestRestServer_BatchGet(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+
+	hash := "0x00000000000000000000000000000000000000000000000000000000000000aa"
+	if err := db.SaveCanonicalBlock(3, hash, []byte(`{"Header": {"Height": 3, "Hash": "`+hash+`"}}`)); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+	if err := db.SaveTransaction("0xabc", []byte(`{"hash":"0xabc"}`)); err != nil {
+		t.Fatalf("Error guardando transacción: %v", err)
+	}
+	post := func(handler http.HandlerFunc, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		return rr
+	}
+
+	var blocks struct {
+		Blocks []*struct {
+			Header    struct{ Height uint64 }
+			Finalized bool `json:"finalized"`
+		} `json:"blocks"`
+	}
+	for _, body := range []string{`{"heights":[4,3]}`, `{"hashes":["0x00000000000000000000000000000000000000000000000000000000000000bb","` + hash + `"]}`} {
+		rr := post(server.handleBlocksBatchGet, body)
+		if err := json.Unmarshal(rr.Body.Bytes(), &blocks); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("%s: status %d, %s (%v)", body, rr.Code, rr.Body.String(), err)
+		}
+		if len(blocks.Blocks) != 2 || blocks.Blocks[0] != nil || blocks.Blocks[1] == nil ||
+			blocks.Blocks[1].Header.Height != 3 || !blocks.Blocks[1].Finalized {
+			t.Errorf("%s: bloques incorrectos: %s", body, rr.Body.String())
+		}
+	}
+
+	rr := post(server.handleTransactionsBatchGet, `{"hashes":["0xabc","0xdef"]}`)
+	var txs struct {
+		Transactions []json.RawMessage `json:"transactions"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &txs); err != nil || len(txs.Transactions) != 2 ||
+		string(txs.Transactions[0]) != `{"hash":"0xabc"}` || string(txs.Transactions[1]) != "null" {
+		t.Errorf("Transacciones incorrectas: %s (%v)", rr.Body.String(), err)
+	}
+
+	tooMany := `{"heights":[` + strings.Repeat("1,", maxBatchGetItems) + `1]}`
+	for _, body := range []string{`{}`, `{"heights":[1],"hashes":["` + hash + `"]}`, `{"hashes":["0x12"]}`, tooMany, `[`} {
+		if rr := post(server.handleBlocksBatchGet, body); rr.Code != http.StatusBadRequest {
+			t.Errorf("%.40s: status %d, esperado 400", body, rr.Code)
+		}
+	}
+	rr = httptest.NewRecorder()
+	server.handleTransactionsBatchGet(rr, httptest.NewRequest("GET", "/", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET debería rechazarse: %d", rr.Code)
+	}
+}
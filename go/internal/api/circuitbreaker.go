@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// Valores por defecto de los timeouts y del circuit breaker de los handlers que dependen del consenso
+const (
+	defaultConsensusTimeout         = 5 * time.Second
+	defaultSubmitTxTimeout          = 12 * time.Second // Mayor que el timeout de broadcast en modo commit
+	defaultBreakerFailures          = 5
+	defaultBreakerOpenDuration      = 30 * time.Second
+	consensusBreakerHealthComponent = "consensus_breaker"
+)
+
+// Estados del circuit breaker
+const (
+	breakerClosed   = "closed"    // Las peticiones pasan normalmente
+	breakerOpen     = "open"      // Las peticiones se rechazan con 503 hasta que venza openDuration
+	breakerHalfOpen = "half_open" // Se deja pasar una petición de prueba
+)
+
+// circuitBreaker corta las peticiones al consenso cuando falla repetidamente (timeouts),
+// para que un CometBFT trabado no deje colgados a los clientes hasta el timeout del servidor
+type circuitBreaker struct {
+	mu           sync.Mutex
+	threshold    int
+	openDuration time.Duration
+	state        string
+	failures     int       // Fallos consecutivos
+	openedAt     time.Time // Momento en que se abrió
+	probing      bool      // Hay una petición de prueba en curso (half-open)
+	onChange     func(state string, failures int, retryAfter time.Duration)
+	now          func() time.Time
+}
+
+// newCircuitBreaker crea un breaker cerrado (valores <= 0 = por defecto)
+func newCircuitBreaker(threshold int, openDuration time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = defaultBreakerFailures
+	}
+	if openDuration <= 0 {
+		openDuration = defaultBreakerOpenDuration
+	}
+	return &circuitBreaker{
+		threshold:    threshold,
+		openDuration: openDuration,
+		state:        breakerClosed,
+		now:          time.Now,
+	}
+}
+
+// allow retorna si una petición puede pasar; si no, cuánto falta para volver a intentar
+func (b *circuitBreaker) allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		remaining := b.openDuration - b.now().Sub(b.openedAt)
+		if remaining > 0 {
+			return false, remaining
+		}
+		b.setState(breakerHalfOpen)
+		b.probing = true
+		return true, 0
+	case breakerHalfOpen:
+		// Solo una petición de prueba a la vez
+		if b.probing {
+			return false, time.Second
+		}
+		b.probing = true
+		return true, 0
+	}
+	return true, 0
+}
+
+// record registra el resultado de una petición que pasó por allow
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		if b.state != breakerClosed {
+			b.setState(breakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.setState(breakerOpen)
+	}
+}
+
+// release libera la petición de prueba sin registrar un resultado
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State retorna el estado actual y los fallos consecutivos
+func (b *circuitBreaker) State() (string, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.failures
+}
+
+// setState cambia el estado y notifica (requiere b.mu)
+func (b *circuitBreaker) setState(state string) {
+	b.state = state
+	if b.onChange != nil {
+		var retryAfter time.Duration
+		if state == breakerOpen {
+			retryAfter = b.openDuration
+		}
+		b.onChange(state, b.failures, retryAfter)
+	}
+}
+
+// consensusHandler aplica a un handler que depende del consenso un deadline propio y el circuit breaker:
+// con el breaker abierto responde 503 con Retry-After sin llamar al consenso.
+// Cuenta como fallo que venza el deadline o que el handler responda 504
+func (s *RestServer) consensusHandler(timeout time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.breaker != nil {
+			allowed, retryAfter := s.breaker.allow()
+			if !allowed {
+				writeBreakerOpen(w, retryAfter)
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		if s.breaker != nil {
+			// En defer para liberar la petición de prueba aunque el handler entre en pánico (cuenta como fallo)
+			defer func() {
+				if completed && r.Context().Err() != nil {
+					// El cliente se fue: no dice nada del estado del consenso
+					s.breaker.release()
+					return
+				}
+				timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+				s.breaker.record(!completed || timedOut || recorder.status == http.StatusGatewayTimeout)
+			}()
+		}
+		next(recorder, r.WithContext(ctx))
+		completed = true
+	}
+}
+
+// writeBreakerOpen responde 503 (CodeUnavailable) con Retry-After en segundos, redondeado hacia arriba
+func writeBreakerOpen(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	writeTxError(w, consensus.NewTxError(consensus.CodeUnavailable, "Consensus backend degraded, retry after %ds", seconds))
+}
+
+// consensusErrorStatus retorna 504 si el error es un deadline vencido y fallback en otro caso
+func consensusErrorStatus(err error, fallback int) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return fallback
+}
+
+// reportBreakerState publica el estado del breaker como componente de /health
+// Abierto o en prueba se reporta como "warning": el nodo queda "degraded" pero sigue sirviendo lecturas de storage
+func (s *RestServer) reportBreakerState(state string, failures int, retryAfter time.Duration) {
+	if s.healthChecker == nil {
+		return
+	}
+	switch state {
+	case breakerOpen:
+		s.healthChecker.UpdateComponent(consensusBreakerHealthComponent, "warning",
+			fmt.Sprintf("Circuit breaker abierto: %d fallos consecutivos del consenso, reintento en %s", failures, retryAfter))
+	case breakerHalfOpen:
+		s.healthChecker.UpdateComponent(consensusBreakerHealthComponent, "warning", "Circuit breaker en prueba (half-open)")
+	default:
+		s.healthChecker.UpdateComponent(consensusBreakerHealthComponent, "ok", "Circuit breaker cerrado")
+	}
+}
+
+// statusRecorder registra el status de la respuesta de un handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader implementa http.ResponseWriter
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write implementa http.ResponseWriter
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(data)
+}
+
+// Hijack implementa http.Hijacker (upgrade a WebSocket de la watchlist)
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("el ResponseWriter no soporta Hijack")
+	}
+	r.status = http.StatusSwitchingProtocols
+	r.wroteHeader = true
+	return hijacker.Hijack()
+}
+
+// Flush implementa http.Flusher
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap expone el ResponseWriter original a http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/health"
+)
+
+// TestCircuitBreaker_Transitions prueba closed -> open -> half-open -> open/closed
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	b := newCircuitBreaker(2, 10*time.Second)
+	now := time.Unix(1700000000, 0)
+	b.now = func() time.Time { return now }
+
+	b.allow()
+	b.record(true)
+	if state, failures := b.State(); state != breakerClosed || failures != 1 {
+		t.Fatalf("Un fallo no debería abrir el breaker: %s, %d", state, failures)
+	}
+	b.allow()
+	b.record(true)
+	if state, _ := b.State(); state != breakerOpen {
+		t.Fatalf("Dos fallos deberían abrir el breaker: %s", state)
+	}
+
+	now = now.Add(4 * time.Second)
+	if allowed, retryAfter := b.allow(); allowed || retryAfter != 6*time.Second {
+		t.Errorf("Abierto: esperado rechazo con 6s, obtenido %v, %s", allowed, retryAfter)
+	}
+
+	// Vencido el plazo pasa una sola petición de prueba; si falla vuelve a abrirse
+	now = now.Add(6 * time.Second)
+	if allowed, _ := b.allow(); !allowed {
+		t.Fatal("Debería pasar la petición de prueba")
+	}
+	if allowed, _ := b.allow(); allowed {
+		t.Error("Solo debería pasar una petición de prueba a la vez")
+	}
+	b.record(true)
+	if state, _ := b.State(); state != breakerOpen {
+		t.Fatalf("Una prueba fallida debería reabrir el breaker: %s", state)
+	}
+
+	now = now.Add(10 * time.Second)
+	b.allow()
+	b.record(false)
+	if state, failures := b.State(); state != breakerClosed || failures != 0 {
+		t.Errorf("Una prueba exitosa debería cerrar el breaker: %s, %d", state, failures)
+	}
+}
+
+// TestConsensusHandler_Breaker prueba el deadline por handler, el 503 con Retry-After y el estado en /health
+func TestConsensusHandler_Breaker(t *testing.T) {
+	s := &RestServer{
+		healthChecker: health.NewHealthChecker(),
+		breaker:       newCircuitBreaker(1, 30*time.Second),
+	}
+	s.breaker.onChange = s.reportBreakerState
+
+	calls := 0
+	handler := s.consensusHandler(10*time.Millisecond, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		<-r.Context().Done() // Consenso trabado
+		http.Error(w, "timeout", consensusErrorStatus(r.Context().Err(), http.StatusInternalServerError))
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/api/v1/node", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("Status esperado 504, obtenido %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/api/v1/node", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("Esperado 503 con Retry-After 30, obtenido %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if calls != 1 {
+		t.Errorf("Con el breaker abierto no debería llamarse al handler (llamadas: %d)", calls)
+	}
+
+	if comp := s.healthChecker.CheckHealth().Components[consensusBreakerHealthComponent]; comp.Status != "warning" {
+		t.Errorf("El breaker abierto debería verse en /health: %+v", comp)
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// complianceActor identifica los cambios hechos por el API de administración en la auditoría
+const complianceActor = "admin-api"
+
+// SetComplianceList configura la blacklist/allowlist de direcciones administrada por el API
+func (s *RestServer) SetComplianceList(list *consensus.ComplianceList) {
+	s.compliance = list
+}
+
+// handleAdminCompliance maneja /api/v1/admin/compliance
+// GET retorna el modo y las direcciones de la lista; POST agrega una dirección
+// Body: {"address": "0x...", "reason": "..."}
+func (s *RestServer) handleAdminCompliance(w http.ResponseWriter, r *http.Request) {
+	if s.compliance == nil {
+		http.Error(w, "Compliance module disabled: OXY_COMPLIANCE_MODE not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		entries := s.compliance.Entries()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"mode":      s.compliance.Mode(),
+			"addresses": entries,
+			"total":     len(entries),
+		})
+
+	case http.MethodPost:
+		var req struct {
+			Address string `json:"address"`
+			Reason  string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+			return
+		}
+		added, err := s.compliance.Add(strings.TrimSpace(req.Address), req.Reason, complianceActor)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		status := http.StatusOK
+		if added {
+			status = http.StatusCreated
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"added":   added,
+			"mode":    s.compliance.Mode(),
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminComplianceAddress maneja /api/v1/admin/compliance/{address} y /api/v1/admin/compliance/audit
+// DELETE quita una dirección de la lista (?reason= queda en la auditoría); GET .../audit retorna los
+// últimos cambios de la lista
+func (s *RestServer) handleAdminComplianceAddress(w http.ResponseWriter, r *http.Request) {
+	if s.compliance == nil {
+		http.Error(w, "Compliance module disabled: OXY_COMPLIANCE_MODE not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/compliance/")
+	if path == "audit" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		audit := s.compliance.Audit()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"audit": audit,
+			"total": len(audit),
+		})
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	removed, err := s.compliance.Remove(path, r.URL.Query().Get("reason"), complianceActor)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !removed {
+		http.Error(w, "Address not listed", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// handleDeployer maneja GET /api/v1/deployers/{address}
+// Retorna si el despliegue de contratos está restringido y si la cuenta puede desplegar o administra el registro
+func (s *RestServer) handleDeployer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.executor == nil {
+		http.Error(w, "EVM executor not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	address := strings.TrimPrefix(r.URL.Path, "/api/v1/deployers/")
+	if !common.IsHexAddress(address) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+
+	status, err := s.executor.GetCommittedDeployerStatus(address)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// maxDevMineBlocks limita los bloques de una sola llamada a /api/v1/dev/mine
+const maxDevMineBlocks = 1000
+
+// devOnly restringe un handler al modo desarrollo (404 en cualquier otro nodo)
+// No pide OXY_ADMIN_TOKEN: la cadena de desarrollo es local y sus claves son públicas
+func (s *RestServer) devOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.consensus == nil || !s.consensus.IsDevMode() {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// decodeDevRequest decodifica el body JSON de un endpoint de desarrollo (body vacío = valores por defecto)
+func decodeDevRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeDevResult escribe la respuesta de un endpoint de desarrollo o su error
+func writeDevResult(w http.ResponseWriter, result map[string]interface{}, err error) {
+	if err != nil {
+		if errors.Is(err, consensus.ErrNotDevMode) || errors.Is(err, consensus.ErrDevSnapshotNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeTxError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleDevMine maneja POST /api/v1/dev/mine (equivalente a evm_mine / hardhat_mine)
+// Body: {"blocks": 1, "timestamp": 1700000000}; ambos opcionales, timestamp es la hora del primer bloque
+// Responde cuando los bloques están confirmados
+func (s *RestServer) handleDevMine(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		Blocks    int   `json:"blocks"`
+		Timestamp int64 `json:"timestamp"`
+	}{Blocks: 1}
+	if !decodeDevRequest(w, r, &req) {
+		return
+	}
+	if req.Blocks < 1 || req.Blocks > maxDevMineBlocks {
+		http.Error(w, fmt.Sprintf("blocks must be between 1 and %d", maxDevMineBlocks), http.StatusBadRequest)
+		return
+	}
+
+	height, err := s.consensus.MineBlocks(r.Context(), req.Blocks, req.Timestamp)
+	writeDevResult(w, map[string]interface{}{"blocks": req.Blocks, "height": height}, err)
+}
+
+// handleDevIncreaseTime maneja POST /api/v1/dev/increase-time (equivalente a evm_increaseTime)
+// Body: {"seconds": 3600}. Responde con el adelanto total acumulado en segundos
+func (s *RestServer) handleDevIncreaseTime(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Seconds int64 `json:"seconds"`
+	}
+	if !decodeDevRequest(w, r, &req) {
+		return
+	}
+
+	offset, err := s.consensus.IncreaseTime(req.Seconds)
+	writeDevResult(w, map[string]interface{}{"offset": offset}, err)
+}
+
+// handleDevNextBlockTimestamp maneja POST /api/v1/dev/next-block-timestamp (equivalente a evm_setNextBlockTimestamp)
+// Body: {"timestamp": 1700000000} (unix, segundos; posterior al último bloque)
+func (s *RestServer) handleDevNextBlockTimestamp(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Timestamp int64 `json:"timestamp"`
+	}
+	if !decodeDevRequest(w, r, &req) {
+		return
+	}
+
+	err := s.consensus.SetNextBlockTimestamp(req.Timestamp)
+	writeDevResult(w, map[string]interface{}{"timestamp": req.Timestamp}, err)
+}
+
+// handleDevSnapshot maneja POST /api/v1/dev/snapshot (equivalente a evm_snapshot)
+// Responde con el ID a usar en /api/v1/dev/revert
+func (s *RestServer) handleDevSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, err := s.consensus.TakeDevSnapshot()
+	writeDevResult(w, map[string]interface{}{"id": id}, err)
+}
+
+// handleDevRevert maneja POST /api/v1/dev/revert (equivalente a evm_revert)
+// Body: {"id": 1}. El snapshot y los tomados después dejan de existir; 404 si no existe
+func (s *RestServer) handleDevRevert(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID uint64 `json:"id"`
+	}
+	if !decodeDevRequest(w, r, &req) {
+		return
+	}
+
+	height, err := s.consensus.RevertDevSnapshot(req.ID)
+	writeDevResult(w, map[string]interface{}{"id": req.ID, "height": height}, err)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// handleAdminGasProfile maneja /api/v1/admin/profile/gas
+// GET retorna el gas y el tiempo por categoría de opcode y precompile de los bloques recientes;
+// PUT activa o desactiva el profiling. Body de PUT: {"enabled": true}
+func (s *RestServer) handleAdminGasProfile(w http.ResponseWriter, r *http.Request) {
+	if s.executor == nil {
+		http.Error(w, "EVM executor not available", http.StatusServiceUnavailable)
+		return
+	}
+	profiler := s.executor.GasProfiler()
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Enabled == nil {
+			http.Error(w, "Missing enabled", http.StatusBadRequest)
+			return
+		}
+		profiler.SetEnabled(*req.Enabled)
+		state := "desactivado"
+		if *req.Enabled {
+			state = "activado"
+		}
+		apiLog.Infof("Profiling de gas %s por el API de administración", state)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(profiler.Report())
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// handleAdminHalt maneja /api/v1/admin/halt
+// GET retorna la detención programada; POST la programa y DELETE la cancela (o reanuda la cadena detenida)
+// Body de POST: {"height": 1000, "reason": "..."} (height 0 = detener después del último bloque confirmado)
+func (s *RestServer) handleAdminHalt(w http.ResponseWriter, r *http.Request) {
+	if s.consensus == nil {
+		http.Error(w, "Consensus not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	status := http.StatusOK
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			Height uint64 `json:"height"`
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Reason == "" {
+			req.Reason = "admin"
+		}
+		if _, err := s.consensus.ScheduleHalt(req.Height, req.Reason, consensus.HaltSourceAdmin); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		status = http.StatusAccepted
+	case http.MethodDelete:
+		if err := s.consensus.ResumeChain(); err != nil {
+			if errors.Is(err, consensus.ErrNoHaltScheduled) {
+				http.Error(w, "No halt scheduled", http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(s.consensus.HaltStatus())
+}
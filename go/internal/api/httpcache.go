@@ -0,0 +1,74 @@
+package api
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/wire"
+)
+
+// Cache-Control de las respuestas con ETag
+const (
+	// Bloques y transacciones confirmados: CometBFT no los revierte, exploradores y CDNs pueden guardarlos un año
+	cacheControlImmutable = "public, max-age=31536000, immutable"
+	// Recursos que pueden cambiar (último bloque, commit todavía no definitivo): se revalidan con If-None-Match
+	cacheControlRevalidate = "public, no-cache"
+)
+
+// etagFor retorna un ETag fuerte calculado del cuerpo de la respuesta
+func etagFor(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%x"`, sum[:16])
+}
+
+// etagMatches retorna si If-None-Match incluye el ETag (o es "*"); los ETag débiles (W/) se comparan sin el prefijo
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCacheableJSON responde un cuerpo JSON con ETag y Cache-Control, o 304 Not Modified si el cliente ya
+// tiene esa versión
+func writeCacheableJSON(w http.ResponseWriter, r *http.Request, body []byte, immutable bool) {
+	writeCacheable(w, r, body, "application/json", immutable)
+}
+
+// writeCacheable responde un cuerpo con ETag y Cache-Control, o 304 Not Modified si el cliente ya tiene esa
+// versión. El cuerpo depende de Accept (JSON o protobuf), por lo que los caches lo separan con Vary
+func writeCacheable(w http.ResponseWriter, r *http.Request, body []byte, contentType string, immutable bool) {
+	etag := etagFor(body)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Vary", "Accept")
+	if immutable {
+		w.Header().Set("Cache-Control", cacheControlImmutable)
+	} else {
+		w.Header().Set("Cache-Control", cacheControlRevalidate)
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// acceptsProto retorna si el cliente pidió la respuesta en protobuf (types.proto) con Accept
+func acceptsProto(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(accept, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), wire.ContentType) {
+			return true
+		}
+	}
+	return false
+}
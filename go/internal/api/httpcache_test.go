@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/wire"
+)
+
+// TestRestServer_ConditionalRequests prueba el ETag, el Cache-Control y las respuestas 304 de transacciones y bloques
+func TestRestServer_ConditionalRequests(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+
+	if err := db.SaveTransaction("0xabc", []byte(`{"hash":"0xabc"}`)); err != nil {
+		t.Fatalf("Error guardando transacción: %v", err)
+	}
+	get := func(handler http.HandlerFunc, path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	rr := get(server.handleTransactions, "/api/v1/transactions/0xabc", "")
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || etag == "" || rr.Header().Get("Cache-Control") != cacheControlImmutable {
+		t.Fatalf("Transacción sin cabeceras de cache: %d %v", rr.Code, rr.Header())
+	}
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"otro", ` + etag, "*"} {
+		rr = get(server.handleTransactions, "/api/v1/transactions/0xabc", ifNoneMatch)
+		if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 || rr.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %s: status %d, cuerpo %q", ifNoneMatch, rr.Code, rr.Body.String())
+		}
+	}
+	if rr = get(server.handleTransactions, "/api/v1/transactions/0xabc", `"otro"`); rr.Code != http.StatusOK {
+		t.Errorf("Un ETag distinto debería responder el recurso: %d", rr.Code)
+	}
+
+	// Sin el commit definitivo el bloque se revalida en cada petición
+	hash := "0x00000000000000000000000000000000000000000000000000000000000000aa"
+	if err := db.SaveCanonicalBlock(3, hash, []byte(`{"Header": {"Height": 3, "Hash": "`+hash+`"}}`)); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+	rr = get(server.handleBlocks, "/api/v1/blocks/3", "")
+	if rr.Code != http.StatusOK || rr.Header().Get("Cache-Control") != cacheControlRevalidate {
+		t.Fatalf("Bloque sin commit con cabeceras incorrectas: %d %v", rr.Code, rr.Header())
+	}
+	if rr = get(server.handleBlocks, "/api/v1/blocks/3", rr.Header().Get("ETag")); rr.Code != http.StatusNotModified {
+		t.Errorf("Bloque no modificado: status %d", rr.Code)
+	}
+}
+
+// TestRestServer_ProtoResponses prueba que Accept: application/x-protobuf retorne los tipos de types.proto
+func TestRestServer_ProtoResponses(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+
+	if err := db.SaveTransaction("0xabc", []byte(`{"Hash":"0xabc","Nonce":4,"Data":"YWJj"}`)); err != nil {
+		t.Fatalf("Error guardando transacción: %v", err)
+	}
+	hash := "0x00000000000000000000000000000000000000000000000000000000000000aa"
+	if err := db.SaveCanonicalBlock(3, hash, []byte(`{"Header": {"Height": 3, "Hash": "`+hash+`"}}`)); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+	get := func(handler http.HandlerFunc, path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", accept)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	rr := get(server.handleTransactions, "/api/v1/transactions/0xabc", "application/x-protobuf; q=1, application/json; q=0.5")
+	var tx wire.Transaction
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != wire.ContentType || rr.Header().Get("Vary") != "Accept" {
+		t.Fatalf("Transacción protobuf con cabeceras incorrectas: %d %v", rr.Code, rr.Header())
+	}
+	if err := tx.Unmarshal(rr.Body.Bytes()); err != nil || tx.Hash != "0xabc" || tx.Nonce != 4 || string(tx.Data) != "abc" {
+		t.Errorf("Transacción protobuf incorrecta: %+v (%v)", tx, err)
+	}
+
+	rr = get(server.handleBlocks, "/api/v1/blocks/3", wire.ContentType)
+	var block wire.Block
+	if err := block.Unmarshal(rr.Body.Bytes()); err != nil || block.Header == nil || block.Header.Height != 3 || block.Header.Hash != hash {
+		t.Errorf("Bloque protobuf incorrecto: %+v (%v)", block.Header, err)
+	}
+
+	if rr = get(server.handleBlocks, "/api/v1/blocks/3", "application/json"); rr.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Sin protobuf en Accept se espera JSON: %v", rr.Header())
+	}
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Valores por defecto de las claves de idempotencia
+const (
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyMaxKeys = 10000
+	maxIdempotencyKeyLength   = 255
+)
+
+// Cabeceras de idempotencia
+const (
+	headerIdempotencyKey      = "Idempotency-Key"
+	headerIdempotencyReplayed = "Idempotent-Replayed"
+)
+
+// idempotencyEntry es la respuesta guardada para una clave (pending mientras la petición original está en curso)
+type idempotencyEntry struct {
+	requestHash [32]byte
+	pending     bool
+	status      int
+	header      http.Header
+	body        []byte
+	expiresAt   time.Time
+}
+
+// idempotencyCache guarda las respuestas de los POST con Idempotency-Key durante ttl,
+// para que los reintentos de un cliente no vuelvan a enviar una transacción ni a fondear una cuenta
+type idempotencyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxKeys int
+	entries map[string]*idempotencyEntry
+	now     func() time.Time
+}
+
+// newIdempotencyCache crea una caché (valores <= 0 = por defecto)
+func newIdempotencyCache(ttl time.Duration, maxKeys int) *idempotencyCache {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	if maxKeys <= 0 {
+		maxKeys = defaultIdempotencyMaxKeys
+	}
+	return &idempotencyCache{
+		ttl:     ttl,
+		maxKeys: maxKeys,
+		entries: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+// wrap aplica la idempotencia a un handler. Sin Idempotency-Key la petición se procesa normalmente.
+// Con clave: una respuesta guardada se repite (cabecera Idempotent-Replayed), una petición en curso con la
+// misma clave recibe 409 y reutilizar la clave con otro body recibe 422.
+// Los 5xx y 429 no se guardan para que el cliente pueda reintentar
+func (c *idempotencyCache) wrap(next http.HandlerFunc) http.HandlerFunc {
+	if c == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(headerIdempotencyKey)
+		if key == "" || r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, "Idempotency-Key too long", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Error reading request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		requestHash := sha256.Sum256(body)
+		cacheKey := r.URL.Path + "\x00" + key
+
+		entry, status := c.begin(cacheKey, requestHash)
+		switch status {
+		case http.StatusConflict:
+			http.Error(w, "A request with this Idempotency-Key is still in progress", http.StatusConflict)
+			return
+		case http.StatusUnprocessableEntity:
+			http.Error(w, "Idempotency-Key reused with a different request body", http.StatusUnprocessableEntity)
+			return
+		}
+		if entry != nil {
+			for name, values := range entry.header {
+				w.Header()[name] = values
+			}
+			w.Header().Set(headerIdempotencyReplayed, "true")
+			w.WriteHeader(entry.status)
+			w.Write(entry.body)
+			return
+		}
+
+		// Si el handler entra en pánico la clave se libera para permitir el reintento
+		completed := false
+		defer func() {
+			if !completed {
+				c.finish(cacheKey, http.StatusInternalServerError, nil, nil)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r)
+		completed = true
+		c.finish(cacheKey, recorder.status, w.Header(), recorder.body.Bytes())
+	}
+}
+
+// begin reserva la clave para una petición nueva; retorna la respuesta guardada si la hay,
+// o un status de conflicto (409 en curso, 422 body distinto)
+func (c *idempotencyCache) begin(cacheKey string, requestHash [32]byte) (*idempotencyEntry, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if entry, exists := c.entries[cacheKey]; exists && now.Before(entry.expiresAt) {
+		switch {
+		case entry.requestHash != requestHash:
+			return nil, http.StatusUnprocessableEntity
+		case entry.pending:
+			return nil, http.StatusConflict
+		}
+		return entry, http.StatusOK
+	}
+
+	if len(c.entries) >= c.maxKeys {
+		c.evict(now)
+	}
+	c.entries[cacheKey] = &idempotencyEntry{
+		requestHash: requestHash,
+		pending:     true,
+		expiresAt:   now.Add(c.ttl),
+	}
+	return nil, http.StatusOK
+}
+
+// finish guarda la respuesta de la petición original (o libera la clave si no debe guardarse)
+func (c *idempotencyCache) finish(cacheKey string, status int, header http.Header, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[cacheKey]
+	if !exists {
+		return
+	}
+	if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+		delete(c.entries, cacheKey)
+		return
+	}
+	entry.pending = false
+	entry.status = status
+	entry.header = header.Clone()
+	entry.body = append([]byte(nil), body...)
+	entry.expiresAt = c.now().Add(c.ttl)
+}
+
+// evict elimina las entradas vencidas y, si no alcanza, la que vence antes (nunca las que están en curso)
+func (c *idempotencyCache) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if !entry.pending && (oldestKey == "" || entry.expiresAt.Before(oldest)) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	if len(c.entries) >= c.maxKeys && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// responseRecorder copia la respuesta de un handler mientras la escribe al cliente
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// WriteHeader implementa http.ResponseWriter
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write implementa http.ResponseWriter
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
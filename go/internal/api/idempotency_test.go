@@ -0,0 +1,115 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// idempotentPost envía un POST con Idempotency-Key al handler
+func idempotentPost(handler http.HandlerFunc, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/v1/submit-tx", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(headerIdempotencyKey, key)
+	}
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+	return rr
+}
+
+// TestIdempotencyCache_Replay prueba que un reintento con la misma clave no vuelva a ejecutar el handler
+func TestIdempotencyCache_Replay(t *testing.T) {
+	cache := newIdempotencyCache(time.Minute, 10)
+	calls := 0
+	handler := cache.wrap(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"call":%d,"body":%q}`, calls, body)
+	})
+
+	first := idempotentPost(handler, "key-1", `{"a":1}`)
+	retry := idempotentPost(handler, "key-1", `{"a":1}`)
+	if calls != 1 {
+		t.Fatalf("El handler debería ejecutarse una vez, se ejecutó %d", calls)
+	}
+	if retry.Body.String() != first.Body.String() || retry.Code != http.StatusOK {
+		t.Errorf("La respuesta repetida difiere: %s vs %s", retry.Body.String(), first.Body.String())
+	}
+	if retry.Header().Get(headerIdempotencyReplayed) != "true" || retry.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Cabeceras de la respuesta repetida incorrectas: %v", retry.Header())
+	}
+
+	// Otra clave o sin clave se procesan normalmente
+	idempotentPost(handler, "key-2", `{"a":1}`)
+	idempotentPost(handler, "", `{"a":1}`)
+	if calls != 3 {
+		t.Errorf("Se esperaban 3 ejecuciones, hubo %d", calls)
+	}
+
+	// Reutilizar la clave con otro body es un error
+	if rr := idempotentPost(handler, "key-1", `{"a":2}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Status esperado 422, obtenido %d", rr.Code)
+	}
+
+	// Al vencer el TTL la clave se puede reutilizar
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	idempotentPost(handler, "key-1", `{"a":2}`)
+	if calls != 4 {
+		t.Errorf("La clave vencida debería procesarse de nuevo (ejecuciones: %d)", calls)
+	}
+}
+
+// TestIdempotencyCache_RetryableErrors prueba que los 5xx/429 no se guarden y que una petición en curso bloquee la clave
+func TestIdempotencyCache_RetryableErrors(t *testing.T) {
+	cache := newIdempotencyCache(time.Minute, 10)
+	status := http.StatusServiceUnavailable
+	handler := cache.wrap(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+
+	idempotentPost(handler, "key", "{}")
+	status = http.StatusCreated
+	if rr := idempotentPost(handler, "key", "{}"); rr.Code != http.StatusCreated || rr.Header().Get(headerIdempotencyReplayed) != "" {
+		t.Errorf("Un 503 no debería guardarse: status %d", rr.Code)
+	}
+
+	// Petición en curso
+	release := make(chan struct{})
+	started := make(chan struct{})
+	slow := cache.wrap(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	done := make(chan struct{})
+	go func() {
+		idempotentPost(slow, "slow", "{}")
+		close(done)
+	}()
+	<-started
+	if rr := idempotentPost(slow, "slow", "{}"); rr.Code != http.StatusConflict {
+		t.Errorf("Status esperado 409 con la petición en curso, obtenido %d", rr.Code)
+	}
+	close(release)
+	<-done
+}
+
+// TestIdempotencyCache_Eviction prueba el límite de claves guardadas
+func TestIdempotencyCache_Eviction(t *testing.T) {
+	cache := newIdempotencyCache(time.Minute, 2)
+	handler := cache.wrap(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	for i := 0; i < 5; i++ {
+		idempotentPost(handler, fmt.Sprintf("key-%d", i), "{}")
+	}
+	if len(cache.entries) > 2 {
+		t.Errorf("La caché debería conservar como máximo 2 claves, tiene %d", len(cache.entries))
+	}
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
+)
+
+// SetMultisigPool configura el pool de transacciones multisig que recolectan firmas
+func (s *RestServer) SetMultisigPool(pool *consensus.MultisigPool) {
+	s.multisig = pool
+}
+
+// handleMultisigAddress maneja POST /api/v1/multisig
+// Body: {"owners": ["0x..."], "threshold": 2}. Retorna la dirección de la cuenta multisig y los owners en el
+// orden canónico. La dirección no se registra: existe en cuanto recibe fondos
+func (s *RestServer) handleMultisigAddress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Owners    []string `json:"owners"`
+		Threshold uint64   `json:"threshold"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+		return
+	}
+	address, err := cryptosigner.MultisigAddress(req.Owners, req.Threshold)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sorted, _ := cryptosigner.SortMultisigOwners(req.Owners, req.Threshold)
+	owners := make([]string, 0, len(sorted))
+	for _, owner := range sorted {
+		owners = append(owners, owner.Hex())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"address":   address.Hex(),
+		"owners":    owners,
+		"threshold": req.Threshold,
+	})
+}
+
+// handleMultisigProposals maneja /api/v1/multisig/proposals
+// GET lista las propuestas pendientes (?address= filtra por cuenta multisig); POST propone una transacción:
+// el esquema de submit-tx sin signature, con "multisig": {"owners", "threshold"} y hash y from opcionales
+func (s *RestServer) handleMultisigProposals(w http.ResponseWriter, r *http.Request) {
+	if s.multisig == nil {
+		http.Error(w, "Multisig not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		proposals := s.multisig.List(r.URL.Query().Get("address"))
+		encoded := make([]map[string]interface{}, 0, len(proposals))
+		for _, proposal := range proposals {
+			encoded = append(encoded, multisigProposalJSON(proposal))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"proposals": encoded,
+			"total":     len(encoded),
+		})
+
+	case http.MethodPost:
+		tx, err := decodeMultisigProposal(r.Body, getEnvInt("OXY_REST_MAX_TX_DATA_BYTES", defaultMaxTxDataBytes))
+		if err != nil {
+			writeTxError(w, err)
+			return
+		}
+		proposal, err := s.multisig.Propose(tx)
+		if err != nil {
+			writeTxError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(multisigProposalJSON(proposal))
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleMultisigProposal maneja /api/v1/multisig/proposals/{hash} y /api/v1/multisig/proposals/{hash}/signatures
+// GET retorna la propuesta y DELETE la descarta; POST .../signatures agrega la firma de un owner
+// Body: {"signer": "0x...", "signature": "0x..."} (firma del hash, directa o EIP-712 como en tx sign)
+// Con ready = true, transaction se envía tal cual a /api/v1/submit-tx
+func (s *RestServer) handleMultisigProposal(w http.ResponseWriter, r *http.Request) {
+	if s.multisig == nil {
+		http.Error(w, "Multisig not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/multisig/proposals/")
+	if hash, ok := strings.CutSuffix(path, "/signatures"); ok {
+		s.handleMultisigSignature(w, r, hash)
+		return
+	}
+	hash := path
+
+	switch r.Method {
+	case http.MethodGet:
+		proposal, ok := s.multisig.Get(hash)
+		if !ok {
+			http.Error(w, "Proposal not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(multisigProposalJSON(proposal))
+
+	case http.MethodDelete:
+		if err := s.multisig.Remove(hash); err != nil {
+			if errors.Is(err, consensus.ErrMultisigProposalNotFound) {
+				http.Error(w, "Proposal not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleMultisigSignature maneja POST /api/v1/multisig/proposals/{hash}/signatures
+func (s *RestServer) handleMultisigSignature(w http.ResponseWriter, r *http.Request, hash string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Signer    string `json:"signer"`
+		Signature string `json:"signature"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+		return
+	}
+	signature, err := decodeBytesField(req.Signature)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid signature: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	proposal, err := s.multisig.AddSignature(hash, consensus.MultisigSignature{Signer: req.Signer, Signature: signature})
+	if err != nil {
+		if errors.Is(err, consensus.ErrMultisigProposalNotFound) {
+			http.Error(w, "Proposal not found", http.StatusNotFound)
+			return
+		}
+		writeTxError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(multisigProposalJSON(proposal))
+}
+
+// multisigProposalJSON codifica una propuesta con la transacción en el esquema de submit-tx
+// (data y firmas en base64), para enviarla sin cambios cuando reúne el umbral
+func multisigProposalJSON(proposal *consensus.MultisigProposal) map[string]interface{} {
+	tx := proposal.Transaction
+	signatures := make([]map[string]interface{}, 0, len(tx.Multisig.Signatures))
+	for _, sig := range tx.Multisig.Signatures {
+		signatures = append(signatures, map[string]interface{}{
+			"signer":    sig.Signer,
+			"signature": sig.Signature,
+		})
+	}
+	encoded := map[string]interface{}{
+		"hash":     tx.Hash,
+		"from":     tx.From,
+		"to":       tx.To,
+		"value":    tx.Value,
+		"gasLimit": tx.GasLimit,
+		"gasPrice": tx.GasPrice,
+		"nonce":    tx.Nonce,
+		"multisig": map[string]interface{}{
+			"threshold":  tx.Multisig.Threshold,
+			"owners":     tx.Multisig.Owners,
+			"signatures": signatures,
+		},
+	}
+	if len(tx.Data) > 0 {
+		encoded["data"] = tx.Data
+	}
+	if tx.Timestamp > 0 {
+		encoded["timestamp"] = tx.Timestamp
+	}
+	if len(tx.AccessList) > 0 {
+		encoded["accessList"] = tx.AccessList
+	}
+	if tx.FeePayer != nil {
+		encoded["feePayer"] = tx.FeePayer
+	}
+
+	return map[string]interface{}{
+		"hash":        tx.Hash,
+		"address":     tx.From,
+		"ready":       proposal.Ready,
+		"signatures":  len(tx.Multisig.Signatures),
+		"threshold":   tx.Multisig.Threshold,
+		"transaction": encoded,
+		"createdAt":   proposal.CreatedAt,
+	}
+}
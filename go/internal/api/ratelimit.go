@@ -0,0 +1,454 @@
+package api
+
+import (
+	"bufio"
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Clases de endpoint con límites independientes
+const (
+	endpointClassRead  = "read"  // GET/HEAD: consultas
+	endpointClassWrite = "write" // POST/DELETE...: envío de transacciones y administración
+)
+
+// defaultRateLimitMaxClients es la cantidad de buckets que conserva el store en memoria antes de expulsar (LRU)
+const defaultRateLimitMaxClients = 10000
+
+// rateLimit es la tasa sostenida (req/s) y la ráfaga permitida de una clase de endpoint
+type rateLimit struct {
+	rps   float64
+	burst float64
+}
+
+// rateLimitStore guarda los token buckets; Allow consume un token de key si hay disponible
+type rateLimitStore interface {
+	Allow(key string, limit rateLimit, now time.Time) (bool, error)
+}
+
+// rateLimiter aplica los límites por IP de cliente y clase de endpoint
+type rateLimiter struct {
+	limits         map[string]rateLimit
+	store          rateLimitStore
+	fallback       rateLimitStore // Se usa si el store compartido falla (no se bloquea el API por Redis caído)
+	trustedProxies []*net.IPNet
+}
+
+// newRateLimiterFromEnv configura el rate limiter desde variables de entorno
+func newRateLimiterFromEnv() *rateLimiter {
+	readLimit := rateLimit{
+		rps:   getEnvFloat("OXY_REST_RATE_LIMIT_RPS", 50),
+		burst: getEnvFloat("OXY_REST_BURST", 100),
+	}
+	writeLimit := rateLimit{
+		rps:   getEnvFloat("OXY_REST_WRITE_RATE_LIMIT_RPS", 10),
+		burst: getEnvFloat("OXY_REST_WRITE_BURST", 20),
+	}
+
+	memory := newMemoryRateLimitStore(getEnvInt("OXY_REST_RATE_LIMIT_MAX_CLIENTS", defaultRateLimitMaxClients))
+	rl := &rateLimiter{
+		limits: map[string]rateLimit{
+			endpointClassRead:  readLimit,
+			endpointClassWrite: writeLimit,
+		},
+		store: memory,
+	}
+
+	if redisURL := os.Getenv("OXY_REST_RATE_LIMIT_REDIS_URL"); redisURL != "" {
+		client, err := newRedisClient(redisURL)
+		if err != nil {
+			apiLog.Warnf("⚠️ OXY_REST_RATE_LIMIT_REDIS_URL inválida, usando rate limit en memoria: %v", err)
+		} else {
+			rl.store = &redisRateLimitStore{client: client, prefix: "oxy:ratelimit:"}
+			rl.fallback = memory
+		}
+	}
+
+	proxies, err := parseTrustedProxies(os.Getenv("OXY_REST_TRUSTED_PROXIES"))
+	if err != nil {
+		apiLog.Warnf("⚠️ OXY_REST_TRUSTED_PROXIES inválida, se ignora X-Forwarded-For: %v", err)
+	}
+	rl.trustedProxies = proxies
+	return rl
+}
+
+// endpointClass clasifica una petición para elegir su límite
+func endpointClass(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return endpointClassRead
+	}
+	return endpointClassWrite
+}
+
+// allow consume un token del cliente de r en su clase de endpoint
+func (rl *rateLimiter) allow(r *http.Request, now time.Time) bool {
+	class := endpointClass(r)
+	limit, ok := rl.limits[class]
+	if !ok || limit.rps <= 0 {
+		return true
+	}
+	key := class + ":" + rl.clientIP(r)
+
+	allowed, err := rl.store.Allow(key, limit, now)
+	if err != nil {
+		if rl.fallback == nil {
+			return true
+		}
+		apiLog.Warnf("⚠️ Error en el store del rate limit, usando memoria: %v", err)
+		allowed, _ = rl.fallback.Allow(key, limit, now)
+	}
+	return allowed
+}
+
+// clientIP retorna la IP del cliente: la conexión directa o, si viene de un proxy de confianza,
+// la primera dirección de X-Forwarded-For (leída de derecha a izquierda) que no es un proxy de confianza
+func (rl *rateLimiter) clientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if len(rl.trustedProxies) == 0 || !rl.isTrusted(remote) {
+		return remote
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if ip == "" {
+			continue
+		}
+		if net.ParseIP(ip) == nil {
+			// Cabecera mal formada: no se confía en lo que queda a la izquierda
+			break
+		}
+		if !rl.isTrusted(ip) {
+			return ip
+		}
+		remote = ip
+	}
+	return remote
+}
+
+// isTrusted indica si una IP pertenece a la lista de proxies de confianza
+func (rl *rateLimiter) isTrusted(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range rl.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies interpreta una lista de IPs o CIDRs separados por coma
+func parseTrustedProxies(value string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("IP inválida: %s", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("CIDR inválido: %s", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// memoryBucket es un token bucket del store en memoria
+type memoryBucket struct {
+	key        string
+	tokens     float64
+	lastRefill time.Time
+}
+
+// memoryRateLimitStore guarda los buckets en memoria y expulsa los menos usados al superar maxClients
+type memoryRateLimitStore struct {
+	mu         sync.Mutex
+	maxClients int
+	buckets    map[string]*list.Element
+	lru        *list.List // Frente = usado más recientemente
+}
+
+// newMemoryRateLimitStore crea un store en memoria (maxClients <= 0 = valor por defecto)
+func newMemoryRateLimitStore(maxClients int) *memoryRateLimitStore {
+	if maxClients <= 0 {
+		maxClients = defaultRateLimitMaxClients
+	}
+	return &memoryRateLimitStore{
+		maxClients: maxClients,
+		buckets:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Allow implementa rateLimitStore
+func (s *memoryRateLimitStore) Allow(key string, limit rateLimit, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b *memoryBucket
+	if element, ok := s.buckets[key]; ok {
+		s.lru.MoveToFront(element)
+		b = element.Value.(*memoryBucket)
+	} else {
+		b = &memoryBucket{key: key, tokens: limit.burst, lastRefill: now}
+		s.buckets[key] = s.lru.PushFront(b)
+		for s.lru.Len() > s.maxClients {
+			oldest := s.lru.Back()
+			s.lru.Remove(oldest)
+			delete(s.buckets, oldest.Value.(*memoryBucket).key)
+		}
+	}
+
+	// Recargar tokens
+	if elapsed := now.Sub(b.lastRefill).Seconds(); elapsed > 0 {
+		b.tokens = minFloat(limit.burst, b.tokens+elapsed*limit.rps)
+		b.lastRefill = now
+	}
+	if b.tokens < 1 {
+		return false, nil
+	}
+	b.tokens--
+	return true, nil
+}
+
+// Len retorna la cantidad de buckets en memoria
+func (s *memoryRateLimitStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+// redisTokenBucketScript aplica el token bucket de forma atómica en Redis
+// KEYS[1] = bucket; ARGV = rps, burst, ahora (ms). Retorna 1 si se permitió la petición
+const redisTokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1]) or burst
+local ts = tonumber(data[2]) or now
+if now > ts then
+  tokens = math.min(burst, tokens + (now - ts) / 1000 * rate)
+  ts = now
+end
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return allowed
+`
+
+// redisRateLimitStore comparte los buckets entre instancias del API a través de Redis
+type redisRateLimitStore struct {
+	client *redisClient
+	prefix string
+}
+
+// Allow implementa rateLimitStore
+func (s *redisRateLimitStore) Allow(key string, limit rateLimit, now time.Time) (bool, error) {
+	reply, err := s.client.Do("EVAL", redisTokenBucketScript, "1", s.prefix+key,
+		strconv.FormatFloat(limit.rps, 'f', -1, 64),
+		strconv.FormatFloat(limit.burst, 'f', -1, 64),
+		strconv.FormatInt(now.UnixMilli(), 10),
+	)
+	if err != nil {
+		return false, err
+	}
+	allowed, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("respuesta inesperada de Redis: %v", reply)
+	}
+	return allowed == 1, nil
+}
+
+// redisClient es un cliente RESP mínimo (una conexión, reconexión automática) para el rate limit compartido
+type redisClient struct {
+	addr     string
+	username string
+	password string
+	db       int
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// newRedisClient crea un cliente desde una URL redis://[usuario:contraseña@]host:puerto[/db]
+func newRedisClient(rawURL string) (*redisClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("esquema no soportado: %s", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	client := &redisClient{addr: host, timeout: 500 * time.Millisecond}
+	if u.User != nil {
+		client.username = u.User.Username()
+		client.password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if client.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("db inválida: %s", db)
+		}
+	}
+	return client, nil
+}
+
+// Do ejecuta un comando y retorna la respuesta (string, int64, []interface{} o nil)
+func (c *redisClient) Do(args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := c.roundTrip(args...)
+	if err != nil {
+		var redisErr redisError
+		if !errors.As(err, &redisErr) {
+			// Error de red: descartar la conexión para reconectar en la próxima llamada
+			c.conn.Close()
+			c.conn = nil
+		}
+		return nil, err
+	}
+	return reply, nil
+}
+
+// connect abre la conexión y se autentica/selecciona la db si corresponde
+func (c *redisClient) connect() error {
+	conn, err := net.DialTimeout("tcp", c.addr, c.timeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+
+	if c.password != "" {
+		args := []string{"AUTH", c.password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.password}
+		}
+		if _, err := c.roundTrip(args...); err != nil {
+			c.conn.Close()
+			c.conn = nil
+			return fmt.Errorf("error autenticando en Redis: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := c.roundTrip("SELECT", strconv.Itoa(c.db)); err != nil {
+			c.conn.Close()
+			c.conn = nil
+			return fmt.Errorf("error seleccionando db de Redis: %w", err)
+		}
+	}
+	return nil
+}
+
+// roundTrip escribe un comando RESP y lee su respuesta
+func (c *redisClient) roundTrip(args ...string) (interface{}, error) {
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := c.conn.Write([]byte(cmd.String())); err != nil {
+		return nil, err
+	}
+	return readRESP(c.reader)
+}
+
+// redisError es un error retornado por Redis (la conexión sigue siendo válida)
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// readRESP lee una respuesta RESP
+func readRESP(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("respuesta RESP vacía")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readRESP(reader); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("tipo RESP desconocido: %q", line[0])
+}
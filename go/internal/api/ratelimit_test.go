@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bufio"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRateLimiter_ClientIP prueba que X-Forwarded-For solo se use detrás de un proxy de confianza
+func TestRateLimiter_ClientIP(t *testing.T) {
+	proxies, err := parseTrustedProxies("10.0.0.0/8, 192.168.1.1")
+	if err != nil {
+		t.Fatalf("Error parseando proxies: %v", err)
+	}
+	rl := &rateLimiter{trustedProxies: proxies}
+
+	cases := []struct {
+		remote    string
+		forwarded string
+		expected  string
+	}{
+		{"203.0.113.7:1234", "198.51.100.1", "203.0.113.7"},                     // Cliente directo: se ignora la cabecera
+		{"10.1.2.3:1234", "198.51.100.1", "198.51.100.1"},                       // Detrás de un proxy de confianza
+		{"10.1.2.3:1234", "1.1.1.1, 198.51.100.1, 192.168.1.1", "198.51.100.1"}, // Se salta la cadena de proxies
+		{"10.1.2.3:1234", "", "10.1.2.3"},                                       // Proxy sin cabecera
+		{"10.1.2.3:1234", "basura, 10.9.9.9", "10.9.9.9"},                       // Cabecera mal formada
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", "/health", nil)
+		req.RemoteAddr = c.remote
+		if c.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", c.forwarded)
+		}
+		if ip := rl.clientIP(req); ip != c.expected {
+			t.Errorf("clientIP(%s, %q) = %s, esperado %s", c.remote, c.forwarded, ip, c.expected)
+		}
+	}
+
+	if _, err := parseTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Error("Un CIDR inválido debería rechazarse")
+	}
+}
+
+// TestRateLimiter_EndpointClasses prueba límites independientes para lecturas y escrituras
+func TestRateLimiter_EndpointClasses(t *testing.T) {
+	rl := &rateLimiter{
+		limits: map[string]rateLimit{
+			endpointClassRead:  {rps: 1, burst: 3},
+			endpointClassWrite: {rps: 1, burst: 1},
+		},
+		store: newMemoryRateLimitStore(10),
+	}
+	now := time.Unix(1700000000, 0)
+
+	post := httptest.NewRequest("POST", "/api/v1/submit-tx", nil)
+	if !rl.allow(post, now) || rl.allow(post, now) {
+		t.Error("La escritura debería permitir una sola petición de ráfaga")
+	}
+	get := httptest.NewRequest("GET", "/api/v1/blocks/1", nil)
+	for i := 0; i < 3; i++ {
+		if !rl.allow(get, now) {
+			t.Fatalf("La lectura %d debería permitirse", i+1)
+		}
+	}
+	if rl.allow(get, now) {
+		t.Error("La cuarta lectura debería rechazarse")
+	}
+	if !rl.allow(post, now.Add(time.Second)) {
+		t.Error("La escritura debería recargarse después de un segundo")
+	}
+}
+
+// TestMemoryRateLimitStore_Eviction prueba la expulsión LRU de buckets
+func TestMemoryRateLimitStore_Eviction(t *testing.T) {
+	store := newMemoryRateLimitStore(2)
+	limit := rateLimit{rps: 1, burst: 1}
+	now := time.Unix(1700000000, 0)
+
+	store.Allow("a", limit, now)
+	store.Allow("b", limit, now)
+	store.Allow("a", limit, now) // "a" pasa a ser el más reciente (rechazada, sin tokens)
+	store.Allow("c", limit, now) // Expulsa "b"
+	if store.Len() != 2 {
+		t.Fatalf("Buckets: esperado 2, obtenido %d", store.Len())
+	}
+	if allowed, _ := store.Allow("a", limit, now); allowed {
+		t.Error("\"a\" debería conservar su bucket vacío")
+	}
+	if allowed, _ := store.Allow("b", limit, now); !allowed {
+		t.Error("\"b\" fue expulsado y debería empezar con un bucket lleno")
+	}
+}
+
+// TestRedisRateLimitStore prueba el store compartido contra un servidor RESP mínimo
+func TestRedisRateLimitStore(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error escuchando: %v", err)
+	}
+	defer listener.Close()
+
+	// Servidor falso: AUTH responde OK; EVAL permite las dos primeras peticiones
+	commands := make(chan []string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		evals := 0
+		for {
+			reply, err := readRESP(reader)
+			if err != nil {
+				return
+			}
+			items := reply.([]interface{})
+			args := make([]string, len(items))
+			for i, item := range items {
+				args[i] = item.(string)
+			}
+			commands <- args
+			switch args[0] {
+			case "AUTH":
+				conn.Write([]byte("+OK\r\n"))
+			case "EVAL":
+				evals++
+				if evals <= 2 {
+					conn.Write([]byte(":1\r\n"))
+				} else {
+					conn.Write([]byte(":0\r\n"))
+				}
+			default:
+				conn.Write([]byte("-ERR unknown command\r\n"))
+			}
+		}
+	}()
+
+	client, err := newRedisClient("redis://:secret@" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Error creando cliente: %v", err)
+	}
+	store := &redisRateLimitStore{client: client, prefix: "oxy:ratelimit:"}
+	limit := rateLimit{rps: 1, burst: 2}
+	now := time.Unix(1700000000, 0)
+
+	for i, expected := range []bool{true, true, false} {
+		allowed, err := store.Allow("read:1.2.3.4", limit, now)
+		if err != nil {
+			t.Fatalf("Error en Allow: %v", err)
+		}
+		if allowed != expected {
+			t.Errorf("Petición %d: esperado %v, obtenido %v", i+1, expected, allowed)
+		}
+	}
+
+	if auth := <-commands; strings.Join(auth, " ") != "AUTH secret" {
+		t.Errorf("Autenticación inesperada: %v", auth)
+	}
+	if eval := <-commands; len(eval) != 7 || eval[3] != "oxy:ratelimit:read:1.2.3.4" || eval[4] != "1" || eval[5] != "2" {
+		t.Errorf("EVAL inesperado: %q", eval)
+	}
+
+	// Los errores de Redis no cierran la conexión
+	if _, err := client.Do("PING"); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("Se esperaba el error de Redis: %v", err)
+	}
+	if client.conn == nil {
+		t.Error("Un error de Redis no debería descartar la conexión")
+	}
+}
@@ -1,33 +1,60 @@
 package api
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
 	"os"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/Q-YZX0/oxy-blockchain/internal/assets"
 	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
 	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
 	"github.com/Q-YZX0/oxy-blockchain/internal/health"
+	"github.com/Q-YZX0/oxy-blockchain/internal/logger"
 	"github.com/Q-YZX0/oxy-blockchain/internal/metrics"
+	"github.com/Q-YZX0/oxy-blockchain/internal/network"
+	"github.com/Q-YZX0/oxy-blockchain/internal/rediscache"
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/Q-YZX0/oxy-blockchain/internal/tracing"
+	"github.com/Q-YZX0/oxy-blockchain/internal/watchlist"
+	"github.com/Q-YZX0/oxy-blockchain/internal/wire"
+	"github.com/gorilla/websocket"
 )
 
+// apiLog es el logger del módulo api
+var apiLog = logger.For("api")
+
 // RestServer maneja el servidor HTTP REST local
 type RestServer struct {
-	host          string
-	port          string
-	storage       *storage.BlockchainDB
-	consensus     *consensus.CometBFT
-	healthChecker *health.HealthChecker
-	metrics       *metrics.Metrics
-	executor      *execution.EVMExecutor
-	server        *http.Server
+	host             string
+	port             string
+	storage          *storage.BlockchainDB
+	consensus        *consensus.CometBFT
+	healthChecker    *health.HealthChecker
+	metrics          *metrics.Metrics
+	executor         *execution.EVMExecutor
+	peerScorer       *network.PeerScorer   // Scoring y bans de peers mesh (API de administración)
+	peerTable        *network.PeerTable    // Nodos descubiertos por sus descriptores anunciados en la mesh
+	queryHandler     *network.QueryHandler // Queries a otros nodos para bloques que no están en storage (opcional)
+	watchlist        *watchlist.Registry   // Suscripciones de notificaciones por dirección (opcional)
+	multisig         *consensus.MultisigPool // Transacciones multisig que recolectan firmas (opcional)
+	compliance       *consensus.ComplianceList // Blacklist/allowlist de direcciones (opcional)
+	assets           *assets.Registry          // Metadatos de OXG y de los tokens bridged (/api/v1/assets)
+	idempotency      *idempotencyCache     // Respuestas de POST con Idempotency-Key
+	sharedCache      *rediscache.Cache     // Cache en Redis compartido entre nodos del API (opcional)
+	breaker          *circuitBreaker       // Corta los handlers que dependen del consenso cuando está degradado
+	consensusTimeout time.Duration         // Deadline de los handlers de lectura que consultan el consenso
+	submitTxTimeout  time.Duration         // Deadline de /api/v1/submit-tx
+	server           *http.Server
 }
 
 // NewRestServer crea un nuevo servidor REST
@@ -40,7 +67,7 @@ func NewRestServer(
 	metrics *metrics.Metrics,
 	executor *execution.EVMExecutor,
 ) *RestServer {
-	return &RestServer{
+	s := &RestServer{
 		host:          host,
 		port:          port,
 		storage:       storage,
@@ -48,7 +75,23 @@ func NewRestServer(
 		healthChecker: healthChecker,
 		metrics:       metrics,
 		executor:      executor,
+		assets:        assets.NewRegistry(),
+		idempotency: newIdempotencyCache(
+			getEnvDurationMs("OXY_REST_IDEMPOTENCY_TTL_MS", int(defaultIdempotencyTTL/time.Millisecond)),
+			getEnvInt("OXY_REST_IDEMPOTENCY_MAX_KEYS", defaultIdempotencyMaxKeys),
+		),
+		breaker: newCircuitBreaker(
+			getEnvInt("OXY_REST_BREAKER_FAILURES", defaultBreakerFailures),
+			getEnvDurationMs("OXY_REST_BREAKER_OPEN_MS", int(defaultBreakerOpenDuration/time.Millisecond)),
+		),
+		consensusTimeout: getEnvDurationMs("OXY_REST_CONSENSUS_TIMEOUT_MS", int(defaultConsensusTimeout/time.Millisecond)),
+		submitTxTimeout:  getEnvDurationMs("OXY_REST_SUBMIT_TX_TIMEOUT_MS", int(defaultSubmitTxTimeout/time.Millisecond)),
 	}
+
+	// Estado del breaker visible en /health
+	s.breaker.onChange = s.reportBreakerState
+	s.reportBreakerState(breakerClosed, 0, 0)
+	return s
 }
 
 // Start inicia el servidor REST
@@ -62,17 +105,52 @@ func (s *RestServer) Start() error {
 	mux.HandleFunc("/metrics", s.handleMetrics)
 	mux.HandleFunc("/metrics/prometheus", s.handlePrometheusMetrics)
 	mux.HandleFunc("/api/v1/blocks/", s.handleBlocks)
+	mux.HandleFunc("/api/v1/blocks/batch-get", s.handleBlocksBatchGet)
 	mux.HandleFunc("/api/v1/transactions/", s.handleTransactions)
+	mux.HandleFunc("/api/v1/transactions/batch-get", s.handleTransactionsBatchGet)
+	mux.HandleFunc("/api/v1/accounts", s.handleListAccounts)
 	mux.HandleFunc("/api/v1/accounts/", s.handleAccounts)
-	mux.HandleFunc("/api/v1/submit-tx", s.handleSubmitTx)
-	mux.HandleFunc("/api/v1/validators", s.handleValidators) // Nuevo endpoint
-
-    // Middlewares: CORS, RateLimit, MaxBody
-    handler := s.maxBodyMiddleware(
+	mux.HandleFunc("/api/v1/submit-tx", s.idempotency.wrap(s.consensusHandler(s.submitTxTimeout, s.handleSubmitTx)))
+	mux.HandleFunc("/api/v1/create-access-list", s.handleCreateAccessList)
+	mux.HandleFunc("/api/v1/multisig", s.handleMultisigAddress)
+	mux.HandleFunc("/api/v1/multisig/proposals", s.handleMultisigProposals)
+	mux.HandleFunc("/api/v1/multisig/proposals/", s.handleMultisigProposal)
+	mux.HandleFunc("/api/v1/validators", s.handleValidators)
+	mux.HandleFunc("/api/v1/validators/", s.handleValidator)
+	mux.HandleFunc("/api/v1/rewards/", s.handleRewards)
+	mux.HandleFunc("/api/v1/supply", s.handleSupply)
+	mux.HandleFunc("/api/v1/deployers/", s.handleDeployer)
+	mux.HandleFunc("/api/v1/paused-contracts/", s.handlePausedContract)
+	mux.HandleFunc("/api/v1/vesting/", s.handleVesting)
+	mux.HandleFunc("/api/v1/assets", s.handleAssets)
+	mux.HandleFunc("/api/v1/assets/", s.handleAssets)
+	mux.HandleFunc("/api/v1/search/transactions", s.consensusHandler(s.consensusTimeout, s.handleSearchTransactions))
+	mux.HandleFunc("/api/v1/node", s.consensusHandler(s.consensusTimeout, s.handleNodeInfo))
+	mux.HandleFunc("/api/v1/network/peers", s.handleNetworkPeers)
+	mux.HandleFunc("/api/v1/admin/peers", s.adminOnly(s.handleAdminPeers))
+	mux.HandleFunc("/api/v1/admin/peers/ban", s.adminOnly(s.handleAdminBanPeer))
+	mux.HandleFunc("/api/v1/admin/peers/unban", s.adminOnly(s.handleAdminUnbanPeer))
+	mux.HandleFunc("/api/v1/admin/log-level", s.adminOnly(s.handleAdminLogLevel))
+	mux.HandleFunc("/admin/v1/state/dump", s.adminOnly(s.handleAdminStateDump))
+	mux.HandleFunc("/api/v1/admin/profile/gas", s.adminOnly(s.handleAdminGasProfile))
+	mux.HandleFunc("/api/v1/admin/halt", s.adminOnly(s.handleAdminHalt))
+	mux.HandleFunc("/api/v1/admin/compliance", s.adminOnly(s.handleAdminCompliance))
+	mux.HandleFunc("/api/v1/admin/compliance/", s.adminOnly(s.handleAdminComplianceAddress))
+	mux.HandleFunc("/api/v1/watchlist", s.adminOnly(s.handleWatchlist))
+	mux.HandleFunc("/api/v1/watchlist/", s.adminOnly(s.handleWatchlistSubscription))
+	mux.HandleFunc("/api/v1/dev/mine", s.devOnly(s.consensusHandler(s.submitTxTimeout, s.handleDevMine)))
+	mux.HandleFunc("/api/v1/dev/increase-time", s.devOnly(s.handleDevIncreaseTime))
+	mux.HandleFunc("/api/v1/dev/next-block-timestamp", s.devOnly(s.handleDevNextBlockTimestamp))
+	mux.HandleFunc("/api/v1/dev/snapshot", s.devOnly(s.handleDevSnapshot))
+	mux.HandleFunc("/api/v1/dev/revert", s.devOnly(s.handleDevRevert))
+
+    // Middlewares: Tracing, Metrics, Recovery, CORS, RateLimit, MaxBody
+    // Recovery va dentro de Tracing y Metrics para que el 500 de un pánico quede registrado
+    handler := s.tracingMiddleware(mux, s.metricsMiddleware(mux, s.recoveryMiddleware(s.maxBodyMiddleware(
         s.rateLimitMiddleware(
             s.corsMiddleware(mux),
         ),
-    )
+    ))))
 
 	addr := s.host + ":" + s.port
     // Timeouts configurables por env
@@ -124,6 +202,26 @@ func (s *RestServer) Start() error {
 	return nil
 }
 
+// SetPeerScorer configura el scoring de peers mesh expuesto por el API de administración
+func (s *RestServer) SetPeerScorer(scorer *network.PeerScorer) {
+	s.peerScorer = scorer
+}
+
+// SetPeerTable configura la tabla de nodos descubiertos por la mesh expuesta en /api/v1/network/peers
+func (s *RestServer) SetPeerTable(table *network.PeerTable) {
+	s.peerTable = table
+}
+
+// SetQueryHandler configura las queries por mesh usadas para obtener bloques que no están en el storage local
+func (s *RestServer) SetQueryHandler(queryHandler *network.QueryHandler) {
+	s.queryHandler = queryHandler
+}
+
+// SetWatchlist configura el registro de suscripciones de notificaciones por dirección
+func (s *RestServer) SetWatchlist(registry *watchlist.Registry) {
+	s.watchlist = registry
+}
+
 // Stop detiene el servidor REST
 func (s *RestServer) Stop() error {
 	if s.server != nil {
@@ -143,8 +241,8 @@ func (s *RestServer) corsMiddleware(next http.Handler) http.Handler {
             w.Header().Set("Access-Control-Allow-Origin", origin)
             w.Header().Set("Vary", "Origin")
         }
-        w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+        w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -155,37 +253,99 @@ func (s *RestServer) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// rateLimitMiddleware aplica un rate limit simple por IP (token bucket en memoria)
+// tracingMiddleware abre un span de servidor por petición, continuando la traza de la cabecera traceparent
+// si el cliente la envía. El span se nombra con la ruta registrada en mux (baja cardinalidad) y el trace ID
+// se devuelve en X-Trace-Id para buscar la traza en Jaeger/Tempo
+func (s *RestServer) tracingMiddleware(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !tracing.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		_, route := mux.Handler(r)
+		ctx := tracing.Extract(r.Context(), r.Header)
+		ctx, span := tracing.StartServer(ctx, r.Method+" "+route,
+			tracing.String("http.method", r.Method),
+			tracing.String("http.route", route),
+			tracing.String("http.target", r.URL.Path),
+		)
+		defer span.End()
+		w.Header().Set("X-Trace-Id", span.SpanContext().TraceID.String())
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(tracing.Int64("http.status_code", int64(recorder.status)))
+		if recorder.status >= http.StatusInternalServerError {
+			span.RecordError(fmt.Errorf("HTTP %d", recorder.status))
+		}
+	})
+}
+
+// metricsMiddleware registra la latencia y el status de cada petición por ruta registrada en mux (baja
+// cardinalidad). Las conexiones WebSocket no se registran: su duración es la de la sesión
+func (s *RestServer) metricsMiddleware(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.metrics == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		_, route := mux.Handler(r)
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		if recorder.status != http.StatusSwitchingProtocols {
+			s.metrics.ObserveHTTPRequest(r.Method, route, recorder.status, time.Since(start))
+		}
+	})
+}
+
+// recoveryMiddleware recupera el pánico de un handler: registra el stack trace y responde 500 en vez de
+// tirar el nodo. Si el handler ya empezó a escribir la respuesta no se puede cambiar el status: se aborta
+// la conexión (http.ErrAbortHandler) para que el cliente no tome como completa una respuesta truncada
+func (s *RestServer) recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// Aborto intencional de net/http: se propaga sin registrarlo
+				panic(rec)
+			}
+			apiLog.Errorf("Pánico en %s %s: %v\n%s", r.Method, r.URL.Path, rec, debug.Stack())
+			if s.metrics != nil {
+				s.metrics.IncrementPanics(metrics.PanicComponentHTTP)
+			}
+			if recorder.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			http.Error(recorder, "Internal server error", http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// rateLimitMiddleware aplica el rate limit por IP de cliente y clase de endpoint (lectura/escritura)
+// Ver newRateLimiterFromEnv para la configuración (proxies de confianza, LRU y store compartido en Redis)
 func (s *RestServer) rateLimitMiddleware(next http.Handler) http.Handler {
-    type bucket struct {
-        tokens     float64
-        lastRefill time.Time
-    }
-    var (
-        rps   = getEnvFloat("OXY_REST_RATE_LIMIT_RPS", 50)
-        burst = getEnvFloat("OXY_REST_BURST", 100)
-        store = make(map[string]*bucket)
-    )
-    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        ip := r.RemoteAddr
-        b, ok := store[ip]
-        now := time.Now()
-        if !ok {
-            b = &bucket{tokens: burst, lastRefill: now}
-            store[ip] = b
-        }
-        // Refill tokens
-        elapsed := now.Sub(b.lastRefill).Seconds()
-        b.tokens = minFloat(burst, b.tokens+elapsed*rps)
-        b.lastRefill = now
-        if b.tokens < 1 {
-            w.Header().Set("Retry-After", "1")
-            http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
-            return
-        }
-        b.tokens -= 1
-        next.ServeHTTP(w, r)
-    })
+	limiter := newRateLimiterFromEnv()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.allow(r, time.Now()) {
+			if s.metrics != nil {
+				s.metrics.IncrementRateLimited(endpointClass(r))
+			}
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 // maxBodyMiddleware limita el tamaño del body según env
@@ -197,6 +357,25 @@ func (s *RestServer) maxBodyMiddleware(next http.Handler) http.Handler {
     })
 }
 
+// adminOnly protege los endpoints de administración: exige "Authorization: Bearer <token>"
+// con el token de OXY_ADMIN_TOKEN. Sin token configurado el API de administración queda deshabilitado
+// (detrás de un proxy todas las conexiones parecen venir de localhost, así que no se confía en el origen)
+func (s *RestServer) adminOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := os.Getenv("OXY_ADMIN_TOKEN")
+		if token == "" {
+			http.Error(w, "Admin API disabled: OXY_ADMIN_TOKEN not configured", http.StatusForbidden)
+			return
+		}
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
 func isOriginAllowed(origin string, allowedList string) bool {
     for _, a := range strings.Split(allowedList, ",") {
         if strings.TrimSpace(a) == origin {
@@ -296,6 +475,7 @@ func (s *RestServer) handleReadiness(w http.ResponseWriter, r *http.Request) {
 	}
 
 	isReady := s.healthChecker.IsReady()
+	healthStatus := s.healthChecker.CheckHealth()
 	w.Header().Set("Content-Type", "application/json")
 	
 	if isReady {
@@ -303,12 +483,16 @@ func (s *RestServer) handleReadiness(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"status": "ready",
 			"timestamp": time.Now().Format(time.RFC3339),
+			"catching_up": healthStatus.CatchingUp,
+			"blocks_behind": healthStatus.BlocksBehind,
 		})
 	} else {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"status": "not_ready",
 			"timestamp": time.Now().Format(time.RFC3339),
+			"catching_up": healthStatus.CatchingUp,
+			"blocks_behind": healthStatus.BlocksBehind,
 		})
 	}
 }
@@ -397,10 +581,21 @@ func (s *RestServer) handlePrometheusMetrics(w http.ResponseWriter, r *http.Requ
 	fmt.Fprintf(w, "# TYPE oxy_transactions_rejected_total counter\n")
 	fmt.Fprintf(w, "oxy_transactions_rejected_total %d\n", metricsData.TransactionsRejected)
 
-	fmt.Fprintf(w, "# HELP oxy_transactions_per_second Current transactions per second\n")
+	fmt.Fprintf(w, "# HELP oxy_transactions_per_second Sustained transactions per second over the recent block window\n")
 	fmt.Fprintf(w, "# TYPE oxy_transactions_per_second gauge\n")
 	fmt.Fprintf(w, "oxy_transactions_per_second %.2f\n", metricsData.TransactionsPerSecond)
 
+	if metricsData.BlockIntervalAverage > 0 {
+		fmt.Fprintf(w, "# HELP oxy_block_interval_seconds Interval between committed blocks over the recent block window\n")
+		fmt.Fprintf(w, "# TYPE oxy_block_interval_seconds gauge\n")
+		fmt.Fprintf(w, "oxy_block_interval_seconds{quantile=\"0.5\"} %.6f\n", metricsData.BlockIntervalP50.Seconds())
+		fmt.Fprintf(w, "oxy_block_interval_seconds{quantile=\"0.95\"} %.6f\n", metricsData.BlockIntervalP95.Seconds())
+
+		fmt.Fprintf(w, "# HELP oxy_block_interval_average_seconds Average interval between committed blocks over the recent block window\n")
+		fmt.Fprintf(w, "# TYPE oxy_block_interval_average_seconds gauge\n")
+		fmt.Fprintf(w, "oxy_block_interval_average_seconds %.6f\n", metricsData.BlockIntervalAverage.Seconds())
+	}
+
 	fmt.Fprintf(w, "# HELP oxy_peers_connected Number of connected peers\n")
 	fmt.Fprintf(w, "# TYPE oxy_peers_connected gauge\n")
 	fmt.Fprintf(w, "oxy_peers_connected %d\n", metricsData.PeersConnected)
@@ -437,14 +632,202 @@ func (s *RestServer) handlePrometheusMetrics(w http.ResponseWriter, r *http.Requ
 	fmt.Fprintf(w, "# TYPE oxy_uptime_seconds gauge\n")
 	fmt.Fprintf(w, "oxy_uptime_seconds %.2f\n", uptimeSeconds)
 
+	if metricsData.TotalSupply != "" {
+		fmt.Fprintf(w, "# HELP oxy_supply_total Total OXG supply in wei (minted minus burned)\n")
+		fmt.Fprintf(w, "# TYPE oxy_supply_total gauge\n")
+		fmt.Fprintf(w, "oxy_supply_total %s\n", metricsData.TotalSupply)
+
+		fmt.Fprintf(w, "# HELP oxy_supply_circulating Circulating OXG supply in wei (excludes module accounts)\n")
+		fmt.Fprintf(w, "# TYPE oxy_supply_circulating gauge\n")
+		fmt.Fprintf(w, "oxy_supply_circulating %s\n", metricsData.CirculatingSupply)
+
+		fmt.Fprintf(w, "# HELP oxy_supply_burned_total Total OXG burned in fees in wei\n")
+		fmt.Fprintf(w, "# TYPE oxy_supply_burned_total counter\n")
+		fmt.Fprintf(w, "oxy_supply_burned_total %s\n", metricsData.BurnedSupply)
+	}
+
+	if metricsData.InvariantsAudited {
+		fmt.Fprintf(w, "# HELP oxy_invariant_violations Accounting invariant violations found by the last background audit\n")
+		fmt.Fprintf(w, "# TYPE oxy_invariant_violations gauge\n")
+		fmt.Fprintf(w, "oxy_invariant_violations %d\n", metricsData.InvariantViolations)
+	}
+
 	if !metricsData.LastBlockTime.IsZero() {
 		fmt.Fprintf(w, "# HELP oxy_last_block_time_seconds Timestamp of last block\n")
 		fmt.Fprintf(w, "# TYPE oxy_last_block_time_seconds gauge\n")
 		fmt.Fprintf(w, "oxy_last_block_time_seconds %.0f\n", float64(metricsData.LastBlockTime.Unix()))
 	}
+
+	// Latencia y errores por método ABCI, etiquetados por método y código de resultado
+	if len(metricsData.ABCIMethods) > 0 {
+		fmt.Fprintf(w, "# HELP oxy_abci_method_duration_seconds Duration of ABCI method calls\n")
+		fmt.Fprintf(w, "# TYPE oxy_abci_method_duration_seconds histogram\n")
+		for _, stat := range metricsData.ABCIMethods {
+			labels := fmt.Sprintf("method=%q,code=%q", stat.Method, stat.Code)
+			for i, bound := range metrics.ABCIDurationBuckets {
+				fmt.Fprintf(w, "oxy_abci_method_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, bound, stat.Buckets[i])
+			}
+			fmt.Fprintf(w, "oxy_abci_method_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, stat.Count)
+			fmt.Fprintf(w, "oxy_abci_method_duration_seconds_sum{%s} %.6f\n", labels, stat.SumSeconds)
+			fmt.Fprintf(w, "oxy_abci_method_duration_seconds_count{%s} %d\n", labels, stat.Count)
+		}
+
+		fmt.Fprintf(w, "# HELP oxy_abci_method_errors_total ABCI method calls that failed or returned a non-zero code\n")
+		fmt.Fprintf(w, "# TYPE oxy_abci_method_errors_total counter\n")
+		for _, stat := range metricsData.ABCIMethods {
+			if stat.IsError() {
+				fmt.Fprintf(w, "oxy_abci_method_errors_total{method=%q,code=%q} %d\n", stat.Method, stat.Code, stat.Count)
+			}
+		}
+	}
+
+	// Peticiones REST por ruta y clase de status
+	if len(metricsData.HTTPRequests) > 0 {
+		fmt.Fprintf(w, "# HELP oxy_http_requests_total REST API requests\n")
+		fmt.Fprintf(w, "# TYPE oxy_http_requests_total counter\n")
+		for _, stat := range metricsData.HTTPRequests {
+			fmt.Fprintf(w, "oxy_http_requests_total{%s} %d\n", httpLabels(stat), stat.Count)
+		}
+
+		fmt.Fprintf(w, "# HELP oxy_http_request_errors_total REST API requests answered with a 4xx or 5xx status\n")
+		fmt.Fprintf(w, "# TYPE oxy_http_request_errors_total counter\n")
+		for _, stat := range metricsData.HTTPRequests {
+			if stat.IsError() {
+				fmt.Fprintf(w, "oxy_http_request_errors_total{%s} %d\n", httpLabels(stat), stat.Count)
+			}
+		}
+
+		fmt.Fprintf(w, "# HELP oxy_http_request_duration_seconds Duration of REST API requests\n")
+		fmt.Fprintf(w, "# TYPE oxy_http_request_duration_seconds histogram\n")
+		for _, stat := range metricsData.HTTPRequests {
+			labels := httpLabels(stat)
+			for i, bound := range metrics.HTTPDurationBuckets {
+				fmt.Fprintf(w, "oxy_http_request_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, bound, stat.Buckets[i])
+			}
+			fmt.Fprintf(w, "oxy_http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, stat.Count)
+			fmt.Fprintf(w, "oxy_http_request_duration_seconds_sum{%s} %.6f\n", labels, stat.SumSeconds)
+			fmt.Fprintf(w, "oxy_http_request_duration_seconds_count{%s} %d\n", labels, stat.Count)
+		}
+	}
+
+	// Peticiones rechazadas por el rate limit, por clase de endpoint
+	fmt.Fprintf(w, "# HELP oxy_http_rate_limited_total REST API requests rejected by the rate limit\n")
+	fmt.Fprintf(w, "# TYPE oxy_http_rate_limited_total counter\n")
+	for _, class := range []string{endpointClassRead, endpointClassWrite} {
+		fmt.Fprintf(w, "oxy_http_rate_limited_total{class=%q} %d\n", class, metricsData.RateLimitedRequests[class])
+	}
+
+	// Pánicos recuperados (handlers REST y ejecución de transacciones)
+	fmt.Fprintf(w, "# HELP oxy_panics_recovered_total Panics recovered without crashing the node, by component\n")
+	fmt.Fprintf(w, "# TYPE oxy_panics_recovered_total counter\n")
+	for _, component := range []string{metrics.PanicComponentHTTP, metrics.PanicComponentABCI} {
+		fmt.Fprintf(w, "oxy_panics_recovered_total{component=%q} %d\n", component, metricsData.PanicsRecovered[component])
+	}
+
+	s.writeValidatorMetrics(w)
+	s.writeStateCacheMetrics(w)
+	s.writeSharedCacheMetrics(w)
+}
+
+// httpLabels retorna las etiquetas Prometheus de una serie de peticiones REST
+func httpLabels(stat metrics.HTTPRequestStats) string {
+	return fmt.Sprintf("method=%q,route=%q,status_class=%q", stat.Method, stat.Route, stat.StatusClass)
+}
+
+// writeStateCacheMetrics escribe los aciertos y fallos del cache de estado confirmado, por tipo de entrada
+func (s *RestServer) writeStateCacheMetrics(w io.Writer) {
+	if s.executor == nil {
+		return
+	}
+	stats := s.executor.StateCacheStats()
+	caches := []struct {
+		name         string
+		hits, misses uint64
+		entries      int
+	}{
+		{name: "account", hits: stats.AccountHits, misses: stats.AccountMisses, entries: stats.Accounts},
+		{name: "storage", hits: stats.SlotHits, misses: stats.SlotMisses, entries: stats.Slots},
+	}
+
+	fmt.Fprintf(w, "# HELP oxy_state_cache_hits_total Committed state reads served from the state cache\n")
+	fmt.Fprintf(w, "# TYPE oxy_state_cache_hits_total counter\n")
+	for _, c := range caches {
+		fmt.Fprintf(w, "oxy_state_cache_hits_total{cache=%q} %d\n", c.name, c.hits)
+	}
+	fmt.Fprintf(w, "# HELP oxy_state_cache_misses_total Committed state reads that went to the state database\n")
+	fmt.Fprintf(w, "# TYPE oxy_state_cache_misses_total counter\n")
+	for _, c := range caches {
+		fmt.Fprintf(w, "oxy_state_cache_misses_total{cache=%q} %d\n", c.name, c.misses)
+	}
+	fmt.Fprintf(w, "# HELP oxy_state_cache_hit_ratio Fraction of committed state reads served from the state cache\n")
+	fmt.Fprintf(w, "# TYPE oxy_state_cache_hit_ratio gauge\n")
+	for _, c := range caches {
+		ratio := 0.0
+		if total := c.hits + c.misses; total > 0 {
+			ratio = float64(c.hits) / float64(total)
+		}
+		fmt.Fprintf(w, "oxy_state_cache_hit_ratio{cache=%q} %.6f\n", c.name, ratio)
+	}
+	fmt.Fprintf(w, "# HELP oxy_state_cache_entries Entries currently held in the state cache\n")
+	fmt.Fprintf(w, "# TYPE oxy_state_cache_entries gauge\n")
+	for _, c := range caches {
+		fmt.Fprintf(w, "oxy_state_cache_entries{cache=%q} %d\n", c.name, c.entries)
+	}
+}
+
+// writeValidatorMetrics escribe el uptime y los bloques firmados/perdidos/propuestos de cada validador del set
+func (s *RestServer) writeValidatorMetrics(w io.Writer) {
+	if s.consensus == nil || s.consensus.GetValidatorSet() == nil {
+		return
+	}
+	validatorSet := s.consensus.GetValidatorSet()
+	validators, _ := validatorSet.ListValidators(consensus.ValidatorStatusAll)
+	if len(validators) == 0 {
+		return
+	}
+	uptimes := make([]consensus.ValidatorUptime, len(validators))
+	for i, v := range validators {
+		uptimes[i] = validatorSet.GetUptime(v.Address)
+	}
+
+	fmt.Fprintf(w, "# HELP oxy_validator_uptime_ratio Fraction of blocks signed by the validator within the uptime window\n")
+	fmt.Fprintf(w, "# TYPE oxy_validator_uptime_ratio gauge\n")
+	for i, v := range validators {
+		fmt.Fprintf(w, "oxy_validator_uptime_ratio{validator=%q} %.6f\n", v.Address, uptimes[i].SignedRatio())
+	}
+	fmt.Fprintf(w, "# HELP oxy_validator_window_missed_blocks Blocks missed by the validator within the uptime window\n")
+	fmt.Fprintf(w, "# TYPE oxy_validator_window_missed_blocks gauge\n")
+	for i, v := range validators {
+		fmt.Fprintf(w, "oxy_validator_window_missed_blocks{validator=%q} %d\n", v.Address, uptimes[i].Missed)
+	}
+	fmt.Fprintf(w, "# HELP oxy_validator_blocks_signed_total Blocks signed by the validator\n")
+	fmt.Fprintf(w, "# TYPE oxy_validator_blocks_signed_total counter\n")
+	for i, v := range validators {
+		fmt.Fprintf(w, "oxy_validator_blocks_signed_total{validator=%q} %d\n", v.Address, uptimes[i].SignedTotal)
+	}
+	fmt.Fprintf(w, "# HELP oxy_validator_blocks_missed_total Blocks missed by the validator\n")
+	fmt.Fprintf(w, "# TYPE oxy_validator_blocks_missed_total counter\n")
+	for i, v := range validators {
+		fmt.Fprintf(w, "oxy_validator_blocks_missed_total{validator=%q} %d\n", v.Address, uptimes[i].MissedTotal)
+	}
+	fmt.Fprintf(w, "# HELP oxy_validator_blocks_proposed_total Blocks proposed by the validator\n")
+	fmt.Fprintf(w, "# TYPE oxy_validator_blocks_proposed_total counter\n")
+	for _, v := range validators {
+		fmt.Fprintf(w, "oxy_validator_blocks_proposed_total{validator=%q} %d\n", v.Address, validatorSet.BlocksProposed(v.Address))
+	}
+	fmt.Fprintf(w, "# HELP oxy_validator_jailed Whether the validator is jailed (1) or not (0)\n")
+	fmt.Fprintf(w, "# TYPE oxy_validator_jailed gauge\n")
+	for _, v := range validators {
+		jailed := 0
+		if v.Jailed {
+			jailed = 1
+		}
+		fmt.Fprintf(w, "oxy_validator_jailed{validator=%q} %d\n", v.Address, jailed)
+	}
 }
 
-// handleBlocks maneja /api/v1/blocks/{height} o /api/v1/blocks/latest
+// handleBlocks maneja /api/v1/blocks/{height}, /api/v1/blocks/latest o /api/v1/blocks/hash/{hash}
+// ?full=false retorna solo los hashes de las transacciones (como eth_getBlockByNumber sin fullTx)
 func (s *RestServer) handleBlocks(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -453,41 +836,327 @@ func (s *RestServer) handleBlocks(w http.ResponseWriter, r *http.Request) {
 
 	// Extraer height del path
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/blocks/")
-	
-	var block *consensus.Block
+
+	// /api/v1/blocks/{height}/results
+	if strings.HasSuffix(path, "/results") {
+		heightStr := strings.TrimSuffix(path, "/results")
+		s.consensusHandler(s.consensusTimeout, func(w http.ResponseWriter, r *http.Request) {
+			s.handleBlockResults(w, r, heightStr)
+		})(w, r)
+		return
+	}
+
+	// /api/v1/blocks/{height}/commit
+	if heightStr, ok := strings.CutSuffix(path, "/commit"); ok {
+		s.consensusHandler(s.consensusTimeout, func(w http.ResponseWriter, r *http.Request) {
+			s.handleBlockCommit(w, r, heightStr)
+		})(w, r)
+		return
+	}
+
+	// /api/v1/blocks/{ref}/transactions/{index}
+	if ref, index, ok := strings.Cut(path, "/transactions/"); ok {
+		s.handleBlockTransaction(w, r, ref, index)
+		return
+	}
+
+	full := true
+	if value := r.URL.Query().Get("full"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid full parameter", http.StatusBadRequest)
+			return
+		}
+		full = parsed
+	}
+
+	// El último bloque se comparte entre los nodos del API por Redis (hasta el próximo commit)
+	latest := path == "latest" || path == ""
+	variant, contentType := "full", "application/json"
+	if acceptsProto(r) {
+		variant, contentType = "proto", wire.ContentType
+	} else if !full {
+		variant = "hashes"
+	}
+	cacheHeight := s.sharedCache.Height()
+	if latest {
+		if body, ok := s.sharedCache.Get(r.Context(), rediscache.KindLatestBlock, variant); ok {
+			writeCacheable(w, r, body, contentType, false)
+			return
+		}
+	}
+
+	block, finalized, ok := s.loadBlock(w, path)
+	if !ok {
+		return
+	}
+
+	response := blockResponse{Block: block, Finalized: finalized, hashesOnly: !full}
+	if finalized {
+		response.Commit = s.commitSummary(r.Context(), block.Header.Height)
+	}
+	if s.consensus != nil && s.consensus.GetValidatorSet() != nil {
+		response.Proposer = s.consensus.GetValidatorSet().ProposerAddress(block.Header.Validator)
+	}
+	// Un bloque pedido por altura o hash no cambia una vez que su commit es el definitivo; "latest" sí
+	immutable := path != "latest" && path != "" && response.Commit != nil && response.Commit.Canonical
+
+	// Accept: application/x-protobuf retorna el wire.Block de types.proto (sin finalized ni commit)
+	var body []byte
+	var err error
+	if variant == "proto" {
+		body = block.ToWire().Marshal()
+	} else if body, err = json.Marshal(response); err != nil {
+		http.Error(w, "Error encoding block", http.StatusInternalServerError)
+		return
+	}
+	if latest && finalized && block.Header.Height == cacheHeight {
+		s.sharedCache.Set(r.Context(), cacheHeight, rediscache.KindLatestBlock, variant, body)
+	}
+	writeCacheable(w, r, body, contentType, immutable)
+}
+
+// loadBlock obtiene el bloque de ref ({height}, latest o hash/{hash}) y si ya fue confirmado en este nodo
+// Si falla responde el error y retorna ok en false
+func (s *RestServer) loadBlock(w http.ResponseWriter, ref string) (block *consensus.Block, finalized bool, ok bool) {
 	var err error
+	finalized = true // Los bloques del storage local se guardan en Commit, ya confirmados por CometBFT
 
-	if path == "latest" || path == "" {
+	if hash, isHash := strings.CutPrefix(ref, "hash/"); isHash {
+		if len(common.FromHex(hash)) != common.HashLength {
+			http.Error(w, "Invalid block hash", http.StatusBadRequest)
+			return nil, false, false
+		}
+		blockData, dbErr := s.storage.GetBlockByHash(hash)
+		if dbErr != nil {
+			http.Error(w, "Block not found", http.StatusNotFound)
+			return nil, false, false
+		}
+		if block, err = consensus.DecodeBlock(blockData); err != nil {
+			http.Error(w, "Error decoding block", http.StatusInternalServerError)
+			return nil, false, false
+		}
+	} else if ref == "latest" || ref == "" {
 		// Obtener último bloque
 		block, err = s.consensus.GetLatestBlock()
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+			return nil, false, false
 		}
+		// Sin bloques guardados GetLatestBlock retorna un bloque vacío
+		finalized = block.Header.Hash != ""
 	} else {
 		// Parsear altura
-		height, parseErr := strconv.ParseUint(path, 10, 64)
+		height, parseErr := strconv.ParseUint(ref, 10, 64)
 		if parseErr != nil {
 			http.Error(w, "Invalid block height", http.StatusBadRequest)
-			return
+			return nil, false, false
 		}
 
 		// Obtener bloque por altura
 		blockData, dbErr := s.storage.GetBlock(height)
 		if dbErr != nil {
-			http.Error(w, "Block not found", http.StatusNotFound)
+			// El nodo todavía no tiene el bloque: pedirlo a la mesh si su hash fue anunciado por un validador
+			block, err = s.queryBlockFromMesh(height)
+			if err != nil {
+				http.Error(w, "Block not found", http.StatusNotFound)
+				return nil, false, false
+			}
+			// Confirmado por otro nodo, todavía no por este
+			finalized = false
+		} else if block, err = consensus.DecodeBlock(blockData); err != nil {
+			http.Error(w, "Error decoding block", http.StatusInternalServerError)
+			return nil, false, false
+		}
+	}
+	return block, finalized, true
+}
+
+// handleBlockTransaction maneja /api/v1/blocks/{ref}/transactions/{index}
+// Retorna la transacción en la posición index del bloque con blockHash, blockNumber y transactionIndex
+// (como eth_getTransactionByBlockNumberAndIndex)
+func (s *RestServer) handleBlockTransaction(w http.ResponseWriter, r *http.Request, ref, indexStr string) {
+	index, err := strconv.ParseUint(indexStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid transaction index", http.StatusBadRequest)
+		return
+	}
+	block, finalized, ok := s.loadBlock(w, ref)
+	if !ok {
+		return
+	}
+	if index >= uint64(len(block.Transactions)) {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	body, err := json.Marshal(blockTransactionResponse{
+		Transaction:      block.Transactions[index],
+		BlockHash:        block.Header.Hash,
+		BlockNumber:      block.Header.Height,
+		TransactionIndex: index,
+	})
+	if err != nil {
+		http.Error(w, "Error encoding transaction", http.StatusInternalServerError)
+		return
+	}
+	// Igual que un bloque: por altura o hash no cambia una vez confirmado en este nodo; "latest" sí
+	writeCacheableJSON(w, r, body, finalized && ref != "latest" && ref != "")
+}
+
+// blockTransactionResponse es una transacción con su posición en el bloque
+type blockTransactionResponse struct {
+	*consensus.Transaction
+	BlockHash        string `json:"blockHash"`
+	BlockNumber      uint64 `json:"blockNumber"`
+	TransactionIndex uint64 `json:"transactionIndex"`
+}
+
+// blockResponse es un bloque con su estado de finalidad, para la lógica de confirmación de depósitos
+type blockResponse struct {
+	*consensus.Block
+	Finalized bool                     `json:"finalized"`          // El bloque fue confirmado por CometBFT en este nodo (no se revierte)
+	Commit    *consensus.CommitSummary `json:"commit,omitempty"`   // Firmas del commit (sin RPC de CometBFT no se incluye)
+	Proposer  string                   `json:"proposer,omitempty"` // Dirección del validador que propuso el bloque (Header.Validator)
+
+	hashesOnly bool // Transactions con solo los hashes (?full=false)
+}
+
+// MarshalJSON agrega finalized, commit y proposer al JSON del bloque
+// Sin este método se promovería Block.MarshalJSON y la respuesta perdería esos campos
+func (r blockResponse) MarshalJSON() ([]byte, error) {
+	blockJSON, err := json.Marshal(r.Block)
+	if err != nil {
+		return nil, err
+	}
+	if r.hashesOnly && r.Block != nil {
+		if blockJSON, err = withTransactionHashes(blockJSON, r.Block.Transactions); err != nil {
+			return nil, err
+		}
+	}
+	finality, err := json.Marshal(struct {
+		Finalized bool                     `json:"finalized"`
+		Commit    *consensus.CommitSummary `json:"commit,omitempty"`
+		Proposer  string                   `json:"proposer,omitempty"`
+	}{r.Finalized, r.Commit, r.Proposer})
+	if err != nil {
+		return nil, err
+	}
+	if len(blockJSON) <= 2 || blockJSON[0] != '{' {
+		return finality, nil
+	}
+	// Unir los dos objetos: {...bloque..., "finalized": ..., "commit": ..., "proposer": ...}
+	merged := append(blockJSON[:len(blockJSON)-1:len(blockJSON)-1], ',')
+	return append(merged, finality[1:]...), nil
+}
+
+// withTransactionHashes reemplaza las transacciones del JSON de un bloque por sus hashes
+func withTransactionHashes(blockJSON []byte, transactions []*consensus.Transaction) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(blockJSON, &fields); err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(transactions))
+	for i, tx := range transactions {
+		hashes[i] = tx.Hash
+	}
+	encoded, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, err
+	}
+	fields["Transactions"] = encoded
+	return json.Marshal(fields)
+}
+
+// commitSummary obtiene las firmas del commit de un bloque (best-effort, con el deadline de las consultas al
+// consenso): un nodo lento o sin RPC no impide servir el bloque desde storage
+func (s *RestServer) commitSummary(ctx context.Context, height uint64) *consensus.CommitSummary {
+	if s.consensus == nil || height == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.consensusTimeout)
+	defer cancel()
+	summary, err := s.consensus.GetCommitSummary(ctx, height)
+	if err != nil {
+		apiLog.Debugf("Commit del bloque %d no disponible: %v", height, err)
+		return nil
+	}
+	return summary
+}
+
+// queryBlockFromMesh obtiene de otros nodos un bloque que no está en el storage local
+func (s *RestServer) queryBlockFromMesh(height uint64) (*consensus.Block, error) {
+	if s.queryHandler == nil {
+		return nil, fmt.Errorf("queries por mesh no configuradas")
+	}
+	return s.queryHandler.QueryBlock(height, network.DefaultQueryTimeout)
+}
+
+// handleBlockResults maneja /api/v1/blocks/{height}/results
+// Retorna resultados por transacción, eventos, validator updates y consensus param updates del bloque
+func (s *RestServer) handleBlockResults(w http.ResponseWriter, r *http.Request, heightStr string) {
+	if s.consensus == nil {
+		http.Error(w, "Consensus not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var height uint64
+	if heightStr != "latest" {
+		parsed, err := strconv.ParseUint(heightStr, 10, 64)
+		if err != nil || parsed == 0 {
+			http.Error(w, "Invalid block height", http.StatusBadRequest)
 			return
 		}
+		height = parsed
+	}
 
-		if err := json.Unmarshal(blockData, &block); err != nil {
-			http.Error(w, "Error decoding block", http.StatusInternalServerError)
+	results, err := s.consensus.GetBlockResults(r.Context(), height)
+	if err != nil {
+		// Solo una altura futura o podada es "no encontrada"; el resto es una falla del nodo
+		if errors.Is(err, consensus.ErrHeightNotAvailable) {
+			http.Error(w, fmt.Sprintf("Block results not available: %v", err), http.StatusNotFound)
 			return
 		}
+		http.Error(w, fmt.Sprintf("Error getting block results: %v", err), consensusErrorStatus(err, http.StatusServiceUnavailable))
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(block)
+	w.Write(results)
+}
+
+// handleBlockCommit maneja /api/v1/blocks/{height}/commit
+// Retorna las firmas del commit que confirmó el bloque: quién firmó, votó nil o no firmó, y cuándo
+func (s *RestServer) handleBlockCommit(w http.ResponseWriter, r *http.Request, heightStr string) {
+	if s.consensus == nil {
+		http.Error(w, "Consensus not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	height, err := strconv.ParseUint(heightStr, 10, 64)
+	if err != nil || height == 0 {
+		http.Error(w, "Invalid block height", http.StatusBadRequest)
+		return
+	}
+
+	commit, err := s.consensus.GetBlockCommit(r.Context(), height)
+	if err != nil {
+		if errors.Is(err, consensus.ErrHeightNotAvailable) {
+			http.Error(w, fmt.Sprintf("Block commit not available: %v", err), http.StatusNotFound)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Error getting block commit: %v", err), consensusErrorStatus(err, http.StatusServiceUnavailable))
+		return
+	}
+
+	body, err := encodeJSON(commit)
+	if err != nil {
+		http.Error(w, "Error encoding block commit", http.StatusInternalServerError)
+		return
+	}
+	// El commit definitivo de una altura no cambia
+	writeCacheableJSON(w, r, body, commit.Canonical)
 }
 
 // handleTransactions maneja /api/v1/transactions/{hash}
@@ -507,29 +1176,49 @@ func (s *RestServer) handleTransactions(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(txData)
+	// Solo se guardan las transacciones de bloques ya decididos por CometBFT
+	if acceptsProto(r) {
+		tx, err := consensus.DecodeTransaction(txData)
+		if err != nil {
+			http.Error(w, "Error decoding transaction", http.StatusInternalServerError)
+			return
+		}
+		writeCacheable(w, r, tx.ToWire().Marshal(), wire.ContentType, true)
+		return
+	}
+	if txData, err = consensus.TransactionJSON(txData); err != nil {
+		http.Error(w, "Error decoding transaction", http.StatusInternalServerError)
+		return
+	}
+	writeCacheableJSON(w, r, txData, true)
 }
 
-// handleAccounts maneja /api/v1/accounts/{address} y /api/v1/accounts/{address}/fund
+// handleAccounts maneja /api/v1/accounts/{address}, /api/v1/accounts/{address}/fund, /code y /storage/{slot}
 func (s *RestServer) handleAccounts(w http.ResponseWriter, r *http.Request) {
 	// Extraer dirección del path
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/accounts/")
 	
 	// Log para debug
-	log.Printf("🔍 handleAccounts: path=%s, method=%s", path, r.Method)
+	apiLog.Debugf("🔍 handleAccounts: path=%s, method=%s", path, r.Method)
 	
 	// Verificar si es el endpoint de fondear
 	// El path puede ser "0x.../fund" o solo "/fund" si la dirección viene en el path completo
 	if strings.HasSuffix(path, "/fund") {
 		// Remover "/fund" del path
 		address := strings.TrimSuffix(path, "/fund")
-		log.Printf("💰 handleFundAccount: address=%s", address)
-		s.handleFundAccount(w, r, address)
+		apiLog.Debugf("💰 handleFundAccount: address=%s", address)
+		s.idempotency.wrap(func(w http.ResponseWriter, r *http.Request) {
+			s.handleFundAccount(w, r, address)
+		})(w, r)
 		return
 	}
-	
+
+	// Endpoints GET /api/v1/accounts/{address}/code y /api/v1/accounts/{address}/storage/{slot}
+	if address, resource, ok := strings.Cut(path, "/"); ok {
+		s.handleAccountState(w, r, address, resource)
+		return
+	}
+
 	// Endpoint GET /api/v1/accounts/{address}
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -550,23 +1239,53 @@ func (s *RestServer) handleAccounts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accountState, err := s.executor.GetState(address)
+	// Estado del último bloque confirmado (a través del cache de estado), o el de ?height=N
+	var accountState *execution.AccountState
+	var err error
+	var cacheID string
+	var cacheHeight uint64
+	if value := r.URL.Query().Get("height"); value != "" {
+		height, parseErr := strconv.ParseUint(value, 10, 64)
+		if parseErr != nil || height == 0 {
+			http.Error(w, "Invalid height", http.StatusBadRequest)
+			return
+		}
+		accountState, err = s.executor.GetStateAtHeight(address, height)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("State at height %d not available: %v", height, err), http.StatusNotFound)
+			return
+		}
+	} else {
+		// El estado confirmado solo cambia en un commit: se comparte entre los nodos del API por Redis
+		cacheID, cacheHeight = strings.ToLower(address), s.sharedCache.Height()
+		if body, ok := s.sharedCache.Get(r.Context(), rediscache.KindAccount, cacheID); ok {
+			writeJSONBody(w, body)
+			return
+		}
+		accountState, err = s.executor.GetCommittedState(address)
+	}
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error getting account state: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(accountState)
-}
-
-// handleFundAccount maneja POST /api/v1/accounts/{address}/fund
+	body, err := encodeJSON(accountState)
+	if err != nil {
+		http.Error(w, "Error encoding account state", http.StatusInternalServerError)
+		return
+	}
+	if cacheID != "" {
+		s.sharedCache.Set(r.Context(), cacheHeight, rediscache.KindAccount, cacheID, body)
+	}
+	writeJSONBody(w, body)
+}
+
+// handleFundAccount maneja POST /api/v1/accounts/{address}/fund
 func (s *RestServer) handleFundAccount(w http.ResponseWriter, r *http.Request, address string) {
-	log.Printf("💰 handleFundAccount llamado: address=%s, method=%s", address, r.Method)
+	apiLog.Debugf("💰 handleFundAccount llamado: address=%s, method=%s", address, r.Method)
 	
 	if r.Method != http.MethodPost {
-		log.Printf("❌ handleFundAccount: método incorrecto: %s (esperado POST)", r.Method)
+		apiLog.Debugf("❌ handleFundAccount: método incorrecto: %s (esperado POST)", r.Method)
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -577,17 +1296,17 @@ func (s *RestServer) handleFundAccount(w http.ResponseWriter, r *http.Request, a
 		path := strings.TrimPrefix(r.URL.Path, "/api/v1/accounts/")
 		path = strings.TrimSuffix(path, "/fund")
 		address = path
-		log.Printf("🔍 handleFundAccount: dirección extraída del path: %s", address)
+		apiLog.Debugf("🔍 handleFundAccount: dirección extraída del path: %s", address)
 	}
 	
 	// Validar dirección
 	if !common.IsHexAddress(address) {
-		log.Printf("❌ handleFundAccount: dirección inválida: %s", address)
+		apiLog.Debugf("❌ handleFundAccount: dirección inválida: %s", address)
 		http.Error(w, "Invalid Ethereum address", http.StatusBadRequest)
 		return
 	}
 	
-	log.Printf("✅ handleFundAccount: dirección válida: %s", address)
+	apiLog.Debugf("✅ handleFundAccount: dirección válida: %s", address)
 
 	// Parsear body
 	var req struct {
@@ -616,7 +1335,7 @@ func (s *RestServer) handleFundAccount(w http.ResponseWriter, r *http.Request, a
 		return
 	}
 
-	// Obtener estado actualizado de la cuenta
+	// Obtener estado actualizado de la cuenta (del StateDB en uso: el fondeo todavía no está confirmado)
 	accountState, err := s.executor.GetState(address)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error getting account state: %v", err), http.StatusInternalServerError)
@@ -639,27 +1358,29 @@ func (s *RestServer) handleSubmitTx(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Decodificar transacción del body
-	var tx consensus.Transaction
-	if err := json.NewDecoder(r.Body).Decode(&tx); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid transaction format: %v", err), http.StatusBadRequest)
+	// Modo de envío: async (por defecto), sync (espera CheckTx) o commit (espera la inclusión en un bloque)
+	mode, err := consensus.ParseBroadcastMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Validar transacción básica
-	if tx.Hash == "" {
-		http.Error(w, "Transaction hash required", http.StatusBadRequest)
+	// Decodificar y validar la transacción contra el esquema (campos requeridos, formatos, tamaño de data)
+	tx, err := decodeSubmitTx(r.Body, getEnvInt("OXY_REST_MAX_TX_DATA_BYTES", defaultMaxTxDataBytes))
+	if err != nil {
+		writeTxError(w, err)
 		return
 	}
 
-	if tx.From == "" {
-		http.Error(w, "Transaction from address required", http.StatusBadRequest)
+	if s.consensus == nil {
+		writeTxError(w, consensus.NewTxError(consensus.CodeUnavailable, "Consensus not available"))
 		return
 	}
 
 	// Enviar transacción al consensus
-	if err := s.consensus.SubmitTransaction(&tx); err != nil {
-		http.Error(w, fmt.Sprintf("Error submitting transaction: %v", err), http.StatusBadRequest)
+	result, err := s.consensus.BroadcastTransaction(r.Context(), tx, mode)
+	if err != nil {
+		writeTxError(w, err)
 		return
 	}
 
@@ -667,16 +1388,59 @@ func (s *RestServer) handleSubmitTx(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
 		"success": true,
 		"hash":    tx.Hash,
+		"mode":    result.Mode,
 		"message": "Transaction submitted successfully",
 	}
+	if result.CheckTx != nil {
+		response["checkTx"] = result.CheckTx
+	}
+	if result.TxResult != nil {
+		// Incluida en un bloque: success refleja el resultado de la ejecución
+		response["success"] = result.TxResult.Code == consensus.CodeOK
+		response["height"] = result.TxResult.Height
+		response["txResult"] = result.TxResult
+		response["message"] = "Transaction committed"
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
-// handleValidators maneja /api/v1/validators
-func (s *RestServer) handleValidators(w http.ResponseWriter, r *http.Request) {
+// handleCreateAccessList maneja POST /api/v1/create-access-list
+// Simula una transacción sin firmar sobre el último estado confirmado y retorna la access list (EIP-2930)
+// que cubre las direcciones y slots que toca, con el gas usado al incluirla
+func (s *RestServer) handleCreateAccessList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tx, err := decodeCallTx(r.Body, getEnvInt("OXY_REST_MAX_TX_DATA_BYTES", defaultMaxTxDataBytes))
+	if err != nil {
+		writeTxError(w, err)
+		return
+	}
+
+	if s.executor == nil {
+		http.Error(w, "Executor not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	result, err := s.executor.CreateAccessList(tx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating access list: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleNodeInfo maneja /api/v1/node
+// Retorna chain ID, versiones, altura/app hash, validador, estado de sync y modo del nodo
+func (s *RestServer) handleNodeInfo(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -687,36 +1451,460 @@ func (s *RestServer) handleValidators(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Obtener validadores activos
-	validators := s.consensus.GetValidators()
-	
-	// Convertir a formato JSON para la API
-	type ValidatorInfo struct {
-		Address      string   `json:"address"`
-		PubKey       string   `json:"pubKey"`
-		Stake        string   `json:"stake"`
-		Power        int64    `json:"power"`
-		Jailed       bool     `json:"jailed"`
-		CreatedAt    string   `json:"createdAt"`
-		LastActiveAt string   `json:"lastActiveAt"`
-	}
-
-	validatorInfos := make([]ValidatorInfo, 0, len(validators))
-	for _, v := range validators {
-		validatorInfos = append(validatorInfos, ValidatorInfo{
-			Address:      v.Address,
-			PubKey:       fmt.Sprintf("0x%x", v.PubKey),
-			Stake:        v.Stake.String(),
-			Power:        v.Power,
-			Jailed:       v.Jailed,
-			CreatedAt:    v.CreatedAt.Format(time.RFC3339),
-			LastActiveAt: v.LastActiveAt.Format(time.RFC3339),
-		})
+	nodeInfo, err := s.consensus.GetNodeInfo(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Node info not available: %v", err), consensusErrorStatus(err, http.StatusServiceUnavailable))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(nodeInfo)
+}
+
+// handleSearchTransactions maneja /api/v1/search/transactions?query=...&page=&per_page=&order_by=
+// Consulta tx_search de CometBFT en proceso: permite buscar por atributos de eventos (ej: transfer.sender='0x...')
+func (s *RestServer) handleSearchTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query().Get("query")
+	if query == "" {
+		http.Error(w, "Query parameter required", http.StatusBadRequest)
+		return
+	}
+
+	// Errores de sintaxis del query son del cliente, no del nodo
+	if err := consensus.ValidateSearchQuery(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if s.consensus == nil {
+		http.Error(w, "Consensus not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	orderBy := r.URL.Query().Get("order_by")
+
+	result, err := s.consensus.SearchTransactions(r.Context(), query, page, perPage, orderBy)
+	if err != nil {
+		if errors.Is(err, consensus.ErrTxIndexerDisabled) {
+			http.Error(w, "Transaction indexer disabled", http.StatusServiceUnavailable)
+			return
+		}
+		if errors.Is(err, consensus.ErrInvalidSearchQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Error searching transactions: %v", err), consensusErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(result)
+}
+
+// writeTxError escribe un error de transacción en JSON usando el registro de códigos del consenso
+// Los clientes pueden distinguir la causa por "code"/"reason" sin parsear el mensaje
+func writeTxError(w http.ResponseWriter, err error) {
+	code := consensus.ErrorCode(err, consensus.CodeInvalidTx)
+
+	status := http.StatusBadRequest
+	switch code {
+	case consensus.CodeRateLimited, consensus.CodeMempoolFull:
+		status = http.StatusTooManyRequests
+	case consensus.CodeDuplicateTx:
+		status = http.StatusConflict
+	case consensus.CodeUnavailable:
+		status = http.StatusServiceUnavailable
+	case consensus.CodeTimeout:
+		status = http.StatusGatewayTimeout
+	}
+
+	body := map[string]interface{}{
+		"codespace": consensus.Codespace,
+		"code":      code,
+		"reason":    consensus.CodeReason(code),
+		"message":   err.Error(),
+	}
+	// Errores de esquema: detalle por campo
+	var schemaErr *TxSchemaError
+	if errors.As(err, &schemaErr) {
+		body["fields"] = schemaErr.Fields
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   body,
+	})
+}
+
+// Límites de /api/v1/validators y del historial de un validador
+const (
+	defaultValidatorsLimit = 100
+	maxValidatorsLimit     = 1000
+)
+
+// validatorInfo es la representación JSON de un validador
+type validatorInfo struct {
+	Address      string                     `json:"address"`
+	PubKey       string                     `json:"pubKey"`
+	Stake        string                     `json:"stake"`
+	Power        int64                      `json:"power"`
+	Jailed       bool                       `json:"jailed"`
+	JailedUntil  string                     `json:"jailedUntil,omitempty"`
+	JailReason   string                     `json:"jailReason,omitempty"` // misbehavior, downtime o double-sign
+	Tombstoned   bool                       `json:"tombstoned,omitempty"` // Expulsado por doble firma (permanente)
+	TotalMissed  int                        `json:"totalMissed"`
+	CreatedAt    string                     `json:"createdAt"`
+	LastActiveAt string                     `json:"lastActiveAt"`
+	Uptime       *consensus.ValidatorUptime `json:"uptime,omitempty"`
+
+	BlocksProposed uint64 `json:"blocksProposed"` // Bloques propuestos desde que se registra el validador
+
+	Commission            *consensus.Commission `json:"commission,omitempty"`
+	AccumulatedCommission string                `json:"accumulatedCommission,omitempty"` // Comisión pendiente de retiro
+}
+
+// newValidatorInfo convierte un validador al formato de la API
+func newValidatorInfo(v *consensus.Validator) validatorInfo {
+	info := validatorInfo{
+		Address:      v.Address,
+		PubKey:       fmt.Sprintf("0x%x", v.PubKey),
+		Stake:        v.Stake.String(),
+		Power:        v.Power,
+		Jailed:       v.Jailed,
+		Tombstoned:   v.Tombstoned,
+		TotalMissed:  v.TotalMissed,
+		CreatedAt:    v.CreatedAt.Format(time.RFC3339),
+		LastActiveAt: v.LastActiveAt.Format(time.RFC3339),
+	}
+	if v.Jailed && !v.JailedUntil.IsZero() {
+		info.JailedUntil = v.JailedUntil.Format(time.RFC3339)
+	}
+	if v.Jailed {
+		info.JailReason = v.JailReason
+	}
+	info.Commission = v.Commission
+	if v.AccumulatedCommission != nil {
+		info.AccumulatedCommission = v.AccumulatedCommission.String()
+	}
+	return info
+}
+
+// parseLimitOffset lee limit (1..maxLimit) y offset (>= 0) de la query
+func parseLimitOffset(r *http.Request, defaultLimit, maxLimit int) (int, int, error) {
+	query := r.URL.Query()
+	limit := defaultLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxLimit {
+			return 0, 0, fmt.Errorf("Invalid limit (1-%d)", maxLimit)
+		}
+		limit = parsed
+	}
+	offset := 0
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, fmt.Errorf("Invalid offset")
+		}
+		offset = parsed
+	}
+	return limit, offset, nil
+}
+
+// handleValidators maneja GET /api/v1/validators?status=active|jailed|all&limit=100&offset=0
+// Lista los validadores ordenados por stake (mayor primero); por defecto solo los activos
+// Con ?height=N retorna el set de CometBFT (direcciones, claves públicas y poder) que firmó esa altura
+func (s *RestServer) handleValidators(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.consensus == nil || s.consensus.GetValidatorSet() == nil {
+		http.Error(w, "Consensus not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if value := r.URL.Query().Get("height"); value != "" {
+		height, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || height == 0 {
+			http.Error(w, "Invalid height", http.StatusBadRequest)
+			return
+		}
+		s.writeValidatorSetAt(w, height)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = consensus.ValidatorStatusActive
+	}
+	limit, offset, err := parseLimitOffset(r, defaultValidatorsLimit, maxValidatorsLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// El set de validadores solo cambia en un commit: se comparte entre los nodos del API por Redis
+	cacheID := fmt.Sprintf("%s:%d:%d", status, limit, offset)
+	cacheHeight := s.sharedCache.Height()
+	if body, ok := s.sharedCache.Get(r.Context(), rediscache.KindValidators, cacheID); ok {
+		writeJSONBody(w, body)
+		return
+	}
+
+	validatorSet := s.consensus.GetValidatorSet()
+	validators, err := validatorSet.ListValidators(status)
+	if err != nil {
+		http.Error(w, "Invalid status (active, jailed or all)", http.StatusBadRequest)
+		return
+	}
+
+	total := len(validators)
+	page := validators[min(offset, total):min(offset+limit, total)]
+	validatorInfos := make([]validatorInfo, 0, len(page))
+	for _, v := range page {
+		info := newValidatorInfo(v)
+		uptime := validatorSet.GetUptime(v.Address)
+		info.Uptime = &uptime
+		info.BlocksProposed = validatorSet.BlocksProposed(v.Address)
+		validatorInfos = append(validatorInfos, info)
 	}
 
 	response := map[string]interface{}{
 		"validators": validatorInfos,
 		"count":      len(validatorInfos),
+		"total":      total,
+		"status":     status,
+		"offset":     offset,
+		"limit":      limit,
+	}
+
+	body, err := encodeJSON(response)
+	if err != nil {
+		http.Error(w, "Error encoding validators", http.StatusInternalServerError)
+		return
+	}
+	s.sharedCache.Set(r.Context(), cacheHeight, rediscache.KindValidators, cacheID, body)
+	writeJSONBody(w, body)
+}
+
+// writeValidatorSetAt responde el set de validadores que rige en height
+func (s *RestServer) writeValidatorSetAt(w http.ResponseWriter, height uint64) {
+	snapshot, err := s.consensus.GetValidatorSet().GetValidatorSetAt(height)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error reading validator set: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if snapshot == nil {
+		http.Error(w, fmt.Sprintf("Validator set at height %d not available", height), http.StatusNotFound)
+		return
+	}
+
+	body, err := encodeJSON(map[string]interface{}{
+		"height":      height,
+		"sinceHeight": snapshot.Height,
+		"totalPower":  snapshot.TotalPower,
+		"validators":  snapshot.Validators,
+		"count":       len(snapshot.Validators),
+	})
+	if err != nil {
+		http.Error(w, "Error encoding validators", http.StatusInternalServerError)
+		return
+	}
+	writeJSONBody(w, body)
+}
+
+// handleValidator maneja GET /api/v1/validators/{address} y GET /api/v1/validators/{address}/history
+// El historial (cambios de stake, slashes y unjails, del más reciente al más antiguo) sigue disponible
+// aunque el validador ya no esté en el set; limit y offset paginan el historial
+// GET /api/v1/validators/tombstones lista los validadores expulsados por doble firma
+func (s *RestServer) handleValidator(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.consensus == nil || s.consensus.GetValidatorSet() == nil {
+		http.Error(w, "Consensus not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/validators/")
+	if path == "tombstones" {
+		tombstones := s.consensus.GetValidatorSet().GetTombstones()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tombstones": tombstones,
+			"count":      len(tombstones),
+		})
+		return
+	}
+	address, history := strings.CutSuffix(path, "/history")
+	if !common.IsHexAddress(address) {
+		http.Error(w, "Invalid validator address", http.StatusBadRequest)
+		return
+	}
+
+	validatorSet := s.consensus.GetValidatorSet()
+	all, _ := validatorSet.ListValidators(consensus.ValidatorStatusAll)
+	var found *consensus.Validator
+	for _, v := range all {
+		if strings.EqualFold(v.Address, address) {
+			found = v
+			break
+		}
+	}
+
+	if !history {
+		if found == nil {
+			http.Error(w, "Validator not found", http.StatusNotFound)
+			return
+		}
+		info := newValidatorInfo(found)
+		uptime := validatorSet.GetUptime(found.Address)
+		info.Uptime = &uptime
+		info.BlocksProposed = validatorSet.BlocksProposed(found.Address)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(info)
+		return
+	}
+
+	limit, offset, err := parseLimitOffset(r, defaultValidatorsLimit, maxValidatorsLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if found != nil {
+		address = found.Address
+	}
+	events, err := validatorSet.GetValidatorHistory(address)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting validator history: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if found == nil && len(events) == 0 {
+		http.Error(w, "Validator not found", http.StatusNotFound)
+		return
+	}
+
+	// Del más reciente al más antiguo
+	total := len(events)
+	page := make([]consensus.ValidatorEvent, 0, limit)
+	for i := total - 1 - offset; i >= 0 && len(page) < limit; i-- {
+		page = append(page, events[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"address": address,
+		"events":  page,
+		"total":   total,
+		"offset":  offset,
+		"limit":   limit,
+		"uptime":  validatorSet.GetUptime(address),
+	})
+}
+
+// handleRewards maneja GET /api/v1/rewards/{address}
+// Retorna las recompensas que address puede retirar con withdraw-rewards, por validador
+// (incluye la comisión acumulada si address es un validador)
+func (s *RestServer) handleRewards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.consensus == nil || s.consensus.GetValidatorSet() == nil {
+		http.Error(w, "Consensus not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	address := strings.TrimPrefix(r.URL.Path, "/api/v1/rewards/")
+	if !common.IsHexAddress(address) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+
+	rewards, total := s.consensus.GetValidatorSet().PendingRewards(address)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"address": address,
+		"rewards": rewards,
+		"total":   total.String(),
+	})
+}
+
+// Límites de /api/v1/accounts
+const (
+	defaultAccountsLimit = 100
+	maxAccountsLimit     = 1000
+)
+
+// handleListAccounts maneja GET /api/v1/accounts?order=balance&limit=100&offset=0
+// Lista las cuentas de mayor a menor balance (top holders) desde el índice mantenido en cada commit
+func (s *RestServer) handleListAccounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	if order := query.Get("order"); order != "" && order != "balance" {
+		http.Error(w, "Unsupported order (only \"balance\")", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultAccountsLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxAccountsLimit {
+			http.Error(w, fmt.Sprintf("Invalid limit (1-%d)", maxAccountsLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	offset := 0
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+		offset = parsed
+	}
+
+	accounts, err := s.storage.ListAccountsByBalance(offset, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error listing accounts: %v", err), http.StatusInternalServerError)
+		return
+	}
+	total, err := s.storage.GetAccountCount()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error counting accounts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"accounts": accounts,
+		"total":    total,
+		"offset":   offset,
+		"limit":    limit,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -724,3 +1912,396 @@ func (s *RestServer) handleValidators(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// handleSupply maneja GET /api/v1/supply
+// Retorna la emisión total y circulante según los contadores on-chain (sin sumar todas las cuentas)
+func (s *RestServer) handleSupply(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.executor == nil {
+		http.Error(w, "EVM executor not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	// La custodia de staking y las recompensas sin retirar no circulan (según el último estado confirmado)
+	supply, err := s.executor.GetCommittedSupply(consensus.ModuleAccountAddresses()...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(supply)
+}
+
+// handleNetworkPeers maneja GET /api/v1/network/peers
+// Retorna los nodos descubiertos por sus descriptores firmados y sus direcciones en el formato de OXY_PERSISTENT_PEERS
+func (s *RestServer) handleNetworkPeers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.peerTable == nil {
+		http.Error(w, "P2P network not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"peers":           s.peerTable.Peers(),
+		"persistentPeers": strings.Join(s.peerTable.PersistentPeers(), ","),
+	})
+}
+
+// handleAdminPeers maneja GET /api/v1/admin/peers
+// Retorna el score de los peers mesh con mal comportamiento registrado y los bans vigentes
+func (s *RestServer) handleAdminPeers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.peerScorer == nil {
+		http.Error(w, "P2P network not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"peers": s.peerScorer.Scores(),
+		"bans":  s.peerScorer.Bans(),
+	})
+}
+
+// handleAdminBanPeer maneja POST /api/v1/admin/peers/ban
+// Body: {"peer": "0x...", "durationSeconds": 3600, "reason": "..."} (duración 0 usa la por defecto)
+func (s *RestServer) handleAdminBanPeer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.peerScorer == nil {
+		http.Error(w, "P2P network not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		Peer            string `json:"peer"`
+		DurationSeconds int64  `json:"durationSeconds"`
+		Reason          string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Peer == "" {
+		http.Error(w, "Peer is required", http.StatusBadRequest)
+		return
+	}
+	if req.DurationSeconds < 0 {
+		http.Error(w, "Invalid duration", http.StatusBadRequest)
+		return
+	}
+	if req.Reason == "" {
+		req.Reason = "admin"
+	}
+
+	// Los peers se identifican por su dirección en minúsculas (ver network.peerID)
+	peer := strings.ToLower(strings.TrimSpace(req.Peer))
+	if err := s.peerScorer.Ban(peer, time.Duration(req.DurationSeconds)*time.Second, req.Reason); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"bans":    s.peerScorer.Bans(),
+	})
+}
+
+// handleAdminUnbanPeer maneja POST /api/v1/admin/peers/unban
+// Body: {"peer": "0x..."}
+func (s *RestServer) handleAdminUnbanPeer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.peerScorer == nil {
+		http.Error(w, "P2P network not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		Peer string `json:"peer"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if !s.peerScorer.Unban(strings.ToLower(strings.TrimSpace(req.Peer))) {
+		http.Error(w, "Peer not banned", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"bans":    s.peerScorer.Bans(),
+	})
+}
+
+// publicSubscription copia una suscripción sin su secret (solo se muestra al crearla)
+func publicSubscription(sub *watchlist.Subscription) *watchlist.Subscription {
+	public := *sub
+	public.Secret = ""
+	return &public
+}
+
+// handleWatchlist maneja /api/v1/watchlist
+// GET lista las suscripciones; POST registra una nueva
+// Body: {"addresses": ["0x..."], "callbackUrl": "https://...", "secret": "..."} (sin callbackUrl = solo WebSocket)
+func (s *RestServer) handleWatchlist(w http.ResponseWriter, r *http.Request) {
+	if s.watchlist == nil {
+		http.Error(w, "Watchlist not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		subscriptions := s.watchlist.List()
+		public := make([]*watchlist.Subscription, 0, len(subscriptions))
+		for _, sub := range subscriptions {
+			public = append(public, publicSubscription(sub))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"subscriptions": public,
+			"total":         len(public),
+		})
+
+	case http.MethodPost:
+		var req watchlist.Subscription
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+			return
+		}
+		sub, err := s.watchlist.Add(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(sub)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleWatchlistSubscription maneja /api/v1/watchlist/{id} y /api/v1/watchlist/{id}/ws
+// GET retorna la suscripción, DELETE la elimina; /ws entrega sus notificaciones por WebSocket
+func (s *RestServer) handleWatchlistSubscription(w http.ResponseWriter, r *http.Request) {
+	if s.watchlist == nil {
+		http.Error(w, "Watchlist not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/watchlist/")
+	if id, ok := strings.CutSuffix(path, "/ws"); ok {
+		s.handleWatchlistStream(w, r, id)
+		return
+	}
+	id := path
+
+	switch r.Method {
+	case http.MethodGet:
+		sub, ok := s.watchlist.Get(id)
+		if !ok {
+			http.Error(w, "Subscription not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(publicSubscription(sub))
+
+	case http.MethodDelete:
+		if err := s.watchlist.Remove(id); err != nil {
+			if errors.Is(err, watchlist.ErrNotFound) {
+				http.Error(w, "Subscription not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminLogLevel maneja GET y PUT /api/v1/admin/log-level
+// Body (PUT): {"level": "info", "modules": {"consensus": "debug", "api": "warn"}}. Sin "level" se conserva
+// el nivel por defecto y sin "modules" los filtros actuales; un objeto "modules" reemplaza todos los filtros
+func (s *RestServer) handleAdminLogLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req logger.Levels
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := logger.SetLevels(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		apiLog.Infof("Niveles de log cambiados por el API de administración: %s", logger.GetLevels())
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(logger.GetLevels())
+}
+
+// Límites de /admin/v1/state/dump
+const (
+	defaultStateDumpLimit = 1000
+	maxStateDumpLimit     = 10000
+)
+
+// handleAdminStateDump maneja GET /admin/v1/state/dump?height=&start=&limit=
+// Lista las cuentas del estado tras el bloque height (por defecto el último confirmado) en orden de clave
+// del trie; "next" de la respuesta es el start de la siguiente página
+func (s *RestServer) handleAdminStateDump(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.executor == nil {
+		http.Error(w, "EVM executor not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	var height uint64
+	if value := query.Get("height"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid height", http.StatusBadRequest)
+			return
+		}
+		height = parsed
+	}
+	var start common.Hash
+	if value := query.Get("start"); value != "" {
+		if len(common.FromHex(value)) != common.HashLength {
+			http.Error(w, "Invalid start (32-byte hex key)", http.StatusBadRequest)
+			return
+		}
+		start = common.HexToHash(value)
+	}
+	limit := defaultStateDumpLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxStateDumpLimit {
+			http.Error(w, fmt.Sprintf("Invalid limit (1-%d)", maxStateDumpLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	dump, err := s.executor.DumpState(height, start, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error dumping state: %v", err), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dump)
+}
+
+// watchlistUpgrader acepta conexiones WebSocket de cualquier origen (el endpoint exige el token de administración)
+var watchlistUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// handleWatchlistStream entrega las notificaciones de una suscripción por WebSocket (un mensaje JSON por notificación)
+func (s *RestServer) handleWatchlistStream(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	notifications, cancel, err := s.watchlist.Listen(id)
+	if err != nil {
+		http.Error(w, "Subscription not found", http.StatusNotFound)
+		return
+	}
+	defer cancel()
+
+	conn, err := watchlistUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade ya respondió al cliente
+		return
+	}
+	defer conn.Close()
+
+	// Leer en segundo plano solo para detectar el cierre de la conexión
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case notification, ok := <-notifications:
+			if !ok {
+				// La suscripción fue eliminada
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "subscription removed"))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteJSON(notification); err != nil {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
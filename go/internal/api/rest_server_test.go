@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
 	"github.com/Q-YZX0/oxy-blockchain/internal/health"
+	"github.com/Q-YZX0/oxy-blockchain/internal/logger"
 	"github.com/Q-YZX0/oxy-blockchain/internal/metrics"
+	"github.com/Q-YZX0/oxy-blockchain/internal/network"
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
 )
 
@@ -148,6 +153,185 @@ func TestRestServer_GetBlock(t *testing.T) {
 	}
 }
 
+// TestRestServer_GetBlockByHash prueba el endpoint GET /api/v1/blocks/hash/{hash}
+func TestRestServer_GetBlockByHash(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+
+	hash := "0x00000000000000000000000000000000000000000000000000000000000000aa"
+	if err := db.SaveCanonicalBlock(3, hash, []byte(`{"header": {"Height": 3, "Hash": "`+hash+`"}}`)); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+
+	for path, expected := range map[string]int{
+		"/api/v1/blocks/hash/" + hash: http.StatusOK,
+		"/api/v1/blocks/hash/0x00000000000000000000000000000000000000000000000000000000000000bb": http.StatusNotFound,
+		"/api/v1/blocks/hash/0x12": http.StatusBadRequest,
+	} {
+		rr := httptest.NewRecorder()
+		server.handleBlocks(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != expected {
+			t.Errorf("%s: status %d, esperado %d", path, rr.Code, expected)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleBlocks(rr, httptest.NewRequest("GET", "/api/v1/blocks/hash/"+hash, nil))
+	var block consensus.Block
+	if err := json.Unmarshal(rr.Body.Bytes(), &block); err != nil || block.Header.Height != 3 {
+		t.Errorf("Bloque por hash incorrecto: %s (%v)", rr.Body.String(), err)
+	}
+
+	// Un bloque guardado ya fue confirmado; sin consenso no hay resumen del commit
+	var finality struct {
+		Finalized bool            `json:"finalized"`
+		Commit    json.RawMessage `json:"commit"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &finality); err != nil || !finality.Finalized || finality.Commit != nil {
+		t.Errorf("Finalidad del bloque incorrecta: %s (%v)", rr.Body.String(), err)
+	}
+}
+
+// TestRestServer_BlockTransactions prueba ?full=false y GET /api/v1/blocks/{ref}/transactions/{index}
+func TestRestServer_BlockTransactions(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+
+	hash := "0x00000000000000000000000000000000000000000000000000000000000000aa"
+	blockJSON := `{"header": {"Height": 3, "Hash": "` + hash + `"}, "Transactions": [{"Hash": "0x01", "From": "0xa"}, {"Hash": "0x02", "From": "0xb"}]}`
+	if err := db.SaveCanonicalBlock(3, hash, []byte(blockJSON)); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+
+	// Solo hashes
+	rr := httptest.NewRecorder()
+	server.handleBlocks(rr, httptest.NewRequest("GET", "/api/v1/blocks/3?full=false", nil))
+	var hashesOnly struct {
+		Transactions []string
+		Finalized    bool `json:"finalized"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &hashesOnly); err != nil || len(hashesOnly.Transactions) != 2 ||
+		hashesOnly.Transactions[1] != "0x02" || !hashesOnly.Finalized {
+		t.Errorf("Bloque con solo hashes incorrecto: %s (%v)", rr.Body.String(), err)
+	}
+
+	// Por defecto (y con full=true) las transacciones completas
+	rr = httptest.NewRecorder()
+	server.handleBlocks(rr, httptest.NewRequest("GET", "/api/v1/blocks/3?full=true", nil))
+	var block consensus.Block
+	if err := json.Unmarshal(rr.Body.Bytes(), &block); err != nil || len(block.Transactions) != 2 || block.Transactions[0].From != "0xa" {
+		t.Errorf("Bloque completo incorrecto: %s (%v)", rr.Body.String(), err)
+	}
+
+	for path, expected := range map[string]int{
+		"/api/v1/blocks/3/transactions/1":                 http.StatusOK,
+		"/api/v1/blocks/hash/" + hash + "/transactions/0": http.StatusOK,
+		"/api/v1/blocks/3/transactions/2":                 http.StatusNotFound,
+		"/api/v1/blocks/3/transactions/x":                 http.StatusBadRequest,
+		"/api/v1/blocks/3?full=maybe":                     http.StatusBadRequest,
+	} {
+		rr := httptest.NewRecorder()
+		server.handleBlocks(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != expected {
+			t.Errorf("%s: status %d, esperado %d", path, rr.Code, expected)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleBlocks(rr, httptest.NewRequest("GET", "/api/v1/blocks/3/transactions/1", nil))
+	var tx struct {
+		Hash             string
+		BlockHash        string `json:"blockHash"`
+		BlockNumber      uint64 `json:"blockNumber"`
+		TransactionIndex uint64 `json:"transactionIndex"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &tx); err != nil || tx.Hash != "0x02" || tx.BlockHash != hash ||
+		tx.BlockNumber != 3 || tx.TransactionIndex != 1 {
+		t.Errorf("Transacción por índice incorrecta: %s (%v)", rr.Body.String(), err)
+	}
+}
+
+// TestRestServer_HTTPMetrics prueba que las peticiones se registren por ruta registrada y clase de status
+func TestRestServer_HTTPMetrics(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/blocks/", server.handleBlocks)
+	handler := server.metricsMiddleware(mux, mux)
+	for _, path := range []string{"/api/v1/blocks/5", "/api/v1/blocks/6", "/api/v1/blocks/x", "/no-existe"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	server.metrics.IncrementRateLimited(endpointClassWrite)
+
+	rr := httptest.NewRecorder()
+	server.handlePrometheusMetrics(rr, httptest.NewRequest("GET", "/metrics/prometheus", nil))
+	body := rr.Body.String()
+	for _, expected := range []string{
+		`oxy_http_requests_total{method="GET",route="/api/v1/blocks/",status_class="4xx"} 3`,
+		`oxy_http_request_errors_total{method="GET",route="unmatched",status_class="4xx"} 1`,
+		`oxy_http_request_duration_seconds_count{method="GET",route="/api/v1/blocks/",status_class="4xx"} 3`,
+		`oxy_http_rate_limited_total{class="write"} 1`,
+		`oxy_http_rate_limited_total{class="read"} 0`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Falta %s en las métricas", expected)
+		}
+	}
+}
+
+// TestRestServer_RecoveryMiddleware prueba que el pánico de un handler responda 500 sin tirar el servidor
+func TestRestServer_RecoveryMiddleware(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("handler roto")
+	})
+	mux.HandleFunc("/panic-after-write", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("handler roto")
+	})
+	handler := server.metricsMiddleware(mux, server.recoveryMiddleware(mux))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/panic", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Status incorrecto: obtenido %d, esperado 500", rr.Code)
+	}
+
+	// Con la respuesta ya empezada se aborta la conexión
+	func() {
+		defer func() {
+			if rec := recover(); rec != http.ErrAbortHandler {
+				t.Errorf("Se esperaba http.ErrAbortHandler: obtenido %v", rec)
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic-after-write", nil))
+	}()
+
+	snapshot := server.metrics.GetMetrics()
+	if snapshot.PanicsRecovered[metrics.PanicComponentHTTP] != 2 {
+		t.Errorf("Pánicos recuperados incorrectos: %v", snapshot.PanicsRecovered)
+	}
+	if len(snapshot.HTTPRequests) != 1 || snapshot.HTTPRequests[0].StatusClass != "5xx" {
+		t.Errorf("El 500 debería registrarse en las métricas HTTP: %+v", snapshot.HTTPRequests)
+	}
+}
+
 // TestRestServer_GetTransaction prueba el endpoint GET /api/v1/transactions/{hash}
 func TestRestServer_GetTransaction(t *testing.T) {
 	server, db := crearTestServer(t)
@@ -257,6 +441,115 @@ func TestRestServer_SubmitTransaction(t *testing.T) {
 	}
 }
 
+// TestRestServer_SubmitTransaction_ErrorCode prueba que los errores incluyan el código del registro
+func TestRestServer_SubmitTransaction_ErrorCode(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+	
+	// Transacción sin hash
+	jsonData := []byte(`{"from": "0x1234567890123456789012345678901234567890"}`)
+	req, err := http.NewRequest("POST", "/api/v1/submit-tx", bytes.NewBuffer(jsonData))
+	if err != nil {
+		t.Fatalf("Error creando request: %v", err)
+	}
+	
+	rr := httptest.NewRecorder()
+	server.handleSubmitTx(rr, req)
+	
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Status code incorrecto: esperado 400, obtenido %d", rr.Code)
+	}
+	
+	var response struct {
+		Success bool `json:"success"`
+		Error   struct {
+			Codespace string `json:"codespace"`
+			Code      uint32 `json:"code"`
+			Reason    string `json:"reason"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Error decodificando respuesta: %v", err)
+	}
+	
+	if response.Success {
+		t.Error("La respuesta no debería ser exitosa")
+	}
+	if response.Error.Code != consensus.CodeInvalidHash || response.Error.Reason != "invalid_hash" {
+		t.Errorf("Error incorrecto: código %d, razón %s", response.Error.Code, response.Error.Reason)
+	}
+	if response.Error.Codespace != consensus.Codespace {
+		t.Errorf("Codespace incorrecto: %s", response.Error.Codespace)
+	}
+}
+
+// TestRestServer_SearchTransactions_MissingQuery prueba que la búsqueda requiera query
+func TestRestServer_SearchTransactions_MissingQuery(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+	
+	req, err := http.NewRequest("GET", "/api/v1/search/transactions", nil)
+	if err != nil {
+		t.Fatalf("Error creando request: %v", err)
+	}
+	
+	rr := httptest.NewRecorder()
+	server.handleSearchTransactions(rr, req)
+	
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Status code incorrecto: esperado 400, obtenido %d", rr.Code)
+	}
+}
+
+// TestRestServer_SearchTransactions_InvalidQuery prueba que un query mal formado retorne 400
+func TestRestServer_SearchTransactions_InvalidQuery(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+
+	req, err := http.NewRequest("GET", "/api/v1/search/transactions?query="+url.QueryEscape("transfer.sender=0xabc"), nil)
+	if err != nil {
+		t.Fatalf("Error creando request: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleSearchTransactions(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Status code incorrecto: esperado 400, obtenido %d", rr.Code)
+	}
+}
+
+// TestRestServer_BlockResults_NoConsensus prueba el ruteo a block results sin consensus
+func TestRestServer_BlockResults_NoConsensus(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+	
+	req, err := http.NewRequest("GET", "/api/v1/blocks/abc/results", nil)
+	if err != nil {
+		t.Fatalf("Error creando request: %v", err)
+	}
+	
+	rr := httptest.NewRecorder()
+	server.handleBlocks(rr, req)
+	
+	// Sin consensus el endpoint no está disponible
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status code incorrecto: esperado 503, obtenido %d", rr.Code)
+	}
+}
+
 // TestRestServer_CORSMiddleware prueba el middleware CORS
 func TestRestServer_CORSMiddleware(t *testing.T) {
 	server, db := crearTestServer(t)
@@ -323,3 +616,184 @@ func TestRestServer_MethodNotAllowed(t *testing.T) {
 	}
 }
 
+// TestRestServer_AdminPeers prueba el API de administración de bans de peers mesh
+func TestRestServer_AdminPeers(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+	server.SetPeerScorer(network.NewPeerScorer())
+	t.Setenv("OXY_ADMIN_TOKEN", "secreto")
+
+	ban := server.adminOnly(server.handleAdminBanPeer)
+
+	// Sin token se rechaza
+	req, _ := http.NewRequest("POST", "/api/v1/admin/peers/ban", bytes.NewBufferString(`{"peer":"0xABC"}`))
+	rr := httptest.NewRecorder()
+	ban(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("Status code incorrecto sin token: esperado 401, obtenido %d", rr.Code)
+	}
+
+	// Con token se banea (el peer se normaliza a minúsculas)
+	req, _ = http.NewRequest("POST", "/api/v1/admin/peers/ban", bytes.NewBufferString(`{"peer":"0xABC","durationSeconds":60,"reason":"spam"}`))
+	req.Header.Set("Authorization", "Bearer secreto")
+	rr = httptest.NewRecorder()
+	ban(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Status code incorrecto: esperado 200, obtenido %d", rr.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/admin/peers", nil)
+	req.Header.Set("Authorization", "Bearer secreto")
+	rr = httptest.NewRecorder()
+	server.adminOnly(server.handleAdminPeers)(rr, req)
+
+	var response struct {
+		Bans []network.PeerBan `json:"bans"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Error decodificando respuesta: %v", err)
+	}
+	if len(response.Bans) != 1 || response.Bans[0].Peer != "0xabc" || response.Bans[0].Reason != "spam" {
+		t.Errorf("Bans incorrectos: %+v", response.Bans)
+	}
+
+	// Levantar el ban
+	req, _ = http.NewRequest("POST", "/api/v1/admin/peers/unban", bytes.NewBufferString(`{"peer":"0xabc"}`))
+	req.Header.Set("Authorization", "Bearer secreto")
+	rr = httptest.NewRecorder()
+	server.adminOnly(server.handleAdminUnbanPeer)(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Status code incorrecto al levantar ban: esperado 200, obtenido %d", rr.Code)
+	}
+
+	// Sin token configurado el API de administración está deshabilitado, incluso desde localhost
+	t.Setenv("OXY_ADMIN_TOKEN", "")
+	for _, remoteAddr := range []string{"203.0.113.5:4000", "127.0.0.1:4000"} {
+		req, _ = http.NewRequest("GET", "/api/v1/admin/peers", nil)
+		req.RemoteAddr = remoteAddr
+		rr = httptest.NewRecorder()
+		server.adminOnly(server.handleAdminPeers)(rr, req)
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("Status code incorrecto sin token desde %s: esperado 403, obtenido %d", remoteAddr, rr.Code)
+		}
+	}
+}
+
+// TestRestServer_AdminCompliance prueba la administración de la lista de compliance
+func TestRestServer_AdminCompliance(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+	t.Setenv("OXY_ADMIN_TOKEN", "secreto")
+	list := server.adminOnly(server.handleAdminCompliance)
+	address := server.adminOnly(server.handleAdminComplianceAddress)
+
+	// Sin módulo configurado los endpoints no están disponibles
+	req, _ := http.NewRequest("GET", "/api/v1/admin/compliance", nil)
+	req.Header.Set("Authorization", "Bearer secreto")
+	rr := httptest.NewRecorder()
+	list(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Status code incorrecto sin módulo: esperado 503, obtenido %d", rr.Code)
+	}
+
+	compliance, err := consensus.NewComplianceList(db, consensus.ComplianceBlacklist)
+	if err != nil {
+		t.Fatalf("Error creando lista: %v", err)
+	}
+	server.SetComplianceList(compliance)
+
+	blocked := "0x00000000000000000000000000000000000000b0"
+	req, _ = http.NewRequest("POST", "/api/v1/admin/compliance", bytes.NewBufferString(`{"address":"`+blocked+`","reason":"sanción"}`))
+	req.Header.Set("Authorization", "Bearer secreto")
+	rr = httptest.NewRecorder()
+	list(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Status code incorrecto al agregar: esperado 201, obtenido %d", rr.Code)
+	}
+	if err := compliance.Check(&consensus.Transaction{From: blocked}); consensus.ErrorCode(err, consensus.CodeOK) != consensus.CodeAddressBlocked {
+		t.Errorf("La dirección agregada debería bloquearse: %v", err)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/v1/admin/compliance", bytes.NewBufferString(`{"address":"0xzz"}`))
+	req.Header.Set("Authorization", "Bearer secreto")
+	rr = httptest.NewRecorder()
+	list(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Status code incorrecto con dirección inválida: esperado 400, obtenido %d", rr.Code)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/admin/compliance/"+blocked+"?reason=revisión", nil)
+	req.Header.Set("Authorization", "Bearer secreto")
+	rr = httptest.NewRecorder()
+	address(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Status code incorrecto al quitar: esperado 200, obtenido %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	address(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Status code incorrecto al quitar una dirección no listada: esperado 404, obtenido %d", rr.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/admin/compliance/audit", nil)
+	req.Header.Set("Authorization", "Bearer secreto")
+	rr = httptest.NewRecorder()
+	address(rr, req)
+	var response struct {
+		Audit []consensus.ComplianceAuditEntry `json:"audit"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Error decodificando respuesta: %v", err)
+	}
+	if len(response.Audit) != 2 || response.Audit[1].Action != "remove" || response.Audit[1].Actor != "admin-api" {
+		t.Errorf("Auditoría incorrecta: %+v", response.Audit)
+	}
+}
+
+// TestRestServer_AdminLogLevel prueba el cambio de niveles de log en runtime
+func TestRestServer_AdminLogLevel(t *testing.T) {
+	server, db := crearTestServer(t)
+	defer func() {
+		db.Close()
+		os.RemoveAll("./test_data_api_" + t.Name())
+	}()
+	t.Setenv("OXY_ADMIN_TOKEN", "secreto")
+	previous := logger.GetLevels()
+	defer logger.SetLevels(previous)
+
+	handler := server.adminOnly(server.handleAdminLogLevel)
+
+	req, _ := http.NewRequest("PUT", "/api/v1/admin/log-level", bytes.NewBufferString(`{"level":"info","modules":{"consensus":"debug"}}`))
+	req.Header.Set("Authorization", "Bearer secreto")
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Status code incorrecto: esperado 200, obtenido %d", rr.Code)
+	}
+
+	var levels logger.Levels
+	if err := json.Unmarshal(rr.Body.Bytes(), &levels); err != nil {
+		t.Fatalf("Error decodificando respuesta: %v", err)
+	}
+	if levels.Level != "info" || levels.Modules["consensus"] != "debug" {
+		t.Errorf("Niveles incorrectos: %+v", levels)
+	}
+
+	// Un nivel inválido se rechaza sin cambiar la configuración
+	req, _ = http.NewRequest("PUT", "/api/v1/admin/log-level", bytes.NewBufferString(`{"modules":{"api":"loud"}}`))
+	req.Header.Set("Authorization", "Bearer secreto")
+	rr = httptest.NewRecorder()
+	handler(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Status code incorrecto con nivel inválido: esperado 400, obtenido %d", rr.Code)
+	}
+	if got := logger.GetLevels(); got.Modules["consensus"] != "debug" || got.Modules["api"] != "" {
+		t.Errorf("La configuración cambió tras un error: %+v", got)
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/rediscache"
+)
+
+// SetSharedCache configura el cache en Redis de las lecturas frecuentes (último bloque, validadores y cuentas)
+// compartido entre los nodos del API
+func (s *RestServer) SetSharedCache(cache *rediscache.Cache) {
+	s.sharedCache = cache
+}
+
+// encodeJSON serializa igual que json.Encoder (con salto de línea final), para que una respuesta leída del
+// cache sea idéntica a la generada
+func encodeJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSONBody responde un cuerpo JSON ya serializado
+func writeJSONBody(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// writeSharedCacheMetrics escribe los aciertos, fallos y errores del cache en Redis
+func (s *RestServer) writeSharedCacheMetrics(w io.Writer) {
+	if s.sharedCache == nil {
+		return
+	}
+	stats := s.sharedCache.Stats()
+	fmt.Fprintf(w, "# HELP oxy_redis_cache_hits_total API reads served from the shared Redis cache\n")
+	fmt.Fprintf(w, "# TYPE oxy_redis_cache_hits_total counter\n")
+	fmt.Fprintf(w, "oxy_redis_cache_hits_total %d\n", stats.Hits)
+	fmt.Fprintf(w, "# HELP oxy_redis_cache_misses_total API reads not found in the shared Redis cache\n")
+	fmt.Fprintf(w, "# TYPE oxy_redis_cache_misses_total counter\n")
+	fmt.Fprintf(w, "oxy_redis_cache_misses_total %d\n", stats.Misses)
+	fmt.Fprintf(w, "# HELP oxy_redis_cache_errors_total Shared Redis cache operations that failed or timed out\n")
+	fmt.Fprintf(w, "# TYPE oxy_redis_cache_errors_total counter\n")
+	fmt.Fprintf(w, "oxy_redis_cache_errors_total %d\n", stats.Errors)
+}
@@ -0,0 +1,501 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+)
+
+// defaultMaxTxDataBytes es el tamaño máximo por defecto del campo data de una transacción
+const defaultMaxTxDataBytes = 128 * 1024
+
+// defaultCallGasLimit es el gas de una simulación que no indica gasLimit
+const defaultCallGasLimit = 10_000_000
+
+var (
+	txHashPattern    = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+	addressPattern   = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	decimalPattern   = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+	maxUint256       = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	submitTxFields   = []string{"hash", "from", "to", "value", "data", "gasLimit", "gasPrice", "nonce", "signature", "timestamp", "accessList", "multisig", "feePayer"}
+	submitTxFieldSet = fieldSet(submitTxFields)
+	callTxFieldSet   = fieldSet([]string{"from", "to", "value", "data", "gasLimit", "gasPrice", "accessList"})
+)
+
+// fieldSet arma el conjunto de campos aceptados por un esquema
+func fieldSet(fields []string) map[string]bool {
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		set[field] = true
+	}
+	return set
+}
+
+// FieldError describe un campo inválido del body de una petición
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	code    uint32 // Código del registro de errores del consenso
+}
+
+// TxSchemaError agrupa los errores de validación de esquema de una transacción enviada
+// Envuelve un consensus.TxError con el código del primer campo inválido
+type TxSchemaError struct {
+	Fields []FieldError
+	txErr  *consensus.TxError
+}
+
+// Error implementa la interfaz error
+func (e *TxSchemaError) Error() string {
+	return e.txErr.Error()
+}
+
+// Unwrap expone el TxError para consensus.ErrorCode
+func (e *TxSchemaError) Unwrap() error {
+	return e.txErr
+}
+
+// newTxSchemaError construye el error a partir de los campos inválidos (en orden del esquema)
+func newTxSchemaError(fields []FieldError) *TxSchemaError {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+	return &TxSchemaError{
+		Fields: fields,
+		txErr:  consensus.NewTxError(fields[0].code, "Invalid transaction: %s", strings.Join(messages, "; ")),
+	}
+}
+
+// decodeSubmitTx decodifica y valida el body de /api/v1/submit-tx contra el esquema estricto:
+// campos requeridos, direcciones y hashes en hex, valores como strings decimales, data acotada y sin campos desconocidos.
+// data y signature se aceptan en hex ("0x...") o en base64 (codificación por defecto de []byte en JSON).
+// Una transacción multisig no lleva signature: la autorizan las firmas de multisig.signatures
+func decodeSubmitTx(body io.Reader, maxDataBytes int) (*consensus.Transaction, error) {
+	return decodeTx(body, maxDataBytes, false)
+}
+
+// decodeMultisigProposal decodifica el body de POST /api/v1/multisig/proposals: una transacción multisig
+// con el mismo esquema que submit-tx, sin signature y con hash y from opcionales (se calculan al proponerla)
+func decodeMultisigProposal(body io.Reader, maxDataBytes int) (*consensus.Transaction, error) {
+	return decodeTx(body, maxDataBytes, true)
+}
+
+// decodeTx decodifica una transacción de submit-tx o, con proposal, una propuesta multisig
+func decodeTx(body io.Reader, maxDataBytes int, proposal bool) (*consensus.Transaction, error) {
+	if maxDataBytes <= 0 {
+		maxDataBytes = defaultMaxTxDataBytes
+	}
+
+	decoder := json.NewDecoder(body)
+	var raw map[string]json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, consensus.NewTxError(consensus.CodeDecodeError, "Invalid transaction format: %v", err)
+	}
+	if raw == nil {
+		return nil, consensus.NewTxError(consensus.CodeDecodeError, "Invalid transaction format: expected a JSON object")
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, consensus.NewTxError(consensus.CodeDecodeError, "Invalid transaction format: unexpected data after the transaction object")
+	}
+
+	var tx consensus.Transaction
+	var fields []FieldError
+	invalid := func(field string, code uint32, format string, args ...interface{}) {
+		fields = append(fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...), code: code})
+	}
+
+	// hash y from son requeridos (salvo en una propuesta multisig)
+	if hash, ok := stringField(raw, "hash", invalid, consensus.CodeInvalidHash); ok {
+		if hash == "" {
+			if !proposal {
+				invalid("hash", consensus.CodeInvalidHash, "required")
+			}
+		} else if !txHashPattern.MatchString(hash) {
+			invalid("hash", consensus.CodeInvalidHash, "must be a 0x-prefixed 32-byte hex string")
+		}
+		tx.Hash = hash
+	}
+	if from, ok := stringField(raw, "from", invalid, consensus.CodeInvalidTx); ok {
+		if from == "" {
+			if !proposal {
+				invalid("from", consensus.CodeInvalidTx, "required")
+			}
+		} else if !addressPattern.MatchString(from) {
+			invalid("from", consensus.CodeInvalidTx, "must be a 0x-prefixed 20-byte hex address")
+		}
+		tx.From = from
+	}
+
+	// to vacío = creación de contrato
+	if to, ok := stringField(raw, "to", invalid, consensus.CodeInvalidTx); ok {
+		if to != "" && !addressPattern.MatchString(to) {
+			invalid("to", consensus.CodeInvalidTx, "must be a 0x-prefixed 20-byte hex address")
+		}
+		tx.To = to
+	}
+
+	if value, ok := stringField(raw, "value", invalid, consensus.CodeInvalidTx); ok {
+		if value != "" {
+			if err := validateUint256String(value); err != nil {
+				invalid("value", consensus.CodeInvalidTx, "%v", err)
+			}
+		}
+		tx.Value = value
+	}
+
+	if data, ok := stringField(raw, "data", invalid, consensus.CodeInvalidTx); ok && data != "" {
+		decoded, err := decodeBytesField(data)
+		if err != nil {
+			invalid("data", consensus.CodeInvalidTx, "%v", err)
+		} else if len(decoded) > maxDataBytes {
+			invalid("data", consensus.CodeInvalidTx, "exceeds %d bytes (%d)", maxDataBytes, len(decoded))
+		}
+		tx.Data = decoded
+	}
+
+	if gasLimit, ok := uintField(raw, "gasLimit", invalid); ok {
+		tx.GasLimit = gasLimit
+	}
+
+	if gasPrice, ok := stringField(raw, "gasPrice", invalid, consensus.CodeInvalidTx); ok {
+		if gasPrice != "" {
+			if err := validateUint256String(gasPrice); err != nil {
+				invalid("gasPrice", consensus.CodeInvalidTx, "%v", err)
+			}
+		}
+		tx.GasPrice = gasPrice
+	}
+
+	if nonce, ok := uintField(raw, "nonce", invalid); ok {
+		tx.Nonce = nonce
+	}
+
+	tx.Multisig = multisigField(raw, invalid)
+	_, isMultisig := raw["multisig"]
+	if proposal && !isMultisig {
+		invalid("multisig", consensus.CodeInvalidSignature, "required")
+	}
+
+	if signature, ok := stringField(raw, "signature", invalid, consensus.CodeInvalidSignature); ok {
+		if signature == "" {
+			if !isMultisig {
+				invalid("signature", consensus.CodeInvalidSignature, "required")
+			}
+		} else if isMultisig {
+			invalid("signature", consensus.CodeInvalidSignature, "not allowed in a multisig transaction (use multisig.signatures)")
+		} else if decoded, err := decodeBytesField(signature); err != nil {
+			invalid("signature", consensus.CodeInvalidSignature, "%v", err)
+		} else if len(decoded) != 65 {
+			invalid("signature", consensus.CodeInvalidSignature, "must be 65 bytes, got %d", len(decoded))
+		} else {
+			tx.Signature = decoded
+		}
+	}
+
+	if timestamp, ok := uintField(raw, "timestamp", invalid); ok {
+		if timestamp > uint64(1<<63-1) {
+			invalid("timestamp", consensus.CodeInvalidTx, "out of range")
+		}
+		tx.Timestamp = int64(timestamp)
+	}
+
+	tx.AccessList = accessListField(raw, invalid)
+	tx.FeePayer = feePayerField(raw, invalid)
+
+	// Campos desconocidos (los nombres son exactos: no se aceptan variantes de mayúsculas)
+	unknownFields(raw, submitTxFieldSet, invalid)
+
+	if len(fields) > 0 {
+		return nil, newTxSchemaError(fields)
+	}
+	return &tx, nil
+}
+
+// decodeCallTx decodifica el body de /api/v1/create-access-list: una transacción sin firmar para simular
+// (from requerido; sin gasLimit se usa defaultCallGasLimit y value y gasPrice vacíos valen 0)
+func decodeCallTx(body io.Reader, maxDataBytes int) (*execution.Transaction, error) {
+	if maxDataBytes <= 0 {
+		maxDataBytes = defaultMaxTxDataBytes
+	}
+
+	decoder := json.NewDecoder(body)
+	var raw map[string]json.RawMessage
+	if err := decoder.Decode(&raw); err != nil || raw == nil {
+		return nil, consensus.NewTxError(consensus.CodeDecodeError, "Invalid transaction format: expected a JSON object")
+	}
+
+	tx := execution.Transaction{Value: "0", GasPrice: "0", GasLimit: defaultCallGasLimit}
+	var fields []FieldError
+	invalid := func(field string, code uint32, format string, args ...interface{}) {
+		fields = append(fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...), code: code})
+	}
+
+	if from, ok := stringField(raw, "from", invalid, consensus.CodeInvalidTx); ok {
+		if from == "" {
+			invalid("from", consensus.CodeInvalidTx, "required")
+		} else if !addressPattern.MatchString(from) {
+			invalid("from", consensus.CodeInvalidTx, "must be a 0x-prefixed 20-byte hex address")
+		}
+		tx.From = from
+	}
+	if to, ok := stringField(raw, "to", invalid, consensus.CodeInvalidTx); ok {
+		if to != "" && !addressPattern.MatchString(to) {
+			invalid("to", consensus.CodeInvalidTx, "must be a 0x-prefixed 20-byte hex address")
+		}
+		tx.To = to
+	}
+	for _, field := range []string{"value", "gasPrice"} {
+		value, ok := stringField(raw, field, invalid, consensus.CodeInvalidTx)
+		if !ok || value == "" {
+			continue
+		}
+		if err := validateUint256String(value); err != nil {
+			invalid(field, consensus.CodeInvalidTx, "%v", err)
+		} else if field == "value" {
+			tx.Value = value
+		} else {
+			tx.GasPrice = value
+		}
+	}
+	if data, ok := stringField(raw, "data", invalid, consensus.CodeInvalidTx); ok && data != "" {
+		decoded, err := decodeBytesField(data)
+		if err != nil {
+			invalid("data", consensus.CodeInvalidTx, "%v", err)
+		} else if len(decoded) > maxDataBytes {
+			invalid("data", consensus.CodeInvalidTx, "exceeds %d bytes (%d)", maxDataBytes, len(decoded))
+		}
+		tx.Data = decoded
+	}
+	if gasLimit, ok := uintField(raw, "gasLimit", invalid); ok && gasLimit > 0 {
+		if gasLimit > defaultCallGasLimit {
+			invalid("gasLimit", consensus.CodeInvalidTx, "exceeds %d", defaultCallGasLimit)
+		}
+		tx.GasLimit = gasLimit
+	}
+	tx.AccessList = accessListField(raw, invalid)
+	unknownFields(raw, callTxFieldSet, invalid)
+
+	if len(fields) > 0 {
+		return nil, newTxSchemaError(fields)
+	}
+	return &tx, nil
+}
+
+// accessListField lee la access list opcional (EIP-2930): [{"address": "0x...", "storageKeys": ["0x..."]}]
+func accessListField(raw map[string]json.RawMessage, invalid func(string, uint32, string, ...interface{})) execution.AccessList {
+	value, exists := raw["accessList"]
+	if !exists || bytes.Equal(value, []byte("null")) {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.DisallowUnknownFields()
+	var list execution.AccessList
+	if err := decoder.Decode(&list); err != nil {
+		invalid("accessList", consensus.CodeInvalidTx, "must be an array of {address, storageKeys}")
+		return nil
+	}
+	valid := true
+	for i, tuple := range list {
+		if !addressPattern.MatchString(tuple.Address) {
+			invalid(fmt.Sprintf("accessList[%d].address", i), consensus.CodeInvalidTx, "must be a 0x-prefixed 20-byte hex address")
+			valid = false
+		}
+		for j, key := range tuple.StorageKeys {
+			if !txHashPattern.MatchString(key) {
+				invalid(fmt.Sprintf("accessList[%d].storageKeys[%d]", i, j), consensus.CodeInvalidTx, "must be a 0x-prefixed 32-byte hex string")
+				valid = false
+			}
+		}
+	}
+	if !valid {
+		return nil
+	}
+	return list
+}
+
+// multisigField lee la autorización multisig opcional:
+// {"threshold": N, "owners": ["0x..."], "signatures": [{"signer": "0x...", "signature": "0x..."}]}
+// Owners y umbral se validan al derivar la dirección; aquí solo se revisan los formatos
+func multisigField(raw map[string]json.RawMessage, invalid func(string, uint32, string, ...interface{})) *consensus.MultisigAuth {
+	value, exists := raw["multisig"]
+	if !exists {
+		return nil
+	}
+	var body struct {
+		Threshold  uint64   `json:"threshold"`
+		Owners     []string `json:"owners"`
+		Signatures []struct {
+			Signer    string `json:"signer"`
+			Signature string `json:"signature"`
+		} `json:"signatures"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil || bytes.Equal(value, []byte("null")) {
+		invalid("multisig", consensus.CodeInvalidSignature, "must be an object {threshold, owners, signatures}")
+		return nil
+	}
+
+	auth := &consensus.MultisigAuth{Threshold: body.Threshold, Owners: body.Owners, Signatures: []consensus.MultisigSignature{}}
+	valid := true
+	if body.Threshold == 0 {
+		invalid("multisig.threshold", consensus.CodeInvalidSignature, "required")
+		valid = false
+	}
+	if len(body.Owners) == 0 {
+		invalid("multisig.owners", consensus.CodeInvalidSignature, "required")
+		valid = false
+	}
+	for i, owner := range body.Owners {
+		if !addressPattern.MatchString(owner) {
+			invalid(fmt.Sprintf("multisig.owners[%d]", i), consensus.CodeInvalidSignature, "must be a 0x-prefixed 20-byte hex address")
+			valid = false
+		}
+	}
+	for i, sig := range body.Signatures {
+		if !addressPattern.MatchString(sig.Signer) {
+			invalid(fmt.Sprintf("multisig.signatures[%d].signer", i), consensus.CodeInvalidSignature, "must be a 0x-prefixed 20-byte hex address")
+			valid = false
+		}
+		decoded, err := decodeBytesField(sig.Signature)
+		if err != nil {
+			invalid(fmt.Sprintf("multisig.signatures[%d].signature", i), consensus.CodeInvalidSignature, "%v", err)
+			valid = false
+		} else if len(decoded) != 65 {
+			invalid(fmt.Sprintf("multisig.signatures[%d].signature", i), consensus.CodeInvalidSignature, "must be 65 bytes, got %d", len(decoded))
+			valid = false
+		}
+		auth.Signatures = append(auth.Signatures, consensus.MultisigSignature{Signer: sig.Signer, Signature: decoded})
+	}
+	if !valid {
+		return nil
+	}
+	return auth
+}
+
+// feePayerField lee el fee payer opcional de una transacción patrocinada:
+// {"address": "0x...", "signature": "0x..."}, con la firma de keccak256("oxy-fee-payer" || hash)
+func feePayerField(raw map[string]json.RawMessage, invalid func(string, uint32, string, ...interface{})) *consensus.FeePayerAuth {
+	value, exists := raw["feePayer"]
+	if !exists || bytes.Equal(value, []byte("null")) {
+		return nil
+	}
+	var body struct {
+		Address   string `json:"address"`
+		Signature string `json:"signature"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		invalid("feePayer", consensus.CodeInvalidSignature, "must be an object {address, signature}")
+		return nil
+	}
+
+	valid := true
+	if !addressPattern.MatchString(body.Address) {
+		invalid("feePayer.address", consensus.CodeInvalidSignature, "must be a 0x-prefixed 20-byte hex address")
+		valid = false
+	}
+	signature, err := decodeBytesField(body.Signature)
+	if body.Signature == "" {
+		invalid("feePayer.signature", consensus.CodeInvalidSignature, "required")
+		valid = false
+	} else if err != nil {
+		invalid("feePayer.signature", consensus.CodeInvalidSignature, "%v", err)
+		valid = false
+	} else if len(signature) != 65 {
+		invalid("feePayer.signature", consensus.CodeInvalidSignature, "must be 65 bytes, got %d", len(signature))
+		valid = false
+	}
+	if !valid {
+		return nil
+	}
+	return &consensus.FeePayerAuth{Address: body.Address, Signature: signature}
+}
+
+// unknownFields registra los campos que el esquema no acepta, en orden alfabético
+func unknownFields(raw map[string]json.RawMessage, accepted map[string]bool, invalid func(string, uint32, string, ...interface{})) {
+	var unknown []string
+	for key := range raw {
+		if !accepted[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		invalid(key, consensus.CodeInvalidTx, "unknown field")
+	}
+}
+
+// stringField lee un campo string; ausente o null retorna "" (ok = true), otro tipo registra un error
+func stringField(raw map[string]json.RawMessage, field string, invalid func(string, uint32, string, ...interface{}), code uint32) (string, bool) {
+	value, exists := raw[field]
+	if !exists || bytes.Equal(value, []byte("null")) {
+		return "", true
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		invalid(field, code, "must be a string")
+		return "", false
+	}
+	return s, true
+}
+
+// uintField lee un campo numérico entero no negativo de 64 bits; ausente o null retorna 0
+func uintField(raw map[string]json.RawMessage, field string, invalid func(string, uint32, string, ...interface{})) (uint64, bool) {
+	value, exists := raw[field]
+	if !exists || bytes.Equal(value, []byte("null")) {
+		return 0, true
+	}
+	var number json.Number
+	if err := json.Unmarshal(value, &number); err != nil || strings.HasPrefix(string(value), `"`) {
+		invalid(field, consensus.CodeInvalidTx, "must be a number")
+		return 0, false
+	}
+	n, err := strconv.ParseUint(number.String(), 10, 64)
+	if err != nil {
+		invalid(field, consensus.CodeInvalidTx, "must be a non-negative 64-bit integer")
+		return 0, false
+	}
+	return n, true
+}
+
+// validateUint256String verifica un entero decimal no negativo que entra en 256 bits
+func validateUint256String(value string) error {
+	if !decimalPattern.MatchString(value) {
+		return fmt.Errorf("must be a non-negative decimal integer string")
+	}
+	n, _ := new(big.Int).SetString(value, 10)
+	if n.Cmp(maxUint256) > 0 {
+		return fmt.Errorf("exceeds 256 bits")
+	}
+	return nil
+}
+
+// decodeBytesField decodifica bytes en hex ("0x...") o base64
+func decodeBytesField(value string) ([]byte, error) {
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		decoded, err := hex.DecodeString(value[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex encoding")
+		}
+		return decoded, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("must be 0x-prefixed hex or base64")
+	}
+	return decoded, nil
+}
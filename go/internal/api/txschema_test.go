@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// validSubmitTx retorna el body de una transacción que cumple el esquema
+func validSubmitTx(signature string) string {
+	return `{
+		"hash": "0x` + strings.Repeat("ab", 32) + `",
+		"from": "0x1234567890123456789012345678901234567890",
+		"to": "0x0987654321098765432109876543210987654321",
+		"value": "1000000000000000000",
+		"data": "0x6001",
+		"gasLimit": 21000,
+		"gasPrice": "1000000000",
+		"nonce": 3,
+		"signature": "` + signature + `"
+	}`
+}
+
+// TestDecodeSubmitTx_Valid prueba que una transacción válida se decodifique (firma en hex o base64)
+func TestDecodeSubmitTx_Valid(t *testing.T) {
+	signature := make([]byte, 65)
+	signature[64] = 27
+	for _, encoded := range []string{"0x" + strings.Repeat("00", 64) + "1b", base64.StdEncoding.EncodeToString(signature)} {
+		tx, err := decodeSubmitTx(strings.NewReader(validSubmitTx(encoded)), 0)
+		if err != nil {
+			t.Fatalf("Error decodificando transacción válida: %v", err)
+		}
+		if tx.Nonce != 3 || tx.GasLimit != 21000 || len(tx.Signature) != 65 || tx.Signature[64] != 27 || len(tx.Data) != 2 {
+			t.Errorf("Transacción decodificada incorrectamente: %+v", tx)
+		}
+	}
+}
+
+// TestDecodeSubmitTx_FieldErrors prueba los errores por campo y el código del primer campo inválido
+func TestDecodeSubmitTx_FieldErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		code   uint32
+		fields []string
+	}{
+		{"sin campos requeridos", `{"to": ""}`, consensus.CodeInvalidHash, []string{"hash", "from", "signature"}},
+		{"hex inválido", `{"hash": "0x12", "from": "1234567890123456789012345678901234567890", "signature": "0x00"}`, consensus.CodeInvalidHash, []string{"hash", "from", "signature"}},
+		{"valor numérico", strings.Replace(validSubmitTx("0x"+strings.Repeat("00", 65)), `"1000000000000000000"`, `1000`, 1), consensus.CodeInvalidTx, []string{"value"}},
+		{"valor negativo", strings.Replace(validSubmitTx("0x"+strings.Repeat("00", 65)), `"1000000000"`, `"-1"`, 1), consensus.CodeInvalidTx, []string{"gasPrice"}},
+		{"nonce como string", strings.Replace(validSubmitTx("0x"+strings.Repeat("00", 65)), `"nonce": 3`, `"nonce": "3"`, 1), consensus.CodeInvalidTx, []string{"nonce"}},
+		{"access list inválida", strings.Replace(validSubmitTx("0x"+strings.Repeat("00", 65)), `"nonce": 3`, `"nonce": 3, "accessList": [{"address": "0x12", "storageKeys": ["0x01"]}]`, 1), consensus.CodeInvalidTx, []string{"accessList[0].address", "accessList[0].storageKeys[0]"}},
+		{"access list con campo desconocido", strings.Replace(validSubmitTx("0x"+strings.Repeat("00", 65)), `"nonce": 3`, `"nonce": 3, "accessList": [{"addr": "0x12"}]`, 1), consensus.CodeInvalidTx, []string{"accessList"}},
+		{"campo desconocido", strings.Replace(validSubmitTx("0x"+strings.Repeat("00", 65)), `"nonce": 3`, `"nonce": 3, "Nonce": 4, "chainId": 1`, 1), consensus.CodeInvalidTx, []string{"Nonce", "chainId"}},
+	}
+	for _, c := range cases {
+		_, err := decodeSubmitTx(strings.NewReader(c.body), 0)
+		var schemaErr *TxSchemaError
+		if !errors.As(err, &schemaErr) {
+			t.Errorf("%s: se esperaba un error de esquema, obtenido %v", c.name, err)
+			continue
+		}
+		if code := consensus.ErrorCode(err, 0); code != c.code {
+			t.Errorf("%s: código %d, esperado %d", c.name, code, c.code)
+		}
+		var fields []string
+		for _, field := range schemaErr.Fields {
+			fields = append(fields, field.Field)
+		}
+		if strings.Join(fields, ",") != strings.Join(c.fields, ",") {
+			t.Errorf("%s: campos %v, esperado %v", c.name, fields, c.fields)
+		}
+	}
+
+	// data acotada
+	body := strings.Replace(validSubmitTx("0x"+strings.Repeat("00", 65)), `"0x6001"`, `"0x`+strings.Repeat("00", 11)+`"`, 1)
+	if _, err := decodeSubmitTx(strings.NewReader(body), 10); err == nil || !strings.Contains(err.Error(), "exceeds 10 bytes") {
+		t.Errorf("data mayor al límite debería rechazarse: %v", err)
+	}
+
+	// JSON inválido o con datos extra es un error de decodificación
+	for _, body := range []string{`[]`, `null`, `{"hash": `, `{} {}`} {
+		if code := consensus.ErrorCode(func() error { _, err := decodeSubmitTx(strings.NewReader(body), 0); return err }(), 0); code != consensus.CodeDecodeError {
+			t.Errorf("Body %q: código %d, esperado %d", body, code, consensus.CodeDecodeError)
+		}
+	}
+}
+
+// TestDecodeSubmitTx_AccessList prueba que la access list (EIP-2930) se decodifique con la transacción
+func TestDecodeSubmitTx_AccessList(t *testing.T) {
+	key := "0x" + strings.Repeat("01", 32)
+	body := strings.Replace(validSubmitTx("0x"+strings.Repeat("00", 65)), `"nonce": 3`,
+		`"nonce": 3, "accessList": [{"address": "0x1111111111111111111111111111111111111111", "storageKeys": ["`+key+`"]}]`, 1)
+	tx, err := decodeSubmitTx(strings.NewReader(body), 0)
+	if err != nil {
+		t.Fatalf("Error decodificando transacción con access list: %v", err)
+	}
+	if len(tx.AccessList) != 1 || tx.AccessList[0].Address != "0x1111111111111111111111111111111111111111" || len(tx.AccessList[0].StorageKeys) != 1 || tx.AccessList[0].StorageKeys[0] != key {
+		t.Errorf("Access list decodificada incorrectamente: %+v", tx.AccessList)
+	}
+}
+
+// TestDecodeCallTx prueba los valores por defecto y los límites de una transacción a simular
+func TestDecodeCallTx(t *testing.T) {
+	tx, err := decodeCallTx(strings.NewReader(`{"from": "0x1234567890123456789012345678901234567890", "to": "0x0987654321098765432109876543210987654321", "data": "0x6001"}`), 0)
+	if err != nil {
+		t.Fatalf("Error decodificando transacción a simular: %v", err)
+	}
+	if tx.Value != "0" || tx.GasPrice != "0" || tx.GasLimit != defaultCallGasLimit || len(tx.Data) != 2 {
+		t.Errorf("Valores por defecto incorrectos: %+v", tx)
+	}
+
+	_, err = decodeCallTx(strings.NewReader(`{"to": "0x12", "gasLimit": 20000000, "hash": "0x00"}`), 0)
+	var schemaErr *TxSchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Se esperaba un error de esquema, obtenido %v", err)
+	}
+	var fields []string
+	for _, field := range schemaErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	if strings.Join(fields, ",") != "from,to,gasLimit,hash" {
+		t.Errorf("Campos inválidos incorrectos: %v", fields)
+	}
+}
+
+// TestDecodeSubmitTx_Multisig prueba que una transacción multisig no requiera signature y que sus firmas se
+// validen por campo; una propuesta acepta hash y from vacíos
+func TestDecodeSubmitTx_Multisig(t *testing.T) {
+	multisig := `"multisig": {"threshold": 1, "owners": ["0x0987654321098765432109876543210987654321"], "signatures": [{"signer": "0x0987654321098765432109876543210987654321", "signature": "0x` + strings.Repeat("00", 65) + `"}]}`
+	body := strings.Replace(validSubmitTx(""), `"signature": ""`, multisig, 1)
+	tx, err := decodeSubmitTx(strings.NewReader(body), 0)
+	if err != nil {
+		t.Fatalf("Error decodificando transacción multisig: %v", err)
+	}
+	if tx.Multisig == nil || tx.Multisig.Threshold != 1 || len(tx.Multisig.Signatures) != 1 || len(tx.Multisig.Signatures[0].Signature) != 65 {
+		t.Errorf("Multisig decodificada incorrectamente: %+v", tx.Multisig)
+	}
+
+	withSignature := strings.Replace(validSubmitTx("0x"+strings.Repeat("00", 65)), `"nonce": 3`, `"nonce": 3, `+multisig, 1)
+	var schemaErr *TxSchemaError
+	if _, err := decodeSubmitTx(strings.NewReader(withSignature), 0); !errors.As(err, &schemaErr) || schemaErr.Fields[0].Field != "signature" {
+		t.Errorf("Se esperaba un error en signature: %v", err)
+	}
+	invalid := strings.Replace(body, `"threshold": 1`, `"threshold": 0`, 1)
+	if _, err := decodeSubmitTx(strings.NewReader(invalid), 0); !errors.As(err, &schemaErr) || schemaErr.Fields[0].Field != "multisig.threshold" {
+		t.Errorf("Se esperaba un error en multisig.threshold: %v", err)
+	}
+
+	proposal := `{"to": "0x0987654321098765432109876543210987654321", "value": "1", "gasLimit": 21000, "gasPrice": "1", "nonce": 0, ` + multisig + `}`
+	if _, err := decodeMultisigProposal(strings.NewReader(proposal), 0); err != nil {
+		t.Errorf("Error decodificando propuesta: %v", err)
+	}
+	if _, err := decodeMultisigProposal(strings.NewReader(`{"to": "0x0987654321098765432109876543210987654321"}`), 0); !errors.As(err, &schemaErr) || schemaErr.Fields[0].Field != "multisig" {
+		t.Errorf("Se esperaba un error por multisig faltante: %v", err)
+	}
+}
+
+// TestDecodeSubmitTx_FeePayer prueba el fee payer de una transacción patrocinada
+func TestDecodeSubmitTx_FeePayer(t *testing.T) {
+	signature := "0x" + strings.Repeat("00", 65)
+	feePayer := `"feePayer": {"address": "0x0987654321098765432109876543210987654321", "signature": "` + signature + `"}`
+	tx, err := decodeSubmitTx(strings.NewReader(strings.Replace(validSubmitTx(signature), `"nonce": 3`, `"nonce": 3, `+feePayer, 1)), 0)
+	if err != nil {
+		t.Fatalf("Error decodificando transacción patrocinada: %v", err)
+	}
+	if tx.FeePayer == nil || tx.FeePayer.Address != "0x0987654321098765432109876543210987654321" || len(tx.FeePayer.Signature) != 65 {
+		t.Errorf("Fee payer decodificado incorrectamente: %+v", tx.FeePayer)
+	}
+
+	var schemaErr *TxSchemaError
+	invalid := strings.Replace(validSubmitTx(signature), `"nonce": 3`, `"nonce": 3, "feePayer": {"address": "0x12"}`, 1)
+	if _, err := decodeSubmitTx(strings.NewReader(invalid), 0); !errors.As(err, &schemaErr) || len(schemaErr.Fields) != 2 ||
+		schemaErr.Fields[0].Field != "feePayer.address" || schemaErr.Fields[1].Field != "feePayer.signature" {
+		t.Errorf("Se esperaban errores en feePayer.address y feePayer.signature: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// VestingResponse es el vesting de una cuenta en /api/v1/vesting/{address} (montos en wei)
+type VestingResponse struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+	Start   int64  `json:"start"`
+	Cliff   int64  `json:"cliff"`
+	End     int64  `json:"end"`
+	Locked  string `json:"locked"` // Bloqueado a la hora del último bloque (incluye lo que esté en stake)
+}
+
+// handleVesting maneja GET /api/v1/vesting/{address}
+// Retorna el calendario de vesting de la cuenta y el monto bloqueado (404 si no tiene vesting)
+func (s *RestServer) handleVesting(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.executor == nil {
+		http.Error(w, "EVM executor not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	address := strings.TrimPrefix(r.URL.Path, "/api/v1/vesting/")
+	if !common.IsHexAddress(address) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := s.executor.GetCommittedVestingSchedule(address)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if schedule == nil {
+		http.Error(w, "Account has no vesting", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(VestingResponse{
+		Address: common.HexToAddress(address).Hex(),
+		Amount:  schedule.Amount.String(),
+		Start:   schedule.Start,
+		Cliff:   schedule.Cliff,
+		End:     schedule.End,
+		Locked:  schedule.Locked(s.executor.CurrentBlockInfo().Timestamp).String(),
+	})
+}
@@ -0,0 +1,136 @@
+// Package assets es el registro de metadatos de los activos de la cadena (OXG nativo y tokens bridged) que
+// usan las wallets para mostrar los montos con sus decimales y símbolo
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NativeDenom es el denom de OXG, la moneda nativa (balances y gas en wei, 18 decimales)
+const NativeDenom = "oxg"
+
+// maxDecimals es la cantidad máxima de decimales de un activo (como uint8 de ERC-20)
+const maxDecimals = 36
+
+// denomPattern son los denom válidos: minúsculas, dígitos y "/", "-", "." (p. ej. "bridge/usdc")
+var denomPattern = regexp.MustCompile(`^[a-z][a-z0-9/.\-]{1,63}$`)
+
+// Asset son los metadatos de un activo
+type Asset struct {
+	Denom       string `json:"denom"`
+	Symbol      string `json:"symbol"`
+	DisplayName string `json:"displayName"`
+	Decimals    uint8  `json:"decimals"`
+	Native      bool   `json:"native"`
+	Contract    string `json:"contract,omitempty"`    // Token bridged: dirección del contrato en esta cadena
+	OriginChain string `json:"originChain,omitempty"` // Token bridged: cadena de origen
+}
+
+// Native retorna los metadatos de OXG
+func Native() Asset {
+	return Asset{Denom: NativeDenom, Symbol: "OXG", DisplayName: "Oxy•gen", Decimals: 18, Native: true}
+}
+
+// Validate verifica el denom, el símbolo, los decimales y el contrato de un activo
+func (a *Asset) Validate() error {
+	if !denomPattern.MatchString(a.Denom) {
+		return fmt.Errorf("denom inválido: %q", a.Denom)
+	}
+	if a.Symbol == "" || len(a.Symbol) > 16 {
+		return fmt.Errorf("símbolo inválido para %s: %q", a.Denom, a.Symbol)
+	}
+	if a.Decimals > maxDecimals {
+		return fmt.Errorf("decimales inválidos para %s: %d", a.Denom, a.Decimals)
+	}
+	if a.Contract != "" && !common.IsHexAddress(a.Contract) {
+		return fmt.Errorf("contrato inválido para %s: %s", a.Denom, a.Contract)
+	}
+	return nil
+}
+
+// Registry es el registro de activos del nodo; siempre incluye OXG
+type Registry struct {
+	mu     sync.RWMutex
+	assets map[string]Asset
+}
+
+// NewRegistry crea un registro con OXG
+func NewRegistry() *Registry {
+	native := Native()
+	return &Registry{assets: map[string]Asset{native.Denom: native}}
+}
+
+// Register agrega o reemplaza un activo bridged (OXG no se puede reemplazar)
+func (r *Registry) Register(asset Asset) error {
+	asset.Denom = strings.TrimSpace(asset.Denom)
+	if err := asset.Validate(); err != nil {
+		return err
+	}
+	if asset.Denom == NativeDenom || asset.Native {
+		return fmt.Errorf("el activo nativo %s no se puede registrar", NativeDenom)
+	}
+	if asset.Contract != "" {
+		asset.Contract = common.HexToAddress(asset.Contract).Hex()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for denom, existing := range r.assets {
+		if denom != asset.Denom && asset.Contract != "" && strings.EqualFold(existing.Contract, asset.Contract) {
+			return fmt.Errorf("el contrato %s ya está registrado como %s", asset.Contract, denom)
+		}
+	}
+	r.assets[asset.Denom] = asset
+	return nil
+}
+
+// LoadFile registra los activos de un archivo JSON con una lista de activos
+func (r *Registry) LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("error leyendo activos: %w", err)
+	}
+	var list []Asset
+	if err := json.Unmarshal(data, &list); err != nil {
+		return 0, fmt.Errorf("archivo de activos inválido: %w", err)
+	}
+	for _, asset := range list {
+		if err := r.Register(asset); err != nil {
+			return 0, err
+		}
+	}
+	return len(list), nil
+}
+
+// Get retorna un activo por denom
+func (r *Registry) Get(denom string) (Asset, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	asset, ok := r.assets[denom]
+	return asset, ok
+}
+
+// List retorna los activos: OXG primero y luego por denom
+func (r *Registry) List() []Asset {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Asset, 0, len(r.assets))
+	for _, asset := range r.assets {
+		list = append(list, asset)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Native != list[j].Native {
+			return list[i].Native
+		}
+		return list[i].Denom < list[j].Denom
+	})
+	return list
+}
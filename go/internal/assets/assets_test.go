@@ -0,0 +1,47 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRegistry prueba el activo nativo, la validación y la carga desde archivo
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	if native, ok := registry.Get(NativeDenom); !ok || native.Decimals != 18 || !native.Native {
+		t.Fatalf("OXG debería estar registrado con 18 decimales: %+v", native)
+	}
+
+	invalid := []Asset{
+		{Denom: NativeDenom, Symbol: "OXG", Decimals: 6},
+		{Denom: "bridge/fake", Symbol: "FAKE", Native: true},
+		{Denom: "Bridge/USDC", Symbol: "USDC", Decimals: 6},
+		{Denom: "bridge/usdc", Symbol: "", Decimals: 6},
+		{Denom: "bridge/usdc", Symbol: "USDC", Decimals: 77},
+		{Denom: "bridge/usdc", Symbol: "USDC", Decimals: 6, Contract: "0x1234"},
+	}
+	for _, asset := range invalid {
+		if err := registry.Register(asset); err == nil {
+			t.Errorf("Activo inválido aceptado: %+v", asset)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "assets.json")
+	list := `[{"denom":"bridge/usdc","symbol":"USDC","displayName":"USD Coin","decimals":6,` +
+		`"contract":"0x00000000000000000000000000000000000000aa","originChain":"ethereum"}]`
+	if err := os.WriteFile(path, []byte(list), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := registry.LoadFile(path); err != nil || n != 1 {
+		t.Fatalf("Error cargando activos: %d %v", n, err)
+	}
+	if err := registry.Register(Asset{Denom: "bridge/usdc2", Symbol: "USDC", Contract: "0x00000000000000000000000000000000000000AA"}); err == nil {
+		t.Error("Un contrato ya registrado con otro denom debería rechazarse")
+	}
+
+	assets := registry.List()
+	if len(assets) != 2 || assets[0].Denom != NativeDenom || assets[1].Decimals != 6 || assets[1].Contract != "0x00000000000000000000000000000000000000AA" {
+		t.Errorf("Lista de activos incorrecta: %+v", assets)
+	}
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config contiene toda la configuración del nodo blockchain
@@ -18,14 +22,40 @@ type Config struct {
 	ValidatorKey  string
 
 	// Configuración de red mesh
-	MeshEndpoint string
+	MeshEndpoint            string
+	MeshReconnectMaxBackoff time.Duration
+	MeshOutboxLimit         int
+	MeshOutboxTTL           time.Duration // Tiempo máximo en la cola persistente de mensajes relevantes para el consenso
+	MeshRequireSigned       bool
+	MeshAllowedPeers        string // Formato: "0xaddr1,0xaddr2" (vacío acepta cualquier peer con firma válida)
+	MeshEncoding            string // "proto" (frames binarios) o "json"
+	MeshCompression         string // "snappy", "gzip" o "none"
+	MeshBanDuration         time.Duration
+	MeshPeerMessageRate     int // Mensajes por segundo aceptados por peer antes de contarlo como spam
 
 	// Configuración de peers P2P (CometBFT)
 	PersistentPeers string // Formato: "nodeid@host:port,nodeid2@host2:port2"
 	Seeds           string // Formato: "nodeid@host:port,nodeid2@host2:port2"
+	NodeRole        string // "seed", "sentry", "validator", "full" o "replica"
+	PrivatePeerIDs  string // IDs de los validadores protegidos por un sentry: "nodeid,nodeid2"
+
+	// Descubrimiento de peers por la mesh: cada nodo anuncia un descriptor firmado con su dirección P2P
+	P2PExternalAddr   string // "host:puerto" P2P anunciado a otros nodos (vacío = OXY_P2P_LADDR si no es 0.0.0.0)
+	RESTPublicURL     string // URL pública del API REST incluida en el descriptor (vacía = no se anuncia)
+	PeerDiscoveryAuto bool   // Agregar como peers persistentes los nodos descubiertos (nunca en validator ni seed)
+
+	// Seeds de respaldo por chain ID usados si no hay OXY_SEEDS: "chain=id@host:puerto,...;chain2=..."
+	FallbackSeeds string
 
 	// Configuración de logging
-	LogLevel string
+	LogLevel     string
+	LogModules   string // Filtros por módulo: "consensus=debug,api=warn"
+	LogLevelFile string // Archivo con niveles ("info,consensus=debug") que se relee con SIGHUP
+
+	// Tracing OpenTelemetry (export OTLP/HTTP); endpoint vacío = deshabilitado
+	TracingEndpoint    string
+	TracingServiceName string
+	TracingSampleRatio float64 // Fracción de trazas nuevas muestreadas (0..1)
 
 	// Configuración de CometBFT
 	CometBFTHome string
@@ -33,31 +63,200 @@ type Config struct {
 	// Configuración de EVMone
 	EVMoneTrace bool
 
+	// Profiling de gas por categoría de opcode y precompile (/api/v1/admin/profile/gas)
+	GasProfiling bool
+
+	// Adelanto máximo de la hora de una propuesta sobre el reloj local (ProcessProposal)
+	MaxBlockTimeDrift time.Duration
+
+	// Configuración de rate limiting del mempool
+	RateLimitPerAddress int
+	RateLimitWindow     time.Duration
+	MempoolSizeLimit    int
+
+	// Límites anti-spam por transacción en CheckTx y en las propuestas (0 = sin límite)
+	MinGasPrice   string // Precio de gas mínimo en wei
+	MaxTxDataSize int    // Bytes máximos de data
+	MaxTxGasLimit uint64 // Gas límite máximo
+
+	// Módulo de compliance: "blacklist", "allowlist" o vacío (deshabilitado)
+	ComplianceMode      string
+	ComplianceAddresses string // Direcciones agregadas a la lista al iniciar: "0xaddr1,0xaddr2"
+
+	// Archivo JSON con los metadatos de los tokens bridged de /api/v1/assets (OXG siempre está incluido)
+	AssetsFile string
+
+	// Espera máxima de /api/v1/submit-tx?mode=commit
+	BroadcastCommitTimeout time.Duration
+
+	// Configuración del indexador de transacciones y RPC de CometBFT
+	TxIndexer     string
+	RPCListenAddr string
+	P2PListenAddr string // Dirección P2P de CometBFT (vacío = tcp://0.0.0.0:26656)
+
+	// Monitor de sincronización (readiness)
+	SyncCheckInterval        time.Duration
+	ReadinessMaxBlocksBehind int64
+
+	// Snapshots de estado (state sync y export/restore fuera de banda)
+	SnapshotInterval     uint64 // Cada cuántos bloques se genera un snapshot (0 = deshabilitado)
+	SnapshotKeepRecent   int    // Snapshots que se conservan (0 = todos)
+	StateSyncRPCServers  string // RPC de CometBFT para verificar snapshots con light client: "host:port,host2:port2"
+	StateSyncTrustHeight int64
+	StateSyncTrustHash   string
+
+	// Watchlist: notificaciones por dirección vía webhook o WebSocket
+	WatchlistMaxSubscriptions int
+	WatchlistMaxAddresses     int           // Direcciones por suscripción
+	WebhookMaxAttempts        int           // Intentos de entrega por notificación
+	WebhookMaxBackoff         time.Duration // Espera máxima entre reintentos (backoff exponencial desde 1s)
+
+	// Publicación de eventos (bloques, transacciones y logs confirmados) en un broker
+	EventSink            string // "nats", "kafka" o vacío (deshabilitado)
+	EventSinkURL         string // nats://host:4222 o URL del REST Proxy de Kafka
+	EventSinkFormat      string // json o protobuf
+	EventSinkTopicPrefix string
+	EventSinkJetStream   bool   // NATS: esperar el ack de JetStream de cada mensaje
+	EventSinkStartHeight uint64 // Primera altura en la primera publicación (0 = desde el próximo bloque)
+
+	// Cache en Redis de las lecturas frecuentes del API, compartido entre nodos (último bloque, validadores, cuentas)
+	RedisURL     string        // redis://[usuario:contraseña@]host:6379/db o vacío (deshabilitado)
+	RedisPrefix  string        // Prefijo de las claves (se le agrega el chain ID)
+	RedisTTL     time.Duration // Expiración de las respuestas de alturas anteriores
+	RedisTimeout time.Duration // Límite de cada operación; al vencer la lectura va al storage
+
+	// Validadores
+	MinStake              string // Stake mínimo para ser validador, en OXG enteros (ver ParseMinStake)
+	ValidatorUptimeWindow int    // Bloques sobre los que se calcula el uptime de cada validador
+	BlockReward           string // Recompensa por bloque en wei, repartida entre los firmantes ("0" = sin recompensas)
+
+	// Cada cuántos bloques se verifican las invariantes contables en segundo plano (0 = deshabilitado)
+	InvariantCheckInterval uint64
+	// Detener la cadena (sin matar el proceso) si la auditoría encuentra invariantes incumplidas
+	HaltOnInvariantViolation bool
+
+	// Cache del estado confirmado (cuentas y slots de storage) para el API y CheckTx (0 = sin cache)
+	StateCacheAccounts int
+	StateCacheSlots    int
+
+	// Commits de estado que pueden esperar su escritura en disco en segundo plano (0 = escribir en cada commit)
+	StateCommitQueue int
+
+	// Memoria de la base de datos del estado EVM, en MB (por defecto según NodeRole, ver stateDBDefaults)
+	StateTrieCacheMB     int // Nodos del trie en memoria (0 = sin cache)
+	StateDBCacheMB       int // Cache de Pebble; la mitad se usa para memtables (mínimo 16)
+	StateDBHandles       int // Archivos abiertos por Pebble (mínimo 16)
+	StateSnapshotCacheMB int
+
 	// Configuración del API REST
 	APIEnabled bool
 	APIPort    string
 	APIHost    string
+
+	// API de Rosetta (Data y Construction) para integraciones de exchanges; escucha en APIHost
+	RosettaEnabled bool
+	RosettaPort    string
+
+	// Variables de entorno con valores inválidos (se reportan en Validate en lugar de ignorarse)
+	envErrors []error
 }
 
+// envErrors acumula los valores inválidos encontrados durante LoadConfig
+var envErrors []error
+
 // LoadConfig carga la configuración desde variables de entorno
 func LoadConfig() *Config {
 	dataDir := getEnv("OXY_DATA_DIR", "./data")
+	nodeRole := getNodeRole()
+	stateDB := stateDBDefaults(nodeRole)
 	
-	return &Config{
+	cfg := &Config{
 		DataDir:        dataDir,
 		ChainID:        getEnv("OXY_CHAIN_ID", "oxy-gen-chain"),
 		ValidatorAddr:  getEnv("OXY_VALIDATOR_ADDR", ""),
 		ValidatorKey:   getEnv("OXY_VALIDATOR_KEY", ""),
 		MeshEndpoint:    getEnv("OXY_MESH_ENDPOINT", "ws://localhost:3001"),
+		MeshReconnectMaxBackoff: time.Duration(getEnvInt("OXY_MESH_RECONNECT_MAX_BACKOFF_MS", 60000)) * time.Millisecond,
+		MeshOutboxLimit:         getEnvInt("OXY_MESH_OUTBOX_LIMIT", 1000),
+		MeshOutboxTTL:           time.Duration(getEnvInt("OXY_MESH_OUTBOX_TTL_MS", 600000)) * time.Millisecond,
+		MeshRequireSigned:       getEnvBool("OXY_MESH_REQUIRE_SIGNED", true),
+		MeshAllowedPeers:        getEnv("OXY_MESH_ALLOWED_PEERS", ""),
+		MeshEncoding:            getEnv("OXY_MESH_ENCODING", "proto"),
+		MeshCompression:         getEnv("OXY_MESH_COMPRESSION", "snappy"),
+		MeshBanDuration:         time.Duration(getEnvInt("OXY_MESH_BAN_DURATION_MS", 600000)) * time.Millisecond,
+		MeshPeerMessageRate:     getEnvInt("OXY_MESH_PEER_MESSAGE_RATE", 100),
 		PersistentPeers: getEnv("OXY_PERSISTENT_PEERS", ""),
 		Seeds:           getEnv("OXY_SEEDS", ""),
+		NodeRole:        nodeRole,
+		PrivatePeerIDs:  getEnv("OXY_PRIVATE_PEER_IDS", ""),
+		P2PExternalAddr:   getEnv("OXY_P2P_EXTERNAL_ADDR", ""),
+		RESTPublicURL:     getEnv("OXY_REST_PUBLIC_URL", ""),
+		PeerDiscoveryAuto: getEnvBool("OXY_PEER_DISCOVERY_AUTO", true),
+		FallbackSeeds:     getEnv("OXY_FALLBACK_SEEDS", ""),
 		LogLevel:        getEnv("OXY_LOG_LEVEL", "info"),
+		LogModules:      getEnv("OXY_LOG_MODULES", ""),
+		LogLevelFile:    getEnv("OXY_LOG_LEVEL_FILE", ""),
+		TracingEndpoint:    getEnv("OXY_OTLP_ENDPOINT", ""),
+		TracingServiceName: getEnv("OXY_TRACING_SERVICE_NAME", "oxy-blockchain"),
+		TracingSampleRatio: getEnvFloat("OXY_TRACING_SAMPLE_RATIO", 1.0),
 		CometBFTHome:   getEnv("COMETBFT_HOME", filepath.Join(dataDir, "cometbft")),
 		EVMoneTrace:    getEnvBool("EVMONE_TRACE", false),
+		GasProfiling:   getEnvBool("OXY_GAS_PROFILING", false),
+		MaxBlockTimeDrift: time.Duration(getEnvInt("OXY_MAX_BLOCK_TIME_DRIFT_MS", 30000)) * time.Millisecond,
+		RateLimitPerAddress: getEnvInt("OXY_RATE_LIMIT_PER_ADDRESS", 10),
+		RateLimitWindow:     time.Duration(getEnvInt("OXY_RATE_LIMIT_WINDOW_MS", 1000)) * time.Millisecond,
+		MempoolSizeLimit:    getEnvInt("OXY_MEMPOOL_SIZE_LIMIT", 10000),
+		MinGasPrice:         getEnv("OXY_MIN_GAS_PRICE", "1"),
+		MaxTxDataSize:       getEnvInt("OXY_MAX_TX_DATA_SIZE", 128*1024),
+		MaxTxGasLimit:       getEnvUint64("OXY_MAX_TX_GAS_LIMIT", 30000000),
+		ComplianceMode:      getEnv("OXY_COMPLIANCE_MODE", ""),
+		ComplianceAddresses: getEnv("OXY_COMPLIANCE_ADDRESSES", ""),
+		AssetsFile:          getEnv("OXY_ASSETS_FILE", ""),
+		BroadcastCommitTimeout: time.Duration(getEnvInt("OXY_BROADCAST_COMMIT_TIMEOUT_MS", 10000)) * time.Millisecond,
+		TxIndexer:           getEnv("OXY_TX_INDEXER", "kv"),
+		RPCListenAddr:       getEnv("OXY_RPC_LADDR", "tcp://127.0.0.1:26657"),
+		P2PListenAddr:       getEnv("OXY_P2P_LADDR", ""),
+		SyncCheckInterval:        time.Duration(getEnvInt("OXY_SYNC_CHECK_INTERVAL_MS", 5000)) * time.Millisecond,
+		ReadinessMaxBlocksBehind: int64(getEnvInt("OXY_READINESS_MAX_BLOCKS_BEHIND", 5)),
+		SnapshotInterval:         getEnvUint64("OXY_SNAPSHOT_INTERVAL", 0),
+		SnapshotKeepRecent:       getEnvInt("OXY_SNAPSHOT_KEEP_RECENT", 2),
+		StateSyncRPCServers:      getEnv("OXY_STATESYNC_RPC_SERVERS", ""),
+		StateSyncTrustHeight:     int64(getEnvInt("OXY_STATESYNC_TRUST_HEIGHT", 0)),
+		StateSyncTrustHash:       getEnv("OXY_STATESYNC_TRUST_HASH", ""),
+		WatchlistMaxSubscriptions: getEnvInt("OXY_WATCHLIST_MAX_SUBSCRIPTIONS", 100),
+		WatchlistMaxAddresses:     getEnvInt("OXY_WATCHLIST_MAX_ADDRESSES", 1000),
+		WebhookMaxAttempts:        getEnvInt("OXY_WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookMaxBackoff:         time.Duration(getEnvInt("OXY_WEBHOOK_MAX_BACKOFF_MS", 60000)) * time.Millisecond,
+		EventSink:                 getEnv("OXY_EVENTS_SINK", ""),
+		EventSinkURL:              getEnv("OXY_EVENTS_URL", ""),
+		EventSinkFormat:           getEnv("OXY_EVENTS_FORMAT", "json"),
+		EventSinkTopicPrefix:      getEnv("OXY_EVENTS_TOPIC_PREFIX", "oxy"),
+		EventSinkJetStream:        getEnvBool("OXY_EVENTS_NATS_JETSTREAM", false),
+		EventSinkStartHeight:      getEnvUint64("OXY_EVENTS_START_HEIGHT", 0),
+		RedisURL:                  getEnv("OXY_REDIS_URL", ""),
+		RedisPrefix:               getEnv("OXY_REDIS_PREFIX", "oxy"),
+		RedisTTL:                  time.Duration(getEnvInt("OXY_REDIS_TTL_MS", 30000)) * time.Millisecond,
+		RedisTimeout:              time.Duration(getEnvInt("OXY_REDIS_TIMEOUT_MS", 100)) * time.Millisecond,
+		MinStake:                  getEnv("OXY_MIN_STAKE", DefaultMinStake),
+		ValidatorUptimeWindow:     getEnvInt("OXY_VALIDATOR_UPTIME_WINDOW", 1000),
+		BlockReward:               getEnv("OXY_BLOCK_REWARD", "0"),
+		InvariantCheckInterval:    getEnvUint64("OXY_INVARIANT_CHECK_INTERVAL", 1000),
+		HaltOnInvariantViolation:  getEnvBool("OXY_HALT_ON_INVARIANT_VIOLATION", true),
+		StateCacheAccounts:        getEnvInt("OXY_STATE_CACHE_ACCOUNTS", 10000),
+		StateCacheSlots:           getEnvInt("OXY_STATE_CACHE_SLOTS", 100000),
+		StateCommitQueue:          getEnvInt("OXY_STATE_COMMIT_QUEUE", 4),
+		StateTrieCacheMB:          getEnvInt("OXY_STATE_TRIE_CACHE_MB", stateDB.trieCacheMB),
+		StateDBCacheMB:            getEnvInt("OXY_STATE_DB_CACHE_MB", stateDB.dbCacheMB),
+		StateDBHandles:            getEnvInt("OXY_STATE_DB_HANDLES", stateDB.dbHandles),
+		StateSnapshotCacheMB:      getEnvInt("OXY_STATE_SNAPSHOT_CACHE_MB", stateDB.snapshotCacheMB),
 		APIEnabled:     getEnvBool("BLOCKCHAIN_API_ENABLED", true),
 		APIPort:         getEnv("BLOCKCHAIN_API_PORT", "8080"),
 		APIHost:         getEnv("BLOCKCHAIN_API_HOST", "localhost"),
+		RosettaEnabled:  getEnvBool("OXY_ROSETTA_ENABLED", false),
+		RosettaPort:     getEnv("OXY_ROSETTA_PORT", "8090"),
 	}
+	cfg.envErrors, envErrors = envErrors, nil
+	return cfg
 }
 
 // getEnv obtiene una variable de entorno o retorna el valor por defecto
@@ -68,11 +267,89 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getNodeRole obtiene el rol del nodo; OXY_SEED_MODE=true se mantiene como alias de OXY_NODE_ROLE=seed
+func getNodeRole() string {
+	if role := getEnv("OXY_NODE_ROLE", ""); role != "" {
+		return role
+	}
+	if getEnvBool("OXY_SEED_MODE", false) {
+		return "seed"
+	}
+	return "full"
+}
+
+// stateDBSizes son los tamaños de memoria de la base de datos del estado EVM
+type stateDBSizes struct {
+	trieCacheMB     int
+	dbCacheMB       int
+	dbHandles       int
+	snapshotCacheMB int
+}
+
+// stateDBDefaults retorna los tamaños por defecto según el rol: el validador prioriza el throughput de
+// bloques, el sentry, el full node y la réplica reparten memoria con las consultas, y el seed no sigue la cadena
+func stateDBDefaults(role string) stateDBSizes {
+	switch strings.ToLower(strings.TrimSpace(role)) {
+	case "validator":
+		return stateDBSizes{trieCacheMB: 256, dbCacheMB: 512, dbHandles: 1024, snapshotCacheMB: 256}
+	case "sentry":
+		return stateDBSizes{trieCacheMB: 64, dbCacheMB: 128, dbHandles: 256, snapshotCacheMB: 64}
+	case "seed":
+		return stateDBSizes{trieCacheMB: 0, dbCacheMB: 16, dbHandles: 64, snapshotCacheMB: 16}
+	default:
+		return stateDBSizes{trieCacheMB: 128, dbCacheMB: 256, dbHandles: 512, snapshotCacheMB: 256}
+	}
+}
+
 // getEnvBool obtiene una variable de entorno booleana
 func getEnvBool(key string, defaultValue bool) bool {
+	switch value := os.Getenv(key); value {
+	case "":
+		return defaultValue
+	case "true", "1", "yes":
+		return true
+	case "false", "0", "no":
+		return false
+	default:
+		invalidEnv(key, value, "true o false")
+		return defaultValue
+	}
+}
+
+// getEnvUint64 obtiene una variable de entorno numérica sin signo
+func getEnvUint64(key string, defaultValue uint64) uint64 {
 	if value := os.Getenv(key); value != "" {
-		return value == "true" || value == "1" || value == "yes"
+		if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return n
+		}
+		invalidEnv(key, value, "entero sin signo")
 	}
 	return defaultValue
 }
 
+// getEnvFloat obtiene una variable de entorno decimal
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+		invalidEnv(key, value, "número decimal")
+	}
+	return defaultValue
+}
+
+// getEnvInt obtiene una variable de entorno entera
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+		invalidEnv(key, value, "entero")
+	}
+	return defaultValue
+}
+
+// invalidEnv registra una variable de entorno con un valor que no se puede interpretar
+func invalidEnv(key, value, expected string) {
+	envErrors = append(envErrors, fmt.Errorf("%s: %q inválido (se esperaba %s)", key, value, expected))
+}
@@ -0,0 +1,20 @@
+package config
+
+import "testing"
+
+// TestLoadConfig_StateDBDefaults prueba que la memoria del estado dependa del rol y que las variables la
+// reemplacen
+func TestLoadConfig_StateDBDefaults(t *testing.T) {
+	t.Setenv("OXY_NODE_ROLE", "validator")
+	cfg := LoadConfig()
+	if cfg.StateTrieCacheMB != 256 || cfg.StateDBCacheMB != 512 || cfg.StateDBHandles != 1024 || cfg.StateSnapshotCacheMB != 256 {
+		t.Errorf("Valores de validador incorrectos: %+v", cfg)
+	}
+
+	t.Setenv("OXY_NODE_ROLE", "seed")
+	t.Setenv("OXY_STATE_TRIE_CACHE_MB", "32")
+	cfg = LoadConfig()
+	if cfg.StateTrieCacheMB != 32 || cfg.StateDBCacheMB != 16 || cfg.StateSnapshotCacheMB != 16 {
+		t.Errorf("Valores de seed incorrectos: %+v", cfg)
+	}
+}
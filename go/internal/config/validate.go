@@ -0,0 +1,282 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultMinStake es el stake mínimo de validador por defecto, en OXG (testnet; 1000 OXG en producción)
+const DefaultMinStake = "10"
+
+// maxChainIDLength es el largo máximo del chain ID que acepta CometBFT en el genesis
+const maxChainIDLength = 50
+
+// chainIDPattern son los caracteres aceptados en el chain ID (también es parte de los identificadores de Rosetta)
+var chainIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Valores aceptados de las opciones enumeradas
+var (
+	nodeRoles         = []string{"full", "seed", "sentry", "validator", "replica"}
+	meshEncodings     = []string{"proto", "json"}
+	meshCompressions  = []string{"snappy", "gzip", "none"}
+	txIndexers        = []string{"kv", "null"}
+	complianceModes   = []string{"", "blacklist", "allowlist"}
+	eventSinks        = []string{"", "nats", "kafka"}
+	eventSinkFormats  = []string{"json", "protobuf"}
+	defaultRPCListen  = "tcp://127.0.0.1:26657"
+	defaultP2PListen  = "tcp://0.0.0.0:26656"
+	wildcardHosts     = map[string]bool{"": true, "0.0.0.0": true, "::": true}
+	loopbackAliasHost = map[string]string{"localhost": "127.0.0.1", "::1": "127.0.0.1"}
+)
+
+// Validate verifica toda la configuración antes de iniciar cualquier componente y retorna todos los
+// problemas juntos (errors.Join), para corregirlos de una vez en lugar de fallar a mitad del arranque
+// Crea el directorio de datos si no existe (para verificar los permisos)
+func (c *Config) Validate() error {
+	errs := append([]error{}, c.envErrors...)
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	// Identidad de la cadena y datos
+	switch {
+	case c.ChainID == "":
+		add("OXY_CHAIN_ID: requerido")
+	case len(c.ChainID) > maxChainIDLength:
+		add("OXY_CHAIN_ID: %q supera los %d caracteres", c.ChainID, maxChainIDLength)
+	case !chainIDPattern.MatchString(c.ChainID):
+		add("OXY_CHAIN_ID: %q inválido (letras, números, '.', '_' y '-')", c.ChainID)
+	}
+	if c.DataDir == "" {
+		add("OXY_DATA_DIR: requerido")
+	} else if err := checkWritableDir(c.DataDir); err != nil {
+		add("OXY_DATA_DIR: %v", err)
+	}
+
+	// Puertos: formato y conflictos entre los servidores del nodo
+	errs = append(errs, c.validateListeners()...)
+
+	// Red
+	if u, err := url.Parse(c.MeshEndpoint); err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+		add("OXY_MESH_ENDPOINT: %q inválido (ws://host:puerto o wss://)", c.MeshEndpoint)
+	}
+	role := strings.ToLower(strings.TrimSpace(c.NodeRole))
+	switch {
+	case !contains(nodeRoles, role):
+		add("OXY_NODE_ROLE: %q desconocido (%s)", c.NodeRole, strings.Join(nodeRoles, ", "))
+	case role == "sentry" && c.PrivatePeerIDs == "":
+		add("OXY_PRIVATE_PEER_IDS: requerido con OXY_NODE_ROLE=sentry")
+	case role == "validator" && c.PersistentPeers == "":
+		add("OXY_PERSISTENT_PEERS: requerido con OXY_NODE_ROLE=validator (sus sentries)")
+	}
+	if c.P2PExternalAddr != "" {
+		host, port, err := net.SplitHostPort(strings.TrimPrefix(c.P2PExternalAddr, "tcp://"))
+		if err != nil || host == "" || wildcardHosts[host] || !validPort(port) {
+			add("OXY_P2P_EXTERNAL_ADDR: %q inválida (host:puerto alcanzable por otros nodos)", c.P2PExternalAddr)
+		}
+	}
+	if c.RESTPublicURL != "" {
+		if u, err := url.Parse(c.RESTPublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("OXY_REST_PUBLIC_URL: %q inválida (http:// o https://)", c.RESTPublicURL)
+		}
+	}
+	if err := checkFallbackSeeds(c.FallbackSeeds); err != nil {
+		add("OXY_FALLBACK_SEEDS: %v", err)
+	}
+	checkEnum(add, "OXY_MESH_ENCODING", c.MeshEncoding, meshEncodings)
+	checkEnum(add, "OXY_MESH_COMPRESSION", c.MeshCompression, meshCompressions)
+
+	// Montos en wei/OXG
+	if _, err := ParseMinStake(c.MinStake); err != nil {
+		add("OXY_MIN_STAKE: %v", err)
+	}
+	if err := checkNonNegativeInt(c.MinGasPrice); err != nil {
+		add("OXY_MIN_GAS_PRICE: %v", err)
+	}
+	if err := checkNonNegativeInt(c.BlockReward); err != nil {
+		add("OXY_BLOCK_REWARD: %v", err)
+	}
+
+	// Módulos opcionales
+	checkEnum(add, "OXY_TX_INDEXER", c.TxIndexer, txIndexers)
+	checkEnum(add, "OXY_COMPLIANCE_MODE", c.ComplianceMode, complianceModes)
+	checkEnum(add, "OXY_EVENTS_SINK", c.EventSink, eventSinks)
+	if c.EventSink != "" {
+		checkEnum(add, "OXY_EVENTS_FORMAT", c.EventSinkFormat, eventSinkFormats)
+		if c.EventSinkURL == "" {
+			add("OXY_EVENTS_URL: requerido con OXY_EVENTS_SINK=%s", c.EventSink)
+		}
+	}
+	if c.RedisURL != "" {
+		if u, err := url.Parse(c.RedisURL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+			add("OXY_REDIS_URL: inválida (redis://host:6379/db o rediss://)")
+		}
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		add("OXY_TRACING_SAMPLE_RATIO: %v fuera de rango (0..1)", c.TracingSampleRatio)
+	}
+	if c.MaxBlockTimeDrift <= 0 {
+		add("OXY_MAX_BLOCK_TIME_DRIFT_MS: debe ser mayor a 0")
+	}
+	if c.SyncCheckInterval <= 0 {
+		add("OXY_SYNC_CHECK_INTERVAL_MS: debe ser mayor a 0")
+	}
+	if c.ReadinessMaxBlocksBehind < 0 {
+		add("OXY_READINESS_MAX_BLOCKS_BEHIND: no puede ser negativo")
+	}
+
+	return errors.Join(errs...)
+}
+
+// ParseMinStake convierte el stake mínimo configurado (entero positivo en OXG) a wei
+func ParseMinStake(value string) (*big.Int, error) {
+	oxg, ok := new(big.Int).SetString(strings.TrimSpace(value), 10)
+	if !ok || oxg.Sign() <= 0 {
+		return nil, fmt.Errorf("%q no es un entero positivo de OXG", value)
+	}
+	return oxg.Mul(oxg, new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)), nil
+}
+
+// listener es un servidor del nodo que escucha en host:puerto
+type listener struct {
+	name string // Variable de entorno que lo configura
+	host string
+	port int
+}
+
+// validateListeners verifica el formato de cada dirección y que dos servidores no usen el mismo puerto
+func (c *Config) validateListeners() []error {
+	var errs []error
+	var listeners []listener
+	addListener := func(name, host, port string) {
+		n, err := strconv.Atoi(port)
+		if err != nil || n < 1 || n > 65535 {
+			errs = append(errs, fmt.Errorf("%s: puerto %q inválido (1-65535)", name, port))
+			return
+		}
+		if alias, ok := loopbackAliasHost[host]; ok {
+			host = alias
+		}
+		listeners = append(listeners, listener{name: name, host: host, port: n})
+	}
+	addAddress := func(name, address, defaultAddress string) {
+		if address == "" {
+			address = defaultAddress
+		}
+		// CometBFT acepta tcp://host:puerto, host:puerto o unix:// (sin puerto)
+		if strings.HasPrefix(address, "unix://") {
+			return
+		}
+		host, port, err := net.SplitHostPort(strings.TrimPrefix(address, "tcp://"))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: dirección %q inválida (tcp://host:puerto)", name, address))
+			return
+		}
+		addListener(name, host, port)
+	}
+
+	if c.APIEnabled {
+		addListener("BLOCKCHAIN_API_PORT", c.APIHost, c.APIPort)
+	}
+	if c.RosettaEnabled {
+		addListener("OXY_ROSETTA_PORT", c.APIHost, c.RosettaPort)
+	}
+	addAddress("OXY_RPC_LADDR", c.RPCListenAddr, defaultRPCListen)
+	addAddress("OXY_P2P_LADDR", c.P2PListenAddr, defaultP2PListen)
+
+	for i, a := range listeners {
+		for _, b := range listeners[i+1:] {
+			if a.port == b.port && (a.host == b.host || wildcardHosts[a.host] || wildcardHosts[b.host]) {
+				errs = append(errs, fmt.Errorf("%s y %s usan el mismo puerto %d", a.name, b.name, a.port))
+			}
+		}
+	}
+	return errs
+}
+
+// checkWritableDir verifica que dir exista (o se pueda crear) y que el proceso pueda escribir en él
+func checkWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("no se puede crear %s: %w", dir, err)
+	}
+	file, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("sin permiso de escritura en %s: %w", dir, err)
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// validPort retorna si port es un puerto TCP válido (1-65535)
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 1 && n <= 65535
+}
+
+// checkFallbackSeeds verifica el formato "chain-id=id@host:puerto,...;otra-cadena=..." de los seeds de respaldo
+func checkFallbackSeeds(value string) error {
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		chainID, seeds, found := strings.Cut(entry, "=")
+		if !found || strings.TrimSpace(chainID) == "" {
+			return fmt.Errorf("entrada %q inválida (chain-id=id@host:puerto,...)", entry)
+		}
+		for _, seed := range strings.Split(seeds, ",") {
+			if seed = strings.TrimSpace(seed); seed == "" {
+				continue
+			}
+			id, hostPort, found := strings.Cut(seed, "@")
+			host, port, err := net.SplitHostPort(hostPort)
+			if !found || id == "" || err != nil || wildcardHosts[host] || !validPort(port) {
+				return fmt.Errorf("seed %q inválido (id@host:puerto)", seed)
+			}
+		}
+	}
+	return nil
+}
+
+// checkNonNegativeInt verifica que value sea un entero decimal >= 0 (montos en wei)
+func checkNonNegativeInt(value string) error {
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok || n.Sign() < 0 {
+		return fmt.Errorf("%q no es un entero no negativo", value)
+	}
+	return nil
+}
+
+// checkEnum agrega un error si value no es uno de los valores aceptados
+func checkEnum(add func(string, ...interface{}), name, value string, accepted []string) {
+	if !contains(accepted, value) {
+		add("%s: %q desconocido (%s)", name, value, strings.Join(nonEmpty(accepted), ", "))
+	}
+}
+
+// contains retorna si values incluye value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// nonEmpty retorna los valores sin el vacío (opción "deshabilitado")
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestValidate_Defaults prueba que la configuración por defecto sea válida
+func TestValidate_Defaults(t *testing.T) {
+	t.Setenv("OXY_DATA_DIR", filepath.Join(t.TempDir(), "data"))
+	cfg := LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Configuración por defecto inválida: %v", err)
+	}
+	if _, err := os.Stat(cfg.DataDir); err != nil {
+		t.Errorf("Validate debería crear el directorio de datos: %v", err)
+	}
+}
+
+// TestValidate_AggregatesErrors prueba que se reporten todos los problemas juntos
+func TestValidate_AggregatesErrors(t *testing.T) {
+	t.Setenv("OXY_DATA_DIR", t.TempDir())
+	t.Setenv("OXY_CHAIN_ID", "oxy chain")
+	t.Setenv("OXY_MESH_ENDPOINT", "localhost:3001")
+	t.Setenv("OXY_MIN_STAKE", "10.5")
+	t.Setenv("OXY_MEMPOOL_SIZE_LIMIT", "mucho")
+	t.Setenv("OXY_ROSETTA_ENABLED", "si")
+	t.Setenv("OXY_NODE_ROLE", "sentry")
+	t.Setenv("OXY_P2P_EXTERNAL_ADDR", "0.0.0.0:26656")
+	t.Setenv("OXY_FALLBACK_SEEDS", "oxy-testnet=abcd@10.0.0.1")
+	cfg := LoadConfig()
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Se esperaban errores de configuración")
+	}
+	for _, expected := range []string{
+		"OXY_CHAIN_ID", "OXY_MESH_ENDPOINT", "OXY_MIN_STAKE", "OXY_MEMPOOL_SIZE_LIMIT", "OXY_ROSETTA_ENABLED",
+		"OXY_PRIVATE_PEER_IDS", "OXY_P2P_EXTERNAL_ADDR", "OXY_FALLBACK_SEEDS",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Falta el error de %s en:\n%v", expected, err)
+		}
+	}
+
+	// Los errores de variables de entorno son de esa carga, no de las siguientes
+	os.Unsetenv("OXY_MEMPOOL_SIZE_LIMIT")
+	if cfg := LoadConfig(); strings.Contains(cfg.Validate().Error(), "OXY_MEMPOOL_SIZE_LIMIT") {
+		t.Error("Error de una carga anterior reportado otra vez")
+	}
+}
+
+// TestValidate_Listeners prueba los conflictos de puertos entre los servidores del nodo
+func TestValidate_Listeners(t *testing.T) {
+	t.Setenv("OXY_DATA_DIR", t.TempDir())
+	cfg := LoadConfig()
+
+	// El API en localhost y el RPC de CometBFT en 127.0.0.1 con el mismo puerto
+	cfg.APIPort = "26657"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "BLOCKCHAIN_API_PORT y OXY_RPC_LADDR") {
+		t.Errorf("Conflicto API/RPC no detectado: %v", err)
+	}
+
+	// El P2P escucha en todas las interfaces por defecto
+	cfg.APIPort = "26656"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "OXY_P2P_LADDR") {
+		t.Errorf("Conflicto API/P2P no detectado: %v", err)
+	}
+
+	// Mismo puerto en hosts distintos es válido
+	cfg.APIPort = "26657"
+	cfg.APIHost = "10.0.0.5"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Hosts distintos no deberían entrar en conflicto: %v", err)
+	}
+
+	cfg.APIPort = "99999"
+	cfg.RPCListenAddr = "unix:///tmp/cometbft.sock"
+	cfg.P2PListenAddr = "tcp://0.0.0.0"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "BLOCKCHAIN_API_PORT") || !strings.Contains(err.Error(), "OXY_P2P_LADDR") {
+		t.Errorf("Direcciones inválidas no detectadas: %v", err)
+	}
+	if strings.Contains(err.Error(), "OXY_RPC_LADDR") {
+		t.Errorf("Un socket unix no tiene puerto: %v", err)
+	}
+}
+
+// TestValidate_DataDir prueba que se detecte un directorio de datos sin permiso de escritura
+func TestValidate_DataDir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "archivo")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OXY_DATA_DIR", filepath.Join(file, "data"))
+	if err := LoadConfig().Validate(); err == nil || !strings.Contains(err.Error(), "OXY_DATA_DIR") {
+		t.Errorf("Directorio de datos inválido no detectado: %v", err)
+	}
+}
+
+// TestParseMinStake prueba la conversión del stake mínimo a wei
+func TestParseMinStake(t *testing.T) {
+	minStake, err := ParseMinStake("10")
+	expected, _ := new(big.Int).SetString("10000000000000000000", 10)
+	if err != nil || minStake.Cmp(expected) != 0 {
+		t.Errorf("ParseMinStake(10) = %v, %v", minStake, err)
+	}
+	for _, invalid := range []string{"", "0", "-5", "1e3", "diez"} {
+		if _, err := ParseMinStake(invalid); err == nil {
+			t.Errorf("ParseMinStake(%q) debería fallar", invalid)
+		}
+	}
+}
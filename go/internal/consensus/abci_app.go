@@ -3,21 +3,29 @@ package consensus
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
 	execution "github.com/Q-YZX0/oxy-blockchain/internal/execution"
 	"github.com/Q-YZX0/oxy-blockchain/internal/logger"
 	"github.com/Q-YZX0/oxy-blockchain/internal/metrics"
+	"github.com/Q-YZX0/oxy-blockchain/internal/snapshot"
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/Q-YZX0/oxy-blockchain/internal/tracing"
 	abcitypes "github.com/cometbft/cometbft/abci/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// consensusLog es el logger del módulo consensus (nivel ajustable en runtime con OXY_LOG_MODULES)
+var consensusLog = logger.For("consensus")
+
 // ABCIApp implementa la interfaz ABCI de CometBFT
 // Esta es la aplicación que corre sobre CometBFT
 type ABCIApp struct {
@@ -27,12 +35,43 @@ type ABCIApp struct {
 	state                *AppState
 	currentBlockHeight   uint64
 	currentBlockTime     int64
+	currentBlockStamp    time.Time // Hora del header con precisión completa (intervalos entre bloques)
+	currentProposer      string    // Dirección CometBFT del proponente del bloque en curso
 	currentBlockTxs      []*Transaction
+	currentBlockHashes   []string // Hashes de todas las transacciones incluidas, exitosas o no
 	currentBlockReceipts []*TransactionReceipt
+	currentTxResults     map[string]*TxCommitResult // Resultado de cada transacción del bloque en curso, por hash
+	txWaiters            *txWaiters                 // Envíos en modo commit esperando su transacción
+	txTraces             *txTraceIndex              // Span de envío de cada transacción (links de tracing)
 	chainID              string
-	getMempool           func() []*Transaction // Función para obtener el mempool local
-	clearMempoolTx       func(string)          // Función para limpiar una transacción del mempool
-	metrics              *metrics.Metrics      // Referencia a las métricas (opcional)
+	getMempool           func() []*Transaction              // Función para obtener el mempool local
+	clearMempoolTx       func(string)                       // Función para limpiar una transacción del mempool
+	metrics              *metrics.Metrics                   // Referencia a las métricas (opcional)
+	recentTxs            *RecentTxIndex                     // Hashes incluidos recientemente (protección contra replays)
+	txLimits             TxLimits                           // Límites anti-spam por transacción (gas price, data, gas límite)
+	maxBlockTimeDrift    time.Duration                      // Adelanto máximo de la hora de una propuesta sobre el reloj local
+	compliance           *ComplianceList                    // Blacklist/allowlist de direcciones (opcional)
+	pendingStakeEvents   []abcitypes.Event                  // Eventos de staking del bloque en curso
+	stakeEventsMutex     sync.Mutex                         // Protege pendingStakeEvents (el handler se invoca desde el ValidatorSet)
+	onBlockCommitted     []func(*Block)                     // Notificaciones de bloque confirmado (opcional, ej: gossip por mesh, watchlist)
+	onTxResults          []func(map[string]*TxCommitResult) // Notificaciones del resultado de cada transacción confirmada (ej: relay de la mesh)
+	snapshotStore        *snapshot.Store                    // Snapshots de estado para state sync (opcional)
+	snapshotInterval     uint64                             // Cada cuántos bloques se genera un snapshot (0 = deshabilitado)
+	snapshotKeepRecent   int                                // Snapshots que se conservan (0 = todos)
+	snapshotReceiver     *snapshot.Receiver                 // Snapshot en recepción por state sync
+	restoredHeight       int64                              // Altura de un snapshot restaurado fuera de banda, pendiente del primer bloque
+	invariantInterval    uint64                             // Cada cuántos bloques se auditan las invariantes contables (0 = deshabilitado)
+	invariantAudit       atomic.Bool                        // Auditoría en curso (evita auditorías superpuestas)
+	chainHead            *ChainHead                         // Último bloque confirmado registrado fuera de blockchain.db (detección de reorgs)
+	halt                 HaltStatus                         // Detención programada de la cadena (API de administración o invariantes)
+	haltMu               sync.RWMutex                       // Protege halt (se programa desde el API mientras corre el consenso)
+	haltOnViolation      bool                               // Detener la cadena si la auditoría encuentra invariantes incumplidas
+	committedHeight      atomic.Uint64                      // Último bloque confirmado (valida la altura de las detenciones)
+	devMode              bool                               // Modo desarrollo: acepta pedidos de bloque en CheckTx
+	devClock             devClock                           // Ajustes de la hora de los bloques en modo desarrollo
+	devBlockMu           sync.Mutex                         // Modo desarrollo: tomado de FinalizeBlock a Commit (los snapshots no se intercalan)
+	devSnapshots         []*devSnapshot                     // Snapshots de desarrollo, del más antiguo al más reciente
+	devSnapshotSeq       uint64                             // Último ID de snapshot de desarrollo asignado
 }
 
 // AppState mantiene el estado de la aplicación
@@ -44,7 +83,7 @@ type AppState struct {
 
 // NewABCIApp crea una nueva aplicación ABCI
 func NewABCIApp(storage *storage.BlockchainDB, executor *execution.EVMExecutor, validators *ValidatorSet, chainID string) *ABCIApp {
-	return &ABCIApp{
+	app := &ABCIApp{
 		storage:    storage,
 		executor:   executor,
 		validators: validators,
@@ -58,7 +97,34 @@ func NewABCIApp(storage *storage.BlockchainDB, executor *execution.EVMExecutor,
 		currentBlockReceipts: make([]*TransactionReceipt, 0),
 		getMempool:           nil, // Se establecerá después
 		clearMempoolTx:       nil, // Se establecerá después
+		txWaiters:            newTxWaiters(),
+		txTraces:             newTxTraceIndex(),
 	}
+
+	app.SetDuplicateTxWindow(DefaultDuplicateTxWindow)
+	app.SetMaxBlockTimeDrift(DefaultMaxBlockTimeDrift)
+
+	// Continuar desde un snapshot restaurado con `snapshot restore` (si lo hay)
+	app.loadRestoredSnapshot()
+
+	// Último bloque confirmado, para no retrocederlo al reejecutar bloques al reiniciar
+	if storage != nil {
+		head, err := ReadChainHead(storage.GetDataDir())
+		if err != nil {
+			consensusLog.Warn("Error leyendo último bloque confirmado: " + err.Error())
+		}
+		app.chainHead = head
+	}
+
+	// Detención de la cadena programada antes de reiniciar (si la hay)
+	app.loadHalt()
+
+	// Emitir eventos de staking cuando cambie el stake de un validador
+	if validators != nil {
+		validators.SetStakeChangeHandler(app.recordStakeEvent)
+	}
+
+	return app
 }
 
 // SetGetMempool establece la función para obtener el mempool local
@@ -76,11 +142,35 @@ func (app *ABCIApp) SetMetrics(m *metrics.Metrics) {
 	app.metrics = m
 }
 
+// AddBlockCommitHandler agrega una función llamada con cada bloque confirmado
+// Los handlers se ejecutan dentro de Commit, en orden de registro, por lo que no deben bloquear
+func (app *ABCIApp) AddBlockCommitHandler(handler func(*Block)) {
+	app.onBlockCommitted = append(app.onBlockCommitted, handler)
+}
+
+// AddTxResultsHandler agrega una función llamada en cada commit con el resultado de todas las transacciones
+// incluidas en el bloque, exitosas o fallidas (por hash). Se ejecuta dentro de Commit: no debe bloquear
+func (app *ABCIApp) AddTxResultsHandler(handler func(map[string]*TxCommitResult)) {
+	app.onTxResults = append(app.onTxResults, handler)
+}
+
+// SetDuplicateTxWindow establece la ventana (en bloques) de protección contra transacciones duplicadas
+// El índice se reconstruye desde los bloques guardados para que sea determinista entre reinicios
+func (app *ABCIApp) SetDuplicateTxWindow(window uint64) {
+	index := NewRecentTxIndex(window)
+	if app.storage != nil {
+		if err := index.LoadFromStorage(app.storage); err != nil {
+			consensusLog.Warn("Error cargando índice de transacciones recientes: " + err.Error())
+		}
+	}
+	app.recentTxs = index
+}
+
 // Info retorna información sobre el estado de la aplicación (nueva API v1.0.1)
 func (app *ABCIApp) Info(ctx context.Context, req *abcitypes.InfoRequest) (*abcitypes.InfoResponse, error) {
 	return &abcitypes.InfoResponse{
-		Data:             fmt.Sprintf("oxy-blockchain-v0.1.0"),
-		Version:          "0.1.0",
+		Data:             fmt.Sprintf("oxy-blockchain-v%s", AppVersion),
+		Version:          AppVersion,
 		AppVersion:       1,
 		LastBlockHeight:  app.state.Height,
 		LastBlockAppHash: app.state.AppHash,
@@ -91,7 +181,18 @@ func (app *ABCIApp) Info(ctx context.Context, req *abcitypes.InfoRequest) (*abci
 func (app *ABCIApp) InitChain(ctx context.Context, req *abcitypes.InitChainRequest) (*abcitypes.InitChainResponse, error) {
 	fmt.Fprintf(os.Stdout, "[ABCI] InitChain iniciado\n")
 	os.Stdout.Sync()
-	logger.Info("Inicializando blockchain")
+	consensusLog.Info("Inicializando blockchain")
+
+	// Cuentas fondeadas en app_state del genesis (iguales en todos los nodos)
+	if err := app.mintGenesisAccounts(req.AppStateBytes); err != nil {
+		return nil, err
+	}
+	if err := app.initGenesisDeployers(req.AppStateBytes); err != nil {
+		return nil, err
+	}
+	if err := app.initGenesisVesting(req.AppStateBytes); err != nil {
+		return nil, err
+	}
 
 	// Cargar validadores guardados
 	fmt.Fprintf(os.Stdout, "[ABCI] Verificando validadores...\n")
@@ -100,7 +201,7 @@ func (app *ABCIApp) InitChain(ctx context.Context, req *abcitypes.InitChainReque
 		fmt.Fprintf(os.Stdout, "[ABCI] Validadores disponibles, cargando...\n")
 		os.Stdout.Sync()
 		if err := app.validators.LoadValidators(); err != nil {
-			logger.Warn("Error cargando validadores: " + err.Error())
+			consensusLog.Warn("Error cargando validadores: " + err.Error())
 		}
 
 		fmt.Fprintf(os.Stdout, "[ABCI] Verificando validadores activos...\n")
@@ -139,7 +240,7 @@ func (app *ABCIApp) InitChain(ctx context.Context, req *abcitypes.InitChainReque
 			if err := app.validators.InitializeGenesisValidators(genesisValidators); err != nil {
 				fmt.Fprintf(os.Stderr, "[ABCI] ERROR inicializando validadores genesis: %v\n", err)
 				os.Stderr.Sync()
-				logger.Error("Error inicializando validadores genesis: " + err.Error())
+				consensusLog.Error("Error inicializando validadores genesis: " + err.Error())
 			} else {
 				fmt.Fprintf(os.Stdout, "[ABCI] Validadores genesis inicializados exitosamente\n")
 				os.Stdout.Sync()
@@ -179,6 +280,12 @@ func (app *ABCIApp) InitChain(ctx context.Context, req *abcitypes.InitChainReque
 		app.state.Validators = req.Validators
 	}
 
+	// Set inicial de CometBFT, base para sacar y devolver validadores por jail
+	if app.validators != nil {
+		app.validators.RecordConsensusUpdates(app.state.Validators)
+		app.validators.SaveValidatorSetAt(uint64(max(req.InitialHeight, 1)))
+	}
+
 	fmt.Fprintf(os.Stdout, "[ABCI] Preparando respuesta InitChain...\n")
 	os.Stdout.Sync()
 	response := &abcitypes.InitChainResponse{
@@ -195,7 +302,7 @@ func (app *ABCIApp) InitChain(ctx context.Context, req *abcitypes.InitChainReque
 func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBlockRequest) (*abcitypes.FinalizeBlockResponse, error) {
 	fmt.Fprintf(os.Stdout, "[ABCI] FinalizeBlock llamado: height=%d, txs=%d\n", req.Height, len(req.Txs))
 	os.Stdout.Sync()
-	logger.Info(fmt.Sprintf("Finalizando bloque: height=%d, txs=%d", req.Height, len(req.Txs)))
+	consensusLog.Ctx(ctx).Info().Msgf("Finalizando bloque: height=%d, txs=%d", req.Height, len(req.Txs))
 	startFinalize := time.Now()
 
 	// Log detallado de transacciones recibidas
@@ -207,20 +314,54 @@ func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBl
 		os.Stdout.Sync()
 	}
 
+	// En modo desarrollo un snapshot o revert espera a que termine el bloque (se libera en Commit)
+	if app.devMode {
+		app.devBlockMu.Lock()
+	}
+
 	// Guardar altura y timestamp actuales para uso en ejecución EVM
+	// En modo desarrollo la hora puede estar adelantada (IncreaseTime, SetNextBlockTimestamp)
+	blockTime := req.Time
+	if app.devMode {
+		blockTime = app.devClock.blockTime(req.Time)
+	}
+
+	// La EVM usa siempre la hora de consenso del header (antes de modificar cualquier estado del bloque)
+	if err := app.executor.BeginBlock(uint64(req.Height), blockTime.Unix()); err != nil {
+		if app.devMode {
+			app.devBlockMu.Unlock()
+		}
+		return nil, fmt.Errorf("error iniciando bloque %d en el ejecutor: %w", req.Height, err)
+	}
 	app.state.Height = req.Height
 	app.currentBlockHeight = uint64(req.Height)
-	app.currentBlockTime = req.Time.Unix()
+	app.currentBlockTime = blockTime.Unix()
+	app.currentBlockStamp = blockTime
+	app.currentProposer = fmt.Sprintf("%X", req.ProposerAddress)
+	if app.validators != nil {
+		app.validators.SetBlockInfo(uint64(req.Height), blockTime)
+		app.validators.HandleMisbehavior(req.Misbehavior)
+		app.validators.RecordCommitVotes(req.DecidedLastCommit.Votes)
+		app.validators.RecordProposer(req.ProposerAddress)
+		app.distributeBlockReward(req.DecidedLastCommit.Votes)
+	}
+	app.runSystemCalls(execution.SystemHookBeginBlock)
 
 	// Limpiar transacciones del bloque anterior
 	app.currentBlockTxs = make([]*Transaction, 0)
+	app.currentBlockHashes = make([]string, 0, len(req.Txs))
 	app.currentBlockReceipts = make([]*TransactionReceipt, 0)
 
+	// Solo se emiten los cambios de stake ocurridos durante este bloque
+	app.takeStakeEvents()
+
 	// Procesar todas las transacciones del bloque
 	txResults := make([]*abcitypes.ExecTxResult, 0, len(req.Txs))
 
-	// Establecer información del bloque actual en el ejecutor
-	app.executor.SetCurrentBlockInfo(uint64(req.Height), app.currentBlockTime)
+	// Hashes vistos en este bloque (para detectar duplicados dentro del mismo bloque)
+	seenInBlock := make(map[string]bool, len(req.Txs))
+	// Hash de cada transacción, alineado con txResults (para los envíos en modo commit)
+	txHashes := make([]string, len(req.Txs))
 
 	// Procesar cada transacción
 	for i, txBytes := range req.Txs {
@@ -232,15 +373,26 @@ func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBl
 		if err := json.Unmarshal(txBytes, &tx); err != nil {
 			fmt.Fprintf(os.Stderr, "[ABCI] ERROR decodificando transacción %d: %v\n", i+1, err)
 			os.Stderr.Sync()
-			txResults = append(txResults, &abcitypes.ExecTxResult{
-				Code: 1,
-				Log:  fmt.Sprintf("Error decodificando transacción: %v", err),
-			})
+			txResults = append(txResults, execTxError(CodeDecodeError, fmt.Sprintf("Error decodificando transacción: %v", err)))
 			continue
 		}
 
 		fmt.Fprintf(os.Stdout, "[ABCI] Transacción decodificada: hash=%s, from=%s, to=%s\n", tx.Hash, tx.From, tx.To)
 		os.Stdout.Sync()
+		txHashes[i] = tx.Hash
+
+		// Rechazar duplicados de forma determinista (mismo bloque o bloques recientes)
+		if seenInBlock[tx.Hash] || app.recentTxs.Contains(tx.Hash) {
+			fmt.Fprintf(os.Stderr, "[ABCI] Transacción duplicada rechazada: hash=%s\n", tx.Hash)
+			os.Stderr.Sync()
+			txResults = append(txResults, execTxError(CodeDuplicateTx, fmt.Sprintf("Transacción duplicada: %s", tx.Hash)))
+			continue
+		}
+		seenInBlock[tx.Hash] = true
+
+		// La transacción queda en la cadena aunque falle la validación o la ejecución:
+		// se registra para que no pueda volver a incluirse
+		app.currentBlockHashes = append(app.currentBlockHashes, tx.Hash)
 
 		// Validar transacción básica
 		fmt.Fprintf(os.Stdout, "[ABCI] Validando transacción: hash=%s\n", tx.Hash)
@@ -248,10 +400,32 @@ func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBl
 		if err := app.validateTransaction(&tx); err != nil {
 			fmt.Fprintf(os.Stderr, "[ABCI] ERROR validación falló: %v\n", err)
 			os.Stderr.Sync()
-			txResults = append(txResults, &abcitypes.ExecTxResult{
-				Code: 2,
-				Log:  fmt.Sprintf("Transacción inválida: %v", err),
-			})
+			txResults = append(txResults, execTxError(ErrorCode(err, CodeInvalidTx), fmt.Sprintf("Transacción inválida: %v", err)))
+			continue
+		}
+		// Las firmas de una multisig se reverifican al ejecutar: el umbral no depende de que el proponente
+		// haya pasado la transacción por CheckTx
+		if isMultisigTx(&tx) {
+			if err := validateMultisigAuth(&tx); err != nil {
+				txResults = append(txResults, execTxError(ErrorCode(err, CodeInvalidSignature), fmt.Sprintf("Transacción inválida: %v", err)))
+				continue
+			}
+		}
+		// Igual con el fee payer: sin su firma el gas se cobraría a una cuenta que no lo autorizó
+		if isSponsoredTx(&tx) {
+			if err := validateFeePayer(&tx); err != nil {
+				txResults = append(txResults, execTxError(ErrorCode(err, CodeInvalidSignature), fmt.Sprintf("Transacción inválida: %v", err)))
+				continue
+			}
+		}
+		// Contratos pausados: la transacción falla sin ejecutarse (freno de emergencia)
+		if tx.To != "" && app.executor.ContractPaused(tx.To) {
+			txResults = append(txResults, execTxError(CodeContractPaused, fmt.Sprintf("Contrato pausado: %s", tx.To)))
+			continue
+		}
+		// Vesting: los fondos bloqueados no se transfieren (sí se pueden stakear)
+		if err := app.checkVestingSpend(&tx, false); err != nil {
+			txResults = append(txResults, execTxError(ErrorCode(err, CodeVestingLocked), err.Error()))
 			continue
 		}
 		fmt.Fprintf(os.Stdout, "[ABCI] Validación exitosa: hash=%s\n", tx.Hash)
@@ -269,37 +443,83 @@ func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBl
 			GasLimit: tx.GasLimit,
 			GasPrice: tx.GasPrice,
 			Nonce:    tx.Nonce,
+
+			AccessList: tx.AccessList,
+		}
+		if isSponsoredTx(&tx) {
+			executionTx.FeePayer = tx.FeePayer.Address
 		}
 
-		// Ejecutar transacción con EVM
+		// Ejecutar transacción con EVM (span enlazado con el envío de la transacción, si pasó por este nodo)
 		fmt.Fprintf(os.Stdout, "[ABCI] Ejecutando transacción con EVM: hash=%s\n", tx.Hash)
 		os.Stdout.Sync()
-		result, err := app.executor.ExecuteTransaction(executionTx)
+		txCtx, txSpan := tracing.Start(ctx, "evm.ExecuteTransaction", tracing.String("tx.hash", tx.Hash), tracing.Int64("tx.index", int64(i)))
+		if submitted, ok := app.txTraces.take(tx.Hash); ok {
+			txSpan.AddLink(submitted)
+		}
+		result, err := app.executeTransactionSafe(txCtx, executionTx)
 		if err != nil {
+			txSpan.RecordError(err)
+			txSpan.End()
 			fmt.Fprintf(os.Stderr, "[ABCI] ERROR ejecutando transacción: %v\n", err)
 			os.Stderr.Sync()
-			txResults = append(txResults, &abcitypes.ExecTxResult{
-				Code: 3,
-				Log:  fmt.Sprintf("Error ejecutando transacción: %v", err),
-			})
+			consensusLog.Ctx(txCtx).Error().Err(err).Str("tx_hash", tx.Hash).Msg("Error ejecutando transacción")
+			txResults = append(txResults, execTxError(ErrorCode(err, CodeExecutionError), fmt.Sprintf("Error ejecutando transacción: %v", err)))
 			continue
 		}
+		txSpan.SetAttributes(tracing.Int64("tx.gas_used", int64(result.GasUsed)), tracing.Bool("tx.success", result.Success))
+		if !result.Success {
+			txSpan.RecordError(errors.New(result.Error))
+		}
+		txSpan.End()
 		fmt.Fprintf(os.Stdout, "[ABCI] Ejecución completada: hash=%s, success=%v\n", tx.Hash, result.Success)
 		os.Stdout.Sync()
 
+		// Las transacciones al módulo de staking modifican el ValidatorSet tras mover el valor a custodia
+		if result.Success && isStakingTx(&tx) {
+			if err := app.applyStakingTxSafe(txCtx, &tx); err != nil {
+				result.Success = false
+				result.Error = fmt.Sprintf("staking fallido: %v", err)
+			}
+		}
+
+		// Las transacciones al registro de deployers cambian la política de despliegue (solo administradores)
+		if result.Success && isDeployerTx(&tx) {
+			if err := app.applyDeployerTxSafe(txCtx, &tx); err != nil {
+				result.Success = false
+				result.Error = fmt.Sprintf("registro de deployers: %v", err)
+			}
+		}
+		if result.Success && isContractPauseTx(&tx) {
+			if err := app.applyContractPauseTxSafe(txCtx, &tx); err != nil {
+				result.Success = false
+				result.Error = fmt.Sprintf("pausa de contrato: %v", err)
+			}
+		}
+		if result.Success && isVestingTx(&tx) {
+			if err := app.applyVestingTxSafe(txCtx, &tx); err != nil {
+				result.Success = false
+				result.Error = fmt.Sprintf("vesting: %v", err)
+			}
+		}
+
 		// Crear resultado de ejecución
 		execTxResult := &abcitypes.ExecTxResult{
-			Code:    0,
+			Code:    CodeOK,
 			Log:     "OK",
 			GasUsed: int64(result.GasUsed),
-			Events:  app.buildEvents(result),
+			Events:  app.buildEvents(&tx, result),
 		}
+		app.attachStakeEvents(execTxResult)
 
 		if !result.Success {
 			fmt.Fprintf(os.Stderr, "[ABCI] Transacción falló en ejecución: hash=%s, error=%s\n", tx.Hash, result.Error)
 			os.Stderr.Sync()
-			execTxResult.Code = 4
+			execTxResult.Code = CodeExecutionFailed
+			execTxResult.Codespace = Codespace
 			execTxResult.Log = result.Error
+			execTxResult.Info = CodeReason(CodeExecutionFailed)
+			execTxResult.Events = append(execTxResult.Events, txErrorEvent(CodeExecutionFailed))
 
 			// Actualizar métricas para transacción rechazada
 			if app.metrics != nil {
@@ -323,7 +543,7 @@ func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBl
 			fmt.Fprintf(os.Stdout, "[ABCI] Transacción exitosa, guardando en storage: hash=%s\n", tx.Hash)
 			os.Stdout.Sync()
 
-			txData, _ := json.Marshal(tx)
+			txData, _ := EncodeTransaction(&tx)
 			if err := app.storage.SaveTransaction(tx.Hash, txData); err != nil {
 				fmt.Fprintf(os.Stderr, "[ABCI] ERROR guardando transacción en storage: %v\n", err)
 				os.Stderr.Sync()
@@ -363,6 +583,11 @@ func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBl
 
 		txResults = append(txResults, execTxResult)
 	}
+	app.currentTxResults = collectTxResults(app.currentBlockHeight, txHashes, txResults)
+	app.runSystemCalls(execution.SystemHookEndBlock)
+
+	// Cierre contable del bloque: fees, pools de staking y slashes (ver module_accounts.go)
+	app.settleModuleAccounts()
 
 	// Rotar validadores periódicamente (cada 100 bloques)
 	// IMPORTANTE: Solo retornar ValidatorUpdates si hay cambios REALES
@@ -389,7 +614,7 @@ func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBl
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "[ABCI] ERROR rotando validadores: %v\n", err)
 					os.Stderr.Sync()
-					logger.Error("Error rotando validadores: " + err.Error())
+					consensusLog.Error("Error rotando validadores: " + err.Error())
 					// Si hay error, no retornar actualizaciones (mantener validadores actuales)
 				} else if len(updates) > 0 {
 					// Comparar si hay cambios REALES antes de retornar updates
@@ -397,18 +622,18 @@ func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBl
 						validatorUpdates = updates
 						fmt.Fprintf(os.Stdout, "[ABCI] Validadores rotados exitosamente con cambios: count=%d\n", len(updates))
 						os.Stdout.Sync()
-						logger.Info(fmt.Sprintf("Validadores rotados: count=%d", len(updates)))
+						consensusLog.Info(fmt.Sprintf("Validadores rotados: count=%d", len(updates)))
 					} else {
 						// No hay cambios, no retornar updates (evita que CometBFT se detenga)
 						fmt.Fprintf(os.Stdout, "[ABCI] Rotación completada pero sin cambios, no retornando ValidatorUpdates\n")
 						os.Stdout.Sync()
-						logger.Info("Rotación de validadores: sin cambios detectados")
+						consensusLog.Info("Rotación de validadores: sin cambios detectados")
 					}
 				} else {
 					// Si no hay validadores después de rotación, NO retornar actualizaciones
 					fmt.Fprintf(os.Stderr, "[ABCI] ADVERTENCIA: Rotación retornó 0 validadores, manteniendo validadores actuales\n")
 					os.Stderr.Sync()
-					logger.Warn("Rotación retornó 0 validadores, manteniendo validadores actuales")
+					consensusLog.Warn("Rotación retornó 0 validadores, manteniendo validadores actuales")
 				}
 			}
 		} else {
@@ -417,11 +642,25 @@ func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBl
 		}
 	}
 
+	// Validadores que entraron o salieron de jail en este bloque (se informan sin esperar la rotación)
+	if app.validators != nil {
+		validatorUpdates = mergeValidatorUpdates(validatorUpdates, app.validators.TakeSetChangeUpdates())
+		validatorUpdates = app.validators.PrepareValidatorUpdates(validatorUpdates)
+		app.validators.RecordConsensusUpdates(validatorUpdates)
+		if len(validatorUpdates) > 0 {
+			app.validators.SaveValidatorSetAt(uint64(req.Height) + validatorUpdateDelay)
+		}
+	}
+
 	dur := time.Since(startFinalize)
 	fmt.Fprintf(os.Stdout, "[ABCI] FinalizeBlock completado: height=%d, txs=%d, duración=%s\n", req.Height, len(req.Txs), dur)
 	os.Stdout.Sync()
 
+	// Los eventos de staking registrados fuera de una transacción se emiten con el bloque
+	blockEvents := app.takeStakeEvents()
+
 	return &abcitypes.FinalizeBlockResponse{
+		Events:           blockEvents,
 		TxResults:        txResults,
 		ValidatorUpdates: validatorUpdates,
 	}, nil
@@ -429,15 +668,25 @@ func (app *ABCIApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBl
 
 // Commit confirma el bloque y retorna el AppHash (nueva API v1.0.1)
 func (app *ABCIApp) Commit(ctx context.Context, req *abcitypes.CommitRequest) (*abcitypes.CommitResponse, error) {
+	if app.devMode {
+		defer app.devBlockMu.Unlock()
+	}
+
 	fmt.Fprintf(os.Stdout, "[ABCI] Commit llamado: currentBlockHeight=%d\n", app.currentBlockHeight)
 	os.Stdout.Sync()
 
 	// Guardar estado EVM completo (esto persiste el StateDB)
+	saveCtx, saveSpan := tracing.Start(ctx, "evm.SaveState", tracing.Int64("block.height", int64(app.currentBlockHeight)))
 	if err := app.executor.SaveState(); err != nil {
+		saveSpan.RecordError(err)
 		fmt.Fprintf(os.Stderr, "[ABCI] ERROR guardando estado EVM: %v\n", err)
 		os.Stderr.Sync()
-		logger.Warn("Error guardando estado EVM: " + err.Error())
+		consensusLog.Ctx(saveCtx).Warn().Msg("Error guardando estado EVM: " + err.Error())
 	}
+	saveSpan.End()
+
+	// Índice de cuentas (top holders) con las cuentas modificadas en el bloque
+	app.updateAccountIndex()
 
 	// Obtener root hash del StateDB
 	stateRoot := app.executor.GetStateManager().GetRootHash()
@@ -462,25 +711,72 @@ func (app *ABCIApp) Commit(ctx context.Context, req *abcitypes.CommitRequest) (*
 
 	// Guardar bloque completo
 	if app.currentBlockHeight > 0 {
-		if err := app.saveBlock(appHash); err != nil {
-			logger.Warn("Error guardando bloque: " + err.Error())
+		// Hashes de todas las transacciones incluidas (también las fallidas), antes que el bloque y su
+		// altura, para reconstruir el mismo índice de duplicados al reiniciar
+		if err := saveIncludedTxHashes(app.storage, app.currentBlockHeight, app.currentBlockHashes); err != nil {
+			consensusLog.Warn("Error guardando hashes incluidos: " + err.Error())
 		}
 
+		blockCtx, blockSpan := tracing.Start(ctx, "storage.SaveBlock", tracing.Int64("block.height", int64(app.currentBlockHeight)))
+		block, err := app.saveBlock(common.BytesToHash(appHash))
+		blockSpan.RecordError(err)
+		blockSpan.End()
+		if err != nil {
+			consensusLog.Ctx(blockCtx).Warn().Msg("Error guardando bloque: " + err.Error())
+		} else {
+			app.advanceChainHead(block)
+			for _, handler := range app.onBlockCommitted {
+				handler(block)
+			}
+		}
+
+		// Registrar todas las transacciones incluidas (también las fallidas) en el índice de duplicados
+		app.recentTxs.Add(app.currentBlockHeight, app.currentBlockHashes)
+
+		// Responder a los envíos en modo commit que esperaban estas transacciones
+		app.txWaiters.resolve(app.currentTxResults)
+		for _, handler := range app.onTxResults {
+			handler(app.currentTxResults)
+		}
+		app.currentTxResults = nil
+
 		// Actualizar métricas para bloque procesado
 		if app.metrics != nil {
 			app.metrics.IncrementBlocks()
 			app.metrics.SetBlockHeight(app.currentBlockHeight)
+			// TPS sostenido e intervalos entre bloques con el tiempo de consenso de cada bloque
+			app.metrics.RecordCommittedBlock(app.currentBlockHeight, len(app.currentBlockHashes), app.currentBlockStamp)
 			// Calcular tiempo de procesamiento (aproximado)
 			if app.currentBlockTime > 0 {
 				processingTime := time.Since(time.Unix(app.currentBlockTime, 0))
 				app.metrics.AddBlockProcessingTime(processingTime)
 			}
+			// Las cuentas de módulo (pools de staking, recompensas, community pool) no circulan
+			if supply, err := app.executor.GetSupply(ModuleAccountAddresses()...); err == nil {
+				app.metrics.SetSupply(supply.Total, supply.Circulating, supply.Burned)
+			}
 		}
 	}
 
 	// Actualizar AppHash con root del StateDB
 	copy(app.state.AppHash, appHash)
 
+	// La cadena avanzó más allá del snapshot restaurado: ya no hace falta su registro
+	app.clearRestoredSnapshot()
+
+	// Snapshot de estado cada SnapshotInterval bloques (para state sync y export)
+	app.maybeCreateSnapshot(app.currentBlockHeight, appHash)
+
+	// Auditoría de invariantes contables cada invariantInterval bloques (en segundo plano)
+	app.maybeAuditInvariants(app.currentBlockHeight, stateRoot)
+
+	// Detener la cadena si este es el bloque de la detención programada
+	app.maybeHalt(app.currentBlockHeight)
+
+	// Revalidar el mempool local contra el nuevo estado (el mempool de CometBFT
+	// se revalida vía CheckTx con tipo RECHECK)
+	app.recheckLocalMempool()
+
 	fmt.Fprintf(os.Stdout, "[ABCI] Commit completado: height=%d, appHash=%s\n", app.currentBlockHeight, common.BytesToHash(appHash).Hex()[:16])
 	os.Stdout.Sync()
 
@@ -490,18 +786,15 @@ func (app *ABCIApp) Commit(ctx context.Context, req *abcitypes.CommitRequest) (*
 	}, nil
 }
 
-// saveBlock guarda el bloque completo en storage
-func (app *ABCIApp) saveBlock(blockHash []byte) error {
-	// Calcular hash del bloque
-	blockHashStr := common.BytesToHash(blockHash).Hex()
-
+// saveBlock guarda el bloque completo en storage por hash, como el canónico de su altura
+// El hash se calcula del header (ver sealHeader), que incluye el app hash como StateRoot
+func (app *ABCIApp) saveBlock(appHash common.Hash) (*Block, error) {
 	// Obtener hash del bloque padre
 	parentHash := ""
 	if app.currentBlockHeight > 0 {
 		parentBlockData, err := app.storage.GetBlock(app.currentBlockHeight - 1)
 		if err == nil && parentBlockData != nil {
-			var parentBlock Block
-			if err := json.Unmarshal(parentBlockData, &parentBlock); err == nil {
+			if parentBlock, err := DecodeBlock(parentBlockData); err == nil {
 				parentHash = parentBlock.Header.Hash
 			}
 		}
@@ -511,34 +804,36 @@ func (app *ABCIApp) saveBlock(blockHash []byte) error {
 	block := &Block{
 		Header: BlockHeader{
 			Height:     app.currentBlockHeight,
-			Hash:       blockHashStr,
 			ParentHash: parentHash,
 			Timestamp:  time.Unix(app.currentBlockTime, 0),
 			ChainID:    app.chainID,
+			Validator:  app.currentProposer,
 		},
 		Transactions: app.currentBlockTxs,
 		Receipts:     app.currentBlockReceipts,
 	}
+	block.sealHeader(appHash)
+	blockHashStr := block.Header.Hash
 
 	// Guardar bloque
-	blockData, err := json.Marshal(block)
+	blockData, err := EncodeBlock(block)
 	if err != nil {
-		return fmt.Errorf("error serializando bloque: %w", err)
+		return nil, fmt.Errorf("error serializando bloque: %w", err)
 	}
 
-	if err := app.storage.SaveBlock(app.currentBlockHeight, blockData); err != nil {
-		return fmt.Errorf("error guardando bloque: %w", err)
+	if err := app.storage.SaveCanonicalBlock(app.currentBlockHeight, blockHashStr, blockData); err != nil {
+		return nil, fmt.Errorf("error guardando bloque: %w", err)
 	}
 
 	// Guardar altura del último bloque
 	if err := app.storage.SaveLatestHeight(app.currentBlockHeight); err != nil {
-		logger.Warn("Error guardando altura: " + err.Error())
+		consensusLog.Warn("Error guardando altura: " + err.Error())
 	}
 
-	logger.Info(fmt.Sprintf("Bloque guardado: height=%d, hash=%s, transactions=%d",
+	consensusLog.Info(fmt.Sprintf("Bloque guardado: height=%d, hash=%s, transactions=%d",
 		app.currentBlockHeight, blockHashStr[:8], len(app.currentBlockTxs)))
 
-	return nil
+	return block, nil
 }
 
 // convertLogs convierte logs de execution a consensus
@@ -578,7 +873,7 @@ func (app *ABCIApp) Query(ctx context.Context, req *abcitypes.QueryRequest) (*ab
 
 	case len(path) > 8 && path[:8] == "balance/":
 		address := path[8:]
-		accountState, err := app.executor.GetState(address)
+		accountState, err := app.executor.GetCommittedState(address)
 		if err != nil {
 			return &abcitypes.QueryResponse{
 				Code: 1,
@@ -599,7 +894,7 @@ func (app *ABCIApp) Query(ctx context.Context, req *abcitypes.QueryRequest) (*ab
 
 	case len(path) > 7 && path[:7] == "account/":
 		address := path[7:]
-		accountState, err := app.executor.GetState(address)
+		accountState, err := app.executor.GetCommittedState(address)
 		if err != nil {
 			return &abcitypes.QueryResponse{
 				Code: 1,
@@ -616,6 +911,9 @@ func (app *ABCIApp) Query(ctx context.Context, req *abcitypes.QueryRequest) (*ab
 	case len(path) > 3 && path[:3] == "tx/":
 		txHash := path[3:]
 		txData, err := app.storage.GetTransaction(txHash)
+		if err == nil {
+			txData, err = TransactionJSON(txData)
+		}
 		if err != nil {
 			return &abcitypes.QueryResponse{
 				Code: 1,
@@ -633,6 +931,9 @@ func (app *ABCIApp) Query(ctx context.Context, req *abcitypes.QueryRequest) (*ab
 		fmt.Sscanf(path[6:], "%d", &height)
 
 		blockData, err := app.storage.GetBlock(height)
+		if err == nil {
+			blockData, err = BlockJSON(blockData)
+		}
 		if err != nil {
 			return &abcitypes.QueryResponse{
 				Code: 1,
@@ -655,41 +956,108 @@ func (app *ABCIApp) Query(ctx context.Context, req *abcitypes.QueryRequest) (*ab
 
 // CheckTx valida una transacción sin ejecutarla (nueva API v1.0.1)
 func (app *ABCIApp) CheckTx(ctx context.Context, req *abcitypes.CheckTxRequest) (*abcitypes.CheckTxResponse, error) {
+	// Pedido de bloque del modo desarrollo (no es una transacción)
+	if isDevBlockRequest(req.Tx) {
+		return app.checkDevBlockRequest(req.Type), nil
+	}
+
 	var tx Transaction
 	if err := json.Unmarshal(req.Tx, &tx); err != nil {
+		return checkTxError(CodeDecodeError, fmt.Sprintf("Error decodificando transacción: %v", err)), nil
+	}
+
+	// Rechazar transacciones ya incluidas dentro de la ventana de duplicados
+	if app.recentTxs.Contains(tx.Hash) {
+		return checkTxError(CodeDuplicateTx, fmt.Sprintf("Transacción duplicada: %s ya fue incluida en un bloque reciente", tx.Hash)), nil
+	}
+
+	// Recheck: la transacción ya pasó la validación completa (firma, hash) al entrar al mempool.
+	// Después de cada commit solo revalidamos lo que depende del estado (nonce y balance)
+	// para que CometBFT expulse las transacciones que ya no son válidas.
+	if req.Type == abcitypes.CHECK_TX_TYPE_RECHECK {
+		if err := app.recheckTransaction(&tx); err != nil {
+			return checkTxError(ErrorCode(err, CodeInvalidTx), fmt.Sprintf("Transacción inválida tras recheck: %v", err)), nil
+		}
 		return &abcitypes.CheckTxResponse{
-			Code: 1,
-			Log:  fmt.Sprintf("Error decodificando transacción: %v", err),
+			Code: CodeOK,
+			Log:  "OK",
 		}, nil
 	}
 
+	// Con la cadena detenida no se aceptan transacciones nuevas
+	if err := app.checkNotHalted(); err != nil {
+		return checkTxError(CodeUnavailable, fmt.Sprintf("Transacción rechazada: %v", err)), nil
+	}
+
+	// El tamaño del mempool no se controla aquí: CometBFT rechaza las transacciones
+	// antes de llamar a CheckTx cuando alcanza mempool.size (configurado con el mismo límite)
+
 	// Validación completa de transacción
 	if err := app.validateTransactionComplete(&tx); err != nil {
-		return &abcitypes.CheckTxResponse{
-			Code: 2,
-			Log:  fmt.Sprintf("Transacción inválida: %v", err),
-		}, nil
+		return checkTxError(ErrorCode(err, CodeInvalidTx), fmt.Sprintf("Transacción inválida: %v", err)), nil
 	}
 
+	// El rate limit por dirección no se aplica aquí sino al entrar por el API REST o la mesh
+	// (SubmitTransaction): CheckTx también recibe las transacciones de otros nodos por P2P
+
 	return &abcitypes.CheckTxResponse{
-		Code: 0,
+		Code: CodeOK,
 		Log:  "OK",
 	}, nil
 }
 
+// checkTxError construye una respuesta CheckTx de error con el código del registro
+// Info lleva la razón legible por máquina (ej: "insufficient_funds")
+func checkTxError(code uint32, log string) *abcitypes.CheckTxResponse {
+	return &abcitypes.CheckTxResponse{
+		Code:      code,
+		Codespace: Codespace,
+		Log:       log,
+		Info:      CodeReason(code),
+	}
+}
+
+// execTxError construye un resultado de ejecución de error con el código del registro
+// Incluye un evento "tx_error" para que los clientes puedan filtrar por razón
+func execTxError(code uint32, log string) *abcitypes.ExecTxResult {
+	return &abcitypes.ExecTxResult{
+		Code:      code,
+		Codespace: Codespace,
+		Log:       log,
+		Info:      CodeReason(code),
+		Events:    []abcitypes.Event{txErrorEvent(code)},
+	}
+}
+
+// txErrorEvent construye el evento con el código y la razón de falla de una transacción
+func txErrorEvent(code uint32) abcitypes.Event {
+	return abcitypes.Event{
+		Type: EventTypeTxError,
+		Attributes: []abcitypes.EventAttribute{
+			{Key: "codespace", Value: Codespace},
+			indexedAttr("code", fmt.Sprintf("%d", code)),
+			indexedAttr("reason", CodeReason(code)),
+		},
+	}
+}
+
 // validateTransaction valida una transacción básica
 func (app *ABCIApp) validateTransaction(tx *Transaction) error {
 	// Validaciones básicas
 	if tx.From == "" {
-		return fmt.Errorf("dirección remitente vacía")
+		return NewTxError(CodeInvalidTx, "dirección remitente vacía")
 	}
 
 	if !common.IsHexAddress(tx.From) {
-		return fmt.Errorf("dirección remitente inválida: %s", tx.From)
+		return NewTxError(CodeInvalidTx, "dirección remitente inválida: %s", tx.From)
 	}
 
 	if tx.To != "" && !common.IsHexAddress(tx.To) {
-		return fmt.Errorf("dirección destino inválida: %s", tx.To)
+		return NewTxError(CodeInvalidTx, "dirección destino inválida: %s", tx.To)
+	}
+
+	if err := tx.AccessList.Validate(); err != nil {
+		return NewTxError(CodeInvalidTx, "%v", err)
 	}
 
 	return nil
@@ -702,115 +1070,234 @@ func (app *ABCIApp) validateTransactionComplete(tx *Transaction) error {
 		return err
 	}
 
+	// Límites anti-spam y lista de compliance antes de verificar firmas o consultar el estado
+	if err := app.validatePolicy(tx, "CheckTx"); err != nil {
+		return err
+	}
+
 	// Validar que tenga hash
 	if tx.Hash == "" {
-		return fmt.Errorf("transacción sin hash")
+		return NewTxError(CodeInvalidHash, "transacción sin hash")
+	}
+
+	// Validar nonce y balance contra el estado actual
+	if err := app.validateAgainstState(tx); err != nil {
+		return err
+	}
+
+	// Despliegues, cambios del registro de deployers y contratos pausados
+	if err := app.checkDeployerPolicy(tx); err != nil {
+		return err
+	}
+	if err := app.checkContractPause(tx); err != nil {
+		return err
+	}
+	if err := app.checkVestingSpend(tx, true); err != nil {
+		return err
 	}
 
+	// Transacción patrocinada: el fee payer autoriza pagar el gas
+	if isSponsoredTx(tx) {
+		if err := validateFeePayer(tx); err != nil {
+			return err
+		}
+	}
+
+	// Las multisig se autorizan con las firmas de sus owners
+	if isMultisigTx(tx) {
+		return validateMultisigAuth(tx)
+	}
+
+	// Validar firma criptográfica
+	if len(tx.Signature) == 0 {
+		return NewTxError(CodeInvalidSignature, "transacción sin firma")
+	}
+
+	// Convertir transacción a mapa para validación de firma (el mismo que firma pkg/txsign)
+	fields := signedFields(tx)
+	txMap := fields.Map()
+	txMap["hash"] = tx.Hash
+	txMap["signature"] = tx.Signature
+
+	// Verificar firma
+	_, err := cryptosigner.VerifyTransactionSignature(txMap)
+	if err != nil {
+		return NewTxError(CodeInvalidSignature, "firma criptográfica inválida: %v", err)
+	}
+
+	// Verificar que el hash de la transacción sea correcto
+	// Calcular hash esperado
+	expectedHash, err := fields.Hash()
+	if err != nil {
+		return NewTxError(CodeInvalidHash, "error calculando hash de transacción: %v", err)
+	}
+
+	// Comparar hash
+	if tx.Hash != expectedHash.Hex() {
+		return NewTxError(CodeInvalidHash, "hash de transacción inválido: esperado %s, tiene %s", expectedHash.Hex(), tx.Hash)
+	}
+
+	return nil
+}
+
+// TransactionHash calcula el hash de una transacción a partir de los campos que cubre su firma
+// (el valor que CheckTx exige en Hash)
+func TransactionHash(tx *Transaction) (string, error) {
+	hash, err := signedFields(tx).Hash()
+	if err != nil {
+		return "", err
+	}
+	return hash.Hex(), nil
+}
+
+// signedFields retorna los campos de una transacción que cubre su hash firmado
+// La access list forma parte del hash solo si la transacción la trae
+func signedFields(tx *Transaction) *cryptosigner.TxFields {
+	fields := &cryptosigner.TxFields{
+		From:     tx.From,
+		To:       tx.To,
+		Value:    tx.Value,
+		Data:     tx.Data,
+		GasLimit: tx.GasLimit,
+		GasPrice: tx.GasPrice,
+		Nonce:    tx.Nonce,
+	}
+	for _, tuple := range tx.AccessList {
+		fields.AccessList = append(fields.AccessList, cryptosigner.AccessTuple{Address: tuple.Address, StorageKeys: tuple.StorageKeys})
+	}
+	if tx.FeePayer != nil {
+		fields.FeePayer = tx.FeePayer.Address
+	}
+	return fields
+}
+
+// validateAgainstState valida nonce y balance de una transacción contra el último estado confirmado
+// (CheckTx puede correr mientras se ejecuta un bloque)
+func (app *ABCIApp) validateAgainstState(tx *Transaction) error {
 	// Validar nonce (obtener nonce actual de la cuenta)
-	accountState, err := app.executor.GetState(tx.From)
+	accountState, err := app.executor.GetCommittedState(tx.From)
 	if err == nil && accountState != nil {
 		if tx.Nonce < accountState.Nonce {
-			return fmt.Errorf("nonce inválido: esperado >= %d, tiene %d", accountState.Nonce, tx.Nonce)
+			return NewTxError(CodeInvalidNonce, "nonce inválido: esperado >= %d, tiene %d", accountState.Nonce, tx.Nonce)
 		}
 	}
 
 	// Validar balance suficiente (si hay transferencia de valor)
 	if tx.Value != "" && tx.Value != "0" {
 		if accountState == nil {
-			return fmt.Errorf("cuenta no encontrada: %s", tx.From)
+			return NewTxError(CodeInsufficientFunds, "cuenta no encontrada: %s", tx.From)
 		}
 
 		// Parsear valor
 		value, ok := new(big.Int).SetString(tx.Value, 10)
 		if !ok {
-			return fmt.Errorf("valor inválido: %s", tx.Value)
+			return NewTxError(CodeInvalidTx, "valor inválido: %s", tx.Value)
 		}
 
 		// Parsear balance
 		balance, ok := new(big.Int).SetString(accountState.Balance, 10)
 		if !ok {
-			return fmt.Errorf("balance inválido: %s", accountState.Balance)
+			return NewTxError(CodeExecutionError, "balance inválido: %s", accountState.Balance)
 		}
 
 		// Calcular gas cost
 		gasPrice, ok := new(big.Int).SetString(tx.GasPrice, 10)
 		if !ok {
-			return fmt.Errorf("gas price inválido: %s", tx.GasPrice)
+			return NewTxError(CodeInvalidTx, "gas price inválido: %s", tx.GasPrice)
 		}
 
 		gasCost := new(big.Int).Mul(gasPrice, big.NewInt(int64(tx.GasLimit)))
 		totalCost := new(big.Int).Add(value, gasCost)
+		if isSponsoredTx(tx) {
+			// El gas lo paga el fee payer
+			totalCost = value
+		}
 
 		// Validar balance suficiente
 		if balance.Cmp(totalCost) < 0 {
-			return fmt.Errorf("balance insuficiente: tiene %s, necesita %s", balance.String(), totalCost.String())
+			return NewTxError(CodeInsufficientFunds, "balance insuficiente: tiene %s, necesita %s", balance.String(), totalCost.String())
 		}
 	}
 
-	// Validar firma criptográfica
-	if len(tx.Signature) == 0 {
-		return fmt.Errorf("transacción sin firma")
+	if isSponsoredTx(tx) {
+		return app.validateFeePayerBalance(tx)
 	}
+	return nil
+}
 
-	// Convertir transacción a mapa para validación de firma
-	txMap := map[string]interface{}{
-		"hash":      tx.Hash,
-		"from":      tx.From,
-		"to":        tx.To,
-		"value":     tx.Value,
-		"data":      tx.Data,
-		"gasLimit":  tx.GasLimit,
-		"gasPrice":  tx.GasPrice,
-		"nonce":     tx.Nonce,
-		"signature": tx.Signature,
+// validateFeePayerBalance valida que el fee payer de una transacción patrocinada cubra gasLimit * gasPrice
+func (app *ABCIApp) validateFeePayerBalance(tx *Transaction) error {
+	gasPrice, ok := new(big.Int).SetString(tx.GasPrice, 10)
+	if !ok {
+		return NewTxError(CodeInvalidTx, "gas price inválido: %s", tx.GasPrice)
 	}
-
-	// Verificar firma
-	_, err = cryptosigner.VerifyTransactionSignature(txMap)
-	if err != nil {
-		return fmt.Errorf("firma criptográfica inválida: %w", err)
+	gasCost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(tx.GasLimit))
+	if gasCost.Sign() == 0 {
+		return nil
 	}
 
-	// Verificar que el hash de la transacción sea correcto
-	// Calcular hash esperado
-	expectedHash, err := cryptosigner.CalculateTransactionHash(txMap)
-	if err != nil {
-		return fmt.Errorf("error calculando hash de transacción: %w", err)
+	payerState, err := app.executor.GetCommittedState(tx.FeePayer.Address)
+	if err != nil || payerState == nil {
+		return NewTxError(CodeInsufficientFunds, "fee payer no encontrado: %s", tx.FeePayer.Address)
 	}
-
-	// Comparar hash
-	if tx.Hash != expectedHash.Hex() {
-		return fmt.Errorf("hash de transacción inválido: esperado %s, tiene %s", expectedHash.Hex(), tx.Hash)
+	balance, ok := new(big.Int).SetString(payerState.Balance, 10)
+	if !ok {
+		return NewTxError(CodeExecutionError, "balance inválido: %s", payerState.Balance)
+	}
+	if balance.Cmp(gasCost) < 0 {
+		return NewTxError(CodeInsufficientFunds, "balance insuficiente del fee payer %s: tiene %s, necesita %s", tx.FeePayer.Address, balance.String(), gasCost.String())
 	}
-
 	return nil
 }
 
-// buildEvents construye eventos a partir del resultado de ejecución
-func (app *ABCIApp) buildEvents(result *execution.ExecutionResult) []abcitypes.Event {
-	events := []abcitypes.Event{}
+// recheckTransaction revalida una transacción que ya está en el mempool después de un commit.
+// Solo verifica las condiciones que dependen del estado (nonce y balance); la firma y el hash
+// no cambian entre bloques y ya fueron verificados en el CheckTx inicial.
+func (app *ABCIApp) recheckTransaction(tx *Transaction) error {
+	if err := app.validateTransaction(tx); err != nil {
+		return err
+	}
+	if app.recentTxs.Contains(tx.Hash) {
+		return NewTxError(CodeDuplicateTx, "transacción duplicada: %s ya fue incluida en un bloque reciente", tx.Hash)
+	}
+	// La lista de compliance puede cambiar después de admitir la transacción
+	if err := app.checkCompliance(tx, "recheck"); err != nil {
+		return err
+	}
+	return app.validateAgainstState(tx)
+}
+
+// validatePolicy verifica las reglas del nodo que no dependen del estado ni de las firmas: los límites
+// anti-spam y la lista de compliance. stage identifica la etapa en el log de auditoría
+// Es configuración local: se aplica en CheckTx y PrepareProposal, pero no en ProcessProposal, para que un
+// validador con otros valores no rechace los bloques válidos que proponen los demás
+func (app *ABCIApp) validatePolicy(tx *Transaction, stage string) error {
+	if err := app.txLimits.Validate(tx); err != nil {
+		return err
+	}
+	return app.checkCompliance(tx, stage)
+}
 
-	// Evento de ejecución
-	events = append(events, abcitypes.Event{
-		Type: "execution",
-		Attributes: []abcitypes.EventAttribute{
-			{Key: "success", Value: fmt.Sprintf("%t", result.Success)},
-			{Key: "gas_used", Value: fmt.Sprintf("%d", result.GasUsed)},
-		},
-	})
+// recheckLocalMempool revalida el mempool local contra el estado recién confirmado
+// y remueve las transacciones que ya no son válidas. Retorna cuántas fueron expulsadas.
+func (app *ABCIApp) recheckLocalMempool() int {
+	if app.getMempool == nil || app.clearMempoolTx == nil {
+		return 0
+	}
 
-	// Eventos de logs de contratos
-	for _, log := range result.Logs {
-		events = append(events, abcitypes.Event{
-			Type: "contract_log",
-			Attributes: []abcitypes.EventAttribute{
-				{Key: "address", Value: log.Address},
-			},
-		})
+	evicted := 0
+	for _, tx := range app.getMempool() {
+		if err := app.recheckTransaction(tx); err != nil {
+			app.clearMempoolTx(tx.Hash)
+			evicted++
+			consensusLog.Debugf("Transacción expulsada del mempool tras recheck: hash=%s, razón=%v", tx.Hash, err)
+		}
 	}
 
-	return events
+	if evicted > 0 {
+		consensusLog.Info(fmt.Sprintf("Recheck del mempool: %d transacciones expulsadas", evicted))
+	}
+	return evicted
 }
 
 // PrepareProposal prepara una propuesta de bloque (nueva API v1.0.1)
@@ -820,6 +1307,13 @@ func (app *ABCIApp) PrepareProposal(ctx context.Context, req *abcitypes.PrepareP
 
 	txs := make([][]byte, 0)
 	var totalBytes int64
+	proposedHashes := make(map[string]bool)
+
+	// Con la cadena detenida la propuesta va vacía (igual la rechaza ProcessProposal)
+	if app.haltedFor(req.Height) {
+		consensusLog.Warnf("Cadena detenida: propuesta vacía para el bloque %d", req.Height)
+		return &abcitypes.PrepareProposalResponse{Txs: txs}, nil
+	}
 
 	// Primero, agregar transacciones del mempool local si está disponible
 	if app.getMempool != nil {
@@ -828,6 +1322,18 @@ func (app *ABCIApp) PrepareProposal(ctx context.Context, req *abcitypes.PrepareP
 		os.Stdout.Sync()
 
 		for i, tx := range localMempool {
+			// Saltar transacciones ya incluidas o repetidas en esta propuesta
+			if app.recentTxs.Contains(tx.Hash) || proposedHashes[tx.Hash] {
+				continue
+			}
+
+			// Saltar transacciones fuera de los límites o de la lista de compliance (async no pasa por CheckTx):
+			// harían rechazar la propuesta
+			if err := app.validatePolicy(tx, "PrepareProposal"); err != nil {
+				consensusLog.Debugf("Transacción %s excluida de la propuesta: %v", tx.Hash, err)
+				continue
+			}
+
 			// Serializar transacción a JSON
 			txBytes, err := json.Marshal(tx)
 			if err != nil {
@@ -845,6 +1351,7 @@ func (app *ABCIApp) PrepareProposal(ctx context.Context, req *abcitypes.PrepareP
 
 			txs = append(txs, txBytes)
 			totalBytes += int64(len(txBytes))
+			proposedHashes[tx.Hash] = true
 			fmt.Fprintf(os.Stdout, "[ABCI] Transacción %s agregada a propuesta (total: %d bytes)\n", tx.Hash, totalBytes)
 			os.Stdout.Sync()
 		}
@@ -855,6 +1362,11 @@ func (app *ABCIApp) PrepareProposal(ctx context.Context, req *abcitypes.PrepareP
 
 	// Luego, agregar transacciones que vienen de CometBFT (si hay espacio)
 	for _, tx := range req.Txs {
+		// Los pedidos de bloque del modo desarrollo solo disparan la propuesta
+		if isDevBlockRequest(tx) {
+			continue
+		}
+
 		if totalBytes+int64(len(tx)) > req.MaxTxBytes {
 			break
 		}
@@ -868,9 +1380,23 @@ func (app *ABCIApp) PrepareProposal(ctx context.Context, req *abcitypes.PrepareP
 			}
 		}
 
+		// Evitar duplicados por hash (misma transacción con distinta serialización o ya incluida)
+		var decoded Transaction
+		if !isDuplicate && json.Unmarshal(tx, &decoded) == nil {
+			isDuplicate = proposedHashes[decoded.Hash] || app.recentTxs.Contains(decoded.Hash)
+		}
+
+		// Saltar transacciones fuera de los límites anti-spam o de la lista de compliance
+		if !isDuplicate && decoded.Hash != "" && app.validatePolicy(&decoded, "PrepareProposal") != nil {
+			continue
+		}
+
 		if !isDuplicate {
 			txs = append(txs, tx)
 			totalBytes += int64(len(tx))
+			if decoded.Hash != "" {
+				proposedHashes[decoded.Hash] = true
+			}
 		}
 	}
 
@@ -885,7 +1411,43 @@ func (app *ABCIApp) ProcessProposal(ctx context.Context, req *abcitypes.ProcessP
 	fmt.Fprintf(os.Stdout, "[ABCI] ProcessProposal llamado: height=%d, txs=%d\n", req.Height, len(req.Txs))
 	os.Stdout.Sync()
 
-	// Por ahora, aceptamos todas las propuestas
+	// Rechazar toda propuesta posterior a la detención programada de la cadena
+	if app.haltedFor(req.Height) {
+		fmt.Fprintf(os.Stderr, "[ABCI] ProcessProposal rechazando propuesta para bloque %d: cadena detenida\n", req.Height)
+		os.Stderr.Sync()
+		return &abcitypes.ProcessProposalResponse{
+			Status: abcitypes.PROCESS_PROPOSAL_STATUS_REJECT,
+		}, nil
+	}
+
+	// Rechazar propuestas con una hora manipulada por el proponente (en desarrollo la hora la ajusta el devClock)
+	if !app.devMode {
+		if err := app.validateProposalTime(req.Height, req.Time); err != nil {
+			fmt.Fprintf(os.Stderr, "[ABCI] ProcessProposal rechazando propuesta para bloque %d: %v\n", req.Height, err)
+			os.Stderr.Sync()
+			return &abcitypes.ProcessProposalResponse{
+				Status: abcitypes.PROCESS_PROPOSAL_STATUS_REJECT,
+			}, nil
+		}
+	}
+
+	// Rechazar propuestas con transacciones duplicadas (dentro de la propuesta o ya incluidas)
+	seen := make(map[string]bool, len(req.Txs))
+	for _, txBytes := range req.Txs {
+		var tx Transaction
+		if err := json.Unmarshal(txBytes, &tx); err != nil {
+			continue // FinalizeBlock marcará la transacción como inválida
+		}
+		if seen[tx.Hash] || app.recentTxs.Contains(tx.Hash) {
+			fmt.Fprintf(os.Stderr, "[ABCI] ProcessProposal rechazando propuesta para bloque %d: transacción duplicada %s\n", req.Height, tx.Hash)
+			os.Stderr.Sync()
+			return &abcitypes.ProcessProposalResponse{
+				Status: abcitypes.PROCESS_PROPOSAL_STATUS_REJECT,
+			}, nil
+		}
+		seen[tx.Hash] = true
+	}
+
 	response := &abcitypes.ProcessProposalResponse{
 		Status: abcitypes.PROCESS_PROPOSAL_STATUS_ACCEPT,
 	}
@@ -910,28 +1472,103 @@ func (app *ABCIApp) VerifyVoteExtension(ctx context.Context, req *abcitypes.Veri
 	}, nil
 }
 
-// ListSnapshots retorna snapshots disponibles (nueva API v1.0.1)
+// ListSnapshots retorna los snapshots locales que se ofrecen a otros nodos por state sync
 func (app *ABCIApp) ListSnapshots(ctx context.Context, req *abcitypes.ListSnapshotsRequest) (*abcitypes.ListSnapshotsResponse, error) {
-	return &abcitypes.ListSnapshotsResponse{}, nil
+	if app.snapshotStore == nil {
+		return &abcitypes.ListSnapshotsResponse{}, nil
+	}
+
+	snapshots, err := app.snapshotStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &abcitypes.ListSnapshotsResponse{}
+	for _, meta := range snapshots {
+		abciSnapshot, err := toABCISnapshot(meta)
+		if err != nil {
+			return nil, err
+		}
+		resp.Snapshots = append(resp.Snapshots, abciSnapshot)
+	}
+	return resp, nil
 }
 
-// OfferSnapshot ofrece un snapshot (nueva API v1.0.1)
+// OfferSnapshot acepta un snapshot de state sync si su app hash coincide con el verificado por el light client
 func (app *ABCIApp) OfferSnapshot(ctx context.Context, req *abcitypes.OfferSnapshotRequest) (*abcitypes.OfferSnapshotResponse, error) {
-	return &abcitypes.OfferSnapshotResponse{
-		Result: abcitypes.OFFER_SNAPSHOT_RESULT_REJECT,
-	}, nil
+	if app.snapshotStore == nil || req.Snapshot == nil {
+		return &abcitypes.OfferSnapshotResponse{Result: abcitypes.OFFER_SNAPSHOT_RESULT_ABORT}, nil
+	}
+	if req.Snapshot.Format != snapshot.Format {
+		return &abcitypes.OfferSnapshotResponse{Result: abcitypes.OFFER_SNAPSHOT_RESULT_REJECT_FORMAT}, nil
+	}
+
+	meta, err := checkOfferedSnapshot(req.Snapshot, req.AppHash)
+	if err != nil {
+		consensusLog.Warn(fmt.Sprintf("Snapshot ofrecido en altura %d rechazado: %v", req.Snapshot.Height, err))
+		return &abcitypes.OfferSnapshotResponse{Result: abcitypes.OFFER_SNAPSHOT_RESULT_REJECT}, nil
+	}
+
+	// Un snapshot nuevo reemplaza al que se estuviera recibiendo
+	if app.snapshotReceiver != nil {
+		app.snapshotReceiver.Abort()
+		app.snapshotReceiver = nil
+	}
+	receiver, err := app.snapshotStore.Receive(meta)
+	if err != nil {
+		return nil, err
+	}
+	app.snapshotReceiver = receiver
+
+	return &abcitypes.OfferSnapshotResponse{Result: abcitypes.OFFER_SNAPSHOT_RESULT_ACCEPT}, nil
 }
 
-// LoadSnapshotChunk carga un chunk de snapshot (nueva API v1.0.1)
+// LoadSnapshotChunk retorna un chunk de un snapshot local
 func (app *ABCIApp) LoadSnapshotChunk(ctx context.Context, req *abcitypes.LoadSnapshotChunkRequest) (*abcitypes.LoadSnapshotChunkResponse, error) {
-	return &abcitypes.LoadSnapshotChunkResponse{}, nil
+	if app.snapshotStore == nil {
+		return &abcitypes.LoadSnapshotChunkResponse{}, nil
+	}
+
+	chunk, err := app.snapshotStore.LoadChunk(req.Height, req.Format, req.Chunk)
+	if err != nil {
+		// Sin chunk: CometBFT lo pide a otro peer
+		consensusLog.Warn("Error cargando chunk de snapshot: " + err.Error())
+		return &abcitypes.LoadSnapshotChunkResponse{}, nil
+	}
+	return &abcitypes.LoadSnapshotChunkResponse{Chunk: chunk}, nil
 }
 
-// ApplySnapshotChunk aplica un chunk de snapshot (nueva API v1.0.1)
+// ApplySnapshotChunk agrega un chunk al snapshot aceptado; con el último chunk verifica el hash y lo restaura
 func (app *ABCIApp) ApplySnapshotChunk(ctx context.Context, req *abcitypes.ApplySnapshotChunkRequest) (*abcitypes.ApplySnapshotChunkResponse, error) {
-	return &abcitypes.ApplySnapshotChunkResponse{
-		Result: abcitypes.APPLY_SNAPSHOT_CHUNK_RESULT_REJECT_SNAPSHOT,
-	}, nil
+	receiver := app.snapshotReceiver
+	if receiver == nil {
+		return &abcitypes.ApplySnapshotChunkResponse{Result: abcitypes.APPLY_SNAPSHOT_CHUNK_RESULT_ABORT}, nil
+	}
+
+	if err := receiver.WriteChunk(req.Index, req.Chunk); err != nil {
+		consensusLog.Warn("Chunk de snapshot inválido: " + err.Error())
+		app.snapshotReceiver = nil
+		receiver.Abort()
+		return &abcitypes.ApplySnapshotChunkResponse{Result: abcitypes.APPLY_SNAPSHOT_CHUNK_RESULT_REJECT_SNAPSHOT}, nil
+	}
+	if !receiver.Done() {
+		return &abcitypes.ApplySnapshotChunkResponse{Result: abcitypes.APPLY_SNAPSHOT_CHUNK_RESULT_ACCEPT}, nil
+	}
+
+	app.snapshotReceiver = nil
+	meta := receiver.Metadata()
+	if err := receiver.Commit(); err != nil {
+		// El contenido no coincide con el hash anunciado: descartar el snapshot
+		consensusLog.Warn("Snapshot recibido inválido: " + err.Error())
+		return &abcitypes.ApplySnapshotChunkResponse{Result: abcitypes.APPLY_SNAPSHOT_CHUNK_RESULT_REJECT_SNAPSHOT}, nil
+	}
+	if err := app.restoreSnapshot(meta); err != nil {
+		// Las bases de datos pueden haber quedado a medio importar: abortar el state sync
+		consensusLog.Error("Error restaurando snapshot: " + err.Error())
+		return &abcitypes.ApplySnapshotChunkResponse{Result: abcitypes.APPLY_SNAPSHOT_CHUNK_RESULT_ABORT}, nil
+	}
+
+	return &abcitypes.ApplySnapshotChunkResponse{Result: abcitypes.APPLY_SNAPSHOT_CHUNK_RESULT_ACCEPT}, nil
 }
 
 // hasValidatorChanges compara dos sets de validadores para detectar cambios reales
@@ -256,6 +256,11 @@ func TestABCIApp_FinalizeBlock_WithTransactions(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error en Commit: %v", err)
 	}
+
+	// Aunque haya fallado, la transacción quedó en la cadena y no puede volver a incluirse
+	if !app.recentTxs.Contains(tx.Hash) {
+		t.Error("La transacción incluida debería registrarse en el índice de duplicados aunque haya fallado")
+	}
 }
 
 // TestABCIApp_Commit_MultipleBlocks prueba múltiples commits en secuencia
@@ -569,5 +574,41 @@ func TestABCIApp_CheckTx_RateLimit(t *testing.T) {
 	} else {
 		t.Logf("CheckTx rechazó la transacción: código %d, log: %s", checkTxResp.Code, checkTxResp.Log)
 	}
+
+	// El mempool local del REST no limita CheckTx: el tamaño lo controla CometBFT (mempool.size),
+	// que también recibe transacciones por P2P
+	app.SetGetMempool(func() []*Transaction { return []*Transaction{&tx} })
+
+	checkTxResp, err = app.CheckTx(ctx, checkTxReq)
+	if err != nil {
+		t.Fatalf("Error en CheckTx: %v", err)
+	}
+	if checkTxResp.Code == CodeMempoolFull {
+		t.Errorf("CheckTx no debería depender del mempool local: código %d, log: %s", checkTxResp.Code, checkTxResp.Log)
+	}
+
+	// El rate limit por dirección se cuenta al entrar por REST o la mesh, no en CheckTx: las transacciones
+	// que llegan por P2P no consumen el cupo del remitente
+	for i := 0; i < DefaultRateLimitPerAddress+1; i++ {
+		checkTxResp, err = app.CheckTx(ctx, checkTxReq)
+		if err != nil {
+			t.Fatalf("Error en CheckTx: %v", err)
+		}
+		if checkTxResp.Code == CodeRateLimited {
+			t.Fatalf("CheckTx no debería aplicar rate limiting: intento %d", i+1)
+		}
+	}
+
+	// El recheck no debe verse afectado por los límites del mempool
+	checkTxResp, err = app.CheckTx(ctx, &abcitypes.CheckTxRequest{
+		Tx:   txData,
+		Type: abcitypes.CHECK_TX_TYPE_RECHECK,
+	})
+	if err != nil {
+		t.Fatalf("Error en CheckTx recheck: %v", err)
+	}
+	if checkTxResp.Code == CodeMempoolFull || checkTxResp.Code == CodeRateLimited {
+		t.Errorf("Recheck no debería aplicar rate limiting: código %d", checkTxResp.Code)
+	}
 }
 
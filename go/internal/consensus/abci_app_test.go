@@ -236,6 +236,90 @@ func TestABCIApp_CheckTx(t *testing.T) {
 	}
 }
 
+// TestABCIApp_CheckTx_Recheck prueba la revalidación de transacciones tras un commit
+func TestABCIApp_CheckTx_Recheck(t *testing.T) {
+	ctx := context.Background()
+
+	testDir := createTestDir("checktx_recheck")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio de test: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+
+	app := NewABCIApp(db, evm, nil, "test-chain")
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Error generando clave: %v", err)
+	}
+	fromAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	// Transacción sin valor: sigue siendo válida en recheck (no depende del balance)
+	zeroValueTx := Transaction{
+		From:     fromAddr.Hex(),
+		To:       common.HexToAddress("0x0000000000000000000000000000000000000001").Hex(),
+		Value:    "0",
+		GasLimit: 21000,
+		GasPrice: "1000000000",
+		Hash:     "0xaaaa",
+	}
+	zeroValueData, _ := json.Marshal(zeroValueTx)
+
+	resp, err := app.CheckTx(ctx, &abcitypes.CheckTxRequest{
+		Tx:   zeroValueData,
+		Type: abcitypes.CHECK_TX_TYPE_RECHECK,
+	})
+	if err != nil {
+		t.Fatalf("Error en CheckTx recheck: %v", err)
+	}
+	if resp.Code != 0 {
+		t.Errorf("Recheck debería aceptar transacción sin valor, obtuvo código %d: %s", resp.Code, resp.Log)
+	}
+
+	// Transacción con valor desde una cuenta sin fondos: debe ser expulsada en recheck
+	valueTx := zeroValueTx
+	valueTx.Hash = "0xbbbb"
+	valueTx.Value = "1000000000000000000"
+	valueData, _ := json.Marshal(valueTx)
+
+	resp, err = app.CheckTx(ctx, &abcitypes.CheckTxRequest{
+		Tx:   valueData,
+		Type: abcitypes.CHECK_TX_TYPE_RECHECK,
+	})
+	if err != nil {
+		t.Fatalf("Error en CheckTx recheck: %v", err)
+	}
+	if resp.Code == 0 {
+		t.Error("Recheck debería rechazar transacción sin balance suficiente")
+	}
+
+	// El recheck del mempool local debe remover solo la transacción inválida
+	mempool := []*Transaction{&zeroValueTx, &valueTx}
+	removed := make(map[string]bool)
+	app.SetGetMempool(func() []*Transaction { return mempool })
+	app.SetClearMempoolTx(func(hash string) { removed[hash] = true })
+
+	if evicted := app.recheckLocalMempool(); evicted != 1 {
+		t.Errorf("Se esperaba 1 transacción expulsada, obtuvo %d", evicted)
+	}
+	if !removed[valueTx.Hash] || removed[zeroValueTx.Hash] {
+		t.Errorf("Transacciones removidas incorrectas: %v", removed)
+	}
+}
+
 // TestABCIApp_Query prueba el sistema de queries
 func TestABCIApp_Query(t *testing.T) {
 	ctx := context.Background()
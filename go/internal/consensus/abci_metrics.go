@@ -0,0 +1,103 @@
+package consensus
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/metrics"
+	"github.com/Q-YZX0/oxy-blockchain/internal/tracing"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// instrumentedApp envuelve la aplicación ABCI que recibe CometBFT y mide la latencia y el código de resultado
+// de cada fase del consenso; FinalizeBlock y Commit además abren un span de tracing.
+// El resto de los métodos pasan directo a ABCIApp
+type instrumentedApp struct {
+	*ABCIApp
+}
+
+// newInstrumentedApp crea la aplicación instrumentada (las métricas se leen en cada llamada: SetMetrics puede llegar después)
+func newInstrumentedApp(app *ABCIApp) *instrumentedApp {
+	return &instrumentedApp{ABCIApp: app}
+}
+
+// observe registra una llamada si hay métricas configuradas
+func (a *instrumentedApp) observe(method string, start time.Time, code string, err error) {
+	if a.metrics == nil {
+		return
+	}
+	if err != nil {
+		code = metrics.ABCICodeError
+	}
+	a.metrics.ObserveABCI(method, code, time.Since(start))
+}
+
+// abciCode convierte un código de respuesta ABCI en la etiqueta de la métrica
+func abciCode(code uint32) string {
+	return strconv.FormatUint(uint64(code), 10)
+}
+
+// Info implementa abcitypes.Application
+func (a *instrumentedApp) Info(ctx context.Context, req *abcitypes.InfoRequest) (*abcitypes.InfoResponse, error) {
+	start := time.Now()
+	resp, err := a.ABCIApp.Info(ctx, req)
+	a.observe("Info", start, metrics.ABCICodeOK, err)
+	return resp, err
+}
+
+// CheckTx implementa abcitypes.Application
+func (a *instrumentedApp) CheckTx(ctx context.Context, req *abcitypes.CheckTxRequest) (*abcitypes.CheckTxResponse, error) {
+	start := time.Now()
+	resp, err := a.ABCIApp.CheckTx(ctx, req)
+	code := metrics.ABCICodeOK
+	if resp != nil {
+		code = abciCode(resp.Code)
+	}
+	a.observe("CheckTx", start, code, err)
+	return resp, err
+}
+
+// PrepareProposal implementa abcitypes.Application
+func (a *instrumentedApp) PrepareProposal(ctx context.Context, req *abcitypes.PrepareProposalRequest) (*abcitypes.PrepareProposalResponse, error) {
+	start := time.Now()
+	resp, err := a.ABCIApp.PrepareProposal(ctx, req)
+	a.observe("PrepareProposal", start, metrics.ABCICodeOK, err)
+	return resp, err
+}
+
+// ProcessProposal implementa abcitypes.Application
+func (a *instrumentedApp) ProcessProposal(ctx context.Context, req *abcitypes.ProcessProposalRequest) (*abcitypes.ProcessProposalResponse, error) {
+	start := time.Now()
+	resp, err := a.ABCIApp.ProcessProposal(ctx, req)
+	code := metrics.ABCICodeOK
+	if resp != nil && resp.Status != abcitypes.PROCESS_PROPOSAL_STATUS_ACCEPT {
+		code = metrics.ABCICodeReject
+	}
+	a.observe("ProcessProposal", start, code, err)
+	return resp, err
+}
+
+// FinalizeBlock implementa abcitypes.Application
+func (a *instrumentedApp) FinalizeBlock(ctx context.Context, req *abcitypes.FinalizeBlockRequest) (*abcitypes.FinalizeBlockResponse, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "abci.FinalizeBlock", tracing.Int64("block.height", req.Height), tracing.Int64("block.txs", int64(len(req.Txs))))
+	defer span.End()
+
+	resp, err := a.ABCIApp.FinalizeBlock(ctx, req)
+	span.RecordError(err)
+	a.observe("FinalizeBlock", start, metrics.ABCICodeOK, err)
+	return resp, err
+}
+
+// Commit implementa abcitypes.Application
+func (a *instrumentedApp) Commit(ctx context.Context, req *abcitypes.CommitRequest) (*abcitypes.CommitResponse, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "abci.Commit", tracing.Int64("block.height", int64(a.currentBlockHeight)))
+	defer span.End()
+
+	resp, err := a.ABCIApp.Commit(ctx, req)
+	span.RecordError(err)
+	a.observe("Commit", start, metrics.ABCICodeOK, err)
+	return resp, err
+}
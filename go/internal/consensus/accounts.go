@@ -0,0 +1,56 @@
+package consensus
+
+import (
+	"fmt"
+)
+
+// updateAccountIndex actualiza el índice de cuentas con las modificadas en el bloque
+// La primera vez lo crea a partir de las direcciones de los bloques ya guardados
+func (app *ABCIApp) updateAccountIndex() {
+	initialized, err := app.storage.AccountIndexInitialized()
+	if err != nil {
+		consensusLog.Warn("Error consultando índice de cuentas: " + err.Error())
+		return
+	}
+	if !initialized {
+		addresses, err := app.storedBlockAddresses()
+		if err != nil {
+			consensusLog.Warn("Error reconstruyendo índice de cuentas: " + err.Error())
+		}
+		app.executor.TouchAccounts(addresses...)
+	}
+
+	if err := app.storage.UpdateAccountIndex(app.executor.TakeTouchedBalances()); err != nil {
+		consensusLog.Warn("Error actualizando índice de cuentas: " + err.Error())
+	}
+}
+
+// storedBlockAddresses retorna las direcciones que aparecen en los bloques guardados
+// (no incluye las cuentas fondeadas fuera de bloque ni las que solo recibieron fondos de contratos)
+func (app *ABCIApp) storedBlockAddresses() ([]string, error) {
+	addresses := []string{StakingAddress}
+	latest, err := app.storage.GetLatestHeight()
+	if err != nil {
+		// Cadena nueva: no hay bloques que recorrer
+		return addresses, nil
+	}
+
+	for height := uint64(1); height <= latest; height++ {
+		data, err := app.storage.GetBlock(height)
+		if err != nil {
+			// Bloques anteriores a un snapshot restaurado
+			continue
+		}
+		block, err := DecodeBlock(data)
+		if err != nil {
+			return addresses, fmt.Errorf("bloque %d inválido: %w", height, err)
+		}
+		for _, tx := range block.Transactions {
+			addresses = append(addresses, tx.From)
+			if tx.To != "" {
+				addresses = append(addresses, tx.To)
+			}
+		}
+	}
+	return addresses, nil
+}
@@ -0,0 +1,48 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TestABCIApp_AccountIndex verifica que el commit mantenga el índice de cuentas por balance
+func TestABCIApp_AccountIndex(t *testing.T) {
+	app, _ := newSnapshotTestApp(t, "account_index")
+
+	rich := common.HexToAddress("0x1234567890123456789012345678901234567890").Hex()
+	poor := common.HexToAddress("0x0000000000000000000000000000000000000abc").Hex()
+	if err := app.executor.FundAccount(poor, "10"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	if err := app.executor.FundAccount(rich, "1000"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	commitTestBlocks(t, app, 1, 1)
+
+	accounts, err := app.storage.ListAccountsByBalance(0, 10)
+	if err != nil {
+		t.Fatalf("Error listando cuentas: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].Address != rich || accounts[0].Balance != "1000" || accounts[1].Address != poor {
+		t.Fatalf("Índice inesperado: %v", accounts)
+	}
+
+	// Los cambios de balance posteriores se reflejan en el siguiente commit
+	if err := app.executor.TransferBalance(rich, poor, big.NewInt(995)); err != nil {
+		t.Fatalf("Error transfiriendo: %v", err)
+	}
+	commitTestBlocks(t, app, 2, 2)
+
+	accounts, err = app.storage.ListAccountsByBalance(0, 1)
+	if err != nil {
+		t.Fatalf("Error listando cuentas: %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].Address != poor || accounts[0].Balance != "1005" {
+		t.Errorf("Top holder inesperado: %v", accounts)
+	}
+	if count, _ := app.storage.GetAccountCount(); count != 2 {
+		t.Errorf("Cantidad de cuentas: esperado 2, obtenido %d", count)
+	}
+}
@@ -0,0 +1,132 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// addrBookBackupFile es la copia del address book de CometBFT dentro del directorio de datos del nodo
+// Vive fuera de COMETBFT_HOME, así sobrevive a los reinicios de las bases de datos y de la configuración de CometBFT
+const addrBookBackupFile = "addrbook.backup.json"
+
+// fallbackSeeds son los seeds de respaldo compilados en el binario, con el formato de OXY_FALLBACK_SEEDS
+// Se fijan al compilar cada release:
+//
+//	go build -ldflags "-X github.com/Q-YZX0/oxy-blockchain/internal/consensus.fallbackSeeds=oxy-mainnet=id@host:26656"
+var fallbackSeeds = ""
+
+// AddrBookBackupPath retorna la ruta de la copia de respaldo del address book (vacía sin directorio de datos)
+func AddrBookBackupPath(dataDir string) string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, addrBookBackupFile)
+}
+
+// addrBookSize retorna la cantidad de direcciones de un address book (0 si no existe o no se puede leer)
+func addrBookSize(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	var book struct {
+		Addrs []json.RawMessage `json:"addrs"`
+	}
+	if err := json.Unmarshal(data, &book); err != nil {
+		return 0
+	}
+	return len(book.Addrs)
+}
+
+// backupAddrBook copia el address book al respaldo; uno vacío no reemplaza al respaldo existente
+func backupAddrBook(addrBookFile, backupFile string) error {
+	if backupFile == "" || addrBookSize(addrBookFile) == 0 {
+		return nil
+	}
+	if err := copyFileAtomic(addrBookFile, backupFile); err != nil {
+		return fmt.Errorf("error respaldando address book: %w", err)
+	}
+	return nil
+}
+
+// restoreAddrBook restaura el respaldo si el address book no existe o está vacío (datos reiniciados)
+// Retorna la cantidad de direcciones restauradas
+func restoreAddrBook(addrBookFile, backupFile string) (int, error) {
+	if backupFile == "" || addrBookSize(addrBookFile) > 0 {
+		return 0, nil
+	}
+	restored := addrBookSize(backupFile)
+	if restored == 0 {
+		return 0, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(addrBookFile), 0755); err != nil {
+		return 0, fmt.Errorf("error creando directorio del address book: %w", err)
+	}
+	if err := copyFileAtomic(backupFile, addrBookFile); err != nil {
+		return 0, fmt.Errorf("error restaurando address book: %w", err)
+	}
+	return restored, nil
+}
+
+// copyFileAtomic copia src a dst escribiendo un temporal y renombrándolo, para no dejar copias a medias
+func copyFileAtomic(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	tmp := dst + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// ParseFallbackSeeds interpreta una lista de seeds de respaldo por chain ID:
+// "chain-id=id@host:puerto,id2@host2:puerto;otra-cadena=id3@host3:puerto"
+func ParseFallbackSeeds(value string) (map[string][]string, error) {
+	seeds := make(map[string][]string)
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		chainID, list, found := strings.Cut(entry, "=")
+		chainID = strings.TrimSpace(chainID)
+		if !found || chainID == "" {
+			return nil, fmt.Errorf("entrada %q inválida (chain-id=id@host:puerto,...)", entry)
+		}
+		for _, seed := range strings.Split(list, ",") {
+			seed = strings.TrimSpace(seed)
+			if seed == "" {
+				continue
+			}
+			id, hostPort, found := strings.Cut(seed, "@")
+			if !found || id == "" {
+				return nil, fmt.Errorf("seed %q inválido (id@host:puerto)", seed)
+			}
+			if _, err := dialableHostPort(hostPort); err != nil {
+				return nil, fmt.Errorf("seed %q inválido: %w", seed, err)
+			}
+			seeds[chainID] = append(seeds[chainID], seed)
+		}
+	}
+	return seeds, nil
+}
+
+// FallbackSeeds retorna los seeds de respaldo de la cadena: los configurados (OXY_FALLBACK_SEEDS)
+// y, si no hay para ese chain ID, los compilados en el binario
+func FallbackSeeds(chainID, configured string) ([]string, error) {
+	for _, source := range []string{configured, fallbackSeeds} {
+		seeds, err := ParseFallbackSeeds(source)
+		if err != nil {
+			return nil, err
+		}
+		if list := seeds[chainID]; len(list) > 0 {
+			return list, nil
+		}
+	}
+	return nil, nil
+}
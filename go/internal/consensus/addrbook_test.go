@@ -0,0 +1,88 @@
+package consensus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAddrBookBackupRestore prueba que el address book se respalde y se restaure después de perderlo
+func TestAddrBookBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	addrBook := filepath.Join(dir, "cometbft", "config", "addrbook.json")
+	backup := AddrBookBackupPath(filepath.Join(dir, "data"))
+	if err := os.MkdirAll(filepath.Dir(addrBook), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	book := `{"key":"abc","addrs":[{"addr":{"id":"n1","ip":"10.0.0.1","port":26656}},{"addr":{"id":"n2","ip":"10.0.0.2","port":26656}}]}`
+	if err := os.WriteFile(addrBook, []byte(book), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := backupAddrBook(addrBook, backup); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un address book vacío no reemplaza al respaldo
+	if err := os.WriteFile(addrBook, []byte(`{"key":"abc","addrs":[]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := backupAddrBook(addrBook, backup); err != nil {
+		t.Fatal(err)
+	}
+	if n := addrBookSize(backup); n != 2 {
+		t.Fatalf("El respaldo debería conservar 2 direcciones, tiene %d", n)
+	}
+
+	// COMETBFT_HOME reiniciado: se restaura el respaldo
+	if err := os.RemoveAll(filepath.Join(dir, "cometbft")); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := restoreAddrBook(addrBook, backup)
+	if err != nil || restored != 2 {
+		t.Fatalf("Restauración incorrecta: %d, %v", restored, err)
+	}
+	if data, _ := os.ReadFile(addrBook); string(data) != book {
+		t.Errorf("Address book restaurado distinto: %s", data)
+	}
+
+	// Con direcciones propias no se toca
+	if restored, err := restoreAddrBook(addrBook, backup); err != nil || restored != 0 {
+		t.Errorf("No debería restaurar sobre un address book con direcciones: %d, %v", restored, err)
+	}
+
+	// Sin directorio de datos no hay respaldo
+	if AddrBookBackupPath("") != "" {
+		t.Error("Sin directorio de datos no debería haber ruta de respaldo")
+	}
+}
+
+// TestFallbackSeeds prueba la selección de seeds de respaldo por chain ID
+func TestFallbackSeeds(t *testing.T) {
+	original := fallbackSeeds
+	defer func() { fallbackSeeds = original }()
+	fallbackSeeds = "oxy-mainnet=m1@seed1.example.org:26656;oxy-testnet=t1@seed.example.org:26656"
+
+	configured := "oxy-testnet=a@10.0.0.1:26656, b@10.0.0.2:26656"
+	seeds, err := FallbackSeeds("oxy-testnet", configured)
+	if err != nil || strings.Join(seeds, ",") != "a@10.0.0.1:26656,b@10.0.0.2:26656" {
+		t.Errorf("Los seeds configurados deberían tener prioridad: %v, %v", seeds, err)
+	}
+	seeds, err = FallbackSeeds("oxy-mainnet", configured)
+	if err != nil || strings.Join(seeds, ",") != "m1@seed1.example.org:26656" {
+		t.Errorf("Sin seeds configurados para la cadena se usan los compilados: %v, %v", seeds, err)
+	}
+	if seeds, err := FallbackSeeds("otra", configured); err != nil || len(seeds) != 0 {
+		t.Errorf("Cadena sin seeds de respaldo: %v, %v", seeds, err)
+	}
+
+	for _, invalid := range []string{"a@10.0.0.1:26656", "oxy-testnet=10.0.0.1:26656", "oxy-testnet=a@0.0.0.0:26656"} {
+		if _, err := ParseFallbackSeeds(invalid); err == nil {
+			t.Errorf("%q debería ser inválido", invalid)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Valores por defecto del benchmark embebido
+const (
+	DefaultBenchBlockInterval = time.Second
+	DefaultBenchMaxBlockTxs   = 5000
+)
+
+// BenchOptions configura un benchmark de throughput con un nodo embebido
+type BenchOptions struct {
+	Rate     int           // Transacciones por segundo a enviar
+	Duration time.Duration // Tiempo de envío
+	Accounts int           // Cuentas que envían las transferencias (round-robin)
+	Seed     int64         // Semilla de las cuentas
+	ChainID  string
+
+	// Producción de bloques: cada BlockInterval (o en cuanto termina el anterior, si tarda más) se incluyen
+	// hasta MaxBlockTxs transacciones del mempool (0 = valores por defecto)
+	BlockInterval time.Duration
+	MaxBlockTxs   int
+}
+
+// BenchResult resume un benchmark de throughput (embebido o contra un nodo)
+type BenchResult struct {
+	Mode         string       `json:"mode"` // embedded o remote
+	TargetRate   int          `json:"targetRate"`
+	Elapsed      float64      `json:"elapsedSeconds"`
+	Submitted    int          `json:"submitted"`
+	Rejected     int          `json:"rejected"`         // Rechazadas al entrar al mempool
+	Included     int          `json:"included"`         // Incluidas en un bloque
+	Failed       int          `json:"failed,omitempty"` // Incluidas con error de ejecución
+	Pending      int          `json:"pending"`          // Aceptadas pero sin incluir al terminar
+	Blocks       int          `json:"blocks"`
+	TPS          float64      `json:"tps"` // Transacciones incluidas por segundo
+	Latency      BenchLatency `json:"latency"`
+	MempoolMax   int          `json:"mempoolMax"`   // Backlog máximo observado
+	MempoolFinal int          `json:"mempoolFinal"` // Backlog al terminar el envío
+}
+
+// BenchLatency son los percentiles de la latencia envío → inclusión en un bloque, en milisegundos
+type BenchLatency struct {
+	P50 float64 `json:"p50Ms"`
+	P90 float64 `json:"p90Ms"`
+	P99 float64 `json:"p99Ms"`
+	Max float64 `json:"maxMs"`
+}
+
+// NewBenchLatency calcula los percentiles de las latencias medidas (ordena samples)
+func NewBenchLatency(samples []time.Duration) BenchLatency {
+	if len(samples) == 0 {
+		return BenchLatency{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	percentile := func(p float64) float64 {
+		index := int(p * float64(len(samples)-1))
+		return float64(samples[index].Microseconds()) / 1000
+	}
+	return BenchLatency{
+		P50: percentile(0.50),
+		P90: percentile(0.90),
+		P99: percentile(0.99),
+		Max: float64(samples[len(samples)-1].Microseconds()) / 1000,
+	}
+}
+
+// benchPending es una transacción aceptada en el mempool del benchmark embebido
+type benchPending struct {
+	data     []byte
+	accepted time.Time
+}
+
+// RunEmbeddedBench mide el throughput de la ejecución con una ABCIApp y un EVM en proceso, sin red ni
+// CometBFT: envía transferencias firmadas de antemano a opts.Rate tx/s durante opts.Duration pasando por
+// CheckTx, y produce bloques con FinalizeBlock y Commit hasta incluir lo aceptado. La latencia va de la
+// aceptación en el mempool al commit del bloque. La base de datos se crea en workDir y se borra al terminar
+func RunEmbeddedBench(ctx context.Context, workDir string, opts BenchOptions) (*BenchResult, error) {
+	if opts.Rate <= 0 || opts.Duration <= 0 {
+		return nil, fmt.Errorf("el benchmark necesita una tasa y una duración positivas")
+	}
+	if opts.Accounts < 2 {
+		return nil, fmt.Errorf("el benchmark necesita al menos 2 cuentas")
+	}
+	if opts.ChainID == "" {
+		opts.ChainID = "oxy-bench"
+	}
+	if opts.BlockInterval <= 0 {
+		opts.BlockInterval = DefaultBenchBlockInterval
+	}
+	if opts.MaxBlockTxs <= 0 {
+		opts.MaxBlockTxs = DefaultBenchMaxBlockTxs
+	}
+
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creando directorio de trabajo: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	simulation := SimulationOptions{
+		Seed:          opts.Seed,
+		Accounts:      opts.Accounts,
+		ChainID:       opts.ChainID,
+		MinStake:      new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)),
+		MaxValidators: 100,
+	}
+	workload := newSimulationWorkload(simulation)
+	app, closeApp, err := openSimulationApp(workDir, simulation, workload)
+	if err != nil {
+		return nil, err
+	}
+	defer closeApp()
+
+	// Firmar todo antes de empezar para no medir el costo de firmar
+	txs, err := benchTransfers(workload, int(float64(opts.Rate)*opts.Duration.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BenchResult{Mode: "embedded", TargetRate: opts.Rate}
+	var (
+		appMu   sync.Mutex // CheckTx no corre durante FinalizeBlock y Commit (como el mempool de CometBFT)
+		queueMu sync.Mutex
+		queue   []benchPending
+	)
+
+	// Envío: CheckTx a la tasa objetivo
+	start := time.Now()
+	sendCtx, stopSending := context.WithTimeout(ctx, opts.Duration)
+	sent := make(chan struct{})
+	defer func() {
+		stopSending()
+		<-sent
+	}()
+	go func() {
+		defer close(sent)
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for next := 0; next < len(txs); {
+			select {
+			case <-sendCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			due := min(int(time.Since(start).Seconds()*float64(opts.Rate)), len(txs))
+			for ; next < due; next++ {
+				appMu.Lock()
+				resp, err := app.CheckTx(sendCtx, &abcitypes.CheckTxRequest{Tx: txs[next], Type: abcitypes.CHECK_TX_TYPE_CHECK})
+				appMu.Unlock()
+				result.Submitted++
+				if err != nil || resp.Code != CodeOK {
+					result.Rejected++
+					continue
+				}
+				queueMu.Lock()
+				queue = append(queue, benchPending{data: txs[next], accepted: time.Now()})
+				queueMu.Unlock()
+			}
+		}
+	}()
+
+	// Bloques: hasta MaxBlockTxs transacciones del mempool por bloque. Terminado el envío se siguen
+	// produciendo hasta vaciar el mempool (como mucho otro opts.Duration)
+	var (
+		latencies     []time.Duration
+		drainDeadline time.Time
+	)
+	proposer := common.HexToAddress(workload.validatorAddress()).Bytes()
+	ticker := time.NewTicker(opts.BlockInterval)
+	defer ticker.Stop()
+	for height := int64(1); ; height++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		queueMu.Lock()
+		if drainDeadline.IsZero() {
+			select {
+			case <-sent:
+				drainDeadline = time.Now().Add(opts.Duration)
+				result.MempoolFinal = len(queue)
+			default:
+			}
+		}
+		result.MempoolMax = max(result.MempoolMax, len(queue))
+		batch := queue[:min(len(queue), opts.MaxBlockTxs)]
+		queue = queue[len(batch):]
+		remaining := len(queue)
+		queueMu.Unlock()
+
+		blockTxs := make([][]byte, len(batch))
+		for i, pending := range batch {
+			blockTxs[i] = pending.data
+		}
+		appMu.Lock()
+		resp, err := app.FinalizeBlock(ctx, &abcitypes.FinalizeBlockRequest{
+			Height:          height,
+			Time:            time.Now().UTC(),
+			ProposerAddress: proposer,
+			Txs:             blockTxs,
+		})
+		if err == nil {
+			_, err = app.Commit(ctx, &abcitypes.CommitRequest{})
+		}
+		appMu.Unlock()
+		if err != nil {
+			return nil, fmt.Errorf("error produciendo el bloque %d: %w", height, err)
+		}
+
+		committed := time.Now()
+		result.Blocks++
+		for i, txResult := range resp.TxResults {
+			result.Included++
+			if txResult.Code != CodeOK {
+				result.Failed++
+			}
+			latencies = append(latencies, committed.Sub(batch[i].accepted))
+		}
+
+		if !drainDeadline.IsZero() && (remaining == 0 || committed.After(drainDeadline)) {
+			result.Pending = remaining
+			result.Elapsed = committed.Sub(start).Seconds()
+			result.TPS = float64(result.Included) / result.Elapsed
+			result.Latency = NewBenchLatency(latencies)
+			return result, nil
+		}
+	}
+}
+
+// benchTransfers firma count transferencias de 1 wei entre las cuentas del workload, en round-robin
+func benchTransfers(workload *simulationWorkload, count int) ([][]byte, error) {
+	nonces := make([]uint64, len(workload.accounts))
+	txs := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		index := i % len(workload.accounts)
+		sender := workload.accounts[index]
+		tx := &Transaction{
+			From:     sender.address.Hex(),
+			To:       workload.accounts[(index+1)%len(workload.accounts)].address.Hex(),
+			Value:    "1",
+			GasLimit: 21000,
+			GasPrice: "1",
+			Nonce:    nonces[index],
+		}
+		nonces[index]++
+		if err := signSimulationTx(tx, sender.key); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(tx)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, data)
+	}
+	return txs, nil
+}
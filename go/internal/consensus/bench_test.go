@@ -0,0 +1,44 @@
+package consensus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestRunEmbeddedBench prueba que el benchmark embebido incluya todo lo aceptado en el mempool
+func TestRunEmbeddedBench(t *testing.T) {
+	result, err := RunEmbeddedBench(context.Background(), t.TempDir(), BenchOptions{
+		Rate:          200,
+		Duration:      time.Second,
+		Accounts:      4,
+		BlockInterval: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Error en RunEmbeddedBench: %v", err)
+	}
+	if result.Submitted == 0 || result.Rejected != 0 || result.Failed != 0 {
+		t.Fatalf("Resultado inesperado: %+v", result)
+	}
+	if result.Included != result.Submitted || result.Pending != 0 {
+		t.Errorf("Se esperaban incluidas las %d transacciones enviadas: %+v", result.Submitted, result)
+	}
+	if result.TPS <= 0 || result.Latency.Max < result.Latency.P50 {
+		t.Errorf("Métricas inválidas: %+v", result)
+	}
+}
+
+// TestNewBenchLatency prueba el cálculo de percentiles
+func TestNewBenchLatency(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	latency := NewBenchLatency(samples)
+	if latency.P50 != 50 || latency.P90 != 90 || latency.P99 != 99 || latency.Max != 100 {
+		t.Errorf("Percentiles incorrectos: %+v", latency)
+	}
+	if empty := NewBenchLatency(nil); empty != (BenchLatency{}) {
+		t.Errorf("Sin muestras se esperaban ceros: %+v", empty)
+	}
+}
@@ -0,0 +1,57 @@
+package consensus
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultMaxBlockTimeDrift es cuánto puede adelantarse la hora de una propuesta respecto del reloj local
+// Es amplio porque los nodos de la mesh pueden estar sin NTP; alcanza para que un proponente no adelante
+// block.timestamp (vencimientos, vesting, subastas) más allá de eso
+const DefaultMaxBlockTimeDrift = 30 * time.Second
+
+// SetMaxBlockTimeDrift establece el adelanto máximo aceptado en la hora de una propuesta
+func (app *ABCIApp) SetMaxBlockTimeDrift(drift time.Duration) {
+	app.maxBlockTimeDrift = drift
+}
+
+// validateProposalTime verifica la hora de una propuesta: no vacía, no más adelantada que maxBlockTimeDrift
+// respecto del reloj local y no anterior a la del bloque padre (en segundos, la resolución de block.timestamp)
+func (app *ABCIApp) validateProposalTime(height int64, proposed time.Time) error {
+	if proposed.IsZero() {
+		return fmt.Errorf("propuesta sin hora")
+	}
+	if app.maxBlockTimeDrift > 0 {
+		if ahead := time.Until(proposed); ahead > app.maxBlockTimeDrift {
+			return fmt.Errorf("hora %s adelantada %s respecto del reloj local (máximo %s)",
+				proposed.UTC().Format(time.RFC3339), ahead.Round(time.Second), app.maxBlockTimeDrift)
+		}
+	}
+	if parent, ok := app.parentBlockTime(height); ok && proposed.Unix() < parent.Unix() {
+		return fmt.Errorf("hora %s anterior a la del bloque %d (%s)",
+			proposed.UTC().Format(time.RFC3339), height-1, parent.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// parentBlockTime retorna la hora del bloque anterior a height: la del último bloque finalizado o la guardada
+func (app *ABCIApp) parentBlockTime(height int64) (time.Time, bool) {
+	if height <= 1 {
+		return time.Time{}, false
+	}
+	if app.currentBlockHeight == uint64(height-1) && !app.currentBlockStamp.IsZero() {
+		return app.currentBlockStamp, true
+	}
+	if app.storage == nil {
+		return time.Time{}, false
+	}
+	data, err := app.storage.GetBlock(uint64(height - 1))
+	if err != nil {
+		return time.Time{}, false
+	}
+	block, err := DecodeBlock(data)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return block.Header.Timestamp, true
+}
@@ -0,0 +1,44 @@
+package consensus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// TestValidateProposalTime verifica el rechazo de propuestas sin hora, adelantadas o anteriores al bloque padre
+func TestValidateProposalTime(t *testing.T) {
+	app := NewABCIApp(nil, nil, nil, "test-chain")
+	app.SetMaxBlockTimeDrift(10 * time.Second)
+
+	now := time.Now()
+	if err := app.validateProposalTime(1, time.Time{}); err == nil {
+		t.Error("Una propuesta sin hora debería rechazarse")
+	}
+	if err := app.validateProposalTime(1, now.Add(time.Minute)); err == nil {
+		t.Error("Una propuesta adelantada más que el máximo debería rechazarse")
+	}
+	if err := app.validateProposalTime(1, now.Add(5*time.Second)); err != nil {
+		t.Errorf("Un adelanto dentro del máximo debería aceptarse: %v", err)
+	}
+
+	// Bloque padre recién finalizado
+	app.currentBlockHeight = 9
+	app.currentBlockStamp = now.Add(-time.Second)
+	if err := app.validateProposalTime(10, now.Add(-time.Minute)); err == nil {
+		t.Error("Una hora anterior a la del bloque padre debería rechazarse")
+	}
+	if err := app.validateProposalTime(10, now); err != nil {
+		t.Errorf("Una hora posterior a la del bloque padre debería aceptarse: %v", err)
+	}
+
+	resp, err := app.ProcessProposal(context.Background(), &abcitypes.ProcessProposalRequest{
+		Height: 10,
+		Time:   now.Add(time.Hour),
+	})
+	if err != nil || resp.Status != abcitypes.PROCESS_PROPOSAL_STATUS_REJECT {
+		t.Errorf("Se esperaba rechazar la propuesta adelantada: %v", err)
+	}
+}
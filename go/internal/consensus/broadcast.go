@@ -0,0 +1,206 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// BroadcastMode indica hasta cuándo espera el envío de una transacción (semántica de broadcast_tx_* de Tendermint)
+type BroadcastMode string
+
+const (
+	BroadcastAsync  BroadcastMode = "async"  // Retorna al agregar la transacción al mempool
+	BroadcastSync   BroadcastMode = "sync"   // Retorna con el resultado de CheckTx
+	BroadcastCommit BroadcastMode = "commit" // Retorna cuando la transacción se incluye en un bloque (o vence el timeout)
+)
+
+// DefaultBroadcastCommitTimeout es la espera máxima del modo commit
+const DefaultBroadcastCommitTimeout = 10 * time.Second
+
+// ParseBroadcastMode interpreta el modo de envío (vacío = async)
+func ParseBroadcastMode(mode string) (BroadcastMode, error) {
+	switch BroadcastMode(mode) {
+	case "", BroadcastAsync:
+		return BroadcastAsync, nil
+	case BroadcastSync, BroadcastCommit:
+		return BroadcastMode(mode), nil
+	}
+	return "", fmt.Errorf("modo de envío inválido: %s (async, sync o commit)", mode)
+}
+
+// CheckTxResult es el resultado de la validación de una transacción antes de entrar al mempool
+type CheckTxResult struct {
+	Code uint32 `json:"code"`
+	Log  string `json:"log"`
+}
+
+// TxCommitResult es el resultado de ejecución de una transacción incluida en un bloque
+type TxCommitResult struct {
+	Height    uint64 `json:"height"`
+	Code      uint32 `json:"code"`
+	Codespace string `json:"codespace,omitempty"`
+	Log       string `json:"log,omitempty"`
+	Info      string `json:"info,omitempty"` // Razón del error (ej: "execution_failed")
+	GasUsed   int64  `json:"gasUsed"`
+}
+
+// BroadcastResult es la respuesta de BroadcastTransaction según el modo
+type BroadcastResult struct {
+	Hash     string          `json:"hash"`
+	Mode     BroadcastMode   `json:"mode"`
+	CheckTx  *CheckTxResult  `json:"checkTx,omitempty"`  // sync y commit
+	TxResult *TxCommitResult `json:"txResult,omitempty"` // commit
+}
+
+// txWaiters entrega el resultado de las transacciones confirmadas a quienes las esperan (modo commit)
+type txWaiters struct {
+	mu      sync.Mutex
+	waiters map[string][]chan *TxCommitResult
+}
+
+// newTxWaiters crea un registro de esperas vacío
+func newTxWaiters() *txWaiters {
+	return &txWaiters{waiters: make(map[string][]chan *TxCommitResult)}
+}
+
+// wait registra una espera por el resultado de hash; cancel la elimina si el resultado no llega
+func (w *txWaiters) wait(hash string) (<-chan *TxCommitResult, func()) {
+	ch := make(chan *TxCommitResult, 1)
+	w.mu.Lock()
+	w.waiters[hash] = append(w.waiters[hash], ch)
+	w.mu.Unlock()
+
+	cancel := func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		chans := w.waiters[hash]
+		for i, c := range chans {
+			if c == ch {
+				chans = append(chans[:i], chans[i+1:]...)
+				break
+			}
+		}
+		if len(chans) == 0 {
+			delete(w.waiters, hash)
+		} else {
+			w.waiters[hash] = chans
+		}
+	}
+	return ch, cancel
+}
+
+// resolve entrega los resultados de un bloque confirmado
+func (w *txWaiters) resolve(results map[string]*TxCommitResult) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.waiters) == 0 {
+		return
+	}
+	for hash, result := range results {
+		for _, ch := range w.waiters[hash] {
+			ch <- result // Buffer de 1: cada canal recibe un único resultado
+		}
+		delete(w.waiters, hash)
+	}
+}
+
+// collectTxResults asocia los resultados de FinalizeBlock con los hashes de las transacciones
+// (hashes[i] vacío = transacción que no se pudo decodificar); ante hashes repetidos se conserva el primero
+func collectTxResults(height uint64, hashes []string, results []*abcitypes.ExecTxResult) map[string]*TxCommitResult {
+	collected := make(map[string]*TxCommitResult, len(hashes))
+	for i, hash := range hashes {
+		if hash == "" || i >= len(results) {
+			continue
+		}
+		if _, exists := collected[hash]; exists {
+			continue
+		}
+		result := results[i]
+		collected[hash] = &TxCommitResult{
+			Height:    height,
+			Code:      result.Code,
+			Codespace: result.Codespace,
+			Log:       result.Log,
+			Info:      result.Info,
+			GasUsed:   result.GasUsed,
+		}
+	}
+	return collected
+}
+
+// WaitForTx registra una espera por el resultado de una transacción en el próximo commit que la incluya
+func (app *ABCIApp) WaitForTx(hash string) (<-chan *TxCommitResult, func()) {
+	return app.txWaiters.wait(hash)
+}
+
+// checkNewTransaction valida una transacción nueva igual que CheckTx, sin consumir el rate limit
+func (app *ABCIApp) checkNewTransaction(tx *Transaction) error {
+	if err := app.checkNotHalted(); err != nil {
+		return err
+	}
+	if app.recentTxs.Contains(tx.Hash) {
+		return NewTxError(CodeDuplicateTx, "transacción duplicada: %s ya fue incluida en un bloque reciente", tx.Hash)
+	}
+	if err := app.validateTransactionComplete(tx); err != nil {
+		return fmt.Errorf("transacción inválida: %w", err)
+	}
+	return nil
+}
+
+// BroadcastTransaction envía una transacción al mempool local y espera según el modo:
+// async retorna al agregarla, sync la valida antes (CheckTx) y commit además espera su inclusión en un bloque.
+// Si en modo commit vence el timeout la transacción sigue en el mempool y se retorna un error CodeTimeout
+func (c *CometBFT) BroadcastTransaction(ctx context.Context, tx *Transaction, mode BroadcastMode) (*BroadcastResult, error) {
+	result := &BroadcastResult{Hash: tx.Hash, Mode: mode}
+	if mode == BroadcastAsync {
+		if err := c.submitTraced(ctx, tx); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if c.node == nil || c.node.abciApp == nil {
+		return nil, NewTxError(CodeUnavailable, "aplicación ABCI no disponible")
+	}
+	app := c.node.abciApp
+
+	if err := app.checkNewTransaction(tx); err != nil {
+		return nil, err
+	}
+	result.CheckTx = &CheckTxResult{Code: CodeOK, Log: "OK"}
+
+	if mode == BroadcastSync {
+		if err := c.submitTraced(ctx, tx); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	// Registrar la espera antes de enviar para no perder un commit inmediato
+	committed, cancel := app.WaitForTx(tx.Hash)
+	defer cancel()
+	if err := c.submitTraced(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	timeout := c.config.BroadcastCommitTimeout
+	if timeout <= 0 {
+		timeout = DefaultBroadcastCommitTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case txResult := <-committed:
+		result.TxResult = txResult
+		return result, nil
+	case <-timer.C:
+		return nil, NewTxError(CodeTimeout, "la transacción %s no se incluyó en un bloque en %s", tx.Hash, timeout)
+	case <-ctx.Done():
+		return nil, NewTxError(CodeTimeout, "envío cancelado esperando la transacción %s: %v", tx.Hash, ctx.Err())
+	}
+}
@@ -0,0 +1,67 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// TestParseBroadcastMode prueba los modos de envío aceptados
+func TestParseBroadcastMode(t *testing.T) {
+	for input, expected := range map[string]BroadcastMode{"": BroadcastAsync, "async": BroadcastAsync, "sync": BroadcastSync, "commit": BroadcastCommit} {
+		mode, err := ParseBroadcastMode(input)
+		if err != nil || mode != expected {
+			t.Errorf("ParseBroadcastMode(%q) = %q, %v; esperado %q", input, mode, err, expected)
+		}
+	}
+	if _, err := ParseBroadcastMode("block"); err == nil {
+		t.Error("Un modo desconocido debería rechazarse")
+	}
+}
+
+// TestABCIApp_WaitForTx verifica que el commit entregue el resultado de ejecución a los envíos en modo commit
+func TestABCIApp_WaitForTx(t *testing.T) {
+	app, _ := newSnapshotTestApp(t, "wait_for_tx")
+	ctx := context.Background()
+
+	// Transacción sin firma: se incluye en el bloque pero falla la validación
+	txBytes, _ := json.Marshal(&Transaction{
+		Hash:  "0xabc",
+		From:  "0x1234567890123456789012345678901234567890",
+		To:    "0x0000000000000000000000000000000000000abc",
+		Value: "1",
+	})
+	committed, cancel := app.WaitForTx("0xabc")
+	defer cancel()
+	other, cancelOther := app.WaitForTx("0xdef")
+	defer cancelOther()
+
+	if _, err := app.FinalizeBlock(ctx, &abcitypes.FinalizeBlockRequest{Height: 1, Time: time.Unix(1700000001, 0), Txs: [][]byte{txBytes}}); err != nil {
+		t.Fatalf("Error en FinalizeBlock: %v", err)
+	}
+	select {
+	case <-committed:
+		t.Fatal("El resultado no debería entregarse antes del commit")
+	default:
+	}
+	if _, err := app.Commit(ctx, &abcitypes.CommitRequest{}); err != nil {
+		t.Fatalf("Error en Commit: %v", err)
+	}
+
+	select {
+	case result := <-committed:
+		if result.Height != 1 || result.Code == CodeOK || result.Codespace != Codespace {
+			t.Errorf("Resultado inesperado: %+v", result)
+		}
+	default:
+		t.Fatal("El commit debería entregar el resultado de la transacción")
+	}
+	select {
+	case result := <-other:
+		t.Errorf("Una transacción no incluida no debería recibir resultado: %+v", result)
+	default:
+	}
+}
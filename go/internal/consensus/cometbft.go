@@ -2,14 +2,15 @@ package consensus
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
 	execution "github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/health"
 	"github.com/Q-YZX0/oxy-blockchain/internal/metrics"
 )
 
@@ -24,6 +25,11 @@ type CometBFT struct {
 	mempoolMutex sync.RWMutex
 	rateLimiter *RateLimiter
 	running     bool
+
+	healthChecker *health.HealthChecker // Recibe el estado de sync periódicamente (opcional)
+	peerHeight    func() int64          // Mayor altura conocida de la red (opcional)
+	stopCh        chan struct{}         // Detiene el monitor de sync y el registro de commits
+	commitHeights chan uint64           // Alturas cuyo commit falta guardar (ver commits.go)
 }
 
 // Config contiene la configuración del consenso
@@ -32,6 +38,99 @@ type Config struct {
 	ChainID       string
 	ValidatorAddr string
 	ValidatorKey  string
+
+	// Adelanto máximo de la hora de una propuesta sobre el reloj local (0 = valor por defecto)
+	MaxBlockTimeDrift time.Duration
+
+	// Rate limiting del mempool (0 = valor por defecto)
+	RateLimitPerAddress int           // Transacciones permitidas por dirección en la ventana
+	RateLimitWindow     time.Duration // Ventana de tiempo del rate limit
+	MempoolSizeLimit    int           // Máximo de transacciones en el mempool
+
+	// Límites anti-spam por transacción (CheckTx, PrepareProposal y ProcessProposal)
+	// Deben coincidir entre validadores: una propuesta fuera de los límites de un nodo recibe su voto en contra
+	TxLimits TxLimits
+
+	// Indexador de transacciones de CometBFT ("kv" o "null", vacío = "kv")
+	TxIndexer string
+
+	// Dirección de escucha del RPC de CometBFT (vacío = valor por defecto de CometBFT)
+	RPCListenAddr string
+
+	// Dirección de escucha P2P de CometBFT (vacío = valor por defecto de CometBFT)
+	P2PListenAddr string
+	// Dirección P2P pública "host:puerto" que se anuncia a los peers (vacío = la de escucha si no es 0.0.0.0)
+	P2PExternalAddr string
+	// Seeds de respaldo por chain ID ("chain=id@host:puerto,...;chain2=...") usados si no hay OXY_SEEDS
+	FallbackSeeds string
+	// Peers persistentes "id@host:puerto,..." (vacío = OXY_PERSISTENT_PEERS)
+	PersistentPeers string
+	// Red local: acepta varios peers en la misma IP y direcciones privadas o de loopback
+	// (redes de prueba con todos los nodos en una máquina)
+	LocalNetwork bool
+
+	// Modo desarrollo: bloques solo cuando hay transacciones y sin espera después de cada commit
+	DevMode bool
+	// Cuentas fondeadas en el genesis que crea el nodo al inicializarse (sin efecto si ya existe)
+	GenesisAccounts []GenesisAccount
+
+	// Intervalo de actualización del estado de sync en el health checker (0 = valor por defecto)
+	SyncCheckInterval time.Duration
+
+	// Espera máxima de los envíos en modo commit (0 = valor por defecto)
+	BroadcastCommitTimeout time.Duration
+
+	// Rol del nodo en la red P2P: "seed", "sentry", "validator" o "full" (vacío = "full")
+	NodeRole string
+	// IDs de nodo CometBFT de los validadores protegidos por un sentry, separados por coma
+	PrivatePeerIDs string
+
+	// Cada cuántos bloques se genera un snapshot de estado (0 = deshabilitado)
+	SnapshotInterval uint64
+	// Cantidad de snapshots que se conservan (0 = todos)
+	SnapshotKeepRecent int
+	// State sync: servidores RPC y punto de confianza del light client que verifica los snapshots
+	// (sin servidores RPC el state sync queda deshabilitado)
+	StateSyncRPCServers  string
+	StateSyncTrustHeight int64
+	StateSyncTrustHash   string
+
+	// Cada cuántos bloques se verifican las invariantes contables en segundo plano (0 = deshabilitado)
+	InvariantCheckInterval uint64
+	// Detener la cadena si la auditoría encuentra invariantes incumplidas
+	HaltOnInvariantViolation bool
+}
+
+// txIndexer retorna el indexador configurado, usando "kv" por defecto
+func (c *Config) txIndexer() string {
+	if c.TxIndexer == "" {
+		return "kv"
+	}
+	return c.TxIndexer
+}
+
+// Valores por defecto del rate limiting del mempool
+const (
+	DefaultRateLimitPerAddress = 10
+	DefaultRateLimitWindow     = time.Second
+	DefaultMempoolSizeLimit    = 10000
+)
+
+// rateLimitSettings retorna los límites configurados, usando los valores por defecto si no se especificaron
+func (c *Config) rateLimitSettings() (int, time.Duration, int) {
+	perAddress := c.RateLimitPerAddress
+	if perAddress <= 0 {
+		perAddress = DefaultRateLimitPerAddress
+	}
+	window := c.RateLimitWindow
+	if window <= 0 {
+		window = DefaultRateLimitWindow
+	}
+	mempoolLimit := c.MempoolSizeLimit
+	if mempoolLimit <= 0 {
+		mempoolLimit = DefaultMempoolSizeLimit
+	}
+	return perAddress, window, mempoolLimit
 }
 
 // NewCometBFT crea una nueva instancia del motor de consenso
@@ -49,8 +148,9 @@ func NewCometBFT(
 		return nil, fmt.Errorf("error creando nodo CometBFT: %w", err)
 	}
 	
-	// Crear rate limiter: 10 transacciones por segundo por dirección, ventana de 1 segundo, límite de mempool 10000
-	rateLimiter := NewRateLimiter(10, time.Second, 10000)
+	// Crear rate limiter con los límites configurados (compartido entre SubmitTransaction y CheckTx)
+	perAddress, window, mempoolLimit := config.rateLimitSettings()
+	rateLimiter := NewRateLimiter(perAddress, window, mempoolLimit)
 	rateLimiter.StartCleanup(30 * time.Second)
 
 	c := &CometBFT{
@@ -81,12 +181,69 @@ func (c *CometBFT) SetMetrics(m *metrics.Metrics) {
 	}
 }
 
+// SetComplianceList establece la blacklist/allowlist de direcciones aplicada en CheckTx y en las propuestas
+func (c *CometBFT) SetComplianceList(list *ComplianceList) {
+	if c.node != nil && c.node.abciApp != nil {
+		c.node.abciApp.SetComplianceList(list)
+	}
+}
+
+// ScheduleHalt programa la detención de la cadena después de confirmar height (0 = detener ya)
+func (c *CometBFT) ScheduleHalt(height uint64, reason, source string) (HaltStatus, error) {
+	if c.node == nil || c.node.abciApp == nil {
+		return HaltStatus{}, NewTxError(CodeUnavailable, "consenso no disponible")
+	}
+	return c.node.abciApp.ScheduleHalt(height, reason, source)
+}
+
+// ResumeChain cancela la detención programada o reanuda la cadena detenida
+func (c *CometBFT) ResumeChain() error {
+	if c.node == nil || c.node.abciApp == nil {
+		return NewTxError(CodeUnavailable, "consenso no disponible")
+	}
+	return c.node.abciApp.ResumeChain()
+}
+
+// HaltStatus retorna la detención programada de la cadena
+func (c *CometBFT) HaltStatus() HaltStatus {
+	if c.node == nil || c.node.abciApp == nil {
+		return HaltStatus{}
+	}
+	return c.node.abciApp.HaltStatus()
+}
+
+// AddBlockCommitHandler agrega una función llamada con cada bloque confirmado
+func (c *CometBFT) AddBlockCommitHandler(handler func(*Block)) {
+	if c.node != nil && c.node.abciApp != nil {
+		c.node.abciApp.AddBlockCommitHandler(handler)
+	}
+}
+
+// AddTxResultsHandler agrega una función llamada en cada commit con el resultado de las transacciones del bloque
+func (c *CometBFT) AddTxResultsHandler(handler func(map[string]*TxCommitResult)) {
+	if c.node != nil && c.node.abciApp != nil {
+		c.node.abciApp.AddTxResultsHandler(handler)
+	}
+}
+
+// SetHealthChecker establece el health checker que recibe el estado de sincronización
+// Debe llamarse antes de Start para que se inicie el monitor de sync
+func (c *CometBFT) SetHealthChecker(h *health.HealthChecker) {
+	c.healthChecker = h
+}
+
 // Start inicia el motor de consenso
 func (c *CometBFT) Start() error {
 	if c.running {
 		return fmt.Errorf("consenso ya está corriendo")
 	}
 
+	// Guardar el commit de cada bloque confirmado (registrado antes de que CometBFT ejecute bloques)
+	if c.commitHeights == nil {
+		c.commitHeights = make(chan uint64, commitRecordQueue)
+		c.AddBlockCommitHandler(c.enqueueCommitRecord)
+	}
+
 	// Iniciar nodo CometBFT
 	if err := c.node.node.Start(); err != nil {
 		return fmt.Errorf("error iniciando nodo CometBFT: %w", err)
@@ -103,6 +260,14 @@ func (c *CometBFT) Start() error {
 	// en el topic "validators" y otros nodos pueden descubrirlos
 
 	c.running = true
+
+	// Reflejar el estado de sincronización en el health checker (readiness)
+	c.stopCh = make(chan struct{})
+	if c.healthChecker != nil {
+		go c.syncStatusLoop(c.config.syncCheckInterval(), c.stopCh)
+	}
+	go c.recordCommitsLoop(c.commitHeights, c.stopCh)
+
 	log.Println("✅ Consenso CometBFT iniciado")
 	return nil
 }
@@ -113,21 +278,39 @@ func (c *CometBFT) Stop() error {
 		return nil
 	}
 
+	close(c.stopCh)
+
 	// Detener nodo CometBFT
 	if err := c.node.node.Stop(); err != nil {
 		return fmt.Errorf("error deteniendo nodo CometBFT: %w", err)
 	}
 
+	// CometBFT guarda el address book al detenerse: respaldarlo para sobrevivir a un reinicio de sus datos
+	if err := backupAddrBook(c.node.addrBook, AddrBookBackupPath(c.config.DataDir)); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+
 	c.running = false
 	log.Println("⏹️  Consenso CometBFT detenido")
 	return nil
 }
 
-// IsValidator retorna si este nodo es validador
+// IsValidator retorna si este nodo es validador (una réplica nunca lo es)
 func (c *CometBFT) IsValidator() bool {
+	if c.node != nil && c.node.role == NodeModeReplica {
+		return false
+	}
 	return c.config.ValidatorAddr != ""
 }
 
+// IsProposer retorna si el bloque fue propuesto por la clave de validador de este nodo
+func (c *CometBFT) IsProposer(block *Block) bool {
+	if block == nil || c.node == nil || c.node.address == "" {
+		return false
+	}
+	return strings.EqualFold(block.Header.Validator, c.node.address)
+}
+
 // GetLatestBlock retorna el último bloque validado
 func (c *CometBFT) GetLatestBlock() (*Block, error) {
 	if !c.running {
@@ -159,23 +342,23 @@ func (c *CometBFT) GetLatestBlock() (*Block, error) {
 	}
 
 	// Decodificar bloque
-	var block Block
-	if err := json.Unmarshal(blockData, &block); err != nil {
+	block, err := DecodeBlock(blockData)
+	if err != nil {
 		return nil, fmt.Errorf("error decodificando bloque: %w", err)
 	}
 
-	return &block, nil
+	return block, nil
 }
 
 // SubmitTransaction envía una transacción para ser validada
 func (c *CometBFT) SubmitTransaction(tx *Transaction) error {
 	if !c.running {
-		return fmt.Errorf("consenso no está corriendo")
+		return NewTxError(CodeUnavailable, "consenso no está corriendo")
 	}
 
 	// Validar que la transacción tenga hash
 	if tx.Hash == "" {
-		return fmt.Errorf("transacción sin hash")
+		return NewTxError(CodeInvalidHash, "transacción sin hash")
 	}
 
 	// Rate limiting: verificar límite de mempool
@@ -184,12 +367,12 @@ func (c *CometBFT) SubmitTransaction(tx *Transaction) error {
 	c.mempoolMutex.RUnlock()
 
 	if !c.rateLimiter.CheckMempoolSize(mempoolSize) {
-		return fmt.Errorf("mempool lleno: límite alcanzado")
+		return NewTxError(CodeMempoolFull, "mempool lleno: límite alcanzado")
 	}
 
 	// Rate limiting: verificar límite por dirección
 	if !c.rateLimiter.Allow(tx.From) {
-		return fmt.Errorf("rate limit excedido para dirección %s", tx.From)
+		return NewTxError(CodeRateLimited, "rate limit excedido para dirección %s", tx.From)
 	}
 
 	// Validar que no esté ya en el mempool
@@ -197,7 +380,7 @@ func (c *CometBFT) SubmitTransaction(tx *Transaction) error {
 	for _, existingTx := range c.mempool {
 		if existingTx.Hash == tx.Hash {
 			c.mempoolMutex.Unlock()
-			return fmt.Errorf("transacción ya está en el mempool")
+			return NewTxError(CodeDuplicateTx, "transacción ya está en el mempool")
 		}
 	}
 
@@ -207,6 +390,13 @@ func (c *CometBFT) SubmitTransaction(tx *Transaction) error {
 
 	log.Printf("📥 Transacción agregada al mempool: %s", tx.Hash)
 
+	// Modo desarrollo: sin bloques vacíos periódicos, CometBFT necesita un pedido para proponer el bloque
+	if c.config.DevMode {
+		if err := c.requestDevBlock(); err != nil {
+			consensusLog.Warnf("Transacción %s en el mempool sin bloque pedido: %v", tx.Hash, err)
+		}
+	}
+
 	// La transacción será procesada por CometBFT cuando PrepareProposal use el mempool local
 	// PrepareProposal incluirá las transacciones del mempool local en los bloques
 
@@ -6,9 +6,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/snapshot"
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
 	cometcfg "github.com/cometbft/cometbft/config"
 	"github.com/cometbft/cometbft/crypto"
@@ -23,10 +25,15 @@ import (
 
 // CometBFTNode maneja el nodo CometBFT
 type CometBFTNode struct {
-	node    *node.Node
-	abciApp *ABCIApp
-	config  *Config
-	running bool
+	node     *node.Node
+	abciApp  *ABCIApp
+	config   *Config
+	rpc      cometRPC // Cliente RPC en proceso (status, block_results, tx_search)
+	role     string   // Rol del nodo: seed, sentry, validator, full o replica
+	nodeID   string   // ID de nodo P2P (node_key.json), el "id" de "id@host:puerto"
+	addrBook string   // Address book de CometBFT (se respalda en AddrBookBackupPath al detener el nodo)
+	address  string   // Dirección CometBFT de la clave de validador local (vacía en una réplica)
+	running  bool
 }
 
 // NewCometBFTNode crea una nueva instancia del nodo CometBFT
@@ -41,6 +48,20 @@ func NewCometBFTNode(
 	// Crear aplicación ABCI con validators
 	// Nota: El mempool se establecerá después de crear CometBFT completo
 	abciApp := NewABCIApp(storage, executor, validators, cfg.ChainID)
+	if cfg.MaxBlockTimeDrift > 0 {
+		abciApp.SetMaxBlockTimeDrift(cfg.MaxBlockTimeDrift)
+	}
+
+	// Snapshots de estado: se sirven por state sync y se generan cada SnapshotInterval bloques
+	snapshotStore, err := snapshot.NewStore(SnapshotDir(cfg.DataDir))
+	if err != nil {
+		return nil, err
+	}
+	abciApp.SetSnapshotStore(snapshotStore, cfg.SnapshotInterval, cfg.SnapshotKeepRecent)
+	abciApp.SetInvariantCheckInterval(cfg.InvariantCheckInterval)
+	abciApp.SetHaltOnInvariantViolation(cfg.HaltOnInvariantViolation)
+	abciApp.SetTxLimits(cfg.TxLimits)
+	abciApp.SetDevMode(cfg.DevMode)
 
 	// Crear configuración de CometBFT
 	cometConfig := cometcfg.DefaultConfig()
@@ -49,9 +70,45 @@ func NewCometBFTNode(
 	// Configurar para crear bloques vacíos automáticamente (importante para testnet)
 	cometConfig.Consensus.CreateEmptyBlocks = true
 	cometConfig.Consensus.CreateEmptyBlocksInterval = 1 * time.Second // Crear bloques vacíos cada segundo
-	
+
+	// Modo desarrollo: un bloque por cada lote de transacciones, sin bloques vacíos periódicos
+	// (CometBFT igual produce un bloque vacío después de cada uno que cambia el app hash)
+	if cfg.DevMode {
+		cometConfig.Consensus.CreateEmptyBlocks = false
+		cometConfig.Consensus.TimeoutPropose = 100 * time.Millisecond
+		cometConfig.Consensus.TimeoutCommit = 0
+	}
+
+	// Revalidar el mempool después de cada commit (CheckTx con tipo RECHECK)
+	// para expulsar transacciones que quedaron inválidas con el nuevo estado
+	cometConfig.Mempool.Recheck = true
+
+	// Limitar también el mempool de CometBFT con el límite configurado
+	_, _, mempoolLimit := cfg.rateLimitSettings()
+	cometConfig.Mempool.Size = mempoolLimit
+
+	// Indexador de transacciones: "kv" habilita tx_search por atributos de eventos, "null" lo desactiva
+	cometConfig.TxIndex.Indexer = cfg.txIndexer()
+	if cfg.RPCListenAddr != "" {
+		cometConfig.RPC.ListenAddress = cfg.RPCListenAddr
+	}
+	if cfg.P2PListenAddr != "" {
+		cometConfig.P2P.ListenAddress = cfg.P2PListenAddr
+	}
+	if cfg.P2PExternalAddr != "" {
+		cometConfig.P2P.ExternalAddress = cfg.P2PExternalAddr
+	}
+	if cfg.LocalNetwork {
+		cometConfig.P2P.AllowDuplicateIP = true
+		cometConfig.P2P.AddrBookStrict = false
+	}
+
 	// Configurar peers persistentes si se proporcionan
-	if persistentPeers := os.Getenv("OXY_PERSISTENT_PEERS"); persistentPeers != "" {
+	persistentPeers := cfg.PersistentPeers
+	if persistentPeers == "" {
+		persistentPeers = os.Getenv("OXY_PERSISTENT_PEERS")
+	}
+	if persistentPeers != "" {
 		cometConfig.P2P.PersistentPeers = persistentPeers
 		fmt.Fprintf(os.Stdout, "[CometBFT] PersistentPeers configurados: %s\n", persistentPeers)
 		os.Stdout.Sync()
@@ -64,11 +121,57 @@ func NewCometBFTNode(
 		os.Stdout.Sync()
 	}
 
+	// Seeds de respaldo de la cadena (OXY_FALLBACK_SEEDS o compilados): sin OXY_SEEDS, el nodo
+	// vuelve a encontrar la red aunque haya perdido su address book
+	if cometConfig.P2P.Seeds == "" {
+		seeds, err := FallbackSeeds(cfg.ChainID, cfg.FallbackSeeds)
+		if err != nil {
+			return nil, fmt.Errorf("OXY_FALLBACK_SEEDS: %w", err)
+		}
+		if len(seeds) > 0 {
+			cometConfig.P2P.Seeds = strings.Join(seeds, ",")
+			fmt.Fprintf(os.Stdout, "[CometBFT] Seeds de respaldo para %s: %s\n", cfg.ChainID, cometConfig.P2P.Seeds)
+			os.Stdout.Sync()
+		}
+	}
+
+	// Rol del nodo (seed, sentry, validator o full): ajusta PEX, seeds y peers privados
+	role, err := ParseNodeRole(cfg.NodeRole)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyNodeRole(cometConfig.P2P, role, cfg.PrivatePeerIDs); err != nil {
+		return nil, fmt.Errorf("error aplicando rol de nodo: %w", err)
+	}
+	if role == NodeModeReplica && cfg.DevMode {
+		return nil, fmt.Errorf("el modo desarrollo produce sus propios bloques: no puede ser una réplica")
+	}
+	fmt.Fprintf(os.Stdout, "[CometBFT] Rol del nodo: %s\n", role)
+	os.Stdout.Sync()
+
+	// State sync: arrancar desde un snapshot de los peers en lugar de reejecutar la cadena
+	stateSync, err := cfg.applyStateSync(cometConfig.StateSync)
+	if err != nil {
+		return nil, err
+	}
+	if stateSync {
+		fmt.Fprintf(os.Stdout, "[CometBFT] State sync habilitado (RPC: %s)\n", cfg.StateSyncRPCServers)
+		os.Stdout.Sync()
+	}
+
 	// Asegurar que el directorio existe
 	if err := os.MkdirAll(cometConfig.RootDir, 0755); err != nil {
 		return nil, fmt.Errorf("error creando directorio CometBFT: %w", err)
 	}
 
+	// Respaldar el address book antes de cualquier reinicio de datos (se restaura antes de crear el nodo)
+	addrBookFile := cometConfig.P2P.AddrBookFile()
+	addrBookBackup := AddrBookBackupPath(cfg.DataDir)
+	if err := backupAddrBook(addrBookFile, addrBookBackup); err != nil {
+		fmt.Fprintf(os.Stderr, "[CometBFT] ADVERTENCIA: %v\n", err)
+		os.Stderr.Sync()
+	}
+
 	// Inicializar CometBFT si no existe
 	fmt.Fprintf(os.Stdout, "[CometBFT] Verificando si está inicializado...\n")
 	os.Stdout.Sync()
@@ -95,6 +198,14 @@ func NewCometBFTNode(
 	}
 	os.Stdout.Sync()
 
+	// Una réplica sigue una red existente: no genera claves ni un genesis propio (se agregaría como validador)
+	if role == NodeModeReplica {
+		if !genesisExists {
+			return nil, fmt.Errorf("el modo replica requiere el genesis.json de la red en %s", genesisFile)
+		}
+		keyExists = true
+	}
+
 	if !genesisExists || !keyExists {
 		fmt.Fprintf(os.Stdout, "[CometBFT] No está completamente inicializado, inicializando...\n")
 		os.Stdout.Sync()
@@ -119,6 +230,11 @@ func NewCometBFTNode(
 				os.Stderr.Sync()
 				return nil, fmt.Errorf("error inicializando CometBFT: %w", err)
 			}
+			if len(cfg.GenesisAccounts) > 0 {
+				if err := addGenesisAccounts(genesisFile, cfg.GenesisAccounts); err != nil {
+					return nil, fmt.Errorf("error agregando cuentas al genesis: %w", err)
+				}
+			}
 		}
 
 		fmt.Fprintf(os.Stdout, "[CometBFT] Inicialización completada\n")
@@ -137,6 +253,9 @@ func NewCometBFTNode(
 			return nil, fmt.Errorf("error cargando genesis: %w", err)
 		}
 
+		if len(genesis.Validators) == 0 && role == NodeModeReplica {
+			return nil, fmt.Errorf("el genesis de %s no tiene validadores: una réplica necesita el genesis de la red", genesisFile)
+		}
 		if len(genesis.Validators) == 0 {
 			fmt.Fprintf(os.Stdout, "[CometBFT] Genesis no tiene validadores, agregando validador...\n")
 			os.Stdout.Sync()
@@ -266,6 +385,25 @@ func NewCometBFTNode(
 	fmt.Fprintf(os.Stdout, "[CometBFT] Configuración válida\n")
 	os.Stdout.Sync()
 
+	// Hardforks de la EVM, contratos de sistema y ventana de duplicados del app_state del genesis: se aplican antes de reejecutar
+	// o recibir bloques
+	genesisParams, err := loadGenesisParams(cometConfig.GenesisFile())
+	if err != nil {
+		return nil, err
+	}
+	if executor != nil {
+		if err := executor.SetHardforkSchedule(genesisParams.Hardforks); err != nil {
+			return nil, err
+		}
+		if err := executor.SetSystemContracts(genesisParams.SystemContracts); err != nil {
+			return nil, err
+		}
+	}
+	// Ventana de duplicados del genesis (el índice se reconstruye con la nueva ventana)
+	if genesisParams.DuplicateTxWindow > 0 {
+		abciApp.SetDuplicateTxWindow(genesisParams.DuplicateTxWindow)
+	}
+
 	// Verificar si hay bases de datos que vamos a eliminar
 	// Si las hay, debemos eliminar el state file ANTES de cargar el private validator
 	dataDirCheck := filepath.Join(cometConfig.RootDir, "data")
@@ -317,8 +455,8 @@ func NewCometBFTNode(
 	fmt.Fprintf(os.Stdout, "[CometBFT] StateFile: %s\n", stateFile)
 	os.Stdout.Sync()
 
-	// Verificar que los archivos existen
-	if _, err := os.Stat(keyFile); os.IsNotExist(err) {
+	// Verificar que los archivos existen (una réplica no usa la clave de validador)
+	if _, err := os.Stat(keyFile); os.IsNotExist(err) && role != NodeModeReplica {
 		fmt.Fprintf(os.Stderr, "[CometBFT] ERROR: KeyFile no existe: %s\n", keyFile)
 		os.Stderr.Sync()
 		return nil, fmt.Errorf("private validator key file no existe: %s", keyFile)
@@ -367,12 +505,22 @@ func NewCometBFTNode(
 		os.Stdout.Sync()
 	}
 
-	fmt.Fprintf(os.Stdout, "[CometBFT] Cargando private validator con LoadFilePV...\n")
-	os.Stdout.Sync()
+	var pv types.PrivValidator
+	validatorAddress := ""
+	if role == NodeModeReplica {
+		// Réplica: sigue la cadena por CometBFT con un private validator que rechaza toda firma
+		pv = newReadOnlyPV()
+		fmt.Fprintf(os.Stdout, "[CometBFT] Modo réplica: el nodo no firma votos ni propuestas\n")
+		os.Stdout.Sync()
+	} else {
+		fmt.Fprintf(os.Stdout, "[CometBFT] Cargando private validator con LoadFilePV...\n")
+		os.Stdout.Sync()
 
-	pv := privval.LoadFilePV(keyFile, stateFile)
-	fmt.Fprintf(os.Stdout, "[CometBFT] Private validator cargado\n")
-	os.Stdout.Sync()
+		filePV := privval.LoadFilePV(keyFile, stateFile)
+		pv, validatorAddress = filePV, filePV.Key.Address.String()
+		fmt.Fprintf(os.Stdout, "[CometBFT] Private validator cargado\n")
+		os.Stdout.Sync()
+	}
 
 	// Crear node key
 	fmt.Fprintf(os.Stdout, "[CometBFT] Cargando node key...\n")
@@ -391,9 +539,10 @@ func NewCometBFTNode(
 
 	// Crear aplicación ABCI directamente (LocalClientCreator)
 	// CometBFT usará LocalClientCreator para comunicarse in-process con la aplicación
+	// La aplicación va instrumentada para medir latencia y errores de cada método ABCI
 	fmt.Fprintf(os.Stdout, "[CometBFT] Creando app creator (LocalClientCreator)...\n")
 	os.Stdout.Sync()
-	appCreator := proxy.NewLocalClientCreator(abciApp)
+	appCreator := proxy.NewLocalClientCreator(newInstrumentedApp(abciApp))
 	fmt.Fprintf(os.Stdout, "[CometBFT] App creator creado\n")
 	os.Stdout.Sync()
 
@@ -426,6 +575,15 @@ func NewCometBFTNode(
 		os.Stdout.Sync()
 	}
 
+	// Estado inicializado con `snapshot restore`: conservar las bases de datos en este inicio
+	snapshotMarker := filepath.Join(dataDir, snapshotBootstrapMarker)
+	if _, err := os.Stat(snapshotMarker); err == nil {
+		fmt.Fprintf(os.Stdout, "[CometBFT] Estado restaurado desde snapshot detectado, conservando bases de datos\n")
+		os.Stdout.Sync()
+		dbExists = false
+		os.Remove(snapshotMarker)
+	}
+
 	// Si hay marcador O si hay bases de datos (para evitar hash mismatch), eliminarlas
 	if markerExists || dbExists {
 		fmt.Fprintf(os.Stdout, "[CometBFT] Eliminando bases de datos para evitar hash mismatch (marcador=%v, dbExists=%v)...\n", markerExists, dbExists)
@@ -483,6 +641,15 @@ func NewCometBFTNode(
 		os.Stdout.Sync()
 	}
 
+	// Address book perdido (COMETBFT_HOME reiniciado): restaurar el respaldo para reconectar sin intervención
+	if restored, err := restoreAddrBook(addrBookFile, addrBookBackup); err != nil {
+		fmt.Fprintf(os.Stderr, "[CometBFT] ADVERTENCIA: %v\n", err)
+		os.Stderr.Sync()
+	} else if restored > 0 {
+		fmt.Fprintf(os.Stdout, "[CometBFT] Address book restaurado desde %s (%d direcciones)\n", addrBookBackup, restored)
+		os.Stdout.Sync()
+	}
+
 	// Crear nodo CometBFT (nueva API v1.0.1: necesita context.Context y firma diferente)
 	fmt.Fprintf(os.Stdout, "[CometBFT] Creando nodo CometBFT (node.NewNode)...\n")
 	os.Stdout.Sync()
@@ -511,6 +678,11 @@ func NewCometBFTNode(
 		node:    cometNode,
 		abciApp: abciApp,
 		config:  cfg,
+		rpc:      newLocalRPC(cometNode),
+		role:     role,
+		nodeID:   string(nodeKey.ID()),
+		addrBook: addrBookFile,
+		address:  validatorAddress,
 		running: false,
 	}
 	fmt.Fprintf(os.Stdout, "[CometBFT] Estructura CometBFTNode creada\n")
@@ -0,0 +1,148 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commitRecordQueue es la cantidad de alturas que pueden esperar a que se guarde su commit; si la cola
+// está llena, el commit de esa altura se guarda la primera vez que se consulta
+const commitRecordQueue = 64
+
+// commitRecordTimeout es el deadline de la consulta al RPC para guardar el commit de una altura
+const commitRecordTimeout = 10 * time.Second
+
+// Estado de la firma de un validador en un commit (block_id_flag de CometBFT)
+const (
+	CommitSignatureAbsent = "absent" // No firmó (1)
+	CommitSignatureCommit = "commit" // Firmó a favor del bloque (2)
+	CommitSignatureNil    = "nil"    // Votó nil (3)
+)
+
+// CommitSignature es la firma de un validador en el commit de un bloque
+type CommitSignature struct {
+	ValidatorAddress string    `json:"validatorAddress"` // Dirección CometBFT (ver consensusAddress en /api/v1/validators?height=)
+	Status           string    `json:"status"`           // commit, nil o absent
+	Timestamp        time.Time `json:"timestamp"`        // Hora del voto según el validador (cero si no firmó)
+	Signature        string    `json:"signature,omitempty"`
+}
+
+// BlockCommit son las firmas del commit de CometBFT que confirmó un bloque, en el orden del set de validadores
+// de esa altura (posiciones vacías incluidas como absent)
+type BlockCommit struct {
+	Height     int64             `json:"height"`
+	Round      int32             `json:"round"`
+	BlockID    string            `json:"blockId"` // Hash del bloque de CometBFT (no es el hash del bloque de la aplicación)
+	Signatures []CommitSignature `json:"signatures"`
+	Canonical  bool              `json:"canonical"` // false: commit visto por este nodo para el último bloque (no se guarda)
+}
+
+// commitSignatureStatus convierte el block_id_flag de CometBFT al estado de la firma
+func commitSignatureStatus(flag int) string {
+	switch flag {
+	case blockIDFlagCommit:
+		return CommitSignatureCommit
+	case blockIDFlagNil:
+		return CommitSignatureNil
+	default:
+		return CommitSignatureAbsent
+	}
+}
+
+// blockCommit convierte la respuesta de /commit de CometBFT
+func (r *rpcCommit) blockCommit() *BlockCommit {
+	commit := &BlockCommit{
+		Round:      r.SignedHeader.Commit.Round,
+		BlockID:    r.SignedHeader.Commit.BlockID.Hash,
+		Signatures: make([]CommitSignature, 0, len(r.SignedHeader.Commit.Signatures)),
+		Canonical:  r.Canonical,
+	}
+	commit.Height, _ = strconv.ParseInt(r.SignedHeader.Commit.Height, 10, 64)
+	for _, sig := range r.SignedHeader.Commit.Signatures {
+		signature := CommitSignature{
+			ValidatorAddress: strings.ToUpper(sig.ValidatorAddress),
+			Status:           commitSignatureStatus(sig.BlockIDFlag),
+			Signature:        sig.Signature,
+		}
+		if signature.Status != CommitSignatureAbsent {
+			signature.Timestamp = sig.Timestamp
+		}
+		commit.Signatures = append(commit.Signatures, signature)
+	}
+	return commit
+}
+
+// GetBlockCommit retorna las firmas del commit que confirmó un bloque: las guardadas en storage o, si todavía
+// no se guardaron, las del RPC de CometBFT (que se guardan si el commit es el definitivo)
+func (c *CometBFT) GetBlockCommit(ctx context.Context, height uint64) (*BlockCommit, error) {
+	if c.storage != nil {
+		if data, err := c.storage.GetBlockCommit(height); err == nil {
+			var commit BlockCommit
+			if err := json.Unmarshal(data, &commit); err == nil {
+				return &commit, nil
+			}
+			consensusLog.Warnf("Commit guardado de la altura %d inválido, se consulta de nuevo", height)
+		}
+	}
+
+	if !c.running {
+		return nil, fmt.Errorf("consenso no está corriendo")
+	}
+	client, err := c.rpc()
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.Commit(ctx, int64(height))
+	if err != nil {
+		if isHeightNotAvailable(err) {
+			return nil, fmt.Errorf("%w: %v", ErrHeightNotAvailable, err)
+		}
+		return nil, fmt.Errorf("commit falló: %w", err)
+	}
+	var rpcResult rpcCommit
+	if err := json.Unmarshal(result, &rpcResult); err != nil {
+		return nil, fmt.Errorf("error decodificando commit: %w", err)
+	}
+
+	commit := rpcResult.blockCommit()
+	if commit.Canonical && c.storage != nil {
+		if data, err := json.Marshal(commit); err == nil {
+			if err := c.storage.SaveBlockCommit(height, data); err != nil {
+				consensusLog.Warnf("Error guardando commit de la altura %d: %v", height, err)
+			}
+		}
+	}
+	return commit, nil
+}
+
+// enqueueCommitRecord pide guardar el commit de la altura anterior a un bloque confirmado: el bloque H
+// incluye el commit definitivo de H-1 (handler de AddBlockCommitHandler, no bloquea)
+func (c *CometBFT) enqueueCommitRecord(block *Block) {
+	if block == nil || block.Header.Height <= 1 {
+		return
+	}
+	select {
+	case c.commitHeights <- block.Header.Height - 1:
+	default:
+	}
+}
+
+// recordCommitsLoop guarda el commit de cada altura encolada hasta que se cierre stop
+func (c *CometBFT) recordCommitsLoop(heights <-chan uint64, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case height := <-heights:
+			ctx, cancel := context.WithTimeout(context.Background(), commitRecordTimeout)
+			if _, err := c.GetBlockCommit(ctx, height); err != nil {
+				consensusLog.Debugf("Commit de la altura %d no guardado: %v", height, err)
+			}
+			cancel()
+		}
+	}
+}
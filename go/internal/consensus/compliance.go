@@ -0,0 +1,237 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/logger"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// complianceLog es el logger del módulo de compliance (cambios de la lista y rechazos)
+var complianceLog = logger.For("compliance")
+
+// Modos de la lista de compliance
+const (
+	ComplianceBlacklist = "blacklist" // Rechaza transacciones desde o hacia direcciones listadas
+	ComplianceAllowlist = "allowlist" // Solo acepta transacciones entre direcciones listadas
+)
+
+// DefaultComplianceAuditSize es la cantidad de cambios de la lista que se conservan en la auditoría
+const DefaultComplianceAuditSize = 1000
+
+// ComplianceEntry es una dirección de la lista
+type ComplianceEntry struct {
+	Address string    `json:"address"`
+	Reason  string    `json:"reason,omitempty"`
+	AddedAt time.Time `json:"addedAt"`
+}
+
+// ComplianceAuditEntry registra un cambio de la lista
+type ComplianceAuditEntry struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"` // "add" o "remove"
+	Address string    `json:"address"`
+	Reason  string    `json:"reason,omitempty"`
+	Actor   string    `json:"actor,omitempty"` // Origen del cambio ("config", "admin-api")
+}
+
+// ComplianceStore persiste la lista y su auditoría (BlockchainDB)
+type ComplianceStore interface {
+	SaveComplianceList(data []byte) error
+	GetComplianceList() ([]byte, error)
+}
+
+// complianceRecord es el formato guardado de la lista
+type complianceRecord struct {
+	Entries []ComplianceEntry      `json:"entries"`
+	Audit   []ComplianceAuditEntry `json:"audit"`
+}
+
+// ComplianceList es una lista de direcciones bloqueadas (blacklist) o permitidas (allowlist) que se aplica
+// en CheckTx y PrepareProposal a from, to y el fee payer de cada transacción
+// La lista es configuración del nodo: ProcessProposal y FinalizeBlock no la aplican, así que un validador
+// no vota en contra de las propuestas de los demás por su propia lista
+type ComplianceList struct {
+	mu        sync.RWMutex
+	store     ComplianceStore
+	mode      string
+	entries   map[common.Address]ComplianceEntry
+	audit     []ComplianceAuditEntry
+	auditSize int
+}
+
+// NewComplianceList crea la lista en el modo indicado y carga las direcciones guardadas
+func NewComplianceList(store ComplianceStore, mode string) (*ComplianceList, error) {
+	if mode != ComplianceBlacklist && mode != ComplianceAllowlist {
+		return nil, fmt.Errorf("modo de compliance inválido: %q (blacklist o allowlist)", mode)
+	}
+	l := &ComplianceList{
+		store:     store,
+		mode:      mode,
+		entries:   make(map[common.Address]ComplianceEntry),
+		auditSize: DefaultComplianceAuditSize,
+	}
+
+	if store != nil {
+		data, err := store.GetComplianceList()
+		if err != nil {
+			return nil, fmt.Errorf("error cargando lista de compliance: %w", err)
+		}
+		if data != nil {
+			var record complianceRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return nil, fmt.Errorf("lista de compliance guardada inválida: %w", err)
+			}
+			for _, entry := range record.Entries {
+				l.entries[common.HexToAddress(entry.Address)] = entry
+			}
+			l.audit = record.Audit
+		}
+	}
+	return l, nil
+}
+
+// Mode retorna el modo de la lista
+func (l *ComplianceList) Mode() string {
+	return l.mode
+}
+
+// Add agrega una dirección a la lista y retorna si no estaba; actor identifica el origen del cambio en la
+// auditoría
+func (l *ComplianceList) Add(address, reason, actor string) (bool, error) {
+	if !common.IsHexAddress(address) {
+		return false, fmt.Errorf("dirección inválida: %s", address)
+	}
+	addr := common.HexToAddress(address)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.entries[addr]; ok {
+		return false, nil
+	}
+	now := time.Now().UTC()
+	l.entries[addr] = ComplianceEntry{Address: addr.Hex(), Reason: reason, AddedAt: now}
+	l.record(ComplianceAuditEntry{Time: now, Action: "add", Address: addr.Hex(), Reason: reason, Actor: actor})
+	if err := l.persist(); err != nil {
+		delete(l.entries, addr)
+		l.audit = l.audit[:len(l.audit)-1]
+		return false, err
+	}
+	complianceLog.Infof("Dirección agregada a la %s: %s (razón: %q, origen: %s)", l.mode, addr.Hex(), reason, actor)
+	return true, nil
+}
+
+// Remove quita una dirección de la lista y retorna si estaba
+func (l *ComplianceList) Remove(address, reason, actor string) (bool, error) {
+	if !common.IsHexAddress(address) {
+		return false, fmt.Errorf("dirección inválida: %s", address)
+	}
+	addr := common.HexToAddress(address)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.entries[addr]
+	if !ok {
+		return false, nil
+	}
+	delete(l.entries, addr)
+	l.record(ComplianceAuditEntry{Time: time.Now().UTC(), Action: "remove", Address: addr.Hex(), Reason: reason, Actor: actor})
+	if err := l.persist(); err != nil {
+		l.entries[addr] = entry
+		l.audit = l.audit[:len(l.audit)-1]
+		return false, err
+	}
+	complianceLog.Infof("Dirección quitada de la %s: %s (razón: %q, origen: %s)", l.mode, addr.Hex(), reason, actor)
+	return true, nil
+}
+
+// Entries retorna las direcciones de la lista ordenadas
+func (l *ComplianceList) Entries() []ComplianceEntry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	entries := make([]ComplianceEntry, 0, len(l.entries))
+	for _, entry := range l.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Address < entries[j].Address })
+	return entries
+}
+
+// Audit retorna los últimos cambios de la lista, del más antiguo al más reciente
+func (l *ComplianceList) Audit() []ComplianceAuditEntry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]ComplianceAuditEntry{}, l.audit...)
+}
+
+// Check verifica las direcciones de una transacción contra la lista
+func (l *ComplianceList) Check(tx *Transaction) error {
+	addresses := []string{tx.From}
+	if tx.To != "" {
+		addresses = append(addresses, tx.To)
+	}
+	if tx.FeePayer != nil {
+		addresses = append(addresses, tx.FeePayer.Address)
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, address := range addresses {
+		_, listed := l.entries[common.HexToAddress(address)]
+		switch {
+		case l.mode == ComplianceBlacklist && listed:
+			return NewTxError(CodeAddressBlocked, "dirección bloqueada: %s", address)
+		case l.mode == ComplianceAllowlist && !listed:
+			return NewTxError(CodeAddressBlocked, "dirección no permitida: %s", address)
+		}
+	}
+	return nil
+}
+
+// record agrega un cambio a la auditoría descartando los más antiguos (requiere el lock)
+func (l *ComplianceList) record(entry ComplianceAuditEntry) {
+	if len(l.audit) >= l.auditSize {
+		l.audit = append([]ComplianceAuditEntry{}, l.audit[len(l.audit)-l.auditSize+1:]...)
+	}
+	l.audit = append(l.audit, entry)
+}
+
+// persist guarda la lista y la auditoría (requiere el lock)
+func (l *ComplianceList) persist() error {
+	if l.store == nil {
+		return nil
+	}
+	record := complianceRecord{Entries: make([]ComplianceEntry, 0, len(l.entries)), Audit: l.audit}
+	for _, entry := range l.entries {
+		record.Entries = append(record.Entries, entry)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error serializando lista de compliance: %w", err)
+	}
+	if err := l.store.SaveComplianceList(data); err != nil {
+		return fmt.Errorf("error guardando lista de compliance: %w", err)
+	}
+	return nil
+}
+
+// SetComplianceList establece la lista de compliance consultada en CheckTx y en las propuestas (nil = sin lista)
+func (app *ABCIApp) SetComplianceList(list *ComplianceList) {
+	app.compliance = list
+}
+
+// checkCompliance verifica una transacción contra la lista de compliance y registra los rechazos
+func (app *ABCIApp) checkCompliance(tx *Transaction, stage string) error {
+	if app.compliance == nil {
+		return nil
+	}
+	if err := app.compliance.Check(tx); err != nil {
+		complianceLog.Warnf("Transacción %s rechazada en %s: %v", tx.Hash, stage, err)
+		return err
+	}
+	return nil
+}
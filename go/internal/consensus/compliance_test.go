@@ -0,0 +1,80 @@
+package consensus
+
+import (
+	"testing"
+)
+
+// memComplianceStore es un ComplianceStore en memoria para tests
+type memComplianceStore struct {
+	data []byte
+}
+
+func (m *memComplianceStore) SaveComplianceList(data []byte) error { m.data = data; return nil }
+func (m *memComplianceStore) GetComplianceList() ([]byte, error)   { return m.data, nil }
+
+// TestComplianceList prueba los modos blacklist y allowlist y la persistencia de la lista y su auditoría
+func TestComplianceList(t *testing.T) {
+	const (
+		alice = "0x00000000000000000000000000000000000000a1"
+		bob   = "0x00000000000000000000000000000000000000b0"
+		payer = "0x00000000000000000000000000000000000000fe"
+	)
+	if _, err := NewComplianceList(nil, "denylist"); err == nil {
+		t.Error("Se esperaba un error por modo inválido")
+	}
+
+	store := &memComplianceStore{}
+	blacklist, err := NewComplianceList(store, ComplianceBlacklist)
+	if err != nil {
+		t.Fatalf("Error creando lista: %v", err)
+	}
+	if added, err := blacklist.Add(bob, "sanción", "test"); err != nil || !added {
+		t.Fatalf("Error agregando dirección: %v", err)
+	}
+	if added, _ := blacklist.Add(bob, "", "test"); added {
+		t.Error("Una dirección repetida no debería agregarse")
+	}
+
+	tx := &Transaction{From: alice, To: "0x0000000000000000000000000000000000000abc"}
+	if err := blacklist.Check(tx); err != nil {
+		t.Errorf("Transacción sin direcciones listadas rechazada: %v", err)
+	}
+	for _, blocked := range []*Transaction{
+		{From: bob, To: alice},
+		{From: alice, To: bob},
+		{From: alice, To: alice, FeePayer: &FeePayerAuth{Address: bob}},
+	} {
+		if err := blacklist.Check(blocked); ErrorCode(err, CodeOK) != CodeAddressBlocked {
+			t.Errorf("Se esperaba CodeAddressBlocked para %+v: %v", blocked, err)
+		}
+	}
+
+	// La lista y la auditoría se recuperan del store
+	reloaded, err := NewComplianceList(store, ComplianceBlacklist)
+	if err != nil {
+		t.Fatalf("Error recargando lista: %v", err)
+	}
+	if entries := reloaded.Entries(); len(entries) != 1 || entries[0].Reason != "sanción" {
+		t.Fatalf("Lista no recuperada: %+v", entries)
+	}
+	if removed, _ := reloaded.Remove(bob, "revisión", "test"); !removed {
+		t.Fatal("La dirección debería quitarse")
+	}
+	if audit := reloaded.Audit(); len(audit) != 2 || audit[0].Action != "add" || audit[1].Action != "remove" || audit[1].Reason != "revisión" {
+		t.Errorf("Auditoría incorrecta: %+v", audit)
+	}
+
+	// Allowlist: todas las direcciones de la transacción deben estar listadas
+	allowlist, _ := NewComplianceList(nil, ComplianceAllowlist)
+	allowlist.Add(alice, "", "test")
+	allowlist.Add(bob, "", "test")
+	if err := allowlist.Check(&Transaction{From: alice, To: bob}); err != nil {
+		t.Errorf("Transacción entre direcciones permitidas rechazada: %v", err)
+	}
+	if err := allowlist.Check(&Transaction{From: alice}); err != nil {
+		t.Errorf("Creación de contrato de una dirección permitida rechazada: %v", err)
+	}
+	if err := allowlist.Check(&Transaction{From: alice, To: bob, FeePayer: &FeePayerAuth{Address: payer}}); ErrorCode(err, CodeOK) != CodeAddressBlocked {
+		t.Errorf("Se esperaba rechazar un fee payer no permitido: %v", err)
+	}
+}
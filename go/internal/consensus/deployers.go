@@ -0,0 +1,164 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DeployerRegistryAddress es la dirección reservada que recibe las transacciones de gobierno del registro
+// de deployers (ver execution.DeployerRegistryAddress)
+const DeployerRegistryAddress = execution.DeployerRegistryAddress
+
+// Acciones del registro de deployers
+const (
+	DeployerActionAllow       = "allow"        // Aprueba a address como deployer
+	DeployerActionRevoke      = "revoke"       // Quita la aprobación de address
+	DeployerActionAddAdmin    = "add-admin"    // Da a address el rol de administrador del registro
+	DeployerActionRemoveAdmin = "remove-admin" // Quita el rol de administrador a address
+	DeployerActionRestrict    = "restrict"     // Solo los deployers aprobados crean contratos
+	DeployerActionUnrestrict  = "unrestrict"   // Cualquier cuenta crea contratos
+)
+
+// DeployerPayload es el contenido (JSON en Transaction.Data) de una transacción al registro de deployers
+// Solo la envían los administradores del registro
+type DeployerPayload struct {
+	Action  string `json:"action"`
+	Address string `json:"address,omitempty"` // Cuenta afectada (salvo restrict y unrestrict)
+}
+
+// DeployerGenesis es la política de despliegue inicial en app_state del genesis
+type DeployerGenesis struct {
+	Restricted bool     `json:"restricted"`
+	Admins     []string `json:"admins,omitempty"`
+	Deployers  []string `json:"deployers,omitempty"`
+}
+
+// Validate verifica las direcciones de la política; restringida sin administradores no se podría cambiar
+func (d *DeployerGenesis) Validate() error {
+	for _, address := range append(append([]string{}, d.Admins...), d.Deployers...) {
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("dirección inválida en deployers del genesis: %s", address)
+		}
+	}
+	if d.Restricted && len(d.Admins) == 0 && len(d.Deployers) == 0 {
+		return fmt.Errorf("deployers del genesis: despliegue restringido sin administradores ni deployers")
+	}
+	return nil
+}
+
+// isDeployerTx retorna si la transacción va dirigida al registro de deployers
+func isDeployerTx(tx *Transaction) bool {
+	return strings.EqualFold(tx.To, DeployerRegistryAddress)
+}
+
+// initGenesisDeployers escribe la política de despliegue del genesis en el estado (una sola vez, en InitChain)
+func (app *ABCIApp) initGenesisDeployers(appState []byte) error {
+	params, err := ParseGenesisParams(appState)
+	if err != nil {
+		return err
+	}
+	if params.Deployers == nil || app.executor == nil {
+		return nil
+	}
+
+	roles := make(map[string]uint64)
+	for _, address := range params.Deployers.Admins {
+		roles[strings.ToLower(address)] |= execution.DeployerRoleAdmin
+	}
+	for _, address := range params.Deployers.Deployers {
+		roles[strings.ToLower(address)] |= execution.DeployerRoleDeployer
+	}
+	for address, role := range roles {
+		if err := app.executor.SetDeployerRoles(address, role); err != nil {
+			return fmt.Errorf("error registrando deployer genesis %s: %w", address, err)
+		}
+	}
+	if err := app.executor.SetDeploymentRestricted(params.Deployers.Restricted); err != nil {
+		return err
+	}
+	consensusLog.Infof("Política de despliegue del genesis: restringido=%v, %d cuentas con rol",
+		params.Deployers.Restricted, len(roles))
+	return nil
+}
+
+// checkDeployerPolicy rechaza en CheckTx los cambios del registro de quien no lo administra y los despliegues
+// directos (sin to) de cuentas no aprobadas. Los CREATE desde contratos se bloquean al ejecutar
+func (app *ABCIApp) checkDeployerPolicy(tx *Transaction) error {
+	if app.executor == nil || (!isDeployerTx(tx) && tx.To != "") {
+		return nil
+	}
+	status, err := app.executor.GetCommittedDeployerStatus(tx.From)
+	if err != nil {
+		return nil
+	}
+	if isDeployerTx(tx) && !status.Admin {
+		return NewTxError(CodeDeployNotAllowed, "cuenta %s no administra el registro de deployers", tx.From)
+	}
+	if tx.To == "" && !status.CanDeploy {
+		return NewTxError(CodeDeployNotAllowed, "cuenta %s no autorizada a desplegar contratos", tx.From)
+	}
+	return nil
+}
+
+// applyDeployerTx aplica al registro de deployers una transacción ya ejecutada con éxito
+// Si la acción falla, el valor transferido al registro se devuelve al remitente
+func (app *ABCIApp) applyDeployerTx(tx *Transaction) error {
+	err := app.executeDeployerAction(tx)
+	if err == nil {
+		return nil
+	}
+	if value, ok := new(big.Int).SetString(tx.Value, 10); ok && value.Sign() > 0 {
+		if refundErr := app.executor.TransferBalance(DeployerRegistryAddress, tx.From, value); refundErr != nil {
+			return fmt.Errorf("%v (error devolviendo fondos: %v)", err, refundErr)
+		}
+	}
+	return err
+}
+
+// executeDeployerAction decodifica el payload y cambia el registro (solo administradores)
+func (app *ABCIApp) executeDeployerAction(tx *Transaction) error {
+	if value, ok := new(big.Int).SetString(tx.Value, 10); ok && value.Sign() > 0 {
+		return fmt.Errorf("las transacciones al registro de deployers no llevan valor")
+	}
+	if app.executor.DeployerRoles(tx.From)&execution.DeployerRoleAdmin == 0 {
+		return fmt.Errorf("cuenta %s no administra el registro de deployers", tx.From)
+	}
+
+	var payload DeployerPayload
+	if err := json.Unmarshal(tx.Data, &payload); err != nil {
+		return fmt.Errorf("payload del registro de deployers inválido: %w", err)
+	}
+
+	switch payload.Action {
+	case DeployerActionRestrict, DeployerActionUnrestrict:
+		return app.executor.SetDeploymentRestricted(payload.Action == DeployerActionRestrict)
+	case DeployerActionAllow, DeployerActionRevoke, DeployerActionAddAdmin, DeployerActionRemoveAdmin:
+	default:
+		return fmt.Errorf("acción del registro de deployers desconocida: %q", payload.Action)
+	}
+
+	if !common.IsHexAddress(payload.Address) {
+		return fmt.Errorf("dirección inválida: %q", payload.Address)
+	}
+	roles := app.executor.DeployerRoles(payload.Address)
+	switch payload.Action {
+	case DeployerActionAllow:
+		roles |= execution.DeployerRoleDeployer
+	case DeployerActionRevoke:
+		roles &^= execution.DeployerRoleDeployer
+	case DeployerActionAddAdmin:
+		roles |= execution.DeployerRoleAdmin
+	case DeployerActionRemoveAdmin:
+		roles &^= execution.DeployerRoleAdmin
+	}
+	if err := app.executor.SetDeployerRoles(payload.Address, roles); err != nil {
+		return err
+	}
+	consensusLog.Infof("Registro de deployers: %s %s (por %s)", payload.Action, payload.Address, tx.From)
+	return nil
+}
@@ -0,0 +1,107 @@
+package consensus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// TestDeployerRegistry prueba la política de despliegue del genesis y su gobierno con transacciones al registro
+func TestDeployerRegistry(t *testing.T) {
+	testDir := createValidatorTestDir("deployers")
+	defer func() {
+		if err := cleanupValidatorTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+	app := NewABCIApp(db, evm, nil, "test-chain")
+
+	const (
+		admin    = "0x1111111111111111111111111111111111111111"
+		deployer = "0x2222222222222222222222222222222222222222"
+		user     = "0x3333333333333333333333333333333333333333"
+	)
+
+	if _, err := ParseGenesisParams([]byte(`{"deployers":{"restricted":true}}`)); err == nil {
+		t.Error("Un despliegue restringido sin cuentas con rol debería rechazarse")
+	}
+	if _, err := ParseGenesisParams([]byte(`{"deployers":{"admins":["0xzz"]}}`)); err == nil {
+		t.Error("Una dirección inválida debería rechazarse")
+	}
+	appState := []byte(`{"deployers":{"restricted":true,"admins":["` + admin + `"],"deployers":["` + deployer + `"]}}`)
+	if err := app.initGenesisDeployers(appState); err != nil {
+		t.Fatalf("Error aplicando la política del genesis: %v", err)
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	// CheckTx: despliegues directos y cambios del registro
+	if err := app.checkDeployerPolicy(&Transaction{From: user, To: ""}); ErrorCode(err, CodeOK) != CodeDeployNotAllowed {
+		t.Errorf("Se esperaba CodeDeployNotAllowed para el despliegue de %s: %v", user, err)
+	}
+	if err := app.checkDeployerPolicy(&Transaction{From: deployer, To: ""}); err != nil {
+		t.Errorf("El deployer aprobado debería poder desplegar: %v", err)
+	}
+	if err := app.checkDeployerPolicy(&Transaction{From: deployer, To: DeployerRegistryAddress}); ErrorCode(err, CodeOK) != CodeDeployNotAllowed {
+		t.Errorf("Solo los administradores cambian el registro: %v", err)
+	}
+	if err := app.checkDeployerPolicy(&Transaction{From: user, To: "0x4444444444444444444444444444444444444444"}); err != nil {
+		t.Errorf("Las llamadas comunes no se restringen: %v", err)
+	}
+
+	governance := func(from, action, address string) error {
+		data, _ := json.Marshal(DeployerPayload{Action: action, Address: address})
+		return app.applyDeployerTx(&Transaction{From: from, To: DeployerRegistryAddress, Value: "0", Data: data})
+	}
+	if err := governance(user, DeployerActionAllow, user); err == nil {
+		t.Error("Una cuenta sin rol de administrador no debería cambiar el registro")
+	}
+	if err := governance(admin, DeployerActionAllow, user); err != nil {
+		t.Fatalf("Error aprobando deployer: %v", err)
+	}
+	if err := governance(admin, DeployerActionRevoke, deployer); err != nil {
+		t.Fatalf("Error revocando deployer: %v", err)
+	}
+	if err := governance(admin, "promote", user); err == nil {
+		t.Error("Una acción desconocida debería rechazarse")
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	for address, allowed := range map[string]bool{user: true, deployer: false, admin: false} {
+		status, err := evm.GetCommittedDeployerStatus(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !status.Restricted || status.CanDeploy != allowed {
+			t.Errorf("Política incorrecta para %s: %+v", address, status)
+		}
+	}
+
+	// Sin restricción cualquier cuenta despliega
+	if err := governance(admin, DeployerActionUnrestrict, ""); err != nil {
+		t.Fatalf("Error quitando la restricción: %v", err)
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+	if status, _ := evm.GetCommittedDeployerStatus(deployer); status.Restricted || !status.CanDeploy {
+		t.Errorf("Sin restricción la cuenta debería poder desplegar: %+v", status)
+	}
+}
@@ -0,0 +1,222 @@
+package consensus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// ErrNotDevMode se retorna al usar los controles de minado y hora fuera del modo desarrollo
+var ErrNotDevMode = errors.New("disponible solo en modo desarrollo")
+
+// devBlockRequestPrefix identifica las transacciones sin contenido con las que el modo desarrollo pide un bloque
+// Las transacciones del API entran solo al mempool local, que CometBFT no ve: con CreateEmptyBlocks=false
+// el consenso esperaría para siempre. El pedido entra al mempool de CometBFT, dispara la propuesta y
+// PrepareProposal lo descarta; el recheck posterior al commit lo expulsa del mempool
+var devBlockRequestPrefix = []byte("oxy-dev-block:")
+
+// devBlockRequests numera los pedidos de bloque (bytes distintos para que la caché del mempool no los descarte)
+var devBlockRequests atomic.Uint64
+
+// isDevBlockRequest retorna si la transacción es un pedido de bloque del modo desarrollo
+func isDevBlockRequest(tx []byte) bool {
+	return bytes.HasPrefix(tx, devBlockRequestPrefix)
+}
+
+// devClock ajusta la hora de los bloques del modo desarrollo (equivalente a evm_increaseTime y
+// evm_setNextBlockTimestamp de Hardhat/Anvil)
+// Los ajustes cambian el resultado de FinalizeBlock y solo viven en memoria: son válidos porque el modo
+// desarrollo tiene un único validador, y no sobreviven a un reinicio
+type devClock struct {
+	mu sync.Mutex
+	devClockState
+}
+
+// devClockState son los ajustes del reloj (se guardan con los snapshots de desarrollo)
+type devClockState struct {
+	offset time.Duration // Adelanto acumulado sobre la hora del header de CometBFT
+	next   int64         // Timestamp fijado para el próximo bloque (0 = ninguno)
+	last   int64         // Timestamp del último bloque
+}
+
+// blockTime retorna la hora del bloque a partir de la del header, sin retroceder respecto del anterior
+func (c *devClock) blockTime(header time.Time) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	blockTime := header.Add(c.offset)
+	if c.next != 0 {
+		// Los bloques siguientes continúan desde el timestamp fijado
+		blockTime = time.Unix(c.next, 0)
+		c.offset = blockTime.Sub(header)
+		c.next = 0
+	}
+	if blockTime.Unix() < c.last {
+		blockTime = time.Unix(c.last, 0)
+	}
+	c.last = blockTime.Unix()
+	return blockTime
+}
+
+// increase adelanta la hora de los próximos bloques y retorna el adelanto total en segundos
+func (c *devClock) increase(seconds int64) (int64, error) {
+	if seconds < 0 {
+		return 0, NewTxError(CodeInvalidTx, "no se puede retroceder la hora: %d segundos", seconds)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offset += time.Duration(seconds) * time.Second
+	return int64(c.offset / time.Second), nil
+}
+
+// setNext fija el timestamp del próximo bloque, que debe ser posterior al del último
+func (c *devClock) setNext(timestamp int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if timestamp <= c.last {
+		return NewTxError(CodeInvalidTx, "timestamp %d no es posterior al del último bloque (%d)", timestamp, c.last)
+	}
+	c.next = timestamp
+	return nil
+}
+
+// save retorna los ajustes actuales
+func (c *devClock) save() devClockState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.devClockState
+}
+
+// restore vuelve a ajustes guardados con save
+func (c *devClock) restore(state devClockState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.devClockState = state
+}
+
+// SetDevMode habilita el modo desarrollo: pedidos de bloque en CheckTx y control de la hora de los bloques
+func (app *ABCIApp) SetDevMode(enabled bool) {
+	app.devMode = enabled
+	if !enabled || app.storage == nil {
+		return
+	}
+
+	// La hora no retrocede respecto del último bloque guardado (pudo haberse adelantado antes de reiniciar)
+	if height, err := app.storage.GetLatestHeight(); err == nil {
+		if data, err := app.storage.GetBlock(height); err == nil {
+			if block, err := DecodeBlock(data); err == nil {
+				app.devClock.last = block.Header.Timestamp.Unix()
+			}
+		}
+	}
+}
+
+// checkDevBlockRequest acepta un pedido de bloque al entrar al mempool y lo expulsa en el recheck
+func (app *ABCIApp) checkDevBlockRequest(checkType abcitypes.CheckTxType) *abcitypes.CheckTxResponse {
+	if !app.devMode {
+		return checkTxError(CodeDecodeError, "Pedido de bloque rechazado: el nodo no está en modo desarrollo")
+	}
+	if checkType == abcitypes.CHECK_TX_TYPE_RECHECK {
+		return checkTxError(CodeInvalidTx, "Pedido de bloque ya atendido")
+	}
+	return &abcitypes.CheckTxResponse{Code: CodeOK, Log: "OK"}
+}
+
+// requestDevBlock pide a CometBFT que proponga un bloque (solo en modo desarrollo)
+func (c *CometBFT) requestDevBlock() error {
+	client, err := c.rpc()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request := fmt.Appendf(bytes.Clone(devBlockRequestPrefix), "%d", devBlockRequests.Add(1))
+	if _, err := client.BroadcastTxAsync(ctx, request); err != nil {
+		return fmt.Errorf("error pidiendo bloque a CometBFT: %w", err)
+	}
+	return nil
+}
+
+// devApp retorna la aplicación ABCI si el nodo corre en modo desarrollo
+func (c *CometBFT) devApp() (*ABCIApp, error) {
+	if !c.running || c.node == nil || c.node.abciApp == nil {
+		return nil, NewTxError(CodeUnavailable, "consenso no está corriendo")
+	}
+	if !c.config.DevMode {
+		return nil, ErrNotDevMode
+	}
+	return c.node.abciApp, nil
+}
+
+// IsDevMode retorna si el nodo corre en modo desarrollo
+func (c *CometBFT) IsDevMode() bool {
+	return c.config.DevMode
+}
+
+// MineBlocks produce blocks bloques vacíos (además de las transacciones pendientes) y espera a que se
+// confirmen; si timestamp no es 0 se usa como hora del primero. Retorna la altura del último bloque
+func (c *CometBFT) MineBlocks(ctx context.Context, blocks int, timestamp int64) (uint64, error) {
+	app, err := c.devApp()
+	if err != nil {
+		return 0, err
+	}
+	if blocks < 1 {
+		return 0, NewTxError(CodeInvalidTx, "cantidad de bloques inválida: %d", blocks)
+	}
+	if timestamp != 0 {
+		if err := app.devClock.setNext(timestamp); err != nil {
+			return 0, err
+		}
+	}
+
+	for i := 0; i < blocks; i++ {
+		target := app.committedHeight.Load() + 1
+		if err := c.requestDevBlock(); err != nil {
+			return 0, err
+		}
+		if err := app.waitForCommittedHeight(ctx, target); err != nil {
+			return 0, err
+		}
+	}
+	return app.committedHeight.Load(), nil
+}
+
+// IncreaseTime adelanta la hora de los próximos bloques y retorna el adelanto total en segundos
+func (c *CometBFT) IncreaseTime(seconds int64) (int64, error) {
+	app, err := c.devApp()
+	if err != nil {
+		return 0, err
+	}
+	return app.devClock.increase(seconds)
+}
+
+// SetNextBlockTimestamp fija el timestamp (unix, segundos) del próximo bloque
+func (c *CometBFT) SetNextBlockTimestamp(timestamp int64) error {
+	app, err := c.devApp()
+	if err != nil {
+		return err
+	}
+	return app.devClock.setNext(timestamp)
+}
+
+// waitForCommittedHeight espera a que se confirme el bloque height
+func (app *ABCIApp) waitForCommittedHeight(ctx context.Context, height uint64) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for app.committedHeight.Load() < height {
+		select {
+		case <-ctx.Done():
+			return NewTxError(CodeTimeout, "bloque %d sin confirmar: %v", height, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
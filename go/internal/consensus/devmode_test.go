@@ -0,0 +1,171 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TestDevBlockRequest prueba que el pedido de bloque dispare la propuesta sin llegar al bloque
+func TestDevBlockRequest(t *testing.T) {
+	rpc := &fakeRPC{}
+	c := newFakeCometBFT(rpc)
+	c.config.DevMode = true
+	c.rateLimiter = NewRateLimiter(10, time.Minute, 10)
+
+	if err := c.SubmitTransaction(&Transaction{Hash: "0x01", From: "0xaa"}); err != nil {
+		t.Fatalf("Error enviando transacción: %v", err)
+	}
+	if len(rpc.broadcasts) != 1 || !isDevBlockRequest(rpc.broadcasts[0]) {
+		t.Fatalf("Se esperaba un pedido de bloque: %q", rpc.broadcasts)
+	}
+	request := rpc.broadcasts[0]
+
+	app := NewABCIApp(nil, nil, nil, "oxy-dev")
+	checkTx := func(checkType abcitypes.CheckTxType) uint32 {
+		resp, err := app.CheckTx(context.Background(), &abcitypes.CheckTxRequest{Tx: request, Type: checkType})
+		if err != nil {
+			t.Fatalf("Error en CheckTx: %v", err)
+		}
+		return resp.Code
+	}
+	if code := checkTx(abcitypes.CHECK_TX_TYPE_CHECK); code != CodeDecodeError {
+		t.Errorf("Fuera del modo desarrollo se esperaba rechazo, código %d", code)
+	}
+	app.SetDevMode(true)
+	if code := checkTx(abcitypes.CHECK_TX_TYPE_CHECK); code != CodeOK {
+		t.Errorf("Se esperaba el pedido aceptado, código %d", code)
+	}
+	if code := checkTx(abcitypes.CHECK_TX_TYPE_RECHECK); code == CodeOK {
+		t.Error("Se esperaba el pedido expulsado en el recheck")
+	}
+
+	resp, err := app.PrepareProposal(context.Background(), &abcitypes.PrepareProposalRequest{
+		Height:     1,
+		MaxTxBytes: 1 << 20,
+		Txs:        [][]byte{request},
+	})
+	if err != nil {
+		t.Fatalf("Error en PrepareProposal: %v", err)
+	}
+	if len(resp.Txs) != 0 {
+		t.Errorf("El pedido de bloque no debe llegar a la propuesta: %q", resp.Txs)
+	}
+}
+
+// TestDevClock prueba el adelanto de la hora y el timestamp fijado del próximo bloque
+func TestDevClock(t *testing.T) {
+	var clock devClock
+	header := time.Unix(1000, 0)
+
+	if got := clock.blockTime(header).Unix(); got != 1000 {
+		t.Errorf("Sin ajustes se esperaba la hora del header, obtenido %d", got)
+	}
+	if offset, err := clock.increase(3600); err != nil || offset != 3600 {
+		t.Fatalf("increase: offset=%d err=%v", offset, err)
+	}
+	if _, err := clock.increase(-1); err == nil {
+		t.Error("Se esperaba error al retroceder la hora")
+	}
+	if got := clock.blockTime(header.Add(time.Second)).Unix(); got != 4601 {
+		t.Errorf("Se esperaba la hora adelantada 4601, obtenido %d", got)
+	}
+
+	if err := clock.setNext(4601); err == nil {
+		t.Error("Se esperaba error con un timestamp no posterior al último bloque")
+	}
+	if err := clock.setNext(10000); err != nil {
+		t.Fatalf("setNext: %v", err)
+	}
+	if got := clock.blockTime(header.Add(2 * time.Second)).Unix(); got != 10000 {
+		t.Errorf("Se esperaba el timestamp fijado 10000, obtenido %d", got)
+	}
+	// Los bloques siguientes continúan desde el timestamp fijado
+	if got := clock.blockTime(header.Add(5 * time.Second)).Unix(); got != 10003 {
+		t.Errorf("Se esperaba 10003, obtenido %d", got)
+	}
+	// La hora nunca retrocede respecto del último bloque
+	if got := clock.blockTime(header).Unix(); got != 10003 {
+		t.Errorf("Se esperaba 10003 sin retroceder, obtenido %d", got)
+	}
+}
+
+// TestDevSnapshotRevert prueba que revertir vuelva al estado y a los bloques del snapshot
+func TestDevSnapshotRevert(t *testing.T) {
+	opts := SimulationOptions{
+		Seed:          3,
+		TxsPerBlock:   4,
+		Accounts:      2,
+		ChainID:       "oxy-dev",
+		MinStake:      new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)),
+		MaxValidators: 100,
+	}
+	workload := newSimulationWorkload(opts)
+	app, closeApp, err := openSimulationApp(t.TempDir(), opts, workload)
+	if err != nil {
+		t.Fatalf("Error abriendo la aplicación: %v", err)
+	}
+	defer closeApp()
+	app.SetDevMode(true)
+
+	produce := func(height int64) {
+		txs, _, err := workload.block(app)
+		if err != nil {
+			t.Fatalf("Error generando bloque %d: %v", height, err)
+		}
+		_, err = app.FinalizeBlock(context.Background(), &abcitypes.FinalizeBlockRequest{
+			Height:          height,
+			Time:            time.Unix(simulationGenesisTime+height*5, 0).UTC(),
+			ProposerAddress: common.HexToAddress(workload.validatorAddress()).Bytes(),
+			Txs:             txs,
+		})
+		if err != nil {
+			t.Fatalf("Error en FinalizeBlock %d: %v", height, err)
+		}
+		if _, err := app.Commit(context.Background(), &abcitypes.CommitRequest{}); err != nil {
+			t.Fatalf("Error en Commit %d: %v", height, err)
+		}
+	}
+	stateManager := app.executor.GetStateManager()
+	sender := workload.accounts[0].address.Hex()
+
+	produce(1)
+	snap, err := app.takeDevSnapshot()
+	if err != nil {
+		t.Fatalf("Error tomando snapshot: %v", err)
+	}
+	balance := app.executor.GetBalance(sender)
+
+	produce(2)
+	produce(3)
+	if stateManager.CommittedRoot() == snap.root {
+		t.Fatal("Los bloques posteriores al snapshot deberían cambiar el estado")
+	}
+
+	if _, err := app.revertDevSnapshot(snap.id); err != nil {
+		t.Fatalf("Error revirtiendo: %v", err)
+	}
+	if root := stateManager.CommittedRoot(); root != snap.root {
+		t.Errorf("Root %s tras revertir, se esperaba %s", root.Hex(), snap.root.Hex())
+	}
+	if height := latestHeight(app.storage); height != 1 {
+		t.Errorf("Último bloque %d tras revertir, se esperaba 1", height)
+	}
+	if got := app.executor.GetBalance(sender); got.Cmp(balance) != 0 {
+		t.Errorf("Balance %s tras revertir, se esperaba %s", got, balance)
+	}
+	if _, err := app.revertDevSnapshot(snap.id); !errors.Is(err, ErrDevSnapshotNotFound) {
+		t.Errorf("Un snapshot usado no debería poder revertirse otra vez: %v", err)
+	}
+
+	// La cadena continúa con la numeración de CometBFT sobre el estado revertido
+	produce(4)
+	if height := latestHeight(app.storage); height != 4 {
+		t.Errorf("Último bloque %d, se esperaba 4", height)
+	}
+}
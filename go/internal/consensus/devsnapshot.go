@@ -0,0 +1,147 @@
+package consensus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrDevSnapshotNotFound se retorna al revertir a un snapshot de desarrollo inexistente o ya usado
+var ErrDevSnapshotNotFound = errors.New("snapshot de desarrollo no encontrado")
+
+// devSnapshot es una copia del estado de la cadena de desarrollo (equivalente a evm_snapshot)
+// El estado EVM no se copia: el trie conserva los roots anteriores, basta con guardar el root. El storage
+// (bloques, metadata, validadores, índices) se copia completo; la cadena de desarrollo es chica
+type devSnapshot struct {
+	id      uint64
+	height  uint64      // Último bloque guardado al tomar el snapshot
+	root    common.Hash // Root del estado EVM
+	storage [][2][]byte // Contenido de blockchain.db (incluye el root con el que se reabre el estado)
+	clock   devClockState
+}
+
+// takeDevSnapshot guarda el estado actual de la cadena como un nuevo snapshot
+func (app *ABCIApp) takeDevSnapshot() (*devSnapshot, error) {
+	app.devBlockMu.Lock()
+	defer app.devBlockMu.Unlock()
+
+	// El trie del root actual tiene que estar en disco para poder reabrirlo al revertir
+	if err := app.executor.FlushState(); err != nil {
+		return nil, fmt.Errorf("error escribiendo estado EVM: %w", err)
+	}
+
+	snap := &devSnapshot{
+		height: latestHeight(app.storage),
+		root:   app.executor.GetStateManager().CommittedRoot(),
+		clock:  app.devClock.save(),
+	}
+	err := app.storage.ExportKV(func(key, value []byte) error {
+		snap.storage = append(snap.storage, [2][]byte{bytes.Clone(key), bytes.Clone(value)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error copiando storage: %w", err)
+	}
+
+	app.devSnapshotSeq++
+	snap.id = app.devSnapshotSeq
+	app.devSnapshots = append(app.devSnapshots, snap)
+	consensusLog.Infof("Snapshot de desarrollo %d tomado: altura=%d, root=%s", snap.id, snap.height, snap.root.Hex())
+	return snap, nil
+}
+
+// revertDevSnapshot vuelve la cadena al snapshot id (equivalente a evm_revert)
+// Como en Hardhat, el snapshot y los posteriores dejan de existir. Los bloques guardados después del
+// snapshot se descartan, pero la altura de CometBFT no retrocede: el próximo bloque continúa su numeración
+func (app *ABCIApp) revertDevSnapshot(id uint64) (*devSnapshot, error) {
+	app.devBlockMu.Lock()
+	defer app.devBlockMu.Unlock()
+
+	index := -1
+	for i, snap := range app.devSnapshots {
+		if snap.id == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrDevSnapshotNotFound, id)
+	}
+	snap := app.devSnapshots[index]
+	app.devSnapshots = app.devSnapshots[:index]
+
+	err := app.storage.ImportKV(func(put func(key, value []byte) error) error {
+		for _, entry := range snap.storage {
+			if err := put(entry[0], entry[1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error restaurando storage: %w", err)
+	}
+
+	// Reabrir el estado EVM en el root guardado en el storage restaurado (sin escrituras pendientes
+	// sobre el triedb que se reemplaza)
+	if err := app.executor.FlushState(); err != nil {
+		return nil, fmt.Errorf("error escribiendo estado EVM: %w", err)
+	}
+	if _, err := app.executor.GetStateManager().LoadState(); err != nil {
+		return nil, fmt.Errorf("error reabriendo estado EVM: %w", err)
+	}
+	app.executor.ReloadState()
+
+	// Estado en memoria derivado del storage
+	if app.validators != nil {
+		if err := app.validators.LoadValidators(); err != nil {
+			consensusLog.Warn("Error recargando validadores del snapshot: " + err.Error())
+		}
+	}
+	app.SetDuplicateTxWindow(app.recentTxs.Window())
+	if err := ResetChainHead(app.storage); err != nil {
+		consensusLog.Warn("Error registrando último bloque tras revertir: " + err.Error())
+	}
+	if head, err := ReadChainHead(app.storage.GetDataDir()); err == nil {
+		app.chainHead = head
+	}
+	app.devClock.restore(snap.clock)
+
+	// Las transacciones pendientes se firmaron contra el estado descartado
+	if app.getMempool != nil && app.clearMempoolTx != nil {
+		for _, tx := range app.getMempool() {
+			app.clearMempoolTx(tx.Hash)
+		}
+	}
+
+	consensusLog.Infof("Cadena revertida al snapshot de desarrollo %d: altura=%d, root=%s", snap.id, snap.height, snap.root.Hex())
+	return snap, nil
+}
+
+// TakeDevSnapshot guarda el estado de la cadena de desarrollo y retorna el ID del snapshot
+func (c *CometBFT) TakeDevSnapshot() (uint64, error) {
+	app, err := c.devApp()
+	if err != nil {
+		return 0, err
+	}
+	snap, err := app.takeDevSnapshot()
+	if err != nil {
+		return 0, err
+	}
+	return snap.id, nil
+}
+
+// RevertDevSnapshot vuelve la cadena de desarrollo al snapshot id y retorna la altura a la que volvió
+func (c *CometBFT) RevertDevSnapshot(id uint64) (uint64, error) {
+	app, err := c.devApp()
+	if err != nil {
+		return 0, err
+	}
+	snap, err := app.revertDevSnapshot(id)
+	if err != nil {
+		return 0, err
+	}
+	return snap.height, nil
+}
@@ -0,0 +1,307 @@
+//go:build e2e
+
+package consensus
+
+// Tests de punta a punta: redes de varios nodos en proceso con CometBFT sobre loopback
+// Correr con: go test -tags e2e ./internal/consensus -run TestE2E -v -timeout 10m
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	cometcfg "github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	"github.com/cometbft/cometbft/p2p"
+	"github.com/cometbft/cometbft/privval"
+	"github.com/cometbft/cometbft/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// e2eChainID es el chain ID de las redes de prueba
+const e2eChainID = "oxy-e2e"
+
+// e2eMinStake es el stake mínimo de los validadores: el genesis convierte el poder 10 en 10 OXG
+var e2eMinStake = new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18))
+
+// e2eNode es un nodo completo (storage, EVM, validadores y CometBFT) de la red de prueba
+type e2eNode struct {
+	dir        string
+	db         *storage.BlockchainDB
+	evm        *execution.EVMExecutor
+	validators *ValidatorSet
+	engine     *CometBFT
+}
+
+// e2eNetwork es una red de validadores en proceso conectados por P2P en 127.0.0.1
+type e2eNetwork struct {
+	t        *testing.T
+	nodes    []*e2eNode
+	accounts []*ecdsa.PrivateKey // Cuentas fondeadas en el genesis
+	nonces   map[*ecdsa.PrivateKey]uint64
+}
+
+// startE2ENetwork genera las claves y un genesis con validators validadores de poder 10 y accounts cuentas
+// fondeadas con 1000 OXG, e inicia un nodo por validador con todos los demás como peers persistentes
+func startE2ENetwork(t *testing.T, validators, accounts int) *e2eNetwork {
+	t.Helper()
+	network := &e2eNetwork{t: t, nonces: make(map[*ecdsa.PrivateKey]uint64)}
+
+	params := GenesisParams{}
+	for i := 0; i < accounts; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("Error generando cuenta: %v", err)
+		}
+		network.accounts = append(network.accounts, key)
+		params.Accounts = append(params.Accounts, GenesisAccount{
+			Address: crypto.PubkeyToAddress(key.PublicKey).Hex(),
+			Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)).String(),
+		})
+	}
+	appState, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Error serializando app_state: %v", err)
+	}
+
+	// Claves de validador y de nodo de cada nodo, y el genesis compartido
+	base := t.TempDir()
+	genesis := &types.GenesisDoc{
+		ChainID:         e2eChainID,
+		GenesisTime:     time.Now().UTC(),
+		ConsensusParams: types.DefaultConsensusParams(),
+		AppState:        appState,
+	}
+	dirs := make([]string, validators)
+	peers := make([]string, validators)
+	p2pPorts := make([]int, validators)
+	for i := range dirs {
+		dirs[i] = filepath.Join(base, fmt.Sprintf("node%d", i))
+		cometConfig := cometcfg.DefaultConfig()
+		cometConfig.SetRoot(filepath.Join(dirs[i], "cometbft"))
+		if err := generateKeys(cometConfig); err != nil {
+			t.Fatalf("Error generando claves del nodo %d: %v", i, err)
+		}
+		pubKey, err := privval.LoadFilePV(cometConfig.PrivValidatorKeyFile(), cometConfig.PrivValidatorStateFile()).GetPubKey()
+		if err != nil {
+			t.Fatalf("Error leyendo clave de validador del nodo %d: %v", i, err)
+		}
+		nodeKey, err := p2p.LoadNodeKey(cometConfig.NodeKeyFile())
+		if err != nil {
+			t.Fatalf("Error leyendo node key del nodo %d: %v", i, err)
+		}
+		genesis.Validators = append(genesis.Validators, types.GenesisValidator{
+			Address: pubKey.Address(),
+			PubKey:  pubKey,
+			Name:    fmt.Sprintf("validator-%d", i),
+			Power:   10,
+		})
+		p2pPorts[i] = freeE2EPort(t)
+		peers[i] = fmt.Sprintf("%s@127.0.0.1:%d", nodeKey.ID(), p2pPorts[i])
+	}
+	for i := range dirs {
+		if err := genesis.SaveAs(filepath.Join(dirs[i], "cometbft", "config", "genesis.json")); err != nil {
+			t.Fatalf("Error guardando genesis del nodo %d: %v", i, err)
+		}
+	}
+
+	for i := range dirs {
+		others := append(append([]string{}, peers[:i]...), peers[i+1:]...)
+		network.nodes = append(network.nodes, startE2ENode(t, dirs[i], &Config{
+			DataDir:                dirs[i],
+			ChainID:                e2eChainID,
+			RPCListenAddr:          fmt.Sprintf("tcp://127.0.0.1:%d", freeE2EPort(t)),
+			P2PListenAddr:          fmt.Sprintf("tcp://127.0.0.1:%d", p2pPorts[i]),
+			PersistentPeers:        strings.Join(others, ","),
+			LocalNetwork:           true,
+			BroadcastCommitTimeout: time.Minute,
+		}))
+	}
+	return network
+}
+
+// startE2ENode inicia un nodo y registra su detención al terminar el test
+func startE2ENode(t *testing.T, dir string, cfg *Config) *e2eNode {
+	t.Helper()
+	node := &e2eNode{dir: dir}
+
+	db, err := storage.NewBlockchainDB(dir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	node.db = db
+	t.Cleanup(func() { db.Close() })
+
+	node.evm = execution.NewEVMExecutor(db)
+	if err := node.evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	t.Cleanup(func() { node.evm.Stop() })
+
+	node.validators = NewValidatorSet(db, node.evm, e2eMinStake, 100)
+	if err := node.validators.LoadValidators(); err != nil {
+		t.Fatalf("Error cargando validadores: %v", err)
+	}
+
+	node.engine, err = NewCometBFT(context.Background(), cfg, db, node.evm, node.validators)
+	if err != nil {
+		t.Fatalf("Error creando nodo %s: %v", dir, err)
+	}
+	if err := node.engine.Start(); err != nil {
+		t.Fatalf("Error iniciando nodo %s: %v", dir, err)
+	}
+	t.Cleanup(func() { node.engine.Stop() })
+	return node
+}
+
+// freeE2EPort retorna un puerto TCP libre de 127.0.0.1
+func freeE2EPort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error buscando puerto libre: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// height retorna el último bloque confirmado por un nodo
+func (n *e2eNode) height() uint64 {
+	height, err := n.db.GetLatestHeight()
+	if err != nil {
+		return 0
+	}
+	return height
+}
+
+// waitForHeight espera a que todos los nodos confirmen el bloque height
+func (network *e2eNetwork) waitForHeight(height uint64, timeout time.Duration) {
+	network.t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		lowest := network.commonHeight()
+		if lowest >= height {
+			return
+		}
+		if time.Now().After(deadline) {
+			network.t.Fatalf("Los nodos no llegaron al bloque %d en %s (el más atrasado está en %d)", height, timeout, lowest)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// commonHeight retorna el último bloque confirmado por todos los nodos
+func (network *e2eNetwork) commonHeight() uint64 {
+	lowest := network.nodes[0].height()
+	for _, node := range network.nodes[1:] {
+		lowest = min(lowest, node.height())
+	}
+	return lowest
+}
+
+// submit firma una transacción de la cuenta y la envía a un nodo esperando su inclusión en un bloque
+// Cada nodo solo propone las transacciones de su mempool local, así que la espera incluye la rotación
+// del proponente
+func (network *e2eNetwork) submit(node int, account int, tx *Transaction) *TxCommitResult {
+	network.t.Helper()
+	key := network.accounts[account]
+	tx.From = crypto.PubkeyToAddress(key.PublicKey).Hex()
+	tx.GasPrice = "1"
+	tx.Nonce = network.nonces[key]
+	if err := signSimulationTx(tx, key); err != nil {
+		network.t.Fatalf("Error firmando transacción: %v", err)
+	}
+
+	result, err := network.nodes[node].engine.BroadcastTransaction(context.Background(), tx, BroadcastCommit)
+	if err != nil {
+		network.t.Fatalf("Error enviando transacción %s al nodo %d: %v", tx.Hash, node, err)
+	}
+	network.nonces[key]++
+	return result.TxResult
+}
+
+// assertConverged verifica que todos los nodos tengan el mismo bloque (hash y app hash) en cada altura
+// que confirmaron todos
+func (network *e2eNetwork) assertConverged() uint64 {
+	network.t.Helper()
+	height := network.commonHeight()
+	for h := uint64(1); h <= height; h++ {
+		reference, err := loadStoredBlock(network.nodes[0].db, h)
+		if err != nil {
+			network.t.Fatalf("Nodo 0: %v", err)
+		}
+		for i, node := range network.nodes[1:] {
+			block, err := loadStoredBlock(node.db, h)
+			if err != nil {
+				network.t.Fatalf("Nodo %d: %v", i+1, err)
+			}
+			if block.Header.AppHash() != reference.Header.AppHash() {
+				network.t.Fatalf("App hash distinto en el bloque %d: nodo 0 %s, nodo %d %s",
+					h, reference.Header.AppHash(), i+1, block.Header.AppHash())
+			}
+			if block.Header.Hash != reference.Header.Hash {
+				network.t.Fatalf("Hash distinto en el bloque %d: nodo 0 %s, nodo %d %s", h, reference.Header.Hash, i+1, block.Header.Hash)
+			}
+		}
+	}
+	return height
+}
+
+// TestE2E_FourValidatorsConverge levanta 4 validadores, envía transferencias a todos los nodos y cambia el
+// set de validadores con staking, y verifica que todos los nodos lleguen a los mismos bloques y app hash
+func TestE2E_FourValidatorsConverge(t *testing.T) {
+	if os.Getenv("OXY_E2E_SKIP") != "" {
+		t.Skip("OXY_E2E_SKIP configurado")
+	}
+	network := startE2ENetwork(t, 4, 4)
+	network.waitForHeight(3, time.Minute)
+
+	// Transferencias enviadas a cada nodo
+	oxg := big.NewInt(1e18)
+	for i := 0; i < 8; i++ {
+		to := crypto.PubkeyToAddress(network.accounts[(i+1)%4].PublicKey).Hex()
+		result := network.submit(i%4, i%4, &Transaction{To: to, Value: oxg.String(), GasLimit: 21000})
+		if result.Code != CodeOK {
+			t.Fatalf("Transferencia %d fallida: %+v", i, result)
+		}
+	}
+
+	// Cambios en el set de validadores: un validador nuevo (sin nodo) y más stake
+	bonded := crypto.PubkeyToAddress(network.accounts[0].PublicKey).Hex()
+	payload, _ := json.Marshal(StakingPayload{Action: StakeActionBond, PubKey: hex.EncodeToString(ed25519.GenPrivKey().PubKey().Bytes())})
+	bond := network.submit(1, 0, &Transaction{To: StakingAddress, Value: new(big.Int).Mul(big.NewInt(12), oxg).String(), Data: payload, GasLimit: 100000})
+	if bond.Code != CodeOK {
+		t.Fatalf("Bond fallido: %+v", bond)
+	}
+	payload, _ = json.Marshal(StakingPayload{Action: StakeActionStake})
+	stake := network.submit(2, 0, &Transaction{To: StakingAddress, Value: new(big.Int).Mul(big.NewInt(3), oxg).String(), Data: payload, GasLimit: 100000})
+	if stake.Code != CodeOK {
+		t.Fatalf("Stake fallido: %+v", stake)
+	}
+
+	network.waitForHeight(stake.Height+2, time.Minute)
+	height := network.assertConverged()
+	t.Logf("%d nodos convergen hasta el bloque %d", len(network.nodes), height)
+
+	want := new(big.Int).Mul(big.NewInt(15), oxg)
+	for i, node := range network.nodes {
+		validator, err := node.validators.GetValidator(bonded)
+		if err != nil {
+			t.Fatalf("Nodo %d no registra el validador nuevo: %v", i, err)
+		}
+		if validator.Stake.Cmp(want) != 0 {
+			t.Errorf("Nodo %d: stake del validador nuevo %s, esperado %s", i, validator.Stake, want)
+		}
+	}
+}
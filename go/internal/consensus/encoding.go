@@ -0,0 +1,319 @@
+package consensus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// recordFormatVersion es la versión de la codificación canónica de bloques y transacciones en storage:
+// un byte de versión seguido del RLP del registro. Los registros anteriores son JSON (empiezan con '{')
+// Un registro con una versión mayor (escrito por un nodo más nuevo) no se interpreta
+const recordFormatVersion = 1
+
+// blockRecord es la codificación canónica de un bloque. Las transacciones se codifican como su hoja del
+// trie de transacciones (txLeaf): el registro guardado es el mismo que cubre el header
+type blockRecord struct {
+	Header       headerRecord
+	Transactions []*txLeaf
+	Receipts     []*receiptRecord
+}
+
+// headerRecord es la codificación canónica del header de un bloque guardado (incluye el hash)
+// El orden de los campos es parte del formato: execution.DecodeBlockHeader lee los primeros
+type headerRecord struct {
+	Height           uint64
+	Hash             string
+	ParentHash       string
+	Timestamp        uint64 // Segundos Unix (int64 como uint64)
+	TimestampNanos   uint64
+	Validator        string
+	ChainID          string
+	StateRoot        string
+	TransactionsRoot string
+	ReceiptsRoot     string
+	GasUsed          uint64
+	GasLimit         uint64
+}
+
+// receiptRecord es la codificación canónica de un receipt guardado. A diferencia de receiptLeaf conserva
+// el bloque y la transacción de cada log, que la verificación de la cadena compara con el bloque
+type receiptRecord struct {
+	TransactionHash string
+	BlockHash       string
+	BlockNumber     uint64
+	GasUsed         uint64
+	Status          string
+	Logs            []logRecord
+	Error           string
+}
+
+// logRecord es la codificación canónica de un log dentro de receiptRecord
+type logRecord struct {
+	Address     string
+	Topics      []string
+	Data        []byte
+	BlockNumber uint64
+	TxHash      string
+}
+
+// EncodeBlock codifica un bloque en el formato canónico de storage
+func EncodeBlock(block *Block) ([]byte, error) {
+	header := &block.Header
+	record := &blockRecord{
+		Header: headerRecord{
+			Height:           header.Height,
+			Hash:             header.Hash,
+			ParentHash:       header.ParentHash,
+			Timestamp:        uint64(header.Timestamp.Unix()),
+			TimestampNanos:   uint64(header.Timestamp.Nanosecond()),
+			Validator:        header.Validator,
+			ChainID:          header.ChainID,
+			StateRoot:        header.StateRoot,
+			TransactionsRoot: header.TransactionsRoot,
+			ReceiptsRoot:     header.ReceiptsRoot,
+			GasUsed:          header.GasUsed,
+			GasLimit:         header.GasLimit,
+		},
+		Transactions: make([]*txLeaf, len(block.Transactions)),
+		Receipts:     make([]*receiptRecord, len(block.Receipts)),
+	}
+	for i, tx := range block.Transactions {
+		record.Transactions[i] = newTxLeaf(tx)
+	}
+	for i, receipt := range block.Receipts {
+		record.Receipts[i] = newReceiptRecord(receipt)
+	}
+	return encodeRecord(record)
+}
+
+// DecodeBlock decodifica un bloque guardado, en formato canónico o JSON (registros anteriores)
+func DecodeBlock(data []byte) (*Block, error) {
+	var block Block
+	if isJSONRecord(data) {
+		if err := json.Unmarshal(data, &block); err != nil {
+			return nil, err
+		}
+		return &block, nil
+	}
+
+	var record blockRecord
+	if err := decodeRecord(data, &record); err != nil {
+		return nil, err
+	}
+	h := &record.Header
+	block.Header = BlockHeader{
+		Height:           h.Height,
+		Hash:             h.Hash,
+		ParentHash:       h.ParentHash,
+		Timestamp:        time.Unix(int64(h.Timestamp), int64(h.TimestampNanos)).UTC(),
+		Validator:        h.Validator,
+		ChainID:          h.ChainID,
+		StateRoot:        h.StateRoot,
+		TransactionsRoot: h.TransactionsRoot,
+		ReceiptsRoot:     h.ReceiptsRoot,
+		GasUsed:          h.GasUsed,
+		GasLimit:         h.GasLimit,
+	}
+	block.Transactions = make([]*Transaction, len(record.Transactions))
+	for i, leaf := range record.Transactions {
+		block.Transactions[i] = leaf.transaction()
+	}
+	block.Receipts = make([]*TransactionReceipt, len(record.Receipts))
+	for i, receipt := range record.Receipts {
+		block.Receipts[i] = receipt.receipt()
+	}
+	return &block, nil
+}
+
+// EncodeTransaction codifica una transacción en el formato canónico de storage
+func EncodeTransaction(tx *Transaction) ([]byte, error) {
+	return encodeRecord(newTxLeaf(tx))
+}
+
+// DecodeTransaction decodifica una transacción guardada, en formato canónico o JSON (registros anteriores)
+func DecodeTransaction(data []byte) (*Transaction, error) {
+	if isJSONRecord(data) {
+		var tx Transaction
+		if err := json.Unmarshal(data, &tx); err != nil {
+			return nil, err
+		}
+		return &tx, nil
+	}
+
+	var leaf txLeaf
+	if err := decodeRecord(data, &leaf); err != nil {
+		return nil, err
+	}
+	return leaf.transaction(), nil
+}
+
+// BlockJSON retorna un bloque guardado en JSON, para las respuestas de las APIs y de la mesh
+// Los registros anteriores ya son JSON y se retornan tal cual
+func BlockJSON(data []byte) ([]byte, error) {
+	if isJSONRecord(data) {
+		return data, nil
+	}
+	block, err := DecodeBlock(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(block)
+}
+
+// TransactionJSON retorna una transacción guardada en JSON, para las respuestas de las APIs y de la mesh
+// Los registros anteriores ya son JSON y se retornan tal cual
+func TransactionJSON(data []byte) ([]byte, error) {
+	if isJSONRecord(data) {
+		return data, nil
+	}
+	tx, err := DecodeTransaction(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tx)
+}
+
+// MigrateLegacyRecords reescribe en formato canónico los bloques y transacciones guardados en JSON
+// Se ejecuta una sola vez: al terminar, la versión del formato queda registrada en storage. Un registro
+// que no se puede decodificar se deja como está (verify lo reporta) y no detiene la migración
+func MigrateLegacyRecords(db *storage.BlockchainDB) (int, error) {
+	format, err := db.GetRecordFormat()
+	if err != nil {
+		return 0, fmt.Errorf("error leyendo el formato de los registros: %w", err)
+	}
+	if format >= recordFormatVersion {
+		return 0, nil
+	}
+
+	migrate := func(decode func(data []byte) ([]byte, error)) func(data []byte) ([]byte, error) {
+		return func(data []byte) ([]byte, error) {
+			if !isJSONRecord(data) {
+				return nil, nil
+			}
+			encoded, err := decode(data)
+			if err != nil {
+				consensusLog.Warnf("Registro JSON no decodificable, se deja sin migrar: %v", err)
+				return nil, nil
+			}
+			return encoded, nil
+		}
+	}
+	migrated, err := db.RewriteRecords(
+		migrate(func(data []byte) ([]byte, error) {
+			block, err := DecodeBlock(data)
+			if err != nil {
+				return nil, err
+			}
+			return EncodeBlock(block)
+		}),
+		migrate(func(data []byte) ([]byte, error) {
+			tx, err := DecodeTransaction(data)
+			if err != nil {
+				return nil, err
+			}
+			return EncodeTransaction(tx)
+		}),
+	)
+	if err != nil {
+		return migrated, fmt.Errorf("error migrando registros: %w", err)
+	}
+	if err := db.SaveRecordFormat(recordFormatVersion); err != nil {
+		return migrated, fmt.Errorf("error guardando el formato de los registros: %w", err)
+	}
+	if migrated > 0 {
+		consensusLog.Infof("Migrados %d bloques y transacciones al formato canónico (versión %d)", migrated, recordFormatVersion)
+	}
+	return migrated, nil
+}
+
+// newReceiptRecord retorna el registro de un receipt (vacío si es nil)
+func newReceiptRecord(receipt *TransactionReceipt) *receiptRecord {
+	if receipt == nil {
+		return &receiptRecord{}
+	}
+	record := &receiptRecord{
+		TransactionHash: receipt.TransactionHash,
+		BlockHash:       receipt.BlockHash,
+		BlockNumber:     receipt.BlockNumber,
+		GasUsed:         receipt.GasUsed,
+		Status:          receipt.Status,
+		Logs:            make([]logRecord, len(receipt.Logs)),
+		Error:           receipt.Error,
+	}
+	for i, log := range receipt.Logs {
+		record.Logs[i] = logRecord{
+			Address:     log.Address,
+			Topics:      log.Topics,
+			Data:        log.Data,
+			BlockNumber: log.BlockNumber,
+			TxHash:      log.TxHash,
+		}
+	}
+	return record
+}
+
+// receipt reconstruye el receipt de un registro
+func (r *receiptRecord) receipt() *TransactionReceipt {
+	receipt := &TransactionReceipt{
+		TransactionHash: r.TransactionHash,
+		BlockHash:       r.BlockHash,
+		BlockNumber:     r.BlockNumber,
+		GasUsed:         r.GasUsed,
+		Status:          r.Status,
+		Logs:            make([]Log, len(r.Logs)),
+		Error:           r.Error,
+	}
+	for i, log := range r.Logs {
+		receipt.Logs[i] = Log{
+			Address:     log.Address,
+			Topics:      append([]string{}, log.Topics...),
+			Data:        nilIfEmpty(log.Data),
+			BlockNumber: log.BlockNumber,
+			TxHash:      log.TxHash,
+		}
+	}
+	return receipt
+}
+
+// encodeRecord codifica un registro: byte de versión + RLP
+func encodeRecord(record interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(recordFormatVersion)
+	if err := rlp.Encode(&buf, record); err != nil {
+		return nil, fmt.Errorf("error codificando registro: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeRecord decodifica un registro en formato canónico
+func decodeRecord(data []byte, record interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("registro vacío")
+	}
+	if data[0] != recordFormatVersion {
+		return fmt.Errorf("formato de registro %d no soportado", data[0])
+	}
+	if err := rlp.DecodeBytes(data[1:], record); err != nil {
+		return fmt.Errorf("error decodificando registro: %w", err)
+	}
+	return nil
+}
+
+// isJSONRecord retorna si un registro está guardado en JSON (formato anterior a la codificación canónica)
+func isJSONRecord(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// nilIfEmpty normaliza los bytes vacíos a nil (el RLP no distingue entre ambos)
+func nilIfEmpty(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,143 @@
+package consensus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// newEncodingTestBlock crea un bloque sellado con transacciones, receipts y logs
+func newEncodingTestBlock() *Block {
+	block := &Block{
+		Header: BlockHeader{Height: 7, ParentHash: "0x06", Timestamp: time.Unix(1700000000, 0).UTC(), Validator: "ABCD", ChainID: "oxy-test"},
+		Transactions: []*Transaction{
+			{Hash: "0x01", From: "0x1234567890123456789012345678901234567890", To: "0x0000000000000000000000000000000000000abc", Value: "5", GasLimit: 21000, GasPrice: "1", Signature: []byte{9}, Timestamp: 1700000000},
+			{Hash: "0x02", From: "0x1234567890123456789012345678901234567890", Data: []byte{0x60, 0x00}, Value: "0", GasLimit: 100000, GasPrice: "1", Nonce: 1,
+				AccessList: execution.AccessList{{Address: "0x0000000000000000000000000000000000000abc", StorageKeys: []string{}}}},
+		},
+		Receipts: []*TransactionReceipt{
+			{TransactionHash: "0x01", BlockNumber: 7, GasUsed: 21000, Status: "success", Logs: []Log{}},
+			{TransactionHash: "0x02", BlockNumber: 7, GasUsed: 53000, Status: "failed", Error: "revert",
+				Logs: []Log{{Address: "0x0000000000000000000000000000000000000def", Topics: []string{"0xaa"}, Data: []byte{1}, BlockNumber: 7, TxHash: "0x02"}}},
+		},
+	}
+	block.sealHeader(types.EmptyRootHash)
+	return block
+}
+
+// TestEncodeBlock prueba que la codificación canónica conserve el bloque, sea determinista y se pueda
+// leer desde execution, y que los registros JSON anteriores se sigan decodificando
+func TestEncodeBlock(t *testing.T) {
+	block := newEncodingTestBlock()
+	data, err := EncodeBlock(block)
+	if err != nil {
+		t.Fatalf("Error codificando bloque: %v", err)
+	}
+	if data[0] != recordFormatVersion {
+		t.Fatalf("Registro sin byte de versión: %x", data[:1])
+	}
+	again, _ := EncodeBlock(newEncodingTestBlock())
+	if string(again) != string(data) {
+		t.Error("La codificación del mismo bloque debería ser idéntica")
+	}
+
+	decoded, err := DecodeBlock(data)
+	if err != nil {
+		t.Fatalf("Error decodificando bloque: %v", err)
+	}
+	if err := decoded.VerifyHeader(); err != nil {
+		t.Errorf("Bloque decodificado inválido: %v", err)
+	}
+	expected, _ := json.Marshal(block)
+	actual, _ := json.Marshal(decoded)
+	if string(expected) != string(actual) {
+		t.Errorf("Bloque decodificado distinto:\n%s\n%s", expected, actual)
+	}
+	if blockJSON, err := BlockJSON(data); err != nil || string(blockJSON) != string(expected) {
+		t.Errorf("BlockJSON incorrecto: %s (%v)", blockJSON, err)
+	}
+
+	header, err := execution.DecodeBlockHeader(data)
+	if err != nil || header.Hash != block.Header.Hash || header.StateRoot != block.Header.StateRoot || header.Timestamp != 1700000000 {
+		t.Errorf("Header leído desde execution incorrecto: %+v (%v)", header, err)
+	}
+
+	// Registros anteriores en JSON
+	legacy, err := DecodeBlock(expected)
+	if err != nil || legacy.Header.Hash != block.Header.Hash || len(legacy.Receipts) != 2 {
+		t.Errorf("Bloque JSON no decodificado: %+v (%v)", legacy, err)
+	}
+	if blockJSON, _ := BlockJSON(expected); string(blockJSON) != string(expected) {
+		t.Error("Un registro JSON debería retornarse tal cual")
+	}
+	if header, err := execution.DecodeBlockHeader(expected); err != nil || header.Hash != block.Header.Hash {
+		t.Errorf("Header JSON leído desde execution incorrecto: %+v (%v)", header, err)
+	}
+
+	// Un registro de una versión futura no se interpreta
+	future := append([]byte{recordFormatVersion + 1}, data[1:]...)
+	if _, err := DecodeBlock(future); err == nil {
+		t.Error("Se esperaba un error con una versión de formato desconocida")
+	}
+}
+
+// TestMigrateLegacyRecords prueba que los bloques y transacciones en JSON se reescriban en formato canónico
+// una sola vez, sin tocar el índice canónico
+func TestMigrateLegacyRecords(t *testing.T) {
+	testDir := createTestDir("migrate_legacy_records")
+	defer cleanupTestDir(testDir)
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	block := newEncodingTestBlock()
+	blockData, _ := json.Marshal(block)
+	if err := db.SaveCanonicalBlock(7, block.Header.Hash, blockData); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+	legacyBlock, _ := json.Marshal(&Block{Header: BlockHeader{Height: 6, Hash: "0x06"}})
+	if err := db.SaveBlock(6, legacyBlock); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+	for _, tx := range block.Transactions {
+		txData, _ := json.Marshal(tx)
+		if err := db.SaveTransaction(tx.Hash, txData); err != nil {
+			t.Fatalf("Error guardando transacción: %v", err)
+		}
+	}
+	canonical, _ := EncodeTransaction(block.Transactions[0])
+	if err := db.SaveTransaction("0x03", canonical); err != nil {
+		t.Fatalf("Error guardando transacción: %v", err)
+	}
+
+	migrated, err := MigrateLegacyRecords(db)
+	if err != nil || migrated != 4 {
+		t.Fatalf("Migrados %d registros, esperados 4 (%v)", migrated, err)
+	}
+	for _, height := range []uint64{6, 7} {
+		data, err := db.GetBlock(height)
+		if err != nil || isJSONRecord(data) {
+			t.Fatalf("Bloque %d no migrado (%v)", height, err)
+		}
+		if decoded, err := DecodeBlock(data); err != nil || decoded.Header.Height != height {
+			t.Errorf("Bloque %d migrado inválido: %v", height, err)
+		}
+	}
+	if hash, _ := db.GetCanonicalHash(7); hash != block.Header.Hash {
+		t.Errorf("Índice canónico modificado: %s", hash)
+	}
+	txData, _ := db.GetTransaction("0x02")
+	if tx, err := DecodeTransaction(txData); err != nil || isJSONRecord(txData) || string(tx.Data) != string(block.Transactions[1].Data) {
+		t.Errorf("Transacción no migrada: %+v (%v)", tx, err)
+	}
+
+	if migrated, err := MigrateLegacyRecords(db); err != nil || migrated != 0 {
+		t.Errorf("La migración debería ejecutarse una sola vez: %d (%v)", migrated, err)
+	}
+}
@@ -0,0 +1,108 @@
+package consensus
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Codespace identifica los errores de la aplicación en las respuestas ABCI
+const Codespace = "oxy"
+
+// Códigos de error de transacciones
+// Los valores son estables: los clientes dependen de ellos, no reutilizar ni renumerar
+const (
+	CodeOK                uint32 = 0
+	CodeDecodeError       uint32 = 1  // No se pudo decodificar la transacción
+	CodeInvalidTx         uint32 = 2  // Transacción mal formada (direcciones, valores)
+	CodeExecutionError    uint32 = 3  // Error interno ejecutando la transacción
+	CodeExecutionFailed   uint32 = 4  // La EVM ejecutó la transacción pero falló (revert, out of gas)
+	CodeDuplicateTx       uint32 = 5  // Transacción ya incluida o ya en el mempool
+	CodeRateLimited       uint32 = 6  // Rate limit por dirección excedido
+	CodeMempoolFull       uint32 = 7  // Mempool lleno
+	CodeInsufficientFunds uint32 = 8  // Balance insuficiente para valor + gas
+	CodeInvalidNonce      uint32 = 9  // Nonce menor al de la cuenta
+	CodeInvalidSignature  uint32 = 10 // Firma ausente o inválida
+	CodeInvalidHash       uint32 = 11 // Hash ausente o no coincide con el contenido
+	CodeUnavailable       uint32 = 12 // El consenso no está disponible
+	CodeTimeout           uint32 = 13 // La transacción no se incluyó en un bloque dentro del timeout (modo commit)
+	CodeInternalError     uint32 = 14 // La ejecución entró en pánico (el detalle queda en los logs del nodo)
+	CodeGasPriceTooLow    uint32 = 15 // Gas price menor al mínimo del nodo
+	CodeTxTooLarge        uint32 = 16 // Data mayor al máximo por transacción
+	CodeGasLimitTooHigh   uint32 = 17 // Gas límite mayor al máximo por transacción
+	CodeAddressBlocked    uint32 = 18 // Dirección bloqueada o fuera de la allowlist de compliance
+	CodeDeployNotAllowed  uint32 = 19 // Despliegue o cambio del registro de deployers sin autorización
+	CodeContractPaused    uint32 = 20 // El contrato destino está pausado
+	CodeVestingLocked     uint32 = 21 // La transacción gasta fondos bloqueados por vesting
+)
+
+// codeReasons mapea cada código a una razón legible por máquina
+var codeReasons = map[uint32]string{
+	CodeOK:                "ok",
+	CodeDecodeError:       "decode_error",
+	CodeInvalidTx:         "invalid_tx",
+	CodeExecutionError:    "execution_error",
+	CodeExecutionFailed:   "execution_failed",
+	CodeDuplicateTx:       "duplicate_tx",
+	CodeRateLimited:       "rate_limited",
+	CodeMempoolFull:       "mempool_full",
+	CodeInsufficientFunds: "insufficient_funds",
+	CodeInvalidNonce:      "invalid_nonce",
+	CodeInvalidSignature:  "invalid_signature",
+	CodeInvalidHash:       "invalid_hash",
+	CodeUnavailable:       "unavailable",
+	CodeTimeout:           "timeout",
+	CodeInternalError:     "internal_error",
+	CodeGasPriceTooLow:    "gas_price_too_low",
+	CodeTxTooLarge:        "tx_too_large",
+	CodeGasLimitTooHigh:   "gas_limit_too_high",
+	CodeAddressBlocked:    "address_blocked",
+	CodeDeployNotAllowed:  "deploy_not_allowed",
+	CodeContractPaused:    "contract_paused",
+	CodeVestingLocked:     "vesting_locked",
+}
+
+// CodeReason retorna la razón legible por máquina de un código
+func CodeReason(code uint32) string {
+	if reason, exists := codeReasons[code]; exists {
+		return reason
+	}
+	return "unknown"
+}
+
+// TxError es un error de transacción con código estable del registro
+type TxError struct {
+	Code    uint32
+	Message string
+}
+
+// NewTxError crea un error de transacción con el código dado
+func NewTxError(code uint32, format string, args ...interface{}) *TxError {
+	return &TxError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Error implementa la interfaz error
+func (e *TxError) Error() string {
+	return e.Message
+}
+
+// Reason retorna la razón legible por máquina del error
+func (e *TxError) Reason() string {
+	return CodeReason(e.Code)
+}
+
+// Codespace retorna el codespace del error
+func (e *TxError) Codespace() string {
+	return Codespace
+}
+
+// ErrorCode extrae el código de un error; si no es un TxError retorna defaultCode
+func ErrorCode(err error, defaultCode uint32) uint32 {
+	var txErr *TxError
+	if errors.As(err, &txErr) {
+		return txErr.Code
+	}
+	return defaultCode
+}
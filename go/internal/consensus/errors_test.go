@@ -0,0 +1,43 @@
+package consensus
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestErrorCode prueba la extracción de códigos desde errores
+func TestErrorCode(t *testing.T) {
+	err := NewTxError(CodeInsufficientFunds, "balance insuficiente: tiene %d", 0)
+	if ErrorCode(err, CodeInvalidTx) != CodeInsufficientFunds {
+		t.Errorf("Código incorrecto: obtenido %d", ErrorCode(err, CodeInvalidTx))
+	}
+
+	// Un TxError envuelto sigue exponiendo su código
+	wrapped := fmt.Errorf("validación: %w", err)
+	if ErrorCode(wrapped, CodeInvalidTx) != CodeInsufficientFunds {
+		t.Errorf("Código de error envuelto incorrecto: obtenido %d", ErrorCode(wrapped, CodeInvalidTx))
+	}
+
+	// Errores sin código usan el valor por defecto
+	if ErrorCode(fmt.Errorf("otro error"), CodeInvalidTx) != CodeInvalidTx {
+		t.Error("Error sin código debería usar el código por defecto")
+	}
+}
+
+// TestCodeReason prueba que cada código tenga una razón única
+func TestCodeReason(t *testing.T) {
+	if CodeReason(CodeRateLimited) != "rate_limited" {
+		t.Errorf("Razón incorrecta: obtenido %s", CodeReason(CodeRateLimited))
+	}
+	if CodeReason(9999) != "unknown" {
+		t.Errorf("Código desconocido debería retornar 'unknown': obtenido %s", CodeReason(9999))
+	}
+
+	seen := make(map[string]uint32)
+	for code, reason := range codeReasons {
+		if other, exists := seen[reason]; exists {
+			t.Errorf("Razón %s duplicada en códigos %d y %d", reason, code, other)
+		}
+		seen[reason] = code
+	}
+}
@@ -0,0 +1,146 @@
+package consensus
+
+import (
+	"fmt"
+	"math/big"
+
+	execution "github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// Tipos de eventos ABCI emitidos por la aplicación
+// Los atributos se marcan con Index=true para que el indexador de CometBFT
+// permita consultas como: tx_search "transfer.sender='0x...'"
+const (
+	EventTypeExecution   = "execution"
+	EventTypeTransfer    = "transfer"
+	EventTypeFee         = "fee"
+	EventTypeContractLog = "contract_log"
+	EventTypeStake       = "stake"
+	EventTypeRewards     = "rewards"
+	EventTypeTxError     = "tx_error"
+)
+
+// Acciones de staking reportadas en eventos stake.action
+const (
+	StakeActionBond    = "bond"
+	StakeActionStake   = "stake"
+	StakeActionUnstake = "unstake"
+
+	StakeActionUnjail          = "unjail"
+	StakeActionSetCommission   = "set-commission"
+	StakeActionWithdrawRewards = "withdraw-rewards"
+)
+
+// indexedAttr crea un atributo de evento indexado por CometBFT
+func indexedAttr(key, value string) abcitypes.EventAttribute {
+	return abcitypes.EventAttribute{Key: key, Value: value, Index: true}
+}
+
+// buildEvents construye los eventos de una transacción ejecutada
+func (app *ABCIApp) buildEvents(tx *Transaction, result *execution.ExecutionResult) []abcitypes.Event {
+	events := []abcitypes.Event{}
+
+	// Evento de ejecución
+	events = append(events, abcitypes.Event{
+		Type: EventTypeExecution,
+		Attributes: []abcitypes.EventAttribute{
+			indexedAttr("success", fmt.Sprintf("%t", result.Success)),
+			{Key: "gas_used", Value: fmt.Sprintf("%d", result.GasUsed)},
+		},
+	})
+
+	// Transferencia de valor nativo (solo si la ejecución fue exitosa)
+	if result.Success && tx.Value != "" && tx.Value != "0" {
+		events = append(events, abcitypes.Event{
+			Type: EventTypeTransfer,
+			Attributes: []abcitypes.EventAttribute{
+				indexedAttr("sender", tx.From),
+				indexedAttr("recipient", tx.To),
+				indexedAttr("amount", tx.Value),
+			},
+		})
+	}
+
+	// Fee pagado por el remitente, o por el fee payer si la transacción es patrocinada (gas usado * gas price)
+	fee := new(big.Int)
+	if gasPrice, ok := new(big.Int).SetString(tx.GasPrice, 10); ok {
+		fee.Mul(gasPrice, new(big.Int).SetUint64(result.GasUsed))
+	}
+	payer := tx.From
+	if isSponsoredTx(tx) {
+		payer = tx.FeePayer.Address
+	}
+	events = append(events, abcitypes.Event{
+		Type: EventTypeFee,
+		Attributes: []abcitypes.EventAttribute{
+			indexedAttr("payer", payer),
+			indexedAttr("amount", fee.String()),
+			{Key: "gas_price", Value: tx.GasPrice},
+		},
+	})
+
+	// Eventos de logs de contratos
+	for _, log := range result.Logs {
+		attrs := []abcitypes.EventAttribute{
+			indexedAttr("address", log.Address),
+		}
+		// El primer topic es la firma del evento Solidity (ej: Transfer(address,address,uint256))
+		if len(log.Topics) > 0 {
+			attrs = append(attrs, indexedAttr("topic0", log.Topics[0]))
+		}
+		events = append(events, abcitypes.Event{
+			Type:       EventTypeContractLog,
+			Attributes: attrs,
+		})
+	}
+
+	return events
+}
+
+// recordStakeEvent registra un cambio de stake para emitirlo en el FinalizeBlock actual, con el resultado de
+// la transacción que lo causó (ver attachStakeEvents) o con los eventos del bloque
+func (app *ABCIApp) recordStakeEvent(validator string, amount *big.Int, action string) {
+	app.stakeEventsMutex.Lock()
+	defer app.stakeEventsMutex.Unlock()
+
+	app.pendingStakeEvents = append(app.pendingStakeEvents, abcitypes.Event{
+		Type: EventTypeStake,
+		Attributes: []abcitypes.EventAttribute{
+			indexedAttr("validator", validator),
+			indexedAttr("amount", amount.String()),
+			indexedAttr("action", action),
+		},
+	})
+}
+
+// recordRewardsEvent registra un retiro de recompensas para emitirlo con el resultado de la transacción
+func (app *ABCIApp) recordRewardsEvent(delegator string, amount *big.Int) {
+	app.stakeEventsMutex.Lock()
+	defer app.stakeEventsMutex.Unlock()
+
+	app.pendingStakeEvents = append(app.pendingStakeEvents, abcitypes.Event{
+		Type: EventTypeRewards,
+		Attributes: []abcitypes.EventAttribute{
+			indexedAttr("delegator", delegator),
+			indexedAttr("amount", amount.String()),
+			indexedAttr("action", StakeActionWithdrawRewards),
+		},
+	})
+}
+
+// attachStakeEvents agrega al resultado de una transacción los eventos de staking que registró su ejecución,
+// para que tx_search la encuentre por stake.validator o rewards.delegator
+func (app *ABCIApp) attachStakeEvents(result *abcitypes.ExecTxResult) {
+	result.Events = append(result.Events, app.takeStakeEvents()...)
+}
+
+// takeStakeEvents retorna los eventos de staking pendientes y vacía la lista
+func (app *ABCIApp) takeStakeEvents() []abcitypes.Event {
+	app.stakeEventsMutex.Lock()
+	defer app.stakeEventsMutex.Unlock()
+
+	events := app.pendingStakeEvents
+	app.pendingStakeEvents = nil
+	return events
+}
@@ -0,0 +1,158 @@
+package consensus
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	execution "github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// findEvent busca un evento por tipo
+func findEvent(events []abcitypes.Event, eventType string) *abcitypes.Event {
+	for i := range events {
+		if events[i].Type == eventType {
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+// eventAttr obtiene el valor de un atributo de un evento
+func eventAttr(event *abcitypes.Event, key string) string {
+	for _, attr := range event.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// TestBuildEvents_Transfer prueba los eventos de transferencia y fee
+func TestBuildEvents_Transfer(t *testing.T) {
+	app := &ABCIApp{}
+
+	tx := &Transaction{
+		From:     "0x1111111111111111111111111111111111111111",
+		To:       "0x2222222222222222222222222222222222222222",
+		Value:    "1000",
+		GasPrice: "10",
+	}
+	result := &execution.ExecutionResult{Success: true, GasUsed: 21000}
+
+	events := app.buildEvents(tx, result)
+
+	transfer := findEvent(events, EventTypeTransfer)
+	if transfer == nil {
+		t.Fatal("Debería emitirse un evento transfer")
+	}
+	if eventAttr(transfer, "sender") != tx.From || eventAttr(transfer, "recipient") != tx.To || eventAttr(transfer, "amount") != "1000" {
+		t.Errorf("Atributos de transfer incorrectos: %+v", transfer.Attributes)
+	}
+	for _, attr := range transfer.Attributes {
+		if !attr.Index {
+			t.Errorf("Atributo %s debería estar indexado", attr.Key)
+		}
+	}
+
+	fee := findEvent(events, EventTypeFee)
+	if fee == nil {
+		t.Fatal("Debería emitirse un evento fee")
+	}
+	if eventAttr(fee, "payer") != tx.From || eventAttr(fee, "amount") != "210000" {
+		t.Errorf("Atributos de fee incorrectos: %+v", fee.Attributes)
+	}
+
+	// Una ejecución fallida no transfiere valor
+	events = app.buildEvents(tx, &execution.ExecutionResult{Success: false, GasUsed: 21000})
+	if findEvent(events, EventTypeTransfer) != nil {
+		t.Error("No debería emitirse transfer si la ejecución falló")
+	}
+
+	// En una transacción patrocinada el fee lo paga el fee payer
+	sponsored := *tx
+	sponsored.FeePayer = &FeePayerAuth{Address: "0x9999999999999999999999999999999999999999"}
+	fee = findEvent(app.buildEvents(&sponsored, result), EventTypeFee)
+	if fee == nil || eventAttr(fee, "payer") != sponsored.FeePayer.Address || eventAttr(fee, "amount") != "210000" {
+		t.Errorf("El fee de una transacción patrocinada debería pagarlo el fee payer: %+v", fee)
+	}
+}
+
+// TestRecordStakeEvent prueba los eventos de staking
+func TestRecordStakeEvent(t *testing.T) {
+	app := &ABCIApp{}
+
+	app.recordStakeEvent("0x3333333333333333333333333333333333333333", big.NewInt(500), StakeActionStake)
+
+	if len(app.pendingStakeEvents) != 1 {
+		t.Fatalf("Debería haber 1 evento pendiente: obtenido %d", len(app.pendingStakeEvents))
+	}
+	stake := &app.pendingStakeEvents[0]
+	if stake.Type != EventTypeStake || eventAttr(stake, "amount") != "500" || eventAttr(stake, "action") != StakeActionStake {
+		t.Errorf("Evento stake incorrecto: %+v", stake)
+	}
+}
+
+// TestApplyStakingTx_EmitsStakeEvent prueba que las transacciones de staking emiten eventos al cambiar el stake
+func TestApplyStakingTx_EmitsStakeEvent(t *testing.T) {
+	testDir := createTestDir("staking_tx_events")
+	defer cleanupTestDir(testDir)
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+
+	validators := NewValidatorSet(db, evm, big.NewInt(100), 10)
+	app := NewABCIApp(db, evm, validators, "test-chain")
+
+	sender := "0x4444444444444444444444444444444444444444"
+	bond := &Transaction{
+		From:  sender,
+		To:    StakingAddress,
+		Value: "1000",
+		Data:  []byte(`{"action":"bond","pubKey":"` + strings.Repeat("ab", 32) + `"}`),
+	}
+	if err := app.applyStakingTx(bond); err != nil {
+		t.Fatalf("Error aplicando bond: %v", err)
+	}
+
+	// El evento se emite con el resultado de la transacción que causó el cambio
+	result := &abcitypes.ExecTxResult{Events: []abcitypes.Event{{Type: EventTypeFee}}}
+	app.attachStakeEvents(result)
+	events := result.Events
+	if len(events) != 2 || events[1].Type != EventTypeStake || eventAttr(&events[1], "action") != StakeActionBond || eventAttr(&events[1], "validator") != sender {
+		t.Fatalf("Debería emitirse un evento bond en el resultado de la transacción: %+v", events)
+	}
+	if len(app.takeStakeEvents()) != 0 {
+		t.Error("Los eventos pendientes deberían vaciarse al tomarlos")
+	}
+
+	// Una acción inválida no cambia el stake y devuelve el valor en custodia
+	if err := evm.FundAccount(StakingAddress, "500"); err != nil {
+		t.Fatalf("Error fondeando custodia: %v", err)
+	}
+	invalid := &Transaction{From: sender, To: StakingAddress, Value: "500", Data: []byte(`{"action":"delegate"}`)}
+	if err := app.applyStakingTx(invalid); err == nil {
+		t.Fatal("Una acción desconocida debería fallar")
+	}
+	if len(app.takeStakeEvents()) != 0 {
+		t.Error("Una acción fallida no debería emitir eventos")
+	}
+	state, err := evm.GetState(sender)
+	if err != nil {
+		t.Fatalf("Error obteniendo estado: %v", err)
+	}
+	if state.Balance != "500" {
+		t.Errorf("El valor debería devolverse al remitente: balance %s", state.Balance)
+	}
+}
@@ -0,0 +1,45 @@
+package consensus
+
+import (
+	"strings"
+
+	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FeePayerAuth autoriza a otra cuenta (relayer) a pagar el gas de una transacción: el remitente firma el
+// hash, que incluye Address, y el fee payer firma keccak256("oxy-fee-payer" || hash). El remitente solo
+// paga value; el fee payer paga el gas usado
+type FeePayerAuth struct {
+	Address   string `json:"address"`
+	Signature []byte `json:"signature"`
+}
+
+// isSponsoredTx retorna si otra cuenta paga el gas de la transacción
+func isSponsoredTx(tx *Transaction) bool {
+	return tx.FeePayer != nil
+}
+
+// validateFeePayer verifica la autorización del fee payer sin depender del estado: que sea una cuenta
+// distinta del remitente y que su firma corresponda al hash de los campos
+func validateFeePayer(tx *Transaction) error {
+	payer := tx.FeePayer
+	if !common.IsHexAddress(payer.Address) {
+		return NewTxError(CodeInvalidSignature, "fee payer inválido: %s", payer.Address)
+	}
+	if strings.EqualFold(payer.Address, tx.From) {
+		return NewTxError(CodeInvalidSignature, "el fee payer no puede ser el remitente")
+	}
+
+	hash, err := signedFields(tx).Hash()
+	if err != nil {
+		return NewTxError(CodeInvalidHash, "error calculando hash de transacción: %v", err)
+	}
+	if tx.Hash != hash.Hex() {
+		return NewTxError(CodeInvalidHash, "hash de transacción inválido: esperado %s, tiene %s", hash.Hex(), tx.Hash)
+	}
+	if err := cryptosigner.VerifyHashSignature(cryptosigner.FeePayerDigest(hash), payer.Signature, common.HexToAddress(payer.Address)); err != nil {
+		return NewTxError(CodeInvalidSignature, "firma del fee payer %s: %v", payer.Address, err)
+	}
+	return nil
+}
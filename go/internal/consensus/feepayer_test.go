@@ -0,0 +1,59 @@
+package consensus
+
+import (
+	"testing"
+
+	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TestValidateFeePayer prueba que el fee payer firme el hash que incluye su dirección
+func TestValidateFeePayer(t *testing.T) {
+	senderKey, _ := crypto.GenerateKey()
+	payerKey, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey).Hex()
+
+	tx := &Transaction{
+		From:     crypto.PubkeyToAddress(senderKey.PublicKey).Hex(),
+		To:       "0x0000000000000000000000000000000000000abc",
+		Value:    "0",
+		GasLimit: 21000,
+		GasPrice: "1",
+		FeePayer: &FeePayerAuth{Address: payer},
+	}
+	hash, err := signedFields(tx).Hash()
+	if err != nil {
+		t.Fatalf("Error calculando hash: %v", err)
+	}
+	tx.Hash = hash.Hex()
+
+	// El fee payer forma parte del hash firmado por el remitente
+	withoutPayer := *tx
+	withoutPayer.FeePayer = nil
+	if other, _ := signedFields(&withoutPayer).Hash(); other == hash {
+		t.Error("El hash debería cambiar con el fee payer")
+	}
+
+	// La firma del remitente sobre el hash no sirve como firma del fee payer
+	tx.FeePayer.Signature, _ = crypto.Sign(hash.Bytes(), payerKey)
+	if err := validateFeePayer(tx); ErrorCode(err, CodeOK) != CodeInvalidSignature {
+		t.Errorf("Se esperaba un error de firma: %v", err)
+	}
+
+	tx.FeePayer.Signature, _ = crypto.Sign(cryptosigner.FeePayerDigest(hash).Bytes(), payerKey)
+	if err := validateFeePayer(tx); err != nil {
+		t.Fatalf("Fee payer rechazado: %v", err)
+	}
+
+	// Cambiar el fee payer invalida el hash
+	tx.FeePayer.Address = common.HexToAddress("0x01").Hex()
+	if err := validateFeePayer(tx); ErrorCode(err, CodeOK) != CodeInvalidHash {
+		t.Errorf("Se esperaba un error de hash: %v", err)
+	}
+
+	tx.FeePayer.Address = tx.From
+	if err := validateFeePayer(tx); err == nil {
+		t.Error("Se esperaba un error por fee payer igual al remitente")
+	}
+}
@@ -0,0 +1,131 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validatorsPerPage es el máximo de validadores por página que acepta el RPC de CometBFT
+const validatorsPerPage = 100
+
+// block_id_flag de una firma del commit (1 = ausente)
+const (
+	blockIDFlagCommit = 2 // Firma a favor del bloque
+	blockIDFlagNil    = 3 // Voto nil
+)
+
+// CommitSummary resume las firmas del commit de CometBFT que finalizó un bloque
+// Con más de 2/3 del poder de voto firmado el bloque es final: CometBFT no revierte bloques confirmados
+type CommitSummary struct {
+	Height            int64  `json:"height"`
+	Round             int32  `json:"round"`
+	BlockID           string `json:"blockId"`           // Hash del bloque de CometBFT (no es el hash del bloque de la aplicación)
+	Signatures        int    `json:"signatures"`        // Validadores que firmaron el bloque
+	Validators        int    `json:"validators"`        // Validadores del conjunto en esa altura
+	SignedVotingPower int64  `json:"signedVotingPower"` // Poder de voto de las firmas
+	TotalVotingPower  int64  `json:"totalVotingPower"`
+	Canonical         bool   `json:"canonical"` // false: commit visto por este nodo para el último bloque, hasta que el siguiente incluya el definitivo
+}
+
+// rpcCommit es el subconjunto de la respuesta de /commit de CometBFT que usamos
+type rpcCommit struct {
+	SignedHeader struct {
+		Commit struct {
+			Height  string `json:"height"`
+			Round   int32  `json:"round"`
+			BlockID struct {
+				Hash string `json:"hash"`
+			} `json:"block_id"`
+			Signatures []struct {
+				BlockIDFlag      int       `json:"block_id_flag"`
+				ValidatorAddress string    `json:"validator_address"`
+				Timestamp        time.Time `json:"timestamp"`
+				Signature        string    `json:"signature"`
+			} `json:"signatures"`
+		} `json:"commit"`
+	} `json:"signed_header"`
+	Canonical bool `json:"canonical"`
+}
+
+// rpcValidators es el subconjunto de la respuesta de /validators de CometBFT que usamos
+type rpcValidators struct {
+	Validators []struct {
+		Address     string `json:"address"`
+		VotingPower string `json:"voting_power"`
+	} `json:"validators"`
+	Total string `json:"total"`
+}
+
+// GetCommitSummary retorna el resumen de las firmas del commit de un bloque y del poder de voto que las respalda
+func (c *CometBFT) GetCommitSummary(ctx context.Context, height uint64) (*CommitSummary, error) {
+	if !c.running {
+		return nil, fmt.Errorf("consenso no está corriendo")
+	}
+
+	client, err := c.rpc()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.Commit(ctx, int64(height))
+	if err != nil {
+		if isHeightNotAvailable(err) {
+			return nil, fmt.Errorf("%w: %v", ErrHeightNotAvailable, err)
+		}
+		return nil, fmt.Errorf("commit falló: %w", err)
+	}
+	var commit rpcCommit
+	if err := json.Unmarshal(result, &commit); err != nil {
+		return nil, fmt.Errorf("error decodificando commit: %w", err)
+	}
+
+	power, err := c.votingPowers(ctx, client, int64(height))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &CommitSummary{
+		Round:      commit.SignedHeader.Commit.Round,
+		BlockID:    commit.SignedHeader.Commit.BlockID.Hash,
+		Validators: len(power),
+		Canonical:  commit.Canonical,
+	}
+	summary.Height, _ = strconv.ParseInt(commit.SignedHeader.Commit.Height, 10, 64)
+	for _, p := range power {
+		summary.TotalVotingPower += p
+	}
+	for _, sig := range commit.SignedHeader.Commit.Signatures {
+		if sig.BlockIDFlag != blockIDFlagCommit {
+			continue
+		}
+		summary.Signatures++
+		summary.SignedVotingPower += power[strings.ToUpper(sig.ValidatorAddress)]
+	}
+	return summary, nil
+}
+
+// votingPowers retorna el poder de voto de cada validador (dirección en mayúsculas) del conjunto de una altura
+func (c *CometBFT) votingPowers(ctx context.Context, client cometRPC, height int64) (map[string]int64, error) {
+	power := make(map[string]int64)
+	for page := 1; ; page++ {
+		result, err := client.Validators(ctx, height, page, validatorsPerPage)
+		if err != nil {
+			return nil, fmt.Errorf("validators falló: %w", err)
+		}
+		var validators rpcValidators
+		if err := json.Unmarshal(result, &validators); err != nil {
+			return nil, fmt.Errorf("error decodificando validators: %w", err)
+		}
+		for _, v := range validators.Validators {
+			power[strings.ToUpper(v.Address)], _ = strconv.ParseInt(v.VotingPower, 10, 64)
+		}
+		total, _ := strconv.Atoi(validators.Total)
+		if len(validators.Validators) == 0 || len(power) >= total {
+			return power, nil
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package consensus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/cometbft/cometbft/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GenesisParams son los parámetros de la aplicación en app_state del genesis de CometBFT
+//
+//	"app_state": {"hardforks": {"shanghaiHeight": 1000, "cancunHeight": 2000},
+//	              "accounts": [{"address": "0x...", "balance": "1000000000000000000"}]}
+type GenesisParams struct {
+	Hardforks       execution.HardforkSchedule `json:"hardforks"`
+	Accounts        []GenesisAccount           `json:"accounts,omitempty"`
+	Deployers       *DeployerGenesis           `json:"deployers,omitempty"` // Política de despliegue de contratos
+	SystemContracts execution.SystemContracts  `json:"systemContracts"`     // Contratos llamados en cada bloque
+	Vesting         []GenesisVesting           `json:"vesting,omitempty"`   // Vesting sobre balances de accounts
+
+	// Bloques durante los que se rechaza el replay de una transacción incluida (0 = DefaultDuplicateTxWindow)
+	// Es parte del consenso: todos los nodos deben rechazar los mismos duplicados
+	DuplicateTxWindow uint64 `json:"duplicateTxWindow,omitempty"`
+}
+
+// GenesisAccount es una cuenta fondeada en el genesis (balance en wei)
+type GenesisAccount struct {
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+}
+
+// ParseGenesisParams decodifica app_state (vacío o null = parámetros por defecto)
+func ParseGenesisParams(appState []byte) (*GenesisParams, error) {
+	params := &GenesisParams{}
+	trimmed := bytes.TrimSpace(appState)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) || bytes.Equal(trimmed, []byte(`""`)) {
+		return params, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(params); err != nil {
+		return nil, fmt.Errorf("app_state del genesis inválido: %w", err)
+	}
+	if err := params.Hardforks.Validate(); err != nil {
+		return nil, err
+	}
+	for _, account := range params.Accounts {
+		if !common.IsHexAddress(account.Address) {
+			return nil, fmt.Errorf("cuenta genesis inválida: %s", account.Address)
+		}
+		if balance, ok := new(big.Int).SetString(account.Balance, 10); !ok || balance.Sign() < 0 {
+			return nil, fmt.Errorf("balance genesis inválido para %s: %s", account.Address, account.Balance)
+		}
+	}
+	if err := validateGenesisVesting(params.Vesting, params.Accounts); err != nil {
+		return nil, err
+	}
+	if err := params.SystemContracts.Validate(); err != nil {
+		return nil, err
+	}
+	if params.Deployers != nil {
+		if err := params.Deployers.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+// mintGenesisAccounts emite el balance de las cuentas del genesis (una sola vez, en InitChain)
+func (app *ABCIApp) mintGenesisAccounts(appState []byte) error {
+	params, err := ParseGenesisParams(appState)
+	if err != nil {
+		return err
+	}
+	if len(params.Accounts) == 0 || app.executor == nil {
+		return nil
+	}
+	total := new(big.Int)
+	for _, account := range params.Accounts {
+		balance, _ := new(big.Int).SetString(account.Balance, 10)
+		if err := app.executor.MintGenesis(account.Address, balance); err != nil {
+			return fmt.Errorf("error fondeando cuenta genesis %s: %w", account.Address, err)
+		}
+		total.Add(total, balance)
+	}
+	consensusLog.Infof("%d cuentas genesis fondeadas (total %s)", len(params.Accounts), total)
+	return nil
+}
+
+// loadGenesisParams lee los parámetros de la aplicación del genesis.json
+func loadGenesisParams(genesisFile string) (*GenesisParams, error) {
+	genesis, err := types.GenesisDocFromFile(genesisFile)
+	if err != nil {
+		return nil, fmt.Errorf("error cargando genesis: %w", err)
+	}
+	return ParseGenesisParams(genesis.AppState)
+}
+
+// addGenesisAccounts agrega cuentas fondeadas al app_state de un genesis.json (antes del primer arranque:
+// InitChain solo las emite una vez)
+func addGenesisAccounts(genesisFile string, accounts []GenesisAccount) error {
+	genesis, err := types.GenesisDocFromFile(genesisFile)
+	if err != nil {
+		return fmt.Errorf("error cargando genesis: %w", err)
+	}
+	params, err := ParseGenesisParams(genesis.AppState)
+	if err != nil {
+		return err
+	}
+	params.Accounts = append(params.Accounts, accounts...)
+
+	appState, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("error serializando app_state: %w", err)
+	}
+	// Validar las cuentas agregadas antes de guardar
+	if _, err := ParseGenesisParams(appState); err != nil {
+		return err
+	}
+	genesis.AppState = appState
+	if err := genesis.SaveAs(genesisFile); err != nil {
+		return fmt.Errorf("error guardando genesis: %w", err)
+	}
+	return nil
+}
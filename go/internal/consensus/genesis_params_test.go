@@ -0,0 +1,71 @@
+package consensus
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/types"
+)
+
+// TestParseGenesisParams_Accounts prueba la validación de las cuentas fondeadas en app_state
+func TestParseGenesisParams_Accounts(t *testing.T) {
+	params, err := ParseGenesisParams([]byte(`{"accounts":[{"address":"0x742d35Cc6634C0532925a3b844Bc454e4438f44e","balance":"1000"}]}`))
+	if err != nil {
+		t.Fatalf("Error parseando app_state: %v", err)
+	}
+	if len(params.Accounts) != 1 || params.Accounts[0].Balance != "1000" {
+		t.Errorf("Cuentas mal decodificadas: %+v", params.Accounts)
+	}
+
+	for _, appState := range []string{
+		`{"accounts":[{"address":"0x1234","balance":"1000"}]}`,
+		`{"accounts":[{"address":"0x742d35Cc6634C0532925a3b844Bc454e4438f44e","balance":"-1"}]}`,
+		`{"accounts":[{"address":"0x742d35Cc6634C0532925a3b844Bc454e4438f44e","balance":"1e18"}]}`,
+	} {
+		if _, err := ParseGenesisParams([]byte(appState)); err == nil {
+			t.Errorf("Se esperaba error para %s", appState)
+		}
+	}
+}
+
+// TestAddGenesisAccounts prueba que las cuentas se agreguen al app_state conservando los hardforks
+func TestAddGenesisAccounts(t *testing.T) {
+	genesisFile := filepath.Join(t.TempDir(), "genesis.json")
+	genesis := &types.GenesisDoc{
+		ChainID:         "oxy-dev",
+		GenesisTime:     time.Now(),
+		ConsensusParams: types.DefaultConsensusParams(),
+		AppState:        []byte(`{"hardforks":{"shanghaiHeight":10}}`),
+	}
+	if err := genesis.SaveAs(genesisFile); err != nil {
+		t.Fatalf("Error guardando genesis: %v", err)
+	}
+
+	accounts := []GenesisAccount{{Address: "0x742d35Cc6634C0532925a3b844Bc454e4438f44e", Balance: "5000"}}
+	if err := addGenesisAccounts(genesisFile, accounts); err != nil {
+		t.Fatalf("Error agregando cuentas: %v", err)
+	}
+	params, err := loadGenesisParams(genesisFile)
+	if err != nil {
+		t.Fatalf("Error leyendo genesis: %v", err)
+	}
+	if len(params.Accounts) != 1 || params.Hardforks.ShanghaiHeight == nil || *params.Hardforks.ShanghaiHeight != 10 {
+		t.Errorf("app_state inesperado: %+v", params)
+	}
+
+	if err := addGenesisAccounts(genesisFile, []GenesisAccount{{Address: "no-es-una-direccion", Balance: "1"}}); err == nil {
+		t.Error("Se esperaba error con una dirección inválida")
+	}
+}
+
+// TestParseGenesisParams_DuplicateTxWindow prueba la ventana de duplicados de app_state
+func TestParseGenesisParams_DuplicateTxWindow(t *testing.T) {
+	params, err := ParseGenesisParams([]byte(`{"duplicateTxWindow":250}`))
+	if err != nil {
+		t.Fatalf("Error parseando app_state: %v", err)
+	}
+	if params.DuplicateTxWindow != 250 {
+		t.Errorf("Ventana mal decodificada: %d", params.DuplicateTxWindow)
+	}
+}
@@ -0,0 +1,177 @@
+package consensus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Orígenes de una detención de la cadena
+const (
+	HaltSourceAdmin      = "admin-api"  // Programada con el API de administración
+	HaltSourceInvariants = "invariants" // Automática al detectar invariantes contables incumplidas
+)
+
+// ErrNoHaltScheduled indica que no hay una detención programada que cancelar
+var ErrNoHaltScheduled = errors.New("no hay una detención de la cadena programada")
+
+// HaltStatus es la detención programada de la cadena
+// Después de confirmar Height el nodo escribe el estado en disco, deja de proponer y rechaza las propuestas
+// y transacciones nuevas hasta que se reanude; el proceso sigue corriendo (API, queries)
+type HaltStatus struct {
+	Height   uint64     `json:"height"` // Último bloque que se confirma (0 = sin detención programada)
+	Reason   string     `json:"reason,omitempty"`
+	Source   string     `json:"source,omitempty"`
+	Halted   bool       `json:"halted"` // Height ya se confirmó y la cadena está detenida
+	HaltedAt *time.Time `json:"haltedAt,omitempty"`
+}
+
+// ScheduleHalt programa la detención de la cadena después de confirmar height (0 = el último bloque
+// confirmado, es decir, detener ya). La detención se guarda y sobrevive a reinicios
+func (app *ABCIApp) ScheduleHalt(height uint64, reason, source string) (HaltStatus, error) {
+	app.haltMu.Lock()
+	defer app.haltMu.Unlock()
+
+	committed := app.committedHeight.Load()
+	if height == 0 {
+		height = committed
+	}
+	if height < committed {
+		return HaltStatus{}, fmt.Errorf("la altura %d ya se confirmó (último bloque: %d)", height, committed)
+	}
+
+	previous := app.halt
+	app.halt = HaltStatus{Height: height, Reason: reason, Source: source}
+	if height == committed {
+		app.markHalted()
+	}
+	if err := app.persistHalt(); err != nil {
+		app.halt = previous
+		return HaltStatus{}, err
+	}
+	consensusLog.Warnf("Detención de la cadena programada en el bloque %d (origen: %s, razón: %q)", height, source, reason)
+	return app.halt, nil
+}
+
+// ResumeChain cancela la detención programada (o reanuda la cadena detenida)
+func (app *ABCIApp) ResumeChain() error {
+	app.haltMu.Lock()
+	defer app.haltMu.Unlock()
+
+	if app.halt.Height == 0 {
+		return ErrNoHaltScheduled
+	}
+	previous := app.halt
+	app.halt = HaltStatus{}
+	if err := app.persistHalt(); err != nil {
+		app.halt = previous
+		return err
+	}
+	consensusLog.Warnf("Cadena reanudada (detención en el bloque %d cancelada)", previous.Height)
+	return nil
+}
+
+// HaltStatus retorna la detención programada de la cadena
+func (app *ABCIApp) HaltStatus() HaltStatus {
+	app.haltMu.RLock()
+	defer app.haltMu.RUnlock()
+	return app.halt
+}
+
+// haltedFor retorna si la cadena está detenida para un bloque de la altura indicada
+func (app *ABCIApp) haltedFor(height int64) bool {
+	app.haltMu.RLock()
+	defer app.haltMu.RUnlock()
+	return app.halt.Height > 0 && height > int64(app.halt.Height)
+}
+
+// checkNotHalted rechaza las transacciones nuevas mientras la cadena está detenida
+func (app *ABCIApp) checkNotHalted() error {
+	app.haltMu.RLock()
+	defer app.haltMu.RUnlock()
+	if app.halt.Halted {
+		return NewTxError(CodeUnavailable, "cadena detenida en el bloque %d: %s", app.halt.Height, app.halt.Reason)
+	}
+	return nil
+}
+
+// maybeHalt registra un bloque confirmado y, si es el de la detención programada, escribe el estado en
+// disco y detiene la cadena
+func (app *ABCIApp) maybeHalt(height uint64) {
+	app.committedHeight.Store(height)
+
+	app.haltMu.Lock()
+	defer app.haltMu.Unlock()
+	if app.halt.Height == 0 || app.halt.Halted || height < app.halt.Height {
+		return
+	}
+	app.markHalted()
+	if err := app.persistHalt(); err != nil {
+		consensusLog.Warnf("Error guardando detención de la cadena: %v", err)
+	}
+}
+
+// markHalted marca la cadena como detenida y escribe el estado en disco (requiere haltMu)
+func (app *ABCIApp) markHalted() {
+	now := time.Now().UTC()
+	app.halt.Halted = true
+	app.halt.HaltedAt = &now
+	if app.executor != nil {
+		if err := app.executor.FlushState(); err != nil {
+			consensusLog.Errorf("Error escribiendo el estado en disco al detener la cadena: %v", err)
+		}
+	}
+	consensusLog.Errorf("Cadena detenida en el bloque %d (origen: %s, razón: %q): no se aceptan propuestas ni transacciones nuevas",
+		app.halt.Height, app.halt.Source, app.halt.Reason)
+}
+
+// persistHalt guarda la detención programada o la borra si no hay (requiere haltMu)
+func (app *ABCIApp) persistHalt() error {
+	if app.storage == nil {
+		return nil
+	}
+	if app.halt.Height == 0 {
+		if err := app.storage.DeleteHaltStatus(); err != nil {
+			return fmt.Errorf("error borrando detención de la cadena: %w", err)
+		}
+		return nil
+	}
+	data, err := json.Marshal(app.halt)
+	if err != nil {
+		return err
+	}
+	if err := app.storage.SaveHaltStatus(data); err != nil {
+		return fmt.Errorf("error guardando detención de la cadena: %w", err)
+	}
+	return nil
+}
+
+// loadHalt carga la detención guardada y la altura del último bloque confirmado
+func (app *ABCIApp) loadHalt() {
+	if app.storage == nil {
+		return
+	}
+	if height, err := app.storage.GetLatestHeight(); err == nil {
+		app.committedHeight.Store(height)
+	}
+	data, err := app.storage.GetHaltStatus()
+	if err != nil || data == nil {
+		if err != nil {
+			consensusLog.Warn("Error cargando detención de la cadena: " + err.Error())
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &app.halt); err != nil {
+		consensusLog.Warn("Detención de la cadena guardada inválida: " + err.Error())
+		return
+	}
+	if app.halt.Halted {
+		consensusLog.Warnf("La cadena sigue detenida en el bloque %d (razón: %q): reanudar con DELETE /api/v1/admin/halt", app.halt.Height, app.halt.Reason)
+	}
+}
+
+// SetHaltOnInvariantViolation configura si una auditoría con invariantes incumplidas detiene la cadena
+func (app *ABCIApp) SetHaltOnInvariantViolation(enabled bool) {
+	app.haltOnViolation = enabled
+}
@@ -0,0 +1,59 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// TestScheduleHalt prueba que la cadena se detenga después del bloque programado y se reanude
+func TestScheduleHalt(t *testing.T) {
+	app := NewABCIApp(nil, nil, nil, "test-chain")
+	app.maybeHalt(3)
+
+	if _, err := app.ScheduleHalt(2, "tarde", HaltSourceAdmin); err == nil {
+		t.Error("Se esperaba un error por altura ya confirmada")
+	}
+	status, err := app.ScheduleHalt(5, "upgrade", HaltSourceAdmin)
+	if err != nil || status.Height != 5 || status.Halted {
+		t.Fatalf("Detención programada incorrecta: %+v, %v", status, err)
+	}
+
+	// Hasta confirmar el bloque 5 la cadena sigue
+	if app.haltedFor(5) || !app.haltedFor(6) {
+		t.Error("Solo los bloques posteriores a 5 deberían rechazarse")
+	}
+	app.maybeHalt(4)
+	if err := app.checkNotHalted(); err != nil {
+		t.Errorf("La cadena no debería estar detenida: %v", err)
+	}
+
+	app.maybeHalt(5)
+	if !app.HaltStatus().Halted {
+		t.Fatal("La cadena debería estar detenida después del bloque 5")
+	}
+	if err := app.checkNotHalted(); ErrorCode(err, CodeOK) != CodeUnavailable {
+		t.Errorf("Se esperaba CodeUnavailable con la cadena detenida: %v", err)
+	}
+	resp, err := app.ProcessProposal(context.Background(), &abcitypes.ProcessProposalRequest{Height: 6})
+	if err != nil || resp.Status != abcitypes.PROCESS_PROPOSAL_STATUS_REJECT {
+		t.Errorf("Se esperaba rechazar la propuesta del bloque 6: %v", err)
+	}
+
+	if err := app.ResumeChain(); err != nil {
+		t.Fatalf("Error reanudando la cadena: %v", err)
+	}
+	if app.haltedFor(6) || app.checkNotHalted() != nil {
+		t.Error("La cadena debería estar reanudada")
+	}
+	if err := app.ResumeChain(); !errors.Is(err, ErrNoHaltScheduled) {
+		t.Errorf("Se esperaba ErrNoHaltScheduled: %v", err)
+	}
+
+	// Sin altura se detiene después del último bloque confirmado
+	if status, _ := app.ScheduleHalt(0, "invariantes", HaltSourceInvariants); status.Height != 5 || !status.Halted {
+		t.Errorf("Se esperaba detener ya en el bloque 5: %+v", status)
+	}
+}
@@ -0,0 +1,223 @@
+package consensus
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// headerLeaf es la codificación canónica del header (RLP) de la que se calcula el hash del bloque:
+// todos los campos salvo el hash
+type headerLeaf struct {
+	Height           uint64
+	ParentHash       string
+	Timestamp        uint64
+	Validator        string
+	ChainID          string
+	StateRoot        string
+	TransactionsRoot string
+	ReceiptsRoot     string
+	GasUsed          uint64
+	GasLimit         uint64
+}
+
+// txLeaf es el valor de una transacción en el trie de transacciones del header (codificado en RLP)
+type txLeaf struct {
+	Hash       string
+	From       string
+	To         string
+	Value      string
+	Data       []byte
+	GasLimit   uint64
+	GasPrice   string
+	Nonce      uint64
+	Signature  []byte
+	Timestamp  uint64
+	AccessList execution.AccessList
+	Multisig   *MultisigAuth `rlp:"nil,optional"` // Omitidos si son los últimos y están vacíos: las demás hojas no cambian
+	FeePayer   *FeePayerAuth `rlp:"nil,optional"`
+}
+
+// receiptLeaf es el valor de un receipt en el trie de receipts del header (codificado en RLP)
+type receiptLeaf struct {
+	TransactionHash string
+	Status          string
+	GasUsed         uint64
+	Logs            []logLeaf
+	Error           string
+}
+
+// logLeaf es un log dentro de receiptLeaf (la altura y el hash de la transacción ya están en el receipt)
+type logLeaf struct {
+	Address string
+	Topics  []string
+	Data    []byte
+}
+
+// rlpList es una lista cuyo trie se arma con las claves RLP(índice), como en Ethereum
+type rlpList []interface{}
+
+func (l rlpList) Len() int { return len(l) }
+
+func (l rlpList) EncodeIndex(i int, w *bytes.Buffer) {
+	// Los tipos de las hojas solo tienen strings, bytes, enteros sin signo y listas: no fallan al codificarse
+	_ = rlp.Encode(w, l[i])
+}
+
+// TransactionsRoot retorna el root del trie de las transacciones de un bloque, en orden
+func TransactionsRoot(txs []*Transaction) common.Hash {
+	leaves := make(rlpList, len(txs))
+	for i, tx := range txs {
+		leaves[i] = newTxLeaf(tx)
+	}
+	return types.DeriveSha(leaves, trie.NewStackTrie(nil))
+}
+
+// newTxLeaf retorna la hoja de una transacción (vacía si es nil)
+func newTxLeaf(tx *Transaction) *txLeaf {
+	if tx == nil {
+		return &txLeaf{}
+	}
+	return &txLeaf{
+		Hash:       tx.Hash,
+		From:       tx.From,
+		To:         tx.To,
+		Value:      tx.Value,
+		Data:       tx.Data,
+		GasLimit:   tx.GasLimit,
+		GasPrice:   tx.GasPrice,
+		Nonce:      tx.Nonce,
+		Signature:  tx.Signature,
+		Timestamp:  uint64(tx.Timestamp),
+		AccessList: tx.AccessList,
+		Multisig:   tx.Multisig,
+		FeePayer:   tx.FeePayer,
+	}
+}
+
+// transaction reconstruye la transacción de una hoja
+func (l *txLeaf) transaction() *Transaction {
+	return &Transaction{
+		Hash:       l.Hash,
+		From:       l.From,
+		To:         l.To,
+		Value:      l.Value,
+		Data:       nilIfEmpty(l.Data),
+		GasLimit:   l.GasLimit,
+		GasPrice:   l.GasPrice,
+		Nonce:      l.Nonce,
+		Signature:  nilIfEmpty(l.Signature),
+		Timestamp:  int64(l.Timestamp),
+		AccessList: l.AccessList,
+		Multisig:   l.Multisig,
+		FeePayer:   l.FeePayer,
+	}
+}
+
+// ReceiptsRoot retorna el root del trie de los receipts de un bloque, en orden
+func ReceiptsRoot(receipts []*TransactionReceipt) common.Hash {
+	leaves := make(rlpList, len(receipts))
+	for i, receipt := range receipts {
+		leaf := &receiptLeaf{}
+		if receipt != nil {
+			leaf = &receiptLeaf{
+				TransactionHash: receipt.TransactionHash,
+				Status:          receipt.Status,
+				GasUsed:         receipt.GasUsed,
+				Logs:            make([]logLeaf, len(receipt.Logs)),
+				Error:           receipt.Error,
+			}
+			for j, log := range receipt.Logs {
+				leaf.Logs[j] = logLeaf{Address: log.Address, Topics: log.Topics, Data: log.Data}
+			}
+		}
+		leaves[i] = leaf
+	}
+	return types.DeriveSha(leaves, trie.NewStackTrie(nil))
+}
+
+// blockGas retorna el gas usado por los receipts y la suma de los gas limit de las transacciones
+func blockGas(block *Block) (used, limit uint64) {
+	for _, receipt := range block.Receipts {
+		if receipt != nil {
+			used += receipt.GasUsed
+		}
+	}
+	for _, tx := range block.Transactions {
+		if tx != nil {
+			limit += tx.GasLimit
+		}
+	}
+	return used, limit
+}
+
+// ComputeHash retorna el hash del bloque: keccak256 de la codificación RLP de los campos del header.
+// Cubre las transacciones y los receipts a través de sus roots
+func (h *BlockHeader) ComputeHash() common.Hash {
+	encoded, _ := rlp.EncodeToBytes(&headerLeaf{
+		Height:           h.Height,
+		ParentHash:       h.ParentHash,
+		Timestamp:        uint64(h.Timestamp.Unix()),
+		Validator:        h.Validator,
+		ChainID:          h.ChainID,
+		StateRoot:        h.StateRoot,
+		TransactionsRoot: h.TransactionsRoot,
+		ReceiptsRoot:     h.ReceiptsRoot,
+		GasUsed:          h.GasUsed,
+		GasLimit:         h.GasLimit,
+	})
+	return crypto.Keccak256Hash(encoded)
+}
+
+// AppHash retorna el app hash del bloque (el root del estado EVM). En los bloques anteriores a StateRoot,
+// el hash del bloque era el app hash
+func (h *BlockHeader) AppHash() string {
+	if h.StateRoot != "" {
+		return h.StateRoot
+	}
+	return h.Hash
+}
+
+// sealHeader completa el header a partir del contenido del bloque (root de estado, roots de transacciones y
+// receipts y gas) y calcula su hash, que también se registra en los receipts
+func (b *Block) sealHeader(stateRoot common.Hash) {
+	b.Header.StateRoot = stateRoot.Hex()
+	b.Header.TransactionsRoot = TransactionsRoot(b.Transactions).Hex()
+	b.Header.ReceiptsRoot = ReceiptsRoot(b.Receipts).Hex()
+	b.Header.GasUsed, b.Header.GasLimit = blockGas(b)
+	b.Header.Hash = b.Header.ComputeHash().Hex()
+	for _, receipt := range b.Receipts {
+		if receipt != nil {
+			receipt.BlockHash = b.Header.Hash
+		}
+	}
+}
+
+// VerifyHeader verifica que el hash del bloque corresponda a su header y que los roots y el gas del header
+// correspondan a las transacciones y receipts. Los bloques guardados antes de que el header tuviera roots
+// (con el app hash como hash) no se verifican
+func (b *Block) VerifyHeader() error {
+	if b.Header.TransactionsRoot == "" && b.Header.ReceiptsRoot == "" {
+		return nil
+	}
+	if hash := b.Header.ComputeHash().Hex(); hash != b.Header.Hash {
+		return fmt.Errorf("hash %s no corresponde al header (%s)", b.Header.Hash, hash)
+	}
+	if root := TransactionsRoot(b.Transactions).Hex(); root != b.Header.TransactionsRoot {
+		return fmt.Errorf("transactionsRoot %s no coincide con las transacciones (%s)", b.Header.TransactionsRoot, root)
+	}
+	if root := ReceiptsRoot(b.Receipts).Hex(); root != b.Header.ReceiptsRoot {
+		return fmt.Errorf("receiptsRoot %s no coincide con los receipts (%s)", b.Header.ReceiptsRoot, root)
+	}
+	if used, limit := blockGas(b); used != b.Header.GasUsed || limit != b.Header.GasLimit {
+		return fmt.Errorf("gas del header (usado %d, límite %d) no coincide con el bloque (usado %d, límite %d)",
+			b.Header.GasUsed, b.Header.GasLimit, used, limit)
+	}
+	return nil
+}
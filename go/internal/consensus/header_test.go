@@ -0,0 +1,127 @@
+package consensus
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TestBlock_VerifyHeader prueba que el hash y los roots del header dependan del contenido y del orden del
+// bloque y que cualquier cambio en el header, las transacciones, los receipts o el gas se detecte
+func TestBlock_VerifyHeader(t *testing.T) {
+	newBlock := func() *Block {
+		return &Block{
+			Header: BlockHeader{Height: 7},
+			Transactions: []*Transaction{
+				{Hash: "0x01", From: "0x1234567890123456789012345678901234567890", To: "0x0000000000000000000000000000000000000abc", Value: "5", GasLimit: 21000, GasPrice: "1", Nonce: 0},
+				{Hash: "0x02", From: "0x1234567890123456789012345678901234567890", Data: []byte{0x60, 0x00}, Value: "0", GasLimit: 100000, GasPrice: "1", Nonce: 1},
+			},
+			Receipts: []*TransactionReceipt{
+				{TransactionHash: "0x01", BlockNumber: 7, GasUsed: 21000, Status: "success"},
+				{TransactionHash: "0x02", BlockNumber: 7, GasUsed: 53000, Status: "success", Logs: []Log{{Address: "0x0000000000000000000000000000000000000def", Topics: []string{"0xaa"}, Data: []byte{1}}}},
+			},
+		}
+	}
+
+	block := newBlock()
+	block.sealHeader(types.EmptyRootHash)
+	if err := block.VerifyHeader(); err != nil {
+		t.Fatalf("Header recién sellado inválido: %v", err)
+	}
+	if block.Header.Hash != block.Header.ComputeHash().Hex() || block.Receipts[0].BlockHash != block.Header.Hash {
+		t.Errorf("Hash del bloque %s no registrado en el header o los receipts", block.Header.ComputeHash().Hex())
+	}
+	if block.Header.GasUsed != 74000 || block.Header.GasLimit != 121000 {
+		t.Errorf("Gas del header incorrecto: usado %d, límite %d", block.Header.GasUsed, block.Header.GasLimit)
+	}
+	if block.Header.TransactionsRoot == types.EmptyRootHash.Hex() || block.Header.ReceiptsRoot == types.EmptyRootHash.Hex() {
+		t.Error("Un bloque con transacciones no debería tener roots vacíos")
+	}
+
+	// Los roots sobreviven a la serialización del bloque
+	data, _ := json.Marshal(block)
+	var decoded Block
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Error decodificando bloque: %v", err)
+	}
+	if err := decoded.VerifyHeader(); err != nil {
+		t.Errorf("Bloque decodificado inválido: %v", err)
+	}
+
+	tampered := newBlock()
+	tampered.Header = block.Header
+	tampered.Transactions[0].Value = "6"
+	if err := tampered.VerifyHeader(); err == nil || !strings.Contains(err.Error(), "transactionsRoot") {
+		t.Errorf("Cambio de valor no detectado: %v", err)
+	}
+
+	tampered = newBlock()
+	tampered.Header = block.Header
+	tampered.Transactions[0], tampered.Transactions[1] = tampered.Transactions[1], tampered.Transactions[0]
+	if err := tampered.VerifyHeader(); err == nil {
+		t.Error("Cambio de orden no detectado")
+	}
+
+	tampered = newBlock()
+	tampered.Header = block.Header
+	tampered.Receipts[1].Logs[0].Topics = []string{"0xbb"}
+	if err := tampered.VerifyHeader(); err == nil || !strings.Contains(err.Error(), "receiptsRoot") {
+		t.Errorf("Cambio de log no detectado: %v", err)
+	}
+
+	// Un header alterado ya no corresponde a su hash; recalculando el hash, el gas no corresponde al bloque
+	tampered = newBlock()
+	tampered.Header = block.Header
+	tampered.Header.GasUsed++
+	if err := tampered.VerifyHeader(); err == nil || !strings.Contains(err.Error(), "no corresponde al header") {
+		t.Errorf("Cambio de header no detectado: %v", err)
+	}
+	tampered.Header.Hash = tampered.Header.ComputeHash().Hex()
+	if err := tampered.VerifyHeader(); err == nil || !strings.Contains(err.Error(), "gas") {
+		t.Errorf("Cambio de gas no detectado: %v", err)
+	}
+
+	// Bloques anteriores a los roots del header
+	if err := newBlock().VerifyHeader(); err != nil {
+		t.Errorf("Un bloque sin roots no debería verificarse: %v", err)
+	}
+}
+
+// TestABCIApp_CommitSealsHeader prueba que el bloque guardado en Commit tenga el root de estado, los roots
+// de un bloque vacío y un hash propio que lo encadena e indexa
+func TestABCIApp_CommitSealsHeader(t *testing.T) {
+	app, _ := newSnapshotTestApp(t, "commit_seals_header")
+	if err := app.executor.FundAccount("0x1234567890123456789012345678901234567890", "1000"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	commitTestBlocks(t, app, 1, 2)
+
+	data, err := app.storage.GetBlock(2)
+	if err != nil {
+		t.Fatalf("Error obteniendo bloque: %v", err)
+	}
+	block, err := DecodeBlock(data)
+	if err != nil {
+		t.Fatalf("Error decodificando bloque: %v", err)
+	}
+	header := block.Header
+	if appHash := common.BytesToHash(app.state.AppHash).Hex(); header.StateRoot != appHash || header.AppHash() != appHash {
+		t.Errorf("StateRoot %s debería ser el app hash %s", header.StateRoot, appHash)
+	}
+	if header.Hash != header.ComputeHash().Hex() || header.Hash == header.StateRoot {
+		t.Errorf("Hash %s no calculado del header", header.Hash)
+	}
+	if byHash, err := app.storage.GetBlockByHash(header.Hash); err != nil || string(byHash) != string(data) {
+		t.Errorf("Bloque no indexado por hash: %v", err)
+	}
+	parentData, _ := app.storage.GetBlock(1)
+	if parent, err := DecodeBlock(parentData); err != nil || header.ParentHash != parent.Header.Hash {
+		t.Errorf("ParentHash %s no es el hash del bloque 1 (%v)", header.ParentHash, err)
+	}
+	if header.TransactionsRoot != types.EmptyRootHash.Hex() || header.ReceiptsRoot != types.EmptyRootHash.Hex() || header.GasUsed != 0 {
+		t.Errorf("Header de bloque vacío incorrecto: %+v", header)
+	}
+}
@@ -0,0 +1,245 @@
+package consensus
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ModuleAccountBalance es el balance de una cuenta de módulo en el reporte de invariantes
+type ModuleAccountBalance struct {
+	ModuleAccount
+	Balance  string `json:"balance"`
+	Expected string `json:"expected,omitempty"` // Lo que registra la contabilidad del protocolo (si aplica)
+}
+
+// InvariantReport es el resultado de verificar las invariantes contables sobre un estado confirmado
+type InvariantReport struct {
+	Height         uint64                 `json:"height,omitempty"` // Bloque cuyos receipts se verificaron (0 = ninguno)
+	Root           string                 `json:"root"`
+	Accounts       uint64                 `json:"accounts"`
+	ModuleAccounts []ModuleAccountBalance `json:"moduleAccounts"`
+	ModuleTotal    string                 `json:"moduleTotal"`   // Suma de las cuentas de módulo
+	FreeBalances   string                 `json:"freeBalances"`  // Balances fuera de las cuentas de módulo
+	TotalBalances  string                 `json:"totalBalances"` // Cuentas de módulo más balances libres
+	TotalSupply    string                 `json:"totalSupply"`   // Emitido menos quemado
+	Violations     []string               `json:"violations,omitempty"`
+}
+
+// CheckInvariants verifica las invariantes contables sobre el último estado confirmado:
+//   - la suma de las cuentas de módulo y los balances libres es igual al supply total (emitido - quemado),
+//     que no es negativo, y ninguna cuenta tiene más que el supply total
+//   - el bonded y el unbonded pool tienen exactamente el stake de los validadores fuera y dentro de jail
+//   - la custodia de recompensas cubre las recompensas y comisiones pendientes
+//   - el fee collector está vacío entre bloques
+//   - stakes, recompensas y comisiones no negativos y poder de voto coherente con el stake
+//   - el último bloque guardado tiene un receipt por transacción
+//
+// validators y blocks pueden ser nil (se omiten las verificaciones que dependen de ellos)
+func CheckInvariants(executor *execution.EVMExecutor, validators *ValidatorSet, blocks *storage.BlockchainDB) (*InvariantReport, error) {
+	var targets *moduleTargets
+	var stakeViolations []string
+	if validators != nil {
+		t := validators.ModuleTargets()
+		targets = &t
+		stakeViolations = validators.stakeViolations()
+	}
+	report, err := checkInvariants(executor.GetStateManager(), executor.GetStateManager().GetRootHash(), targets)
+	if err != nil {
+		return nil, err
+	}
+	report.Violations = append(report.Violations, stakeViolations...)
+	if blocks != nil {
+		if height, err := blocks.GetLatestHeight(); err == nil && height > 0 {
+			report.checkBlockReceipts(blocks, height)
+		}
+	}
+	return report, nil
+}
+
+// checkInvariants verifica las invariantes en root contra los balances esperados (targets puede ser nil)
+func checkInvariants(stateManager *execution.StateManager, root common.Hash, targets *moduleTargets) (*InvariantReport, error) {
+	accounts := ModuleAccounts()
+	addresses := make([]common.Address, len(accounts))
+	for i, account := range accounts {
+		addresses[i] = common.HexToAddress(account.Address)
+	}
+	ledger, err := stateManager.Ledger(root, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := map[string]*big.Int{}
+	if targets != nil {
+		expected[BondedPoolAddress] = targets.Bonded
+		expected[UnbondedPoolAddress] = targets.Unbonded
+	}
+
+	report := &InvariantReport{Root: ledger.Root, Accounts: ledger.Accounts}
+	moduleTotal := new(big.Int)
+	for i, account := range accounts {
+		balance := ledger.Balances[addresses[i]]
+		moduleTotal.Add(moduleTotal, balance)
+		entry := ModuleAccountBalance{ModuleAccount: account, Balance: balance.String()}
+		if want, ok := expected[account.Address]; ok {
+			entry.Expected = want.String()
+			if balance.Cmp(want) != 0 {
+				report.addViolation("%s tiene %s y el stake registrado es %s", account.Name, balance, want)
+			}
+		}
+		report.ModuleAccounts = append(report.ModuleAccounts, entry)
+	}
+	if targets != nil {
+		custody := ledger.Balances[common.HexToAddress(RewardsAddress)]
+		if custody.Cmp(targets.Rewards) < 0 {
+			report.addViolation("la custodia de recompensas (%s) no cubre las recompensas pendientes (%s)", custody, targets.Rewards)
+		}
+	}
+	if collected := ledger.Balances[common.HexToAddress(execution.FeeCollectorAddress)]; collected.Sign() != 0 {
+		report.addViolation("el fee collector tiene %s fuera de un bloque", collected)
+	}
+
+	supply := new(big.Int).Sub(ledger.Minted, ledger.Burned)
+	free := new(big.Int).Sub(ledger.TotalBalances, moduleTotal)
+	report.ModuleTotal = moduleTotal.String()
+	report.FreeBalances = free.String()
+	report.TotalBalances = ledger.TotalBalances.String()
+	report.TotalSupply = supply.String()
+	// Una cadena anterior a los contadores de emisión no tiene nada emitido registrado
+	if ledger.Minted.Sign() != 0 {
+		if supply.Sign() < 0 {
+			report.addViolation("supply total negativo: quemado (%s) mayor que emitido (%s)", ledger.Burned, ledger.Minted)
+		}
+		if ledger.TotalBalances.Cmp(supply) != 0 {
+			report.addViolation("cuentas de módulo (%s) + balances libres (%s) = %s, distinto del supply total (%s)",
+				moduleTotal, free, ledger.TotalBalances, supply)
+		}
+		// Los balances del trie no tienen signo: una resta que se pasa de cero deja un balance enorme
+		if ledger.MaxBalance.Cmp(supply) > 0 {
+			report.addViolation("la cuenta %s tiene %s, más que el supply total (%s)", ledger.MaxBalanceKey.Hex(), ledger.MaxBalance, supply)
+		}
+	}
+	return report, nil
+}
+
+// stakeViolations verifica la contabilidad del set de validadores: stakes, delegaciones, recompensas y
+// comisiones no negativos y poder de voto igual al calculado del stake
+func (vs *ValidatorSet) stakeViolations() []string {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+
+	var violations []string
+	negative := func(what string, amount *big.Int) {
+		if amount != nil && amount.Sign() < 0 {
+			violations = append(violations, fmt.Sprintf("%s negativo: %s", what, amount))
+		}
+	}
+	for _, v := range vs.validators {
+		negative("stake de "+v.Address, v.Stake)
+		negative("comisión acumulada de "+v.Address, v.AccumulatedCommission)
+		for delegator, d := range v.Delegations {
+			negative("delegación de "+delegator+" en "+v.Address, d.Shares)
+			negative("recompensa acumulada de "+delegator+" en "+v.Address, d.Accrued)
+		}
+		if v.Stake != nil {
+			if want := vs.calculatePower(v.Stake); v.Power != want {
+				violations = append(violations, fmt.Sprintf("%s tiene poder %d y su stake (%s) corresponde a %d", v.Address, v.Power, v.Stake, want))
+			}
+		}
+	}
+	for address, unclaimed := range vs.unclaimedRewards {
+		negative("recompensa sin reclamar de "+address, unclaimed)
+	}
+	sort.Strings(violations)
+	return violations
+}
+
+// checkBlockReceipts verifica que el bloque guardado en height tenga un receipt por transacción, en el
+// mismo orden
+func (r *InvariantReport) checkBlockReceipts(blocks *storage.BlockchainDB, height uint64) {
+	r.Height = height
+	data, err := blocks.GetBlock(height)
+	if err != nil {
+		r.addViolation("bloque %d no encontrado: %v", height, err)
+		return
+	}
+	block, err := DecodeBlock(data)
+	if err != nil {
+		r.addViolation("bloque %d no decodificable: %v", height, err)
+		return
+	}
+	if len(block.Receipts) != len(block.Transactions) {
+		r.addViolation("el bloque %d tiene %d transacciones y %d receipts", height, len(block.Transactions), len(block.Receipts))
+		return
+	}
+	for i, tx := range block.Transactions {
+		if block.Receipts[i].TransactionHash != tx.Hash {
+			r.addViolation("el receipt %d del bloque %d es de %s y la transacción es %s", i, height, block.Receipts[i].TransactionHash, tx.Hash)
+			return
+		}
+	}
+}
+
+// addViolation registra una invariante incumplida
+func (r *InvariantReport) addViolation(format string, args ...interface{}) {
+	r.Violations = append(r.Violations, fmt.Sprintf(format, args...))
+}
+
+// SetInvariantCheckInterval configura cada cuántos bloques se verifican las invariantes en segundo plano
+// (0 = deshabilitado)
+func (app *ABCIApp) SetInvariantCheckInterval(interval uint64) {
+	app.invariantInterval = interval
+}
+
+// maybeAuditInvariants verifica las invariantes cada invariantInterval bloques sobre el estado recién
+// confirmado. Los balances esperados se toman en el commit y el recorrido del estado corre en segundo
+// plano: lee solo el root confirmado, así que no interfiere con el bloque siguiente
+func (app *ABCIApp) maybeAuditInvariants(height uint64, root common.Hash) {
+	if app.invariantInterval == 0 || height == 0 || height%app.invariantInterval != 0 {
+		return
+	}
+	if !app.invariantAudit.CompareAndSwap(false, true) {
+		consensusLog.Warnf("Auditoría de invariantes anterior en curso: se omite la del bloque %d", height)
+		return
+	}
+	var targets *moduleTargets
+	var stakeViolations []string
+	if app.validators != nil {
+		t := app.validators.ModuleTargets()
+		targets = &t
+		stakeViolations = app.validators.stakeViolations()
+	}
+
+	go func() {
+		defer app.invariantAudit.Store(false)
+		report, err := checkInvariants(app.executor.GetStateManager(), root, targets)
+		if err != nil {
+			consensusLog.Warnf("No se pudo auditar las invariantes en el bloque %d: %v", height, err)
+			return
+		}
+		report.Violations = append(report.Violations, stakeViolations...)
+		if app.storage != nil {
+			report.checkBlockReceipts(app.storage, height)
+		}
+		if app.metrics != nil {
+			app.metrics.SetInvariantViolations(len(report.Violations))
+		}
+		if len(report.Violations) == 0 {
+			consensusLog.Infof("Invariantes verificadas en el bloque %d: supply %s", height, report.TotalSupply)
+			return
+		}
+		for _, violation := range report.Violations {
+			consensusLog.Errorf("Invariante incumplida en el bloque %d: %s", height, violation)
+		}
+		if app.haltOnViolation {
+			reason := fmt.Sprintf("%d invariantes incumplidas en el bloque %d: %s", len(report.Violations), height, report.Violations[0])
+			if _, err := app.ScheduleHalt(0, reason, HaltSourceInvariants); err != nil {
+				consensusLog.Errorf("No se pudo detener la cadena: %v", err)
+			}
+		}
+	}()
+}
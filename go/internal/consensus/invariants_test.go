@@ -0,0 +1,82 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// TestValidatorSet_StakeViolations prueba la verificación de stakes negativos y poder incoherente
+func TestValidatorSet_StakeViolations(t *testing.T) {
+	oxg := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	vs := &ValidatorSet{validators: map[string]*Validator{
+		"0x1111111111111111111111111111111111111111": {
+			Address: "0x1111111111111111111111111111111111111111",
+			Stake:   new(big.Int).Mul(big.NewInt(5000), oxg),
+			Power:   5000,
+		},
+	}}
+	if violations := vs.stakeViolations(); len(violations) != 0 {
+		t.Fatalf("No debería haber violaciones: %v", violations)
+	}
+
+	vs.validators["0x1111111111111111111111111111111111111111"].Power = 4000
+	vs.validators["0x2222222222222222222222222222222222222222"] = &Validator{
+		Address:               "0x2222222222222222222222222222222222222222",
+		Stake:                 big.NewInt(0),
+		AccumulatedCommission: big.NewInt(-1),
+	}
+	vs.unclaimedRewards = map[string]*big.Int{"0x3333333333333333333333333333333333333333": big.NewInt(-5)}
+	if violations := vs.stakeViolations(); len(violations) != 3 {
+		t.Errorf("Se esperaban 3 violaciones (poder, comisión y recompensa sin reclamar): %v", violations)
+	}
+}
+
+// TestInvariantReport_CheckBlockReceipts prueba la verificación de un receipt por transacción
+func TestInvariantReport_CheckBlockReceipts(t *testing.T) {
+	testDir := createValidatorTestDir("invariants_receipts")
+	defer func() {
+		if err := cleanupValidatorTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	save := func(height uint64, block *Block) {
+		block.Header.Height = height
+		data, err := EncodeBlock(block)
+		if err != nil {
+			t.Fatalf("Error codificando bloque: %v", err)
+		}
+		if err := db.SaveCanonicalBlock(height, block.Header.Hash, data); err != nil {
+			t.Fatalf("Error guardando bloque: %v", err)
+		}
+	}
+	save(1, &Block{
+		Header:       BlockHeader{Hash: "0xaa"},
+		Transactions: []*Transaction{{Hash: "0x01"}, {Hash: "0x02"}},
+		Receipts:     []*TransactionReceipt{{TransactionHash: "0x01"}, {TransactionHash: "0x02"}},
+	})
+	save(2, &Block{
+		Header:       BlockHeader{Hash: "0xbb"},
+		Transactions: []*Transaction{{Hash: "0x03"}, {Hash: "0x04"}},
+		Receipts:     []*TransactionReceipt{{TransactionHash: "0x03"}},
+	})
+
+	report := &InvariantReport{}
+	report.checkBlockReceipts(db, 1)
+	if len(report.Violations) != 0 || report.Height != 1 {
+		t.Errorf("El bloque 1 es coherente: %+v", report)
+	}
+	report.checkBlockReceipts(db, 2)
+	report.checkBlockReceipts(db, 3)
+	if len(report.Violations) != 2 {
+		t.Errorf("Se esperaba reportar el receipt faltante y el bloque inexistente: %v", report.Violations)
+	}
+}
@@ -0,0 +1,160 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// Motivos de jail reportados en la API y en el historial
+const (
+	JailReasonMisbehavior = "misbehavior" // Slash por comportamiento malicioso (ej: doble firma)
+	JailReasonDowntime    = "downtime"    // Demasiados bloques sin firmar
+)
+
+// validatorConsensusKey es la clave de storage del poder de cada validador según CometBFT
+const validatorConsensusKey = "validators:consensus"
+
+// now retorna la hora del bloque en curso (la hora local si todavía no hay bloque),
+// para que los plazos de jail se evalúen igual en todos los nodos (requiere lock)
+func (vs *ValidatorSet) now() time.Time {
+	if vs.blockTime.IsZero() {
+		return time.Now()
+	}
+	return vs.blockTime
+}
+
+// markSetChange registra que un validador entró o salió del set activo (jail, unjail o remoción)
+// para informar el cambio a CometBFT al final del bloque (requiere lock)
+func (vs *ValidatorSet) markSetChange(validator *Validator) {
+	if vs.setChanges == nil {
+		vs.setChanges = make(map[string][]byte)
+	}
+	vs.setChanges[validator.Address] = validator.PubKey
+}
+
+// isActive retorna si el validador debe estar en el set de CometBFT (requiere lock)
+func (vs *ValidatorSet) isActive(v *Validator) bool {
+	return !v.Jailed && v.Stake.Cmp(vs.minStake) >= 0 && v.Power > 0
+}
+
+// TakeSetChangeUpdates retorna las actualizaciones de CometBFT de los validadores que entraron
+// o salieron del set activo en este bloque: poder 0 para los que quedaron en jail o fueron
+// removidos, y su poder actual para los que salieron de jail. Solo se remueven validadores que
+// CometBFT conoce, y nunca todos (CometBFT se detiene con un set vacío)
+func (vs *ValidatorSet) TakeSetChangeUpdates() []abcitypes.ValidatorUpdate {
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+
+	if len(vs.setChanges) == 0 {
+		return nil
+	}
+	addresses := make([]string, 0, len(vs.setChanges))
+	for address := range vs.setChanges {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	remaining := make(map[string]int64, len(vs.consensusPower))
+	for key, power := range vs.consensusPower {
+		remaining[key] = power
+	}
+	var additions, removals []abcitypes.ValidatorUpdate
+	for _, address := range addresses {
+		pubKey := vs.setChanges[address]
+		key := hex.EncodeToString(pubKey)
+		v, exists := vs.validators[address]
+		if exists && vs.isActive(v) {
+			if remaining[key] != v.Power {
+				additions = append(additions, abcitypes.ValidatorUpdate{PubKeyBytes: pubKey, Power: v.Power})
+				remaining[key] = v.Power
+			}
+			continue
+		}
+		if _, known := remaining[key]; known {
+			removals = append(removals, abcitypes.ValidatorUpdate{PubKeyBytes: pubKey, Power: 0})
+			delete(remaining, key)
+		}
+	}
+	vs.setChanges = nil
+
+	if len(remaining) == 0 && len(removals) > 0 {
+		consensusLog.Warnf("Se omite la salida de %d validadores en jail: el set de CometBFT quedaría vacío", len(removals))
+		removals = nil
+	}
+	return append(additions, removals...)
+}
+
+// RecordConsensusUpdates registra las actualizaciones de validadores entregadas a CometBFT
+// (InitChain y FinalizeBlock) para saber qué validadores conoce y con qué poder
+func (vs *ValidatorSet) RecordConsensusUpdates(updates []abcitypes.ValidatorUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+
+	if vs.consensusPower == nil {
+		vs.consensusPower = make(map[string]int64)
+	}
+	for _, update := range updates {
+		key := hex.EncodeToString(update.PubKeyBytes)
+		if update.Power <= 0 {
+			delete(vs.consensusPower, key)
+		} else {
+			vs.consensusPower[key] = update.Power
+		}
+	}
+
+	if vs.storage == nil {
+		return
+	}
+	data, err := json.Marshal(vs.consensusPower)
+	if err != nil {
+		consensusLog.Warn("Error serializando el set de CometBFT: " + err.Error())
+		return
+	}
+	if err := vs.storage.SaveAccount(validatorConsensusKey, data); err != nil {
+		consensusLog.Warn("Error guardando el set de CometBFT: " + err.Error())
+	}
+}
+
+// loadConsensusPower lee de storage el poder de cada validador según CometBFT (requiere lock)
+func (vs *ValidatorSet) loadConsensusPower() {
+	vs.consensusPower = make(map[string]int64)
+	data, err := vs.storage.GetAccount(validatorConsensusKey)
+	if err != nil {
+		// Sin set guardado: se completa con la próxima actualización
+		return
+	}
+	if err := json.Unmarshal(data, &vs.consensusPower); err != nil {
+		consensusLog.Warn("Set de CometBFT guardado inválido, se descarta: " + err.Error())
+		vs.consensusPower = make(map[string]int64)
+	}
+}
+
+// mergeValidatorUpdates combina dos listas de actualizaciones; si una clave aparece en ambas gana extra
+func mergeValidatorUpdates(base, extra []abcitypes.ValidatorUpdate) []abcitypes.ValidatorUpdate {
+	if len(extra) == 0 {
+		return base
+	}
+	index := make(map[string]int, len(base))
+	merged := make([]abcitypes.ValidatorUpdate, 0, len(base)+len(extra))
+	for _, update := range base {
+		index[hex.EncodeToString(update.PubKeyBytes)] = len(merged)
+		merged = append(merged, update)
+	}
+	for _, update := range extra {
+		key := hex.EncodeToString(update.PubKeyBytes)
+		if i, ok := index[key]; ok {
+			merged[i] = update
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, update)
+	}
+	return merged
+}
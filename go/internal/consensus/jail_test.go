@@ -0,0 +1,109 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// TestValidatorSet_JailSetChanges prueba que el jail saque al validador del set de CometBFT y el unjail lo devuelva
+func TestValidatorSet_JailSetChanges(t *testing.T) {
+	validatorSet, keys := crearValidatorSetConGenesis(t)
+	address := "0x1111111111111111111111111111111111111111"
+	blockTime := time.Unix(1700000000, 0).UTC()
+
+	validatorSet.RecordConsensusUpdates([]abcitypes.ValidatorUpdate{
+		{PubKeyBytes: keys[0].PubKey().Bytes(), Power: 5000},
+		{PubKeyBytes: keys[1].PubKey().Bytes(), Power: 3000},
+	})
+
+	validatorSet.SetBlockInfo(10, blockTime)
+	if err := validatorSet.SlashWithReason(address, 10, time.Hour, JailReasonDowntime); err != nil {
+		t.Fatalf("Error slasheando validador: %v", err)
+	}
+	updates := validatorSet.TakeSetChangeUpdates()
+	if len(updates) != 1 || updates[0].Power != 0 || string(updates[0].PubKeyBytes) != string(keys[0].PubKey().Bytes()) {
+		t.Fatalf("Debería sacar al validador del set: %+v", updates)
+	}
+	validatorSet.RecordConsensusUpdates(updates)
+	if again := validatorSet.TakeSetChangeUpdates(); len(again) != 0 {
+		t.Errorf("Los cambios deberían consumirse una sola vez: %+v", again)
+	}
+
+	validator, _ := validatorSet.GetValidator(address)
+	if validator.JailReason != JailReasonDowntime || !validator.JailedUntil.Equal(blockTime.Add(time.Hour)) {
+		t.Errorf("Jail incorrecto: reason=%s until=%s", validator.JailReason, validator.JailedUntil)
+	}
+	history, _ := validatorSet.GetValidatorHistory(address)
+	if len(history) != 1 || history[0].Reason != JailReasonDowntime {
+		t.Errorf("El slash debería registrar el motivo: %+v", history)
+	}
+
+	// El plazo se evalúa con la hora del bloque
+	if err := validatorSet.Unjail(address); err == nil {
+		t.Fatal("No debería salir de jail antes del plazo")
+	}
+	validatorSet.SetBlockInfo(20, blockTime.Add(2*time.Hour))
+	if err := validatorSet.Unjail(address); err != nil {
+		t.Fatalf("Error en unjail: %v", err)
+	}
+	updates = validatorSet.TakeSetChangeUpdates()
+	if len(updates) != 1 || updates[0].Power != 4500 {
+		t.Errorf("Debería volver al set con su poder actual: %+v", updates)
+	}
+	if validator, _ := validatorSet.GetValidator(address); validator.Jailed || validator.JailReason != "" {
+		t.Errorf("Validador debería estar libre: %+v", validator)
+	}
+}
+
+// TestValidatorSet_UnjailRequiresMinStake prueba el re-bond de un validador que quedó bajo el stake mínimo
+func TestValidatorSet_UnjailRequiresMinStake(t *testing.T) {
+	validatorSet, _ := crearValidatorSetConGenesis(t)
+	address := "0x2222222222222222222222222222222222222222"
+	blockTime := time.Unix(1700000000, 0).UTC()
+
+	validatorSet.SetBlockInfo(10, blockTime)
+	if err := validatorSet.Slash(address, 90, time.Hour); err != nil {
+		t.Fatalf("Error slasheando validador: %v", err)
+	}
+	if _, err := validatorSet.GetValidator(address); err != nil {
+		t.Fatalf("El validador debería seguir en el set hasta el re-bond: %v", err)
+	}
+
+	validatorSet.SetBlockInfo(20, blockTime.Add(2*time.Hour))
+	if err := validatorSet.Unjail(address); err == nil {
+		t.Fatal("No debería salir de jail con stake bajo el mínimo")
+	}
+
+	oxg := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	if err := validatorSet.Stake(address, new(big.Int).Mul(big.NewInt(1000), oxg)); err != nil {
+		t.Fatalf("Error agregando stake en jail: %v", err)
+	}
+	if err := validatorSet.Unjail(address); err != nil {
+		t.Fatalf("Error en unjail después del re-bond: %v", err)
+	}
+}
+
+// TestValidatorSet_JailKeepsLastValidator prueba que nunca se vacíe el set de CometBFT
+func TestValidatorSet_JailKeepsLastValidator(t *testing.T) {
+	validatorSet, keys := crearValidatorSetConGenesis(t)
+	validatorSet.RecordConsensusUpdates([]abcitypes.ValidatorUpdate{{PubKeyBytes: keys[1].PubKey().Bytes(), Power: 3000}})
+
+	if err := validatorSet.Slash("0x2222222222222222222222222222222222222222", 10, time.Hour); err != nil {
+		t.Fatalf("Error slasheando validador: %v", err)
+	}
+	if updates := validatorSet.TakeSetChangeUpdates(); len(updates) != 0 {
+		t.Errorf("No debería sacar al último validador: %+v", updates)
+	}
+}
+
+// TestMergeValidatorUpdates prueba que las actualizaciones de jail reemplacen a las de la rotación
+func TestMergeValidatorUpdates(t *testing.T) {
+	base := []abcitypes.ValidatorUpdate{{PubKeyBytes: []byte{1}, Power: 10}, {PubKeyBytes: []byte{2}, Power: 20}}
+	merged := mergeValidatorUpdates(base, []abcitypes.ValidatorUpdate{{PubKeyBytes: []byte{2}, Power: 0}, {PubKeyBytes: []byte{3}, Power: 30}})
+	if len(merged) != 3 || merged[1].Power != 0 || merged[2].Power != 30 {
+		t.Errorf("Combinación incorrecta: %+v", merged)
+	}
+}
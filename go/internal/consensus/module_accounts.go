@@ -0,0 +1,155 @@
+package consensus
+
+import (
+	"math/big"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+)
+
+// Cuentas de módulo: cuentas de sistema sin clave privada cuyo balance respalda la contabilidad del protocolo
+const (
+	// BondedPoolAddress custodia el stake de los validadores fuera de jail (la custodia de staking)
+	BondedPoolAddress = StakingAddress
+	// UnbondedPoolAddress custodia el stake de los validadores en jail (incluidos los que tienen tombstone)
+	UnbondedPoolAddress = "0x0000000000000000000000000000000000000103"
+	// CommunityPoolAddress recibe el stake descontado por slashes
+	CommunityPoolAddress = "0x0000000000000000000000000000000000000105"
+)
+
+// ModuleAccount es una cuenta de módulo con su nombre en reportes y métricas
+type ModuleAccount struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// ModuleAccounts retorna las cuentas de módulo; su balance no se considera circulante
+func ModuleAccounts() []ModuleAccount {
+	return []ModuleAccount{
+		{Name: "bonded-pool", Address: BondedPoolAddress},
+		{Name: "unbonded-pool", Address: UnbondedPoolAddress},
+		{Name: "rewards", Address: RewardsAddress},
+		{Name: "fee-collector", Address: execution.FeeCollectorAddress},
+		{Name: "community-pool", Address: CommunityPoolAddress},
+	}
+}
+
+// ModuleAccountAddresses retorna las direcciones de las cuentas de módulo (ver GetSupply)
+func ModuleAccountAddresses() []string {
+	accounts := ModuleAccounts()
+	addresses := make([]string, len(accounts))
+	for i, account := range accounts {
+		addresses[i] = account.Address
+	}
+	return addresses
+}
+
+// moduleTargets es lo que la contabilidad del ValidatorSet espera en cada cuenta de módulo
+type moduleTargets struct {
+	Bonded   *big.Int // Stake de los validadores fuera de jail
+	Unbonded *big.Int // Stake de los validadores en jail
+	Rewards  *big.Int // Recompensas y comisiones pendientes de retiro (la custodia puede tener más por redondeo)
+}
+
+// ModuleTargets calcula los balances que deben tener las cuentas de módulo según el set de validadores
+func (vs *ValidatorSet) ModuleTargets() moduleTargets {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+
+	targets := moduleTargets{Bonded: new(big.Int), Unbonded: new(big.Int), Rewards: new(big.Int)}
+	for _, v := range vs.validators {
+		if v.Jailed {
+			targets.Unbonded.Add(targets.Unbonded, v.Stake)
+		} else {
+			targets.Bonded.Add(targets.Bonded, v.Stake)
+		}
+		for _, d := range v.Delegations {
+			// Igual que PendingRewards: lo devengado menos lo ya liquidado, más lo acumulado
+			pending := new(big.Int).Mul(d.Shares, v.RewardPerShare)
+			pending.Sub(pending, d.RewardDebt)
+			pending.Div(pending, rewardPrecision)
+			targets.Rewards.Add(targets.Rewards, pending.Add(pending, d.Accrued))
+		}
+		if v.AccumulatedCommission != nil {
+			targets.Rewards.Add(targets.Rewards, v.AccumulatedCommission)
+		}
+	}
+	for _, unclaimed := range vs.unclaimedRewards {
+		targets.Rewards.Add(targets.Rewards, unclaimed)
+	}
+	return targets
+}
+
+// releaseStake transfiere stake liberado (unstake) a su dueño desde los pools de staking:
+// primero el bonded pool y el resto del unbonded pool (un validador en jail ya está en el unbonded
+// pool salvo que haya entrado a jail en este mismo bloque)
+func (app *ABCIApp) releaseStake(to string, amount *big.Int) error {
+	fromBonded := new(big.Int).Set(amount)
+	if available := app.executor.GetBalance(BondedPoolAddress); available.Cmp(fromBonded) < 0 {
+		fromBonded.Set(available)
+	}
+	if fromBonded.Sign() > 0 {
+		if err := app.executor.TransferBalance(BondedPoolAddress, to, fromBonded); err != nil {
+			return err
+		}
+	}
+	if rest := new(big.Int).Sub(amount, fromBonded); rest.Sign() > 0 {
+		return app.executor.TransferBalance(UnbondedPoolAddress, to, rest)
+	}
+	return nil
+}
+
+// settleModuleAccounts cierra la contabilidad del bloque: quema los fees del fee collector, mueve
+// entre el bonded y el unbonded pool el stake de los validadores que entraron o salieron de jail,
+// y pasa al community pool el stake descontado por slashes (lo que sobra en los pools)
+func (app *ABCIApp) settleModuleAccounts() {
+	if _, err := app.executor.BurnCollectedFees(); err != nil {
+		consensusLog.Errorf("Error quemando fees del bloque: %v", err)
+	}
+	if app.validators == nil {
+		return
+	}
+
+	targets := app.validators.ModuleTargets()
+	bondedExcess := new(big.Int).Sub(app.executor.GetBalance(BondedPoolAddress), targets.Bonded)
+	unbondedExcess := new(big.Int).Sub(app.executor.GetBalance(UnbondedPoolAddress), targets.Unbonded)
+
+	// Jail y unjail: lo que sobra en un pool cubre lo que falta en el otro
+	if bondedExcess.Sign() > 0 && unbondedExcess.Sign() < 0 {
+		moved := minBig(bondedExcess, new(big.Int).Neg(unbondedExcess))
+		app.moveModuleFunds(BondedPoolAddress, UnbondedPoolAddress, moved)
+		bondedExcess.Sub(bondedExcess, moved)
+		unbondedExcess.Add(unbondedExcess, moved)
+	} else if unbondedExcess.Sign() > 0 && bondedExcess.Sign() < 0 {
+		moved := minBig(unbondedExcess, new(big.Int).Neg(bondedExcess))
+		app.moveModuleFunds(UnbondedPoolAddress, BondedPoolAddress, moved)
+		unbondedExcess.Sub(unbondedExcess, moved)
+		bondedExcess.Add(bondedExcess, moved)
+	}
+
+	// Slashes: el stake descontado sigue en los pools hasta este punto
+	if bondedExcess.Sign() > 0 {
+		app.moveModuleFunds(BondedPoolAddress, CommunityPoolAddress, bondedExcess)
+	}
+	if unbondedExcess.Sign() > 0 {
+		app.moveModuleFunds(UnbondedPoolAddress, CommunityPoolAddress, unbondedExcess)
+	}
+	if bondedExcess.Sign() < 0 || unbondedExcess.Sign() < 0 {
+		consensusLog.Errorf("Pools de staking sin fondos suficientes para el stake registrado (bonded %s, unbonded %s)",
+			bondedExcess.String(), unbondedExcess.String())
+	}
+}
+
+// moveModuleFunds transfiere fondos entre cuentas de módulo
+func (app *ABCIApp) moveModuleFunds(from, to string, amount *big.Int) {
+	if err := app.executor.TransferBalance(from, to, amount); err != nil {
+		consensusLog.Errorf("Error moviendo %s de %s a %s: %v", amount.String(), from, to, err)
+	}
+}
+
+// minBig retorna el menor de dos montos
+func minBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
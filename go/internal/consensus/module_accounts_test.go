@@ -0,0 +1,95 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// TestABCIApp_SettleModuleAccounts prueba que el cierre de bloque mueva el stake en jail al unbonded pool,
+// envíe el slash al community pool y deje las invariantes contables en orden
+func TestABCIApp_SettleModuleAccounts(t *testing.T) {
+	testDir := createValidatorTestDir("module_accounts")
+	defer func() {
+		if err := cleanupValidatorTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+
+	oxg := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	validatorSet := NewValidatorSet(db, evm, new(big.Int).Mul(big.NewInt(1000), oxg), 100)
+	err = validatorSet.InitializeGenesisValidators([]GenesisValidator{
+		{Address: "0x1111111111111111111111111111111111111111", PubKey: make([]byte, 32), Stake: new(big.Int).Mul(big.NewInt(5000), oxg)},
+		{Address: "0x2222222222222222222222222222222222222222", PubKey: make([]byte, 32), Stake: new(big.Int).Mul(big.NewInt(3000), oxg)},
+	})
+	if err != nil {
+		t.Fatalf("Error inicializando validadores: %v", err)
+	}
+	app := NewABCIApp(db, evm, validatorSet, "test-chain")
+
+	// Slash del 10% con jail: 2700 pasan al unbonded pool y 300 al community pool
+	validatorSet.SetBlockInfo(10, time.Unix(1700000000, 0).UTC())
+	if err := validatorSet.Slash("0x2222222222222222222222222222222222222222", 10, time.Hour); err != nil {
+		t.Fatalf("Error slasheando validador: %v", err)
+	}
+	app.settleModuleAccounts()
+
+	expected := map[string]int64{BondedPoolAddress: 5000, UnbondedPoolAddress: 2700, CommunityPoolAddress: 300}
+	for address, amount := range expected {
+		want := new(big.Int).Mul(big.NewInt(amount), oxg)
+		if balance := evm.GetBalance(address); balance.Cmp(want) != 0 {
+			t.Errorf("Balance de %s incorrecto: %s, esperado %s", address, balance, want)
+		}
+	}
+
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+	report, err := CheckInvariants(evm, validatorSet, nil)
+	if err != nil {
+		t.Fatalf("Error verificando invariantes: %v", err)
+	}
+	if len(report.Violations) != 0 {
+		t.Fatalf("No debería haber invariantes incumplidas: %v", report.Violations)
+	}
+	if report.TotalSupply != new(big.Int).Mul(big.NewInt(8000), oxg).String() || report.FreeBalances != "0" {
+		t.Errorf("Reporte incorrecto: %+v", report)
+	}
+
+	// El unstake paga primero desde el bonded pool y luego desde el unbonded pool
+	release := new(big.Int).Mul(big.NewInt(6000), oxg)
+	if err := app.releaseStake("0x3333333333333333333333333333333333333333", release); err != nil {
+		t.Fatalf("Error liberando stake: %v", err)
+	}
+	if evm.GetBalance(BondedPoolAddress).Sign() != 0 || evm.GetBalance(UnbondedPoolAddress).Cmp(new(big.Int).Mul(big.NewInt(1700), oxg)) != 0 {
+		t.Errorf("Stake liberado desde los pools incorrectos: bonded %s, unbonded %s",
+			evm.GetBalance(BondedPoolAddress), evm.GetBalance(UnbondedPoolAddress))
+	}
+
+	// Fondos en un pool que el set de validadores no registra se reportan
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+	report, err = CheckInvariants(evm, validatorSet, nil)
+	if err != nil {
+		t.Fatalf("Error verificando invariantes: %v", err)
+	}
+	if len(report.Violations) != 2 {
+		t.Errorf("Deberían reportarse el bonded y el unbonded pool: %v", report.Violations)
+	}
+}
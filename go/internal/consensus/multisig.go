@@ -0,0 +1,80 @@
+package consensus
+
+import (
+	"strings"
+
+	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MultisigAuth autoriza una transacción de una cuenta multisig: los owners y el umbral de los que se deriva
+// la dirección (Transaction.From) y al menos Threshold firmas de owners distintos sobre el hash de la
+// transacción. Las firmas no forman parte del hash, así que se recolectan por separado
+type MultisigAuth struct {
+	Threshold  uint64              `json:"threshold"`
+	Owners     []string            `json:"owners"`
+	Signatures []MultisigSignature `json:"signatures"`
+}
+
+// MultisigSignature es la firma de un owner sobre el hash de una transacción multisig
+type MultisigSignature struct {
+	Signer    string `json:"signer"`
+	Signature []byte `json:"signature"`
+}
+
+// isMultisigTx retorna si la transacción se autoriza con firmas de una multisig
+func isMultisigTx(tx *Transaction) bool {
+	return tx.Multisig != nil
+}
+
+// validateMultisigAuth verifica la autorización de una transacción multisig sin depender del estado:
+// que From sea la dirección derivada de owners y umbral, que el hash corresponda a los campos y que haya al
+// menos Threshold firmas válidas de owners distintos
+func validateMultisigAuth(tx *Transaction) error {
+	auth := tx.Multisig
+	if len(tx.Signature) > 0 {
+		return NewTxError(CodeInvalidSignature, "una transacción multisig no lleva signature: las firmas van en multisig.signatures")
+	}
+
+	address, err := cryptosigner.MultisigAddress(auth.Owners, auth.Threshold)
+	if err != nil {
+		return NewTxError(CodeInvalidSignature, "multisig inválida: %v", err)
+	}
+	if !strings.EqualFold(tx.From, address.Hex()) {
+		return NewTxError(CodeInvalidSignature, "from %s no es la dirección de la multisig (%s)", tx.From, address.Hex())
+	}
+
+	expectedHash, err := signedFields(tx).Hash()
+	if err != nil {
+		return NewTxError(CodeInvalidHash, "error calculando hash de transacción: %v", err)
+	}
+	if tx.Hash != expectedHash.Hex() {
+		return NewTxError(CodeInvalidHash, "hash de transacción inválido: esperado %s, tiene %s", expectedHash.Hex(), tx.Hash)
+	}
+
+	owners := make(map[common.Address]bool, len(auth.Owners))
+	for _, owner := range auth.Owners {
+		owners[common.HexToAddress(owner)] = true
+	}
+	signed := make(map[common.Address]bool, len(auth.Signatures))
+	for i, sig := range auth.Signatures {
+		if !common.IsHexAddress(sig.Signer) {
+			return NewTxError(CodeInvalidSignature, "firma %d: firmante inválido: %s", i, sig.Signer)
+		}
+		signer := common.HexToAddress(sig.Signer)
+		if !owners[signer] {
+			return NewTxError(CodeInvalidSignature, "firma %d: %s no es owner de la multisig", i, signer.Hex())
+		}
+		if signed[signer] {
+			return NewTxError(CodeInvalidSignature, "firma %d: %s ya firmó", i, signer.Hex())
+		}
+		if err := cryptosigner.VerifyHashSignature(expectedHash, sig.Signature, signer); err != nil {
+			return NewTxError(CodeInvalidSignature, "firma %d de %s: %v", i, signer.Hex(), err)
+		}
+		signed[signer] = true
+	}
+	if uint64(len(signed)) < auth.Threshold {
+		return NewTxError(CodeInvalidSignature, "multisig con %d de %d firmas requeridas", len(signed), auth.Threshold)
+	}
+	return nil
+}
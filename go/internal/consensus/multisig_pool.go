@@ -0,0 +1,309 @@
+package consensus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Límites por defecto de las propuestas multisig
+const (
+	DefaultMaxMultisigProposals = 1000
+	DefaultMultisigProposalTTL  = 7 * 24 * time.Hour
+)
+
+// ErrMultisigProposalNotFound indica que no hay una propuesta pendiente con ese hash
+var ErrMultisigProposalNotFound = errors.New("propuesta multisig no encontrada")
+
+// MultisigProposal es una transacción multisig que todavía recolecta firmas de los owners
+type MultisigProposal struct {
+	Transaction *Transaction `json:"transaction"` // Multisig.Signatures tiene las firmas recolectadas
+	Ready       bool         `json:"ready"`       // Tiene al menos Threshold firmas y puede enviarse
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+// MultisigStore persiste las propuestas pendientes (BlockchainDB)
+type MultisigStore interface {
+	SaveMultisigProposals(data []byte) error
+	GetMultisigProposals() ([]byte, error)
+}
+
+// MultisigPool guarda las transacciones multisig propuestas mientras los owners agregan sus firmas
+// Las propuestas no pasan por el mempool: cuando reúnen el umbral se envían a submit-tx como cualquier
+// transacción, y se descartan al confirmarse en un bloque o al vencer
+type MultisigPool struct {
+	mu           sync.Mutex
+	store        MultisigStore
+	proposals    map[string]*MultisigProposal // hash -> propuesta
+	maxProposals int
+	ttl          time.Duration
+}
+
+// NewMultisigPool crea el pool y carga las propuestas guardadas
+func NewMultisigPool(store MultisigStore) (*MultisigPool, error) {
+	p := &MultisigPool{
+		store:        store,
+		proposals:    make(map[string]*MultisigProposal),
+		maxProposals: DefaultMaxMultisigProposals,
+		ttl:          DefaultMultisigProposalTTL,
+	}
+
+	if store != nil {
+		data, err := store.GetMultisigProposals()
+		if err != nil {
+			return nil, fmt.Errorf("error cargando propuestas multisig: %w", err)
+		}
+		if data != nil {
+			var proposals []*MultisigProposal
+			if err := json.Unmarshal(data, &proposals); err != nil {
+				return nil, fmt.Errorf("propuestas multisig guardadas inválidas: %w", err)
+			}
+			for _, proposal := range proposals {
+				p.proposals[proposal.Transaction.Hash] = proposal
+			}
+		}
+	}
+	return p, nil
+}
+
+// SetLimits configura la cantidad máxima de propuestas pendientes y su vigencia (0 = sin cambio)
+func (p *MultisigPool) SetLimits(maxProposals int, ttl time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if maxProposals > 0 {
+		p.maxProposals = maxProposals
+	}
+	if ttl > 0 {
+		p.ttl = ttl
+	}
+}
+
+// Propose registra una transacción multisig sin firmas (o con algunas ya recolectadas)
+// From se completa con la dirección de la multisig y Hash con el hash de los campos; si la transacción
+// ya estaba propuesta, retorna la existente
+func (p *MultisigPool) Propose(tx *Transaction) (*MultisigProposal, error) {
+	if tx.Multisig == nil {
+		return nil, NewTxError(CodeInvalidSignature, "falta multisig (owners y threshold)")
+	}
+	if len(tx.Signature) > 0 {
+		return nil, NewTxError(CodeInvalidSignature, "una transacción multisig no lleva signature: las firmas van en multisig.signatures")
+	}
+
+	address, err := cryptosigner.MultisigAddress(tx.Multisig.Owners, tx.Multisig.Threshold)
+	if err != nil {
+		return nil, NewTxError(CodeInvalidSignature, "multisig inválida: %v", err)
+	}
+	if tx.From == "" {
+		tx.From = address.Hex()
+	} else if !strings.EqualFold(tx.From, address.Hex()) {
+		return nil, NewTxError(CodeInvalidSignature, "from %s no es la dirección de la multisig (%s)", tx.From, address.Hex())
+	}
+
+	hash, err := signedFields(tx).Hash()
+	if err != nil {
+		return nil, NewTxError(CodeInvalidHash, "error calculando hash de transacción: %v", err)
+	}
+	if tx.Hash != "" && tx.Hash != hash.Hex() {
+		return nil, NewTxError(CodeInvalidHash, "hash de transacción inválido: esperado %s, tiene %s", hash.Hex(), tx.Hash)
+	}
+	tx.Hash = hash.Hex()
+
+	signatures := tx.Multisig.Signatures
+	tx.Multisig = &MultisigAuth{
+		Threshold:  tx.Multisig.Threshold,
+		Owners:     append([]string(nil), tx.Multisig.Owners...),
+		Signatures: []MultisigSignature{},
+	}
+	proposal := &MultisigProposal{Transaction: tx, CreatedAt: time.Now().UTC()}
+	for i, sig := range signatures {
+		if err := addMultisigSignature(tx, sig); err != nil {
+			return nil, fmt.Errorf("firma %d: %w", i, err)
+		}
+	}
+	proposal.Ready = multisigReady(tx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.expire()
+	if existing, ok := p.proposals[tx.Hash]; ok {
+		return existing.copy(), nil
+	}
+	if len(p.proposals) >= p.maxProposals {
+		return nil, NewTxError(CodeMempoolFull, "límite de propuestas multisig alcanzado (%d)", p.maxProposals)
+	}
+	p.proposals[tx.Hash] = proposal
+	if err := p.persist(); err != nil {
+		delete(p.proposals, tx.Hash)
+		return nil, err
+	}
+	return proposal.copy(), nil
+}
+
+// AddSignature agrega la firma de un owner a una propuesta (verificada contra el hash) y retorna la
+// propuesta actualizada; una firma repetida del mismo owner se ignora
+func (p *MultisigPool) AddSignature(hash string, sig MultisigSignature) (*MultisigProposal, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.expire()
+
+	proposal, ok := p.proposals[hash]
+	if !ok {
+		return nil, ErrMultisigProposalNotFound
+	}
+	before := len(proposal.Transaction.Multisig.Signatures)
+	if err := addMultisigSignature(proposal.Transaction, sig); err != nil {
+		return nil, err
+	}
+	if len(proposal.Transaction.Multisig.Signatures) == before {
+		return proposal.copy(), nil
+	}
+	proposal.Ready = multisigReady(proposal.Transaction)
+	if err := p.persist(); err != nil {
+		signatures := proposal.Transaction.Multisig.Signatures
+		proposal.Transaction.Multisig.Signatures = signatures[:before]
+		proposal.Ready = multisigReady(proposal.Transaction)
+		return nil, err
+	}
+	return proposal.copy(), nil
+}
+
+// Get retorna una propuesta pendiente por hash
+func (p *MultisigPool) Get(hash string) (*MultisigProposal, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.expire()
+	proposal, ok := p.proposals[hash]
+	if !ok {
+		return nil, false
+	}
+	return proposal.copy(), true
+}
+
+// List retorna las propuestas pendientes de una dirección multisig (todas si address es vacía),
+// de la más antigua a la más reciente
+func (p *MultisigPool) List(address string) []*MultisigProposal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.expire()
+	proposals := make([]*MultisigProposal, 0, len(p.proposals))
+	for _, proposal := range p.proposals {
+		if address == "" || strings.EqualFold(proposal.Transaction.From, address) {
+			proposals = append(proposals, proposal.copy())
+		}
+	}
+	sort.Slice(proposals, func(i, j int) bool { return proposals[i].CreatedAt.Before(proposals[j].CreatedAt) })
+	return proposals
+}
+
+// Remove descarta una propuesta pendiente
+func (p *MultisigPool) Remove(hash string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.proposals[hash]; !ok {
+		return ErrMultisigProposalNotFound
+	}
+	delete(p.proposals, hash)
+	return p.persist()
+}
+
+// HandleBlock descarta las propuestas cuyas transacciones se confirmaron (handler de AddBlockCommitHandler)
+func (p *MultisigPool) HandleBlock(block *Block) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	removed := false
+	for _, tx := range block.Transactions {
+		if tx == nil || !isMultisigTx(tx) {
+			continue
+		}
+		if _, ok := p.proposals[tx.Hash]; ok {
+			delete(p.proposals, tx.Hash)
+			removed = true
+		}
+	}
+	if removed {
+		if err := p.persist(); err != nil {
+			consensusLog.Warnf("Error guardando propuestas multisig: %v", err)
+		}
+	}
+}
+
+// copy retorna una copia de la propuesta que no cambia al agregarse firmas (se codifica fuera del lock)
+func (proposal *MultisigProposal) copy() *MultisigProposal {
+	tx := *proposal.Transaction
+	auth := *tx.Multisig
+	auth.Signatures = append([]MultisigSignature{}, auth.Signatures...)
+	tx.Multisig = &auth
+	return &MultisigProposal{Transaction: &tx, Ready: proposal.Ready, CreatedAt: proposal.CreatedAt}
+}
+
+// expire descarta las propuestas vencidas (requiere el lock); no persiste, el próximo cambio lo hace
+func (p *MultisigPool) expire() {
+	cutoff := time.Now().Add(-p.ttl)
+	for hash, proposal := range p.proposals {
+		if proposal.CreatedAt.Before(cutoff) {
+			delete(p.proposals, hash)
+		}
+	}
+}
+
+// persist guarda las propuestas (requiere el lock)
+func (p *MultisigPool) persist() error {
+	if p.store == nil {
+		return nil
+	}
+	proposals := make([]*MultisigProposal, 0, len(p.proposals))
+	for _, proposal := range p.proposals {
+		proposals = append(proposals, proposal)
+	}
+	data, err := json.Marshal(proposals)
+	if err != nil {
+		return fmt.Errorf("error codificando propuestas multisig: %w", err)
+	}
+	if err := p.store.SaveMultisigProposals(data); err != nil {
+		return fmt.Errorf("error guardando propuestas multisig: %w", err)
+	}
+	return nil
+}
+
+// addMultisigSignature verifica la firma de un owner sobre el hash de la transacción y la agrega
+// (una segunda firma del mismo owner se ignora)
+func addMultisigSignature(tx *Transaction, sig MultisigSignature) error {
+	if !common.IsHexAddress(sig.Signer) {
+		return NewTxError(CodeInvalidSignature, "firmante inválido: %s", sig.Signer)
+	}
+	signer := common.HexToAddress(sig.Signer)
+
+	isOwner := false
+	for _, owner := range tx.Multisig.Owners {
+		if common.HexToAddress(owner) == signer {
+			isOwner = true
+			break
+		}
+	}
+	if !isOwner {
+		return NewTxError(CodeInvalidSignature, "%s no es owner de la multisig", signer.Hex())
+	}
+	for _, existing := range tx.Multisig.Signatures {
+		if common.HexToAddress(existing.Signer) == signer {
+			return nil
+		}
+	}
+
+	if err := cryptosigner.VerifyHashSignature(common.HexToHash(tx.Hash), sig.Signature, signer); err != nil {
+		return NewTxError(CodeInvalidSignature, "firma de %s: %v", signer.Hex(), err)
+	}
+	tx.Multisig.Signatures = append(tx.Multisig.Signatures, MultisigSignature{Signer: signer.Hex(), Signature: sig.Signature})
+	return nil
+}
+
+// multisigReady retorna si la transacción tiene las firmas requeridas por su umbral
+func multisigReady(tx *Transaction) bool {
+	return uint64(len(tx.Multisig.Signatures)) >= tx.Multisig.Threshold
+}
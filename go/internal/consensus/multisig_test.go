@@ -0,0 +1,192 @@
+package consensus
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// memMultisigStore es un MultisigStore en memoria para tests
+type memMultisigStore struct {
+	data []byte
+}
+
+func (m *memMultisigStore) SaveMultisigProposals(data []byte) error { m.data = data; return nil }
+func (m *memMultisigStore) GetMultisigProposals() ([]byte, error)   { return m.data, nil }
+
+// newMultisigTestTx crea una transferencia sin firmas de una multisig 2 de 3 y las claves de sus owners
+func newMultisigTestTx(t *testing.T) (*Transaction, []*ecdsa.PrivateKey) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	owners := make([]string, 3)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("Error generando clave: %v", err)
+		}
+		keys[i] = key
+		owners[i] = crypto.PubkeyToAddress(key.PublicKey).Hex()
+	}
+	address, err := cryptosigner.MultisigAddress(owners, 2)
+	if err != nil {
+		t.Fatalf("Error derivando dirección: %v", err)
+	}
+
+	tx := &Transaction{
+		From:     address.Hex(),
+		To:       "0x0000000000000000000000000000000000000abc",
+		Value:    "1000",
+		GasLimit: 21000,
+		GasPrice: "1",
+		Multisig: &MultisigAuth{Threshold: 2, Owners: owners, Signatures: []MultisigSignature{}},
+	}
+	hash, err := signedFields(tx).Hash()
+	if err != nil {
+		t.Fatalf("Error calculando hash: %v", err)
+	}
+	tx.Hash = hash.Hex()
+	return tx, keys
+}
+
+// multisigTestSignature firma el hash de la transacción con la clave de un owner
+func multisigTestSignature(t *testing.T, tx *Transaction, key *ecdsa.PrivateKey) MultisigSignature {
+	signature, err := crypto.Sign(common.HexToHash(tx.Hash).Bytes(), key)
+	if err != nil {
+		t.Fatalf("Error firmando: %v", err)
+	}
+	return MultisigSignature{Signer: crypto.PubkeyToAddress(key.PublicKey).Hex(), Signature: signature}
+}
+
+// TestValidateMultisigAuth prueba que una transacción multisig requiera el umbral de firmas de owners distintos
+func TestValidateMultisigAuth(t *testing.T) {
+	tx, keys := newMultisigTestTx(t)
+
+	tx.Multisig.Signatures = []MultisigSignature{multisigTestSignature(t, tx, keys[0])}
+	if err := validateMultisigAuth(tx); ErrorCode(err, CodeOK) != CodeInvalidSignature {
+		t.Errorf("Se esperaba un error por firmas insuficientes: %v", err)
+	}
+
+	// La misma firma dos veces no cuenta como dos owners
+	tx.Multisig.Signatures = append(tx.Multisig.Signatures, tx.Multisig.Signatures[0])
+	if err := validateMultisigAuth(tx); err == nil {
+		t.Error("Se esperaba un error por firma repetida")
+	}
+
+	tx.Multisig.Signatures = []MultisigSignature{multisigTestSignature(t, tx, keys[0]), multisigTestSignature(t, tx, keys[2])}
+	if err := validateMultisigAuth(tx); err != nil {
+		t.Fatalf("Transacción multisig rechazada: %v", err)
+	}
+
+	// Firma de una clave que no es owner
+	outsider, _ := crypto.GenerateKey()
+	tx.Multisig.Signatures[1] = multisigTestSignature(t, tx, outsider)
+	if err := validateMultisigAuth(tx); err == nil {
+		t.Error("Se esperaba un error por firmante que no es owner")
+	}
+
+	// Cambiar un campo invalida el hash
+	tx.Multisig.Signatures[1] = multisigTestSignature(t, tx, keys[1])
+	tx.Value = "1001"
+	if err := validateMultisigAuth(tx); ErrorCode(err, CodeOK) != CodeInvalidHash {
+		t.Errorf("Se esperaba un error de hash: %v", err)
+	}
+	tx.Value = "1000"
+
+	// From debe ser la dirección derivada de owners y umbral
+	tx.Multisig.Threshold = 1
+	if err := validateMultisigAuth(tx); err == nil {
+		t.Error("Se esperaba un error por from distinto de la multisig")
+	}
+	tx.Multisig.Threshold = 2
+
+	tx.Signature = []byte{1}
+	if err := validateMultisigAuth(tx); err == nil {
+		t.Error("Se esperaba un error por signature en una transacción multisig")
+	}
+}
+
+// TestMultisigTransactionEncoding prueba que la autorización multisig se conserve en el registro canónico y
+// en types.proto
+func TestMultisigTransactionEncoding(t *testing.T) {
+	tx, keys := newMultisigTestTx(t)
+	tx.Multisig.Signatures = []MultisigSignature{multisigTestSignature(t, tx, keys[1])}
+
+	data, err := EncodeTransaction(tx)
+	if err != nil {
+		t.Fatalf("Error codificando transacción: %v", err)
+	}
+	decoded, err := DecodeTransaction(data)
+	if err != nil {
+		t.Fatalf("Error decodificando transacción: %v", err)
+	}
+	for _, converted := range []*Transaction{decoded, TransactionFromWire(tx.ToWire())} {
+		if converted.Multisig == nil || converted.Multisig.Threshold != 2 || len(converted.Multisig.Owners) != 3 ||
+			len(converted.Multisig.Signatures) != 1 || converted.Multisig.Signatures[0].Signer != tx.Multisig.Signatures[0].Signer {
+			t.Errorf("Autorización multisig perdida: %+v", converted.Multisig)
+		}
+	}
+}
+
+// TestMultisigPool prueba la recolección de firmas de una propuesta y su descarte al confirmarse
+func TestMultisigPool(t *testing.T) {
+	store := &memMultisigStore{}
+	pool, err := NewMultisigPool(store)
+	if err != nil {
+		t.Fatalf("Error creando pool: %v", err)
+	}
+
+	tx, keys := newMultisigTestTx(t)
+	hash := tx.Hash
+	tx.From, tx.Hash = "", ""
+	proposal, err := pool.Propose(tx)
+	if err != nil {
+		t.Fatalf("Error proponiendo transacción: %v", err)
+	}
+	if proposal.Transaction.Hash != hash || proposal.Ready {
+		t.Fatalf("Propuesta incorrecta: hash %s, ready %v", proposal.Transaction.Hash, proposal.Ready)
+	}
+
+	outsider, _ := crypto.GenerateKey()
+	if _, err := pool.AddSignature(hash, multisigTestSignature(t, proposal.Transaction, outsider)); err == nil {
+		t.Error("Se esperaba un error por firmante que no es owner")
+	}
+	if _, err := pool.AddSignature(hash, MultisigSignature{Signer: crypto.PubkeyToAddress(keys[0].PublicKey).Hex(), Signature: make([]byte, 65)}); err == nil {
+		t.Error("Se esperaba un error por firma inválida")
+	}
+	if _, err := pool.AddSignature(hash, multisigTestSignature(t, proposal.Transaction, keys[0])); err != nil {
+		t.Fatalf("Error agregando firma: %v", err)
+	}
+	// Una segunda firma del mismo owner se ignora
+	if proposal, _ = pool.AddSignature(hash, multisigTestSignature(t, proposal.Transaction, keys[0])); proposal.Ready {
+		t.Error("La firma repetida no debería completar el umbral")
+	}
+	proposal, err = pool.AddSignature(hash, multisigTestSignature(t, proposal.Transaction, keys[2]))
+	if err != nil || !proposal.Ready {
+		t.Fatalf("La propuesta debería estar lista: %v", err)
+	}
+	if err := validateMultisigAuth(proposal.Transaction); err != nil {
+		t.Errorf("La transacción de la propuesta no verifica: %v", err)
+	}
+
+	// Las propuestas se recuperan del store
+	reloaded, err := NewMultisigPool(store)
+	if err != nil {
+		t.Fatalf("Error recargando pool: %v", err)
+	}
+	if saved, ok := reloaded.Get(hash); !ok || !saved.Ready || len(saved.Transaction.Multisig.Signatures) != 2 {
+		t.Fatalf("Propuesta no recuperada: %+v", saved)
+	}
+	if list := reloaded.List(proposal.Transaction.From); len(list) != 1 {
+		t.Errorf("Se esperaba una propuesta de la multisig, hay %d", len(list))
+	}
+
+	reloaded.HandleBlock(&Block{Transactions: []*Transaction{proposal.Transaction}})
+	if _, ok := reloaded.Get(hash); ok {
+		t.Error("La propuesta confirmada debería descartarse")
+	}
+	if _, err := reloaded.AddSignature(hash, multisigTestSignature(t, proposal.Transaction, keys[1])); err != ErrMultisigProposalNotFound {
+		t.Errorf("Se esperaba ErrMultisigProposalNotFound: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+)
+
+// AppVersion es la versión de la aplicación reportada en Info y en /api/v1/node
+const AppVersion = "0.1.0"
+
+// Modos de operación del nodo
+const (
+	NodeModeValidator = "validator"
+	NodeModeFull      = "full"
+	NodeModeSeed      = "seed"
+	NodeModeSentry    = "sentry"  // Nodo completo público que protege a un validador privado
+	NodeModeReplica   = "replica" // Nodo completo de solo lectura para el API: sigue la cadena sin firmar nunca
+)
+
+// NodeInfo contiene la identidad y el estado del nodo para monitoreo y exploradores
+type NodeInfo struct {
+	NodeID           string    `json:"nodeId"`
+	Moniker          string    `json:"moniker"`
+	ChainID          string    `json:"chainId"`
+	AppVersion       string    `json:"appVersion"`
+	CometBFTVersion  string    `json:"cometbftVersion"`
+	LatestHeight     int64     `json:"latestHeight"`
+	LatestBlockHash  string    `json:"latestBlockHash"`
+	LatestAppHash    string    `json:"latestAppHash"`
+	LatestBlockTime  time.Time `json:"latestBlockTime"`
+	CatchingUp       bool      `json:"catchingUp"`
+	BlocksBehind     int64     `json:"blocksBehind"`
+	Peers            int       `json:"peers"`
+	ValidatorAddress string    `json:"validatorAddress"`
+	VotingPower      int64     `json:"votingPower"`
+	Mode             string    `json:"mode"`
+
+	Hardforks []execution.HardforkStatus `json:"hardforks"` // Calendario de hardforks de la EVM y su estado en LatestHeight
+}
+
+// rpcStatus es el subconjunto de la respuesta de /status de CometBFT que usamos
+type rpcStatus struct {
+	NodeInfo struct {
+		ID      string `json:"id"`
+		Network string `json:"network"`
+		Version string `json:"version"`
+		Moniker string `json:"moniker"`
+	} `json:"node_info"`
+	SyncInfo struct {
+		LatestBlockHash   string    `json:"latest_block_hash"`
+		LatestAppHash     string    `json:"latest_app_hash"`
+		LatestBlockHeight string    `json:"latest_block_height"`
+		LatestBlockTime   time.Time `json:"latest_block_time"`
+		CatchingUp        bool      `json:"catching_up"`
+	} `json:"sync_info"`
+	ValidatorInfo struct {
+		Address     string `json:"address"`
+		VotingPower string `json:"voting_power"`
+	} `json:"validator_info"`
+}
+
+// GetNodeInfo retorna la identidad, versiones y estado de sincronización del nodo
+func (c *CometBFT) GetNodeInfo(ctx context.Context) (*NodeInfo, error) {
+	if !c.running {
+		return nil, fmt.Errorf("consenso no está corriendo")
+	}
+
+	client, err := c.rpc()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("status falló: %w", err)
+	}
+
+	var status rpcStatus
+	if err := json.Unmarshal(result, &status); err != nil {
+		return nil, fmt.Errorf("error decodificando status: %w", err)
+	}
+
+	height, _ := strconv.ParseInt(status.SyncInfo.LatestBlockHeight, 10, 64)
+	votingPower, _ := strconv.ParseInt(status.ValidatorInfo.VotingPower, 10, 64)
+
+	// Un nodo seed no sigue la cadena y un sentry o una réplica no votan; fuera de esos roles,
+	// uno con poder de voto participa del consenso como validador
+	mode := NodeModeFull
+	switch {
+	case c.node.role == NodeModeSeed || c.node.role == NodeModeSentry || c.node.role == NodeModeReplica:
+		mode = c.node.role
+	case votingPower > 0:
+		mode = NodeModeValidator
+	}
+
+	info := &NodeInfo{
+		NodeID:           status.NodeInfo.ID,
+		Moniker:          status.NodeInfo.Moniker,
+		ChainID:          status.NodeInfo.Network,
+		AppVersion:       AppVersion,
+		CometBFTVersion:  status.NodeInfo.Version,
+		LatestHeight:     height,
+		LatestBlockHash:  status.SyncInfo.LatestBlockHash,
+		LatestAppHash:    status.SyncInfo.LatestAppHash,
+		LatestBlockTime:  status.SyncInfo.LatestBlockTime,
+		CatchingUp:       status.SyncInfo.CatchingUp,
+		ValidatorAddress: status.ValidatorInfo.Address,
+		VotingPower:      votingPower,
+		Mode:             mode,
+	}
+
+	// Peers y atraso respecto a la red (best-effort: si falla se reporta solo catchingUp)
+	if peers, err := c.peerCount(ctx, client); err == nil {
+		info.Peers = peers
+	}
+	if maxPeerHeight := c.maxPeerHeight(); maxPeerHeight > height {
+		info.BlocksBehind = maxPeerHeight - height
+	}
+	if c.executor != nil {
+		info.Hardforks = c.executor.HardforkSchedule().Status(uint64(height))
+	}
+
+	return info, nil
+}
@@ -0,0 +1,60 @@
+package consensus
+
+import (
+	"fmt"
+	"strings"
+
+	cometcfg "github.com/cometbft/cometbft/config"
+)
+
+// ParseNodeRole normaliza el rol configurado del nodo (vacío = nodo completo)
+func ParseNodeRole(role string) (string, error) {
+	switch role = strings.ToLower(strings.TrimSpace(role)); role {
+	case "":
+		return NodeModeFull, nil
+	case NodeModeFull, NodeModeSeed, NodeModeSentry, NodeModeValidator, NodeModeReplica:
+		return role, nil
+	default:
+		return "", fmt.Errorf("rol de nodo desconocido: %s (valores: full, seed, sentry, validator, replica)", role)
+	}
+}
+
+// applyNodeRole ajusta la configuración P2P de CometBFT según el rol del nodo:
+//   - seed: solo rastrea la red y comparte direcciones de peers (PEX), no sigue la cadena
+//   - sentry: nodo público con PEX que nunca anuncia ni pierde la conexión con los validadores privados
+//   - validator: detrás de sentries, solo se conecta a sus peers persistentes y no participa de PEX
+//   - full y replica: valores por defecto de CometBFT (la réplica además nunca firma, ver readOnlyPV)
+func applyNodeRole(p2p *cometcfg.P2PConfig, role string, privatePeerIDs string) error {
+	role, err := ParseNodeRole(role)
+	if err != nil {
+		return err
+	}
+
+	switch role {
+	case NodeModeSeed:
+		p2p.SeedMode = true
+		p2p.PexReactor = true
+
+	case NodeModeSentry:
+		if privatePeerIDs == "" {
+			return fmt.Errorf("el modo sentry requiere los IDs de los validadores privados (OXY_PRIVATE_PEER_IDS)")
+		}
+		p2p.SeedMode = false
+		p2p.PexReactor = true
+		// Los validadores no se anuncian por PEX y la conexión con ellos se mantiene aunque se llene el cupo de peers
+		p2p.PrivatePeerIDs = privatePeerIDs
+		p2p.UnconditionalPeerIDs = privatePeerIDs
+
+	case NodeModeValidator:
+		if p2p.PersistentPeers == "" {
+			return fmt.Errorf("el modo validator requiere sus sentries como peers persistentes (OXY_PERSISTENT_PEERS)")
+		}
+		p2p.SeedMode = false
+		p2p.PexReactor = false
+		p2p.Seeds = ""
+		// Los sentries suelen estar en la red privada del validador
+		p2p.AddrBookStrict = false
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package consensus
+
+import (
+	"testing"
+
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	cometcfg "github.com/cometbft/cometbft/config"
+)
+
+// TestParseNodeRole prueba la normalización del rol configurado
+func TestParseNodeRole(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":          NodeModeFull,
+		"full":      NodeModeFull,
+		" Sentry ":  NodeModeSentry,
+		"SEED":      NodeModeSeed,
+		"validator": NodeModeValidator,
+		"Replica":   NodeModeReplica,
+	} {
+		role, err := ParseNodeRole(input)
+		if err != nil || role != expected {
+			t.Errorf("ParseNodeRole(%q) = %q, %v; esperado %q", input, role, err, expected)
+		}
+	}
+
+	if _, err := ParseNodeRole("archivo"); err == nil {
+		t.Error("Rol desconocido debería rechazarse")
+	}
+}
+
+// TestApplyNodeRole prueba los ajustes P2P de cada rol
+func TestApplyNodeRole(t *testing.T) {
+	// Seed: solo PEX
+	p2p := cometcfg.DefaultP2PConfig()
+	if err := applyNodeRole(p2p, NodeModeSeed, ""); err != nil {
+		t.Fatalf("Error aplicando rol seed: %v", err)
+	}
+	if !p2p.SeedMode || !p2p.PexReactor {
+		t.Errorf("Seed debería tener seed_mode y PEX: %+v", p2p)
+	}
+
+	// Sentry: requiere los validadores privados y nunca los anuncia
+	p2p = cometcfg.DefaultP2PConfig()
+	if err := applyNodeRole(p2p, NodeModeSentry, ""); err == nil {
+		t.Error("Sentry sin validadores privados debería rechazarse")
+	}
+	if err := applyNodeRole(p2p, NodeModeSentry, "abcd"); err != nil {
+		t.Fatalf("Error aplicando rol sentry: %v", err)
+	}
+	if p2p.SeedMode || !p2p.PexReactor || p2p.PrivatePeerIDs != "abcd" || p2p.UnconditionalPeerIDs != "abcd" {
+		t.Errorf("Configuración de sentry incorrecta: %+v", p2p)
+	}
+
+	// Validator: solo peers persistentes, sin PEX ni seeds
+	p2p = cometcfg.DefaultP2PConfig()
+	p2p.Seeds = "seed@host:26656"
+	if err := applyNodeRole(p2p, NodeModeValidator, ""); err == nil {
+		t.Error("Validator sin sentries debería rechazarse")
+	}
+	p2p.PersistentPeers = "sentry@10.0.0.2:26656"
+	if err := applyNodeRole(p2p, NodeModeValidator, ""); err != nil {
+		t.Fatalf("Error aplicando rol validator: %v", err)
+	}
+	if p2p.PexReactor || p2p.Seeds != "" || p2p.AddrBookStrict {
+		t.Errorf("Configuración de validator incorrecta: %+v", p2p)
+	}
+
+	// Full: valores por defecto
+	p2p = cometcfg.DefaultP2PConfig()
+	if err := applyNodeRole(p2p, "", ""); err != nil {
+		t.Fatalf("Error aplicando rol full: %v", err)
+	}
+	if p2p.SeedMode || !p2p.PexReactor {
+		t.Errorf("Full debería mantener los valores por defecto: %+v", p2p)
+	}
+
+	// Replica: P2P de un nodo completo
+	p2p = cometcfg.DefaultP2PConfig()
+	if err := applyNodeRole(p2p, NodeModeReplica, ""); err != nil {
+		t.Fatalf("Error aplicando rol replica: %v", err)
+	}
+	if p2p.SeedMode || !p2p.PexReactor {
+		t.Errorf("Replica debería mantener los valores por defecto: %+v", p2p)
+	}
+}
+
+// TestReadOnlyPV prueba que el private validator de una réplica rechaza toda firma
+func TestReadOnlyPV(t *testing.T) {
+	pv := newReadOnlyPV()
+	pubKey, err := pv.GetPubKey()
+	if err != nil || pubKey == nil {
+		t.Fatalf("GetPubKey: %v", err)
+	}
+	if other, _ := newReadOnlyPV().GetPubKey(); other.Equals(pubKey) {
+		t.Error("Cada réplica debería usar una clave efímera distinta")
+	}
+	if err := pv.SignVote("oxy", &cmtproto.Vote{}, false); err == nil {
+		t.Error("SignVote debería fallar")
+	}
+	if err := pv.SignProposal("oxy", &cmtproto.Proposal{}); err == nil {
+		t.Error("SignProposal debería fallar")
+	}
+	if _, err := pv.SignBytes([]byte("x")); err == nil {
+		t.Error("SignBytes debería fallar")
+	}
+}
@@ -0,0 +1,81 @@
+package consensus
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// defaultP2PListenAddr es la dirección P2P por defecto de CometBFT
+const defaultP2PListenAddr = "tcp://0.0.0.0:26656"
+
+// P2PNodeID retorna el ID de nodo P2P de CometBFT (vacío si el nodo no está creado)
+func (c *CometBFT) P2PNodeID() string {
+	if c.node == nil {
+		return ""
+	}
+	return c.node.nodeID
+}
+
+// NodeRole retorna el rol del nodo: seed, sentry, validator, full o replica
+func (c *CometBFT) NodeRole() string {
+	if c.node != nil && c.node.role != "" {
+		return c.node.role
+	}
+	return c.config.NodeRole
+}
+
+// P2PAddress retorna la dirección "id@host:puerto" con la que otros nodos pueden conectarse a este
+// Usa P2PExternalAddr o, si no hay, la dirección de escucha; vacío si no se conoce un host alcanzable
+// (escucha en 0.0.0.0 sin dirección externa configurada)
+func (c *CometBFT) P2PAddress() string {
+	nodeID := c.P2PNodeID()
+	if nodeID == "" {
+		return ""
+	}
+	addr := c.config.P2PExternalAddr
+	if addr == "" {
+		addr = c.config.P2PListenAddr
+		if addr == "" {
+			addr = defaultP2PListenAddr
+		}
+	}
+	hostPort, err := dialableHostPort(addr)
+	if err != nil {
+		return ""
+	}
+	return nodeID + "@" + hostPort
+}
+
+// AddPersistentPeers agrega peers "id@host:puerto" como persistentes (se reconectan si se caen)
+// y los disca en segundo plano; es el equivalente en caliente de OXY_PERSISTENT_PEERS
+func (c *CometBFT) AddPersistentPeers(peers []string) error {
+	if !c.running || c.node == nil || c.node.node == nil {
+		return fmt.Errorf("consenso no está corriendo")
+	}
+	if len(peers) == 0 {
+		return nil
+	}
+	sw := c.node.node.Switch()
+	if err := sw.AddPersistentPeers(peers); err != nil {
+		return fmt.Errorf("error agregando peers persistentes: %w", err)
+	}
+	if err := sw.DialPeersAsync(peers); err != nil {
+		return fmt.Errorf("error conectando a peers: %w", err)
+	}
+	return nil
+}
+
+// dialableHostPort extrae "host:puerto" de una dirección P2P ("tcp://host:puerto" o "host:puerto")
+// Rechaza hosts comodín, que no sirven para que otro nodo se conecte
+func dialableHostPort(addr string) (string, error) {
+	addr = strings.TrimPrefix(addr, "tcp://")
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("dirección P2P inválida %q: %w", addr, err)
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		return "", fmt.Errorf("dirección P2P sin host alcanzable: %q", addr)
+	}
+	return net.JoinHostPort(host, port), nil
+}
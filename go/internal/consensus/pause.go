@@ -0,0 +1,101 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ContractPauseAddress es la dirección reservada que recibe las transacciones que pausan o reanudan contratos
+// (ver execution.PausedContractsAddress). Las envían los administradores del registro de deployers
+const ContractPauseAddress = execution.PausedContractsAddress
+
+// Acciones de pausa de contratos
+const (
+	ContractActionPause   = "pause"   // Las transacciones dirigidas a address fallan sin ejecutarse
+	ContractActionUnpause = "unpause" // Reanuda address
+)
+
+// ContractPausePayload es el contenido (JSON en Transaction.Data) de una transacción de pausa
+type ContractPausePayload struct {
+	Action  string `json:"action"`
+	Address string `json:"address"`
+}
+
+// unpausableAddresses son las cuentas de sistema que no se pueden pausar (pausar el propio registro de
+// pausas impediría reanudar)
+var unpausableAddresses = []string{ContractPauseAddress, DeployerRegistryAddress, StakingAddress}
+
+// isContractPauseTx retorna si la transacción va dirigida al registro de pausas
+func isContractPauseTx(tx *Transaction) bool {
+	return strings.EqualFold(tx.To, ContractPauseAddress)
+}
+
+// checkContractPause rechaza en CheckTx las transacciones a contratos pausados y las pausas de quien
+// no administra el registro de deployers
+func (app *ABCIApp) checkContractPause(tx *Transaction) error {
+	if app.executor == nil || tx.To == "" {
+		return nil
+	}
+	if paused, err := app.executor.IsCommittedContractPaused(tx.To); err == nil && paused {
+		return NewTxError(CodeContractPaused, "contrato pausado: %s", tx.To)
+	}
+	if isContractPauseTx(tx) {
+		status, err := app.executor.GetCommittedDeployerStatus(tx.From)
+		if err == nil && !status.Admin {
+			return NewTxError(CodeInvalidTx, "cuenta %s no puede pausar contratos", tx.From)
+		}
+	}
+	return nil
+}
+
+// applyContractPauseTx aplica una transacción de pausa ya ejecutada con éxito
+// Si la acción falla, el valor transferido al registro se devuelve al remitente
+func (app *ABCIApp) applyContractPauseTx(tx *Transaction) error {
+	err := app.executeContractPause(tx)
+	if err == nil {
+		return nil
+	}
+	if value, ok := new(big.Int).SetString(tx.Value, 10); ok && value.Sign() > 0 {
+		if refundErr := app.executor.TransferBalance(ContractPauseAddress, tx.From, value); refundErr != nil {
+			return fmt.Errorf("%v (error devolviendo fondos: %v)", err, refundErr)
+		}
+	}
+	return err
+}
+
+// executeContractPause decodifica el payload y pausa o reanuda el contrato (solo administradores)
+func (app *ABCIApp) executeContractPause(tx *Transaction) error {
+	if value, ok := new(big.Int).SetString(tx.Value, 10); ok && value.Sign() > 0 {
+		return fmt.Errorf("las transacciones de pausa no llevan valor")
+	}
+	if app.executor.DeployerRoles(tx.From)&execution.DeployerRoleAdmin == 0 {
+		return fmt.Errorf("cuenta %s no puede pausar contratos", tx.From)
+	}
+
+	var payload ContractPausePayload
+	if err := json.Unmarshal(tx.Data, &payload); err != nil {
+		return fmt.Errorf("payload de pausa inválido: %w", err)
+	}
+	if payload.Action != ContractActionPause && payload.Action != ContractActionUnpause {
+		return fmt.Errorf("acción de pausa desconocida: %q", payload.Action)
+	}
+	if !common.IsHexAddress(payload.Address) {
+		return fmt.Errorf("dirección inválida: %q", payload.Address)
+	}
+	for _, reserved := range unpausableAddresses {
+		if strings.EqualFold(payload.Address, reserved) {
+			return fmt.Errorf("la cuenta de sistema %s no se puede pausar", payload.Address)
+		}
+	}
+
+	if err := app.executor.SetContractPaused(payload.Address, payload.Action == ContractActionPause); err != nil {
+		return err
+	}
+	consensusLog.Warnf("Contrato %s: %s (por %s)", payload.Address, payload.Action, tx.From)
+	return nil
+}
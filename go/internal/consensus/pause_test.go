@@ -0,0 +1,82 @@
+package consensus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// TestContractPause prueba la pausa de contratos por los administradores y el rechazo en CheckTx
+func TestContractPause(t *testing.T) {
+	testDir := createValidatorTestDir("pause")
+	defer func() {
+		if err := cleanupValidatorTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+	app := NewABCIApp(db, evm, nil, "test-chain")
+
+	const (
+		admin    = "0x1111111111111111111111111111111111111111"
+		user     = "0x3333333333333333333333333333333333333333"
+		contract = "0x4444444444444444444444444444444444444444"
+	)
+	if err := app.initGenesisDeployers([]byte(`{"deployers":{"admins":["` + admin + `"]}}`)); err != nil {
+		t.Fatalf("Error aplicando la política del genesis: %v", err)
+	}
+
+	pause := func(from, action, address string) error {
+		data, _ := json.Marshal(ContractPausePayload{Action: action, Address: address})
+		return app.applyContractPauseTx(&Transaction{From: from, To: ContractPauseAddress, Value: "0", Data: data})
+	}
+	if err := pause(user, ContractActionPause, contract); err == nil {
+		t.Error("Una cuenta sin rol de administrador no debería pausar contratos")
+	}
+	if err := pause(admin, ContractActionPause, DeployerRegistryAddress); err == nil {
+		t.Error("Las cuentas de sistema no deberían poder pausarse")
+	}
+	if err := pause(admin, ContractActionPause, contract); err != nil {
+		t.Fatalf("Error pausando contrato: %v", err)
+	}
+	if !evm.ContractPaused(contract) {
+		t.Error("El contrato debería estar pausado en el bloque en ejecución")
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	// CheckTx
+	if err := app.checkContractPause(&Transaction{From: user, To: contract}); ErrorCode(err, CodeOK) != CodeContractPaused {
+		t.Errorf("Se esperaba CodeContractPaused: %v", err)
+	}
+	if err := app.checkContractPause(&Transaction{From: user, To: ContractPauseAddress}); err == nil {
+		t.Error("Solo los administradores pausan contratos")
+	}
+	if err := app.checkContractPause(&Transaction{From: user, To: user}); err != nil {
+		t.Errorf("Las transferencias a otras cuentas no se restringen: %v", err)
+	}
+
+	if err := pause(admin, ContractActionUnpause, contract); err != nil {
+		t.Fatalf("Error reanudando contrato: %v", err)
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+	if paused, err := evm.IsCommittedContractPaused(contract); err != nil || paused {
+		t.Errorf("El contrato debería estar reanudado: paused=%v err=%v", paused, err)
+	}
+}
@@ -0,0 +1,171 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"runtime/debug"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/metrics"
+)
+
+// txSnapshot es el estado previo a aplicar una transacción: si la transacción entra en pánico se
+// restaura, así que falla sin dejar cambios a medias
+type txSnapshot struct {
+	evm         int                   // Revisión del StateDB en ejecución (-1 = sin ejecutor)
+	validators  *validatorSetSnapshot // nil si la transacción no modifica el ValidatorSet
+	stakeEvents int                   // Eventos de staking pendientes antes de la transacción
+}
+
+// takeTxSnapshot toma el snapshot previo a una transacción (withValidators: también el ValidatorSet)
+func (app *ABCIApp) takeTxSnapshot(withValidators bool) *txSnapshot {
+	snap := &txSnapshot{evm: -1}
+	if app.executor != nil {
+		snap.evm = app.executor.Snapshot()
+	}
+	if withValidators && app.validators != nil {
+		validators, err := app.validators.snapshot()
+		if err != nil {
+			consensusLog.Warn("Error copiando el set de validadores: " + err.Error())
+		}
+		snap.validators = validators
+	}
+	app.stakeEventsMutex.Lock()
+	snap.stakeEvents = len(app.pendingStakeEvents)
+	app.stakeEventsMutex.Unlock()
+	return snap
+}
+
+// revertTxSnapshot deshace los cambios posteriores al snapshot: estado EVM, ValidatorSet y eventos de staking
+func (app *ABCIApp) revertTxSnapshot(ctx context.Context, txHash string, snap *txSnapshot) {
+	if snap.evm >= 0 {
+		if err := app.executor.RevertToSnapshot(snap.evm); err != nil {
+			consensusLog.Ctx(ctx).Error().Err(err).Str("tx_hash", txHash).Msg("Error deshaciendo el estado EVM de la transacción")
+		}
+	}
+	if snap.validators != nil {
+		if err := app.validators.restore(snap.validators); err != nil {
+			consensusLog.Ctx(ctx).Error().Err(err).Str("tx_hash", txHash).Msg("Error deshaciendo el set de validadores de la transacción")
+		}
+	}
+	app.stakeEventsMutex.Lock()
+	if len(app.pendingStakeEvents) > snap.stakeEvents {
+		app.pendingStakeEvents = app.pendingStakeEvents[:snap.stakeEvents]
+	}
+	app.stakeEventsMutex.Unlock()
+}
+
+// recoverTxPanic convierte un pánico durante la ejecución de una transacción en un error con
+// CodeInternalError, registrando el stack trace. Se usa en defer con el error de retorno de la función
+// Los cambios que la transacción alcanzó a hacer se deshacen con el snapshot tomado antes de aplicarla:
+// falla de forma atómica, igual en todos los validadores
+func (app *ABCIApp) recoverTxPanic(ctx context.Context, txHash string, snap *txSnapshot, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	consensusLog.Ctx(ctx).Error().
+		Str("tx_hash", txHash).
+		Str("panic", fmt.Sprint(r)).
+		Str("stack", string(debug.Stack())).
+		Msg("Pánico recuperado ejecutando transacción")
+	app.revertTxSnapshot(ctx, txHash, snap)
+	if app.metrics != nil {
+		app.metrics.IncrementPanics(metrics.PanicComponentABCI)
+	}
+	*err = NewTxError(CodeInternalError, "error interno ejecutando transacción %s: %v", txHash, r)
+}
+
+// executeTransactionSafe ejecuta la transacción en la EVM; un pánico hace fallar solo esta transacción
+func (app *ABCIApp) executeTransactionSafe(ctx context.Context, tx *execution.Transaction) (result *execution.ExecutionResult, err error) {
+	defer app.recoverTxPanic(ctx, tx.Hash, app.takeTxSnapshot(false), &err)
+	return app.executor.ExecuteTransaction(tx)
+}
+
+// applyStakingTxSafe aplica una transacción de staking; si la acción entra en pánico se deshacen sus cambios
+// y falla como una acción rechazada (el valor en custodia se devuelve al remitente)
+func (app *ABCIApp) applyStakingTxSafe(ctx context.Context, tx *Transaction) error {
+	return app.refundFailedStake(tx, app.executeStakingActionSafe(ctx, tx))
+}
+
+// executeStakingActionSafe ejecuta la acción de staking; un pánico deshace lo que modificó en el estado
+// EVM y en el ValidatorSet
+func (app *ABCIApp) executeStakingActionSafe(ctx context.Context, tx *Transaction) (err error) {
+	defer app.recoverTxPanic(ctx, tx.Hash, app.takeTxSnapshot(true), &err)
+	return app.executeStakingAction(tx)
+}
+
+// applyDeployerTxSafe aplica una transacción al registro de deployers; un pánico hace fallar solo esta transacción
+func (app *ABCIApp) applyDeployerTxSafe(ctx context.Context, tx *Transaction) (err error) {
+	defer app.recoverTxPanic(ctx, tx.Hash, app.takeTxSnapshot(false), &err)
+	return app.applyDeployerTx(tx)
+}
+
+// applyContractPauseTxSafe aplica una transacción de pausa; un pánico hace fallar solo esta transacción
+func (app *ABCIApp) applyContractPauseTxSafe(ctx context.Context, tx *Transaction) (err error) {
+	defer app.recoverTxPanic(ctx, tx.Hash, app.takeTxSnapshot(false), &err)
+	return app.applyContractPauseTx(tx)
+}
+
+// applyVestingTxSafe aplica una transacción de vesting; un pánico hace fallar solo esta transacción
+func (app *ABCIApp) applyVestingTxSafe(ctx context.Context, tx *Transaction) (err error) {
+	defer app.recoverTxPanic(ctx, tx.Hash, app.takeTxSnapshot(false), &err)
+	return app.applyVestingTx(tx)
+}
+
+// validatorSetSnapshot es una copia de lo que modifican las transacciones de staking en el ValidatorSet
+type validatorSetSnapshot struct {
+	validators       map[string][]byte // Validador serializado como en su registro de storage
+	unclaimedRewards map[string]*big.Int
+	setChanges       map[string][]byte
+}
+
+// snapshot copia los validadores, las recompensas sin reclamar y los cambios del set activo
+func (vs *ValidatorSet) snapshot() (*validatorSetSnapshot, error) {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+
+	snap := &validatorSetSnapshot{
+		validators:       make(map[string][]byte, len(vs.validators)),
+		unclaimedRewards: make(map[string]*big.Int, len(vs.unclaimedRewards)),
+		setChanges:       make(map[string][]byte, len(vs.setChanges)),
+	}
+	for address, v := range vs.validators {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("error serializando validador %s: %w", address, err)
+		}
+		snap.validators[address] = data
+	}
+	for address, amount := range vs.unclaimedRewards {
+		snap.unclaimedRewards[address] = new(big.Int).Set(amount)
+	}
+	for address, pubKey := range vs.setChanges {
+		snap.setChanges[address] = pubKey
+	}
+	return snap, nil
+}
+
+// restore vuelve el ValidatorSet al snapshot y guarda los registros que cambiaron
+func (vs *ValidatorSet) restore(snap *validatorSetSnapshot) error {
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+
+	validators := make(map[string]*Validator, len(snap.validators))
+	for address, data := range snap.validators {
+		var v Validator
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("error restaurando validador %s: %w", address, err)
+		}
+		v.initRewards()
+		validators[address] = &v
+	}
+	vs.validators = validators
+	vs.unclaimedRewards = snap.unclaimedRewards
+	vs.setChanges = snap.setChanges
+
+	vs.saveUnclaimedRewards()
+	return vs.saveValidatorsLocked()
+}
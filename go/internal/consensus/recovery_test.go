@@ -0,0 +1,101 @@
+package consensus
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/metrics"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// TestExecuteTransactionSafe prueba que un pánico del ejecutor se convierta en un error con CodeInternalError
+func TestExecuteTransactionSafe(t *testing.T) {
+	// Sin ejecutor la llamada entra en pánico (nil pointer)
+	app := &ABCIApp{metrics: metrics.NewMetrics()}
+
+	result, err := app.executeTransactionSafe(context.Background(), &execution.Transaction{Hash: "0xabc"})
+	if result != nil || err == nil {
+		t.Fatalf("Se esperaba un error: result=%v", result)
+	}
+	if code := ErrorCode(err, CodeExecutionError); code != CodeInternalError {
+		t.Errorf("Código incorrecto: obtenido %d (%v)", code, err)
+	}
+	if panics := app.metrics.GetMetrics().PanicsRecovered; panics[metrics.PanicComponentABCI] != 1 {
+		t.Errorf("El pánico debería contarse en las métricas: %v", panics)
+	}
+}
+
+// TestApplyStakingTxSafe_RevertsOnPanic prueba que una transacción de staking que entra en pánico
+// no deje cambios: ni en el estado EVM ni en el set de validadores ni en los eventos pendientes
+func TestApplyStakingTxSafe_RevertsOnPanic(t *testing.T) {
+	testDir := createTestDir("staking_tx_panic")
+	defer cleanupTestDir(testDir)
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+
+	validators := NewValidatorSet(db, evm, big.NewInt(100), 10)
+	app := NewABCIApp(db, evm, validators, "test-chain")
+
+	// El callback de stake registra el evento, modifica el estado EVM y entra en pánico:
+	// el validador ya quedó registrado en memoria cuando ocurre el pánico
+	other := "0x5555555555555555555555555555555555555555"
+	validators.SetStakeChangeHandler(func(address string, amount *big.Int, action string) {
+		app.recordStakeEvent(address, amount, action)
+		if err := evm.FundAccount(other, "42"); err != nil {
+			t.Errorf("Error fondeando cuenta: %v", err)
+		}
+		panic("fallo en el callback de stake")
+	})
+
+	sender := "0x4444444444444444444444444444444444444444"
+	if err := evm.FundAccount(StakingAddress, "1000"); err != nil {
+		t.Fatalf("Error fondeando custodia: %v", err)
+	}
+	bond := &Transaction{
+		Hash:  "0xbond",
+		From:  sender,
+		To:    StakingAddress,
+		Value: "1000",
+		Data:  []byte(`{"action":"bond","pubKey":"` + strings.Repeat("ab", 32) + `"}`),
+	}
+	err = app.applyStakingTxSafe(context.Background(), bond)
+	if code := ErrorCode(err, CodeExecutionError); err == nil || code != CodeInternalError {
+		t.Fatalf("Se esperaba un error interno: código %d (%v)", code, err)
+	}
+
+	if _, err := validators.GetValidator(sender); err == nil {
+		t.Error("El validador no debería quedar registrado")
+	}
+	if events := app.takeStakeEvents(); len(events) != 0 {
+		t.Errorf("No deberían quedar eventos de staking: %+v", events)
+	}
+	state, err := evm.GetState(other)
+	if err != nil {
+		t.Fatalf("Error obteniendo estado: %v", err)
+	}
+	if state.Balance != "0" {
+		t.Errorf("El cambio al estado EVM debería deshacerse: balance %s", state.Balance)
+	}
+
+	// La transacción falla como una acción rechazada: el valor en custodia vuelve al remitente
+	state, err = evm.GetState(sender)
+	if err != nil {
+		t.Fatalf("Error obteniendo estado: %v", err)
+	}
+	if state.Balance != "1000" {
+		t.Errorf("El valor debería devolverse al remitente: balance %s", state.Balance)
+	}
+}
@@ -0,0 +1,213 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/Q-YZX0/oxy-blockchain/internal/watchlist"
+)
+
+// chainHeadFile guarda el último bloque confirmado fuera de blockchain.db, para detectar al iniciar que la
+// cadena guardada retrocedió (blockchain.db borrado o restaurado de un backup o snapshot anterior)
+const chainHeadFile = "chain_head.json"
+
+// reorgCheckDepth es la cantidad de bloques recientes cuyo ParentHash se verifica al iniciar
+const reorgCheckDepth = 64
+
+// ChainHead es el último bloque confirmado por el nodo
+type ChainHead struct {
+	Height uint64 `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+// ChainReorg describe un retroceso de la cadena guardada respecto de lo ya confirmado a los clientes
+type ChainReorg struct {
+	Height    uint64 // Primera altura invalidada
+	OldHeight uint64 // Último bloque confirmado antes del reinicio (0 si no hay registro)
+	OldHash   string
+	NewHeight uint64 // Último bloque guardado
+	NewHash   string
+	Reason    string
+}
+
+// Error describe el retroceso para rechazar el inicio del nodo
+func (r *ChainReorg) Error() string {
+	return fmt.Sprintf("la cadena retrocedió desde la altura %d: %s", r.Height, r.Reason)
+}
+
+// ReadChainHead lee el último bloque confirmado registrado en dataDir (nil si no hay registro)
+func ReadChainHead(dataDir string) (*ChainHead, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, chainHeadFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo %s: %w", chainHeadFile, err)
+	}
+	var head ChainHead
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("%s inválido: %w", chainHeadFile, err)
+	}
+	return &head, nil
+}
+
+// writeChainHead registra el último bloque confirmado (escritura atómica con rename)
+func writeChainHead(dataDir string, head ChainHead) error {
+	data, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dataDir, chainHeadFile)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("error guardando %s: %w", chainHeadFile, err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// DetectReorg compara la cadena guardada con el último bloque confirmado registrado y verifica que los
+// últimos bloques se encadenen por ParentHash. Retorna nil si la cadena no retrocedió
+func DetectReorg(db *storage.BlockchainDB) (*ChainReorg, error) {
+	head, err := ReadChainHead(db.GetDataDir())
+	if err != nil {
+		return nil, err
+	}
+	latest := latestHeight(db)
+	latestBlock, err := storedBlock(db, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	reorg := &ChainReorg{NewHeight: latest}
+	if latestBlock != nil {
+		reorg.NewHash = latestBlock.Header.Hash
+	}
+	if head != nil {
+		reorg.OldHeight, reorg.OldHash = head.Height, head.Hash
+	}
+
+	// Altura menor que la ya confirmada
+	if head != nil && latest < head.Height {
+		reorg.Height = latest + 1
+		reorg.Reason = fmt.Sprintf("la altura guardada %d es menor que la última confirmada %d", latest, head.Height)
+		return reorg, nil
+	}
+
+	// Otro bloque en la última altura confirmada
+	if head != nil && head.Height > 0 {
+		confirmed, err := storedBlock(db, head.Height)
+		if err != nil {
+			return nil, err
+		}
+		if confirmed != nil && !strings.EqualFold(confirmed.Header.Hash, head.Hash) {
+			reorg.Height = head.Height
+			reorg.Reason = fmt.Sprintf("el bloque %d tiene hash %s en lugar de %s", head.Height, confirmed.Header.Hash, head.Hash)
+			return reorg, nil
+		}
+	}
+
+	// Los últimos bloques deben encadenarse (se detiene en el primer bloque ausente, ej: antes de un snapshot)
+	block := latestBlock
+	for height := latest; block != nil && height > 1 && latest-height < reorgCheckDepth; height-- {
+		parent, err := storedBlock(db, height-1)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			break
+		}
+		if !strings.EqualFold(block.Header.ParentHash, parent.Header.Hash) {
+			reorg.Height = height
+			reorg.Reason = fmt.Sprintf("el parentHash %s del bloque %d no es el hash del bloque %d (%s)",
+				block.Header.ParentHash, height, height-1, parent.Header.Hash)
+			return reorg, nil
+		}
+		block = parent
+	}
+	return nil, nil
+}
+
+// ResetChainHead registra el último bloque guardado como el último confirmado (al aceptar un retroceso)
+func ResetChainHead(db *storage.BlockchainDB) error {
+	latest := latestHeight(db)
+	head := ChainHead{Height: latest}
+	block, err := storedBlock(db, latest)
+	if err != nil {
+		return err
+	}
+	if block != nil {
+		head.Hash = block.Header.Hash
+	}
+	return writeChainHead(db.GetDataDir(), head)
+}
+
+// RollbackToState descarta los bloques guardados por encima de height, la altura del estado EVM retomado
+// tras una caída, y la registra como el último bloque confirmado: esos bloques no tienen estado y se
+// vuelven a ejecutar. Retorna la cantidad de alturas descartadas
+func RollbackToState(db *storage.BlockchainDB, height uint64) (int, error) {
+	removed, err := db.TruncateBlocks(height)
+	if err != nil {
+		return 0, fmt.Errorf("error descartando bloques sobre la altura %d: %w", height, err)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := ResetChainHead(db); err != nil {
+		return removed, fmt.Errorf("error registrando el último bloque confirmado: %w", err)
+	}
+	return removed, nil
+}
+
+// WatchlistReorg convierte un retroceso de la cadena al aviso chain_reorg del watchlist
+func WatchlistReorg(reorg *ChainReorg) *watchlist.Reorg {
+	return &watchlist.Reorg{
+		Height:    reorg.Height,
+		OldHeight: reorg.OldHeight,
+		OldHash:   reorg.OldHash,
+		NewHeight: reorg.NewHeight,
+		NewHash:   reorg.NewHash,
+		Reason:    reorg.Reason,
+	}
+}
+
+// advanceChainHead registra un bloque guardado como el último confirmado si supera al registrado
+// Los bloques reejecutados al reiniciar (altura ya confirmada) no lo modifican
+func (app *ABCIApp) advanceChainHead(block *Block) {
+	if app.chainHead != nil && block.Header.Height <= app.chainHead.Height {
+		return
+	}
+	head := ChainHead{Height: block.Header.Height, Hash: block.Header.Hash}
+	if err := writeChainHead(app.storage.GetDataDir(), head); err != nil {
+		consensusLog.Warn("Error registrando último bloque confirmado: " + err.Error())
+		return
+	}
+	app.chainHead = &head
+}
+
+// latestHeight retorna la altura del último bloque guardado (0 si la cadena está vacía)
+func latestHeight(db *storage.BlockchainDB) uint64 {
+	height, err := db.GetLatestHeight()
+	if err != nil {
+		return 0
+	}
+	return height
+}
+
+// storedBlock obtiene y decodifica el bloque canónico de una altura (nil si no está guardado)
+func storedBlock(db *storage.BlockchainDB, height uint64) (*Block, error) {
+	if height == 0 {
+		return nil, nil
+	}
+	data, err := db.GetBlock(height)
+	if err != nil {
+		return nil, nil
+	}
+	block, err := DecodeBlock(data)
+	if err != nil {
+		return nil, fmt.Errorf("bloque %d inválido: %w", height, err)
+	}
+	return block, nil
+}
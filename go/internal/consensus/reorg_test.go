@@ -0,0 +1,116 @@
+package consensus
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDetectReorg prueba que al iniciar se detecten la altura menor que la confirmada, el cambio del bloque
+// confirmado y los bloques que no se encadenan, y que aceptar el retroceso registre la cadena guardada
+func TestDetectReorg(t *testing.T) {
+	app, _ := newSnapshotTestApp(t, "detect_reorg")
+	db := app.storage
+	if reorg, err := DetectReorg(db); err != nil || reorg != nil {
+		t.Fatalf("Una cadena vacía sin registro no debería retroceder: %v %v", reorg, err)
+	}
+
+	commitTestBlocks(t, app, 1, 3)
+	head, err := ReadChainHead(db.GetDataDir())
+	if err != nil || head == nil || head.Height != 3 {
+		t.Fatalf("Último bloque confirmado no registrado: %+v (%v)", head, err)
+	}
+	if reorg, err := DetectReorg(db); err != nil || reorg != nil {
+		t.Fatalf("La cadena no retrocedió: %v %v", reorg, err)
+	}
+
+	// Los bloques reejecutados al reiniciar no retroceden el registro
+	app.advanceChainHead(&Block{Header: BlockHeader{Height: 1, Hash: "0x01"}})
+	if head, _ := ReadChainHead(db.GetDataDir()); head.Height != 3 {
+		t.Errorf("El registro retrocedió a la altura %d", head.Height)
+	}
+
+	// Altura guardada menor que la confirmada (ej: backup anterior)
+	if err := db.SaveLatestHeight(2); err != nil {
+		t.Fatalf("Error guardando altura: %v", err)
+	}
+	reorg, err := DetectReorg(db)
+	if err != nil || reorg == nil || reorg.Height != 3 || reorg.OldHeight != 3 || reorg.NewHeight != 2 {
+		t.Fatalf("Retroceso de altura no detectado: %+v (%v)", reorg, err)
+	}
+	if reorg.OldHash != head.Hash || WatchlistReorg(reorg).OldHash != head.Hash {
+		t.Errorf("Hash confirmado incorrecto en el reorg: %s", reorg.OldHash)
+	}
+
+	// Otro bloque en la altura confirmada
+	if err := db.SaveLatestHeight(3); err != nil {
+		t.Fatalf("Error guardando altura: %v", err)
+	}
+	block := loadTestBlock(t, app, 3)
+	block.Header.Validator = "0x0000000000000000000000000000000000000001"
+	block.Header.Hash = block.Header.ComputeHash().Hex()
+	saveTestBlock(t, app, block)
+	if reorg, err := DetectReorg(db); err != nil || reorg == nil || reorg.Height != 3 || !strings.Contains(reorg.Reason, "hash") {
+		t.Fatalf("Cambio del bloque confirmado no detectado: %+v (%v)", reorg, err)
+	}
+
+	// Aceptar el retroceso registra la cadena guardada
+	if err := ResetChainHead(db); err != nil {
+		t.Fatalf("Error en ResetChainHead: %v", err)
+	}
+	if reorg, err := DetectReorg(db); err != nil || reorg != nil {
+		t.Fatalf("El retroceso aceptado no debería volver a detectarse: %v %v", reorg, err)
+	}
+
+	// Un bloque que no se encadena con el anterior (conserva su hash para que el bloque 3 sí se encadene)
+	block = loadTestBlock(t, app, 2)
+	block.Header.ParentHash = "0xbad"
+	saveTestBlock(t, app, block)
+	if reorg, err := DetectReorg(db); err != nil || reorg == nil || reorg.Height != 2 {
+		t.Fatalf("ParentHash inconsistente no detectado: %+v (%v)", reorg, err)
+	}
+}
+
+// loadTestBlock decodifica el bloque canónico guardado de una altura
+func loadTestBlock(t *testing.T, app *ABCIApp, height uint64) *Block {
+	t.Helper()
+	data, err := app.storage.GetBlock(height)
+	if err != nil {
+		t.Fatalf("Error obteniendo bloque %d: %v", height, err)
+	}
+	block, err := DecodeBlock(data)
+	if err != nil {
+		t.Fatalf("Error decodificando bloque %d: %v", height, err)
+	}
+	return block
+}
+
+// saveTestBlock guarda un bloque como el canónico de su altura
+func saveTestBlock(t *testing.T, app *ABCIApp, block *Block) {
+	t.Helper()
+	data, _ := EncodeBlock(block)
+	if err := app.storage.SaveCanonicalBlock(block.Header.Height, block.Header.Hash, data); err != nil {
+		t.Fatalf("Error guardando bloque %d: %v", block.Header.Height, err)
+	}
+}
+
+// TestRollbackToState prueba que descartar los bloques sin estado deje la cadena en la altura del estado
+// retomado sin que se detecte un retroceso al iniciar
+func TestRollbackToState(t *testing.T) {
+	app, _ := newSnapshotTestApp(t, "rollback_to_state")
+	db := app.storage
+	commitTestBlocks(t, app, 1, 4)
+
+	removed, err := RollbackToState(db, 2)
+	if err != nil || removed != 2 {
+		t.Fatalf("Se esperaban 2 bloques descartados: %d (%v)", removed, err)
+	}
+	if latest := latestHeight(db); latest != 2 {
+		t.Errorf("Última altura %d, esperada 2", latest)
+	}
+	if head, err := ReadChainHead(db.GetDataDir()); err != nil || head == nil || head.Height != 2 {
+		t.Errorf("Último bloque confirmado no registrado en la altura 2: %+v (%v)", head, err)
+	}
+	if reorg, err := DetectReorg(db); err != nil || reorg != nil {
+		t.Errorf("La cadena descartada no debería detectarse como retroceso: %v %v", reorg, err)
+	}
+}
@@ -0,0 +1,243 @@
+package consensus
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	execution "github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/snapshot"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ReplayOptions configura la reejecución de bloques guardados
+type ReplayOptions struct {
+	From uint64 // Primer bloque a comparar (0 = 1)
+	To   uint64 // Último bloque a reejecutar (0 = último bloque guardado)
+
+	// Parámetros del ValidatorSet para las transacciones de staking (MinStake nil = sin staking)
+	MinStake      *big.Int
+	MaxValidators int
+}
+
+// ReplayResult resume una reejecución
+type ReplayResult struct {
+	BaseHeight uint64            `json:"baseHeight"` // Altura del snapshot usado como estado inicial (0 = estado vacío)
+	From       uint64            `json:"from"`
+	To         uint64            `json:"to"`
+	Replayed   uint64            `json:"replayed"` // Bloques reejecutados (incluye los previos a From desde la base)
+	Divergence *ReplayDivergence `json:"divergence,omitempty"`
+}
+
+// ReplayDivergence describe el primer punto en que la reejecución difiere de lo guardado
+type ReplayDivergence struct {
+	Height          uint64              `json:"height"`
+	Reason          string              `json:"reason"`
+	ExpectedAppHash string              `json:"expectedAppHash"`
+	ActualAppHash   string              `json:"actualAppHash,omitempty"`
+	TxIndex         int                 `json:"txIndex"` // -1 si ninguna transacción difiere (solo el app hash)
+	Tx              *Transaction        `json:"tx,omitempty"`
+	ExpectedReceipt *TransactionReceipt `json:"expectedReceipt,omitempty"`
+	ActualResult    *ReplayTxResult     `json:"actualResult,omitempty"`
+}
+
+// ReplayTxResult es el resultado de reejecutar una transacción
+type ReplayTxResult struct {
+	Code    uint32 `json:"code"`
+	Log     string `json:"log"`
+	GasUsed int64  `json:"gasUsed"`
+}
+
+// Replay reejecuta los bloques guardados en source a través de una ABCIApp y un EVMExecutor nuevos,
+// comparando el app hash de cada bloque con el guardado y reportando la primera transacción divergente.
+// El estado inicial es el snapshot local más reciente anterior a From (o el estado vacío, desde el bloque 1).
+// Las bases de datos de trabajo se crean en workDir y se borran al terminar; source no se modifica.
+// Nota: los bloques guardados solo contienen las transacciones exitosas, por lo que los efectos de las
+// fallidas (nonce y gas cobrado) no se reejecutan
+func Replay(ctx context.Context, source *storage.BlockchainDB, snapshots *snapshot.Store, workDir string, opts ReplayOptions) (*ReplayResult, error) {
+	from, to := opts.From, opts.To
+	if from == 0 {
+		from = 1
+	}
+	if to == 0 {
+		latest, err := source.GetLatestHeight()
+		if err != nil {
+			return nil, fmt.Errorf("error obteniendo último bloque: %w", err)
+		}
+		to = latest
+	}
+	if from > to {
+		return nil, fmt.Errorf("rango inválido: %d..%d", from, to)
+	}
+
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creando directorio de trabajo: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	workDB, err := storage.NewBlockchainDB(workDir)
+	if err != nil {
+		return nil, err
+	}
+	defer workDB.Close()
+
+	evm := execution.NewEVMExecutor(workDB)
+	if err := evm.Start(); err != nil {
+		return nil, err
+	}
+	defer evm.Stop()
+
+	// Estado inicial: el snapshot más reciente anterior al rango
+	result := &ReplayResult{From: from, To: to}
+	if snapshots != nil {
+		base, err := replayBase(snapshots, from)
+		if err != nil {
+			return nil, err
+		}
+		if base != nil {
+			if err := snapshots.Restore(base.Height, snapshotSources(workDB, evm)); err != nil {
+				return nil, fmt.Errorf("error restaurando snapshot %d: %w", base.Height, err)
+			}
+			evm.ReloadState()
+			result.BaseHeight = base.Height
+		}
+	}
+
+	var validators *ValidatorSet
+	if opts.MinStake != nil {
+		validators = NewValidatorSet(workDB, evm, opts.MinStake, opts.MaxValidators)
+		if err := validators.LoadValidators(); err != nil {
+			return nil, fmt.Errorf("error cargando validadores: %w", err)
+		}
+	}
+
+	var app *ABCIApp
+	for height := result.BaseHeight + 1; height <= to; height++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		block, err := loadStoredBlock(source, height)
+		if err != nil {
+			return result, err
+		}
+		if app == nil {
+			app = NewABCIApp(workDB, evm, validators, block.Header.ChainID)
+		}
+
+		divergence, err := replayBlock(ctx, app, block)
+		if err != nil {
+			return result, fmt.Errorf("error reejecutando bloque %d: %w", height, err)
+		}
+		result.Replayed++
+		if divergence != nil {
+			result.Divergence = divergence
+			return result, nil
+		}
+	}
+
+	return result, nil
+}
+
+// replayBase retorna el snapshot más reciente anterior a from (nil si no hay)
+func replayBase(snapshots *snapshot.Store, from uint64) (*snapshot.Metadata, error) {
+	list, err := snapshots.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, meta := range list {
+		if meta.Height < from {
+			return meta, nil
+		}
+	}
+	return nil, nil
+}
+
+// loadStoredBlock carga un bloque guardado
+func loadStoredBlock(source *storage.BlockchainDB, height uint64) (*Block, error) {
+	data, err := source.GetBlock(height)
+	if err != nil {
+		return nil, fmt.Errorf("bloque %d no encontrado: %w", height, err)
+	}
+	block, err := DecodeBlock(data)
+	if err != nil {
+		return nil, fmt.Errorf("bloque %d inválido: %w", height, err)
+	}
+	return block, nil
+}
+
+// replayBlock reejecuta un bloque y lo compara con el guardado (nil si coincide)
+func replayBlock(ctx context.Context, app *ABCIApp, block *Block) (*ReplayDivergence, error) {
+	txs := make([][]byte, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		txBytes, err := json.Marshal(tx)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, txBytes)
+	}
+	proposer, _ := hex.DecodeString(block.Header.Validator)
+
+	resp, err := app.FinalizeBlock(ctx, &abcitypes.FinalizeBlockRequest{
+		Height:          int64(block.Header.Height),
+		Time:            block.Header.Timestamp,
+		ProposerAddress: proposer,
+		Txs:             txs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := app.Commit(ctx, &abcitypes.CommitRequest{}); err != nil {
+		return nil, err
+	}
+
+	actualAppHash := common.BytesToHash(app.state.AppHash).Hex()
+	divergence := &ReplayDivergence{
+		Height:          block.Header.Height,
+		ExpectedAppHash: block.Header.AppHash(),
+		ActualAppHash:   actualAppHash,
+		TxIndex:         -1,
+	}
+
+	// Primera transacción cuyo resultado difiere del receipt guardado
+	for i, txResult := range resp.TxResults {
+		var expected *TransactionReceipt
+		if i < len(block.Receipts) {
+			expected = block.Receipts[i]
+		}
+		if reason := compareTxResult(txResult, expected); reason != "" {
+			divergence.Reason = reason
+			divergence.TxIndex = i
+			divergence.Tx = block.Transactions[i]
+			divergence.ExpectedReceipt = expected
+			divergence.ActualResult = &ReplayTxResult{Code: txResult.Code, Log: txResult.Log, GasUsed: txResult.GasUsed}
+			return divergence, nil
+		}
+	}
+
+	if actualAppHash != divergence.ExpectedAppHash {
+		divergence.Reason = "app hash distinto con los mismos resultados de transacciones"
+		return divergence, nil
+	}
+	return nil, nil
+}
+
+// compareTxResult compara el resultado reejecutado con el receipt guardado (vacío si coinciden)
+// Los bloques solo guardan transacciones exitosas, así que todas deben volver a ser exitosas
+func compareTxResult(actual *abcitypes.ExecTxResult, expected *TransactionReceipt) string {
+	if expected == nil {
+		return "transacción sin receipt guardado"
+	}
+	if actual.Code != CodeOK {
+		return fmt.Sprintf("la transacción fue exitosa y ahora falla (código %d)", actual.Code)
+	}
+	if uint64(actual.GasUsed) != expected.GasUsed {
+		return fmt.Sprintf("gas usado distinto: guardado %d, reejecutado %d", expected.GasUsed, actual.GasUsed)
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package consensus
+
+import (
+	"errors"
+
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	"github.com/cometbft/cometbft/crypto"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+)
+
+// errReplicaSign es el error de cualquier firma pedida a una réplica
+var errReplicaSign = errors.New("nodo réplica: no firma votos ni propuestas")
+
+// readOnlyPV es el private validator de una réplica: una clave efímera (no se guarda, así que nunca está en
+// el set de validadores) que rechaza toda firma. CometBFT sigue la cadena con él sin poder votar aunque el
+// directorio tenga un priv_validator_key.json de un validador
+type readOnlyPV struct {
+	pubKey crypto.PubKey
+}
+
+// newReadOnlyPV crea el private validator de una réplica
+func newReadOnlyPV() *readOnlyPV {
+	return &readOnlyPV{pubKey: ed25519.GenPrivKey().PubKey()}
+}
+
+// GetPubKey implementa types.PrivValidator
+func (pv *readOnlyPV) GetPubKey() (crypto.PubKey, error) {
+	return pv.pubKey, nil
+}
+
+// SignVote implementa types.PrivValidator
+func (pv *readOnlyPV) SignVote(string, *cmtproto.Vote, bool) error {
+	return errReplicaSign
+}
+
+// SignProposal implementa types.PrivValidator
+func (pv *readOnlyPV) SignProposal(string, *cmtproto.Proposal) error {
+	return errReplicaSign
+}
+
+// SignBytes implementa types.PrivValidator
+func (pv *readOnlyPV) SignBytes([]byte) ([]byte, error) {
+	return nil, errReplicaSign
+}
@@ -0,0 +1,414 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+)
+
+// RewardsAddress es la cuenta de sistema que custodia las recompensas de bloque hasta que se retiran
+const RewardsAddress = "0x0000000000000000000000000000000000000102"
+
+// Comisión por defecto de los validadores que no configuran la suya (genesis y bond sin comisión)
+const (
+	DefaultCommissionRateBps      = 1000 // 10%
+	DefaultMaxCommissionRateBps   = 2000 // 20%
+	DefaultMaxCommissionChangeBps = 100  // 1 punto porcentual por día
+)
+
+const (
+	// commissionBasisPoints es el 100% expresado en puntos básicos
+	commissionBasisPoints = 10000
+	// commissionChangeInterval es el tiempo mínimo (hora de bloque) entre dos cambios de tasa
+	commissionChangeInterval = 24 * time.Hour
+	// unclaimedRewardsKey es la clave de storage de las recompensas de validadores ya removidos del set
+	unclaimedRewardsKey = "validators:rewards:unclaimed"
+)
+
+// rewardPrecision escala la recompensa acumulada por unidad de stake para no perder decimales
+var rewardPrecision = new(big.Int).Exp(big.NewInt(10), big.NewInt(27), nil)
+
+// Commission es la parte de las recompensas que cobra un validador antes de repartir el resto
+// entre sus delegadores. MaxRateBps y MaxChangeBps se fijan en el bond y no se pueden cambiar
+type Commission struct {
+	RateBps      int64     `json:"rateBps"`      // Tasa actual en puntos básicos (100 = 1%)
+	MaxRateBps   int64     `json:"maxRateBps"`   // Tasa máxima que puede configurar
+	MaxChangeBps int64     `json:"maxChangeBps"` // Cambio máximo de la tasa por día
+	UpdatedAt    time.Time `json:"updatedAt"`    // Hora del bloque del último cambio de tasa
+}
+
+// DefaultCommission retorna la comisión por defecto
+func DefaultCommission() Commission {
+	return Commission{
+		RateBps:      DefaultCommissionRateBps,
+		MaxRateBps:   DefaultMaxCommissionRateBps,
+		MaxChangeBps: DefaultMaxCommissionChangeBps,
+	}
+}
+
+// Validate verifica que las tasas estén entre 0 y 100% y que la tasa no supere la máxima
+func (c Commission) Validate() error {
+	if c.MaxRateBps < 0 || c.MaxRateBps > commissionBasisPoints {
+		return fmt.Errorf("comisión máxima inválida: %d bps", c.MaxRateBps)
+	}
+	if c.RateBps < 0 || c.RateBps > c.MaxRateBps {
+		return fmt.Errorf("comisión inválida: %d bps (máximo %d bps)", c.RateBps, c.MaxRateBps)
+	}
+	if c.MaxChangeBps < 0 || c.MaxChangeBps > c.MaxRateBps {
+		return fmt.Errorf("cambio máximo de comisión inválido: %d bps", c.MaxChangeBps)
+	}
+	return nil
+}
+
+// Delegation es el stake que un delegador tiene en un validador y sus recompensas
+// Por ahora la única delegación es la del propio validador (Shares = Stake)
+type Delegation struct {
+	Shares     *big.Int `json:"shares"`     // Stake delegado
+	RewardDebt *big.Int `json:"rewardDebt"` // Shares * RewardPerShare en el último ajuste (escalado por rewardPrecision)
+	Accrued    *big.Int `json:"accrued"`    // Recompensas acumuladas pendientes de retiro
+}
+
+// DelegatorRewards son las recompensas pendientes de un delegador en un validador
+type DelegatorRewards struct {
+	Validator  string `json:"validator"`
+	Rewards    string `json:"rewards"`              // Recompensas como delegador
+	Commission string `json:"commission,omitempty"` // Comisión acumulada (solo si el delegador es el validador)
+}
+
+// commission retorna la comisión del validador (la por defecto si fue creado antes de existir comisiones)
+func (v *Validator) commission() Commission {
+	if v.Commission == nil {
+		return DefaultCommission()
+	}
+	return *v.Commission
+}
+
+// initRewards inicializa los acumuladores de recompensas y la autodelegación del validador
+func (v *Validator) initRewards() {
+	if v.Commission == nil {
+		commission := DefaultCommission()
+		v.Commission = &commission
+	}
+	if v.RewardPerShare == nil {
+		v.RewardPerShare = new(big.Int)
+	}
+	if v.AccumulatedCommission == nil {
+		v.AccumulatedCommission = new(big.Int)
+	}
+	if v.Delegations == nil {
+		v.Delegations = make(map[string]*Delegation)
+	}
+	v.syncSelfDelegation()
+}
+
+// settle acumula en Accrued lo que ganó la delegación desde el último ajuste
+func (v *Validator) settle(d *Delegation) {
+	earned := new(big.Int).Mul(d.Shares, v.RewardPerShare)
+	pending := new(big.Int).Sub(earned, d.RewardDebt)
+	pending.Div(pending, rewardPrecision)
+	d.Accrued.Add(d.Accrued, pending)
+	d.RewardDebt = earned
+}
+
+// syncSelfDelegation ajusta la autodelegación al stake actual, acumulando antes lo ganado con el stake anterior
+func (v *Validator) syncSelfDelegation() {
+	d, ok := v.Delegations[v.Address]
+	if !ok {
+		d = &Delegation{Shares: new(big.Int), RewardDebt: new(big.Int), Accrued: new(big.Int)}
+		v.Delegations[v.Address] = d
+	}
+	v.settle(d)
+	d.Shares = new(big.Int).Set(v.Stake)
+	d.RewardDebt = new(big.Int).Mul(d.Shares, v.RewardPerShare)
+}
+
+// totalShares retorna la suma de las delegaciones del validador
+func (v *Validator) totalShares() *big.Int {
+	total := new(big.Int)
+	for _, d := range v.Delegations {
+		total.Add(total, d.Shares)
+	}
+	return total
+}
+
+// allocate acredita amount al validador: la comisión a AccumulatedCommission y el resto
+// a sus delegadores en proporción a sus shares (sin delegaciones todo es comisión)
+func (v *Validator) allocate(amount *big.Int) {
+	commission := new(big.Int).Mul(amount, big.NewInt(v.commission().RateBps))
+	commission.Div(commission, big.NewInt(commissionBasisPoints))
+	rest := new(big.Int).Sub(amount, commission)
+
+	shares := v.totalShares()
+	if shares.Sign() == 0 {
+		commission.Set(amount)
+		rest.SetInt64(0)
+	}
+	v.AccumulatedCommission.Add(v.AccumulatedCommission, commission)
+	if rest.Sign() > 0 {
+		increment := new(big.Int).Mul(rest, rewardPrecision)
+		increment.Div(increment, shares)
+		v.RewardPerShare.Add(v.RewardPerShare, increment)
+	}
+}
+
+// SetBlockReward establece la recompensa (en wei) que se emite en cada bloque (nil o 0 = sin recompensas)
+func (vs *ValidatorSet) SetBlockReward(reward *big.Int) {
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+	if reward == nil || reward.Sign() <= 0 {
+		vs.blockReward = nil
+		return
+	}
+	vs.blockReward = new(big.Int).Set(reward)
+}
+
+// DistributeRewards reparte la recompensa de bloque entre los validadores que firmaron el bloque
+// anterior (DecidedLastCommit), en proporción a su poder de voto. Retorna el monto repartido,
+// que el llamador debe emitir a RewardsAddress
+func (vs *ValidatorSet) DistributeRewards(votes []abcitypes.VoteInfo) *big.Int {
+	distributed := new(big.Int)
+
+	vs.mutex.Lock()
+	if vs.blockReward == nil || len(votes) == 0 {
+		vs.mutex.Unlock()
+		return distributed
+	}
+
+	type signer struct {
+		validator *Validator
+		power     int64
+	}
+	byConsensusAddress := vs.consensusAddresses()
+	signers := make([]signer, 0, len(votes))
+	totalPower := big.NewInt(0)
+	for _, vote := range votes {
+		if cmttypes.BlockIDFlag(vote.BlockIdFlag) == cmttypes.BlockIDFlagAbsent || vote.Validator.Power <= 0 {
+			continue
+		}
+		address, ok := byConsensusAddress[fmt.Sprintf("%X", vote.Validator.Address)]
+		if !ok {
+			continue
+		}
+		validator := vs.validators[address]
+		if validator.Jailed {
+			continue
+		}
+		signers = append(signers, signer{validator: validator, power: vote.Validator.Power})
+		totalPower.Add(totalPower, big.NewInt(vote.Validator.Power))
+	}
+
+	for _, s := range signers {
+		share := new(big.Int).Mul(vs.blockReward, big.NewInt(s.power))
+		share.Div(share, totalPower)
+		if share.Sign() == 0 {
+			continue
+		}
+		s.validator.initRewards()
+		s.validator.allocate(share)
+		distributed.Add(distributed, share)
+	}
+	vs.mutex.Unlock()
+
+	if distributed.Sign() > 0 {
+		if err := vs.SaveValidators(); err != nil {
+			consensusLog.Warnf("Error guardando validadores después de repartir recompensas: %v", err)
+		}
+	}
+	return distributed
+}
+
+// configureCommission fija la comisión elegida en el bond (la tasa puede cambiarse después con SetCommission)
+func (vs *ValidatorSet) configureCommission(address string, commission Commission) error {
+	if err := commission.Validate(); err != nil {
+		return err
+	}
+
+	vs.mutex.Lock()
+	validator, exists := vs.validators[address]
+	if !exists {
+		vs.mutex.Unlock()
+		return fmt.Errorf("validador no encontrado: %s", address)
+	}
+	commission.UpdatedAt = vs.blockTime
+	validator.Commission = &commission
+	vs.mutex.Unlock()
+
+	return vs.SaveValidators()
+}
+
+// SetCommission cambia la tasa de comisión de un validador. La nueva tasa no puede superar la máxima,
+// ni diferir de la actual en más de MaxChangeBps, y solo se permite un cambio por día (hora de bloque)
+func (vs *ValidatorSet) SetCommission(address string, rateBps int64) error {
+	vs.mutex.Lock()
+	validator, exists := vs.validators[address]
+	if !exists {
+		vs.mutex.Unlock()
+		return fmt.Errorf("validador no encontrado: %s", address)
+	}
+
+	commission := validator.commission()
+	change := rateBps - commission.RateBps
+	if change < 0 {
+		change = -change
+	}
+	switch {
+	case rateBps < 0 || rateBps > commission.MaxRateBps:
+		vs.mutex.Unlock()
+		return fmt.Errorf("comisión inválida: %d bps (máximo %d bps)", rateBps, commission.MaxRateBps)
+	case change > commission.MaxChangeBps:
+		vs.mutex.Unlock()
+		return fmt.Errorf("cambio de comisión demasiado grande: %d bps (máximo %d bps por día)", change, commission.MaxChangeBps)
+	case !commission.UpdatedAt.IsZero() && vs.blockTime.Before(commission.UpdatedAt.Add(commissionChangeInterval)):
+		vs.mutex.Unlock()
+		return fmt.Errorf("la comisión solo puede cambiarse una vez por día (próximo cambio desde %s)",
+			commission.UpdatedAt.Add(commissionChangeInterval).Format(time.RFC3339))
+	}
+
+	// La tasa nueva aplica a las recompensas futuras: las ya repartidas no cambian
+	commission.RateBps = rateBps
+	commission.UpdatedAt = vs.blockTime
+	validator.Commission = &commission
+	consensusLog.Infof("Comisión de %s cambiada a %d bps", address, rateBps)
+	vs.recordHistory(address, StakeActionSetCommission, big.NewInt(rateBps), validator.Stake)
+	vs.mutex.Unlock()
+
+	return vs.SaveValidators()
+}
+
+// releaseRewards mueve a las recompensas sin reclamar lo pendiente de un validador que se remueve del set (requiere lock)
+func (vs *ValidatorSet) releaseRewards(validator *Validator) {
+	if validator.Delegations == nil {
+		return
+	}
+	if vs.unclaimedRewards == nil {
+		vs.unclaimedRewards = make(map[string]*big.Int)
+	}
+	credit := func(address string, amount *big.Int) {
+		if amount == nil || amount.Sign() == 0 {
+			return
+		}
+		if vs.unclaimedRewards[address] == nil {
+			vs.unclaimedRewards[address] = new(big.Int)
+		}
+		vs.unclaimedRewards[address].Add(vs.unclaimedRewards[address], amount)
+	}
+
+	for delegator, d := range validator.Delegations {
+		validator.settle(d)
+		credit(delegator, d.Accrued)
+		d.Accrued = new(big.Int)
+	}
+	credit(validator.Address, validator.AccumulatedCommission)
+	validator.AccumulatedCommission = new(big.Int)
+	vs.saveUnclaimedRewards()
+}
+
+// PendingRewards retorna las recompensas que delegator puede retirar, por validador
+// (las de validadores ya removidos del set se reportan con Validator vacío). La dirección no distingue mayúsculas
+func (vs *ValidatorSet) PendingRewards(delegator string) ([]DelegatorRewards, *big.Int) {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+
+	rewards := make([]DelegatorRewards, 0)
+	total := new(big.Int)
+	for _, v := range vs.validators {
+		delegated := new(big.Int)
+		for address, d := range v.Delegations {
+			if !strings.EqualFold(address, delegator) {
+				continue
+			}
+			// Igual que settle pero sin modificar la delegación
+			earned := new(big.Int).Mul(d.Shares, v.RewardPerShare)
+			pending := new(big.Int).Sub(earned, d.RewardDebt)
+			pending.Div(pending, rewardPrecision)
+			delegated.Add(delegated, pending.Add(pending, d.Accrued))
+		}
+		entry := DelegatorRewards{Validator: v.Address, Rewards: delegated.String()}
+		total.Add(total, delegated)
+		if strings.EqualFold(v.Address, delegator) && v.AccumulatedCommission != nil {
+			entry.Commission = v.AccumulatedCommission.String()
+			total.Add(total, v.AccumulatedCommission)
+		} else if delegated.Sign() == 0 {
+			continue
+		}
+		rewards = append(rewards, entry)
+	}
+	for address, unclaimed := range vs.unclaimedRewards {
+		if strings.EqualFold(address, delegator) && unclaimed.Sign() > 0 {
+			rewards = append(rewards, DelegatorRewards{Rewards: unclaimed.String()})
+			total.Add(total, unclaimed)
+		}
+	}
+
+	sort.Slice(rewards, func(i, j int) bool {
+		return rewards[i].Validator < rewards[j].Validator
+	})
+	return rewards, total
+}
+
+// WithdrawRewards liquida todas las recompensas pendientes de delegator (incluida su comisión si es
+// validador) y retorna el monto, que el llamador debe transferir desde RewardsAddress
+func (vs *ValidatorSet) WithdrawRewards(delegator string) (*big.Int, error) {
+	vs.mutex.Lock()
+	total := new(big.Int)
+	for _, v := range vs.validators {
+		if d, ok := v.Delegations[delegator]; ok {
+			v.settle(d)
+			total.Add(total, d.Accrued)
+			d.Accrued = new(big.Int)
+		}
+		if v.Address == delegator && v.AccumulatedCommission != nil {
+			total.Add(total, v.AccumulatedCommission)
+			v.AccumulatedCommission = new(big.Int)
+		}
+	}
+	if unclaimed := vs.unclaimedRewards[delegator]; unclaimed != nil {
+		total.Add(total, unclaimed)
+		delete(vs.unclaimedRewards, delegator)
+		vs.saveUnclaimedRewards()
+	}
+	vs.mutex.Unlock()
+
+	if total.Sign() == 0 {
+		return nil, fmt.Errorf("sin recompensas pendientes para %s", delegator)
+	}
+	if err := vs.SaveValidators(); err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// loadUnclaimedRewards lee de storage las recompensas de validadores removidos (requiere lock)
+func (vs *ValidatorSet) loadUnclaimedRewards() error {
+	vs.unclaimedRewards = make(map[string]*big.Int)
+	data, err := vs.storage.GetAccount(unclaimedRewardsKey)
+	if err != nil {
+		// Sin recompensas sin reclamar
+		return nil
+	}
+	if err := json.Unmarshal(data, &vs.unclaimedRewards); err != nil {
+		return fmt.Errorf("error parseando recompensas sin reclamar: %w", err)
+	}
+	return nil
+}
+
+// saveUnclaimedRewards guarda en storage las recompensas de validadores removidos (requiere lock)
+func (vs *ValidatorSet) saveUnclaimedRewards() {
+	if vs.storage == nil {
+		return
+	}
+	data, err := json.Marshal(vs.unclaimedRewards)
+	if err != nil {
+		consensusLog.Warn("Error serializando recompensas sin reclamar: " + err.Error())
+		return
+	}
+	if err := vs.storage.SaveAccount(unclaimedRewardsKey, data); err != nil {
+		consensusLog.Warn("Error guardando recompensas sin reclamar: " + err.Error())
+	}
+}
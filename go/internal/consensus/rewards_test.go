@@ -0,0 +1,119 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// TestValidatorSet_DistributeRewards prueba el reparto por poder de voto, la comisión y el retiro
+func TestValidatorSet_DistributeRewards(t *testing.T) {
+	validatorSet, keys := crearValidatorSetConGenesis(t)
+	first := "0x1111111111111111111111111111111111111111"
+	second := "0x2222222222222222222222222222222222222222"
+
+	// BlockIdFlag: 1 = ausente, 2 = commit
+	votes := func(secondSigned bool) []abcitypes.VoteInfo {
+		vote := abcitypes.VoteInfo{Validator: abcitypes.Validator{Address: keys[1].PubKey().Address(), Power: 3000}, BlockIdFlag: 1}
+		if secondSigned {
+			vote.BlockIdFlag = 2
+		}
+		return []abcitypes.VoteInfo{
+			{Validator: abcitypes.Validator{Address: keys[0].PubKey().Address(), Power: 5000}, BlockIdFlag: 2},
+			vote,
+		}
+	}
+
+	// Sin recompensa configurada no se reparte nada
+	if distributed := validatorSet.DistributeRewards(votes(true)); distributed.Sign() != 0 {
+		t.Fatalf("No debería repartir sin recompensa: %s", distributed)
+	}
+
+	validatorSet.SetBlockReward(big.NewInt(8000))
+
+	// Solo firma el primero: recibe todo, 10% de comisión y el resto a su autodelegación
+	if distributed := validatorSet.DistributeRewards(votes(false)); distributed.Int64() != 8000 {
+		t.Fatalf("Monto repartido incorrecto: %s", distributed)
+	}
+	rewards, total := validatorSet.PendingRewards(first)
+	if total.Int64() != 8000 || len(rewards) != 1 || rewards[0].Rewards != "7200" || rewards[0].Commission != "800" {
+		t.Errorf("Recompensas incorrectas: %+v (total %s)", rewards, total)
+	}
+
+	// Firman los dos: 5000 y 3000 según el poder
+	validatorSet.DistributeRewards(votes(true))
+	if _, total := validatorSet.PendingRewards(first); total.Int64() != 13000 {
+		t.Errorf("Total del primer validador incorrecto: %s", total)
+	}
+	if _, total := validatorSet.PendingRewards(second); total.Int64() != 3000 {
+		t.Errorf("Total del segundo validador incorrecto: %s", total)
+	}
+
+	// Un cambio de stake conserva lo acumulado
+	validatorSet.mutex.Lock()
+	validator := validatorSet.validators[first]
+	validator.Stake.Add(validator.Stake, validator.Stake)
+	validator.initRewards()
+	validatorSet.mutex.Unlock()
+	if _, total := validatorSet.PendingRewards(first); total.Int64() != 13000 {
+		t.Errorf("El cambio de stake no debería alterar lo acumulado: %s", total)
+	}
+
+	withdrawn, err := validatorSet.WithdrawRewards(first)
+	if err != nil || withdrawn.Int64() != 13000 {
+		t.Fatalf("Retiro incorrecto: %v, %v", withdrawn, err)
+	}
+	if _, total := validatorSet.PendingRewards(first); total.Sign() != 0 {
+		t.Errorf("No deberían quedar recompensas después del retiro: %s", total)
+	}
+	if _, err := validatorSet.WithdrawRewards(first); err == nil {
+		t.Error("Debería fallar un retiro sin recompensas pendientes")
+	}
+}
+
+// TestValidatorSet_SetCommission prueba los límites de tasa máxima, cambio máximo y un cambio por día
+func TestValidatorSet_SetCommission(t *testing.T) {
+	validatorSet, _ := crearValidatorSetConGenesis(t)
+	address := "0x1111111111111111111111111111111111111111"
+	blockTime := time.Unix(1700000000, 0).UTC()
+
+	validatorSet.SetBlockInfo(10, blockTime)
+	if err := validatorSet.SetCommission(address, DefaultCommissionRateBps+100); err != nil {
+		t.Fatalf("Error cambiando comisión: %v", err)
+	}
+	if err := validatorSet.SetCommission(address, DefaultCommissionRateBps+200); err == nil {
+		t.Error("Debería rechazar un segundo cambio en el mismo día")
+	}
+
+	validatorSet.SetBlockInfo(20, blockTime.Add(25*time.Hour))
+	if err := validatorSet.SetCommission(address, DefaultCommissionRateBps+400); err == nil {
+		t.Error("Debería rechazar un cambio mayor a MaxChangeBps")
+	}
+	if err := validatorSet.SetCommission(address, DefaultMaxCommissionRateBps+1); err == nil {
+		t.Error("Debería rechazar una tasa mayor a la máxima")
+	}
+	if err := validatorSet.SetCommission(address, DefaultCommissionRateBps); err != nil {
+		t.Fatalf("Error cambiando comisión: %v", err)
+	}
+
+	validator, _ := validatorSet.GetValidator(address)
+	if validator.Commission.RateBps != DefaultCommissionRateBps || !validator.Commission.UpdatedAt.Equal(blockTime.Add(25*time.Hour)) {
+		t.Errorf("Comisión incorrecta: %+v", validator.Commission)
+	}
+	history, _ := validatorSet.GetValidatorHistory(address)
+	if len(history) != 2 || history[1].Type != StakeActionSetCommission || history[1].Amount != "1000" {
+		t.Errorf("Historial de comisión incorrecto: %+v", history)
+	}
+
+	for _, invalid := range []Commission{
+		{RateBps: 500, MaxRateBps: 10001},
+		{RateBps: 3000, MaxRateBps: 2000},
+		{RateBps: 100, MaxRateBps: 2000, MaxChangeBps: -1},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Comisión inválida aceptada: %+v", invalid)
+		}
+	}
+}
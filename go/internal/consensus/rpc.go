@@ -0,0 +1,160 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/node"
+	rpclocal "github.com/cometbft/cometbft/rpc/client/local"
+)
+
+// ErrHeightNotAvailable se retorna cuando la altura pedida es futura o ya fue podada
+var ErrHeightNotAvailable = errors.New("altura no disponible")
+
+// heightNotAvailableErrors son los mensajes con los que el RPC de CometBFT rechaza alturas inexistentes
+var heightNotAvailableErrors = []string{
+	"must be less than or equal to the current blockchain height",
+	"is not available, lowest height is",
+	"could not find results for height",
+}
+
+// isHeightNotAvailable retorna si el error del RPC indica una altura futura o podada
+func isHeightNotAvailable(err error) bool {
+	for _, msg := range heightNotAvailableErrors {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// cometRPC es el subconjunto del RPC de CometBFT que consulta la aplicación
+// Los resultados se retornan en el mismo formato JSON que entrega el RPC HTTP de CometBFT
+type cometRPC interface {
+	Status(ctx context.Context) (json.RawMessage, error)
+	NetInfo(ctx context.Context) (json.RawMessage, error)
+	BlockResults(ctx context.Context, height int64) (json.RawMessage, error)
+	TxSearch(ctx context.Context, query string, page, perPage int, orderBy string) (json.RawMessage, error)
+	Commit(ctx context.Context, height int64) (json.RawMessage, error)
+	Validators(ctx context.Context, height int64, page, perPage int) (json.RawMessage, error)
+	BroadcastTxAsync(ctx context.Context, tx []byte) (json.RawMessage, error)
+}
+
+// localRPC consulta el RPC de CometBFT en proceso, sin pasar por el listener HTTP del nodo
+type localRPC struct {
+	client *rpclocal.Local
+}
+
+// newLocalRPC crea el cliente RPC en proceso para un nodo CometBFT
+func newLocalRPC(n *node.Node) *localRPC {
+	return &localRPC{client: rpclocal.New(n)}
+}
+
+// rpcJSON serializa un resultado con el codec JSON de CometBFT (int64 como string, bytes en hex/base64)
+func rpcJSON(result interface{}, err error) (json.RawMessage, error) {
+	if err != nil {
+		return nil, err
+	}
+	data, err := cmtjson.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("error serializando resultado RPC: %w", err)
+	}
+	return data, nil
+}
+
+// callRPC ejecuta una consulta respetando el deadline de ctx
+// El cliente local de CometBFT ignora el contexto, así que un nodo trabado dejaría la consulta colgada:
+// al vencer ctx se retorna un error que envuelve ctx.Err() y la consulta termina en segundo plano
+func callRPC(ctx context.Context, call func() (interface{}, error)) (json.RawMessage, error) {
+	type reply struct {
+		data json.RawMessage
+		err  error
+	}
+	done := make(chan reply, 1)
+	go func() {
+		data, err := rpcJSON(call())
+		done <- reply{data, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.data, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("RPC de CometBFT sin respuesta: %w", ctx.Err())
+	}
+}
+
+// Status implementa cometRPC
+func (l *localRPC) Status(ctx context.Context) (json.RawMessage, error) {
+	return callRPC(ctx, func() (interface{}, error) { return l.client.Status(ctx) })
+}
+
+// NetInfo implementa cometRPC
+func (l *localRPC) NetInfo(ctx context.Context) (json.RawMessage, error) {
+	return callRPC(ctx, func() (interface{}, error) { return l.client.NetInfo(ctx) })
+}
+
+// BlockResults implementa cometRPC (height 0 = último bloque)
+func (l *localRPC) BlockResults(ctx context.Context, height int64) (json.RawMessage, error) {
+	var heightPtr *int64
+	if height > 0 {
+		heightPtr = &height
+	}
+	return callRPC(ctx, func() (interface{}, error) { return l.client.BlockResults(ctx, heightPtr) })
+}
+
+// TxSearch implementa cometRPC
+func (l *localRPC) TxSearch(ctx context.Context, query string, page, perPage int, orderBy string) (json.RawMessage, error) {
+	return callRPC(ctx, func() (interface{}, error) { return l.client.TxSearch(ctx, query, false, &page, &perPage, orderBy) })
+}
+
+// Commit implementa cometRPC
+func (l *localRPC) Commit(ctx context.Context, height int64) (json.RawMessage, error) {
+	return callRPC(ctx, func() (interface{}, error) { return l.client.Commit(ctx, &height) })
+}
+
+// Validators implementa cometRPC
+func (l *localRPC) Validators(ctx context.Context, height int64, page, perPage int) (json.RawMessage, error) {
+	return callRPC(ctx, func() (interface{}, error) { return l.client.Validators(ctx, &height, &page, &perPage) })
+}
+
+// BroadcastTxAsync implementa cometRPC: agrega la transacción al mempool de CometBFT sin esperar CheckTx
+func (l *localRPC) BroadcastTxAsync(ctx context.Context, tx []byte) (json.RawMessage, error) {
+	return callRPC(ctx, func() (interface{}, error) { return l.client.BroadcastTxAsync(ctx, tx) })
+}
+
+// rpc retorna el cliente RPC del nodo o un error si el nodo no lo expone
+func (c *CometBFT) rpc() (cometRPC, error) {
+	if c.node == nil || c.node.rpc == nil {
+		return nil, fmt.Errorf("RPC de CometBFT no disponible")
+	}
+	return c.node.rpc, nil
+}
+
+// GetBlockResults retorna los resultados de un bloque tal como los entrega block_results de CometBFT:
+// resultados por transacción, eventos de FinalizeBlock, validator updates y consensus param updates
+// Si height es 0 se retornan los resultados del último bloque
+func (c *CometBFT) GetBlockResults(ctx context.Context, height uint64) (json.RawMessage, error) {
+	if !c.running {
+		return nil, fmt.Errorf("consenso no está corriendo")
+	}
+
+	client, err := c.rpc()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.BlockResults(ctx, int64(height))
+	if err != nil {
+		if isHeightNotAvailable(err) {
+			return nil, fmt.Errorf("%w: %v", ErrHeightNotAvailable, err)
+		}
+		return nil, fmt.Errorf("block_results falló: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,349 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/health"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// fakeRPC simula el RPC en proceso de CometBFT con respuestas JSON fijas
+type fakeRPC struct {
+	status       string
+	netInfo      string
+	blockResults map[int64]string
+	commits      map[int64]string
+	validators   []string // Páginas de /validators
+	lastQuery    string
+	broadcasts   [][]byte
+}
+
+func (f *fakeRPC) Status(ctx context.Context) (json.RawMessage, error) {
+	return json.RawMessage(f.status), nil
+}
+
+func (f *fakeRPC) NetInfo(ctx context.Context) (json.RawMessage, error) {
+	return json.RawMessage(f.netInfo), nil
+}
+
+func (f *fakeRPC) BlockResults(ctx context.Context, height int64) (json.RawMessage, error) {
+	result, ok := f.blockResults[height]
+	if !ok {
+		return nil, fmt.Errorf("height %d must be less than or equal to the current blockchain height 5", height)
+	}
+	return json.RawMessage(result), nil
+}
+
+func (f *fakeRPC) TxSearch(ctx context.Context, query string, page, perPage int, orderBy string) (json.RawMessage, error) {
+	f.lastQuery = query
+	return json.RawMessage(`{"txs":[],"total_count":"0"}`), nil
+}
+
+func (f *fakeRPC) Commit(ctx context.Context, height int64) (json.RawMessage, error) {
+	result, ok := f.commits[height]
+	if !ok {
+		return nil, fmt.Errorf("height %d must be less than or equal to the current blockchain height 5", height)
+	}
+	return json.RawMessage(result), nil
+}
+
+func (f *fakeRPC) Validators(ctx context.Context, height int64, page, perPage int) (json.RawMessage, error) {
+	if page > len(f.validators) {
+		return nil, fmt.Errorf("page should be within [1, %d] range, given %d", len(f.validators), page)
+	}
+	return json.RawMessage(f.validators[page-1]), nil
+}
+
+func (f *fakeRPC) BroadcastTxAsync(ctx context.Context, tx []byte) (json.RawMessage, error) {
+	f.broadcasts = append(f.broadcasts, tx)
+	return json.RawMessage(`{"code":0}`), nil
+}
+
+// newFakeCometBFT crea un CometBFT corriendo sobre un RPC simulado
+func newFakeCometBFT(rpc *fakeRPC) *CometBFT {
+	return &CometBFT{
+		config:  &Config{},
+		node:    &CometBFTNode{rpc: rpc},
+		running: true,
+	}
+}
+
+// TestGetBlockResults prueba la consulta de block_results al RPC en proceso
+func TestGetBlockResults(t *testing.T) {
+	c := newFakeCometBFT(&fakeRPC{
+		blockResults: map[int64]string{5: `{"height":"5","txs_results":[],"validator_updates":[]}`},
+	})
+
+	results, err := c.GetBlockResults(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("Error obteniendo resultados: %v", err)
+	}
+	if !strings.Contains(string(results), `"txs_results"`) {
+		t.Errorf("Resultado inesperado: %s", results)
+	}
+
+	if _, err := c.GetBlockResults(context.Background(), 999); !errors.Is(err, ErrHeightNotAvailable) {
+		t.Errorf("Debería retornar ErrHeightNotAvailable para una altura futura: %v", err)
+	}
+
+	// Otros errores del RPC no se reportan como altura inexistente
+	c.running = false
+	if _, err := c.GetBlockResults(context.Background(), 5); err == nil || errors.Is(err, ErrHeightNotAvailable) {
+		t.Errorf("Un nodo detenido no debería reportar altura inexistente: %v", err)
+	}
+}
+
+// TestGetCommitSummary prueba el poder de voto que firmó un bloque, con el conjunto de validadores paginado
+func TestGetCommitSummary(t *testing.T) {
+	c := newFakeCometBFT(&fakeRPC{
+		commits: map[int64]string{5: `{"signed_header":{"header":{"height":"5"},"commit":{"height":"5","round":1,
+			"block_id":{"hash":"ABCD"},"signatures":[
+				{"block_id_flag":2,"validator_address":"aa01"},
+				{"block_id_flag":1,"validator_address":""},
+				{"block_id_flag":3,"validator_address":"AA03"},
+				{"block_id_flag":2,"validator_address":"AA04"}]}},"canonical":true}`},
+		validators: []string{
+			`{"block_height":"5","validators":[{"address":"AA01","voting_power":"40"},{"address":"AA02","voting_power":"30"}],"count":"2","total":"4"}`,
+			`{"block_height":"5","validators":[{"address":"AA03","voting_power":"20"},{"address":"AA04","voting_power":"10"}],"count":"2","total":"4"}`,
+		},
+	})
+
+	summary, err := c.GetCommitSummary(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("Error obteniendo commit: %v", err)
+	}
+	if summary.Height != 5 || summary.Round != 1 || summary.BlockID != "ABCD" || !summary.Canonical {
+		t.Errorf("Commit incorrecto: %+v", summary)
+	}
+	if summary.Signatures != 2 || summary.Validators != 4 || summary.SignedVotingPower != 50 || summary.TotalVotingPower != 100 {
+		t.Errorf("Firmas incorrectas: %+v", summary)
+	}
+
+	if _, err := c.GetCommitSummary(context.Background(), 999); !errors.Is(err, ErrHeightNotAvailable) {
+		t.Errorf("Debería retornar ErrHeightNotAvailable para una altura futura: %v", err)
+	}
+}
+
+// TestGetBlockCommit prueba las firmas de un commit y que solo el definitivo se guarde en storage
+func TestGetBlockCommit(t *testing.T) {
+	testDir := createValidatorTestDir(t.Name())
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer cleanupValidatorTestDir(testDir)
+	defer db.Close()
+
+	rpc := &fakeRPC{commits: map[int64]string{
+		4: `{"signed_header":{"commit":{"height":"4","round":0,"block_id":{"hash":"ABCD"},"signatures":[
+			{"block_id_flag":2,"validator_address":"aa01","timestamp":"2025-01-01T00:00:01Z","signature":"c2ln"},
+			{"block_id_flag":1,"validator_address":"","timestamp":"0001-01-01T00:00:00Z","signature":null},
+			{"block_id_flag":3,"validator_address":"AA03","timestamp":"2025-01-01T00:00:02Z","signature":"bmls"}]}},
+			"canonical":true}`,
+		5: `{"signed_header":{"commit":{"height":"5","round":0,"block_id":{"hash":"EF01"},"signatures":[]}},"canonical":false}`,
+	}}
+	c := newFakeCometBFT(rpc)
+	c.storage = db
+
+	commit, err := c.GetBlockCommit(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("Error obteniendo commit: %v", err)
+	}
+	if commit.Height != 4 || commit.BlockID != "ABCD" || !commit.Canonical || len(commit.Signatures) != 3 {
+		t.Fatalf("Commit incorrecto: %+v", commit)
+	}
+	first := commit.Signatures[0]
+	if first.ValidatorAddress != "AA01" || first.Status != CommitSignatureCommit || first.Signature != "c2ln" ||
+		!first.Timestamp.Equal(time.Date(2025, 1, 1, 0, 0, 1, 0, time.UTC)) {
+		t.Errorf("Firma incorrecta: %+v", first)
+	}
+	if commit.Signatures[1].Status != CommitSignatureAbsent || !commit.Signatures[1].Timestamp.IsZero() {
+		t.Errorf("Firma ausente incorrecta: %+v", commit.Signatures[1])
+	}
+	if commit.Signatures[2].Status != CommitSignatureNil {
+		t.Errorf("Voto nil incorrecto: %+v", commit.Signatures[2])
+	}
+
+	// El commit definitivo queda en storage: se sirve aunque CometBFT ya no lo tenga
+	delete(rpc.commits, 4)
+	if stored, err := c.GetBlockCommit(context.Background(), 4); err != nil || stored.BlockID != "ABCD" || len(stored.Signatures) != 3 {
+		t.Errorf("Commit guardado incorrecto: %+v, %v", stored, err)
+	}
+
+	// El commit del último bloque todavía no es el definitivo: no se guarda
+	if _, err := c.GetBlockCommit(context.Background(), 5); err != nil {
+		t.Fatalf("Error obteniendo commit: %v", err)
+	}
+	if _, err := db.GetBlockCommit(5); err == nil {
+		t.Error("Un commit no definitivo no debería guardarse")
+	}
+
+	if _, err := c.GetBlockCommit(context.Background(), 999); !errors.Is(err, ErrHeightNotAvailable) {
+		t.Errorf("Debería retornar ErrHeightNotAvailable para una altura futura: %v", err)
+	}
+}
+
+// TestGetNodeInfo prueba la construcción de NodeInfo desde status
+func TestGetNodeInfo(t *testing.T) {
+	c := newFakeCometBFT(&fakeRPC{
+		status: `{
+			"node_info":{"id":"abc123","network":"oxy-test","version":"1.0.1","moniker":"node0"},
+			"sync_info":{"latest_block_hash":"AA","latest_app_hash":"BB","latest_block_height":"42","latest_block_time":"2025-01-01T00:00:00Z","catching_up":true},
+			"validator_info":{"address":"CC","voting_power":"10"}}`,
+		netInfo: `{"n_peers":"0"}`,
+	})
+
+	info, err := c.GetNodeInfo(context.Background())
+	if err != nil {
+		t.Fatalf("Error obteniendo node info: %v", err)
+	}
+
+	if info.ChainID != "oxy-test" || info.CometBFTVersion != "1.0.1" || info.AppVersion != AppVersion {
+		t.Errorf("Identidad incorrecta: %+v", info)
+	}
+	if info.LatestHeight != 42 || !info.CatchingUp {
+		t.Errorf("Estado de sync incorrecto: %+v", info)
+	}
+	if info.Mode != NodeModeValidator || info.VotingPower != 10 {
+		t.Errorf("Modo incorrecto: %s (power %d)", info.Mode, info.VotingPower)
+	}
+
+	// Los roles seed y sentry tienen prioridad sobre el poder de voto
+	for _, role := range []string{NodeModeSeed, NodeModeSentry} {
+		c.node.role = role
+		info, err = c.GetNodeInfo(context.Background())
+		if err != nil {
+			t.Fatalf("Error obteniendo node info: %v", err)
+		}
+		if info.Mode != role {
+			t.Errorf("Modo incorrecto para nodo %s: %s", role, info.Mode)
+		}
+	}
+}
+
+// TestGetSyncStatus prueba el cálculo de bloques de atraso respecto a la red
+func TestGetSyncStatus(t *testing.T) {
+	c := newFakeCometBFT(&fakeRPC{
+		status:  `{"sync_info":{"latest_block_height":"10","catching_up":false}}`,
+		netInfo: `{"listening":true,"n_peers":"2","peers":[]}`,
+	})
+
+	// Sin proveedor de altura solo se conoce la altura local
+	status, err := c.GetSyncStatus(context.Background())
+	if err != nil {
+		t.Fatalf("Error obteniendo sync status: %v", err)
+	}
+	if status.Peers != 2 || status.BlocksBehind != 0 {
+		t.Errorf("Sync status incorrecto: %+v", status)
+	}
+
+	c.SetPeerHeightProvider(func() int64 { return 20 })
+	status, err = c.GetSyncStatus(context.Background())
+	if err != nil {
+		t.Fatalf("Error obteniendo sync status: %v", err)
+	}
+	if status.MaxPeerHeight != 20 || status.BlocksBehind != 10 {
+		t.Errorf("Sync status incorrecto: %+v", status)
+	}
+}
+
+// TestUpdateSyncStatus prueba que el monitor de sync actualice el health checker
+func TestUpdateSyncStatus(t *testing.T) {
+	c := newFakeCometBFT(&fakeRPC{
+		status:  `{"sync_info":{"latest_block_height":"10","catching_up":true}}`,
+		netInfo: `{"n_peers":"3"}`,
+	})
+	c.ctx = context.Background()
+	h := health.NewHealthChecker()
+	c.SetHealthChecker(h)
+
+	c.updateSyncStatus()
+
+	status := h.CheckHealth()
+	if !status.CatchingUp || status.Peers != 3 || status.BlockHeight != 10 {
+		t.Errorf("Health checker no actualizado: %+v", status)
+	}
+}
+
+// TestSearchTransactions_InvalidQuery prueba que los errores de sintaxis no lleguen al RPC
+func TestSearchTransactions_InvalidQuery(t *testing.T) {
+	rpc := &fakeRPC{}
+	c := newFakeCometBFT(rpc)
+
+	_, err := c.SearchTransactions(context.Background(), "transfer.sender=0xabc", 1, 10, "asc")
+	if !errors.Is(err, ErrInvalidSearchQuery) {
+		t.Fatalf("Debería retornar ErrInvalidSearchQuery: %v", err)
+	}
+	if rpc.lastQuery != "" {
+		t.Error("Un query inválido no debería consultarse al RPC")
+	}
+
+	if _, err := c.SearchTransactions(context.Background(), "transfer.sender='0xabc'", 1, 10, "asc"); err != nil {
+		t.Fatalf("Error buscando transacciones: %v", err)
+	}
+	if rpc.lastQuery != "transfer.sender='0xabc'" {
+		t.Errorf("Query incorrecto enviado al RPC: %s", rpc.lastQuery)
+	}
+}
+
+// TestValidateSearchQuery prueba la validación de sintaxis de queries de eventos
+func TestValidateSearchQuery(t *testing.T) {
+	valid := []string{
+		"transfer.sender='0xabc' AND tx.height>10",
+		"tx.height >= 5",
+		"fee.amount EXISTS",
+		"contract_log.address CONTAINS '0x12'",
+		"block.time > TIME 2024-01-01T00:00:00Z",
+		"tx.date = DATE 2024-01-01",
+	}
+	for _, query := range valid {
+		if err := ValidateSearchQuery(query); err != nil {
+			t.Errorf("Query válido rechazado %q: %v", query, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"transfer.sender=0xabc",
+		"transfer.sender",
+		"transfer.sender='0xabc' AND",
+		"transfer.sender='0xabc",
+		"tx.height ~ 5",
+		"= 5",
+		"tx.height CONTAINS 5",
+	}
+	for _, query := range invalid {
+		if err := ValidateSearchQuery(query); !errors.Is(err, ErrInvalidSearchQuery) {
+			t.Errorf("Query inválido aceptado %q: %v", query, err)
+		}
+	}
+}
+
+// TestCallRPC_Deadline prueba que una consulta trabada respete el deadline del contexto
+func TestCallRPC_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := callRPC(ctx, func() (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Se esperaba context.DeadlineExceeded: %v", err)
+	}
+
+	data, err := callRPC(context.Background(), func() (interface{}, error) {
+		return map[string]int64{"height": 5}, nil
+	})
+	if err != nil || string(data) != `{"height":"5"}` {
+		t.Errorf("Resultado inesperado: %s, %v", data, err)
+	}
+}
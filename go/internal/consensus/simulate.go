@@ -0,0 +1,373 @@
+package consensus
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	execution "github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// simulationGenesisTime es el tiempo del bloque 0 de una simulación (los bloques avanzan de a 5 segundos)
+const simulationGenesisTime = 1700000000
+
+// simulationContract es el init code de los contratos que despliega la simulación: copia y retorna un
+// runtime de 10 bytes que responde 42 a cualquier llamada
+var simulationContract = common.FromHex("600a600c600039600a6000f3602a60005260206000f3")
+
+// SimulationOptions configura una simulación de carga
+type SimulationOptions struct {
+	Seed        int64  // Semilla del generador: misma semilla, mismas transacciones
+	Blocks      uint64 // Bloques a producir
+	TxsPerBlock int    // Transacciones por bloque
+	Accounts    int    // Cuentas fondeadas en el genesis que envían las transacciones
+	ChainID     string
+
+	// Parámetros del ValidatorSet (MinStake nil = 1000 OXG)
+	MinStake      *big.Int
+	MaxValidators int
+
+	// Directorio donde se conserva la base de datos de la primera corrida como fixture (vacío = se borra)
+	FixtureDir string
+}
+
+// SimulationResult resume una simulación: las dos corridas con la misma semilla y su comparación
+type SimulationResult struct {
+	Seed         int64                 `json:"seed"`
+	Blocks       uint64                `json:"blocks"`
+	Transactions int                   `json:"transactions"`
+	Succeeded    int                   `json:"succeeded"`
+	Failed       int                   `json:"failed"`
+	ByKind       map[string]int        `json:"byKind"`
+	AppHash      string                `json:"appHash"` // App hash del último bloque de la primera corrida
+	Divergence   *SimulationDivergence `json:"divergence,omitempty"`
+}
+
+// SimulationDivergence es el primer bloque cuyo app hash difiere entre las dos corridas
+type SimulationDivergence struct {
+	Height   uint64 `json:"height"`
+	AppHash1 string `json:"appHash1"`
+	AppHash2 string `json:"appHash2"`
+}
+
+// simulationRun es el resultado de una corrida
+type simulationRun struct {
+	appHashes []string // App hash de cada bloque, desde el 1
+	total     int
+	succeeded int
+	byKind    map[string]int
+}
+
+// Simulate produce opts.Blocks bloques con transacciones pseudoaleatorias (transferencias, despliegues y
+// llamadas a contratos, operaciones de staking) generadas con opts.Seed contra una ABCIApp y un EVM en
+// proceso, dos veces desde el mismo genesis, y compara el app hash de cada bloque entre las corridas.
+// Una divergencia indica estado no determinista (mapas, tiempo de reloj, concurrencia).
+// Las bases de datos se crean en workDir y se borran al terminar, salvo la de la primera corrida si
+// opts.FixtureDir está configurado
+func Simulate(ctx context.Context, workDir string, opts SimulationOptions) (*SimulationResult, error) {
+	if opts.Blocks == 0 {
+		return nil, fmt.Errorf("la simulación necesita al menos un bloque")
+	}
+	if opts.Accounts < 2 {
+		return nil, fmt.Errorf("la simulación necesita al menos 2 cuentas")
+	}
+	if opts.ChainID == "" {
+		opts.ChainID = "oxy-simulation"
+	}
+	if opts.MinStake == nil {
+		opts.MinStake = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	}
+	if opts.MaxValidators == 0 {
+		opts.MaxValidators = 100
+	}
+	if opts.FixtureDir != "" {
+		// El fixture tiene que partir del genesis: no se reutiliza una base de datos existente
+		if entries, err := os.ReadDir(opts.FixtureDir); err == nil && len(entries) > 0 {
+			return nil, fmt.Errorf("el directorio del fixture %s no está vacío", opts.FixtureDir)
+		}
+	}
+
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creando directorio de trabajo: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	firstDir := filepath.Join(workDir, "run-1")
+	if opts.FixtureDir != "" {
+		firstDir = opts.FixtureDir
+	}
+	first, err := simulateRun(ctx, firstDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("primera corrida: %w", err)
+	}
+	second, err := simulateRun(ctx, filepath.Join(workDir, "run-2"), opts)
+	if err != nil {
+		return nil, fmt.Errorf("segunda corrida: %w", err)
+	}
+
+	result := &SimulationResult{
+		Seed:         opts.Seed,
+		Blocks:       opts.Blocks,
+		Transactions: first.total,
+		Succeeded:    first.succeeded,
+		Failed:       first.total - first.succeeded,
+		ByKind:       first.byKind,
+		AppHash:      first.appHashes[len(first.appHashes)-1],
+	}
+	for i := range first.appHashes {
+		if first.appHashes[i] != second.appHashes[i] {
+			result.Divergence = &SimulationDivergence{
+				Height:   uint64(i + 1),
+				AppHash1: first.appHashes[i],
+				AppHash2: second.appHashes[i],
+			}
+			break
+		}
+	}
+	return result, nil
+}
+
+// simulateRun produce los bloques de una corrida en una base de datos nueva en dir
+func simulateRun(ctx context.Context, dir string, opts SimulationOptions) (*simulationRun, error) {
+	workload := newSimulationWorkload(opts)
+	app, closeApp, err := openSimulationApp(dir, opts, workload)
+	if err != nil {
+		return nil, err
+	}
+	defer closeApp()
+
+	run := &simulationRun{byKind: make(map[string]int)}
+	proposer := workload.validatorAddress()
+	for height := uint64(1); height <= opts.Blocks; height++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		txs, kinds, err := workload.block(app)
+		if err != nil {
+			return nil, fmt.Errorf("error generando bloque %d: %w", height, err)
+		}
+		resp, err := app.FinalizeBlock(ctx, &abcitypes.FinalizeBlockRequest{
+			Height:          int64(height),
+			Time:            time.Unix(simulationGenesisTime+int64(height)*5, 0).UTC(),
+			ProposerAddress: common.HexToAddress(proposer).Bytes(),
+			Txs:             txs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error en FinalizeBlock %d: %w", height, err)
+		}
+		if _, err := app.Commit(ctx, &abcitypes.CommitRequest{}); err != nil {
+			return nil, fmt.Errorf("error en Commit %d: %w", height, err)
+		}
+
+		for i, txResult := range resp.TxResults {
+			run.total++
+			run.byKind[kinds[i]]++
+			if txResult.Code == CodeOK {
+				run.succeeded++
+			}
+		}
+		run.appHashes = append(run.appHashes, common.BytesToHash(app.state.AppHash).Hex())
+	}
+	return run, nil
+}
+
+// openSimulationApp crea una ABCIApp sobre una base de datos nueva en dir con el genesis de la simulación:
+// un validador con el doble del stake mínimo y las cuentas del workload fondeadas con 1M OXG
+// La función retornada detiene el EVM y cierra la base de datos
+func openSimulationApp(dir string, opts SimulationOptions, workload *simulationWorkload) (*ABCIApp, func(), error) {
+	db, err := storage.NewBlockchainDB(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	closeApp := func() {
+		evm.Stop()
+		db.Close()
+	}
+
+	validators := NewValidatorSet(db, evm, opts.MinStake, opts.MaxValidators)
+	err = validators.InitializeGenesisValidators([]GenesisValidator{{
+		Address: workload.validatorAddress(),
+		PubKey:  workload.pubKey(),
+		Stake:   new(big.Int).Mul(opts.MinStake, big.NewInt(2)),
+	}})
+	if err != nil {
+		closeApp()
+		return nil, nil, fmt.Errorf("error inicializando validador genesis: %w", err)
+	}
+	funding := new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18))
+	for _, account := range workload.accounts {
+		if err := evm.MintGenesis(account.address.Hex(), funding); err != nil {
+			closeApp()
+			return nil, nil, fmt.Errorf("error fondeando cuenta %s: %w", account.address.Hex(), err)
+		}
+	}
+
+	return NewABCIApp(db, evm, validators, opts.ChainID), closeApp, nil
+}
+
+// simulationAccount es una cuenta de la simulación con su clave derivada de la semilla
+type simulationAccount struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// simulationWorkload genera las transacciones de una simulación. Todo lo que decide depende solo de la
+// semilla y del estado confirmado, por lo que dos corridas con la misma semilla generan los mismos bloques
+type simulationWorkload struct {
+	rng        *rand.Rand
+	opts       SimulationOptions
+	accounts   []simulationAccount
+	contracts  []common.Address // Direcciones de los contratos desplegados (pueden no existir si falló el despliegue)
+	nonces     map[common.Address]uint64
+	nextPubKey uint64
+	seedPrefix []byte
+}
+
+// newSimulationWorkload deriva las cuentas de la semilla
+func newSimulationWorkload(opts SimulationOptions) *simulationWorkload {
+	w := &simulationWorkload{
+		rng:        rand.New(rand.NewSource(opts.Seed)),
+		opts:       opts,
+		seedPrefix: binary.BigEndian.AppendUint64([]byte("oxy-simulation"), uint64(opts.Seed)),
+	}
+	for i := 0; i < opts.Accounts; i++ {
+		key, err := crypto.ToECDSA(crypto.Keccak256(w.seedPrefix, binary.BigEndian.AppendUint64(nil, uint64(i))))
+		if err != nil {
+			// Un keccak fuera del rango de secp256k1 es prácticamente imposible
+			panic(err)
+		}
+		w.accounts = append(w.accounts, simulationAccount{key: key, address: crypto.PubkeyToAddress(key.PublicKey)})
+	}
+	return w
+}
+
+// validatorAddress retorna la dirección del validador genesis (derivada de la semilla)
+func (w *simulationWorkload) validatorAddress() string {
+	return common.BytesToAddress(crypto.Keccak256(w.seedPrefix, []byte("validator"))).Hex()
+}
+
+// pubKey retorna una clave pública de validador nueva (32 bytes derivados de la semilla)
+func (w *simulationWorkload) pubKey() []byte {
+	w.nextPubKey++
+	return crypto.Keccak256(w.seedPrefix, []byte("pubkey"), binary.BigEndian.AppendUint64(nil, w.nextPubKey))
+}
+
+// block genera las transacciones firmadas de un bloque y el tipo de cada una
+// Los nonces parten del estado confirmado y avanzan con cada transacción del bloque
+func (w *simulationWorkload) block(app *ABCIApp) ([][]byte, []string, error) {
+	w.nonces = make(map[common.Address]uint64)
+	txs := make([][]byte, 0, w.opts.TxsPerBlock)
+	kinds := make([]string, 0, w.opts.TxsPerBlock)
+	for i := 0; i < w.opts.TxsPerBlock; i++ {
+		sender := w.accounts[w.rng.Intn(len(w.accounts))]
+		nonce := w.nonce(app, sender.address)
+		tx, kind := w.next(app, sender, nonce)
+		tx.From = sender.address.Hex()
+		tx.GasPrice = "1"
+		tx.Nonce = nonce
+		if err := signSimulationTx(tx, sender.key); err != nil {
+			return nil, nil, err
+		}
+		data, err := json.Marshal(tx)
+		if err != nil {
+			return nil, nil, err
+		}
+		txs = append(txs, data)
+		kinds = append(kinds, kind)
+	}
+	return txs, kinds, nil
+}
+
+// next genera una transacción sin firmar del remitente: transferencias (50%), despliegues (15%),
+// llamadas a contratos (15%) y operaciones de staking (20%)
+func (w *simulationWorkload) next(app *ABCIApp, sender simulationAccount, nonce uint64) (*Transaction, string) {
+	oxg := big.NewInt(1e18)
+	switch roll := w.rng.Intn(100); {
+	case roll < 50:
+		to := w.accounts[w.rng.Intn(len(w.accounts))].address
+		// Una de cada diez transferencias va a una cuenta nueva
+		if w.rng.Intn(10) == 0 {
+			to = common.BytesToAddress(crypto.Keccak256(w.seedPrefix, binary.BigEndian.AppendUint64(nil, w.rng.Uint64())))
+		}
+		value := new(big.Int).Mul(big.NewInt(w.rng.Int63n(1000)+1), big.NewInt(1e15))
+		return &Transaction{To: to.Hex(), Value: value.String(), GasLimit: 21000}, "transfer"
+
+	case roll < 65:
+		contract := crypto.CreateAddress(sender.address, nonce)
+		w.contracts = append(w.contracts, contract)
+		return &Transaction{Value: "0", Data: simulationContract, GasLimit: 200000}, "deploy"
+
+	case roll < 80 && len(w.contracts) > 0:
+		contract := w.contracts[w.rng.Intn(len(w.contracts))]
+		return &Transaction{To: contract.Hex(), Value: "0", Data: []byte{byte(w.rng.Intn(256))}, GasLimit: 50000}, "call"
+
+	case roll < 80:
+		to := w.accounts[w.rng.Intn(len(w.accounts))].address
+		return &Transaction{To: to.Hex(), Value: "1", GasLimit: 21000}, "transfer"
+	}
+
+	var payload StakingPayload
+	value := big.NewInt(0)
+	if _, err := app.validators.GetValidator(sender.address.Hex()); err != nil {
+		payload = StakingPayload{Action: StakeActionBond, PubKey: hex.EncodeToString(w.pubKey())}
+		value.Add(w.opts.MinStake, new(big.Int).Mul(big.NewInt(w.rng.Int63n(1000)), oxg))
+	} else {
+		switch w.rng.Intn(3) {
+		case 0:
+			payload = StakingPayload{Action: StakeActionStake}
+			value.Mul(big.NewInt(w.rng.Int63n(100)+1), oxg)
+		case 1:
+			payload = StakingPayload{Action: StakeActionUnstake, Amount: new(big.Int).Mul(big.NewInt(w.rng.Int63n(100)+1), oxg).String()}
+		default:
+			payload = StakingPayload{Action: StakeActionWithdrawRewards}
+		}
+	}
+	data, _ := json.Marshal(payload)
+	return &Transaction{To: StakingAddress, Value: value.String(), Data: data, GasLimit: 100000}, "staking:" + payload.Action
+}
+
+// nonce retorna el siguiente nonce del remitente en el bloque en curso y lo reserva
+func (w *simulationWorkload) nonce(app *ABCIApp, address common.Address) uint64 {
+	nonce, ok := w.nonces[address]
+	if !ok {
+		if state, err := app.executor.GetCommittedState(address.Hex()); err == nil && state != nil {
+			nonce = state.Nonce
+		}
+	}
+	w.nonces[address] = nonce + 1
+	return nonce
+}
+
+// signSimulationTx calcula el hash de la transacción y la firma como lo haría pkg/txsign
+func signSimulationTx(tx *Transaction, key *ecdsa.PrivateKey) error {
+	hash, err := signedFields(tx).Hash()
+	if err != nil {
+		return fmt.Errorf("error calculando hash: %w", err)
+	}
+	signature, err := crypto.Sign(hash.Bytes(), key)
+	if err != nil {
+		return fmt.Errorf("error firmando transacción: %w", err)
+	}
+	tx.Hash = hash.Hex()
+	tx.Signature = signature
+	return nil
+}
@@ -0,0 +1,35 @@
+package consensus
+
+import (
+	"context"
+	"testing"
+)
+
+// TestSimulate_Deterministic prueba que dos corridas con la misma semilla lleguen al mismo app hash y
+// que otra semilla genere otra cadena
+func TestSimulate_Deterministic(t *testing.T) {
+	opts := SimulationOptions{Seed: 7, Blocks: 5, TxsPerBlock: 10, Accounts: 4}
+	result, err := Simulate(context.Background(), t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("Error en Simulate: %v", err)
+	}
+	if result.Divergence != nil {
+		t.Fatalf("App hash no determinista: %+v", result.Divergence)
+	}
+	if result.Transactions != 50 || result.Succeeded == 0 {
+		t.Errorf("Resultado inesperado: %+v", result)
+	}
+
+	opts.Seed = 8
+	other, err := Simulate(context.Background(), t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("Error en Simulate: %v", err)
+	}
+	if other.AppHash == result.AppHash {
+		t.Error("Semillas distintas deberían generar estados distintos")
+	}
+
+	if _, err := Simulate(context.Background(), t.TempDir(), SimulationOptions{Blocks: 1, Accounts: 1}); err == nil {
+		t.Error("Una simulación con una sola cuenta debería rechazarse")
+	}
+}
@@ -0,0 +1,220 @@
+package consensus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	execution "github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/snapshot"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cometcfg "github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/node"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// snapshotBootstrapMarker indica que el estado de CometBFT se inicializó desde un snapshot
+// y que sus bases de datos no deben borrarse en el próximo inicio
+const snapshotBootstrapMarker = ".snapshot_bootstrap"
+
+// SnapshotDir retorna el directorio de snapshots de un nodo
+func SnapshotDir(dataDir string) string {
+	return filepath.Join(dataDir, "snapshots")
+}
+
+// snapshotSources retorna las bases de datos que forman un snapshot
+// El storage va primero: contiene el root con el que se reabre el estado EVM al importarlo
+func snapshotSources(storage *storage.BlockchainDB, executor *execution.EVMExecutor) []snapshot.Source {
+	return []snapshot.Source{
+		{Name: "blockchain", Store: storage},
+		{Name: "evm_state", Store: executor.GetStateManager()},
+	}
+}
+
+// restoredSnapshot es el registro de un snapshot restaurado fuera de banda, pendiente del primer bloque
+type restoredSnapshot struct {
+	Height  int64  `json:"height"`
+	AppHash string `json:"app_hash"`
+}
+
+// SetSnapshotStore habilita la generación de snapshots cada interval bloques (0 = solo servir y restaurar)
+// conservando los keepRecent más recientes (0 = todos)
+func (app *ABCIApp) SetSnapshotStore(store *snapshot.Store, interval uint64, keepRecent int) {
+	app.snapshotStore = store
+	app.snapshotInterval = interval
+	app.snapshotKeepRecent = keepRecent
+}
+
+// loadRestoredSnapshot retoma la altura y app hash de un snapshot restaurado con `snapshot restore`
+// para que CometBFT continúe desde ahí en lugar de reejecutar la cadena desde genesis
+func (app *ABCIApp) loadRestoredSnapshot() {
+	data, err := app.storage.GetSnapshotRestore()
+	if err != nil || data == nil {
+		return
+	}
+
+	var restored restoredSnapshot
+	if err := json.Unmarshal(data, &restored); err != nil {
+		consensusLog.Warn("Registro de snapshot restaurado inválido: " + err.Error())
+		return
+	}
+
+	app.state.Height = restored.Height
+	app.state.AppHash = common.FromHex(restored.AppHash)
+	app.restoredHeight = restored.Height
+}
+
+// clearRestoredSnapshot borra el registro del snapshot restaurado una vez que la cadena avanzó
+func (app *ABCIApp) clearRestoredSnapshot() {
+	if app.restoredHeight == 0 {
+		return
+	}
+	if err := app.storage.DeleteSnapshotRestore(); err != nil {
+		consensusLog.Warn("Error borrando registro de snapshot restaurado: " + err.Error())
+		return
+	}
+	app.restoredHeight = 0
+}
+
+// maybeCreateSnapshot genera un snapshot si la altura es múltiplo del intervalo configurado
+// Se ejecuta dentro de Commit para que las bases de datos no cambien mientras se exportan
+func (app *ABCIApp) maybeCreateSnapshot(height uint64, appHash []byte) {
+	if app.snapshotStore == nil || app.snapshotInterval == 0 || height == 0 || height%app.snapshotInterval != 0 {
+		return
+	}
+
+	meta, err := app.snapshotStore.Create(height, appHash, snapshotSources(app.storage, app.executor))
+	if err != nil {
+		consensusLog.Warn(fmt.Sprintf("Error generando snapshot en altura %d: %v", height, err))
+		return
+	}
+	consensusLog.Infof("Snapshot generado: altura=%d, tamaño=%d bytes, chunks=%d", meta.Height, meta.Size, meta.Chunks)
+
+	if err := app.snapshotStore.Prune(app.snapshotKeepRecent); err != nil {
+		consensusLog.Warn("Error podando snapshots: " + err.Error())
+	}
+}
+
+// toABCISnapshot convierte la metadata de un snapshot al formato de state sync
+func toABCISnapshot(meta *snapshot.Metadata) (*abcitypes.Snapshot, error) {
+	metadata, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	return &abcitypes.Snapshot{
+		Height:   meta.Height,
+		Format:   meta.Format,
+		Chunks:   meta.Chunks,
+		Hash:     meta.Hash,
+		Metadata: metadata,
+	}, nil
+}
+
+// checkOfferedSnapshot valida un snapshot ofrecido por state sync contra el app hash verificado por el light client
+func checkOfferedSnapshot(offered *abcitypes.Snapshot, trustedAppHash []byte) (*snapshot.Metadata, error) {
+	var meta snapshot.Metadata
+	if err := json.Unmarshal(offered.Metadata, &meta); err != nil {
+		return nil, fmt.Errorf("metadata inválida: %w", err)
+	}
+	if meta.Height != offered.Height || meta.Format != offered.Format || meta.Chunks != offered.Chunks || !bytes.Equal(meta.Hash, offered.Hash) {
+		return nil, fmt.Errorf("la metadata no coincide con el snapshot ofrecido")
+	}
+	if !bytes.Equal(meta.AppHash, trustedAppHash) {
+		return nil, fmt.Errorf("app hash del snapshot no coincide con el de la cadena")
+	}
+	return &meta, nil
+}
+
+// restoreSnapshot importa un snapshot ya recibido y verificado en las bases de datos de la aplicación
+func (app *ABCIApp) restoreSnapshot(meta *snapshot.Metadata) error {
+	if err := app.snapshotStore.Restore(meta.Height, snapshotSources(app.storage, app.executor)); err != nil {
+		return err
+	}
+	app.executor.ReloadState()
+	if app.validators != nil {
+		if err := app.validators.LoadValidators(); err != nil {
+			consensusLog.Warn("Error recargando validadores del snapshot: " + err.Error())
+		}
+	}
+
+	app.state.Height = int64(meta.Height)
+	app.state.AppHash = append([]byte(nil), meta.AppHash...)
+	consensusLog.Infof("Snapshot restaurado: altura=%d", meta.Height)
+	return nil
+}
+
+// applyStateSync configura el state sync de CometBFT; retorna false si no hay servidores RPC configurados
+func (c *Config) applyStateSync(stateSync *cometcfg.StateSyncConfig) (bool, error) {
+	if c.StateSyncRPCServers == "" {
+		return false, nil
+	}
+
+	stateSync.Enable = true
+	stateSync.RPCServers = strings.Split(c.StateSyncRPCServers, ",")
+	stateSync.TrustHeight = c.StateSyncTrustHeight
+	stateSync.TrustHash = c.StateSyncTrustHash
+	if err := stateSync.ValidateBasic(); err != nil {
+		return false, fmt.Errorf("configuración de state sync inválida: %w", err)
+	}
+	return true, nil
+}
+
+// RestoreSnapshot restaura con el nodo detenido un snapshot del store local (ej: importado de un archivo):
+// reemplaza las bases de datos de la aplicación e inicializa el estado de CometBFT en la altura del
+// snapshot, verificando el app hash con el light client (requiere OXY_STATESYNC_RPC_SERVERS y punto de confianza)
+func RestoreSnapshot(ctx context.Context, cfg *Config, storage *storage.BlockchainDB, executor *execution.EVMExecutor, store *snapshot.Store, height uint64) error {
+	meta, err := store.Get(height)
+	if err != nil {
+		return err
+	}
+
+	cometConfig := cometcfg.DefaultConfig()
+	cometConfig.SetRoot(filepath.Join(cfg.DataDir, "cometbft"))
+	enabled, err := cfg.applyStateSync(cometConfig.StateSync)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return fmt.Errorf("restaurar un snapshot requiere OXY_STATESYNC_RPC_SERVERS para verificar el app hash con el light client")
+	}
+
+	if err := store.Restore(height, snapshotSources(storage, executor)); err != nil {
+		return fmt.Errorf("error restaurando snapshot: %w", err)
+	}
+	executor.ReloadState()
+
+	restored, err := json.Marshal(restoredSnapshot{
+		Height:  int64(meta.Height),
+		AppHash: common.BytesToHash(meta.AppHash).Hex(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := storage.SaveSnapshotRestore(restored); err != nil {
+		return fmt.Errorf("error guardando registro de snapshot restaurado: %w", err)
+	}
+
+	// BootstrapState requiere las bases de datos de CometBFT vacías
+	dataDir := filepath.Join(cometConfig.RootDir, "data")
+	if err := os.RemoveAll(dataDir); err != nil {
+		return fmt.Errorf("error limpiando datos de CometBFT: %w", err)
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return fmt.Errorf("error creando directorio data de CometBFT: %w", err)
+	}
+
+	err = node.BootstrapState(ctx, cometConfig, cometcfg.DefaultDBProvider, node.DefaultGenesisDocProviderFunc(cometConfig), meta.Height, meta.AppHash)
+	if err != nil {
+		return fmt.Errorf("error inicializando estado de CometBFT: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dataDir, snapshotBootstrapMarker), []byte(fmt.Sprintf("%d\n", meta.Height)), 0644); err != nil {
+		return fmt.Errorf("error guardando marcador de snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package consensus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	execution "github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/snapshot"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cometcfg "github.com/cometbft/cometbft/config"
+)
+
+// newSnapshotTestApp crea una app ABCI con storage, EVM y store de snapshots propios
+func newSnapshotTestApp(t *testing.T, name string) (*ABCIApp, *snapshot.Store) {
+	testDir := createTestDir(name)
+	t.Cleanup(func() { cleanupTestDir(testDir) })
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	t.Cleanup(func() {
+		evm.Stop()
+		db.Close()
+	})
+
+	store, err := snapshot.NewStore(SnapshotDir(testDir))
+	if err != nil {
+		t.Fatalf("Error creando store de snapshots: %v", err)
+	}
+	store.SetChunkSize(1024)
+
+	app := NewABCIApp(db, evm, nil, "test-chain")
+	app.SetSnapshotStore(store, 2, 1)
+	return app, store
+}
+
+// TestABCIApp_StateSyncSnapshots genera snapshots en Commit y los restaura en otro nodo por state sync
+func TestABCIApp_StateSyncSnapshots(t *testing.T) {
+	ctx := context.Background()
+	source, _ := newSnapshotTestApp(t, "snapshot_source")
+
+	account := "0x1234567890123456789012345678901234567890"
+	if err := source.executor.FundAccount(account, "5000000000000000000"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+
+	for height := int64(1); height <= 4; height++ {
+		if _, err := source.FinalizeBlock(ctx, &abcitypes.FinalizeBlockRequest{Height: height, Time: time.Now()}); err != nil {
+			t.Fatalf("Error en FinalizeBlock %d: %v", height, err)
+		}
+		if _, err := source.Commit(ctx, &abcitypes.CommitRequest{}); err != nil {
+			t.Fatalf("Error en Commit %d: %v", height, err)
+		}
+	}
+
+	// Intervalo 2 y keep-recent 1: solo queda el snapshot de la altura 4
+	listResp, err := source.ListSnapshots(ctx, &abcitypes.ListSnapshotsRequest{})
+	if err != nil {
+		t.Fatalf("Error en ListSnapshots: %v", err)
+	}
+	if len(listResp.Snapshots) != 1 || listResp.Snapshots[0].Height != 4 {
+		t.Fatalf("Se esperaba un snapshot en altura 4, hay %d", len(listResp.Snapshots))
+	}
+	offered := listResp.Snapshots[0]
+	appHash := append([]byte(nil), source.state.AppHash...)
+
+	target, _ := newSnapshotTestApp(t, "snapshot_target")
+
+	// Un app hash distinto al verificado por el light client se rechaza
+	offerResp, _ := target.OfferSnapshot(ctx, &abcitypes.OfferSnapshotRequest{Snapshot: offered, AppHash: []byte("otro")})
+	if offerResp.Result != abcitypes.OFFER_SNAPSHOT_RESULT_REJECT {
+		t.Fatalf("Snapshot con app hash distinto debería rechazarse: %v", offerResp.Result)
+	}
+
+	offerResp, _ = target.OfferSnapshot(ctx, &abcitypes.OfferSnapshotRequest{Snapshot: offered, AppHash: appHash})
+	if offerResp.Result != abcitypes.OFFER_SNAPSHOT_RESULT_ACCEPT {
+		t.Fatalf("Snapshot válido debería aceptarse: %v", offerResp.Result)
+	}
+
+	for i := uint32(0); i < offered.Chunks; i++ {
+		chunkResp, err := source.LoadSnapshotChunk(ctx, &abcitypes.LoadSnapshotChunkRequest{Height: offered.Height, Format: offered.Format, Chunk: i})
+		if err != nil || len(chunkResp.Chunk) == 0 {
+			t.Fatalf("Error cargando chunk %d: %v", i, err)
+		}
+		applyResp, err := target.ApplySnapshotChunk(ctx, &abcitypes.ApplySnapshotChunkRequest{Index: i, Chunk: chunkResp.Chunk})
+		if err != nil || applyResp.Result != abcitypes.APPLY_SNAPSHOT_CHUNK_RESULT_ACCEPT {
+			t.Fatalf("Chunk %d no aplicado: %v, %v", i, applyResp, err)
+		}
+	}
+
+	infoResp, _ := target.Info(ctx, &abcitypes.InfoRequest{})
+	if infoResp.LastBlockHeight != 4 || string(infoResp.LastBlockAppHash) != string(appHash) {
+		t.Errorf("Info debería reportar la altura y app hash del snapshot: %d %X", infoResp.LastBlockHeight, infoResp.LastBlockAppHash)
+	}
+
+	accountState, err := target.executor.GetState(account)
+	if err != nil {
+		t.Fatalf("Error obteniendo cuenta restaurada: %v", err)
+	}
+	if accountState.Balance != "5000000000000000000" {
+		t.Errorf("Balance restaurado incorrecto: %v", accountState.Balance)
+	}
+}
+
+// TestApplyStateSync prueba la configuración de state sync desde la configuración del nodo
+func TestApplyStateSync(t *testing.T) {
+	stateSync := cometcfg.DefaultStateSyncConfig()
+	enabled, err := (&Config{}).applyStateSync(stateSync)
+	if err != nil || enabled || stateSync.Enable {
+		t.Fatalf("Sin servidores RPC el state sync debe quedar deshabilitado: %v, %v", enabled, err)
+	}
+
+	cfg := &Config{
+		StateSyncRPCServers:  "node1:26657,node2:26657",
+		StateSyncTrustHeight: 100,
+		StateSyncTrustHash:   "0A1B2C3D4E5F60718293A4B5C6D7E8F90A1B2C3D4E5F60718293A4B5C6D7E8F9",
+	}
+	enabled, err = cfg.applyStateSync(stateSync)
+	if err != nil || !enabled || !stateSync.Enable || len(stateSync.RPCServers) != 2 {
+		t.Fatalf("State sync debería habilitarse: %v, %v, %+v", enabled, err, stateSync)
+	}
+
+	// Sin punto de confianza la configuración es inválida
+	if _, err := (&Config{StateSyncRPCServers: "node1:26657,node2:26657"}).applyStateSync(cometcfg.DefaultStateSyncConfig()); err == nil {
+		t.Error("State sync sin trust height/hash debería rechazarse")
+	}
+}
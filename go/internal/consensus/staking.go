@@ -0,0 +1,166 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// StakingAddress es la dirección reservada que recibe las transacciones de staking
+// El valor enviado queda en custodia en esta cuenta mientras el stake esté activo
+const StakingAddress = "0x0000000000000000000000000000000000000100"
+
+// StakingPayload es el contenido (JSON en Transaction.Data) de una transacción de staking
+type StakingPayload struct {
+	Action     string      `json:"action"`               // bond, stake, unstake, unjail, set-commission o withdraw-rewards
+	PubKey     string      `json:"pubKey,omitempty"`     // Clave pública ed25519 en hex (solo bond)
+	Amount     string      `json:"amount,omitempty"`     // Cantidad a retirar (solo unstake)
+	Commission *Commission `json:"commission,omitempty"` // Comisión inicial (bond, opcional) o nueva tasa en rateBps (set-commission)
+}
+
+// isStakingTx retorna si la transacción va dirigida al módulo de staking
+func isStakingTx(tx *Transaction) bool {
+	return strings.EqualFold(tx.To, StakingAddress)
+}
+
+// applyStakingTx aplica al ValidatorSet una transacción de staking ya ejecutada con éxito
+// Los cambios de stake emiten sus eventos a través del handler registrado en NewABCIApp
+// Si la acción falla, el valor transferido a la custodia se devuelve al remitente
+func (app *ABCIApp) applyStakingTx(tx *Transaction) error {
+	return app.refundFailedStake(tx, app.executeStakingAction(tx))
+}
+
+// refundFailedStake devuelve al remitente el valor transferido a la custodia si la acción de staking falló
+func (app *ABCIApp) refundFailedStake(tx *Transaction, err error) error {
+	if err == nil {
+		return nil
+	}
+	if value, ok := new(big.Int).SetString(tx.Value, 10); ok && value.Sign() > 0 {
+		if refundErr := app.executor.TransferBalance(StakingAddress, tx.From, value); refundErr != nil {
+			return fmt.Errorf("%v (error devolviendo fondos: %v)", err, refundErr)
+		}
+	}
+	return err
+}
+
+// executeStakingAction decodifica el payload y ejecuta la acción sobre el ValidatorSet
+func (app *ABCIApp) executeStakingAction(tx *Transaction) error {
+	if app.validators == nil {
+		return fmt.Errorf("staking no disponible: sin conjunto de validadores")
+	}
+
+	var payload StakingPayload
+	if err := json.Unmarshal(tx.Data, &payload); err != nil {
+		return fmt.Errorf("payload de staking inválido: %w", err)
+	}
+
+	switch payload.Action {
+	case StakeActionBond:
+		amount, err := stakingValue(tx.Value)
+		if err != nil {
+			return err
+		}
+		pubKey, err := hex.DecodeString(strings.TrimPrefix(payload.PubKey, "0x"))
+		if err != nil || len(pubKey) != 32 {
+			return fmt.Errorf("clave pública de validador inválida")
+		}
+		// La comisión se valida antes del registro para no dejar un validador a medio configurar
+		if payload.Commission != nil {
+			if err := payload.Commission.Validate(); err != nil {
+				return err
+			}
+		}
+		if _, err = app.validators.RegisterValidator(tx.From, pubKey, amount); err != nil {
+			return err
+		}
+		if payload.Commission != nil {
+			return app.validators.configureCommission(tx.From, *payload.Commission)
+		}
+		return nil
+
+	case StakeActionStake:
+		amount, err := stakingValue(tx.Value)
+		if err != nil {
+			return err
+		}
+		return app.validators.Stake(tx.From, amount)
+
+	case StakeActionUnstake:
+		amount, ok := new(big.Int).SetString(payload.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			return fmt.Errorf("cantidad de unstake inválida: %s", payload.Amount)
+		}
+		if err := app.validators.Unstake(tx.From, amount); err != nil {
+			return err
+		}
+		// Devolver los fondos liberados desde los pools de staking
+		return app.releaseStake(tx.From, amount)
+
+	case StakeActionUnjail:
+		// El operador pide volver al set una vez cumplido el jail (y con el stake mínimo)
+		if err := noStakingValue(tx.Value); err != nil {
+			return err
+		}
+		return app.validators.Unjail(tx.From)
+
+	case StakeActionSetCommission:
+		if err := noStakingValue(tx.Value); err != nil {
+			return err
+		}
+		if payload.Commission == nil {
+			return fmt.Errorf("falta la comisión (commission.rateBps)")
+		}
+		return app.validators.SetCommission(tx.From, payload.Commission.RateBps)
+
+	case StakeActionWithdrawRewards:
+		if err := noStakingValue(tx.Value); err != nil {
+			return err
+		}
+		amount, err := app.validators.WithdrawRewards(tx.From)
+		if err != nil {
+			return err
+		}
+		// Las recompensas se pagan desde la cuenta que las custodia
+		if err := app.executor.TransferBalance(RewardsAddress, tx.From, amount); err != nil {
+			return err
+		}
+		app.recordRewardsEvent(tx.From, amount)
+		return nil
+
+	default:
+		return fmt.Errorf("acción de staking desconocida: %s", payload.Action)
+	}
+}
+
+// noStakingValue verifica que una acción sin depósito (unjail, set-commission, withdraw-rewards) no envíe valor
+func noStakingValue(value string) error {
+	if amount, ok := new(big.Int).SetString(value, 10); ok && amount.Sign() != 0 {
+		return fmt.Errorf("la acción no admite valor: %s", value)
+	}
+	return nil
+}
+
+// stakingValue parsea el valor enviado con una transacción de bond/stake
+func stakingValue(value string) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("valor de staking inválido: %q", value)
+	}
+	return amount, nil
+}
+
+// distributeBlockReward reparte la recompensa del bloque entre los firmantes del bloque anterior
+// y emite el monto repartido a RewardsAddress, desde donde se paga con withdraw-rewards
+func (app *ABCIApp) distributeBlockReward(votes []abcitypes.VoteInfo) {
+	distributed := app.validators.DistributeRewards(votes)
+	if distributed.Sign() == 0 {
+		return
+	}
+	if err := app.executor.MintReward(RewardsAddress, distributed); err != nil {
+		consensusLog.Errorf("Error emitiendo recompensas del bloque: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DefaultSyncCheckInterval es el intervalo por defecto de actualización del estado de sync
+const DefaultSyncCheckInterval = 5 * time.Second
+
+// SyncStatus describe el estado de sincronización del nodo respecto a sus peers
+type SyncStatus struct {
+	CatchingUp    bool  `json:"catchingUp"`    // CometBFT está en blocksync/statesync
+	LatestHeight  int64 `json:"latestHeight"`  // Última altura confirmada localmente
+	MaxPeerHeight int64 `json:"maxPeerHeight"` // Mayor altura conocida de la red
+	BlocksBehind  int64 `json:"blocksBehind"`  // Bloques de atraso respecto a la mayor altura conocida
+	Peers         int   `json:"peers"`         // Peers conectados
+}
+
+// rpcNetInfo es el subconjunto de la respuesta de net_info que usamos
+type rpcNetInfo struct {
+	NPeers string `json:"n_peers"`
+}
+
+// syncCheckInterval retorna el intervalo configurado, usando el valor por defecto si no se especificó
+func (c *Config) syncCheckInterval() time.Duration {
+	if c.SyncCheckInterval <= 0 {
+		return DefaultSyncCheckInterval
+	}
+	return c.SyncCheckInterval
+}
+
+// SetPeerHeightProvider establece la función que retorna la mayor altura conocida de la red
+// (ej: headers recibidos por mesh). Sin proveedor solo se reporta catching_up de CometBFT
+func (c *CometBFT) SetPeerHeightProvider(provider func() int64) {
+	c.peerHeight = provider
+}
+
+// GetSyncStatus retorna si el nodo está sincronizando y cuántos bloques está atrasado
+func (c *CometBFT) GetSyncStatus(ctx context.Context) (*SyncStatus, error) {
+	if !c.running {
+		return nil, fmt.Errorf("consenso no está corriendo")
+	}
+
+	client, err := c.rpc()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("status falló: %w", err)
+	}
+
+	var status rpcStatus
+	if err := json.Unmarshal(result, &status); err != nil {
+		return nil, fmt.Errorf("error decodificando status: %w", err)
+	}
+
+	latestHeight, _ := strconv.ParseInt(status.SyncInfo.LatestBlockHeight, 10, 64)
+	syncStatus := &SyncStatus{
+		CatchingUp:    status.SyncInfo.CatchingUp,
+		LatestHeight:  latestHeight,
+		MaxPeerHeight: latestHeight,
+	}
+
+	peers, err := c.peerCount(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	syncStatus.Peers = peers
+
+	if maxPeerHeight := c.maxPeerHeight(); maxPeerHeight > syncStatus.MaxPeerHeight {
+		syncStatus.MaxPeerHeight = maxPeerHeight
+	}
+	syncStatus.BlocksBehind = syncStatus.MaxPeerHeight - syncStatus.LatestHeight
+
+	return syncStatus, nil
+}
+
+// peerCount retorna la cantidad de peers conectados según net_info
+func (c *CometBFT) peerCount(ctx context.Context, client cometRPC) (int, error) {
+	result, err := client.NetInfo(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("net_info falló: %w", err)
+	}
+
+	var netInfo rpcNetInfo
+	if err := json.Unmarshal(result, &netInfo); err != nil {
+		return 0, fmt.Errorf("error decodificando net_info: %w", err)
+	}
+
+	peers, _ := strconv.Atoi(netInfo.NPeers)
+	return peers, nil
+}
+
+// maxPeerHeight retorna la mayor altura conocida de la red (0 si no hay proveedor)
+func (c *CometBFT) maxPeerHeight() int64 {
+	if c.peerHeight == nil {
+		return 0
+	}
+	return c.peerHeight()
+}
+
+// syncStatusLoop actualiza periódicamente el estado de sincronización en el health checker
+func (c *CometBFT) syncStatusLoop(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.updateSyncStatus()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateSyncStatus consulta el estado de sync y lo refleja en el health checker
+func (c *CometBFT) updateSyncStatus() {
+	ctx, cancel := context.WithTimeout(c.ctx, 3*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	syncStatus, err := c.GetSyncStatus(ctx)
+	if err != nil {
+		// Sin información de sync: el componente queda en warning con la causa
+		c.healthChecker.ReportCheck("sync", time.Since(start), fmt.Errorf("error consultando el estado de sync: %w", err))
+		return
+	}
+
+	c.healthChecker.SetSyncStatus(syncStatus.CatchingUp, syncStatus.BlocksBehind)
+	c.healthChecker.ReportCheck("sync", time.Since(start), nil)
+	c.healthChecker.SetPeers(syncStatus.Peers)
+	if syncStatus.LatestHeight > 0 {
+		c.healthChecker.SetBlockHeight(uint64(syncStatus.LatestHeight))
+	}
+}
@@ -0,0 +1,20 @@
+package consensus
+
+// runSystemCalls llama a los contratos de sistema de un hook (ver execution.SystemContracts)
+// Un contrato que falla no detiene el bloque: sus cambios se descartan y se registra el error
+func (app *ABCIApp) runSystemCalls(hook string) {
+	if app.executor == nil {
+		return
+	}
+	results, err := app.executor.RunSystemCalls(hook)
+	if err != nil {
+		consensusLog.Errorf("Error en llamadas de sistema %s (bloque %d): %v", hook, app.currentBlockHeight, err)
+		return
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			consensusLog.Warnf("Contrato de sistema %s falló en %s (bloque %d): %v",
+				result.Contract, hook, app.currentBlockHeight, result.Err)
+		}
+	}
+}
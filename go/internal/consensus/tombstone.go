@@ -0,0 +1,189 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// JailReasonDoubleSign es el motivo del jail permanente por doble firma (tombstone)
+const JailReasonDoubleSign = "double-sign"
+
+// DoubleSignSlashPercent es el porcentaje del stake que se descuenta por una doble firma
+const DoubleSignSlashPercent = 5
+
+// misbehaviorDuplicateVote es MISBEHAVIOR_TYPE_DUPLICATE_VOTE de la evidencia de CometBFT
+const misbehaviorDuplicateVote = 1
+
+// validatorTombstonesKey es la clave de storage de la lista de tombstones
+const validatorTombstonesKey = "validators:tombstones"
+
+// Tombstone es el registro permanente de un validador expulsado por doble firma
+// Ni la dirección ni la clave pública pueden volver a entrar al set, aunque agreguen stake
+type Tombstone struct {
+	Address        string    `json:"address"`
+	PubKey         string    `json:"pubKey"`         // Clave pública ed25519 en hex
+	Height         uint64    `json:"height"`         // Bloque en que se aplicó
+	Time           time.Time `json:"time"`           // Hora de ese bloque
+	EvidenceHeight int64     `json:"evidenceHeight"` // Altura de la doble firma
+	SlashAmount    string    `json:"slashAmount"`
+}
+
+// tombstoneKey es la clave de una dirección en la lista de tombstones
+func tombstoneKey(address string) string {
+	return strings.ToLower(address)
+}
+
+// isTombstoned retorna si la dirección o la clave pública tienen tombstone (requiere lock)
+func (vs *ValidatorSet) isTombstoned(address string, pubKey []byte) bool {
+	if _, ok := vs.tombstones[tombstoneKey(address)]; ok {
+		return true
+	}
+	if len(pubKey) == 0 {
+		return false
+	}
+	encoded := hex.EncodeToString(pubKey)
+	for _, t := range vs.tombstones {
+		if t.PubKey == encoded {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTombstoned retorna si la dirección fue expulsada por doble firma
+func (vs *ValidatorSet) IsTombstoned(address string) bool {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+	return vs.isTombstoned(address, nil)
+}
+
+// GetTombstones retorna la lista de tombstones, del más antiguo al más reciente
+func (vs *ValidatorSet) GetTombstones() []Tombstone {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+
+	tombstones := make([]Tombstone, 0, len(vs.tombstones))
+	for _, t := range vs.tombstones {
+		tombstones = append(tombstones, *t)
+	}
+	sort.Slice(tombstones, func(i, j int) bool {
+		if tombstones[i].Height != tombstones[j].Height {
+			return tombstones[i].Height < tombstones[j].Height
+		}
+		return tombstones[i].Address < tombstones[j].Address
+	})
+	return tombstones
+}
+
+// HandleMisbehavior aplica la evidencia de FinalizeBlock: una doble firma hace tombstone del validador
+// Los demás tipos de evidencia solo se registran en el log
+func (vs *ValidatorSet) HandleMisbehavior(misbehavior []abcitypes.Misbehavior) {
+	if len(misbehavior) == 0 {
+		return
+	}
+	vs.mutex.RLock()
+	byConsensusAddress := vs.consensusAddresses()
+	vs.mutex.RUnlock()
+
+	for _, m := range misbehavior {
+		consensusAddress := fmt.Sprintf("%X", m.Validator.Address)
+		address, ok := byConsensusAddress[consensusAddress]
+		if !ok {
+			consensusLog.Warnf("Evidencia de un validador desconocido (%s) en la altura %d", consensusAddress, m.Height)
+			continue
+		}
+		if m.Type != misbehaviorDuplicateVote {
+			consensusLog.Warnf("Evidencia de tipo %v contra %s en la altura %d: sin penalización", m.Type, address, m.Height)
+			continue
+		}
+		if err := vs.Tombstone(address, m.Height); err != nil {
+			consensusLog.Warnf("No se aplicó el tombstone de %s: %v", address, err)
+		}
+	}
+}
+
+// Tombstone penaliza una doble firma: descuenta DoubleSignSlashPercent del stake y envía al validador
+// a jail de forma permanente. Sale del set de CometBFT al final del bloque y no puede volver (unjail,
+// stake y bond con la misma dirección o clave pública se rechazan); solo puede retirar su stake
+func (vs *ValidatorSet) Tombstone(address string, evidenceHeight int64) error {
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+
+	validator, exists := vs.validators[address]
+	if !exists {
+		return fmt.Errorf("validador no encontrado: %s", address)
+	}
+	// La misma doble firma puede llegar en varias evidencias: se penaliza una sola vez
+	if validator.Tombstoned {
+		return nil
+	}
+
+	validator.Tombstoned = true
+	slashAmount := vs.slashLocked(validator, DoubleSignSlashPercent, 0, JailReasonDoubleSign)
+	validator.JailedUntil = time.Time{}
+
+	if vs.tombstones == nil {
+		vs.tombstones = make(map[string]*Tombstone)
+	}
+	vs.tombstones[tombstoneKey(address)] = &Tombstone{
+		Address:        address,
+		PubKey:         hex.EncodeToString(validator.PubKey),
+		Height:         vs.height,
+		Time:           vs.now(),
+		EvidenceHeight: evidenceHeight,
+		SlashAmount:    slashAmount.String(),
+	}
+	consensusLog.Warnf("Validador %s con tombstone por doble firma en la altura %d (slash %s)", address, evidenceHeight, slashAmount.String())
+	vs.saveTombstones()
+
+	if err := vs.saveValidatorsLocked(); err != nil {
+		consensusLog.Warnf("Error guardando validadores: %v", err)
+	}
+	return nil
+}
+
+// loadTombstones lee la lista de tombstones de storage (requiere lock)
+func (vs *ValidatorSet) loadTombstones() error {
+	vs.tombstones = make(map[string]*Tombstone)
+	data, err := vs.storage.GetAccount(validatorTombstonesKey)
+	if err != nil {
+		// Sin tombstones
+		return nil
+	}
+	var tombstones []*Tombstone
+	if err := json.Unmarshal(data, &tombstones); err != nil {
+		return fmt.Errorf("error parseando tombstones: %w", err)
+	}
+	for _, t := range tombstones {
+		vs.tombstones[tombstoneKey(t.Address)] = t
+	}
+	return nil
+}
+
+// saveTombstones guarda la lista de tombstones en storage (requiere lock)
+func (vs *ValidatorSet) saveTombstones() {
+	if vs.storage == nil {
+		return
+	}
+	tombstones := make([]*Tombstone, 0, len(vs.tombstones))
+	for _, t := range vs.tombstones {
+		tombstones = append(tombstones, t)
+	}
+	sort.Slice(tombstones, func(i, j int) bool {
+		return tombstones[i].Address < tombstones[j].Address
+	})
+	data, err := json.Marshal(tombstones)
+	if err != nil {
+		consensusLog.Warn("Error serializando tombstones: " + err.Error())
+		return
+	}
+	if err := vs.storage.SaveAccount(validatorTombstonesKey, data); err != nil {
+		consensusLog.Warn("Error guardando tombstones: " + err.Error())
+	}
+}
@@ -0,0 +1,63 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// TestValidatorSet_Tombstone prueba que una doble firma expulse al validador de forma permanente
+func TestValidatorSet_Tombstone(t *testing.T) {
+	validatorSet, keys := crearValidatorSetConGenesis(t)
+	address := "0x1111111111111111111111111111111111111111"
+	oxg := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	blockTime := time.Unix(1700000000, 0).UTC()
+
+	// Type 1 = MISBEHAVIOR_TYPE_DUPLICATE_VOTE (llega dos veces: se penaliza una sola)
+	evidence := abcitypes.Misbehavior{Type: 1, Validator: abcitypes.Validator{Address: keys[0].PubKey().Address(), Power: 5000}, Height: 9}
+	validatorSet.SetBlockInfo(10, blockTime)
+	validatorSet.HandleMisbehavior([]abcitypes.Misbehavior{evidence, evidence})
+
+	validator, _ := validatorSet.GetValidator(address)
+	if !validator.Tombstoned || !validator.Jailed || validator.JailReason != JailReasonDoubleSign {
+		t.Fatalf("El validador debería tener tombstone: %+v", validator)
+	}
+	if validator.Stake.Cmp(new(big.Int).Mul(big.NewInt(4750), oxg)) != 0 {
+		t.Errorf("Slash de doble firma incorrecto: %s", validator.Stake)
+	}
+	tombstones := validatorSet.GetTombstones()
+	if len(tombstones) != 1 || tombstones[0].Address != address || tombstones[0].EvidenceHeight != 9 || tombstones[0].Height != 10 {
+		t.Errorf("Lista de tombstones incorrecta: %+v", tombstones)
+	}
+
+	// Ni el paso del tiempo ni más stake lo devuelven al set
+	validatorSet.SetBlockInfo(20, blockTime.Add(365*24*time.Hour))
+	if err := validatorSet.Unjail(address); err == nil {
+		t.Error("Un validador con tombstone no debería salir de jail")
+	}
+	if err := validatorSet.Stake(address, oxg); err == nil {
+		t.Error("Un validador con tombstone no debería poder agregar stake")
+	}
+
+	// Puede retirar todo su stake, pero no volver a registrarse
+	if err := validatorSet.Unstake(address, new(big.Int).Set(validator.Stake)); err != nil {
+		t.Fatalf("Error retirando el stake: %v", err)
+	}
+	if _, err := validatorSet.RegisterValidator(address, keys[1].PubKey().Bytes(), new(big.Int).Mul(big.NewInt(2000), oxg)); err == nil {
+		t.Error("La dirección con tombstone no debería poder registrarse")
+	}
+	if _, err := validatorSet.RegisterValidator("0x3333333333333333333333333333333333333333", keys[0].PubKey().Bytes(), new(big.Int).Mul(big.NewInt(2000), oxg)); err == nil {
+		t.Error("La clave pública con tombstone no debería poder registrarse")
+	}
+
+	// La lista sobrevive a un reinicio
+	restored := NewValidatorSet(validatorSet.storage, nil, validatorSet.minStake, 100)
+	if err := restored.LoadValidators(); err != nil {
+		t.Fatalf("Error cargando validadores: %v", err)
+	}
+	if !restored.IsTombstoned("0X1111111111111111111111111111111111111111") || len(restored.GetTombstones()) != 1 {
+		t.Error("Los tombstones deberían persistir")
+	}
+}
@@ -0,0 +1,71 @@
+package consensus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/tracing"
+)
+
+// maxTxTraces es la cantidad máxima de transacciones en el mempool cuyo span de envío se recuerda
+const maxTxTraces = 10000
+
+// txTraceIndex recuerda el span con el que se envió cada transacción, para enlazar su ejecución
+// en FinalizeBlock (otra traza, iniciada por CometBFT) con la petición REST que la originó
+type txTraceIndex struct {
+	mu     sync.Mutex
+	traces map[string]tracing.SpanContext
+}
+
+// newTxTraceIndex crea un índice vacío
+func newTxTraceIndex() *txTraceIndex {
+	return &txTraceIndex{traces: make(map[string]tracing.SpanContext)}
+}
+
+// remember guarda el span de envío de una transacción; si está lleno descarta una entrada cualquiera
+// (transacciones que nunca se incluyeron): el link es opcional
+func (i *txTraceIndex) remember(hash string, sc tracing.SpanContext) {
+	if i == nil || !sc.IsValid() {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.traces) >= maxTxTraces {
+		for old := range i.traces {
+			delete(i.traces, old)
+			break
+		}
+	}
+	i.traces[hash] = sc
+}
+
+// take retorna y olvida el span de envío de una transacción
+func (i *txTraceIndex) take(hash string) (tracing.SpanContext, bool) {
+	if i == nil {
+		return tracing.SpanContext{}, false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	sc, ok := i.traces[hash]
+	if ok {
+		delete(i.traces, hash)
+	}
+	return sc, ok
+}
+
+// submitTraced agrega una transacción al mempool dentro de un span "mempool.Insert"
+// y recuerda el span para enlazar la ejecución de la transacción con el envío
+func (c *CometBFT) submitTraced(ctx context.Context, tx *Transaction) error {
+	ctx, span := tracing.Start(ctx, "mempool.Insert", tracing.String("tx.hash", tx.Hash), tracing.String("tx.from", tx.From))
+	defer span.End()
+
+	err := c.SubmitTransaction(tx)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if c.node != nil && c.node.abciApp != nil {
+		c.node.abciApp.txTraces.remember(tx.Hash, span.SpanContext())
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// DefaultDuplicateTxWindow es la cantidad de bloques por defecto durante los que
+// se recuerda el hash de una transacción incluida
+const DefaultDuplicateTxWindow uint64 = 100
+
+// RecentTxIndex mantiene los hashes de las transacciones incluidas en los últimos
+// N bloques para rechazar replays de forma determinista
+type RecentTxIndex struct {
+	window   uint64
+	byHash   map[string]uint64   // hash -> altura en la que se incluyó
+	byHeight map[uint64][]string // altura -> hashes incluidos
+	mutex    sync.RWMutex
+}
+
+// NewRecentTxIndex crea un nuevo índice con la ventana dada (en bloques)
+func NewRecentTxIndex(window uint64) *RecentTxIndex {
+	if window == 0 {
+		window = DefaultDuplicateTxWindow
+	}
+	return &RecentTxIndex{
+		window:   window,
+		byHash:   make(map[string]uint64),
+		byHeight: make(map[uint64][]string),
+	}
+}
+
+// Window retorna el tamaño de la ventana en bloques
+func (idx *RecentTxIndex) Window() uint64 {
+	return idx.window
+}
+
+// Contains verifica si un hash fue incluido dentro de la ventana
+func (idx *RecentTxIndex) Contains(hash string) bool {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	_, exists := idx.byHash[hash]
+	return exists
+}
+
+// Add registra los hashes incluidos en un bloque y descarta los que quedan fuera de la ventana
+func (idx *RecentTxIndex) Add(height uint64, hashes []string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	for _, hash := range hashes {
+		if hash == "" {
+			continue
+		}
+		idx.byHash[hash] = height
+		idx.byHeight[height] = append(idx.byHeight[height], hash)
+	}
+
+	idx.prune(height)
+}
+
+// Len retorna la cantidad de hashes en el índice
+func (idx *RecentTxIndex) Len() int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	return len(idx.byHash)
+}
+
+// prune elimina los bloques que quedaron fuera de la ventana (requiere lock)
+func (idx *RecentTxIndex) prune(currentHeight uint64) {
+	if currentHeight < idx.window {
+		return
+	}
+	minHeight := currentHeight - idx.window + 1
+
+	for height, hashes := range idx.byHeight {
+		if height >= minHeight {
+			continue
+		}
+		for _, hash := range hashes {
+			// Solo borrar si no fue re-registrado en una altura más reciente
+			if h, ok := idx.byHash[hash]; ok && h == height {
+				delete(idx.byHash, hash)
+			}
+		}
+		delete(idx.byHeight, height)
+	}
+}
+
+// saveIncludedTxHashes guarda los hashes incluidos en un bloque (exitosos o no) para reconstruir el
+// índice al reiniciar: el bloque guardado solo contiene las transacciones exitosas
+func saveIncludedTxHashes(db *storage.BlockchainDB, height uint64, hashes []string) error {
+	if hashes == nil {
+		hashes = []string{}
+	}
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return fmt.Errorf("error serializando hashes incluidos: %w", err)
+	}
+	return db.SaveIncludedTxHashes(height, data)
+}
+
+// LoadFromStorage reconstruye el índice desde los hashes incluidos guardados en cada altura
+// Necesario al reiniciar para que todos los nodos rechacen los mismos replays
+func (idx *RecentTxIndex) LoadFromStorage(db *storage.BlockchainDB) error {
+	latestHeight, err := db.GetLatestHeight()
+	if err != nil || latestHeight == 0 {
+		return nil // Sin bloques guardados, nada que cargar
+	}
+
+	startHeight := uint64(1)
+	if latestHeight >= idx.window {
+		startHeight = latestHeight - idx.window + 1
+	}
+
+	for height := startHeight; height <= latestHeight; height++ {
+		hashes, err := loadIncludedTxHashes(db, height)
+		if err != nil {
+			return err
+		}
+		idx.Add(height, hashes)
+	}
+
+	return nil
+}
+
+// loadIncludedTxHashes lee los hashes incluidos en una altura
+// Los bloques guardados antes de registrar los hashes incluidos solo aportan sus transacciones exitosas
+func loadIncludedTxHashes(db *storage.BlockchainDB, height uint64) ([]string, error) {
+	if data, err := db.GetIncludedTxHashes(height); err == nil {
+		var hashes []string
+		if err := json.Unmarshal(data, &hashes); err != nil {
+			return nil, fmt.Errorf("hashes incluidos inválidos en altura %d: %w", height, err)
+		}
+		return hashes, nil
+	}
+
+	blockData, err := db.GetBlock(height)
+	if err != nil || blockData == nil {
+		return nil, nil
+	}
+	block, err := DecodeBlock(blockData)
+	if err != nil {
+		return nil, nil
+	}
+	hashes := make([]string, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		hashes = append(hashes, tx.Hash)
+	}
+	return hashes, nil
+}
@@ -0,0 +1,120 @@
+package consensus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// TestRecentTxIndex_Contains prueba el registro y la expiración de hashes
+func TestRecentTxIndex_Contains(t *testing.T) {
+	idx := NewRecentTxIndex(3)
+
+	idx.Add(1, []string{"0xaaa"})
+	idx.Add(2, []string{"0xbbb"})
+
+	if !idx.Contains("0xaaa") || !idx.Contains("0xbbb") {
+		t.Error("Hashes incluidos deberían estar en el índice")
+	}
+	if idx.Contains("0xccc") {
+		t.Error("Hash no incluido no debería estar en el índice")
+	}
+
+	// Altura 3: la ventana cubre 1..3
+	idx.Add(3, nil)
+	if !idx.Contains("0xaaa") {
+		t.Error("Hash de altura 1 debería seguir dentro de la ventana")
+	}
+
+	// Altura 4: la altura 1 queda fuera de la ventana
+	idx.Add(4, nil)
+	if idx.Contains("0xaaa") {
+		t.Error("Hash de altura 1 debería haber expirado")
+	}
+	if !idx.Contains("0xbbb") {
+		t.Error("Hash de altura 2 debería seguir dentro de la ventana")
+	}
+	if idx.Len() != 1 {
+		t.Errorf("Len debería ser 1: obtenido %d", idx.Len())
+	}
+}
+
+// TestRecentTxIndex_DefaultWindow prueba que una ventana 0 usa el valor por defecto
+func TestRecentTxIndex_DefaultWindow(t *testing.T) {
+	idx := NewRecentTxIndex(0)
+	if idx.Window() != DefaultDuplicateTxWindow {
+		t.Errorf("Window debería ser %d: obtenido %d", DefaultDuplicateTxWindow, idx.Window())
+	}
+}
+
+// TestRecentTxIndex_LoadFromStorage prueba la reconstrucción del índice desde bloques guardados
+func TestRecentTxIndex_LoadFromStorage(t *testing.T) {
+	testDir := createTestDir("txindex_load")
+	defer cleanupTestDir(testDir)
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	for height := uint64(1); height <= 5; height++ {
+		block := &Block{
+			Header:       BlockHeader{Height: height},
+			Transactions: []*Transaction{{Hash: "0x" + string(rune('a'+height))}},
+		}
+		blockData, _ := json.Marshal(block)
+		if err := db.SaveBlock(height, blockData); err != nil {
+			t.Fatalf("Error guardando bloque: %v", err)
+		}
+	}
+	db.SaveLatestHeight(5)
+
+	idx := NewRecentTxIndex(2)
+	if err := idx.LoadFromStorage(db); err != nil {
+		t.Fatalf("Error cargando índice: %v", err)
+	}
+
+	// Solo los bloques 4 y 5 están dentro de la ventana
+	if idx.Contains("0xb") || idx.Contains("0xd") {
+		t.Error("Hashes fuera de la ventana no deberían cargarse")
+	}
+	if !idx.Contains("0xe") || !idx.Contains("0xf") {
+		t.Error("Hashes dentro de la ventana deberían cargarse")
+	}
+}
+
+// TestRecentTxIndex_LoadIncludedHashes prueba que al reiniciar se recuperen también las transacciones
+// fallidas, que no quedan en el bloque guardado pero sí en el índice en ejecución
+func TestRecentTxIndex_LoadIncludedHashes(t *testing.T) {
+	testDir := createTestDir("txindex_included")
+	defer cleanupTestDir(testDir)
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	block := &Block{
+		Header:       BlockHeader{Height: 1},
+		Transactions: []*Transaction{{Hash: "0xok"}},
+	}
+	blockData, _ := json.Marshal(block)
+	if err := db.SaveBlock(1, blockData); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+	if err := saveIncludedTxHashes(db, 1, []string{"0xok", "0xfallida"}); err != nil {
+		t.Fatalf("Error guardando hashes incluidos: %v", err)
+	}
+	db.SaveLatestHeight(1)
+
+	idx := NewRecentTxIndex(10)
+	if err := idx.LoadFromStorage(db); err != nil {
+		t.Fatalf("Error cargando índice: %v", err)
+	}
+	if !idx.Contains("0xok") || !idx.Contains("0xfallida") {
+		t.Error("El índice reconstruido debería tener las transacciones exitosas y las fallidas")
+	}
+}
@@ -0,0 +1,42 @@
+package consensus
+
+import (
+	"math/big"
+)
+
+// TxLimits son los límites anti-spam por transacción que se aplican en CheckTx y PrepareProposal.
+// Un cero deshabilita el límite correspondiente
+// ProcessProposal y FinalizeBlock no los aplican: dependen de la configuración de cada nodo y no deben
+// rechazar las propuestas de otros validadores ni cambiar el resultado de un bloque ya decidido
+type TxLimits struct {
+	MinGasPrice *big.Int // Precio de gas mínimo en wei (nil = sin mínimo)
+	MaxDataSize int      // Bytes máximos de data
+	MaxGasLimit uint64   // Gas límite máximo
+}
+
+// Validate verifica una transacción contra los límites
+func (l TxLimits) Validate(tx *Transaction) error {
+	if l.MaxDataSize > 0 && len(tx.Data) > l.MaxDataSize {
+		return NewTxError(CodeTxTooLarge, "data demasiado grande: %d bytes, máximo %d", len(tx.Data), l.MaxDataSize)
+	}
+	if l.MaxGasLimit > 0 && tx.GasLimit > l.MaxGasLimit {
+		return NewTxError(CodeGasLimitTooHigh, "gas límite demasiado alto: %d, máximo %d", tx.GasLimit, l.MaxGasLimit)
+	}
+	if l.MinGasPrice != nil && l.MinGasPrice.Sign() > 0 {
+		gasPrice := new(big.Int)
+		if tx.GasPrice != "" {
+			if _, ok := gasPrice.SetString(tx.GasPrice, 10); !ok {
+				return NewTxError(CodeInvalidTx, "gas price inválido: %s", tx.GasPrice)
+			}
+		}
+		if gasPrice.Cmp(l.MinGasPrice) < 0 {
+			return NewTxError(CodeGasPriceTooLow, "gas price demasiado bajo: %s, mínimo %s", gasPrice, l.MinGasPrice)
+		}
+	}
+	return nil
+}
+
+// SetTxLimits establece los límites anti-spam por transacción
+func (app *ABCIApp) SetTxLimits(limits TxLimits) {
+	app.txLimits = limits
+}
@@ -0,0 +1,60 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// TestTxLimits prueba los límites anti-spam y sus códigos de rechazo
+func TestTxLimits(t *testing.T) {
+	limits := TxLimits{MinGasPrice: big.NewInt(10), MaxDataSize: 4, MaxGasLimit: 100000}
+	tx := &Transaction{Hash: "0x01", GasLimit: 21000, GasPrice: "10", Data: []byte{1, 2, 3, 4}}
+	if err := limits.Validate(tx); err != nil {
+		t.Fatalf("Transacción dentro de los límites rechazada: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		modify func(tx *Transaction)
+		code   uint32
+	}{
+		{"gas price bajo", func(tx *Transaction) { tx.GasPrice = "9" }, CodeGasPriceTooLow},
+		{"gas price vacío", func(tx *Transaction) { tx.GasPrice = "" }, CodeGasPriceTooLow},
+		{"gas price inválido", func(tx *Transaction) { tx.GasPrice = "abc" }, CodeInvalidTx},
+		{"data grande", func(tx *Transaction) { tx.Data = make([]byte, 5) }, CodeTxTooLarge},
+		{"gas límite alto", func(tx *Transaction) { tx.GasLimit = 100001 }, CodeGasLimitTooHigh},
+	}
+	for _, c := range cases {
+		modified := *tx
+		c.modify(&modified)
+		if err := limits.Validate(&modified); ErrorCode(err, CodeOK) != c.code {
+			t.Errorf("%s: se esperaba el código %d: %v", c.name, c.code, err)
+		}
+	}
+
+	// Sin límites configurados se acepta cualquier transacción
+	free := &Transaction{GasLimit: 1 << 40, GasPrice: "0", Data: make([]byte, 1<<20)}
+	if err := (TxLimits{}).Validate(free); err != nil {
+		t.Errorf("Sin límites no debería rechazar: %v", err)
+	}
+}
+
+// TestProcessProposal_IgnoresTxLimits prueba que los límites del nodo no rechacen propuestas de otros
+// validadores: son configuración local y solo filtran CheckTx y PrepareProposal
+func TestProcessProposal_IgnoresTxLimits(t *testing.T) {
+	app := NewABCIApp(nil, nil, nil, "test-chain")
+	app.SetTxLimits(TxLimits{MinGasPrice: big.NewInt(1)})
+
+	free, _ := json.Marshal(&Transaction{Hash: "0x01", From: "0x0000000000000000000000000000000000000001", GasLimit: 21000, GasPrice: "0"})
+	resp, err := app.ProcessProposal(context.Background(), &abcitypes.ProcessProposalRequest{Height: 1, Txs: [][]byte{free}})
+	if err != nil {
+		t.Fatalf("Error en ProcessProposal: %v", err)
+	}
+	if resp.Status != abcitypes.PROCESS_PROPOSAL_STATUS_ACCEPT {
+		t.Error("Una transacción fuera de los límites locales no debería rechazar la propuesta")
+	}
+}
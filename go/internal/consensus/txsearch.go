@@ -0,0 +1,219 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Límites de paginación de tx_search (mismos que aplica CometBFT)
+const (
+	DefaultSearchPerPage = 30
+	MaxSearchPerPage     = 100
+)
+
+// ErrTxIndexerDisabled se retorna cuando el indexador de CometBFT está deshabilitado ("null")
+var ErrTxIndexerDisabled = errors.New("indexador de transacciones deshabilitado")
+
+// ErrInvalidSearchQuery se retorna cuando el query no respeta la sintaxis de CometBFT
+var ErrInvalidSearchQuery = errors.New("query de búsqueda inválido")
+
+// SearchTransactions busca transacciones por atributos de eventos usando tx_search de CometBFT
+// query usa la sintaxis de CometBFT, ej: "transfer.sender='0x...' AND tx.height>10"
+// Retorna el resultado JSON tal como lo entrega CometBFT (txs y total_count)
+func (c *CometBFT) SearchTransactions(ctx context.Context, query string, page, perPage int, orderBy string) (json.RawMessage, error) {
+	if !c.running {
+		return nil, fmt.Errorf("consenso no está corriendo")
+	}
+
+	if c.config.txIndexer() == "null" {
+		return nil, ErrTxIndexerDisabled
+	}
+
+	if err := ValidateSearchQuery(query); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = DefaultSearchPerPage
+	}
+	if perPage > MaxSearchPerPage {
+		perPage = MaxSearchPerPage
+	}
+	if orderBy != "asc" && orderBy != "desc" {
+		orderBy = "asc"
+	}
+
+	client, err := c.rpc()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.TxSearch(ctx, query, page, perPage, orderBy)
+	if err != nil {
+		return nil, fmt.Errorf("tx_search falló: %w", err)
+	}
+
+	return result, nil
+}
+
+// ValidateSearchQuery verifica que el query respete la sintaxis de eventos de CometBFT:
+// condiciones "clave operador valor" unidas por AND, con operadores =, <, <=, >, >=, CONTAINS o EXISTS
+// y valores entre comillas simples, numéricos, DATE yyyy-mm-dd o TIME RFC3339
+func ValidateSearchQuery(query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("%w: query requerido", ErrInvalidSearchQuery)
+	}
+
+	tokens, err := tokenizeSearchQuery(query)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+	}
+
+	for i := 0; i < len(tokens); {
+		if !isSearchTag(tokens[i]) {
+			return fmt.Errorf("%w: clave inválida %q", ErrInvalidSearchQuery, tokens[i])
+		}
+		if i+1 >= len(tokens) {
+			return fmt.Errorf("%w: falta operador después de %q", ErrInvalidSearchQuery, tokens[i])
+		}
+
+		op := tokens[i+1]
+		i += 2
+		switch op {
+		case "EXISTS":
+		case "=", "<", "<=", ">", ">=", "CONTAINS":
+			if i >= len(tokens) {
+				return fmt.Errorf("%w: falta valor después de %q", ErrInvalidSearchQuery, op)
+			}
+			consumed, err := searchOperand(tokens[i:], op)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+			}
+			i += consumed
+		default:
+			return fmt.Errorf("%w: operador desconocido %q", ErrInvalidSearchQuery, op)
+		}
+
+		if i < len(tokens) {
+			if tokens[i] != "AND" || i+1 >= len(tokens) {
+				return fmt.Errorf("%w: se esperaba AND y otra condición en %q", ErrInvalidSearchQuery, tokens[i])
+			}
+			i++
+		}
+	}
+
+	return nil
+}
+
+// tokenizeSearchQuery separa el query en claves, operadores y valores (los strings conservan sus comillas)
+func tokenizeSearchQuery(query string) ([]string, error) {
+	var tokens []string
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("string sin cerrar")
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case r == '<' || r == '>' || r == '=':
+			if (r == '<' || r == '>') && i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+			} else {
+				tokens = append(tokens, string(r))
+				i++
+			}
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("'<>=", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+// isSearchTag retorna si el token es una clave de evento válida (ej: transfer.sender, tx.height)
+func isSearchTag(token string) bool {
+	if token == "" || token == "AND" || !unicode.IsLetter([]rune(token)[0]) {
+		return false
+	}
+	for _, r := range token {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// searchOperand valida el valor de una condición y retorna cuántos tokens consume
+func searchOperand(tokens []string, op string) (int, error) {
+	value := tokens[0]
+
+	if strings.HasPrefix(value, "'") {
+		return 1, nil
+	}
+	if op == "CONTAINS" {
+		return 0, fmt.Errorf("CONTAINS requiere un string entre comillas simples")
+	}
+
+	switch value {
+	case "DATE", "TIME":
+		if len(tokens) < 2 {
+			return 0, fmt.Errorf("falta valor después de %s", value)
+		}
+		layout := "2006-01-02"
+		if value == "TIME" {
+			layout = time.RFC3339
+		}
+		if _, err := time.Parse(layout, tokens[1]); err != nil {
+			return 0, fmt.Errorf("%s inválido: %q", value, tokens[1])
+		}
+		return 2, nil
+	}
+
+	if !isSearchNumber(value) {
+		return 0, fmt.Errorf("valor inválido %q: los strings van entre comillas simples", value)
+	}
+	return 1, nil
+}
+
+// isSearchNumber retorna si el token es un número entero o decimal
+func isSearchNumber(token string) bool {
+	token = strings.TrimPrefix(token, "-")
+	if token == "" {
+		return false
+	}
+	dot := false
+	for i, r := range token {
+		switch {
+		case r >= '0' && r <= '9':
+		case r == '.' && !dot && i > 0 && i < len(token)-1:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -3,16 +3,25 @@ package consensus
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
 )
 
 // BlockHeader representa el header de un bloque
 type BlockHeader struct {
 	Height     uint64
-	Hash       string
+	Hash       string // Hash del header (ComputeHash); en bloques anteriores a StateRoot, el app hash
 	ParentHash string
 	Timestamp  time.Time
-	Validator  string
+	Validator  string // Dirección CometBFT del proponente
 	ChainID    string
+
+	// Contenido del bloque (ver sealHeader); vacíos en bloques guardados antes de que el header los tuviera
+	StateRoot        string // Root del estado EVM tras ejecutar el bloque
+	TransactionsRoot string // Root del trie de transacciones (clave RLP(índice), valor txLeaf)
+	ReceiptsRoot     string // Root del trie de receipts (clave RLP(índice), valor receiptLeaf)
+	GasUsed          uint64 // Gas usado por las transacciones del bloque
+	GasLimit         uint64 // Suma de los gas limit de las transacciones del bloque
 }
 
 // Block representa un bloque completo en la blockchain
@@ -34,6 +43,15 @@ type Transaction struct {
 	Nonce       uint64
 	Signature   []byte // Firma de la transacción
 	Timestamp   int64
+
+	// Access list (EIP-2930); omitida si está vacía para que las transacciones sin ella se codifiquen igual
+	AccessList execution.AccessList `json:"accessList,omitempty"`
+
+	// Autorización de una cuenta multisig (From es la dirección multisig y Signature queda vacía)
+	Multisig *MultisigAuth `json:"multisig,omitempty"`
+
+	// Cuenta que paga el gas de una transacción patrocinada (relayer) y su autorización
+	FeePayer *FeePayerAuth `json:"feePayer,omitempty"`
 }
 
 // TransactionReceipt representa el recibo de una transacción
@@ -0,0 +1,164 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Filtros de estado de /api/v1/validators
+const (
+	ValidatorStatusActive = "active" // Fuera de jail y con el stake mínimo
+	ValidatorStatusJailed = "jailed"
+	ValidatorStatusAll    = "all"
+)
+
+// Tipos de evento del historial de un validador (además de StakeActionBond/Stake/Unstake)
+const (
+	ValidatorEventSlash  = "slash"
+	ValidatorEventUnjail = "unjail"
+)
+
+// maxValidatorHistory es la cantidad de eventos que se conservan por validador (se descartan los más antiguos)
+const maxValidatorHistory = 1000
+
+// ValidatorEvent es un cambio en el stake o estado de un validador
+type ValidatorEvent struct {
+	Type   string    `json:"type"` // bond, stake, unstake, slash, unjail o set-commission
+	Height uint64    `json:"height"`
+	Time   time.Time `json:"time"`
+	Amount string    `json:"amount,omitempty"` // Monto del cambio (slash: monto descontado, set-commission: nueva tasa en bps)
+	Stake  string    `json:"stake"`            // Stake después del evento
+	Reason string    `json:"reason,omitempty"` // Motivo del jail (solo slash)
+}
+
+// SetBlockInfo establece la altura y hora del bloque en curso, usadas para fechar el historial
+func (vs *ValidatorSet) SetBlockInfo(height uint64, blockTime time.Time) {
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+	vs.height = height
+	vs.blockTime = blockTime
+}
+
+// ListValidators retorna copias de los validadores con el estado indicado (active, jailed o all),
+// ordenados por stake (mayor primero) y luego por dirección
+func (vs *ValidatorSet) ListValidators(status string) ([]*Validator, error) {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+
+	validators := make([]*Validator, 0, len(vs.validators))
+	for _, v := range vs.validators {
+		active := !v.Jailed && v.Stake.Cmp(vs.minStake) >= 0
+		switch status {
+		case ValidatorStatusActive:
+			if !active {
+				continue
+			}
+		case ValidatorStatusJailed:
+			if !v.Jailed {
+				continue
+			}
+		case ValidatorStatusAll:
+		default:
+			return nil, fmt.Errorf("estado de validador inválido: %q", status)
+		}
+		copied := *v
+		copied.Stake = new(big.Int).Set(v.Stake)
+		if v.Commission != nil {
+			commission := *v.Commission
+			copied.Commission = &commission
+		}
+		copied.Delegations = nil
+		if v.AccumulatedCommission != nil {
+			copied.AccumulatedCommission = new(big.Int).Set(v.AccumulatedCommission)
+		}
+		if v.RewardPerShare != nil {
+			copied.RewardPerShare = new(big.Int).Set(v.RewardPerShare)
+		}
+		validators = append(validators, &copied)
+	}
+
+	sort.Slice(validators, func(i, j int) bool {
+		if cmp := validators[i].Stake.Cmp(validators[j].Stake); cmp != 0 {
+			return cmp > 0
+		}
+		return validators[i].Address < validators[j].Address
+	})
+	return validators, nil
+}
+
+// validatorHistoryKey es la clave de storage del historial de un validador
+func validatorHistoryKey(address string) string {
+	return "validators:history:" + strings.ToLower(address)
+}
+
+// GetValidatorHistory retorna el historial de un validador (del más antiguo al más reciente)
+// Se lee de storage, por lo que incluye validadores ya removidos del set
+func (vs *ValidatorSet) GetValidatorHistory(address string) ([]ValidatorEvent, error) {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+	return vs.loadHistory(address)
+}
+
+// loadHistory lee el historial de storage (requiere lock)
+func (vs *ValidatorSet) loadHistory(address string) ([]ValidatorEvent, error) {
+	if vs.storage == nil {
+		return []ValidatorEvent{}, nil
+	}
+	data, err := vs.storage.GetAccount(validatorHistoryKey(address))
+	if err != nil {
+		// Sin historial guardado
+		return []ValidatorEvent{}, nil
+	}
+	var history []ValidatorEvent
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("error parseando historial del validador: %w", err)
+	}
+	return history, nil
+}
+
+// recordHistory agrega un evento al historial persistido del validador (requiere lock)
+func (vs *ValidatorSet) recordHistory(address, eventType string, amount, stake *big.Int) {
+	vs.appendHistory(address, vs.historyEvent(eventType, amount, stake))
+}
+
+// historyEvent crea un evento fechado con el bloque en curso (requiere lock)
+func (vs *ValidatorSet) historyEvent(eventType string, amount, stake *big.Int) ValidatorEvent {
+	event := ValidatorEvent{Type: eventType, Height: vs.height, Time: vs.now(), Stake: "0"}
+	if amount != nil {
+		event.Amount = amount.String()
+	}
+	if stake != nil {
+		event.Stake = stake.String()
+	}
+	return event
+}
+
+// appendHistory agrega event al historial persistido del validador (requiere lock)
+func (vs *ValidatorSet) appendHistory(address string, event ValidatorEvent) {
+	if vs.storage == nil {
+		return
+	}
+	history, err := vs.loadHistory(address)
+	if err != nil {
+		consensusLog.Warn("Historial de validador inválido, se reinicia: " + err.Error())
+		history = nil
+	}
+
+	history = append(history, event)
+	if len(history) > maxValidatorHistory {
+		history = history[len(history)-maxValidatorHistory:]
+	}
+
+	data, err := json.Marshal(history)
+	if err != nil {
+		consensusLog.Warn("Error serializando historial de validador: " + err.Error())
+		return
+	}
+	if err := vs.storage.SaveAccount(validatorHistoryKey(address), data); err != nil {
+		consensusLog.Warn("Error guardando historial de validador: " + err.Error())
+	}
+}
@@ -0,0 +1,101 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+)
+
+// crearValidatorSetConGenesis crea un set con dos validadores genesis (stake 5000 y 3000 OXG)
+func crearValidatorSetConGenesis(t *testing.T) (*ValidatorSet, []ed25519.PrivKey) {
+	testDir := createValidatorTestDir(t.Name())
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		cleanupValidatorTestDir(testDir)
+	})
+
+	oxg := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	validatorSet := NewValidatorSet(db, nil, new(big.Int).Mul(big.NewInt(1000), oxg), 100)
+
+	keys := []ed25519.PrivKey{ed25519.GenPrivKey(), ed25519.GenPrivKey()}
+	err = validatorSet.InitializeGenesisValidators([]GenesisValidator{
+		{Address: "0x1111111111111111111111111111111111111111", PubKey: keys[0].PubKey().Bytes(), Stake: new(big.Int).Mul(big.NewInt(5000), oxg)},
+		{Address: "0x2222222222222222222222222222222222222222", PubKey: keys[1].PubKey().Bytes(), Stake: new(big.Int).Mul(big.NewInt(3000), oxg)},
+	})
+	if err != nil {
+		t.Fatalf("Error inicializando validadores: %v", err)
+	}
+	return validatorSet, keys
+}
+
+// TestValidatorSet_ListValidators prueba el filtro por estado y el orden por stake
+func TestValidatorSet_ListValidators(t *testing.T) {
+	validatorSet, _ := crearValidatorSetConGenesis(t)
+	validatorSet.validators["0x2222222222222222222222222222222222222222"].Jailed = true
+
+	active, err := validatorSet.ListValidators(ValidatorStatusActive)
+	if err != nil || len(active) != 1 || active[0].Address != "0x1111111111111111111111111111111111111111" {
+		t.Fatalf("Validadores activos incorrectos: %v, %v", active, err)
+	}
+	jailed, _ := validatorSet.ListValidators(ValidatorStatusJailed)
+	if len(jailed) != 1 || !jailed[0].Jailed {
+		t.Errorf("Validadores en jail incorrectos: %v", jailed)
+	}
+	all, _ := validatorSet.ListValidators(ValidatorStatusAll)
+	if len(all) != 2 || all[0].Stake.Cmp(all[1].Stake) <= 0 {
+		t.Errorf("Todos los validadores deberían estar ordenados por stake: %v", all)
+	}
+
+	// Las copias no comparten el stake con el set
+	all[0].Stake.SetInt64(0)
+	if validatorSet.validators[all[0].Address].Stake.Sign() == 0 {
+		t.Error("ListValidators debería retornar copias")
+	}
+
+	if _, err := validatorSet.ListValidators("bonded"); err == nil {
+		t.Error("Debería rechazar un estado inválido")
+	}
+}
+
+// TestValidatorSet_History prueba que el historial se persista con la altura del bloque en curso
+func TestValidatorSet_History(t *testing.T) {
+	validatorSet, _ := crearValidatorSetConGenesis(t)
+	address := "0x1111111111111111111111111111111111111111"
+	blockTime := time.Unix(1700000000, 0).UTC()
+
+	validatorSet.SetBlockInfo(10, blockTime)
+	validatorSet.mutex.Lock()
+	validatorSet.recordHistory(address, StakeActionStake, big.NewInt(100), big.NewInt(5100))
+	validatorSet.mutex.Unlock()
+
+	validatorSet.SetBlockInfo(12, blockTime.Add(10*time.Second))
+	validatorSet.mutex.Lock()
+	validatorSet.recordHistory(address, ValidatorEventSlash, big.NewInt(255), big.NewInt(4845))
+	validatorSet.mutex.Unlock()
+
+	history, err := validatorSet.GetValidatorHistory("0x1111111111111111111111111111111111111111")
+	if err != nil {
+		t.Fatalf("Error obteniendo historial: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Esperados 2 eventos, obtenidos %d", len(history))
+	}
+	if history[0].Type != StakeActionStake || history[0].Height != 10 || !history[0].Time.Equal(blockTime) || history[0].Stake != "5100" {
+		t.Errorf("Primer evento incorrecto: %+v", history[0])
+	}
+	if history[1].Type != ValidatorEventSlash || history[1].Height != 12 || history[1].Amount != "255" {
+		t.Errorf("Segundo evento incorrecto: %+v", history[1])
+	}
+
+	// Un validador sin eventos tiene historial vacío
+	if empty, err := validatorSet.GetValidatorHistory("0x2222222222222222222222222222222222222222"); err != nil || len(empty) != 0 {
+		t.Errorf("Historial inesperado: %v, %v", empty, err)
+	}
+}
@@ -0,0 +1,169 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"sort"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+)
+
+// maxPowerChangeDenominator limita el cambio de poder por bloque: la suma de los cambios no supera
+// 1/maxPowerChangeDenominator del poder total que CometBFT conoce (lo que falta se aplica en los bloques siguientes)
+const maxPowerChangeDenominator = 3
+
+// validatorPendingPowerKey es la clave de storage de los poderes objetivo aún no aplicados
+const validatorPendingPowerKey = "validators:pending-power"
+
+// scaleValidatorUpdates escala los poderes en proporción si su suma supera MaxTotalVotingPower de CometBFT
+// Ningún validador con poder queda en 0 por el escalado
+func scaleValidatorUpdates(updates []abcitypes.ValidatorUpdate) {
+	total := new(big.Int)
+	for _, update := range updates {
+		if update.Power > 0 {
+			total.Add(total, big.NewInt(update.Power))
+		}
+	}
+	maxTotal := big.NewInt(cmttypes.MaxTotalVotingPower)
+	if total.Cmp(maxTotal) <= 0 {
+		return
+	}
+
+	// Cada poder se redondea hacia abajo y se garantiza al menos 1, por lo que hace falta margen
+	// para los validadores que suben a 1
+	limit := new(big.Int).Sub(maxTotal, big.NewInt(int64(len(updates))))
+	for i := range updates {
+		if updates[i].Power <= 0 {
+			continue
+		}
+		scaled := new(big.Int).Mul(big.NewInt(updates[i].Power), limit)
+		scaled.Div(scaled, total)
+		updates[i].Power = max(scaled.Int64(), 1)
+	}
+}
+
+// boundPowerChanges valida las actualizaciones contra el set que CometBFT conoce (poder por clave pública en hex):
+//   - descarta las que no cambian nada y las que remueven validadores desconocidos
+//   - limita la suma de los cambios del bloque a 1/maxPowerChangeDenominator del poder total actual
+//     (sin límite si el set está vacío); el resto queda en deferred como poder objetivo
+//   - nunca deja el poder total por encima de MaxTotalVotingPower ni el set vacío
+func boundPowerChanges(current map[string]int64, updates []abcitypes.ValidatorUpdate) ([]abcitypes.ValidatorUpdate, map[string]int64) {
+	next := make(map[string]int64, len(current))
+	total := int64(0)
+	for key, power := range current {
+		next[key] = power
+		total += power
+	}
+	unlimited := total == 0
+	budget := max(total/maxPowerChangeDenominator, 1)
+
+	applied := make([]abcitypes.ValidatorUpdate, 0, len(updates))
+	deferred := make(map[string]int64)
+	for _, update := range updates {
+		key := hex.EncodeToString(update.PubKeyBytes)
+		target := max(update.Power, 0)
+		old := next[key]
+		if target == old {
+			continue
+		}
+
+		power := target
+		if !unlimited {
+			delta := target - old
+			if delta < 0 {
+				delta = -delta
+			}
+			if delta > budget {
+				deferred[key] = target
+				if budget == 0 {
+					continue
+				}
+				if target > old {
+					power = old + budget
+				} else {
+					power = old - budget
+				}
+				delta = budget
+			}
+			budget -= delta
+		}
+
+		newTotal := total - old + power
+		if newTotal > cmttypes.MaxTotalVotingPower || newTotal <= 0 {
+			deferred[key] = target
+			continue
+		}
+		total = newTotal
+		if power == 0 {
+			delete(next, key)
+		} else {
+			next[key] = power
+		}
+		update.Power = power
+		applied = append(applied, update)
+	}
+	return applied, deferred
+}
+
+// PrepareValidatorUpdates aplica los límites de CometBFT a las actualizaciones del bloque (ver boundPowerChanges),
+// retomando los poderes objetivo que quedaron pendientes de bloques anteriores. Una actualización nueva
+// de un validador reemplaza a su objetivo pendiente
+func (vs *ValidatorSet) PrepareValidatorUpdates(updates []abcitypes.ValidatorUpdate) []abcitypes.ValidatorUpdate {
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+
+	keys := make([]string, 0, len(vs.pendingPower))
+	for key := range vs.pendingPower {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pending := make([]abcitypes.ValidatorUpdate, 0, len(keys))
+	for _, key := range keys {
+		pubKey, err := hex.DecodeString(key)
+		if err != nil {
+			continue
+		}
+		pending = append(pending, abcitypes.ValidatorUpdate{PubKeyBytes: pubKey, Power: vs.pendingPower[key]})
+	}
+
+	applied, deferred := boundPowerChanges(vs.consensusPower, mergeValidatorUpdates(pending, updates))
+	if len(deferred) > 0 {
+		consensusLog.Infof("Cambios de poder limitados en este bloque: %d validadores con poder pendiente", len(deferred))
+	}
+	if len(deferred) > 0 || len(vs.pendingPower) > 0 {
+		vs.pendingPower = deferred
+		vs.savePendingPower()
+	}
+	return applied
+}
+
+// loadPendingPower lee de storage los poderes objetivo pendientes (requiere lock)
+func (vs *ValidatorSet) loadPendingPower() {
+	vs.pendingPower = make(map[string]int64)
+	data, err := vs.storage.GetAccount(validatorPendingPowerKey)
+	if err != nil {
+		// Sin cambios pendientes
+		return
+	}
+	if err := json.Unmarshal(data, &vs.pendingPower); err != nil {
+		consensusLog.Warn("Poderes pendientes guardados inválidos, se descartan: " + err.Error())
+		vs.pendingPower = make(map[string]int64)
+	}
+}
+
+// savePendingPower guarda en storage los poderes objetivo pendientes (requiere lock)
+func (vs *ValidatorSet) savePendingPower() {
+	if vs.storage == nil {
+		return
+	}
+	data, err := json.Marshal(vs.pendingPower)
+	if err != nil {
+		consensusLog.Warn("Error serializando poderes pendientes: " + err.Error())
+		return
+	}
+	if err := vs.storage.SaveAccount(validatorPendingPowerKey, data); err != nil {
+		consensusLog.Warn("Error guardando poderes pendientes: " + err.Error())
+	}
+}
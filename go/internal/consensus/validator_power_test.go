@@ -0,0 +1,139 @@
+package consensus
+
+import (
+	"math"
+	"testing"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+)
+
+// TestScaleValidatorUpdates_HugePowers prueba que el poder total nunca supere MaxTotalVotingPower
+func TestScaleValidatorUpdates_HugePowers(t *testing.T) {
+	updates := []abcitypes.ValidatorUpdate{
+		{PubKeyBytes: []byte{1}, Power: math.MaxInt64},
+		{PubKeyBytes: []byte{2}, Power: math.MaxInt64 / 2},
+		{PubKeyBytes: []byte{3}, Power: 1},
+		{PubKeyBytes: []byte{4}, Power: 0},
+	}
+	scaleValidatorUpdates(updates)
+
+	total := int64(0)
+	for _, update := range updates[:3] {
+		if update.Power < 1 {
+			t.Errorf("Ningún validador con poder debería quedar en 0: %+v", update)
+		}
+		total += update.Power
+	}
+	if total > cmttypes.MaxTotalVotingPower {
+		t.Errorf("Poder total %d supera el máximo %d", total, cmttypes.MaxTotalVotingPower)
+	}
+	if updates[0].Power <= updates[1].Power {
+		t.Errorf("El escalado debería mantener la proporción: %d <= %d", updates[0].Power, updates[1].Power)
+	}
+	if updates[3].Power != 0 {
+		t.Errorf("Una remoción no debería escalarse: %d", updates[3].Power)
+	}
+}
+
+// TestScaleValidatorUpdates_ManyValidators prueba el escalado con muchos validadores y que no toque sets válidos
+func TestScaleValidatorUpdates_ManyValidators(t *testing.T) {
+	const count = 10000
+	updates := make([]abcitypes.ValidatorUpdate, count)
+	for i := range updates {
+		updates[i] = abcitypes.ValidatorUpdate{PubKeyBytes: []byte{byte(i >> 8), byte(i)}, Power: cmttypes.MaxTotalVotingPower / 1000}
+	}
+	scaleValidatorUpdates(updates)
+	total := int64(0)
+	for _, update := range updates {
+		total += update.Power
+	}
+	if total > cmttypes.MaxTotalVotingPower || updates[0].Power != updates[count-1].Power {
+		t.Errorf("Escalado incorrecto: total=%d", total)
+	}
+
+	small := []abcitypes.ValidatorUpdate{{PubKeyBytes: []byte{1}, Power: 5000}, {PubKeyBytes: []byte{2}, Power: 3000}}
+	scaleValidatorUpdates(small)
+	if small[0].Power != 5000 || small[1].Power != 3000 {
+		t.Errorf("Un set dentro del límite no debería cambiar: %+v", small)
+	}
+}
+
+// TestBoundPowerChanges prueba los casos borde de las actualizaciones contra el set de CometBFT
+func TestBoundPowerChanges(t *testing.T) {
+	current := map[string]int64{"01": 5000, "02": 3000, "03": 1000}
+
+	// Sin cambios y remoción de un validador desconocido: se descartan
+	applied, deferred := boundPowerChanges(current, []abcitypes.ValidatorUpdate{
+		{PubKeyBytes: []byte{1}, Power: 5000},
+		{PubKeyBytes: []byte{9}, Power: 0},
+	})
+	if len(applied) != 0 || len(deferred) != 0 {
+		t.Errorf("No debería haber cambios: applied=%+v deferred=%+v", applied, deferred)
+	}
+
+	// Un validador nuevo enorme entra de a 1/3 del poder total
+	applied, deferred = boundPowerChanges(current, []abcitypes.ValidatorUpdate{{PubKeyBytes: []byte{4}, Power: 1000000}})
+	if len(applied) != 1 || applied[0].Power != 3000 || deferred["04"] != 1000000 {
+		t.Errorf("Entrada limitada incorrecta: applied=%+v deferred=%+v", applied, deferred)
+	}
+
+	// El presupuesto se comparte entre las actualizaciones del bloque
+	applied, deferred = boundPowerChanges(current, []abcitypes.ValidatorUpdate{
+		{PubKeyBytes: []byte{3}, Power: 0},
+		{PubKeyBytes: []byte{2}, Power: 6000},
+	})
+	if len(applied) != 2 || applied[0].Power != 0 || applied[1].Power != 5000 || deferred["02"] != 6000 {
+		t.Errorf("Presupuesto compartido incorrecto: applied=%+v deferred=%+v", applied, deferred)
+	}
+
+	// Nunca se vacía el set
+	applied, _ = boundPowerChanges(map[string]int64{"01": 10}, []abcitypes.ValidatorUpdate{{PubKeyBytes: []byte{1}, Power: 0}})
+	if len(applied) != 0 {
+		t.Errorf("No debería vaciar el set: %+v", applied)
+	}
+
+	// Nunca se supera MaxTotalVotingPower
+	applied, deferred = boundPowerChanges(map[string]int64{"01": cmttypes.MaxTotalVotingPower}, []abcitypes.ValidatorUpdate{{PubKeyBytes: []byte{2}, Power: 10}})
+	if len(applied) != 0 || deferred["02"] != 10 {
+		t.Errorf("No debería superar el poder máximo: applied=%+v deferred=%+v", applied, deferred)
+	}
+
+	// Sin set conocido (genesis o nodo sin historial) no hay límite
+	applied, deferred = boundPowerChanges(nil, []abcitypes.ValidatorUpdate{{PubKeyBytes: []byte{1}, Power: 1000000}})
+	if len(applied) != 1 || applied[0].Power != 1000000 || len(deferred) != 0 {
+		t.Errorf("Sin set conocido no debería limitar: applied=%+v deferred=%+v", applied, deferred)
+	}
+}
+
+// TestValidatorSet_PrepareValidatorUpdates prueba que el poder pendiente se aplique en los bloques siguientes
+func TestValidatorSet_PrepareValidatorUpdates(t *testing.T) {
+	validatorSet, keys := crearValidatorSetConGenesis(t)
+	validatorSet.RecordConsensusUpdates([]abcitypes.ValidatorUpdate{
+		{PubKeyBytes: keys[0].PubKey().Bytes(), Power: 5000},
+		{PubKeyBytes: keys[1].PubKey().Bytes(), Power: 3000},
+	})
+
+	// 3000 -> 9000: 2666 en este bloque, el resto queda pendiente
+	updates := validatorSet.PrepareValidatorUpdates([]abcitypes.ValidatorUpdate{{PubKeyBytes: keys[1].PubKey().Bytes(), Power: 9000}})
+	if len(updates) != 1 || updates[0].Power != 5666 {
+		t.Fatalf("Primer bloque incorrecto: %+v", updates)
+	}
+	validatorSet.RecordConsensusUpdates(updates)
+
+	// La pendiente sobrevive a un reinicio
+	restored := NewValidatorSet(validatorSet.storage, nil, validatorSet.minStake, 100)
+	if err := restored.LoadValidators(); err != nil {
+		t.Fatalf("Error cargando validadores: %v", err)
+	}
+	for _, vs := range []*ValidatorSet{validatorSet, restored} {
+		updates = vs.PrepareValidatorUpdates(nil)
+		if len(updates) != 1 || updates[0].Power != 9000 {
+			t.Fatalf("Segundo bloque incorrecto: %+v", updates)
+		}
+		vs.RecordConsensusUpdates(updates)
+		if again := vs.PrepareValidatorUpdates(nil); len(again) != 0 {
+			t.Errorf("No deberían quedar cambios pendientes: %+v", again)
+		}
+	}
+}
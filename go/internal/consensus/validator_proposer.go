@@ -0,0 +1,80 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// validatorProposedKey es la clave de storage de la cantidad de bloques propuestos por cada validador
+const validatorProposedKey = "validators:proposed"
+
+// RecordProposer suma un bloque propuesto al validador con la dirección CometBFT indicada
+// (ProposerAddress de FinalizeBlock) y guarda los contadores en storage. Un proponente que no está en el set
+// local no se cuenta
+func (vs *ValidatorSet) RecordProposer(proposerAddress []byte) {
+	if len(proposerAddress) == 0 {
+		return
+	}
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+
+	address, ok := vs.consensusAddresses()[fmt.Sprintf("%X", proposerAddress)]
+	if !ok {
+		return
+	}
+	if vs.proposed == nil {
+		vs.proposed = make(map[string]uint64)
+	}
+	vs.proposed[address]++
+	vs.saveProposedBlocks()
+}
+
+// BlocksProposed retorna la cantidad de bloques propuestos por un validador desde que se registra
+func (vs *ValidatorSet) BlocksProposed(address string) uint64 {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+	return vs.proposed[address]
+}
+
+// ProposerAddress retorna la dirección del validador con la dirección CometBFT indicada (la de
+// BlockHeader.Validator, en hex), o vacío si no está en el set local
+func (vs *ValidatorSet) ProposerAddress(consensusAddress string) string {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+	for key, address := range vs.consensusAddresses() {
+		if strings.EqualFold(key, consensusAddress) {
+			return address
+		}
+	}
+	return ""
+}
+
+// saveProposedBlocks guarda los contadores de bloques propuestos en storage (requiere lock)
+func (vs *ValidatorSet) saveProposedBlocks() {
+	if vs.storage == nil {
+		return
+	}
+	data, err := json.Marshal(vs.proposed)
+	if err != nil {
+		consensusLog.Warn("Error serializando bloques propuestos: " + err.Error())
+		return
+	}
+	if err := vs.storage.SaveAccount(validatorProposedKey, data); err != nil {
+		consensusLog.Warn("Error guardando bloques propuestos: " + err.Error())
+	}
+}
+
+// loadProposedBlocks lee de storage los contadores de bloques propuestos (requiere lock)
+func (vs *ValidatorSet) loadProposedBlocks() {
+	vs.proposed = make(map[string]uint64)
+	data, err := vs.storage.GetAccount(validatorProposedKey)
+	if err != nil {
+		// Sin contadores guardados
+		return
+	}
+	if err := json.Unmarshal(data, &vs.proposed); err != nil {
+		consensusLog.Warn("Bloques propuestos guardados inválidos, se descartan: " + err.Error())
+		vs.proposed = make(map[string]uint64)
+	}
+}
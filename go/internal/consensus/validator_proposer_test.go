@@ -0,0 +1,39 @@
+package consensus
+
+import "testing"
+
+// TestValidatorSet_RecordProposer prueba el conteo de bloques propuestos y su restauración desde storage
+func TestValidatorSet_RecordProposer(t *testing.T) {
+	validatorSet, keys := crearValidatorSetConGenesis(t)
+	first := "0x1111111111111111111111111111111111111111"
+	second := "0x2222222222222222222222222222222222222222"
+
+	validatorSet.RecordProposer(keys[0].PubKey().Address())
+	validatorSet.RecordProposer(keys[0].PubKey().Address())
+	validatorSet.RecordProposer(keys[1].PubKey().Address())
+	validatorSet.RecordProposer([]byte{1, 2, 3}) // Proponente desconocido: no se cuenta
+	validatorSet.RecordProposer(nil)
+
+	if got := validatorSet.BlocksProposed(first); got != 2 {
+		t.Errorf("Bloques propuestos de %s: esperado 2, obtenido %d", first, got)
+	}
+	if got := validatorSet.BlocksProposed(second); got != 1 {
+		t.Errorf("Bloques propuestos de %s: esperado 1, obtenido %d", second, got)
+	}
+
+	// Header.Validator guarda la dirección CometBFT en hex
+	if got := validatorSet.ProposerAddress(keys[1].PubKey().Address().String()); got != second {
+		t.Errorf("Proponente incorrecto: %q", got)
+	}
+	if got := validatorSet.ProposerAddress("ABCDEF"); got != "" {
+		t.Errorf("Un proponente desconocido no debería resolverse: %q", got)
+	}
+
+	restored := NewValidatorSet(validatorSet.storage, nil, validatorSet.minStake, 100)
+	if err := restored.LoadValidators(); err != nil {
+		t.Fatalf("Error cargando validadores: %v", err)
+	}
+	if got := restored.BlocksProposed(first); got != 2 {
+		t.Errorf("Los bloques propuestos deberían restaurarse: %d", got)
+	}
+}
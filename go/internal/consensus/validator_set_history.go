@@ -0,0 +1,135 @@
+package consensus
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/cometbft/cometbft/crypto/ed25519"
+)
+
+// validatorSetHeightsKey es la clave de storage del índice de alturas en las que cambió el set de CometBFT
+const validatorSetHeightsKey = "validators:set-heights"
+
+// validatorUpdateDelay es la cantidad de bloques que tarda CometBFT en aplicar las actualizaciones de
+// validadores: las que retorna FinalizeBlock en H rigen desde H+2
+const validatorUpdateDelay = 2
+
+// validatorSetKey es la clave de storage del set de validadores que rige desde una altura
+func validatorSetKey(height uint64) string {
+	return fmt.Sprintf("validators:set:%d", height)
+}
+
+// ValidatorSetMember es un validador del set de CometBFT en una altura
+type ValidatorSetMember struct {
+	Address          string `json:"address,omitempty"` // Dirección del operador (vacía si ya no está en el set local)
+	ConsensusAddress string `json:"consensusAddress"`  // Dirección CometBFT (firma de los bloques)
+	PubKey           string `json:"pubKey"`            // Clave pública ed25519 en hex
+	Power            int64  `json:"power"`
+}
+
+// ValidatorSetSnapshot es el set de validadores de CometBFT desde la altura Height hasta el próximo cambio
+type ValidatorSetSnapshot struct {
+	Height     uint64               `json:"height"`
+	TotalPower int64                `json:"totalPower"`
+	Validators []ValidatorSetMember `json:"validators"` // Ordenados por poder (mayor primero) y luego por clave
+}
+
+// SaveValidatorSetAt guarda el set actual de CometBFT (ver RecordConsensusUpdates) como el que rige
+// desde height. Si la altura ya estaba registrada (re-ejecución de un bloque) se reemplaza, junto con
+// los cambios posteriores
+func (vs *ValidatorSet) SaveValidatorSetAt(height uint64) {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+	if vs.storage == nil {
+		return
+	}
+
+	snapshot := ValidatorSetSnapshot{Height: height, Validators: make([]ValidatorSetMember, 0, len(vs.consensusPower))}
+	for key, power := range vs.consensusPower {
+		member := ValidatorSetMember{PubKey: key, Power: power}
+		if pubKey, err := hex.DecodeString(key); err == nil {
+			if len(pubKey) == ed25519.PubKeySize {
+				member.ConsensusAddress = ed25519.PubKey(pubKey).Address().String()
+			}
+			for _, v := range vs.validators {
+				if bytes.Equal(v.PubKey, pubKey) {
+					member.Address = v.Address
+					break
+				}
+			}
+		}
+		snapshot.TotalPower += power
+		snapshot.Validators = append(snapshot.Validators, member)
+	}
+	sort.Slice(snapshot.Validators, func(i, j int) bool {
+		if snapshot.Validators[i].Power != snapshot.Validators[j].Power {
+			return snapshot.Validators[i].Power > snapshot.Validators[j].Power
+		}
+		return snapshot.Validators[i].PubKey < snapshot.Validators[j].PubKey
+	})
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		consensusLog.Warn("Error serializando el set de validadores: " + err.Error())
+		return
+	}
+	if err := vs.storage.SaveAccount(validatorSetKey(height), data); err != nil {
+		consensusLog.Warn("Error guardando el set de validadores: " + err.Error())
+		return
+	}
+
+	heights := vs.loadValidatorSetHeights()
+	heights = heights[:sort.Search(len(heights), func(i int) bool { return heights[i] >= height })]
+	heights = append(heights, height)
+	data, err = json.Marshal(heights)
+	if err != nil {
+		consensusLog.Warn("Error serializando el índice de sets de validadores: " + err.Error())
+		return
+	}
+	if err := vs.storage.SaveAccount(validatorSetHeightsKey, data); err != nil {
+		consensusLog.Warn("Error guardando el índice de sets de validadores: " + err.Error())
+	}
+}
+
+// GetValidatorSetAt retorna el set de validadores de CometBFT que rige en height: el último guardado en
+// una altura menor o igual (nil si no hay ninguno, p. ej. alturas anteriores a que el nodo registrara sets)
+func (vs *ValidatorSet) GetValidatorSetAt(height uint64) (*ValidatorSetSnapshot, error) {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+	if vs.storage == nil {
+		return nil, nil
+	}
+
+	heights := vs.loadValidatorSetHeights()
+	i := sort.Search(len(heights), func(i int) bool { return heights[i] > height })
+	if i == 0 {
+		return nil, nil
+	}
+	data, err := vs.storage.GetAccount(validatorSetKey(heights[i-1]))
+	if err != nil {
+		return nil, fmt.Errorf("set de validadores de la altura %d no disponible: %w", heights[i-1], err)
+	}
+	var snapshot ValidatorSetSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("error parseando set de validadores: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// loadValidatorSetHeights lee de storage las alturas (ascendentes) en las que cambió el set (requiere lock)
+func (vs *ValidatorSet) loadValidatorSetHeights() []uint64 {
+	data, err := vs.storage.GetAccount(validatorSetHeightsKey)
+	if err != nil {
+		// Sin sets guardados
+		return nil
+	}
+	var heights []uint64
+	if err := json.Unmarshal(data, &heights); err != nil {
+		consensusLog.Warn("Índice de sets de validadores inválido, se descarta: " + err.Error())
+		return nil
+	}
+	return heights
+}
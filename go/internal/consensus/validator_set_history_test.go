@@ -0,0 +1,53 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"testing"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// TestValidatorSet_ValidatorSetAt prueba que cada altura retorne el último set guardado hasta ella
+func TestValidatorSet_ValidatorSetAt(t *testing.T) {
+	validatorSet, keys := crearValidatorSetConGenesis(t)
+	validatorSet.RecordConsensusUpdates([]abcitypes.ValidatorUpdate{
+		{PubKeyBytes: keys[0].PubKey().Bytes(), Power: 5000},
+		{PubKeyBytes: keys[1].PubKey().Bytes(), Power: 3000},
+	})
+	validatorSet.SaveValidatorSetAt(1)
+
+	// Sale el segundo validador en el bloque 10: rige desde el 12
+	validatorSet.RecordConsensusUpdates([]abcitypes.ValidatorUpdate{{PubKeyBytes: keys[1].PubKey().Bytes(), Power: 0}})
+	validatorSet.SaveValidatorSetAt(10 + validatorUpdateDelay)
+
+	if snapshot, err := validatorSet.GetValidatorSetAt(0); err != nil || snapshot != nil {
+		t.Errorf("No debería haber set antes del genesis: %+v, %v", snapshot, err)
+	}
+
+	snapshot, err := validatorSet.GetValidatorSetAt(11)
+	if err != nil || snapshot == nil {
+		t.Fatalf("Set de la altura 11 no disponible: %v", err)
+	}
+	if snapshot.Height != 1 || snapshot.TotalPower != 8000 || len(snapshot.Validators) != 2 {
+		t.Fatalf("Set de la altura 11 incorrecto: %+v", snapshot)
+	}
+	first := snapshot.Validators[0]
+	if first.Address != "0x1111111111111111111111111111111111111111" || first.Power != 5000 ||
+		first.PubKey != hex.EncodeToString(keys[0].PubKey().Bytes()) ||
+		first.ConsensusAddress != keys[0].PubKey().Address().String() {
+		t.Errorf("Primer validador incorrecto: %+v", first)
+	}
+
+	for _, height := range []uint64{12, 1000} {
+		snapshot, err = validatorSet.GetValidatorSetAt(height)
+		if err != nil || snapshot == nil || snapshot.Height != 12 || len(snapshot.Validators) != 1 || snapshot.TotalPower != 5000 {
+			t.Errorf("Set de la altura %d incorrecto: %+v, %v", height, snapshot, err)
+		}
+	}
+
+	// Re-ejecutar el bloque 10 reemplaza el set guardado en vez de duplicarlo
+	validatorSet.SaveValidatorSetAt(10 + validatorUpdateDelay)
+	if heights := validatorSet.loadValidatorSetHeights(); len(heights) != 2 {
+		t.Errorf("Índice de alturas incorrecto: %v", heights)
+	}
+}
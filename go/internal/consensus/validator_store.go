@@ -0,0 +1,125 @@
+package consensus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// validatorRecordVersion es la versión del formato de los registros de validadores en storage
+// Un registro con una versión mayor (escrito por un nodo más nuevo) no se interpreta
+const validatorRecordVersion = 1
+
+// validatorRecord es el registro de un validador en storage (uno por validador)
+type validatorRecord struct {
+	Version   int        `json:"version"`
+	Validator *Validator `json:"validator"`
+}
+
+// loadValidatorRecords lee los registros de validadores de storage (requiere lock)
+// Un registro corrupto se descarta sin afectar al resto del set. Si todavía no hay registros,
+// migra el set guardado en el formato anterior (un solo JSON en las cuentas)
+func (vs *ValidatorSet) loadValidatorRecords() (map[string]*Validator, error) {
+	records, err := vs.storage.ListValidatorRecords()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return vs.migrateLegacyValidatorSet()
+	}
+
+	validators := make(map[string]*Validator, len(records))
+	saved := make(map[string][]byte, len(records))
+	for address, data := range records {
+		var record validatorRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			consensusLog.Warnf("Registro del validador %s corrupto, se descarta: %v", address, err)
+			continue
+		}
+		if record.Version > validatorRecordVersion || record.Validator == nil || record.Validator.Address != address {
+			consensusLog.Warnf("Registro del validador %s inválido (versión %d), se descarta", address, record.Version)
+			continue
+		}
+		validators[address] = record.Validator
+		saved[address] = data
+	}
+
+	vs.saveMutex.Lock()
+	vs.savedRecords = saved
+	vs.saveMutex.Unlock()
+	return validators, nil
+}
+
+// migrateLegacyValidatorSet pasa el set guardado como un solo JSON a registros por validador
+// y borra el formato anterior. Retorna nil si no había set guardado (requiere lock)
+func (vs *ValidatorSet) migrateLegacyValidatorSet() (map[string]*Validator, error) {
+	data, err := vs.storage.GetLegacyValidatorSet()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo validadores: %w", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	var validatorsList []*Validator
+	if err := json.Unmarshal(data, &validatorsList); err != nil {
+		return nil, fmt.Errorf("error parseando validadores: %w", err)
+	}
+	validators := make(map[string]*Validator, len(validatorsList))
+	for _, v := range validatorsList {
+		validators[v.Address] = v
+	}
+
+	if err := vs.writeValidatorRecords(validators); err != nil {
+		return nil, fmt.Errorf("error migrando validadores: %w", err)
+	}
+	if err := vs.storage.DeleteLegacyValidatorSet(); err != nil {
+		consensusLog.Warn("Error borrando el set de validadores anterior: " + err.Error())
+	}
+	consensusLog.Infof("Migrados %d validadores al formato por validador (versión %d)", len(validators), validatorRecordVersion)
+	return validators, nil
+}
+
+// writeValidatorRecords guarda solo los validadores que cambiaron desde la última escritura y borra
+// los removidos. Si un validador no se puede serializar se conserva su registro anterior y se
+// guardan los demás (requiere lock del set)
+func (vs *ValidatorSet) writeValidatorRecords(validators map[string]*Validator) error {
+	// SaveValidators se llama con RLock: los registros guardados tienen su propio lock
+	vs.saveMutex.Lock()
+	defer vs.saveMutex.Unlock()
+
+	var failed error
+	changed := make(map[string][]byte)
+	for address, v := range validators {
+		data, err := json.Marshal(validatorRecord{Version: validatorRecordVersion, Validator: v})
+		if err != nil {
+			failed = fmt.Errorf("error serializando validador %s: %w", address, err)
+			continue
+		}
+		if !bytes.Equal(vs.savedRecords[address], data) {
+			changed[address] = data
+		}
+	}
+	var deleted []string
+	for address := range vs.savedRecords {
+		if _, exists := validators[address]; !exists {
+			deleted = append(deleted, address)
+		}
+	}
+	sort.Strings(deleted)
+
+	if err := vs.storage.WriteValidatorRecords(changed, deleted); err != nil {
+		return fmt.Errorf("error guardando validadores: %w", err)
+	}
+	if vs.savedRecords == nil {
+		vs.savedRecords = make(map[string][]byte)
+	}
+	for address, data := range changed {
+		vs.savedRecords[address] = data
+	}
+	for _, address := range deleted {
+		delete(vs.savedRecords, address)
+	}
+	return failed
+}
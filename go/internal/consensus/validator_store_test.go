@@ -0,0 +1,86 @@
+package consensus
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+// TestValidatorSet_StoreRecords prueba que un registro corrupto no borre el resto del set
+// y que solo se escriban los validadores modificados
+func TestValidatorSet_StoreRecords(t *testing.T) {
+	validatorSet, _ := crearValidatorSetConGenesis(t)
+	db := validatorSet.storage
+	first := "0x1111111111111111111111111111111111111111"
+	second := "0x2222222222222222222222222222222222222222"
+
+	records, err := db.ListValidatorRecords()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Deberían existir 2 registros: %d (%v)", len(records), err)
+	}
+	var record validatorRecord
+	if err := json.Unmarshal(records[first], &record); err != nil || record.Version != validatorRecordVersion {
+		t.Fatalf("Registro inválido: %+v (%v)", record, err)
+	}
+
+	// Un cambio de stake solo reescribe el registro de ese validador
+	if err := db.WriteValidatorRecords(map[string][]byte{second: []byte("{corrupto")}, nil); err != nil {
+		t.Fatalf("Error corrompiendo registro: %v", err)
+	}
+	oxg := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	if err := validatorSet.Stake(first, oxg); err != nil {
+		t.Fatalf("Error agregando stake: %v", err)
+	}
+	records, _ = db.ListValidatorRecords()
+	if string(records[second]) != "{corrupto" {
+		t.Error("No debería reescribir validadores sin cambios")
+	}
+
+	restored := NewValidatorSet(db, nil, validatorSet.minStake, 100)
+	if err := restored.LoadValidators(); err != nil {
+		t.Fatalf("Error cargando validadores: %v", err)
+	}
+	validator, err := restored.GetValidator(first)
+	if err != nil || validator.Stake.Cmp(new(big.Int).Mul(big.NewInt(5001), oxg)) != 0 {
+		t.Fatalf("El validador sano debería cargarse: %+v (%v)", validator, err)
+	}
+	if _, err := restored.GetValidator(second); err == nil {
+		t.Error("El registro corrupto debería descartarse")
+	}
+}
+
+// TestValidatorSet_MigrateLegacySet prueba la migración del set guardado como un solo JSON
+func TestValidatorSet_MigrateLegacySet(t *testing.T) {
+	validatorSet, _ := crearValidatorSetConGenesis(t)
+	db := validatorSet.storage
+
+	legacy, err := json.Marshal([]*Validator{validatorSet.validators["0x1111111111111111111111111111111111111111"]})
+	if err != nil {
+		t.Fatalf("Error serializando set anterior: %v", err)
+	}
+	records, _ := db.ListValidatorRecords()
+	deleted := make([]string, 0, len(records))
+	for address := range records {
+		deleted = append(deleted, address)
+	}
+	if err := db.WriteValidatorRecords(nil, deleted); err != nil {
+		t.Fatalf("Error borrando registros: %v", err)
+	}
+	if err := db.SaveAccount("validators:set", legacy); err != nil {
+		t.Fatalf("Error guardando set anterior: %v", err)
+	}
+
+	restored := NewValidatorSet(db, nil, validatorSet.minStake, 100)
+	if err := restored.LoadValidators(); err != nil {
+		t.Fatalf("Error cargando validadores: %v", err)
+	}
+	if len(restored.GetValidators()) != 1 {
+		t.Fatalf("Debería migrar 1 validador: %d", len(restored.GetValidators()))
+	}
+	if data, _ := db.GetLegacyValidatorSet(); data != nil {
+		t.Error("El set anterior debería borrarse después de migrar")
+	}
+	if records, _ := db.ListValidatorRecords(); len(records) != 1 {
+		t.Errorf("Debería existir 1 registro: %d", len(records))
+	}
+}
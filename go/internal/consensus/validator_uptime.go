@@ -0,0 +1,237 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	cmttypes "github.com/cometbft/cometbft/types"
+)
+
+// DefaultUptimeWindow es la cantidad de bloques sobre la que se calcula el uptime por defecto
+const DefaultUptimeWindow = 1000
+
+// validatorUptimeKey es la clave de storage de las ventanas de uptime
+const validatorUptimeKey = "validators:uptime"
+
+// ValidatorUptime es la participación de un validador en las últimas Window firmas de bloque
+type ValidatorUptime struct {
+	Window      int     `json:"window"`      // Tamaño configurado de la ventana
+	Blocks      int     `json:"blocks"`      // Bloques observados dentro de la ventana
+	Signed      int     `json:"signed"`      // Bloques firmados (commit o nil)
+	Missed      int     `json:"missed"`      // Bloques sin firma
+	Percent     float64 `json:"percent"`     // Signed / Blocks * 100 (100 sin bloques observados)
+	SignedTotal uint64  `json:"signedTotal"` // Bloques firmados desde que se registra el validador
+	MissedTotal uint64  `json:"missedTotal"` // Bloques perdidos desde que se registra el validador
+}
+
+// SignedRatio retorna la fracción de bloques firmados en la ventana (0..1)
+func (u ValidatorUptime) SignedRatio() float64 {
+	return u.Percent / 100
+}
+
+// uptimeWindow guarda si el validador firmó cada uno de los últimos bloques (buffer circular)
+type uptimeWindow struct {
+	signed      []bool
+	next        int
+	count       int
+	missed      int
+	totalSigned uint64
+	totalMissed uint64
+}
+
+// newUptimeWindow crea una ventana de size bloques
+func newUptimeWindow(size int) *uptimeWindow {
+	return &uptimeWindow{signed: make([]bool, size)}
+}
+
+// add registra un bloque firmado o perdido
+func (u *uptimeWindow) add(signed bool) {
+	if u.count == len(u.signed) {
+		if !u.signed[u.next] {
+			u.missed--
+		}
+	} else {
+		u.count++
+	}
+	u.signed[u.next] = signed
+	if signed {
+		u.totalSigned++
+	} else {
+		u.missed++
+		u.totalMissed++
+	}
+	u.next = (u.next + 1) % len(u.signed)
+}
+
+// ordered retorna los bloques de la ventana del más antiguo al más reciente
+func (u *uptimeWindow) ordered() []bool {
+	blocks := make([]bool, 0, u.count)
+	start := (u.next - u.count + len(u.signed)) % len(u.signed)
+	for i := 0; i < u.count; i++ {
+		blocks = append(blocks, u.signed[(start+i)%len(u.signed)])
+	}
+	return blocks
+}
+
+// persistedUptime es la representación en storage de una ventana
+type persistedUptime struct {
+	Bits        []byte `json:"bits"` // Bloques del más antiguo al más reciente, un bit por bloque (1 = firmado)
+	Count       int    `json:"count"`
+	TotalSigned uint64 `json:"totalSigned"`
+	TotalMissed uint64 `json:"totalMissed"`
+}
+
+// encode convierte la ventana a su representación en storage
+func (u *uptimeWindow) encode() persistedUptime {
+	blocks := u.ordered()
+	bits := make([]byte, (len(blocks)+7)/8)
+	for i, signed := range blocks {
+		if signed {
+			bits[i/8] |= 1 << (i % 8)
+		}
+	}
+	return persistedUptime{Bits: bits, Count: len(blocks), TotalSigned: u.totalSigned, TotalMissed: u.totalMissed}
+}
+
+// decodeUptimeWindow reconstruye una ventana de size bloques (si la guardada es más grande
+// se conservan los bloques más recientes) y restaura los totales
+func decodeUptimeWindow(p persistedUptime, size int) (*uptimeWindow, error) {
+	if p.Count < 0 || len(p.Bits) < (p.Count+7)/8 {
+		return nil, fmt.Errorf("ventana de uptime inválida: %d bloques en %d bytes", p.Count, len(p.Bits))
+	}
+	window := newUptimeWindow(size)
+	for i := max(0, p.Count-size); i < p.Count; i++ {
+		window.add(p.Bits[i/8]&(1<<(i%8)) != 0)
+	}
+	window.totalSigned, window.totalMissed = p.TotalSigned, p.TotalMissed
+	return window, nil
+}
+
+// SetUptimeWindow configura la cantidad de bloques del cálculo de uptime (<= 0 = DefaultUptimeWindow)
+// y restaura las ventanas guardadas en storage, recortadas al nuevo tamaño
+func (vs *ValidatorSet) SetUptimeWindow(blocks int) {
+	if blocks <= 0 {
+		blocks = DefaultUptimeWindow
+	}
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+	vs.uptimeWindowSize = blocks
+	vs.uptime = make(map[string]*uptimeWindow)
+
+	if vs.storage == nil {
+		return
+	}
+	data, err := vs.storage.GetAccount(validatorUptimeKey)
+	if err != nil {
+		// Sin ventanas guardadas
+		return
+	}
+	var persisted map[string]persistedUptime
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		consensusLog.Warn("Ventanas de uptime guardadas inválidas, se descartan: " + err.Error())
+		return
+	}
+	for address, p := range persisted {
+		window, err := decodeUptimeWindow(p, blocks)
+		if err != nil {
+			consensusLog.Warnf("Ventana de uptime de %s descartada: %v", address, err)
+			continue
+		}
+		vs.uptime[address] = window
+	}
+}
+
+// RecordCommitVotes registra qué validadores firmaron el último bloque (DecidedLastCommit de FinalizeBlock)
+// y guarda las ventanas en storage. Los votos se identifican por la dirección CometBFT derivada
+// de la clave pública de cada validador
+func (vs *ValidatorSet) RecordCommitVotes(votes []abcitypes.VoteInfo) {
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+
+	if len(votes) == 0 {
+		return
+	}
+	byConsensusAddress := vs.consensusAddresses()
+
+	size := vs.uptimeWindowSize
+	if size <= 0 {
+		size = DefaultUptimeWindow
+	}
+	if vs.uptime == nil {
+		vs.uptime = make(map[string]*uptimeWindow)
+	}
+	recorded := false
+	for _, vote := range votes {
+		address, ok := byConsensusAddress[fmt.Sprintf("%X", vote.Validator.Address)]
+		if !ok {
+			continue
+		}
+		window, ok := vs.uptime[address]
+		if !ok {
+			window = newUptimeWindow(size)
+			vs.uptime[address] = window
+		}
+		window.add(cmttypes.BlockIDFlag(vote.BlockIdFlag) != cmttypes.BlockIDFlagAbsent)
+		recorded = true
+	}
+
+	if recorded {
+		vs.saveUptime()
+	}
+}
+
+// consensusAddresses mapea la dirección CometBFT de cada validador (la de los votos) a su dirección (requiere lock)
+func (vs *ValidatorSet) consensusAddresses() map[string]string {
+	byConsensusAddress := make(map[string]string, len(vs.validators))
+	for _, v := range vs.validators {
+		if len(v.PubKey) == ed25519.PubKeySize {
+			byConsensusAddress[ed25519.PubKey(v.PubKey).Address().String()] = v.Address
+		}
+	}
+	return byConsensusAddress
+}
+
+// saveUptime guarda las ventanas de uptime en storage (requiere lock)
+func (vs *ValidatorSet) saveUptime() {
+	if vs.storage == nil {
+		return
+	}
+	persisted := make(map[string]persistedUptime, len(vs.uptime))
+	for address, window := range vs.uptime {
+		persisted[address] = window.encode()
+	}
+	data, err := json.Marshal(persisted)
+	if err != nil {
+		consensusLog.Warn("Error serializando ventanas de uptime: " + err.Error())
+		return
+	}
+	if err := vs.storage.SaveAccount(validatorUptimeKey, data); err != nil {
+		consensusLog.Warn("Error guardando ventanas de uptime: " + err.Error())
+	}
+}
+
+// GetUptime retorna el uptime de un validador sobre la ventana configurada
+func (vs *ValidatorSet) GetUptime(address string) ValidatorUptime {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+
+	uptime := ValidatorUptime{Window: vs.uptimeWindowSize, Percent: 100}
+	if uptime.Window <= 0 {
+		uptime.Window = DefaultUptimeWindow
+	}
+	window, ok := vs.uptime[address]
+	if !ok {
+		return uptime
+	}
+	uptime.SignedTotal, uptime.MissedTotal = window.totalSigned, window.totalMissed
+	if window.count == 0 {
+		return uptime
+	}
+	uptime.Blocks = window.count
+	uptime.Missed = window.missed
+	uptime.Signed = window.count - window.missed
+	uptime.Percent = float64(uptime.Signed) / float64(uptime.Blocks) * 100
+	return uptime
+}
@@ -0,0 +1,96 @@
+package consensus
+
+import (
+	"testing"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// TestValidatorSet_Uptime prueba el uptime sobre una ventana de bloques a partir de los votos del commit
+func TestValidatorSet_Uptime(t *testing.T) {
+	validatorSet, keys := crearValidatorSetConGenesis(t)
+	validatorSet.SetUptimeWindow(4)
+
+	// BlockIdFlag: 1 = ausente, 2 = commit
+	votes := func(secondSigned bool) []abcitypes.VoteInfo {
+		second := abcitypes.VoteInfo{Validator: abcitypes.Validator{Address: keys[1].PubKey().Address(), Power: 3000}, BlockIdFlag: 1}
+		if secondSigned {
+			second.BlockIdFlag = 2
+		}
+		return []abcitypes.VoteInfo{
+			{Validator: abcitypes.Validator{Address: keys[0].PubKey().Address(), Power: 5000}, BlockIdFlag: 2},
+			second,
+		}
+	}
+
+	// El segundo validador pierde 3 de 6 bloques, pero solo 1 de los últimos 4
+	for _, signed := range []bool{false, false, true, false, true, true} {
+		validatorSet.RecordCommitVotes(votes(signed))
+	}
+
+	first := validatorSet.GetUptime("0x1111111111111111111111111111111111111111")
+	if first.Window != 4 || first.Blocks != 4 || first.Percent != 100 {
+		t.Errorf("Uptime incorrecto del primer validador: %+v", first)
+	}
+	second := validatorSet.GetUptime("0x2222222222222222222222222222222222222222")
+	if second.Blocks != 4 || second.Missed != 1 || second.Signed != 3 || second.Percent != 75 {
+		t.Errorf("Uptime incorrecto del segundo validador: %+v", second)
+	}
+
+	// Sin bloques observados el uptime es 100%
+	if unknown := validatorSet.GetUptime("0x3333333333333333333333333333333333333333"); unknown.Blocks != 0 || unknown.Percent != 100 {
+		t.Errorf("Uptime incorrecto sin bloques: %+v", unknown)
+	}
+}
+
+// TestValidatorSet_UptimePersistence prueba que las ventanas sobrevivan a un reinicio, recortadas al nuevo tamaño
+func TestValidatorSet_UptimePersistence(t *testing.T) {
+	validatorSet, keys := crearValidatorSetConGenesis(t)
+	validatorSet.SetUptimeWindow(8)
+
+	// BlockIdFlag: 1 = ausente, 2 = commit
+	for _, signed := range []bool{true, false, true, true, false, true} {
+		vote := abcitypes.VoteInfo{Validator: abcitypes.Validator{Address: keys[0].PubKey().Address(), Power: 5000}, BlockIdFlag: 1}
+		if signed {
+			vote.BlockIdFlag = 2
+		}
+		validatorSet.RecordCommitVotes([]abcitypes.VoteInfo{vote})
+	}
+
+	// Un nuevo set sobre el mismo storage restaura la ventana (solo los 4 bloques más recientes)
+	restored := NewValidatorSet(validatorSet.storage, nil, validatorSet.minStake, 100)
+	restored.SetUptimeWindow(4)
+	uptime := restored.GetUptime("0x1111111111111111111111111111111111111111")
+	if uptime.Window != 4 || uptime.Blocks != 4 || uptime.Missed != 1 || uptime.Percent != 75 {
+		t.Errorf("Ventana restaurada incorrecta: %+v", uptime)
+	}
+	if uptime.SignedTotal != 4 || uptime.MissedTotal != 2 {
+		t.Errorf("Totales restaurados incorrectos: %+v", uptime)
+	}
+}
+
+// TestUptimeWindow_Encode prueba la codificación en bits de una ventana que ya dio la vuelta
+func TestUptimeWindow_Encode(t *testing.T) {
+	window := newUptimeWindow(10)
+	for i := 0; i < 13; i++ {
+		window.add(i%3 != 0)
+	}
+
+	decoded, err := decodeUptimeWindow(window.encode(), 10)
+	if err != nil {
+		t.Fatalf("Error decodificando ventana: %v", err)
+	}
+	original, restored := window.ordered(), decoded.ordered()
+	if len(restored) != len(original) || decoded.missed != window.missed {
+		t.Fatalf("Ventana decodificada incorrecta: %v vs %v", restored, original)
+	}
+	for i := range original {
+		if original[i] != restored[i] {
+			t.Fatalf("Bloque %d distinto: %v vs %v", i, restored, original)
+		}
+	}
+
+	if _, err := decodeUptimeWindow(persistedUptime{Bits: []byte{0xff}, Count: 9}, 10); err == nil {
+		t.Error("Debería rechazar una ventana con menos bits que bloques")
+	}
+}
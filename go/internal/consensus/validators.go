@@ -1,7 +1,6 @@
 package consensus
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"math/big"
@@ -25,10 +24,18 @@ type Validator struct {
 	DelegatedTo   string    // Dirección que tiene delegado el stake (opcional)
 	Jailed        bool      // Si está en jail (slashed)
 	JailedUntil   time.Time // Fecha hasta la que está en jail
+	JailReason    string    // Motivo del jail (JailReasonMisbehavior, JailReasonDowntime, JailReasonDoubleSign)
+	Tombstoned    bool      // Expulsado por doble firma: jail permanente (ver tombstone.go)
 	CreatedAt     time.Time // Fecha de creación
 	LastActiveAt  time.Time // Última actividad
 	MissedBlocks  int       // Bloques perdidos consecutivos
 	TotalMissed   int       // Total de bloques perdidos
+
+	// Recompensas: comisión del validador y acumulado por unidad de stake delegado (ver rewards.go)
+	Commission            *Commission            // nil en validadores creados antes de las comisiones (se usa DefaultCommission)
+	Delegations           map[string]*Delegation // Delegaciones por delegador (por ahora solo la propia)
+	RewardPerShare        *big.Int               // Recompensa acumulada por unidad de stake, escalada por rewardPrecision
+	AccumulatedCommission *big.Int               // Comisión pendiente de retiro
 }
 
 // ValidatorSet maneja el conjunto de validadores
@@ -39,6 +46,33 @@ type ValidatorSet struct {
 	mutex         sync.RWMutex
 	minStake      *big.Int // Stake mínimo para ser validador
 	maxValidators int      // Número máximo de validadores
+
+	// Callback opcional invocado cuando cambia el stake (validador, monto, acción)
+	onStakeChange func(address string, amount *big.Int, action string)
+
+	// Bloque en curso (fecha los eventos del historial) y firmas recientes por validador (uptime)
+	height           uint64
+	blockTime        time.Time
+	uptimeWindowSize int
+	uptime           map[string]*uptimeWindow
+	proposed         map[string]uint64 // Bloques propuestos por validador (ver validator_proposer.go)
+
+	// Recompensa emitida por bloque (nil = sin recompensas) y recompensas de validadores ya removidos
+	blockReward      *big.Int
+	unclaimedRewards map[string]*big.Int
+
+	// Poder de cada validador según CometBFT (por clave pública en hex) y validadores que entraron
+	// o salieron del set activo en el bloque en curso (dirección -> clave pública)
+	consensusPower map[string]int64
+	setChanges     map[string][]byte
+	pendingPower   map[string]int64 // Poder objetivo que excedió el límite de cambio por bloque (ver validator_power.go)
+
+	// Validadores expulsados por doble firma (por dirección en minúsculas)
+	tombstones map[string]*Tombstone
+
+	// Último registro escrito de cada validador, para guardar solo los que cambian (ver validator_store.go)
+	savedRecords map[string][]byte
+	saveMutex    sync.Mutex
 }
 
 // NewValidatorSet crea un nuevo conjunto de validadores
@@ -51,9 +85,28 @@ func NewValidatorSet(
 	return &ValidatorSet{
 		storage:       storage,
 		executor:      executor,
-		validators:    make(map[string]*Validator),
-		minStake:      minStake,
-		maxValidators: maxValidators,
+		validators:       make(map[string]*Validator),
+		minStake:         minStake,
+		maxValidators:    maxValidators,
+		uptimeWindowSize: DefaultUptimeWindow,
+		uptime:           make(map[string]*uptimeWindow),
+		unclaimedRewards: make(map[string]*big.Int),
+		tombstones:       make(map[string]*Tombstone),
+	}
+}
+
+// SetStakeChangeHandler establece el callback invocado en cada cambio de stake
+// Se llama con el lock del set tomado: el callback no debe llamar de vuelta al ValidatorSet
+func (vs *ValidatorSet) SetStakeChangeHandler(handler func(address string, amount *big.Int, action string)) {
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+	vs.onStakeChange = handler
+}
+
+// notifyStakeChange invoca el callback de cambio de stake si está configurado (requiere lock)
+func (vs *ValidatorSet) notifyStakeChange(address string, amount *big.Int, action string) {
+	if vs.onStakeChange != nil {
+		vs.onStakeChange(address, new(big.Int).Set(amount), action)
 	}
 }
 
@@ -62,22 +115,29 @@ func (vs *ValidatorSet) LoadValidators() error {
 	vs.mutex.Lock()
 	defer vs.mutex.Unlock()
 
+	if err := vs.loadUnclaimedRewards(); err != nil {
+		return err
+	}
+	vs.loadConsensusPower()
+	vs.loadPendingPower()
+	vs.loadProposedBlocks()
+	if err := vs.loadTombstones(); err != nil {
+		return err
+	}
+
 	// Cargar validadores guardados
-	validatorsData, err := vs.storage.GetAccount("validators:set")
+	validators, err := vs.loadValidatorRecords()
 	if err != nil {
-		// No hay validadores guardados, retornar nil
+		return err
+	}
+	if validators == nil {
 		log.Println("No hay validadores guardados, iniciando con set vacío")
 		return nil
 	}
 
-	var validatorsList []*Validator
-	if err := json.Unmarshal(validatorsData, &validatorsList); err != nil {
-		return fmt.Errorf("error parseando validadores: %w", err)
-	}
-
-	vs.validators = make(map[string]*Validator)
-	for _, v := range validatorsList {
-		vs.validators[v.Address] = v
+	vs.validators = validators
+	for _, v := range vs.validators {
+		v.initRewards()
 	}
 
 	log.Printf("Cargados %d validadores desde storage", len(vs.validators))
@@ -96,39 +156,23 @@ func (vs *ValidatorSet) SaveValidators() error {
 	fmt.Fprintf(os.Stdout, "[Validators] RLock adquirido\n")
 	os.Stdout.Sync()
 
-	fmt.Fprintf(os.Stdout, "[Validators] Creando lista de validadores (count=%d)...\n", len(vs.validators))
-	os.Stdout.Sync()
-	validatorsList := make([]*Validator, 0, len(vs.validators))
-	for _, v := range vs.validators {
-		validatorsList = append(validatorsList, v)
-	}
-	fmt.Fprintf(os.Stdout, "[Validators] Lista de validadores creada (count=%d)\n", len(validatorsList))
-	os.Stdout.Sync()
-
-	fmt.Fprintf(os.Stdout, "[Validators] Serializando validadores...\n")
-	os.Stdout.Sync()
-	validatorsData, err := json.Marshal(validatorsList)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "[Validators] ERROR serializando validadores: %v\n", err)
-		os.Stderr.Sync()
-		return fmt.Errorf("error serializando validadores: %w", err)
+	if err := vs.saveValidatorsLocked(); err != nil {
+		return err
 	}
-	fmt.Fprintf(os.Stdout, "[Validators] Validadores serializados (%d bytes)\n", len(validatorsData))
+	
+	fmt.Fprintf(os.Stdout, "[Validators] SaveValidators completado exitosamente\n")
 	os.Stdout.Sync()
+	return nil
+}
 
-	fmt.Fprintf(os.Stdout, "[Validators] Llamando a storage.SaveAccount('validators:set')...\n")
-	os.Stdout.Sync()
-	err = vs.storage.SaveAccount("validators:set", validatorsData)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "[Validators] ERROR en storage.SaveAccount(): %v\n", err)
+// saveValidatorsLocked guarda validadores en storage (requiere lock)
+// Los métodos que modifican el set ya tienen el lock tomado y no pueden llamar a SaveValidators
+func (vs *ValidatorSet) saveValidatorsLocked() error {
+	if err := vs.writeValidatorRecords(vs.validators); err != nil {
+		fmt.Fprintf(os.Stderr, "[Validators] ERROR guardando validadores: %v\n", err)
 		os.Stderr.Sync()
 		return err
 	}
-	fmt.Fprintf(os.Stdout, "[Validators] storage.SaveAccount() completado exitosamente\n")
-	os.Stdout.Sync()
-	
-	fmt.Fprintf(os.Stdout, "[Validators] SaveValidators completado exitosamente\n")
-	os.Stdout.Sync()
 	return nil
 }
 
@@ -146,6 +190,11 @@ func (vs *ValidatorSet) RegisterValidator(
 		return nil, fmt.Errorf("validador ya está registrado: %s", address)
 	}
 
+	// Una doble firma expulsa para siempre a la dirección y a la clave pública
+	if vs.isTombstoned(address, pubKey) {
+		return nil, fmt.Errorf("validador con tombstone por doble firma: %s", address)
+	}
+
 	// Validar stake mínimo
 	if initialStake.Cmp(vs.minStake) < 0 {
 		return nil, fmt.Errorf("stake insuficiente: requiere mínimo %s, tiene %s", vs.minStake.String(), initialStake.String())
@@ -160,6 +209,8 @@ func (vs *ValidatorSet) RegisterValidator(
 		}
 
 		// Remover validador con menor stake
+		vs.releaseRewards(minStakeVal)
+		vs.markSetChange(minStakeVal)
 		delete(vs.validators, minStakeVal.Address)
 		log.Printf("Removido validador %s con stake %s para hacer espacio", minStakeVal.Address, minStakeVal.Stake.String())
 	}
@@ -173,13 +224,16 @@ func (vs *ValidatorSet) RegisterValidator(
 		CreatedAt:    time.Now(),
 		LastActiveAt: time.Now(),
 	}
+	validator.initRewards()
 
 	vs.validators[address] = validator
 
 	log.Printf("✅ Validador registrado: %s con stake %s", address, initialStake.String())
+	vs.notifyStakeChange(address, initialStake, StakeActionBond)
+	vs.recordHistory(address, StakeActionBond, initialStake, validator.Stake)
 
 	// Guardar validadores
-	if err := vs.SaveValidators(); err != nil {
+	if err := vs.saveValidatorsLocked(); err != nil {
 		log.Printf("Advertencia: error guardando validadores: %v", err)
 	}
 
@@ -196,20 +250,24 @@ func (vs *ValidatorSet) Stake(address string, amount *big.Int) error {
 		return fmt.Errorf("validador no encontrado: %s", address)
 	}
 
-	// Validar que no esté en jail
-	if validator.Jailed {
-		return fmt.Errorf("validador está en jail: %s", address)
+	// Un validador en jail puede agregar stake (re-bond) para volver al mínimo antes del unjail,
+	// salvo que tenga tombstone: no puede volver al set
+	if validator.Tombstoned {
+		return fmt.Errorf("validador con tombstone por doble firma: %s", address)
 	}
 
 	// Actualizar stake
 	validator.Stake.Add(validator.Stake, amount)
 	validator.Power = vs.calculatePower(validator.Stake)
+	validator.initRewards()
 	validator.LastActiveAt = time.Now()
 
 	log.Printf("✅ Stake actualizado para %s: %s (nuevo total: %s)", address, amount.String(), validator.Stake.String())
+	vs.notifyStakeChange(address, amount, StakeActionStake)
+	vs.recordHistory(address, StakeActionStake, amount, validator.Stake)
 
 	// Guardar validadores
-	if err := vs.SaveValidators(); err != nil {
+	if err := vs.saveValidatorsLocked(); err != nil {
 		log.Printf("Advertencia: error guardando validadores: %v", err)
 	}
 
@@ -226,32 +284,40 @@ func (vs *ValidatorSet) Unstake(address string, amount *big.Int) error {
 		return fmt.Errorf("validador no encontrado: %s", address)
 	}
 
-	// Validar que no esté en jail
-	if validator.Jailed {
+	// Validar que no esté en jail (con tombstone puede retirar todo su stake: no va a volver al set)
+	if validator.Jailed && !validator.Tombstoned {
 		return fmt.Errorf("validador está en jail: %s", address)
 	}
 
 	// Validar que no baje del mínimo
 	newStake := new(big.Int).Sub(validator.Stake, amount)
-	if newStake.Cmp(vs.minStake) < 0 {
+	if newStake.Sign() < 0 {
+		return fmt.Errorf("no se puede unstake: stake insuficiente (%s)", validator.Stake.String())
+	}
+	if newStake.Cmp(vs.minStake) < 0 && !validator.Tombstoned {
 		return fmt.Errorf("no se puede unstake: quedaría con %s, requiere mínimo %s", newStake.String(), vs.minStake.String())
 	}
 
 	// Actualizar stake
 	validator.Stake.Sub(validator.Stake, amount)
 	validator.Power = vs.calculatePower(validator.Stake)
+	validator.initRewards()
 	validator.LastActiveAt = time.Now()
 
 	log.Printf("✅ Stake reducido para %s: -%s (nuevo total: %s)", address, amount.String(), validator.Stake.String())
+	vs.notifyStakeChange(address, amount, StakeActionUnstake)
+	vs.recordHistory(address, StakeActionUnstake, amount, validator.Stake)
 
 	// Si el stake es muy bajo, puede ser removido del set activo
 	if validator.Stake.Cmp(vs.minStake) < 0 {
+		vs.releaseRewards(validator)
+		vs.markSetChange(validator)
 		delete(vs.validators, address)
 		log.Printf("⚠️ Validador %s removido por stake insuficiente", address)
 	}
 
 	// Guardar validadores
-	if err := vs.SaveValidators(); err != nil {
+	if err := vs.saveValidatorsLocked(); err != nil {
 		log.Printf("Advertencia: error guardando validadores: %v", err)
 	}
 
@@ -260,6 +326,13 @@ func (vs *ValidatorSet) Unstake(address string, amount *big.Int) error {
 
 // Slash penaliza a un validador por comportamiento malicioso
 func (vs *ValidatorSet) Slash(address string, slashPercent int, jailDuration time.Duration) error {
+	return vs.SlashWithReason(address, slashPercent, jailDuration, JailReasonMisbehavior)
+}
+
+// SlashWithReason penaliza a un validador y lo envía a jail por jailDuration (hora de bloque)
+// El validador sale del set de CometBFT al final del bloque y vuelve con una transacción unjail
+// cuando termina el jail; si el slash lo dejó bajo el stake mínimo debe agregar stake antes
+func (vs *ValidatorSet) SlashWithReason(address string, slashPercent int, jailDuration time.Duration, reason string) error {
 	vs.mutex.Lock()
 	defer vs.mutex.Unlock()
 
@@ -268,6 +341,20 @@ func (vs *ValidatorSet) Slash(address string, slashPercent int, jailDuration tim
 		return fmt.Errorf("validador no encontrado: %s", address)
 	}
 
+	vs.slashLocked(validator, slashPercent, jailDuration, reason)
+
+	// Guardar validadores
+	if err := vs.saveValidatorsLocked(); err != nil {
+		log.Printf("Advertencia: error guardando validadores: %v", err)
+	}
+
+	return nil
+}
+
+// slashLocked descuenta slashPercent del stake, envía al validador a jail y retorna el monto descontado (requiere lock)
+func (vs *ValidatorSet) slashLocked(validator *Validator, slashPercent int, jailDuration time.Duration, reason string) *big.Int {
+	address := validator.Address
+
 	// Calcular cantidad a slashear
 	slashAmount := new(big.Int)
 	slashAmount.Mul(validator.Stake, big.NewInt(int64(slashPercent)))
@@ -278,29 +365,30 @@ func (vs *ValidatorSet) Slash(address string, slashPercent int, jailDuration tim
 
 	// Actualizar power
 	validator.Power = vs.calculatePower(validator.Stake)
+	validator.initRewards()
 
 	// Enviar a jail
 	validator.Jailed = true
-	validator.JailedUntil = time.Now().Add(jailDuration)
+	validator.JailedUntil = vs.now().Add(jailDuration)
+	validator.JailReason = reason
+	vs.markSetChange(validator)
 
 	log.Printf("⚠️ Validador slasheado: %s -%s (%%%d)", address, slashAmount.String(), slashPercent)
-	log.Printf("⛓️ Validador %s enviado a jail hasta %s", address, validator.JailedUntil.Format(time.RFC3339))
-
-	// Si el stake es muy bajo después de slash, remover
-	if validator.Stake.Cmp(vs.minStake) < 0 {
-		delete(vs.validators, address)
-		log.Printf("⚠️ Validador %s removido por stake insuficiente después de slash", address)
-	}
+	log.Printf("⛓️ Validador %s enviado a jail hasta %s (%s)", address, validator.JailedUntil.Format(time.RFC3339), reason)
+	event := vs.historyEvent(ValidatorEventSlash, slashAmount, validator.Stake)
+	event.Reason = reason
+	vs.appendHistory(address, event)
 
-	// Guardar validadores
-	if err := vs.SaveValidators(); err != nil {
-		log.Printf("Advertencia: error guardando validadores: %v", err)
+	// Si el stake quedó bajo el mínimo, el validador sigue en jail hasta que agregue stake (re-bond)
+	if validator.Stake.Cmp(vs.minStake) < 0 && !validator.Tombstoned {
+		log.Printf("⚠️ Validador %s bajo el stake mínimo después de slash: requiere re-bond antes del unjail", address)
 	}
 
-	return nil
+	return slashAmount
 }
 
-// Unjail libera a un validador de jail
+// Unjail libera a un validador de jail una vez cumplido el plazo (hora de bloque) y con el stake mínimo
+// El validador vuelve al set de CometBFT al final del bloque
 func (vs *ValidatorSet) Unjail(address string) error {
 	vs.mutex.Lock()
 	defer vs.mutex.Unlock()
@@ -314,19 +402,32 @@ func (vs *ValidatorSet) Unjail(address string) error {
 		return fmt.Errorf("validador no está en jail: %s", address)
 	}
 
-	if time.Now().Before(validator.JailedUntil) {
+	if validator.Tombstoned {
+		return fmt.Errorf("validador con tombstone por doble firma: no puede salir de jail")
+	}
+
+	if vs.now().Before(validator.JailedUntil) {
 		return fmt.Errorf("validador aún está en jail hasta %s", validator.JailedUntil.Format(time.RFC3339))
 	}
 
+	if validator.Stake.Cmp(vs.minStake) < 0 {
+		return fmt.Errorf("stake insuficiente para salir de jail: requiere mínimo %s, tiene %s (agregar stake con la acción stake)",
+			vs.minStake.String(), validator.Stake.String())
+	}
+
 	// Liberar de jail
 	validator.Jailed = false
 	validator.JailedUntil = time.Time{}
+	validator.JailReason = ""
 	validator.MissedBlocks = 0
+	vs.markSetChange(validator)
 
 	log.Printf("✅ Validador %s liberado de jail", address)
+	vs.notifyStakeChange(address, new(big.Int), StakeActionUnjail)
+	vs.recordHistory(address, ValidatorEventUnjail, nil, validator.Stake)
 
 	// Guardar validadores
-	if err := vs.SaveValidators(); err != nil {
+	if err := vs.saveValidatorsLocked(); err != nil {
 		log.Printf("Advertencia: error guardando validadores: %v", err)
 	}
 
@@ -383,6 +484,17 @@ func (vs *ValidatorSet) GetValidator(address string) (*Validator, error) {
 	return validator, nil
 }
 
+// StakeOf retorna el stake de una cuenta (cero si no es validador)
+func (vs *ValidatorSet) StakeOf(address string) *big.Int {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+
+	if validator, exists := vs.validators[address]; exists && validator.Stake != nil {
+		return new(big.Int).Set(validator.Stake)
+	}
+	return new(big.Int)
+}
+
 // ToCometBFTValidators convierte validadores a formato CometBFT
 func (vs *ValidatorSet) ToCometBFTValidators() []abcitypes.ValidatorUpdate {
 	vs.mutex.RLock()
@@ -423,6 +535,8 @@ func (vs *ValidatorSet) ToCometBFTValidators() []abcitypes.ValidatorUpdate {
 		})
 	}
 
+	// CometBFT rechaza un set cuyo poder total supere MaxTotalVotingPower (stakes muy grandes)
+	scaleValidatorUpdates(updates)
 	return updates
 }
 
@@ -585,6 +699,7 @@ func (vs *ValidatorSet) RotateValidators() ([]abcitypes.ValidatorUpdate, error)
 
 	log.Printf("🔄 Rotación de validadores: %d validadores activos", len(updates))
 
+	scaleValidatorUpdates(updates)
 	return updates, nil
 }
 
@@ -599,6 +714,24 @@ func (vs *ValidatorSet) InitializeGenesisValidators(genesisValidators []GenesisV
 	fmt.Fprintf(os.Stdout, "[Validators] Mutex adquirido\n")
 	os.Stdout.Sync()
 
+	// El stake genesis se emite a la custodia de staking: el supply y el stake coinciden desde el
+	// bloque 1 y unstake devuelve fondos que existen en el estado
+	totalStake := new(big.Int)
+	for _, gv := range genesisValidators {
+		if gv.Stake == nil || gv.Stake.Sign() <= 0 {
+			vs.mutex.Unlock()
+			return fmt.Errorf("stake genesis inválido para %s", gv.Address)
+		}
+		totalStake.Add(totalStake, gv.Stake)
+	}
+	if vs.executor != nil && totalStake.Sign() > 0 {
+		if err := vs.executor.MintGenesis(StakingAddress, totalStake); err != nil {
+			vs.mutex.Unlock()
+			return fmt.Errorf("error fondeando stake genesis: %w", err)
+		}
+		log.Printf("🔒 Stake genesis %s bloqueado en %s", totalStake.String(), StakingAddress)
+	}
+
 	fmt.Fprintf(os.Stdout, "[Validators] Iterando sobre %d validadores genesis...\n", len(genesisValidators))
 	os.Stdout.Sync()
 	for i, gv := range genesisValidators {
@@ -613,6 +746,7 @@ func (vs *ValidatorSet) InitializeGenesisValidators(genesisValidators []GenesisV
 			CreatedAt:    time.Now(),
 			LastActiveAt: time.Now(),
 		}
+		validator.initRewards()
 
 		vs.validators[gv.Address] = validator
 		log.Printf("✅ Validador genesis registrado: %s con stake %s", gv.Address, gv.Stake.String())
@@ -694,3 +694,51 @@ func TestValidatorSet_RotateValidators(t *testing.T) {
 		}
 	}
 }
+
+// TestValidatorSet_GenesisStakeFunding prueba que el stake genesis quede bloqueado en la custodia de staking
+func TestValidatorSet_GenesisStakeFunding(t *testing.T) {
+	testDir := createValidatorTestDir("genesis_funding")
+	defer func() {
+		if err := cleanupValidatorTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+
+	oxg := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	validatorSet := NewValidatorSet(db, evm, new(big.Int).Mul(big.NewInt(1000), oxg), 100)
+	err = validatorSet.InitializeGenesisValidators([]GenesisValidator{
+		{Address: "0x1111111111111111111111111111111111111111", PubKey: make([]byte, 32), Stake: new(big.Int).Mul(big.NewInt(5000), oxg)},
+		{Address: "0x2222222222222222222222222222222222222222", PubKey: make([]byte, 32), Stake: new(big.Int).Mul(big.NewInt(3000), oxg)},
+	})
+	if err != nil {
+		t.Fatalf("Error inicializando validadores: %v", err)
+	}
+
+	expected := new(big.Int).Mul(big.NewInt(8000), oxg).String()
+	pool, err := evm.GetState(StakingAddress)
+	if err != nil || pool.Balance != expected {
+		t.Fatalf("La custodia de staking debería tener %s: %+v (%v)", expected, pool, err)
+	}
+	supply, err := evm.GetSupply(StakingAddress)
+	if err != nil || supply.Minted != expected || supply.Locked != expected || supply.Circulating != "0" {
+		t.Errorf("Emisión inconsistente con el stake: %+v (%v)", supply, err)
+	}
+
+	// Un stake genesis inválido se rechaza sin emitir fondos
+	err = validatorSet.InitializeGenesisValidators([]GenesisValidator{{Address: "0x3333333333333333333333333333333333333333", PubKey: make([]byte, 32)}})
+	if err == nil {
+		t.Error("Un validador genesis sin stake debería rechazarse")
+	}
+}
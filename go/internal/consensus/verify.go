@@ -0,0 +1,163 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// ChainVerification es el resultado de verificar los bloques guardados en BlockchainDB
+type ChainVerification struct {
+	LatestHeight  uint64   `json:"latestHeight"`
+	LatestAppHash string   `json:"latestAppHash"`
+	Blocks        uint64   `json:"blocks"`
+	Transactions  uint64   `json:"transactions"`
+	MissingBlocks uint64   `json:"missingBlocks"` // Alturas sin bloque guardado (p. ej. anteriores a un snapshot restaurado)
+	Errors        []string `json:"errors,omitempty"`
+	maxErrors     int
+	previousBlock *Block
+	addresses     map[string]bool // Direcciones de las transacciones, para verificar sus balances
+}
+
+// addError registra un problema; retorna true si se alcanzó el máximo
+func (v *ChainVerification) addError(format string, args ...interface{}) bool {
+	v.Errors = append(v.Errors, fmt.Sprintf(format, args...))
+	return v.maxErrors > 0 && len(v.Errors) >= v.maxErrors
+}
+
+// VerifyChain valida la consistencia bloque→receipt→transacción de todos los bloques guardados:
+// altura y encadenamiento de cada bloque, roots y gas del header, un receipt por transacción que apunte a
+// ella y al bloque, y que cada transacción esté indexada por hash con el mismo contenido
+// Se detiene después de maxErrors problemas (0 = recorrer todo)
+func VerifyChain(db *storage.BlockchainDB, maxErrors int) (*ChainVerification, error) {
+	latest, err := db.GetLatestHeight()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo último bloque: %w", err)
+	}
+
+	result := &ChainVerification{LatestHeight: latest, maxErrors: maxErrors, addresses: make(map[string]bool)}
+	for height := uint64(1); height <= latest; height++ {
+		data, err := db.GetBlock(height)
+		if err != nil || data == nil {
+			// Tras restaurar un snapshot no hay bloques anteriores; solo el último es obligatorio
+			result.MissingBlocks++
+			result.previousBlock = nil
+			if height == latest && result.addError("último bloque %d no encontrado", height) {
+				return result, nil
+			}
+			continue
+		}
+		if stop := result.verifyBlock(db, height, data); stop {
+			return result, nil
+		}
+	}
+
+	if result.previousBlock != nil {
+		result.LatestAppHash = result.previousBlock.Header.AppHash()
+	}
+	return result, nil
+}
+
+// Addresses retorna las direcciones que aparecen en las transacciones verificadas
+func (v *ChainVerification) Addresses() []string {
+	addresses := make([]string, 0, len(v.addresses))
+	for address := range v.addresses {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// verifyBlock valida un bloque y sus transacciones; retorna true si se alcanzó maxErrors
+func (v *ChainVerification) verifyBlock(db *storage.BlockchainDB, height uint64, data []byte) bool {
+	block, err := DecodeBlock(data)
+	if err != nil {
+		v.previousBlock = nil
+		return v.addError("bloque %d no decodificable: %v", height, err)
+	}
+	v.Blocks++
+
+	if block.Header.Height != height {
+		if v.addError("bloque %d guardado con altura %d", height, block.Header.Height) {
+			return true
+		}
+	}
+	if v.previousBlock != nil && block.Header.ParentHash != v.previousBlock.Header.Hash {
+		if v.addError("bloque %d: parentHash %s no coincide con el hash del bloque %d (%s)",
+			height, block.Header.ParentHash, height-1, v.previousBlock.Header.Hash) {
+			return true
+		}
+	}
+	v.previousBlock = block
+
+	if err := block.VerifyHeader(); err != nil {
+		if v.addError("bloque %d: %v", height, err) {
+			return true
+		}
+	}
+	if len(block.Receipts) != len(block.Transactions) {
+		if v.addError("bloque %d: %d transacciones y %d receipts", height, len(block.Transactions), len(block.Receipts)) {
+			return true
+		}
+	}
+
+	for i, tx := range block.Transactions {
+		v.Transactions++
+		if tx == nil {
+			if v.addError("bloque %d: transacción %d vacía", height, i) {
+				return true
+			}
+			continue
+		}
+		v.addresses[tx.From] = true
+		if tx.To != "" {
+			v.addresses[tx.To] = true
+		}
+		if i < len(block.Receipts) {
+			if reason := checkReceipt(block.Receipts[i], tx, height); reason != "" {
+				if v.addError("bloque %d, transacción %d (%s): %s", height, i, tx.Hash, reason) {
+					return true
+				}
+			}
+		}
+		if reason := checkIndexedTransaction(db, tx); reason != "" {
+			if v.addError("bloque %d, transacción %d (%s): %s", height, i, tx.Hash, reason) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkReceipt verifica que el receipt corresponda a la transacción y al bloque (vacío si es correcto)
+func checkReceipt(receipt *TransactionReceipt, tx *Transaction, height uint64) string {
+	switch {
+	case receipt == nil:
+		return "receipt vacío"
+	case receipt.TransactionHash != tx.Hash:
+		return fmt.Sprintf("receipt de otra transacción (%s)", receipt.TransactionHash)
+	case receipt.BlockNumber != height:
+		return fmt.Sprintf("receipt del bloque %d", receipt.BlockNumber)
+	}
+	return ""
+}
+
+// checkIndexedTransaction verifica que la transacción esté guardada por hash con el mismo contenido
+func checkIndexedTransaction(db *storage.BlockchainDB, tx *Transaction) string {
+	data, err := db.GetTransaction(tx.Hash)
+	if err != nil || data == nil {
+		return "no está indexada por hash"
+	}
+	indexed, err := DecodeTransaction(data)
+	if err != nil {
+		return fmt.Sprintf("transacción indexada no decodificable: %v", err)
+	}
+	expected, _ := json.Marshal(tx)
+	actual, _ := json.Marshal(indexed)
+	if string(expected) != string(actual) {
+		return "la transacción indexada difiere de la del bloque"
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package consensus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TestVerifyChain_DetectsInconsistencies valida la cadena guardada y detecta receipts e índices rotos
+func TestVerifyChain_DetectsInconsistencies(t *testing.T) {
+	app, _ := newSnapshotTestApp(t, "verify_chain")
+	commitTestBlocks(t, app, 1, 3)
+
+	result, err := VerifyChain(app.storage, 0)
+	if err != nil {
+		t.Fatalf("Error en VerifyChain: %v", err)
+	}
+	if len(result.Errors) != 0 || result.Blocks != 3 {
+		t.Fatalf("Cadena válida reportada con problemas: %+v", result)
+	}
+	if result.LatestAppHash != common.BytesToHash(app.state.AppHash).Hex() {
+		t.Errorf("LatestAppHash %s no coincide con el app hash confirmado", result.LatestAppHash)
+	}
+
+	// Bloque 3 con una transacción sin indexar y un receipt de otra transacción
+	data, _ := app.storage.GetBlock(3)
+	block, err := DecodeBlock(data)
+	if err != nil {
+		t.Fatalf("Error decodificando bloque: %v", err)
+	}
+	block.Transactions = []*Transaction{{Hash: "0xaaaa", From: "0x1234567890123456789012345678901234567890"}}
+	block.Receipts = []*TransactionReceipt{{TransactionHash: "0xbbbb", BlockNumber: 3}}
+	data, _ = EncodeBlock(block)
+	if err := app.storage.SaveBlock(3, data); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+
+	result, err = VerifyChain(app.storage, 0)
+	if err != nil {
+		t.Fatalf("Error en VerifyChain: %v", err)
+	}
+	// El header del bloque ya no corresponde a sus transacciones
+	if len(result.Errors) != 3 {
+		t.Fatalf("Se esperaban 3 problemas, hubo %d: %v", len(result.Errors), result.Errors)
+	}
+	if !strings.Contains(result.Errors[0], "transactionsRoot") || !strings.Contains(result.Errors[1], "receipt de otra transacción") || !strings.Contains(result.Errors[2], "no está indexada") {
+		t.Errorf("Problemas inesperados: %v", result.Errors)
+	}
+	if addresses := result.Addresses(); len(addresses) != 1 {
+		t.Errorf("Se esperaba 1 dirección, hubo %v", addresses)
+	}
+}
+
+// TestVerifyState_TotalSupply recorre el trie y recalcula el total de balances
+func TestVerifyState_TotalSupply(t *testing.T) {
+	app, _ := newSnapshotTestApp(t, "verify_state")
+
+	funded := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	if err := app.executor.FundAccount(funded.Hex(), "1000"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	if err := app.executor.FundAccount("0x0000000000000000000000000000000000000abc", "500"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	commitTestBlocks(t, app, 1, 1)
+
+	unknown := common.HexToAddress("0x00000000000000000000000000000000000000ff")
+	result, err := app.executor.GetStateManager().VerifyState([]common.Address{funded, unknown}, 0)
+	if err != nil {
+		t.Fatalf("Error en VerifyState: %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Fatalf("Estado válido reportado con problemas: %v", result.Errors)
+	}
+	// Las dos cuentas fondeadas y la cuenta de sistema de los contadores de emisión
+	if result.Accounts != 3 || result.CheckedAddrs != 2 || result.TrieNodes == 0 {
+		t.Errorf("Resultado inesperado: %+v", result)
+	}
+	if result.TotalSupply.String() != "1500" {
+		t.Errorf("Total recalculado %s, se esperaba 1500", result.TotalSupply)
+	}
+	if result.Root != common.BytesToHash(app.state.AppHash).Hex() {
+		t.Errorf("Root %s no coincide con el app hash", result.Root)
+	}
+}
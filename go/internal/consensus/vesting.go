@@ -0,0 +1,241 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// VestingAddress es la dirección reservada que recibe las transacciones que crean un vesting
+// (ver execution.VestingAddress)
+const VestingAddress = execution.VestingAddress
+
+// VestingActionCreate crea un vesting para address con el valor de la transacción
+const VestingActionCreate = "create"
+
+// VestingPayload es el contenido (JSON en Transaction.Data) de una transacción de vesting
+// Las fechas son Unix en segundos: start <= cliff <= end
+type VestingPayload struct {
+	Action  string `json:"action"`
+	Address string `json:"address"` // Beneficiario (no puede tener otro vesting)
+	Start   int64  `json:"start"`
+	Cliff   int64  `json:"cliff"`
+	End     int64  `json:"end"`
+}
+
+// GenesisVesting es un vesting del genesis sobre el balance de una cuenta de accounts (monto en wei)
+type GenesisVesting struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+	Start   int64  `json:"start"`
+	Cliff   int64  `json:"cliff"`
+	End     int64  `json:"end"`
+}
+
+// schedule convierte el vesting del genesis en un calendario validado
+func (g GenesisVesting) schedule() (*execution.VestingSchedule, error) {
+	amount, ok := new(big.Int).SetString(g.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("monto de vesting genesis inválido para %s: %s", g.Address, g.Amount)
+	}
+	schedule := &execution.VestingSchedule{Amount: amount, Start: g.Start, Cliff: g.Cliff, End: g.End}
+	if err := schedule.Validate(); err != nil {
+		return nil, fmt.Errorf("vesting genesis de %s: %w", g.Address, err)
+	}
+	return schedule, nil
+}
+
+// validateGenesisVesting verifica que cada vesting sea de una cuenta del genesis con balance suficiente
+func validateGenesisVesting(vesting []GenesisVesting, accounts []GenesisAccount) error {
+	balances := make(map[string]*big.Int, len(accounts))
+	for _, account := range accounts {
+		balance, _ := new(big.Int).SetString(account.Balance, 10)
+		key := strings.ToLower(account.Address)
+		if prev, ok := balances[key]; ok {
+			balance.Add(balance, prev)
+		}
+		balances[key] = balance
+	}
+	seen := make(map[string]bool, len(vesting))
+	for _, entry := range vesting {
+		if !common.IsHexAddress(entry.Address) {
+			return fmt.Errorf("dirección inválida en vesting del genesis: %s", entry.Address)
+		}
+		key := strings.ToLower(entry.Address)
+		if seen[key] {
+			return fmt.Errorf("vesting genesis duplicado para %s", entry.Address)
+		}
+		seen[key] = true
+		schedule, err := entry.schedule()
+		if err != nil {
+			return err
+		}
+		if balance, ok := balances[key]; !ok || balance.Cmp(schedule.Amount) < 0 {
+			return fmt.Errorf("vesting genesis de %s mayor que su balance en accounts", entry.Address)
+		}
+	}
+	return nil
+}
+
+// isVestingTx retorna si la transacción va dirigida al registro de vesting
+func isVestingTx(tx *Transaction) bool {
+	return strings.EqualFold(tx.To, VestingAddress)
+}
+
+// initGenesisVesting registra los vesting del genesis (una sola vez, en InitChain, después de fondear las cuentas)
+func (app *ABCIApp) initGenesisVesting(appState []byte) error {
+	params, err := ParseGenesisParams(appState)
+	if err != nil {
+		return err
+	}
+	if len(params.Vesting) == 0 || app.executor == nil {
+		return nil
+	}
+	for _, entry := range params.Vesting {
+		schedule, err := entry.schedule()
+		if err != nil {
+			return err
+		}
+		if err := app.executor.SetVestingSchedule(entry.Address, schedule); err != nil {
+			return fmt.Errorf("error registrando vesting genesis %s: %w", entry.Address, err)
+		}
+	}
+	consensusLog.Infof("%d cuentas con vesting en el genesis", len(params.Vesting))
+	return nil
+}
+
+// lockedUnstaked retorna lo bloqueado por vesting de una cuenta que no está en stake, en el estado
+// confirmado (CheckTx) o en el del bloque en ejecución. El stake se cuenta primero contra lo bloqueado:
+// stakear fondos en vesting no libera fondos transferibles, y retirarlos del stake los vuelve a bloquear
+func (app *ABCIApp) lockedUnstaked(address string, committed bool) *big.Int {
+	var schedule *execution.VestingSchedule
+	if committed {
+		schedule, _ = app.executor.GetCommittedVestingSchedule(address)
+	} else {
+		schedule = app.executor.VestingSchedule(address)
+	}
+	if schedule == nil {
+		return new(big.Int)
+	}
+	locked := schedule.Locked(app.currentBlockTime)
+	if app.validators != nil {
+		locked.Sub(locked, app.validators.StakeOf(address))
+	}
+	if locked.Sign() < 0 {
+		return new(big.Int)
+	}
+	return locked
+}
+
+// checkVestingSpend rechaza la transacción si el remitente (valor y gas) o el fee payer (gas) gastarían fondos
+// bloqueados por vesting. El valor enviado a staking puede salir de lo bloqueado
+func (app *ABCIApp) checkVestingSpend(tx *Transaction, committed bool) error {
+	if app.executor == nil {
+		return nil
+	}
+	gasPrice, ok := new(big.Int).SetString(tx.GasPrice, 10)
+	if !ok {
+		return nil
+	}
+	gasCost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(tx.GasLimit))
+
+	spend := new(big.Int)
+	if value, ok := new(big.Int).SetString(tx.Value, 10); ok && !isStakingTx(tx) {
+		spend.Set(value)
+	}
+	if isSponsoredTx(tx) {
+		if err := app.checkSpendable(tx.FeePayer.Address, gasCost, committed); err != nil {
+			return err
+		}
+	} else {
+		spend.Add(spend, gasCost)
+	}
+	return app.checkSpendable(tx.From, spend, committed)
+}
+
+// checkSpendable verifica que una cuenta pueda gastar amount sin tocar lo bloqueado por vesting
+func (app *ABCIApp) checkSpendable(address string, amount *big.Int, committed bool) error {
+	if amount.Sign() <= 0 {
+		return nil
+	}
+	locked := app.lockedUnstaked(address, committed)
+	if locked.Sign() == 0 {
+		return nil
+	}
+	var account *execution.AccountState
+	var err error
+	if committed {
+		account, err = app.executor.GetCommittedState(address)
+	} else {
+		account, err = app.executor.GetState(address)
+	}
+	if err != nil || account == nil {
+		return nil
+	}
+	balance, ok := new(big.Int).SetString(account.Balance, 10)
+	if !ok {
+		return nil
+	}
+	spendable := new(big.Int).Sub(balance, locked)
+	if spendable.Cmp(amount) < 0 {
+		if spendable.Sign() < 0 {
+			spendable.SetInt64(0)
+		}
+		return NewTxError(CodeVestingLocked, "fondos bloqueados por vesting en %s: disponible %s, necesita %s",
+			address, spendable, amount)
+	}
+	return nil
+}
+
+// applyVestingTx aplica una transacción de vesting ya ejecutada con éxito
+// Si la acción falla, el valor transferido al registro se devuelve al remitente
+func (app *ABCIApp) applyVestingTx(tx *Transaction) error {
+	err := app.executeVestingAction(tx)
+	if err == nil {
+		return nil
+	}
+	if value, ok := new(big.Int).SetString(tx.Value, 10); ok && value.Sign() > 0 {
+		if refundErr := app.executor.TransferBalance(VestingAddress, tx.From, value); refundErr != nil {
+			return fmt.Errorf("%v (error devolviendo fondos: %v)", err, refundErr)
+		}
+	}
+	return err
+}
+
+// executeVestingAction crea el vesting del beneficiario y le pasa el valor de la transacción
+func (app *ABCIApp) executeVestingAction(tx *Transaction) error {
+	var payload VestingPayload
+	if err := json.Unmarshal(tx.Data, &payload); err != nil {
+		return fmt.Errorf("payload de vesting inválido: %w", err)
+	}
+	if payload.Action != VestingActionCreate {
+		return fmt.Errorf("acción de vesting desconocida: %q", payload.Action)
+	}
+	if !common.IsHexAddress(payload.Address) {
+		return fmt.Errorf("dirección inválida: %q", payload.Address)
+	}
+	amount, ok := new(big.Int).SetString(tx.Value, 10)
+	if !ok || amount.Sign() <= 0 {
+		return fmt.Errorf("el vesting requiere valor: %q", tx.Value)
+	}
+	if app.executor.VestingSchedule(payload.Address) != nil {
+		return fmt.Errorf("la cuenta %s ya tiene un vesting", payload.Address)
+	}
+
+	schedule := &execution.VestingSchedule{Amount: amount, Start: payload.Start, Cliff: payload.Cliff, End: payload.End}
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	if err := app.executor.TransferBalance(VestingAddress, payload.Address, amount); err != nil {
+		return err
+	}
+	if err := app.executor.SetVestingSchedule(payload.Address, schedule); err != nil {
+		return err
+	}
+	consensusLog.Infof("Vesting de %s para %s (por %s)", amount, payload.Address, tx.From)
+	return nil
+}
@@ -0,0 +1,142 @@
+package consensus
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// TestGenesisVesting_Validate prueba que los vesting del genesis cubran balances de accounts
+func TestGenesisVesting_Validate(t *testing.T) {
+	const account = `{"address":"0x1111111111111111111111111111111111111111","balance":"1000"}`
+	if _, err := ParseGenesisParams([]byte(`{"accounts":[` + account + `],"vesting":[{"address":"0x1111111111111111111111111111111111111111","amount":"1000","start":100,"cliff":150,"end":200}]}`)); err != nil {
+		t.Fatalf("Vesting válido rechazado: %v", err)
+	}
+	for _, vesting := range []string{
+		`{"address":"0x1111111111111111111111111111111111111111","amount":"1001","start":100,"cliff":150,"end":200}`,
+		`{"address":"0x2222222222222222222222222222222222222222","amount":"1","start":100,"cliff":150,"end":200}`,
+		`{"address":"0x1111111111111111111111111111111111111111","amount":"1000","start":100,"cliff":250,"end":200}`,
+		`{"address":"0x1111111111111111111111111111111111111111","amount":"0","start":100,"cliff":150,"end":200}`,
+	} {
+		if _, err := ParseGenesisParams([]byte(`{"accounts":[` + account + `],"vesting":[` + vesting + `]}`)); err == nil {
+			t.Errorf("Se esperaba error para %s", vesting)
+		}
+	}
+}
+
+// TestVestingSpend prueba que los fondos bloqueados no se gasten, que se puedan stakear y la creación por transacción
+func TestVestingSpend(t *testing.T) {
+	testDir := createValidatorTestDir("vesting")
+	defer func() {
+		if err := cleanupValidatorTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := execution.NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+	app := NewABCIApp(db, evm, NewValidatorSet(db, evm, big.NewInt(100), 10), "test-chain")
+
+	const (
+		holder      = "0x1111111111111111111111111111111111111111"
+		funder      = "0x2222222222222222222222222222222222222222"
+		beneficiary = "0x3333333333333333333333333333333333333333"
+	)
+	appState := []byte(`{"accounts":[{"address":"` + holder + `","balance":"1000"}],` +
+		`"vesting":[{"address":"` + holder + `","amount":"1000","start":100,"cliff":120,"end":200}]}`)
+	if err := app.mintGenesisAccounts(appState); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.initGenesisVesting(appState); err != nil {
+		t.Fatal(err)
+	}
+
+	transfer := func(value string) *Transaction {
+		return &Transaction{From: holder, To: funder, Value: value, GasPrice: "0", GasLimit: 21000}
+	}
+	// Antes del cliff todo está bloqueado; a mitad de camino, la mitad
+	app.currentBlockTime = 110
+	if err := app.checkVestingSpend(transfer("1"), false); ErrorCode(err, CodeOK) != CodeVestingLocked {
+		t.Errorf("Antes del cliff se esperaba CodeVestingLocked: %v", err)
+	}
+	app.currentBlockTime = 150
+	if err := app.checkVestingSpend(transfer("500"), false); err != nil {
+		t.Errorf("La mitad liberada debería poder transferirse: %v", err)
+	}
+	if err := app.checkVestingSpend(transfer("501"), false); ErrorCode(err, CodeOK) != CodeVestingLocked {
+		t.Errorf("Se esperaba CodeVestingLocked al gastar fondos bloqueados: %v", err)
+	}
+	// El gas también se cuenta
+	withGas := transfer("500")
+	withGas.GasPrice = "1"
+	if err := app.checkVestingSpend(withGas, false); err == nil {
+		t.Error("Valor más gas por encima de lo disponible debería rechazarse")
+	}
+
+	// Los fondos bloqueados se pueden stakear, y el stake no libera fondos transferibles
+	bond := &Transaction{
+		From:     holder,
+		To:       StakingAddress,
+		Value:    "300",
+		GasPrice: "0",
+		Data:     []byte(`{"action":"bond","pubKey":"` + strings.Repeat("ab", 32) + `"}`),
+	}
+	if err := app.checkVestingSpend(bond, false); err != nil {
+		t.Fatalf("El stake de fondos bloqueados debería permitirse: %v", err)
+	}
+	if err := evm.TransferBalance(holder, StakingAddress, big.NewInt(300)); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.applyStakingTx(bond); err != nil {
+		t.Fatalf("Error aplicando bond: %v", err)
+	}
+	// Balance 700, bloqueado 500 de los que 300 están en stake: siguen disponibles 500
+	if err := app.checkVestingSpend(transfer("500"), false); err != nil {
+		t.Errorf("Lo liberado debería seguir disponible: %v", err)
+	}
+	if err := app.checkVestingSpend(transfer("501"), false); err == nil {
+		t.Error("El stake no debería liberar fondos bloqueados")
+	}
+
+	// Vesting creado por transacción: el valor llega al registro y pasa al beneficiario
+	create := func(value string) *Transaction {
+		data, _ := json.Marshal(VestingPayload{Action: VestingActionCreate, Address: beneficiary, Start: 100, Cliff: 100, End: 300})
+		return &Transaction{From: funder, To: VestingAddress, Value: value, Data: data}
+	}
+	if err := evm.FundAccount(VestingAddress, "300"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.applyVestingTx(create("300")); err != nil {
+		t.Fatalf("Error creando vesting: %v", err)
+	}
+	if schedule := evm.VestingSchedule(beneficiary); schedule == nil || schedule.Amount.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("Vesting del beneficiario incorrecto: %+v", schedule)
+	}
+	if state, _ := evm.GetState(beneficiary); state.Balance != "300" {
+		t.Errorf("El beneficiario debería recibir el valor: %s", state.Balance)
+	}
+
+	// Un segundo vesting para la misma cuenta se rechaza y el valor vuelve al remitente
+	if err := evm.FundAccount(VestingAddress, "50"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.applyVestingTx(create("50")); err == nil {
+		t.Error("Una cuenta con vesting no debería recibir otro")
+	}
+	if state, _ := evm.GetState(funder); state.Balance != "50" {
+		t.Errorf("El valor debería devolverse al remitente: %s", state.Balance)
+	}
+}
@@ -0,0 +1,44 @@
+package consensus
+
+import (
+	"github.com/Q-YZX0/oxy-blockchain/internal/watchlist"
+)
+
+// WatchlistBlock convierte un bloque confirmado al formato que compara el watchlist
+// Los logs de cada transacción se toman de su receipt
+func WatchlistBlock(block *Block) *watchlist.Block {
+	receipts := make(map[string]*TransactionReceipt, len(block.Receipts))
+	for _, receipt := range block.Receipts {
+		if receipt != nil {
+			receipts[receipt.TransactionHash] = receipt
+		}
+	}
+
+	converted := &watchlist.Block{
+		Height:       block.Header.Height,
+		Hash:         block.Header.Hash,
+		Transactions: make([]watchlist.Transaction, 0, len(block.Transactions)),
+	}
+	for _, tx := range block.Transactions {
+		if tx == nil {
+			continue
+		}
+		wtx := watchlist.Transaction{
+			Hash:  tx.Hash,
+			From:  tx.From,
+			To:    tx.To,
+			Value: tx.Value,
+		}
+		if receipt, ok := receipts[tx.Hash]; ok {
+			for _, log := range receipt.Logs {
+				wtx.Logs = append(wtx.Logs, watchlist.Log{
+					Address: log.Address,
+					Topics:  log.Topics,
+					Data:    log.Data,
+				})
+			}
+		}
+		converted.Transactions = append(converted.Transactions, wtx)
+	}
+	return converted
+}
@@ -0,0 +1,183 @@
+package consensus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/wire"
+)
+
+// ToWire convierte la transacción al tipo de types.proto (formato de los SDKs y del mesh)
+func (tx *Transaction) ToWire() *wire.Transaction {
+	msg := &wire.Transaction{
+		Hash:      tx.Hash,
+		From:      tx.From,
+		To:        tx.To,
+		Value:     tx.Value,
+		Data:      tx.Data,
+		GasLimit:  tx.GasLimit,
+		GasPrice:  tx.GasPrice,
+		Nonce:     tx.Nonce,
+		Signature: tx.Signature,
+		Timestamp: tx.Timestamp,
+	}
+	for _, tuple := range tx.AccessList {
+		msg.AccessList = append(msg.AccessList, &wire.AccessTuple{Address: tuple.Address, StorageKeys: tuple.StorageKeys})
+	}
+	if tx.Multisig != nil {
+		msg.Multisig = &wire.MultisigAuth{Threshold: tx.Multisig.Threshold, Owners: tx.Multisig.Owners}
+		for _, sig := range tx.Multisig.Signatures {
+			msg.Multisig.Signatures = append(msg.Multisig.Signatures, &wire.MultisigSignature{Signer: sig.Signer, Signature: sig.Signature})
+		}
+	}
+	if tx.FeePayer != nil {
+		msg.FeePayer = &wire.FeePayer{Address: tx.FeePayer.Address, Signature: tx.FeePayer.Signature}
+	}
+	return msg
+}
+
+// TransactionFromWire convierte una transacción de types.proto
+func TransactionFromWire(msg *wire.Transaction) *Transaction {
+	tx := &Transaction{
+		Hash:      msg.Hash,
+		From:      msg.From,
+		To:        msg.To,
+		Value:     msg.Value,
+		Data:      msg.Data,
+		GasLimit:  msg.GasLimit,
+		GasPrice:  msg.GasPrice,
+		Nonce:     msg.Nonce,
+		Signature: msg.Signature,
+		Timestamp: msg.Timestamp,
+	}
+	for _, tuple := range msg.AccessList {
+		tx.AccessList = append(tx.AccessList, execution.AccessTuple{
+			Address:     tuple.Address,
+			StorageKeys: append([]string{}, tuple.StorageKeys...),
+		})
+	}
+	if msg.Multisig != nil {
+		tx.Multisig = &MultisigAuth{Threshold: msg.Multisig.Threshold, Owners: msg.Multisig.Owners}
+		for _, sig := range msg.Multisig.Signatures {
+			tx.Multisig.Signatures = append(tx.Multisig.Signatures, MultisigSignature{Signer: sig.Signer, Signature: sig.Signature})
+		}
+	}
+	if msg.FeePayer != nil {
+		tx.FeePayer = &FeePayerAuth{Address: msg.FeePayer.Address, Signature: msg.FeePayer.Signature}
+	}
+	return tx
+}
+
+// ToWire convierte el bloque al tipo de types.proto (formato de los SDKs y del mesh)
+func (b *Block) ToWire() *wire.Block {
+	header := &b.Header
+	msg := &wire.Block{
+		Header: &wire.BlockHeader{
+			Height:           header.Height,
+			Hash:             header.Hash,
+			ParentHash:       header.ParentHash,
+			Timestamp:        header.Timestamp.Unix(),
+			TimestampNanos:   int32(header.Timestamp.Nanosecond()),
+			Validator:        header.Validator,
+			ChainID:          header.ChainID,
+			StateRoot:        header.StateRoot,
+			TransactionsRoot: header.TransactionsRoot,
+			ReceiptsRoot:     header.ReceiptsRoot,
+			GasUsed:          header.GasUsed,
+			GasLimit:         header.GasLimit,
+		},
+		Transactions: make([]*wire.Transaction, len(b.Transactions)),
+		Receipts:     make([]*wire.TransactionReceipt, len(b.Receipts)),
+	}
+	for i, tx := range b.Transactions {
+		if tx == nil {
+			tx = &Transaction{}
+		}
+		msg.Transactions[i] = tx.ToWire()
+	}
+	for i, receipt := range b.Receipts {
+		msg.Receipts[i] = receiptToWire(receipt)
+	}
+	return msg
+}
+
+// BlockFromWire convierte un bloque de types.proto
+func BlockFromWire(msg *wire.Block) (*Block, error) {
+	if msg.Header == nil {
+		return nil, fmt.Errorf("bloque sin header")
+	}
+	h := msg.Header
+	block := &Block{
+		Header: BlockHeader{
+			Height:           h.Height,
+			Hash:             h.Hash,
+			ParentHash:       h.ParentHash,
+			Timestamp:        time.Unix(h.Timestamp, int64(h.TimestampNanos)).UTC(),
+			Validator:        h.Validator,
+			ChainID:          h.ChainID,
+			StateRoot:        h.StateRoot,
+			TransactionsRoot: h.TransactionsRoot,
+			ReceiptsRoot:     h.ReceiptsRoot,
+			GasUsed:          h.GasUsed,
+			GasLimit:         h.GasLimit,
+		},
+		Transactions: make([]*Transaction, len(msg.Transactions)),
+		Receipts:     make([]*TransactionReceipt, len(msg.Receipts)),
+	}
+	for i, tx := range msg.Transactions {
+		block.Transactions[i] = TransactionFromWire(tx)
+	}
+	for i, receipt := range msg.Receipts {
+		block.Receipts[i] = receiptFromWire(receipt)
+	}
+	return block, nil
+}
+
+// receiptToWire convierte un receipt al tipo de types.proto (vacío si es nil)
+func receiptToWire(receipt *TransactionReceipt) *wire.TransactionReceipt {
+	if receipt == nil {
+		return &wire.TransactionReceipt{}
+	}
+	msg := &wire.TransactionReceipt{
+		TransactionHash: receipt.TransactionHash,
+		BlockHash:       receipt.BlockHash,
+		BlockNumber:     receipt.BlockNumber,
+		GasUsed:         receipt.GasUsed,
+		Status:          receipt.Status,
+		Error:           receipt.Error,
+	}
+	for _, log := range receipt.Logs {
+		msg.Logs = append(msg.Logs, &wire.Log{
+			Address:     log.Address,
+			Topics:      log.Topics,
+			Data:        log.Data,
+			BlockNumber: log.BlockNumber,
+			TxHash:      log.TxHash,
+		})
+	}
+	return msg
+}
+
+// receiptFromWire convierte un receipt de types.proto
+func receiptFromWire(msg *wire.TransactionReceipt) *TransactionReceipt {
+	receipt := &TransactionReceipt{
+		TransactionHash: msg.TransactionHash,
+		BlockHash:       msg.BlockHash,
+		BlockNumber:     msg.BlockNumber,
+		GasUsed:         msg.GasUsed,
+		Status:          msg.Status,
+		Logs:            make([]Log, len(msg.Logs)),
+		Error:           msg.Error,
+	}
+	for i, log := range msg.Logs {
+		receipt.Logs[i] = Log{
+			Address:     log.Address,
+			Topics:      append([]string{}, log.Topics...),
+			Data:        log.Data,
+			BlockNumber: log.BlockNumber,
+			TxHash:      log.TxHash,
+		}
+	}
+	return receipt
+}
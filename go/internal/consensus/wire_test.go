@@ -0,0 +1,39 @@
+package consensus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/wire"
+)
+
+// TestBlockWireRoundTrip prueba que un bloque convertido a types.proto y de vuelta conserve el contenido
+// y siga verificando su header
+func TestBlockWireRoundTrip(t *testing.T) {
+	block := newEncodingTestBlock()
+	var msg wire.Block
+	if err := msg.Unmarshal(block.ToWire().Marshal()); err != nil {
+		t.Fatalf("Error decodificando bloque: %v", err)
+	}
+	decoded, err := BlockFromWire(&msg)
+	if err != nil {
+		t.Fatalf("Error convirtiendo bloque: %v", err)
+	}
+	if err := decoded.VerifyHeader(); err != nil {
+		t.Errorf("Bloque convertido inválido: %v", err)
+	}
+	expected, _ := json.Marshal(block)
+	actual, _ := json.Marshal(decoded)
+	if string(expected) != string(actual) {
+		t.Errorf("Bloque convertido distinto:\n%s\n%s", expected, actual)
+	}
+
+	if _, err := BlockFromWire(&wire.Block{}); err == nil {
+		t.Error("Se esperaba un error con un bloque sin header")
+	}
+
+	tx := TransactionFromWire(block.Transactions[1].ToWire())
+	if tx.Hash != "0x02" || string(tx.Data) != string(block.Transactions[1].Data) || len(tx.AccessList) != 1 {
+		t.Errorf("Transacción convertida incorrecta: %+v", tx)
+	}
+}
@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MaxMultisigOwners es la cantidad máxima de owners de una cuenta multisig
+const MaxMultisigOwners = 20
+
+// multisigAddressPrefix separa el espacio de direcciones multisig del de las claves secp256k1
+var multisigAddressPrefix = []byte("oxy-multisig")
+
+// MultisigAddress deriva la dirección de una cuenta multisig de sus owners y su umbral:
+// keccak256("oxy-multisig" || umbral (8 bytes big endian) || owners ordenados)[12:]
+// No hay clave privada para la dirección: solo la mueven transacciones con threshold firmas de los owners
+func MultisigAddress(owners []string, threshold uint64) (common.Address, error) {
+	sorted, err := SortMultisigOwners(owners, threshold)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	preimage := make([]byte, 0, len(multisigAddressPrefix)+8+len(sorted)*common.AddressLength)
+	preimage = append(preimage, multisigAddressPrefix...)
+	preimage = binary.BigEndian.AppendUint64(preimage, threshold)
+	for _, owner := range sorted {
+		preimage = append(preimage, owner.Bytes()...)
+	}
+	return common.BytesToAddress(crypto.Keccak256(preimage)[12:]), nil
+}
+
+// SortMultisigOwners valida los owners y el umbral de una multisig y retorna los owners ordenados por bytes
+func SortMultisigOwners(owners []string, threshold uint64) ([]common.Address, error) {
+	if len(owners) == 0 || len(owners) > MaxMultisigOwners {
+		return nil, fmt.Errorf("una multisig requiere entre 1 y %d owners, tiene %d", MaxMultisigOwners, len(owners))
+	}
+	if threshold == 0 || threshold > uint64(len(owners)) {
+		return nil, fmt.Errorf("umbral inválido: %d de %d owners", threshold, len(owners))
+	}
+
+	sorted := make([]common.Address, len(owners))
+	for i, owner := range owners {
+		if !common.IsHexAddress(owner) {
+			return nil, fmt.Errorf("owner inválido: %s", owner)
+		}
+		sorted[i] = common.HexToAddress(owner)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i].Bytes(), sorted[j].Bytes()) < 0 })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i] == sorted[i-1] {
+			return nil, fmt.Errorf("owner repetido: %s", sorted[i].Hex())
+		}
+	}
+	return sorted, nil
+}
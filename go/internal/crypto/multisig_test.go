@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TestMultisigAddress prueba que la dirección no dependa del orden de los owners pero sí del umbral
+func TestMultisigAddress(t *testing.T) {
+	owners := []string{
+		"0x0000000000000000000000000000000000000003",
+		"0x0000000000000000000000000000000000000001",
+		"0x0000000000000000000000000000000000000002",
+	}
+	address, err := MultisigAddress(owners, 2)
+	if err != nil {
+		t.Fatalf("Error derivando dirección: %v", err)
+	}
+
+	reordered, err := MultisigAddress([]string{owners[1], owners[2], owners[0]}, 2)
+	if err != nil || reordered != address {
+		t.Errorf("El orden de los owners cambió la dirección: %s != %s (%v)", reordered.Hex(), address.Hex(), err)
+	}
+	if other, _ := MultisigAddress(owners, 3); other == address {
+		t.Error("Umbrales distintos deberían dar direcciones distintas")
+	}
+
+	invalid := []struct {
+		owners    []string
+		threshold uint64
+	}{
+		{nil, 1},
+		{owners, 0},
+		{owners, 4},
+		{[]string{owners[0], owners[0]}, 1},
+		{[]string{"0x01"}, 1},
+	}
+	for _, tc := range invalid {
+		if _, err := MultisigAddress(tc.owners, tc.threshold); err == nil {
+			t.Errorf("Se esperaba un error para %v con umbral %d", tc.owners, tc.threshold)
+		}
+	}
+}
+
+// TestVerifyHashSignature prueba la verificación de una firma parcial, directa y EIP-712
+func TestVerifyHashSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Error generando clave: %v", err)
+	}
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	hash := crypto.Keccak256Hash([]byte("tx"))
+
+	direct, _ := crypto.Sign(hash.Bytes(), key)
+	if err := VerifyHashSignature(hash, direct, signer); err != nil {
+		t.Errorf("Firma directa rechazada: %v", err)
+	}
+	typed, _ := crypto.Sign(TypedTransactionDigest(hash).Bytes(), key)
+	typed[64] += 27
+	if err := VerifyHashSignature(hash, typed, signer); err != nil {
+		t.Errorf("Firma EIP-712 rechazada: %v", err)
+	}
+
+	other, _ := crypto.GenerateKey()
+	if err := VerifyHashSignature(hash, direct, crypto.PubkeyToAddress(other.PublicKey)); err == nil {
+		t.Error("Se esperaba un error por firmante distinto")
+	}
+	if err := VerifyHashSignature(crypto.Keccak256Hash([]byte("otra")), direct, signer); err == nil {
+		t.Error("Se esperaba un error por hash distinto")
+	}
+}
@@ -41,30 +41,18 @@ func VerifyTransactionSignature(txData map[string]interface{}) (common.Address,
 		return common.Address{}, fmt.Errorf("firma inválida: debe tener 65 bytes, tiene %d", len(signatureBytes))
 	}
 
-	// Ajustar V (último byte) para recuperación de clave pública
-	// crypto.Sign() retorna V en rango 0-1, crypto.SigToPub espera 27-28
-	v := signatureBytes[64]
-	// Normalizar V: crypto.Sign() retorna 0-1, pero SigToPub necesita 27-28
-	if v < 27 {
-		// Si V está en 0-1, ajustarlo a 27-28
-		v = v + 27
-	}
-
-	// Recuperar clave pública desde la firma usando Ecrecover (más directo)
-	// Ecrecover retorna la clave pública sin necesidad de ajustar V manualmente
-	pubKeyBytes, err := crypto.Ecrecover(hashBytes, signatureBytes)
+	recoveredAddr, err := recoverAddress(hashBytes, signatureBytes)
 	if err != nil {
-		return common.Address{}, fmt.Errorf("error recuperando clave pública: %w", err)
-	}
-	
-	// Convertir bytes de clave pública a *ecdsa.PublicKey
-	pubKey, err := crypto.UnmarshalPubkey(pubKeyBytes)
-	if err != nil {
-		return common.Address{}, fmt.Errorf("error parseando clave pública: %w", err)
+		return common.Address{}, err
 	}
 
-	// Derivar dirección desde la clave pública
-	recoveredAddr := crypto.PubkeyToAddress(*pubKey)
+	// Las hardware wallets no firman hashes arbitrarios: firman el digest EIP-712 del hash
+	if recoveredAddr != fromAddr {
+		typedDigest := TypedTransactionDigest(common.BytesToHash(hashBytes))
+		if typedAddr, err := recoverAddress(typedDigest.Bytes(), signatureBytes); err == nil && typedAddr == fromAddr {
+			recoveredAddr = typedAddr
+		}
+	}
 
 	// Verificar que la dirección recuperada coincida con tx.From
 	if recoveredAddr != fromAddr {
@@ -80,31 +68,11 @@ func VerifyTransactionSignatureFromBytes(txHash []byte, signature []byte, fromAd
 		return fmt.Errorf("firma inválida: debe tener 65 bytes, tiene %d", len(signature))
 	}
 
-	// Ajustar V (último byte) para recuperación de clave pública
-	// crypto.Sign() retorna V en rango 0-1, crypto.SigToPub espera 27-28
-	v := signature[64]
-	// Normalizar V: crypto.Sign() retorna 0-1, pero SigToPub necesita 27-28
-	if v < 27 {
-		// Si V está en 0-1, ajustarlo a 27-28
-		v = v + 27
-	}
-
-	// Recuperar clave pública desde la firma usando Ecrecover (más directo)
-	// Ecrecover retorna la clave pública sin necesidad de ajustar V manualmente
-	pubKeyBytes, err := crypto.Ecrecover(txHash, signature)
+	recoveredAddr, err := recoverAddress(txHash, signature)
 	if err != nil {
-		return fmt.Errorf("error recuperando clave pública: %w", err)
-	}
-	
-	// Convertir bytes de clave pública a *ecdsa.PublicKey
-	pubKey, err := crypto.UnmarshalPubkey(pubKeyBytes)
-	if err != nil {
-		return fmt.Errorf("error parseando clave pública: %w", err)
+		return err
 	}
 
-	// Derivar dirección
-	recoveredAddr := crypto.PubkeyToAddress(*pubKey)
-
 	// Verificar coincidencia
 	if recoveredAddr != fromAddr {
 		return fmt.Errorf("firma inválida: dirección recuperada %s no coincide con remitente %s", recoveredAddr.Hex(), fromAddr.Hex())
@@ -113,24 +81,56 @@ func VerifyTransactionSignatureFromBytes(txHash []byte, signature []byte, fromAd
 	return nil
 }
 
-// SignTransaction firma una transacción con una clave privada
-func SignTransaction(txData map[string]interface{}, privateKey *ecdsa.PrivateKey) ([]byte, error) {
-	// Crear hash de la transacción (excluyendo signature)
-	txCopy := make(map[string]interface{})
-	for k, v := range txData {
-		if k != "signature" {
-			txCopy[k] = v
-		}
+// VerifyHashSignature verifica que signature sea la firma de signer sobre el hash de una transacción,
+// directa o sobre su digest EIP-712 (hardware wallets). La usan las firmas parciales de las multisig
+func VerifyHashSignature(hash common.Hash, signature []byte, signer common.Address) error {
+	if len(signature) != 65 {
+		return fmt.Errorf("firma inválida: debe tener 65 bytes, tiene %d", len(signature))
+	}
+	recovered, err := recoverAddress(hash.Bytes(), signature)
+	if err != nil {
+		return err
+	}
+	if recovered == signer {
+		return nil
+	}
+	if typed, err := recoverAddress(TypedTransactionDigest(hash).Bytes(), signature); err == nil && typed == signer {
+		return nil
+	}
+	return fmt.Errorf("firma inválida: dirección recuperada %s no coincide con firmante %s", recovered.Hex(), signer.Hex())
+}
+
+// recoverAddress recupera la dirección que firmó un hash
+// Acepta V en 0-1 (crypto.Sign) o en 27-28 (hardware wallets y firmas de Ethereum)
+func recoverAddress(hash []byte, signature []byte) (common.Address, error) {
+	sig := signature
+	if v := signature[64]; v == 27 || v == 28 {
+		sig = make([]byte, len(signature))
+		copy(sig, signature)
+		sig[64] = v - 27
 	}
 
-	// Serializar transacción para hashing
-	txBytes, err := json.Marshal(txCopy)
+	pubKeyBytes, err := crypto.Ecrecover(hash, sig)
 	if err != nil {
-		return nil, fmt.Errorf("error serializando transacción: %w", err)
+		return common.Address{}, fmt.Errorf("error recuperando clave pública: %w", err)
 	}
 
-	// Hashear con Keccak256 (hash Ethereum)
-	hash := crypto.Keccak256Hash(txBytes)
+	// Convertir bytes de clave pública a *ecdsa.PublicKey
+	pubKey, err := crypto.UnmarshalPubkey(pubKeyBytes)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error parseando clave pública: %w", err)
+	}
+
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// SignTransaction firma una transacción con una clave privada
+func SignTransaction(txData map[string]interface{}, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	// Crear hash de la transacción (excluyendo hash y signature)
+	hash, err := CalculateTransactionHash(txData)
+	if err != nil {
+		return nil, err
+	}
 
 	// Firmar hash
 	signature, err := crypto.Sign(hash.Bytes(), privateKey)
@@ -141,12 +141,13 @@ func SignTransaction(txData map[string]interface{}, privateKey *ecdsa.PrivateKey
 	return signature, nil
 }
 
-// CalculateTransactionHash calcula el hash de una transacción para firma
+// CalculateTransactionHash calcula el hash de una transacción para firma: keccak256 del JSON de sus campos
+// (claves en orden alfabético) sin hash ni signature. El campo hash no se incluye porque es el resultado
 func CalculateTransactionHash(txData map[string]interface{}) (common.Hash, error) {
-	// Crear copia sin signature
+	// Crear copia sin hash ni signature
 	txCopy := make(map[string]interface{})
 	for k, v := range txData {
-		if k != "signature" {
+		if k != "hash" && k != "signature" {
 			txCopy[k] = v
 		}
 	}
@@ -0,0 +1,90 @@
+package crypto
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AccessTuple es una entrada de access list tal como se hashea (mismo JSON que execution.AccessTuple)
+type AccessTuple struct {
+	Address     string   `json:"address"`
+	StorageKeys []string `json:"storageKeys"`
+}
+
+// TxFields son los campos de una transacción que cubre el hash firmado
+// Los valores se hashean tal como se envían a /api/v1/submit-tx: las direcciones y los valores decimales
+// no se normalizan, así que deben firmarse exactamente como se van a enviar
+type TxFields struct {
+	From       string
+	To         string
+	Value      string
+	Data       []byte
+	GasLimit   uint64
+	GasPrice   string
+	Nonce      uint64
+	AccessList []AccessTuple // Solo forma parte del hash si no está vacía
+	FeePayer   string        // Cuenta que paga el gas (patrocinada); solo forma parte del hash si no está vacía
+}
+
+// Map retorna el mapa que se hashea (sin hash ni signature), con los mismos tipos que el nodo
+func (f *TxFields) Map() map[string]interface{} {
+	// El nodo decodifica data vacía como nil: []byte{} se hashearía como "" en lugar de null
+	data := f.Data
+	if len(data) == 0 {
+		data = nil
+	}
+	txMap := map[string]interface{}{
+		"from":     f.From,
+		"to":       f.To,
+		"value":    f.Value,
+		"data":     data,
+		"gasLimit": f.GasLimit,
+		"gasPrice": f.GasPrice,
+		"nonce":    f.Nonce,
+	}
+	if len(f.AccessList) > 0 {
+		txMap["accessList"] = f.AccessList
+	}
+	if f.FeePayer != "" {
+		txMap["feePayer"] = f.FeePayer
+	}
+	return txMap
+}
+
+// Hash calcula el hash de la transacción (el valor del campo hash y lo que se firma)
+func (f *TxFields) Hash() (common.Hash, error) {
+	return CalculateTransactionHash(f.Map())
+}
+
+// VerifyTxFields verifica una transacción como el nodo en CheckTx: que hash sea el hash de los campos y que
+// la firma corresponda a From. Retorna la dirección recuperada
+func VerifyTxFields(fields *TxFields, hash string, signature []byte) (common.Address, error) {
+	txMap := fields.Map()
+	txMap["hash"] = hash
+	txMap["signature"] = signature
+
+	recovered, err := VerifyTransactionSignature(txMap)
+	if err != nil {
+		return common.Address{}, err
+	}
+	expected, err := CalculateTransactionHash(txMap)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if hash != expected.Hex() {
+		return common.Address{}, fmt.Errorf("hash de transacción inválido: esperado %s, tiene %s", expected.Hex(), hash)
+	}
+	return recovered, nil
+}
+
+// feePayerPrefix separa la firma del fee payer de la del remitente sobre el mismo hash
+var feePayerPrefix = []byte("oxy-fee-payer")
+
+// FeePayerDigest retorna lo que firma el fee payer de una transacción patrocinada:
+// keccak256("oxy-fee-payer" || hash). El hash incluye la dirección del fee payer, así que el remitente
+// también acepta quién paga el gas
+func FeePayerDigest(hash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(feePayerPrefix, hash.Bytes())
+}
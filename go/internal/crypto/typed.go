@@ -0,0 +1,43 @@
+package crypto
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Las hardware wallets (Ledger) no firman hashes arbitrarios, pero sí mensajes EIP-712 por hash (dominio y
+// mensaje). El hash de una transacción se firma como el mensaje OxyTransaction(bytes32 hash) del dominio
+// {name: "Oxy Blockchain", version: "1"}; el nodo acepta tanto la firma directa del hash como esta
+var (
+	typedDomainSeparator = crypto.Keccak256Hash(
+		crypto.Keccak256([]byte("EIP712Domain(string name,string version)")),
+		crypto.Keccak256([]byte("Oxy Blockchain")),
+		crypto.Keccak256([]byte("1")),
+	)
+	typedTransactionTypeHash = crypto.Keccak256([]byte("OxyTransaction(bytes32 hash)"))
+)
+
+// TypedTransactionData retorna los 66 bytes que se firman como EIP-712 para el hash de una transacción:
+// 0x19 0x01, el separador de dominio y el hash del mensaje (formato de accounts.MimetypeTypedData)
+func TypedTransactionData(hash common.Hash) []byte {
+	data := make([]byte, 0, 66)
+	data = append(data, 0x19, 0x01)
+	data = append(data, typedDomainSeparator.Bytes()...)
+	return append(data, TypedTransactionMessageHash(hash).Bytes()...)
+}
+
+// TypedTransactionMessageHash retorna el hash del mensaje EIP-712 de una transacción (el que muestra la
+// hardware wallet al firmar)
+func TypedTransactionMessageHash(hash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(typedTransactionTypeHash, hash.Bytes())
+}
+
+// TypedTransactionDomainSeparator retorna el separador de dominio EIP-712 de las transacciones
+func TypedTransactionDomainSeparator() common.Hash {
+	return typedDomainSeparator
+}
+
+// TypedTransactionDigest retorna el digest EIP-712 que firma una hardware wallet para el hash de una transacción
+func TypedTransactionDigest(hash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(TypedTransactionData(hash))
+}
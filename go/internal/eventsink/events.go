@@ -0,0 +1,142 @@
+package eventsink
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/wire"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Formatos de serialización de los eventos
+const (
+	FormatJSON     = "json"     // Objetos JSON (bloques con el mismo formato que /api/v1/blocks)
+	FormatProtobuf = "protobuf" // Mensajes de types.proto: Block, Transaction y Log
+)
+
+// Tópicos (sufijo del prefijo configurado: <prefijo>.blocks, ...)
+const (
+	TopicBlocks       = "blocks"
+	TopicTransactions = "transactions"
+	TopicLogs         = "logs"
+)
+
+// Message es un mensaje a publicar
+type Message struct {
+	Topic string
+	Key   string // Identifica el evento: altura, hash de transacción o hash:índice del log
+	Value []byte
+}
+
+// TransactionEvent es el evento JSON de una transacción confirmada, con el resultado de su ejecución
+type TransactionEvent struct {
+	Height           uint64 `json:"height"`
+	BlockHash        string `json:"blockHash"`
+	TransactionIndex int    `json:"transactionIndex"`
+	Hash             string `json:"hash"`
+	From             string `json:"from"`
+	To               string `json:"to,omitempty"`
+	Value            string `json:"value"`
+	Data             string `json:"data"`
+	GasLimit         uint64 `json:"gasLimit"`
+	GasPrice         string `json:"gasPrice"`
+	Nonce            uint64 `json:"nonce"`
+	FeePayer         string `json:"feePayer,omitempty"`
+	Status           string `json:"status"` // success o failed
+	GasUsed          uint64 `json:"gasUsed"`
+	Error            string `json:"error,omitempty"`
+}
+
+// LogEvent es el evento JSON de un log emitido por un contrato
+type LogEvent struct {
+	Height           uint64   `json:"height"`
+	BlockHash        string   `json:"blockHash"`
+	TransactionHash  string   `json:"transactionHash"`
+	TransactionIndex int      `json:"transactionIndex"`
+	LogIndex         int      `json:"logIndex"` // Posición dentro de la transacción
+	Address          string   `json:"address"`
+	Topics           []string `json:"topics"`
+	Data             string   `json:"data"`
+}
+
+// blockMessages retorna los mensajes de un bloque en orden: el bloque, sus transacciones y sus logs
+func blockMessages(block *consensus.Block, format, prefix string) ([]Message, error) {
+	topic := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+	encode := func(event interface{}, proto interface{ Marshal() []byte }) ([]byte, error) {
+		if format == FormatProtobuf {
+			return proto.Marshal(), nil
+		}
+		return json.Marshal(event)
+	}
+
+	value, err := encode(block, block.ToWire())
+	if err != nil {
+		return nil, fmt.Errorf("error serializando bloque %d: %w", block.Header.Height, err)
+	}
+	messages := []Message{{Topic: topic(TopicBlocks), Key: strconv.FormatUint(block.Header.Height, 10), Value: value}}
+
+	receipts := make(map[string]*consensus.TransactionReceipt, len(block.Receipts))
+	for _, receipt := range block.Receipts {
+		if receipt != nil {
+			receipts[receipt.TransactionHash] = receipt
+		}
+	}
+	for i, tx := range block.Transactions {
+		if tx == nil {
+			continue
+		}
+		event := &TransactionEvent{
+			Height:           block.Header.Height,
+			BlockHash:        block.Header.Hash,
+			TransactionIndex: i,
+			Hash:             tx.Hash,
+			From:             tx.From,
+			To:               tx.To,
+			Value:            tx.Value,
+			Data:             hexutil.Encode(tx.Data),
+			GasLimit:         tx.GasLimit,
+			GasPrice:         tx.GasPrice,
+			Nonce:            tx.Nonce,
+		}
+		if tx.FeePayer != nil {
+			event.FeePayer = tx.FeePayer.Address
+		}
+		receipt := receipts[tx.Hash]
+		if receipt != nil {
+			event.Status, event.GasUsed, event.Error = receipt.Status, receipt.GasUsed, receipt.Error
+		}
+		if value, err = encode(event, tx.ToWire()); err != nil {
+			return nil, fmt.Errorf("error serializando transacción %s: %w", tx.Hash, err)
+		}
+		messages = append(messages, Message{Topic: topic(TopicTransactions), Key: tx.Hash, Value: value})
+
+		if receipt == nil {
+			continue
+		}
+		for j, log := range receipt.Logs {
+			event := &LogEvent{
+				Height:           block.Header.Height,
+				BlockHash:        block.Header.Hash,
+				TransactionHash:  tx.Hash,
+				TransactionIndex: i,
+				LogIndex:         j,
+				Address:          log.Address,
+				Topics:           log.Topics,
+				Data:             hexutil.Encode(log.Data),
+			}
+			wireLog := &wire.Log{Address: log.Address, Topics: log.Topics, Data: log.Data, BlockNumber: block.Header.Height, TxHash: tx.Hash}
+			if value, err = encode(event, wireLog); err != nil {
+				return nil, fmt.Errorf("error serializando log %d de %s: %w", j, tx.Hash, err)
+			}
+			messages = append(messages, Message{Topic: topic(TopicLogs), Key: tx.Hash + ":" + strconv.Itoa(j), Value: value})
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,323 @@
+package eventsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/Q-YZX0/oxy-blockchain/internal/wire"
+)
+
+// testBlock retorna un bloque con una transacción fallida y otra con un log
+func testBlock(height uint64) *consensus.Block {
+	return &consensus.Block{
+		Header: consensus.BlockHeader{Height: height, Hash: fmt.Sprintf("0x%02x", height), Timestamp: time.Unix(1700000000, 0).UTC()},
+		Transactions: []*consensus.Transaction{
+			{Hash: fmt.Sprintf("0x%da1", height), From: "0x01", To: "0x02", Value: "5", GasPrice: "1"},
+			{Hash: fmt.Sprintf("0x%da2", height), From: "0x01", Data: []byte{0x60}},
+		},
+		Receipts: []*consensus.TransactionReceipt{
+			{TransactionHash: fmt.Sprintf("0x%da1", height), Status: "failed", GasUsed: 21000, Error: "revert"},
+			{TransactionHash: fmt.Sprintf("0x%da2", height), Status: "success", GasUsed: 50000, Logs: []consensus.Log{
+				{Address: "0x04", Topics: []string{"0xt0"}, Data: []byte{0x01}},
+			}},
+		},
+	}
+}
+
+func TestBlockMessages(t *testing.T) {
+	messages, err := blockMessages(testBlock(7), FormatJSON, "oxy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var topics []string
+	for _, msg := range messages {
+		topics = append(topics, msg.Topic+"/"+msg.Key)
+	}
+	if strings.Join(topics, ",") != "oxy.blocks/7,oxy.transactions/0x7a1,oxy.transactions/0x7a2,oxy.logs/0x7a2:0" {
+		t.Fatalf("mensajes incorrectos: %v", topics)
+	}
+	var tx TransactionEvent
+	if err := json.Unmarshal(messages[1].Value, &tx); err != nil || tx.Status != "failed" || tx.Error != "revert" || tx.TransactionIndex != 0 {
+		t.Errorf("evento de transacción incorrecto: %s (%v)", messages[1].Value, err)
+	}
+	var log LogEvent
+	if err := json.Unmarshal(messages[3].Value, &log); err != nil || log.TransactionIndex != 1 || log.Data != "0x01" || log.Height != 7 {
+		t.Errorf("evento de log incorrecto: %s (%v)", messages[3].Value, err)
+	}
+
+	messages, err = blockMessages(testBlock(7), FormatProtobuf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block wire.Block
+	if err := block.Unmarshal(messages[0].Value); err != nil || block.Header.Height != 7 || len(block.Receipts) != 2 || messages[0].Topic != TopicBlocks {
+		t.Errorf("bloque protobuf incorrecto: %+v (%v)", block.Header, err)
+	}
+	var wireLog wire.Log
+	if err := wireLog.Unmarshal(messages[3].Value); err != nil || wireLog.TxHash != "0x7a2" || wireLog.Address != "0x04" {
+		t.Errorf("log protobuf incorrecto: %+v (%v)", wireLog, err)
+	}
+}
+
+// fakeNATS es un servidor NATS mínimo que registra los subjects publicados
+// Con jetstream responde cada HPUB con un ack en su subject de respuesta
+type fakeNATS struct {
+	listener  net.Listener
+	jetstream bool
+
+	mu       sync.Mutex
+	subjects []string
+	msgIDs   []string
+}
+
+func newFakeNATS(t *testing.T, jetstream bool) *fakeNATS {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeNATS{listener: listener, jetstream: jetstream}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeNATS) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	io.WriteString(conn, "INFO {\"server_id\":\"test\",\"headers\":true}\r\n")
+	seq := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			io.WriteString(conn, "PONG\r\n")
+		case "PUB", "HPUB":
+			total, _ := strconv.Atoi(fields[len(fields)-1])
+			body := make([]byte, total+2)
+			if _, err := io.ReadFull(reader, body); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.subjects = append(s.subjects, fields[1])
+			if fields[0] == "HPUB" {
+				headerSize, _ := strconv.Atoi(fields[3])
+				for _, header := range strings.Split(string(body[:headerSize]), "\r\n") {
+					if id, ok := strings.CutPrefix(header, "Nats-Msg-Id: "); ok {
+						s.msgIDs = append(s.msgIDs, id)
+					}
+				}
+			}
+			s.mu.Unlock()
+			if s.jetstream && fields[0] == "HPUB" {
+				seq++
+				ack := fmt.Sprintf(`{"stream":"OXY","seq":%d}`, seq)
+				fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", fields[2], len(ack), ack)
+			}
+		}
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	for _, jetstream := range []bool{false, true} {
+		server := newFakeNATS(t, jetstream)
+		publisher, err := NewNATSPublisher("nats://"+server.listener.Addr().String(), jetstream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages, _ := blockMessages(testBlock(3), FormatJSON, "oxy")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := publisher.Publish(ctx, messages); err != nil {
+			t.Fatalf("jetstream=%v: %v", jetstream, err)
+		}
+		cancel()
+		publisher.Close()
+
+		server.mu.Lock()
+		if len(server.subjects) != len(messages) || server.subjects[0] != "oxy.blocks" {
+			t.Errorf("jetstream=%v: subjects publicados %v", jetstream, server.subjects)
+		}
+		if jetstream && (len(server.msgIDs) != len(messages) || server.msgIDs[1] != "oxy.transactions:0x3a1") {
+			t.Errorf("Nats-Msg-Id incorrectos: %v", server.msgIDs)
+		}
+		server.mu.Unlock()
+	}
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	var mu sync.Mutex
+	records := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != kafkaBinaryContentType {
+			http.Error(w, "unsupported media type", http.StatusUnsupportedMediaType)
+			return
+		}
+		var body struct {
+			Records []kafkaRecord `json:"records"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		topic := strings.TrimPrefix(r.URL.Path, "/topics/")
+		mu.Lock()
+		records[topic] += len(body.Records)
+		mu.Unlock()
+
+		if topic == "oxy.logs" && string(body.Records[0].Key) != "0x5a2:0" {
+			http.Error(w, "bad key", http.StatusBadRequest)
+			return
+		}
+		offsets := make([]map[string]interface{}, len(body.Records))
+		for i := range offsets {
+			offsets[i] = map[string]interface{}{"partition": 0, "offset": i, "error_code": nil, "error": nil}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"offsets": offsets})
+	}))
+	defer server.Close()
+
+	publisher, err := NewKafkaPublisher(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, _ := blockMessages(testBlock(5), FormatJSON, "oxy")
+	if err := publisher.Publish(context.Background(), messages); err != nil {
+		t.Fatal(err)
+	}
+	if records["oxy.blocks"] != 1 || records["oxy.transactions"] != 2 || records["oxy.logs"] != 1 {
+		t.Errorf("records por topic incorrectos: %v", records)
+	}
+
+	if _, err := NewKafkaPublisher("kafka://broker:9092"); err == nil {
+		t.Error("se esperaba error por URL que no es del REST Proxy")
+	}
+}
+
+// recordingPublisher registra las alturas publicadas y falla los primeros intentos
+type recordingPublisher struct {
+	mu       sync.Mutex
+	failures int
+	heights  []uint64
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, messages []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures > 0 {
+		p.failures--
+		return fmt.Errorf("broker no disponible")
+	}
+	height, _ := strconv.ParseUint(messages[0].Key, 10, 64)
+	p.heights = append(p.heights, height)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func (p *recordingPublisher) published() []uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]uint64{}, p.heights...)
+}
+
+// saveBlock guarda un bloque como el último de la cadena
+func saveBlock(t *testing.T, db *storage.BlockchainDB, height uint64) {
+	block := testBlock(height)
+	data, err := consensus.EncodeBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveCanonicalBlock(height, block.Header.Hash, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveLatestHeight(height); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitPublished espera hasta que el publisher haya publicado count bloques
+func waitPublished(t *testing.T, publisher *recordingPublisher, count int) []uint64 {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if heights := publisher.published(); len(heights) >= count {
+			return heights
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("publicados %v, esperados %d bloques", publisher.published(), count)
+	return nil
+}
+
+// TestSinkResumes prueba los reintentos tras una falla del broker y que un reinicio continúe desde la
+// última altura publicada
+func TestSinkResumes(t *testing.T) {
+	testDir := "./test_data_eventsink"
+	defer os.RemoveAll(testDir)
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for height := uint64(1); height <= 3; height++ {
+		saveBlock(t, db, height)
+	}
+
+	publisher := &recordingPublisher{failures: 2}
+	sink, err := NewSink(db, publisher, "test", FormatJSON, "oxy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.SetStartHeight(2)
+	sink.SetRetryPolicy(time.Millisecond, 5*time.Millisecond)
+	if err := sink.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if heights := waitPublished(t, publisher, 2); heights[0] != 2 || heights[1] != 3 {
+		t.Fatalf("alturas publicadas %v, esperadas [2 3]", heights)
+	}
+	sink.Stop()
+
+	// Reinicio: continúa en el bloque 4 aunque el start height sea otro
+	saveBlock(t, db, 4)
+	publisher = &recordingPublisher{}
+	sink, _ = NewSink(db, publisher, "test", FormatJSON, "oxy")
+	sink.SetStartHeight(1)
+	if err := sink.Start(); err != nil {
+		t.Fatal(err)
+	}
+	saveBlock(t, db, 5)
+	sink.Notify(nil)
+	if heights := waitPublished(t, publisher, 2); heights[0] != 4 || heights[1] != 5 {
+		t.Fatalf("alturas publicadas tras reiniciar %v, esperadas [4 5]", heights)
+	}
+	sink.Stop()
+
+	if cursor, ok, err := db.GetEventSinkCursor("test"); err != nil || !ok || cursor != 5 {
+		t.Errorf("última altura publicada %d (%v, %v), esperada 5", cursor, ok, err)
+	}
+}
@@ -0,0 +1,113 @@
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Content types de la API v2 del REST Proxy de Kafka
+const (
+	kafkaBinaryContentType = "application/vnd.kafka.binary.v2+json"
+	kafkaAcceptType        = "application/vnd.kafka.v2+json"
+)
+
+// KafkaPublisher publica en Kafka a través de un REST Proxy con la API v2 (Confluent REST Proxy, Redpanda
+// HTTP Proxy), sin cliente nativo. Cada tópico es un topic de Kafka y la clave del mensaje es la clave del
+// record, así que los eventos de una misma transacción van a la misma partición
+type KafkaPublisher struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewKafkaPublisher crea un publisher para el REST Proxy en baseURL (http[s]://[usuario:contraseña@]host:puerto)
+func NewKafkaPublisher(baseURL string) (*KafkaPublisher, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("URL del REST Proxy de Kafka inválida: %s", baseURL)
+	}
+	return &KafkaPublisher{baseURL: strings.TrimSuffix(baseURL, "/"), client: &http.Client{}}, nil
+}
+
+// kafkaRecord es un record de la API v2 (clave y valor en base64)
+type kafkaRecord struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Publish implementa Publisher: un request por topic con sus records en orden
+func (p *KafkaPublisher) Publish(ctx context.Context, messages []Message) error {
+	var topics []string
+	records := make(map[string][]kafkaRecord)
+	for _, msg := range messages {
+		if _, ok := records[msg.Topic]; !ok {
+			topics = append(topics, msg.Topic)
+		}
+		records[msg.Topic] = append(records[msg.Topic], kafkaRecord{Key: []byte(msg.Key), Value: msg.Value})
+	}
+	for _, topic := range topics {
+		if err := p.produce(ctx, topic, records[topic]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// produce envía los records de un topic y verifica que todos tengan offset
+func (p *KafkaPublisher) produce(ctx context.Context, topic string, records []kafkaRecord) error {
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaBinaryContentType)
+	req.Header.Set("Accept", kafkaAcceptType)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error publicando en Kafka (%s): %w", topic, err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("el REST Proxy de Kafka respondió %d para %s: %s", resp.StatusCode, topic, strings.TrimSpace(string(data)))
+	}
+	var result struct {
+		Offsets []struct {
+			Partition int     `json:"partition"`
+			Offset    int64   `json:"offset"`
+			ErrorCode *int    `json:"error_code"`
+			Error     *string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("respuesta del REST Proxy de Kafka inválida: %w", err)
+	}
+	if len(result.Offsets) != len(records) {
+		return fmt.Errorf("el REST Proxy de Kafka confirmó %d de %d records de %s", len(result.Offsets), len(records), topic)
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil || offset.Error != nil {
+			reason := ""
+			if offset.Error != nil {
+				reason = *offset.Error
+			}
+			return fmt.Errorf("Kafka rechazó un record de %s: %s", topic, reason)
+		}
+	}
+	return nil
+}
+
+// Close implementa Publisher
+func (p *KafkaPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,263 @@
+package eventsink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsDialTimeout limita la conexión y el handshake con el servidor NATS
+const natsDialTimeout = 10 * time.Second
+
+// NATSPublisher publica en NATS con el protocolo de texto del cliente (sin dependencias externas)
+// Cada tópico es un subject. Sin JetStream la publicación se confirma con PING/PONG (el servidor recibió
+// los mensajes, pero solo los entrega a los suscriptores conectados); con JetStream cada mensaje espera
+// el ack del stream que captura su subject y lleva Nats-Msg-Id con su clave, para que el stream descarte
+// los duplicados de un bloque republicado dentro de su ventana de deduplicación
+type NATSPublisher struct {
+	url       *url.URL
+	jetstream bool
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	inbox  string // Prefijo de los subjects de respuesta de los acks de JetStream
+}
+
+// NewNATSPublisher crea un publisher para url (nats://[usuario:contraseña@]host:puerto, tls:// para TLS;
+// un usuario sin contraseña se envía como token). Se conecta en la primera publicación
+func NewNATSPublisher(rawURL string, jetstream bool) (*NATSPublisher, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL de NATS inválida: %w", err)
+	}
+	if parsed.Scheme != "nats" && parsed.Scheme != "tls" {
+		return nil, fmt.Errorf("URL de NATS inválida: %s (nats:// o tls://)", rawURL)
+	}
+	if parsed.Port() == "" {
+		parsed.Host = net.JoinHostPort(parsed.Hostname(), "4222")
+	}
+	return &NATSPublisher{url: parsed, jetstream: jetstream}, nil
+}
+
+// Publish implementa Publisher
+func (p *NATSPublisher) Publish(ctx context.Context, messages []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.publish(ctx, messages); err != nil {
+		// La conexión queda en un estado desconocido: el próximo intento reconecta
+		p.closeConn()
+		return err
+	}
+	return nil
+}
+
+// Close implementa Publisher
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closeConn()
+}
+
+// closeConn cierra la conexión actual (si hay)
+func (p *NATSPublisher) closeConn() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.reader = nil, nil
+	return err
+}
+
+// connect abre la conexión: INFO del servidor, CONNECT y PING/PONG para confirmar la autenticación
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: natsDialTimeout}
+	var conn net.Conn
+	var err error
+	if p.url.Scheme == "tls" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: p.url.Hostname()}}).DialContext(ctx, "tcp", p.url.Host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", p.url.Host)
+	}
+	if err != nil {
+		return fmt.Errorf("error conectando a NATS %s: %w", p.url.Host, err)
+	}
+	p.conn, p.reader = conn, bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(natsDialTimeout))
+
+	line, err := p.readLine()
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		p.closeConn()
+		return fmt.Errorf("respuesta inicial de NATS inválida: %q (%v)", line, err)
+	}
+
+	options := map[string]interface{}{
+		"verbose": false, "pedantic": false, "lang": "go", "version": "1", "protocol": 1,
+		"name": "oxy-blockchain", "headers": true, "no_responders": true,
+	}
+	if user := p.url.User; user != nil {
+		if pass, ok := user.Password(); ok {
+			options["user"], options["pass"] = user.Username(), pass
+		} else {
+			options["auth_token"] = user.Username()
+		}
+	}
+	connect, _ := json.Marshal(options)
+	fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect)
+	if p.jetstream {
+		suffix := make([]byte, 8)
+		rand.Read(suffix)
+		p.inbox = "_INBOX.oxy." + hex.EncodeToString(suffix)
+		fmt.Fprintf(conn, "SUB %s.* 1\r\n", p.inbox)
+	}
+	if err := p.waitPong(); err != nil {
+		p.closeConn()
+		return fmt.Errorf("error conectando a NATS: %w", err)
+	}
+	return nil
+}
+
+// publish envía los mensajes y espera la confirmación del servidor (PONG) o de JetStream (un ack por mensaje)
+func (p *NATSPublisher) publish(ctx context.Context, messages []Message) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(DefaultPublishTimeout)
+	}
+	p.conn.SetDeadline(deadline)
+
+	writer := bufio.NewWriter(p.conn)
+	for i, msg := range messages {
+		if p.jetstream {
+			header := "NATS/1.0\r\nNats-Msg-Id: " + msg.Topic + ":" + msg.Key + "\r\n\r\n"
+			fmt.Fprintf(writer, "HPUB %s %s.%d %d %d\r\n%s", msg.Topic, p.inbox, i, len(header), len(header)+len(msg.Value), header)
+		} else {
+			fmt.Fprintf(writer, "PUB %s %d\r\n", msg.Topic, len(msg.Value))
+		}
+		writer.Write(msg.Value)
+		writer.WriteString("\r\n")
+	}
+	writer.WriteString("PING\r\n")
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("error publicando en NATS: %w", err)
+	}
+	if !p.jetstream {
+		return p.waitPong()
+	}
+
+	// Acks de JetStream: {"stream":...,"seq":...} o {"error":{...}}; sin stream para el subject el servidor
+	// responde 503 (no responders)
+	acked := make([]bool, len(messages))
+	pending := len(messages)
+	for pending > 0 {
+		subject, header, payload, err := p.readMessage()
+		if err != nil {
+			return err
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(subject, p.inbox+"."))
+		if err != nil || index < 0 || index >= len(messages) || acked[index] {
+			continue
+		}
+		if bytes.Contains(header, []byte(" 503")) {
+			return fmt.Errorf("ningún stream de JetStream captura el subject %s", messages[index].Topic)
+		}
+		var ack struct {
+			Error *struct {
+				Code        int    `json:"code"`
+				Description string `json:"description"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(payload, &ack); err != nil {
+			return fmt.Errorf("ack de JetStream inválido: %q", payload)
+		}
+		if ack.Error != nil {
+			return fmt.Errorf("JetStream rechazó %s: %s (código %d)", messages[index].Topic, ack.Error.Description, ack.Error.Code)
+		}
+		acked[index] = true
+		pending--
+	}
+	return nil
+}
+
+// waitPong lee hasta el PONG (responde los PING del servidor e ignora +OK e INFO)
+func (p *NATSPublisher) waitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		case line == "PING":
+			io.WriteString(p.conn, "PONG\r\n")
+		}
+	}
+}
+
+// readMessage lee el próximo MSG o HMSG (responde PING e ignora +OK, PONG e INFO)
+func (p *NATSPublisher) readMessage() (subject string, header, payload []byte, err error) {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return "", nil, nil, err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			io.WriteString(p.conn, "PONG\r\n")
+			continue
+		case "-ERR":
+			return "", nil, nil, fmt.Errorf("NATS: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		case "MSG", "HMSG":
+		default:
+			continue
+		}
+
+		// MSG <subject> <sid> [reply] <bytes> | HMSG <subject> <sid> [reply] <bytes de header> <bytes>
+		headerSize := 0
+		total, err := strconv.Atoi(fields[len(fields)-1])
+		if err == nil && fields[0] == "HMSG" {
+			headerSize, err = strconv.Atoi(fields[len(fields)-2])
+		}
+		if err != nil || len(fields) < 4 || headerSize > total {
+			return "", nil, nil, fmt.Errorf("mensaje de NATS inválido: %q", line)
+		}
+		body := make([]byte, total+2)
+		if _, err := io.ReadFull(p.reader, body); err != nil {
+			return "", nil, nil, err
+		}
+		return fields[1], body[:headerSize], body[headerSize:total], nil
+	}
+}
+
+// readLine lee una línea del protocolo sin el \r\n
+func (p *NATSPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("error leyendo de NATS: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,220 @@
+// Package eventsink publica los bloques confirmados, sus transacciones y sus logs en un broker (NATS o
+// Kafka) para indexadores que necesitan un feed durable en lugar de consultar el API.
+//
+// La entrega es al menos una vez: la última altura publicada se guarda en storage tras la confirmación
+// del broker, así que un reinicio del nodo o una caída del broker retoman desde el bloque siguiente, y un
+// bloque cuya publicación se interrumpió se vuelve a publicar completo. Los consumidores deduplican por la
+// clave del mensaje (altura, hash de transacción o hash:índice del log).
+package eventsink
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/logger"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// sinkLog es el logger del módulo eventsink
+var sinkLog = logger.For("eventsink")
+
+// Valores por defecto de la publicación
+const (
+	DefaultTopicPrefix    = "oxy"
+	DefaultPublishTimeout = 30 * time.Second
+	DefaultBaseBackoff    = time.Second
+	DefaultMaxBackoff     = time.Minute
+)
+
+// Publisher entrega mensajes a un broker
+type Publisher interface {
+	// Publish publica los mensajes en orden y retorna cuando el broker los aceptó
+	Publish(ctx context.Context, messages []Message) error
+	// Close cierra la conexión con el broker
+	Close() error
+}
+
+// Sink publica los eventos de cada bloque confirmado, en orden de altura
+type Sink struct {
+	name        string // Identifica el cursor en storage
+	db          *storage.BlockchainDB
+	publisher   Publisher
+	format      string
+	prefix      string
+	startHeight uint64
+	timeout     time.Duration
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+
+	cursor  uint64 // Última altura publicada
+	notify  chan struct{}
+	ctx     context.Context // Se cancela en Stop (interrumpe una publicación en curso)
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	stopped sync.Once
+}
+
+// NewSink crea un sink que publica con publisher los bloques guardados en db
+// name identifica el destino: cada uno tiene su propia última altura publicada
+func NewSink(db *storage.BlockchainDB, publisher Publisher, name, format, prefix string) (*Sink, error) {
+	if format != FormatJSON && format != FormatProtobuf {
+		return nil, fmt.Errorf("formato de eventos desconocido: %s (%s o %s)", format, FormatJSON, FormatProtobuf)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Sink{
+		name:        name,
+		db:          db,
+		publisher:   publisher,
+		format:      format,
+		prefix:      prefix,
+		timeout:     DefaultPublishTimeout,
+		baseBackoff: DefaultBaseBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		notify:      make(chan struct{}, 1),
+		ctx:         ctx,
+		cancel:      cancel,
+	}, nil
+}
+
+// SetStartHeight configura la primera altura a publicar cuando el destino nunca recibió eventos
+// (0 = desde el próximo bloque); con una altura ya publicada se continúa desde ella
+// Debe llamarse antes de Start
+func (s *Sink) SetStartHeight(height uint64) {
+	s.startHeight = height
+}
+
+// SetRetryPolicy configura el backoff entre reintentos cuando el broker falla (valores <= 0 = sin cambio)
+// Debe llamarse antes de Start
+func (s *Sink) SetRetryPolicy(baseBackoff, maxBackoff time.Duration) {
+	if baseBackoff > 0 {
+		s.baseBackoff = baseBackoff
+	}
+	if maxBackoff > 0 {
+		s.maxBackoff = maxBackoff
+	}
+}
+
+// Start retoma la publicación desde la última altura publicada y publica los bloques que se confirmen
+func (s *Sink) Start() error {
+	cursor, ok, err := s.db.GetEventSinkCursor(s.name)
+	if err != nil {
+		return fmt.Errorf("error leyendo la última altura publicada: %w", err)
+	}
+	if !ok {
+		if s.startHeight > 0 {
+			cursor = s.startHeight - 1
+		} else {
+			cursor = s.latestHeight()
+		}
+		if err := s.db.SaveEventSinkCursor(s.name, cursor); err != nil {
+			return fmt.Errorf("error guardando la última altura publicada: %w", err)
+		}
+	}
+	s.cursor = cursor
+	sinkLog.Infof("Publicando eventos en %s desde el bloque %d", s.name, cursor+1)
+
+	s.wg.Add(1)
+	go s.run()
+	s.Notify(nil)
+	return nil
+}
+
+// Notify avisa que se confirmó un bloque (handler de AddBlockCommitHandler)
+// El bloque se lee de storage, así que no bloquea el commit aunque el broker esté caído
+func (s *Sink) Notify(*consensus.Block) {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Stop detiene la publicación y cierra el publisher
+func (s *Sink) Stop() {
+	s.stopped.Do(func() {
+		s.cancel()
+		s.wg.Wait()
+		if err := s.publisher.Close(); err != nil {
+			sinkLog.Warnf("Error cerrando %s: %v", s.name, err)
+		}
+	})
+}
+
+// run publica los bloques pendientes cada vez que se confirma uno, reintentando con backoff
+func (s *Sink) run() {
+	defer s.wg.Done()
+	backoff := s.baseBackoff
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.notify:
+		case <-retry:
+		}
+		retry = nil
+
+		if err := s.publishPending(); err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			sinkLog.Warnf("Error publicando eventos en %s (reintento en %s): %v", s.name, backoff, err)
+			retry = time.After(backoff)
+			backoff = min(backoff*2, s.maxBackoff)
+			continue
+		}
+		backoff = s.baseBackoff
+	}
+}
+
+// publishPending publica los bloques guardados después de la última altura publicada
+func (s *Sink) publishPending() error {
+	latest := s.latestHeight()
+	for height := s.cursor + 1; height <= latest; height++ {
+		if s.ctx.Err() != nil {
+			return nil
+		}
+		if err := s.publishBlock(height); err != nil {
+			return err
+		}
+		if err := s.db.SaveEventSinkCursor(s.name, height); err != nil {
+			return fmt.Errorf("error guardando la última altura publicada: %w", err)
+		}
+		s.cursor = height
+	}
+	return nil
+}
+
+// latestHeight retorna la altura del último bloque guardado (0 si la cadena está vacía)
+func (s *Sink) latestHeight() uint64 {
+	height, err := s.db.GetLatestHeight()
+	if err != nil {
+		return 0
+	}
+	return height
+}
+
+// publishBlock publica los eventos del bloque de una altura
+func (s *Sink) publishBlock(height uint64) error {
+	data, err := s.db.GetBlock(height)
+	if err != nil {
+		return fmt.Errorf("bloque %d no disponible: %w", height, err)
+	}
+	block, err := consensus.DecodeBlock(data)
+	if err != nil {
+		return fmt.Errorf("error decodificando bloque %d: %w", height, err)
+	}
+	messages, err := blockMessages(block, s.format, s.prefix)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+	if err := s.publisher.Publish(ctx, messages); err != nil {
+		return fmt.Errorf("bloque %d: %w", height, err)
+	}
+	return nil
+}
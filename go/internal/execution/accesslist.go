@@ -0,0 +1,150 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+)
+
+// maxAccessListIterations limita las simulaciones de CreateAccessList: cada vuelta agrega a la lista lo que
+// la anterior tocó, y normalmente se estabiliza en dos o tres
+const maxAccessListIterations = 10
+
+var storageKeyPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+
+// AccessTuple es una entrada de access list (EIP-2930): una dirección y los slots de su storage
+type AccessTuple struct {
+	Address     string   `json:"address"`
+	StorageKeys []string `json:"storageKeys"`
+}
+
+// AccessList son las direcciones y slots que una transacción declara de antemano: se cobran al inicio
+// (2400 de gas por dirección y 1900 por slot) y los accesos durante la ejecución pagan la tarifa "caliente"
+type AccessList []AccessTuple
+
+// AccessListResult es el resultado de CreateAccessList
+type AccessListResult struct {
+	AccessList AccessList `json:"accessList"`
+	GasUsed    uint64     `json:"gasUsed"`         // Gas de la transacción usando la access list generada
+	Error      string     `json:"error,omitempty"` // Error de la EVM al simular (la lista cubre lo ejecutado hasta el error)
+}
+
+// Validate verifica el formato de la access list: direcciones de 20 bytes y slots de 32 bytes en hex
+func (l AccessList) Validate() error {
+	for i, tuple := range l {
+		if !common.IsHexAddress(tuple.Address) {
+			return fmt.Errorf("accessList[%d]: dirección inválida: %s", i, tuple.Address)
+		}
+		for j, key := range tuple.StorageKeys {
+			if !storageKeyPattern.MatchString(key) {
+				return fmt.Errorf("accessList[%d].storageKeys[%d]: slot inválido: %s", i, j, key)
+			}
+		}
+	}
+	return nil
+}
+
+// toEthereum convierte la access list al tipo de go-ethereum (nil si está vacía)
+func (l AccessList) toEthereum() types.AccessList {
+	if len(l) == 0 {
+		return nil
+	}
+	list := make(types.AccessList, len(l))
+	for i, tuple := range l {
+		keys := make([]common.Hash, len(tuple.StorageKeys))
+		for j, key := range tuple.StorageKeys {
+			keys[j] = common.HexToHash(key)
+		}
+		list[i] = types.AccessTuple{Address: common.HexToAddress(tuple.Address), StorageKeys: keys}
+	}
+	return list
+}
+
+// accessListFromEthereum convierte una access list de go-ethereum (siempre no nil, para el JSON)
+func accessListFromEthereum(list types.AccessList) AccessList {
+	result := make(AccessList, len(list))
+	for i, tuple := range list {
+		keys := make([]string, len(tuple.StorageKeys))
+		for j, key := range tuple.StorageKeys {
+			keys[j] = key.Hex()
+		}
+		result[i] = AccessTuple{Address: tuple.Address.Hex(), StorageKeys: keys}
+	}
+	return result
+}
+
+// CreateAccessList simula la transacción sobre el último estado confirmado (sin modificarlo) y retorna la
+// access list de las direcciones y slots que toca, sin el remitente, el destinatario ni los precompilados.
+// Igual que eth_createAccessList, repite la simulación con la lista obtenida hasta que deja de cambiar.
+// No verifica nonce ni firma; tx.AccessList, si viene, es el punto de partida
+func (e *EVMExecutor) CreateAccessList(tx *Transaction) (*AccessListResult, error) {
+	if !e.running {
+		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	if err := tx.AccessList.Validate(); err != nil {
+		return nil, err
+	}
+	value, ok := new(big.Int).SetString(tx.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("valor inválido: %s", tx.Value)
+	}
+	gasPrice, ok := new(big.Int).SetString(tx.GasPrice, 10)
+	if !ok {
+		return nil, fmt.Errorf("gas price inválido: %s", tx.GasPrice)
+	}
+
+	base, err := e.stateManager.CommittedState()
+	if err != nil {
+		return nil, err
+	}
+
+	// Mismo mensaje que ExecuteTransaction
+	from := common.HexToAddress(tx.From)
+	to := common.HexToAddress(tx.To)
+	blockContext, chainConfig := e.blockContext(tx.GasLimit)
+	rules := chainConfig.Rules(blockContext.BlockNumber, blockContext.Random != nil, blockContext.Time)
+	exclude := map[common.Address]struct{}{from: {}, to: {}}
+	for _, addr := range vm.ActivePrecompiles(rules) {
+		exclude[addr] = struct{}{}
+	}
+
+	prev := logger.NewAccessListTracer(tx.AccessList.toEthereum(), exclude)
+	for i := 0; i < maxAccessListIterations; i++ {
+		accessList := prev.AccessList()
+		msg := core.Message{
+			From:            from,
+			To:              &to,
+			Nonce:           base.GetNonce(from),
+			Value:           value,
+			GasLimit:        tx.GasLimit,
+			GasPrice:        gasPrice,
+			GasFeeCap:       gasPrice,
+			GasTipCap:       gasPrice,
+			Data:            tx.Data,
+			AccessList:      accessList,
+			SkipNonceChecks: true,
+		}
+
+		tracer := logger.NewAccessListTracer(accessList, exclude)
+		evm := vm.NewEVM(blockContext, base.Copy(), chainConfig, vm.Config{Tracer: tracer.Hooks()})
+		result, err := core.ApplyMessage(evm, &msg, new(core.GasPool).AddGas(tx.GasLimit))
+		if err != nil {
+			return nil, fmt.Errorf("error simulando transacción: %w", err)
+		}
+		if tracer.Equal(prev) {
+			res := &AccessListResult{AccessList: accessListFromEthereum(accessList), GasUsed: result.UsedGas}
+			if result.Err != nil {
+				res.Error = result.Err.Error()
+			}
+			return res, nil
+		}
+		prev = tracer
+	}
+	return nil, fmt.Errorf("la access list no se estabilizó en %d simulaciones", maxAccessListIterations)
+}
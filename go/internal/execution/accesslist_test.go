@@ -0,0 +1,70 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+)
+
+// TestEVMExecutor_AccessList prueba que CreateAccessList detecte las direcciones accedidas y que la lista
+// se aplique al ejecutar (la dirección se cobra al inicio y el acceso paga la tarifa caliente)
+func TestEVMExecutor_AccessList(t *testing.T) {
+	testDir := createTestDir("access_list")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+	evm.SetCurrentBlockInfo(1, 1700000000)
+
+	// PUSH20 <target> BALANCE POP STOP
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	target := common.HexToAddress("0x00000000000000000000000000000000000000a3")
+	code := append(append([]byte{0x73}, target.Bytes()...), 0x31, 0x50, 0x00)
+	evm.getStateDB().SetCode(contract, code, tracing.CodeChangeUnspecified)
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	from := "0x00000000000000000000000000000000000000b1"
+	call := &Transaction{From: from, To: contract.Hex(), Value: "0", GasPrice: "0", GasLimit: 100000}
+	result, err := evm.CreateAccessList(call)
+	if err != nil {
+		t.Fatalf("Error creando access list: %v", err)
+	}
+	if len(result.AccessList) != 1 || result.AccessList[0].Address != target.Hex() || len(result.AccessList[0].StorageKeys) != 0 {
+		t.Fatalf("Access list incorrecta: %+v", result.AccessList)
+	}
+	// 21000 + 2400 (dirección declarada) + 3 (PUSH20) + 100 (BALANCE caliente) + 2 (POP)
+	if result.GasUsed != 23505 || result.Error != "" {
+		t.Errorf("Simulación incorrecta: %+v", result)
+	}
+
+	// Sin la lista el BALANCE paga el acceso frío (2600)
+	withoutList, err := evm.ExecuteTransaction(&Transaction{From: from, To: contract.Hex(), Value: "0", GasPrice: "0", GasLimit: 100000, Nonce: 0})
+	if err != nil || withoutList.GasUsed != 23605 {
+		t.Errorf("Gas sin access list incorrecto: %+v (%v)", withoutList, err)
+	}
+	withList, err := evm.ExecuteTransaction(&Transaction{From: from, To: contract.Hex(), Value: "0", GasPrice: "0", GasLimit: 100000, Nonce: 1, AccessList: result.AccessList})
+	if err != nil || withList.GasUsed != result.GasUsed {
+		t.Errorf("Gas con access list incorrecto: %+v (%v)", withList, err)
+	}
+
+	if err := (AccessList{{Address: target.Hex(), StorageKeys: []string{"0x01"}}}).Validate(); err == nil {
+		t.Error("Un slot que no tiene 32 bytes debería rechazarse")
+	}
+}
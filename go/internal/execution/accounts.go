@@ -0,0 +1,74 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+)
+
+// markTouched registra cuentas cuyo balance cambió, para actualizar el índice de cuentas en el commit
+func (e *EVMExecutor) markTouched(addrs ...common.Address) {
+	e.touchedMu.Lock()
+	defer e.touchedMu.Unlock()
+	if e.touched == nil {
+		e.touched = make(map[common.Address]struct{})
+	}
+	for _, addr := range addrs {
+		e.touched[addr] = struct{}{}
+	}
+}
+
+// TouchAccounts marca cuentas para que el próximo TakeTouchedBalances incluya su balance
+// (ej: para crear el índice de cuentas de una cadena existente)
+func (e *EVMExecutor) TouchAccounts(addresses ...string) {
+	addrs := make([]common.Address, 0, len(addresses))
+	for _, address := range addresses {
+		if common.IsHexAddress(address) {
+			addrs = append(addrs, common.HexToAddress(address))
+		}
+	}
+	e.markTouched(addrs...)
+}
+
+// balanceHooks registra toda cuenta cuyo balance cambia durante la ejecución EVM
+// (incluye transferencias internas de contratos, no solo remitente y destinatario)
+func (e *EVMExecutor) balanceHooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnBalanceChange: func(addr common.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+			e.markTouched(addr)
+		},
+	}
+}
+
+// TakeTouchedBalances retorna el balance actual de las cuentas modificadas desde la última llamada
+// y vacía el registro. Las cuentas que ya no existen (vacías) tienen balance nil
+func (e *EVMExecutor) TakeTouchedBalances() map[string]*big.Int {
+	e.touchedMu.Lock()
+	touched := e.touched
+	e.touched = nil
+	e.touchedMu.Unlock()
+
+	balances := make(map[string]*big.Int, len(touched))
+	if len(touched) == 0 || !e.running {
+		return balances
+	}
+
+	stateDB := e.getStateDB()
+	for addr := range touched {
+		if stateDB.Empty(addr) {
+			balances[addr.Hex()] = nil
+			continue
+		}
+		balances[addr.Hex()] = stateDB.GetBalance(addr).ToBig()
+	}
+	return balances
+}
+
+// GetBalance retorna el balance actual de una cuenta (0 si el ejecutor no está corriendo)
+func (e *EVMExecutor) GetBalance(address string) *big.Int {
+	if !e.running {
+		return new(big.Int)
+	}
+	return e.getStateDB().GetBalance(common.HexToAddress(address)).ToBig()
+}
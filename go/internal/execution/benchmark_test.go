@@ -0,0 +1,115 @@
+package execution
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// benchTxsPerBlock es cada cuántas transacciones los benchmarks confirman el estado, como al final de un bloque
+const benchTxsPerBlock = 100
+
+// erc20Code es el runtime de un ERC-20 mínimo que solo implementa transfer(address,uint256):
+// balances en keccak(dirección . 0), revierte sin fondos, emite Transfer y retorna true
+var erc20Code = common.FromHex("60003560e01c63a9059cbb14601357600080fd5b33600052600060205260406000208054602435808210607c57809103825590506004356000526040600020805482019055600052600435337fddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef60206000a3600160005260206000f35b600080fd")
+
+// erc20BalanceSlot retorna el slot del balance de una cuenta en erc20Code (mapping en el slot 0)
+func erc20BalanceSlot(addr common.Address) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(addr.Bytes(), 32), make([]byte, 32))
+}
+
+// erc20TransferData codifica la llamada transfer(to, amount)
+func erc20TransferData(to common.Address, amount int64) []byte {
+	data := common.FromHex("a9059cbb")
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	return append(data, common.LeftPadBytes(big.NewInt(amount).Bytes(), 32)...)
+}
+
+// newBenchExecutor crea un ejecutor con el remitente con fondos y el contrato ERC-20 desplegado
+func newBenchExecutor(b *testing.B, sender, token common.Address) *EVMExecutor {
+	db, err := storage.NewBlockchainDB(b.TempDir())
+	if err != nil {
+		b.Fatalf("Error creando storage: %v", err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	evm := NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		b.Fatalf("Error iniciando EVM: %v", err)
+	}
+	b.Cleanup(func() { evm.Stop() })
+	evm.SetCurrentBlockInfo(1, 1700000000)
+
+	stateDB := evm.getStateDB()
+	stateDB.SetBalance(sender, uint256.MustFromDecimal("1000000000000000000000000"), tracing.BalanceChangeUnspecified)
+	stateDB.SetCode(token, erc20Code, tracing.CodeChangeUnspecified)
+	stateDB.SetState(token, erc20BalanceSlot(sender), common.BigToHash(big.NewInt(1_000_000_000)))
+	if err := evm.SaveState(); err != nil {
+		b.Fatalf("Error guardando estado: %v", err)
+	}
+	return evm
+}
+
+// runBenchTxs ejecuta b.N transacciones del remitente (tx arma la i-ésima) y reporta tx/s
+func runBenchTxs(b *testing.B, evm *EVMExecutor, tx func(i int) *Transaction) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := evm.ExecuteTransaction(tx(i))
+		if err != nil || !result.Success {
+			b.Fatalf("Transacción %d falló: %+v (%v)", i, result, err)
+		}
+		if (i+1)%benchTxsPerBlock == 0 {
+			if err := evm.SaveState(); err != nil {
+				b.Fatalf("Error guardando estado: %v", err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "tx/s")
+}
+
+// benchRecipient retorna uno de 1000 destinatarios, para que los benchmarks toquen cuentas y slots distintos
+func benchRecipient(i int) common.Address {
+	return common.BigToAddress(big.NewInt(int64(0x10000 + i%1000)))
+}
+
+// BenchmarkEVMExecutor_Transfer mide transferencias nativas por segundo
+func BenchmarkEVMExecutor_Transfer(b *testing.B) {
+	sender := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	evm := newBenchExecutor(b, sender, common.HexToAddress("0x00000000000000000000000000000000000000c1"))
+
+	runBenchTxs(b, evm, func(i int) *Transaction {
+		return &Transaction{
+			From:     sender.Hex(),
+			To:       benchRecipient(i).Hex(),
+			Value:    "1",
+			GasLimit: 21000,
+			GasPrice: "1",
+			Nonce:    uint64(i),
+		}
+	})
+}
+
+// BenchmarkEVMExecutor_ERC20Transfer mide llamadas transfer de un ERC-20 por segundo
+func BenchmarkEVMExecutor_ERC20Transfer(b *testing.B) {
+	sender := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	token := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	evm := newBenchExecutor(b, sender, token)
+
+	runBenchTxs(b, evm, func(i int) *Transaction {
+		return &Transaction{
+			From:     sender.Hex(),
+			To:       token.Hex(),
+			Value:    "0",
+			Data:     erc20TransferData(benchRecipient(i), 1),
+			GasLimit: 100000,
+			GasPrice: "1",
+			Nonce:    uint64(i),
+		}
+	})
+}
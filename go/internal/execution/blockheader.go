@@ -0,0 +1,73 @@
+package execution
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// blockRecordFormat es la versión de la codificación canónica de bloques que se sabe leer
+// (ver consensus.EncodeBlock: un byte de versión seguido del RLP del bloque)
+const blockRecordFormat = 1
+
+// StoredBlockHeader son los campos del header de un bloque guardado que usa el estado EVM
+type StoredBlockHeader struct {
+	Hash      string
+	StateRoot string // Vacío en bloques anteriores a ese campo
+	Timestamp int64  // Segundos Unix
+}
+
+// canonicalHeader es el comienzo del header en la codificación canónica de bloques: el orden de los
+// campos es el de consensus.headerRecord y el resto se ignora
+type canonicalHeader struct {
+	Height         uint64
+	Hash           string
+	ParentHash     string
+	Timestamp      uint64
+	TimestampNanos uint64
+	Validator      string
+	ChainID        string
+	StateRoot      string
+	Rest           []rlp.RawValue `rlp:"tail"`
+}
+
+// DecodeBlockHeader lee el header de un bloque guardado, en formato canónico o JSON (registros anteriores)
+// El paquete execution no depende de consensus, así que lee solo los campos que necesita
+func DecodeBlockHeader(data []byte) (*StoredBlockHeader, error) {
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		var block struct {
+			Header struct {
+				Hash      string    `json:"hash"`
+				StateRoot string    `json:"stateRoot"`
+				Timestamp time.Time `json:"timestamp"`
+			} `json:"header"`
+		}
+		if err := json.Unmarshal(data, &block); err != nil {
+			return nil, err
+		}
+		return &StoredBlockHeader{
+			Hash:      block.Header.Hash,
+			StateRoot: block.Header.StateRoot,
+			Timestamp: block.Header.Timestamp.Unix(),
+		}, nil
+	}
+
+	if len(data) == 0 || data[0] != blockRecordFormat {
+		return nil, fmt.Errorf("formato de bloque no soportado")
+	}
+	var block struct {
+		Header canonicalHeader
+		Rest   []rlp.RawValue `rlp:"tail"`
+	}
+	if err := rlp.DecodeBytes(data[1:], &block); err != nil {
+		return nil, fmt.Errorf("error decodificando bloque: %w", err)
+	}
+	return &StoredBlockHeader{
+		Hash:      block.Header.Hash,
+		StateRoot: block.Header.StateRoot,
+		Timestamp: int64(block.Header.Timestamp),
+	}, nil
+}
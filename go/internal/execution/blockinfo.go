@@ -0,0 +1,33 @@
+package execution
+
+import "fmt"
+
+// BlockInfo es la altura y la hora de consenso (Unix, segundos) del bloque que ve la EVM
+// Se reemplaza entero en cada bloque, así una llamada concurrente nunca mezcla la altura de un bloque con la
+// hora de otro
+type BlockInfo struct {
+	Height    uint64
+	Timestamp int64
+}
+
+// BeginBlock fija la altura y la hora de consenso del bloque que se va a ejecutar
+// Rechaza una hora anterior a la del último bloque ejecutado: block.timestamp nunca retrocede para los
+// contratos. Una altura menor o igual a la anterior (reejecución o revert en desarrollo) no se compara
+func (e *EVMExecutor) BeginBlock(height uint64, timestamp int64) error {
+	if height == 0 {
+		return fmt.Errorf("altura de bloque inválida: 0")
+	}
+	if prev := e.blockInfo.Load(); prev != nil && height > prev.Height && timestamp < prev.Timestamp {
+		return fmt.Errorf("hora del bloque %d (%d) anterior a la del bloque %d (%d)", height, timestamp, prev.Height, prev.Timestamp)
+	}
+	e.blockInfo.Store(&BlockInfo{Height: height, Timestamp: timestamp})
+	return nil
+}
+
+// CurrentBlockInfo retorna la altura y hora del bloque actual (ceros antes del primer bloque)
+func (e *EVMExecutor) CurrentBlockInfo() BlockInfo {
+	if info := e.blockInfo.Load(); info != nil {
+		return *info
+	}
+	return BlockInfo{}
+}
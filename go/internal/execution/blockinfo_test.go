@@ -0,0 +1,36 @@
+package execution
+
+import "testing"
+
+// TestBeginBlockMonotonic verifica que la hora de consenso no retroceda entre bloques
+func TestBeginBlockMonotonic(t *testing.T) {
+	evm := &EVMExecutor{}
+
+	if got := evm.CurrentBlockInfo(); got != (BlockInfo{}) {
+		t.Errorf("Antes del primer bloque se esperaban ceros: %+v", got)
+	}
+	if err := evm.BeginBlock(0, 1700000000); err == nil {
+		t.Error("La altura 0 debería rechazarse")
+	}
+	if err := evm.BeginBlock(10, 1700000000); err != nil {
+		t.Fatal(err)
+	}
+	// Misma hora en el bloque siguiente: válido (block.timestamp tiene resolución de segundos)
+	if err := evm.BeginBlock(11, 1700000000); err != nil {
+		t.Fatal(err)
+	}
+	if err := evm.BeginBlock(12, 1699999999); err == nil {
+		t.Error("Una hora anterior a la del bloque previo debería rechazarse")
+	}
+	if got := evm.CurrentBlockInfo(); got.Height != 11 || got.Timestamp != 1700000000 {
+		t.Errorf("Un bloque rechazado no debería cambiar la información actual: %+v", got)
+	}
+
+	// Reejecución de una altura anterior: no se compara la hora
+	if err := evm.BeginBlock(5, 1600000000); err != nil {
+		t.Fatal(err)
+	}
+	if got := evm.CurrentBlockInfo(); got.Height != 5 || got.Timestamp != 1600000000 {
+		t.Errorf("Información de bloque incorrecta: %+v", got)
+	}
+}
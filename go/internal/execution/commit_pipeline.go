@@ -0,0 +1,176 @@
+package execution
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// DefaultStateCommitQueue es cuántos commits de estado pueden esperar su escritura en disco.
+// Con la cola llena, SaveState espera a que se escriba el más antiguo (backpressure)
+const DefaultStateCommitQueue = 4
+
+// durableRootKey es la clave, en la base de datos EVM, del último root cuyo trie está completo en disco
+// y durableHeightKey la de la altura del bloque que lo produjo
+var (
+	durableRootKey   = []byte("oxy-durable-state-root")
+	durableHeightKey = []byte("oxy-durable-state-height")
+)
+
+// flushRequest es un root confirmado en memoria pendiente de escribirse en disco
+type flushRequest struct {
+	root     common.Hash
+	height   uint64              // Bloque cuyo estado es el root
+	database state.Database      // triedb que tiene los nodos del root
+	disk     ethdb.KeyValueStore // Base de datos donde se registra el root escrito
+}
+
+// commitPipeline escribe en disco, en una goroutine y en el orden en que se confirmaron, los tries que
+// SaveState confirma en memoria. Un root se registra como recuperable solo después de que todos sus nodos
+// están en disco, así que tras una caída el estado se retoma desde un trie completo (ver recoverableRoot)
+type commitPipeline struct {
+	queue   chan flushRequest // nil = escritura síncrona dentro de SaveState
+	pending sync.WaitGroup    // Roots encolados que todavía no se escribieron
+	stopped chan struct{}
+	write   func(flushRequest) error
+
+	errMu sync.Mutex
+	err   error // Primer error de escritura: lo retornan los siguientes submit y wait
+}
+
+// newCommitPipeline crea la cola de escritura (depth 0 = síncrona) e inicia su goroutine
+func newCommitPipeline(depth int, write func(flushRequest) error) *commitPipeline {
+	p := &commitPipeline{write: write, stopped: make(chan struct{})}
+	if depth <= 0 {
+		close(p.stopped)
+		return p
+	}
+	p.queue = make(chan flushRequest, depth)
+	go p.run()
+	return p
+}
+
+// run escribe los roots encolados de a uno, en orden
+func (p *commitPipeline) run() {
+	defer close(p.stopped)
+	for req := range p.queue {
+		// Después de un error no se escriben roots posteriores: en disco queda el último root completo
+		if p.failed() == nil {
+			if err := p.write(req); err != nil {
+				p.fail(err)
+			}
+		}
+		p.pending.Done()
+	}
+}
+
+// submit encola un root para escribirlo en disco; espera si la cola está llena
+func (p *commitPipeline) submit(req flushRequest) error {
+	if err := p.failed(); err != nil {
+		return err
+	}
+	if p.queue == nil {
+		if err := p.write(req); err != nil {
+			p.fail(err)
+			return err
+		}
+		return nil
+	}
+	p.pending.Add(1)
+	p.queue <- req
+	return nil
+}
+
+// wait espera a que se escriban todos los roots encolados
+func (p *commitPipeline) wait() error {
+	p.pending.Wait()
+	return p.failed()
+}
+
+// close escribe lo pendiente y detiene la goroutine
+func (p *commitPipeline) close() error {
+	if p.queue != nil {
+		close(p.queue)
+	}
+	<-p.stopped
+	return p.failed()
+}
+
+func (p *commitPipeline) fail(err error) {
+	p.errMu.Lock()
+	defer p.errMu.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *commitPipeline) failed() error {
+	p.errMu.Lock()
+	defer p.errMu.Unlock()
+	return p.err
+}
+
+// writeRoot escribe en disco los nodos del trie de un root (y las preimágenes) y lo registra como recuperable
+// junto con su altura
+func writeRoot(req flushRequest) error {
+	if req.root == (common.Hash{}) || req.root == types.EmptyRootHash {
+		return nil
+	}
+	if err := req.database.TrieDB().Commit(req.root, false); err != nil {
+		return fmt.Errorf("error escribiendo trie %s en disco: %w", req.root.Hex(), err)
+	}
+	batch := req.disk.NewBatch()
+	batch.Put(durableRootKey, req.root.Bytes())
+	batch.Put(durableHeightKey, binary.BigEndian.AppendUint64(nil, req.height))
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("error registrando root %s: %w", req.root.Hex(), err)
+	}
+	return nil
+}
+
+// SetStateCommitQueue configura cuántos commits de estado pueden esperar su escritura en disco en segundo
+// plano (0 = escribir dentro de SaveState). Debe llamarse antes de Start
+func (e *EVMExecutor) SetStateCommitQueue(depth int) {
+	e.stateManager.commitQueue = depth
+}
+
+// FlushState espera las escrituras en segundo plano pendientes y escribe en disco el trie del último root
+// confirmado, de modo que el estado quede completo en disco sin cerrar el executor (ej: al detener la cadena)
+func (e *EVMExecutor) FlushState() error {
+	return e.stateManager.flushTrie()
+}
+
+// RecoveredHeight retorna la altura del estado retomado al iniciar y true si el último root confirmado no
+// llegó a disco (el nodo se detuvo antes de escribirlo): los bloques guardados después de esa altura no
+// tienen estado y deben reejecutarse
+func (e *EVMExecutor) RecoveredHeight() (uint64, bool) {
+	return e.stateManager.recoveredHeight, e.stateManager.recovered
+}
+
+// recoverableRoot retorna root si su trie está en disco; si no (el nodo se detuvo antes de que la escritura
+// en segundo plano terminara), el último root registrado como completo y, si se registró, su altura y true
+func (sm *StateManager) recoverableRoot(root common.Hash) (common.Hash, uint64, bool) {
+	if root == (common.Hash{}) || root == types.EmptyRootHash || rawdb.HasLegacyTrieNode(sm.pebbleDB, root) {
+		return root, 0, false
+	}
+	durable, err := sm.pebbleDB.Get(durableRootKey)
+	if err != nil || len(durable) != common.HashLength {
+		return root, 0, false
+	}
+	data, err := sm.pebbleDB.Get(durableHeightKey)
+	if err != nil || len(data) != 8 {
+		// Root registrado sin su altura (versión anterior): se retoma el estado pero no se conoce su bloque
+		log.Printf("Advertencia: el trie del root %s no llegó a disco; se retoma el estado desde el root %s", root.Hex(), common.BytesToHash(durable).Hex())
+		return common.BytesToHash(durable), 0, false
+	}
+	height := binary.BigEndian.Uint64(data)
+	log.Printf("Advertencia: el trie del root %s no llegó a disco; se retoma el estado desde el root %s (altura %d)", root.Hex(), common.BytesToHash(durable).Hex(), height)
+	return common.BytesToHash(durable), height, true
+}
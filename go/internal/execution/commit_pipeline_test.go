@@ -0,0 +1,194 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TestCommitPipeline prueba que los roots se escriban en orden, que submit espere con la cola llena y que
+// un error de escritura se retorne en los siguientes commits
+func TestCommitPipeline(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var written []common.Hash
+	p := newCommitPipeline(1, func(req flushRequest) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		written = append(written, req.root)
+		if req.root == common.BigToHash(common.Big3) {
+			return fmt.Errorf("disco lleno")
+		}
+		return nil
+	})
+
+	// El primero queda escribiéndose y el segundo ocupa la cola: el tercero espera
+	if err := p.submit(flushRequest{root: common.BigToHash(common.Big1)}); err != nil {
+		t.Fatalf("Error encolando: %v", err)
+	}
+	if err := p.submit(flushRequest{root: common.BigToHash(common.Big2)}); err != nil {
+		t.Fatalf("Error encolando: %v", err)
+	}
+	submitted := make(chan error, 1)
+	go func() { submitted <- p.submit(flushRequest{root: common.BigToHash(common.Big3)}) }()
+	select {
+	case <-submitted:
+		t.Fatal("submit debería esperar con la cola llena")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-submitted; err != nil {
+		t.Fatalf("Error encolando: %v", err)
+	}
+	if err := p.wait(); err == nil {
+		t.Error("wait debería retornar el error de escritura")
+	}
+	if err := p.submit(flushRequest{root: common.BigToHash(common.Big32)}); err == nil {
+		t.Error("submit después de un error debería fallar")
+	}
+	if err := p.close(); err == nil {
+		t.Error("close debería retornar el error de escritura")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []common.Hash{common.BigToHash(common.Big1), common.BigToHash(common.Big2), common.BigToHash(common.Big3)}
+	if fmt.Sprint(written) != fmt.Sprint(expected) {
+		t.Errorf("Orden de escritura %v, esperado %v", written, expected)
+	}
+}
+
+// TestStateManager_CommitPipelineRecovery prueba que el estado escrito en segundo plano persista al
+// reiniciar y que, si el último root no llegó a disco, se retome desde el último root completo
+func TestStateManager_CommitPipelineRecovery(t *testing.T) {
+	testDir := createTestDir("commit_pipeline_recovery")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+	address := "0x00000000000000000000000000000000000000b1"
+
+	open := func() (*EVMExecutor, *storage.BlockchainDB) {
+		db, err := storage.NewBlockchainDB(testDir)
+		if err != nil {
+			t.Fatalf("Error creando storage: %v", err)
+		}
+		evm := NewEVMExecutor(db)
+		if err := evm.Start(); err != nil {
+			db.Close()
+			t.Fatalf("Error iniciando EVM: %v", err)
+		}
+		return evm, db
+	}
+
+	evm, db := open()
+	for i := 0; i < 10; i++ {
+		if err := evm.FundAccount(address, "10"); err != nil {
+			t.Fatalf("Error fondeando cuenta: %v", err)
+		}
+		if err := evm.SaveState(); err != nil {
+			t.Fatalf("Error guardando estado: %v", err)
+		}
+	}
+	root := evm.GetStateManager().CommittedRoot()
+	evm.Stop()
+
+	// Un root confirmado cuyo trie no llegó a disco (el nodo se detuvo antes de escribirlo)
+	stateData, _ := json.Marshal(map[string]interface{}{"root": common.BigToHash(common.Big1).Hex()})
+	if err := db.SaveState(stateData); err != nil {
+		t.Fatalf("Error guardando root: %v", err)
+	}
+	db.Close()
+
+	evm, db = open()
+	defer db.Close()
+	defer evm.Stop()
+	if current := evm.GetStateManager().CommittedRoot(); current != root {
+		t.Errorf("Root retomado %s, esperado %s", current.Hex(), root.Hex())
+	}
+	if state, err := evm.GetCommittedState(address); err != nil || state.Balance != "100" {
+		t.Errorf("Balance esperado 100 tras reiniciar: %+v (%v)", state, err)
+	}
+}
+
+// TestStateManager_CrashBetweenCommitAndFlush prueba que una caída entre el commit de un bloque y la escritura
+// de su trie retome el estado del último bloque escrito en disco e informe su altura
+func TestStateManager_CrashBetweenCommitAndFlush(t *testing.T) {
+	testDir := createTestDir("commit_pipeline_crash")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+	address := "0x00000000000000000000000000000000000000b2"
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	evm := NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		db.Close()
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+
+	commitBlock := func(height uint64) {
+		if err := evm.BeginBlock(height, int64(height)); err != nil {
+			t.Fatalf("Error iniciando bloque %d: %v", height, err)
+		}
+		if err := evm.FundAccount(address, "10"); err != nil {
+			t.Fatalf("Error fondeando cuenta: %v", err)
+		}
+		if err := evm.SaveState(); err != nil {
+			t.Fatalf("Error guardando estado: %v", err)
+		}
+	}
+	commitBlock(1)
+	commitBlock(2)
+	if err := evm.FlushState(); err != nil {
+		t.Fatalf("Error escribiendo estado: %v", err)
+	}
+	durable := evm.GetStateManager().CommittedRoot()
+
+	// El bloque 3 se confirma en memoria pero la escritura en disco nunca ocurre
+	sm := evm.GetStateManager()
+	if err := sm.pipeline.close(); err != nil {
+		t.Fatalf("Error cerrando la cola de escritura: %v", err)
+	}
+	sm.pipeline = newCommitPipeline(0, func(flushRequest) error { return nil })
+	commitBlock(3)
+
+	// Caída: se cierra la base de datos sin escribir el trie en memoria (el error de iteradores abiertos
+	// por el snapshot tree es el mismo que registra Close)
+	sm.pebbleDB.Close()
+	db.Close()
+
+	db, err = storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error reabriendo storage: %v", err)
+	}
+	defer db.Close()
+	evm = NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error reiniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+
+	if height, recovered := evm.RecoveredHeight(); !recovered || height != 2 {
+		t.Errorf("Se esperaba el estado retomado en la altura 2: altura %d, retomado %v", height, recovered)
+	}
+	if current := evm.GetStateManager().CommittedRoot(); current != durable {
+		t.Errorf("Root retomado %s, esperado %s", current.Hex(), durable.Hex())
+	}
+	if state, err := evm.GetCommittedState(address); err != nil || state.Balance != "20" {
+		t.Errorf("Balance esperado 20 tras la caída: %+v (%v)", state, err)
+	}
+}
@@ -0,0 +1,429 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+)
+
+// Variables de entorno de la suite de conformidad
+const (
+	stateTestsDirEnv = "OXY_STATE_TESTS_DIR" // Directorio GeneralStateTests de un checkout de ethereum/tests
+	stateTestsEnv    = "OXY_STATE_TESTS"     // Lista de fixtures separada por comas (reemplaza stateTestSubset)
+)
+
+// stateTestSubset son los GeneralStateTests que se ejecutan por defecto (rutas relativas a OXY_STATE_TESTS_DIR).
+// Quedan fuera los que dependen del entorno del bloque, que en Oxy•gen es distinto al de mainnet:
+// COINBASE, BASEFEE, GASLIMIT, PREVRANDAO, CHAINID y BLOCKHASH, las creaciones de contratos
+// (ExecuteTransaction siempre llama a una dirección) y las transacciones blob o con autorizaciones
+var stateTestSubset = []string{
+	"stExample/add11.json",
+	"stArgsZeroOneBalance/addNonConst.json",
+	"stShift/shl01.json",
+	"stShift/sar00.json",
+	"stSStoreTest/sstore_0to1.json",
+	"stSStoreTest/sstore_Xto0.json",
+	"stRefundTest/refund_getEtherBack.json",
+	"stCallCodes/callcall_00.json",
+	"stLogTests/log0_nonEmptyMem.json",
+	"stSelfBalance/selfBalance.json",
+	"Shanghai/stEIP3855-push0/push0.json",
+	"Cancun/stEIP1153-transientStorage/01_tloadBeginningTxn.json",
+	"Cancun/stEIP5656-MCOPY/MCOPY.json",
+}
+
+// stateTest es un GeneralStateTest en el formato de ethereum/tests (solo los campos que usamos)
+type stateTest struct {
+	Env struct {
+		Coinbase  common.UnprefixedAddress `json:"currentCoinbase"`
+		Number    math.HexOrDecimal64      `json:"currentNumber"`
+		Timestamp math.HexOrDecimal64      `json:"currentTimestamp"`
+		BaseFee   *math.HexOrDecimal256    `json:"currentBaseFee"`
+	} `json:"env"`
+	Pre  types.GenesisAlloc         `json:"pre"`
+	Tx   stateTestTx                `json:"transaction"`
+	Post map[string][]stateTestPost `json:"post"`
+}
+
+type stateTestTx struct {
+	GasPrice             *math.HexOrDecimal256 `json:"gasPrice"`
+	MaxFeePerGas         *math.HexOrDecimal256 `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *math.HexOrDecimal256 `json:"maxPriorityFeePerGas"`
+	Nonce                math.HexOrDecimal64   `json:"nonce"`
+	To                   string                `json:"to"`
+	Data                 []string              `json:"data"`
+	AccessLists          []*types.AccessList   `json:"accessLists"`
+	GasLimit             []math.HexOrDecimal64 `json:"gasLimit"`
+	Value                []string              `json:"value"`
+	SecretKey            hexutil.Bytes         `json:"secretKey"`
+	BlobVersionedHashes  []common.Hash         `json:"blobVersionedHashes"`
+	AuthorizationList    json.RawMessage       `json:"authorizationList"`
+}
+
+// stateTestPost es el resultado esperado para una combinación de data/gas/value en un fork
+type stateTestPost struct {
+	Root            common.UnprefixedHash `json:"hash"`
+	Logs            common.UnprefixedHash `json:"logs"`
+	ExpectException string                `json:"expectException"`
+	Indexes         struct {
+		Data  int `json:"data"`
+		Gas   int `json:"gas"`
+		Value int `json:"value"`
+	} `json:"indexes"`
+}
+
+// stateTestSchedule retorna el calendario de hardforks equivalente a un fork de los fixtures.
+// La cadena siempre corre con reglas post-Merge, así que los forks anteriores a Paris no se ejecutan
+func stateTestSchedule(fork string) (HardforkSchedule, bool) {
+	genesis := uint64(0)
+	switch fork {
+	case "Paris", "Merge":
+		return HardforkSchedule{}, true
+	case "Shanghai":
+		return HardforkSchedule{ShanghaiHeight: &genesis}, true
+	case "Cancun":
+		return HardforkSchedule{ShanghaiHeight: &genesis, CancunHeight: &genesis}, true
+	}
+	return HardforkSchedule{}, false
+}
+
+// TestEVMExecutor_GeneralStateTests ejecuta un subconjunto de los GeneralStateTests de ethereum/tests contra
+// el EVMExecutor y compara el state root y el hash de los logs. Requiere OXY_STATE_TESTS_DIR
+func TestEVMExecutor_GeneralStateTests(t *testing.T) {
+	dir := os.Getenv(stateTestsDirEnv)
+	if dir == "" {
+		t.Skipf("%s no configurado (checkout de ethereum/tests/GeneralStateTests)", stateTestsDirEnv)
+	}
+	files := stateTestSubset
+	if list := os.Getenv(stateTestsEnv); list != "" {
+		files = strings.Split(list, ",")
+	}
+
+	for _, file := range files {
+		file = strings.TrimSpace(file)
+		t.Run(file, func(t *testing.T) {
+			// Los fixtures cambian de carpeta entre versiones de ethereum/tests
+			data, err := os.ReadFile(filepath.Join(dir, file))
+			if os.IsNotExist(err) {
+				t.Skipf("Fixture no encontrado en %s", dir)
+			}
+			if err != nil {
+				t.Fatalf("Error leyendo fixture: %v", err)
+			}
+			var tests map[string]*stateTest
+			if err := json.Unmarshal(data, &tests); err != nil {
+				t.Fatalf("Error decodificando fixture: %v", err)
+			}
+
+			for name, test := range tests {
+				forks := make([]string, 0, len(test.Post))
+				for fork := range test.Post {
+					forks = append(forks, fork)
+				}
+				sort.Strings(forks)
+				for _, fork := range forks {
+					for i, post := range test.Post[fork] {
+						t.Run(fmt.Sprintf("%s/%s/%d", name, fork, i), func(t *testing.T) {
+							runStateTest(t, test, fork, post)
+						})
+					}
+				}
+			}
+		})
+	}
+}
+
+// runStateTest ejecuta una combinación de un GeneralStateTest en un ejecutor nuevo.
+// El fee de Oxy•gen va al fee collector en vez de al coinbase del bloque: antes de calcular el root se
+// deja el fee collector como estaba y se acredita al coinbase del fixture la propina que le daría Ethereum
+func runStateTest(t *testing.T, test *stateTest, fork string, post stateTestPost) {
+	schedule, ok := stateTestSchedule(fork)
+	if !ok {
+		t.Skipf("Fork %s no soportado", fork)
+	}
+	if test.Tx.To == "" {
+		t.Skip("Creación de contratos no soportada por ExecuteTransaction")
+	}
+	if len(test.Tx.BlobVersionedHashes) > 0 || len(test.Tx.AuthorizationList) > 0 {
+		t.Skip("Transacciones blob y con autorizaciones no soportadas")
+	}
+
+	db, err := storage.NewBlockchainDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := NewEVMExecutor(db)
+	if err := evm.SetHardforkSchedule(schedule); err != nil {
+		t.Fatalf("Error configurando hardforks: %v", err)
+	}
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+	evm.SetCurrentBlockInfo(uint64(test.Env.Number), int64(test.Env.Timestamp))
+
+	loadAlloc(evm.getStateDB(), test.Pre)
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	tx, tip, err := test.transaction(post)
+	if err != nil {
+		t.Fatalf("Transacción inválida en el fixture: %v", err)
+	}
+	result, err := evm.ExecuteTransaction(tx)
+	if err != nil {
+		t.Fatalf("Error ejecutando transacción: %v", err)
+	}
+
+	// Una transacción rechazada (nonce, fondos, gas intrínseco) no modifica el estado
+	rejected := result.Error != ""
+	if rejected != (post.ExpectException != "") {
+		t.Errorf("Rechazo inesperado: esperado %q, obtenido %q", post.ExpectException, result.Error)
+	}
+
+	stateDB := evm.getStateDB()
+	if !rejected {
+		var collectorBalance *uint256.Int
+		if account, ok := test.Pre[feeCollector]; ok {
+			collectorBalance = uint256.MustFromBig(account.Balance)
+		} else {
+			collectorBalance = new(uint256.Int)
+		}
+		stateDB.SetBalance(feeCollector, collectorBalance, tracing.BalanceChangeUnspecified)
+		tip.Mul(tip, uint256.NewInt(result.GasUsed))
+		stateDB.AddBalance(common.Address(test.Env.Coinbase), tip, tracing.BalanceChangeUnspecified)
+	}
+
+	if root := stateDB.IntermediateRoot(true); root != common.Hash(post.Root) {
+		t.Errorf("State root incorrecto: esperado %s, obtenido %s (gas usado %d)", common.Hash(post.Root).Hex(), root.Hex(), result.GasUsed)
+	}
+	if logs := logsHash(result.Logs); logs != common.Hash(post.Logs) {
+		t.Errorf("Hash de logs incorrecto: esperado %s, obtenido %s", common.Hash(post.Logs).Hex(), logs.Hex())
+	}
+}
+
+// transaction arma la transacción de la combinación post y la propina por unidad de gas que recibiría el coinbase.
+// El executor no tiene base fee, así que el gas price es el precio efectivo que pagaría el remitente en Ethereum
+func (test *stateTest) transaction(post stateTestPost) (*Transaction, *uint256.Int, error) {
+	idx := post.Indexes
+	if idx.Data >= len(test.Tx.Data) || idx.Gas >= len(test.Tx.GasLimit) || idx.Value >= len(test.Tx.Value) {
+		return nil, nil, fmt.Errorf("índices fuera de rango: %+v", idx)
+	}
+	key, err := crypto.ToECDSA(test.Tx.SecretKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("clave privada inválida: %w", err)
+	}
+	value, ok := math.ParseBig256(test.Tx.Value[idx.Value])
+	if !ok {
+		return nil, nil, fmt.Errorf("valor inválido: %s", test.Tx.Value[idx.Value])
+	}
+
+	baseFee := new(big.Int)
+	if test.Env.BaseFee != nil {
+		baseFee = (*big.Int)(test.Env.BaseFee)
+	}
+	var gasPrice *big.Int
+	switch {
+	case test.Tx.GasPrice != nil:
+		gasPrice = (*big.Int)(test.Tx.GasPrice)
+	case test.Tx.MaxFeePerGas != nil && test.Tx.MaxPriorityFeePerGas != nil:
+		gasPrice = new(big.Int).Add(baseFee, (*big.Int)(test.Tx.MaxPriorityFeePerGas))
+		if maxFee := (*big.Int)(test.Tx.MaxFeePerGas); gasPrice.Cmp(maxFee) > 0 {
+			gasPrice = new(big.Int).Set(maxFee)
+		}
+	default:
+		return nil, nil, fmt.Errorf("transacción sin gasPrice ni maxFeePerGas")
+	}
+	tip := new(big.Int).Sub(gasPrice, baseFee)
+	if tip.Sign() < 0 {
+		tip.SetInt64(0)
+	}
+
+	tx := &Transaction{
+		From:     crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		To:       common.HexToAddress(test.Tx.To).Hex(),
+		Value:    value.String(),
+		Data:     common.FromHex(test.Tx.Data[idx.Data]),
+		GasLimit: uint64(test.Tx.GasLimit[idx.Gas]),
+		GasPrice: gasPrice.String(),
+		Nonce:    uint64(test.Tx.Nonce),
+	}
+	if idx.Data < len(test.Tx.AccessLists) && test.Tx.AccessLists[idx.Data] != nil {
+		tx.AccessList = accessListFromEthereum(*test.Tx.AccessLists[idx.Data])
+	}
+	return tx, uint256.MustFromBig(tip), nil
+}
+
+// loadAlloc escribe un estado inicial (balances, nonces, código y storage) en el StateDB
+func loadAlloc(stateDB *state.StateDB, alloc types.GenesisAlloc) {
+	for addr, account := range alloc {
+		if account.Balance != nil {
+			stateDB.SetBalance(addr, uint256.MustFromBig(account.Balance), tracing.BalanceChangeUnspecified)
+		}
+		stateDB.SetNonce(addr, account.Nonce, tracing.NonceChangeUnspecified)
+		stateDB.SetCode(addr, account.Code, tracing.CodeChangeUnspecified)
+		for key, value := range account.Storage {
+			stateDB.SetState(addr, key, value)
+		}
+	}
+}
+
+// logsHash calcula el hash RLP de los logs como lo hacen los GeneralStateTests
+func logsHash(logs []Log) common.Hash {
+	ethLogs := make([]*types.Log, len(logs))
+	for i, log := range logs {
+		topics := make([]common.Hash, len(log.Topics))
+		for j, topic := range log.Topics {
+			topics[j] = common.HexToHash(topic)
+		}
+		ethLogs[i] = &types.Log{Address: common.HexToAddress(log.Address), Topics: topics, Data: log.Data}
+	}
+	encoded, _ := rlp.EncodeToBytes(ethLogs)
+	return crypto.Keccak256Hash(encoded)
+}
+
+// TestEVMExecutor_GasParity compara el EVMExecutor con go-ethereum aplicando el mismo mensaje sobre un
+// estado de referencia: gas usado, retorno y el estado resultante de las cuentas de la transacción
+// deben coincidir (el fee que Ethereum deja en el coinbase aquí va al fee collector)
+func TestEVMExecutor_GasParity(t *testing.T) {
+	sender := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	token := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	storer := common.HexToAddress("0x00000000000000000000000000000000000000c2")
+	reverter := common.HexToAddress("0x00000000000000000000000000000000000000c3")
+
+	senderSlot := erc20BalanceSlot(sender)
+	pre := types.GenesisAlloc{
+		sender:    types.Account{Balance: new(big.Int).Mul(big.NewInt(1e18), big.NewInt(1000))},
+		recipient: types.Account{Balance: big.NewInt(1)},
+		token:     types.Account{Code: erc20Code, Storage: map[common.Hash]common.Hash{senderSlot: common.BigToHash(big.NewInt(1000))}},
+		// SLOAD(0) + 1 -> SSTORE(0); el slot 1 se borra (refund)
+		storer:   types.Account{Code: common.FromHex("6000546001016000556000600155"), Storage: map[common.Hash]common.Hash{{31: 1}: {31: 7}}},
+		reverter: types.Account{Code: common.FromHex("600160005560006000fd")}, // SSTORE(0, 1) y REVERT
+	}
+
+	cases := []struct {
+		name  string
+		tx    Transaction
+		slots map[common.Address][]common.Hash
+	}{
+		{name: "transfer", tx: Transaction{To: recipient.Hex(), Value: "1000", GasLimit: 21000}},
+		{
+			name:  "erc20",
+			tx:    Transaction{To: token.Hex(), Value: "0", Data: erc20TransferData(recipient, 10), GasLimit: 100000},
+			slots: map[common.Address][]common.Hash{token: {senderSlot, erc20BalanceSlot(recipient)}},
+		},
+		{
+			name:  "sstore_refund",
+			tx:    Transaction{To: storer.Hex(), Value: "0", GasLimit: 100000},
+			slots: map[common.Address][]common.Hash{storer: {{}, {31: 1}}},
+		},
+		{
+			name:  "revert",
+			tx:    Transaction{To: reverter.Hex(), Value: "0", GasLimit: 100000},
+			slots: map[common.Address][]common.Hash{reverter: {{}}},
+		},
+		{name: "out_of_gas", tx: Transaction{To: storer.Hex(), Value: "0", GasLimit: 25000}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := storage.NewBlockchainDB(t.TempDir())
+			if err != nil {
+				t.Fatalf("Error creando storage: %v", err)
+			}
+			defer db.Close()
+
+			evm := NewEVMExecutor(db)
+			if err := evm.Start(); err != nil {
+				t.Fatalf("Error iniciando EVM: %v", err)
+			}
+			defer evm.Stop()
+			evm.SetCurrentBlockInfo(1, 1700000000)
+			loadAlloc(evm.getStateDB(), pre)
+			if err := evm.SaveState(); err != nil {
+				t.Fatalf("Error guardando estado: %v", err)
+			}
+
+			// Referencia: el mismo mensaje aplicado directamente con go-ethereum
+			reference, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+			if err != nil {
+				t.Fatalf("Error creando estado de referencia: %v", err)
+			}
+			loadAlloc(reference, pre)
+			reference.Finalise(true)
+
+			tx := tc.tx
+			tx.From = sender.Hex()
+			tx.GasPrice = "7"
+			to := common.HexToAddress(tx.To)
+			value, _ := new(big.Int).SetString(tx.Value, 10)
+			blockContext, chainConfig := evm.blockContext(tx.GasLimit)
+			msg := &core.Message{
+				From:      sender,
+				To:        &to,
+				Value:     value,
+				GasLimit:  tx.GasLimit,
+				GasPrice:  big.NewInt(7),
+				GasFeeCap: big.NewInt(7),
+				GasTipCap: big.NewInt(7),
+				Data:      tx.Data,
+			}
+			expected, err := core.ApplyMessage(vm.NewEVM(blockContext, reference, chainConfig, vm.Config{}), msg, new(core.GasPool).AddGas(tx.GasLimit))
+			if err != nil {
+				t.Fatalf("Error en la ejecución de referencia: %v", err)
+			}
+
+			result, err := evm.ExecuteTransaction(&tx)
+			if err != nil {
+				t.Fatalf("Error ejecutando transacción: %v", err)
+			}
+			if result.GasUsed != expected.UsedGas || result.Success == expected.Failed() || string(result.ReturnData) != string(expected.ReturnData) {
+				t.Fatalf("Resultado distinto a go-ethereum: gas %d/%d, éxito %v/%v", result.GasUsed, expected.UsedGas, result.Success, !expected.Failed())
+			}
+
+			stateDB := evm.getStateDB()
+			for _, addr := range []common.Address{sender, recipient} {
+				if got, want := stateDB.GetBalance(addr), reference.GetBalance(addr); !got.Eq(want) {
+					t.Errorf("Balance de %s: esperado %s, obtenido %s", addr.Hex(), want, got)
+				}
+				if got, want := stateDB.GetNonce(addr), reference.GetNonce(addr); got != want {
+					t.Errorf("Nonce de %s: esperado %d, obtenido %d", addr.Hex(), want, got)
+				}
+			}
+			for addr, slots := range tc.slots {
+				for _, slot := range slots {
+					if got, want := stateDB.GetState(addr, slot), reference.GetState(addr, slot); got != want {
+						t.Errorf("Slot %s de %s: esperado %s, obtenido %s", slot.Hex(), addr.Hex(), want.Hex(), got.Hex())
+					}
+				}
+			}
+			// El fee completo (sin base fee) va al fee collector
+			fee := uint256.NewInt(result.GasUsed * 7)
+			if got := stateDB.GetBalance(feeCollector); !got.Eq(fee) {
+				t.Errorf("Fee collector: esperado %s, obtenido %s", fee, got)
+			}
+		})
+	}
+}
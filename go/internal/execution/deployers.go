@@ -0,0 +1,157 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// DeployerRegistryAddress es la cuenta de sistema cuyo storage guarda la política de despliegue de contratos:
+// si el despliegue está restringido y el rol de cada cuenta. Al estar en el estado, la política es la misma
+// en todos los nodos y forma parte del root de estado. Su nonce es 1 para que no se elimine como cuenta vacía
+const DeployerRegistryAddress = "0x0000000000000000000000000000000000000106"
+
+// Roles de una cuenta en el registro de deployers (se combinan)
+const (
+	DeployerRoleDeployer uint64 = 1 << iota // Puede crear contratos con el despliegue restringido
+	DeployerRoleAdmin                       // Puede cambiar la restricción y los roles (transacciones al registro)
+)
+
+var (
+	deployerRegistry = common.HexToAddress(DeployerRegistryAddress)
+	restrictedSlot   = common.BigToHash(big.NewInt(0)) // 1 = solo los deployers aprobados crean contratos
+	deployerRoleBase = common.LeftPadBytes(big.NewInt(1).Bytes(), 32)
+)
+
+// DeployerStatus es la política de despliegue aplicada a una cuenta
+type DeployerStatus struct {
+	Address    string `json:"address"`
+	Restricted bool   `json:"restricted"` // El despliegue está restringido a los deployers aprobados
+	Deployer   bool   `json:"deployer"`
+	Admin      bool   `json:"admin"`
+	CanDeploy  bool   `json:"canDeploy"`
+}
+
+// deployerRoleSlot es el slot del rol de una cuenta (como un mapping de Solidity en el slot 1)
+func deployerRoleSlot(addr common.Address) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(addr.Bytes(), 32), deployerRoleBase)
+}
+
+// deploymentRestricted indica si el despliegue de contratos está restringido en un StateDB
+func deploymentRestricted(stateDB *state.StateDB) bool {
+	return stateDB.GetState(deployerRegistry, restrictedSlot) != (common.Hash{})
+}
+
+// deployerRoles retorna los roles de una cuenta en un StateDB
+func deployerRoles(stateDB *state.StateDB, addr common.Address) uint64 {
+	return new(uint256.Int).SetBytes32(stateDB.GetState(deployerRegistry, deployerRoleSlot(addr)).Bytes()).Uint64()
+}
+
+// canDeploy indica si una cuenta puede crear contratos en un StateDB
+func canDeploy(stateDB *state.StateDB, addr common.Address) bool {
+	return !deploymentRestricted(stateDB) || deployerRoles(stateDB, addr)&DeployerRoleDeployer != 0
+}
+
+// setSystemSlot escribe un slot del storage de una cuenta de sistema (nonce 1 para que no se elimine como vacía)
+func setSystemSlot(stateDB *state.StateDB, account common.Address, slot common.Hash, value uint64) {
+	if stateDB.GetNonce(account) == 0 {
+		stateDB.SetNonce(account, 1, tracing.NonceChangeUnspecified)
+	}
+	stateDB.SetState(account, slot, common.Hash(uint256.NewInt(value).Bytes32()))
+}
+
+// SetDeploymentRestricted activa o desactiva la restricción de despliegue en el bloque en ejecución
+func (e *EVMExecutor) SetDeploymentRestricted(restricted bool) error {
+	if !e.running {
+		return fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	value := uint64(0)
+	if restricted {
+		value = 1
+	}
+	setSystemSlot(e.getStateDB(), deployerRegistry, restrictedSlot, value)
+	return nil
+}
+
+// SetDeployerRoles reemplaza los roles de una cuenta en el bloque en ejecución (0 = sin roles)
+func (e *EVMExecutor) SetDeployerRoles(address string, roles uint64) error {
+	if !e.running {
+		return fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("dirección inválida: %s", address)
+	}
+	setSystemSlot(e.getStateDB(), deployerRegistry, deployerRoleSlot(common.HexToAddress(address)), roles)
+	return nil
+}
+
+// DeployerRoles retorna los roles de una cuenta en el bloque en ejecución
+func (e *EVMExecutor) DeployerRoles(address string) uint64 {
+	stateDB := e.getStateDB()
+	if stateDB == nil {
+		return 0
+	}
+	return deployerRoles(stateDB, common.HexToAddress(address))
+}
+
+// GetCommittedDeployerStatus retorna la política de despliegue de una cuenta en el último estado confirmado
+// (para CheckTx y consultas del API)
+func (e *EVMExecutor) GetCommittedDeployerStatus(address string) (*DeployerStatus, error) {
+	if !e.running {
+		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	stateDB, err := e.stateManager.CommittedState()
+	if err != nil {
+		return nil, err
+	}
+	addr := common.HexToAddress(address)
+	roles := deployerRoles(stateDB, addr)
+	return &DeployerStatus{
+		Address:    addr.Hex(),
+		Restricted: deploymentRestricted(stateDB),
+		Deployer:   roles&DeployerRoleDeployer != 0,
+		Admin:      roles&DeployerRoleAdmin != 0,
+		CanDeploy:  canDeploy(stateDB, addr),
+	}, nil
+}
+
+// denyCreates hace fallar los CREATE/CREATE2 de una transacción. La EVM consulta CanTransfer al crear un
+// contrato (también sin valor) justo después de avisar el inicio de la llamada a los hooks: el CREATE falla
+// como si quien crea no tuviera fondos y el gas enviado vuelve a quien lo ejecutó
+func denyCreates(blockContext *vm.BlockContext, hooks *tracing.Hooks) *tracing.Hooks {
+	gated := &tracing.Hooks{}
+	if hooks != nil {
+		*gated = *hooks
+	}
+
+	creating := false
+	onEnter, onExit := gated.OnEnter, gated.OnExit
+	gated.OnEnter = func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+		creating = vm.OpCode(typ) == vm.CREATE || vm.OpCode(typ) == vm.CREATE2
+		if onEnter != nil {
+			onEnter(depth, typ, from, to, input, gas, value)
+		}
+	}
+	gated.OnExit = func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+		creating = false
+		if onExit != nil {
+			onExit(depth, output, gasUsed, err, reverted)
+		}
+	}
+
+	canTransfer := blockContext.CanTransfer
+	blockContext.CanTransfer = func(db vm.StateDB, addr common.Address, amount *uint256.Int) bool {
+		if creating {
+			creating = false
+			return false
+		}
+		return canTransfer(db, addr, amount)
+	}
+	return gated
+}
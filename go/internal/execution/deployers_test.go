@@ -0,0 +1,86 @@
+package execution
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// TestDeployerRegistrySlots verifica la restricción y los roles guardados en el registro
+func TestDeployerRegistrySlots(t *testing.T) {
+	stateDB, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployer := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	if deploymentRestricted(stateDB) || !canDeploy(stateDB, other) {
+		t.Fatal("Sin política cualquier cuenta debería poder desplegar")
+	}
+	setSystemSlot(stateDB, deployerRegistry, restrictedSlot, 1)
+	setSystemSlot(stateDB, deployerRegistry, deployerRoleSlot(deployer), DeployerRoleDeployer|DeployerRoleAdmin)
+	if !canDeploy(stateDB, deployer) || canDeploy(stateDB, other) {
+		t.Error("Con la restricción solo el deployer aprobado debería poder desplegar")
+	}
+	if roles := deployerRoles(stateDB, deployer); roles != DeployerRoleDeployer|DeployerRoleAdmin {
+		t.Errorf("Roles incorrectos: %d", roles)
+	}
+
+	// El registro no se elimina como cuenta vacía
+	stateDB.Finalise(true)
+	if !deploymentRestricted(stateDB) {
+		t.Error("La restricción debería sobrevivir a Finalise")
+	}
+}
+
+// TestDenyCreates verifica que un CREATE desde un contrato falle con los hooks de despliegue restringido
+func TestDenyCreates(t *testing.T) {
+	// CREATE(0, 0, 0) y guarda la dirección creada en el slot 0
+	factoryCode := []byte{
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CREATE),
+		byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+	}
+	factory := common.HexToAddress("0xfac7")
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	run := func(deny bool) common.Hash {
+		stateDB, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		if err != nil {
+			t.Fatal(err)
+		}
+		stateDB.SetCode(factory, factoryCode, tracing.CodeChangeUnspecified)
+		blockContext := vm.BlockContext{
+			CanTransfer: core.CanTransfer,
+			Transfer:    core.Transfer,
+			BlockNumber: big.NewInt(1),
+			Difficulty:  big.NewInt(0),
+			BaseFee:     big.NewInt(0),
+			GasLimit:    1000000,
+		}
+		config := vm.Config{}
+		if deny {
+			config.Tracer = denyCreates(&blockContext, nil)
+		}
+		evm := vm.NewEVM(blockContext, stateDB, params.TestChainConfig, config)
+		if _, _, err := evm.Call(sender, factory, nil, 1000000, new(uint256.Int)); err != nil {
+			t.Fatalf("Error ejecutando la factory: %v", err)
+		}
+		return stateDB.GetState(factory, common.Hash{})
+	}
+
+	if created := run(false); created == (common.Hash{}) {
+		t.Fatal("Sin restricción el CREATE debería crear el contrato")
+	}
+	if created := run(true); created != (common.Hash{}) {
+		t.Errorf("Con la restricción el CREATE debería fallar: %s", created.Hex())
+	}
+}
@@ -0,0 +1,126 @@
+package execution
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// StateDumpAccount es una cuenta del volcado de estado
+type StateDumpAccount struct {
+	Address     string `json:"address,omitempty"` // Vacío si no hay preimagen de la clave (cuenta anterior al registro)
+	Key         string `json:"key"`               // Hash de la dirección: la clave de la cuenta en el trie
+	Balance     string `json:"balance"`
+	Nonce       uint64 `json:"nonce"`
+	CodeHash    string `json:"codeHash"`
+	StorageRoot string `json:"storageRoot"`
+}
+
+// StateDump es un volcado (o una página) de las cuentas del trie de estado, ordenadas por clave
+type StateDump struct {
+	Root             string             `json:"root"`
+	Height           uint64             `json:"height,omitempty"`
+	Accounts         []StateDumpAccount `json:"accounts"`
+	MissingPreimages int                `json:"missingPreimages"` // Cuentas de la página sin dirección conocida
+	Next             string             `json:"next,omitempty"`   // Clave desde la que sigue el volcado (vacío = fin)
+}
+
+// DumpState lista las cuentas del estado tras el bloque height (0 = último estado confirmado), con su
+// dirección recuperada de las preimágenes de las claves del trie. Empieza en la clave start y retorna hasta
+// limit cuentas (0 = todas); Next indica dónde continuar. Los roots de alturas anteriores solo están
+// disponibles mientras sus nodos sigan en la base de datos del trie
+func (e *EVMExecutor) DumpState(height uint64, start common.Hash, limit int) (*StateDump, error) {
+	if e.stateManager == nil {
+		return nil, fmt.Errorf("stateManager no está inicializado")
+	}
+	sm := e.stateManager
+
+	root := sm.CommittedRoot()
+	if height > 0 {
+		var err error
+		if root, err = sm.rootAtHeight(height); err != nil {
+			return nil, err
+		}
+	}
+	dump, err := sm.dumpState(root, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	dump.Height = height
+	return dump, nil
+}
+
+// rootAtHeight retorna el root del estado EVM tras ejecutar el bloque height: el stateRoot del header o,
+// en bloques anteriores a ese campo, el hash del bloque (el app hash, que es ese root)
+func (sm *StateManager) rootAtHeight(height uint64) (common.Hash, error) {
+	blockData, err := sm.storage.GetBlock(height)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("bloque %d no encontrado: %w", height, err)
+	}
+	header, err := DecodeBlockHeader(blockData)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error parseando bloque %d: %w", height, err)
+	}
+	if header.StateRoot != "" {
+		return common.HexToHash(header.StateRoot), nil
+	}
+	return common.HexToHash(header.Hash), nil
+}
+
+// dumpState recorre el trie de cuentas del root desde la clave start
+func (sm *StateManager) dumpState(root, start common.Hash, limit int) (*StateDump, error) {
+	database := sm.stateDatabase()
+	if database == nil {
+		return nil, fmt.Errorf("estado EVM no está cargado")
+	}
+	if root == (common.Hash{}) {
+		root = types.EmptyRootHash
+	}
+	dump := &StateDump{
+		Root:     root.Hex(),
+		Accounts: []StateDumpAccount{},
+	}
+	if root == types.EmptyRootHash {
+		return dump, nil
+	}
+
+	accountTrie, err := trie.NewStateTrie(trie.StateTrieID(root), database.TrieDB())
+	if err != nil {
+		return nil, fmt.Errorf("estado del root %s no disponible: %w", root.Hex(), err)
+	}
+	nodeIt, err := accountTrie.NodeIterator(start.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error recorriendo trie de cuentas: %w", err)
+	}
+
+	it := trie.NewIterator(nodeIt)
+	for it.Next() {
+		if limit > 0 && len(dump.Accounts) >= limit {
+			dump.Next = common.BytesToHash(it.Key).Hex()
+			break
+		}
+		account, err := types.FullAccount(it.Value)
+		if err != nil {
+			return nil, fmt.Errorf("cuenta %x no decodificable: %w", it.Key, err)
+		}
+		entry := StateDumpAccount{
+			Key:         common.BytesToHash(it.Key).Hex(),
+			Balance:     account.Balance.String(),
+			Nonce:       account.Nonce,
+			CodeHash:    common.BytesToHash(account.CodeHash).Hex(),
+			StorageRoot: account.Root.Hex(),
+		}
+		if preimage := accountTrie.GetKey(it.Key); preimage != nil {
+			entry.Address = common.BytesToAddress(preimage).Hex()
+		} else {
+			dump.MissingPreimages++
+		}
+		dump.Accounts = append(dump.Accounts, entry)
+	}
+	if it.Err != nil {
+		return nil, fmt.Errorf("trie de cuentas incompleto en el root %s: %w", root.Hex(), it.Err)
+	}
+	return dump, nil
+}
@@ -0,0 +1,103 @@
+package execution
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TestEVMExecutor_DumpState prueba que el volcado recupere las direcciones de las preimágenes,
+// que pagine con next y que lea el estado de una altura anterior
+func TestEVMExecutor_DumpState(t *testing.T) {
+	testDir := createTestDir("dump_state")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+
+	funded := map[string]string{
+		"0x00000000000000000000000000000000000000b1": "100",
+		"0x00000000000000000000000000000000000000b2": "200",
+		"0x00000000000000000000000000000000000000b3": "300",
+	}
+	for address, amount := range funded {
+		if err := evm.FundAccount(address, amount); err != nil {
+			t.Fatalf("Error fondeando cuenta: %v", err)
+		}
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	// El hash del bloque 1 es el root del estado tras ejecutarlo
+	root := evm.GetStateManager().CommittedRoot()
+	block, _ := json.Marshal(map[string]interface{}{"header": map[string]interface{}{"height": 1, "hash": root.Hex()}})
+	if err := db.SaveBlock(1, block); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+
+	dump, err := evm.DumpState(0, common.Hash{}, 0)
+	if err != nil {
+		t.Fatalf("Error volcando estado: %v", err)
+	}
+	// Las cuentas fondeadas y la del contador de emisión
+	if dump.Root != root.Hex() || len(dump.Accounts) != 4 || dump.MissingPreimages != 0 || dump.Next != "" {
+		t.Fatalf("Volcado incorrecto: %+v", dump)
+	}
+	balances := make(map[string]string)
+	for _, account := range dump.Accounts {
+		balances[strings.ToLower(account.Address)] = account.Balance
+	}
+	for address, amount := range funded {
+		if balances[address] != amount {
+			t.Errorf("Cuenta %s con balance %q, esperado %s", address, balances[address], amount)
+		}
+	}
+	if _, ok := balances[SupplyAddress]; !ok {
+		t.Errorf("Falta la cuenta del contador de emisión: %+v", dump.Accounts)
+	}
+
+	// Paginado de a 3 cuentas
+	first, err := evm.DumpState(0, common.Hash{}, 3)
+	if err != nil || len(first.Accounts) != 3 || first.Next != dump.Accounts[3].Key {
+		t.Fatalf("Primera página incorrecta: %+v (%v)", first, err)
+	}
+	second, err := evm.DumpState(0, common.HexToHash(first.Next), 3)
+	if err != nil || len(second.Accounts) != 1 || second.Accounts[0].Address != dump.Accounts[3].Address || second.Next != "" {
+		t.Fatalf("Segunda página incorrecta: %+v (%v)", second, err)
+	}
+
+	// Después de otro commit, la altura 1 sigue mostrando el estado anterior
+	if err := evm.FundAccount("0x00000000000000000000000000000000000000b4", "400"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+	atHeight, err := evm.DumpState(1, common.Hash{}, 0)
+	if err != nil {
+		t.Fatalf("Error volcando estado en altura 1: %v", err)
+	}
+	if atHeight.Root != root.Hex() || atHeight.Height != 1 || len(atHeight.Accounts) != 4 {
+		t.Errorf("Volcado en altura 1 incorrecto: %+v", atHeight)
+	}
+	if _, err := evm.DumpState(5, common.Hash{}, 0); err == nil {
+		t.Error("Una altura sin bloque debería fallar")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"sync"
+	"sync/atomic"
 
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
 	"github.com/ethereum/go-ethereum/common"
@@ -25,9 +27,15 @@ type EVMExecutor struct {
 	stateManager     *StateManager
 	stateDB          *state.StateDB
 	chainConfig      *params.ChainConfig
-	currentHeight    uint64
-	currentTimestamp int64
+	hardforks        HardforkSchedule
+	systemContracts  SystemContracts // Contratos llamados al inicio y al final de cada bloque
+	blockInfo        atomic.Pointer[BlockInfo] // Altura y hora de consenso del bloque actual (BeginBlock)
+	profiler         *GasProfiler              // Gas por categoría de opcode y precompile (desactivado por defecto)
 	running          bool
+
+	// Cuentas con balance modificado desde el último commit (índice de cuentas)
+	touched   map[common.Address]struct{}
+	touchedMu sync.Mutex
 }
 
 // NewEVMExecutor crea una nueva instancia del ejecutor EVM
@@ -55,6 +63,7 @@ func NewEVMExecutor(storage *storage.BlockchainDB) *EVMExecutor {
 		storage:      storage,
 		stateManager: stateManager,
 		chainConfig:  chainConfig,
+		profiler:     NewGasProfiler(0),
 		running:      false,
 	}
 }
@@ -94,10 +103,10 @@ func (e *EVMExecutor) Stop() error {
 	return nil
 }
 
-// SetCurrentBlockInfo establece la información del bloque actual
+// SetCurrentBlockInfo establece la información del bloque actual sin validarla (tests y herramientas)
+// Los bloques de consenso usan BeginBlock
 func (e *EVMExecutor) SetCurrentBlockInfo(height uint64, timestamp int64) {
-	e.currentHeight = height
-	e.currentTimestamp = timestamp
+	e.blockInfo.Store(&BlockInfo{Height: height, Timestamp: timestamp})
 }
 
 // ExecuteTransaction ejecuta una transacción y actualiza el estado
@@ -127,46 +136,17 @@ func (e *EVMExecutor) ExecuteTransaction(tx *Transaction) (*ExecutionResult, err
 		}
 	}
 
-	// Preparar header del bloque con valores reales
-	// Coinbase es la dirección del validador (usar zero address si no hay validador específico)
-	coinbase := common.Address{}
-	
-	// BaseFee: usar big.NewInt(0) para chains sin EIP-1559
-	// NewEVMBlockContext requiere que BaseFee no sea nil
-	baseFee := big.NewInt(0) // 0 significa que no se usa EIP-1559
-	
-	// Crear header completo con todos los campos necesarios
-	header := &types.Header{
-		ParentHash: common.Hash{}, // Hash del bloque padre (zero hash para simplificar)
-		UncleHash:  types.EmptyUncleHash,
-		Coinbase:   coinbase, // Dirección del validador
-		Root:       common.Hash{}, // Root del estado (zero hash para simplificar)
-		TxHash:     types.EmptyRootHash,
-		ReceiptHash: types.EmptyRootHash,
-		Bloom:      types.Bloom{},
-		Difficulty: big.NewInt(0), // Difficulty 0 para PoS
-		Number:     big.NewInt(int64(e.currentHeight)),
-		GasLimit:   tx.GasLimit,
-		GasUsed:    0,
-		Time:       uint64(e.currentTimestamp),
-		Extra:      []byte{},
-		MixDigest:  common.Hash{},
-		Nonce:      types.BlockNonce{},
-		BaseFee:    baseFee, // 0 para chains sin EIP-1559
-	}
+	blockContext, chainConfig := e.blockContext(tx.GasLimit)
 
-	// Preparar contexto de ejecución
-	// NewEVMBlockContext requiere: header, ChainContext (para obtener headers previos), y author (dirección del validador)
-	// author puede ser zero address si no hay validador específico
-	author := &coinbase // Usar dirección del validador (zero address si no hay)
-	
-	// Crear adaptador de ChainContext con configuración de chain y engine nil (no necesario para ejecución básica)
-	chainAdapter := &chainContextAdapter{
-		chainConfig: e.chainConfig,
-		engine:      nil, // No necesitamos engine para ejecución básica
+	// Despliegue restringido: solo los deployers aprobados crean contratos, directamente o con un CREATE
+	// desde un contrato (factories) en una transacción suya
+	denyDeploy := e.getStateDB() != nil && !canDeploy(e.getStateDB(), from)
+	if denyDeploy && tx.To == "" {
+		return &ExecutionResult{
+			Success: false,
+			Error:   fmt.Sprintf("cuenta %s no autorizada a desplegar contratos", tx.From),
+		}, nil
 	}
-	
-	blockContext := core.NewEVMBlockContext(header, chainAdapter, author)
 
 	// Crear message para ejecutar
 	// Para chains sin EIP-1559, usamos GasPrice tradicional
@@ -181,15 +161,44 @@ func (e *EVMExecutor) ExecuteTransaction(tx *Transaction) (*ExecutionResult, err
 		GasFeeCap:  gasPrice, // Usar gasPrice como GasFeeCap si no se especifica
 		GasTipCap:  gasPrice, // Usar gasPrice como GasTipCap si no se especifica
 		Data:       tx.Data,
-		AccessList: nil,
+		AccessList: tx.AccessList.toEthereum(),
 	}
 
 	// Crear EVM (v1.16+: TxContext se pasa directamente en ApplyMessage)
-	evm := vm.NewEVM(blockContext, e.getStateDB(), e.chainConfig, vm.Config{})
+	// El StateDB con hooks registra las cuentas cuyo balance cambia (índice de cuentas)
+	// Los hooks de tracing alimentan el profiling de gas (si está activo) y bloquean los CREATE de
+	// cuentas no autorizadas con el despliegue restringido
+	vmConfig := vm.Config{}
+	var profile *txGasProfile
+	if e.profiler.Enabled() {
+		profile = e.profiler.newTx(chainConfig, blockContext)
+		vmConfig.Tracer = profile.hooks()
+	}
+	if denyDeploy {
+		vmConfig.Tracer = denyCreates(&blockContext, vmConfig.Tracer)
+	}
+	evm := vm.NewEVM(blockContext, state.NewHookedState(e.getStateDB(), e.balanceHooks()), chainConfig, vmConfig)
+
+	// Transacción patrocinada: el fee payer adelanta el gas; si el mensaje no se aplica, se revierte
+	var payer common.Address
+	snapshot := -1
+	if tx.FeePayer != "" {
+		payer = common.HexToAddress(tx.FeePayer)
+		snapshot = e.getStateDB().Snapshot()
+		if err := prefundGas(e.getStateDB(), payer, from, tx.GasLimit, gasPrice); err != nil {
+			return &ExecutionResult{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
+	}
 
 	// Ejecutar transacción
 	result, err := core.ApplyMessage(evm, &msg, new(core.GasPool).AddGas(tx.GasLimit))
 
+	if err != nil && snapshot >= 0 {
+		e.getStateDB().RevertToSnapshot(snapshot)
+	}
 	if err != nil {
 		// Si hay error, result puede ser nil, usar 0 para GasUsed
 		gasUsed := uint64(0)
@@ -203,6 +212,18 @@ func (e *EVMExecutor) ExecuteTransaction(tx *Transaction) (*ExecutionResult, err
 		}, nil
 	}
 
+	if profile != nil {
+		e.profiler.record(blockContext.BlockNumber.Uint64(), profile, result.UsedGas)
+	}
+
+	// El fee se acreditó al coinbase (zero address): pasa al fee collector, que se quema al final del bloque
+	collectFee(e.getStateDB(), blockContext.Coinbase, result.UsedGas, gasPrice)
+	e.markTouched(from, feeCollector)
+	if tx.FeePayer != "" {
+		refundUnusedGas(e.getStateDB(), payer, from, tx.GasLimit, result.UsedGas, gasPrice)
+		e.markTouched(payer)
+	}
+
 	// Si la ejecución fue exitosa, guardar estado intermedio
 	if err == nil && !result.Failed() {
 		// Finalizar el StateDB para aplicar cambios
@@ -236,6 +257,61 @@ func (e *EVMExecutor) ExecuteTransaction(tx *Transaction) (*ExecutionResult, err
 	}, nil
 }
 
+// blockContext arma el contexto de bloque de la EVM para la altura actual y la configuración de la chain
+// con los hardforks activos en ella
+func (e *EVMExecutor) blockContext(gasLimit uint64) (vm.BlockContext, *params.ChainConfig) {
+	// Altura y hora del mismo bloque, aunque un bloque nuevo empiece durante la llamada
+	info := e.CurrentBlockInfo()
+
+	// Preparar header del bloque con valores reales
+	// Coinbase es la dirección del validador (usar zero address si no hay validador específico)
+	coinbase := common.Address{}
+	
+	// BaseFee: usar big.NewInt(0) para chains sin EIP-1559
+	// NewEVMBlockContext requiere que BaseFee no sea nil
+	baseFee := big.NewInt(0) // 0 significa que no se usa EIP-1559
+	
+	// Crear header completo con todos los campos necesarios
+	header := &types.Header{
+		ParentHash: common.Hash{}, // Hash del bloque padre (zero hash para simplificar)
+		UncleHash:  types.EmptyUncleHash,
+		Coinbase:   coinbase, // Dirección del validador
+		Root:       common.Hash{}, // Root del estado (zero hash para simplificar)
+		TxHash:     types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+		Bloom:      types.Bloom{},
+		Difficulty: big.NewInt(0), // Difficulty 0 para PoS
+		Number:     big.NewInt(int64(info.Height)),
+		GasLimit:   gasLimit,
+		GasUsed:    0,
+		Time:       uint64(info.Timestamp),
+		Extra:      []byte{},
+		MixDigest:  common.Hash{},
+		Nonce:      types.BlockNonce{},
+		BaseFee:    baseFee, // 0 para chains sin EIP-1559
+	}
+
+	// Hardforks activos en este bloque; con Cancun el header lleva el exceso de blob gas (BLOBBASEFEE)
+	chainConfig := e.chainConfigAt(info.Height)
+	if chainConfig.CancunTime != nil {
+		header.ExcessBlobGas = new(uint64)
+		header.BlobGasUsed = new(uint64)
+	}
+
+	// Preparar contexto de ejecución
+	// NewEVMBlockContext requiere: header, ChainContext (para obtener headers previos), y author (dirección del validador)
+	// author puede ser zero address si no hay validador específico
+	author := &coinbase // Usar dirección del validador (zero address si no hay)
+	
+	// Crear adaptador de ChainContext con configuración de chain y engine nil (no necesario para ejecución básica)
+	chainAdapter := &chainContextAdapter{
+		chainConfig: chainConfig,
+		engine:      nil, // No necesitamos engine para ejecución básica
+	}
+	
+	return core.NewEVMBlockContext(header, chainAdapter, author), chainConfig
+}
+
 // getStateDB obtiene o crea el StateDB
 func (e *EVMExecutor) getStateDB() *state.StateDB {
 	if e.stateDB == nil {
@@ -253,8 +329,63 @@ func (e *EVMExecutor) GetState(address string) (*AccountState, error) {
 		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
 	}
 
+	return accountState(e.getStateDB(), address), nil
+}
+
+// GetStateAtHeight retorna el estado de una cuenta tras el bloque height, leído de un StateDB de solo
+// lectura en el root de ese bloque (seguro desde cualquier goroutine mientras se ejecuta un bloque)
+func (e *EVMExecutor) GetStateAtHeight(address string, height uint64) (*AccountState, error) {
+	if !e.running {
+		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+
+	root, err := e.stateManager.rootAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	stateDB, err := e.stateManager.StateAt(root)
+	if err != nil {
+		return nil, err
+	}
+	return accountState(stateDB, address), nil
+}
+
+// GetCodeAt retorna el code de una cuenta tras el bloque height (0 = último estado confirmado)
+func (e *EVMExecutor) GetCodeAt(address string, height uint64) ([]byte, error) {
+	stateDB, err := e.stateAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return stateDB.GetCode(common.HexToAddress(address)), stateDB.Error()
+}
+
+// GetStorageAt retorna un slot de storage de una cuenta tras el bloque height (0 = último estado confirmado)
+func (e *EVMExecutor) GetStorageAt(address string, slot common.Hash, height uint64) (common.Hash, error) {
+	stateDB, err := e.stateAtHeight(height)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return stateDB.GetState(common.HexToAddress(address), slot), stateDB.Error()
+}
+
+// stateAtHeight retorna un StateDB de solo lectura tras el bloque height (0 = último estado confirmado)
+func (e *EVMExecutor) stateAtHeight(height uint64) (*state.StateDB, error) {
+	if !e.running {
+		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	if height == 0 {
+		return e.stateManager.CommittedState()
+	}
+	root, err := e.stateManager.rootAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return e.stateManager.StateAt(root)
+}
+
+// accountState lee el balance, nonce, code hash y los primeros slots de storage de una cuenta
+func accountState(stateDB *state.StateDB, address string) *AccountState {
 	addr := common.HexToAddress(address)
-	stateDB := e.getStateDB()
 
 	balance := stateDB.GetBalance(addr)
 	nonce := stateDB.GetNonce(addr)
@@ -276,7 +407,7 @@ func (e *EVMExecutor) GetState(address string) (*AccountState, error) {
 		Nonce:    nonce,
 		CodeHash: codeHash.Hex(),
 		Storage:  storage,
-	}, nil
+	}
 }
 
 // FundAccount agrega fondos a una cuenta (útil para testing)
@@ -301,7 +432,9 @@ func (e *EVMExecutor) FundAccount(address string, amount string) error {
 
 	// Agregar balance a la cuenta (nueva API requiere BalanceChangeReason)
 	// Usar BalanceIncreaseGenesisBalance para fondear cuentas en testnet
-	stateDB.AddBalance(addr, amountU256, tracing.BalanceIncreaseGenesisBalance)
+	// Los fondos son nuevos: se suman al contador de emisión
+	mint(stateDB, addr, amountU256, tracing.BalanceIncreaseGenesisBalance)
+	e.markTouched(addr)
 
 	// Guardar estado (esto guardará los cambios en el StateDB)
 	// Nota: El estado se guardará cuando se haga commit del bloque
@@ -315,6 +448,30 @@ func (e *EVMExecutor) FundAccount(address string, amount string) error {
 	return nil
 }
 
+// TransferBalance mueve fondos entre dos cuentas fuera de la ejecución EVM
+// Lo usan módulos del protocolo (ej: staking) para liberar fondos en custodia
+func (e *EVMExecutor) TransferBalance(from, to string, amount *big.Int) error {
+	if !e.running {
+		return fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+
+	fromAddr := common.HexToAddress(from)
+	toAddr := common.HexToAddress(to)
+	stateDB := e.getStateDB()
+
+	value := new(uint256.Int)
+	value.SetFromBig(amount)
+
+	if stateDB.GetBalance(fromAddr).Cmp(value) < 0 {
+		return fmt.Errorf("balance insuficiente en %s para transferir %s", from, amount.String())
+	}
+
+	stateDB.SubBalance(fromAddr, value, tracing.BalanceChangeTransfer)
+	stateDB.AddBalance(toAddr, value, tracing.BalanceChangeTransfer)
+	e.markTouched(fromAddr, toAddr)
+	return nil
+}
+
 // DeployContract despliega un contrato inteligente
 func (e *EVMExecutor) DeployContract(
 	from string,
@@ -367,7 +524,7 @@ func (e *EVMExecutor) DeployContract(
 	return contractAddr, result, nil
 }
 
-// CallContract ejecuta una llamada a un contrato (sin modificar estado)
+// CallContract ejecuta una llamada de solo lectura a un contrato (eth_call) sobre el último estado confirmado
 func (e *EVMExecutor) CallContract(
 	from string,
 	contractAddr string,
@@ -378,32 +535,35 @@ func (e *EVMExecutor) CallContract(
 		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
 	}
 
-	// Obtener nonce actual
-	stateDB := e.getStateDB()
-	nonce := uint64(0)
-	if stateDB != nil {
-		nonce = stateDB.GetNonce(common.HexToAddress(from))
-	}
-
-	// Crear transacción de llamada
-	tx := &Transaction{
-		From:     from,
-		To:       contractAddr,
-		Value:    "0",
-		Data:     data,
-		GasLimit: gasLimit,
-		GasPrice: "0", // Sin costo para calls
-		Nonce:    nonce,
+	// Sobre una copia del último estado confirmado: los cambios de la llamada se descartan y el bloque
+	// en ejecución no se ve afectado
+	stateDB, err := e.stateManager.CommittedState()
+	if err != nil {
+		return nil, err
 	}
 
-	// Ejecutar transacción
-	result, err := e.ExecuteTransaction(tx)
+	to := common.HexToAddress(contractAddr)
+	msg := core.Message{
+		From:                  common.HexToAddress(from),
+		To:                    &to,
+		Value:                 new(big.Int),
+		GasLimit:              gasLimit,
+		GasPrice:              new(big.Int), // Sin costo para calls
+		GasFeeCap:             new(big.Int),
+		GasTipCap:             new(big.Int),
+		Data:                  data,
+		SkipNonceChecks:       true,
+		SkipTransactionChecks: true, // Como eth_call: el remitente puede ser un contrato
+	}
+	blockContext, chainConfig := e.blockContext(gasLimit)
+	evm := vm.NewEVM(blockContext, stateDB, chainConfig, vm.Config{})
+	result, err := core.ApplyMessage(evm, &msg, new(core.GasPool).AddGas(gasLimit))
 	if err != nil {
 		return nil, fmt.Errorf("error ejecutando call: %w", err)
 	}
 
-	if !result.Success {
-		return nil, fmt.Errorf("call falló: %s", result.Error)
+	if result.Failed() {
+		return nil, fmt.Errorf("call falló: %v", result.Err)
 	}
 
 	return result.ReturnData, nil
@@ -419,7 +579,10 @@ func (e *EVMExecutor) SaveState() error {
 	if e.stateManager == nil {
 		return fmt.Errorf("stateManager no está inicializado")
 	}
-	return e.stateManager.SaveState()
+	err := e.stateManager.saveState(e.CurrentBlockInfo().Height)
+	// Un StateDB confirmado ya no admite cambios: seguir con el que el gestor recargó desde el nuevo root
+	e.stateDB = e.stateManager.GetStateDB()
+	return err
 }
 
 // SaveStateAtHeight guarda el estado en una altura específica
@@ -427,7 +590,9 @@ func (e *EVMExecutor) SaveStateAtHeight(height uint64) error {
 	if e.stateManager == nil {
 		return fmt.Errorf("stateManager no está inicializado")
 	}
-	return e.stateManager.SaveStateAtHeight(height)
+	err := e.stateManager.SaveStateAtHeight(height)
+	e.stateDB = e.stateManager.GetStateDB()
+	return err
 }
 
 // Transaction representa una transacción a ejecutar
@@ -440,6 +605,9 @@ type Transaction struct {
 	GasLimit uint64
 	GasPrice string
 	Nonce    uint64
+
+	AccessList AccessList // Direcciones y slots precalentados (EIP-2930)
+	FeePayer   string     // Cuenta que paga el gas (transacción patrocinada); vacía = el remitente
 }
 
 // ExecutionResult contiene el resultado de ejecutar una transacción
@@ -503,3 +671,26 @@ func (c *chainContextAdapter) GetHeaderByHash(hash common.Hash) *types.Header {
 func (c *chainContextAdapter) Engine() consensus.Engine {
 	return c.engine // Puede ser nil, pero debe ser de tipo consensus.Engine
 }
+
+// ReloadState descarta el StateDB en uso y lo recarga desde el gestor de estado
+// (ej: después de restaurar un snapshot)
+func (e *EVMExecutor) ReloadState() {
+	e.stateDB = e.stateManager.GetStateDB()
+}
+
+// Snapshot marca el estado en ejecución para deshacer los cambios posteriores con RevertToSnapshot
+func (e *EVMExecutor) Snapshot() int {
+	return e.getStateDB().Snapshot()
+}
+
+// RevertToSnapshot deshace los cambios al estado en ejecución posteriores al snapshot
+// Falla si el snapshot ya no es válido (el StateDB se finalizó después de tomarlo)
+func (e *EVMExecutor) RevertToSnapshot(id int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("no se puede revertir al snapshot %d: %v", id, r)
+		}
+	}()
+	e.getStateDB().RevertToSnapshot(id)
+	return nil
+}
@@ -680,3 +680,157 @@ func TestEVMExecutor_ExecuteTransaction_InvalidNonce(t *testing.T) {
 	}
 }
 
+
+// TestEVMExecutor_Supply prueba los contadores de emisión: fondeos emiten y los fees se queman al final del bloque
+func TestEVMExecutor_Supply(t *testing.T) {
+	testDir := createTestDir("supply")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+	evm.SetCurrentBlockInfo(1, 1699999999)
+
+	fromAddr := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	lockedAddr := common.HexToAddress("0x0000000000000000000000000000000000000100")
+	if err := evm.FundAccount(fromAddr.Hex(), "1000000000000000000"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	if err := evm.FundAccount(lockedAddr.Hex(), "500"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+
+	// Transferencia simple: 21000 gas a 1 gwei se queman
+	result, err := evm.ExecuteTransaction(&Transaction{
+		From:     fromAddr.Hex(),
+		To:       common.HexToAddress("0xabcdef").Hex(),
+		Value:    "1",
+		GasLimit: 21000,
+		GasPrice: "1000000000",
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("Transacción debería ser exitosa: %v %+v", err, result)
+	}
+
+	// El fee queda en el fee collector hasta el final del bloque
+	collector, err := evm.GetState(FeeCollectorAddress)
+	if err != nil || collector.Balance != "21000000000000" {
+		t.Fatalf("El fee collector debería tener el fee: %+v (%v)", collector, err)
+	}
+	burned, err := evm.BurnCollectedFees()
+	if err != nil || burned.String() != "21000000000000" {
+		t.Fatalf("Error quemando fees: %v (%v)", burned, err)
+	}
+
+	supply, err := evm.GetSupply(lockedAddr.Hex())
+	if err != nil {
+		t.Fatalf("Error obteniendo emisión: %v", err)
+	}
+	expected := Supply{
+		Minted:      "1000000000000000500",
+		Burned:      "21000000000000",
+		Total:       "999979000000000500",
+		Locked:      "500",
+		Circulating: "999979000000000000",
+	}
+	if *supply != expected {
+		t.Errorf("Emisión inesperada: %+v", supply)
+	}
+
+	// El fee no queda en el coinbase
+	coinbase, err := evm.GetState(common.Address{}.Hex())
+	if err != nil {
+		t.Fatalf("Error obteniendo coinbase: %v", err)
+	}
+	if coinbase.Balance != "0" {
+		t.Errorf("El coinbase no debería conservar el fee: %s", coinbase.Balance)
+	}
+	if collector, _ := evm.GetState(FeeCollectorAddress); collector.Balance != "0" {
+		t.Errorf("El fee collector debería quedar vacío: %s", collector.Balance)
+	}
+}
+
+// TestEVMExecutor_SponsoredTransaction prueba que el fee payer pague el gas usado y el remitente solo el valor
+func TestEVMExecutor_SponsoredTransaction(t *testing.T) {
+	testDir := createTestDir("sponsored")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+	evm.SetCurrentBlockInfo(1, 1699999999)
+
+	sender := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	payer := common.HexToAddress("0x0987654321098765432109876543210987654321")
+	recipient := common.HexToAddress("0xabcdef")
+	if err := evm.FundAccount(sender.Hex(), "5"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	if err := evm.FundAccount(payer.Hex(), "1000000000000000000"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+
+	// El remitente no tiene para el gas: con 50000 de límite solo se cobran los 21000 usados al fee payer
+	result, err := evm.ExecuteTransaction(&Transaction{
+		From:     sender.Hex(),
+		To:       recipient.Hex(),
+		Value:    "5",
+		GasLimit: 50000,
+		GasPrice: "1000000000",
+		FeePayer: payer.Hex(),
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("Transacción patrocinada debería ser exitosa: %v %+v", err, result)
+	}
+	balances := map[common.Address]string{
+		sender:    "0",
+		recipient: "5",
+		payer:     "999979000000000000",
+	}
+	for addr, expected := range balances {
+		if state, err := evm.GetState(addr.Hex()); err != nil || state.Balance != expected {
+			t.Errorf("Balance de %s: %+v, esperado %s (%v)", addr.Hex(), state, expected, err)
+		}
+	}
+
+	// Un fee payer sin balance no ejecuta la transacción ni mueve fondos
+	poorPayer := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	result, err = evm.ExecuteTransaction(&Transaction{
+		From:     recipient.Hex(),
+		To:       sender.Hex(),
+		Value:    "1",
+		GasLimit: 21000,
+		GasPrice: "1000000000",
+		FeePayer: poorPayer.Hex(),
+	})
+	if err != nil || result.Success {
+		t.Fatalf("Se esperaba un fallo por balance del fee payer: %v %+v", err, result)
+	}
+	if state, _ := evm.GetState(recipient.Hex()); state.Balance != "5" {
+		t.Errorf("El remitente no debería cambiar: %s", state.Balance)
+	}
+}
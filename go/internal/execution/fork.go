@@ -0,0 +1,363 @@
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// DefaultForkTimeout es el tiempo máximo de cada consulta a la red remota de un fork
+const DefaultForkTimeout = 30 * time.Second
+
+// ForkBackend lee el estado de la red remota de la que se hace fork
+type ForkBackend interface {
+	// LatestHeight retorna la última altura de la red remota
+	LatestHeight(ctx context.Context) (uint64, error)
+	// Account retorna la cuenta en la altura dada (nil si no existe)
+	Account(ctx context.Context, address common.Address, height uint64) (*ForkAccount, error)
+	// Storage retorna un slot de storage de la cuenta en la altura dada
+	Storage(ctx context.Context, address common.Address, slot common.Hash, height uint64) (common.Hash, error)
+}
+
+// ForkAccount es una cuenta leída de la red remota
+type ForkAccount struct {
+	Balance *big.Int
+	Nonce   uint64
+	Code    []byte
+}
+
+// ForkInfo identifica la red remota y la altura fijada de un fork
+type ForkInfo struct {
+	Source string `json:"source"`
+	Height uint64 `json:"height"`
+}
+
+// Registros del fork en storage (ver storage.GetForkRecord)
+const (
+	forkMetaKey     = "meta"
+	forkAccountKey  = "account:"
+	forkSlotKey     = "slot:"
+	forkDeletedKey  = "deleted:"
+	forkDeletedMark = "1"
+)
+
+// forkAccountRecord es una cuenta remota guardada; el code se guarda aparte en la base de datos del estado
+type forkAccountRecord struct {
+	Exists   bool   `json:"exists"`
+	Balance  string `json:"balance,omitempty"`
+	Nonce    uint64 `json:"nonce,omitempty"`
+	CodeHash string `json:"codeHash,omitempty"`
+}
+
+// forkKey identifica una cuenta (isSlot=false) o un slot leído con valor de la red remota
+type forkKey struct {
+	address common.Address
+	slot    common.Hash
+	isSlot  bool
+}
+
+// Fork completa el estado local con el de una red remota en una altura fijada: las cuentas y slots que no
+// existen localmente se piden a la red remota la primera vez y quedan guardados en storage. Lo que se
+// borra localmente queda marcado para no volver a leerlo de la red remota
+type Fork struct {
+	backend ForkBackend
+	info    ForkInfo
+	storage *storage.BlockchainDB
+
+	mu     sync.Mutex
+	remote map[forkKey]struct{} // Cuentas y slots remotos leídos desde el último commit
+}
+
+// NewFork crea un fork de la red remota source en la altura height (0 = la última de la red remota, o la
+// ya fijada si el directorio de datos viene de un fork anterior)
+func NewFork(backend ForkBackend, source string, height uint64) *Fork {
+	return &Fork{
+		backend: backend,
+		info:    ForkInfo{Source: source, Height: height},
+		remote:  make(map[forkKey]struct{}),
+	}
+}
+
+// Info retorna la red remota y la altura fijada
+func (f *Fork) Info() ForkInfo {
+	return f.info
+}
+
+// SetFork habilita el fork de una red remota (solo modo desarrollo). Fija la altura remota y la registra
+// en storage: un directorio de datos no puede reutilizarse con otra red o altura. Debe llamarse antes de Start
+func (e *EVMExecutor) SetFork(ctx context.Context, fork *Fork) error {
+	if err := fork.bind(ctx, e.storage); err != nil {
+		return err
+	}
+	e.stateManager.fork = fork
+	log.Printf("Fork de %s en la altura %d", fork.info.Source, fork.info.Height)
+	return nil
+}
+
+// bind fija la altura del fork contra la registrada en storage
+func (f *Fork) bind(ctx context.Context, db *storage.BlockchainDB) error {
+	f.storage = db
+	data, err := db.GetForkRecord(forkMetaKey)
+	if err != nil {
+		return fmt.Errorf("error leyendo fork registrado: %w", err)
+	}
+	if data != nil {
+		var stored ForkInfo
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("error parseando fork registrado: %w", err)
+		}
+		if f.info.Height == 0 {
+			f.info.Height = stored.Height
+		}
+		if stored != f.info {
+			return fmt.Errorf("el directorio de datos es un fork de %s en la altura %d", stored.Source, stored.Height)
+		}
+		return nil
+	}
+
+	if f.info.Height == 0 {
+		height, err := f.backend.LatestHeight(ctx)
+		if err != nil {
+			return fmt.Errorf("error obteniendo la altura de %s: %w", f.info.Source, err)
+		}
+		f.info.Height = height
+	}
+	data, err = json.Marshal(f.info)
+	if err != nil {
+		return err
+	}
+	return db.WriteForkRecords(map[string][]byte{forkMetaKey: data})
+}
+
+// wrap retorna la base de datos del estado con lecturas que completan el estado local con el remoto
+func (f *Fork) wrap(database state.Database) state.Database {
+	return &forkDatabase{Database: database, fork: f}
+}
+
+// deleted indica si la cuenta o el slot se borraron localmente después de leerse de la red remota
+func (f *Fork) deleted(key string) (bool, error) {
+	data, err := f.storage.GetForkRecord(forkDeletedKey + key)
+	return data != nil, err
+}
+
+// markRemote registra una cuenta o slot que existe en la red remota
+func (f *Fork) markRemote(key forkKey) {
+	f.mu.Lock()
+	f.remote[key] = struct{}{}
+	f.mu.Unlock()
+}
+
+// remoteExists indica si la cuenta ya se leyó de la red remota y existe allí (sin consultarla)
+func (f *Fork) remoteExists(address common.Address) bool {
+	data, err := f.storage.GetForkRecord(forkAccountKey + address.Hex())
+	if err != nil || data == nil {
+		return false
+	}
+	var record forkAccountRecord
+	return json.Unmarshal(data, &record) == nil && record.Exists
+}
+
+// account retorna la cuenta remota, leída de storage o de la red remota (nil si no existe)
+// El code se guarda en la base de datos del estado (disk) para que el StateDB lo lea por su hash
+func (f *Fork) account(address common.Address, disk state.Database) (*types.StateAccount, error) {
+	key := forkAccountKey + address.Hex()
+	data, err := f.storage.GetForkRecord(key)
+	if err != nil {
+		return nil, err
+	}
+	var record forkAccountRecord
+	if data != nil {
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("error parseando cuenta remota %s: %w", address.Hex(), err)
+		}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultForkTimeout)
+		remote, err := f.backend.Account(ctx, address, f.info.Height)
+		cancel()
+		if err != nil {
+			// Un error del StateDB abortaría el commit del bloque: la cuenta se trata como inexistente sin
+			// guardarla, y la próxima lectura vuelve a consultar la red remota
+			log.Printf("Advertencia: error leyendo cuenta %s de %s: %v", address.Hex(), f.info.Source, err)
+			return nil, nil
+		}
+		if remote != nil && remote.Balance == nil {
+			remote.Balance = new(big.Int)
+		}
+		if remote != nil && (remote.Balance.Sign() > 0 || remote.Nonce > 0 || len(remote.Code) > 0) {
+			record = forkAccountRecord{Exists: true, Balance: remote.Balance.String(), Nonce: remote.Nonce}
+			if len(remote.Code) > 0 {
+				codeHash := crypto.Keccak256Hash(remote.Code)
+				rawdb.WriteCode(disk.TrieDB().Disk(), codeHash, remote.Code)
+				record.CodeHash = codeHash.Hex()
+			}
+		}
+		if data, err = json.Marshal(record); err != nil {
+			return nil, err
+		}
+		if err := f.storage.WriteForkRecords(map[string][]byte{key: data}); err != nil {
+			return nil, fmt.Errorf("error guardando cuenta remota %s: %w", address.Hex(), err)
+		}
+	}
+	if !record.Exists {
+		return nil, nil
+	}
+
+	balance, ok := new(big.Int).SetString(record.Balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("balance remoto inválido para %s: %s", address.Hex(), record.Balance)
+	}
+	account := types.NewEmptyStateAccount()
+	account.Balance = uint256.MustFromBig(balance)
+	account.Nonce = record.Nonce
+	if record.CodeHash != "" {
+		account.CodeHash = common.HexToHash(record.CodeHash).Bytes()
+	}
+	return account, nil
+}
+
+// storageAt retorna un slot remoto, leído de storage o de la red remota. Solo se consulta la red remota
+// si la cuenta remota tiene code (las cuentas sin code no tienen storage)
+func (f *Fork) storageAt(address common.Address, slot common.Hash, disk state.Database) (common.Hash, error) {
+	key := forkSlotKey + address.Hex() + ":" + slot.Hex()
+	data, err := f.storage.GetForkRecord(key)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if data != nil {
+		return common.BytesToHash(data), nil
+	}
+
+	account, err := f.account(address, disk)
+	if err != nil || account == nil || common.BytesToHash(account.CodeHash) == types.EmptyCodeHash {
+		return common.Hash{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultForkTimeout)
+	value, err := f.backend.Storage(ctx, address, slot, f.info.Height)
+	cancel()
+	if err != nil {
+		// Igual que en account: el slot se trata como vacío sin guardarlo
+		log.Printf("Advertencia: error leyendo storage %s de %s en %s: %v", slot.Hex(), address.Hex(), f.info.Source, err)
+		return common.Hash{}, nil
+	}
+	if err := f.storage.WriteForkRecords(map[string][]byte{key: value.Bytes()}); err != nil {
+		return common.Hash{}, fmt.Errorf("error guardando storage remoto de %s: %w", address.Hex(), err)
+	}
+	return value, nil
+}
+
+// recordDeletions marca como borrados las cuentas y slots remotos leídos desde el último commit que ya no
+// existen en stateDB, para que las lecturas siguientes no vuelvan a tomarlos de la red remota.
+// Se llama antes de confirmar stateDB
+func (f *Fork) recordDeletions(stateDB *state.StateDB) error {
+	// Las cuentas destruidas o vacías recién se eliminan al finalizar
+	stateDB.Finalise(true)
+
+	f.mu.Lock()
+	remote := f.remote
+	f.remote = make(map[forkKey]struct{})
+	f.mu.Unlock()
+
+	deleted := make(map[string][]byte)
+	for key := range remote {
+		if key.isSlot {
+			if stateDB.GetState(key.address, key.slot) == (common.Hash{}) {
+				deleted[forkDeletedKey+key.address.Hex()+":"+key.slot.Hex()] = []byte(forkDeletedMark)
+			}
+		} else if !stateDB.Exist(key.address) {
+			deleted[forkDeletedKey+key.address.Hex()] = []byte(forkDeletedMark)
+		}
+	}
+	if err := f.storage.WriteForkRecords(deleted); err != nil {
+		return fmt.Errorf("error guardando borrados del fork: %w", err)
+	}
+	return nil
+}
+
+// forkDatabase es la base de datos del estado con lecturas completadas por el fork
+type forkDatabase struct {
+	state.Database
+	fork *Fork
+}
+
+// Reader retorna el reader del root con lecturas completadas por el fork
+func (db *forkDatabase) Reader(root common.Hash) (state.Reader, error) {
+	reader, err := db.Database.Reader(root)
+	if err != nil {
+		return nil, err
+	}
+	return &forkReader{Reader: reader, database: db.Database, fork: db.fork}, nil
+}
+
+// forkReader lee primero el estado local; lo que no existe localmente ni se borró se lee del fork
+type forkReader struct {
+	state.Reader
+	database state.Database
+	fork     *Fork
+}
+
+// Account retorna la cuenta local o, si no existe localmente, la remota
+func (r *forkReader) Account(address common.Address) (*types.StateAccount, error) {
+	account, err := r.Reader.Account(address)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil {
+		// Una cuenta local puede tapar una remota: se registra para marcarla si se borra
+		if r.fork.remoteExists(address) {
+			r.fork.markRemote(forkKey{address: address})
+		}
+		return account, nil
+	}
+	deleted, err := r.fork.deleted(address.Hex())
+	if err != nil || deleted {
+		return nil, err
+	}
+	remote, err := r.fork.account(address, r.database)
+	if err != nil {
+		return nil, err
+	}
+	if remote != nil {
+		r.fork.markRemote(forkKey{address: address})
+	}
+	return remote, nil
+}
+
+// Storage retorna el slot local o, si está vacío localmente, el remoto
+func (r *forkReader) Storage(address common.Address, slot common.Hash) (common.Hash, error) {
+	value, err := r.Reader.Storage(address, slot)
+	if err != nil || value != (common.Hash{}) {
+		// Un slot local puede tapar uno remoto: se registra para marcarlo si se borra
+		if err == nil {
+			if remote, err := r.fork.storage.GetForkRecord(forkSlotKey + address.Hex() + ":" + slot.Hex()); err == nil && common.BytesToHash(remote) != (common.Hash{}) {
+				r.fork.markRemote(forkKey{address: address, slot: slot, isSlot: true})
+			}
+		}
+		return value, err
+	}
+	for _, key := range []string{address.Hex(), address.Hex() + ":" + slot.Hex()} {
+		deleted, err := r.fork.deleted(key)
+		if err != nil || deleted {
+			return common.Hash{}, err
+		}
+	}
+	value, err = r.fork.storageAt(address, slot, r.database)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if value != (common.Hash{}) {
+		r.fork.markRemote(forkKey{address: address, slot: slot, isSlot: true})
+	}
+	return value, nil
+}
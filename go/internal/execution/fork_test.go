@@ -0,0 +1,118 @@
+package execution
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeForkBackend es una red remota con una cuenta y un contrato fijos
+type fakeForkBackend struct {
+	accounts map[common.Address]*ForkAccount
+	slots    map[common.Hash]common.Hash
+	calls    atomic.Int32
+}
+
+func (b *fakeForkBackend) LatestHeight(ctx context.Context) (uint64, error) {
+	return 42, nil
+}
+
+func (b *fakeForkBackend) Account(ctx context.Context, address common.Address, height uint64) (*ForkAccount, error) {
+	b.calls.Add(1)
+	return b.accounts[address], nil
+}
+
+func (b *fakeForkBackend) Storage(ctx context.Context, address common.Address, slot common.Hash, height uint64) (common.Hash, error) {
+	b.calls.Add(1)
+	return b.slots[slot], nil
+}
+
+// TestEVMExecutor_Fork prueba que el estado remoto complete el local, quede guardado después de la primera
+// lectura y que lo borrado localmente no vuelva a leerse de la red remota
+func TestEVMExecutor_Fork(t *testing.T) {
+	testDir := createTestDir("fork")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	holder := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	slot := common.BigToHash(common.Big1)
+	code := []byte{0x60, 0x00}
+	backend := &fakeForkBackend{
+		accounts: map[common.Address]*ForkAccount{
+			holder:   {Balance: big.NewInt(1000), Nonce: 3},
+			contract: {Balance: new(big.Int), Nonce: 1, Code: code},
+		},
+		slots: map[common.Hash]common.Hash{slot: common.BigToHash(big.NewInt(7))},
+	}
+
+	evm := NewEVMExecutor(db)
+	fork := NewFork(backend, "http://remoto", 0)
+	if err := evm.SetFork(context.Background(), fork); err != nil {
+		t.Fatalf("Error en SetFork: %v", err)
+	}
+	if fork.Info().Height != 42 {
+		t.Errorf("Altura fijada esperada 42, obtenida %d", fork.Info().Height)
+	}
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+
+	stateDB := evm.getStateDB()
+	if balance := stateDB.GetBalance(holder); balance.Uint64() != 1000 || stateDB.GetNonce(holder) != 3 {
+		t.Errorf("Cuenta remota incorrecta: balance %s, nonce %d", balance, stateDB.GetNonce(holder))
+	}
+	if !bytes.Equal(stateDB.GetCode(contract), code) {
+		t.Errorf("Code remoto incorrecto: %x", stateDB.GetCode(contract))
+	}
+	if value := stateDB.GetState(contract, slot); value.Big().Int64() != 7 {
+		t.Errorf("Slot remoto esperado 7, obtenido %s", value.Hex())
+	}
+
+	// Las lecturas siguientes salen de storage, sin consultar la red remota
+	calls := backend.calls.Load()
+	state, err := evm.GetCommittedState(holder.Hex())
+	if err != nil {
+		t.Fatalf("Error leyendo estado confirmado: %v", err)
+	}
+	if state.Balance != "1000" || backend.calls.Load() != calls {
+		t.Errorf("Se esperaba la cuenta guardada sin nuevas consultas: balance %s, consultas %d -> %d", state.Balance, calls, backend.calls.Load())
+	}
+
+	// Lo borrado localmente no vuelve a leerse de la red remota
+	stateDB.SetState(contract, slot, common.Hash{})
+	stateDB.SelfDestruct(holder)
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+	stateDB = evm.getStateDB()
+	if value := stateDB.GetState(contract, slot); value != (common.Hash{}) {
+		t.Errorf("Slot borrado leído de la red remota: %s", value.Hex())
+	}
+	if stateDB.Exist(holder) {
+		t.Error("Cuenta destruida leída de la red remota")
+	}
+	if !bytes.Equal(stateDB.GetCode(contract), code) {
+		t.Errorf("Code del contrato perdido después del commit: %x", stateDB.GetCode(contract))
+	}
+
+	// El directorio de datos queda fijado a la red y altura del fork
+	if err := NewEVMExecutor(db).SetFork(context.Background(), NewFork(backend, "http://otro", 0)); err == nil {
+		t.Error("Se esperaba error al reutilizar el directorio con otra red remota")
+	}
+}
@@ -0,0 +1,382 @@
+package execution
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Valores por defecto del profiling de gas
+const (
+	DefaultGasProfileBlocks   = 100 // Bloques recientes que se conservan
+	gasProfileTopContracts    = 20  // Contratos con más gas que se reportan por bloque
+	gasCategoryFailed         = "failed"
+	gasCategoryPrecompilePref = "precompile:"
+)
+
+// GasCategoryStats es el gas, la cantidad de ejecuciones y el tiempo acumulados de una categoría
+// El gas es determinista (igual en todos los nodos); el tiempo es el de este nodo
+type GasCategoryStats struct {
+	Gas    uint64 `json:"gas"`
+	Count  uint64 `json:"count"`  // Opcodes ejecutados o llamadas al precompile
+	TimeNs int64  `json:"timeNs"` // Tiempo de ejecución (reloj de pared)
+}
+
+// ContractGas es el gas de opcodes consumido por el código de un contrato
+type ContractGas struct {
+	Address string `json:"address"`
+	Gas     uint64 `json:"gas"`
+}
+
+// GasProfile es el gas de un bloque (o de varios) agregado por categoría de opcode y precompile
+// "failed" es el gas quemado por llamadas que fallaron sin revert (out of gas, opcode inválido)
+type GasProfile struct {
+	Height       uint64                       `json:"height,omitempty"`
+	TxCount      int                          `json:"txCount"`
+	GasUsed      uint64                       `json:"gasUsed"`      // Gas cobrado a las transacciones (neto de reembolsos)
+	IntrinsicGas uint64                       `json:"intrinsicGas"` // Costo base de las transacciones (21000, calldata, access list)
+	RefundedGas  uint64                       `json:"refundedGas"`
+	Categories   map[string]*GasCategoryStats `json:"categories"`
+	TopContracts []ContractGas                `json:"topContracts"`
+}
+
+// GasProfileReport es el estado del profiling: los bloques recientes y el total desde que se activó
+type GasProfileReport struct {
+	Enabled bool         `json:"enabled"`
+	Since   time.Time    `json:"since,omitempty"`
+	Total   GasProfile   `json:"total"`
+	Blocks  []GasProfile `json:"blocks"` // El más reciente primero
+}
+
+// gasProfileAcc acumula el profiling de un bloque o del total
+type gasProfileAcc struct {
+	height     uint64
+	txCount    int
+	gasUsed    uint64
+	intrinsic  uint64
+	refunded   uint64
+	categories map[string]*GasCategoryStats
+	contracts  map[common.Address]uint64
+}
+
+func newGasProfileAcc(height uint64) *gasProfileAcc {
+	return &gasProfileAcc{
+		height:     height,
+		categories: make(map[string]*GasCategoryStats),
+		contracts:  make(map[common.Address]uint64),
+	}
+}
+
+// add suma el profiling de una transacción
+func (a *gasProfileAcc) add(tx *txGasProfile, gasUsed uint64) {
+	a.txCount++
+	a.gasUsed += gasUsed
+	a.intrinsic += tx.intrinsic
+	a.refunded += tx.refunded
+	for name, stats := range tx.categories {
+		acc := a.categories[name]
+		if acc == nil {
+			acc = &GasCategoryStats{}
+			a.categories[name] = acc
+		}
+		acc.Gas += stats.Gas
+		acc.Count += stats.Count
+		acc.TimeNs += stats.TimeNs
+	}
+	for addr, gas := range tx.contracts {
+		a.contracts[addr] += gas
+	}
+}
+
+// snapshot copia el acumulado al formato del reporte
+func (a *gasProfileAcc) snapshot() GasProfile {
+	profile := GasProfile{
+		Height:       a.height,
+		TxCount:      a.txCount,
+		GasUsed:      a.gasUsed,
+		IntrinsicGas: a.intrinsic,
+		RefundedGas:  a.refunded,
+		Categories:   make(map[string]*GasCategoryStats, len(a.categories)),
+		TopContracts: make([]ContractGas, 0, len(a.contracts)),
+	}
+	for name, stats := range a.categories {
+		copied := *stats
+		profile.Categories[name] = &copied
+	}
+	for addr, gas := range a.contracts {
+		profile.TopContracts = append(profile.TopContracts, ContractGas{Address: addr.Hex(), Gas: gas})
+	}
+	sort.Slice(profile.TopContracts, func(i, j int) bool {
+		if profile.TopContracts[i].Gas != profile.TopContracts[j].Gas {
+			return profile.TopContracts[i].Gas > profile.TopContracts[j].Gas
+		}
+		return profile.TopContracts[i].Address < profile.TopContracts[j].Address
+	})
+	if len(profile.TopContracts) > gasProfileTopContracts {
+		profile.TopContracts = profile.TopContracts[:gasProfileTopContracts]
+	}
+	return profile
+}
+
+// GasProfiler agrega el gas y el tiempo de las transacciones de cada bloque por categoría de opcode y precompile
+// Está desactivado por defecto: activo, cada transacción se ejecuta con hooks de tracing (más lenta)
+type GasProfiler struct {
+	enabled   atomic.Bool
+	maxBlocks int
+	blocks    []*gasProfileAcc // Bloques recientes, el más reciente al final
+	total     *gasProfileAcc
+	since     time.Time
+	mu        sync.Mutex
+}
+
+// NewGasProfiler crea un profiler desactivado que conserva maxBlocks bloques (0 usa DefaultGasProfileBlocks)
+func NewGasProfiler(maxBlocks int) *GasProfiler {
+	if maxBlocks <= 0 {
+		maxBlocks = DefaultGasProfileBlocks
+	}
+	return &GasProfiler{maxBlocks: maxBlocks}
+}
+
+// GasProfiler retorna el profiler de gas del ejecutor
+func (e *EVMExecutor) GasProfiler() *GasProfiler {
+	return e.profiler
+}
+
+// SetEnabled activa o desactiva el profiling; al activarlo se descarta lo acumulado antes
+func (p *GasProfiler) SetEnabled(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if enabled && !p.enabled.Load() {
+		p.blocks = nil
+		p.total = newGasProfileAcc(0)
+		p.since = time.Now()
+	}
+	p.enabled.Store(enabled)
+}
+
+// Enabled indica si el profiling está activo
+func (p *GasProfiler) Enabled() bool {
+	return p != nil && p.enabled.Load()
+}
+
+// Report retorna los bloques recientes y el total acumulado
+func (p *GasProfiler) Report() GasProfileReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	report := GasProfileReport{
+		Enabled: p.enabled.Load(),
+		Since:   p.since,
+		Blocks:  make([]GasProfile, 0, len(p.blocks)),
+	}
+	if p.total != nil {
+		report.Total = p.total.snapshot()
+	}
+	for i := len(p.blocks) - 1; i >= 0; i-- {
+		report.Blocks = append(report.Blocks, p.blocks[i].snapshot())
+	}
+	return report
+}
+
+// record suma el profiling de una transacción ejecutada en el bloque height
+func (p *GasProfiler) record(height uint64, tx *txGasProfile, gasUsed uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.enabled.Load() {
+		return
+	}
+
+	var block *gasProfileAcc
+	if n := len(p.blocks); n > 0 && p.blocks[n-1].height == height {
+		block = p.blocks[n-1]
+	} else {
+		block = newGasProfileAcc(height)
+		p.blocks = append(p.blocks, block)
+		if len(p.blocks) > p.maxBlocks {
+			p.blocks = p.blocks[len(p.blocks)-p.maxBlocks:]
+		}
+	}
+	block.add(tx, gasUsed)
+	p.total.add(tx, gasUsed)
+}
+
+// newTx crea el colector de una transacción con los precompiles activos en el bloque
+func (p *GasProfiler) newTx(chainConfig *params.ChainConfig, blockContext vm.BlockContext) *txGasProfile {
+	rules := chainConfig.Rules(blockContext.BlockNumber, blockContext.Random != nil, blockContext.Time)
+	precompiles := make(map[common.Address]string)
+	for addr, contract := range vm.ActivePrecompiledContracts(rules) {
+		precompiles[addr] = gasCategoryPrecompilePref + contract.Name()
+	}
+	return &txGasProfile{
+		precompiles: precompiles,
+		categories:  make(map[string]*GasCategoryStats),
+		contracts:   make(map[common.Address]uint64),
+	}
+}
+
+// txGasProfile recolecta el gas y el tiempo de una transacción desde los hooks de tracing de la EVM
+// El costo que la EVM reporta para CALL/CALLCODE/DELEGATECALL/STATICCALL incluye el gas enviado a la llamada;
+// ese gas se descuenta al entrar, porque lo cuentan los opcodes del contrato llamado
+type txGasProfile struct {
+	precompiles map[common.Address]string
+	categories  map[string]*GasCategoryStats
+	contracts   map[common.Address]uint64
+	intrinsic   uint64
+	refunded    uint64
+
+	lastCategory string // Categoría del último opcode, cuyo tiempo corre hasta el siguiente evento
+	lastStart    time.Time
+	pendingCall  string // Categoría del CALL que está por entrar (para descontar el gas enviado)
+	pendingAddr  common.Address
+	frames       []txGasFrame
+}
+
+// txGasFrame es una llamada en curso; precompile no está vacío si la llamada es a un precompile
+type txGasFrame struct {
+	precompile string
+	start      time.Time
+}
+
+// hooks retorna los hooks de tracing que alimentan al colector
+func (t *txGasProfile) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnOpcode:    t.onOpcode,
+		OnEnter:     t.onEnter,
+		OnExit:      t.onExit,
+		OnGasChange: t.onGasChange,
+	}
+}
+
+// stats retorna las estadísticas de una categoría
+func (t *txGasProfile) stats(category string) *GasCategoryStats {
+	stats := t.categories[category]
+	if stats == nil {
+		stats = &GasCategoryStats{}
+		t.categories[category] = stats
+	}
+	return stats
+}
+
+// closeOpcode cierra el tiempo del último opcode
+func (t *txGasProfile) closeOpcode(now time.Time) {
+	if t.lastCategory != "" {
+		t.stats(t.lastCategory).TimeNs += now.Sub(t.lastStart).Nanoseconds()
+		t.lastCategory = ""
+	}
+}
+
+func (t *txGasProfile) onOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	now := time.Now()
+	t.closeOpcode(now)
+	// Un opcode que falla no cobra su costo: lo que quede del gas se quema y se cuenta como "failed"
+	if err != nil {
+		return
+	}
+
+	category := opcodeCategory(vm.OpCode(op))
+	stats := t.stats(category)
+	stats.Gas += cost
+	stats.Count++
+	t.contracts[scope.Address()] += cost
+	t.lastCategory, t.lastStart = category, now
+
+	switch vm.OpCode(op) {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.pendingCall, t.pendingAddr = category, scope.Address()
+	}
+}
+
+func (t *txGasProfile) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	now := time.Now()
+	t.closeOpcode(now)
+
+	if t.pendingCall != "" {
+		// El stipend de una llamada con valor lo agrega la EVM, no lo paga quien llama
+		forwarded := gas
+		if (vm.OpCode(typ) == vm.CALL || vm.OpCode(typ) == vm.CALLCODE) && value != nil && value.Sign() > 0 {
+			forwarded -= min(forwarded, params.CallStipend)
+		}
+		stats := t.stats(t.pendingCall)
+		stats.Gas -= min(stats.Gas, forwarded)
+		t.contracts[t.pendingAddr] -= min(t.contracts[t.pendingAddr], forwarded)
+		t.pendingCall = ""
+	}
+
+	frame := txGasFrame{start: now}
+	if name, ok := t.precompiles[to]; ok {
+		frame.precompile = name
+		t.stats(name).Count++
+	}
+	t.frames = append(t.frames, frame)
+}
+
+func (t *txGasProfile) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	now := time.Now()
+	t.closeOpcode(now)
+	if len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	if frame.precompile != "" {
+		t.stats(frame.precompile).TimeNs += now.Sub(frame.start).Nanoseconds()
+	}
+}
+
+func (t *txGasProfile) onGasChange(old, new uint64, reason tracing.GasChangeReason) {
+	switch reason {
+	case tracing.GasChangeTxIntrinsicGas:
+		t.intrinsic += old - new
+	case tracing.GasChangeTxRefunds:
+		t.refunded += new - old
+	case tracing.GasChangeCallPrecompiledContract:
+		if n := len(t.frames); n > 0 && t.frames[n-1].precompile != "" {
+			t.stats(t.frames[n-1].precompile).Gas += old - new
+		}
+	case tracing.GasChangeCallCodeStorage:
+		t.stats("create").Gas += old - new
+	case tracing.GasChangeCallFailedExecution:
+		t.stats(gasCategoryFailed).Gas += old - new
+	}
+}
+
+// opcodeCategory agrupa los opcodes por el tipo de recurso que consumen
+func opcodeCategory(op vm.OpCode) string {
+	switch {
+	case op >= vm.ADD && op <= vm.SIGNEXTEND:
+		return "arithmetic"
+	case op >= vm.LT && op <= vm.SAR:
+		return "bitwise"
+	case op == vm.KECCAK256:
+		return "keccak"
+	case op == vm.BALANCE || op == vm.EXTCODESIZE || op == vm.EXTCODECOPY || op == vm.EXTCODEHASH || op == vm.SELFBALANCE:
+		return "account"
+	case op >= vm.ADDRESS && op <= vm.BLOBBASEFEE:
+		return "environment"
+	case op == vm.SLOAD || op == vm.SSTORE:
+		return "storage"
+	case op == vm.TLOAD || op == vm.TSTORE:
+		return "transientStorage"
+	case op == vm.MLOAD || op == vm.MSTORE || op == vm.MSTORE8 || op == vm.MSIZE || op == vm.MCOPY:
+		return "memory"
+	case op == vm.POP || (op >= vm.PUSH0 && op <= vm.SWAP16):
+		return "stack"
+	case op >= vm.LOG0 && op <= vm.LOG4:
+		return "log"
+	case op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL:
+		return "call"
+	case op == vm.CREATE || op == vm.CREATE2:
+		return "create"
+	case op == vm.SELFDESTRUCT:
+		return "selfdestruct"
+	default: // STOP, JUMP, JUMPI, PC, GAS, JUMPDEST, RETURN, REVERT, INVALID
+		return "control"
+	}
+}
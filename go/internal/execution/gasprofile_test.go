@@ -0,0 +1,133 @@
+package execution
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// testOpContext es el contexto mínimo de un opcode: solo la dirección del contrato en ejecución
+type testOpContext struct {
+	address common.Address
+}
+
+func (c testOpContext) MemoryData() []byte       { return nil }
+func (c testOpContext) StackData() []uint256.Int { return nil }
+func (c testOpContext) Caller() common.Address   { return common.Address{} }
+func (c testOpContext) Address() common.Address  { return c.address }
+func (c testOpContext) CallValue() *uint256.Int  { return new(uint256.Int) }
+func (c testOpContext) CallInput() []byte        { return nil }
+func (c testOpContext) ContractCode() []byte     { return nil }
+
+// TestGasProfileCategories verifica el gas por categoría: el gas enviado por CALL se descuenta,
+// los precompiles y las llamadas fallidas se cuentan aparte
+func TestGasProfileCategories(t *testing.T) {
+	caller := testOpContext{common.HexToAddress("0x1000")}
+	callee := testOpContext{common.HexToAddress("0x2000")}
+	ecrecover := common.BytesToAddress([]byte{1})
+
+	profiler := NewGasProfiler(2)
+	profiler.SetEnabled(true)
+	tx := &txGasProfile{
+		precompiles: map[common.Address]string{ecrecover: gasCategoryPrecompilePref + "ecrecover"},
+		categories:  make(map[string]*GasCategoryStats),
+		contracts:   make(map[common.Address]uint64),
+	}
+	hooks := tx.hooks()
+
+	hooks.OnGasChange(100000, 79000, tracing.GasChangeTxIntrinsicGas)
+	hooks.OnEnter(0, byte(vm.CALL), common.Address{}, caller.address, nil, 79000, big.NewInt(0))
+	hooks.OnOpcode(0, byte(vm.PUSH1), 79000, 3, caller, nil, 1, nil)
+	hooks.OnOpcode(2, byte(vm.SSTORE), 78997, 20000, caller, nil, 1, nil)
+	// CALL con valor: el costo reportado incluye los 10000 de gas enviado; el stipend lo agrega la EVM
+	hooks.OnOpcode(4, byte(vm.CALL), 58997, 9000+10000, caller, nil, 1, nil)
+	hooks.OnEnter(1, byte(vm.CALL), caller.address, callee.address, nil, 10000+params.CallStipend, big.NewInt(1))
+	hooks.OnOpcode(0, byte(vm.SLOAD), 12300, 2100, callee, nil, 2, nil)
+	hooks.OnOpcode(1, byte(vm.INVALID), 10200, 0, callee, nil, 2, &vm.ErrInvalidOpCode{})
+	hooks.OnGasChange(10200, 0, tracing.GasChangeCallFailedExecution)
+	hooks.OnExit(1, nil, 12300, &vm.ErrInvalidOpCode{}, true)
+	// STATICCALL a un precompile
+	hooks.OnOpcode(5, byte(vm.STATICCALL), 39997, 100+3000, caller, nil, 1, nil)
+	hooks.OnEnter(1, byte(vm.STATICCALL), caller.address, ecrecover, nil, 3000, nil)
+	hooks.OnGasChange(3000, 0, tracing.GasChangeCallPrecompiledContract)
+	hooks.OnExit(1, nil, 3000, nil, false)
+	hooks.OnGasChange(0, 4800, tracing.GasChangeTxRefunds)
+	hooks.OnExit(0, nil, 42000, nil, false)
+
+	profiler.record(7, tx, 55000)
+	profiler.record(8, tx, 55000)
+	profiler.record(9, tx, 55000)
+
+	report := profiler.Report()
+	if len(report.Blocks) != 2 || report.Blocks[0].Height != 9 || report.Blocks[1].Height != 8 {
+		t.Fatalf("Se esperaban los 2 bloques más recientes: %+v", report.Blocks)
+	}
+	if report.Total.TxCount != 3 || report.Total.GasUsed != 165000 {
+		t.Errorf("Total incorrecto: %+v", report.Total)
+	}
+
+	block := report.Blocks[0]
+	expected := map[string]uint64{
+		"stack":                3,
+		"storage":              20000 + 2100,
+		"call":                 9000 + 100,
+		"failed":               10200,
+		"precompile:ecrecover": 3000,
+	}
+	for name, gas := range expected {
+		if stats := block.Categories[name]; stats == nil || stats.Gas != gas {
+			t.Errorf("Gas de %s incorrecto: esperado %d, obtenido %+v", name, gas, stats)
+		}
+	}
+	if stats := block.Categories["control"]; stats != nil {
+		t.Errorf("El opcode fallido no debería contarse: %+v", stats)
+	}
+	if block.IntrinsicGas != 21000 || block.RefundedGas != 4800 {
+		t.Errorf("Gas intrínseco o reembolso incorrectos: %+v", block)
+	}
+	if len(block.TopContracts) != 2 || block.TopContracts[0].Address != caller.address.Hex() ||
+		block.TopContracts[0].Gas != 3+20000+9000+100 || block.TopContracts[1].Gas != 2100 {
+		t.Errorf("Contratos incorrectos: %+v", block.TopContracts)
+	}
+
+	// Desactivado no registra; al reactivar se descarta lo anterior
+	profiler.SetEnabled(false)
+	profiler.record(10, tx, 55000)
+	profiler.SetEnabled(true)
+	if report := profiler.Report(); len(report.Blocks) != 0 || report.Total.TxCount != 0 {
+		t.Errorf("Al reactivar se esperaba un profiling vacío: %+v", report)
+	}
+}
+
+// TestOpcodeCategory verifica la categoría de algunos opcodes representativos
+func TestOpcodeCategory(t *testing.T) {
+	cases := map[vm.OpCode]string{
+		vm.ADD:          "arithmetic",
+		vm.SHR:          "bitwise",
+		vm.KECCAK256:    "keccak",
+		vm.BALANCE:      "account",
+		vm.SELFBALANCE:  "account",
+		vm.CALLER:       "environment",
+		vm.TIMESTAMP:    "environment",
+		vm.SSTORE:       "storage",
+		vm.TSTORE:       "transientStorage",
+		vm.MCOPY:        "memory",
+		vm.PUSH32:       "stack",
+		vm.SWAP1:        "stack",
+		vm.LOG2:         "log",
+		vm.DELEGATECALL: "call",
+		vm.CREATE2:      "create",
+		vm.JUMPI:        "control",
+		vm.REVERT:       "control",
+	}
+	for op, category := range cases {
+		if got := opcodeCategory(op); got != category {
+			t.Errorf("Categoría de %s incorrecta: esperada %s, obtenida %s", op, category, got)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package execution
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Hardforks de la EVM reportados en /api/v1/node
+const (
+	HardforkLondon   = "london"
+	HardforkShanghai = "shanghai"
+	HardforkCancun   = "cancun"
+)
+
+// HardforkSchedule son las alturas de activación de los hardforks posteriores a London (nil = no se activa)
+// London y los anteriores están activos desde el genesis. Se configuran en app_state del genesis y
+// cambiarlos en una cadena en marcha requiere que todos los nodos actualicen antes de la altura
+type HardforkSchedule struct {
+	ShanghaiHeight *uint64 `json:"shanghaiHeight,omitempty"` // PUSH0, límite de initcode, coinbase caliente
+	CancunHeight   *uint64 `json:"cancunHeight,omitempty"`   // Transient storage (TLOAD/TSTORE), MCOPY, BLOBBASEFEE
+}
+
+// HardforkStatus es un hardfork con su altura de activación y si está activo en una altura dada
+type HardforkStatus struct {
+	Name   string  `json:"name"`
+	Height *uint64 `json:"height"` // nil = no programado
+	Active bool    `json:"active"`
+}
+
+// Validate verifica que el orden de los hardforks sea el de Ethereum (Cancun requiere Shanghai)
+func (h HardforkSchedule) Validate() error {
+	if h.CancunHeight == nil {
+		return nil
+	}
+	if h.ShanghaiHeight == nil {
+		return fmt.Errorf("cancunHeight requiere shanghaiHeight")
+	}
+	if *h.CancunHeight < *h.ShanghaiHeight {
+		return fmt.Errorf("cancunHeight (%d) no puede ser anterior a shanghaiHeight (%d)", *h.CancunHeight, *h.ShanghaiHeight)
+	}
+	return nil
+}
+
+// Status retorna los hardforks, del más antiguo al más reciente, con su estado en height
+func (h HardforkSchedule) Status(height uint64) []HardforkStatus {
+	genesis := uint64(0)
+	return []HardforkStatus{
+		{Name: HardforkLondon, Height: &genesis, Active: true},
+		{Name: HardforkShanghai, Height: h.ShanghaiHeight, Active: forkActive(h.ShanghaiHeight, height)},
+		{Name: HardforkCancun, Height: h.CancunHeight, Active: forkActive(h.CancunHeight, height)},
+	}
+}
+
+// forkActive retorna si un hardfork programado en activation está activo en height
+func forkActive(activation *uint64, height uint64) bool {
+	return activation != nil && height >= *activation
+}
+
+// SetHardforkSchedule configura las alturas de activación de los hardforks (antes de ejecutar bloques)
+func (e *EVMExecutor) SetHardforkSchedule(schedule HardforkSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return fmt.Errorf("calendario de hardforks inválido: %w", err)
+	}
+	e.hardforks = schedule
+	return nil
+}
+
+// HardforkSchedule retorna las alturas de activación de los hardforks
+func (e *EVMExecutor) HardforkSchedule() HardforkSchedule {
+	return e.hardforks
+}
+
+// chainConfigAt retorna la configuración de la chain para un bloque. go-ethereum activa Shanghai y
+// Cancun por timestamp: se marcan activos desde el tiempo 0 en los bloques a partir de su altura
+func (e *EVMExecutor) chainConfigAt(height uint64) *params.ChainConfig {
+	config := *e.chainConfig
+	activeSince := uint64(0)
+	if forkActive(e.hardforks.ShanghaiHeight, height) {
+		config.ShanghaiTime = &activeSince
+	}
+	if forkActive(e.hardforks.CancunHeight, height) {
+		config.CancunTime = &activeSince
+		// BLOBBASEFEE necesita la configuración de blobs (sin transacciones de blobs el fee queda en el mínimo)
+		config.BlobScheduleConfig = &params.BlobScheduleConfig{Cancun: params.DefaultCancunBlobConfig}
+	}
+	return &config
+}
@@ -0,0 +1,97 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+)
+
+// TestHardforkSchedule_Validate prueba el orden obligatorio de los hardforks
+func TestHardforkSchedule_Validate(t *testing.T) {
+	height := func(h uint64) *uint64 { return &h }
+
+	valid := []HardforkSchedule{
+		{},
+		{ShanghaiHeight: height(0)},
+		{ShanghaiHeight: height(10), CancunHeight: height(10)},
+	}
+	for _, schedule := range valid {
+		if err := schedule.Validate(); err != nil {
+			t.Errorf("Calendario válido rechazado %+v: %v", schedule, err)
+		}
+	}
+
+	invalid := []HardforkSchedule{
+		{CancunHeight: height(0)},
+		{ShanghaiHeight: height(20), CancunHeight: height(10)},
+	}
+	for _, schedule := range invalid {
+		if err := schedule.Validate(); err == nil {
+			t.Errorf("Calendario inválido aceptado: %+v", schedule)
+		}
+	}
+
+	status := HardforkSchedule{ShanghaiHeight: height(5)}.Status(5)
+	if len(status) != 3 || !status[0].Active || !status[1].Active || status[2].Active || status[2].Height != nil {
+		t.Errorf("Estado de hardforks incorrecto: %+v", status)
+	}
+}
+
+// TestEVMExecutor_HardforkActivation prueba que PUSH0 y TSTORE solo estén disponibles desde su altura de activación
+func TestEVMExecutor_HardforkActivation(t *testing.T) {
+	testDir := createTestDir("hardforks")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := NewEVMExecutor(db)
+	shanghai, cancun := uint64(5), uint64(10)
+	if err := evm.SetHardforkSchedule(HardforkSchedule{ShanghaiHeight: &shanghai, CancunHeight: &cancun}); err != nil {
+		t.Fatalf("Error configurando hardforks: %v", err)
+	}
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+
+	push0 := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	tstore := common.HexToAddress("0x00000000000000000000000000000000000000a2")
+	evm.getStateDB().SetCode(push0, []byte{0x5f, 0x00}, tracing.CodeChangeUnspecified)              // PUSH0 STOP
+	evm.getStateDB().SetCode(tstore, []byte{0x5f, 0x5f, 0x5d, 0x00}, tracing.CodeChangeUnspecified) // PUSH0 PUSH0 TSTORE STOP
+	// CallContract lee el último estado confirmado
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+	from := "0x00000000000000000000000000000000000000b1"
+
+	cases := []struct {
+		height uint64
+		push0  bool
+		tstore bool
+	}{
+		{height: 1, push0: false, tstore: false},
+		{height: 5, push0: true, tstore: false},
+		{height: 10, push0: true, tstore: true},
+	}
+	for _, tc := range cases {
+		evm.SetCurrentBlockInfo(tc.height, 1700000000)
+		_, err := evm.CallContract(from, push0.Hex(), nil, 100000)
+		if (err == nil) != tc.push0 {
+			t.Errorf("PUSH0 en la altura %d: esperado disponible=%v, error=%v", tc.height, tc.push0, err)
+		}
+		_, err = evm.CallContract(from, tstore.Hex(), nil, 100000)
+		if (err == nil) != tc.tstore {
+			t.Errorf("TSTORE en la altura %d: esperado disponible=%v, error=%v", tc.height, tc.tstore, err)
+		}
+	}
+}
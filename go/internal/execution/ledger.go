@@ -0,0 +1,84 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Ledger resume los balances de un estado confirmado para las verificaciones de invariantes
+type Ledger struct {
+	Root          string
+	Accounts      uint64
+	TotalBalances *big.Int                    // Suma de los balances de todas las cuentas
+	Minted        *big.Int                    // Contador de emisión en ese estado
+	Burned        *big.Int                    // Contador de quemado en ese estado
+	MaxBalance    *big.Int                    // Mayor balance de una cuenta (un underflow lo deja enorme)
+	MaxBalanceKey common.Hash                 // Clave en el trie (hash de la dirección) de la cuenta con MaxBalance
+	Balances      map[common.Address]*big.Int // Balance de las direcciones pedidas
+}
+
+// Ledger recorre las cuentas del estado confirmado en root sumando sus balances, y lee del mismo
+// root los contadores de emisión y el balance de addresses. No usa el StateDB del bloque en curso,
+// por lo que puede llamarse desde otra goroutine mientras se ejecuta un bloque
+func (sm *StateManager) Ledger(root common.Hash, addresses []common.Address) (*Ledger, error) {
+	database := sm.stateDatabase()
+	if database == nil {
+		return nil, fmt.Errorf("estado EVM no está cargado")
+	}
+	if root == (common.Hash{}) {
+		root = types.EmptyRootHash
+	}
+
+	reader, err := state.New(root, database)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo estado %s: %w", root.Hex(), err)
+	}
+	ledger := &Ledger{
+		Root:          root.Hex(),
+		TotalBalances: new(big.Int),
+		MaxBalance:    new(big.Int),
+		Minted:        reader.GetState(supplyAccount, mintedSlot).Big(),
+		Burned:        reader.GetState(supplyAccount, burnedSlot).Big(),
+		Balances:      make(map[common.Address]*big.Int, len(addresses)),
+	}
+	for _, addr := range addresses {
+		ledger.Balances[addr] = reader.GetBalance(addr).ToBig()
+	}
+	if root == types.EmptyRootHash {
+		return ledger, nil
+	}
+
+	accountTrie, err := trie.NewStateTrie(trie.StateTrieID(root), database.TrieDB())
+	if err != nil {
+		return nil, fmt.Errorf("root %s no encontrado: %w", root.Hex(), err)
+	}
+	it, err := accountTrie.NodeIterator(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error recorriendo trie de cuentas: %w", err)
+	}
+	for it.Next(true) {
+		if !it.Leaf() {
+			continue
+		}
+		account, err := types.FullAccount(it.LeafBlob())
+		if err != nil {
+			return nil, fmt.Errorf("cuenta %x no decodificable: %w", it.LeafKey(), err)
+		}
+		ledger.Accounts++
+		balance := account.Balance.ToBig()
+		ledger.TotalBalances.Add(ledger.TotalBalances, balance)
+		if balance.Cmp(ledger.MaxBalance) > 0 {
+			ledger.MaxBalance = balance
+			ledger.MaxBalanceKey = common.BytesToHash(it.LeafKey())
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("trie de cuentas incompleto: %w", err)
+	}
+	return ledger, nil
+}
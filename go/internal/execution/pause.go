@@ -0,0 +1,63 @@
+package execution
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// PausedContractsAddress es la cuenta de sistema cuyo storage marca los contratos pausados
+// Como el registro de deployers, está en el estado: la pausa es la misma en todos los nodos
+const PausedContractsAddress = "0x0000000000000000000000000000000000000107"
+
+var pausedContracts = common.HexToAddress(PausedContractsAddress)
+
+// pausedSlot es el slot que marca la pausa de un contrato (como un mapping de Solidity en el slot 0)
+func pausedSlot(addr common.Address) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(addr.Bytes(), 32), make([]byte, 32))
+}
+
+// contractPaused indica si un contrato está pausado en un StateDB
+func contractPaused(stateDB *state.StateDB, addr common.Address) bool {
+	return stateDB.GetState(pausedContracts, pausedSlot(addr)) != (common.Hash{})
+}
+
+// SetContractPaused pausa o reanuda un contrato en el bloque en ejecución
+func (e *EVMExecutor) SetContractPaused(address string, paused bool) error {
+	if !e.running {
+		return fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("dirección inválida: %s", address)
+	}
+	value := uint64(0)
+	if paused {
+		value = 1
+	}
+	setSystemSlot(e.getStateDB(), pausedContracts, pausedSlot(common.HexToAddress(address)), value)
+	return nil
+}
+
+// ContractPaused indica si un contrato está pausado en el bloque en ejecución
+func (e *EVMExecutor) ContractPaused(address string) bool {
+	stateDB := e.getStateDB()
+	if stateDB == nil || !common.IsHexAddress(address) {
+		return false
+	}
+	return contractPaused(stateDB, common.HexToAddress(address))
+}
+
+// IsCommittedContractPaused indica si un contrato está pausado en el último estado confirmado
+// (para CheckTx y consultas del API)
+func (e *EVMExecutor) IsCommittedContractPaused(address string) (bool, error) {
+	if !e.running {
+		return false, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	stateDB, err := e.stateManager.CommittedState()
+	if err != nil {
+		return false, err
+	}
+	return contractPaused(stateDB, common.HexToAddress(address)), nil
+}
@@ -0,0 +1,48 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/holiman/uint256"
+)
+
+// Transacciones patrocinadas: la EVM cobra el gas a msg.From, así que el fee payer adelanta
+// gasLimit * gasPrice al remitente antes de ejecutar y recupera el gas no usado después. El remitente
+// termina pagando solo value y el fee payer el gas usado
+
+// prefundGas transfiere gasLimit * gasPrice del fee payer al remitente
+func prefundGas(stateDB *state.StateDB, payer, sender common.Address, gasLimit uint64, gasPrice *big.Int) error {
+	amount, overflow := uint256.FromBig(new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasPrice))
+	if overflow {
+		return fmt.Errorf("costo de gas fuera de rango")
+	}
+	if balance := stateDB.GetBalance(payer); balance.Cmp(amount) < 0 {
+		return fmt.Errorf("balance insuficiente del fee payer %s: tiene %s, necesita %s", payer.Hex(), balance, amount)
+	}
+	if amount.IsZero() {
+		return nil
+	}
+	stateDB.SubBalance(payer, amount, tracing.BalanceChangeTransfer)
+	stateDB.AddBalance(sender, amount, tracing.BalanceChangeTransfer)
+	return nil
+}
+
+// refundUnusedGas devuelve al fee payer el gas no usado, que la EVM reintegró al remitente
+func refundUnusedGas(stateDB *state.StateDB, payer, sender common.Address, gasLimit, gasUsed uint64, gasPrice *big.Int) {
+	if gasUsed >= gasLimit {
+		return
+	}
+	unused, overflow := uint256.FromBig(new(big.Int).Mul(new(big.Int).SetUint64(gasLimit-gasUsed), gasPrice))
+	if overflow || unused.IsZero() {
+		return
+	}
+	if balance := stateDB.GetBalance(sender); balance.Cmp(unused) < 0 {
+		unused = balance.Clone()
+	}
+	stateDB.SubBalance(sender, unused, tracing.BalanceChangeTransfer)
+	stateDB.AddBalance(payer, unused, tracing.BalanceChangeTransfer)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -13,121 +14,221 @@ import (
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/hashdb"
 	"github.com/ethereum/go-ethereum/ethdb"
 	ethdbpebble "github.com/ethereum/go-ethereum/ethdb/pebble"
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
 )
 
+// StateDBConfig configura la memoria de la base de datos del estado EVM (tamaños en MB)
+type StateDBConfig struct {
+	TrieCacheMB     int // Nodos del trie leídos de disco que se mantienen en memoria (0 = sin cache)
+	DatabaseCacheMB int // Cache de Pebble; la mitad se reparte entre sus 4 memtables (mínimo 16)
+	DatabaseHandles int // Archivos que Pebble mantiene abiertos (mínimo 16)
+	SnapshotCacheMB int // Cache de lecturas de cuentas y slots del snapshot
+}
+
+// DefaultStateDBConfig son los valores usados antes de que fueran configurables
+var DefaultStateDBConfig = StateDBConfig{
+	TrieCacheMB:     0,
+	DatabaseCacheMB: 16,
+	DatabaseHandles: 16,
+	SnapshotCacheMB: 256,
+}
+
+// SetStateDBConfig configura la memoria de la base de datos del estado EVM. Debe llamarse antes de Start
+func (e *EVMExecutor) SetStateDBConfig(config StateDBConfig) {
+	e.stateManager.dbConfig = config
+}
+
+// trieConfig registra las preimágenes de las claves del trie (keccak de direcciones y slots) para poder
+// recuperar las direcciones al recorrer el estado (DumpState)
+func (c StateDBConfig) trieConfig() *triedb.Config {
+	return &triedb.Config{
+		Preimages: true,
+		HashDB:    &hashdb.Config{CleanCacheSize: c.TrieCacheMB * 1024 * 1024},
+	}
+}
+
 // StateManager maneja el estado de la blockchain EVM
 type StateManager struct {
-	storage    *storage.BlockchainDB
-	stateDB    *state.StateDB
-	database   state.Database
-	pebbleDB   *ethdbpebble.Database // Guardar referencia a Pebble DB para cerrarlo correctamente
-	stateRoot  common.Hash
-	dataDir    string
+	storage   *storage.BlockchainDB
+	stateDB   *state.StateDB
+	pebbleDB  *ethdbpebble.Database          // Guardar referencia a Pebble DB para cerrarlo correctamente
+	committed atomic.Pointer[committedState] // Último estado confirmado, del que leen las consultas
+	cache     *stateCache                    // Cuentas y slots del estado confirmado (GetCommittedState)
+	pipeline  *commitPipeline                // Escritura en disco de los tries confirmados, en segundo plano
+	dbConfig  StateDBConfig
+	dataDir   string
+	fork      *Fork // Estado remoto que completa el local (modo desarrollo, nil = sin fork)
+
+	commitQueue int // Commits que pueden esperar su escritura en disco (0 = escribir dentro de SaveState)
+
+	// Altura del estado retomado por LoadState cuando el último root confirmado no estaba en disco
+	recoveredHeight uint64
+	recovered       bool
+}
+
+// committedState es una referencia inmutable al último estado confirmado: su root y la base de datos de
+// la que se lee. Se reemplaza entera en cada commit, así que una consulta nunca combina el root de un
+// bloque con la base de datos de otro ni ve el StateDB que modifica el bloque en ejecución
+type committedState struct {
+	root     common.Hash
+	height   uint64 // Bloque cuyo estado es el root (0 = desconocido)
+	database state.Database
 }
 
 // NewStateManager crea un nuevo gestor de estado
 func NewStateManager(storage *storage.BlockchainDB, dataDir string) *StateManager {
 	return &StateManager{
 		storage: storage,
-		dataDir: dataDir,
+		cache:    newStateCache(DefaultStateCacheAccounts, DefaultStateCacheSlots),
+		dbConfig: DefaultStateDBConfig,
+		dataDir:  dataDir,
+
+		commitQueue: DefaultStateCommitQueue,
 	}
 }
 
 // LoadState carga el estado desde storage
+// La base de datos Pebble se abre una sola vez; cada carga crea un triedb nuevo sobre ella
+// (ej: después de ImportKV, para no conservar nodos en cache del estado anterior)
 func (sm *StateManager) LoadState() (*state.StateDB, error) {
-	// Crear base de datos para StateDB usando Pebble
-	stateDBPath := filepath.Join(sm.dataDir, "evm_state")
+	if err := sm.openDatabase(); err != nil {
+		return nil, err
+	}
+	if sm.pipeline == nil {
+		sm.pipeline = newCommitPipeline(sm.commitQueue, writeRoot)
+	}
+	database, err := sm.newStateDatabase()
+	if err != nil {
+		return nil, err
+	}
+	previous := sm.stateDatabase()
 	
-	// Cerrar base de datos anterior si existe
-	if sm.pebbleDB != nil {
-		if err := sm.pebbleDB.Close(); err != nil {
-			// Log pero no fallar si hay error al cerrar
+	// Intentar cargar root hash guardado
+	stateData, err := sm.storage.GetState()
+	var root common.Hash
+	var height uint64
+	
+	if err == nil && stateData != nil {
+		// Parsear estado guardado
+		var stateInfo map[string]interface{}
+		if err := json.Unmarshal(stateData, &stateInfo); err == nil {
+			if rootStr, ok := stateInfo["root"].(string); ok {
+				root = common.HexToHash(rootStr)
+			}
+			if h, ok := stateInfo["height"].(float64); ok {
+				height = uint64(h)
+			}
 		}
-		sm.pebbleDB = nil
 	}
+
+	// Si el trie del root guardado no llegó a disco se retoma el último completo, y el metadata pasa a
+	// apuntar a él para que storage no indique un estado que no existe
+	durable, durableHeight, recovered := sm.recoverableRoot(root)
+	sm.recoveredHeight, sm.recovered = durableHeight, recovered
+	if recovered {
+		if err := sm.saveStateMetadata(durable, durableHeight); err != nil {
+			return nil, err
+		}
+	}
+	if durable != root {
+		root, height = durable, durableHeight
+	}
+	
+	// Crear StateDB desde root (nueva API v1.16+: solo root y database, sin tercer argumento)
+	// Un root vacío es un estado nuevo
+	stateDB, err := state.New(root, database)
+	if err != nil {
+		return nil, fmt.Errorf("error creando StateDB: %w", err)
+	}
+	
+	sm.stateDB = stateDB
+	sm.setState(root, height, database)
+	
+	// El triedb reemplazado libera su cache de nodos (las lecturas en curso siguen leyendo de disco)
+	if previous != nil {
+		previous.TrieDB().Close()
+	}
+	
+	return stateDB, nil
+}
+
+// openDatabase abre la base de datos Pebble del estado EVM si todavía no está abierta
+// Pebble bloquea el directorio: una segunda instancia sobre el mismo path falla, así que todas las
+// lecturas (incluidas las de roots históricos) comparten este handle
+func (sm *StateManager) openDatabase() error {
+	if sm.pebbleDB != nil {
+		return nil
+	}
+	stateDBPath := filepath.Join(sm.dataDir, "evm_state")
 	
 	// Intentar crear base de datos Ethereum usando Pebble
 	// Si falla por WAL corrupto, limpiar directorio y reintentar
-	db, err := ethdbpebble.New(stateDBPath, 0, 0, "", false)
+	cacheMB, handles := sm.dbConfig.DatabaseCacheMB, sm.dbConfig.DatabaseHandles
+	db, err := ethdbpebble.New(stateDBPath, cacheMB, handles, "", false)
 	if err != nil {
 		// Si hay error, puede ser por WAL corrupto, intentar limpiar y recrear
 		// Nota: En producción esto debería manejarse diferente, pero en tests es útil
 		if err2 := os.RemoveAll(stateDBPath); err2 == nil {
 			// Reintentar después de limpiar
-			db, err = ethdbpebble.New(stateDBPath, 0, 0, "", false)
+			db, err = ethdbpebble.New(stateDBPath, cacheMB, handles, "", false)
 			if err != nil {
-				return nil, fmt.Errorf("error creando base de datos EVM (después de limpieza): %w", err)
+				return fmt.Errorf("error creando base de datos EVM (después de limpieza): %w", err)
 			}
 		} else {
-			return nil, fmt.Errorf("error creando base de datos EVM: %w", err)
+			return fmt.Errorf("error creando base de datos EVM: %w", err)
 		}
 	}
 	
 	// Guardar referencia a Pebble DB para poder cerrarlo correctamente
 	sm.pebbleDB = db
-	
+	return nil
+}
+
+// newStateDatabase crea el triedb, el snapshot tree y el state.Database sobre la base de datos abierta
+func (sm *StateManager) newStateDatabase() (state.Database, error) {
 	// Crear triedb database (nueva API v1.16+: usar rawdb.NewDatabase para envolver pebble)
 	// rawdb.NewDatabase crea un wrapper que implementa ethdb.Database completo
-	ethdbWrapper := rawdb.NewDatabase(db)
-	trieDB := triedb.NewDatabase(ethdbWrapper, &triedb.Config{})
+	ethdbWrapper := rawdb.NewDatabase(sm.pebbleDB)
+	trieDB := triedb.NewDatabase(ethdbWrapper, sm.dbConfig.trieConfig())
 	
 	// Crear snapshot tree (vacío por defecto)
 	snapConfig := snapshot.Config{
-		CacheSize: 256,
+		CacheSize: sm.dbConfig.SnapshotCacheMB,
 	}
-	snapTree, err := snapshot.New(snapConfig, db, trieDB, types.EmptyRootHash)
+	snapTree, err := snapshot.New(snapConfig, sm.pebbleDB, trieDB, types.EmptyRootHash)
 	if err != nil {
-		db.Close()
-		sm.pebbleDB = nil
 		return nil, fmt.Errorf("error creando snapshot tree: %w", err)
 	}
 	
 	// Crear database wrapper para StateDB (nueva API v1.16+)
 	database := state.NewDatabase(trieDB, snapTree)
-	
-	// Intentar cargar root hash guardado
-	stateData, err := sm.storage.GetState()
-	var root common.Hash
-	
-	if err == nil && stateData != nil {
-		// Parsear estado guardado
-		var stateInfo map[string]interface{}
-		if err := json.Unmarshal(stateData, &stateInfo); err == nil {
-			if rootStr, ok := stateInfo["root"].(string); ok {
-				root = common.HexToHash(rootStr)
-			}
-		}
-	}
-	
-	// Si no hay root guardado, usar hash vacío (estado nuevo)
-	if root == (common.Hash{}) {
-		root = common.Hash{}
+	if sm.fork != nil {
+		return sm.fork.wrap(database), nil
 	}
-	
-	// Crear StateDB desde root (nueva API v1.16+: solo root y database, sin tercer argumento)
-	stateDB, err := state.New(root, database)
-	if err != nil {
-		db.Close()
-		sm.pebbleDB = nil
-		return nil, fmt.Errorf("error creando StateDB: %w", err)
-	}
-	
-	sm.stateDB = stateDB
-	sm.database = database
-	sm.stateRoot = root
-	
-	return stateDB, nil
+	return database, nil
 }
 
 // SaveState guarda el estado completo en storage
 func (sm *StateManager) SaveState() error {
+	return sm.saveState(0)
+}
+
+// saveState confirma el estado del bloque height (0 = el último bloque guardado) y encola la escritura de
+// su trie en disco
+func (sm *StateManager) saveState(height uint64) error {
 	if sm.stateDB == nil {
 		return fmt.Errorf("StateDB no está inicializado")
 	}
 	
+	if sm.fork != nil {
+		if err := sm.fork.recordDeletions(sm.stateDB); err != nil {
+			return err
+		}
+	}
+	
 	// Calcular root hash intermedio (commits todos los cambios)
 	root := sm.stateDB.IntermediateRoot(true)
 	
@@ -152,9 +253,12 @@ func (sm *StateManager) SaveState() error {
 	}
 	
 	// Guardar root hash en metadata storage
+	if height == 0 {
+		height = sm.getCurrentHeight()
+	}
 	stateData, err := json.Marshal(map[string]interface{}{
 		"root":      root.Hex(),
-		"height":    sm.getCurrentHeight(),
+		"height":    height,
 		"timestamp": sm.getCurrentTimestamp(),
 	})
 	if err != nil {
@@ -166,19 +270,50 @@ func (sm *StateManager) SaveState() error {
 	}
 	
 	// Actualizar root hash local
-	sm.stateRoot = root
+	sm.setStateRoot(root, height)
 	
+	// El trie queda confirmado en memoria (las consultas ya lo leen); la escritura en disco sigue en
+	// segundo plano y solo espera aquí si hay demasiados commits pendientes
+	return sm.queueFlush(root, height)
+}
+
+// saveStateMetadata guarda en storage el root del estado y la altura del bloque que lo produjo
+func (sm *StateManager) saveStateMetadata(root common.Hash, height uint64) error {
+	stateData, err := json.Marshal(map[string]interface{}{
+		"root":   root.Hex(),
+		"height": height,
+	})
+	if err != nil {
+		return fmt.Errorf("error serializando estado: %w", err)
+	}
+	if err := sm.storage.SaveState(stateData); err != nil {
+		return fmt.Errorf("error guardando estado: %w", err)
+	}
 	return nil
 }
 
+// queueFlush encola la escritura en disco del trie del root confirmado
+func (sm *StateManager) queueFlush(root common.Hash, height uint64) error {
+	if sm.pipeline == nil {
+		return nil
+	}
+	return sm.pipeline.submit(flushRequest{
+		root:     root,
+		height:   height,
+		database: sm.stateDatabase(),
+		disk:     sm.pebbleDB,
+	})
+}
+
 // reloadStateFromRoot recarga el StateDB desde un root hash específico
 func (sm *StateManager) reloadStateFromRoot(root common.Hash) (*state.StateDB, error) {
-	if sm.database == nil {
+	database := sm.stateDatabase()
+	if database == nil {
 		return nil, fmt.Errorf("database no está inicializado")
 	}
 	
 	// Crear nuevo StateDB desde el root committeado
-	newStateDB, err := state.New(root, sm.database)
+	newStateDB, err := state.New(root, database)
 	if err != nil {
 		return nil, fmt.Errorf("error creando StateDB desde root: %w", err)
 	}
@@ -192,6 +327,12 @@ func (sm *StateManager) SaveStateAtHeight(height uint64) error {
 		return fmt.Errorf("StateDB no está inicializado")
 	}
 	
+	if sm.fork != nil {
+		if err := sm.fork.recordDeletions(sm.stateDB); err != nil {
+			return err
+		}
+	}
+	
 	// Calcular root hash
 	root := sm.stateDB.IntermediateRoot(true)
 	
@@ -201,6 +342,14 @@ func (sm *StateManager) SaveStateAtHeight(height uint64) error {
 		return fmt.Errorf("error haciendo commit del StateDB: %w", err)
 	}
 	
+	// Igual que en SaveState: el StateDB confirmado no admite más cambios
+	newStateDB, err := sm.reloadStateFromRoot(root)
+	if err != nil {
+		return fmt.Errorf("error recargando StateDB después de commit: %w", err)
+	}
+	sm.stateDB = newStateDB
+	sm.setStateRoot(root, height)
+	
 	// Guardar estado con altura
 	stateData, err := json.Marshal(map[string]interface{}{
 		"root":   root.Hex(),
@@ -218,10 +367,10 @@ func (sm *StateManager) SaveStateAtHeight(height uint64) error {
 		return fmt.Errorf("error guardando estado en altura %d: %w", height, err)
 	}
 	
-	return nil
+	return sm.queueFlush(root, height)
 }
 
-// LoadStateAtHeight carga el estado en una altura específica
+// LoadStateAtHeight carga el estado en una altura específica como estado actual
 func (sm *StateManager) LoadStateAtHeight(height uint64) (*state.StateDB, error) {
 	// Obtener estado guardado en altura
 	key := fmt.Sprintf("state:%d", height)
@@ -244,51 +393,87 @@ func (sm *StateManager) LoadStateAtHeight(height uint64) (*state.StateDB, error)
 	
 	root := common.HexToHash(rootStr)
 	
-	// Crear StateDB desde root usando la misma estructura que LoadState
-	stateDBPath := filepath.Join(sm.dataDir, "evm_state")
-	
-	// Crear base de datos Ethereum usando Pebble (reemplazo de LevelDB)
-	db, err := ethdbpebble.New(stateDBPath, 0, 0, "", false)
-	if err != nil {
-		return nil, fmt.Errorf("error creando base de datos EVM: %w", err)
-	}
-	
-	// Crear triedb database (nueva API v1.16+: usar rawdb.NewDatabase para envolver pebble)
-	ethdb := rawdb.NewDatabase(db)
-	trieDB := triedb.NewDatabase(ethdb, &triedb.Config{})
-	
-	// Crear snapshot tree (vacío por defecto)
-	snapConfig := snapshot.Config{
-		CacheSize: 256,
+	// Crear StateDB desde root sobre la misma base de datos que LoadState
+	database := sm.stateDatabase()
+	if database == nil {
+		return nil, fmt.Errorf("estado EVM no está cargado")
 	}
-	snapTree, err := snapshot.New(snapConfig, db, trieDB, types.EmptyRootHash)
-	if err != nil {
-		db.Close()
-		return nil, fmt.Errorf("error creando snapshot tree: %w", err)
-	}
-	
-	// Crear database wrapper para StateDB (nueva API v1.16+)
-	database := state.NewDatabase(trieDB, snapTree)
-	
-	// Crear StateDB desde root (nueva API v1.16+: solo root y database)
 	stateDB, err := state.New(root, database)
 	if err != nil {
-		db.Close()
 		return nil, fmt.Errorf("error creando StateDB desde root: %w", err)
 	}
 	
 	sm.stateDB = stateDB
-	sm.database = database
-	sm.stateRoot = root
+	sm.setStateRoot(root, height)
 	
 	return stateDB, nil
 }
 
+// StateAt retorna un StateDB de solo lectura en un root confirmado (ej: el de una altura anterior)
+// Cada llamada crea una instancia independiente del StateDB en uso: se puede leer desde cualquier
+// goroutine mientras se ejecuta un bloque. Los cambios que se le hagan no se confirman nunca
+func (sm *StateManager) StateAt(root common.Hash) (*state.StateDB, error) {
+	database := sm.stateDatabase()
+	if database == nil {
+		return nil, fmt.Errorf("estado EVM no está cargado")
+	}
+	return stateAt(database, root)
+}
+
+// stateAt crea un StateDB independiente en root
+func stateAt(database state.Database, root common.Hash) (*state.StateDB, error) {
+	if root == (common.Hash{}) {
+		root = types.EmptyRootHash
+	}
+	stateDB, err := state.New(root, database)
+	if err != nil {
+		return nil, fmt.Errorf("estado del root %s no disponible: %w", root.Hex(), err)
+	}
+	return stateDB, nil
+}
+
 // GetStateDB retorna el StateDB actual
 func (sm *StateManager) GetStateDB() *state.StateDB {
 	return sm.stateDB
 }
 
+// setStateRoot registra el root del último estado confirmado y vacía el cache de estado
+func (sm *StateManager) setStateRoot(root common.Hash, height uint64) {
+	sm.setState(root, height, sm.stateDatabase())
+}
+
+// setState publica el nuevo estado confirmado (root, altura y base de datos) para las consultas
+func (sm *StateManager) setState(root common.Hash, height uint64, database state.Database) {
+	sm.committed.Store(&committedState{root: root, height: height, database: database})
+	sm.cache.reset(root, database)
+}
+
+// stateDatabase retorna la base de datos del estado (segura desde cualquier goroutine)
+func (sm *StateManager) stateDatabase() state.Database {
+	if committed := sm.committed.Load(); committed != nil {
+		return committed.database
+	}
+	return nil
+}
+
+// CommittedRoot retorna el root del último estado confirmado (seguro desde cualquier goroutine)
+func (sm *StateManager) CommittedRoot() common.Hash {
+	if committed := sm.committed.Load(); committed != nil {
+		return committed.root
+	}
+	return common.Hash{}
+}
+
+// CommittedState retorna un StateDB de solo lectura en el último estado confirmado, para servir consultas
+// (API, eth_call) sin tocar el StateDB que modifica FinalizeBlock
+func (sm *StateManager) CommittedState() (*state.StateDB, error) {
+	committed := sm.committed.Load()
+	if committed == nil || committed.database == nil {
+		return nil, fmt.Errorf("estado EVM no está cargado")
+	}
+	return stateAt(committed.database, committed.root)
+}
+
 // GetRootHash retorna el root hash actual
 func (sm *StateManager) GetRootHash() common.Hash {
 	if sm.stateDB == nil {
@@ -308,6 +493,17 @@ func (sm *StateManager) Close() error {
 		}
 	}
 	
+	// Escribir en disco el trie y las preimágenes que solo están en memoria
+	if err := sm.flushTrie(); err != nil {
+		errs = append(errs, err)
+	}
+	if sm.pipeline != nil {
+		if err := sm.pipeline.close(); err != nil {
+			errs = append(errs, err)
+		}
+		sm.pipeline = nil
+	}
+
 	// Cerrar StateDB primero (cerrar iterators si existen)
 	if sm.stateDB != nil {
 		// Asegurar que no haya iterators activos
@@ -315,13 +511,17 @@ func (sm *StateManager) Close() error {
 	}
 	
 	// Cerrar database (StateDatabase) - esto cierra snapshots
-	if sm.database != nil {
-		if closer, ok := sm.database.(ethdb.Database); ok {
+	if database := sm.stateDatabase(); database != nil {
+		if closer, ok := database.(ethdb.Database); ok {
 			if err := closer.Close(); err != nil {
 				errs = append(errs, fmt.Errorf("error cerrando state database: %w", err))
 			}
 		}
-		sm.database = nil
+		// Liberar el cache de nodos del triedb
+		if err := database.TrieDB().Close(); err != nil {
+			errs = append(errs, fmt.Errorf("error cerrando triedb: %w", err))
+		}
+		sm.committed.Store(nil)
 	}
 	
 	// Cerrar instancia de Pebble DB directamente (esto es crítico)
@@ -371,19 +571,114 @@ func (sm *StateManager) getCurrentTimestamp() int64 {
 	}
 
 	// Parsear bloque para obtener timestamp
-	// El timestamp se guarda como time.Time en el BlockHeader
-	type BlockHeader struct {
-		Timestamp time.Time `json:"timestamp"`
+	header, err := DecodeBlockHeader(blockData)
+	if err != nil {
+		return 0
 	}
-	type Block struct {
-		Header BlockHeader `json:"header"`
+
+	return header.Timestamp
+}
+
+
+// flushTrie escribe en disco los nodos del trie y las preimágenes que solo están en memoria (triedb hashdb)
+// Primero espera las escrituras en segundo plano pendientes
+func (sm *StateManager) flushTrie() error {
+	if sm.pipeline != nil {
+		if err := sm.pipeline.wait(); err != nil {
+			return err
+		}
 	}
-	
-	var block Block
-	if err := json.Unmarshal(blockData, &block); err != nil {
-		return 0
+	committed := sm.committed.Load()
+	if committed == nil || committed.database == nil || sm.pebbleDB == nil {
+		return nil
 	}
+	return writeRoot(flushRequest{root: committed.root, height: committed.height, database: committed.database, disk: sm.pebbleDB})
+}
+
+// ExportKV recorre todas las claves de la base de datos EVM (usado por los snapshots de estado)
+// El trie en memoria se escribe en disco antes de recorrer para que el export sea completo
+func (sm *StateManager) ExportKV(fn func(key, value []byte) error) error {
+	if sm.pebbleDB == nil {
+		return fmt.Errorf("base de datos EVM no está abierta")
+	}
+	if err := sm.flushTrie(); err != nil {
+		return err
+	}
+
+	iter := sm.pebbleDB.NewIterator(nil, nil)
+	defer iter.Release()
 
-	return block.Header.Timestamp.Unix()
+	for iter.Next() {
+		if err := fn(iter.Key(), iter.Value()); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
 }
 
+// ImportKV reemplaza el contenido de la base de datos EVM por las entradas recibidas
+// Después de importar, el StateDB se recarga desde el root guardado en storage
+// (el storage debe importarse antes para que el root corresponda al estado importado)
+func (sm *StateManager) ImportKV(entries func(put func(key, value []byte) error) error) error {
+	if sm.pebbleDB == nil {
+		if _, err := sm.LoadState(); err != nil {
+			return err
+		}
+	}
+	db := sm.pebbleDB
+
+	// Ninguna escritura pendiente puede llegar después del borrado
+	if err := sm.pipeline.wait(); err != nil {
+		return err
+	}
+
+	// Borrar el contenido actual
+	batch := db.NewBatch()
+	iter := db.NewIterator(nil, nil)
+	for iter.Next() {
+		if err := batch.Delete(common.CopyBytes(iter.Key())); err != nil {
+			iter.Release()
+			return err
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				iter.Release()
+				return fmt.Errorf("error limpiando base de datos EVM: %w", err)
+			}
+			batch.Reset()
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("error recorriendo base de datos EVM: %w", err)
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("error limpiando base de datos EVM: %w", err)
+	}
+
+	batch.Reset()
+	err := entries(func(key, value []byte) error {
+		if err := batch.Put(key, value); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("error escribiendo base de datos EVM: %w", err)
+	}
+
+	// Reabrir el StateDB sobre el estado importado
+	if _, err := sm.LoadState(); err != nil {
+		return fmt.Errorf("error recargando estado importado: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package execution
+
+import (
+	"container/list"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// Tamaños por defecto del cache de estado confirmado
+const (
+	DefaultStateCacheAccounts = 10000
+	DefaultStateCacheSlots    = 100000
+)
+
+// StateCacheStats son los aciertos, fallos y entradas del cache de estado confirmado
+type StateCacheStats struct {
+	AccountHits   uint64 `json:"accountHits"`
+	AccountMisses uint64 `json:"accountMisses"`
+	Accounts      int    `json:"accounts"` // Cuentas en el cache
+	SlotHits      uint64 `json:"slotHits"`
+	SlotMisses    uint64 `json:"slotMisses"`
+	Slots         int    `json:"slots"` // Slots de storage en el cache
+}
+
+// cachedAccount son los campos de una cuenta que sirve el cache
+type cachedAccount struct {
+	balance     *uint256.Int
+	nonce       uint64
+	codeHash    common.Hash
+	storageRoot common.Hash
+}
+
+// slotKey identifica un slot de storage en el cache
+type slotKey struct {
+	address common.Address
+	key     common.Hash
+}
+
+// lruEntry es una entrada de lruCache
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// lruCache es un mapa acotado que expulsa la entrada usada hace más tiempo (capacidad 0 = no guarda nada)
+type lruCache[K comparable, V any] struct {
+	capacity int
+	entries  map[K]*list.Element
+	lru      *list.List // Frente = usado más recientemente
+}
+
+func newLRUCache[K comparable, V any](capacity int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		capacity: capacity,
+		entries:  make(map[K]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	if element, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(element)
+		return element.Value.(*lruEntry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (c *lruCache[K, V]) add(key K, value V) {
+	if c.capacity <= 0 {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEntry[K, V]).value = value
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&lruEntry[K, V]{key: key, value: value})
+	c.evict()
+}
+
+// evict expulsa las entradas que exceden la capacidad
+func (c *lruCache[K, V]) evict() {
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+func (c *lruCache[K, V]) purge() {
+	c.entries = make(map[K]*list.Element)
+	c.lru.Init()
+}
+
+// stateCache guarda cuentas y slots de storage del último estado confirmado. Las lecturas van al reader
+// de ese root y no al StateDB en uso, que la ejecución del bloque modifica desde otra goroutine.
+// Se vacía en cada commit (ver reset): el cache solo contiene valores del root actual
+type stateCache struct {
+	mu       sync.Mutex
+	root     common.Hash
+	database state.Database
+	reader   state.Reader // Se abre con el primer fallo después de reset
+	accounts *lruCache[common.Address, cachedAccount]
+	slots    *lruCache[slotKey, common.Hash]
+	stats    StateCacheStats
+}
+
+func newStateCache(accounts, slots int) *stateCache {
+	return &stateCache{
+		accounts: newLRUCache[common.Address, cachedAccount](accounts),
+		slots:    newLRUCache[slotKey, common.Hash](slots),
+	}
+}
+
+// resize cambia la capacidad del cache (0 = deshabilitado: cada lectura va al reader)
+func (c *stateCache) resize(accounts, slots int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accounts.capacity = accounts
+	c.accounts.evict()
+	c.slots.capacity = slots
+	c.slots.evict()
+}
+
+// reset apunta el cache a un nuevo root confirmado y descarta todas las entradas
+func (c *stateCache) reset(root common.Hash, database state.Database) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if root == (common.Hash{}) {
+		root = types.EmptyRootHash
+	}
+	c.root = root
+	c.database = database
+	c.reader = nil
+	c.accounts.purge()
+	c.slots.purge()
+}
+
+// openReader retorna el reader del root actual (con c.mu tomado)
+func (c *stateCache) openReader() (state.Reader, error) {
+	if c.reader != nil {
+		return c.reader, nil
+	}
+	if c.database == nil {
+		return nil, fmt.Errorf("estado no cargado")
+	}
+	reader, err := c.database.Reader(c.root)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo estado %s: %w", c.root.Hex(), err)
+	}
+	c.reader = reader
+	return reader, nil
+}
+
+// account retorna una cuenta del estado confirmado (balance 0 si no existe)
+func (c *stateCache) account(address common.Address) (cachedAccount, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if account, ok := c.accounts.get(address); ok {
+		c.stats.AccountHits++
+		return account, nil
+	}
+	c.stats.AccountMisses++
+
+	reader, err := c.openReader()
+	if err != nil {
+		return cachedAccount{}, err
+	}
+	data, err := reader.Account(address)
+	if err != nil {
+		return cachedAccount{}, fmt.Errorf("error leyendo cuenta %s: %w", address.Hex(), err)
+	}
+	// Mismos valores que el StateDB: una cuenta inexistente no tiene code hash
+	account := cachedAccount{balance: new(uint256.Int), storageRoot: types.EmptyRootHash}
+	if data != nil {
+		account = cachedAccount{
+			balance:     data.Balance,
+			nonce:       data.Nonce,
+			codeHash:    common.BytesToHash(data.CodeHash),
+			storageRoot: data.Root,
+		}
+	}
+	c.accounts.add(address, account)
+	return account, nil
+}
+
+// storage retorna un slot de storage del estado confirmado
+func (c *stateCache) storage(address common.Address, key common.Hash) (common.Hash, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := slotKey{address: address, key: key}
+	if value, ok := c.slots.get(id); ok {
+		c.stats.SlotHits++
+		return value, nil
+	}
+	c.stats.SlotMisses++
+
+	reader, err := c.openReader()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	value, err := reader.Storage(address, key)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error leyendo storage de %s: %w", address.Hex(), err)
+	}
+	c.slots.add(id, value)
+	return value, nil
+}
+
+// snapshot retorna las estadísticas actuales
+func (c *stateCache) snapshot() StateCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Accounts = c.accounts.lru.Len()
+	stats.Slots = c.slots.lru.Len()
+	return stats
+}
+
+// SetStateCacheSize configura cuántas cuentas y slots de storage del estado confirmado se guardan en memoria
+// (0 = sin cache)
+func (e *EVMExecutor) SetStateCacheSize(accounts, slots int) {
+	e.stateManager.cache.resize(accounts, slots)
+}
+
+// StateCacheStats retorna los aciertos y fallos del cache de estado confirmado
+func (e *EVMExecutor) StateCacheStats() StateCacheStats {
+	return e.stateManager.cache.snapshot()
+}
+
+// GetCommittedState retorna el estado de una cuenta en el último bloque confirmado, sin los cambios del
+// bloque en ejecución. Lee a través del cache, así que es seguro llamarlo desde cualquier goroutine
+// (API, CheckTx) mientras se ejecuta un bloque
+func (e *EVMExecutor) GetCommittedState(address string) (*AccountState, error) {
+	if !e.running {
+		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+
+	addr := common.HexToAddress(address)
+	cache := e.stateManager.cache
+	account, err := cache.account(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mismos slots que GetState; una cuenta sin storage (ej: la de un usuario) no los consulta
+	storage := make(map[string]string)
+	for i := 0; i < 100 && account.storageRoot != types.EmptyRootHash; i++ {
+		key := common.BigToHash(big.NewInt(int64(i)))
+		value, err := cache.storage(addr, key)
+		if err != nil {
+			return nil, err
+		}
+		if value != (common.Hash{}) {
+			storage[key.Hex()] = value.Hex()
+		}
+	}
+
+	return &AccountState{
+		Address:  address,
+		Balance:  account.balance.String(),
+		Nonce:    account.nonce,
+		CodeHash: account.codeHash.Hex(),
+		Storage:  storage,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+)
+
+// TestEVMExecutor_GetCommittedState prueba que GetCommittedState solo vea el estado confirmado,
+// que el cache acierte en lecturas repetidas y que se vacíe en cada commit
+func TestEVMExecutor_GetCommittedState(t *testing.T) {
+	testDir := createTestDir("state_cache")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+
+	address := "0x00000000000000000000000000000000000000b1"
+	if err := evm.FundAccount(address, "1000"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+
+	// Sin confirmar, el cambio solo se ve en el estado en uso
+	committed, err := evm.GetCommittedState(address)
+	if err != nil {
+		t.Fatalf("Error leyendo estado confirmado: %v", err)
+	}
+	if committed.Balance != "0" {
+		t.Errorf("Balance confirmado esperado 0 antes del commit, obtenido %s", committed.Balance)
+	}
+	if _, err := evm.GetCommittedState(address); err != nil {
+		t.Fatalf("Error leyendo estado confirmado: %v", err)
+	}
+	if stats := evm.StateCacheStats(); stats.AccountHits != 1 || stats.AccountMisses != 1 || stats.Accounts != 1 {
+		t.Errorf("Estadísticas incorrectas: %+v", stats)
+	}
+
+	// Un contrato con storage lee sus slots a través del cache
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	stateDB := evm.getStateDB()
+	stateDB.SetCode(contract, []byte{0x00}, tracing.CodeChangeUnspecified)
+	stateDB.SetState(contract, common.BigToHash(common.Big1), common.BigToHash(common.Big2))
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	// El commit vacía el cache y la siguiente lectura ve el nuevo balance
+	if stats := evm.StateCacheStats(); stats.Accounts != 0 || stats.Slots != 0 {
+		t.Errorf("El cache debería vaciarse en el commit: %+v", stats)
+	}
+	committed, err = evm.GetCommittedState(address)
+	if err != nil {
+		t.Fatalf("Error leyendo estado confirmado: %v", err)
+	}
+	if committed.Balance != "1000" {
+		t.Errorf("Balance confirmado esperado 1000, obtenido %s", committed.Balance)
+	}
+
+	contractState, err := evm.GetCommittedState(contract.Hex())
+	if err != nil {
+		t.Fatalf("Error leyendo contrato: %v", err)
+	}
+	if value := contractState.Storage[common.BigToHash(common.Big1).Hex()]; value != common.BigToHash(common.Big2).Hex() {
+		t.Errorf("Slot 1 esperado 0x..02, obtenido %q", value)
+	}
+	live, err := evm.GetState(contract.Hex())
+	if err != nil {
+		t.Fatalf("Error leyendo contrato: %v", err)
+	}
+	if live.CodeHash != contractState.CodeHash {
+		t.Errorf("Code hash confirmado %s distinto del estado en uso %s", contractState.CodeHash, live.CodeHash)
+	}
+	if stats := evm.StateCacheStats(); stats.Slots != 100 || stats.SlotMisses != 100 {
+		t.Errorf("Se esperaban 100 slots leídos y guardados: %+v", stats)
+	}
+
+	// Con capacidad 0 cada lectura va al estado
+	evm.SetStateCacheSize(0, 0)
+	if stats := evm.StateCacheStats(); stats.Accounts != 0 || stats.Slots != 0 {
+		t.Errorf("El cache debería vaciarse al deshabilitarlo: %+v", stats)
+	}
+	before := evm.StateCacheStats()
+	if _, err := evm.GetCommittedState(address); err != nil {
+		t.Fatalf("Error leyendo estado confirmado: %v", err)
+	}
+	if _, err := evm.GetCommittedState(address); err != nil {
+		t.Fatalf("Error leyendo estado confirmado: %v", err)
+	}
+	if stats := evm.StateCacheStats(); stats.AccountMisses != before.AccountMisses+2 || stats.AccountHits != before.AccountHits {
+		t.Errorf("Sin cache todas las lecturas deberían ser fallos: %+v", stats)
+	}
+}
+
+// TestLRUCache prueba que el cache expulse la entrada usada hace más tiempo
+func TestLRUCache(t *testing.T) {
+	cache := newLRUCache[int, string](2)
+	cache.add(1, "a")
+	cache.add(2, "b")
+	if _, ok := cache.get(1); !ok {
+		t.Fatal("La entrada 1 debería estar en el cache")
+	}
+	cache.add(3, "c") // Expulsa 2: la 1 se usó más recientemente
+
+	if _, ok := cache.get(2); ok {
+		t.Error("La entrada 2 debería haberse expulsado")
+	}
+	if value, ok := cache.get(1); !ok || value != "a" {
+		t.Errorf("Entrada 1 incorrecta: %q (%v)", value, ok)
+	}
+	if value, ok := cache.get(3); !ok || value != "c" {
+		t.Errorf("Entrada 3 incorrecta: %q (%v)", value, ok)
+	}
+}
@@ -0,0 +1,262 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+)
+
+// newStateTestExecutor crea un ejecutor iniciado sobre un directorio temporal
+func newStateTestExecutor(t *testing.T, name string) (*EVMExecutor, *storage.BlockchainDB) {
+	testDir := createTestDir(name)
+	t.Cleanup(func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	})
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	evm := NewEVMExecutor(db)
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	t.Cleanup(func() { evm.Stop() })
+	evm.SetCurrentBlockInfo(1, 1700000000)
+	return evm, db
+}
+
+// saveTestBlock guarda un bloque cuyo hash es el root confirmado, como hace el consenso en cada commit
+func saveTestBlock(t *testing.T, evm *EVMExecutor, db *storage.BlockchainDB, height uint64) common.Hash {
+	root := evm.GetStateManager().CommittedRoot()
+	block, _ := json.Marshal(map[string]interface{}{"header": map[string]interface{}{"height": height, "hash": root.Hex()}})
+	if err := db.SaveBlock(height, block); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+	return root
+}
+
+// TestStateManager_LoadStateAtHeight prueba que cargar una altura anterior use la base de datos ya abierta
+// (una segunda instancia de Pebble sobre el mismo directorio falla)
+func TestStateManager_LoadStateAtHeight(t *testing.T) {
+	evm, _ := newStateTestExecutor(t, "load_state_at_height")
+	sm := evm.GetStateManager()
+	address := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+
+	if err := evm.FundAccount(address.Hex(), "100"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+	if err := evm.SaveStateAtHeight(1); err != nil {
+		t.Fatalf("Error guardando estado en altura 1: %v", err)
+	}
+
+	if err := evm.FundAccount(address.Hex(), "50"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	stateDB, err := sm.LoadStateAtHeight(1)
+	if err != nil {
+		t.Fatalf("Error cargando estado en altura 1: %v", err)
+	}
+	if balance := stateDB.GetBalance(address).String(); balance != "100" {
+		t.Errorf("Balance en altura 1 esperado 100, obtenido %s", balance)
+	}
+
+	// El estado cargado sigue admitiendo cambios y commits sobre la misma base de datos
+	evm.ReloadState()
+	if err := evm.FundAccount(address.Hex(), "1"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado después de cargar altura 1: %v", err)
+	}
+	if state, err := evm.GetCommittedState(address.Hex()); err != nil || state.Balance != "101" {
+		t.Errorf("Balance confirmado esperado 101: %+v (%v)", state, err)
+	}
+}
+
+// TestEVMExecutor_QueriesReadCommittedState prueba que las consultas (CallContract, GetCommittedSupply)
+// lean el último estado confirmado y no modifiquen el StateDB en uso
+func TestEVMExecutor_QueriesReadCommittedState(t *testing.T) {
+	evm, _ := newStateTestExecutor(t, "committed_queries")
+	from := "0x00000000000000000000000000000000000000b1"
+
+	// SLOAD(0) y lo retorna; el otro contrato escribe 1 en el slot 0
+	reader := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	writer := common.HexToAddress("0x00000000000000000000000000000000000000a2")
+	stateDB := evm.getStateDB()
+	stateDB.SetCode(reader, common.FromHex("60005460005260206000f3"), tracing.CodeChangeUnspecified)
+	stateDB.SetState(reader, common.Hash{}, common.BigToHash(common.Big1))
+	stateDB.SetCode(writer, common.FromHex("600160005500"), tracing.CodeChangeUnspecified)
+	if err := evm.FundAccount(from, "1000"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	// Cambios del bloque en ejecución, sin confirmar
+	evm.getStateDB().SetState(reader, common.Hash{}, common.BigToHash(common.Big2))
+	if err := evm.FundAccount(from, "500"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+
+	ret, err := evm.CallContract(from, reader.Hex(), nil, 100000)
+	if err != nil || common.BytesToHash(ret) != common.BigToHash(common.Big1) {
+		t.Errorf("CallContract debería leer el slot confirmado (1): %x (%v)", ret, err)
+	}
+	if supply, err := evm.GetCommittedSupply(); err != nil || supply.Minted != "1000" {
+		t.Errorf("Emisión confirmada esperada 1000: %+v (%v)", supply, err)
+	}
+	if supply, err := evm.GetSupply(); err != nil || supply.Minted != "1500" {
+		t.Errorf("Emisión en uso esperada 1500: %+v (%v)", supply, err)
+	}
+
+	// Una llamada que escribe no deja cambios en el estado en uso
+	if _, err := evm.CallContract(from, writer.Hex(), nil, 100000); err != nil {
+		t.Fatalf("Error en CallContract: %v", err)
+	}
+	if value := evm.getStateDB().GetState(writer, common.Hash{}); value != (common.Hash{}) {
+		t.Errorf("CallContract modificó el estado en uso: %s", value.Hex())
+	}
+}
+
+// TestEVMExecutor_ConcurrentStateReads prueba lecturas del API (estado confirmado, alturas anteriores,
+// llamadas y volcado) desde otras goroutines mientras se ejecutan y confirman bloques
+func TestEVMExecutor_ConcurrentStateReads(t *testing.T) {
+	evm, db := newStateTestExecutor(t, "concurrent_state_reads")
+	sender := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000000b2")
+
+	if err := evm.FundAccount(sender.Hex(), "1000000000000"); err != nil {
+		t.Fatalf("Error fondeando cuenta: %v", err)
+	}
+	if err := evm.SaveState(); err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+	root := saveTestBlock(t, evm, db, 1)
+
+	const blocks, txsPerBlock = 10, 5
+	done := make(chan struct{})
+	errs := make(chan error, 16)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+	stop := func() { stopOnce.Do(func() { close(done); wg.Wait() }) }
+	defer stop() // Antes de detener el ejecutor, también si el test falla
+	read := func(name string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if err := fn(); err != nil {
+					errs <- fmt.Errorf("%s: %w", name, err)
+					return
+				}
+			}
+		}()
+	}
+
+	// El estado de la altura 1 no cambia mientras avanzan los bloques
+	read("GetStateAtHeight", func() error {
+		state, err := evm.GetStateAtHeight(recipient.Hex(), 1)
+		if err != nil {
+			return err
+		}
+		if state.Balance != "0" {
+			return fmt.Errorf("balance en altura 1 %s, esperado 0", state.Balance)
+		}
+		return nil
+	})
+	read("StateAt", func() error {
+		stateDB, err := evm.GetStateManager().StateAt(root)
+		if err != nil {
+			return err
+		}
+		if nonce := stateDB.GetNonce(sender); nonce != 0 {
+			return fmt.Errorf("nonce en altura 1 %d, esperado 0", nonce)
+		}
+		return nil
+	})
+	// El estado confirmado avanza de a bloques completos
+	read("GetCommittedState", func() error {
+		state, err := evm.GetCommittedState(sender.Hex())
+		if err != nil {
+			return err
+		}
+		if state.Nonce%txsPerBlock != 0 {
+			return fmt.Errorf("nonce confirmado %d a mitad de bloque", state.Nonce)
+		}
+		return nil
+	})
+	read("GetCommittedSupply", func() error {
+		_, err := evm.GetCommittedSupply()
+		return err
+	})
+	read("CallContract", func() error {
+		_, err := evm.CallContract(sender.Hex(), recipient.Hex(), nil, 100000)
+		return err
+	})
+	read("DumpState", func() error {
+		dump, err := evm.DumpState(1, common.Hash{}, 0)
+		if err != nil {
+			return err
+		}
+		// El remitente y la cuenta del contador de emisión
+		if len(dump.Accounts) != 2 {
+			return fmt.Errorf("%d cuentas en altura 1, esperadas 2", len(dump.Accounts))
+		}
+		return nil
+	})
+
+	nonce := uint64(0)
+	for block := uint64(2); block < 2+blocks; block++ {
+		for i := 0; i < txsPerBlock; i++ {
+			result, err := evm.ExecuteTransaction(&Transaction{
+				From:     sender.Hex(),
+				To:       recipient.Hex(),
+				Value:    "1",
+				GasLimit: 21000,
+				GasPrice: "1",
+				Nonce:    nonce,
+			})
+			if err != nil || !result.Success {
+				t.Fatalf("Transacción %d falló: %+v (%v)", nonce, result, err)
+			}
+			nonce++
+		}
+		if err := evm.SaveState(); err != nil {
+			t.Fatalf("Error guardando estado: %v", err)
+		}
+		saveTestBlock(t, evm, db, block)
+	}
+	stop()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	state, err := evm.GetCommittedState(recipient.Hex())
+	if err != nil || state.Balance != fmt.Sprint(blocks*txsPerBlock) {
+		t.Errorf("Balance final esperado %d: %+v (%v)", blocks*txsPerBlock, state, err)
+	}
+}
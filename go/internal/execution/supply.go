@@ -0,0 +1,156 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/holiman/uint256"
+)
+
+// SupplyAddress es la cuenta de sistema cuyo storage guarda los contadores de emisión de OXG
+// No tiene código ni balance; su nonce es 1 para que no se elimine como cuenta vacía
+const SupplyAddress = "0x0000000000000000000000000000000000000101"
+
+// FeeCollectorAddress es la cuenta de módulo que recibe los fees de gas del bloque en curso
+// Al final de cada bloque su balance se quema (BurnCollectedFees): entre bloques está vacía
+const FeeCollectorAddress = "0x0000000000000000000000000000000000000104"
+
+var (
+	supplyAccount = common.HexToAddress(SupplyAddress)
+	feeCollector  = common.HexToAddress(FeeCollectorAddress)
+	mintedSlot    = common.BigToHash(big.NewInt(0)) // Total emitido (fondeos y recompensas)
+	burnedSlot    = common.BigToHash(big.NewInt(1)) // Total quemado (fees)
+)
+
+// Supply resume la emisión de OXG según los contadores on-chain
+type Supply struct {
+	Minted      string `json:"minted"`
+	Burned      string `json:"burned"`
+	Total       string `json:"total"`       // Emitido menos quemado
+	Locked      string `json:"locked"`      // Balance de las cuentas excluidas (ej: custodia de staking)
+	Circulating string `json:"circulating"` // Total menos bloqueado
+}
+
+// GetSupply retorna los contadores de emisión; el balance de lockedAddresses no se considera circulante
+func (e *EVMExecutor) GetSupply(lockedAddresses ...string) (*Supply, error) {
+	if !e.running {
+		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+
+	return supplyFrom(e.getStateDB(), lockedAddresses), nil
+}
+
+// GetCommittedSupply es GetSupply sobre el último estado confirmado (para consultas del API)
+func (e *EVMExecutor) GetCommittedSupply(lockedAddresses ...string) (*Supply, error) {
+	if !e.running {
+		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+
+	stateDB, err := e.stateManager.CommittedState()
+	if err != nil {
+		return nil, err
+	}
+	return supplyFrom(stateDB, lockedAddresses), nil
+}
+
+// supplyFrom lee los contadores de emisión y el balance de lockedAddresses de un StateDB
+func supplyFrom(stateDB *state.StateDB, lockedAddresses []string) *Supply {
+	minted := stateDB.GetState(supplyAccount, mintedSlot).Big()
+	burned := stateDB.GetState(supplyAccount, burnedSlot).Big()
+	total := new(big.Int).Sub(minted, burned)
+
+	locked := new(big.Int)
+	for _, address := range lockedAddresses {
+		locked.Add(locked, stateDB.GetBalance(common.HexToAddress(address)).ToBig())
+	}
+	circulating := new(big.Int).Sub(total, locked)
+	if circulating.Sign() < 0 {
+		circulating.SetInt64(0)
+	}
+
+	return &Supply{
+		Minted:      minted.String(),
+		Burned:      burned.String(),
+		Total:       total.String(),
+		Locked:      locked.String(),
+		Circulating: circulating.String(),
+	}
+}
+
+// MintReward emite amount como recompensa de bloque a una cuenta (ej: la custodia de recompensas)
+func (e *EVMExecutor) MintReward(address string, amount *big.Int) error {
+	return e.mintTo(address, amount, tracing.BalanceIncreaseRewardMineBlock)
+}
+
+// MintGenesis emite amount en el genesis a una cuenta (ej: la custodia del stake de los validadores genesis)
+func (e *EVMExecutor) MintGenesis(address string, amount *big.Int) error {
+	return e.mintTo(address, amount, tracing.BalanceIncreaseGenesisBalance)
+}
+
+// mintTo valida el monto y lo emite a una cuenta
+func (e *EVMExecutor) mintTo(address string, amount *big.Int, reason tracing.BalanceChangeReason) error {
+	if !e.running {
+		return fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	value, overflow := uint256.FromBig(amount)
+	if overflow || amount.Sign() < 0 {
+		return fmt.Errorf("monto inválido: %s", amount.String())
+	}
+	addr := common.HexToAddress(address)
+	mint(e.getStateDB(), addr, value, reason)
+	e.markTouched(addr)
+	return nil
+}
+
+// mint acredita fondos nuevos a una cuenta y los suma al contador de emisión
+func mint(stateDB *state.StateDB, addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) {
+	stateDB.AddBalance(addr, amount, reason)
+	addToSupplyCounter(stateDB, mintedSlot, amount)
+}
+
+// BurnCollectedFees quema los fees acumulados en el fee collector durante el bloque y los suma
+// al contador de quemado. Retorna el monto quemado
+func (e *EVMExecutor) BurnCollectedFees() (*big.Int, error) {
+	if !e.running {
+		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	stateDB := e.getStateDB()
+	collected := stateDB.GetBalance(feeCollector).Clone()
+	if collected.IsZero() {
+		return new(big.Int), nil
+	}
+	stateDB.SubBalance(feeCollector, collected, tracing.BalanceChangeUnspecified)
+	addToSupplyCounter(stateDB, burnedSlot, collected)
+	e.markTouched(feeCollector)
+	return collected.ToBig(), nil
+}
+
+// collectFee pasa al fee collector el fee que la EVM acreditó al coinbase
+func collectFee(stateDB *state.StateDB, coinbase common.Address, gasUsed uint64, gasPrice *big.Int) {
+	fee, overflow := uint256.FromBig(new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice))
+	if overflow || fee.IsZero() {
+		return
+	}
+	// El coinbase puede haber recibido menos si la transacción falló antes de cobrar gas
+	if balance := stateDB.GetBalance(coinbase); balance.Cmp(fee) < 0 {
+		fee = balance.Clone()
+	}
+	if fee.IsZero() {
+		return
+	}
+	stateDB.SubBalance(coinbase, fee, tracing.BalanceChangeTransfer)
+	stateDB.AddBalance(feeCollector, fee, tracing.BalanceChangeTransfer)
+}
+
+// addToSupplyCounter suma amount a un contador de SupplyAddress
+func addToSupplyCounter(stateDB *state.StateDB, slot common.Hash, amount *uint256.Int) {
+	if stateDB.GetNonce(supplyAccount) == 0 {
+		stateDB.SetNonce(supplyAccount, 1, tracing.NonceChangeUnspecified)
+	}
+	current := new(uint256.Int).SetBytes32(stateDB.GetState(supplyAccount, slot).Bytes())
+	current.Add(current, amount)
+	stateDB.SetState(supplyAccount, slot, common.Hash(current.Bytes32()))
+}
@@ -0,0 +1,100 @@
+package execution
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Hooks de bloque de los contratos de sistema: la función del contrato que se llama (sin argumentos;
+// el contrato lee block.number y block.timestamp)
+const (
+	SystemHookBeginBlock = "beginBlock" // Antes de las transacciones del bloque
+	SystemHookEndBlock   = "endBlock"   // Después de las transacciones, antes del cierre contable
+)
+
+// systemCallGas es el gas de una llamada de sistema. No lo paga nadie ni cuenta para el gas del bloque:
+// en la práctica es ilimitado, así que el contrato de sistema debe terminar siempre (un bucle infinito
+// detendría la cadena en todos los nodos por igual)
+const systemCallGas = math.MaxUint64 / 2
+
+// SystemContracts son los contratos que el nodo llama en cada bloque, en orden, con remitente
+// params.SystemAddress (0xff...fe). Permiten escribir lógica de protocolo (staking, oráculos) en Solidity
+// sin transacciones de usuarios
+type SystemContracts struct {
+	BeginBlock []string `json:"beginBlock,omitempty"`
+	EndBlock   []string `json:"endBlock,omitempty"`
+}
+
+// SystemCallResult es el resultado de la llamada de sistema a un contrato
+type SystemCallResult struct {
+	Contract string
+	GasUsed  uint64
+	Err      error // Revert o error de la EVM; los cambios del contrato se descartaron
+}
+
+// Validate verifica las direcciones de los contratos de sistema
+func (s SystemContracts) Validate() error {
+	for _, address := range append(append([]string{}, s.BeginBlock...), s.EndBlock...) {
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("dirección inválida en contratos de sistema: %s", address)
+		}
+	}
+	return nil
+}
+
+// SetSystemContracts configura los contratos de sistema de cada bloque (antes de ejecutar bloques)
+func (e *EVMExecutor) SetSystemContracts(contracts SystemContracts) error {
+	if err := contracts.Validate(); err != nil {
+		return err
+	}
+	e.systemContracts = contracts
+	return nil
+}
+
+// SystemContracts retorna los contratos de sistema configurados
+func (e *EVMExecutor) SystemContracts() SystemContracts {
+	return e.systemContracts
+}
+
+// RunSystemCalls llama a los contratos de sistema de un hook en el bloque en ejecución
+// Una llamada que falla no detiene el bloque: sus cambios se revierten y el error queda en el resultado
+func (e *EVMExecutor) RunSystemCalls(hook string) ([]SystemCallResult, error) {
+	var contracts []string
+	switch hook {
+	case SystemHookBeginBlock:
+		contracts = e.systemContracts.BeginBlock
+	case SystemHookEndBlock:
+		contracts = e.systemContracts.EndBlock
+	default:
+		return nil, fmt.Errorf("hook de sistema desconocido: %q", hook)
+	}
+	if len(contracts) == 0 {
+		return nil, nil
+	}
+	if !e.running {
+		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+
+	stateDB := e.getStateDB()
+	blockContext, chainConfig := e.blockContext(systemCallGas)
+	evm := vm.NewEVM(blockContext, state.NewHookedState(stateDB, e.balanceHooks()), chainConfig, vm.Config{})
+	evm.SetTxContext(vm.TxContext{Origin: params.SystemAddress, GasPrice: new(big.Int)})
+
+	input := crypto.Keccak256([]byte(hook + "()"))[:4]
+	results := make([]SystemCallResult, 0, len(contracts))
+	for _, contract := range contracts {
+		addr := common.HexToAddress(contract)
+		stateDB.AddAddressToAccessList(addr)
+		_, leftOver, err := evm.Call(params.SystemAddress, addr, input, systemCallGas, common.U2560)
+		stateDB.Finalise(true)
+		results = append(results, SystemCallResult{Contract: addr.Hex(), GasUsed: systemCallGas - leftOver, Err: err})
+	}
+	return results, nil
+}
@@ -0,0 +1,75 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// TestEVMExecutor_SystemCalls prueba las llamadas de sistema de cada bloque: remitente, cambios de estado
+// y revert de un contrato que falla
+func TestEVMExecutor_SystemCalls(t *testing.T) {
+	testDir := createTestDir("system_calls")
+	defer func() {
+		if err := cleanupTestDir(testDir); err != nil {
+			t.Logf("Advertencia: error limpiando directorio: %v", err)
+		}
+	}()
+
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer db.Close()
+
+	evm := NewEVMExecutor(db)
+	if err := evm.SetSystemContracts(SystemContracts{BeginBlock: []string{"0xzz"}}); err == nil {
+		t.Error("Una dirección inválida debería rechazarse")
+	}
+	if err := evm.Start(); err != nil {
+		t.Fatalf("Error iniciando EVM: %v", err)
+	}
+	defer evm.Stop()
+	evm.SetCurrentBlockInfo(1, 1700000000)
+
+	counter := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	reverter := common.HexToAddress("0x00000000000000000000000000000000000000c2")
+	// CALLER -> slot 0; slot 1 += 1
+	evm.getStateDB().SetCode(counter, common.FromHex("3360005560016001540160015500"), tracing.CodeChangeUnspecified)
+	// SSTORE(0, 1) y REVERT
+	evm.getStateDB().SetCode(reverter, common.FromHex("60016000555f5ffd"), tracing.CodeChangeUnspecified)
+	if err := evm.SetSystemContracts(SystemContracts{
+		BeginBlock: []string{counter.Hex(), reverter.Hex()},
+		EndBlock:   []string{counter.Hex()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := evm.RunSystemCalls(SystemHookBeginBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Err != nil || results[0].GasUsed == 0 || results[1].Err == nil {
+		t.Fatalf("Resultados incorrectos: %+v", results)
+	}
+	if _, err := evm.RunSystemCalls(SystemHookEndBlock); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := evm.RunSystemCalls("midBlock"); err == nil {
+		t.Error("Un hook desconocido debería rechazarse")
+	}
+
+	stateDB := evm.getStateDB()
+	if caller := common.BytesToAddress(stateDB.GetState(counter, common.Hash{}).Bytes()); caller != params.SystemAddress {
+		t.Errorf("Remitente de la llamada de sistema incorrecto: %s", caller.Hex())
+	}
+	if calls := stateDB.GetState(counter, common.BigToHash(common.Big1)).Big().Uint64(); calls != 2 {
+		t.Errorf("Se esperaban 2 llamadas (inicio y fin de bloque), hubo %d", calls)
+	}
+	if stateDB.GetState(reverter, common.Hash{}) != (common.Hash{}) {
+		t.Error("Los cambios de un contrato que revierte deberían descartarse")
+	}
+}
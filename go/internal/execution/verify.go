@@ -0,0 +1,171 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// StateVerification es el resultado de recorrer el trie de estado EVM
+type StateVerification struct {
+	Root         string   `json:"root"`
+	TrieNodes    uint64   `json:"trieNodes"`
+	Accounts     uint64   `json:"accounts"`
+	Contracts    uint64   `json:"contracts"`
+	StorageSlots uint64   `json:"storageSlots"`
+	TotalSupply  *big.Int `json:"totalSupply"` // Suma de los balances de todas las cuentas
+	CheckedAddrs uint64   `json:"checkedAddresses"`
+	Errors       []string `json:"errors,omitempty"`
+}
+
+// addError registra un problema encontrado, hasta maxErrors (0 = sin límite)
+func (v *StateVerification) addError(maxErrors int, format string, args ...interface{}) bool {
+	v.Errors = append(v.Errors, fmt.Sprintf(format, args...))
+	return maxErrors > 0 && len(v.Errors) >= maxErrors
+}
+
+// VerifyState recorre el trie de estado en el root actual verificando el hash de cada nodo,
+// decodifica todas las cuentas y sus tries de storage y recalcula el total de balances.
+// Luego contrasta el balance de cada dirección de addresses leído por el StateDB con la hoja
+// del trie, y que su suma no supere el total recalculado
+// Se detiene después de maxErrors problemas (0 = recorrer todo)
+func (sm *StateManager) VerifyState(addresses []common.Address, maxErrors int) (*StateVerification, error) {
+	database := sm.stateDatabase()
+	if database == nil {
+		return nil, fmt.Errorf("estado EVM no está cargado")
+	}
+
+	root := sm.CommittedRoot()
+	if root == (common.Hash{}) {
+		root = types.EmptyRootHash
+	}
+	result := &StateVerification{
+		Root:        root.Hex(),
+		TotalSupply: new(big.Int),
+	}
+	if root == types.EmptyRootHash {
+		return result, nil
+	}
+
+	trieDB := database.TrieDB()
+	accountTrie, err := trie.NewStateTrie(trie.StateTrieID(root), trieDB)
+	if err != nil {
+		result.addError(maxErrors, "root %s no encontrado: %v", root.Hex(), err)
+		return result, nil
+	}
+	it, err := accountTrie.NodeIterator(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error recorriendo trie de cuentas: %w", err)
+	}
+
+	// Balances por hash de dirección, para el contraste con el StateDB
+	balances := make(map[common.Hash]*big.Int)
+
+	for it.Next(true) {
+		if !checkNodeHash(it, result) && result.addError(maxErrors, "nodo %s del trie de cuentas corrupto (path %x)", it.Hash().Hex(), it.Path()) {
+			return result, nil
+		}
+		if !it.Leaf() {
+			continue
+		}
+
+		accountHash := common.BytesToHash(it.LeafKey())
+		account, err := types.FullAccount(it.LeafBlob())
+		if err != nil {
+			if result.addError(maxErrors, "cuenta %s no decodificable: %v", accountHash.Hex(), err) {
+				return result, nil
+			}
+			continue
+		}
+		result.Accounts++
+		balance := account.Balance.ToBig()
+		balances[accountHash] = balance
+		result.TotalSupply.Add(result.TotalSupply, balance)
+
+		if account.Root != types.EmptyRootHash {
+			result.Contracts++
+			if stop := verifyStorageTrie(trieDB, root, accountHash, account.Root, result, maxErrors); stop {
+				return result, nil
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		if result.addError(maxErrors, "trie de cuentas incompleto: %v", err) {
+			return result, nil
+		}
+	}
+
+	sm.checkBalances(addresses, balances, result, maxErrors)
+	return result, nil
+}
+
+// checkBalances contrasta los balances del StateDB con los de las hojas del trie
+func (sm *StateManager) checkBalances(addresses []common.Address, balances map[common.Hash]*big.Int, result *StateVerification, maxErrors int) {
+	if sm.stateDB == nil {
+		return
+	}
+	known := new(big.Int)
+	seen := make(map[common.Address]bool, len(addresses))
+	for _, addr := range addresses {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		result.CheckedAddrs++
+
+		expected, ok := balances[crypto.Keccak256Hash(addr.Bytes())]
+		if !ok {
+			expected = new(big.Int)
+		}
+		actual := sm.stateDB.GetBalance(addr).ToBig()
+		if actual.Cmp(expected) != 0 {
+			if result.addError(maxErrors, "balance de %s: StateDB %s, trie %s", addr.Hex(), actual, expected) {
+				return
+			}
+		}
+		known.Add(known, actual)
+	}
+	if known.Cmp(result.TotalSupply) > 0 {
+		result.addError(maxErrors, "los balances de las direcciones conocidas (%s) superan el total recalculado (%s)", known, result.TotalSupply)
+	}
+}
+
+// verifyStorageTrie recorre el trie de storage de una cuenta; retorna true si se alcanzó maxErrors
+func verifyStorageTrie(trieDB *triedb.Database, stateRoot, accountHash, storageRoot common.Hash, result *StateVerification, maxErrors int) bool {
+	storageTrie, err := trie.NewStateTrie(trie.StorageTrieID(stateRoot, accountHash, storageRoot), trieDB)
+	if err != nil {
+		return result.addError(maxErrors, "storage %s de la cuenta %s no encontrado: %v", storageRoot.Hex(), accountHash.Hex(), err)
+	}
+	it, err := storageTrie.NodeIterator(nil)
+	if err != nil {
+		return result.addError(maxErrors, "error recorriendo storage de la cuenta %s: %v", accountHash.Hex(), err)
+	}
+
+	for it.Next(true) {
+		if !checkNodeHash(it, result) && result.addError(maxErrors, "nodo %s del storage de la cuenta %s corrupto", it.Hash().Hex(), accountHash.Hex()) {
+			return true
+		}
+		if it.Leaf() {
+			result.StorageSlots++
+		}
+	}
+	if err := it.Error(); err != nil {
+		return result.addError(maxErrors, "storage de la cuenta %s incompleto: %v", accountHash.Hex(), err)
+	}
+	return false
+}
+
+// checkNodeHash verifica que el contenido de un nodo corresponda a su hash (los nodos embebidos no tienen hash)
+func checkNodeHash(it trie.NodeIterator, result *StateVerification) bool {
+	hash := it.Hash()
+	if hash == (common.Hash{}) {
+		return true
+	}
+	result.TrieNodes++
+	return crypto.Keccak256Hash(it.NodeBlob()) == hash
+}
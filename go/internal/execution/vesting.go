@@ -0,0 +1,120 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// VestingAddress es la cuenta de sistema cuyo storage guarda el calendario de vesting de cada cuenta
+// Recibe el valor de las transacciones que crean un vesting y lo pasa al beneficiario en la misma transacción
+const VestingAddress = "0x0000000000000000000000000000000000000108"
+
+var vestingRegistry = common.HexToAddress(VestingAddress)
+
+// VestingSchedule es un vesting con cliff y liberación lineal: hasta Cliff todo Amount está bloqueado; desde
+// Cliff se libera lo acumulado linealmente entre Start y End, y desde End nada está bloqueado (Unix, segundos)
+type VestingSchedule struct {
+	Amount *big.Int
+	Start  int64
+	Cliff  int64
+	End    int64
+}
+
+// Validate verifica el monto y el orden de las fechas (Start <= Cliff <= End, Start < End)
+func (v *VestingSchedule) Validate() error {
+	if v.Amount == nil || v.Amount.Sign() <= 0 {
+		return fmt.Errorf("monto de vesting inválido")
+	}
+	if v.Amount.BitLen() > 256 {
+		return fmt.Errorf("monto de vesting demasiado grande")
+	}
+	if v.Start < 0 || v.Start > v.Cliff || v.Cliff > v.End || v.Start >= v.End {
+		return fmt.Errorf("fechas de vesting inválidas: start=%d cliff=%d end=%d", v.Start, v.Cliff, v.End)
+	}
+	return nil
+}
+
+// Locked retorna el monto todavía bloqueado en el instante now
+func (v *VestingSchedule) Locked(now int64) *big.Int {
+	switch {
+	case now < v.Cliff:
+		return new(big.Int).Set(v.Amount)
+	case now >= v.End:
+		return new(big.Int)
+	}
+	remaining := new(big.Int).Mul(v.Amount, big.NewInt(v.End-now))
+	return remaining.Div(remaining, big.NewInt(v.End-v.Start))
+}
+
+// vestingSlot es el primer slot del calendario de una cuenta (como un mapping de Solidity en el slot 0 a un
+// struct): monto, inicio, cliff y fin en slots consecutivos
+func vestingSlot(addr common.Address, field int64) common.Hash {
+	base := crypto.Keccak256(common.LeftPadBytes(addr.Bytes(), 32), make([]byte, 32))
+	return common.BigToHash(new(big.Int).Add(new(big.Int).SetBytes(base), big.NewInt(field)))
+}
+
+// vestingSchedule retorna el calendario de una cuenta en un StateDB (nil si no tiene)
+func vestingSchedule(stateDB *state.StateDB, addr common.Address) *VestingSchedule {
+	field := func(i int64) *uint256.Int {
+		return new(uint256.Int).SetBytes32(stateDB.GetState(vestingRegistry, vestingSlot(addr, i)).Bytes())
+	}
+	amount := field(0)
+	if amount.IsZero() {
+		return nil
+	}
+	return &VestingSchedule{
+		Amount: amount.ToBig(),
+		Start:  int64(field(1).Uint64()),
+		Cliff:  int64(field(2).Uint64()),
+		End:    int64(field(3).Uint64()),
+	}
+}
+
+// SetVestingSchedule registra el calendario de vesting de una cuenta en el bloque en ejecución
+// No mueve fondos: el monto debe estar ya en el balance de la cuenta
+func (e *EVMExecutor) SetVestingSchedule(address string, schedule *VestingSchedule) error {
+	if !e.running {
+		return fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("dirección inválida: %s", address)
+	}
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	addr := common.HexToAddress(address)
+	stateDB := e.getStateDB()
+	setSystemSlot(stateDB, vestingRegistry, vestingSlot(addr, 1), uint64(schedule.Start))
+	setSystemSlot(stateDB, vestingRegistry, vestingSlot(addr, 2), uint64(schedule.Cliff))
+	setSystemSlot(stateDB, vestingRegistry, vestingSlot(addr, 3), uint64(schedule.End))
+	amount, _ := uint256.FromBig(schedule.Amount)
+	stateDB.SetState(vestingRegistry, vestingSlot(addr, 0), common.Hash(amount.Bytes32()))
+	return nil
+}
+
+// VestingSchedule retorna el calendario de vesting de una cuenta en el bloque en ejecución (nil si no tiene)
+func (e *EVMExecutor) VestingSchedule(address string) *VestingSchedule {
+	stateDB := e.getStateDB()
+	if stateDB == nil {
+		return nil
+	}
+	return vestingSchedule(stateDB, common.HexToAddress(address))
+}
+
+// GetCommittedVestingSchedule retorna el calendario de vesting de una cuenta en el último estado confirmado
+// (para CheckTx y consultas del API; nil si no tiene)
+func (e *EVMExecutor) GetCommittedVestingSchedule(address string) (*VestingSchedule, error) {
+	if !e.running {
+		return nil, fmt.Errorf("ejecutor EVM no está corriendo")
+	}
+	stateDB, err := e.stateManager.CommittedState()
+	if err != nil {
+		return nil, err
+	}
+	return vestingSchedule(stateDB, common.HexToAddress(address)), nil
+}
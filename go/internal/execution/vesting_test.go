@@ -0,0 +1,56 @@
+package execution
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TestVestingSchedule_Locked prueba el cliff y la liberación lineal
+func TestVestingSchedule_Locked(t *testing.T) {
+	schedule := &VestingSchedule{Amount: big.NewInt(1000), Start: 100, Cliff: 150, End: 200}
+	if err := schedule.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for now, want := range map[int64]int64{0: 1000, 149: 1000, 150: 500, 175: 250, 199: 10, 200: 0, 500: 0} {
+		if got := schedule.Locked(now); got.Int64() != want {
+			t.Errorf("Bloqueado en %d: %s, esperado %d", now, got, want)
+		}
+	}
+
+	for _, invalid := range []*VestingSchedule{
+		{Amount: big.NewInt(0), Start: 100, Cliff: 150, End: 200},
+		{Amount: big.NewInt(1), Start: 100, Cliff: 90, End: 200},
+		{Amount: big.NewInt(1), Start: 100, Cliff: 100, End: 100},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Calendario inválido aceptado: %+v", invalid)
+		}
+	}
+}
+
+// TestVestingScheduleSlots verifica que el calendario se lea igual que se escribió
+func TestVestingScheduleSlots(t *testing.T) {
+	stateDB, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	if err != nil {
+		t.Fatal(err)
+	}
+	evm := &EVMExecutor{stateDB: stateDB, running: true}
+	holder := "0x1111111111111111111111111111111111111111"
+
+	if evm.VestingSchedule(holder) != nil {
+		t.Fatal("Una cuenta sin vesting no debería tener calendario")
+	}
+	amount, _ := new(big.Int).SetString("1000000000000000000000000", 10)
+	if err := evm.SetVestingSchedule(holder, &VestingSchedule{Amount: amount, Start: 1, Cliff: 2, End: 3}); err != nil {
+		t.Fatal(err)
+	}
+	stateDB.Finalise(true)
+	got := vestingSchedule(stateDB, common.HexToAddress(holder))
+	if got == nil || got.Amount.Cmp(amount) != 0 || got.Start != 1 || got.Cliff != 2 || got.End != 3 {
+		t.Errorf("Calendario incorrecto: %+v", got)
+	}
+}
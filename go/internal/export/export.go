@@ -0,0 +1,287 @@
+// Package export convierte los bloques guardados en filas planas (JSON-lines o CSV) para cargarlos en
+// herramientas de análisis (pandas, BigQuery, DuckDB) sin un scraper propio.
+//
+// Hay una tabla por entidad: blocks, transactions, receipts y logs. Las filas de transactions, receipts y
+// logs llevan la altura y el hash del bloque para unirlas con blocks. Los montos (value, gas_price) son
+// enteros decimales en wei como texto, porque no entran en un INT64; los bytes (data, topics) van en hex con
+// 0x. En CSV la primera línea es el encabezado con los mismos nombres que las claves JSON.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Tablas exportables
+const (
+	TableBlocks       = "blocks"
+	TableTransactions = "transactions"
+	TableReceipts     = "receipts"
+	TableLogs         = "logs"
+)
+
+// Formatos de salida
+const (
+	FormatJSONL = "jsonl" // Un objeto JSON por línea
+	FormatCSV   = "csv"   // Encabezado y una fila por línea (RFC 4180)
+)
+
+// Tables son las tablas en el orden en que se documentan
+var Tables = []string{TableBlocks, TableTransactions, TableReceipts, TableLogs}
+
+// BlockRow es una fila de la tabla blocks
+type BlockRow struct {
+	Height           uint64 `json:"height"`
+	Hash             string `json:"hash"`
+	ParentHash       string `json:"parent_hash"`
+	Timestamp        string `json:"timestamp"` // RFC 3339 en UTC
+	Validator        string `json:"validator"` // Dirección CometBFT del proponente
+	ChainID          string `json:"chain_id"`
+	StateRoot        string `json:"state_root"` // Vacíos en bloques anteriores a los roots del header
+	TransactionsRoot string `json:"transactions_root"`
+	ReceiptsRoot     string `json:"receipts_root"`
+	GasUsed          uint64 `json:"gas_used"`
+	GasLimit         uint64 `json:"gas_limit"`
+	TransactionCount int    `json:"transaction_count"`
+}
+
+// TransactionRow es una fila de la tabla transactions
+type TransactionRow struct {
+	BlockHeight      uint64 `json:"block_height"`
+	BlockHash        string `json:"block_hash"`
+	BlockTimestamp   string `json:"block_timestamp"`
+	TransactionIndex int    `json:"transaction_index"` // Posición en el bloque
+	Hash             string `json:"hash"`
+	From             string `json:"from"`
+	To               string `json:"to"` // Vacío en la creación de contratos
+	Value            string `json:"value"`
+	Data             string `json:"data"`
+	GasLimit         uint64 `json:"gas_limit"`
+	GasPrice         string `json:"gas_price"`
+	Nonce            uint64 `json:"nonce"`
+	FeePayer         string `json:"fee_payer"` // Cuenta que pagó el gas de una transacción patrocinada (vacío si es From)
+	Multisig         bool   `json:"multisig"`  // Enviada por una cuenta multisig
+}
+
+// ReceiptRow es una fila de la tabla receipts
+type ReceiptRow struct {
+	BlockHeight      uint64 `json:"block_height"`
+	BlockHash        string `json:"block_hash"`
+	TransactionIndex int    `json:"transaction_index"`
+	TransactionHash  string `json:"transaction_hash"`
+	Status           string `json:"status"` // success o failed
+	GasUsed          uint64 `json:"gas_used"`
+	Error            string `json:"error"` // Motivo de la falla (vacío si success)
+	LogCount         int    `json:"log_count"`
+}
+
+// LogRow es una fila de la tabla logs
+type LogRow struct {
+	BlockHeight      uint64   `json:"block_height"`
+	BlockHash        string   `json:"block_hash"`
+	TransactionIndex int      `json:"transaction_index"`
+	TransactionHash  string   `json:"transaction_hash"`
+	LogIndex         int      `json:"log_index"` // Posición dentro de la transacción
+	Address          string   `json:"address"`   // Contrato emisor
+	Topics           []string `json:"topics"`    // En CSV, separados por ";"
+	Data             string   `json:"data"`
+}
+
+// Exporter escribe las filas de una tabla de los bloques que recibe, en orden
+type Exporter struct {
+	table   string
+	format  string
+	json    *json.Encoder
+	csv     *csv.Writer
+	header  bool // Encabezado CSV ya escrito
+	written int
+}
+
+// NewExporter crea un exporter de table en format que escribe en w
+func NewExporter(w io.Writer, table, format string) (*Exporter, error) {
+	switch table {
+	case TableBlocks, TableTransactions, TableReceipts, TableLogs:
+	default:
+		return nil, fmt.Errorf("tabla desconocida: %s (%s)", table, strings.Join(Tables, ", "))
+	}
+	e := &Exporter{table: table, format: format}
+	switch format {
+	case FormatJSONL:
+		e.json = json.NewEncoder(w)
+	case FormatCSV:
+		e.csv = csv.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("formato desconocido: %s (%s o %s)", format, FormatJSONL, FormatCSV)
+	}
+	return e, nil
+}
+
+// Rows retorna la cantidad de filas escritas
+func (e *Exporter) Rows() int {
+	return e.written
+}
+
+// WriteBlock escribe las filas del bloque en la tabla del exporter
+func (e *Exporter) WriteBlock(block *consensus.Block) error {
+	header := block.Header
+	timestamp := header.Timestamp.UTC().Format(time.RFC3339)
+
+	switch e.table {
+	case TableBlocks:
+		return e.write(&BlockRow{
+			Height:           header.Height,
+			Hash:             header.Hash,
+			ParentHash:       header.ParentHash,
+			Timestamp:        timestamp,
+			Validator:        header.Validator,
+			ChainID:          header.ChainID,
+			StateRoot:        header.StateRoot,
+			TransactionsRoot: header.TransactionsRoot,
+			ReceiptsRoot:     header.ReceiptsRoot,
+			GasUsed:          header.GasUsed,
+			GasLimit:         header.GasLimit,
+			TransactionCount: len(block.Transactions),
+		})
+
+	case TableTransactions:
+		for i, tx := range block.Transactions {
+			row := &TransactionRow{
+				BlockHeight:      header.Height,
+				BlockHash:        header.Hash,
+				BlockTimestamp:   timestamp,
+				TransactionIndex: i,
+				Hash:             tx.Hash,
+				From:             tx.From,
+				To:               tx.To,
+				Value:            tx.Value,
+				Data:             hexutil.Encode(tx.Data),
+				GasLimit:         tx.GasLimit,
+				GasPrice:         tx.GasPrice,
+				Nonce:            tx.Nonce,
+				Multisig:         tx.Multisig != nil,
+			}
+			if tx.FeePayer != nil {
+				row.FeePayer = tx.FeePayer.Address
+			}
+			if err := e.write(row); err != nil {
+				return err
+			}
+		}
+
+	case TableReceipts, TableLogs:
+		for _, receipt := range block.Receipts {
+			index := transactionIndex(block, receipt.TransactionHash)
+			if e.table == TableReceipts {
+				if err := e.write(&ReceiptRow{
+					BlockHeight:      header.Height,
+					BlockHash:        header.Hash,
+					TransactionIndex: index,
+					TransactionHash:  receipt.TransactionHash,
+					Status:           receipt.Status,
+					GasUsed:          receipt.GasUsed,
+					Error:            receipt.Error,
+					LogCount:         len(receipt.Logs),
+				}); err != nil {
+					return err
+				}
+				continue
+			}
+			for i, log := range receipt.Logs {
+				if err := e.write(&LogRow{
+					BlockHeight:      header.Height,
+					BlockHash:        header.Hash,
+					TransactionIndex: index,
+					TransactionHash:  receipt.TransactionHash,
+					LogIndex:         i,
+					Address:          log.Address,
+					Topics:           append([]string{}, log.Topics...),
+					Data:             hexutil.Encode(log.Data),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Flush escribe lo que quede en el buffer (CSV) y retorna el primer error de escritura
+func (e *Exporter) Flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
+
+// write escribe una fila
+func (e *Exporter) write(row interface{}) error {
+	e.written++
+	if e.json != nil {
+		return e.json.Encode(row)
+	}
+	if !e.header {
+		e.header = true
+		if err := e.csv.Write(columns(row)); err != nil {
+			return err
+		}
+	}
+	return e.csv.Write(record(row))
+}
+
+// transactionIndex retorna la posición de una transacción en el bloque (-1 si no está)
+func transactionIndex(block *consensus.Block, hash string) int {
+	for i, tx := range block.Transactions {
+		if tx.Hash == hash {
+			return i
+		}
+	}
+	return -1
+}
+
+// columns retorna el encabezado CSV de una fila: sus claves JSON en orden
+func columns(row interface{}) []string {
+	switch row.(type) {
+	case *BlockRow:
+		return []string{"height", "hash", "parent_hash", "timestamp", "validator", "chain_id", "state_root",
+			"transactions_root", "receipts_root", "gas_used", "gas_limit", "transaction_count"}
+	case *TransactionRow:
+		return []string{"block_height", "block_hash", "block_timestamp", "transaction_index", "hash", "from", "to",
+			"value", "data", "gas_limit", "gas_price", "nonce", "fee_payer", "multisig"}
+	case *ReceiptRow:
+		return []string{"block_height", "block_hash", "transaction_index", "transaction_hash", "status", "gas_used",
+			"error", "log_count"}
+	case *LogRow:
+		return []string{"block_height", "block_hash", "transaction_index", "transaction_hash", "log_index", "address",
+			"topics", "data"}
+	}
+	return nil
+}
+
+// record retorna los valores CSV de una fila, en el orden de columns
+func record(row interface{}) []string {
+	u := func(v uint64) string { return strconv.FormatUint(v, 10) }
+	switch r := row.(type) {
+	case *BlockRow:
+		return []string{u(r.Height), r.Hash, r.ParentHash, r.Timestamp, r.Validator, r.ChainID, r.StateRoot,
+			r.TransactionsRoot, r.ReceiptsRoot, u(r.GasUsed), u(r.GasLimit), strconv.Itoa(r.TransactionCount)}
+	case *TransactionRow:
+		return []string{u(r.BlockHeight), r.BlockHash, r.BlockTimestamp, strconv.Itoa(r.TransactionIndex), r.Hash,
+			r.From, r.To, r.Value, r.Data, u(r.GasLimit), r.GasPrice, u(r.Nonce), r.FeePayer, strconv.FormatBool(r.Multisig)}
+	case *ReceiptRow:
+		return []string{u(r.BlockHeight), r.BlockHash, strconv.Itoa(r.TransactionIndex), r.TransactionHash, r.Status,
+			u(r.GasUsed), r.Error, strconv.Itoa(r.LogCount)}
+	case *LogRow:
+		return []string{u(r.BlockHeight), r.BlockHash, strconv.Itoa(r.TransactionIndex), r.TransactionHash,
+			strconv.Itoa(r.LogIndex), r.Address, strings.Join(r.Topics, ";"), r.Data}
+	}
+	return nil
+}
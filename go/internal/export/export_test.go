@@ -0,0 +1,116 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// testBlock retorna un bloque con dos transacciones, una fallida y otra con un log
+func testBlock() *consensus.Block {
+	return &consensus.Block{
+		Header: consensus.BlockHeader{Height: 7, Hash: "0x07", ParentHash: "0x06", Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Transactions: []*consensus.Transaction{
+			{Hash: "0xa1", From: "0x01", To: "0x02", Value: "1000000000000000000000", GasPrice: "1", Nonce: 3},
+			{Hash: "0xa2", From: "0x01", Data: []byte{0x60, 0x00}, FeePayer: &consensus.FeePayerAuth{Address: "0x03"}},
+		},
+		Receipts: []*consensus.TransactionReceipt{
+			{TransactionHash: "0xa1", Status: "failed", GasUsed: 21000, Error: "revert"},
+			{TransactionHash: "0xa2", Status: "success", GasUsed: 50000, Logs: []consensus.Log{
+				{Address: "0x04", Topics: []string{"0xt0", "0xt1"}, Data: []byte{0x01}},
+			}},
+		},
+	}
+}
+
+func TestExportJSONL(t *testing.T) {
+	var out bytes.Buffer
+	exporter, err := NewExporter(&out, TableTransactions, FormatJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.WriteBlock(testBlock()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || exporter.Rows() != 2 {
+		t.Fatalf("se esperaban 2 filas: %q", out.String())
+	}
+	var row TransactionRow
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil {
+		t.Fatal(err)
+	}
+	if row.BlockHeight != 7 || row.TransactionIndex != 1 || row.Data != "0x6000" || row.FeePayer != "0x03" ||
+		row.BlockTimestamp != "2024-01-02T03:04:05Z" {
+		t.Errorf("fila incorrecta: %+v", row)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	for table, expected := range map[string][][]string{
+		TableReceipts: {
+			{"block_height", "block_hash", "transaction_index", "transaction_hash", "status", "gas_used", "error", "log_count"},
+			{"7", "0x07", "0", "0xa1", "failed", "21000", "revert", "0"},
+			{"7", "0x07", "1", "0xa2", "success", "50000", "", "1"},
+		},
+		TableLogs: {
+			{"block_height", "block_hash", "transaction_index", "transaction_hash", "log_index", "address", "topics", "data"},
+			{"7", "0x07", "1", "0xa2", "0", "0x04", "0xt0;0xt1", "0x01"},
+		},
+	} {
+		var out bytes.Buffer
+		exporter, err := NewExporter(&out, table, FormatCSV)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := exporter.WriteBlock(testBlock()); err != nil {
+			t.Fatal(err)
+		}
+		if err := exporter.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		records, err := csv.NewReader(&out).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != len(expected) {
+			t.Fatalf("%s: %d filas, esperadas %d: %v", table, len(records), len(expected), records)
+		}
+		for i := range expected {
+			if strings.Join(records[i], ",") != strings.Join(expected[i], ",") {
+				t.Errorf("%s fila %d: %v, esperada %v", table, i, records[i], expected[i])
+			}
+		}
+	}
+}
+
+// TestExportColumnsMatchJSON prueba que el encabezado CSV de cada tabla sean las claves JSON de sus filas
+func TestExportColumnsMatchJSON(t *testing.T) {
+	for _, row := range []interface{}{&BlockRow{}, &TransactionRow{}, &ReceiptRow{}, &LogRow{}} {
+		encoded, _ := json.Marshal(row)
+		var fields map[string]json.RawMessage
+		json.Unmarshal(encoded, &fields)
+		cols := columns(row)
+		if len(cols) != len(fields) || len(record(row)) != len(cols) {
+			t.Errorf("%T: %d columnas, %d valores, %d claves JSON", row, len(cols), len(record(row)), len(fields))
+		}
+		for _, col := range cols {
+			if _, ok := fields[col]; !ok {
+				t.Errorf("%T: columna %s no es una clave JSON", row, col)
+			}
+		}
+	}
+
+	if _, err := NewExporter(&bytes.Buffer{}, "accounts", FormatCSV); err == nil {
+		t.Error("se esperaba error por tabla desconocida")
+	}
+	if _, err := NewExporter(&bytes.Buffer{}, TableBlocks, "parquet"); err == nil {
+		t.Error("se esperaba error por formato desconocido")
+	}
+}
@@ -1,42 +1,62 @@
 package health
 
 import (
+	"fmt"
 	"sync"
 	"time"
 )
 
 // HealthStatus representa el estado de salud del nodo
 type HealthStatus struct {
-	Status      string                 `json:"status"`      // "healthy", "degraded", "unhealthy"
-	Timestamp   time.Time              `json:"timestamp"`
-	Components  map[string]ComponentStatus `json:"components"`
-	BlockHeight uint64                 `json:"block_height"`
-	Peers       int                    `json:"peers"`
+	Status       string                     `json:"status"` // "healthy", "degraded", "unhealthy"
+	Timestamp    time.Time                  `json:"timestamp"`
+	Components   map[string]ComponentStatus `json:"components"`
+	BlockHeight  uint64                     `json:"block_height"`
+	Peers        int                        `json:"peers"`
+	CatchingUp   bool                       `json:"catching_up"`
+	BlocksBehind int64                      `json:"blocks_behind"`
+	MeshRTTMs    float64                    `json:"mesh_rtt_ms,omitempty"` // Último round-trip ping/pong con el mesh
 }
 
 // ComponentStatus representa el estado de un componente
+// Además del estado actual conserva la causa del último fallo, para diagnosticar por qué el nodo está degradado
 type ComponentStatus struct {
-	Status    string    `json:"status"`    // "ok", "warning", "error"
+	Status    string    `json:"status"` // "ok", "warning", "error"
 	Message   string    `json:"message,omitempty"`
 	LastCheck time.Time `json:"last_check"`
+
+	ConsecutiveFailures int        `json:"consecutive_failures"`        // Verificaciones fallidas seguidas (0 = ok)
+	LastError           string     `json:"last_error,omitempty"`        // Causa del último fallo (se conserva al recuperarse)
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`     // Momento del último fallo
+	CheckDurationMs     float64    `json:"check_duration_ms,omitempty"` // Duración de la última verificación
+	BlocksBehind        int64      `json:"blocks_behind,omitempty"`     // sync: atraso respecto a la mayor altura de los peers
+	RTTMs               float64    `json:"rtt_ms,omitempty"`            // mesh: último round-trip ping/pong
 }
 
 // HealthChecker maneja el estado de salud del nodo
 type HealthChecker struct {
-	mu              sync.RWMutex
-	components      map[string]ComponentStatus
-	blockHeight     uint64
-	peers           int
-	storageHealthy  bool
-	evmHealthy      bool
+	mu               sync.RWMutex
+	components       map[string]ComponentStatus
+	blockHeight      uint64
+	peers            int
+	storageHealthy   bool
+	evmHealthy       bool
 	consensusHealthy bool
-	meshHealthy     bool
+	meshHealthy      bool
+	meshRTT          time.Duration
+	catchingUp       bool
+	blocksBehind     int64
+	maxBlocksBehind  int64 // Atraso máximo respecto a los peers para considerarse "ready"
 }
 
+// DefaultMaxBlocksBehind es el atraso máximo por defecto para que el nodo esté "ready"
+const DefaultMaxBlocksBehind int64 = 5
+
 // NewHealthChecker crea un nuevo verificador de salud
 func NewHealthChecker() *HealthChecker {
 	return &HealthChecker{
-		components: make(map[string]ComponentStatus),
+		components:      make(map[string]ComponentStatus),
+		maxBlocksBehind: DefaultMaxBlocksBehind,
 	}
 }
 
@@ -46,16 +66,17 @@ func (h *HealthChecker) CheckHealth() HealthStatus {
 	defer h.mu.RUnlock()
 
 	status := "healthy"
-	
+
 	// Verificar estado general
 	allHealthy := true
 	anyWarning := false
-	
-	for _, comp := range h.components {
+
+	components := make(map[string]ComponentStatus, len(h.components))
+	for name, comp := range h.components {
+		components[name] = comp
 		if comp.Status == "error" {
 			allHealthy = false
 			status = "unhealthy"
-			break
 		} else if comp.Status == "warning" {
 			anyWarning = true
 		}
@@ -71,11 +92,14 @@ func (h *HealthChecker) CheckHealth() HealthStatus {
 	}
 
 	return HealthStatus{
-		Status:      status,
-		Timestamp:   time.Now(),
-		Components:  h.components,
-		BlockHeight: h.blockHeight,
-		Peers:       h.peers,
+		Status:       status,
+		Timestamp:    time.Now(),
+		Components:   components,
+		BlockHeight:  h.blockHeight,
+		Peers:        h.peers,
+		CatchingUp:   h.catchingUp,
+		BlocksBehind: h.blocksBehind,
+		MeshRTTMs:    durationMs(h.meshRTT),
 	}
 }
 
@@ -83,12 +107,42 @@ func (h *HealthChecker) CheckHealth() HealthStatus {
 func (h *HealthChecker) UpdateComponent(name string, status string, message string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	h.setComponent(name, status, message, nil)
+}
 
-	h.components[name] = ComponentStatus{
-		Status:    status,
-		Message:   message,
-		LastCheck: time.Now(),
+// ReportCheck registra el resultado de una verificación de un componente: con error pasa a "warning" y cuenta
+// un fallo más; sin error solo registra la duración (el estado lo fijan los setters del componente)
+func (h *HealthChecker) ReportCheck(name string, duration time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err != nil {
+		h.setComponent(name, "warning", "Verificación fallida", err)
+	}
+	comp := h.components[name]
+	comp.CheckDurationMs = durationMs(duration)
+	comp.LastCheck = time.Now()
+	h.components[name] = comp
+}
+
+// setComponent actualiza un componente conservando su historial de fallos (requiere el lock)
+// Un estado distinto de "ok" cuenta como fallo; cause es el error concreto (si no, se usa el mensaje)
+func (h *HealthChecker) setComponent(name, status, message string, cause error) {
+	now := time.Now()
+	comp := h.components[name]
+	comp.Status = status
+	comp.Message = message
+	comp.LastCheck = now
+	if status == "ok" {
+		comp.ConsecutiveFailures = 0
+	} else {
+		comp.ConsecutiveFailures++
+		comp.LastError = message
+		if cause != nil {
+			comp.LastError = cause.Error()
+		}
+		comp.LastErrorAt = &now
 	}
+	h.components[name] = comp
 }
 
 // SetBlockHeight actualiza la altura del bloque
@@ -105,92 +159,91 @@ func (h *HealthChecker) SetPeers(count int) {
 	h.peers = count
 }
 
+// SetMaxBlocksBehind establece el atraso máximo (en bloques) para considerar el nodo "ready"
+func (h *HealthChecker) SetMaxBlocksBehind(maxBlocks int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maxBlocksBehind = maxBlocks
+}
+
+// SetSyncStatus actualiza el estado de sincronización respecto a los peers
+func (h *HealthChecker) SetSyncStatus(catchingUp bool, blocksBehind int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.catchingUp = catchingUp
+	h.blocksBehind = blocksBehind
+	name := "sync"
+	if catchingUp || blocksBehind > h.maxBlocksBehind {
+		h.setComponent(name, "warning", fmt.Sprintf("Sincronizando: %d bloques de atraso", blocksBehind), nil)
+	} else {
+		h.setComponent(name, "ok", "Sincronizado", nil)
+	}
+	comp := h.components[name]
+	comp.BlocksBehind = blocksBehind
+	h.components[name] = comp
+}
+
 // SetStorageHealth actualiza el estado del storage
 func (h *HealthChecker) SetStorageHealth(healthy bool) {
 	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.storageHealthy = healthy
-	// Actualizar componente sin adquirir el lock de nuevo (ya lo tenemos)
-	name := "storage"
 	if healthy {
-		h.components[name] = ComponentStatus{
-			Status:    "ok",
-			Message:   "Storage operativo",
-			LastCheck: time.Now(),
-		}
+		h.setComponent("storage", "ok", "Storage operativo", nil)
 	} else {
-		h.components[name] = ComponentStatus{
-			Status:    "error",
-			Message:   "Storage no disponible",
-			LastCheck: time.Now(),
-		}
+		h.setComponent("storage", "error", "Storage no disponible", nil)
 	}
-	h.mu.Unlock()
 }
 
 // SetEVMHealth actualiza el estado del EVM
 func (h *HealthChecker) SetEVMHealth(healthy bool) {
 	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.evmHealthy = healthy
-	// Actualizar componente sin adquirir el lock de nuevo (ya lo tenemos)
-	name := "evm"
 	if healthy {
-		h.components[name] = ComponentStatus{
-			Status:    "ok",
-			Message:   "EVM operativo",
-			LastCheck: time.Now(),
-		}
+		h.setComponent("evm", "ok", "EVM operativo", nil)
 	} else {
-		h.components[name] = ComponentStatus{
-			Status:    "error",
-			Message:   "EVM no disponible",
-			LastCheck: time.Now(),
-		}
+		h.setComponent("evm", "error", "EVM no disponible", nil)
 	}
-	h.mu.Unlock()
 }
 
 // SetConsensusHealth actualiza el estado del consenso
 func (h *HealthChecker) SetConsensusHealth(healthy bool) {
 	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.consensusHealthy = healthy
-	// Actualizar componente sin adquirir el lock de nuevo (ya lo tenemos)
-	name := "consensus"
 	if healthy {
-		h.components[name] = ComponentStatus{
-			Status:    "ok",
-			Message:   "Consenso operativo",
-			LastCheck: time.Now(),
-		}
+		h.setComponent("consensus", "ok", "Consenso operativo", nil)
 	} else {
-		h.components[name] = ComponentStatus{
-			Status:    "error",
-			Message:   "Consenso no disponible",
-			LastCheck: time.Now(),
-		}
+		h.setComponent("consensus", "error", "Consenso no disponible", nil)
 	}
-	h.mu.Unlock()
 }
 
 // SetMeshHealth actualiza el estado de la mesh network
 func (h *HealthChecker) SetMeshHealth(healthy bool) {
+	h.SetMeshStatus(healthy, nil)
+}
+
+// SetMeshStatus actualiza el estado de la mesh network con la causa de la desconexión (si se conoce)
+func (h *HealthChecker) SetMeshStatus(healthy bool, cause error) {
 	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.meshHealthy = healthy
-	// Actualizar componente sin adquirir el lock de nuevo (ya lo tenemos)
-	name := "mesh"
 	if healthy {
-		h.components[name] = ComponentStatus{
-			Status:    "ok",
-			Message:   "Mesh network operativa",
-			LastCheck: time.Now(),
-		}
+		h.setComponent("mesh", "ok", "Mesh network operativa", nil)
 	} else {
-		h.components[name] = ComponentStatus{
-			Status:    "warning",
-			Message:   "Mesh network degradada",
-			LastCheck: time.Now(),
-		}
+		h.setComponent("mesh", "warning", "Mesh network degradada", cause)
 	}
-	h.mu.Unlock()
+}
+
+// SetMeshRTT registra el último round-trip ping/pong con el mesh
+func (h *HealthChecker) SetMeshRTT(rtt time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.meshRTT = rtt
+	comp := h.components["mesh"]
+	comp.RTTMs = durationMs(rtt)
+	h.components["mesh"] = comp
 }
 
 // IsHealthy retorna si el nodo está saludable
@@ -204,7 +257,7 @@ func (h *HealthChecker) IsHealthy() bool {
 func (h *HealthChecker) IsLive() bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	
+
 	// Liveness: solo verificar que los componentes críticos no estén todos caídos
 	// Si al menos uno está funcionando, el nodo está "live"
 	return h.storageHealthy || h.evmHealthy || h.consensusHealthy
@@ -215,8 +268,14 @@ func (h *HealthChecker) IsLive() bool {
 func (h *HealthChecker) IsReady() bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	
+
 	// Readiness: todos los componentes críticos deben estar operativos
-	return h.storageHealthy && h.evmHealthy && h.consensusHealthy
+	// y el nodo no debe estar sincronizando (para no servir estado desactualizado)
+	synced := !h.catchingUp && h.blocksBehind <= h.maxBlocksBehind
+	return h.storageHealthy && h.evmHealthy && h.consensusHealthy && synced
 }
 
+// durationMs convierte una duración a milisegundos con decimales
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
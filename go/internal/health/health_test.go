@@ -0,0 +1,95 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestHealthChecker_IsReady_Sync prueba que readiness dependa del estado de sincronización
+func TestHealthChecker_IsReady_Sync(t *testing.T) {
+	h := NewHealthChecker()
+	h.SetStorageHealth(true)
+	h.SetEVMHealth(true)
+	h.SetConsensusHealth(true)
+
+	if !h.IsReady() {
+		t.Error("Nodo con componentes operativos debería estar ready")
+	}
+
+	// Catching up: no ready
+	h.SetSyncStatus(true, 0)
+	if h.IsReady() {
+		t.Error("Nodo sincronizando no debería estar ready")
+	}
+
+	// Dentro del atraso máximo: ready
+	h.SetSyncStatus(false, DefaultMaxBlocksBehind)
+	if !h.IsReady() {
+		t.Error("Nodo dentro del atraso máximo debería estar ready")
+	}
+
+	// Fuera del atraso máximo: no ready
+	h.SetSyncStatus(false, DefaultMaxBlocksBehind+1)
+	if h.IsReady() {
+		t.Error("Nodo fuera del atraso máximo no debería estar ready")
+	}
+
+	status := h.CheckHealth()
+	if status.BlocksBehind != DefaultMaxBlocksBehind+1 || status.Components["sync"].Status != "warning" {
+		t.Errorf("Estado de sync incorrecto: %+v", status)
+	}
+
+	// Ajustar el umbral
+	h.SetMaxBlocksBehind(10)
+	if !h.IsReady() {
+		t.Error("Nodo debería estar ready con umbral mayor")
+	}
+}
+
+// TestHealthChecker_ComponentDetail prueba el historial de fallos, la duración y las métricas de cada componente
+func TestHealthChecker_ComponentDetail(t *testing.T) {
+	h := NewHealthChecker()
+
+	// Fallos seguidos con su causa
+	h.SetMeshStatus(false, errors.New("dial tcp: connection refused"))
+	h.SetMeshStatus(false, errors.New("dial tcp: i/o timeout"))
+	mesh := h.CheckHealth().Components["mesh"]
+	if mesh.Status != "warning" || mesh.ConsecutiveFailures != 2 || mesh.LastError != "dial tcp: i/o timeout" || mesh.LastErrorAt == nil {
+		t.Errorf("Fallos de mesh incorrectos: %+v", mesh)
+	}
+
+	// Al recuperarse se reinicia el contador pero se conserva la última causa
+	h.SetMeshStatus(true, nil)
+	h.SetMeshRTT(1500 * time.Microsecond)
+	status := h.CheckHealth()
+	mesh = status.Components["mesh"]
+	if mesh.Status != "ok" || mesh.ConsecutiveFailures != 0 || mesh.LastError != "dial tcp: i/o timeout" {
+		t.Errorf("Recuperación de mesh incorrecta: %+v", mesh)
+	}
+	if mesh.RTTMs != 1.5 || status.MeshRTTMs != 1.5 {
+		t.Errorf("RTT de mesh incorrecto: %v, %v", mesh.RTTMs, status.MeshRTTMs)
+	}
+
+	// Verificación fallida: warning con la causa y la duración
+	h.ReportCheck("sync", 20*time.Millisecond, errors.New("rpc no disponible"))
+	sync := h.CheckHealth().Components["sync"]
+	if sync.Status != "warning" || sync.LastError != "rpc no disponible" || sync.CheckDurationMs != 20 || sync.ConsecutiveFailures != 1 {
+		t.Errorf("Verificación fallida incorrecta: %+v", sync)
+	}
+
+	// Verificación exitosa: el estado lo fija SetSyncStatus, con el atraso en bloques
+	h.SetSyncStatus(true, 42)
+	h.ReportCheck("sync", 3*time.Millisecond, nil)
+	sync = h.CheckHealth().Components["sync"]
+	if sync.Status != "warning" || sync.BlocksBehind != 42 || sync.CheckDurationMs != 3 || sync.ConsecutiveFailures != 2 {
+		t.Errorf("Estado de sync incorrecto: %+v", sync)
+	}
+
+	// El mapa retornado es una copia
+	status = h.CheckHealth()
+	status.Components["sync"] = ComponentStatus{Status: "ok"}
+	if h.CheckHealth().Components["sync"].Status != "warning" {
+		t.Error("CheckHealth no debería exponer el mapa interno")
+	}
+}
@@ -0,0 +1,253 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// Levels es la configuración de niveles de log: un nivel por defecto y filtros por módulo
+// (ej: consensus=debug, api=warn). En texto se escribe como "info,consensus=debug,api=warn"
+type Levels struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// String serializa los niveles en el formato de ParseLevels (módulos en orden alfabético)
+func (l Levels) String() string {
+	parts := make([]string, 0, len(l.Modules)+1)
+	if l.Level != "" {
+		parts = append(parts, l.Level)
+	}
+	modules := make([]string, 0, len(l.Modules))
+	for module := range l.Modules {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	for _, module := range modules {
+		parts = append(parts, module+"="+l.Modules[module])
+	}
+	return strings.Join(parts, ",")
+}
+
+// levelState es la configuración vigente; se publica completa para que los loggers la lean sin locks
+type levelState struct {
+	base         zerolog.Logger
+	defaultLevel zerolog.Level
+	modules      map[string]zerolog.Level
+}
+
+var (
+	levelsMu sync.Mutex // Serializa los cambios de configuración
+	state    atomic.Pointer[levelState]
+)
+
+// ParseLevels parsea "nivel,modulo=nivel,...": una entrada sin "=" es el nivel por defecto
+// y las demás son filtros por módulo. Un nivel por defecto vacío conserva el actual al aplicarlo
+func ParseLevels(spec string) (Levels, error) {
+	levels := Levels{Modules: make(map[string]string)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		module, level, isModule := strings.Cut(entry, "=")
+		if !isModule {
+			if levels.Level != "" {
+				return Levels{}, fmt.Errorf("nivel por defecto duplicado: %q", entry)
+			}
+			levels.Level = strings.ToLower(entry)
+			continue
+		}
+		module = strings.ToLower(strings.TrimSpace(module))
+		if module == "" {
+			return Levels{}, fmt.Errorf("módulo vacío en %q", entry)
+		}
+		levels.Modules[module] = strings.ToLower(strings.TrimSpace(level))
+	}
+	if _, _, err := levels.parse(); err != nil {
+		return Levels{}, err
+	}
+	return levels, nil
+}
+
+// parse valida los niveles y los convierte a zerolog
+func (l Levels) parse() (*zerolog.Level, map[string]zerolog.Level, error) {
+	var defaultLevel *zerolog.Level
+	if l.Level != "" {
+		level, err := parseLevel(l.Level)
+		if err != nil {
+			return nil, nil, err
+		}
+		defaultLevel = &level
+	}
+	if l.Modules == nil {
+		return defaultLevel, nil, nil
+	}
+	modules := make(map[string]zerolog.Level, len(l.Modules))
+	for module, name := range l.Modules {
+		level, err := parseLevel(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("módulo %s: %w", module, err)
+		}
+		modules[strings.ToLower(module)] = level
+	}
+	return defaultLevel, modules, nil
+}
+
+// parseLevel parsea un nivel de zerolog (trace, debug, info, warn, error, fatal, panic, disabled)
+func parseLevel(name string) (zerolog.Level, error) {
+	level, err := zerolog.ParseLevel(strings.ToLower(strings.TrimSpace(name)))
+	if err != nil || level == zerolog.NoLevel {
+		return zerolog.NoLevel, fmt.Errorf("nivel de log inválido: %q", name)
+	}
+	return level, nil
+}
+
+// SetLevels cambia los niveles en runtime sin reiniciar el nodo. Level vacío conserva el nivel por defecto
+// y Modules nil conserva los filtros actuales; un mapa (aunque esté vacío) los reemplaza por completo
+func SetLevels(levels Levels) error {
+	defaultLevel, modules, err := levels.parse()
+	if err != nil {
+		return err
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	current := state.Load()
+	if defaultLevel == nil {
+		defaultLevel = &current.defaultLevel
+	}
+	if modules == nil {
+		modules = current.modules
+	}
+	publish(levelState{base: current.base, defaultLevel: *defaultLevel, modules: modules})
+	return nil
+}
+
+// GetLevels retorna la configuración de niveles vigente
+func GetLevels() Levels {
+	current := state.Load()
+	levels := Levels{Level: current.defaultLevel.String(), Modules: make(map[string]string, len(current.modules))}
+	for module, level := range current.modules {
+		levels.Modules[module] = level.String()
+	}
+	return levels
+}
+
+// setBase reemplaza el output del logger conservando los niveles
+func setBase(base zerolog.Logger) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	next := levelState{base: base, defaultLevel: zerolog.InfoLevel}
+	if current := state.Load(); current != nil {
+		next.defaultLevel, next.modules = current.defaultLevel, current.modules
+	}
+	publish(next)
+}
+
+// applyLevels fija el nivel por defecto (modules nil conserva los filtros)
+func applyLevels(defaultLevel zerolog.Level, modules map[string]zerolog.Level) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	current := state.Load()
+	if modules == nil {
+		modules = current.modules
+	}
+	publish(levelState{base: current.base, defaultLevel: defaultLevel, modules: modules})
+}
+
+// publish activa una configuración: el nivel global de zerolog es el más bajo de todos
+// (para no descartar los módulos más verbosos) y cada logger filtra con su propio nivel
+func publish(next levelState) {
+	minLevel := next.defaultLevel
+	for _, level := range next.modules {
+		if level < minLevel {
+			minLevel = level
+		}
+	}
+	global := next.base.Level(next.defaultLevel)
+	state.Store(&next)
+	globalLogger.Store(&global)
+	zerolog.SetGlobalLevel(minLevel)
+}
+
+// ModuleLogger es el logger de un módulo (consensus, api, ...), filtrado con el nivel
+// configurado para ese módulo o con el nivel por defecto si no tiene filtro
+type ModuleLogger struct {
+	name string
+}
+
+// For retorna el logger del módulo name
+func For(name string) ModuleLogger {
+	return ModuleLogger{name: strings.ToLower(name)}
+}
+
+// Logger retorna el logger del módulo con el nivel vigente
+func (m ModuleLogger) Logger() zerolog.Logger {
+	current := state.Load()
+	level, ok := current.modules[m.name]
+	if !ok {
+		level = current.defaultLevel
+	}
+	return current.base.Level(level).With().Str("module", m.name).Logger()
+}
+
+// Ctx retorna el logger del módulo con trace_id y span_id del span activo de ctx
+func (m ModuleLogger) Ctx(ctx context.Context) *zerolog.Logger {
+	logger := withTrace(ctx, m.Logger())
+	return &logger
+}
+
+// Debug logs a debug message
+func (m ModuleLogger) Debug(msg string) {
+	logger := m.Logger()
+	logger.Debug().Msg(msg)
+}
+
+// Info logs an info message
+func (m ModuleLogger) Info(msg string) {
+	logger := m.Logger()
+	logger.Info().Msg(msg)
+}
+
+// Warn logs a warning message
+func (m ModuleLogger) Warn(msg string) {
+	logger := m.Logger()
+	logger.Warn().Msg(msg)
+}
+
+// Error logs an error message
+func (m ModuleLogger) Error(msg string) {
+	logger := m.Logger()
+	logger.Error().Msg(msg)
+}
+
+// Debugf logs a formatted debug message
+func (m ModuleLogger) Debugf(format string, args ...interface{}) {
+	logger := m.Logger()
+	logger.Debug().Msgf(format, args...)
+}
+
+// Infof logs a formatted info message
+func (m ModuleLogger) Infof(format string, args ...interface{}) {
+	logger := m.Logger()
+	logger.Info().Msgf(format, args...)
+}
+
+// Warnf logs a formatted warning message
+func (m ModuleLogger) Warnf(format string, args ...interface{}) {
+	logger := m.Logger()
+	logger.Warn().Msgf(format, args...)
+}
+
+// Errorf logs a formatted error message
+func (m ModuleLogger) Errorf(format string, args ...interface{}) {
+	logger := m.Logger()
+	logger.Error().Msgf(format, args...)
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// TestParseLevels prueba el formato "nivel,modulo=nivel"
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels(" info, consensus=DEBUG ,api=warn,")
+	if err != nil {
+		t.Fatalf("Error parseando niveles: %v", err)
+	}
+	if levels.Level != "info" || levels.Modules["consensus"] != "debug" || levels.Modules["api"] != "warn" {
+		t.Errorf("Niveles inesperados: %+v", levels)
+	}
+	if levels.String() != "info,api=warn,consensus=debug" {
+		t.Errorf("Serialización inesperada: %s", levels.String())
+	}
+
+	for _, invalid := range []string{"verbose", "info,debug", "consensus=", "=debug", "api=loud"} {
+		if _, err := ParseLevels(invalid); err == nil {
+			t.Errorf("Especificación inválida aceptada: %q", invalid)
+		}
+	}
+}
+
+// TestSetLevels prueba que los filtros por módulo se apliquen en runtime sobre los loggers existentes
+func TestSetLevels(t *testing.T) {
+	previous := GetLevels()
+	var out bytes.Buffer
+	setBase(zerolog.New(&out))
+	defer func() {
+		SetLevels(previous)
+		Init(previous.Level, false)
+	}()
+
+	consensus := For("consensus")
+	api := For("api")
+	if err := SetLevels(Levels{Level: "info", Modules: map[string]string{}}); err != nil {
+		t.Fatalf("Error aplicando niveles: %v", err)
+	}
+	consensus.Debug("oculto")
+	Debug("oculto")
+
+	if err := SetLevels(Levels{Modules: map[string]string{"consensus": "debug", "api": "error"}}); err != nil {
+		t.Fatalf("Error aplicando niveles: %v", err)
+	}
+	consensus.Debug("consensus visible")
+	api.Warn("oculto")
+	Debug("oculto")
+	Info("global visible")
+
+	logged := out.String()
+	if strings.Contains(logged, "oculto") {
+		t.Errorf("Se registró un mensaje filtrado: %s", logged)
+	}
+	if !strings.Contains(logged, `"module":"consensus"`) || !strings.Contains(logged, "consensus visible") || !strings.Contains(logged, "global visible") {
+		t.Errorf("Faltan mensajes esperados: %s", logged)
+	}
+
+	// Level vacío y Modules nil conservan la configuración actual
+	if err := SetLevels(Levels{}); err != nil {
+		t.Fatalf("Error aplicando niveles: %v", err)
+	}
+	if got := GetLevels(); got.Level != "info" || got.Modules["consensus"] != "debug" || got.Modules["api"] != "error" {
+		t.Errorf("Niveles inesperados: %+v", got)
+	}
+	if err := SetLevels(Levels{Level: "loud"}); err == nil {
+		t.Error("Nivel inválido aceptado")
+	}
+}
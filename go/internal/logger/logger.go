@@ -1,14 +1,18 @@
 package logger
 
 import (
+	"context"
 	"os"
+	"sync/atomic"
 	"time"
 
+	"github.com/Q-YZX0/oxy-blockchain/internal/tracing"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
-var globalLogger zerolog.Logger
+// globalLogger es el logger del proceso; se reemplaza atómicamente al cambiar el nivel en runtime
+var globalLogger atomic.Pointer[zerolog.Logger]
 
 func init() {
 	// Configurar logger global
@@ -23,24 +27,23 @@ func init() {
 		NoColor:    false,
 	}
 
-	globalLogger = log.Output(output)
+	setBase(log.Output(output))
 }
 
 // Init inicializa el logger con configuración
 func Init(logLevel string, useJSON bool) {
 	// Configurar nivel de log
 	level, err := zerolog.ParseLevel(logLevel)
-	if err != nil {
+	if err != nil || logLevel == "" {
 		level = zerolog.InfoLevel
 	}
-	zerolog.SetGlobalLevel(level)
 
 	// Configurar output
 	if useJSON {
 		// JSON format para producción
-		globalLogger = zerolog.New(os.Stderr).With().
+		setBase(zerolog.New(os.Stderr).With().
 			Timestamp().
-			Logger()
+			Logger())
 	} else {
 		// Console format para desarrollo
 		output := zerolog.ConsoleWriter{
@@ -48,18 +51,24 @@ func Init(logLevel string, useJSON bool) {
 			TimeFormat: time.RFC3339,
 			NoColor:    false,
 		}
-		globalLogger = log.Output(output)
+		setBase(log.Output(output))
 	}
+	applyLevels(level, nil)
+}
+
+// current retorna el logger global con el nivel por defecto aplicado
+func current() *zerolog.Logger {
+	return globalLogger.Load()
 }
 
 // Logger retorna el logger global configurado
 func Logger() zerolog.Logger {
-	return globalLogger
+	return *current()
 }
 
 // WithContext añade contexto al logger
 func WithContext(fields map[string]interface{}) zerolog.Logger {
-	logger := globalLogger
+	logger := *current()
 	for k, v := range fields {
 		logger = logger.With().Interface(k, v).Logger()
 	}
@@ -68,57 +77,76 @@ func WithContext(fields map[string]interface{}) zerolog.Logger {
 
 // Debug logs a debug message
 func Debug(msg string) {
-	globalLogger.Debug().Msg(msg)
+	current().Debug().Msg(msg)
 }
 
 // Info logs an info message
 func Info(msg string) {
-	globalLogger.Info().Msg(msg)
+	current().Info().Msg(msg)
 }
 
 // Warn logs a warning message
 func Warn(msg string) {
-	globalLogger.Warn().Msg(msg)
+	current().Warn().Msg(msg)
 }
 
 // Error logs an error message
 func Error(msg string) {
-	globalLogger.Error().Msg(msg)
+	current().Error().Msg(msg)
 }
 
 // Fatal logs a fatal message and exits
 func Fatal(msg string) {
-	globalLogger.Fatal().Msg(msg)
+	current().Fatal().Msg(msg)
 }
 
 // Debugf logs a formatted debug message
 func Debugf(format string, args ...interface{}) {
-	globalLogger.Debug().Msgf(format, args...)
+	current().Debug().Msgf(format, args...)
 }
 
 // Infof logs a formatted info message
 func Infof(format string, args ...interface{}) {
-	globalLogger.Info().Msgf(format, args...)
+	current().Info().Msgf(format, args...)
 }
 
 // Warnf logs a formatted warning message
 func Warnf(format string, args ...interface{}) {
-	globalLogger.Warn().Msgf(format, args...)
+	current().Warn().Msgf(format, args...)
 }
 
 // Errorf logs a formatted error message
 func Errorf(format string, args ...interface{}) {
-	globalLogger.Error().Msgf(format, args...)
+	current().Error().Msgf(format, args...)
 }
 
 // Fatalf logs a formatted fatal message and exits
 func Fatalf(format string, args ...interface{}) {
-	globalLogger.Fatal().Msgf(format, args...)
+	current().Fatal().Msgf(format, args...)
+}
+
+// Ctx retorna el logger con trace_id y span_id del span activo de ctx (si lo hay),
+// para correlacionar los logs con las trazas en Jaeger/Tempo
+func Ctx(ctx context.Context) *zerolog.Logger {
+	logger := withTrace(ctx, *current())
+	return &logger
+}
+
+// withTrace agrega trace_id y span_id del span activo de ctx a un logger
+func withTrace(ctx context.Context, logger zerolog.Logger) zerolog.Logger {
+	sc := tracing.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return logger
+	}
+	return logger.With().
+		Str("trace_id", sc.TraceID.String()).
+		Str("span_id", sc.SpanID.String()).
+		Logger()
 }
 
 // WithField añade un campo al logger
 func WithField(key string, value interface{}) zerolog.Logger {
-	return globalLogger.With().Interface(key, value).Logger()
+	return current().With().Interface(key, value).Logger()
 }
 
 // WithFields añade múltiples campos al logger
@@ -128,6 +156,6 @@ func WithFields(fields map[string]interface{}) zerolog.Logger {
 
 // WithError añade un error al logger
 func WithError(err error) zerolog.Logger {
-	return globalLogger.With().Err(err).Logger()
+	return current().With().Err(err).Logger()
 }
 
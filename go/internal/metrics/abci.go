@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// ABCIDurationBuckets son los límites superiores (en segundos) del histograma de latencia de los métodos ABCI
+var ABCIDurationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Códigos de resultado de los métodos ABCI que no retornan un código numérico
+const (
+	ABCICodeOK     = "0"      // Respuesta exitosa (código ABCI 0)
+	ABCICodeError  = "error"  // El método retornó un error de Go
+	ABCICodeReject = "reject" // ProcessProposal rechazó la propuesta
+)
+
+// abciKey identifica una serie por método y código de resultado
+type abciKey struct {
+	method string
+	code   string
+}
+
+// histogram acumula las observaciones de una serie (llamadas ABCI, peticiones HTTP)
+type histogram struct {
+	count   uint64
+	sum     float64  // Segundos
+	buckets []uint64 // Observaciones por bucket (no acumulado)
+}
+
+// newHistogram crea un histograma con un bucket por límite
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{buckets: make([]uint64, len(bounds))}
+}
+
+// observe registra una duración en el primer bucket que la contiene (las mayores solo cuentan en +Inf)
+func (h *histogram) observe(duration time.Duration, bounds []float64) {
+	seconds := duration.Seconds()
+	h.count++
+	h.sum += seconds
+	for i, bound := range bounds {
+		if seconds <= bound {
+			h.buckets[i]++
+			break
+		}
+	}
+}
+
+// cumulative retorna los buckets acumulados (formato Prometheus, sin +Inf)
+func (h *histogram) cumulative() []uint64 {
+	cumulative := make([]uint64, len(h.buckets))
+	var total uint64
+	for i, n := range h.buckets {
+		total += n
+		cumulative[i] = total
+	}
+	return cumulative
+}
+
+// ABCIMethodStats es la latencia y cantidad de llamadas de un método ABCI con un código de resultado
+type ABCIMethodStats struct {
+	Method     string   `json:"method"`
+	Code       string   `json:"code"`
+	Count      uint64   `json:"count"`
+	SumSeconds float64  `json:"sumSeconds"`
+	Buckets    []uint64 `json:"buckets"` // Acumulado por ABCIDurationBuckets (formato Prometheus, sin +Inf)
+}
+
+// IsError retorna si la serie corresponde a llamadas fallidas
+func (s ABCIMethodStats) IsError() bool {
+	return s.Code != ABCICodeOK
+}
+
+// ObserveABCI registra la duración de una llamada a un método ABCI (Info, CheckTx, PrepareProposal,
+// ProcessProposal, FinalizeBlock, Commit) con su código de resultado
+func (m *Metrics) ObserveABCI(method string, code string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.abci == nil {
+		m.abci = make(map[abciKey]*histogram)
+	}
+	key := abciKey{method: method, code: code}
+	h, exists := m.abci[key]
+	if !exists {
+		h = newHistogram(ABCIDurationBuckets)
+		m.abci[key] = h
+	}
+	h.observe(duration, ABCIDurationBuckets)
+}
+
+// GetABCIMetrics retorna las series de los métodos ABCI ordenadas por método y código
+func (m *Metrics) GetABCIMetrics() []ABCIMethodStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.abciStats()
+}
+
+// abciStats construye las series (requiere m.mu)
+func (m *Metrics) abciStats() []ABCIMethodStats {
+	stats := make([]ABCIMethodStats, 0, len(m.abci))
+	for key, h := range m.abci {
+		stats = append(stats, ABCIMethodStats{
+			Method:     key.method,
+			Code:       key.code,
+			Count:      h.count,
+			SumSeconds: h.sum,
+			Buckets:    h.cumulative(),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Method != stats[j].Method {
+			return stats[i].Method < stats[j].Method
+		}
+		return stats[i].Code < stats[j].Code
+	})
+	return stats
+}
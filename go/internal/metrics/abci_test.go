@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+// TestObserveABCI prueba el histograma acumulado y las series por método y código
+func TestObserveABCI(t *testing.T) {
+	m := NewMetrics()
+	m.ObserveABCI("FinalizeBlock", ABCICodeOK, 3*time.Millisecond)
+	m.ObserveABCI("FinalizeBlock", ABCICodeOK, 200*time.Millisecond)
+	m.ObserveABCI("FinalizeBlock", ABCICodeOK, 30*time.Second) // Solo en +Inf
+	m.ObserveABCI("CheckTx", "4", time.Millisecond)
+
+	stats := m.GetABCIMetrics()
+	if len(stats) != 2 || stats[0].Method != "CheckTx" || stats[1].Method != "FinalizeBlock" {
+		t.Fatalf("Series inesperadas: %+v", stats)
+	}
+
+	checkTx := stats[0]
+	if !checkTx.IsError() || checkTx.Count != 1 || checkTx.Buckets[0] != 1 {
+		t.Errorf("CheckTx con código 4 debería contarse como error en el primer bucket: %+v", checkTx)
+	}
+
+	finalize := stats[1]
+	if finalize.IsError() || finalize.Count != 3 {
+		t.Errorf("FinalizeBlock inesperado: %+v", finalize)
+	}
+	// Buckets acumulados: 0.005 -> 1, 0.25 -> 2, 10 -> 2 (la llamada de 30s solo cuenta en +Inf)
+	if finalize.Buckets[1] != 1 || finalize.Buckets[6] != 2 || finalize.Buckets[len(ABCIDurationBuckets)-1] != 2 {
+		t.Errorf("Buckets inesperados: %v", finalize.Buckets)
+	}
+	if finalize.SumSeconds < 30.2 || finalize.SumSeconds > 30.21 {
+		t.Errorf("Suma inesperada: %f", finalize.SumSeconds)
+	}
+
+	if len(m.GetMetrics().ABCIMethods) != 2 {
+		t.Error("GetMetrics debería incluir las series ABCI")
+	}
+	m.Reset()
+	if len(m.GetABCIMetrics()) != 0 {
+		t.Error("Reset debería limpiar las series ABCI")
+	}
+}
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultBlockWindowSize es la cantidad de bloques confirmados sobre la que se calculan TPS e intervalos
+const DefaultBlockWindowSize = 100
+
+// committedBlock es un bloque confirmado dentro de la ventana
+type committedBlock struct {
+	height  uint64
+	txCount int
+	time    time.Time // Hora del header del bloque (tiempo de consenso)
+}
+
+// blockWindow guarda los últimos bloques confirmados en un buffer circular
+type blockWindow struct {
+	blocks []committedBlock
+	next   int
+	full   bool
+}
+
+// newBlockWindow crea una ventana de size bloques (<= 1 = por defecto)
+func newBlockWindow(size int) *blockWindow {
+	if size <= 1 {
+		size = DefaultBlockWindowSize
+	}
+	return &blockWindow{blocks: make([]committedBlock, size)}
+}
+
+// add agrega un bloque; una altura que no avanza (replay, reinicio) descarta la ventana
+func (w *blockWindow) add(block committedBlock) {
+	if last, ok := w.last(); ok && block.height <= last.height {
+		w.next, w.full = 0, false
+	}
+	w.blocks[w.next] = block
+	w.next = (w.next + 1) % len(w.blocks)
+	if w.next == 0 {
+		w.full = true
+	}
+}
+
+// last retorna el último bloque agregado
+func (w *blockWindow) last() (committedBlock, bool) {
+	if !w.full && w.next == 0 {
+		return committedBlock{}, false
+	}
+	return w.blocks[(w.next-1+len(w.blocks))%len(w.blocks)], true
+}
+
+// ordered retorna los bloques de la ventana del más antiguo al más reciente
+func (w *blockWindow) ordered() []committedBlock {
+	if !w.full {
+		return append([]committedBlock(nil), w.blocks[:w.next]...)
+	}
+	return append(append([]committedBlock(nil), w.blocks[w.next:]...), w.blocks[:w.next]...)
+}
+
+// blockWindowStats son las estadísticas de la ventana
+type blockWindowStats struct {
+	tps         float64
+	avgInterval time.Duration
+	p50Interval time.Duration
+	p95Interval time.Duration
+}
+
+// stats calcula el TPS sostenido (transacciones después del primer bloque sobre el tiempo transcurrido)
+// y los percentiles del intervalo entre bloques consecutivos. Con menos de dos bloques retorna ok = false
+func (w *blockWindow) stats() (blockWindowStats, bool) {
+	blocks := w.ordered()
+	if len(blocks) < 2 {
+		return blockWindowStats{}, false
+	}
+
+	intervals := make([]time.Duration, 0, len(blocks)-1)
+	txs := 0
+	for i := 1; i < len(blocks); i++ {
+		interval := blocks[i].time.Sub(blocks[i-1].time)
+		if interval < 0 {
+			interval = 0
+		}
+		intervals = append(intervals, interval)
+		txs += blocks[i].txCount
+	}
+
+	var stats blockWindowStats
+	elapsed := blocks[len(blocks)-1].time.Sub(blocks[0].time)
+	if elapsed > 0 {
+		stats.tps = float64(txs) / elapsed.Seconds()
+		stats.avgInterval = elapsed / time.Duration(len(intervals))
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	stats.p50Interval = percentile(intervals, 0.50)
+	stats.p95Interval = percentile(intervals, 0.95)
+	return stats, true
+}
+
+// percentile retorna el percentil p (nearest-rank) de valores ordenados
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// RecordCommittedBlock registra un bloque confirmado (llamado desde Commit) para calcular
+// TPS sostenido e intervalos entre bloques sobre los últimos DefaultBlockWindowSize bloques
+func (m *Metrics) RecordCommittedBlock(height uint64, txCount int, blockTime time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.blockWindow == nil {
+		m.blockWindow = newBlockWindow(DefaultBlockWindowSize)
+	}
+	m.blockWindow.add(committedBlock{height: height, txCount: txCount, time: blockTime})
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRecordCommittedBlock prueba TPS sostenido y percentiles de intervalo sobre la ventana de bloques
+func TestRecordCommittedBlock(t *testing.T) {
+	m := NewMetrics()
+	start := time.Unix(1700000000, 0)
+
+	// Sin bloques suficientes no hay intervalos
+	m.RecordCommittedBlock(1, 5, start)
+	if got := m.GetMetrics(); got.BlockIntervalAverage != 0 {
+		t.Errorf("Con un bloque no debería haber intervalo: %s", got.BlockIntervalAverage)
+	}
+
+	// Intervalos: 9 de 1s y uno de 10s; 10 transacciones por bloque
+	at := start
+	for height := uint64(2); height <= 11; height++ {
+		if height == 11 {
+			at = at.Add(10 * time.Second)
+		} else {
+			at = at.Add(time.Second)
+		}
+		m.RecordCommittedBlock(height, 10, at)
+	}
+
+	got := m.GetMetrics()
+	if got.BlockIntervalP50 != time.Second || got.BlockIntervalP95 != 10*time.Second {
+		t.Errorf("Percentiles inesperados: p50=%s p95=%s", got.BlockIntervalP50, got.BlockIntervalP95)
+	}
+	if got.BlockIntervalAverage != 1900*time.Millisecond {
+		t.Errorf("Intervalo promedio inesperado: %s", got.BlockIntervalAverage)
+	}
+	// 100 transacciones (sin contar el primer bloque) en 19s
+	if tps := got.TransactionsPerSecond; tps < 5.26 || tps > 5.27 {
+		t.Errorf("TPS inesperado: %f", tps)
+	}
+}
+
+// TestBlockWindow_Rotation prueba el buffer circular y el descarte cuando la altura no avanza
+func TestBlockWindow_Rotation(t *testing.T) {
+	w := newBlockWindow(3)
+	start := time.Unix(1700000000, 0)
+	for height := uint64(1); height <= 5; height++ {
+		w.add(committedBlock{height: height, txCount: 1, time: start.Add(time.Duration(height) * time.Second)})
+	}
+	blocks := w.ordered()
+	if len(blocks) != 3 || blocks[0].height != 3 || blocks[2].height != 5 {
+		t.Fatalf("Ventana inesperada: %+v", blocks)
+	}
+
+	// Replay desde una altura anterior: la ventana empieza de nuevo
+	w.add(committedBlock{height: 2, time: start})
+	if blocks := w.ordered(); len(blocks) != 1 || blocks[0].height != 2 {
+		t.Errorf("La ventana debería descartarse: %+v", blocks)
+	}
+	if _, ok := w.stats(); ok {
+		t.Error("Con un bloque no debería haber estadísticas")
+	}
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// HTTPDurationBuckets son los límites superiores (en segundos) del histograma de latencia de las peticiones REST
+var HTTPDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// HTTPRouteUnmatched es la ruta de las peticiones que no corresponden a ningún endpoint (evita una serie por path)
+const HTTPRouteUnmatched = "unmatched"
+
+// httpKey identifica una serie por método, ruta y clase de status
+type httpKey struct {
+	method      string
+	route       string
+	statusClass string
+}
+
+// HTTPRequestStats es la latencia y cantidad de peticiones REST de una ruta con una clase de status
+type HTTPRequestStats struct {
+	Method      string   `json:"method"`
+	Route       string   `json:"route"`       // Ruta registrada (ej: /api/v1/blocks/), no el path pedido
+	StatusClass string   `json:"statusClass"` // 2xx, 3xx, 4xx o 5xx
+	Count       uint64   `json:"count"`
+	SumSeconds  float64  `json:"sumSeconds"`
+	Buckets     []uint64 `json:"buckets"` // Acumulado por HTTPDurationBuckets (formato Prometheus, sin +Inf)
+}
+
+// IsError retorna si la serie corresponde a peticiones con error (4xx o 5xx)
+func (s HTTPRequestStats) IsError() bool {
+	return s.StatusClass == "4xx" || s.StatusClass == "5xx"
+}
+
+// StatusClass retorna la clase de un status HTTP (ej: 404 -> "4xx")
+func StatusClass(status int) string {
+	return fmt.Sprintf("%dxx", status/100)
+}
+
+// ObserveHTTPRequest registra la duración de una petición REST por método, ruta registrada y status
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = HTTPRouteUnmatched
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.http == nil {
+		m.http = make(map[httpKey]*histogram)
+	}
+	key := httpKey{method: method, route: route, statusClass: StatusClass(status)}
+	h, exists := m.http[key]
+	if !exists {
+		h = newHistogram(HTTPDurationBuckets)
+		m.http[key] = h
+	}
+	h.observe(duration, HTTPDurationBuckets)
+}
+
+// IncrementRateLimited cuenta una petición rechazada por el rate limit en una clase de endpoint
+func (m *Metrics) IncrementRateLimited(class string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rateLimited == nil {
+		m.rateLimited = make(map[string]uint64)
+	}
+	m.rateLimited[class]++
+}
+
+// GetHTTPMetrics retorna las series de las peticiones REST ordenadas por ruta, método y clase de status
+func (m *Metrics) GetHTTPMetrics() []HTTPRequestStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.httpStats()
+}
+
+// httpStats construye las series (requiere m.mu)
+func (m *Metrics) httpStats() []HTTPRequestStats {
+	stats := make([]HTTPRequestStats, 0, len(m.http))
+	for key, h := range m.http {
+		stats = append(stats, HTTPRequestStats{
+			Method:      key.method,
+			Route:       key.route,
+			StatusClass: key.statusClass,
+			Count:       h.count,
+			SumSeconds:  h.sum,
+			Buckets:     h.cumulative(),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Route != stats[j].Route {
+			return stats[i].Route < stats[j].Route
+		}
+		if stats[i].Method != stats[j].Method {
+			return stats[i].Method < stats[j].Method
+		}
+		return stats[i].StatusClass < stats[j].StatusClass
+	})
+	return stats
+}
+
+// rateLimitedCopy copia los contadores de rate limit (requiere m.mu)
+func (m *Metrics) rateLimitedCopy() map[string]uint64 {
+	counts := make(map[string]uint64, len(m.rateLimited))
+	for class, n := range m.rateLimited {
+		counts[class] = n
+	}
+	return counts
+}
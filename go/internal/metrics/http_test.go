@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+// TestObserveHTTPRequest prueba las series por ruta y clase de status y los contadores de rate limit
+func TestObserveHTTPRequest(t *testing.T) {
+	m := NewMetrics()
+	m.ObserveHTTPRequest("GET", "/api/v1/blocks/", 200, 20*time.Millisecond)
+	m.ObserveHTTPRequest("GET", "/api/v1/blocks/", 204, 2*time.Millisecond)
+	m.ObserveHTTPRequest("GET", "/api/v1/blocks/", 404, time.Millisecond)
+	m.ObserveHTTPRequest("GET", "", 404, time.Millisecond)
+	m.IncrementRateLimited("read")
+	m.IncrementRateLimited("read")
+
+	stats := m.GetHTTPMetrics()
+	if len(stats) != 3 || stats[0].Route != "/api/v1/blocks/" || stats[2].Route != HTTPRouteUnmatched {
+		t.Fatalf("Series inesperadas: %+v", stats)
+	}
+	ok := stats[0]
+	if ok.StatusClass != "2xx" || ok.IsError() || ok.Count != 2 || ok.Buckets[0] != 1 || ok.Buckets[2] != 2 {
+		t.Errorf("Serie 2xx inesperada: %+v", ok)
+	}
+	if notFound := stats[1]; notFound.StatusClass != "4xx" || !notFound.IsError() || notFound.Count != 1 {
+		t.Errorf("Serie 4xx inesperada: %+v", notFound)
+	}
+
+	snapshot := m.GetMetrics()
+	if len(snapshot.HTTPRequests) != 3 || snapshot.RateLimitedRequests["read"] != 2 {
+		t.Errorf("GetMetrics debería incluir las series HTTP y el rate limit: %+v", snapshot.RateLimitedRequests)
+	}
+	m.Reset()
+	if len(m.GetHTTPMetrics()) != 0 || len(m.GetMetrics().RateLimitedRequests) != 0 {
+		t.Error("Reset debería limpiar las series HTTP")
+	}
+}
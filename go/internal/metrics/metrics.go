@@ -16,8 +16,14 @@ type Metrics struct {
 	// Métricas de transacciones
 	TransactionsProcessed uint64
 	TransactionsRejected  uint64
-	TransactionsPerSecond  float64
+	TransactionsPerSecond  float64 // Sostenido sobre los últimos bloques confirmados
 	
+	// Intervalo entre bloques confirmados (últimos DefaultBlockWindowSize bloques)
+	BlockIntervalAverage time.Duration
+	BlockIntervalP50     time.Duration
+	BlockIntervalP95     time.Duration
+	blockWindow          *blockWindow
+
 	// Métricas de red
 	PeersConnected     int
 	MessagesReceived   uint64
@@ -32,6 +38,29 @@ type Metrics struct {
 	AverageGasUsed     uint64
 	TotalGasUsed       uint64
 	
+	// Métricas de emisión de OXG (en wei, como texto porque exceden uint64)
+	TotalSupply        string
+	CirculatingSupply  string
+	BurnedSupply       string
+
+	// Última auditoría de invariantes contables (ver SetInvariantViolations)
+	InvariantsAudited   bool
+	InvariantViolations int
+
+	// Latencias y errores por método ABCI (ver ObserveABCI)
+	ABCIMethods        []ABCIMethodStats
+	abci               map[abciKey]*histogram
+
+	// Peticiones REST por ruta y clase de status, y peticiones rechazadas por rate limit (ver ObserveHTTPRequest)
+	HTTPRequests        []HTTPRequestStats
+	RateLimitedRequests map[string]uint64 // Por clase de endpoint (read/write)
+	http                map[httpKey]*histogram
+	rateLimited         map[string]uint64
+
+	// Pánicos recuperados por componente (ver IncrementPanics)
+	PanicsRecovered map[string]uint64
+	panics          map[string]uint64
+
 	// Timestamps
 	LastBlockTime      time.Time
 	Uptime             time.Duration
@@ -52,13 +81,26 @@ func (m *Metrics) GetMetrics() Metrics {
 	
 	// Calcular uptime
 	uptime := time.Since(m.StartTime)
+
+	// TPS e intervalos desde los bloques confirmados; sin bloques suficientes, promedio desde el arranque
+	tps := m.calculateTPS()
+	var window blockWindowStats
+	if m.blockWindow != nil {
+		if stats, ok := m.blockWindow.stats(); ok {
+			window = stats
+			tps = stats.tps
+		}
+	}
 	
 	return Metrics{
 		BlocksProcessed:       m.BlocksProcessed,
 		BlockProcessingTime:   m.BlockProcessingTime,
 		TransactionsProcessed: m.TransactionsProcessed,
 		TransactionsRejected:  m.TransactionsRejected,
-		TransactionsPerSecond: m.calculateTPS(),
+		TransactionsPerSecond: tps,
+		BlockIntervalAverage:  window.avgInterval,
+		BlockIntervalP50:      window.p50Interval,
+		BlockIntervalP95:      window.p95Interval,
 		PeersConnected:        m.PeersConnected,
 		MessagesReceived:      m.MessagesReceived,
 		MessagesSent:          m.MessagesSent,
@@ -67,6 +109,15 @@ func (m *Metrics) GetMetrics() Metrics {
 		MempoolSize:           m.MempoolSize,
 		AverageGasUsed:        m.AverageGasUsed,
 		TotalGasUsed:          m.TotalGasUsed,
+		TotalSupply:           m.TotalSupply,
+		CirculatingSupply:     m.CirculatingSupply,
+		BurnedSupply:          m.BurnedSupply,
+		InvariantsAudited:     m.InvariantsAudited,
+		InvariantViolations:   m.InvariantViolations,
+		ABCIMethods:           m.abciStats(),
+		HTTPRequests:          m.httpStats(),
+		RateLimitedRequests:   m.rateLimitedCopy(),
+		PanicsRecovered:       m.panicsCopy(),
 		LastBlockTime:         m.LastBlockTime,
 		Uptime:                uptime,
 		StartTime:             m.StartTime,
@@ -158,6 +209,23 @@ func (m *Metrics) AddGasUsed(gas uint64) {
 	}
 }
 
+// SetSupply actualiza las métricas de emisión
+func (m *Metrics) SetSupply(total, circulating, burned string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.TotalSupply = total
+	m.CirculatingSupply = circulating
+	m.BurnedSupply = burned
+}
+
+// SetInvariantViolations registra el resultado de la última auditoría de invariantes
+func (m *Metrics) SetInvariantViolations(violations int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.InvariantsAudited = true
+	m.InvariantViolations = violations
+}
+
 // calculateTPS calcula transacciones por segundo
 func (m *Metrics) calculateTPS() float64 {
 	uptime := time.Since(m.StartTime).Seconds()
@@ -178,6 +246,11 @@ func (m *Metrics) Reset() {
 	m.MessagesSent = 0
 	m.TotalGasUsed = 0
 	m.AverageGasUsed = 0
+	m.abci = nil
+	m.http = nil
+	m.rateLimited = nil
+	m.panics = nil
+	m.blockWindow = nil
 	m.StartTime = time.Now()
 }
 
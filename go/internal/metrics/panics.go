@@ -0,0 +1,26 @@
+package metrics
+
+// Componentes en los que se recuperan pánicos (etiqueta component de oxy_panics_recovered_total)
+const (
+	PanicComponentHTTP = "http" // Handlers del API REST
+	PanicComponentABCI = "abci" // Ejecución de transacciones en FinalizeBlock
+)
+
+// IncrementPanics cuenta un pánico recuperado en un componente
+func (m *Metrics) IncrementPanics(component string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.panics == nil {
+		m.panics = make(map[string]uint64)
+	}
+	m.panics[component]++
+}
+
+// panicsCopy copia los contadores de pánicos recuperados (requiere m.mu)
+func (m *Metrics) panicsCopy() map[string]uint64 {
+	counts := make(map[string]uint64, len(m.panics))
+	for component, n := range m.panics {
+		counts[component] = n
+	}
+	return counts
+}
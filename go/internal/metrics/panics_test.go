@@ -0,0 +1,20 @@
+package metrics
+
+import "testing"
+
+// TestIncrementPanics prueba los contadores de pánicos recuperados por componente
+func TestIncrementPanics(t *testing.T) {
+	m := NewMetrics()
+	m.IncrementPanics(PanicComponentHTTP)
+	m.IncrementPanics(PanicComponentABCI)
+	m.IncrementPanics(PanicComponentABCI)
+
+	panics := m.GetMetrics().PanicsRecovered
+	if panics[PanicComponentHTTP] != 1 || panics[PanicComponentABCI] != 2 {
+		t.Errorf("Contadores inesperados: %v", panics)
+	}
+	m.Reset()
+	if len(m.GetMetrics().PanicsRecovered) != 0 {
+		t.Error("Reset debería limpiar los pánicos recuperados")
+	}
+}
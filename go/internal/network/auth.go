@@ -0,0 +1,254 @@
+package network
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultMaxClockSkew es la diferencia máxima aceptada entre timestamp del mensaje y reloj local
+const DefaultMaxClockSkew = 2 * time.Minute
+
+// ErrInvalidPayload indica que un peer envió un payload que no se pudo decodificar
+var ErrInvalidPayload = errors.New("payload inválido")
+
+// ErrReplayedMessage indica que un mensaje firmado ya fue recibido dentro de la ventana de skew
+var ErrReplayedMessage = errors.New("mensaje repetido")
+
+// messageDomain separa las firmas de mensajes mesh de cualquier otro uso de la clave del nodo
+const messageDomain = "oxy-mesh-message-v1"
+
+// maxSeenMessages limita la memoria del caché anti-replay
+const maxSeenMessages = 65536
+
+// seenMessage es una entrada del caché anti-replay
+type seenMessage struct {
+	hash    common.Hash
+	expires time.Time
+}
+
+// MeshAuth firma los mensajes salientes con la clave del nodo y verifica
+// firma e identidad de los mensajes entrantes. Los peers que envían mensajes
+// inválidos de forma reiterada se banean temporalmente (ver PeerScorer)
+type MeshAuth struct {
+	privateKey    *ecdsa.PrivateKey
+	address       common.Address
+	requireSigned bool
+	maxClockSkew  time.Duration
+	allowedPeers  map[common.Address]bool // Vacío: se acepta cualquier peer con firma válida
+	chainID       string                  // Cadena incluida en el hash firmado (evita replays entre redes)
+	scorer        *PeerScorer
+	seen          map[common.Hash]time.Time // Hashes verificados dentro de la ventana de skew
+	seenOrder     []seenMessage             // Orden de llegada para expirar y acotar el caché
+	mu            sync.Mutex
+}
+
+// NewMeshAuth crea el autenticador del mesh con la clave del nodo
+// Si requireSigned es true se descartan los mensajes sin firma
+func NewMeshAuth(privateKey *ecdsa.PrivateKey, requireSigned bool) (*MeshAuth, error) {
+	if privateKey == nil {
+		return nil, fmt.Errorf("clave del nodo requerida para firmar mensajes mesh")
+	}
+
+	return &MeshAuth{
+		privateKey:    privateKey,
+		address:       crypto.PubkeyToAddress(privateKey.PublicKey),
+		requireSigned: requireSigned,
+		maxClockSkew:  DefaultMaxClockSkew,
+		allowedPeers:  make(map[common.Address]bool),
+		scorer:        NewPeerScorer(),
+		seen:          make(map[common.Hash]time.Time),
+	}, nil
+}
+
+// Scorer retorna el scoring de peers usado para decidir los bans
+func (a *MeshAuth) Scorer() *PeerScorer {
+	return a.scorer
+}
+
+// Address retorna la identidad (dirección) con la que firma este nodo
+func (a *MeshAuth) Address() common.Address {
+	return a.address
+}
+
+// SetAllowedPeers restringe los mensajes aceptados a las direcciones indicadas
+// Una lista vacía acepta cualquier peer con firma válida
+func (a *MeshAuth) SetAllowedPeers(peers []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.allowedPeers = make(map[common.Address]bool)
+	for _, peer := range peers {
+		peer = strings.TrimSpace(peer)
+		if common.IsHexAddress(peer) {
+			a.allowedPeers[common.HexToAddress(peer)] = true
+		}
+	}
+}
+
+// SetChainID establece la cadena a la que pertenecen los mensajes firmados y verificados
+// Un mensaje firmado para otra cadena no verifica en esta
+func (a *MeshAuth) SetChainID(chainID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.chainID = chainID
+}
+
+// SetBanPolicy configura cuántos mensajes inválidos se toleran y por cuánto tiempo se banea al peer
+func (a *MeshAuth) SetBanPolicy(maxInvalid int, banDuration time.Duration) {
+	a.scorer.SetBanPolicy(float64(maxInvalid)*misbehaviorPenalties[MisbehaviorInvalid], banDuration)
+}
+
+// messageHash calcula el hash firmado de un mensaje
+// Incluye dominio, cadena, tipo, topic, destinatario, remitente, timestamp y datos para que no puedan alterarse
+func messageHash(chainID string, msg *MeshMessage) []byte {
+	return crypto.Keccak256(
+		[]byte(messageDomain), []byte{0},
+		[]byte(chainID), []byte{0},
+		[]byte(msg.Type), []byte{0},
+		[]byte(msg.Topic), []byte{0},
+		[]byte(msg.To), []byte{0},
+		[]byte(msg.Sender), []byte{0},
+		[]byte(strconv.FormatInt(msg.Timestamp, 10)), []byte{0},
+		msg.Data,
+	)
+}
+
+// currentChainID retorna la cadena configurada
+func (a *MeshAuth) currentChainID() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.chainID
+}
+
+// Sign firma un mensaje saliente con la clave del nodo
+func (a *MeshAuth) Sign(msg *MeshMessage) error {
+	msg.Sender = a.address.Hex()
+	msg.Timestamp = time.Now().UnixMilli()
+
+	signature, err := crypto.Sign(messageHash(a.currentChainID(), msg), a.privateKey)
+	if err != nil {
+		return fmt.Errorf("error firmando mensaje mesh: %w", err)
+	}
+
+	msg.Signature = hexutil.Encode(signature)
+	return nil
+}
+
+// Verify verifica la firma e identidad de un mensaje entrante
+// Retorna la dirección del remitente verificada (vacía si el mensaje no está firmado y no se exige firma)
+func (a *MeshAuth) Verify(msg *MeshMessage) (common.Address, error) {
+	if msg.Signature == "" {
+		if a.requireSigned {
+			return common.Address{}, fmt.Errorf("mensaje sin firma")
+		}
+		return common.Address{}, nil
+	}
+
+	if !common.IsHexAddress(msg.Sender) {
+		return common.Address{}, fmt.Errorf("remitente inválido: %q", msg.Sender)
+	}
+
+	signature, err := hexutil.Decode(msg.Signature)
+	if err != nil || len(signature) != 65 {
+		return common.Address{}, fmt.Errorf("firma mal formada")
+	}
+
+	// Rechazar mensajes viejos o del futuro para limitar replays
+	sentAt := time.UnixMilli(msg.Timestamp)
+	skew := time.Since(sentAt)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > a.maxClockSkew {
+		return common.Address{}, fmt.Errorf("timestamp fuera de rango: %s", sentAt.UTC().Format(time.RFC3339))
+	}
+
+	hash := messageHash(a.currentChainID(), msg)
+	pubKey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error recuperando clave pública: %w", err)
+	}
+
+	// La identidad declarada debe coincidir con la clave que firmó
+	signer := crypto.PubkeyToAddress(*pubKey)
+	if signer != common.HexToAddress(msg.Sender) {
+		return common.Address{}, fmt.Errorf("firma inválida: firmante %s no coincide con remitente %s", signer.Hex(), msg.Sender)
+	}
+
+	a.mu.Lock()
+	allowed := len(a.allowedPeers) == 0 || a.allowedPeers[signer]
+	a.mu.Unlock()
+	if !allowed {
+		return common.Address{}, fmt.Errorf("peer no autorizado: %s", signer.Hex())
+	}
+
+	// Un mensaje válido solo se acepta una vez mientras su timestamp esté dentro de la ventana
+	if !a.markSeen(common.BytesToHash(hash), sentAt.Add(a.maxClockSkew)) {
+		return common.Address{}, ErrReplayedMessage
+	}
+
+	return signer, nil
+}
+
+// markSeen registra el hash de un mensaje verificado hasta que expire su ventana de skew
+// Retorna false si el hash ya estaba registrado (replay)
+func (a *MeshAuth) markSeen(hash common.Hash, expires time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	for len(a.seenOrder) > 0 && (now.After(a.seenOrder[0].expires) || len(a.seenOrder) >= maxSeenMessages) {
+		oldest := a.seenOrder[0]
+		if a.seen[oldest.hash].Equal(oldest.expires) {
+			delete(a.seen, oldest.hash)
+		}
+		a.seenOrder = a.seenOrder[1:]
+	}
+
+	if expiresAt, ok := a.seen[hash]; ok && now.Before(expiresAt) {
+		return false
+	}
+
+	a.seen[hash] = expires
+	a.seenOrder = append(a.seenOrder, seenMessage{hash: hash, expires: expires})
+	return true
+}
+
+// peerID identifica al peer que envió un mensaje: la identidad firmada una vez verificada,
+// o si no la identidad de transporte asignada por el mesh (msg.From). El remitente declarado
+// (msg.Sender) nunca se usa antes de verificar la firma, para no penalizar a un peer suplantado
+func peerID(msg *MeshMessage) string {
+	if msg.verifiedSender != "" {
+		return msg.verifiedSender
+	}
+	return msg.From
+}
+
+// IsBanned indica si un peer está baneado
+func (a *MeshAuth) IsBanned(peer string) bool {
+	return a.scorer.IsBanned(peer)
+}
+
+// ReportInvalid registra un mensaje inválido de un peer y lo banea al superar el límite
+// Retorna true si el peer quedó baneado
+func (a *MeshAuth) ReportInvalid(peer string, reason error) bool {
+	return a.scorer.Penalize(peer, MisbehaviorInvalid, reason)
+}
+
+// BannedPeers retorna los peers baneados actualmente y hasta cuándo
+func (a *MeshAuth) BannedPeers() map[string]time.Time {
+	result := make(map[string]time.Time)
+	for _, ban := range a.scorer.Bans() {
+		result[ban.Peer] = ban.Until
+	}
+	return result
+}
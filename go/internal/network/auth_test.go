@@ -0,0 +1,272 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newTestAuth crea un autenticador con una clave nueva
+func newTestAuth(t *testing.T, requireSigned bool) *MeshAuth {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Error generando clave: %v", err)
+	}
+	auth, err := NewMeshAuth(key, requireSigned)
+	if err != nil {
+		t.Fatalf("Error creando MeshAuth: %v", err)
+	}
+	return auth
+}
+
+// TestMeshAuthSignVerify verifica que un mensaje firmado se acepta y uno alterado se rechaza
+func TestMeshAuthSignVerify(t *testing.T) {
+	sender := newTestAuth(t, true)
+	receiver := newTestAuth(t, true)
+
+	msg := &MeshMessage{
+		Type:  MessageTypePublish,
+		Topic: TopicTransactions,
+		Data:  json.RawMessage(`{"hash":"0xabc"}`),
+	}
+	if err := sender.Sign(msg); err != nil {
+		t.Fatalf("Error firmando: %v", err)
+	}
+
+	signer, err := receiver.Verify(msg)
+	if err != nil {
+		t.Fatalf("Mensaje firmado debería ser válido: %v", err)
+	}
+	if signer != sender.Address() {
+		t.Errorf("Firmante esperado %s, obtenido %s", sender.Address().Hex(), signer.Hex())
+	}
+
+	// Alterar datos invalida la firma
+	tampered := *msg
+	tampered.Data = json.RawMessage(`{"hash":"0xdef"}`)
+	if _, err := receiver.Verify(&tampered); err == nil {
+		t.Error("Mensaje alterado debería ser rechazado")
+	}
+
+	// Suplantar identidad invalida la firma
+	impersonated := *msg
+	impersonated.Sender = receiver.Address().Hex()
+	if _, err := receiver.Verify(&impersonated); err == nil {
+		t.Error("Mensaje con remitente suplantado debería ser rechazado")
+	}
+
+	// Timestamp viejo se rechaza aunque la firma sea válida
+	old := &MeshMessage{Type: MessageTypePublish, Topic: TopicBlocks}
+	sender.Sign(old)
+	old.Timestamp = time.Now().Add(-time.Hour).UnixMilli()
+	if _, err := receiver.Verify(old); err == nil {
+		t.Error("Mensaje con timestamp viejo debería ser rechazado")
+	}
+}
+
+// TestMeshAuthUnsignedAndAllowedPeers verifica mensajes sin firma y la lista de peers permitidos
+func TestMeshAuthUnsignedAndAllowedPeers(t *testing.T) {
+	strict := newTestAuth(t, true)
+	lenient := newTestAuth(t, false)
+
+	unsigned := &MeshMessage{Type: MessageTypePublish, Topic: TopicTransactions}
+	if _, err := strict.Verify(unsigned); err == nil {
+		t.Error("Mensaje sin firma debería rechazarse si se exige firma")
+	}
+	if _, err := lenient.Verify(unsigned); err != nil {
+		t.Errorf("Mensaje sin firma debería aceptarse si no se exige firma: %v", err)
+	}
+
+	sender := newTestAuth(t, true)
+	other := newTestAuth(t, true)
+	strict.SetAllowedPeers([]string{other.Address().Hex()})
+
+	msg := &MeshMessage{Type: MessageTypePublish, Topic: TopicTransactions}
+	sender.Sign(msg)
+	if _, err := strict.Verify(msg); err == nil || !strings.Contains(err.Error(), "no autorizado") {
+		t.Errorf("Peer fuera de la lista debería rechazarse, obtenido: %v", err)
+	}
+
+	strict.SetAllowedPeers([]string{" " + sender.Address().Hex() + " "})
+	if _, err := strict.Verify(msg); err != nil {
+		t.Errorf("Peer permitido debería aceptarse: %v", err)
+	}
+}
+
+// TestMeshAuthBan verifica que un peer se banea tras varios mensajes inválidos
+func TestMeshAuthBan(t *testing.T) {
+	auth := newTestAuth(t, true)
+	auth.SetBanPolicy(2, 50*time.Millisecond)
+
+	peer := "peer-1"
+	if auth.ReportInvalid(peer, fmt.Errorf("firma inválida")) {
+		t.Fatal("No debería banear al primer mensaje inválido")
+	}
+	if auth.IsBanned(peer) {
+		t.Fatal("Peer no debería estar baneado aún")
+	}
+	if !auth.ReportInvalid(peer, fmt.Errorf("firma inválida")) {
+		t.Fatal("Debería banear al alcanzar el límite")
+	}
+	if !auth.IsBanned(peer) {
+		t.Fatal("Peer debería estar baneado")
+	}
+	if _, ok := auth.BannedPeers()[peer]; !ok {
+		t.Error("BannedPeers debería incluir al peer")
+	}
+
+	// El ban expira
+	time.Sleep(60 * time.Millisecond)
+	if auth.IsBanned(peer) {
+		t.Error("El ban debería haber expirado")
+	}
+}
+
+// TestMeshBridgeAuthenticate verifica que el bridge descarta mensajes sin firma y banea al peer
+func TestMeshBridgeAuthenticate(t *testing.T) {
+	meshBridge := NewMeshBridge(context.Background(), nil, "ws://localhost:3001", nil)
+	auth := newTestAuth(t, true)
+	auth.SetBanPolicy(2, time.Minute)
+	meshBridge.SetAuth(auth)
+
+	forged := &MeshMessage{Type: MessageTypePublish, Topic: TopicTransactions, From: "mesh-peer", Data: json.RawMessage(`{}`)}
+	for i := 0; i < 2; i++ {
+		if err := meshBridge.handleMessage(forged); err == nil {
+			t.Fatal("Mensaje sin firma debería descartarse")
+		}
+	}
+	if !auth.IsBanned("mesh-peer") {
+		t.Error("Peer debería quedar baneado tras mensajes inválidos")
+	}
+
+	// Payload inválido de un peer autenticado cuenta para el ban de su identidad verificada
+	sender := newTestAuth(t, true)
+	for i := 0; i < 2; i++ {
+		bad := &MeshMessage{Type: MessageTypePublish, Topic: TopicBlocks, From: "relay", Data: json.RawMessage(fmt.Sprintf(`"no es un bloque %d"`, i))}
+		sender.Sign(bad)
+		if err := meshBridge.handleMessage(bad); err == nil {
+			t.Fatal("Payload inválido debería retornar error")
+		}
+	}
+	if !auth.IsBanned(strings.ToLower(sender.Address().Hex())) {
+		t.Error("Peer con payloads inválidos debería quedar baneado")
+	}
+	if auth.IsBanned("relay") {
+		t.Error("El relay no debería penalizarse por payloads firmados por otro peer")
+	}
+}
+
+// TestMeshBridgeAuthenticate_SpoofedSender verifica que una firma inválida penaliza la identidad
+// de transporte y no al remitente declarado, que puede ser suplantado
+func TestMeshBridgeAuthenticate_SpoofedSender(t *testing.T) {
+	meshBridge := NewMeshBridge(context.Background(), nil, "ws://localhost:3001", nil)
+	auth := newTestAuth(t, true)
+	auth.SetBanPolicy(1, time.Minute)
+	meshBridge.SetAuth(auth)
+
+	victim := newTestAuth(t, true)
+	spoofed := &MeshMessage{
+		Type:      MessageTypePublish,
+		Topic:     TopicTransactions,
+		From:      "attacker",
+		Sender:    victim.Address().Hex(),
+		Timestamp: time.Now().UnixMilli(),
+		Signature: "0x" + strings.Repeat("11", 65),
+	}
+	if err := meshBridge.handleMessage(spoofed); err == nil {
+		t.Fatal("Mensaje con firma inválida debería descartarse")
+	}
+
+	if auth.IsBanned(strings.ToLower(victim.Address().Hex())) {
+		t.Error("El remitente suplantado no debería penalizarse")
+	}
+	if !auth.IsBanned("attacker") {
+		t.Error("La identidad de transporte debería penalizarse")
+	}
+}
+
+// TestMeshBridgeAuthenticate_RateLimitVerifiedIdentity verifica que la tasa de mensajes se cuenta
+// sobre el firmante verificado: rotar la identidad de transporte no evita el límite
+func TestMeshBridgeAuthenticate_RateLimitVerifiedIdentity(t *testing.T) {
+	meshBridge := NewMeshBridge(context.Background(), nil, "ws://localhost:3001", nil)
+	auth := newTestAuth(t, true)
+	auth.Scorer().SetMessageRate(2)
+	meshBridge.SetAuth(auth)
+
+	sender := newTestAuth(t, true)
+	accepted := 0
+	for i := 0; i < 5; i++ {
+		msg := &MeshMessage{
+			Type:  MessageTypePublish,
+			Topic: TopicTransactions,
+			From:  fmt.Sprintf("relay-%d", i),
+			Data:  []byte(fmt.Sprintf(`{"n":%d}`, i)),
+		}
+		if err := sender.Sign(msg); err != nil {
+			t.Fatalf("Error firmando: %v", err)
+		}
+		if meshBridge.authenticate(msg) == nil {
+			accepted++
+		}
+	}
+
+	if accepted != 2 {
+		t.Errorf("Rotar la identidad de transporte no debería evitar el límite: esperados 2, aceptados %d", accepted)
+	}
+}
+
+// TestMeshAuthReplayAndChainID verifica que un mensaje firmado no se acepta dos veces ni en otra cadena
+func TestMeshAuthReplayAndChainID(t *testing.T) {
+	sender := newTestAuth(t, true)
+	receiver := newTestAuth(t, true)
+	sender.SetChainID("oxy-test")
+	receiver.SetChainID("oxy-test")
+
+	msg := &MeshMessage{Type: MessageTypePublish, Topic: TopicTransactions, Data: json.RawMessage(`{"hash":"0xabc"}`)}
+	if err := sender.Sign(msg); err != nil {
+		t.Fatalf("Error firmando: %v", err)
+	}
+
+	if _, err := receiver.Verify(msg); err != nil {
+		t.Fatalf("Primer mensaje debería aceptarse: %v", err)
+	}
+	if _, err := receiver.Verify(msg); !errors.Is(err, ErrReplayedMessage) {
+		t.Errorf("Mensaje repetido debería rechazarse como replay: %v", err)
+	}
+
+	// Una firma hecha para otra cadena no verifica
+	other := newTestAuth(t, true)
+	other.SetChainID("oxy-main")
+	foreign := &MeshMessage{Type: MessageTypePublish, Topic: TopicTransactions, Data: json.RawMessage(`{"hash":"0xdef"}`)}
+	sender.Sign(foreign)
+	if _, err := other.Verify(foreign); err == nil {
+		t.Error("Mensaje firmado para otra cadena debería rechazarse")
+	}
+}
+
+// TestLoadOrCreateNodeKey verifica que la identidad mesh se persiste entre reinicios
+func TestLoadOrCreateNodeKey(t *testing.T) {
+	if _, err := loadOrCreateNodeKey(""); err == nil {
+		t.Error("Sin clave ni directorio de datos debería fallar")
+	}
+
+	dir := t.TempDir()
+	first, err := loadOrCreateNodeKey(dir)
+	if err != nil {
+		t.Fatalf("Error creando clave: %v", err)
+	}
+	second, err := loadOrCreateNodeKey(dir)
+	if err != nil {
+		t.Fatalf("Error cargando clave: %v", err)
+	}
+
+	if crypto.PubkeyToAddress(first.PublicKey) != crypto.PubkeyToAddress(second.PublicKey) {
+		t.Error("La clave cargada debería ser la misma que la generada")
+	}
+}
@@ -0,0 +1,256 @@
+package network
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Codificaciones de mensajes soportadas por el mesh bridge
+const (
+	EncodingJSON  = "json"  // Frames de texto JSON (compatibilidad con SDKs antiguos)
+	EncodingProto = "proto" // Frames binarios protobuf
+)
+
+// Compresiones soportadas para frames binarios
+const (
+	CompressionNone   = "none"
+	CompressionGzip   = "gzip"
+	CompressionSnappy = "snappy"
+)
+
+// Mensajes de negociación de codificación con el SDK
+const (
+	MessageTypeHello    = "hello"
+	MessageTypeHelloAck = "hello_ack"
+)
+
+const (
+	frameVersion = 1
+
+	// Los mensajes pequeños (ping, suscripciones, queries) no ganan nada comprimidos
+	compressionThreshold = 256
+
+	// Tamaño máximo de un mensaje descomprimido (protege contra bombas de compresión)
+	maxFrameSize = 32 << 20
+)
+
+// Identificadores de compresión en el header del frame
+var compressionIDs = map[string]byte{
+	CompressionNone:   0,
+	CompressionGzip:   1,
+	CompressionSnappy: 2,
+}
+
+// meshCodec es la codificación negociada para la conexión actual
+type meshCodec struct {
+	Encoding    string `json:"encoding"`
+	Compression string `json:"compression"`
+}
+
+// defaultCodec es la codificación usada hasta completar la negociación
+var defaultCodec = meshCodec{Encoding: EncodingJSON, Compression: CompressionNone}
+
+// meshHello anuncia al SDK las codificaciones soportadas en orden de preferencia
+type meshHello struct {
+	Encodings   []string `json:"encodings"`
+	Compression []string `json:"compression"`
+}
+
+// supportedCodec indica si el bridge sabe codificar/decodificar la combinación indicada
+func supportedCodec(codec meshCodec) bool {
+	if codec.Encoding == EncodingJSON {
+		return true
+	}
+	if codec.Encoding != EncodingProto {
+		return false
+	}
+	_, ok := compressionIDs[codec.Compression]
+	return ok
+}
+
+// Campos protobuf de MeshMessage (esquema en meshmessage.proto)
+const (
+	fieldType      protowire.Number = 1
+	fieldTopic     protowire.Number = 2
+	fieldData      protowire.Number = 3
+	fieldFrom      protowire.Number = 4
+	fieldTo        protowire.Number = 5
+	fieldSender    protowire.Number = 6
+	fieldTimestamp protowire.Number = 7
+	fieldSignature protowire.Number = 8
+)
+
+// appendStringField agrega un campo string al mensaje protobuf (omitido si está vacío)
+func appendStringField(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+// marshalProto codifica un MeshMessage en formato protobuf
+func marshalProto(msg *MeshMessage) []byte {
+	b := make([]byte, 0, len(msg.Data)+len(msg.Signature)+128)
+	b = appendStringField(b, fieldType, msg.Type)
+	b = appendStringField(b, fieldTopic, msg.Topic)
+	if len(msg.Data) > 0 {
+		b = protowire.AppendTag(b, fieldData, protowire.BytesType)
+		b = protowire.AppendBytes(b, msg.Data)
+	}
+	b = appendStringField(b, fieldFrom, msg.From)
+	b = appendStringField(b, fieldTo, msg.To)
+	b = appendStringField(b, fieldSender, msg.Sender)
+	if msg.Timestamp != 0 {
+		b = protowire.AppendTag(b, fieldTimestamp, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(msg.Timestamp))
+	}
+	b = appendStringField(b, fieldSignature, msg.Signature)
+	return b
+}
+
+// unmarshalProto decodifica un MeshMessage en formato protobuf
+// Los campos desconocidos se ignoran para permitir extender el esquema
+func unmarshalProto(b []byte) (*MeshMessage, error) {
+	msg := &MeshMessage{}
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, fmt.Errorf("tag protobuf inválido: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		switch {
+		case num == fieldTimestamp && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			msg.Timestamp = int64(v)
+
+		case typ == protowire.BytesType && num >= fieldType && num <= fieldSignature:
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			if n < 0 {
+				break
+			}
+			switch num {
+			case fieldType:
+				msg.Type = string(v)
+			case fieldTopic:
+				msg.Topic = string(v)
+			case fieldData:
+				msg.Data = json.RawMessage(append([]byte(nil), v...))
+			case fieldFrom:
+				msg.From = string(v)
+			case fieldTo:
+				msg.To = string(v)
+			case fieldSender:
+				msg.Sender = string(v)
+			case fieldSignature:
+				msg.Signature = string(v)
+			}
+
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+
+		if n < 0 {
+			return nil, fmt.Errorf("campo protobuf %d inválido: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+	}
+
+	return msg, nil
+}
+
+// encodeFrame codifica un mensaje como frame binario: [versión][compresión][payload protobuf]
+// La compresión solo se aplica a mensajes por encima del umbral
+func encodeFrame(msg *MeshMessage, compression string) ([]byte, error) {
+	payload := marshalProto(msg)
+
+	if len(payload) < compressionThreshold {
+		compression = CompressionNone
+	}
+
+	id, ok := compressionIDs[compression]
+	if !ok {
+		return nil, fmt.Errorf("compresión no soportada: %s", compression)
+	}
+
+	frame := []byte{frameVersion, id}
+	switch compression {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		buf.Write(frame)
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(payload); err != nil {
+			return nil, fmt.Errorf("error comprimiendo frame: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("error comprimiendo frame: %w", err)
+		}
+		return buf.Bytes(), nil
+
+	case CompressionSnappy:
+		return append(frame, snappy.Encode(nil, payload)...), nil
+
+	default:
+		return append(frame, payload...), nil
+	}
+}
+
+// decodeFrame decodifica un frame binario recibido del mesh
+func decodeFrame(frame []byte) (*MeshMessage, error) {
+	if len(frame) < 2 {
+		return nil, fmt.Errorf("frame demasiado corto")
+	}
+	if frame[0] != frameVersion {
+		return nil, fmt.Errorf("versión de frame no soportada: %d", frame[0])
+	}
+
+	body := frame[2:]
+	var payload []byte
+
+	switch frame[1] {
+	case compressionIDs[CompressionNone]:
+		payload = body
+
+	case compressionIDs[CompressionGzip]:
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("error descomprimiendo frame gzip: %w", err)
+		}
+		defer zr.Close()
+		payload, err = io.ReadAll(io.LimitReader(zr, maxFrameSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("error descomprimiendo frame gzip: %w", err)
+		}
+		if len(payload) > maxFrameSize {
+			return nil, fmt.Errorf("frame excede el tamaño máximo")
+		}
+
+	case compressionIDs[CompressionSnappy]:
+		size, err := snappy.DecodedLen(body)
+		if err != nil {
+			return nil, fmt.Errorf("error descomprimiendo frame snappy: %w", err)
+		}
+		if size > maxFrameSize {
+			return nil, fmt.Errorf("frame excede el tamaño máximo")
+		}
+		payload, err = snappy.Decode(nil, body)
+		if err != nil {
+			return nil, fmt.Errorf("error descomprimiendo frame snappy: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("compresión desconocida: %d", frame[1])
+	}
+
+	return unmarshalProto(payload)
+}
@@ -0,0 +1,262 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// testBlockPayload genera un payload JSON similar a un bloque con transacciones
+func testBlockPayload(txs int) json.RawMessage {
+	var sb strings.Builder
+	sb.WriteString(`{"header":{"height":1234,"chainId":"oxy-gen-chain"},"transactions":[`)
+	for i := 0; i < txs; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"hash":"0x%064x","from":"0x%040x","to":"0x%040x","value":"1000000000000000000","gasLimit":21000,"gasPrice":"1000000000","nonce":%d}`, i, i, i+1, i)
+	}
+	sb.WriteString(`]}`)
+	return json.RawMessage(sb.String())
+}
+
+// TestProtoRoundTrip verifica que un MeshMessage se codifica y decodifica sin pérdidas
+func TestProtoRoundTrip(t *testing.T) {
+	msg := &MeshMessage{
+		Type:      MessageTypePublish,
+		Topic:     TopicBlocks,
+		Data:      json.RawMessage(`{"height":1}`),
+		From:      "peer-a",
+		To:        "peer-b",
+		Sender:    "0x0000000000000000000000000000000000000001",
+		Timestamp: time.Now().UnixMilli(),
+		Signature: "0xdeadbeef",
+	}
+
+	decoded, err := unmarshalProto(marshalProto(msg))
+	if err != nil {
+		t.Fatalf("Error decodificando: %v", err)
+	}
+
+	if decoded.Type != msg.Type || decoded.Topic != msg.Topic || string(decoded.Data) != string(msg.Data) ||
+		decoded.From != msg.From || decoded.To != msg.To || decoded.Sender != msg.Sender ||
+		decoded.Timestamp != msg.Timestamp || decoded.Signature != msg.Signature {
+		t.Errorf("Mensaje decodificado no coincide: %+v", decoded)
+	}
+
+	// Campos desconocidos se ignoran (compatibilidad hacia adelante)
+	extended := protowire.AppendTag(marshalProto(msg), 99, protowire.VarintType)
+	extended = protowire.AppendVarint(extended, 42)
+	if _, err := unmarshalProto(extended); err != nil {
+		t.Errorf("Campos desconocidos no deberían fallar: %v", err)
+	}
+
+	// Datos truncados fallan
+	if _, err := unmarshalProto(marshalProto(msg)[:10]); err == nil {
+		t.Error("Mensaje truncado debería fallar")
+	}
+}
+
+// TestFrameCompression verifica frames con cada compresión y la reducción de tamaño respecto a JSON
+func TestFrameCompression(t *testing.T) {
+	msg := &MeshMessage{Type: MessageTypePublish, Topic: TopicBlocks, Data: testBlockPayload(50)}
+
+	jsonSize := len(mustJSON(t, msg))
+
+	for _, compression := range []string{CompressionNone, CompressionGzip, CompressionSnappy} {
+		frame, err := encodeFrame(msg, compression)
+		if err != nil {
+			t.Fatalf("%s: error codificando: %v", compression, err)
+		}
+
+		decoded, err := decodeFrame(frame)
+		if err != nil {
+			t.Fatalf("%s: error decodificando: %v", compression, err)
+		}
+		if string(decoded.Data) != string(msg.Data) {
+			t.Errorf("%s: datos no coinciden", compression)
+		}
+
+		if compression != CompressionNone && len(frame) >= jsonSize/2 {
+			t.Errorf("%s: frame de %d bytes no reduce suficiente el JSON de %d bytes", compression, len(frame), jsonSize)
+		}
+		t.Logf("%s: JSON %d bytes -> frame %d bytes", compression, jsonSize, len(frame))
+	}
+
+	// Los mensajes pequeños no se comprimen
+	small, _ := encodeFrame(&MeshMessage{Type: MessageTypePing}, CompressionGzip)
+	if small[1] != compressionIDs[CompressionNone] {
+		t.Error("Mensajes pequeños no deberían comprimirse")
+	}
+
+	// Frames inválidos
+	if _, err := decodeFrame([]byte{9, 0}); err == nil {
+		t.Error("Versión desconocida debería fallar")
+	}
+	if _, err := decodeFrame([]byte{frameVersion, 7}); err == nil {
+		t.Error("Compresión desconocida debería fallar")
+	}
+}
+
+// mustJSON codifica un valor en JSON o falla el test
+func mustJSON(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Error codificando JSON: %v", err)
+	}
+	return data
+}
+
+// TestMeshBridgeCodecNegotiation verifica el handshake con el SDK y el envío en frames binarios
+func TestMeshBridgeCodecNegotiation(t *testing.T) {
+	received := make(chan *MeshMessage, 10)
+	upgrader := websocket.Upgrader{}
+
+	// Servidor mesh simulado: responde hello con proto/snappy y reenvía lo recibido al test
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			messageType, frame, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var msg *MeshMessage
+			if messageType == websocket.BinaryMessage {
+				if msg, err = decodeFrame(frame); err != nil {
+					t.Errorf("Frame binario inválido: %v", err)
+					return
+				}
+			} else {
+				msg = &MeshMessage{}
+				json.Unmarshal(frame, msg)
+			}
+
+			if msg.Type == MessageTypeHello {
+				var hello meshHello
+				json.Unmarshal(msg.Data, &hello)
+				ack, _ := json.Marshal(meshCodec{Encoding: hello.Encodings[0], Compression: hello.Compression[0]})
+				conn.WriteJSON(MeshMessage{Type: MessageTypeHelloAck, Data: ack})
+				continue
+			}
+
+			if messageType == websocket.BinaryMessage && msg.Type == MessageTypePublish {
+				received <- msg
+			}
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	meshBridge := NewMeshBridge(ctx, nil, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err := meshBridge.Start(); err != nil {
+		t.Fatalf("Error iniciando bridge: %v", err)
+	}
+	defer meshBridge.Stop()
+
+	// Esperar a que se negocie la codificación binaria
+	deadline := time.Now().Add(2 * time.Second)
+	for meshBridge.currentCodec().Encoding != EncodingProto {
+		if time.Now().After(deadline) {
+			t.Fatal("No se negoció la codificación proto")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	payload := testBlockPayload(20)
+	if err := meshBridge.sendMessage(&MeshMessage{Type: MessageTypePublish, Topic: TopicBlocks, Data: payload}); err != nil {
+		t.Fatalf("Error enviando: %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		if string(msg.Data) != string(payload) {
+			t.Error("Datos recibidos no coinciden")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("El servidor no recibió el frame binario")
+	}
+
+	if sent, _ := meshBridge.TrafficStats(); sent == 0 {
+		t.Error("TrafficStats debería registrar bytes enviados")
+	}
+}
+
+// TestSetCodecPreferences verifica la validación de preferencias de codificación
+func TestSetCodecPreferences(t *testing.T) {
+	meshBridge := NewMeshBridge(context.Background(), nil, "ws://localhost:3001", nil)
+
+	if err := meshBridge.SetCodecPreferences(EncodingProto, CompressionGzip); err != nil {
+		t.Errorf("proto/gzip debería ser válido: %v", err)
+	}
+	if err := meshBridge.SetCodecPreferences(EncodingProto, "lz4"); err == nil {
+		t.Error("Compresión desconocida debería fallar")
+	}
+	if err := meshBridge.SetCodecPreferences("cbor", CompressionNone); err == nil {
+		t.Error("Codificación desconocida debería fallar")
+	}
+
+	// hello_ack con codificación no soportada se rechaza y se mantiene JSON
+	if err := meshBridge.applyHelloAck([]byte(`{"encoding":"proto","compression":"lz4"}`)); err == nil {
+		t.Error("hello_ack no soportado debería fallar")
+	}
+	if meshBridge.currentCodec() != defaultCodec {
+		t.Error("La codificación debería seguir en JSON")
+	}
+}
+
+// TestMeshMessageProtoSchema verifica que el codec use los números de campo de meshmessage.proto
+func TestMeshMessageProtoSchema(t *testing.T) {
+	schema, err := os.ReadFile("meshmessage.proto")
+	if err != nil {
+		t.Fatalf("Error leyendo esquema: %v", err)
+	}
+
+	expected := map[string]protowire.Number{
+		"type":      fieldType,
+		"topic":     fieldTopic,
+		"data":      fieldData,
+		"from":      fieldFrom,
+		"to":        fieldTo,
+		"sender":    fieldSender,
+		"timestamp": fieldTimestamp,
+		"signature": fieldSignature,
+	}
+
+	fieldRe := regexp.MustCompile(`(?m)^\s*(string|bytes|int64)\s+(\w+)\s*=\s*(\d+);`)
+	matches := fieldRe.FindAllStringSubmatch(string(schema), -1)
+	if len(matches) != len(expected) {
+		t.Fatalf("El esquema define %d campos, el codec %d", len(matches), len(expected))
+	}
+
+	for _, m := range matches {
+		name := m[2]
+		number, _ := strconv.Atoi(m[3])
+		codecNumber, ok := expected[name]
+		if !ok {
+			t.Errorf("Campo %s del esquema no está en el codec", name)
+			continue
+		}
+		if protowire.Number(number) != codecNumber {
+			t.Errorf("Campo %s: esquema %d, codec %d", name, number, codecNumber)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TopicNodes es el topic donde cada nodo anuncia su descriptor firmado
+const TopicNodes = "oxy-blockchain:nodes"
+
+// Valores por defecto del descubrimiento de peers
+const (
+	DefaultDescriptorInterval = time.Minute     // Cada cuánto se anuncia el descriptor propio
+	DefaultPeerTTL            = 5 * time.Minute // Sin un descriptor nuevo en este tiempo, el peer se descarta
+	DefaultMaxDiscoveredPeers = 256             // Máximo de peers en la tabla
+	maxDescriptorClockSkew    = 2 * time.Minute // Desfase de reloj aceptado en el timestamp del descriptor
+)
+
+// ErrUnsignedDescriptor se retorna cuando un descriptor de nodo llega sin firma verificada
+var ErrUnsignedDescriptor = errors.New("descriptor de nodo sin firma")
+
+// NodeDescriptor identifica a un nodo de la red; viaja firmado con la clave mesh del nodo
+type NodeDescriptor struct {
+	ChainID      string `json:"chainId"`
+	NodeID       string `json:"nodeId"`               // ID de nodo P2P de CometBFT
+	P2PAddress   string `json:"p2pAddress,omitempty"` // "id@host:puerto" (vacío si el nodo no acepta conexiones)
+	AppVersion   string `json:"appVersion"`
+	RESTEndpoint string `json:"restEndpoint,omitempty"` // URL pública del API REST
+	Role         string `json:"role"`
+	Timestamp    int64  `json:"timestamp"` // Unix ms
+}
+
+// DiscoveredPeer es un nodo descubierto por su descriptor anunciado en la mesh
+type DiscoveredPeer struct {
+	NodeDescriptor
+	MeshAddress string    `json:"meshAddress"` // Identidad mesh que firmó el descriptor
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+	Persistent  bool      `json:"persistent"` // Agregado como peer persistente de CometBFT
+}
+
+// PeerTable mantiene los nodos descubiertos por la mesh, indexados por la identidad mesh que firmó su descriptor
+// Solo acepta descriptores firmados de la misma cadena; los peers sin anuncios recientes expiran
+type PeerTable struct {
+	chainID    string
+	ttl        time.Duration
+	maxPeers   int
+	self       string // Identidad mesh propia (sus descriptores se ignoran)
+	selfNodeID string
+	peers      map[string]*DiscoveredPeer
+	onPeer     func(DiscoveredPeer)
+	now        func() time.Time
+	mu         sync.RWMutex
+}
+
+// NewPeerTable crea la tabla de peers de la cadena indicada (0 usa los valores por defecto)
+func NewPeerTable(chainID string, ttl time.Duration, maxPeers int) *PeerTable {
+	if ttl <= 0 {
+		ttl = DefaultPeerTTL
+	}
+	if maxPeers <= 0 {
+		maxPeers = DefaultMaxDiscoveredPeers
+	}
+	return &PeerTable{
+		chainID:  chainID,
+		ttl:      ttl,
+		maxPeers: maxPeers,
+		peers:    make(map[string]*DiscoveredPeer),
+		now:      time.Now,
+	}
+}
+
+// SetSelf configura la identidad mesh y el ID de nodo P2P propios, para ignorar los ecos del propio descriptor
+func (t *PeerTable) SetSelf(meshAddress, nodeID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.self = strings.ToLower(meshAddress)
+	t.selfNodeID = nodeID
+}
+
+// SetPeerHandler establece la función llamada cuando un peer anuncia una dirección P2P nueva
+func (t *PeerTable) SetPeerHandler(handler func(DiscoveredPeer)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onPeer = handler
+}
+
+// HandleDescriptor procesa un descriptor anunciado por la mesh con la identidad verificada de quien lo firmó
+// Se rechazan descriptores sin firma, de otra cadena, con una dirección P2P que no corresponde al ID de nodo
+// o con un timestamp fuera de rango; uno más viejo que el ya registrado para el mismo firmante se ignora
+func (t *PeerTable) HandleDescriptor(descriptor *NodeDescriptor, signer string) error {
+	if signer == "" {
+		return ErrUnsignedDescriptor
+	}
+	if err := t.validate(descriptor); err != nil {
+		return err
+	}
+	signer = strings.ToLower(signer)
+
+	t.mu.Lock()
+	if signer == t.self || (t.selfNodeID != "" && descriptor.NodeID == t.selfNodeID) {
+		t.mu.Unlock()
+		return nil
+	}
+
+	now := t.now()
+	peer, ok := t.peers[signer]
+	if ok && descriptor.Timestamp <= peer.Timestamp {
+		t.mu.Unlock()
+		return nil
+	}
+	if !ok {
+		t.prune(now)
+		if len(t.peers) >= t.maxPeers {
+			t.mu.Unlock()
+			return fmt.Errorf("tabla de peers llena (%d)", t.maxPeers)
+		}
+		peer = &DiscoveredPeer{MeshAddress: signer, FirstSeen: now}
+		t.peers[signer] = peer
+	}
+
+	addressChanged := descriptor.P2PAddress != "" && descriptor.P2PAddress != peer.P2PAddress
+	peer.NodeDescriptor = *descriptor
+	peer.LastSeen = now
+	if addressChanged {
+		peer.Persistent = false
+	}
+	snapshot := *peer
+	handler := t.onPeer
+	t.mu.Unlock()
+
+	if addressChanged && handler != nil {
+		handler(snapshot)
+	}
+	return nil
+}
+
+// validate verifica los campos de un descriptor
+func (t *PeerTable) validate(descriptor *NodeDescriptor) error {
+	if descriptor == nil || descriptor.NodeID == "" {
+		return fmt.Errorf("descriptor de nodo incompleto")
+	}
+	if t.chainID != "" && descriptor.ChainID != t.chainID {
+		return fmt.Errorf("descriptor de otra cadena: %s", descriptor.ChainID)
+	}
+	if descriptor.P2PAddress != "" {
+		id, hostPort, found := strings.Cut(descriptor.P2PAddress, "@")
+		if !found || id != descriptor.NodeID {
+			return fmt.Errorf("dirección P2P %q no corresponde al nodo %s", descriptor.P2PAddress, descriptor.NodeID)
+		}
+		if host, _, err := net.SplitHostPort(hostPort); err != nil || host == "" {
+			return fmt.Errorf("dirección P2P inválida: %q", descriptor.P2PAddress)
+		}
+	}
+	age := t.now().Sub(time.UnixMilli(descriptor.Timestamp))
+	if age > t.ttl || age < -maxDescriptorClockSkew {
+		return fmt.Errorf("descriptor de nodo fuera de tiempo: %s", time.UnixMilli(descriptor.Timestamp).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// MarkPersistent registra que el peer fue agregado como peer persistente de CometBFT
+func (t *PeerTable) MarkPersistent(meshAddress string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if peer, ok := t.peers[strings.ToLower(meshAddress)]; ok {
+		peer.Persistent = true
+	}
+}
+
+// Peers retorna los peers vigentes, el más reciente primero
+func (t *PeerTable) Peers() []DiscoveredPeer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(t.now())
+
+	peers := make([]DiscoveredPeer, 0, len(t.peers))
+	for _, peer := range t.peers {
+		peers = append(peers, *peer)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		if !peers[i].LastSeen.Equal(peers[j].LastSeen) {
+			return peers[i].LastSeen.After(peers[j].LastSeen)
+		}
+		return peers[i].MeshAddress < peers[j].MeshAddress
+	})
+	return peers
+}
+
+// PersistentPeers retorna las direcciones "id@host:puerto" de los peers vigentes, en el formato de OXY_PERSISTENT_PEERS
+func (t *PeerTable) PersistentPeers() []string {
+	var addresses []string
+	for _, peer := range t.Peers() {
+		if peer.P2PAddress != "" {
+			addresses = append(addresses, peer.P2PAddress)
+		}
+	}
+	return addresses
+}
+
+// prune descarta los peers sin anuncios dentro del TTL (requiere lock)
+func (t *PeerTable) prune(now time.Time) {
+	for signer, peer := range t.peers {
+		if now.Sub(peer.LastSeen) > t.ttl {
+			delete(t.peers, signer)
+		}
+	}
+}
+
+// nodeAdvertiser anuncia periódicamente el descriptor del nodo por la mesh
+type nodeAdvertiser struct {
+	bridge     *MeshBridge
+	descriptor func() *NodeDescriptor
+	interval   time.Duration
+	stopChan   chan struct{}
+	stopOnce   sync.Once
+}
+
+// newNodeAdvertiser crea el anunciador del descriptor propio (interval 0 usa DefaultDescriptorInterval)
+func newNodeAdvertiser(bridge *MeshBridge, descriptor func() *NodeDescriptor, interval time.Duration) *nodeAdvertiser {
+	if interval <= 0 {
+		interval = DefaultDescriptorInterval
+	}
+	return &nodeAdvertiser{
+		bridge:     bridge,
+		descriptor: descriptor,
+		interval:   interval,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// run anuncia el descriptor al iniciar y luego en cada intervalo hasta que se detenga
+func (a *nodeAdvertiser) run() {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		if err := a.bridge.AdvertiseNode(a.descriptor()); err != nil {
+			log.Printf("⚠️ Error anunciando descriptor del nodo por mesh: %v", err)
+		}
+		select {
+		case <-a.stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// stop detiene el anunciador
+func (a *nodeAdvertiser) stop() {
+	a.stopOnce.Do(func() {
+		close(a.stopChan)
+	})
+}
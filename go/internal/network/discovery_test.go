@@ -0,0 +1,112 @@
+package network
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// testDescriptor crea un descriptor de la cadena de test con el timestamp indicado
+func testDescriptor(nodeID string, at time.Time) *NodeDescriptor {
+	return &NodeDescriptor{
+		ChainID:    "oxy-test",
+		NodeID:     nodeID,
+		P2PAddress: nodeID + "@10.0.0.1:26656",
+		AppVersion: "0.1.0",
+		Role:       "full",
+		Timestamp:  at.UnixMilli(),
+	}
+}
+
+// TestPeerTableDescriptors verifica la validación de descriptores, los ecos propios y el aviso de direcciones nuevas
+func TestPeerTableDescriptors(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	table := NewPeerTable("oxy-test", time.Minute, 0)
+	table.now = func() time.Time { return now }
+	table.SetSelf("0x00000000000000000000000000000000000000AA", "self")
+
+	var discovered []string
+	table.SetPeerHandler(func(peer DiscoveredPeer) {
+		discovered = append(discovered, peer.P2PAddress)
+	})
+
+	if err := table.HandleDescriptor(testDescriptor("node1", now), ""); !errors.Is(err, ErrUnsignedDescriptor) {
+		t.Errorf("Descriptor sin firma debería rechazarse: %v", err)
+	}
+	other := testDescriptor("node1", now)
+	other.ChainID = "otra"
+	if err := table.HandleDescriptor(other, "0xbb"); err == nil {
+		t.Error("Descriptor de otra cadena debería rechazarse")
+	}
+	spoofed := testDescriptor("node1", now)
+	spoofed.P2PAddress = "node2@10.0.0.1:26656"
+	if err := table.HandleDescriptor(spoofed, "0xbb"); err == nil {
+		t.Error("Dirección P2P de otro nodo debería rechazarse")
+	}
+	if err := table.HandleDescriptor(testDescriptor("node1", now.Add(-2*time.Minute)), "0xbb"); err == nil {
+		t.Error("Descriptor vencido debería rechazarse")
+	}
+
+	// Ecos del propio descriptor
+	if err := table.HandleDescriptor(testDescriptor("self", now), "0x00000000000000000000000000000000000000aa"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.HandleDescriptor(testDescriptor("node1", now), "0xBB"); err != nil {
+		t.Fatal(err)
+	}
+	// Re-anuncio con la misma dirección: se actualiza sin volver a avisar
+	if err := table.HandleDescriptor(testDescriptor("node1", now.Add(time.Second)), "0xbb"); err != nil {
+		t.Fatal(err)
+	}
+	// Descriptor más viejo que el registrado: se ignora
+	old := testDescriptor("node1", now.Add(-time.Second))
+	old.P2PAddress = "node1@10.0.0.9:26656"
+	if err := table.HandleDescriptor(old, "0xbb"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(discovered) != 1 || discovered[0] != "node1@10.0.0.1:26656" {
+		t.Errorf("Avisos de peers incorrectos: %v", discovered)
+	}
+	peers := table.Peers()
+	if len(peers) != 1 || peers[0].MeshAddress != "0xbb" || peers[0].Timestamp != now.Add(time.Second).UnixMilli() {
+		t.Fatalf("Tabla de peers incorrecta: %+v", peers)
+	}
+
+	table.MarkPersistent("0xBB")
+	if !table.Peers()[0].Persistent {
+		t.Error("El peer debería quedar marcado como persistente")
+	}
+}
+
+// TestPeerTableExpiry verifica la expiración por TTL, el límite de tamaño y las direcciones persistentes
+func TestPeerTableExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	table := NewPeerTable("oxy-test", time.Minute, 2)
+	table.now = func() time.Time { return now }
+
+	if err := table.HandleDescriptor(testDescriptor("node1", now), "0x01"); err != nil {
+		t.Fatal(err)
+	}
+	hidden := testDescriptor("node2", now)
+	hidden.P2PAddress = "" // Validador detrás de sentries
+	if err := table.HandleDescriptor(hidden, "0x02"); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.HandleDescriptor(testDescriptor("node3", now), "0x03"); err == nil {
+		t.Error("Con la tabla llena un peer nuevo debería rechazarse")
+	}
+	if got := table.PersistentPeers(); len(got) != 1 || got[0] != "node1@10.0.0.1:26656" {
+		t.Errorf("Direcciones persistentes incorrectas: %v", got)
+	}
+
+	// Pasado el TTL los peers expiran y liberan lugar
+	now = now.Add(2 * time.Minute)
+	if err := table.HandleDescriptor(testDescriptor("node3", now), "0x03"); err != nil {
+		t.Fatal(err)
+	}
+	if peers := table.Peers(); len(peers) != 1 || peers[0].NodeID != "node3" {
+		t.Errorf("Los peers vencidos deberían expirar: %+v", peers)
+	}
+}
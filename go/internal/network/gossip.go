@@ -0,0 +1,430 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// Topics de gossip de la cadena por mesh
+// Permiten que participantes de la mesh sin conexión CometBFT sigan la cadena
+const (
+	TopicBlockHeaders    = "oxy-blockchain:headers"
+	TopicTxAnnouncements = "oxy-blockchain:tx-announce"
+)
+
+// DefaultFollowedHeaders es la cantidad de headers recientes que mantiene el ChainFollower
+const DefaultFollowedHeaders = 1000
+
+// BlockHeaderAnnouncement es el header de un bloque confirmado anunciado por la mesh
+type BlockHeaderAnnouncement struct {
+	Height     uint64    `json:"height"`
+	Hash       string    `json:"hash"`
+	ParentHash string    `json:"parentHash"`
+	Timestamp  time.Time `json:"timestamp"`
+	ChainID    string    `json:"chainId"`
+	Validator  string    `json:"validator,omitempty"`
+	TxCount    int       `json:"txCount"`
+
+	// Roots del header: permiten verificar pruebas de estado, transacciones y receipts contra el anuncio
+	StateRoot        string `json:"stateRoot,omitempty"`
+	TransactionsRoot string `json:"transactionsRoot,omitempty"`
+	ReceiptsRoot     string `json:"receiptsRoot,omitempty"`
+	GasUsed          uint64 `json:"gasUsed,omitempty"`
+	GasLimit         uint64 `json:"gasLimit,omitempty"`
+}
+
+// TxAnnouncement anuncia una transacción incluida en un bloque confirmado
+type TxAnnouncement struct {
+	Hash   string `json:"hash"`
+	From   string `json:"from"`
+	To     string `json:"to,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Nonce  uint64 `json:"nonce"`
+	Status string `json:"status,omitempty"` // "success" o "failed" según el recibo
+}
+
+// TxAnnouncementBatch agrupa los anuncios de transacciones de un bloque
+type TxAnnouncementBatch struct {
+	Height    uint64           `json:"height"`
+	BlockHash string           `json:"blockHash"`
+	Txs       []TxAnnouncement `json:"txs"`
+}
+
+// NewBlockHeaderAnnouncement crea el anuncio de header de un bloque confirmado
+func NewBlockHeaderAnnouncement(block *consensus.Block) *BlockHeaderAnnouncement {
+	return &BlockHeaderAnnouncement{
+		Height:     block.Header.Height,
+		Hash:       block.Header.Hash,
+		ParentHash: block.Header.ParentHash,
+		Timestamp:  block.Header.Timestamp,
+		ChainID:    block.Header.ChainID,
+		Validator:  block.Header.Validator,
+		TxCount:    len(block.Transactions),
+
+		StateRoot:        block.Header.StateRoot,
+		TransactionsRoot: block.Header.TransactionsRoot,
+		ReceiptsRoot:     block.Header.ReceiptsRoot,
+		GasUsed:          block.Header.GasUsed,
+		GasLimit:         block.Header.GasLimit,
+	}
+}
+
+// NewTxAnnouncementBatch crea los anuncios de las transacciones de un bloque confirmado
+func NewTxAnnouncementBatch(block *consensus.Block) *TxAnnouncementBatch {
+	statuses := make(map[string]string, len(block.Receipts))
+	for _, receipt := range block.Receipts {
+		if receipt != nil {
+			statuses[receipt.TransactionHash] = receipt.Status
+		}
+	}
+
+	batch := &TxAnnouncementBatch{
+		Height:    block.Header.Height,
+		BlockHash: block.Header.Hash,
+		Txs:       make([]TxAnnouncement, 0, len(block.Transactions)),
+	}
+	for _, tx := range block.Transactions {
+		if tx == nil {
+			continue
+		}
+		batch.Txs = append(batch.Txs, TxAnnouncement{
+			Hash:   tx.Hash,
+			From:   tx.From,
+			To:     tx.To,
+			Value:  tx.Value,
+			Nonce:  tx.Nonce,
+			Status: statuses[tx.Hash],
+		})
+	}
+	return batch
+}
+
+// ErrUnauthorizedAnnouncement se retorna cuando un anuncio de la cadena no viene firmado por un validador activo
+var ErrUnauthorizedAnnouncement = errors.New("anuncio no firmado por un validador activo")
+
+// ChainFollower sigue la cadena a partir de los headers anunciados por la mesh
+// Solo acepta headers firmados por validadores activos o confirmados por el CometBFT local,
+// verifica que encadenen con su padre y registra las transacciones anunciadas de cada bloque
+type ChainFollower struct {
+	chainID     string
+	maxHeaders  int
+	headers     map[uint64]*BlockHeaderAnnouncement
+	committed   map[uint64]bool // Alturas confirmadas por el CometBFT local
+	disputed    map[uint64]bool // Alturas con headers conflictivos de validadores, pendientes de confirmar
+	txs         map[uint64][]TxAnnouncement
+	latest      *BlockHeaderAnnouncement
+	onHeader    func(*BlockHeaderAnnouncement)
+	isValidator func(signer string) bool
+	mu          sync.RWMutex
+}
+
+// NewChainFollower crea un seguidor de la cadena indicada (chainID vacío acepta cualquiera)
+func NewChainFollower(chainID string, maxHeaders int) *ChainFollower {
+	if maxHeaders <= 0 {
+		maxHeaders = DefaultFollowedHeaders
+	}
+	return &ChainFollower{
+		chainID:    chainID,
+		maxHeaders: maxHeaders,
+		headers:    make(map[uint64]*BlockHeaderAnnouncement),
+		committed:  make(map[uint64]bool),
+		disputed:   make(map[uint64]bool),
+		txs:        make(map[uint64][]TxAnnouncement),
+	}
+}
+
+// SetHeaderHandler establece la función llamada con cada header nuevo aceptado
+func (f *ChainFollower) SetHeaderHandler(handler func(*BlockHeaderAnnouncement)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onHeader = handler
+}
+
+// SetValidatorCheck establece la función que indica si una identidad mesh verificada
+// pertenece al conjunto de validadores activos. Sin ella solo se aceptan headers locales
+func (f *ChainFollower) SetValidatorCheck(check func(signer string) bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.isValidator = check
+}
+
+// authorize verifica que el firmante verificado de un anuncio sea un validador activo
+func (f *ChainFollower) authorize(signer string) error {
+	f.mu.RLock()
+	check := f.isValidator
+	f.mu.RUnlock()
+
+	if signer == "" {
+		return fmt.Errorf("%w: anuncio sin firma", ErrUnauthorizedAnnouncement)
+	}
+	if check == nil || !check(signer) {
+		return fmt.Errorf("%w: %s", ErrUnauthorizedAnnouncement, signer)
+	}
+	return nil
+}
+
+// HandleHeader procesa un header anunciado por la mesh con la identidad verificada de quien lo firmó
+// Los duplicados se ignoran; se rechazan headers sin firma de un validador activo, de otra cadena,
+// que no encadenan con el padre conocido o conflictivos con uno ya aceptado. Dos validadores
+// anunciando headers distintos en la misma altura la dejan en disputa hasta que el CometBFT local la confirme
+func (f *ChainFollower) HandleHeader(header *BlockHeaderAnnouncement, signer string) error {
+	if err := f.authorize(signer); err != nil {
+		return err
+	}
+	return f.handleHeader(header, false)
+}
+
+// HandleCommittedHeader procesa el header de un bloque confirmado por el CometBFT local
+// Es la fuente de verdad: reemplaza cualquier header anunciado por la mesh en la misma altura
+func (f *ChainFollower) HandleCommittedHeader(header *BlockHeaderAnnouncement) error {
+	return f.handleHeader(header, true)
+}
+
+// handleHeader registra un header ya autorizado
+func (f *ChainFollower) handleHeader(header *BlockHeaderAnnouncement, committed bool) error {
+	if header == nil || header.Height == 0 || header.Hash == "" {
+		return fmt.Errorf("header incompleto")
+	}
+	if f.chainID != "" && header.ChainID != f.chainID {
+		return fmt.Errorf("header de otra cadena: %s", header.ChainID)
+	}
+
+	f.mu.Lock()
+
+	if !committed && f.disputed[header.Height] {
+		f.mu.Unlock()
+		return fmt.Errorf("altura %d en disputa, se espera confirmación local", header.Height)
+	}
+
+	if existing, ok := f.headers[header.Height]; ok {
+		if strings.EqualFold(existing.Hash, header.Hash) {
+			if committed {
+				f.committed[header.Height] = true
+			}
+			f.mu.Unlock()
+			return nil
+		}
+		switch {
+		case committed:
+			// El bloque confirmado localmente reemplaza al anunciado y a sus transacciones
+			delete(f.txs, header.Height)
+		case f.committed[header.Height]:
+			f.mu.Unlock()
+			return fmt.Errorf("header conflictivo en altura %d: %s != %s (confirmado)", header.Height, header.Hash, existing.Hash)
+		default:
+			// Ningún anuncio prevalece por llegar primero: la altura queda en disputa
+			f.dropHeight(header.Height)
+			f.disputed[header.Height] = true
+			f.mu.Unlock()
+			return fmt.Errorf("header conflictivo en altura %d: %s != %s", header.Height, header.Hash, existing.Hash)
+		}
+	}
+
+	if !committed {
+		if parent, ok := f.headers[header.Height-1]; ok && header.ParentHash != "" && !strings.EqualFold(parent.Hash, header.ParentHash) {
+			f.mu.Unlock()
+			return fmt.Errorf("header en altura %d no encadena con el padre %s", header.Height, parent.Hash)
+		}
+	}
+
+	// Ignorar headers demasiado viejos para la ventana que mantenemos
+	if f.latest != nil && header.Height+uint64(f.maxHeaders) <= f.latest.Height {
+		f.mu.Unlock()
+		return nil
+	}
+
+	f.headers[header.Height] = header
+	if committed {
+		f.committed[header.Height] = true
+		delete(f.disputed, header.Height)
+	}
+	if f.latest == nil || header.Height >= f.latest.Height {
+		f.latest = header
+		f.prune()
+	}
+	handler := f.onHeader
+	f.mu.Unlock()
+
+	if handler != nil {
+		handler(header)
+	}
+	return nil
+}
+
+// dropHeight descarta el header y las transacciones de una altura (requiere lock)
+func (f *ChainFollower) dropHeight(height uint64) {
+	delete(f.headers, height)
+	delete(f.txs, height)
+	if f.latest != nil && f.latest.Height == height {
+		f.latest = nil
+		for _, header := range f.headers {
+			if f.latest == nil || header.Height > f.latest.Height {
+				f.latest = header
+			}
+		}
+	}
+}
+
+// HandleTxAnnouncements registra las transacciones anunciadas de un bloque ya conocido
+// El anuncio debe venir firmado por un validador activo y corresponder al header aceptado
+func (f *ChainFollower) HandleTxAnnouncements(batch *TxAnnouncementBatch, signer string) error {
+	if batch == nil || batch.Height == 0 {
+		return fmt.Errorf("anuncio de transacciones incompleto")
+	}
+	if err := f.authorize(signer); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	header, ok := f.headers[batch.Height]
+	if !ok || !strings.EqualFold(header.Hash, batch.BlockHash) {
+		return fmt.Errorf("anuncio de transacciones para bloque desconocido %s en altura %d", batch.BlockHash, batch.Height)
+	}
+	if f.latest != nil && batch.Height+uint64(f.maxHeaders) <= f.latest.Height {
+		return nil
+	}
+
+	f.txs[batch.Height] = batch.Txs
+	return nil
+}
+
+// prune descarta headers y transacciones fuera de la ventana (requiere lock)
+func (f *ChainFollower) prune() {
+	if f.latest == nil || f.latest.Height <= uint64(f.maxHeaders) {
+		return
+	}
+	minHeight := f.latest.Height - uint64(f.maxHeaders) + 1
+	for height := range f.headers {
+		if height < minHeight {
+			delete(f.headers, height)
+		}
+	}
+	for height := range f.txs {
+		if height < minHeight {
+			delete(f.txs, height)
+		}
+	}
+	for height := range f.committed {
+		if height < minHeight {
+			delete(f.committed, height)
+		}
+	}
+	for height := range f.disputed {
+		if height < minHeight {
+			delete(f.disputed, height)
+		}
+	}
+}
+
+// LatestHeader retorna el header más alto conocido (nil si aún no se recibió ninguno)
+func (f *ChainFollower) LatestHeader() *BlockHeaderAnnouncement {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.latest
+}
+
+// LatestHeight retorna la altura del header más alto conocido (0 si aún no se recibió ninguno)
+// Se usa como altura de la red para calcular cuántos bloques está atrasado el nodo
+func (f *ChainFollower) LatestHeight() int64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.latest == nil {
+		return 0
+	}
+	return int64(f.latest.Height)
+}
+
+// Header retorna el header conocido en una altura
+func (f *ChainFollower) Header(height uint64) (*BlockHeaderAnnouncement, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	header, ok := f.headers[height]
+	return header, ok
+}
+
+// BlockTxs retorna las transacciones anunciadas de un bloque
+func (f *ChainFollower) BlockTxs(height uint64) ([]TxAnnouncement, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	txs, ok := f.txs[height]
+	return txs, ok
+}
+
+// blockAnnouncer publica por la mesh los bloques confirmados localmente
+// Commit no debe bloquearse por la red, así que los bloques se encolan y
+// un worker los publica en orden. Solo el proponente de cada bloque lo anuncia,
+// así la mesh recibe un anuncio por bloque y no uno por nodo
+type blockAnnouncer struct {
+	bridge     *MeshBridge
+	follower   *ChainFollower
+	isProposer func(*consensus.Block) bool // Sin función no se anuncia ningún bloque
+	queue      chan *consensus.Block
+	stopChan   chan struct{}
+	stopOnce   sync.Once
+}
+
+// announceQueueSize es la cantidad de bloques pendientes de anunciar antes de descartar
+const announceQueueSize = 64
+
+// newBlockAnnouncer crea el publicador de bloques confirmados
+func newBlockAnnouncer(bridge *MeshBridge, follower *ChainFollower) *blockAnnouncer {
+	return &blockAnnouncer{
+		bridge:   bridge,
+		follower: follower,
+		queue:    make(chan *consensus.Block, announceQueueSize),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// enqueue encola un bloque confirmado sin bloquear (se descarta si la cola está llena)
+func (a *blockAnnouncer) enqueue(block *consensus.Block) {
+	if block == nil {
+		return
+	}
+
+	// El propio nodo también sigue la cadena: así los ecos de la mesh se ven como duplicados
+	if a.follower != nil {
+		if err := a.follower.HandleCommittedHeader(NewBlockHeaderAnnouncement(block)); err != nil {
+			log.Printf("⚠️ Header local rechazado por el follower: %v", err)
+		}
+	}
+
+	if a.isProposer == nil || !a.isProposer(block) {
+		return
+	}
+
+	select {
+	case a.queue <- block:
+	default:
+		log.Printf("⚠️ Cola de anuncios de bloques llena, altura %d no se anunciará por mesh", block.Header.Height)
+	}
+}
+
+// run publica los bloques encolados hasta que se detenga
+func (a *blockAnnouncer) run() {
+	for {
+		select {
+		case <-a.stopChan:
+			return
+		case block := <-a.queue:
+			if err := a.bridge.AnnounceBlock(block); err != nil {
+				log.Printf("⚠️ Error anunciando bloque %d por mesh: %v", block.Header.Height, err)
+			}
+		}
+	}
+}
+
+// stop detiene el worker
+func (a *blockAnnouncer) stop() {
+	a.stopOnce.Do(func() {
+		close(a.stopChan)
+	})
+}
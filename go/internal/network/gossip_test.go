@@ -0,0 +1,290 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// testHeader crea un header encadenado para la altura indicada
+func testHeader(height uint64) *BlockHeaderAnnouncement {
+	return &BlockHeaderAnnouncement{
+		Height:     height,
+		Hash:       fmt.Sprintf("0x%064x", height),
+		ParentHash: fmt.Sprintf("0x%064x", height-1),
+		Timestamp:  time.Unix(int64(height), 0),
+		ChainID:    "oxy-test",
+	}
+}
+
+// testValidator es la identidad mesh de un validador activo en los tests
+const testValidator = "0x00000000000000000000000000000000000000aa"
+
+// newTestFollower crea un follower que reconoce a testValidator como validador activo
+func newTestFollower(chainID string, maxHeaders int) *ChainFollower {
+	follower := NewChainFollower(chainID, maxHeaders)
+	follower.SetValidatorCheck(func(signer string) bool {
+		return strings.EqualFold(signer, testValidator)
+	})
+	return follower
+}
+
+// TestChainFollowerHeaders verifica el seguimiento de headers, duplicados, conflictos y pruning
+func TestChainFollowerHeaders(t *testing.T) {
+	follower := newTestFollower("oxy-test", 3)
+
+	var notified []uint64
+	follower.SetHeaderHandler(func(h *BlockHeaderAnnouncement) {
+		notified = append(notified, h.Height)
+	})
+
+	for height := uint64(1); height <= 5; height++ {
+		if err := follower.HandleHeader(testHeader(height), testValidator); err != nil {
+			t.Fatalf("Header %d debería aceptarse: %v", height, err)
+		}
+	}
+
+	if latest := follower.LatestHeader(); latest == nil || latest.Height != 5 {
+		t.Fatalf("Último header esperado 5, obtenido %+v", latest)
+	}
+	if follower.LatestHeight() != 5 {
+		t.Errorf("Altura esperada 5, obtenida %d", follower.LatestHeight())
+	}
+
+	// Duplicado se ignora sin notificar
+	if err := follower.HandleHeader(testHeader(5), testValidator); err != nil {
+		t.Errorf("Duplicado no debería fallar: %v", err)
+	}
+	if len(notified) != 5 {
+		t.Errorf("Esperadas 5 notificaciones, obtenidas %d", len(notified))
+	}
+
+	// Header que no encadena con el padre conocido
+	orphan := testHeader(6)
+	orphan.ParentHash = "0xbad"
+	if err := follower.HandleHeader(orphan, testValidator); err == nil {
+		t.Error("Header que no encadena debería rechazarse")
+	}
+
+	// Otra cadena
+	other := testHeader(6)
+	other.ChainID = "otra"
+	if err := follower.HandleHeader(other, testValidator); err == nil {
+		t.Error("Header de otra cadena debería rechazarse")
+	}
+
+	// Solo se mantienen los últimos 3 headers
+	if _, ok := follower.Header(2); ok {
+		t.Error("Header 2 debería haberse descartado")
+	}
+	if _, ok := follower.Header(3); !ok {
+		t.Error("Header 3 debería mantenerse")
+	}
+}
+
+// TestChainFollowerRejectsUnauthorized verifica que solo se aceptan headers firmados por validadores activos
+func TestChainFollowerRejectsUnauthorized(t *testing.T) {
+	follower := newTestFollower("oxy-test", 10)
+
+	if err := follower.HandleHeader(testHeader(1), ""); !errors.Is(err, ErrUnauthorizedAnnouncement) {
+		t.Errorf("Header sin firma debería rechazarse, error: %v", err)
+	}
+	if err := follower.HandleHeader(testHeader(1), "0x00000000000000000000000000000000000000bb"); !errors.Is(err, ErrUnauthorizedAnnouncement) {
+		t.Errorf("Header de un no validador debería rechazarse, error: %v", err)
+	}
+	if follower.LatestHeader() != nil {
+		t.Error("Ningún header no autorizado debería registrarse")
+	}
+
+	// Sin función de validadores solo se aceptan headers locales
+	local := NewChainFollower("oxy-test", 10)
+	if err := local.HandleHeader(testHeader(1), testValidator); !errors.Is(err, ErrUnauthorizedAnnouncement) {
+		t.Errorf("Sin validadores conocidos el header debería rechazarse, error: %v", err)
+	}
+	if err := local.HandleCommittedHeader(testHeader(1)); err != nil {
+		t.Errorf("Header confirmado localmente debería aceptarse: %v", err)
+	}
+}
+
+// TestChainFollowerConflicts verifica que el primer header anunciado no prevalece para siempre
+func TestChainFollowerConflicts(t *testing.T) {
+	follower := newTestFollower("oxy-test", 10)
+
+	forged := testHeader(1)
+	forged.Hash = "0xbad"
+	if err := follower.HandleHeader(forged, testValidator); err != nil {
+		t.Fatalf("Primer header debería aceptarse: %v", err)
+	}
+
+	// Dos headers distintos en la misma altura: la altura queda en disputa
+	if err := follower.HandleHeader(testHeader(1), testValidator); err == nil {
+		t.Error("Header conflictivo debería reportarse")
+	}
+	if _, ok := follower.Header(1); ok {
+		t.Error("Una altura en disputa no debería tener header")
+	}
+	if err := follower.HandleHeader(forged, testValidator); err == nil {
+		t.Error("Una altura en disputa no debería aceptar anuncios")
+	}
+
+	// El bloque confirmado localmente resuelve la disputa
+	if err := follower.HandleCommittedHeader(testHeader(1)); err != nil {
+		t.Fatalf("Header confirmado debería aceptarse: %v", err)
+	}
+	if header, ok := follower.Header(1); !ok || header.Hash != testHeader(1).Hash {
+		t.Errorf("Header confirmado esperado, obtenido %+v", header)
+	}
+
+	// Un anuncio no reemplaza un header confirmado
+	if err := follower.HandleHeader(forged, testValidator); err == nil {
+		t.Error("Header conflictivo con uno confirmado debería rechazarse")
+	}
+
+	// Un header confirmado reemplaza al anunciado
+	announced := testHeader(2)
+	announced.Hash = "0xbad2"
+	if err := follower.HandleHeader(announced, testValidator); err != nil {
+		t.Fatalf("Header anunciado debería aceptarse: %v", err)
+	}
+	if err := follower.HandleCommittedHeader(testHeader(2)); err != nil {
+		t.Fatalf("Header confirmado debería reemplazar al anunciado: %v", err)
+	}
+	if latest := follower.LatestHeader(); latest == nil || latest.Hash != testHeader(2).Hash {
+		t.Errorf("Último header debería ser el confirmado, obtenido %+v", latest)
+	}
+}
+
+// TestChainFollowerTxAnnouncements verifica el registro de transacciones anunciadas
+func TestChainFollowerTxAnnouncements(t *testing.T) {
+	follower := newTestFollower("", 10)
+
+	batch := &TxAnnouncementBatch{
+		Height:    1,
+		BlockHash: testHeader(1).Hash,
+		Txs:       []TxAnnouncement{{Hash: "0xaa", From: "0x01", Nonce: 1}},
+	}
+
+	// Sin header conocido el anuncio no se puede validar
+	if err := follower.HandleTxAnnouncements(batch, testValidator); err == nil {
+		t.Error("Anuncio sin header conocido debería rechazarse")
+	}
+
+	follower.HandleHeader(testHeader(1), testValidator)
+	if err := follower.HandleTxAnnouncements(batch, ""); !errors.Is(err, ErrUnauthorizedAnnouncement) {
+		t.Errorf("Anuncio sin firma debería rechazarse, error: %v", err)
+	}
+	if err := follower.HandleTxAnnouncements(batch, testValidator); err != nil {
+		t.Fatalf("Anuncio debería aceptarse: %v", err)
+	}
+
+	txs, ok := follower.BlockTxs(1)
+	if !ok || len(txs) != 1 || txs[0].Hash != "0xaa" {
+		t.Errorf("Transacciones inesperadas: %+v", txs)
+	}
+
+	// Anuncio de un bloque distinto al header conocido
+	batch.BlockHash = "0xbad"
+	if err := follower.HandleTxAnnouncements(batch, testValidator); err == nil {
+		t.Error("Anuncio de bloque desconocido debería rechazarse")
+	}
+}
+
+// TestBlockAnnouncementFromBlock verifica la construcción de anuncios a partir de un bloque
+func TestBlockAnnouncementFromBlock(t *testing.T) {
+	block := &consensus.Block{
+		Header: consensus.BlockHeader{Height: 7, Hash: "0x07", ParentHash: "0x06", ChainID: "oxy-test"},
+		Transactions: []*consensus.Transaction{
+			{Hash: "0xaa", From: "0x01", To: "0x02", Value: "10", Nonce: 3},
+			{Hash: "0xbb", From: "0x03", Nonce: 1},
+		},
+		Receipts: []*consensus.TransactionReceipt{
+			{TransactionHash: "0xaa", Status: "success"},
+			{TransactionHash: "0xbb", Status: "failed"},
+		},
+	}
+
+	header := NewBlockHeaderAnnouncement(block)
+	if header.Height != 7 || header.TxCount != 2 || header.ParentHash != "0x06" {
+		t.Errorf("Header inesperado: %+v", header)
+	}
+
+	batch := NewTxAnnouncementBatch(block)
+	if batch.BlockHash != "0x07" || len(batch.Txs) != 2 {
+		t.Fatalf("Anuncio inesperado: %+v", batch)
+	}
+	if batch.Txs[0].Status != "success" || batch.Txs[1].Status != "failed" {
+		t.Errorf("Estados inesperados: %+v", batch.Txs)
+	}
+}
+
+// TestMeshBridgeReceiveHeader verifica que solo los headers firmados por validadores alimentan al follower
+func TestMeshBridgeReceiveHeader(t *testing.T) {
+	meshBridge := NewMeshBridge(context.Background(), nil, "ws://localhost:3001", nil)
+	follower := newTestFollower("oxy-test", 10)
+	meshBridge.SetChainFollower(follower)
+
+	data, _ := json.Marshal(testHeader(1))
+	if err := meshBridge.ReceiveMessage(TopicBlockHeaders, data); err == nil {
+		t.Error("Header sin remitente verificado debería rechazarse")
+	}
+	if err := meshBridge.receiveMessage(TopicBlockHeaders, data, testValidator); err != nil {
+		t.Fatalf("Header de un validador debería aceptarse: %v", err)
+	}
+	if latest := follower.LatestHeader(); latest == nil || latest.Height != 1 {
+		t.Errorf("Follower debería tener el header 1, tiene %+v", latest)
+	}
+
+	if err := meshBridge.ReceiveMessage(TopicBlockHeaders, []byte("no json")); err == nil {
+		t.Error("Payload inválido debería fallar")
+	}
+}
+
+// TestBlockAnnouncerQueue verifica que el announcer no bloquea, registra el header local
+// y solo encola los bloques propuestos por el nodo
+func TestBlockAnnouncerQueue(t *testing.T) {
+	follower := NewChainFollower("oxy-test", 10)
+	announcer := newBlockAnnouncer(nil, follower)
+	announcer.isProposer = func(block *consensus.Block) bool {
+		return block.Header.Validator == "LOCAL"
+	}
+
+	for height := uint64(1); height <= announceQueueSize+5; height++ {
+		announcer.enqueue(&consensus.Block{Header: consensus.BlockHeader{
+			Height:     height,
+			Hash:       testHeader(height).Hash,
+			ParentHash: testHeader(height).ParentHash,
+			ChainID:    "oxy-test",
+			Validator:  "LOCAL",
+		}})
+	}
+
+	if len(announcer.queue) != announceQueueSize {
+		t.Errorf("La cola debería estar llena (%d), tiene %d", announceQueueSize, len(announcer.queue))
+	}
+	if latest := follower.LatestHeader(); latest == nil || latest.Height != announceQueueSize+5 {
+		t.Errorf("El follower debería seguir los bloques locales, último: %+v", latest)
+	}
+
+	// Un bloque de otro proponente se sigue pero no se anuncia
+	<-announcer.queue
+	height := uint64(announceQueueSize + 6)
+	announcer.enqueue(&consensus.Block{Header: consensus.BlockHeader{
+		Height:     height,
+		Hash:       testHeader(height).Hash,
+		ParentHash: testHeader(height).ParentHash,
+		ChainID:    "oxy-test",
+		Validator:  "OTRO",
+	}})
+	if len(announcer.queue) != announceQueueSize-1 {
+		t.Errorf("Un bloque ajeno no debería encolarse, cola: %d", len(announcer.queue))
+	}
+	if follower.LatestHeight() != int64(height) {
+		t.Errorf("El follower debería seguir el bloque ajeno, altura: %d", follower.LatestHeight())
+	}
+}
@@ -0,0 +1,265 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// Sincronización de headers por mesh: la query "headers/{from}/{to}" (get_headers) se responde con un lote de
+// headers consecutivos (headers). Permite que nodos livianos con enlaces intermitentes recuperen los headers
+// anunciados que se perdieron mientras estaban desconectados
+const (
+	headersPathPrefix         = "headers/"
+	MaxHeadersPerQuery        = 100 // Headers por respuesta: lotes más grandes se piden en varias queries
+	DefaultHeaderSyncInterval = 30 * time.Second
+	maxHeaderSyncBatches      = 50 // Lotes por ronda de sincronización (el resto se pide en la siguiente)
+)
+
+// HeadersBatch es la respuesta a una query de headers
+type HeadersBatch struct {
+	From    uint64                     `json:"from"`
+	Headers []*BlockHeaderAnnouncement `json:"headers"` // Consecutivos desde From (vacío si From supera a Latest)
+	Latest  uint64                     `json:"latest"`  // Altura más alta del nodo que responde
+}
+
+// HeadersQueryPath retorna el path de la query de headers entre dos alturas (inclusive)
+func HeadersQueryPath(from, to uint64) string {
+	return fmt.Sprintf("%s%d/%d", headersPathPrefix, from, to)
+}
+
+// parseHeadersPath extrae el rango de una query de headers
+func parseHeadersPath(path string) (uint64, uint64, error) {
+	var from, to uint64
+	if _, err := fmt.Sscanf(path[len(headersPathPrefix):], "%d/%d", &from, &to); err != nil {
+		return 0, 0, fmt.Errorf("rango de headers inválido: %s", path)
+	}
+	if from == 0 || to < from {
+		return 0, 0, fmt.Errorf("rango de headers inválido: %d-%d", from, to)
+	}
+	return from, to, nil
+}
+
+// headersBatch arma la respuesta a una query de headers desde el storage local, con hasta
+// MaxHeadersPerQuery headers y sin pasar del último bloque. Los bloques podados cortan el lote
+func (qh *QueryHandler) headersBatch(from, to uint64) (*HeadersBatch, error) {
+	latest, err := qh.storage.GetLatestHeight()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo altura: %w", err)
+	}
+	batch := &HeadersBatch{From: from, Headers: []*BlockHeaderAnnouncement{}, Latest: latest}
+	if to > latest {
+		to = latest
+	}
+	if to-from >= MaxHeadersPerQuery {
+		to = from + MaxHeadersPerQuery - 1
+	}
+
+	for height := from; height <= to; height++ {
+		data, err := qh.storage.GetBlock(height)
+		if err != nil {
+			if len(batch.Headers) == 0 {
+				return nil, fmt.Errorf("header no disponible: altura %d", height)
+			}
+			break
+		}
+		block, err := consensus.DecodeBlock(data)
+		if err != nil {
+			return nil, fmt.Errorf("bloque %d inválido: %w", height, err)
+		}
+		batch.Headers = append(batch.Headers, NewBlockHeaderAnnouncement(block))
+	}
+	return batch, nil
+}
+
+// ErrInvalidHeaders se retorna cuando un lote de headers no es consecutivo o no encadena
+var ErrInvalidHeaders = errors.New("lote de headers inválido")
+
+// verifyHeadersBatch verifica que los headers sean consecutivos desde from, que cada hash corresponda a su
+// header y que encadenen entre sí y con parentHash (el hash conocido de from-1, vacío si no se conoce)
+func verifyHeadersBatch(batch *HeadersBatch, from uint64, parentHash string) error {
+	if batch.From != from {
+		return fmt.Errorf("%w: desde %d, se pidió %d", ErrInvalidHeaders, batch.From, from)
+	}
+	for i, header := range batch.Headers {
+		if header == nil || header.Height != from+uint64(i) {
+			return fmt.Errorf("%w: headers no consecutivos desde %d", ErrInvalidHeaders, from)
+		}
+		// Los headers anteriores a los roots tienen el app hash como hash y no se pueden recalcular
+		if header.TransactionsRoot != "" || header.ReceiptsRoot != "" {
+			computed := (&consensus.BlockHeader{
+				Height:           header.Height,
+				ParentHash:       header.ParentHash,
+				Timestamp:        header.Timestamp,
+				Validator:        header.Validator,
+				ChainID:          header.ChainID,
+				StateRoot:        header.StateRoot,
+				TransactionsRoot: header.TransactionsRoot,
+				ReceiptsRoot:     header.ReceiptsRoot,
+				GasUsed:          header.GasUsed,
+				GasLimit:         header.GasLimit,
+			}).ComputeHash().Hex()
+			if !strings.EqualFold(computed, header.Hash) {
+				return fmt.Errorf("%w: hash %s no corresponde al header de la altura %d", ErrInvalidHeaders, header.Hash, header.Height)
+			}
+		}
+		if parentHash != "" && !strings.EqualFold(header.ParentHash, parentHash) {
+			return fmt.Errorf("%w: altura %d no encadena con el padre %s", ErrInvalidHeaders, header.Height, parentHash)
+		}
+		parentHash = header.Hash
+	}
+	return nil
+}
+
+// QueryHeaders pide a otros nodos los headers entre dos alturas (como máximo MaxHeadersPerQuery)
+// parentHash es el hash conocido de from-1 con el que debe encadenar el lote (vacío para no verificarlo);
+// validate permite descartar además la respuesta de un peer (ej: que no sea validador) y consultar a otro
+func (qh *QueryHandler) QueryHeaders(from, to uint64, parentHash string, validate func(*QueryResponse) error, timeout time.Duration) (*HeadersBatch, string, error) {
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	var batch HeadersBatch
+	response, err := qh.QueryWithOptions(HeadersQueryPath(from, to), QueryOptions{
+		Timeout:      timeout / time.Duration(DefaultQueryRetries+1),
+		TotalTimeout: timeout,
+		Retries:      DefaultQueryRetries,
+		FanOut:       DefaultQueryFanOut,
+		Validate: func(response *QueryResponse) error {
+			if validate != nil {
+				if err := validate(response); err != nil {
+					return err
+				}
+			}
+			batch = HeadersBatch{}
+			if err := json.Unmarshal(response.Data, &batch); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidHeaders, err)
+			}
+			return verifyHeadersBatch(&batch, from, parentHash)
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &batch, response.From, nil
+}
+
+// HeaderSync mantiene al ChainFollower al día pidiendo por mesh los headers que faltan desde el último
+// conocido. Cada ronda retoma desde donde quedó la anterior, así un enlace que se corta a mitad de la
+// sincronización no la reinicia. Solo se aceptan lotes respondidos por validadores activos
+type HeaderSync struct {
+	query    *QueryHandler
+	follower *ChainFollower
+	interval time.Duration
+	timeout  time.Duration
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewHeaderSync crea el sincronizador de headers (interval 0 usa DefaultHeaderSyncInterval)
+func NewHeaderSync(query *QueryHandler, follower *ChainFollower, interval time.Duration) *HeaderSync {
+	if interval <= 0 {
+		interval = DefaultHeaderSyncInterval
+	}
+	return &HeaderSync{
+		query:    query,
+		follower: follower,
+		interval: interval,
+		timeout:  DefaultQueryTimeout,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// SyncOnce pide lotes de headers desde el último conocido hasta alcanzar la altura del peer que responde
+// y retorna la cantidad de headers nuevos aceptados. Sin headers conocidos empieza por la ventana del follower
+func (s *HeaderSync) SyncOnce() (int, error) {
+	validator := func(response *QueryResponse) error {
+		return s.follower.authorize(response.From)
+	}
+
+	accepted := 0
+	for i := 0; i < maxHeaderSyncBatches; i++ {
+		from := uint64(s.follower.LatestHeight()) + 1
+		parentHash := ""
+		if from == 1 {
+			start, err := s.startHeight(validator)
+			if err != nil {
+				return accepted, err
+			}
+			from = start
+		} else if parent, ok := s.follower.Header(from - 1); ok {
+			parentHash = parent.Hash
+		}
+
+		batch, peer, err := s.query.QueryHeaders(from, from+MaxHeadersPerQuery-1, parentHash, validator, s.timeout)
+		if err != nil {
+			return accepted, fmt.Errorf("error pidiendo headers desde %d: %w", from, err)
+		}
+		for _, header := range batch.Headers {
+			if err := s.follower.HandleHeader(header, peer); err != nil {
+				return accepted, fmt.Errorf("header %d de %s rechazado: %w", header.Height, peer, err)
+			}
+			accepted++
+		}
+		if len(batch.Headers) == 0 || batch.Headers[len(batch.Headers)-1].Height >= batch.Latest {
+			return accepted, nil
+		}
+	}
+	return accepted, nil
+}
+
+// startHeight elige desde dónde sincronizar sin headers conocidos: los últimos que entran en la ventana
+// del follower según la altura de un validador
+func (s *HeaderSync) startHeight(validate func(*QueryResponse) error) (uint64, error) {
+	response, err := s.query.QueryWithOptions("height", QueryOptions{
+		Timeout:      s.timeout / time.Duration(DefaultQueryRetries+1),
+		TotalTimeout: s.timeout,
+		Retries:      DefaultQueryRetries,
+		FanOut:       DefaultQueryFanOut,
+		Validate:     validate,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo altura de la red: %w", err)
+	}
+	var status struct {
+		Height uint64 `json:"height"`
+	}
+	if err := json.Unmarshal(response.Data, &status); err != nil {
+		return 0, fmt.Errorf("altura de la red mal formada: %w", err)
+	}
+	window := uint64(s.follower.maxHeaders)
+	if status.Height <= window {
+		return 1, nil
+	}
+	return status.Height - window + 1, nil
+}
+
+// run sincroniza al iniciar y luego en cada intervalo hasta que se detenga
+func (s *HeaderSync) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if accepted, err := s.SyncOnce(); err != nil {
+			log.Printf("⚠️ Sincronización de headers por mesh incompleta (%d headers nuevos): %v", accepted, err)
+		} else if accepted > 0 {
+			log.Printf("📥 %d headers sincronizados por mesh (altura %d)", accepted, s.follower.LatestHeight())
+		}
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// stop detiene el sincronizador
+func (s *HeaderSync) stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+}
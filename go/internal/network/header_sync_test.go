@@ -0,0 +1,131 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// testHeaderChain crea n bloques encadenados con el hash calculado de su header
+func testHeaderChain(n int) []*consensus.Block {
+	blocks := make([]*consensus.Block, 0, n)
+	parent := ""
+	for height := 1; height <= n; height++ {
+		block := &consensus.Block{Header: consensus.BlockHeader{
+			Height:           uint64(height),
+			ParentHash:       parent,
+			Timestamp:        time.Unix(1700000000+int64(height), 0).UTC(),
+			ChainID:          "oxy-test",
+			StateRoot:        "0x01",
+			TransactionsRoot: "0x02",
+			ReceiptsRoot:     "0x03",
+		}}
+		block.Header.Hash = block.Header.ComputeHash().Hex()
+		parent = block.Header.Hash
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// TestHeadersBatch verifica el lote servido desde storage y la verificación del lado del cliente
+func TestHeadersBatch(t *testing.T) {
+	testDir := "./test_data_headers_" + t.Name()
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	defer func() {
+		db.Close()
+		os.RemoveAll(testDir)
+	}()
+
+	blocks := testHeaderChain(3)
+	for _, block := range blocks {
+		data, err := consensus.EncodeBlock(block)
+		if err != nil {
+			t.Fatalf("Error codificando bloque: %v", err)
+		}
+		if err := db.SaveBlock(block.Header.Height, data); err != nil {
+			t.Fatalf("Error guardando bloque: %v", err)
+		}
+	}
+	db.SaveLatestHeight(3)
+	handler := NewQueryHandler(context.Background(), db, nil, nil)
+
+	if _, _, err := parseHeadersPath("headers/5/2"); err == nil {
+		t.Error("Un rango invertido debería rechazarse")
+	}
+	from, to, err := parseHeadersPath(HeadersQueryPath(2, 500))
+	if err != nil || from != 2 || to != 500 {
+		t.Fatalf("Rango mal interpretado: %d-%d, %v", from, to, err)
+	}
+
+	batch, err := handler.headersBatch(from, to)
+	if err != nil {
+		t.Fatalf("Error armando lote: %v", err)
+	}
+	if batch.Latest != 3 || len(batch.Headers) != 2 || batch.Headers[1].Hash != blocks[2].Header.Hash {
+		t.Fatalf("Lote incorrecto: %+v", batch)
+	}
+	if err := verifyHeadersBatch(batch, 2, blocks[0].Header.Hash); err != nil {
+		t.Errorf("Lote válido rechazado: %v", err)
+	}
+	if err := verifyHeadersBatch(batch, 2, "0xotro"); !errors.Is(err, ErrInvalidHeaders) {
+		t.Errorf("Un lote que no encadena con el padre conocido debería rechazarse: %v", err)
+	}
+
+	// Un header alterado no corresponde a su hash
+	batch.Headers[1].GasUsed = 1
+	if err := verifyHeadersBatch(batch, 2, ""); !errors.Is(err, ErrInvalidHeaders) {
+		t.Errorf("Un header alterado debería rechazarse: %v", err)
+	}
+
+	// Desde más allá del último bloque el lote viene vacío: el cliente está al día
+	if batch, err := handler.headersBatch(4, 10); err != nil || len(batch.Headers) != 0 || batch.Latest != 3 {
+		t.Errorf("Lote más allá del último bloque incorrecto: %+v, %v", batch, err)
+	}
+}
+
+// TestHeaderSyncResume verifica que la sincronización retoma desde el último header conocido
+// y solo acepta lotes de validadores
+func TestHeaderSyncResume(t *testing.T) {
+	handler, meshBridge := newTestQueryHandler(t)
+	follower := NewChainFollower("oxy-test", 0)
+	follower.SetValidatorCheck(func(signer string) bool { return signer == "validator" })
+
+	blocks := testHeaderChain(3)
+	if err := follower.HandleCommittedHeader(NewBlockHeaderAnnouncement(blocks[0])); err != nil {
+		t.Fatalf("Error registrando header local: %v", err)
+	}
+
+	syncer := NewHeaderSync(handler, follower, time.Minute)
+	syncer.timeout = 3 * time.Second
+
+	go func() {
+		requests := waitForRequests(t, meshBridge, 1)
+		if requests[0].Path != HeadersQueryPath(2, MaxHeadersPerQuery+1) {
+			t.Errorf("Query inesperada: %s", requests[0].Path)
+		}
+		data, _ := json.Marshal(&HeadersBatch{
+			From:    2,
+			Headers: []*BlockHeaderAnnouncement{NewBlockHeaderAnnouncement(blocks[1]), NewBlockHeaderAnnouncement(blocks[2])},
+			Latest:  3,
+		})
+		handler.HandleResponse(QueryResponse{RequestID: requests[0].RequestID, Path: requests[0].Path, From: "intruso", Data: data})
+		handler.HandleResponse(QueryResponse{RequestID: requests[0].RequestID, Path: requests[0].Path, From: "validator", Data: data})
+	}()
+
+	accepted, err := syncer.SyncOnce()
+	if err != nil {
+		t.Fatalf("Error sincronizando: %v", err)
+	}
+	if accepted != 2 || follower.LatestHeight() != 3 {
+		t.Errorf("Esperados 2 headers nuevos hasta la altura 3, obtenidos %d (altura %d)", accepted, follower.LatestHeight())
+	}
+}
@@ -3,14 +3,19 @@ package network
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
 	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/health"
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
 )
 
@@ -26,6 +31,34 @@ type MeshBridge struct {
 	topics        map[string]bool // Topics suscritos
 	topicsMutex   sync.RWMutex
 	queryHandler  *QueryHandler // Handler de queries
+	writeMutex    sync.Mutex    // gorilla/websocket admite un solo escritor concurrente
+
+	// Reconexión automática con backoff exponencial
+	reconnecting   int32 // 1 mientras hay un ciclo de reconexión en curso
+	pingSentAt     int64 // Momento (UnixNano) del último ping sin pong, para medir el round-trip
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	// Buffer de mensajes salientes mientras no hay conexión
+	outbox        []*MeshMessage
+	outboxLimit   int
+	outboxDropped uint64
+	outboxMutex   sync.Mutex
+	durable       *durableOutbox // Mensajes relevantes para el consenso, persistidos (opcional)
+
+	healthChecker *health.HealthChecker // Refleja el estado de la conexión en /health
+	auth          *MeshAuth             // Firma y verificación de mensajes (nil deshabilita)
+	follower      *ChainFollower        // Seguimiento de la cadena por headers anunciados (opcional)
+	peerTable     *PeerTable            // Nodos descubiertos por sus descriptores anunciados (opcional)
+	txRelay       *TxRelay              // Transacciones enviadas por clientes de la mesh (opcional)
+
+	// Codificación negociada con el SDK (JSON hasta recibir hello_ack)
+	codec                meshCodec
+	codecMutex           sync.RWMutex
+	preferredEncoding    string
+	preferredCompression string
+	bytesSent            uint64
+	bytesReceived        uint64
 }
 
 // MeshMessage representa un mensaje del mesh network
@@ -35,6 +68,14 @@ type MeshMessage struct {
 	Data    json.RawMessage `json:"data,omitempty"`
 	From    string          `json:"from,omitempty"`
 	To      string          `json:"to,omitempty"`
+
+	// Autenticación: identidad del nodo emisor, timestamp en ms y firma secp256k1
+	Sender    string `json:"sender,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Signature string `json:"signature,omitempty"`
+
+	// Identidad verificada en authenticate (no viaja por la red)
+	verifiedSender string
 }
 
 // MeshTopics
@@ -44,6 +85,13 @@ const (
 	TopicValidators   = "validators"
 )
 
+// Valores por defecto de reconexión y buffering
+const (
+	DefaultReconnectInitialBackoff = 1 * time.Second
+	DefaultReconnectMaxBackoff     = 60 * time.Second
+	DefaultOutboxLimit             = 1000
+)
+
 // MessageTypes
 const (
 	MessageTypeSubscribe   = "subscribe"
@@ -67,6 +115,12 @@ func NewMeshBridge(
 		running:      false,
 		stopChan:     make(chan struct{}),
 		topics:       make(map[string]bool),
+		initialBackoff: DefaultReconnectInitialBackoff,
+		maxBackoff:     DefaultReconnectMaxBackoff,
+		outboxLimit:    DefaultOutboxLimit,
+		codec:                defaultCodec,
+		preferredEncoding:    EncodingProto,
+		preferredCompression: CompressionSnappy,
 	}
 
 	// Crear query handler si tenemos storage
@@ -83,24 +137,36 @@ func (mb *MeshBridge) Start() error {
 		return fmt.Errorf("mesh bridge ya está corriendo")
 	}
 
-	// Conectar a oxygen-sdk mesh endpoint
-	if err := mb.connect(); err != nil {
-		return fmt.Errorf("error conectando a mesh: %w", err)
-	}
-
-	// Suscribirse a topics necesarios
+	// Registrar topics necesarios (se re-suscriben en cada reconexión)
 	topics := []string{
 		TopicTransactions,
 		TopicBlocks,
 		TopicValidators,
 		"oxy-blockchain:query",
 		"oxy-blockchain:response",
+		TopicBlockHeaders,
+		TopicTxAnnouncements,
+		TopicNodes,
+		TopicTxRelay,
 	}
-	
+
+	mb.topicsMutex.Lock()
 	for _, topic := range topics {
-		if err := mb.subscribe(topic); err != nil {
-			log.Printf("Advertencia: error suscribiéndose a topic %s: %v", topic, err)
-		}
+		mb.topics[topic] = true
+	}
+	mb.topicsMutex.Unlock()
+
+	mb.running = true
+
+	// Conectar a oxygen-sdk mesh endpoint
+	// Si el SDK aún no está disponible, reintentar en segundo plano en lugar de fallar
+	if err := mb.connect(); err != nil {
+		log.Printf("⚠️ Mesh no disponible, reintentando en segundo plano: %v", err)
+		mb.setMeshHealth(false, err)
+		mb.scheduleReconnect()
+	} else {
+		mb.resubscribe()
+		log.Println("✅ Mesh bridge conectado")
 	}
 
 	// Iniciar goroutine para leer mensajes
@@ -109,11 +175,199 @@ func (mb *MeshBridge) Start() error {
 	// Iniciar heartbeat (ping/pong)
 	go mb.heartbeat()
 
-	log.Println("✅ Mesh bridge iniciado y conectado")
-	mb.running = true
+	// Iniciar query handler (limpieza de queries vencidas)
+	if mb.queryHandler != nil {
+		mb.queryHandler.Start()
+	}
+
+	log.Println("✅ Mesh bridge iniciado")
+	return nil
+}
+
+// SetHealthChecker configura el health checker donde se refleja el estado de la conexión
+func (mb *MeshBridge) SetHealthChecker(healthChecker *health.HealthChecker) {
+	mb.healthChecker = healthChecker
+	mb.setMeshHealth(mb.IsConnected(), nil)
+}
+
+// SetAuth configura la firma de mensajes salientes y la verificación de los entrantes
+func (mb *MeshBridge) SetAuth(auth *MeshAuth) {
+	mb.auth = auth
+}
+
+// QueryHandler retorna el handler de queries por mesh (nil si no hay storage)
+func (mb *MeshBridge) QueryHandler() *QueryHandler {
+	return mb.queryHandler
+}
+
+// SetChainFollower configura el seguidor de la cadena que recibe los headers anunciados por la mesh
+func (mb *MeshBridge) SetChainFollower(follower *ChainFollower) {
+	mb.follower = follower
+}
+
+// SetTxRelay configura el relay que recibe las transacciones enviadas por clientes de la mesh
+func (mb *MeshBridge) SetTxRelay(relay *TxRelay) {
+	mb.txRelay = relay
+}
+
+// SetPeerTable configura la tabla que recibe los descriptores de nodo anunciados por la mesh
+func (mb *MeshBridge) SetPeerTable(table *PeerTable) {
+	mb.peerTable = table
+}
+
+// SetCodecPreferences configura la codificación y compresión preferidas que se negocian con el SDK
+// EncodingJSON deshabilita la negociación y mantiene frames de texto JSON
+func (mb *MeshBridge) SetCodecPreferences(encoding, compression string) error {
+	if !supportedCodec(meshCodec{Encoding: encoding, Compression: compression}) {
+		return fmt.Errorf("codificación no soportada: %s/%s", encoding, compression)
+	}
+
+	mb.preferredEncoding = encoding
+	mb.preferredCompression = compression
+	return nil
+}
+
+// TrafficStats retorna los bytes enviados y recibidos por la conexión mesh
+func (mb *MeshBridge) TrafficStats() (sent, received uint64) {
+	return atomic.LoadUint64(&mb.bytesSent), atomic.LoadUint64(&mb.bytesReceived)
+}
+
+// currentCodec retorna la codificación negociada para la conexión actual
+func (mb *MeshBridge) currentCodec() meshCodec {
+	mb.codecMutex.RLock()
+	defer mb.codecMutex.RUnlock()
+	return mb.codec
+}
+
+// setCodec cambia la codificación de la conexión actual
+func (mb *MeshBridge) setCodec(codec meshCodec) {
+	mb.codecMutex.Lock()
+	mb.codec = codec
+	mb.codecMutex.Unlock()
+}
+
+// sendHello anuncia al SDK las codificaciones soportadas en orden de preferencia
+// Un SDK que no soporte la negociación ignora el mensaje y la conexión sigue en JSON
+func (mb *MeshBridge) sendHello(conn *websocket.Conn) error {
+	hello := meshHello{
+		Encodings:   []string{mb.preferredEncoding, EncodingJSON},
+		Compression: []string{mb.preferredCompression, CompressionNone},
+	}
+	data, err := json.Marshal(hello)
+	if err != nil {
+		return fmt.Errorf("error codificando hello: %w", err)
+	}
+	return mb.writeMessage(conn, &MeshMessage{Type: MessageTypeHello, Data: data})
+}
+
+// applyHelloAck adopta la codificación elegida por el SDK
+func (mb *MeshBridge) applyHelloAck(data []byte) error {
+	var codec meshCodec
+	if err := json.Unmarshal(data, &codec); err != nil {
+		return fmt.Errorf("hello_ack inválido: %w", err)
+	}
+	if codec.Compression == "" {
+		codec.Compression = CompressionNone
+	}
+	if !supportedCodec(codec) {
+		return fmt.Errorf("codificación no soportada en hello_ack: %s/%s", codec.Encoding, codec.Compression)
+	}
+
+	mb.setCodec(codec)
+	log.Printf("Codificación mesh negociada: %s/%s", codec.Encoding, codec.Compression)
+	return nil
+}
+
+// decodeMessage decodifica un frame recibido: texto JSON o binario protobuf
+func (mb *MeshBridge) decodeMessage(messageType int, frame []byte) (*MeshMessage, error) {
+	atomic.AddUint64(&mb.bytesReceived, uint64(len(frame)))
+
+	if messageType == websocket.BinaryMessage {
+		return decodeFrame(frame)
+	}
+
+	var msg MeshMessage
+	if err := json.Unmarshal(frame, &msg); err != nil {
+		return nil, fmt.Errorf("error decodificando JSON: %w", err)
+	}
+	return &msg, nil
+}
+
+// SetReconnectBackoff configura el backoff inicial y máximo entre intentos de reconexión
+func (mb *MeshBridge) SetReconnectBackoff(initial, max time.Duration) {
+	if initial > 0 {
+		mb.initialBackoff = initial
+	}
+	if max > 0 {
+		mb.maxBackoff = max
+	}
+	if mb.maxBackoff < mb.initialBackoff {
+		mb.maxBackoff = mb.initialBackoff
+	}
+}
+
+// SetOutboxLimit configura cuántos mensajes salientes se guardan mientras no hay conexión (0 deshabilita el buffer)
+func (mb *MeshBridge) SetOutboxLimit(limit int) {
+	mb.outboxMutex.Lock()
+	defer mb.outboxMutex.Unlock()
+
+	if limit < 0 {
+		limit = 0
+	}
+	mb.outboxLimit = limit
+	if len(mb.outbox) > limit {
+		dropped := len(mb.outbox) - limit
+		mb.outbox = mb.outbox[dropped:]
+		mb.outboxDropped += uint64(dropped)
+	}
+}
+
+// SetDurableOutbox persiste en store los mensajes relevantes para el consenso que no se pudieron enviar
+// (ver outboxPriorities), hasta ttl y con el mismo límite que el buffer, y carga los que quedaron pendientes
+// antes de un reinicio. Se envían al reconectar, antes que el resto del buffer
+func (mb *MeshBridge) SetDurableOutbox(store OutboxStore, ttl time.Duration) error {
+	mb.outboxMutex.Lock()
+	limit := mb.outboxLimit
+	mb.outboxMutex.Unlock()
+
+	durable, err := newDurableOutbox(store, ttl, limit)
+	if err != nil {
+		return err
+	}
+	mb.durable = durable
+	if pending := durable.len(); pending > 0 {
+		log.Printf("📦 %d mensajes mesh pendientes recuperados del storage", pending)
+	}
 	return nil
 }
 
+// IsConnected indica si hay una conexión activa con el mesh
+func (mb *MeshBridge) IsConnected() bool {
+	mb.connMutex.RLock()
+	defer mb.connMutex.RUnlock()
+	return mb.conn != nil
+}
+
+// BufferedMessages retorna la cantidad de mensajes pendientes de envío
+func (mb *MeshBridge) BufferedMessages() int {
+	mb.outboxMutex.Lock()
+	buffered := len(mb.outbox)
+	mb.outboxMutex.Unlock()
+
+	if mb.durable != nil {
+		buffered += mb.durable.len()
+	}
+	return buffered
+}
+
+// setMeshHealth refleja el estado de la conexión en el health checker (si está configurado)
+// cause es el error que cortó o impidió la conexión (nil si no se conoce)
+func (mb *MeshBridge) setMeshHealth(connected bool, cause error) {
+	if mb.healthChecker != nil {
+		mb.healthChecker.SetMeshStatus(connected, cause)
+	}
+}
+
 // connect establece conexión WebSocket con mesh endpoint
 func (mb *MeshBridge) connect() error {
 	mb.connMutex.Lock()
@@ -142,6 +396,15 @@ func (mb *MeshBridge) connect() error {
 
 	mb.conn = conn
 	log.Printf("Conectado a mesh endpoint: %s", u.String())
+	mb.setMeshHealth(true, nil)
+
+	// Cada conexión arranca en JSON hasta negociar una codificación binaria
+	mb.setCodec(defaultCodec)
+	if mb.preferredEncoding != EncodingJSON {
+		if err := mb.sendHello(conn); err != nil {
+			log.Printf("Advertencia: error enviando hello a mesh: %v", err)
+		}
+	}
 	
 	return nil
 }
@@ -152,39 +415,59 @@ func (mb *MeshBridge) subscribe(topic string) error {
 	conn := mb.conn
 	mb.connMutex.RUnlock()
 
+	// Marcar topic como suscrito (se re-suscribe al reconectar aunque ahora falle)
+	mb.topicsMutex.Lock()
+	mb.topics[topic] = true
+	mb.topicsMutex.Unlock()
+
 	if conn == nil {
 		return fmt.Errorf("no hay conexión establecida")
 	}
 
 	// Enviar mensaje de suscripción
-	msg := MeshMessage{
+	msg := &MeshMessage{
 		Type:  MessageTypeSubscribe,
 		Topic: topic,
 	}
 
-	if err := conn.WriteJSON(msg); err != nil {
+	if err := mb.writeMessage(conn, msg); err != nil {
 		return fmt.Errorf("error enviando suscripción: %w", err)
 	}
 
-	// Marcar topic como suscrito
-	mb.topicsMutex.Lock()
-	mb.topics[topic] = true
-	mb.topicsMutex.Unlock()
-
 	log.Printf("Suscrito a topic: %s", topic)
 	return nil
 }
 
+// resubscribe envía la suscripción de todos los topics registrados
+func (mb *MeshBridge) resubscribe() {
+	mb.topicsMutex.RLock()
+	topics := make([]string, 0, len(mb.topics))
+	for topic := range mb.topics {
+		topics = append(topics, topic)
+	}
+	mb.topicsMutex.RUnlock()
+
+	for _, topic := range topics {
+		if err := mb.subscribe(topic); err != nil {
+			log.Printf("Advertencia: error suscribiéndose a topic %s: %v", topic, err)
+		}
+	}
+}
+
 // readMessages lee mensajes del WebSocket
 func (mb *MeshBridge) readMessages() {
 	defer func() {
 		// Recover de panics de WebSocket para evitar que terminen el proceso
 		if r := recover(); r != nil {
 			log.Printf("⚠️ Panic en readMessages recuperado: %v", r)
-			// Cerrar conexión y reconectar
-			mb.closeConnection()
-			time.Sleep(2 * time.Second)
-			mb.reconnect()
+			// Cerrar conexión, reconectar en segundo plano y reanudar la lectura
+			mb.connMutex.RLock()
+			conn := mb.conn
+			mb.connMutex.RUnlock()
+			mb.handleDisconnect(conn, fmt.Errorf("panic leyendo mensajes: %v", r))
+			if mb.running {
+				go mb.readMessages()
+			}
 		}
 	}()
 
@@ -200,10 +483,16 @@ func (mb *MeshBridge) readMessages() {
 			mb.connMutex.RUnlock()
 
 			if conn == nil {
-				time.Sleep(2 * time.Second)
-				// Intentar reconectar si no hay conexión
+				// Esperar a que el ciclo de reconexión restablezca la conexión
 				if mb.running {
-					mb.reconnect()
+					mb.scheduleReconnect()
+				}
+				select {
+				case <-mb.stopChan:
+					return
+				case <-mb.ctx.Done():
+					return
+				case <-time.After(500 * time.Millisecond):
 				}
 				continue
 			}
@@ -213,14 +502,12 @@ func (mb *MeshBridge) readMessages() {
 			readDeadline := time.Now().Add(120 * time.Second)
 			if err := conn.SetReadDeadline(readDeadline); err != nil {
 				log.Printf("⚠️ Error configurando read deadline: %v", err)
-				mb.closeConnection()
-				time.Sleep(2 * time.Second)
-				mb.reconnect()
+				mb.handleDisconnect(conn, err)
 				continue
 			}
 			
-			var msg MeshMessage
-			if err := conn.ReadJSON(&msg); err != nil {
+			messageType, frame, err := conn.ReadMessage()
+			if err != nil {
 				// Verificar si es un error de cierre esperado
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 					log.Printf("⚠️ Error leyendo mensaje WebSocket: %v", err)
@@ -230,19 +517,20 @@ func (mb *MeshBridge) readMessages() {
 					log.Printf("⚠️ Error WebSocket: %v", err)
 				}
 				
-				// Cerrar conexión actual y reconectar
-				mb.closeConnection()
-				time.Sleep(2 * time.Second)
-				
-				// Intentar reconectar solo si aún está corriendo
-				if mb.running {
-					mb.reconnect()
-				}
+				// Cerrar conexión actual y reconectar en segundo plano
+				mb.handleDisconnect(conn, err)
+				continue
+			}
+
+			// Decodificar según el tipo de frame (texto JSON o binario protobuf)
+			msg, err := mb.decodeMessage(messageType, frame)
+			if err != nil {
+				log.Printf("⚠️ Error decodificando mensaje: %v", err)
 				continue
 			}
 
 			// Procesar mensaje
-			if err := mb.handleMessage(&msg); err != nil {
+			if err := mb.handleMessage(msg); err != nil {
 				log.Printf("⚠️ Error procesando mensaje: %v", err)
 			}
 		}
@@ -257,33 +545,65 @@ func (mb *MeshBridge) handleMessage(msg *MeshMessage) error {
 		return mb.sendMessage(&MeshMessage{
 			Type: MessageTypePong,
 		})
+
+	case MessageTypePong:
+		// Respuesta al heartbeat: round-trip de la conexión con el mesh
+		if sentAt := atomic.SwapInt64(&mb.pingSentAt, 0); sentAt != 0 && mb.healthChecker != nil {
+			mb.healthChecker.SetMeshRTT(time.Since(time.Unix(0, sentAt)))
+		}
+		return nil
 	
 	case MessageTypePublish:
 		// Procesar mensaje publicado en un topic
-		return mb.ReceiveMessage(msg.Topic, msg.Data)
+		if err := mb.authenticate(msg); err != nil {
+			return err
+		}
+		err := mb.receiveMessage(msg.Topic, msg.Data, msg.verifiedSender)
+		if errors.Is(err, ErrInvalidPayload) {
+			mb.reportInvalid(msg, err)
+		}
+		return err
 	
 	case "query":
 		// Procesar query recibida (formato directo)
+		if err := mb.authenticate(msg); err != nil {
+			return err
+		}
 		if mb.queryHandler != nil {
-			var queryReq QueryRequest
-			if err := json.Unmarshal(msg.Data, &queryReq); err == nil {
-				if err := mb.queryHandler.HandleQuery(queryReq); err != nil {
-					log.Printf("Error manejando query: %v", err)
-				}
+			queryReq, err := decodeQueryRequest(msg.Data)
+			if err != nil {
+				err = fmt.Errorf("%w: query: %v", ErrInvalidPayload, err)
+				mb.reportInvalid(msg, err)
+				return err
+			}
+			if err := mb.queryHandler.HandleQuery(queryReq); err != nil {
+				log.Printf("Error manejando query: %v", err)
 			}
 		}
 		return nil
 	
 	case "response":
 		// Procesar respuesta recibida (formato directo)
+		if err := mb.authenticate(msg); err != nil {
+			return err
+		}
 		if mb.queryHandler != nil {
-			var queryResp QueryResponse
-			if err := json.Unmarshal(msg.Data, &queryResp); err == nil {
-				mb.queryHandler.HandleResponse(queryResp)
+			queryResp, err := decodeQueryResponse(msg.Data)
+			if err != nil {
+				err = fmt.Errorf("%w: response: %v", ErrInvalidPayload, err)
+				mb.reportInvalid(msg, err)
+				return err
 			}
+			// El remitente declarado en el payload no se usa: solo cuenta la identidad verificada
+			queryResp.From = msg.verifiedSender
+			mb.queryHandler.HandleResponse(queryResp)
 		}
 		return nil
 	
+	case MessageTypeHelloAck:
+		// El SDK eligió la codificación para esta conexión
+		return mb.applyHelloAck(msg.Data)
+
 	case "error":
 		// Mensajes de error del servidor mesh (pueden ser errores de conexión, autenticación, etc.)
 		// Ignorar silenciosamente - ya se manejan los errores en readMessages()
@@ -299,6 +619,78 @@ func (mb *MeshBridge) handleMessage(msg *MeshMessage) error {
 	return nil
 }
 
+// authenticate descarta mensajes de peers baneados y verifica firma e identidad del remitente
+// Antes de verificar solo se penaliza la identidad de transporte (msg.From): el remitente declarado
+// puede ser falso. El exceso de mensajes se cuenta sobre la identidad verificada
+func (mb *MeshBridge) authenticate(msg *MeshMessage) error {
+	if mb.auth == nil {
+		return nil
+	}
+
+	transport := msg.From
+	if transport != "" && mb.auth.IsBanned(transport) {
+		return fmt.Errorf("mensaje descartado: peer %s baneado", transport)
+	}
+
+	signer, err := mb.auth.Verify(msg)
+	if err != nil {
+		if errors.Is(err, ErrReplayedMessage) {
+			// Un replay no aporta nada al atacante: se descarta sin penalizar al relay
+			return fmt.Errorf("mensaje de %s descartado: %w", transport, err)
+		}
+		if transport != "" {
+			mb.auth.ReportInvalid(transport, err)
+		}
+		return fmt.Errorf("mensaje de %s descartado: %w", transport, err)
+	}
+
+	if msg.Signature != "" {
+		msg.verifiedSender = strings.ToLower(signer.Hex())
+	}
+
+	peer := peerID(msg)
+	if peer == "" {
+		return nil
+	}
+	if peer != transport && mb.auth.IsBanned(peer) {
+		return fmt.Errorf("mensaje descartado: peer %s baneado", peer)
+	}
+
+	// Limitar la tasa por peer una vez verificada su identidad
+	if !mb.auth.Scorer().AllowMessage(peer) {
+		return fmt.Errorf("mensaje descartado: peer %s excede la tasa de mensajes", peer)
+	}
+
+	// Un remitente verificado es candidato para queries dirigidas
+	if mb.queryHandler != nil && msg.verifiedSender != "" {
+		mb.queryHandler.ObservePeer(msg.verifiedSender)
+	}
+
+	return nil
+}
+
+// reportInvalid registra un payload inválido del peer que envió el mensaje
+func (mb *MeshBridge) reportInvalid(msg *MeshMessage, reason error) {
+	if mb.auth != nil {
+		mb.auth.ReportInvalid(peerID(msg), reason)
+	}
+}
+
+// signOutbound firma los mensajes de consenso y queries antes de enviarlos
+// Los mensajes de control (ping/pong/suscripción) no se firman
+func (mb *MeshBridge) signOutbound(msg *MeshMessage) error {
+	if mb.auth == nil {
+		return nil
+	}
+
+	switch msg.Type {
+	case MessageTypePing, MessageTypePong, MessageTypeSubscribe, MessageTypeUnsubscribe:
+		return nil
+	}
+
+	return mb.auth.Sign(msg)
+}
+
 // closeConnection cierra la conexión WebSocket de forma segura
 func (mb *MeshBridge) closeConnection() {
 	mb.connMutex.Lock()
@@ -316,38 +708,104 @@ func (mb *MeshBridge) closeConnection() {
 					// Ignorar panics al cerrar (puede estar ya cerrado)
 				}
 			}()
-			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+			func() {
+				mb.writeMutex.Lock()
+				defer mb.writeMutex.Unlock()
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+			}()
 			conn.Close()
 		}()
 	}
 }
 
-// reconnect intenta reconectar al mesh
+// handleDisconnect cierra la conexión caída, actualiza el health con la causa y agenda la reconexión
+// Si la conexión ya fue reemplazada por una nueva no hace nada
+func (mb *MeshBridge) handleDisconnect(conn *websocket.Conn, cause error) {
+	mb.connMutex.RLock()
+	current := mb.conn
+	mb.connMutex.RUnlock()
+
+	if current != conn {
+		return
+	}
+
+	mb.closeConnection()
+	mb.setMeshHealth(false, cause)
+
+	if mb.running {
+		mb.scheduleReconnect()
+	}
+}
+
+// scheduleReconnect inicia un ciclo de reconexión en segundo plano si no hay uno en curso
+func (mb *MeshBridge) scheduleReconnect() {
+	if !atomic.CompareAndSwapInt32(&mb.reconnecting, 0, 1) {
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&mb.reconnecting, 0)
+		mb.reconnect()
+	}()
+}
+
+// reconnect intenta reconectar al mesh con backoff exponencial hasta lograrlo
+// o hasta que el bridge se detenga. Al reconectar re-suscribe los topics y
+// envía los mensajes acumulados mientras no había conexión
 func (mb *MeshBridge) reconnect() {
 	log.Println("Intentando reconectar a mesh...")
-	
-	for i := 0; i < 5; i++ {
-		if err := mb.connect(); err == nil {
-			// Re-suscribirse a todos los topics
-			mb.topicsMutex.RLock()
-			topics := make([]string, 0, len(mb.topics))
-			for topic := range mb.topics {
-				topics = append(topics, topic)
-			}
-			mb.topicsMutex.RUnlock()
-			
-			for _, topic := range topics {
-				mb.subscribe(topic)
-			}
-			
-			log.Println("✅ Reconectado a mesh exitosamente")
+
+	backoff := mb.initialBackoff
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-mb.stopChan:
 			return
+		case <-mb.ctx.Done():
+			return
+		default:
 		}
-		
-		time.Sleep(time.Duration(i+1) * 2 * time.Second)
+
+		err := mb.connect()
+		if err == nil {
+			mb.resubscribe()
+			mb.flushOutbox()
+			log.Printf("✅ Reconectado a mesh exitosamente (intento %d)", attempt)
+			return
+		}
+
+		wait := withJitter(backoff)
+		log.Printf("⚠️ Reconexión a mesh falló (intento %d), reintentando en %s: %v", attempt, wait.Round(time.Millisecond), err)
+		mb.setMeshHealth(false, err)
+
+		select {
+		case <-mb.stopChan:
+			return
+		case <-mb.ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		backoff = nextBackoff(backoff, mb.maxBackoff)
 	}
-	
-	log.Printf("⚠️ No se pudo reconectar a mesh después de 5 intentos")
+}
+
+// nextBackoff duplica el backoff actual sin superar el máximo
+func nextBackoff(current, max time.Duration) time.Duration {
+	next := current * 2
+	if next > max || next <= 0 {
+		return max
+	}
+	return next
+}
+
+// withJitter retorna un valor aleatorio entre d/2 y d para que varios nodos
+// no reconecten al mismo tiempo cuando el SDK se reinicia
+func withJitter(d time.Duration) time.Duration {
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
 }
 
 // heartbeat envía ping periódico para mantener la conexión viva
@@ -363,6 +821,7 @@ func (mb *MeshBridge) heartbeat() {
 		case <-mb.ctx.Done():
 			return
 		case <-ticker.C:
+			atomic.StoreInt64(&mb.pingSentAt, time.Now().UnixNano())
 			if err := mb.sendMessage(&MeshMessage{
 				Type: MessageTypePing,
 			}); err != nil {
@@ -379,11 +838,174 @@ func (mb *MeshBridge) sendMessage(msg *MeshMessage) error {
 	mb.connMutex.RUnlock()
 
 	if conn == nil {
-		return fmt.Errorf("no hay conexión establecida")
+		return mb.bufferMessage(msg)
+	}
+
+	// Se firma al momento de escribir para que el timestamp sea reciente
+	if err := mb.signOutbound(msg); err != nil {
+		return err
+	}
+
+	if err := mb.writeMessage(conn, msg); err != nil {
+		// La conexión se cayó: guardar el mensaje para reenviarlo al reconectar
+		log.Printf("⚠️ Error enviando mensaje a mesh: %v", err)
+		mb.handleDisconnect(conn, err)
+		return mb.bufferMessage(msg)
 	}
 
+	return nil
+}
+
+// writeMessage escribe un mensaje en la conexión serializando los escritores
+// Usa frames binarios protobuf si se negoció esa codificación, o texto JSON en caso contrario
+func (mb *MeshBridge) writeMessage(conn *websocket.Conn, msg *MeshMessage) error {
+	codec := mb.currentCodec()
+
+	messageType := websocket.TextMessage
+	var frame []byte
+	var err error
+	if codec.Encoding == EncodingProto && msg.Type != MessageTypeHello {
+		messageType = websocket.BinaryMessage
+		frame, err = encodeFrame(msg, codec.Compression)
+	} else {
+		frame, err = json.Marshal(msg)
+	}
+	if err != nil {
+		return fmt.Errorf("error codificando mensaje: %w", err)
+	}
+
+	mb.writeMutex.Lock()
+	defer mb.writeMutex.Unlock()
+
 	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-	return conn.WriteJSON(msg)
+	if err := conn.WriteMessage(messageType, frame); err != nil {
+		return err
+	}
+
+	atomic.AddUint64(&mb.bytesSent, uint64(len(frame)))
+	return nil
+}
+
+// bufferMessage guarda un mensaje saliente mientras no hay conexión
+// Solo se guardan publicaciones; ping/pong no tienen sentido fuera de la conexión actual
+// Los mensajes relevantes para el consenso van a la cola persistente si está configurada
+// Si el buffer está lleno se descarta el mensaje más antiguo
+func (mb *MeshBridge) bufferMessage(msg *MeshMessage) error {
+	if msg.Type != MessageTypePublish {
+		return fmt.Errorf("no hay conexión establecida")
+	}
+	if priority, ok := outboxPriorities[msg.Topic]; ok && mb.durable != nil {
+		return mb.durable.push(msg, priority)
+	}
+
+	mb.outboxMutex.Lock()
+	defer mb.outboxMutex.Unlock()
+
+	if mb.outboxLimit <= 0 {
+		return fmt.Errorf("no hay conexión establecida")
+	}
+
+	if len(mb.outbox) >= mb.outboxLimit {
+		mb.outbox = mb.outbox[1:]
+		mb.outboxDropped++
+		if mb.outboxDropped%100 == 1 {
+			log.Printf("⚠️ Buffer de mesh lleno (%d mensajes), descartando los más antiguos (%d descartados)", mb.outboxLimit, mb.outboxDropped)
+		}
+	}
+
+	mb.outbox = append(mb.outbox, msg)
+	return nil
+}
+
+// flushOutbox envía en orden los mensajes acumulados mientras no había conexión: primero los de la cola
+// persistente y después los del buffer. Si la conexión vuelve a caer, los mensajes no enviados quedan pendientes
+func (mb *MeshBridge) flushOutbox() {
+	if !mb.flushDurable() {
+		return
+	}
+
+	mb.outboxMutex.Lock()
+	pending := mb.outbox
+	mb.outbox = nil
+	mb.outboxMutex.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	mb.connMutex.RLock()
+	conn := mb.conn
+	mb.connMutex.RUnlock()
+
+	for i, msg := range pending {
+		if conn == nil {
+			mb.requeue(pending[i:])
+			return
+		}
+		if err := mb.signOutbound(msg); err != nil {
+			log.Printf("⚠️ Error firmando mensaje pendiente, descartado: %v", err)
+			continue
+		}
+		if err := mb.writeMessage(conn, msg); err != nil {
+			log.Printf("⚠️ Error reenviando mensajes pendientes a mesh: %v", err)
+			mb.requeue(pending[i:])
+			mb.handleDisconnect(conn, err)
+			return
+		}
+	}
+
+	log.Printf("📤 %d mensajes pendientes reenviados por mesh", len(pending))
+}
+
+// flushDurable envía los mensajes de la cola persistente por clase y en orden de llegada, y los borra a medida
+// que se envían. Retorna false si la conexión se cayó durante el envío
+func (mb *MeshBridge) flushDurable() bool {
+	if mb.durable == nil {
+		return true
+	}
+	pending := mb.durable.pending()
+	if len(pending) == 0 {
+		return true
+	}
+
+	mb.connMutex.RLock()
+	conn := mb.conn
+	mb.connMutex.RUnlock()
+	if conn == nil {
+		return false
+	}
+
+	sent := make([]uint64, 0, len(pending))
+	defer func() { mb.durable.remove(sent) }()
+	for _, entry := range pending {
+		if err := mb.signOutbound(entry.Message); err != nil {
+			log.Printf("⚠️ Error firmando mensaje pendiente, descartado: %v", err)
+			sent = append(sent, entry.Seq)
+			continue
+		}
+		if err := mb.writeMessage(conn, entry.Message); err != nil {
+			log.Printf("⚠️ Error reenviando mensajes pendientes a mesh: %v", err)
+			mb.handleDisconnect(conn, err)
+			return false
+		}
+		sent = append(sent, entry.Seq)
+	}
+
+	log.Printf("📤 %d mensajes pendientes de la cola persistente reenviados por mesh", len(sent))
+	return true
+}
+
+// requeue devuelve mensajes no enviados al inicio del buffer respetando el límite
+func (mb *MeshBridge) requeue(msgs []*MeshMessage) {
+	mb.outboxMutex.Lock()
+	defer mb.outboxMutex.Unlock()
+
+	mb.outbox = append(append([]*MeshMessage{}, msgs...), mb.outbox...)
+	if len(mb.outbox) > mb.outboxLimit {
+		dropped := len(mb.outbox) - mb.outboxLimit
+		mb.outbox = mb.outbox[dropped:]
+		mb.outboxDropped += uint64(dropped)
+	}
 }
 
 // Stop detiene el puente
@@ -398,12 +1020,15 @@ func (mb *MeshBridge) Stop() error {
 	// Desconectar WebSocket
 	mb.connMutex.Lock()
 	if mb.conn != nil {
+		mb.writeMutex.Lock()
 		mb.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		mb.writeMutex.Unlock()
 		mb.conn.Close()
 		mb.conn = nil
 	}
 	mb.connMutex.Unlock()
 
+	mb.setMeshHealth(false, errors.New("mesh bridge detenido"))
 	mb.running = false
 	log.Println("⏹️ Mesh bridge detenido")
 	return nil
@@ -463,13 +1088,90 @@ func (mb *MeshBridge) BroadcastBlock(block *consensus.Block) error {
 	return nil
 }
 
-// ReceiveMessage maneja mensajes recibidos de la mesh
+// AdvertiseNode anuncia por la mesh el descriptor del nodo (firmado como todo mensaje saliente)
+func (mb *MeshBridge) AdvertiseNode(descriptor *NodeDescriptor) error {
+	if !mb.running {
+		return fmt.Errorf("mesh bridge no está corriendo")
+	}
+
+	data, err := json.Marshal(descriptor)
+	if err != nil {
+		return fmt.Errorf("error codificando descriptor de nodo: %w", err)
+	}
+	return mb.sendMessage(&MeshMessage{
+		Type:  MessageTypePublish,
+		Topic: TopicNodes,
+		Data:  data,
+	})
+}
+
+// AnnounceBlock anuncia por la mesh el header de un bloque confirmado y sus transacciones
+// Es más liviano que BroadcastBlock y alcanza para que nodos sin CometBFT sigan la cadena
+func (mb *MeshBridge) AnnounceBlock(block *consensus.Block) error {
+	if !mb.running {
+		return fmt.Errorf("mesh bridge no está corriendo")
+	}
+
+	headerData, err := json.Marshal(NewBlockHeaderAnnouncement(block))
+	if err != nil {
+		return fmt.Errorf("error codificando header: %w", err)
+	}
+	if err := mb.sendMessage(&MeshMessage{
+		Type:  MessageTypePublish,
+		Topic: TopicBlockHeaders,
+		Data:  headerData,
+	}); err != nil {
+		return fmt.Errorf("error anunciando header: %w", err)
+	}
+
+	if len(block.Transactions) > 0 {
+		txData, err := json.Marshal(NewTxAnnouncementBatch(block))
+		if err != nil {
+			return fmt.Errorf("error codificando anuncio de transacciones: %w", err)
+		}
+		if err := mb.sendMessage(&MeshMessage{
+			Type:  MessageTypePublish,
+			Topic: TopicTxAnnouncements,
+			Data:  txData,
+		}); err != nil {
+			return fmt.Errorf("error anunciando transacciones: %w", err)
+		}
+	}
+
+	log.Printf("📤 Bloque anunciado por mesh: altura %d (%d transacciones)", block.Header.Height, len(block.Transactions))
+	return nil
+}
+
+// PublishTxRelayAck responde por la mesh a un cliente del relay de transacciones
+func (mb *MeshBridge) PublishTxRelayAck(ack *TxRelayAck) error {
+	if !mb.running {
+		return fmt.Errorf("mesh bridge no está corriendo")
+	}
+
+	data, err := json.Marshal(ack)
+	if err != nil {
+		return fmt.Errorf("error codificando ack del relay: %w", err)
+	}
+	return mb.sendMessage(&MeshMessage{
+		Type:  MessageTypePublish,
+		Topic: TopicTxRelayAck,
+		Data:  data,
+	})
+}
+
+// ReceiveMessage maneja mensajes recibidos de la mesh sin remitente verificado
+// Los anuncios de la cadena sin firma de un validador se rechazan
 func (mb *MeshBridge) ReceiveMessage(topic string, data []byte) error {
+	return mb.receiveMessage(topic, data, "")
+}
+
+// receiveMessage maneja mensajes recibidos de la mesh con la identidad verificada del firmante
+func (mb *MeshBridge) receiveMessage(topic string, data []byte, signer string) error {
 	switch topic {
 	case TopicTransactions:
 		var tx consensus.Transaction
 		if err := json.Unmarshal(data, &tx); err != nil {
-			return fmt.Errorf("error decodificando transacción: %w", err)
+			return fmt.Errorf("%w: error decodificando transacción: %v", ErrInvalidPayload, err)
 		}
 		
 		// Enviar transacción a CometBFT para validación
@@ -483,7 +1185,7 @@ func (mb *MeshBridge) ReceiveMessage(topic string, data []byte) error {
 	case TopicBlocks:
 		var block consensus.Block
 		if err := json.Unmarshal(data, &block); err != nil {
-			return fmt.Errorf("error decodificando bloque: %w", err)
+			return fmt.Errorf("%w: error decodificando bloque: %v", ErrInvalidPayload, err)
 		}
 		
 		// Procesar bloque recibido
@@ -502,6 +1204,50 @@ func (mb *MeshBridge) ReceiveMessage(topic string, data []byte) error {
 			log.Printf("📥 Bloque recibido de mesh: altura %d", block.Header.Height)
 		}
 		
+	case TopicBlockHeaders:
+		var header BlockHeaderAnnouncement
+		if err := json.Unmarshal(data, &header); err != nil {
+			return fmt.Errorf("%w: error decodificando header: %v", ErrInvalidPayload, err)
+		}
+		if mb.follower != nil {
+			if err := mb.follower.HandleHeader(&header, signer); err != nil {
+				return fmt.Errorf("header rechazado: %w", err)
+			}
+		}
+
+	case TopicTxAnnouncements:
+		var batch TxAnnouncementBatch
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return fmt.Errorf("%w: error decodificando anuncio de transacciones: %v", ErrInvalidPayload, err)
+		}
+		if mb.follower != nil {
+			if err := mb.follower.HandleTxAnnouncements(&batch, signer); err != nil {
+				return fmt.Errorf("anuncio de transacciones rechazado: %w", err)
+			}
+		}
+
+	case TopicTxRelay:
+		var req TxRelayRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return fmt.Errorf("%w: error decodificando transacción del relay: %v", ErrInvalidPayload, err)
+		}
+		if mb.txRelay != nil {
+			if err := mb.txRelay.HandleRequest(&req, signer); err != nil {
+				return fmt.Errorf("transacción del relay no procesada: %w", err)
+			}
+		}
+
+	case TopicNodes:
+		var descriptor NodeDescriptor
+		if err := json.Unmarshal(data, &descriptor); err != nil {
+			return fmt.Errorf("%w: error decodificando descriptor de nodo: %v", ErrInvalidPayload, err)
+		}
+		if mb.peerTable != nil {
+			if err := mb.peerTable.HandleDescriptor(&descriptor, signer); err != nil {
+				return fmt.Errorf("descriptor de nodo rechazado: %w", err)
+			}
+		}
+
 	case TopicValidators:
 		// Procesar actualizaciones de validadores
 		// Por ahora, solo loguear. La gestión de validadores se hace internamente
@@ -512,11 +1258,12 @@ func (mb *MeshBridge) ReceiveMessage(topic string, data []byte) error {
 	case "oxy-blockchain:query":
 		// Procesar query recibida
 		if mb.queryHandler != nil {
-			var queryReq QueryRequest
-			if err := json.Unmarshal(data, &queryReq); err == nil {
-				if err := mb.queryHandler.HandleQuery(queryReq); err != nil {
-					log.Printf("Error manejando query: %v", err)
-				}
+			queryReq, err := decodeQueryRequest(data)
+			if err != nil {
+				return fmt.Errorf("%w: query: %v", ErrInvalidPayload, err)
+			}
+			if err := mb.queryHandler.HandleQuery(queryReq); err != nil {
+				log.Printf("Error manejando query: %v", err)
 			}
 		}
 		return nil
@@ -524,10 +1271,12 @@ func (mb *MeshBridge) ReceiveMessage(topic string, data []byte) error {
 	case "oxy-blockchain:response":
 		// Procesar respuesta recibida
 		if mb.queryHandler != nil {
-			var queryResp QueryResponse
-			if err := json.Unmarshal(data, &queryResp); err == nil {
-				mb.queryHandler.HandleResponse(queryResp)
+			queryResp, err := decodeQueryResponse(data)
+			if err != nil {
+				return fmt.Errorf("%w: response: %v", ErrInvalidPayload, err)
 			}
+			queryResp.From = signer
+			mb.queryHandler.HandleResponse(queryResp)
 		}
 		return nil
 		
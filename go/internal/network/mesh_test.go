@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
 )
@@ -68,7 +69,9 @@ func TestMeshBridgeStartStop(t *testing.T) {
 
 // TestMeshBridgeReconnect verifica la lógica de reconexión
 func TestMeshBridgeReconnect(t *testing.T) {
-	ctx := context.Background()
+	// La reconexión reintenta indefinidamente; el contexto la corta
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
 
 	// Crear storage temporal
 	testDir := "./test_data_mesh_reconnect_" + t.Name()
@@ -82,6 +85,7 @@ func TestMeshBridgeReconnect(t *testing.T) {
 	}()
 
 	meshBridge := NewMeshBridge(ctx, nil, "ws://localhost:3001", db)
+	meshBridge.SetReconnectBackoff(10*time.Millisecond, 50*time.Millisecond)
 
 	// Simular reconexión (sin servidor real, solo verificar lógica)
 	meshBridge.running = true
@@ -91,5 +95,66 @@ func TestMeshBridgeReconnect(t *testing.T) {
 	// Como no hay servidor, fallará pero no debería crashear
 	meshBridge.reconnect()
 
-	// Si llegamos aquí, la función no crasheó
+	// Si llegamos aquí, la función no crasheó y respetó la cancelación del contexto
+	if meshBridge.IsConnected() {
+		t.Error("No debería haber conexión sin servidor")
+	}
+}
+
+// TestNextBackoff verifica que el backoff se duplica sin superar el máximo
+func TestNextBackoff(t *testing.T) {
+	max := 8 * time.Second
+	backoff := time.Second
+
+	expected := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second}
+	for i, want := range expected {
+		backoff = nextBackoff(backoff, max)
+		if backoff != want {
+			t.Errorf("paso %d: esperado %s, obtenido %s", i, want, backoff)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		wait := withJitter(4 * time.Second)
+		if wait < 2*time.Second || wait > 4*time.Second {
+			t.Fatalf("jitter fuera de rango: %s", wait)
+		}
+	}
+}
+
+// TestMeshBridgeOutbox verifica el buffer de mensajes salientes sin conexión
+func TestMeshBridgeOutbox(t *testing.T) {
+	meshBridge := NewMeshBridge(context.Background(), nil, "ws://localhost:3001", nil)
+	meshBridge.SetOutboxLimit(3)
+
+	// Las publicaciones se guardan mientras no hay conexión
+	for _, topic := range []string{"a", "b", "c", "d"} {
+		msg := &MeshMessage{Type: MessageTypePublish, Topic: topic}
+		if err := meshBridge.sendMessage(msg); err != nil {
+			t.Fatalf("sendMessage no debería fallar sin conexión: %v", err)
+		}
+	}
+
+	if meshBridge.BufferedMessages() != 3 {
+		t.Fatalf("Esperado 3 mensajes en buffer, obtenido %d", meshBridge.BufferedMessages())
+	}
+
+	// Al llenarse se descarta el más antiguo
+	if meshBridge.outbox[0].Topic != "b" || meshBridge.outbox[2].Topic != "d" {
+		t.Errorf("Orden inesperado del buffer: %s..%s", meshBridge.outbox[0].Topic, meshBridge.outbox[2].Topic)
+	}
+
+	// Ping no se guarda
+	if err := meshBridge.sendMessage(&MeshMessage{Type: MessageTypePing}); err == nil {
+		t.Error("Ping sin conexión debería fallar")
+	}
+
+	// Sin buffer los mensajes fallan
+	meshBridge.SetOutboxLimit(0)
+	if meshBridge.BufferedMessages() != 0 {
+		t.Error("Reducir el límite debería descartar mensajes")
+	}
+	if err := meshBridge.sendMessage(&MeshMessage{Type: MessageTypePublish, Topic: "e"}); err == nil {
+		t.Error("Sin buffer el envío sin conexión debería fallar")
+	}
 }
@@ -0,0 +1,233 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OutboxPriority es la clase de un mensaje en la cola persistente: al reconectar se envían primero las
+// clases más prioritarias y, si la cola se llena, se descartan primero los mensajes de las menos prioritarias
+type OutboxPriority int
+
+// Clases de la cola persistente (de mayor a menor prioridad)
+const (
+	OutboxPriorityConsensus    OutboxPriority = iota // Bloques, headers, anuncios y validadores
+	OutboxPriorityTransactions                       // Transacciones y acks del relay
+)
+
+// DefaultOutboxTTL es el tiempo que un mensaje puede esperar en la cola persistente antes de descartarse
+const DefaultOutboxTTL = 10 * time.Minute
+
+// outboxPriorities asigna una clase a los topics que se conservan entre desconexiones (y reinicios)
+// Las queries, las respuestas y los descriptores de nodo vencen o se reenvían solos: quedan en el buffer en memoria
+var outboxPriorities = map[string]OutboxPriority{
+	TopicBlocks:          OutboxPriorityConsensus,
+	TopicBlockHeaders:    OutboxPriorityConsensus,
+	TopicTxAnnouncements: OutboxPriorityConsensus,
+	TopicValidators:      OutboxPriorityConsensus,
+	TopicTransactions:    OutboxPriorityTransactions,
+	TopicTxRelayAck:      OutboxPriorityTransactions,
+}
+
+// OutboxStore persiste la cola de mensajes salientes entre reinicios del nodo
+type OutboxStore interface {
+	SaveOutboxMessage(seq uint64, data []byte) error
+	DeleteOutboxMessages(seqs []uint64) error
+	ListOutboxMessages(fn func(seq uint64, data []byte) error) error
+}
+
+// outboxEntry es un mensaje en la cola persistente
+type outboxEntry struct {
+	Seq      uint64         `json:"seq"`
+	Priority OutboxPriority `json:"priority"`
+	Queued   time.Time      `json:"queued"`
+	Message  *MeshMessage   `json:"message"`
+}
+
+// durableOutbox guarda los mensajes relevantes para el consenso mientras no hay conexión con la mesh,
+// en orden de llegada dentro de cada clase. Cada mensaje se persiste al encolarlo y se borra al enviarlo,
+// así un validador que pierde la mesh (o se reinicia) envía lo pendiente al reconectar
+type durableOutbox struct {
+	store   OutboxStore
+	ttl     time.Duration
+	limit   int
+	queues  map[OutboxPriority][]*outboxEntry
+	nextSeq uint64
+	dropped uint64
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// newDurableOutbox crea la cola y carga los mensajes que quedaron pendientes (ttl 0 usa DefaultOutboxTTL)
+func newDurableOutbox(store OutboxStore, ttl time.Duration, limit int) (*durableOutbox, error) {
+	if ttl <= 0 {
+		ttl = DefaultOutboxTTL
+	}
+	o := &durableOutbox{
+		store:   store,
+		ttl:     ttl,
+		limit:   limit,
+		queues:  make(map[OutboxPriority][]*outboxEntry),
+		nextSeq: 1,
+		now:     time.Now,
+	}
+
+	var invalid []uint64
+	err := store.ListOutboxMessages(func(seq uint64, data []byte) error {
+		if seq >= o.nextSeq {
+			o.nextSeq = seq + 1
+		}
+		var entry outboxEntry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Message == nil {
+			invalid = append(invalid, seq)
+			return nil
+		}
+		entry.Seq = seq
+		o.queues[entry.Priority] = append(o.queues[entry.Priority], &entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error cargando mensajes mesh pendientes: %w", err)
+	}
+	if err := store.DeleteOutboxMessages(invalid); err != nil {
+		log.Printf("⚠️ Error descartando mensajes mesh pendientes inválidos: %v", err)
+	}
+	return o, nil
+}
+
+// push encola un mensaje y lo persiste. Con la cola llena se descarta el mensaje más antiguo de la clase
+// menos prioritaria con mensajes (un mensaje no desplaza a los de clases más prioritarias que la suya)
+func (o *durableOutbox) push(msg *MeshMessage, priority OutboxPriority) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.limit <= 0 {
+		return fmt.Errorf("no hay conexión establecida")
+	}
+	if o.size() >= o.limit {
+		victim, ok := o.lowestPriority()
+		if !ok || victim < priority {
+			o.dropped++
+			return fmt.Errorf("cola de mensajes mesh llena (%d mensajes)", o.limit)
+		}
+		oldest := o.queues[victim][0]
+		o.queues[victim] = o.queues[victim][1:]
+		o.delete([]uint64{oldest.Seq})
+		o.dropped++
+		if o.dropped%100 == 1 {
+			log.Printf("⚠️ Cola de mensajes mesh llena (%d mensajes), descartando los más antiguos (%d descartados)", o.limit, o.dropped)
+		}
+	}
+
+	entry := &outboxEntry{Seq: o.nextSeq, Priority: priority, Queued: o.now(), Message: msg}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error codificando mensaje pendiente: %w", err)
+	}
+	if err := o.store.SaveOutboxMessage(entry.Seq, data); err != nil {
+		return fmt.Errorf("error guardando mensaje pendiente: %w", err)
+	}
+	o.nextSeq++
+	o.queues[priority] = append(o.queues[priority], entry)
+	return nil
+}
+
+// pending retorna los mensajes vigentes en orden de envío: por clase y, dentro de cada clase, por llegada
+// Los vencidos se descartan. Los mensajes siguen en la cola hasta confirmarlos con remove
+func (o *durableOutbox) pending() []*outboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	var expired []uint64
+	var entries []*outboxEntry
+	for _, priority := range o.priorities() {
+		kept := o.queues[priority][:0]
+		for _, entry := range o.queues[priority] {
+			if now.Sub(entry.Queued) > o.ttl {
+				expired = append(expired, entry.Seq)
+				continue
+			}
+			kept = append(kept, entry)
+			entries = append(entries, entry)
+		}
+		o.queues[priority] = kept
+	}
+	if len(expired) > 0 {
+		o.delete(expired)
+		log.Printf("⚠️ %d mensajes mesh pendientes vencidos (más de %s sin conexión), descartados", len(expired), o.ttl)
+	}
+	return entries
+}
+
+// remove quita de la cola los mensajes enviados
+func (o *durableOutbox) remove(seqs []uint64) {
+	if len(seqs) == 0 {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	sent := make(map[uint64]bool, len(seqs))
+	for _, seq := range seqs {
+		sent[seq] = true
+	}
+	for priority, queue := range o.queues {
+		kept := queue[:0]
+		for _, entry := range queue {
+			if !sent[entry.Seq] {
+				kept = append(kept, entry)
+			}
+		}
+		o.queues[priority] = kept
+	}
+	o.delete(seqs)
+}
+
+// len retorna la cantidad de mensajes en la cola
+func (o *durableOutbox) len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.size()
+}
+
+// size cuenta los mensajes de todas las clases (requiere lock)
+func (o *durableOutbox) size() int {
+	total := 0
+	for _, queue := range o.queues {
+		total += len(queue)
+	}
+	return total
+}
+
+// priorities retorna las clases con mensajes, de mayor a menor prioridad (requiere lock)
+func (o *durableOutbox) priorities() []OutboxPriority {
+	priorities := make([]OutboxPriority, 0, len(o.queues))
+	for priority := range o.queues {
+		priorities = append(priorities, priority)
+	}
+	sort.Slice(priorities, func(i, j int) bool { return priorities[i] < priorities[j] })
+	return priorities
+}
+
+// lowestPriority retorna la clase menos prioritaria con mensajes (requiere lock)
+func (o *durableOutbox) lowestPriority() (OutboxPriority, bool) {
+	priorities := o.priorities()
+	for i := len(priorities) - 1; i >= 0; i-- {
+		if len(o.queues[priorities[i]]) > 0 {
+			return priorities[i], true
+		}
+	}
+	return 0, false
+}
+
+// delete borra mensajes del store (requiere lock)
+func (o *durableOutbox) delete(seqs []uint64) {
+	if err := o.store.DeleteOutboxMessages(seqs); err != nil {
+		log.Printf("⚠️ Error borrando mensajes mesh pendientes: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package network
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// newTestOutboxStore crea un storage temporal para la cola persistente
+func newTestOutboxStore(t *testing.T) *storage.BlockchainDB {
+	testDir := "./test_data_outbox_" + t.Name()
+	db, err := storage.NewBlockchainDB(testDir)
+	if err != nil {
+		t.Fatalf("Error creando storage: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(testDir)
+	})
+	return db
+}
+
+// outboxTopics retorna los topics de los mensajes en orden de envío
+func outboxTopics(entries []*outboxEntry) []string {
+	topics := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		topics = append(topics, entry.Message.Topic)
+	}
+	return topics
+}
+
+// TestDurableOutbox verifica el orden por clase, el límite, el TTL y la recuperación tras un reinicio
+func TestDurableOutbox(t *testing.T) {
+	db := newTestOutboxStore(t)
+	outbox, err := newDurableOutbox(db, time.Minute, 3)
+	if err != nil {
+		t.Fatalf("Error creando cola: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	outbox.now = func() time.Time { return now }
+
+	outbox.push(&MeshMessage{Type: MessageTypePublish, Topic: TopicTransactions, Data: []byte(`"tx1"`)}, OutboxPriorityTransactions)
+	outbox.push(&MeshMessage{Type: MessageTypePublish, Topic: TopicBlockHeaders, Data: []byte(`"h1"`)}, OutboxPriorityConsensus)
+	outbox.push(&MeshMessage{Type: MessageTypePublish, Topic: TopicTxRelayAck}, OutboxPriorityTransactions)
+
+	// Llena: un header desplaza a la transacción más antigua, una transacción no desplaza headers
+	if err := outbox.push(&MeshMessage{Type: MessageTypePublish, Topic: TopicTxAnnouncements}, OutboxPriorityConsensus); err != nil {
+		t.Fatalf("Error encolando anuncio: %v", err)
+	}
+	outbox.push(&MeshMessage{Type: MessageTypePublish, Topic: TopicValidators}, OutboxPriorityConsensus)
+	if err := outbox.push(&MeshMessage{Type: MessageTypePublish, Topic: TopicTransactions}, OutboxPriorityTransactions); err == nil {
+		t.Error("Una transacción no debería desplazar mensajes de consenso")
+	}
+
+	pending := outbox.pending()
+	topics := outboxTopics(pending)
+	if len(topics) != 3 || topics[0] != TopicBlockHeaders || topics[1] != TopicTxAnnouncements || topics[2] != TopicValidators {
+		t.Fatalf("Orden inesperado: %v", topics)
+	}
+
+	// Lo enviado se borra; lo pendiente se recupera tras un reinicio en el mismo orden
+	outbox.remove([]uint64{pending[0].Seq})
+	restored, err := newDurableOutbox(db, time.Minute, 3)
+	if err != nil {
+		t.Fatalf("Error recuperando cola: %v", err)
+	}
+	restored.now = func() time.Time { return now }
+	if topics := outboxTopics(restored.pending()); len(topics) != 2 || topics[0] != TopicTxAnnouncements {
+		t.Fatalf("Cola recuperada incorrecta: %v", topics)
+	}
+	restored.push(&MeshMessage{Type: MessageTypePublish, Topic: TopicBlocks}, OutboxPriorityConsensus)
+	if entries := restored.pending(); entries[2].Seq <= entries[1].Seq {
+		t.Errorf("La secuencia debería continuar tras el reinicio: %d <= %d", entries[2].Seq, entries[1].Seq)
+	}
+
+	// Vencido el TTL los mensajes se descartan también del storage
+	now = now.Add(2 * time.Minute)
+	if pending := restored.pending(); len(pending) != 0 {
+		t.Errorf("Los mensajes vencidos deberían descartarse: %v", outboxTopics(pending))
+	}
+	if reloaded, err := newDurableOutbox(db, time.Minute, 3); err != nil || reloaded.len() != 0 {
+		t.Errorf("Los mensajes vencidos no deberían quedar en el storage: %v", err)
+	}
+}
+
+// TestMeshBridgeDurableOutbox verifica que solo los mensajes relevantes para el consenso van a la cola persistente
+func TestMeshBridgeDurableOutbox(t *testing.T) {
+	meshBridge := NewMeshBridge(context.Background(), nil, "ws://localhost:3001", nil)
+	meshBridge.SetOutboxLimit(10)
+	if err := meshBridge.SetDurableOutbox(newTestOutboxStore(t), 0); err != nil {
+		t.Fatalf("Error configurando cola persistente: %v", err)
+	}
+
+	for _, topic := range []string{TopicBlockHeaders, TopicNodes, QueryTopic, TopicTransactions} {
+		if err := meshBridge.sendMessage(&MeshMessage{Type: MessageTypePublish, Topic: topic}); err != nil {
+			t.Fatalf("sendMessage no debería fallar sin conexión: %v", err)
+		}
+	}
+	if meshBridge.durable.len() != 2 || len(meshBridge.outbox) != 2 || meshBridge.BufferedMessages() != 4 {
+		t.Errorf("Reparto inesperado: %d persistentes, %d en memoria", meshBridge.durable.len(), len(meshBridge.outbox))
+	}
+	if meshBridge.durable.ttl != DefaultOutboxTTL {
+		t.Errorf("TTL por defecto incorrecto: %s", meshBridge.durable.ttl)
+	}
+}
@@ -2,11 +2,18 @@ package network
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/health"
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 )
 
 // P2PNetwork maneja la comunicación P2P usando oxygen-sdk mesh
@@ -16,6 +23,12 @@ type P2PNetwork struct {
 	consensus    *consensus.CometBFT
 	meshBridge   *MeshBridge
 	meshEndpoint string
+	follower     *ChainFollower
+	announcer    *blockAnnouncer
+	headerSync   *HeaderSync
+	scorer       *PeerScorer
+	peerTable    *PeerTable
+	advertiser   *nodeAdvertiser // Anuncia el descriptor propio (nil sin consenso)
 	running      bool
 }
 
@@ -23,6 +36,34 @@ type P2PNetwork struct {
 type Config struct {
 	MeshEndpoint string
 	PeerID       string
+	ChainID      string // Cadena seguida por los headers anunciados en la mesh
+
+	// Reconexión y buffering del mesh bridge (0 usa los valores por defecto)
+	// OutboxTTL es cuánto esperan en la cola persistente los mensajes relevantes para el consenso
+	ReconnectMaxBackoff time.Duration
+	OutboxLimit         int
+	OutboxTTL           time.Duration
+
+	// Autenticación de mensajes: clave del nodo (nil carga o genera una persistente en DataDir),
+	// si se exige firma y qué peers se aceptan (vacío acepta cualquiera con firma válida)
+	NodeKey               *ecdsa.PrivateKey
+	DataDir               string
+	RequireSignedMessages bool
+	AllowedPeers          []string
+
+	// Codificación preferida para negociar con el SDK ("proto" o "json") y compresión ("snappy", "gzip", "none")
+	Encoding    string
+	Compression string
+
+	// Scoring de peers: duración del ban y mensajes por segundo aceptados por peer (0 usa los valores por defecto)
+	BanDuration     time.Duration
+	PeerMessageRate int
+
+	// Descubrimiento de peers: URL pública del API REST incluida en el descriptor, cada cuánto se anuncia
+	// (0 usa DefaultDescriptorInterval) y si los nodos descubiertos se agregan como peers persistentes de CometBFT
+	RESTEndpoint        string
+	DescriptorInterval  time.Duration
+	AutoPersistentPeers bool
 }
 
 // NewP2PNetwork crea una nueva instancia de la red P2P
@@ -35,6 +76,132 @@ func NewP2PNetwork(
 	
 	// Crear mesh bridge con storage para query handler
 	meshBridge := NewMeshBridge(ctx, consensus, config.MeshEndpoint, storage)
+	meshBridge.SetReconnectBackoff(0, config.ReconnectMaxBackoff)
+	if config.OutboxLimit > 0 {
+		meshBridge.SetOutboxLimit(config.OutboxLimit)
+	}
+	// Los mensajes relevantes para el consenso sobreviven a desconexiones largas y a reinicios
+	if storage != nil {
+		if err := meshBridge.SetDurableOutbox(storage, config.OutboxTTL); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	}
+
+	if config.Encoding != "" {
+		compression := config.Compression
+		if compression == "" {
+			compression = CompressionNone
+		}
+		if err := meshBridge.SetCodecPreferences(config.Encoding, compression); err != nil {
+			return nil, err
+		}
+	}
+
+	// Firmar mensajes con la clave del nodo (la identidad mesh debe sobrevivir reinicios)
+	nodeKey := config.NodeKey
+	if nodeKey == nil {
+		key, err := loadOrCreateNodeKey(config.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		nodeKey = key
+	}
+
+	auth, err := NewMeshAuth(nodeKey, config.RequireSignedMessages)
+	if err != nil {
+		return nil, fmt.Errorf("error creando autenticación mesh: %w", err)
+	}
+	auth.SetAllowedPeers(config.AllowedPeers)
+	auth.SetChainID(config.ChainID)
+	meshBridge.SetAuth(auth)
+
+	// Scoring de peers con la lista de bans persistida en storage
+	scorer := auth.Scorer()
+	scorer.SetBanPolicy(0, config.BanDuration)
+	if config.PeerMessageRate > 0 {
+		scorer.SetMessageRate(config.PeerMessageRate)
+	}
+	if storage != nil {
+		if err := scorer.SetStore(storage); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	}
+	log.Printf("Identidad mesh del nodo: %s", auth.Address().Hex())
+
+	// Gossip de headers y transacciones confirmadas para nodos que siguen la cadena sin CometBFT
+	// Solo se aceptan anuncios firmados por validadores activos y solo el proponente anuncia cada bloque
+	follower := NewChainFollower(config.ChainID, DefaultFollowedHeaders)
+	meshBridge.SetChainFollower(follower)
+	announcer := newBlockAnnouncer(meshBridge, follower)
+	if consensus != nil {
+		follower.SetValidatorCheck(func(signer string) bool {
+			for _, validator := range consensus.GetValidators() {
+				if strings.EqualFold(validator.Address, signer) {
+					return true
+				}
+			}
+			return false
+		})
+		announcer.isProposer = consensus.IsProposer
+		consensus.AddBlockCommitHandler(announcer.enqueue)
+		// La mayor altura anunciada por los validadores alimenta el estado de sync
+		consensus.SetPeerHeightProvider(follower.LatestHeight)
+	}
+
+	// Relay de transacciones: clientes de la mesh sin acceso al API REST envían transacciones firmadas,
+	// que se validan (CheckTx) antes de entrar al mempool y se confirman al cliente por la mesh
+	if consensus != nil {
+		relay := NewTxRelay(checkAndSubmit(ctx, consensus), meshBridge.PublishTxRelayAck, DefaultTxRelayTTL)
+		meshBridge.SetTxRelay(relay)
+		consensus.AddTxResultsHandler(relay.HandleTxResults)
+	}
+
+	// Descubrimiento de peers: cada nodo anuncia un descriptor firmado con su dirección P2P de CometBFT
+	// La tabla expira los peers que dejan de anunciarse (5 intervalos sin descriptor)
+	descriptorInterval := config.DescriptorInterval
+	if descriptorInterval <= 0 {
+		descriptorInterval = DefaultDescriptorInterval
+	}
+	peerTable := NewPeerTable(config.ChainID, 5*descriptorInterval, 0)
+	meshBridge.SetPeerTable(peerTable)
+	var advertiser *nodeAdvertiser
+	if consensus != nil {
+		peerTable.SetSelf(auth.Address().Hex(), consensus.P2PNodeID())
+		engine := consensus
+		advertiser = newNodeAdvertiser(meshBridge, func() *NodeDescriptor {
+			return localDescriptor(config, engine)
+		}, descriptorInterval)
+		if config.AutoPersistentPeers && autoPersistentPeersAllowed(consensus.NodeRole()) {
+			peerTable.SetPeerHandler(func(peer DiscoveredPeer) {
+				if err := engine.AddPersistentPeers([]string{peer.P2PAddress}); err != nil {
+					log.Printf("⚠️ No se pudo agregar el peer descubierto %s: %v", peer.P2PAddress, err)
+					return
+				}
+				peerTable.MarkPersistent(peer.MeshAddress)
+				log.Printf("🔗 Peer descubierto por mesh agregado como persistente: %s (%s)", peer.P2PAddress, peer.Role)
+			})
+		}
+	}
+
+	// Queries dirigidas: identificar al nodo y validar bloques contra los hashes firmados por validadores
+	if queryHandler := meshBridge.QueryHandler(); queryHandler != nil {
+		queryHandler.SetNodeID(auth.Address().Hex())
+		queryHandler.SetPeerScorer(scorer)
+		queryHandler.SetKnownHashProvider(func(height uint64) (string, bool) {
+			header, ok := follower.Header(height)
+			if !ok {
+				return "", false
+			}
+			return header.Hash, true
+		})
+	}
+
+	// Sincronización de headers por mesh: recupera los anuncios perdidos mientras la mesh estuvo caída
+	// Los lotes se aceptan solo de validadores activos, así que hace falta el consenso local para conocerlos
+	var headerSync *HeaderSync
+	if queryHandler := meshBridge.QueryHandler(); queryHandler != nil && consensus != nil {
+		headerSync = NewHeaderSync(queryHandler, follower, DefaultHeaderSyncInterval)
+	}
 	
 	n := &P2PNetwork{
 		ctx:          ctx,
@@ -42,6 +209,12 @@ func NewP2PNetwork(
 		consensus:    consensus,
 		meshBridge:   meshBridge,
 		meshEndpoint: config.MeshEndpoint,
+		follower:     follower,
+		announcer:    announcer,
+		headerSync:   headerSync,
+		scorer:       scorer,
+		peerTable:    peerTable,
+		advertiser:   advertiser,
 		running:      false,
 	}
 
@@ -60,17 +233,63 @@ func (n *P2PNetwork) Start() error {
 		return fmt.Errorf("error iniciando mesh bridge: %w", err)
 	}
 
+	// Anunciar bloques confirmados por la mesh
+	go n.announcer.run()
+
+	// Anunciar el descriptor del nodo para el descubrimiento de peers
+	if n.advertiser != nil {
+		go n.advertiser.run()
+	}
+
+	// Recuperar por mesh los headers que falten respecto de los validadores
+	if n.headerSync != nil {
+		go n.headerSync.run()
+	}
+
 	n.running = true
 	log.Println("✅ Red P2P iniciada")
 	return nil
 }
 
+// SetHealthChecker configura el health checker donde se refleja el estado del mesh
+func (n *P2PNetwork) SetHealthChecker(healthChecker *health.HealthChecker) {
+	n.meshBridge.SetHealthChecker(healthChecker)
+}
+
+// ChainFollower retorna el seguidor de la cadena alimentado por los headers anunciados en la mesh
+func (n *P2PNetwork) ChainFollower() *ChainFollower {
+	return n.follower
+}
+
+// QueryHandler retorna el handler de queries dirigidas a otros nodos de la mesh
+func (n *P2PNetwork) QueryHandler() *QueryHandler {
+	return n.meshBridge.QueryHandler()
+}
+
+// PeerScorer retorna el scoring de peers del mesh (bans y mal comportamiento registrado)
+func (n *P2PNetwork) PeerScorer() *PeerScorer {
+	return n.scorer
+}
+
+// PeerTable retorna los nodos descubiertos por sus descriptores anunciados en la mesh
+func (n *P2PNetwork) PeerTable() *PeerTable {
+	return n.peerTable
+}
+
 // Stop detiene la red P2P
 func (n *P2PNetwork) Stop() error {
 	if !n.running {
 		return nil
 	}
 
+	n.announcer.stop()
+	if n.advertiser != nil {
+		n.advertiser.stop()
+	}
+	if n.headerSync != nil {
+		n.headerSync.stop()
+	}
+
 	// Detener mesh bridge
 	if err := n.meshBridge.Stop(); err != nil {
 		return fmt.Errorf("error deteniendo mesh bridge: %w", err)
@@ -99,3 +318,61 @@ func (n *P2PNetwork) BroadcastBlock(block *consensus.Block) error {
 	return n.meshBridge.BroadcastBlock(block)
 }
 
+// localDescriptor arma el descriptor del nodo con su identidad y dirección P2P de CometBFT
+// Un validador no anuncia su dirección: detrás de sentries solo se conecta a OXY_PERSISTENT_PEERS
+func localDescriptor(config *Config, engine *consensus.CometBFT) *NodeDescriptor {
+	role := engine.NodeRole()
+	descriptor := &NodeDescriptor{
+		ChainID:      config.ChainID,
+		NodeID:       engine.P2PNodeID(),
+		AppVersion:   consensus.AppVersion,
+		RESTEndpoint: config.RESTEndpoint,
+		Role:         role,
+		Timestamp:    time.Now().UnixMilli(),
+	}
+	if role != consensus.NodeModeValidator {
+		descriptor.P2PAddress = engine.P2PAddress()
+	}
+	return descriptor
+}
+
+// autoPersistentPeersAllowed retorna si el rol del nodo admite agregar peers descubiertos:
+// un validador solo habla con sus sentries y un seed solo rastrea direcciones por PEX
+func autoPersistentPeersAllowed(role string) bool {
+	return role != consensus.NodeModeValidator && role != consensus.NodeModeSeed
+}
+
+// nodeKeyFile es el archivo (dentro del directorio de datos) con la clave mesh del nodo
+const nodeKeyFile = "mesh_node_key"
+
+// loadOrCreateNodeKey carga la clave mesh del nodo desde dataDir o genera y guarda una nueva
+func loadOrCreateNodeKey(dataDir string) (*ecdsa.PrivateKey, error) {
+	if dataDir == "" {
+		return nil, fmt.Errorf("clave de nodo requerida: configurar NodeKey o DataDir para persistir la identidad mesh")
+	}
+
+	keyPath := filepath.Join(dataDir, nodeKeyFile)
+	if _, err := os.Stat(keyPath); err == nil {
+		key, err := ethcrypto.LoadECDSA(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error cargando clave mesh del nodo: %w", err)
+		}
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error leyendo clave mesh del nodo: %w", err)
+	}
+
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("error generando clave mesh del nodo: %w", err)
+	}
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, fmt.Errorf("error creando directorio de datos: %w", err)
+	}
+	if err := ethcrypto.SaveECDSA(keyPath, key); err != nil {
+		return nil, fmt.Errorf("error guardando clave mesh del nodo: %w", err)
+	}
+
+	log.Printf("Clave mesh del nodo generada en %s", keyPath)
+	return key, nil
+}
@@ -0,0 +1,410 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Misbehavior es un tipo de mal comportamiento de un peer del mesh
+type Misbehavior string
+
+const (
+	MisbehaviorInvalid Misbehavior = "invalid" // Firma, identidad o payload inválido
+	MisbehaviorSpam    Misbehavior = "spam"    // Excede la tasa de mensajes permitida
+	MisbehaviorTimeout Misbehavior = "timeout" // No responde queries dirigidas a él
+)
+
+// misbehaviorPenalties es la penalización que suma cada tipo de mal comportamiento
+var misbehaviorPenalties = map[Misbehavior]float64{
+	MisbehaviorInvalid: 10,
+	MisbehaviorSpam:    5,
+	MisbehaviorTimeout: 2,
+}
+
+// Valores por defecto del scoring de peers
+const (
+	DefaultMaxInvalidMessages = 3                // Mensajes inválidos tolerados antes de banear al peer
+	DefaultPeerBanDuration    = 10 * time.Minute // Duración del ban
+	DefaultScoreHalfLife      = 5 * time.Minute  // La penalización acumulada se reduce a la mitad en este tiempo
+	DefaultPeerMessageRate    = 100              // Mensajes por segundo aceptados por peer
+	maxTrackedPeers           = 4096             // Peers con estado antes de descartar los inactivos
+)
+
+// PeerBan es un ban temporal de un peer
+type PeerBan struct {
+	Peer   string    `json:"peer"`
+	Until  time.Time `json:"until"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// PeerScore es el estado de scoring de un peer expuesto por el API de administración
+type PeerScore struct {
+	Peer        string     `json:"peer"`
+	Score       float64    `json:"score"` // Penalización acumulada con decaimiento (0 = sin mal comportamiento reciente)
+	Invalid     uint64     `json:"invalid"`
+	Spam        uint64     `json:"spam"`
+	Timeouts    uint64     `json:"timeouts"`
+	Bans        int        `json:"bans"`
+	BannedUntil *time.Time `json:"bannedUntil,omitempty"`
+}
+
+// BanStore persiste la lista de bans entre reinicios del nodo
+type BanStore interface {
+	SavePeerBans(data []byte) error
+	GetPeerBans() ([]byte, error)
+}
+
+// peerState es el estado interno de un peer
+type peerState struct {
+	score    float64
+	updated  time.Time
+	invalid  uint64
+	spam     uint64
+	timeouts uint64
+	bans     int
+
+	// Ventana de un segundo para detectar spam
+	windowStart time.Time
+	windowCount int
+}
+
+// PeerScorer lleva la cuenta del mal comportamiento de cada peer del mesh
+// Cada falta suma una penalización que decae exponencialmente con el tiempo;
+// al superar el umbral el peer se banea temporalmente y su score se reinicia
+type PeerScorer struct {
+	threshold   float64
+	halfLife    time.Duration
+	banDuration time.Duration
+	messageRate int
+
+	peers map[string]*peerState
+	bans  map[string]PeerBan
+	store BanStore
+	mu    sync.Mutex
+}
+
+// NewPeerScorer crea un scorer con la política por defecto
+func NewPeerScorer() *PeerScorer {
+	return &PeerScorer{
+		threshold:   DefaultMaxInvalidMessages * misbehaviorPenalties[MisbehaviorInvalid],
+		halfLife:    DefaultScoreHalfLife,
+		banDuration: DefaultPeerBanDuration,
+		messageRate: DefaultPeerMessageRate,
+		peers:       make(map[string]*peerState),
+		bans:        make(map[string]PeerBan),
+	}
+}
+
+// SetBanPolicy configura el umbral de penalización y la duración del ban (valores <= 0 se ignoran)
+func (s *PeerScorer) SetBanPolicy(threshold float64, banDuration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if threshold > 0 {
+		s.threshold = threshold
+	}
+	if banDuration > 0 {
+		s.banDuration = banDuration
+	}
+}
+
+// SetHalfLife configura el tiempo en que la penalización acumulada se reduce a la mitad
+func (s *PeerScorer) SetHalfLife(halfLife time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if halfLife > 0 {
+		s.halfLife = halfLife
+	}
+}
+
+// SetMessageRate configura los mensajes por segundo aceptados por peer (0 deshabilita el límite)
+func (s *PeerScorer) SetMessageRate(perSecond int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if perSecond >= 0 {
+		s.messageRate = perSecond
+	}
+}
+
+// SetStore configura dónde se persiste la lista de bans y carga los bans vigentes
+func (s *PeerScorer) SetStore(store BanStore) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store = store
+	if store == nil {
+		return nil
+	}
+
+	data, err := store.GetPeerBans()
+	if err != nil {
+		return fmt.Errorf("error cargando lista de bans: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var bans []PeerBan
+	if err := json.Unmarshal(data, &bans); err != nil {
+		return fmt.Errorf("error decodificando lista de bans: %w", err)
+	}
+
+	now := time.Now()
+	for _, ban := range bans {
+		if ban.Peer != "" && ban.Until.After(now) {
+			s.bans[ban.Peer] = ban
+		}
+	}
+	if len(s.bans) > 0 {
+		log.Printf("🚫 %d peers mesh baneados restaurados", len(s.bans))
+	}
+	return nil
+}
+
+// state retorna el estado del peer aplicando el decaimiento del score (requiere lock)
+func (s *PeerScorer) state(peer string, now time.Time) *peerState {
+	st, ok := s.peers[peer]
+	if !ok {
+		if len(s.peers) >= maxTrackedPeers {
+			s.evictIdle(now)
+		}
+		st = &peerState{updated: now}
+		s.peers[peer] = st
+		return st
+	}
+
+	if elapsed := now.Sub(st.updated); elapsed > 0 && st.score > 0 {
+		// Redondear a centésimas: faltas consecutivas no deben quedar justo por debajo del umbral
+		st.score = math.Round(st.score*math.Pow(0.5, float64(elapsed)/float64(s.halfLife))*100) / 100
+	}
+	st.updated = now
+	return st
+}
+
+// evictIdle descarta el estado de peers sin penalización vigente ni actividad reciente (requiere lock)
+func (s *PeerScorer) evictIdle(now time.Time) {
+	for peer, st := range s.peers {
+		if _, banned := s.bans[peer]; banned || now.Sub(st.windowStart) < time.Second {
+			continue
+		}
+		if s.state(peer, now).score == 0 {
+			delete(s.peers, peer)
+		}
+	}
+}
+
+// Penalize registra un mal comportamiento del peer y lo banea al superar el umbral
+// Retorna true si el peer quedó baneado
+func (s *PeerScorer) Penalize(peer string, kind Misbehavior, reason error) bool {
+	if peer == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.penalize(peer, kind, reason, time.Now())
+}
+
+// penalize aplica la penalización (requiere lock)
+func (s *PeerScorer) penalize(peer string, kind Misbehavior, reason error, now time.Time) bool {
+	if _, banned := s.activeBan(peer, now); banned {
+		return false
+	}
+
+	st := s.state(peer, now)
+	st.score += misbehaviorPenalties[kind]
+	switch kind {
+	case MisbehaviorInvalid:
+		st.invalid++
+	case MisbehaviorSpam:
+		st.spam++
+	case MisbehaviorTimeout:
+		st.timeouts++
+	}
+
+	if st.score < s.threshold {
+		return false
+	}
+
+	st.score = 0
+	st.bans++
+	s.ban(peer, s.banDuration, fmt.Sprintf("%s: %v", kind, reason), now)
+	log.Printf("🚫 Peer mesh %s baneado por %s (%s): %v", peer, s.banDuration, kind, reason)
+	return true
+}
+
+// AllowMessage registra un mensaje recibido del peer y retorna false si excede la tasa permitida
+// Superar la tasa cuenta como spam una vez por ventana
+func (s *PeerScorer) AllowMessage(peer string) bool {
+	if peer == "" {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.messageRate == 0 {
+		return true
+	}
+
+	now := time.Now()
+	st := s.state(peer, now)
+	if now.Sub(st.windowStart) >= time.Second {
+		st.windowStart = now
+		st.windowCount = 0
+	}
+	st.windowCount++
+
+	if st.windowCount <= s.messageRate {
+		return true
+	}
+	if st.windowCount == s.messageRate+1 {
+		s.penalize(peer, MisbehaviorSpam, fmt.Errorf("más de %d mensajes por segundo", s.messageRate), now)
+	}
+	return false
+}
+
+// Ban banea manualmente a un peer (duración <= 0 usa la duración por defecto)
+func (s *PeerScorer) Ban(peer string, duration time.Duration, reason string) error {
+	if peer == "" {
+		return fmt.Errorf("peer requerido")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if duration <= 0 {
+		duration = s.banDuration
+	}
+	s.ban(peer, duration, reason, time.Now())
+	log.Printf("🚫 Peer mesh %s baneado manualmente por %s: %s", peer, duration, reason)
+	return nil
+}
+
+// ban registra el ban y persiste la lista (requiere lock)
+func (s *PeerScorer) ban(peer string, duration time.Duration, reason string, now time.Time) {
+	s.bans[peer] = PeerBan{Peer: peer, Until: now.Add(duration), Reason: reason}
+	s.persist()
+}
+
+// Unban levanta el ban de un peer y reinicia su score
+// Retorna false si el peer no estaba baneado
+func (s *PeerScorer) Unban(peer string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, banned := s.activeBan(peer, time.Now()); !banned {
+		return false
+	}
+
+	delete(s.bans, peer)
+	if st, ok := s.peers[peer]; ok {
+		st.score = 0
+	}
+	s.persist()
+	log.Printf("✅ Ban de peer mesh %s levantado", peer)
+	return true
+}
+
+// activeBan retorna el ban vigente del peer, descartando los expirados (requiere lock)
+func (s *PeerScorer) activeBan(peer string, now time.Time) (PeerBan, bool) {
+	ban, ok := s.bans[peer]
+	if !ok {
+		return PeerBan{}, false
+	}
+	if now.After(ban.Until) {
+		delete(s.bans, peer)
+		return PeerBan{}, false
+	}
+	return ban, true
+}
+
+// IsBanned indica si un peer está baneado
+func (s *PeerScorer) IsBanned(peer string) bool {
+	if peer == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, banned := s.activeBan(peer, time.Now())
+	return banned
+}
+
+// Bans retorna los bans vigentes ordenados por vencimiento
+func (s *PeerScorer) Bans() []PeerBan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	bans := make([]PeerBan, 0, len(s.bans))
+	for peer := range s.bans {
+		if ban, ok := s.activeBan(peer, now); ok {
+			bans = append(bans, ban)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Until.Before(bans[j].Until) })
+	return bans
+}
+
+// Scores retorna el estado de todos los peers con mal comportamiento registrado, de mayor a menor score
+func (s *PeerScorer) Scores() []PeerScore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	scores := make([]PeerScore, 0, len(s.peers))
+	for peer := range s.peers {
+		st := s.state(peer, now)
+		if st.invalid+st.spam+st.timeouts == 0 {
+			continue
+		}
+		score := PeerScore{
+			Peer:     peer,
+			Score:    st.score,
+			Invalid:  st.invalid,
+			Spam:     st.spam,
+			Timeouts: st.timeouts,
+			Bans:     st.bans,
+		}
+		if ban, ok := s.activeBan(peer, now); ok {
+			until := ban.Until
+			score.BannedUntil = &until
+		}
+		scores = append(scores, score)
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Peer < scores[j].Peer
+	})
+	return scores
+}
+
+// persist guarda la lista de bans vigentes (requiere lock)
+func (s *PeerScorer) persist() {
+	if s.store == nil {
+		return
+	}
+
+	bans := make([]PeerBan, 0, len(s.bans))
+	for _, ban := range s.bans {
+		bans = append(bans, ban)
+	}
+	data, err := json.Marshal(bans)
+	if err != nil {
+		log.Printf("⚠️ Error codificando lista de bans: %v", err)
+		return
+	}
+	if err := s.store.SavePeerBans(data); err != nil {
+		log.Printf("⚠️ Error persistiendo lista de bans: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// memoryBanStore es un BanStore en memoria para tests
+type memoryBanStore struct {
+	data []byte
+}
+
+func (m *memoryBanStore) SavePeerBans(data []byte) error {
+	m.data = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memoryBanStore) GetPeerBans() ([]byte, error) {
+	return m.data, nil
+}
+
+// TestPeerScorerPenaltiesAndDecay verifica el umbral de ban por tipo de falta y el decaimiento del score
+func TestPeerScorerPenaltiesAndDecay(t *testing.T) {
+	scorer := NewPeerScorer()
+
+	// Los timeouts penalizan menos que los mensajes inválidos
+	for i := 0; i < 5; i++ {
+		if scorer.Penalize("peer-a", MisbehaviorTimeout, fmt.Errorf("timeout")) {
+			t.Fatal("Pocos timeouts no deberían banear")
+		}
+	}
+	scores := scorer.Scores()
+	if len(scores) != 1 || scores[0].Timeouts != 5 || scores[0].Score <= 0 {
+		t.Fatalf("Scores inesperados: %+v", scores)
+	}
+
+	// El score decae con el tiempo
+	scorer.SetHalfLife(20 * time.Millisecond)
+	before := scorer.Scores()[0].Score
+	time.Sleep(60 * time.Millisecond)
+	if after := scorer.Scores()[0].Score; after >= before/4 {
+		t.Errorf("El score debería decaer: antes %.2f, después %.2f", before, after)
+	}
+
+	// Con el score decaído, hacen falta los 3 mensajes inválidos para banear
+	scorer.Penalize("peer-a", MisbehaviorInvalid, fmt.Errorf("firma inválida"))
+	scorer.Penalize("peer-a", MisbehaviorInvalid, fmt.Errorf("firma inválida"))
+	if scorer.IsBanned("peer-a") {
+		t.Fatal("Peer no debería estar baneado aún")
+	}
+	if !scorer.Penalize("peer-a", MisbehaviorInvalid, fmt.Errorf("firma inválida")) {
+		t.Fatal("Debería banear al superar el umbral")
+	}
+	if score := scorer.Scores()[0]; score.Bans != 1 || score.BannedUntil == nil || score.Score != 0 {
+		t.Errorf("Estado de ban inesperado: %+v", score)
+	}
+}
+
+// TestPeerScorerSpam verifica que exceder la tasa de mensajes descarta mensajes y penaliza al peer
+func TestPeerScorerSpam(t *testing.T) {
+	scorer := NewPeerScorer()
+	scorer.SetMessageRate(5)
+	scorer.SetBanPolicy(misbehaviorPenalties[MisbehaviorSpam], time.Minute)
+
+	allowed := 0
+	for i := 0; i < 20; i++ {
+		if scorer.AllowMessage("spammer") {
+			allowed++
+		}
+	}
+	if allowed != 5 {
+		t.Errorf("Esperados 5 mensajes aceptados, aceptados %d", allowed)
+	}
+	if !scorer.IsBanned("spammer") {
+		t.Error("Spammer debería quedar baneado")
+	}
+	if scores := scorer.Scores(); len(scores) != 1 || scores[0].Spam != 1 {
+		t.Errorf("Spam debería contarse una vez por ventana: %+v", scores)
+	}
+
+	if !scorer.AllowMessage("otro") {
+		t.Error("Otros peers no deberían verse afectados")
+	}
+}
+
+// TestPeerScorerPersistence verifica que los bans sobreviven a un reinicio y pueden levantarse
+func TestPeerScorerPersistence(t *testing.T) {
+	store := &memoryBanStore{}
+
+	scorer := NewPeerScorer()
+	if err := scorer.SetStore(store); err != nil {
+		t.Fatalf("Error configurando store: %v", err)
+	}
+	scorer.Ban("peer-a", time.Hour, "manual")
+	scorer.Ban("peer-b", time.Millisecond, "corto")
+
+	time.Sleep(5 * time.Millisecond)
+
+	restored := NewPeerScorer()
+	if err := restored.SetStore(store); err != nil {
+		t.Fatalf("Error cargando bans: %v", err)
+	}
+	bans := restored.Bans()
+	if len(bans) != 1 || bans[0].Peer != "peer-a" || bans[0].Reason != "manual" {
+		t.Fatalf("Bans restaurados inesperados: %+v", bans)
+	}
+
+	if !restored.Unban("peer-a") {
+		t.Fatal("Unban debería levantar el ban")
+	}
+	if restored.Unban("peer-a") {
+		t.Error("Unban de un peer no baneado debería retornar false")
+	}
+
+	var persisted []PeerBan
+	json.Unmarshal(store.data, &persisted)
+	if len(persisted) != 0 {
+		t.Errorf("La lista persistida debería quedar vacía: %+v", persisted)
+	}
+}
+
+// TestQueryHandler_TimeoutPenalizesPeer verifica que los peers que no responden se penalizan y,
+// una vez baneados, dejan de consultarse
+func TestQueryHandler_TimeoutPenalizesPeer(t *testing.T) {
+	meshBridge := NewMeshBridge(context.Background(), nil, "ws://localhost:3001", nil)
+	handler := NewQueryHandler(context.Background(), nil, nil, meshBridge)
+	scorer := NewPeerScorer()
+	scorer.SetBanPolicy(misbehaviorPenalties[MisbehaviorTimeout], time.Minute)
+	handler.SetPeerScorer(scorer)
+	handler.ObservePeer("0xABC")
+
+	if _, err := handler.QueryWithOptions("height", QueryOptions{Timeout: 10 * time.Millisecond, FanOut: 1}); err == nil {
+		t.Fatal("Query sin respuesta debería fallar")
+	}
+	if !scorer.IsBanned("0xabc") {
+		t.Fatal("Peer que no responde debería quedar baneado")
+	}
+
+	if peers := handler.selectPeers(3, map[string]bool{}); len(peers) != 0 {
+		t.Errorf("Peers baneados no deberían consultarse: %v", peers)
+	}
+}
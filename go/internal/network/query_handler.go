@@ -3,8 +3,12 @@ package network
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,16 +22,51 @@ type QueryHandler struct {
 	storage       *storage.BlockchainDB
 	consensus     *consensus.CometBFT
 	meshBridge    *MeshBridge
-	pendingQueries map[string]chan QueryResponse
+	pendingQueries map[string]*pendingQuery
 	mu            sync.RWMutex
+
+	nodeID     string                             // Identidad de este nodo en la mesh (responde solo queries dirigidas a él)
+	knownPeers map[string]time.Time               // Peers vistos recientemente (candidatos para queries dirigidas)
+	knownHash  func(height uint64) (string, bool) // Hash conocido de un bloque para validar respuestas (opcional)
+	scorer     *PeerScorer                        // Penaliza a los peers que no responden (opcional)
+	startOnce  sync.Once                          // La limpieza de queries vencidas se lanza una sola vez
+}
+
+// pendingQuery es una query enviada esperando respuesta
+type pendingQuery struct {
+	responses chan QueryResponse
+	deadline  time.Time
 }
 
+// QueryOptions configura cómo se resuelve una query por mesh
+type QueryOptions struct {
+	Timeout      time.Duration              // Tiempo de espera por intento
+	TotalTimeout time.Duration              // Duración máxima de la query con todos sus reintentos (0: sin límite adicional)
+	Retries      int                        // Reintentos contra otros peers si no hay respuesta válida
+	FanOut       int                        // Peers consultados en paralelo por intento (gana la primera respuesta válida)
+	Validate     func(*QueryResponse) error // Validación adicional de la respuesta (opcional)
+}
+
+// Valores por defecto de las queries por mesh
+const (
+	DefaultQueryTimeout = 5 * time.Second
+	DefaultQueryRetries = 2
+	DefaultQueryFanOut  = 3
+	maxKnownPeers       = 256
+)
+
+// errQueryCancelled indica que el contexto del handler se canceló durante una query
+var errQueryCancelled = errors.New("contexto cancelado")
+
 // QueryRequest representa una solicitud de query
 type QueryRequest struct {
 	Type      string `json:"type"`      // "query"
 	Path      string `json:"path"`      // "block/123", "account/0x...", etc.
 	RequestID string `json:"request_id"` // UUID para identificar la respuesta
 	From      string `json:"from,omitempty"` // Dirección del solicitante
+	To        string `json:"to,omitempty"`   // Peer al que va dirigida (vacío: cualquiera puede responder)
+
+	wireProto bool // La query llegó en protobuf (types.proto): se responde en el mismo formato
 }
 
 // QueryResponse representa una respuesta a una query
@@ -37,7 +76,9 @@ type QueryResponse struct {
 	Path      string          `json:"path"`
 	Data      json.RawMessage `json:"data,omitempty"`
 	Error     string          `json:"error,omitempty"`
-	From      string          `json:"from"`       // Dirección del nodo que responde
+	From      string          `json:"from"`       // Dirección del nodo que responde (el mesh bridge la reemplaza por la verificada)
+
+	wireProto bool // Se envía en protobuf (types.proto), como la query que responde
 }
 
 const (
@@ -57,65 +98,410 @@ func NewQueryHandler(
 		storage:        storage,
 		consensus:      consensus,
 		meshBridge:     meshBridge,
-		pendingQueries: make(map[string]chan QueryResponse),
+		pendingQueries: make(map[string]*pendingQuery),
+		knownPeers:     make(map[string]time.Time),
 	}
 }
 
+// SetNodeID establece la identidad de este nodo en la mesh
+func (qh *QueryHandler) SetNodeID(nodeID string) {
+	qh.mu.Lock()
+	defer qh.mu.Unlock()
+	qh.nodeID = nodeID
+}
+
+// SetKnownHashProvider establece la función que retorna el hash conocido de un bloque
+// Las respuestas a "block/N" cuyo hash no coincida con el conocido se descartan
+func (qh *QueryHandler) SetKnownHashProvider(provider func(height uint64) (string, bool)) {
+	qh.mu.Lock()
+	defer qh.mu.Unlock()
+	qh.knownHash = provider
+}
+
+// SetPeerScorer establece el scoring de peers: los peers baneados no se consultan
+// y los que no responden una query dirigida a ellos se penalizan
+func (qh *QueryHandler) SetPeerScorer(scorer *PeerScorer) {
+	qh.mu.Lock()
+	defer qh.mu.Unlock()
+	qh.scorer = scorer
+}
+
+// ObservePeer registra que un peer está activo para dirigirle queries
+// Solo debe llamarse con identidades verificadas por firma (ver MeshBridge.authenticate)
+func (qh *QueryHandler) ObservePeer(peer string) {
+	if peer == "" {
+		return
+	}
+
+	qh.mu.Lock()
+	defer qh.mu.Unlock()
+
+	if strings.EqualFold(peer, qh.nodeID) {
+		return
+	}
+
+	qh.knownPeers[peer] = time.Now()
+
+	// Descartar el peer visto hace más tiempo si se supera el límite
+	if len(qh.knownPeers) > maxKnownPeers {
+		oldestPeer := ""
+		var oldest time.Time
+		for p, seen := range qh.knownPeers {
+			if oldestPeer == "" || seen.Before(oldest) {
+				oldestPeer, oldest = p, seen
+			}
+		}
+		delete(qh.knownPeers, oldestPeer)
+	}
+}
+
+// KnownPeers retorna los peers conocidos, del visto más recientemente al más antiguo
+func (qh *QueryHandler) KnownPeers() []string {
+	qh.mu.RLock()
+	defer qh.mu.RUnlock()
+
+	peers := make([]string, 0, len(qh.knownPeers))
+	for peer := range qh.knownPeers {
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return qh.knownPeers[peers[i]].After(qh.knownPeers[peers[j]])
+	})
+	return peers
+}
+
+// PendingQueries retorna la cantidad de queries esperando respuesta
+func (qh *QueryHandler) PendingQueries() int {
+	qh.mu.RLock()
+	defer qh.mu.RUnlock()
+	return len(qh.pendingQueries)
+}
+
 // Start inicia el handler de queries
+// El mesh bridge lo llama en cada arranque (también al reconectar), pero la limpieza se lanza una sola vez
 func (qh *QueryHandler) Start() error {
 	// El query handler se integra con mesh_bridge cuando se crea
 	// mesh_bridge ya se suscribió a los topics necesarios
 	// Aquí solo inicializamos el handler
-	
-	log.Println("Query handler iniciado e integrado con mesh bridge")
+	qh.startOnce.Do(func() {
+		// Limpiar periódicamente queries vencidas cuyo solicitante ya no espera respuesta
+		go qh.cleanupLoop()
+		log.Println("Query handler iniciado e integrado con mesh bridge")
+	})
 	return nil
 }
 
+// cleanupLoop elimina queries pendientes vencidas hasta que se cancele el contexto
+func (qh *QueryHandler) cleanupLoop() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-qh.ctx.Done():
+			return
+		case <-ticker.C:
+			qh.cleanupExpired(time.Now())
+		}
+	}
+}
+
+// cleanupExpired elimina las queries pendientes cuyo deadline ya pasó
+func (qh *QueryHandler) cleanupExpired(now time.Time) int {
+	qh.mu.Lock()
+	defer qh.mu.Unlock()
+
+	removed := 0
+	for requestID, pending := range qh.pendingQueries {
+		if now.After(pending.deadline) {
+			delete(qh.pendingQueries, requestID)
+			removed++
+		}
+	}
+	return removed
+}
+
 // Query realiza una query a otros nodos por mesh network
+// Consulta hasta DefaultQueryFanOut peers por intento y reintenta contra otros peers
+// hasta DefaultQueryRetries veces; timeout es la duración máxima de la query completa
+// y se reparte entre los intentos
 func (qh *QueryHandler) Query(path string, timeout time.Duration) (*QueryResponse, error) {
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	return qh.QueryWithOptions(path, QueryOptions{
+		Timeout:      timeout / time.Duration(DefaultQueryRetries+1),
+		TotalTimeout: timeout,
+		Retries:      DefaultQueryRetries,
+		FanOut:       DefaultQueryFanOut,
+	})
+}
+
+// ErrUnknownBlockHash se retorna al pedir por mesh un bloque cuyo hash no fue anunciado por un validador
+var ErrUnknownBlockHash = errors.New("hash del bloque desconocido")
+
+// QueryBlock obtiene de otros nodos un bloque que no está en el storage local
+// Solo se piden bloques cuyo hash se conoce por los headers firmados por validadores,
+// así la respuesta de un peer se puede verificar antes de aceptarla
+func (qh *QueryHandler) QueryBlock(height uint64, timeout time.Duration) (*consensus.Block, error) {
+	qh.mu.RLock()
+	knownHash := qh.knownHash
+	qh.mu.RUnlock()
+
+	if knownHash == nil {
+		return nil, fmt.Errorf("%w: altura %d", ErrUnknownBlockHash, height)
+	}
+	if _, ok := knownHash(height); !ok {
+		return nil, fmt.Errorf("%w: altura %d", ErrUnknownBlockHash, height)
+	}
+
+	response, err := qh.Query(fmt.Sprintf("block/%d", height), timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var block consensus.Block
+	if err := json.Unmarshal(response.Data, &block); err != nil {
+		return nil, fmt.Errorf("bloque mal formado: %w", err)
+	}
+	return &block, nil
+}
+
+// QueryWithOptions realiza una query con estrategia de fan-out y reintentos:
+// en cada intento se consulta a FanOut peers no consultados antes y se toma la
+// primera respuesta válida. Si no hay peers conocidos la query se difunde a toda la mesh
+func (qh *QueryHandler) QueryWithOptions(path string, opts QueryOptions) (*QueryResponse, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultQueryTimeout
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.FanOut <= 0 {
+		opts.FanOut = 1
+	}
+
+	var deadline time.Time
+	if opts.TotalTimeout > 0 {
+		deadline = time.Now().Add(opts.TotalTimeout)
+	}
+
+	tried := make(map[string]bool)
+	var lastErr error
+
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		// Ningún intento puede extenderse más allá de la duración total de la query
+		attemptOpts := opts
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				if lastErr == nil {
+					lastErr = fmt.Errorf("timeout esperando respuesta para query: %s", path)
+				}
+				break
+			}
+			if remaining < attemptOpts.Timeout {
+				attemptOpts.Timeout = remaining
+			}
+		}
+
+		peers := qh.selectPeers(opts.FanOut, tried)
+
+		response, err := qh.queryAttempt(path, peers, attemptOpts)
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+
+		if errors.Is(err, errQueryCancelled) {
+			break
+		}
+		if attempt < opts.Retries {
+			log.Printf("Query %s sin respuesta válida (intento %d/%d): %v", path, attempt+1, opts.Retries+1, err)
+		}
+	}
+
+	return nil, lastErr
+}
+
+// selectPeers elige hasta k peers conocidos no consultados, priorizando los vistos más recientemente
+// Retorna nil si no quedan peers, en cuyo caso la query se difunde
+func (qh *QueryHandler) selectPeers(k int, tried map[string]bool) []string {
+	qh.mu.RLock()
+	scorer := qh.scorer
+	qh.mu.RUnlock()
+
+	var peers []string
+	for _, peer := range qh.KnownPeers() {
+		if len(peers) >= k {
+			break
+		}
+		if tried[peer] {
+			continue
+		}
+		if scorer != nil && scorer.IsBanned(strings.ToLower(peer)) {
+			continue
+		}
+		tried[peer] = true
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+// queryAttempt envía la query a los peers indicados (o a toda la mesh) y espera la primera respuesta válida
+func (qh *QueryHandler) queryAttempt(path string, peers []string, opts QueryOptions) (*QueryResponse, error) {
 	// Generar request ID único
 	requestID := generateRequestID()
-	
-	// Crear canal para recibir respuesta
-	responseChan := make(chan QueryResponse, 1)
-	
-	// Registrar query pendiente
+
+	// Registrar query pendiente (el buffer permite recibir una respuesta por peer sin bloquear)
+	responseChan := make(chan QueryResponse, len(peers)+1)
 	qh.mu.Lock()
-	qh.pendingQueries[requestID] = responseChan
+	qh.pendingQueries[requestID] = &pendingQuery{
+		responses: responseChan,
+		deadline:  time.Now().Add(opts.Timeout),
+	}
+	nodeID := qh.nodeID
+	scorer := qh.scorer
 	qh.mu.Unlock()
-	
-	// Limpiar después de timeout
+
+	// Limpiar al terminar el intento
 	defer func() {
 		qh.mu.Lock()
 		delete(qh.pendingQueries, requestID)
 		qh.mu.Unlock()
 	}()
-	
-	// Crear request
-	request := QueryRequest{
-		Type:      "query",
-		Path:      path,
-		RequestID: requestID,
+
+	targets := peers
+	if len(targets) == 0 {
+		targets = []string{""}
 	}
-	
-	// Enviar query por mesh
-	if err := qh.sendQuery(request); err != nil {
-		return nil, fmt.Errorf("error enviando query: %w", err)
+
+	// Enviar query por mesh a cada peer
+	sent := 0
+	var sendErr error
+	for _, peer := range targets {
+		request := QueryRequest{
+			Type:      "query",
+			Path:      path,
+			RequestID: requestID,
+			From:      nodeID,
+			To:        peer,
+		}
+		if err := qh.sendQuery(request); err != nil {
+			sendErr = err
+			continue
+		}
+		sent++
 	}
-	
-	// Esperar respuesta con timeout
-	select {
-	case response := <-responseChan:
-		return &response, nil
-	case <-time.After(timeout):
-		return nil, fmt.Errorf("timeout esperando respuesta para query: %s", path)
-	case <-qh.ctx.Done():
-		return nil, fmt.Errorf("contexto cancelado")
+	if sent == 0 {
+		return nil, fmt.Errorf("error enviando query: %w", sendErr)
+	}
+
+	timer := time.NewTimer(opts.Timeout)
+	defer timer.Stop()
+
+	// Solo se aceptan respuestas de los peers consultados (identidad verificada por el mesh bridge)
+	targeted := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		targeted[strings.ToLower(peer)] = true
+	}
+
+	// Esperar la primera respuesta válida
+	answers := 0
+	answered := make(map[string]bool, len(peers))
+	var lastErr error
+	for {
+		select {
+		case response := <-responseChan:
+			from := strings.ToLower(response.From)
+			if len(peers) > 0 && (!targeted[from] || answered[from]) {
+				log.Printf("⚠️ Respuesta de %s para query %s descartada: peer no consultado", response.From, path)
+				continue
+			}
+			answers++
+			answered[from] = true
+			if err := qh.validateResponse(path, &response, opts.Validate); err != nil {
+				lastErr = fmt.Errorf("respuesta inválida de %s para query %s: %w", response.From, path, err)
+				log.Printf("⚠️ %v", lastErr)
+
+				// Si todos los peers consultados respondieron mal, pasar al siguiente intento
+				if len(peers) > 0 && answers >= sent {
+					return nil, lastErr
+				}
+				continue
+			}
+			return &response, nil
+
+		case <-timer.C:
+			// Penalizar a los peers consultados que no respondieron a tiempo
+			if scorer != nil {
+				for _, peer := range peers {
+					if peer = strings.ToLower(peer); !answered[peer] {
+						scorer.Penalize(peer, MisbehaviorTimeout, fmt.Errorf("sin respuesta a query %s", path))
+					}
+				}
+			}
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, fmt.Errorf("timeout esperando respuesta para query: %s", path)
+
+		case <-qh.ctx.Done():
+			return nil, errQueryCancelled
+		}
+	}
+}
+
+// validateResponse verifica una respuesta: sin error reportado por el peer y, para
+// "block/N", que el bloque sea de la altura pedida y su hash coincida con el conocido
+func (qh *QueryHandler) validateResponse(path string, response *QueryResponse, validate func(*QueryResponse) error) error {
+	if response.Error != "" {
+		return errors.New(response.Error)
+	}
+
+	if strings.HasPrefix(path, "block/") {
+		height, err := strconv.ParseUint(path[len("block/"):], 10, 64)
+		if err == nil {
+			var block consensus.Block
+			if err := json.Unmarshal(response.Data, &block); err != nil {
+				return fmt.Errorf("bloque mal formado: %w", err)
+			}
+			if block.Header.Height != height {
+				return fmt.Errorf("bloque de altura %d, se pidió %d", block.Header.Height, height)
+			}
+			// El hash cubre el header y los roots cubren el contenido: un bloque alterado no pasa
+			if err := block.VerifyHeader(); err != nil {
+				return fmt.Errorf("bloque inconsistente: %w", err)
+			}
+
+			qh.mu.RLock()
+			knownHash := qh.knownHash
+			qh.mu.RUnlock()
+			if knownHash != nil {
+				if hash, ok := knownHash(height); ok && !strings.EqualFold(hash, block.Header.Hash) {
+					return fmt.Errorf("hash %s no coincide con el conocido %s", block.Header.Hash, hash)
+				}
+			}
+		}
+	}
+
+	if validate != nil {
+		return validate(response)
 	}
+	return nil
 }
 
 // HandleQuery maneja una query recibida de otro nodo
 func (qh *QueryHandler) HandleQuery(request QueryRequest) error {
+	qh.mu.RLock()
+	nodeID := qh.nodeID
+	qh.mu.RUnlock()
+
+	// Ignorar queries dirigidas a otro peer
+	if request.To != "" && nodeID != "" && !strings.EqualFold(request.To, nodeID) {
+		return nil
+	}
+
 	// Procesar query localmente
 	var response QueryResponse
 	
@@ -147,6 +533,9 @@ func (qh *QueryHandler) HandleQuery(request QueryRequest) error {
 		fmt.Sscanf(request.Path[6:], "%d", &height)
 		
 		blockData, err := qh.storage.GetBlock(height)
+		if err == nil {
+			blockData, err = consensus.BlockJSON(blockData)
+		}
 		if err != nil {
 			response = QueryResponse{
 				Type:      "response",
@@ -163,11 +552,38 @@ func (qh *QueryHandler) HandleQuery(request QueryRequest) error {
 			}
 		}
 	
+	case strings.HasPrefix(request.Path, headersPathPrefix):
+		// Lote de headers consecutivos para nodos que sincronizan la cadena por mesh
+		var batch *HeadersBatch
+		from, to, err := parseHeadersPath(request.Path)
+		if err == nil {
+			batch, err = qh.headersBatch(from, to)
+		}
+		if err != nil {
+			response = QueryResponse{
+				Type:      "response",
+				RequestID: request.RequestID,
+				Path:      request.Path,
+				Error:     err.Error(),
+			}
+		} else {
+			data, _ := json.Marshal(batch)
+			response = QueryResponse{
+				Type:      "response",
+				RequestID: request.RequestID,
+				Path:      request.Path,
+				Data:      data,
+			}
+		}
+
 	case len(request.Path) > 3 && request.Path[:3] == "tx/":
 		// Extraer hash de transacción
 		txHash := request.Path[3:]
 		
 		txData, err := qh.storage.GetTransaction(txHash)
+		if err == nil {
+			txData, err = consensus.TransactionJSON(txData)
+		}
 		if err != nil {
 			response = QueryResponse{
 				Type:      "response",
@@ -217,20 +633,23 @@ func (qh *QueryHandler) HandleQuery(request QueryRequest) error {
 		}
 	}
 	
-	// Enviar respuesta por mesh
+	// Enviar respuesta por mesh identificando a este nodo
+	response.From = nodeID
+	response.wireProto = request.wireProto
 	return qh.sendResponse(response)
 }
 
 // HandleResponse maneja una respuesta recibida de otro nodo
+// response.From debe ser la identidad verificada del remitente, no la declarada en el payload
 func (qh *QueryHandler) HandleResponse(response QueryResponse) {
 	qh.mu.RLock()
-	responseChan, exists := qh.pendingQueries[response.RequestID]
+	pending, exists := qh.pendingQueries[response.RequestID]
 	qh.mu.RUnlock()
 	
 	if exists {
 		// Enviar respuesta al canal correspondiente
 		select {
-		case responseChan <- response:
+		case pending.responses <- response:
 		default:
 			// Canal cerrado o buffer lleno, ignorar
 		}
@@ -244,17 +663,32 @@ func (qh *QueryHandler) sendQuery(request QueryRequest) error {
 		return fmt.Errorf("error serializando query: %w", err)
 	}
 	
+	if qh.meshBridge == nil {
+		return fmt.Errorf("mesh bridge no configurado")
+	}
+
 	// Usar mesh_bridge para enviar mensaje
 	return qh.meshBridge.sendQueryMessage(requestData)
 }
 
 // sendResponse envía una respuesta por mesh network
+// Una respuesta protobuf solo se envía si la conexión negoció frames protobuf: en JSON el payload debe ser JSON
 func (qh *QueryHandler) sendResponse(response QueryResponse) error {
-	responseData, err := json.Marshal(response)
+	if qh.meshBridge == nil {
+		return fmt.Errorf("mesh bridge no configurado")
+	}
+
+	var responseData []byte
+	var err error
+	if response.wireProto && qh.meshBridge.currentCodec().Encoding == EncodingProto {
+		responseData, err = encodeWireResponse(response)
+	} else {
+		responseData, err = json.Marshal(response)
+	}
 	if err != nil {
 		return fmt.Errorf("error serializando respuesta: %w", err)
 	}
-	
+
 	// Usar mesh_bridge para enviar mensaje
 	return qh.meshBridge.sendResponseMessage(responseData)
 }
@@ -272,10 +706,10 @@ func generateRequestID() string {
 
 // getAccountState obtiene el estado de una cuenta (helper)
 func (qh *QueryHandler) getAccountState(address string) (map[string]interface{}, error) {
-	// Intentar obtener desde el executor EVM si está disponible
+	// Intentar obtener desde el executor EVM si está disponible (último estado confirmado)
 	executor := qh.consensus.GetExecutor()
 	if executor != nil {
-		accountState, err := executor.GetState(address)
+		accountState, err := executor.GetCommittedState(address)
 		if err == nil && accountState != nil {
 			return map[string]interface{}{
 				"address":  accountState.Address,
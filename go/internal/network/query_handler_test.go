@@ -3,10 +3,12 @@ package network
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
 	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
 )
 
@@ -195,7 +197,7 @@ func TestQueryHandler_HandleResponse(t *testing.T) {
 	requestID := "test-request-6"
 	
 	handler.mu.Lock()
-	handler.pendingQueries[requestID] = responseChan
+	handler.pendingQueries[requestID] = &pendingQuery{responses: responseChan, deadline: time.Now().Add(time.Second)}
 	handler.mu.Unlock()
 	
 	// Crear respuesta
@@ -248,3 +250,288 @@ func TestQueryHandler_HandleResponse_NoPending(t *testing.T) {
 	handler.HandleResponse(response)
 }
 
+// newTestQueryHandler crea un handler con un mesh bridge desconectado:
+// las queries enviadas quedan en el buffer del bridge y se pueden inspeccionar
+func newTestQueryHandler(t *testing.T) (*QueryHandler, *MeshBridge) {
+	meshBridge := NewMeshBridge(context.Background(), nil, "ws://localhost:3001", nil)
+	handler := NewQueryHandler(context.Background(), nil, nil, meshBridge)
+	handler.SetNodeID("self")
+	return handler, meshBridge
+}
+
+// waitForRequests espera a que se hayan enviado n queries y las retorna
+func waitForRequests(t *testing.T, meshBridge *MeshBridge, n int) []QueryRequest {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		meshBridge.outboxMutex.Lock()
+		msgs := append([]*MeshMessage{}, meshBridge.outbox...)
+		meshBridge.outboxMutex.Unlock()
+
+		if len(msgs) >= n {
+			requests := make([]QueryRequest, 0, len(msgs))
+			for _, msg := range msgs {
+				var request QueryRequest
+				json.Unmarshal(msg.Data, &request)
+				requests = append(requests, request)
+			}
+			return requests
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Esperadas %d queries enviadas, hay %d", n, len(msgs))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestQueryHandler_TimeoutCleanup verifica que las queries sin respuesta no quedan pendientes
+func TestQueryHandler_TimeoutCleanup(t *testing.T) {
+	handler, _ := newTestQueryHandler(t)
+
+	_, err := handler.QueryWithOptions("height", QueryOptions{Timeout: 20 * time.Millisecond, Retries: 1, FanOut: 2})
+	if err == nil {
+		t.Fatal("Query sin respuesta debería fallar")
+	}
+	if handler.PendingQueries() != 0 {
+		t.Errorf("No deberían quedar queries pendientes, hay %d", handler.PendingQueries())
+	}
+
+	// Las queries vencidas se limpian aunque el solicitante no las haya retirado
+	handler.pendingQueries["vencida"] = &pendingQuery{responses: make(chan QueryResponse, 1), deadline: time.Now().Add(-time.Second)}
+	handler.pendingQueries["vigente"] = &pendingQuery{responses: make(chan QueryResponse, 1), deadline: time.Now().Add(time.Minute)}
+	if removed := handler.cleanupExpired(time.Now()); removed != 1 {
+		t.Errorf("Esperada 1 query eliminada, obtenidas %d", removed)
+	}
+	if _, ok := handler.pendingQueries["vigente"]; !ok {
+		t.Error("La query vigente no debería eliminarse")
+	}
+}
+
+// TestQueryHandler_FanOutFirstValid verifica que se consulta a k peers y gana la primera respuesta válida
+func TestQueryHandler_FanOutFirstValid(t *testing.T) {
+	handler, meshBridge := newTestQueryHandler(t)
+	for _, peer := range []string{"peer-a", "peer-b", "peer-c", "peer-d"} {
+		handler.ObservePeer(peer)
+		time.Sleep(time.Millisecond)
+	}
+
+	go func() {
+		requests := waitForRequests(t, meshBridge, 3)
+		requestID := requests[0].RequestID
+		handler.HandleResponse(QueryResponse{RequestID: requestID, Path: "height", From: requests[0].To, Error: "no disponible"})
+		handler.HandleResponse(QueryResponse{RequestID: requestID, Path: "height", From: requests[1].To, Data: json.RawMessage(`{"height":10}`)})
+	}()
+
+	response, err := handler.QueryWithOptions("height", QueryOptions{Timeout: time.Second, FanOut: 3})
+	if err != nil {
+		t.Fatalf("Query debería resolverse: %v", err)
+	}
+
+	requests := waitForRequests(t, meshBridge, 3)
+	if len(requests) != 3 {
+		t.Fatalf("Esperadas 3 queries (fan-out), obtenidas %d", len(requests))
+	}
+	// Se consulta primero a los peers vistos más recientemente
+	if requests[0].To != "peer-d" || requests[1].To != "peer-c" || requests[2].To != "peer-b" {
+		t.Errorf("Peers consultados inesperados: %s, %s, %s", requests[0].To, requests[1].To, requests[2].To)
+	}
+	if response.From != requests[1].To {
+		t.Errorf("Se esperaba la respuesta válida de %s, obtenida de %s", requests[1].To, response.From)
+	}
+}
+
+// TestQueryHandler_RetryAlternatePeers verifica que los reintentos van a peers no consultados
+func TestQueryHandler_RetryAlternatePeers(t *testing.T) {
+	handler, meshBridge := newTestQueryHandler(t)
+	handler.ObservePeer("peer-a")
+	time.Sleep(time.Millisecond)
+	handler.ObservePeer("peer-b")
+
+	go func() {
+		// El primer peer no responde; el segundo sí
+		requests := waitForRequests(t, meshBridge, 2)
+		handler.HandleResponse(QueryResponse{RequestID: requests[1].RequestID, Path: "height", From: requests[1].To, Data: json.RawMessage(`{"height":3}`)})
+	}()
+
+	response, err := handler.QueryWithOptions("height", QueryOptions{Timeout: 100 * time.Millisecond, Retries: 2, FanOut: 1})
+	if err != nil {
+		t.Fatalf("Query debería resolverse en el reintento: %v", err)
+	}
+
+	requests := waitForRequests(t, meshBridge, 2)
+	if requests[0].To != "peer-b" || requests[1].To != "peer-a" {
+		t.Errorf("Reintento debería ir a otro peer: %s -> %s", requests[0].To, requests[1].To)
+	}
+	if requests[0].RequestID == requests[1].RequestID {
+		t.Error("Cada intento debería usar un request ID distinto")
+	}
+	if response.From != "peer-a" {
+		t.Errorf("Respuesta esperada de peer-a, obtenida de %s", response.From)
+	}
+}
+
+// TestQueryHandler_ValidateKnownHash verifica que se descartan bloques con hash distinto al conocido
+func TestQueryHandler_ValidateKnownHash(t *testing.T) {
+	handler, _ := newTestQueryHandler(t)
+	handler.SetKnownHashProvider(func(height uint64) (string, bool) {
+		if height == 5 {
+			return "0xAB", true
+		}
+		return "", false
+	})
+
+	block := func(height uint64, hash string) json.RawMessage {
+		data, _ := json.Marshal(&consensus.Block{Header: consensus.BlockHeader{Height: height, Hash: hash}})
+		return data
+	}
+
+	if err := handler.validateResponse("block/5", &QueryResponse{Data: block(5, "0xab")}, nil); err != nil {
+		t.Errorf("Bloque con hash conocido debería aceptarse: %v", err)
+	}
+	if err := handler.validateResponse("block/5", &QueryResponse{Data: block(5, "0xbad")}, nil); err == nil {
+		t.Error("Bloque con hash distinto al conocido debería rechazarse")
+	}
+	if err := handler.validateResponse("block/5", &QueryResponse{Data: block(6, "0xab")}, nil); err == nil {
+		t.Error("Bloque de otra altura debería rechazarse")
+	}
+	if err := handler.validateResponse("block/7", &QueryResponse{Data: block(7, "0xcd")}, nil); err != nil {
+		t.Errorf("Bloque sin hash conocido debería aceptarse: %v", err)
+	}
+}
+
+// TestQueryHandler_IgnoresQueriesForOtherPeers verifica que solo se responden queries dirigidas a este nodo
+func TestQueryHandler_IgnoresQueriesForOtherPeers(t *testing.T) {
+	handler, meshBridge := newTestQueryHandler(t)
+
+	if err := handler.HandleQuery(QueryRequest{Type: "query", Path: "desconocido", RequestID: "r1", To: "otro", From: "peer-a"}); err != nil {
+		t.Fatalf("Query para otro peer no debería fallar: %v", err)
+	}
+	if meshBridge.BufferedMessages() != 0 {
+		t.Error("No debería responderse una query dirigida a otro peer")
+	}
+
+	if err := handler.HandleQuery(QueryRequest{Type: "query", Path: "desconocido", RequestID: "r2", To: "self", From: "peer-a"}); err != nil {
+		t.Fatalf("Error respondiendo query: %v", err)
+	}
+	if meshBridge.BufferedMessages() != 1 {
+		t.Error("Debería responderse la query dirigida a este nodo")
+	}
+
+	// El solicitante declarado en el payload no se registra: solo cuentan identidades verificadas
+	if peers := handler.KnownPeers(); len(peers) != 0 {
+		t.Errorf("Peers conocidos inesperados: %v", peers)
+	}
+}
+
+// TestQueryHandler_DropsUntargetedResponses verifica que solo cuentan las respuestas de los peers consultados
+func TestQueryHandler_DropsUntargetedResponses(t *testing.T) {
+	handler, meshBridge := newTestQueryHandler(t)
+	handler.ObservePeer("peer-a")
+
+	go func() {
+		requests := waitForRequests(t, meshBridge, 1)
+		requestID := requests[0].RequestID
+		handler.HandleResponse(QueryResponse{RequestID: requestID, Path: "height", From: "intruso", Data: json.RawMessage(`{"height":99}`)})
+		handler.HandleResponse(QueryResponse{RequestID: requestID, Path: "height", From: "", Data: json.RawMessage(`{"height":98}`)})
+		handler.HandleResponse(QueryResponse{RequestID: requestID, Path: "height", From: "PEER-A", Data: json.RawMessage(`{"height":3}`)})
+	}()
+
+	response, err := handler.QueryWithOptions("height", QueryOptions{Timeout: time.Second, FanOut: 1})
+	if err != nil {
+		t.Fatalf("Query debería resolverse con la respuesta del peer consultado: %v", err)
+	}
+	if string(response.Data) != `{"height":3}` {
+		t.Errorf("Se aceptó la respuesta de un peer no consultado: %s", response.Data)
+	}
+}
+
+// TestQueryHandler_TotalTimeout verifica que los reintentos no extienden la query más allá del timeout total
+func TestQueryHandler_TotalTimeout(t *testing.T) {
+	handler, meshBridge := newTestQueryHandler(t)
+	for _, peer := range []string{"peer-a", "peer-b", "peer-c"} {
+		handler.ObservePeer(peer)
+	}
+
+	start := time.Now()
+	_, err := handler.QueryWithOptions("height", QueryOptions{
+		Timeout:      100 * time.Millisecond,
+		TotalTimeout: 150 * time.Millisecond,
+		Retries:      2,
+		FanOut:       1,
+	})
+	if err == nil {
+		t.Fatal("Query sin respuesta debería fallar")
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("La query debería terminar cerca de 150ms, tardó %v", elapsed)
+	}
+	if sent := meshBridge.BufferedMessages(); sent != 2 {
+		t.Errorf("Esperados 2 intentos dentro del timeout total, enviados %d", sent)
+	}
+}
+
+// TestQueryHandler_QueryBlockRequiresKnownHash verifica que solo se piden bloques con hash anunciado por validadores
+func TestQueryHandler_QueryBlockRequiresKnownHash(t *testing.T) {
+	handler, meshBridge := newTestQueryHandler(t)
+
+	if _, err := handler.QueryBlock(5, 50*time.Millisecond); !errors.Is(err, ErrUnknownBlockHash) {
+		t.Errorf("Sin hashes conocidos la query debería rechazarse, error: %v", err)
+	}
+
+	handler.SetKnownHashProvider(func(height uint64) (string, bool) {
+		return "0xab", height == 5
+	})
+	if _, err := handler.QueryBlock(6, 50*time.Millisecond); !errors.Is(err, ErrUnknownBlockHash) {
+		t.Errorf("Bloque sin hash conocido debería rechazarse, error: %v", err)
+	}
+	if meshBridge.BufferedMessages() != 0 {
+		t.Error("No deberían enviarse queries para bloques sin hash conocido")
+	}
+
+	handler.ObservePeer("peer-a")
+	go func() {
+		requests := waitForRequests(t, meshBridge, 1)
+		data, _ := json.Marshal(&consensus.Block{Header: consensus.BlockHeader{Height: 5, Hash: "0xAB"}})
+		handler.HandleResponse(QueryResponse{RequestID: requests[0].RequestID, Path: requests[0].Path, From: "peer-a", Data: data})
+	}()
+
+	block, err := handler.QueryBlock(5, time.Second)
+	if err != nil {
+		t.Fatalf("Bloque con hash conocido debería obtenerse: %v", err)
+	}
+	if block.Header.Height != 5 {
+		t.Errorf("Bloque inesperado: %+v", block.Header)
+	}
+}
+
+// TestQueryHandler_StartOnce verifica que reiniciar el mesh bridge no lanza otra limpieza de queries
+func TestQueryHandler_StartOnce(t *testing.T) {
+	handler, _ := newTestQueryHandler(t)
+
+	started := 0
+	for i := 0; i < 3; i++ {
+		handler.Start()
+		handler.startOnce.Do(func() { started++ })
+	}
+	if started != 0 {
+		t.Error("Start debería lanzar la limpieza en la primera llamada y no repetirla")
+	}
+}
+
+// TestMeshBridge_ResponseUsesVerifiedSender verifica que el remitente declarado en una respuesta se ignora
+func TestMeshBridge_ResponseUsesVerifiedSender(t *testing.T) {
+	handler, meshBridge := newTestQueryHandler(t)
+	meshBridge.queryHandler = handler
+
+	responseChan := make(chan QueryResponse, 1)
+	handler.pendingQueries["r1"] = &pendingQuery{responses: responseChan, deadline: time.Now().Add(time.Second)}
+
+	data, _ := json.Marshal(QueryResponse{Type: "response", RequestID: "r1", Path: "height", From: "peer-falso"})
+	if err := meshBridge.handleMessage(&MeshMessage{Type: "response", Data: data}); err != nil {
+		t.Fatalf("Error procesando respuesta: %v", err)
+	}
+
+	received := <-responseChan
+	if received.From != "" {
+		t.Errorf("Sin firma verificada el remitente debería quedar vacío, obtenido %q", received.From)
+	}
+}
@@ -0,0 +1,219 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// Topics del relay de transacciones: clientes de la mesh sin acceso al API REST envían transacciones
+// firmadas y reciben por la misma mesh el resultado de la validación y, después, el de su inclusión
+const (
+	TopicTxRelay    = "oxy-blockchain:tx-relay"
+	TopicTxRelayAck = "oxy-blockchain:tx-relay-ack"
+)
+
+// Estados de una transacción recibida por el relay
+const (
+	TxRelayAccepted  = "accepted"  // Validada (CheckTx) y agregada al mempool
+	TxRelayRejected  = "rejected"  // Inválida: Code y Log indican el motivo
+	TxRelayCommitted = "committed" // Incluida en un bloque confirmado: Height y Result
+)
+
+// Valores por defecto del relay
+const (
+	DefaultTxRelayTTL = 10 * time.Minute // Tiempo que se recuerda una transacción aceptada (deduplicación y ack del bloque)
+	maxTxRelayPending = 10000            // Transacciones aceptadas esperando bloque
+)
+
+// ErrTxRelayFull se retorna cuando el relay tiene demasiadas transacciones esperando bloque
+var ErrTxRelayFull = errors.New("relay de transacciones lleno")
+
+// TxRelayRequest es una transacción firmada enviada por un cliente de la mesh
+type TxRelayRequest struct {
+	RequestID   string                 `json:"requestId,omitempty"` // Elegido por el cliente; se repite en los acks
+	Transaction *consensus.Transaction `json:"transaction"`
+}
+
+// TxRelayAck es la respuesta del nodo a una transacción del relay. Se publica en TopicTxRelayAck:
+// el cliente la reconoce por RequestID o Hash
+type TxRelayAck struct {
+	RequestID string `json:"requestId,omitempty"`
+	Hash      string `json:"hash"`
+	Status    string `json:"status"`           // accepted, rejected o committed
+	Code      uint32 `json:"code,omitempty"`   // rejected o committed fallida: código de error (ver consensus.Code*)
+	Reason    string `json:"reason,omitempty"` // rejected o committed fallida: razón del código (ej: "invalid_nonce")
+	Log       string `json:"log,omitempty"`
+	Height    uint64 `json:"height,omitempty"` // committed: bloque que incluyó la transacción
+	Result    string `json:"result,omitempty"` // committed: "success" o "failed" según el resultado de la ejecución
+	To        string `json:"to,omitempty"`     // Identidad mesh del cliente (vacía sin mensajes firmados)
+}
+
+// relayedTx es una transacción aceptada por el relay que espera bloque
+type relayedTx struct {
+	requestID string
+	client    string
+	accepted  time.Time
+}
+
+// TxRelay valida e inyecta en el mempool local las transacciones que llegan por la mesh, descarta las
+// repetidas y responde a cada cliente con el estado de su transacción
+type TxRelay struct {
+	submit  func(*consensus.Transaction) error // Valida (CheckTx) y agrega al mempool
+	publish func(*TxRelayAck) error
+	ttl     time.Duration
+	pending map[string]*relayedTx // Por hash en minúsculas
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// NewTxRelay crea el relay (ttl 0 usa DefaultTxRelayTTL)
+func NewTxRelay(submit func(*consensus.Transaction) error, publish func(*TxRelayAck) error, ttl time.Duration) *TxRelay {
+	if ttl <= 0 {
+		ttl = DefaultTxRelayTTL
+	}
+	return &TxRelay{
+		submit:  submit,
+		publish: publish,
+		ttl:     ttl,
+		pending: make(map[string]*relayedTx),
+		now:     time.Now,
+	}
+}
+
+// checkAndSubmit agrega transacciones al mempool local solo si pasan CheckTx, para poder rechazarlas
+// al cliente con el código de error
+func checkAndSubmit(ctx context.Context, engine *consensus.CometBFT) func(*consensus.Transaction) error {
+	return func(tx *consensus.Transaction) error {
+		_, err := engine.BroadcastTransaction(ctx, tx, consensus.BroadcastSync)
+		return err
+	}
+}
+
+// HandleRequest valida la transacción de un cliente y le responde si se aceptó o se rechazó
+// Una transacción ya aceptada que todavía espera bloque no se reenvía al mempool: se vuelve a confirmar
+// (el cliente reintenta si no recibió el ack). Una rechazada se puede reenviar
+// El hash se recalcula de los campos antes de deduplicar: un hash que no corresponde a la transacción se
+// rechaza, así que un cliente no puede ocupar el hash de otra transacción
+func (r *TxRelay) HandleRequest(req *TxRelayRequest, client string) error {
+	if req.Transaction == nil || req.Transaction.Hash == "" {
+		return fmt.Errorf("%w: transacción del relay sin hash", ErrInvalidPayload)
+	}
+	tx := req.Transaction
+	ack := &TxRelayAck{RequestID: req.RequestID, Hash: tx.Hash, To: client}
+
+	expected, err := consensus.TransactionHash(tx)
+	if err == nil && tx.Hash != expected {
+		err = fmt.Errorf("hash de transacción inválido: esperado %s, tiene %s", expected, tx.Hash)
+	}
+	if err != nil {
+		ack.Status = TxRelayRejected
+		ack.Code = consensus.CodeInvalidHash
+		ack.Reason = consensus.CodeReason(ack.Code)
+		ack.Log = err.Error()
+		return r.publish(ack)
+	}
+	key := strings.ToLower(expected)
+
+	r.mu.Lock()
+	r.prune()
+	if _, exists := r.pending[key]; exists {
+		r.mu.Unlock()
+		ack.Status = TxRelayAccepted
+		return r.publish(ack)
+	}
+	if len(r.pending) >= maxTxRelayPending {
+		r.mu.Unlock()
+		return ErrTxRelayFull
+	}
+	r.pending[key] = &relayedTx{requestID: req.RequestID, client: client, accepted: r.now()}
+	r.mu.Unlock()
+
+	if err := r.submit(tx); err != nil {
+		r.mu.Lock()
+		delete(r.pending, key)
+		r.mu.Unlock()
+		ack.Status = TxRelayRejected
+		ack.Code = consensus.ErrorCode(err, consensus.CodeInvalidTx)
+		ack.Reason = consensus.CodeReason(ack.Code)
+		ack.Log = err.Error()
+	} else {
+		ack.Status = TxRelayAccepted
+		log.Printf("📥 Transacción recibida por el relay de la mesh: %s", tx.Hash)
+	}
+	return r.publish(ack)
+}
+
+// HandleTxResults responde a los clientes cuyas transacciones se incluyeron en un bloque confirmado, con el
+// resultado de su ejecución: también las fallidas, que no tienen recibo (handler de AddTxResultsHandler:
+// los acks se publican fuera de Commit)
+func (r *TxRelay) HandleTxResults(results map[string]*consensus.TxCommitResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	var acks []*TxRelayAck
+	r.mu.Lock()
+	for hash, result := range results {
+		if result == nil {
+			continue
+		}
+		key := strings.ToLower(hash)
+		relayed, ok := r.pending[key]
+		if !ok {
+			continue
+		}
+		delete(r.pending, key)
+		ack := &TxRelayAck{
+			RequestID: relayed.requestID,
+			Hash:      hash,
+			Status:    TxRelayCommitted,
+			Height:    result.Height,
+			Result:    "success",
+			To:        relayed.client,
+		}
+		if result.Code != consensus.CodeOK {
+			ack.Result = "failed"
+			ack.Code = result.Code
+			ack.Reason = consensus.CodeReason(result.Code)
+			ack.Log = result.Log
+		}
+		acks = append(acks, ack)
+	}
+	r.mu.Unlock()
+
+	if len(acks) == 0 {
+		return
+	}
+	go func() {
+		for _, ack := range acks {
+			if err := r.publish(ack); err != nil {
+				log.Printf("⚠️ Error confirmando transacción %s por el relay de la mesh: %v", ack.Hash, err)
+			}
+		}
+	}()
+}
+
+// Pending retorna la cantidad de transacciones aceptadas que esperan bloque
+func (r *TxRelay) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// prune olvida las transacciones aceptadas hace más del TTL (requiere lock)
+// El cliente puede seguir su estado por los anuncios de transacciones o reenviarla
+func (r *TxRelay) prune() {
+	now := r.now()
+	for key, relayed := range r.pending {
+		if now.Sub(relayed.accepted) > r.ttl {
+			delete(r.pending, key)
+		}
+	}
+}
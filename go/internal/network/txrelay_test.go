@@ -0,0 +1,173 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+)
+
+// relayRecorder guarda los acks publicados por el relay
+type relayRecorder struct {
+	mu   sync.Mutex
+	acks []*TxRelayAck
+}
+
+func (r *relayRecorder) publish(ack *TxRelayAck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.acks = append(r.acks, ack)
+	return nil
+}
+
+func (r *relayRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.acks)
+}
+
+// byHash retorna el último ack publicado de cada transacción
+func (r *relayRecorder) byHash() map[string]*TxRelayAck {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	acks := make(map[string]*TxRelayAck, len(r.acks))
+	for _, ack := range r.acks {
+		acks[ack.Hash] = ack
+	}
+	return acks
+}
+
+func (r *relayRecorder) last() *TxRelayAck {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.acks) == 0 {
+		return nil
+	}
+	return r.acks[len(r.acks)-1]
+}
+
+// relayTx crea una transacción con el hash de sus campos
+func relayTx(t *testing.T, nonce uint64) *consensus.Transaction {
+	t.Helper()
+	tx := &consensus.Transaction{
+		From:     "0x1111111111111111111111111111111111111111",
+		To:       "0x2222222222222222222222222222222222222222",
+		Value:    "1",
+		GasLimit: 21000,
+		GasPrice: "1",
+		Nonce:    nonce,
+	}
+	hash, err := consensus.TransactionHash(tx)
+	if err != nil {
+		t.Fatalf("Error calculando hash: %v", err)
+	}
+	tx.Hash = hash
+	return tx
+}
+
+// TestTxRelayRequests verifica la validación, la deduplicación y los acks de aceptación y rechazo
+func TestTxRelayRequests(t *testing.T) {
+	submitted := 0
+	submit := func(tx *consensus.Transaction) error {
+		submitted++
+		if tx.Nonce == 99 {
+			return consensus.NewTxError(consensus.CodeInvalidNonce, "nonce inválido")
+		}
+		return nil
+	}
+	recorder := &relayRecorder{}
+	relay := NewTxRelay(submit, recorder.publish, time.Minute)
+	now := time.Unix(1700000000, 0)
+	relay.now = func() time.Time { return now }
+
+	if err := relay.HandleRequest(&TxRelayRequest{RequestID: "r0"}, "0xclient"); !errors.Is(err, ErrInvalidPayload) {
+		t.Errorf("Una solicitud sin transacción debería rechazarse: %v", err)
+	}
+
+	tx := relayTx(t, 1)
+	if err := relay.HandleRequest(&TxRelayRequest{RequestID: "r1", Transaction: tx}, "0xclient"); err != nil {
+		t.Fatalf("Error procesando transacción: %v", err)
+	}
+	ack := recorder.last()
+	if ack == nil || ack.Status != TxRelayAccepted || ack.RequestID != "r1" || ack.To != "0xclient" {
+		t.Fatalf("Ack de aceptación incorrecto: %+v", ack)
+	}
+
+	// Reintento del cliente: se confirma de nuevo sin reenviar al mempool
+	retry := *tx
+	if err := relay.HandleRequest(&TxRelayRequest{RequestID: "r2", Transaction: &retry}, "0xclient"); err != nil {
+		t.Fatalf("Error procesando reintento: %v", err)
+	}
+	if submitted != 1 || recorder.last().Status != TxRelayAccepted || recorder.last().RequestID != "r2" {
+		t.Errorf("El reintento no debería reenviarse: %d envíos, ack %+v", submitted, recorder.last())
+	}
+
+	// Un hash que no corresponde a los campos se rechaza sin llegar al mempool ni ocupar ese hash
+	forged := relayTx(t, 2)
+	forged.Hash = relayTx(t, 3).Hash
+	if err := relay.HandleRequest(&TxRelayRequest{Transaction: forged}, ""); err != nil {
+		t.Fatalf("Error procesando transacción con hash falso: %v", err)
+	}
+	ack = recorder.last()
+	if ack.Status != TxRelayRejected || ack.Code != consensus.CodeInvalidHash || submitted != 1 {
+		t.Errorf("Una transacción con hash falso debería rechazarse sin enviarse: %d envíos, ack %+v", submitted, ack)
+	}
+
+	bad := relayTx(t, 99)
+	if err := relay.HandleRequest(&TxRelayRequest{Transaction: bad}, ""); err != nil {
+		t.Fatalf("Error procesando transacción inválida: %v", err)
+	}
+	ack = recorder.last()
+	if ack.Status != TxRelayRejected || ack.Code != consensus.CodeInvalidNonce || ack.Reason != consensus.CodeReason(consensus.CodeInvalidNonce) {
+		t.Errorf("Ack de rechazo incorrecto: %+v", ack)
+	}
+	if relay.Pending() != 1 {
+		t.Errorf("Una transacción rechazada no debería quedar pendiente: %d", relay.Pending())
+	}
+
+	// Vencido el TTL la transacción se olvida y se puede reenviar
+	now = now.Add(2 * time.Minute)
+	if err := relay.HandleRequest(&TxRelayRequest{Transaction: tx}, "0xclient"); err != nil || submitted != 3 {
+		t.Errorf("La transacción vencida debería reenviarse: %d envíos, %v", submitted, err)
+	}
+}
+
+// TestTxRelayCommitted verifica el ack de inclusión con el resultado de la ejecución, también de las
+// transacciones fallidas (que no tienen recibo)
+func TestTxRelayCommitted(t *testing.T) {
+	recorder := &relayRecorder{}
+	relay := NewTxRelay(func(*consensus.Transaction) error { return nil }, recorder.publish, 0)
+	ok := relayTx(t, 1)
+	failed := relayTx(t, 2)
+	for i, tx := range []*consensus.Transaction{ok, failed} {
+		if err := relay.HandleRequest(&TxRelayRequest{RequestID: fmt.Sprintf("r%d", i), Transaction: tx}, "0xclient"); err != nil {
+			t.Fatalf("Error procesando transacción: %v", err)
+		}
+	}
+
+	relay.HandleTxResults(map[string]*consensus.TxCommitResult{
+		"0xother":   {Height: 7},
+		ok.Hash:     {Height: 7, GasUsed: 21000},
+		failed.Hash: {Height: 7, Code: consensus.CodeExecutionFailed, Log: "execution reverted"},
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for relay.Pending() != 0 || recorder.count() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Faltan acks de inclusión: %d pendientes, %d acks", relay.Pending(), recorder.count())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	acks := recorder.byHash()
+	if ack := acks[ok.Hash]; ack.Status != TxRelayCommitted || ack.Height != 7 || ack.Result != "success" || ack.RequestID != "r0" || ack.To != "0xclient" {
+		t.Errorf("Ack de inclusión incorrecto: %+v", ack)
+	}
+	ack := acks[failed.Hash]
+	if ack.Status != TxRelayCommitted || ack.Result != "failed" || ack.Code != consensus.CodeExecutionFailed ||
+		ack.Reason != consensus.CodeReason(consensus.CodeExecutionFailed) || ack.Log != "execution reverted" || ack.RequestID != "r1" {
+		t.Errorf("Ack de transacción fallida incorrecto: %+v", ack)
+	}
+}
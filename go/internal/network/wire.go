@@ -0,0 +1,114 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/wire"
+)
+
+// Las queries y respuestas por mesh viajan en JSON (entre nodos) o en protobuf con los tipos de types.proto
+// (SDKs). Un payload protobuf nunca empieza con '{': sería el campo 15 como grupo, que el esquema no usa
+
+// isJSONPayload retorna si el payload de una query o respuesta está en JSON
+func isJSONPayload(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// decodeQueryRequest decodifica una query recibida, en JSON o protobuf (wire.QueryRequest)
+// Una query en protobuf se responde en protobuf si la conexión lo permite (ver sendResponse)
+func decodeQueryRequest(data []byte) (QueryRequest, error) {
+	var request QueryRequest
+	if isJSONPayload(data) {
+		err := json.Unmarshal(data, &request)
+		return request, err
+	}
+
+	var msg wire.QueryRequest
+	if err := msg.Unmarshal(data); err != nil {
+		return request, err
+	}
+	return QueryRequest{
+		Type:      msg.Type,
+		Path:      msg.Path,
+		RequestID: msg.RequestID,
+		From:      msg.From,
+		To:        msg.To,
+		wireProto: true,
+	}, nil
+}
+
+// decodeQueryResponse decodifica una respuesta recibida, en JSON o protobuf (wire.QueryResponse)
+// El bloque o la transacción de una respuesta protobuf se convierten a JSON en Data, como los envía un nodo
+func decodeQueryResponse(data []byte) (QueryResponse, error) {
+	var response QueryResponse
+	if isJSONPayload(data) {
+		err := json.Unmarshal(data, &response)
+		return response, err
+	}
+
+	var msg wire.QueryResponse
+	if err := msg.Unmarshal(data); err != nil {
+		return response, err
+	}
+	response = QueryResponse{
+		Type:      msg.Type,
+		RequestID: msg.RequestID,
+		Path:      msg.Path,
+		Error:     msg.Error,
+		From:      msg.From,
+	}
+
+	var err error
+	switch {
+	case msg.Block != nil:
+		var block *consensus.Block
+		if block, err = consensus.BlockFromWire(msg.Block); err != nil {
+			return response, err
+		}
+		response.Data, err = json.Marshal(block)
+	case msg.Transaction != nil:
+		response.Data, err = json.Marshal(consensus.TransactionFromWire(msg.Transaction))
+	case len(msg.Data) > 0:
+		if !json.Valid(msg.Data) {
+			return response, fmt.Errorf("data no es JSON válido")
+		}
+		response.Data = msg.Data
+	}
+	return response, err
+}
+
+// encodeWireResponse codifica una respuesta en protobuf (wire.QueryResponse)
+// Los bloques y transacciones van tipados; el resto de los paths conserva el payload JSON en data
+func encodeWireResponse(response QueryResponse) ([]byte, error) {
+	msg := &wire.QueryResponse{
+		Type:      response.Type,
+		RequestID: response.RequestID,
+		Path:      response.Path,
+		Error:     response.Error,
+		From:      response.From,
+	}
+
+	switch {
+	case len(response.Data) == 0:
+	case strings.HasPrefix(response.Path, "block/"):
+		block, err := consensus.DecodeBlock(response.Data)
+		if err != nil {
+			return nil, fmt.Errorf("error decodificando bloque: %w", err)
+		}
+		msg.Block = block.ToWire()
+	case strings.HasPrefix(response.Path, "tx/"):
+		tx, err := consensus.DecodeTransaction(response.Data)
+		if err != nil {
+			return nil, fmt.Errorf("error decodificando transacción: %w", err)
+		}
+		msg.Transaction = tx.ToWire()
+	default:
+		msg.Data = response.Data
+	}
+	return msg.Marshal(), nil
+}
@@ -0,0 +1,46 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/wire"
+)
+
+// TestWireQueryPayloads prueba que las queries y respuestas protobuf (types.proto) se decodifiquen igual
+// que las JSON y que los bloques viajen tipados
+func TestWireQueryPayloads(t *testing.T) {
+	request, err := decodeQueryRequest((&wire.QueryRequest{Type: "query", Path: "block/3", RequestID: "req-1", To: "node"}).Marshal())
+	if err != nil || request.Path != "block/3" || request.RequestID != "req-1" || request.To != "node" || !request.wireProto {
+		t.Fatalf("Query protobuf mal decodificada: %+v (%v)", request, err)
+	}
+	if request, err = decodeQueryRequest([]byte(`{"type":"query","path":"height","request_id":"req-2"}`)); err != nil || request.Path != "height" || request.wireProto {
+		t.Fatalf("Query JSON mal decodificada: %+v (%v)", request, err)
+	}
+
+	block := &consensus.Block{Header: consensus.BlockHeader{Height: 3, Hash: "0x03", Timestamp: time.Unix(1700000000, 0).UTC()}}
+	blockData, _ := json.Marshal(block)
+	data, err := encodeWireResponse(QueryResponse{Type: "response", RequestID: "req-1", Path: "block/3", Data: blockData, From: "node"})
+	if err != nil {
+		t.Fatalf("Error codificando respuesta: %v", err)
+	}
+	var msg wire.QueryResponse
+	if err := msg.Unmarshal(data); err != nil || msg.Block == nil || msg.Block.Header.Height != 3 || len(msg.Data) != 0 {
+		t.Fatalf("El bloque debería viajar tipado: %+v (%v)", msg, err)
+	}
+	response, err := decodeQueryResponse(data)
+	if err != nil || response.RequestID != "req-1" || string(response.Data) != string(blockData) {
+		t.Errorf("Respuesta protobuf mal decodificada: %s (%v)", response.Data, err)
+	}
+
+	// Los paths sin tipo propio conservan el payload JSON
+	data, _ = encodeWireResponse(QueryResponse{Type: "response", Path: "height", Data: json.RawMessage(`{"height":3}`)})
+	if response, err = decodeQueryResponse(data); err != nil || string(response.Data) != `{"height":3}` {
+		t.Errorf("Payload JSON no conservado: %s (%v)", response.Data, err)
+	}
+	if _, err := decodeQueryResponse((&wire.QueryResponse{Path: "height", Data: []byte{0xff}}).Marshal()); err == nil {
+		t.Error("Se esperaba un error con data que no es JSON")
+	}
+}
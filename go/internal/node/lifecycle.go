@@ -0,0 +1,45 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// lifecycle registra cómo detener cada componente iniciado, para detenerlos en orden inverso al de inicio
+// (los servidores antes que el consenso, el consenso antes que el ejecutor y el storage al final)
+type lifecycle struct {
+	mu    sync.Mutex
+	stops []stopFunc
+}
+
+// stopFunc detiene un componente
+type stopFunc struct {
+	name string
+	stop func() error
+}
+
+// onStop agrega la detención de un componente recién iniciado
+func (l *lifecycle) onStop(name string, stop func() error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stops = append(l.stops, stopFunc{name: name, stop: stop})
+}
+
+// stopAll detiene los componentes en orden inverso y retorna todos los errores juntos
+// Es idempotente: cada componente se detiene una sola vez
+func (l *lifecycle) stopAll() error {
+	l.mu.Lock()
+	stops := l.stops
+	l.stops = nil
+	l.mu.Unlock()
+
+	var errs []error
+	for i := len(stops) - 1; i >= 0; i-- {
+		if err := stops[i].stop(); err != nil {
+			nodeLog.Errorf("Error deteniendo %s: %v", stops[i].name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", stops[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,59 @@
+package node
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/config"
+)
+
+// TestLifecycleStopOrder prueba que los componentes se detengan en orden inverso y una sola vez
+func TestLifecycleStopOrder(t *testing.T) {
+	var l lifecycle
+	var stopped []string
+	for _, name := range []string{"storage", "ejecutor EVM", "consenso", "API REST"} {
+		name := name
+		l.onStop(name, func() error {
+			stopped = append(stopped, name)
+			if name == "consenso" {
+				return errors.New("timeout")
+			}
+			return nil
+		})
+	}
+
+	err := l.stopAll()
+	if got := strings.Join(stopped, ","); got != "API REST,consenso,ejecutor EVM,storage" {
+		t.Errorf("Orden de detención incorrecto: %s", got)
+	}
+	if err == nil || !strings.Contains(err.Error(), "consenso: timeout") {
+		t.Errorf("Error de detención no reportado: %v", err)
+	}
+
+	if err := l.stopAll(); err != nil || len(stopped) != 4 {
+		t.Errorf("Una segunda detención no debería repetir componentes: %v, %v", err, stopped)
+	}
+}
+
+// TestNewNodeInvalidConfig prueba que un nodo con configuración inválida no se cree
+func TestNewNodeInvalidConfig(t *testing.T) {
+	t.Setenv("OXY_DATA_DIR", t.TempDir())
+	t.Setenv("OXY_CHAIN_ID", "oxy chain")
+	if _, err := NewNode(config.LoadConfig()); err == nil || !strings.Contains(err.Error(), "OXY_CHAIN_ID") {
+		t.Errorf("Se esperaba error de configuración: %v", err)
+	}
+	if _, err := NewNode(nil); err == nil {
+		t.Error("Se esperaba error sin configuración")
+	}
+
+	// Stop sin Start no hace nada
+	t.Setenv("OXY_CHAIN_ID", "oxy-test")
+	node, err := NewNode(config.LoadConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := node.Stop(); err != nil {
+		t.Errorf("Stop sin Start: %v", err)
+	}
+}
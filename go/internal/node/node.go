@@ -0,0 +1,584 @@
+// Package node arma un nodo Oxy•gen completo a partir de la configuración: storage, ejecutor EVM, validadores,
+// consenso CometBFT, red mesh, módulos opcionales (watchlist, eventos, multisig, compliance) y servidores del
+// API. El binario, el nodo de desarrollo y los tests comparten así el mismo cableado
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/api"
+	"github.com/Q-YZX0/oxy-blockchain/internal/assets"
+	"github.com/Q-YZX0/oxy-blockchain/internal/config"
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/eventsink"
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/health"
+	"github.com/Q-YZX0/oxy-blockchain/internal/logger"
+	"github.com/Q-YZX0/oxy-blockchain/internal/metrics"
+	"github.com/Q-YZX0/oxy-blockchain/internal/network"
+	"github.com/Q-YZX0/oxy-blockchain/internal/rediscache"
+	"github.com/Q-YZX0/oxy-blockchain/internal/rosetta"
+	"github.com/Q-YZX0/oxy-blockchain/internal/security"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+	"github.com/Q-YZX0/oxy-blockchain/internal/watchlist"
+)
+
+var nodeLog = logger.For("node")
+
+// MaxValidators es el tamaño máximo del set de validadores
+const MaxValidators = 100
+
+// ForkSetup prepara el ejecutor antes de iniciarlo (fork de una red remota del nodo de desarrollo)
+type ForkSetup func(ctx context.Context, evm *execution.EVMExecutor) error
+
+// Node es un nodo Oxy•gen con todos sus componentes
+// Start los crea e inicia en orden de dependencia y Stop los detiene en orden inverso
+type Node struct {
+	cfg *config.Config
+
+	// Opciones que no vienen de las variables de entorno (se configuran antes de Start)
+	devMode         bool
+	genesisAccounts []consensus.GenesisAccount
+	forkSetup       ForkSetup
+	allowRollback   bool
+
+	mu        sync.Mutex
+	started   bool
+	cancel    context.CancelFunc
+	lifecycle lifecycle
+
+	// Componentes (disponibles después de Start)
+	healthChecker *health.HealthChecker
+	metrics       *metrics.Metrics
+	db            *storage.BlockchainDB
+	evm           *execution.EVMExecutor
+	validators    *consensus.ValidatorSet
+	consensus     *consensus.CometBFT
+	network       *network.P2PNetwork
+	watchlist     *watchlist.Registry
+	multisig      *consensus.MultisigPool
+	compliance    *consensus.ComplianceList
+	restServer    *api.RestServer
+	rosetta       *rosetta.Server
+}
+
+// NewNode crea un nodo con la configuración dada (validada con cfg.Validate)
+// No abre ni inicia nada hasta Start
+func NewNode(cfg *config.Config) (*Node, error) {
+	if cfg == nil {
+		return nil, errors.New("configuración requerida")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuración inválida:\n%w", err)
+	}
+	return &Node{
+		cfg:           cfg,
+		healthChecker: health.NewHealthChecker(),
+		metrics:       metrics.NewMetrics(),
+	}, nil
+}
+
+// SetDevMode habilita el modo desarrollo de CometBFT (bloques a demanda) con las cuentas fondeadas en el genesis
+func (n *Node) SetDevMode(accounts []consensus.GenesisAccount) {
+	n.devMode = true
+	n.genesisAccounts = accounts
+}
+
+// SetForkSetup configura la preparación del ejecutor antes de iniciarlo
+func (n *Node) SetForkSetup(setup ForkSetup) {
+	n.forkSetup = setup
+}
+
+// SetAllowRollback acepta iniciar aunque la cadena guardada haya retrocedido (--allow-rollback)
+func (n *Node) SetAllowRollback(allow bool) {
+	n.allowRollback = allow
+}
+
+// Start crea e inicia los componentes del nodo. Si alguno falla detiene los ya iniciados y retorna el error
+func (n *Node) Start(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.started {
+		return errors.New("el nodo ya está iniciado")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	n.cancel = cancel
+	if err := n.start(ctx); err != nil {
+		n.lifecycle.stopAll()
+		cancel()
+		return err
+	}
+	n.started = true
+	nodeLog.Info("Oxy•gen Blockchain iniciada correctamente")
+	return nil
+}
+
+// Stop detiene los componentes en orden inverso al de inicio y retorna todos los errores juntos
+func (n *Node) Stop() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.started {
+		return nil
+	}
+	n.started = false
+
+	nodeLog.Info("Deteniendo Oxy•gen Blockchain...")
+	err := n.lifecycle.stopAll()
+	n.cancel()
+	return err
+}
+
+// start crea e inicia cada componente registrando su detención
+func (n *Node) start(ctx context.Context) error {
+	cfg := n.cfg
+
+	// Storage
+	nodeLog.Infof("Inicializando storage (DataDir=%s)", cfg.DataDir)
+	db, err := storage.NewBlockchainDB(cfg.DataDir)
+	if err != nil {
+		return fmt.Errorf("error inicializando storage: %w", err)
+	}
+	n.db = db
+	n.lifecycle.onStop("storage", db.Close)
+
+	// Bloques y transacciones guardados en JSON por versiones anteriores pasan a la codificación canónica
+	if _, err := consensus.MigrateLegacyRecords(db); err != nil {
+		return fmt.Errorf("error migrando bloques y transacciones: %w", err)
+	}
+
+	// Un retroceso de la cadena (blockchain.db borrado o restaurado de un backup anterior) invalidaría en
+	// silencio los bloques que los clientes ya vieron: solo se acepta con --allow-rollback
+	reorg, err := consensus.DetectReorg(db)
+	if err != nil {
+		return fmt.Errorf("error verificando la cadena guardada: %w", err)
+	}
+	if reorg != nil {
+		if !n.allowRollback {
+			return fmt.Errorf("%v. Si el retroceso es intencional, reiniciar con --allow-rollback", reorg)
+		}
+		nodeLog.Warnf("Retroceso de la cadena aceptado con --allow-rollback: %v", reorg)
+		if err := consensus.ResetChainHead(db); err != nil {
+			return fmt.Errorf("error registrando el último bloque confirmado: %w", err)
+		}
+	}
+	n.healthChecker.SetStorageHealth(true)
+
+	// Ejecutor EVM
+	evm := execution.NewEVMExecutor(db)
+	evm.SetStateCacheSize(cfg.StateCacheAccounts, cfg.StateCacheSlots)
+	evm.SetStateCommitQueue(cfg.StateCommitQueue)
+	evm.SetStateDBConfig(execution.StateDBConfig{
+		TrieCacheMB:     cfg.StateTrieCacheMB,
+		DatabaseCacheMB: cfg.StateDBCacheMB,
+		DatabaseHandles: cfg.StateDBHandles,
+		SnapshotCacheMB: cfg.StateSnapshotCacheMB,
+	})
+	if cfg.GasProfiling {
+		evm.GasProfiler().SetEnabled(true)
+		nodeLog.Infof("Profiling de gas activado (/api/v1/admin/profile/gas)")
+	}
+	if n.forkSetup != nil {
+		if err := n.forkSetup(ctx, evm); err != nil {
+			return fmt.Errorf("error configurando el fork de la red remota: %w", err)
+		}
+	}
+	if err := evm.Start(); err != nil {
+		return fmt.Errorf("error iniciando ejecutor EVM: %w", err)
+	}
+	n.evm = evm
+	n.lifecycle.onStop("ejecutor EVM", evm.Stop)
+
+	// Si el último estado confirmado no llegó a disco antes de detenerse, el estado EVM se retomó en una
+	// altura anterior: los bloques guardados después se descartan para reejecutarlos sobre ese estado
+	if height, recovered := evm.RecoveredHeight(); recovered {
+		removed, err := consensus.RollbackToState(db, height)
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			nodeLog.Warnf("Estado EVM retomado en la altura %d: %d bloques posteriores se reejecutarán", height, removed)
+		}
+	}
+	n.healthChecker.SetEVMHealth(true)
+
+	// Validadores (Validate ya verificó el stake mínimo)
+	minStake, err := config.ParseMinStake(cfg.MinStake)
+	if err != nil {
+		return fmt.Errorf("OXY_MIN_STAKE: %w", err)
+	}
+	nodeLog.Infof("minStake configurado: %s OXG", cfg.MinStake)
+	validators := consensus.NewValidatorSet(db, evm, minStake, MaxValidators)
+	validators.SetUptimeWindow(cfg.ValidatorUptimeWindow)
+	if blockReward, ok := new(big.Int).SetString(cfg.BlockReward, 10); ok && blockReward.Sign() >= 0 {
+		validators.SetBlockReward(blockReward)
+	}
+	if err := validators.LoadValidators(); err != nil {
+		nodeLog.Warnf("Error cargando validadores: %v", err)
+	}
+	n.validators = validators
+
+	// Consenso (CometBFT)
+	nodeLog.Infof("Inicializando CometBFT (DataDir=%s, ChainID=%s)", cfg.DataDir, cfg.ChainID)
+	consensusEngine, err := consensus.NewCometBFT(ctx, n.consensusConfig(), db, evm, validators)
+	if err != nil {
+		return fmt.Errorf("error inicializando consenso: %w", err)
+	}
+	n.consensus = consensusEngine
+	// Las métricas y el health checker se actualizan con cada bloque
+	consensusEngine.SetMetrics(n.metrics)
+	n.healthChecker.SetConsensusHealth(true)
+	// Monitor de sincronización: readiness solo si el nodo está al día con la red
+	n.healthChecker.SetMaxBlocksBehind(cfg.ReadinessMaxBlocksBehind)
+	consensusEngine.SetHealthChecker(n.healthChecker)
+
+	// Red mesh (integración con oxygen-sdk)
+	if err := n.newNetwork(ctx); err != nil {
+		return err
+	}
+
+	// Módulos que se enganchan a los bloques confirmados (antes de iniciar el consenso para no perder ninguno)
+	if err := n.startModules(reorg); err != nil {
+		return err
+	}
+
+	// Iniciar consenso y red
+	if err := consensusEngine.Start(); err != nil {
+		return fmt.Errorf("error iniciando consenso: %w", err)
+	}
+	n.lifecycle.onStop("consenso", consensusEngine.Stop)
+
+	if err := n.network.Start(); err != nil {
+		return fmt.Errorf("error iniciando red P2P: %w", err)
+	}
+	n.lifecycle.onStop("red P2P", n.network.Stop)
+
+	// Servidores del API
+	if err := n.startRestServer(); err != nil {
+		return err
+	}
+	n.startRosetta()
+	return nil
+}
+
+// consensusConfig arma la configuración del consenso a partir de la del nodo
+func (n *Node) consensusConfig() *consensus.Config {
+	cfg := n.cfg
+	return &consensus.Config{
+		DataDir:       cfg.DataDir,
+		ChainID:       cfg.ChainID,
+		ValidatorAddr: cfg.ValidatorAddr,
+		ValidatorKey:  cfg.ValidatorKey,
+
+		MaxBlockTimeDrift:   cfg.MaxBlockTimeDrift,
+		RateLimitPerAddress: cfg.RateLimitPerAddress,
+		RateLimitWindow:     cfg.RateLimitWindow,
+		MempoolSizeLimit:    cfg.MempoolSizeLimit,
+		TxLimits:            n.txLimits(),
+		TxIndexer:           cfg.TxIndexer,
+		RPCListenAddr:       cfg.RPCListenAddr,
+		P2PListenAddr:       cfg.P2PListenAddr,
+		P2PExternalAddr:     cfg.P2PExternalAddr,
+		FallbackSeeds:       cfg.FallbackSeeds,
+		NodeRole:            cfg.NodeRole,
+		PrivatePeerIDs:      cfg.PrivatePeerIDs,
+		SyncCheckInterval:   cfg.SyncCheckInterval,
+
+		BroadcastCommitTimeout: cfg.BroadcastCommitTimeout,
+
+		SnapshotInterval:     cfg.SnapshotInterval,
+		SnapshotKeepRecent:   cfg.SnapshotKeepRecent,
+		StateSyncRPCServers:  cfg.StateSyncRPCServers,
+		StateSyncTrustHeight: cfg.StateSyncTrustHeight,
+		StateSyncTrustHash:   cfg.StateSyncTrustHash,
+
+		InvariantCheckInterval:   cfg.InvariantCheckInterval,
+		HaltOnInvariantViolation: cfg.HaltOnInvariantViolation,
+
+		DevMode:         n.devMode,
+		GenesisAccounts: n.genesisAccounts,
+	}
+}
+
+// txLimits retorna los límites anti-spam por transacción (CheckTx, propuestas y precio sugerido de Rosetta)
+func (n *Node) txLimits() consensus.TxLimits {
+	limits := consensus.TxLimits{MaxDataSize: n.cfg.MaxTxDataSize, MaxGasLimit: n.cfg.MaxTxGasLimit}
+	if minGasPrice, ok := new(big.Int).SetString(n.cfg.MinGasPrice, 10); ok && minGasPrice.Sign() >= 0 {
+		limits.MinGasPrice = minGasPrice
+	}
+	return limits
+}
+
+// newNetwork crea la red mesh; la clave del validador identifica al nodo y firma los mensajes
+func (n *Node) newNetwork(ctx context.Context) error {
+	cfg := n.cfg
+	nodeLog.Infof("Inicializando red P2P (MeshEndpoint=%s)", cfg.MeshEndpoint)
+	var allowedPeers []string
+	if cfg.MeshAllowedPeers != "" {
+		allowedPeers = strings.Split(cfg.MeshAllowedPeers, ",")
+	}
+	networkConfig := &network.Config{
+		MeshEndpoint:          cfg.MeshEndpoint,
+		PeerID:                cfg.ValidatorAddr,
+		ChainID:               cfg.ChainID,
+		ReconnectMaxBackoff:   cfg.MeshReconnectMaxBackoff,
+		OutboxLimit:           cfg.MeshOutboxLimit,
+		OutboxTTL:             cfg.MeshOutboxTTL,
+		RequireSignedMessages: cfg.MeshRequireSigned,
+		AllowedPeers:          allowedPeers,
+		Encoding:              cfg.MeshEncoding,
+		Compression:           cfg.MeshCompression,
+		BanDuration:           cfg.MeshBanDuration,
+		PeerMessageRate:       cfg.MeshPeerMessageRate,
+		DataDir:               cfg.DataDir,
+		RESTEndpoint:          cfg.RESTPublicURL,
+		AutoPersistentPeers:   cfg.PeerDiscoveryAuto,
+	}
+	if cfg.ValidatorKey != "" {
+		nodeKey, err := security.ParsePrivateKey(cfg.ValidatorKey)
+		if err != nil {
+			return fmt.Errorf("error cargando clave del nodo: %w", err)
+		}
+		networkConfig.NodeKey = nodeKey
+	}
+
+	p2pNetwork, err := network.NewP2PNetwork(ctx, networkConfig, n.consensus, n.db)
+	if err != nil {
+		return fmt.Errorf("error inicializando red P2P: %w", err)
+	}
+	// El estado del mesh (conexión/desconexión) se refleja en el health checker
+	p2pNetwork.SetHealthChecker(n.healthChecker)
+	n.network = p2pNetwork
+	return nil
+}
+
+// startModules crea los módulos que procesan los bloques confirmados: watchlist, publicación de eventos,
+// multisig y compliance
+func (n *Node) startModules(reorg *consensus.ChainReorg) error {
+	cfg := n.cfg
+
+	// Watchlist: las suscripciones se comparan con cada bloque confirmado y se entregan por webhook o WebSocket
+	webhookDispatcher := watchlist.NewDispatcher()
+	webhookDispatcher.SetRetryPolicy(cfg.WebhookMaxAttempts, 0, cfg.WebhookMaxBackoff)
+	watchlistRegistry, err := watchlist.NewRegistry(n.db, webhookDispatcher)
+	if err != nil {
+		return fmt.Errorf("error cargando watchlist: %w", err)
+	}
+	watchlistRegistry.SetLimits(cfg.WatchlistMaxSubscriptions, cfg.WatchlistMaxAddresses)
+	n.consensus.AddBlockCommitHandler(func(block *consensus.Block) {
+		watchlistRegistry.Notify(consensus.WatchlistBlock(block))
+	})
+	webhookDispatcher.Start()
+	n.lifecycle.onStop("webhooks", func() error {
+		webhookDispatcher.Stop()
+		return nil
+	})
+	if reorg != nil {
+		watchlistRegistry.NotifyReorg(consensus.WatchlistReorg(reorg))
+	}
+	n.watchlist = watchlistRegistry
+
+	// Eventos: cada bloque confirmado se publica en NATS o Kafka, continuando desde el último publicado
+	if cfg.EventSink != "" {
+		eventSink, err := newEventSink(cfg, n.db)
+		if err != nil {
+			return fmt.Errorf("error configurando la publicación de eventos: %w", err)
+		}
+		n.consensus.AddBlockCommitHandler(eventSink.Notify)
+		if err := eventSink.Start(); err != nil {
+			return fmt.Errorf("error iniciando la publicación de eventos: %w", err)
+		}
+		n.lifecycle.onStop("publicación de eventos", func() error {
+			eventSink.Stop()
+			return nil
+		})
+	}
+
+	// Multisig: las transacciones propuestas recolectan firmas de los owners hasta confirmarse en un bloque
+	multisigPool, err := consensus.NewMultisigPool(n.db)
+	if err != nil {
+		return fmt.Errorf("error cargando propuestas multisig: %w", err)
+	}
+	n.consensus.AddBlockCommitHandler(multisigPool.HandleBlock)
+	n.multisig = multisigPool
+
+	// Compliance: blacklist/allowlist de direcciones aplicada en CheckTx y en las propuestas
+	if cfg.ComplianceMode != "" {
+		complianceList, err := consensus.NewComplianceList(n.db, cfg.ComplianceMode)
+		if err != nil {
+			return fmt.Errorf("error cargando lista de compliance: %w", err)
+		}
+		for _, address := range strings.Split(cfg.ComplianceAddresses, ",") {
+			if address = strings.TrimSpace(address); address == "" {
+				continue
+			}
+			if _, err := complianceList.Add(address, "", "config"); err != nil {
+				return fmt.Errorf("OXY_COMPLIANCE_ADDRESSES inválido: %w", err)
+			}
+		}
+		n.consensus.SetComplianceList(complianceList)
+		n.compliance = complianceList
+		nodeLog.Infof("Compliance habilitado: modo=%s, direcciones=%d", complianceList.Mode(), len(complianceList.Entries()))
+	}
+	return nil
+}
+
+// startRestServer inicia el API REST si está habilitado
+func (n *Node) startRestServer() error {
+	cfg := n.cfg
+	nodeLog.Infof("Configuración API REST: APIEnabled=%v, APIPort=%s, APIHost=%s", cfg.APIEnabled, cfg.APIPort, cfg.APIHost)
+	if !cfg.APIEnabled {
+		return nil
+	}
+
+	restServer := api.NewRestServer(cfg.APIHost, cfg.APIPort, n.db, n.consensus, n.healthChecker, n.metrics, n.evm)
+	// Bloques que el nodo aún no tiene se piden a la mesh
+	restServer.SetQueryHandler(n.network.QueryHandler())
+	// Bans y scoring de peers mesh para el API de administración
+	restServer.SetPeerScorer(n.network.PeerScorer())
+	// Nodos descubiertos por la mesh
+	restServer.SetPeerTable(n.network.PeerTable())
+	// Suscripciones de notificaciones por dirección
+	restServer.SetWatchlist(n.watchlist)
+	// Recolección de firmas de transacciones multisig
+	restServer.SetMultisigPool(n.multisig)
+	// Administración de la lista de compliance
+	restServer.SetComplianceList(n.compliance)
+	// Metadatos de OXG y de los tokens bridged para las wallets
+	if cfg.AssetsFile != "" {
+		assetRegistry := assets.NewRegistry()
+		count, err := assetRegistry.LoadFile(cfg.AssetsFile)
+		if err != nil {
+			return fmt.Errorf("OXY_ASSETS_FILE inválido: %w", err)
+		}
+		restServer.SetAssetRegistry(assetRegistry)
+		nodeLog.Infof("%d activos bridged registrados desde %s", count, cfg.AssetsFile)
+	}
+	// Cache en Redis compartido con los otros nodos del API; cada commit avanza la altura de sus claves
+	if cfg.RedisURL != "" {
+		sharedCache, err := newSharedCache(cfg, n.db)
+		if err != nil {
+			return fmt.Errorf("error configurando el cache en Redis: %w", err)
+		}
+		n.consensus.AddBlockCommitHandler(func(block *consensus.Block) {
+			sharedCache.SetHeight(block.Header.Height)
+		})
+		restServer.SetSharedCache(sharedCache)
+		n.lifecycle.onStop("cache en Redis", sharedCache.Close)
+	}
+
+	go func() {
+		nodeLog.Infof("Iniciando servidor REST local en %s:%s", cfg.APIHost, cfg.APIPort)
+		if err := restServer.Start(); err != nil && err != http.ErrServerClosed {
+			nodeLog.Errorf("Error iniciando servidor REST: %v", err)
+		}
+	}()
+	n.restServer = restServer
+	n.lifecycle.onStop("API REST", restServer.Stop)
+	return nil
+}
+
+// startRosetta inicia el API de Rosetta si está habilitada (mismo host que el API REST)
+func (n *Node) startRosetta() {
+	cfg := n.cfg
+	if !cfg.RosettaEnabled {
+		return
+	}
+	rosettaServer := rosetta.NewServer(cfg.APIHost, cfg.RosettaPort, cfg.ChainID, n.db, n.consensus, n.evm)
+	// Precio de gas sugerido en /construction/metadata
+	rosettaServer.SetMinGasPrice(n.txLimits().MinGasPrice)
+	go func() {
+		if err := rosettaServer.Start(); err != nil && err != http.ErrServerClosed {
+			nodeLog.Errorf("Error iniciando API de Rosetta: %v", err)
+		}
+	}()
+	n.rosetta = rosettaServer
+	n.lifecycle.onStop("API de Rosetta", rosettaServer.Stop)
+}
+
+// newEventSink crea el publicador de eventos configurado (OXY_EVENTS_SINK)
+// Cada destino (tipo y prefijo) guarda su propia última altura publicada
+func newEventSink(cfg *config.Config, db *storage.BlockchainDB) (*eventsink.Sink, error) {
+	var publisher eventsink.Publisher
+	var err error
+	switch cfg.EventSink {
+	case "nats":
+		publisher, err = eventsink.NewNATSPublisher(cfg.EventSinkURL, cfg.EventSinkJetStream)
+	case "kafka":
+		publisher, err = eventsink.NewKafkaPublisher(cfg.EventSinkURL)
+	default:
+		return nil, fmt.Errorf("OXY_EVENTS_SINK desconocido: %s (nats o kafka)", cfg.EventSink)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sink, err := eventsink.NewSink(db, publisher, cfg.EventSink+":"+cfg.EventSinkTopicPrefix, cfg.EventSinkFormat, cfg.EventSinkTopicPrefix)
+	if err != nil {
+		return nil, err
+	}
+	sink.SetStartHeight(cfg.EventSinkStartHeight)
+	return sink, nil
+}
+
+// newSharedCache crea el cache en Redis de las lecturas del API (OXY_REDIS_URL) con la altura del último bloque
+// Si Redis no responde al iniciar el nodo sigue: las lecturas van al storage hasta que vuelva
+func newSharedCache(cfg *config.Config, db *storage.BlockchainDB) (*rediscache.Cache, error) {
+	client, err := rediscache.NewClient(cfg.RedisURL, rediscache.DefaultPoolSize)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx); err != nil {
+		nodeLog.Warnf("Redis no disponible al iniciar (las lecturas van al storage): %v", err)
+	}
+
+	// Las claves llevan el chain ID para que dos redes no compartan respuestas
+	cache := rediscache.NewCache(client, cfg.RedisPrefix+":"+cfg.ChainID, cfg.RedisTTL, cfg.RedisTimeout)
+	if height, err := db.GetLatestHeight(); err == nil {
+		cache.SetHeight(height)
+	}
+	return cache, nil
+}
+
+// Config retorna la configuración del nodo
+func (n *Node) Config() *config.Config {
+	return n.cfg
+}
+
+// DB retorna el storage del nodo (nil antes de Start)
+func (n *Node) DB() *storage.BlockchainDB {
+	return n.db
+}
+
+// EVM retorna el ejecutor EVM del nodo (nil antes de Start)
+func (n *Node) EVM() *execution.EVMExecutor {
+	return n.evm
+}
+
+// Consensus retorna el motor de consenso del nodo (nil antes de Start)
+func (n *Node) Consensus() *consensus.CometBFT {
+	return n.consensus
+}
+
+// Network retorna la red mesh del nodo (nil antes de Start)
+func (n *Node) Network() *network.P2PNetwork {
+	return n.network
+}
+
+// HealthChecker retorna el health checker del nodo
+func (n *Node) HealthChecker() *health.HealthChecker {
+	return n.healthChecker
+}
+
+// Metrics retorna las métricas del nodo
+func (n *Node) Metrics() *metrics.Metrics {
+	return n.metrics
+}
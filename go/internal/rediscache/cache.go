@@ -0,0 +1,158 @@
+// Package rediscache comparte en Redis las respuestas de las lecturas más frecuentes del API (último bloque,
+// set de validadores y cuentas) entre una flota de nodos.
+//
+// Las claves incluyen la altura del último bloque confirmado: cada commit avanza la altura y las respuestas
+// anteriores dejan de leerse (expiran con su TTL), así que no hace falta borrar claves ni coordinar la
+// invalidación entre nodos. Un nodo que todavía no confirmó el bloque sigue leyendo y escribiendo las claves
+// de su altura. Si Redis no responde, las lecturas van al storage local.
+package rediscache
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/logger"
+)
+
+// cacheLog es el logger del módulo rediscache
+var cacheLog = logger.For("rediscache")
+
+// Valores por defecto del cache
+const (
+	DefaultPrefix  = "oxy"
+	DefaultTTL     = 30 * time.Second
+	DefaultTimeout = 100 * time.Millisecond
+)
+
+// Tipos de respuesta cacheados (segmento de la clave)
+const (
+	KindLatestBlock = "latest-block"
+	KindValidators  = "validators"
+	KindAccount     = "account"
+)
+
+// Stats son los contadores del cache
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	Errors uint64 // Operaciones que fallaron o vencieron (la lectura fue al storage)
+}
+
+// Cache guarda respuestas serializadas en Redis con claves <prefijo>:<altura>:<tipo>:<id>
+// Un Cache nil no guarda nada: Get siempre falla y Set no hace nada
+type Cache struct {
+	client  *Client
+	prefix  string
+	ttl     time.Duration
+	timeout time.Duration
+
+	height  atomic.Uint64 // Último bloque confirmado
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	errors  atomic.Uint64
+	failing atomic.Bool // Evita repetir el aviso mientras Redis siga fallando
+}
+
+// NewCache crea un cache sobre client; ttl y timeout <= 0 usan los valores por defecto
+func NewCache(client *Client, prefix string, ttl, timeout time.Duration) *Cache {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Cache{client: client, prefix: prefix, ttl: ttl, timeout: timeout}
+}
+
+// SetHeight registra la altura del último bloque confirmado, lo que invalida las respuestas anteriores
+// Se llama al iniciar con la altura del storage y en cada commit
+func (c *Cache) SetHeight(height uint64) {
+	if c != nil {
+		c.height.Store(height)
+	}
+}
+
+// Height retorna la altura con la que se generan las claves
+func (c *Cache) Height() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.height.Load()
+}
+
+// Get retorna la respuesta guardada para kind e id en la altura actual
+func (c *Cache) Get(ctx context.Context, kind, id string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	value, ok, err := c.client.Get(ctx, c.key(c.height.Load(), kind, id))
+	if err != nil {
+		c.recordError(err)
+		return nil, false
+	}
+	c.recordSuccess()
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return value, true
+}
+
+// Set guarda la respuesta de kind e id generada en height (la altura leída antes de generarla)
+// Si la altura ya avanzó la respuesta puede estar desactualizada y no se guarda
+func (c *Cache) Set(ctx context.Context, height uint64, kind, id string, value []byte) {
+	if c == nil || height != c.height.Load() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	if err := c.client.Set(ctx, c.key(height, kind, id), value, c.ttl); err != nil {
+		c.recordError(err)
+		return
+	}
+	c.recordSuccess()
+}
+
+// Stats retorna los contadores del cache
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Errors: c.errors.Load()}
+}
+
+// Close cierra las conexiones con Redis
+func (c *Cache) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.client.Close()
+}
+
+// key retorna la clave de una respuesta
+func (c *Cache) key(height uint64, kind, id string) string {
+	return c.prefix + ":" + strconv.FormatUint(height, 10) + ":" + kind + ":" + id
+}
+
+// recordError cuenta un error y avisa la primera vez que Redis falla
+func (c *Cache) recordError(err error) {
+	c.errors.Add(1)
+	if !c.failing.Swap(true) {
+		cacheLog.Warnf("Redis no disponible, las lecturas van al storage: %v", err)
+	}
+}
+
+// recordSuccess avisa cuando Redis vuelve a responder
+func (c *Cache) recordSuccess() {
+	if c.failing.Swap(false) {
+		cacheLog.Infof("Redis disponible de nuevo")
+	}
+}
@@ -0,0 +1,272 @@
+package rediscache
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPoolSize es la cantidad de conexiones inactivas que se conservan
+const DefaultPoolSize = 16
+
+// errNil es la respuesta nula de Redis (GET de una clave que no existe)
+var errNil = errors.New("redis: nil")
+
+// Client es un cliente mínimo de Redis (protocolo RESP2, sin dependencias externas) con un pool de conexiones
+type Client struct {
+	addr     string
+	useTLS   bool
+	username string
+	password string
+	db       int
+	idle     chan *conn
+}
+
+// conn es una conexión del pool
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// NewClient crea un cliente para rawURL (redis://[usuario:contraseña@]host:6379/db, rediss:// para TLS)
+// Las conexiones se abren a demanda
+func NewClient(rawURL string, poolSize int) (*Client, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL de Redis inválida: %w", err)
+	}
+	if parsed.Scheme != "redis" && parsed.Scheme != "rediss" {
+		return nil, fmt.Errorf("URL de Redis inválida: %s (redis:// o rediss://)", rawURL)
+	}
+	if poolSize <= 0 {
+		poolSize = DefaultPoolSize
+	}
+	client := &Client{
+		addr:   parsed.Host,
+		useTLS: parsed.Scheme == "rediss",
+		idle:   make(chan *conn, poolSize),
+	}
+	if parsed.Port() == "" {
+		client.addr = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+	if user := parsed.User; user != nil {
+		client.username = user.Username()
+		client.password, _ = user.Password()
+	}
+	if db := strings.TrimPrefix(parsed.Path, "/"); db != "" {
+		if client.db, err = strconv.Atoi(db); err != nil || client.db < 0 {
+			return nil, fmt.Errorf("base de datos de Redis inválida: %s", db)
+		}
+	}
+	return client, nil
+}
+
+// Get retorna el valor de key (ok en false si no existe)
+func (c *Client) Get(ctx context.Context, key string) (value []byte, ok bool, err error) {
+	reply, err := c.do(ctx, "GET", key)
+	if errors.Is(err, errNil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	value, isBulk := reply.([]byte)
+	if !isBulk {
+		return nil, false, fmt.Errorf("respuesta de GET inesperada: %v", reply)
+	}
+	return value, true, nil
+}
+
+// Set guarda value en key con expiración ttl (0 = sin expiración)
+func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := c.do(ctx, args...)
+	return err
+}
+
+// Del elimina las claves
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := c.do(ctx, append([]string{"DEL"}, keys...)...)
+	return err
+}
+
+// Ping verifica la conexión con el servidor
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.do(ctx, "PING")
+	return err
+}
+
+// Close cierra las conexiones inactivas
+func (c *Client) Close() error {
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do ejecuta un comando con una conexión del pool; una conexión con error se descarta
+func (c *Client) do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		cn.SetDeadline(deadline)
+	} else {
+		cn.SetDeadline(time.Time{})
+	}
+	reply, err := cn.command(args...)
+	if err != nil && !isReplyError(err) {
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// get retorna una conexión inactiva o abre una nueva (con AUTH y SELECT)
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{}
+	var netConn net.Conn
+	var err error
+	if c.useTLS {
+		host, _, _ := net.SplitHostPort(c.addr)
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a Redis %s: %w", c.addr, err)
+	}
+	cn := &conn{Conn: netConn, reader: bufio.NewReader(netConn)}
+	if deadline, ok := ctx.Deadline(); ok {
+		cn.SetDeadline(deadline)
+	}
+
+	if c.password != "" {
+		args := []string{"AUTH", c.password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.password}
+		}
+		if _, err := cn.command(args...); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("error autenticando en Redis: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := cn.command("SELECT", strconv.Itoa(c.db)); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("error seleccionando la base de datos %d de Redis: %w", c.db, err)
+		}
+	}
+	return cn, nil
+}
+
+// put devuelve una conexión al pool (o la cierra si está lleno)
+func (c *Client) put(cn *conn) {
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+// replyError es un error retornado por el servidor (-ERR ...); la conexión sigue siendo válida
+type replyError string
+
+func (e replyError) Error() string { return "redis: " + string(e) }
+
+// isReplyError retorna si err es una respuesta de error del servidor (o la respuesta nula)
+func isReplyError(err error) bool {
+	var reply replyError
+	return errors.As(err, &reply) || errors.Is(err, errNil)
+}
+
+// command envía un comando como array de bulk strings y lee la respuesta
+func (cn *conn) command(args ...string) (interface{}, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(cn, b.String()); err != nil {
+		return nil, fmt.Errorf("error escribiendo en Redis: %w", err)
+	}
+	return cn.readReply()
+}
+
+// readReply lee una respuesta RESP2: +simple, -error, :entero, $bulk o *array
+func (cn *conn) readReply() (interface{}, error) {
+	line, err := cn.reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo de Redis: %w", err)
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("respuesta de Redis vacía")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, replyError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("respuesta de Redis inválida: %q", line)
+		}
+		if size < 0 {
+			return nil, errNil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(cn.reader, data); err != nil {
+			return nil, fmt.Errorf("error leyendo de Redis: %w", err)
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("respuesta de Redis inválida: %q", line)
+		}
+		if count < 0 {
+			return nil, errNil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			// Un elemento nulo o de error no invalida el resto del array
+			item, err := cn.readReply()
+			if err != nil && !isReplyError(err) {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("respuesta de Redis inválida: %q", line)
+}
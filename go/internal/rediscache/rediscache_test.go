@@ -0,0 +1,251 @@
+package rediscache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis es un servidor RESP mínimo con GET, SET, DEL, PING, AUTH y SELECT
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string]string
+	ttls     map[string]string
+	selected string
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeRedis{listener: listener, password: password, data: map[string]string{}, ttls: map[string]string{}}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeRedis) url() string {
+	return "redis://" + s.listener.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authenticated := s.password == ""
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			authenticated = args[len(args)-1] == s.password
+			if authenticated {
+				io.WriteString(conn, "+OK\r\n")
+			} else {
+				io.WriteString(conn, "-WRONGPASS invalid password\r\n")
+			}
+		case "SELECT":
+			s.selected = args[1]
+			io.WriteString(conn, "+OK\r\n")
+		default:
+			if !authenticated {
+				io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+				break
+			}
+			s.execute(conn, args)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *fakeRedis) execute(conn net.Conn, args []string) {
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		io.WriteString(conn, "+PONG\r\n")
+	case "GET":
+		value, ok := s.data[args[1]]
+		if !ok {
+			io.WriteString(conn, "$-1\r\n")
+			return
+		}
+		fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		s.data[args[1]] = args[2]
+		if len(args) == 5 {
+			s.ttls[args[1]] = args[4]
+		}
+		io.WriteString(conn, "+OK\r\n")
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.data[key]; ok {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		fmt.Fprintf(conn, ":%d\r\n", deleted)
+	default:
+		fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// readCommand lee un comando RESP (array de bulk strings)
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func TestClient(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	ctx := context.Background()
+
+	client, err := NewClient("redis://:wrong@"+server.listener.Addr().String(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(ctx); err == nil {
+		t.Fatal("se esperaba error de autenticación")
+	}
+
+	client, err = NewClient("redis://:secret@"+server.listener.Addr().String()+"/3", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, ok, err := client.Get(ctx, "missing"); err != nil || ok {
+		t.Fatalf("GET de clave inexistente: ok=%v err=%v", ok, err)
+	}
+	value := []byte("{\"a\":1}\r\nbinario\x00")
+	if err := client.Set(ctx, "key", value, 1500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := client.Get(ctx, "key")
+	if err != nil || !ok || string(got) != string(value) {
+		t.Fatalf("GET retornó %q ok=%v err=%v", got, ok, err)
+	}
+	if err := client.Del(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := client.Get(ctx, "key"); ok {
+		t.Error("la clave debería haberse eliminado")
+	}
+	server.mu.Lock()
+	if server.selected != "3" || server.ttls["key"] != "1500" {
+		t.Errorf("SELECT %q, PX %q", server.selected, server.ttls["key"])
+	}
+	server.mu.Unlock()
+
+	for _, invalid := range []string{"http://localhost:6379", "redis://localhost/x"} {
+		if _, err := NewClient(invalid, 0); err == nil {
+			t.Errorf("se esperaba error para %s", invalid)
+		}
+	}
+}
+
+func TestCacheHeightInvalidation(t *testing.T) {
+	server := newFakeRedis(t, "")
+	client, err := NewClient(server.url(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewCache(client, "oxy:test", time.Minute, time.Second)
+	ctx := context.Background()
+
+	cache.SetHeight(10)
+	if _, ok := cache.Get(ctx, KindAccount, "0xabc"); ok {
+		t.Fatal("el cache debería estar vacío")
+	}
+	cache.Set(ctx, 10, KindAccount, "0xabc", []byte("v10"))
+	if value, ok := cache.Get(ctx, KindAccount, "0xabc"); !ok || string(value) != "v10" {
+		t.Fatalf("Get retornó %q ok=%v", value, ok)
+	}
+
+	// Un commit invalida la respuesta: la clave de la nueva altura todavía no existe
+	cache.SetHeight(11)
+	if _, ok := cache.Get(ctx, KindAccount, "0xabc"); ok {
+		t.Error("la respuesta de la altura 10 no debería leerse en la 11")
+	}
+	// Una respuesta generada antes del commit no se guarda con la nueva altura
+	cache.Set(ctx, 10, KindAccount, "0xabc", []byte("v10"))
+	if _, ok := cache.Get(ctx, KindAccount, "0xabc"); ok {
+		t.Error("una respuesta de la altura 10 no debería guardarse en la 11")
+	}
+
+	server.mu.Lock()
+	_, ok := server.data["oxy:test:10:account:0xabc"]
+	server.mu.Unlock()
+	if !ok {
+		t.Error("clave con formato inesperado")
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 3 || stats.Errors != 0 {
+		t.Errorf("stats inesperados: %+v", stats)
+	}
+}
+
+func TestCacheUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client, _ := NewClient("redis://"+addr, 0)
+	cache := NewCache(client, "", 0, 50*time.Millisecond)
+	cache.Set(context.Background(), 0, KindLatestBlock, "full", []byte("x"))
+	if _, ok := cache.Get(context.Background(), KindLatestBlock, "full"); ok {
+		t.Error("sin Redis Get debería fallar")
+	}
+	if stats := cache.Stats(); stats.Errors != 2 {
+		t.Errorf("errores %d, esperados 2", stats.Errors)
+	}
+
+	// Un cache nil (deshabilitado) no guarda nada
+	var disabled *Cache
+	disabled.SetHeight(5)
+	disabled.Set(context.Background(), 0, KindValidators, "all", []byte("x"))
+	if _, ok := disabled.Get(context.Background(), KindValidators, "all"); ok || disabled.Height() != 0 {
+		t.Error("un cache nil no debería retornar respuestas")
+	}
+}
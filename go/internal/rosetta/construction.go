@@ -0,0 +1,333 @@
+package rosetta
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/pkg/client"
+	"github.com/Q-YZX0/oxy-blockchain/pkg/txsign"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// CurveSecp256k1 es la única curva soportada
+	CurveSecp256k1 = "secp256k1"
+	// SignatureEcdsaRecovery es la firma que verifica el nodo: 65 bytes [R][S][V]
+	SignatureEcdsaRecovery = "ecdsa_recovery"
+)
+
+// constructionOptions son las opciones que /construction/preprocess pasa a /construction/metadata
+type constructionOptions struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Value    string `json:"value"`
+	GasPrice string `json:"gas_price,omitempty"` // Opcional (metadata de preprocess); por defecto el mínimo del nodo
+	GasLimit uint64 `json:"gas_limit,omitempty"` // Opcional; por defecto el de una transferencia
+}
+
+// constructionMetadata es lo que /construction/metadata obtiene del nodo para armar la transacción
+type constructionMetadata struct {
+	Nonce    uint64 `json:"nonce"`
+	GasPrice string `json:"gas_price"`
+	GasLimit uint64 `json:"gas_limit"`
+}
+
+// constructionDerive maneja /construction/derive: la dirección de una clave pública secp256k1
+// (comprimida o no)
+func (s *Server) constructionDerive(ctx context.Context, req *deriveRequest) (interface{}, *Error) {
+	if req.PublicKey == nil || req.PublicKey.CurveType != CurveSecp256k1 {
+		return nil, wrapErr(ErrInvalidPublicKey, "expected a %s public key", CurveSecp256k1)
+	}
+	raw, err := decodeHex(req.PublicKey.HexBytes)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidPublicKey, "%v", err)
+	}
+	var pubkey *ecdsa.PublicKey
+	if len(raw) == 33 {
+		pubkey, err = crypto.DecompressPubkey(raw)
+	} else {
+		pubkey, err = crypto.UnmarshalPubkey(raw)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrInvalidPublicKey, "%v", err)
+	}
+	return map[string]interface{}{
+		"account_identifier": AccountIdentifier{Address: crypto.PubkeyToAddress(*pubkey).Hex()},
+	}, nil
+}
+
+// constructionPreprocess maneja /construction/preprocess: valida las operaciones (una transferencia) y
+// retorna las opciones para /construction/metadata. La metadata puede fijar gas_price y gas_limit
+func (s *Server) constructionPreprocess(ctx context.Context, req *preprocessRequest) (interface{}, *Error) {
+	from, to, value, rerr := parseTransfer(req.Operations)
+	if rerr != nil {
+		return nil, rerr
+	}
+	options := constructionOptions{From: from, To: to, Value: value.String()}
+	if price, ok := req.Metadata["gas_price"].(string); ok {
+		if parsed, ok := new(big.Int).SetString(price, 10); !ok || parsed.Sign() <= 0 {
+			return nil, wrapErr(ErrInvalidRequest, "invalid gas_price %q", price)
+		}
+		options.GasPrice = price
+	}
+	if limit, ok := req.Metadata["gas_limit"].(float64); ok {
+		if limit < txsign.TransferGasLimit {
+			return nil, wrapErr(ErrInvalidRequest, "gas_limit below %d", txsign.TransferGasLimit)
+		}
+		options.GasLimit = uint64(limit)
+	}
+	return map[string]interface{}{
+		"options":              options,
+		"required_public_keys": []AccountIdentifier{{Address: from}},
+	}, nil
+}
+
+// constructionMetadata maneja /construction/metadata: el nonce del remitente (contando sus transacciones
+// en el mempool), el precio de gas y el fee sugerido
+func (s *Server) constructionMetadata(ctx context.Context, req *metadataRequest) (interface{}, *Error) {
+	if !common.IsHexAddress(req.Options.From) {
+		return nil, ErrInvalidAddress
+	}
+	if s.executor == nil {
+		return nil, ErrUnavailable
+	}
+	account, err := s.executor.GetCommittedState(req.Options.From)
+	if err != nil {
+		return nil, wrapErr(ErrUnavailable, "%v", err)
+	}
+	nonce := account.Nonce
+	if s.consensus != nil {
+		for _, tx := range s.consensus.GetMempool() {
+			if strings.EqualFold(tx.From, req.Options.From) && tx.Nonce >= nonce {
+				nonce = tx.Nonce + 1
+			}
+		}
+	}
+
+	gasPrice := big.NewInt(1)
+	if s.minGasPrice != nil && s.minGasPrice.Sign() > 0 {
+		gasPrice = new(big.Int).Set(s.minGasPrice)
+	}
+	if req.Options.GasPrice != "" {
+		if price, ok := new(big.Int).SetString(req.Options.GasPrice, 10); ok {
+			gasPrice = price
+		}
+	}
+	gasLimit := uint64(txsign.TransferGasLimit)
+	if req.Options.GasLimit > 0 {
+		gasLimit = req.Options.GasLimit
+	}
+
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+	return map[string]interface{}{
+		"metadata":      constructionMetadata{Nonce: nonce, GasPrice: gasPrice.String(), GasLimit: gasLimit},
+		"suggested_fee": []Amount{{Value: fee.String(), Currency: OXG}},
+	}, nil
+}
+
+// constructionPayloads maneja /construction/payloads: la transacción sin firmar (el JSON de
+// /api/v1/submit-tx) y el hash que debe firmar el remitente
+func (s *Server) constructionPayloads(ctx context.Context, req *payloadsRequest) (interface{}, *Error) {
+	from, to, value, rerr := parseTransfer(req.Operations)
+	if rerr != nil {
+		return nil, rerr
+	}
+	gasPrice, ok := new(big.Int).SetString(req.Metadata.GasPrice, 10)
+	if !ok || gasPrice.Sign() <= 0 {
+		return nil, wrapErr(ErrInvalidRequest, "invalid gas_price %q", req.Metadata.GasPrice)
+	}
+	tx := txsign.NewTransfer(from, to, value, req.Metadata.Nonce, gasPrice)
+	if req.Metadata.GasLimit > 0 {
+		tx.GasLimit = req.Metadata.GasLimit
+	}
+
+	hash, err := txsign.Hash(tx)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidTx, "%v", err)
+	}
+	unsigned, err := json.Marshal(tx)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidTx, "%v", err)
+	}
+	return map[string]interface{}{
+		"unsigned_transaction": string(unsigned),
+		"payloads": []SigningPayload{{
+			AccountIdentifier: &AccountIdentifier{Address: from},
+			HexBytes:          strings.TrimPrefix(hash, "0x"),
+			SignatureType:     SignatureEcdsaRecovery,
+		}},
+	}, nil
+}
+
+// constructionCombine maneja /construction/combine: agrega la firma del remitente (verificada como en
+// CheckTx) a la transacción sin firmar
+func (s *Server) constructionCombine(ctx context.Context, req *combineRequest) (interface{}, *Error) {
+	tx, rerr := decodeTransaction(req.UnsignedTransaction)
+	if rerr != nil {
+		return nil, rerr
+	}
+	if len(req.Signatures) != 1 {
+		return nil, wrapErr(ErrInvalidSignature, "expected 1 signature, got %d", len(req.Signatures))
+	}
+	signature, err := decodeHex(req.Signatures[0].HexBytes)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidSignature, "%v", err)
+	}
+
+	hash, err := txsign.Hash(tx)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidTx, "%v", err)
+	}
+	tx.Hash = hash
+	tx.Signature = signature
+	if rerr := verifySender(tx); rerr != nil {
+		return nil, rerr
+	}
+
+	signed, err := json.Marshal(tx)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidTx, "%v", err)
+	}
+	return map[string]interface{}{"signed_transaction": string(signed)}, nil
+}
+
+// constructionParse maneja /construction/parse: las operaciones de una transacción firmada o sin firmar
+func (s *Server) constructionParse(ctx context.Context, req *parseRequest) (interface{}, *Error) {
+	tx, rerr := decodeTransaction(req.Transaction)
+	if rerr != nil {
+		return nil, rerr
+	}
+	resp := map[string]interface{}{
+		"operations": transferOperations(toConsensus(tx), nil),
+		"metadata":   constructionMetadata{Nonce: tx.Nonce, GasPrice: tx.GasPrice, GasLimit: tx.GasLimit},
+	}
+	if req.Signed {
+		if rerr := verifySender(tx); rerr != nil {
+			return nil, rerr
+		}
+		resp["account_identifier_signers"] = []AccountIdentifier{{Address: checksum(tx.From)}}
+	}
+	return resp, nil
+}
+
+// constructionHash maneja /construction/hash
+func (s *Server) constructionHash(ctx context.Context, req *signedTransactionRequest) (interface{}, *Error) {
+	tx, rerr := decodeTransaction(req.SignedTransaction)
+	if rerr != nil {
+		return nil, rerr
+	}
+	hash, err := txsign.Hash(tx)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidTx, "%v", err)
+	}
+	return map[string]interface{}{"transaction_identifier": TransactionIdentifier{Hash: hash}}, nil
+}
+
+// constructionSubmit maneja /construction/submit: envía la transacción firmada y espera CheckTx
+func (s *Server) constructionSubmit(ctx context.Context, req *signedTransactionRequest) (interface{}, *Error) {
+	tx, rerr := decodeTransaction(req.SignedTransaction)
+	if rerr != nil {
+		return nil, rerr
+	}
+	if rerr := verifySender(tx); rerr != nil {
+		return nil, rerr
+	}
+	if s.consensus == nil {
+		return nil, ErrUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, submitTimeout)
+	defer cancel()
+	if _, err := s.consensus.BroadcastTransaction(ctx, toConsensus(tx), consensus.BroadcastSync); err != nil {
+		return nil, wrapErr(ErrSubmitFailed, "%v", err)
+	}
+	return map[string]interface{}{"transaction_identifier": TransactionIdentifier{Hash: tx.Hash}}, nil
+}
+
+// parseTransfer valida que las operaciones sean una transferencia de OXG (débito y crédito del mismo
+// monto) y retorna remitente, destinatario y valor
+func parseTransfer(operations []*Operation) (string, string, *big.Int, *Error) {
+	if len(operations) != 2 {
+		return "", "", nil, wrapErr(ErrInvalidOperations, "expected 2 TRANSFER operations, got %d", len(operations))
+	}
+	var from, to string
+	var debit, credit *big.Int
+	for _, op := range operations {
+		if op.Type != OpTransfer || op.Account == nil || op.Amount == nil {
+			return "", "", nil, wrapErr(ErrInvalidOperations, "expected TRANSFER operations with account and amount")
+		}
+		if op.Amount.Currency != OXG {
+			return "", "", nil, wrapErr(ErrInvalidOperations, "unsupported currency %s", op.Amount.Currency.Symbol)
+		}
+		if !common.IsHexAddress(op.Account.Address) {
+			return "", "", nil, wrapErr(ErrInvalidAddress, "%s", op.Account.Address)
+		}
+		amount, ok := new(big.Int).SetString(op.Amount.Value, 10)
+		if !ok || amount.Sign() == 0 {
+			return "", "", nil, wrapErr(ErrInvalidOperations, "invalid amount %q", op.Amount.Value)
+		}
+		if amount.Sign() < 0 {
+			from, debit = checksum(op.Account.Address), amount.Neg(amount)
+		} else {
+			to, credit = checksum(op.Account.Address), amount
+		}
+	}
+	if debit == nil || credit == nil || debit.Cmp(credit) != 0 {
+		return "", "", nil, wrapErr(ErrInvalidOperations, "expected a debit and a credit of the same amount")
+	}
+	return from, to, debit, nil
+}
+
+// decodeTransaction decodifica una transacción de la Construction API (solo transferencias de una cuenta
+// con clave: sin data, access list, multisig ni fee payer)
+func decodeTransaction(raw string) (*client.Transaction, *Error) {
+	var tx client.Transaction
+	if err := json.Unmarshal([]byte(raw), &tx); err != nil {
+		return nil, wrapErr(ErrInvalidTx, "%v", err)
+	}
+	if len(tx.Data) > 0 || len(tx.AccessList) > 0 || tx.Multisig != nil || tx.FeePayer != nil {
+		return nil, wrapErr(ErrInvalidTx, "only plain transfers are supported")
+	}
+	if !common.IsHexAddress(tx.From) || !common.IsHexAddress(tx.To) {
+		return nil, ErrInvalidAddress
+	}
+	return &tx, nil
+}
+
+// verifySender verifica el hash y la firma de la transacción como el nodo
+func verifySender(tx *client.Transaction) *Error {
+	signer, err := txsign.Verify(tx)
+	if err != nil {
+		return wrapErr(ErrInvalidSignature, "%v", err)
+	}
+	if !strings.EqualFold(signer.Hex(), tx.From) {
+		return wrapErr(ErrInvalidSignature, "signed by %s, not by %s", signer.Hex(), tx.From)
+	}
+	return nil
+}
+
+// toConsensus convierte una transferencia de la Construction API en una transacción del nodo
+func toConsensus(tx *client.Transaction) *consensus.Transaction {
+	return &consensus.Transaction{
+		Hash:      tx.Hash,
+		From:      tx.From,
+		To:        tx.To,
+		Value:     tx.Value,
+		GasLimit:  tx.GasLimit,
+		GasPrice:  tx.GasPrice,
+		Nonce:     tx.Nonce,
+		Signature: tx.Signature,
+		Timestamp: tx.Timestamp,
+	}
+}
+
+// decodeHex decodifica hex con o sin 0x (Rosetta usa hex sin prefijo)
+func decodeHex(value string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(value, "0x"))
+}
@@ -0,0 +1,325 @@
+package rosetta
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tipos y estados de las operaciones
+const (
+	OpTransfer = "TRANSFER" // Valor enviado: débito del remitente y crédito del destinatario
+	OpFee      = "FEE"      // Gas pagado (quemado): débito de quien paga el gas
+
+	StatusSuccess = "SUCCESS"
+	StatusFailure = "FAILURE" // Transacción incluida con ejecución fallida: solo se cobra el fee
+)
+
+// networkList maneja /network/list
+func (s *Server) networkList(ctx context.Context, req *networkRequest) (interface{}, *Error) {
+	return map[string]interface{}{"network_identifiers": []NetworkIdentifier{s.network}}, nil
+}
+
+// networkStatus maneja /network/status
+func (s *Server) networkStatus(ctx context.Context, req *networkRequest) (interface{}, *Error) {
+	current, err := s.latestBlock()
+	if err != nil {
+		return nil, err
+	}
+	genesis, err := s.blockAt(1)
+	if err != nil {
+		return nil, wrapErr(ErrUnavailable, "genesis block not available (the node needs the full block history)")
+	}
+
+	resp := map[string]interface{}{
+		"current_block_identifier": blockIdentifier(current),
+		"current_block_timestamp":  current.Header.Timestamp.UnixMilli(),
+		"genesis_block_identifier": blockIdentifier(genesis),
+		"peers":                    []Peer{},
+	}
+	if s.consensus != nil {
+		if status, err := s.consensus.GetSyncStatus(ctx); err == nil {
+			synced := !status.CatchingUp && status.BlocksBehind == 0
+			target := max(status.MaxPeerHeight, status.LatestHeight)
+			resp["sync_status"] = SyncStatus{CurrentIndex: &status.LatestHeight, TargetIndex: &target, Synced: &synced}
+		}
+	}
+	return resp, nil
+}
+
+// networkOptions maneja /network/options
+func (s *Server) networkOptions(ctx context.Context, req *networkRequest) (interface{}, *Error) {
+	return map[string]interface{}{
+		"version": Version{RosettaVersion: RosettaVersion, NodeVersion: consensus.AppVersion},
+		"allow": Allow{
+			OperationStatuses: []OperationStatus{
+				{Status: StatusSuccess, Successful: true},
+				{Status: StatusFailure, Successful: false},
+			},
+			OperationTypes:          []string{OpTransfer, OpFee},
+			Errors:                  allErrors,
+			HistoricalBalanceLookup: true,
+			CallMethods:             []string{},
+			BalanceExemptions:       []interface{}{},
+		},
+	}, nil
+}
+
+// block maneja /block
+func (s *Server) block(ctx context.Context, req *blockRequest) (interface{}, *Error) {
+	block, err := s.findBlock(&req.BlockIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.parentIdentifier(block)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*Transaction, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		transactions = append(transactions, blockTransaction(tx, findReceipt(block, tx.Hash)))
+	}
+	return map[string]interface{}{
+		"block": Block{
+			BlockIdentifier:       blockIdentifier(block),
+			ParentBlockIdentifier: parent,
+			Timestamp:             block.Header.Timestamp.UnixMilli(),
+			Transactions:          transactions,
+		},
+	}, nil
+}
+
+// blockTransaction maneja /block/transaction
+func (s *Server) blockTransaction(ctx context.Context, req *blockTransactionRequest) (interface{}, *Error) {
+	index, hash := req.BlockIdentifier.Index, req.BlockIdentifier.Hash
+	block, err := s.findBlock(&PartialBlockIdentifier{Index: &index, Hash: &hash})
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range block.Transactions {
+		if strings.EqualFold(tx.Hash, req.TransactionIdentifier.Hash) {
+			return map[string]interface{}{"transaction": blockTransaction(tx, findReceipt(block, tx.Hash))}, nil
+		}
+	}
+	return nil, wrapErr(ErrTxNotFound, "%s not in block %d", req.TransactionIdentifier.Hash, index)
+}
+
+// accountBalance maneja /account/balance: el balance tras el bloque pedido (por defecto el último)
+func (s *Server) accountBalance(ctx context.Context, req *accountBalanceRequest) (interface{}, *Error) {
+	if req.AccountIdentifier == nil || !common.IsHexAddress(req.AccountIdentifier.Address) {
+		return nil, ErrInvalidAddress
+	}
+	if s.executor == nil {
+		return nil, ErrUnavailable
+	}
+	partial := req.BlockIdentifier
+	if partial == nil {
+		partial = &PartialBlockIdentifier{}
+	}
+	block, rerr := s.findBlock(partial)
+	if rerr != nil {
+		return nil, rerr
+	}
+
+	account, err := s.executor.GetStateAtHeight(req.AccountIdentifier.Address, block.Header.Height)
+	if err != nil {
+		return nil, wrapErr(ErrStateNotAvailable, "%v", err)
+	}
+	return map[string]interface{}{
+		"block_identifier": blockIdentifier(block),
+		"balances":         []Amount{{Value: account.Balance, Currency: OXG}},
+		"metadata":         map[string]interface{}{"nonce": account.Nonce},
+	}, nil
+}
+
+// mempool maneja /mempool
+func (s *Server) mempool(ctx context.Context, req *networkRequest) (interface{}, *Error) {
+	if s.consensus == nil {
+		return nil, ErrUnavailable
+	}
+	identifiers := []TransactionIdentifier{}
+	for _, tx := range s.consensus.GetMempool() {
+		identifiers = append(identifiers, TransactionIdentifier{Hash: tx.Hash})
+	}
+	return map[string]interface{}{"transaction_identifiers": identifiers}, nil
+}
+
+// mempoolTransaction maneja /mempool/transaction (operaciones sin estado: la transacción no se ejecutó)
+func (s *Server) mempoolTransaction(ctx context.Context, req *mempoolTransactionRequest) (interface{}, *Error) {
+	if s.consensus == nil {
+		return nil, ErrUnavailable
+	}
+	for _, tx := range s.consensus.GetMempool() {
+		if strings.EqualFold(tx.Hash, req.TransactionIdentifier.Hash) {
+			return map[string]interface{}{
+				"transaction": &Transaction{
+					TransactionIdentifier: TransactionIdentifier{Hash: tx.Hash},
+					Operations:            transferOperations(tx, nil),
+				},
+			}, nil
+		}
+	}
+	return nil, wrapErr(ErrTxNotFound, "%s not in mempool", req.TransactionIdentifier.Hash)
+}
+
+// latestBlock retorna el último bloque guardado
+func (s *Server) latestBlock() (*consensus.Block, *Error) {
+	height, err := s.storage.GetLatestHeight()
+	if err != nil || height == 0 {
+		return nil, wrapErr(ErrUnavailable, "no blocks yet")
+	}
+	return s.blockAt(height)
+}
+
+// blockAt retorna el bloque canónico de una altura
+func (s *Server) blockAt(height uint64) (*consensus.Block, *Error) {
+	data, err := s.storage.GetBlock(height)
+	if err != nil {
+		return nil, wrapErr(ErrBlockNotFound, "height %d", height)
+	}
+	block, err := consensus.DecodeBlock(data)
+	if err != nil {
+		return nil, wrapErr(ErrUnavailable, "error decoding block %d: %v", height, err)
+	}
+	return block, nil
+}
+
+// findBlock busca un bloque canónico por altura y/o hash (ninguno = el último)
+func (s *Server) findBlock(partial *PartialBlockIdentifier) (*consensus.Block, *Error) {
+	var block *consensus.Block
+	var rerr *Error
+	switch {
+	case partial.Index != nil:
+		if *partial.Index < 1 {
+			return nil, wrapErr(ErrBlockNotFound, "height %d", *partial.Index)
+		}
+		block, rerr = s.blockAt(uint64(*partial.Index))
+	case partial.Hash != nil:
+		data, err := s.storage.GetBlockByHash(*partial.Hash)
+		if err != nil {
+			return nil, wrapErr(ErrBlockNotFound, "hash %s", *partial.Hash)
+		}
+		decoded, err := consensus.DecodeBlock(data)
+		if err != nil {
+			return nil, wrapErr(ErrUnavailable, "error decoding block %s: %v", *partial.Hash, err)
+		}
+		// Un bloque reemplazado por un reorg sigue guardado por hash pero ya no es canónico
+		if canonical, err := s.storage.GetCanonicalHash(decoded.Header.Height); err == nil && !strings.EqualFold(canonical, decoded.Header.Hash) {
+			return nil, wrapErr(ErrBlockNotFound, "block %s is not canonical", *partial.Hash)
+		}
+		block = decoded
+	default:
+		block, rerr = s.latestBlock()
+	}
+	if rerr != nil {
+		return nil, rerr
+	}
+	if partial.Hash != nil && !strings.EqualFold(block.Header.Hash, *partial.Hash) {
+		return nil, wrapErr(ErrBlockNotFound, "block %d has hash %s", block.Header.Height, block.Header.Hash)
+	}
+	return block, nil
+}
+
+// parentIdentifier retorna el bloque anterior (el génesis es su propio padre, como pide Rosetta)
+func (s *Server) parentIdentifier(block *consensus.Block) (BlockIdentifier, *Error) {
+	if block.Header.Height <= 1 {
+		return blockIdentifier(block), nil
+	}
+	if block.Header.ParentHash != "" {
+		return BlockIdentifier{Index: int64(block.Header.Height - 1), Hash: block.Header.ParentHash}, nil
+	}
+	parent, err := s.blockAt(block.Header.Height - 1)
+	if err != nil {
+		return BlockIdentifier{}, err
+	}
+	return blockIdentifier(parent), nil
+}
+
+// blockIdentifier retorna el identificador de un bloque
+func blockIdentifier(block *consensus.Block) BlockIdentifier {
+	return BlockIdentifier{Index: int64(block.Header.Height), Hash: block.Header.Hash}
+}
+
+// findReceipt retorna el receipt de una transacción del bloque
+func findReceipt(block *consensus.Block, hash string) *consensus.TransactionReceipt {
+	for _, receipt := range block.Receipts {
+		if receipt.TransactionHash == hash {
+			return receipt
+		}
+	}
+	return nil
+}
+
+// blockTransaction convierte una transacción incluida en sus operaciones: TRANSFER con el estado de la
+// ejecución y FEE (siempre exitosa: el gas se cobra aunque la ejecución falle)
+func blockTransaction(tx *consensus.Transaction, receipt *consensus.TransactionReceipt) *Transaction {
+	status := StatusSuccess
+	if receipt != nil && receipt.Status != "success" {
+		status = StatusFailure
+	}
+	operations := transferOperations(tx, &status)
+
+	if receipt != nil {
+		gasPrice, ok := new(big.Int).SetString(tx.GasPrice, 10)
+		if ok && gasPrice.Sign() > 0 && receipt.GasUsed > 0 {
+			fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+			payer := tx.From
+			if tx.FeePayer != nil && tx.FeePayer.Address != "" {
+				payer = tx.FeePayer.Address
+			}
+			success := StatusSuccess
+			operations = append(operations, &Operation{
+				OperationIdentifier: OperationIdentifier{Index: int64(len(operations))},
+				Type:                OpFee,
+				Status:              &success,
+				Account:             &AccountIdentifier{Address: checksum(payer)},
+				Amount:              &Amount{Value: new(big.Int).Neg(fee).String(), Currency: OXG},
+			})
+		}
+	}
+
+	result := &Transaction{
+		TransactionIdentifier: TransactionIdentifier{Hash: tx.Hash},
+		Operations:            operations,
+	}
+	if receipt != nil && receipt.Error != "" {
+		result.Metadata = map[string]interface{}{"error": receipt.Error}
+	}
+	return result
+}
+
+// transferOperations retorna las operaciones TRANSFER del valor enviado (ninguna sin valor). Una creación
+// de contrato solo tiene el débito: la dirección del contrato no queda en la transacción
+func transferOperations(tx *consensus.Transaction, status *string) []*Operation {
+	value, ok := new(big.Int).SetString(tx.Value, 10)
+	if !ok || value.Sign() <= 0 {
+		return []*Operation{}
+	}
+	operations := []*Operation{{
+		OperationIdentifier: OperationIdentifier{Index: 0},
+		Type:                OpTransfer,
+		Status:              status,
+		Account:             &AccountIdentifier{Address: checksum(tx.From)},
+		Amount:              &Amount{Value: new(big.Int).Neg(value).String(), Currency: OXG},
+	}}
+	if tx.To != "" {
+		operations = append(operations, &Operation{
+			OperationIdentifier: OperationIdentifier{Index: 1},
+			RelatedOperations:   []OperationIdentifier{{Index: 0}},
+			Type:                OpTransfer,
+			Status:              status,
+			Account:             &AccountIdentifier{Address: checksum(tx.To)},
+			Amount:              &Amount{Value: value.String(), Currency: OXG},
+		})
+	}
+	return operations
+}
+
+// checksum normaliza una dirección (EIP-55) para que una cuenta tenga un solo identificador
+func checksum(address string) string {
+	return common.HexToAddress(address).Hex()
+}
@@ -0,0 +1,49 @@
+package rosetta
+
+import "fmt"
+
+// Error es un error de Rosetta: un código fijo del catálogo (ver /network/options) con detalles opcionales
+type Error struct {
+	Code      int32                  `json:"code"`
+	Message   string                 `json:"message"`
+	Retriable bool                   `json:"retriable"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// Error implementa error
+func (e *Error) Error() string {
+	if reason, ok := e.Details["reason"]; ok {
+		return fmt.Sprintf("%s: %v", e.Message, reason)
+	}
+	return e.Message
+}
+
+// Catálogo de errores (los códigos no deben cambiar: los clientes los guardan)
+var (
+	ErrUnavailable       = &Error{Code: 1, Message: "Node not available", Retriable: true}
+	ErrInvalidRequest    = &Error{Code: 2, Message: "Invalid request"}
+	ErrInvalidNetwork    = &Error{Code: 3, Message: "Invalid network identifier"}
+	ErrBlockNotFound     = &Error{Code: 4, Message: "Block not found", Retriable: true}
+	ErrTxNotFound        = &Error{Code: 5, Message: "Transaction not found", Retriable: true}
+	ErrInvalidAddress    = &Error{Code: 6, Message: "Invalid address"}
+	ErrStateNotAvailable = &Error{Code: 7, Message: "State not available at block"}
+	ErrInvalidPublicKey  = &Error{Code: 8, Message: "Invalid public key"}
+	ErrInvalidOperations = &Error{Code: 9, Message: "Unsupported operations"}
+	ErrInvalidTx         = &Error{Code: 10, Message: "Invalid transaction"}
+	ErrInvalidSignature  = &Error{Code: 11, Message: "Invalid signature"}
+	ErrSubmitFailed      = &Error{Code: 12, Message: "Transaction rejected"}
+)
+
+// allErrors es el catálogo que se publica en /network/options
+var allErrors = []*Error{
+	ErrUnavailable, ErrInvalidRequest, ErrInvalidNetwork, ErrBlockNotFound, ErrTxNotFound, ErrInvalidAddress,
+	ErrStateNotAvailable, ErrInvalidPublicKey, ErrInvalidOperations, ErrInvalidTx, ErrInvalidSignature,
+	ErrSubmitFailed,
+}
+
+// wrapErr retorna una copia del error del catálogo con el motivo en details
+func wrapErr(base *Error, format string, args ...interface{}) *Error {
+	err := *base
+	err.Details = map[string]interface{}{"reason": fmt.Sprintf(format, args...)}
+	return &err
+}
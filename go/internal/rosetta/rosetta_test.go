@@ -0,0 +1,141 @@
+package rosetta
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// transfer retorna las operaciones de una transferencia de value wei
+func transfer(from, to string, value string) []*Operation {
+	return []*Operation{
+		{OperationIdentifier: OperationIdentifier{Index: 0}, Type: OpTransfer, Account: &AccountIdentifier{Address: from}, Amount: &Amount{Value: "-" + value, Currency: OXG}},
+		{OperationIdentifier: OperationIdentifier{Index: 1}, Type: OpTransfer, Account: &AccountIdentifier{Address: to}, Amount: &Amount{Value: value, Currency: OXG}},
+	}
+}
+
+func TestConstructionFlow(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer("localhost", "0", "test-chain", nil, nil, nil)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived, rerr := server.constructionDerive(ctx, &deriveRequest{PublicKey: &PublicKey{
+		HexBytes:  hex.EncodeToString(crypto.CompressPubkey(&key.PublicKey)),
+		CurveType: CurveSecp256k1,
+	}})
+	if rerr != nil {
+		t.Fatalf("derive: %v", rerr)
+	}
+	from := derived.(map[string]interface{})["account_identifier"].(AccountIdentifier).Address
+	if from != crypto.PubkeyToAddress(key.PublicKey).Hex() {
+		t.Fatalf("derive: got %s", from)
+	}
+	to := common.HexToAddress("0x2").Hex()
+
+	payloads, rerr := server.constructionPayloads(ctx, &payloadsRequest{
+		Operations: transfer(from, to, "1000"),
+		Metadata:   constructionMetadata{Nonce: 3, GasPrice: "2", GasLimit: 21000},
+	})
+	if rerr != nil {
+		t.Fatalf("payloads: %v", rerr)
+	}
+	unsigned := payloads.(map[string]interface{})["unsigned_transaction"].(string)
+	payload := payloads.(map[string]interface{})["payloads"].([]SigningPayload)[0]
+
+	hash, _ := hex.DecodeString(payload.HexBytes)
+	signature, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	combined, rerr := server.constructionCombine(ctx, &combineRequest{
+		UnsignedTransaction: unsigned,
+		Signatures:          []*Signature{{SigningPayload: &payload, SignatureType: SignatureEcdsaRecovery, HexBytes: hex.EncodeToString(signature)}},
+	})
+	if rerr != nil {
+		t.Fatalf("combine: %v", rerr)
+	}
+	signed := combined.(map[string]interface{})["signed_transaction"].(string)
+
+	parsed, rerr := server.constructionParse(ctx, &parseRequest{Signed: true, Transaction: signed})
+	if rerr != nil {
+		t.Fatalf("parse: %v", rerr)
+	}
+	operations := parsed.(map[string]interface{})["operations"].([]*Operation)
+	if len(operations) != 2 || operations[0].Amount.Value != "-1000" || operations[1].Account.Address != to {
+		t.Fatalf("parse: unexpected operations %+v", operations)
+	}
+	if signers := parsed.(map[string]interface{})["account_identifier_signers"].([]AccountIdentifier); signers[0].Address != from {
+		t.Fatalf("parse: signers %v", signers)
+	}
+
+	hashed, rerr := server.constructionHash(ctx, &signedTransactionRequest{SignedTransaction: signed})
+	if rerr != nil {
+		t.Fatalf("hash: %v", rerr)
+	}
+	if got := hashed.(map[string]interface{})["transaction_identifier"].(TransactionIdentifier).Hash; got != "0x"+payload.HexBytes {
+		t.Fatalf("hash: got %s, want 0x%s", got, payload.HexBytes)
+	}
+
+	// Sin consenso no se puede enviar
+	if _, rerr := server.constructionSubmit(ctx, &signedTransactionRequest{SignedTransaction: signed}); rerr == nil || rerr.Code != ErrUnavailable.Code {
+		t.Fatalf("submit: expected unavailable, got %v", rerr)
+	}
+
+	// Una firma de otra cuenta se rechaza
+	other, _ := crypto.GenerateKey()
+	wrong, _ := crypto.Sign(hash, other)
+	if _, rerr := server.constructionCombine(ctx, &combineRequest{
+		UnsignedTransaction: unsigned,
+		Signatures:          []*Signature{{HexBytes: hex.EncodeToString(wrong)}},
+	}); rerr == nil || rerr.Code != ErrInvalidSignature.Code {
+		t.Fatalf("combine with wrong key: expected invalid signature, got %v", rerr)
+	}
+}
+
+func TestParseTransferRejectsUnbalanced(t *testing.T) {
+	from, to := common.HexToAddress("0x1").Hex(), common.HexToAddress("0x2").Hex()
+	operations := transfer(from, to, "10")
+	operations[1].Amount.Value = "9"
+	if _, _, _, rerr := parseTransfer(operations); rerr == nil || rerr.Code != ErrInvalidOperations.Code {
+		t.Fatalf("expected invalid operations, got %v", rerr)
+	}
+	if _, _, _, rerr := parseTransfer(operations[:1]); rerr == nil {
+		t.Fatal("expected error for a single operation")
+	}
+}
+
+func TestBlockTransactionOperations(t *testing.T) {
+	from, to, payer := common.HexToAddress("0x1").Hex(), common.HexToAddress("0x2").Hex(), common.HexToAddress("0x3").Hex()
+	tx := &consensus.Transaction{Hash: "0xabc", From: from, To: to, Value: "100", GasPrice: "2"}
+
+	// Ejecución fallida: la transferencia falla pero el fee se cobra
+	result := blockTransaction(tx, &consensus.TransactionReceipt{TransactionHash: "0xabc", GasUsed: 21000, Status: "failed"})
+	if len(result.Operations) != 3 {
+		t.Fatalf("expected 3 operations, got %d", len(result.Operations))
+	}
+	if *result.Operations[0].Status != StatusFailure || *result.Operations[1].Status != StatusFailure {
+		t.Fatal("expected failed transfer operations")
+	}
+	fee := result.Operations[2]
+	if fee.Type != OpFee || *fee.Status != StatusSuccess || fee.Account.Address != from || fee.Amount.Value != "-42000" {
+		t.Fatalf("unexpected fee operation %+v", fee)
+	}
+
+	// Transacción patrocinada sin valor: solo el fee, debitado al fee payer
+	tx = &consensus.Transaction{Hash: "0xdef", From: from, To: to, Value: "0", GasPrice: "1", FeePayer: &consensus.FeePayerAuth{Address: payer}}
+	result = blockTransaction(tx, &consensus.TransactionReceipt{TransactionHash: "0xdef", GasUsed: 30000, Status: "success"})
+	if len(result.Operations) != 1 || result.Operations[0].Account.Address != payer {
+		t.Fatalf("unexpected operations %+v", result.Operations)
+	}
+	if result.Operations[0].Amount.Value != new(big.Int).Neg(big.NewInt(30000)).String() {
+		t.Fatalf("unexpected fee %s", result.Operations[0].Amount.Value)
+	}
+}
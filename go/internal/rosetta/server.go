@@ -0,0 +1,171 @@
+// Package rosetta implementa la Data API y la Construction API de Rosetta sobre el storage y el executor
+// del nodo, para integraciones (exchanges, custodios) que usan Rosetta como interfaz estándar.
+//
+// Las operaciones de cada transacción son TRANSFER (débito del remitente y crédito del destinatario por el
+// valor enviado) y FEE (débito de quien paga el gas por gasUsed*gasPrice; los fees se queman). La
+// Construction API arma transferencias de OXG firmadas con secp256k1 (ecdsa_recovery) sobre el mismo hash
+// que verifica el nodo en CheckTx.
+package rosetta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/consensus"
+	"github.com/Q-YZX0/oxy-blockchain/internal/execution"
+	"github.com/Q-YZX0/oxy-blockchain/internal/logger"
+	"github.com/Q-YZX0/oxy-blockchain/internal/storage"
+)
+
+// rosettaLog es el logger del módulo rosetta
+var rosettaLog = logger.For("rosetta")
+
+const (
+	// Blockchain es el nombre de la cadena en los network identifiers (network es el chain ID)
+	Blockchain = "oxy-gen"
+	// maxRequestBytes limita el body de los requests
+	maxRequestBytes = 1 << 20
+	// submitTimeout es el tiempo máximo de /construction/submit (espera el resultado de CheckTx)
+	submitTimeout = 30 * time.Second
+)
+
+// OXG es la moneda nativa (montos en wei)
+var OXG = Currency{Symbol: "OXG", Decimals: 18}
+
+// Server es el servidor HTTP de la API de Rosetta
+type Server struct {
+	host        string
+	port        string
+	network     NetworkIdentifier
+	minGasPrice *big.Int // Precio de gas sugerido en /construction/metadata (nil = 1 wei)
+	storage     *storage.BlockchainDB
+	consensus   *consensus.CometBFT
+	executor    *execution.EVMExecutor
+	server      *http.Server
+}
+
+// NewServer crea el servidor de Rosetta para la red chainID
+func NewServer(
+	host string,
+	port string,
+	chainID string,
+	storage *storage.BlockchainDB,
+	consensus *consensus.CometBFT,
+	executor *execution.EVMExecutor,
+) *Server {
+	return &Server{
+		host:      host,
+		port:      port,
+		network:   NetworkIdentifier{Blockchain: Blockchain, Network: chainID},
+		storage:   storage,
+		consensus: consensus,
+		executor:  executor,
+	}
+}
+
+// SetMinGasPrice configura el precio de gas sugerido en /construction/metadata (el mínimo del nodo)
+func (s *Server) SetMinGasPrice(price *big.Int) {
+	s.minGasPrice = price
+}
+
+// Handler retorna el handler HTTP con todos los endpoints de Rosetta
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	// Data API
+	mux.HandleFunc("/network/list", handle(s, s.networkList))
+	mux.HandleFunc("/network/status", handle(s, s.networkStatus))
+	mux.HandleFunc("/network/options", handle(s, s.networkOptions))
+	mux.HandleFunc("/block", handle(s, s.block))
+	mux.HandleFunc("/block/transaction", handle(s, s.blockTransaction))
+	mux.HandleFunc("/account/balance", handle(s, s.accountBalance))
+	mux.HandleFunc("/mempool", handle(s, s.mempool))
+	mux.HandleFunc("/mempool/transaction", handle(s, s.mempoolTransaction))
+
+	// Construction API
+	mux.HandleFunc("/construction/derive", handle(s, s.constructionDerive))
+	mux.HandleFunc("/construction/preprocess", handle(s, s.constructionPreprocess))
+	mux.HandleFunc("/construction/metadata", handle(s, s.constructionMetadata))
+	mux.HandleFunc("/construction/payloads", handle(s, s.constructionPayloads))
+	mux.HandleFunc("/construction/combine", handle(s, s.constructionCombine))
+	mux.HandleFunc("/construction/parse", handle(s, s.constructionParse))
+	mux.HandleFunc("/construction/hash", handle(s, s.constructionHash))
+	mux.HandleFunc("/construction/submit", handle(s, s.constructionSubmit))
+	return mux
+}
+
+// Start inicia el servidor (bloquea hasta que se detiene)
+func (s *Server) Start() error {
+	s.server = &http.Server{
+		Addr:         s.host + ":" + s.port,
+		Handler:      s.Handler(),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: submitTimeout + 5*time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+	rosettaLog.Infof("API de Rosetta escuchando en %s (red %s/%s)", s.server.Addr, s.network.Blockchain, s.network.Network)
+	return s.server.ListenAndServe()
+}
+
+// Stop detiene el servidor
+func (s *Server) Stop() error {
+	if s.server != nil {
+		return s.server.Close()
+	}
+	return nil
+}
+
+// handle adapta un endpoint: todos son POST con un body JSON que lleva network_identifier, que debe ser el
+// de este nodo (salvo en /network/list). Los errores se responden con HTTP 500 y el Error de Rosetta
+func handle[T any](s *Server, fn func(ctx context.Context, req *T) (interface{}, *Error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes))
+		if err != nil {
+			writeResponse(w, nil, wrapErr(ErrInvalidRequest, "%v", err))
+			return
+		}
+
+		if r.URL.Path != "/network/list" {
+			var network networkRequest
+			if err := json.Unmarshal(body, &network); err != nil {
+				writeResponse(w, nil, wrapErr(ErrInvalidRequest, "%v", err))
+				return
+			}
+			if network.NetworkIdentifier == nil || *network.NetworkIdentifier != s.network {
+				writeResponse(w, nil, wrapErr(ErrInvalidNetwork, "expected %s/%s", s.network.Blockchain, s.network.Network))
+				return
+			}
+		}
+
+		var req T
+		if len(bytes.TrimSpace(body)) > 0 {
+			if err := json.Unmarshal(body, &req); err != nil {
+				writeResponse(w, nil, wrapErr(ErrInvalidRequest, "%v", err))
+				return
+			}
+		}
+		resp, rerr := fn(r.Context(), &req)
+		writeResponse(w, resp, rerr)
+	}
+}
+
+// writeResponse escribe la respuesta o el error de Rosetta
+func writeResponse(w http.ResponseWriter, resp interface{}, rerr *Error) {
+	w.Header().Set("Content-Type", "application/json")
+	if rerr != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(rerr)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,190 @@
+package rosetta
+
+// Modelos de la especificación de Rosetta (solo los campos que usa este servidor)
+// https://www.rosetta-api.org/docs/Reference.html
+
+// RosettaVersion es la versión de la especificación implementada
+const RosettaVersion = "1.4.13"
+
+// NetworkIdentifier identifica la red
+type NetworkIdentifier struct {
+	Blockchain string `json:"blockchain"`
+	Network    string `json:"network"`
+}
+
+// BlockIdentifier identifica un bloque
+type BlockIdentifier struct {
+	Index int64  `json:"index"`
+	Hash  string `json:"hash"`
+}
+
+// PartialBlockIdentifier identifica un bloque por altura o hash (ninguno = el último)
+type PartialBlockIdentifier struct {
+	Index *int64  `json:"index,omitempty"`
+	Hash  *string `json:"hash,omitempty"`
+}
+
+// TransactionIdentifier identifica una transacción
+type TransactionIdentifier struct {
+	Hash string `json:"hash"`
+}
+
+// AccountIdentifier identifica una cuenta
+type AccountIdentifier struct {
+	Address string `json:"address"`
+}
+
+// Currency es la moneda de un monto
+type Currency struct {
+	Symbol   string `json:"symbol"`
+	Decimals int32  `json:"decimals"`
+}
+
+// Amount es un monto con signo en la unidad mínima de la moneda
+type Amount struct {
+	Value    string   `json:"value"`
+	Currency Currency `json:"currency"`
+}
+
+// OperationIdentifier identifica una operación dentro de una transacción
+type OperationIdentifier struct {
+	Index int64 `json:"index"`
+}
+
+// Operation es un cambio de balance de una cuenta
+type Operation struct {
+	OperationIdentifier OperationIdentifier   `json:"operation_identifier"`
+	RelatedOperations   []OperationIdentifier `json:"related_operations,omitempty"`
+	Type                string                `json:"type"`
+	Status              *string               `json:"status,omitempty"` // Vacío en construcción y mempool
+	Account             *AccountIdentifier    `json:"account,omitempty"`
+	Amount              *Amount               `json:"amount,omitempty"`
+}
+
+// Transaction es una transacción con sus operaciones
+type Transaction struct {
+	TransactionIdentifier TransactionIdentifier  `json:"transaction_identifier"`
+	Operations            []*Operation           `json:"operations"`
+	Metadata              map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Block es un bloque con sus transacciones
+type Block struct {
+	BlockIdentifier       BlockIdentifier `json:"block_identifier"`
+	ParentBlockIdentifier BlockIdentifier `json:"parent_block_identifier"`
+	Timestamp             int64           `json:"timestamp"` // Milisegundos unix
+	Transactions          []*Transaction  `json:"transactions"`
+}
+
+// PublicKey es una clave pública en hex (sin 0x)
+type PublicKey struct {
+	HexBytes  string `json:"hex_bytes"`
+	CurveType string `json:"curve_type"`
+}
+
+// SigningPayload es lo que debe firmar una cuenta
+type SigningPayload struct {
+	AccountIdentifier *AccountIdentifier `json:"account_identifier"`
+	HexBytes          string             `json:"hex_bytes"`
+	SignatureType     string             `json:"signature_type"`
+}
+
+// Signature es la firma de un SigningPayload
+type Signature struct {
+	SigningPayload *SigningPayload `json:"signing_payload"`
+	PublicKey      *PublicKey      `json:"public_key"`
+	SignatureType  string          `json:"signature_type"`
+	HexBytes       string          `json:"hex_bytes"`
+}
+
+// Peer es un peer del nodo
+type Peer struct {
+	PeerID string `json:"peer_id"`
+}
+
+// SyncStatus es el estado de sincronización del nodo
+type SyncStatus struct {
+	CurrentIndex *int64 `json:"current_index,omitempty"`
+	TargetIndex  *int64 `json:"target_index,omitempty"`
+	Synced       *bool  `json:"synced,omitempty"`
+}
+
+// Version son las versiones de la especificación y del nodo
+type Version struct {
+	RosettaVersion string `json:"rosetta_version"`
+	NodeVersion    string `json:"node_version"`
+}
+
+// OperationStatus es un estado de operación y si cambia balances
+type OperationStatus struct {
+	Status     string `json:"status"`
+	Successful bool   `json:"successful"`
+}
+
+// Allow describe lo que soporta la implementación
+type Allow struct {
+	OperationStatuses       []OperationStatus `json:"operation_statuses"`
+	OperationTypes          []string          `json:"operation_types"`
+	Errors                  []*Error          `json:"errors"`
+	HistoricalBalanceLookup bool              `json:"historical_balance_lookup"`
+	CallMethods             []string          `json:"call_methods"`
+	BalanceExemptions       []interface{}     `json:"balance_exemptions"`
+	MempoolCoins            bool              `json:"mempool_coins"`
+}
+
+// Requests: todos llevan network_identifier
+type (
+	networkRequest struct {
+		NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+	}
+	blockRequest struct {
+		NetworkIdentifier *NetworkIdentifier     `json:"network_identifier"`
+		BlockIdentifier   PartialBlockIdentifier `json:"block_identifier"`
+	}
+	blockTransactionRequest struct {
+		NetworkIdentifier     *NetworkIdentifier    `json:"network_identifier"`
+		BlockIdentifier       BlockIdentifier       `json:"block_identifier"`
+		TransactionIdentifier TransactionIdentifier `json:"transaction_identifier"`
+	}
+	accountBalanceRequest struct {
+		NetworkIdentifier *NetworkIdentifier      `json:"network_identifier"`
+		AccountIdentifier *AccountIdentifier      `json:"account_identifier"`
+		BlockIdentifier   *PartialBlockIdentifier `json:"block_identifier,omitempty"`
+	}
+	mempoolTransactionRequest struct {
+		NetworkIdentifier     *NetworkIdentifier    `json:"network_identifier"`
+		TransactionIdentifier TransactionIdentifier `json:"transaction_identifier"`
+	}
+	deriveRequest struct {
+		NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+		PublicKey         *PublicKey         `json:"public_key"`
+	}
+	preprocessRequest struct {
+		NetworkIdentifier *NetworkIdentifier     `json:"network_identifier"`
+		Operations        []*Operation           `json:"operations"`
+		Metadata          map[string]interface{} `json:"metadata"`
+	}
+	metadataRequest struct {
+		NetworkIdentifier *NetworkIdentifier  `json:"network_identifier"`
+		Options           constructionOptions `json:"options"`
+	}
+	payloadsRequest struct {
+		NetworkIdentifier *NetworkIdentifier   `json:"network_identifier"`
+		Operations        []*Operation         `json:"operations"`
+		Metadata          constructionMetadata `json:"metadata"`
+	}
+	combineRequest struct {
+		NetworkIdentifier   *NetworkIdentifier `json:"network_identifier"`
+		UnsignedTransaction string             `json:"unsigned_transaction"`
+		Signatures          []*Signature       `json:"signatures"`
+	}
+	parseRequest struct {
+		NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+		Signed            bool               `json:"signed"`
+		Transaction       string             `json:"transaction"`
+	}
+	signedTransactionRequest struct {
+		NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+		SignedTransaction string             `json:"signed_transaction"`
+	}
+)
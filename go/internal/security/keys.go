@@ -75,6 +75,11 @@ func LoadPrivateKeyFromEnv(keyEnvVar string) (*ecdsa.PrivateKey, error) {
 		return nil, fmt.Errorf("variable de entorno %s no configurada", keyEnvVar)
 	}
 
+	return ParsePrivateKey(privateKeyHex)
+}
+
+// ParsePrivateKey decodifica una clave privada en hex (con o sin prefijo "0x")
+func ParsePrivateKey(privateKeyHex string) (*ecdsa.PrivateKey, error) {
 	// Remover prefijo "0x" si existe
 	if len(privateKeyHex) > 2 && privateKeyHex[:2] == "0x" {
 		privateKeyHex = privateKeyHex[2:]
@@ -0,0 +1,94 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// fileMagic identifica un archivo de snapshot exportado
+const fileMagic = "OXYSNAP1"
+
+// maxHeaderSize limita la línea de metadata al leer un archivo de snapshot
+const maxHeaderSize = 1 << 20
+
+// Export escribe el snapshot a la altura dada como archivo portable:
+// una línea con la marca, una línea con la metadata en JSON y el contenido
+func (s *Store) Export(height uint64, w io.Writer) (*Metadata, error) {
+	file, meta, err := s.Open(height)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	header, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("error serializando metadata: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "%s\n%s\n", fileMagic, header); err != nil {
+		return nil, err
+	}
+
+	written, err := io.Copy(w, file)
+	if err != nil {
+		return nil, fmt.Errorf("error exportando snapshot %d: %w", height, err)
+	}
+	if written != meta.Size {
+		return nil, fmt.Errorf("snapshot %d incompleto: %d de %d bytes", height, written, meta.Size)
+	}
+	return meta, nil
+}
+
+// Import lee un archivo de snapshot exportado, verifica su hash y lo agrega al store
+func (s *Store) Import(r io.Reader) (*Metadata, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := readLine(br)
+	if err != nil || magic != fileMagic {
+		return nil, fmt.Errorf("no es un archivo de snapshot de Oxy•gen")
+	}
+	header, err := readLine(br)
+	if err != nil {
+		return nil, fmt.Errorf("archivo de snapshot sin metadata: %w", err)
+	}
+
+	var meta Metadata
+	if err := json.Unmarshal([]byte(header), &meta); err != nil {
+		return nil, fmt.Errorf("metadata de snapshot inválida: %w", err)
+	}
+	if meta.Format != Format {
+		return nil, fmt.Errorf("formato de snapshot no soportado: %d", meta.Format)
+	}
+
+	receiver, err := s.Receive(&meta)
+	if err != nil {
+		return nil, err
+	}
+	// Leer como máximo el tamaño declarado: datos adicionales invalidan el archivo
+	if _, err := io.Copy(receiver, io.LimitReader(br, meta.Size+1)); err != nil {
+		receiver.Abort()
+		return nil, fmt.Errorf("error leyendo archivo de snapshot: %w", err)
+	}
+	if err := receiver.Commit(); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// readLine lee una línea de la cabecera sin el salto de línea
+func readLine(r *bufio.Reader) (string, error) {
+	var line strings.Builder
+	for line.Len() <= maxHeaderSize {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		line.Write(chunk)
+		if !isPrefix {
+			return line.String(), nil
+		}
+	}
+	return "", fmt.Errorf("cabecera de snapshot demasiado grande")
+}
@@ -0,0 +1,172 @@
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Format es la versión del formato de contenido de los snapshots
+const Format uint32 = 1
+
+// Marcas de registro dentro del contenido
+const (
+	recordSection byte = 1 // Inicio de una base de datos: nombre
+	recordEntry   byte = 2 // Entrada clave/valor
+	recordEnd     byte = 3 // Fin de la base de datos en curso
+)
+
+// maxFieldSize limita el tamaño de claves, valores y nombres al decodificar
+const maxFieldSize = 64 << 20
+
+// KVStore es una base de datos clave/valor que forma parte del snapshot
+type KVStore interface {
+	// ExportKV recorre todas las entradas en orden
+	ExportKV(fn func(key, value []byte) error) error
+	// ImportKV reemplaza todo el contenido por las entradas que entrega la función
+	ImportKV(entries func(put func(key, value []byte) error) error) error
+}
+
+// Source es una base de datos con nombre incluida en el snapshot
+type Source struct {
+	Name  string
+	Store KVStore
+}
+
+// Encode escribe el contenido de las bases de datos (en el orden dado) comprimido con gzip
+func Encode(w io.Writer, sources []Source) error {
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+
+	for _, source := range sources {
+		if err := writeRecord(bw, recordSection, []byte(source.Name)); err != nil {
+			return err
+		}
+		err := source.Store.ExportKV(func(key, value []byte) error {
+			return writeRecord(bw, recordEntry, key, value)
+		})
+		if err != nil {
+			return fmt.Errorf("error exportando %s: %w", source.Name, err)
+		}
+		if err := writeRecord(bw, recordEnd); err != nil {
+			return err
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Decode importa el contenido en las bases de datos, que deben aparecer en el mismo orden que al codificar
+func Decode(r io.Reader, sources []Source) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("contenido de snapshot inválido: %w", err)
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+
+	for _, source := range sources {
+		kind, fields, err := readRecord(br)
+		if err != nil {
+			return err
+		}
+		if kind != recordSection || string(fields[0]) != source.Name {
+			return fmt.Errorf("snapshot inválido: se esperaba la base de datos %s", source.Name)
+		}
+
+		err = source.Store.ImportKV(func(put func(key, value []byte) error) error {
+			for {
+				kind, fields, err := readRecord(br)
+				if err != nil {
+					return err
+				}
+				switch kind {
+				case recordEnd:
+					return nil
+				case recordEntry:
+					if err := put(fields[0], fields[1]); err != nil {
+						return err
+					}
+				default:
+					return fmt.Errorf("snapshot inválido: registro inesperado %d", kind)
+				}
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("error importando %s: %w", source.Name, err)
+		}
+	}
+
+	if _, err := br.ReadByte(); err != io.EOF {
+		return fmt.Errorf("snapshot inválido: datos adicionales al final")
+	}
+	return nil
+}
+
+// writeRecord escribe la marca del registro seguida de cada campo con su longitud
+func writeRecord(w *bufio.Writer, kind byte, fields ...[]byte) error {
+	if err := w.WriteByte(kind); err != nil {
+		return err
+	}
+	var lenBuf [binary.MaxVarintLen64]byte
+	for _, field := range fields {
+		n := binary.PutUvarint(lenBuf[:], uint64(len(field)))
+		if _, err := w.Write(lenBuf[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRecord lee un registro y sus campos según la marca
+func readRecord(r *bufio.Reader) (byte, [][]byte, error) {
+	kind, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+
+	var count int
+	switch kind {
+	case recordSection:
+		count = 1
+	case recordEntry:
+		count = 2
+	case recordEnd:
+		count = 0
+	default:
+		return 0, nil, fmt.Errorf("snapshot inválido: registro desconocido %d", kind)
+	}
+
+	fields := make([][]byte, count)
+	for i := range fields {
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, nil, unexpectedEOF(err)
+		}
+		if size > maxFieldSize {
+			return 0, nil, fmt.Errorf("snapshot inválido: campo de %d bytes", size)
+		}
+		fields[i] = make([]byte, size)
+		if _, err := io.ReadFull(r, fields[i]); err != nil {
+			return 0, nil, unexpectedEOF(err)
+		}
+	}
+	return kind, fields, nil
+}
+
+// unexpectedEOF convierte un fin de archivo en medio de un registro en error de snapshot truncado
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("snapshot inválido: contenido truncado")
+	}
+	return err
+}
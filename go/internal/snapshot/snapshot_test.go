@@ -0,0 +1,205 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// memStore es un KVStore en memoria para tests
+type memStore struct {
+	data map[string]string
+}
+
+func newMemStore(n int, prefix string) *memStore {
+	store := &memStore{data: make(map[string]string)}
+	for i := 0; i < n; i++ {
+		store.data[fmt.Sprintf("%s-key-%04d", prefix, i)] = fmt.Sprintf("%s-value-%d", prefix, i*i)
+	}
+	return store
+}
+
+func (m *memStore) ExportKV(fn func(key, value []byte) error) error {
+	keys := make([]string, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fn([]byte(key), []byte(m.data[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memStore) ImportKV(entries func(put func(key, value []byte) error) error) error {
+	m.data = make(map[string]string)
+	return entries(func(key, value []byte) error {
+		m.data[string(key)] = string(value)
+		return nil
+	})
+}
+
+func sameData(t *testing.T, name string, got, want *memStore) {
+	t.Helper()
+	if len(got.data) != len(want.data) {
+		t.Fatalf("%s: esperaba %d entradas, obtuvo %d", name, len(want.data), len(got.data))
+	}
+	for key, value := range want.data {
+		if got.data[key] != value {
+			t.Fatalf("%s: valor de %s no coincide", name, key)
+		}
+	}
+}
+
+func TestStore_CreateAndRestore(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain, evm := newMemStore(50, "chain"), newMemStore(200, "evm")
+	meta, err := store.Create(10, []byte("apphash"), []Source{{"blockchain", chain}, {"evm_state", evm}})
+	if err != nil {
+		t.Fatalf("Create falló: %v", err)
+	}
+	if meta.Height != 10 || meta.Format != Format || meta.Chunks != 1 || len(meta.Hash) != 32 {
+		t.Fatalf("metadata inesperada: %+v", meta)
+	}
+
+	// Las bases de datos de destino tienen contenido previo que debe reemplazarse
+	restoredChain, restoredEVM := newMemStore(3, "old"), newMemStore(0, "")
+	if err := store.Restore(10, []Source{{"blockchain", restoredChain}, {"evm_state", restoredEVM}}); err != nil {
+		t.Fatalf("Restore falló: %v", err)
+	}
+	sameData(t, "blockchain", restoredChain, chain)
+	sameData(t, "evm_state", restoredEVM, evm)
+}
+
+func TestStore_RestoreRejectsWrongSources(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	if _, err := store.Create(1, nil, []Source{{"blockchain", newMemStore(5, "a")}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Restore(1, []Source{{"evm_state", newMemStore(0, "")}}); err == nil {
+		t.Error("se esperaba error restaurando en una base de datos con otro nombre")
+	}
+	if err := store.Restore(2, []Source{{"blockchain", newMemStore(0, "")}}); err == nil {
+		t.Error("se esperaba error restaurando una altura inexistente")
+	}
+}
+
+func TestStore_ChunksRoundTrip(t *testing.T) {
+	source, _ := NewStore(t.TempDir())
+	source.SetChunkSize(256)
+
+	chain := newMemStore(500, "chain")
+	meta, err := source.Create(7, []byte("apphash"), []Source{{"blockchain", chain}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Chunks < 2 {
+		t.Fatalf("se esperaban varios chunks, hay %d", meta.Chunks)
+	}
+
+	// Recibir chunk por chunk como en state sync
+	target, _ := NewStore(t.TempDir())
+	receiver, err := target.Receive(&Metadata{
+		Height: meta.Height, Format: meta.Format, Chunks: meta.Chunks,
+		ChunkSize: meta.ChunkSize, Size: meta.Size, Hash: meta.Hash, AppHash: meta.AppHash,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := receiver.WriteChunk(1, []byte("x")); err == nil {
+		t.Error("se esperaba error con chunk fuera de orden")
+	}
+	for i := uint32(0); i < meta.Chunks; i++ {
+		chunk, err := source.LoadChunk(7, Format, i)
+		if err != nil {
+			t.Fatalf("LoadChunk %d falló: %v", i, err)
+		}
+		if err := receiver.WriteChunk(i, chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !receiver.Done() {
+		t.Fatal("el receptor debería estar completo")
+	}
+	if err := receiver.Commit(); err != nil {
+		t.Fatalf("Commit falló: %v", err)
+	}
+
+	restored := newMemStore(0, "")
+	if err := target.Restore(7, []Source{{"blockchain", restored}}); err != nil {
+		t.Fatal(err)
+	}
+	sameData(t, "blockchain", restored, chain)
+
+	if _, err := source.LoadChunk(7, Format, meta.Chunks); err == nil {
+		t.Error("se esperaba error con chunk fuera de rango")
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	for _, height := range []uint64{100, 200, 300, 400} {
+		if _, err := store.Create(height, nil, []Source{{"blockchain", newMemStore(1, "a")}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Prune(2); err != nil {
+		t.Fatal(err)
+	}
+	snapshots, _ := store.List()
+	if len(snapshots) != 2 || snapshots[0].Height != 400 || snapshots[1].Height != 300 {
+		t.Fatalf("se esperaban los snapshots 400 y 300, hay %d", len(snapshots))
+	}
+}
+
+func TestStore_ExportImport(t *testing.T) {
+	source, _ := NewStore(t.TempDir())
+	chain := newMemStore(100, "chain")
+	if _, err := source.Create(42, []byte("apphash"), []Source{{"blockchain", chain}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var file bytes.Buffer
+	if _, err := source.Export(42, &file); err != nil {
+		t.Fatalf("Export falló: %v", err)
+	}
+	exported := file.Bytes()
+
+	target, _ := NewStore(t.TempDir())
+	meta, err := target.Import(bytes.NewReader(exported))
+	if err != nil {
+		t.Fatalf("Import falló: %v", err)
+	}
+	if meta.Height != 42 || string(meta.AppHash) != "apphash" {
+		t.Fatalf("metadata inesperada: %+v", meta)
+	}
+
+	restored := newMemStore(0, "")
+	if err := target.Restore(42, []Source{{"blockchain", restored}}); err != nil {
+		t.Fatal(err)
+	}
+	sameData(t, "blockchain", restored, chain)
+
+	// Un archivo alterado no se importa
+	corrupted := append([]byte(nil), exported...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	other, _ := NewStore(t.TempDir())
+	if _, err := other.Import(bytes.NewReader(corrupted)); err == nil {
+		t.Error("se esperaba error importando un archivo alterado")
+	}
+	if _, err := other.Import(bytes.NewReader(exported[:len(exported)-10])); err == nil {
+		t.Error("se esperaba error importando un archivo truncado")
+	}
+	if snapshots, _ := other.List(); len(snapshots) != 0 {
+		t.Errorf("no debería quedar ningún snapshot, hay %d", len(snapshots))
+	}
+}
@@ -0,0 +1,358 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultChunkSize es el tamaño de los chunks en que se sirve un snapshot por state sync
+const DefaultChunkSize int64 = 4 << 20
+
+// ErrNotFound se retorna cuando no existe un snapshot a la altura pedida
+var ErrNotFound = errors.New("snapshot no encontrado")
+
+const (
+	dataFile     = "snapshot.dat"
+	metadataFile = "metadata.json"
+)
+
+// Metadata describe un snapshot guardado
+type Metadata struct {
+	Height    uint64    `json:"height"`
+	Format    uint32    `json:"format"`
+	Chunks    uint32    `json:"chunks"`
+	ChunkSize int64     `json:"chunkSize"`
+	Size      int64     `json:"size"`
+	Hash      []byte    `json:"hash"`    // SHA-256 del contenido
+	AppHash   []byte    `json:"appHash"` // App hash de la cadena a esa altura
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Store guarda snapshots en disco, uno por directorio de altura
+type Store struct {
+	mu        sync.Mutex
+	dir       string
+	chunkSize int64
+}
+
+// NewStore crea (si no existe) el directorio de snapshots
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creando directorio de snapshots: %w", err)
+	}
+	return &Store{
+		dir:       dir,
+		chunkSize: DefaultChunkSize,
+	}, nil
+}
+
+// SetChunkSize cambia el tamaño de chunk de los snapshots que se creen de ahora en adelante
+func (s *Store) SetChunkSize(size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if size > 0 {
+		s.chunkSize = size
+	}
+}
+
+// heightDir retorna el directorio de un snapshot
+func (s *Store) heightDir(height uint64) string {
+	return filepath.Join(s.dir, strconv.FormatUint(height, 10))
+}
+
+// Create genera un snapshot de las bases de datos a la altura dada
+func (s *Store) Create(height uint64, appHash []byte, sources []Source) (*Metadata, error) {
+	s.mu.Lock()
+	chunkSize := s.chunkSize
+	s.mu.Unlock()
+
+	receiver, err := s.Receive(&Metadata{
+		Height:    height,
+		Format:    Format,
+		ChunkSize: chunkSize,
+		AppHash:   appHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Encode(receiver, sources); err != nil {
+		receiver.Abort()
+		return nil, fmt.Errorf("error generando snapshot: %w", err)
+	}
+
+	receiver.meta.Size = receiver.size
+	receiver.meta.Hash = receiver.hasher.Sum(nil)
+	receiver.meta.Chunks = chunkCount(receiver.size, chunkSize)
+	if err := receiver.Commit(); err != nil {
+		return nil, err
+	}
+	return receiver.meta, nil
+}
+
+// List retorna los snapshots guardados, del más reciente al más antiguo
+func (s *Store) List() ([]*Metadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error listando snapshots: %w", err)
+	}
+
+	snapshots := make([]*Metadata, 0, len(entries))
+	for _, entry := range entries {
+		height, err := strconv.ParseUint(entry.Name(), 10, 64)
+		if err != nil || !entry.IsDir() {
+			continue // Temporales u otros archivos
+		}
+		meta, err := s.readMetadata(height)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, meta)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Height > snapshots[j].Height
+	})
+	return snapshots, nil
+}
+
+// Get retorna la metadata del snapshot a la altura dada
+func (s *Store) Get(height uint64) (*Metadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readMetadata(height)
+}
+
+// readMetadata lee la metadata de un snapshot (el lock debe estar tomado)
+func (s *Store) readMetadata(height uint64) (*Metadata, error) {
+	data, err := os.ReadFile(filepath.Join(s.heightDir(height), metadataFile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: altura %d", ErrNotFound, height)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo snapshot %d: %w", height, err)
+	}
+
+	var meta Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("metadata de snapshot %d inválida: %w", height, err)
+	}
+	return &meta, nil
+}
+
+// Open abre el contenido de un snapshot para lectura
+func (s *Store) Open(height uint64) (*os.File, *Metadata, error) {
+	meta, err := s.Get(height)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(filepath.Join(s.heightDir(height), dataFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error abriendo snapshot %d: %w", height, err)
+	}
+	return file, meta, nil
+}
+
+// LoadChunk retorna un chunk del snapshot
+func (s *Store) LoadChunk(height uint64, format uint32, index uint32) ([]byte, error) {
+	file, meta, err := s.Open(height)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if meta.Format != format || index >= meta.Chunks {
+		return nil, fmt.Errorf("%w: altura %d, formato %d, chunk %d", ErrNotFound, height, format, index)
+	}
+
+	offset := int64(index) * meta.ChunkSize
+	size := meta.ChunkSize
+	if offset+size > meta.Size {
+		size = meta.Size - offset
+	}
+
+	chunk := make([]byte, size)
+	if _, err := file.ReadAt(chunk, offset); err != nil {
+		return nil, fmt.Errorf("error leyendo chunk %d del snapshot %d: %w", index, height, err)
+	}
+	return chunk, nil
+}
+
+// Restore verifica el contenido del snapshot y lo importa en las bases de datos
+func (s *Store) Restore(height uint64, sources []Source) error {
+	file, meta, err := s.Open(height)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Verificar antes de importar para no dejar las bases de datos a medio reemplazar
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return fmt.Errorf("error leyendo snapshot %d: %w", height, err)
+	}
+	if !bytes.Equal(hasher.Sum(nil), meta.Hash) {
+		return fmt.Errorf("snapshot %d corrupto: hash no coincide", height)
+	}
+	if meta.Format != Format {
+		return fmt.Errorf("formato de snapshot no soportado: %d", meta.Format)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return Decode(file, sources)
+}
+
+// Delete borra el snapshot a la altura dada
+func (s *Store) Delete(height uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.RemoveAll(s.heightDir(height))
+}
+
+// Prune borra los snapshots más antiguos, conservando los keepRecent más recientes (0 = conservar todos)
+func (s *Store) Prune(keepRecent int) error {
+	if keepRecent <= 0 {
+		return nil
+	}
+
+	snapshots, err := s.List()
+	if err != nil {
+		return err
+	}
+	for i := keepRecent; i < len(snapshots); i++ {
+		if err := s.Delete(snapshots[i].Height); err != nil {
+			return fmt.Errorf("error borrando snapshot %d: %w", snapshots[i].Height, err)
+		}
+	}
+	return nil
+}
+
+// Receiver escribe el contenido de un snapshot que llega por partes (state sync o archivo)
+// y lo agrega al store una vez verificado
+type Receiver struct {
+	store  *Store
+	meta   *Metadata
+	file   *os.File
+	hasher hash.Hash
+	size   int64
+	next   uint32 // Próximo chunk esperado
+}
+
+// Receive prepara la recepción de un snapshot descripto por meta
+func (s *Store) Receive(meta *Metadata) (*Receiver, error) {
+	file, err := os.CreateTemp(s.dir, "receiving-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creando snapshot temporal: %w", err)
+	}
+	return &Receiver{
+		store:  s,
+		meta:   meta,
+		file:   file,
+		hasher: sha256.New(),
+	}, nil
+}
+
+// Write agrega contenido al snapshot (implementa io.Writer)
+func (r *Receiver) Write(p []byte) (int, error) {
+	n, err := r.file.Write(p)
+	r.hasher.Write(p[:n])
+	r.size += int64(n)
+	return n, err
+}
+
+// WriteChunk agrega el chunk index, que debe llegar en orden
+func (r *Receiver) WriteChunk(index uint32, chunk []byte) error {
+	if index != r.next {
+		return fmt.Errorf("chunk fuera de orden: esperado %d, recibido %d", r.next, index)
+	}
+	if index >= r.meta.Chunks {
+		return fmt.Errorf("chunk %d fuera de rango (%d chunks)", index, r.meta.Chunks)
+	}
+	if _, err := r.Write(chunk); err != nil {
+		return fmt.Errorf("error escribiendo chunk %d: %w", index, err)
+	}
+	r.next++
+	return nil
+}
+
+// Metadata retorna la metadata del snapshot en recepción
+func (r *Receiver) Metadata() *Metadata {
+	return r.meta
+}
+
+// Done retorna si ya se recibieron todos los chunks
+func (r *Receiver) Done() bool {
+	return r.next >= r.meta.Chunks
+}
+
+// Commit verifica tamaño y hash del contenido recibido y lo agrega al store
+func (r *Receiver) Commit() error {
+	defer r.Abort()
+
+	if r.size != r.meta.Size {
+		return fmt.Errorf("snapshot %d incompleto: %d de %d bytes", r.meta.Height, r.size, r.meta.Size)
+	}
+	if !bytes.Equal(r.hasher.Sum(nil), r.meta.Hash) {
+		return fmt.Errorf("snapshot %d corrupto: hash no coincide", r.meta.Height)
+	}
+	if err := r.file.Sync(); err != nil {
+		return err
+	}
+	if r.meta.CreatedAt.IsZero() {
+		r.meta.CreatedAt = time.Now()
+	}
+
+	metaData, err := json.MarshalIndent(r.meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializando metadata: %w", err)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	dir := r.store.heightDir(r.meta.Height)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creando directorio del snapshot: %w", err)
+	}
+	if err := os.Rename(r.file.Name(), filepath.Join(dir, dataFile)); err != nil {
+		return fmt.Errorf("error guardando snapshot: %w", err)
+	}
+	// La metadata se escribe al final: un snapshot sin metadata no se lista
+	if err := os.WriteFile(filepath.Join(dir, metadataFile), metaData, 0644); err != nil {
+		return fmt.Errorf("error guardando metadata del snapshot: %w", err)
+	}
+	return nil
+}
+
+// Abort descarta el contenido recibido (no hace nada después de Commit)
+func (r *Receiver) Abort() {
+	r.file.Close()
+	os.Remove(r.file.Name())
+}
+
+// chunkCount retorna la cantidad de chunks de un contenido de size bytes
+func chunkCount(size int64, chunkSize int64) uint32 {
+	if size == 0 {
+		return 1
+	}
+	return uint32((size + chunkSize - 1) / chunkSize)
+}
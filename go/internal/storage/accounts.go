@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Claves del índice de cuentas (se actualiza en cada commit con las cuentas modificadas)
+const (
+	accountIndexPrefix   = "accounts:addr:" // dirección -> balance (32 bytes big-endian)
+	accountBalancePrefix = "accounts:bal:"  // balance (32 bytes big-endian) + dirección -> vacío, ordenado por balance
+	accountCountKey      = "accounts:count"
+)
+
+// AccountBalance es una entrada del índice de cuentas
+type AccountBalance struct {
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+}
+
+// UpdateAccountIndex actualiza el índice con el balance actual de las cuentas modificadas
+// Un balance nil elimina la cuenta del índice (ya no existe en el estado)
+func (b *BlockchainDB) UpdateAccountIndex(balances map[string]*big.Int) error {
+	count, err := b.GetAccountCount()
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	for address, balance := range balances {
+		addrKey := []byte(accountIndexPrefix + address)
+		previous, err := b.db.Get(addrKey, nil)
+		switch {
+		case err == leveldb.ErrNotFound:
+			previous = nil
+		case err != nil:
+			return fmt.Errorf("error leyendo índice de cuentas: %w", err)
+		}
+
+		if previous != nil {
+			batch.Delete(accountBalanceKey(previous, address))
+		}
+		if balance == nil {
+			if previous != nil {
+				batch.Delete(addrKey)
+				count--
+			}
+			continue
+		}
+
+		encoded := balance.FillBytes(make([]byte, 32))
+		batch.Put(addrKey, encoded)
+		batch.Put(accountBalanceKey(encoded, address), nil)
+		if previous == nil {
+			count++
+		}
+	}
+
+	countBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(countBytes, count)
+	batch.Put([]byte(accountCountKey), countBytes)
+
+	return b.db.Write(batch, nil)
+}
+
+// AccountIndexInitialized indica si el índice de cuentas ya fue creado
+func (b *BlockchainDB) AccountIndexInitialized() (bool, error) {
+	return b.db.Has([]byte(accountCountKey), nil)
+}
+
+// GetAccountCount retorna la cantidad de cuentas indexadas
+func (b *BlockchainDB) GetAccountCount() (uint64, error) {
+	data, err := b.db.Get([]byte(accountCountKey), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("contador de cuentas inválido")
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// ListAccountsByBalance retorna las cuentas indexadas de mayor a menor balance
+func (b *BlockchainDB) ListAccountsByBalance(offset, limit int) ([]AccountBalance, error) {
+	accounts := make([]AccountBalance, 0, limit)
+	if limit <= 0 {
+		return accounts, nil
+	}
+
+	iter := b.db.NewIterator(util.BytesPrefix([]byte(accountBalancePrefix)), nil)
+	defer iter.Release()
+
+	skipped := 0
+	for ok := iter.Last(); ok && len(accounts) < limit; ok = iter.Prev() {
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		key := iter.Key()[len(accountBalancePrefix):]
+		if len(key) < 32 {
+			continue
+		}
+		accounts = append(accounts, AccountBalance{
+			Address: string(key[32:]),
+			Balance: new(big.Int).SetBytes(key[:32]).String(),
+		})
+	}
+	return accounts, iter.Error()
+}
+
+// accountBalanceKey construye la clave ordenada por balance de una cuenta
+func accountBalanceKey(balance []byte, address string) []byte {
+	key := make([]byte, 0, len(accountBalancePrefix)+len(balance)+len(address))
+	key = append(key, accountBalancePrefix...)
+	key = append(key, balance...)
+	return append(key, address...)
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// Claves de bloques. Los bloques se guardan por hash, así que un bloque que deja de ser canónico (reorg,
+// state sync) sigue disponible por hash; el índice canónico indica cuál es el bloque de cada altura
+const (
+	blockHashPrefix      = "block:hash:"      // hash (minúsculas) -> bloque
+	blockCanonicalPrefix = "block:canonical:" // altura -> hash del bloque canónico
+	legacyBlockPrefix    = "block:"           // altura -> bloque guardado sin hash (formato anterior, solo lectura)
+
+	// Fuera de "block:" para que RewriteRecords no lo tome como bloques en el formato anterior
+	blockCommitPrefix   = "commit:"   // altura -> firmas del commit de CometBFT que confirmó el bloque
+	blockIncludedPrefix = "included:" // altura -> hashes de todas las transacciones incluidas (también las fallidas)
+)
+
+func blockHashKey(hash string) []byte {
+	return []byte(blockHashPrefix + strings.ToLower(hash))
+}
+
+func blockCanonicalKey(height uint64) []byte {
+	return []byte(fmt.Sprintf("%s%d", blockCanonicalPrefix, height))
+}
+
+func legacyBlockKey(height uint64) []byte {
+	return []byte(fmt.Sprintf("%s%d", legacyBlockPrefix, height))
+}
+
+func blockCommitKey(height uint64) []byte {
+	return []byte(fmt.Sprintf("%s%d", blockCommitPrefix, height))
+}
+
+func blockIncludedKey(height uint64) []byte {
+	return []byte(fmt.Sprintf("%s%d", blockIncludedPrefix, height))
+}
+
+// SaveBlock guarda un bloque sin hash en una altura (formato anterior al índice por hash)
+// Reemplaza al bloque canónico de esa altura
+func (b *BlockchainDB) SaveBlock(height uint64, blockData []byte) error {
+	batch := new(leveldb.Batch)
+	batch.Put(legacyBlockKey(height), blockData)
+	batch.Delete(blockCanonicalKey(height))
+	return b.db.Write(batch, nil)
+}
+
+// SaveCanonicalBlock guarda un bloque por hash y lo marca como el canónico de su altura
+func (b *BlockchainDB) SaveCanonicalBlock(height uint64, hash string, blockData []byte) error {
+	batch := new(leveldb.Batch)
+	batch.Put(blockHashKey(hash), blockData)
+	batch.Put(blockCanonicalKey(height), []byte(strings.ToLower(hash)))
+	batch.Delete(legacyBlockKey(height))
+	return b.db.Write(batch, nil)
+}
+
+// SaveBlockByHash guarda un bloque por hash sin cambiar el índice canónico (ej: un bloque que todavía no
+// es, o ya no es, el canónico de su altura)
+func (b *BlockchainDB) SaveBlockByHash(hash string, blockData []byte) error {
+	return b.db.Put(blockHashKey(hash), blockData, nil)
+}
+
+// SetCanonicalHash marca como canónico de una altura a un bloque ya guardado por hash
+func (b *BlockchainDB) SetCanonicalHash(height uint64, hash string) error {
+	exists, err := b.db.Has(blockHashKey(hash), nil)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("bloque %s no encontrado", hash)
+	}
+	batch := new(leveldb.Batch)
+	batch.Put(blockCanonicalKey(height), []byte(strings.ToLower(hash)))
+	batch.Delete(legacyBlockKey(height))
+	return b.db.Write(batch, nil)
+}
+
+// GetCanonicalHash obtiene el hash del bloque canónico de una altura
+func (b *BlockchainDB) GetCanonicalHash(height uint64) (string, error) {
+	hash, err := b.db.Get(blockCanonicalKey(height), nil)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// GetBlock obtiene el bloque canónico de una altura
+func (b *BlockchainDB) GetBlock(height uint64) ([]byte, error) {
+	hash, err := b.db.Get(blockCanonicalKey(height), nil)
+	switch {
+	case err == nil:
+		return b.db.Get(blockHashKey(string(hash)), nil)
+	case err != leveldb.ErrNotFound:
+		return nil, err
+	}
+	return b.db.Get(legacyBlockKey(height), nil)
+}
+
+// GetBlockByHash obtiene un bloque por hash, sea o no el canónico de su altura
+func (b *BlockchainDB) GetBlockByHash(hash string) ([]byte, error) {
+	return b.db.Get(blockHashKey(hash), nil)
+}
+
+// SaveBlockCommit guarda las firmas del commit que confirmó el bloque de una altura
+func (b *BlockchainDB) SaveBlockCommit(height uint64, commitData []byte) error {
+	return b.db.Put(blockCommitKey(height), commitData, nil)
+}
+
+// GetBlockCommit obtiene las firmas del commit que confirmó el bloque de una altura
+func (b *BlockchainDB) GetBlockCommit(height uint64) ([]byte, error) {
+	return b.db.Get(blockCommitKey(height), nil)
+}
+
+// SaveIncludedTxHashes guarda los hashes de todas las transacciones incluidas en el bloque de una altura,
+// también las que fallaron y no quedaron en el bloque guardado
+func (b *BlockchainDB) SaveIncludedTxHashes(height uint64, hashesData []byte) error {
+	return b.db.Put(blockIncludedKey(height), hashesData, nil)
+}
+
+// GetIncludedTxHashes obtiene los hashes de las transacciones incluidas en el bloque de una altura
+func (b *BlockchainDB) GetIncludedTxHashes(height uint64) ([]byte, error) {
+	return b.db.Get(blockIncludedKey(height), nil)
+}
+
+// TruncateBlocks deja height como la última altura: descarta el índice canónico, las firmas del commit y los
+// hashes incluidos de las alturas siguientes. Los bloques descartados siguen disponibles por hash
+func (b *BlockchainDB) TruncateBlocks(height uint64) (int, error) {
+	latest, err := b.GetLatestHeight()
+	if err == leveldb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	batch := new(leveldb.Batch)
+	removed := 0
+	for h := latest; h > height; h-- {
+		batch.Delete(blockCanonicalKey(h))
+		batch.Delete(legacyBlockKey(h))
+		batch.Delete(blockCommitKey(h))
+		batch.Delete(blockIncludedKey(h))
+		removed++
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	batch.Put([]byte("height:latest"), []byte(fmt.Sprintf("%d", height)))
+	if err := b.db.Write(batch, nil); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
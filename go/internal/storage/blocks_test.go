@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"os"
+	"testing"
+)
+
+// TestBlockStore verifica el índice canónico por altura, los bloques no canónicos por hash y la lectura
+// de bloques guardados sin hash
+func TestBlockStore(t *testing.T) {
+	tmpDir := "./test_data_blocks"
+	defer os.RemoveAll(tmpDir)
+
+	db, err := NewBlockchainDB(tmpDir)
+	if err != nil {
+		t.Fatalf("Error creando base de datos: %v", err)
+	}
+	defer db.Close()
+
+	// Formato anterior: bloque por altura, sin hash
+	if err := db.SaveBlock(1, []byte("bloque 1")); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+	if data, err := db.GetBlock(1); err != nil || string(data) != "bloque 1" {
+		t.Errorf("Bloque sin hash no encontrado: %s (%v)", data, err)
+	}
+
+	if err := db.SaveCanonicalBlock(2, "0xAA", []byte("bloque 2a")); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+	if err := db.SaveBlockByHash("0xbb", []byte("bloque 2b")); err != nil {
+		t.Fatalf("Error guardando bloque: %v", err)
+	}
+	if data, err := db.GetBlock(2); err != nil || string(data) != "bloque 2a" {
+		t.Errorf("Bloque canónico incorrecto: %s (%v)", data, err)
+	}
+	if hash, err := db.GetCanonicalHash(2); err != nil || hash != "0xaa" {
+		t.Errorf("Hash canónico incorrecto: %s (%v)", hash, err)
+	}
+
+	// Reorg: el bloque reemplazado sigue disponible por hash
+	if err := db.SetCanonicalHash(2, "0xBB"); err != nil {
+		t.Fatalf("Error cambiando bloque canónico: %v", err)
+	}
+	if data, err := db.GetBlock(2); err != nil || string(data) != "bloque 2b" {
+		t.Errorf("Bloque canónico tras reorg incorrecto: %s (%v)", data, err)
+	}
+	if data, err := db.GetBlockByHash("0xaa"); err != nil || string(data) != "bloque 2a" {
+		t.Errorf("Bloque reemplazado no disponible por hash: %s (%v)", data, err)
+	}
+	if err := db.SetCanonicalHash(2, "0xcc"); err == nil {
+		t.Error("Un hash sin bloque guardado no debería ser canónico")
+	}
+
+	if _, err := db.GetBlock(3); err == nil {
+		t.Error("Una altura sin bloque debería fallar")
+	}
+	if _, err := db.GetBlockByHash("0xdd"); err == nil {
+		t.Error("Un hash desconocido debería fallar")
+	}
+
+	// El commit de una altura se guarda aparte del bloque
+	if err := db.SaveBlockCommit(2, []byte("commit 2")); err != nil {
+		t.Fatalf("Error guardando commit: %v", err)
+	}
+	if data, err := db.GetBlockCommit(2); err != nil || string(data) != "commit 2" {
+		t.Errorf("Commit incorrecto: %s (%v)", data, err)
+	}
+	if _, err := db.GetBlockCommit(1); err == nil {
+		t.Error("Una altura sin commit guardado debería fallar")
+	}
+}
+
+// TestTruncateBlocks verifica que las alturas descartadas dejen de ser canónicas pero sigan por hash
+func TestTruncateBlocks(t *testing.T) {
+	tmpDir := "./test_data_truncate"
+	defer os.RemoveAll(tmpDir)
+
+	db, err := NewBlockchainDB(tmpDir)
+	if err != nil {
+		t.Fatalf("Error creando base de datos: %v", err)
+	}
+	defer db.Close()
+
+	for height, hash := range []string{"0x01", "0x02", "0x03"} {
+		if err := db.SaveCanonicalBlock(uint64(height+1), hash, []byte("bloque "+hash)); err != nil {
+			t.Fatalf("Error guardando bloque: %v", err)
+		}
+		if err := db.SaveBlockCommit(uint64(height+1), []byte("commit")); err != nil {
+			t.Fatalf("Error guardando commit: %v", err)
+		}
+	}
+	db.SaveLatestHeight(3)
+
+	removed, err := db.TruncateBlocks(1)
+	if err != nil || removed != 2 {
+		t.Fatalf("Se esperaban 2 alturas descartadas: %d (%v)", removed, err)
+	}
+	if latest, err := db.GetLatestHeight(); err != nil || latest != 1 {
+		t.Errorf("Última altura %d, esperada 1 (%v)", latest, err)
+	}
+	if _, err := db.GetBlock(2); err == nil {
+		t.Error("La altura 2 no debería tener bloque canónico")
+	}
+	if _, err := db.GetBlockCommit(3); err == nil {
+		t.Error("La altura 3 no debería tener commit")
+	}
+	if data, err := db.GetBlockByHash("0x03"); err != nil || string(data) != "bloque 0x03" {
+		t.Errorf("Bloque descartado no disponible por hash: %s (%v)", data, err)
+	}
+	if data, err := db.GetBlock(1); err != nil || string(data) != "bloque 0x01" {
+		t.Errorf("Bloque 1 incorrecto: %s (%v)", data, err)
+	}
+
+	if removed, err := db.TruncateBlocks(5); err != nil || removed != 0 {
+		t.Errorf("Truncar por encima de la última altura no debería descartar nada: %d (%v)", removed, err)
+	}
+}
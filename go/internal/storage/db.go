@@ -69,18 +69,6 @@ func (b *BlockchainDB) GetDataDir() string {
 	return b.dataDir
 }
 
-// SaveBlock guarda un bloque en la base de datos
-func (b *BlockchainDB) SaveBlock(height uint64, blockData []byte) error {
-	key := []byte(fmt.Sprintf("block:%d", height))
-	return b.db.Put(key, blockData, nil)
-}
-
-// GetBlock obtiene un bloque por altura
-func (b *BlockchainDB) GetBlock(height uint64) ([]byte, error) {
-	key := []byte(fmt.Sprintf("block:%d", height))
-	return b.db.Get(key, nil)
-}
-
 // SaveState guarda el estado de la blockchain
 func (b *BlockchainDB) SaveState(stateData []byte) error {
 	return b.db.Put([]byte("state:latest"), stateData, nil)
@@ -91,16 +79,17 @@ func (b *BlockchainDB) GetState() ([]byte, error) {
 	return b.db.Get([]byte("state:latest"), nil)
 }
 
+// transactionPrefix es el prefijo de las transacciones guardadas por hash
+const transactionPrefix = "tx:"
+
 // SaveTransaction guarda una transacción
 func (b *BlockchainDB) SaveTransaction(txHash string, txData []byte) error {
-	key := []byte(fmt.Sprintf("tx:%s", txHash))
-	return b.db.Put(key, txData, nil)
+	return b.db.Put([]byte(transactionPrefix+txHash), txData, nil)
 }
 
 // GetTransaction obtiene una transacción por hash
 func (b *BlockchainDB) GetTransaction(txHash string) ([]byte, error) {
-	key := []byte(fmt.Sprintf("tx:%s", txHash))
-	return b.db.Get(key, nil)
+	return b.db.Get([]byte(transactionPrefix+txHash), nil)
 }
 
 // SaveAccount guarda el estado de una cuenta
@@ -133,3 +122,143 @@ func (b *BlockchainDB) GetLatestHeight() (uint64, error) {
 	return height, nil
 }
 
+
+// SavePeerBans guarda la lista de peers mesh baneados
+func (b *BlockchainDB) SavePeerBans(data []byte) error {
+	return b.db.Put([]byte("network:bans"), data, nil)
+}
+
+// GetPeerBans obtiene la lista de peers mesh baneados (nil si nunca se guardó)
+func (b *BlockchainDB) GetPeerBans() ([]byte, error) {
+	data, err := b.db.Get([]byte("network:bans"), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return data, err
+}
+
+// SaveWatchlist guarda las suscripciones de notificaciones por dirección
+func (b *BlockchainDB) SaveWatchlist(data []byte) error {
+	return b.db.Put([]byte("watchlist:subscriptions"), data, nil)
+}
+
+// GetWatchlist obtiene las suscripciones de notificaciones (nil si nunca se guardaron)
+func (b *BlockchainDB) GetWatchlist() ([]byte, error) {
+	data, err := b.db.Get([]byte("watchlist:subscriptions"), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return data, err
+}
+
+// SaveMultisigProposals guarda las transacciones multisig que recolectan firmas
+func (b *BlockchainDB) SaveMultisigProposals(data []byte) error {
+	return b.db.Put([]byte("multisig:proposals"), data, nil)
+}
+
+// GetMultisigProposals obtiene las propuestas multisig pendientes (nil si nunca se guardaron)
+func (b *BlockchainDB) GetMultisigProposals() ([]byte, error) {
+	data, err := b.db.Get([]byte("multisig:proposals"), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return data, err
+}
+
+// SaveComplianceList guarda la lista de compliance y su auditoría
+func (b *BlockchainDB) SaveComplianceList(data []byte) error {
+	return b.db.Put([]byte("compliance:list"), data, nil)
+}
+
+// GetComplianceList obtiene la lista de compliance (nil si nunca se guardó)
+func (b *BlockchainDB) GetComplianceList() ([]byte, error) {
+	data, err := b.db.Get([]byte("compliance:list"), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return data, err
+}
+
+// SaveHaltStatus guarda la detención programada de la cadena
+func (b *BlockchainDB) SaveHaltStatus(data []byte) error {
+	return b.db.Put([]byte("halt:status"), data, nil)
+}
+
+// GetHaltStatus obtiene la detención programada de la cadena (nil si no hay)
+func (b *BlockchainDB) GetHaltStatus() ([]byte, error) {
+	data, err := b.db.Get([]byte("halt:status"), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return data, err
+}
+
+// DeleteHaltStatus borra la detención programada de la cadena
+func (b *BlockchainDB) DeleteHaltStatus() error {
+	return b.db.Delete([]byte("halt:status"), nil)
+}
+
+// ExportKV recorre todas las claves de la base de datos en orden (usado por los snapshots de estado)
+func (b *BlockchainDB) ExportKV(fn func(key, value []byte) error) error {
+	iter := b.db.NewIterator(nil, nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		if err := fn(iter.Key(), iter.Value()); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+// ImportKV reemplaza todo el contenido de la base de datos por las entradas recibidas
+func (b *BlockchainDB) ImportKV(entries func(put func(key, value []byte) error) error) error {
+	// Borrar el contenido actual
+	batch := new(leveldb.Batch)
+	iter := b.db.NewIterator(nil, nil)
+	for iter.Next() {
+		batch.Delete(append([]byte(nil), iter.Key()...))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("error recorriendo base de datos: %w", err)
+	}
+	if err := b.db.Write(batch, nil); err != nil {
+		return fmt.Errorf("error limpiando base de datos: %w", err)
+	}
+
+	batch.Reset()
+	err := entries(func(key, value []byte) error {
+		batch.Put(key, value)
+		if batch.Len() >= 1024 {
+			if err := b.db.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return b.db.Write(batch, nil)
+}
+
+// SaveSnapshotRestore registra la altura y app hash de un snapshot restaurado fuera de banda
+func (b *BlockchainDB) SaveSnapshotRestore(data []byte) error {
+	return b.db.Put([]byte("snapshot:restored"), data, nil)
+}
+
+// GetSnapshotRestore obtiene el registro del snapshot restaurado (nil si no hay)
+func (b *BlockchainDB) GetSnapshotRestore() ([]byte, error) {
+	data, err := b.db.Get([]byte("snapshot:restored"), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return data, err
+}
+
+// DeleteSnapshotRestore borra el registro del snapshot restaurado
+func (b *BlockchainDB) DeleteSnapshotRestore() error {
+	return b.db.Delete([]byte("snapshot:restored"), nil)
+}
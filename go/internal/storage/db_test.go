@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"math/big"
 	"os"
 	"testing"
 )
@@ -33,7 +34,7 @@ func TestBlockchainDBBasic(t *testing.T) {
 	if string(retrieved) != string(blockData) {
 		t.Errorf("Datos del bloque no coinciden: esperado %s, obtenido %s", blockData, retrieved)
 	}
-	
+
 	// Test SaveTransaction y GetTransaction
 	txHash := "0x1234567890abcdef"
 	txData := []byte("test transaction")
@@ -67,3 +68,101 @@ func TestBlockchainDBBasic(t *testing.T) {
 	}
 }
 
+// TestAccountIndex verifica el índice de cuentas ordenado por balance
+func TestAccountIndex(t *testing.T) {
+	db, err := NewBlockchainDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("Error creando base de datos: %v", err)
+	}
+	defer db.Close()
+
+	if initialized, _ := db.AccountIndexInitialized(); initialized {
+		t.Fatal("El índice no debería existir todavía")
+	}
+
+	err = db.UpdateAccountIndex(map[string]*big.Int{
+		"0xaaaa": big.NewInt(100),
+		"0xbbbb": big.NewInt(300),
+		"0xcccc": big.NewInt(200),
+	})
+	if err != nil {
+		t.Fatalf("Error actualizando índice: %v", err)
+	}
+
+	// Cambio de balance, cuenta eliminada y cuenta nueva
+	err = db.UpdateAccountIndex(map[string]*big.Int{
+		"0xaaaa": big.NewInt(1000),
+		"0xcccc": nil,
+		"0xdddd": big.NewInt(50),
+	})
+	if err != nil {
+		t.Fatalf("Error actualizando índice: %v", err)
+	}
+
+	if initialized, _ := db.AccountIndexInitialized(); !initialized {
+		t.Error("El índice debería estar inicializado")
+	}
+	count, err := db.GetAccountCount()
+	if err != nil || count != 3 {
+		t.Errorf("Cantidad de cuentas: esperado 3, obtenido %d (%v)", count, err)
+	}
+
+	accounts, err := db.ListAccountsByBalance(0, 10)
+	if err != nil {
+		t.Fatalf("Error listando cuentas: %v", err)
+	}
+	expected := []AccountBalance{{"0xaaaa", "1000"}, {"0xbbbb", "300"}, {"0xdddd", "50"}}
+	if len(accounts) != len(expected) {
+		t.Fatalf("Cuentas inesperadas: %v", accounts)
+	}
+	for i := range expected {
+		if accounts[i] != expected[i] {
+			t.Errorf("Posición %d: esperado %v, obtenido %v", i, expected[i], accounts[i])
+		}
+	}
+
+	page, err := db.ListAccountsByBalance(1, 1)
+	if err != nil || len(page) != 1 || page[0].Address != "0xbbbb" {
+		t.Errorf("Paginación inesperada: %v (%v)", page, err)
+	}
+}
+
+// TestValidatorRecords verifica los registros por validador y la migración del formato anterior
+func TestValidatorRecords(t *testing.T) {
+	db, err := NewBlockchainDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("Error creando base de datos: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.SaveAccount("validators:set", []byte(`[]`)); err != nil {
+		t.Fatalf("Error guardando set anterior: %v", err)
+	}
+	if legacy, err := db.GetLegacyValidatorSet(); err != nil || string(legacy) != `[]` {
+		t.Fatalf("Set anterior: obtenido %q (%v)", legacy, err)
+	}
+
+	err = db.WriteValidatorRecords(map[string][]byte{"0xaaaa": []byte("a"), "0xbbbb": []byte("b")}, nil)
+	if err != nil {
+		t.Fatalf("Error guardando registros: %v", err)
+	}
+	err = db.WriteValidatorRecords(map[string][]byte{"0xaaaa": []byte("a2")}, []string{"0xbbbb"})
+	if err != nil {
+		t.Fatalf("Error actualizando registros: %v", err)
+	}
+
+	records, err := db.ListValidatorRecords()
+	if err != nil {
+		t.Fatalf("Error listando registros: %v", err)
+	}
+	if len(records) != 1 || string(records["0xaaaa"]) != "a2" {
+		t.Errorf("Registros incorrectos: %q", records)
+	}
+
+	if err := db.DeleteLegacyValidatorSet(); err != nil {
+		t.Fatalf("Error borrando set anterior: %v", err)
+	}
+	if legacy, _ := db.GetLegacyValidatorSet(); legacy != nil {
+		t.Error("El set anterior debería estar borrado")
+	}
+}
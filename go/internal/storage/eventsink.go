@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"strconv"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// eventSinkCursorPrefix guarda, por destino, la última altura cuyos eventos se publicaron
+const eventSinkCursorPrefix = "eventsink:cursor:"
+
+// GetEventSinkCursor obtiene la última altura publicada en el destino sink (ok en false si nunca publicó)
+func (b *BlockchainDB) GetEventSinkCursor(sink string) (height uint64, ok bool, err error) {
+	data, err := b.db.Get([]byte(eventSinkCursorPrefix+sink), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	height, err = strconv.ParseUint(string(data), 10, 64)
+	return height, err == nil, err
+}
+
+// SaveEventSinkCursor registra la última altura publicada en el destino sink
+func (b *BlockchainDB) SaveEventSinkCursor(sink string, height uint64) error {
+	return b.db.Put([]byte(eventSinkCursorPrefix+sink), []byte(strconv.FormatUint(height, 10)), nil)
+}
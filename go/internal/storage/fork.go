@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// forkRecordPrefix agrupa los registros del fork de una red remota (modo desarrollo): cuentas y slots
+// leídos de la red remota y las marcas de los que se borraron localmente
+const forkRecordPrefix = "fork:"
+
+// GetForkRecord obtiene un registro del fork (nil si no existe)
+func (b *BlockchainDB) GetForkRecord(key string) ([]byte, error) {
+	data, err := b.db.Get([]byte(forkRecordPrefix+key), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return data, err
+}
+
+// WriteForkRecords guarda registros del fork en un solo batch
+func (b *BlockchainDB) WriteForkRecords(records map[string][]byte) error {
+	if len(records) == 0 {
+		return nil
+	}
+	batch := new(leveldb.Batch)
+	for key, data := range records {
+		batch.Put([]byte(forkRecordPrefix+key), data)
+	}
+	return b.db.Write(batch, nil)
+}
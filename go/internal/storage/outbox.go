@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// meshOutboxPrefix guarda los mensajes mesh pendientes de envío, por número de secuencia
+const meshOutboxPrefix = "network:outbox:"
+
+// meshOutboxKey es la clave de un mensaje pendiente; la secuencia con ancho fijo ordena las claves
+func meshOutboxKey(seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s%020d", meshOutboxPrefix, seq))
+}
+
+// SaveOutboxMessage guarda un mensaje mesh pendiente de envío
+func (b *BlockchainDB) SaveOutboxMessage(seq uint64, data []byte) error {
+	return b.db.Put(meshOutboxKey(seq), data, nil)
+}
+
+// DeleteOutboxMessages elimina mensajes mesh enviados o descartados
+func (b *BlockchainDB) DeleteOutboxMessages(seqs []uint64) error {
+	if len(seqs) == 0 {
+		return nil
+	}
+	batch := new(leveldb.Batch)
+	for _, seq := range seqs {
+		batch.Delete(meshOutboxKey(seq))
+	}
+	return b.db.Write(batch, nil)
+}
+
+// ListOutboxMessages recorre los mensajes mesh pendientes en orden de secuencia
+func (b *BlockchainDB) ListOutboxMessages(fn func(seq uint64, data []byte) error) error {
+	iter := b.db.NewIterator(util.BytesPrefix([]byte(meshOutboxPrefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		seq, err := strconv.ParseUint(strings.TrimPrefix(string(iter.Key()), meshOutboxPrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		if err := fn(seq, append([]byte(nil), iter.Value()...)); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// recordFormatKey guarda la versión del formato de los bloques y transacciones (sin clave: JSON)
+const recordFormatKey = "records:format"
+
+// rewriteBatchSize es la cantidad de registros reescritos por batch
+const rewriteBatchSize = 1024
+
+// GetRecordFormat obtiene la versión del formato de los bloques y transacciones guardados
+// (0 si la base de datos es anterior al formato versionado)
+func (b *BlockchainDB) GetRecordFormat() (int, error) {
+	data, err := b.db.Get([]byte(recordFormatKey), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(data))
+}
+
+// SaveRecordFormat registra la versión del formato de los bloques y transacciones guardados
+func (b *BlockchainDB) SaveRecordFormat(version int) error {
+	return b.db.Put([]byte(recordFormatKey), []byte(strconv.Itoa(version)), nil)
+}
+
+// RewriteRecords recorre los bloques (por hash y en el formato anterior por altura) y las transacciones,
+// y reemplaza cada registro por lo que retorna rewriteBlock o rewriteTx (nil lo deja como está)
+// Retorna la cantidad de registros reescritos
+func (b *BlockchainDB) RewriteRecords(rewriteBlock, rewriteTx func(data []byte) ([]byte, error)) (int, error) {
+	rewritten := 0
+	batch := new(leveldb.Batch)
+	flush := func() error {
+		if batch.Len() == 0 {
+			return nil
+		}
+		if err := b.db.Write(batch, nil); err != nil {
+			return err
+		}
+		rewritten += batch.Len()
+		batch.Reset()
+		return nil
+	}
+
+	rewrite := func(prefix string, skip func(key string) bool, fn func(data []byte) ([]byte, error)) error {
+		iter := b.db.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+		defer iter.Release()
+		for iter.Next() {
+			if skip != nil && skip(string(iter.Key())) {
+				continue
+			}
+			data, err := fn(iter.Value())
+			if err != nil {
+				return fmt.Errorf("registro %s: %w", iter.Key(), err)
+			}
+			if data == nil {
+				continue
+			}
+			batch.Put(append([]byte(nil), iter.Key()...), data)
+			if batch.Len() >= rewriteBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return iter.Error()
+	}
+
+	// El prefijo de los bloques sin hash también cubre el índice canónico, que no son registros
+	legacyOnly := func(key string) bool {
+		return strings.HasPrefix(key, blockHashPrefix) || strings.HasPrefix(key, blockCanonicalPrefix)
+	}
+	if err := rewrite(blockHashPrefix, nil, rewriteBlock); err != nil {
+		return rewritten, err
+	}
+	if err := rewrite(legacyBlockPrefix, legacyOnly, rewriteBlock); err != nil {
+		return rewritten, err
+	}
+	if err := rewrite(transactionPrefix, nil, rewriteTx); err != nil {
+		return rewritten, err
+	}
+	return rewritten, flush()
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"os"
+	"testing"
+)
+
+// TestRewriteRecords verifica que se recorran los bloques y transacciones (sin el índice canónico) y
+// la versión del formato de los registros
+func TestRewriteRecords(t *testing.T) {
+	tmpDir := "./test_data_records"
+	defer os.RemoveAll(tmpDir)
+
+	db, err := NewBlockchainDB(tmpDir)
+	if err != nil {
+		t.Fatalf("Error creando base de datos: %v", err)
+	}
+	defer db.Close()
+
+	if format, err := db.GetRecordFormat(); err != nil || format != 0 {
+		t.Errorf("Formato inicial incorrecto: %d (%v)", format, err)
+	}
+	db.SaveBlock(1, []byte("bloque 1"))
+	db.SaveCanonicalBlock(2, "0xaa", []byte("bloque 2"))
+	db.SaveTransaction("0x01", []byte("tx 1"))
+	db.SaveTransaction("0x02", []byte("nueva"))
+
+	var blocks []string
+	rewritten, err := db.RewriteRecords(
+		func(data []byte) ([]byte, error) {
+			blocks = append(blocks, string(data))
+			return append([]byte("v2 "), data...), nil
+		},
+		func(data []byte) ([]byte, error) {
+			if string(data) == "nueva" {
+				return nil, nil
+			}
+			return []byte("v2 tx"), nil
+		},
+	)
+	if err != nil || rewritten != 3 || len(blocks) != 2 {
+		t.Fatalf("Reescritos %d registros, bloques %v (%v)", rewritten, blocks, err)
+	}
+	if data, _ := db.GetBlock(1); string(data) != "v2 bloque 1" {
+		t.Errorf("Bloque sin hash no reescrito: %s", data)
+	}
+	if data, _ := db.GetBlock(2); string(data) != "v2 bloque 2" {
+		t.Errorf("Bloque por hash no reescrito: %s", data)
+	}
+	if data, _ := db.GetTransaction("0x02"); string(data) != "nueva" {
+		t.Errorf("Transacción sin cambios reescrita: %s", data)
+	}
+
+	if err := db.SaveRecordFormat(1); err != nil {
+		t.Fatalf("Error guardando formato: %v", err)
+	}
+	if format, err := db.GetRecordFormat(); err != nil || format != 1 {
+		t.Errorf("Formato incorrecto: %d (%v)", format, err)
+	}
+}
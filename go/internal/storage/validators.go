@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Claves de los validadores: un registro por validador, fuera del espacio de cuentas
+const (
+	validatorRecordPrefix = "validators:record:" // dirección -> registro JSON versionado
+	legacyValidatorSetKey = "account:validators:set"
+)
+
+// WriteValidatorRecords guarda y borra registros de validadores en un solo batch
+// Solo se escriben los validadores modificados, el resto del set no se toca
+func (b *BlockchainDB) WriteValidatorRecords(records map[string][]byte, deleted []string) error {
+	if len(records) == 0 && len(deleted) == 0 {
+		return nil
+	}
+	batch := new(leveldb.Batch)
+	for address, data := range records {
+		batch.Put([]byte(validatorRecordPrefix+address), data)
+	}
+	for _, address := range deleted {
+		batch.Delete([]byte(validatorRecordPrefix + address))
+	}
+	return b.db.Write(batch, nil)
+}
+
+// ListValidatorRecords retorna todos los registros de validadores (dirección -> registro)
+func (b *BlockchainDB) ListValidatorRecords() (map[string][]byte, error) {
+	iter := b.db.NewIterator(util.BytesPrefix([]byte(validatorRecordPrefix)), nil)
+	defer iter.Release()
+
+	records := make(map[string][]byte)
+	for iter.Next() {
+		address := string(iter.Key()[len(validatorRecordPrefix):])
+		records[address] = append([]byte(nil), iter.Value()...)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("error recorriendo validadores: %w", err)
+	}
+	return records, nil
+}
+
+// GetLegacyValidatorSet obtiene el set de validadores en el formato anterior (un solo JSON en
+// las cuentas), nil si no existe. Se usa una sola vez para migrar al formato por validador
+func (b *BlockchainDB) GetLegacyValidatorSet() ([]byte, error) {
+	data, err := b.db.Get([]byte(legacyValidatorSetKey), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return data, err
+}
+
+// DeleteLegacyValidatorSet borra el set de validadores en el formato anterior
+func (b *BlockchainDB) DeleteLegacyValidatorSet() error {
+	return b.db.Delete([]byte(legacyValidatorSetKey), nil)
+}
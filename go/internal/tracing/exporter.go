@@ -0,0 +1,299 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Valores por defecto del exportador
+const (
+	DefaultQueueSize     = 4096
+	DefaultBatchSize     = 512
+	DefaultFlushInterval = 5 * time.Second
+	DefaultExportTimeout = 10 * time.Second
+	scopeName            = "github.com/Q-YZX0/oxy-blockchain"
+)
+
+// Exporter envía spans en lotes a un collector OTLP/HTTP con codificación JSON (POST {endpoint}/v1/traces),
+// compatible con el OpenTelemetry Collector, Jaeger y Tempo. Si la cola se llena los spans se descartan
+type Exporter struct {
+	endpoint      string
+	serviceName   string
+	client        *http.Client
+	queue         chan *Span
+	batchSize     int
+	flushInterval time.Duration
+	dropped       atomic.Uint64
+
+	flush    chan chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewExporter crea un exportador para endpoint (URL base del collector o URL completa de /v1/traces)
+func NewExporter(endpoint, serviceName string) (*Exporter, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("endpoint OTLP inválido: %q", endpoint)
+	}
+	if !strings.HasSuffix(parsed.Path, "/v1/traces") {
+		parsed.Path = strings.TrimSuffix(parsed.Path, "/") + "/v1/traces"
+	}
+	return &Exporter{
+		endpoint:      parsed.String(),
+		serviceName:   serviceName,
+		client:        &http.Client{Timeout: DefaultExportTimeout},
+		queue:         make(chan *Span, DefaultQueueSize),
+		batchSize:     DefaultBatchSize,
+		flushInterval: DefaultFlushInterval,
+		flush:         make(chan chan struct{}),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}, nil
+}
+
+// Start inicia el envío en segundo plano
+func (e *Exporter) Start() {
+	go e.run()
+}
+
+// Dropped retorna cuántos spans se descartaron por cola llena o error de envío
+func (e *Exporter) Dropped() uint64 {
+	return e.dropped.Load()
+}
+
+// enqueue encola un span terminado sin bloquear la operación medida
+func (e *Exporter) enqueue(span *Span) {
+	select {
+	case e.queue <- span:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// ForceFlush exporta los spans encolados hasta el momento
+func (e *Exporter) ForceFlush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case e.flush <- ack:
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown exporta lo pendiente y detiene el exportador
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.stop) })
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run agrupa los spans en lotes y los envía por tamaño o por intervalo
+func (e *Exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, e.batchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.export(batch); err != nil {
+			e.dropped.Add(uint64(len(batch)))
+			log.Printf("⚠️ Error exportando %d spans a %s: %v", len(batch), e.endpoint, err)
+		}
+		batch = batch[:0]
+	}
+	drain := func() {
+		for {
+			select {
+			case span := <-e.queue:
+				batch = append(batch, span)
+				if len(batch) >= e.batchSize {
+					send()
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) >= e.batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-e.flush:
+			drain()
+			send()
+			close(ack)
+		case <-e.stop:
+			drain()
+			send()
+			return
+		}
+	}
+}
+
+// export envía un lote al collector
+func (e *Exporter) export(batch []*Span) error {
+	body, err := json.Marshal(e.encode(batch))
+	if err != nil {
+		return fmt.Errorf("error serializando spans: %w", err)
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector respondió %s", resp.Status)
+	}
+	return nil
+}
+
+// Tipos del mensaje ExportTraceServiceRequest de OTLP en JSON
+// (IDs en hex, enteros de 64 bits y timestamps como string)
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Links             []otlpLink     `json:"links,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpLink struct {
+	TraceID string `json:"traceId"`
+	SpanID  string `json:"spanId"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"` // 0 = unset, 2 = error
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+// encode convierte un lote de spans al formato OTLP
+func (e *Exporter) encode(batch []*Span) otlpRequest {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, span := range batch {
+		span.mu.Lock()
+		encoded := otlpSpan{
+			TraceID:           span.sc.TraceID.String(),
+			SpanID:            span.sc.SpanID.String(),
+			Name:              span.name,
+			Kind:              span.kind,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+			Attributes:        encodeAttributes(span.attributes),
+		}
+		if span.parent.IsValid() {
+			encoded.ParentSpanID = span.parent.String()
+		}
+		for _, link := range span.links {
+			encoded.Links = append(encoded.Links, otlpLink{TraceID: link.TraceID.String(), SpanID: link.SpanID.String()})
+		}
+		if span.errMessage != "" {
+			encoded.Status = otlpStatus{Code: 2, Message: span.errMessage}
+		}
+		span.mu.Unlock()
+		spans = append(spans, encoded)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: encodeAttributes([]Attribute{String("service.name", e.serviceName)})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: scopeName}, Spans: spans}},
+	}}}
+}
+
+// encodeAttributes convierte atributos a key/value de OTLP (otros tipos se serializan como texto)
+func encodeAttributes(attributes []Attribute) []otlpKeyValue {
+	encoded := make([]otlpKeyValue, 0, len(attributes))
+	for _, attr := range attributes {
+		var value otlpValue
+		switch v := attr.Value.(type) {
+		case string:
+			value.StringValue = &v
+		case int64:
+			s := strconv.FormatInt(v, 10)
+			value.IntValue = &s
+		case float64:
+			value.DoubleValue = &v
+		case bool:
+			value.BoolValue = &v
+		default:
+			s := fmt.Sprint(v)
+			value.StringValue = &s
+		}
+		encoded = append(encoded, otlpKeyValue{Key: attr.Key, Value: value})
+	}
+	return encoded
+}
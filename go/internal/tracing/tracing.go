@@ -0,0 +1,318 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID identifica una traza (16 bytes, W3C Trace Context)
+type TraceID [16]byte
+
+// SpanID identifica un span dentro de una traza (8 bytes)
+type SpanID [8]byte
+
+// String retorna el ID en hex
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsValid retorna si el ID no es todo ceros
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// String retorna el ID en hex
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsValid retorna si el ID no es todo ceros
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext es la parte propagable de un span (entre procesos vía traceparent o entre fases vía links)
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid retorna si el contexto identifica un span
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Attribute es un atributo de un span (string, int64, float64 o bool)
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String crea un atributo de texto
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Int64 crea un atributo entero
+func Int64(key string, value int64) Attribute { return Attribute{Key: key, Value: value} }
+
+// Float64 crea un atributo decimal
+func Float64(key string, value float64) Attribute { return Attribute{Key: key, Value: value} }
+
+// Bool crea un atributo booleano
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// Tipos de span (valores de SpanKind de OTLP)
+const (
+	KindInternal = 1
+	KindServer   = 2
+)
+
+// Span es una operación medida. Un *Span nil es válido y no hace nada (tracing deshabilitado)
+type Span struct {
+	tracer *Tracer
+	name   string
+	kind   int
+	sc     SpanContext
+	parent SpanID
+	start  time.Time
+
+	mu         sync.Mutex
+	end        time.Time
+	attributes []Attribute
+	links      []SpanContext
+	errMessage string
+	ended      bool
+}
+
+// SpanContext retorna el contexto propagable del span
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetAttributes agrega atributos al span
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes = append(s.attributes, attributes...)
+}
+
+// AddLink relaciona el span con otro de otra traza (ej: la petición REST que envió una transacción)
+func (s *Span) AddLink(sc SpanContext) {
+	if s == nil || !sc.IsValid() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links = append(s.links, sc)
+}
+
+// RecordError marca el span como fallido (err nil no hace nada)
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errMessage = err.Error()
+}
+
+// End termina el span y lo encola para exportar si está muestreado
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if s.sc.Sampled && s.tracer != nil && s.tracer.exporter != nil {
+		s.tracer.exporter.enqueue(s)
+	}
+}
+
+// Tracer crea spans y los entrega al exportador OTLP
+type Tracer struct {
+	sampleRatio float64
+	exporter    *Exporter
+}
+
+// Config configura el tracing
+type Config struct {
+	Endpoint    string  // Endpoint OTLP/HTTP del collector (ej: http://localhost:4318); vacío = deshabilitado
+	ServiceName string  // service.name del recurso
+	SampleRatio float64 // Fracción de trazas nuevas que se muestrean (0..1); las trazas entrantes respetan su flag
+}
+
+// global es el tracer del proceso (nil = tracing deshabilitado)
+var global atomic.Pointer[Tracer]
+
+// Init configura el tracer global. Con Endpoint vacío el tracing queda deshabilitado y Start no crea spans
+func Init(cfg Config) (*Tracer, error) {
+	if cfg.Endpoint == "" {
+		global.Store(nil)
+		return nil, nil
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("sample ratio fuera de rango (0..1): %v", cfg.SampleRatio)
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "oxy-blockchain"
+	}
+	exporter, err := NewExporter(cfg.Endpoint, cfg.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	tracer := &Tracer{sampleRatio: cfg.SampleRatio, exporter: exporter}
+	exporter.Start()
+	global.Store(tracer)
+	return tracer, nil
+}
+
+// Shutdown deshabilita el tracer y exporta los spans pendientes
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	global.CompareAndSwap(t, nil)
+	return t.exporter.Shutdown(ctx)
+}
+
+// Enabled retorna si hay un tracer configurado
+func Enabled() bool {
+	return global.Load() != nil
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// Start crea un span interno hijo del span de ctx
+func Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, *Span) {
+	return start(ctx, name, KindInternal, attributes)
+}
+
+// StartServer crea un span de servidor (peticiones HTTP entrantes)
+func StartServer(ctx context.Context, name string, attributes ...Attribute) (context.Context, *Span) {
+	return start(ctx, name, KindServer, attributes)
+}
+
+// start crea un span; sin tracer retorna ctx y un span nil
+func start(ctx context.Context, name string, kind int, attributes []Attribute) (context.Context, *Span) {
+	tracer := global.Load()
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	parent := SpanContextFromContext(ctx)
+	span := &Span{
+		tracer:     tracer,
+		name:       name,
+		kind:       kind,
+		start:      time.Now(),
+		attributes: attributes,
+	}
+	if parent.IsValid() {
+		span.sc.TraceID = parent.TraceID
+		span.sc.Sampled = parent.Sampled
+		span.parent = parent.SpanID
+	} else {
+		rand.Read(span.sc.TraceID[:])
+		span.sc.Sampled = tracer.sample()
+	}
+	rand.Read(span.sc.SpanID[:])
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// sample decide si se muestrea una traza nueva
+func (t *Tracer) sample() bool {
+	if t.sampleRatio >= 1 {
+		return true
+	}
+	if t.sampleRatio <= 0 {
+		return false
+	}
+	const precision = 1 << 30
+	n, err := rand.Int(rand.Reader, big.NewInt(precision))
+	if err != nil {
+		return false
+	}
+	return float64(n.Int64()) < t.sampleRatio*precision
+}
+
+// SpanFromContext retorna el span activo de ctx (nil si no hay)
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext retorna el contexto del span activo o el remoto extraído de una petición
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.sc
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// Extract lee la cabecera traceparent (W3C Trace Context) y la deja en ctx como padre remoto
+func Extract(ctx context.Context, header http.Header) context.Context {
+	sc, ok := parseTraceparent(header.Get("traceparent"))
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// Inject escribe la cabecera traceparent del span activo de ctx
+func Inject(ctx context.Context, header http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	header.Set("traceparent", FormatTraceparent(sc))
+}
+
+// FormatTraceparent serializa un SpanContext como traceparent (versión 00)
+func FormatTraceparent(sc SpanContext) string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// parseTraceparent parsea "00-<trace-id>-<span-id>-<flags>"
+func parseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&0x01 == 0x01
+	return sc, sc.IsValid()
+}
@@ -0,0 +1,121 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestTraceparent prueba el parseo y la serialización de W3C traceparent
+func TestTraceparent(t *testing.T) {
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := parseTraceparent(value)
+	if !ok || !sc.Sampled || sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("traceparent mal parseado: %+v, %v", sc, ok)
+	}
+	if FormatTraceparent(sc) != value {
+		t.Errorf("Serialización inesperada: %s", FormatTraceparent(sc))
+	}
+
+	for _, invalid := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01", // Trace ID cero
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",    // Sin flags
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", // Versión inválida
+		"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01", // Hex inválido
+	} {
+		if _, ok := parseTraceparent(invalid); ok {
+			t.Errorf("traceparent inválido aceptado: %q", invalid)
+		}
+	}
+}
+
+// TestStart_Disabled prueba que sin tracer no se creen spans
+func TestStart_Disabled(t *testing.T) {
+	ctx, span := Start(context.Background(), "noop")
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Fatal("Sin tracer no debería crearse un span")
+	}
+	// Los métodos de un span nil no hacen nada
+	span.SetAttributes(String("a", "b"))
+	span.RecordError(errors.New("x"))
+	span.End()
+}
+
+// TestExporter prueba la jerarquía de spans, la propagación remota y el envío OTLP/JSON al collector
+func TestExporter(t *testing.T) {
+	var mu sync.Mutex
+	var received []otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Petición inesperada: %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Body inválido: %v", err)
+		}
+		mu.Lock()
+		received = append(received, req)
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	tracer, err := Init(Config{Endpoint: collector.URL, ServiceName: "oxy-test", SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("Error inicializando tracer: %v", err)
+	}
+	defer tracer.Shutdown(context.Background())
+
+	// Padre remoto desde traceparent
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := Extract(context.Background(), header)
+
+	ctx, parent := StartServer(ctx, "HTTP POST", String("http.method", "POST"))
+	_, child := Start(ctx, "mempool.Insert", Int64("tx.size", 120))
+	child.RecordError(errors.New("mempool lleno"))
+	child.End()
+	parent.End()
+	parent.End() // Idempotente
+
+	out := http.Header{}
+	Inject(ctx, out)
+	if out.Get("traceparent") != FormatTraceparent(parent.SpanContext()) {
+		t.Errorf("Inject debería propagar el span activo: %s", out.Get("traceparent"))
+	}
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracer.exporter.ForceFlush(flushCtx); err != nil {
+		t.Fatalf("Error en ForceFlush: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("Esperado 1 envío, obtenido %d", len(received))
+	}
+	resource := received[0].ResourceSpans[0]
+	if *resource.Resource.Attributes[0].Value.StringValue != "oxy-test" {
+		t.Errorf("service.name inesperado: %+v", resource.Resource.Attributes)
+	}
+	spans := resource.ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("Esperados 2 spans, obtenidos %d", len(spans))
+	}
+	childSpan, parentSpan := spans[0], spans[1]
+	if parentSpan.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || parentSpan.ParentSpanID != "00f067aa0ba902b7" || parentSpan.Kind != KindServer {
+		t.Errorf("El span de servidor debería continuar la traza remota: %+v", parentSpan)
+	}
+	if childSpan.ParentSpanID != parentSpan.SpanID || childSpan.TraceID != parentSpan.TraceID {
+		t.Errorf("Jerarquía inesperada: %+v", childSpan)
+	}
+	if childSpan.Status.Code != 2 || childSpan.Status.Message != "mempool lleno" || *childSpan.Attributes[0].Value.IntValue != "120" {
+		t.Errorf("Span hijo inesperado: %+v", childSpan)
+	}
+}
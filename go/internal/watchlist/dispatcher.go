@@ -0,0 +1,214 @@
+package watchlist
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Q-YZX0/oxy-blockchain/internal/logger"
+)
+
+// watchlistLog es el logger del módulo watchlist
+var watchlistLog = logger.For("watchlist")
+
+// Valores por defecto de la entrega de webhooks
+const (
+	DefaultWorkers     = 4
+	DefaultQueueSize   = 1000
+	DefaultMaxAttempts = 5
+	DefaultBaseBackoff = time.Second
+	DefaultMaxBackoff  = time.Minute
+	DefaultTimeout     = 10 * time.Second
+)
+
+// Cabeceras de los webhooks
+const (
+	HeaderNotificationID = "X-Oxy-Notification-Id"
+	HeaderSignature      = "X-Oxy-Signature" // "sha256=" + HMAC-SHA256 del cuerpo con el secret de la suscripción
+	HeaderAttempt        = "X-Oxy-Attempt"
+)
+
+// delivery es una notificación pendiente de entrega
+type delivery struct {
+	url          string
+	secret       string
+	notification Notification
+	body         []byte
+	attempt      int
+}
+
+// Dispatcher entrega notificaciones por HTTP POST con reintentos y backoff exponencial
+type Dispatcher struct {
+	client      *http.Client
+	queue       chan *delivery
+	workers     int
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewDispatcher crea un dispatcher con los valores por defecto
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		client:      &http.Client{Timeout: DefaultTimeout},
+		queue:       make(chan *delivery, DefaultQueueSize),
+		workers:     DefaultWorkers,
+		maxAttempts: DefaultMaxAttempts,
+		baseBackoff: DefaultBaseBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// SetRetryPolicy configura los intentos y el backoff entre reintentos (valores <= 0 = sin cambio)
+// Debe llamarse antes de Start
+func (d *Dispatcher) SetRetryPolicy(maxAttempts int, baseBackoff, maxBackoff time.Duration) {
+	if maxAttempts > 0 {
+		d.maxAttempts = maxAttempts
+	}
+	if baseBackoff > 0 {
+		d.baseBackoff = baseBackoff
+	}
+	if maxBackoff > 0 {
+		d.maxBackoff = maxBackoff
+	}
+}
+
+// SetTimeout configura el timeout de cada POST
+func (d *Dispatcher) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		d.client.Timeout = timeout
+	}
+}
+
+// Start inicia los workers de entrega
+func (d *Dispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running {
+		return
+	}
+	d.running = true
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+}
+
+// Stop detiene los workers; las entregas pendientes se descartan
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	if !d.running {
+		d.mu.Unlock()
+		return
+	}
+	d.running = false
+	close(d.stopCh)
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+// Enqueue encola una notificación para entregarla a url
+// Si la cola está llena la notificación se descarta (se registra en el log)
+func (d *Dispatcher) Enqueue(url, secret string, notification Notification) {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		watchlistLog.Warn("Error serializando notificación: " + err.Error())
+		return
+	}
+	d.enqueue(&delivery{url: url, secret: secret, notification: notification, body: body, attempt: 1})
+}
+
+// enqueue agrega una entrega a la cola sin bloquear
+func (d *Dispatcher) enqueue(item *delivery) {
+	select {
+	case <-d.stopCh:
+		return
+	default:
+	}
+	select {
+	case d.queue <- item:
+	default:
+		watchlistLog.Warnf("Cola de webhooks llena: notificación %s descartada", item.notification.ID)
+	}
+}
+
+// worker entrega las notificaciones de la cola
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case item := <-d.queue:
+			if err := d.post(item); err != nil {
+				d.retry(item, err)
+			}
+		}
+	}
+}
+
+// retry reprograma una entrega fallida con backoff exponencial hasta maxAttempts
+func (d *Dispatcher) retry(item *delivery, err error) {
+	if item.attempt >= d.maxAttempts {
+		watchlistLog.Warnf("Webhook %s abandonado tras %d intentos: %v", item.notification.ID, item.attempt, err)
+		return
+	}
+	backoff := d.backoff(item.attempt)
+	item.attempt++
+	time.AfterFunc(backoff, func() { d.enqueue(item) })
+}
+
+// backoff retorna la espera antes del intento siguiente a attempt
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	backoff := d.baseBackoff
+	for i := 1; i < attempt && backoff < d.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.maxBackoff {
+		backoff = d.maxBackoff
+	}
+	return backoff
+}
+
+// post envía una notificación; cualquier respuesta fuera de 2xx es un fallo
+func (d *Dispatcher) post(item *delivery) error {
+	req, err := http.NewRequest(http.MethodPost, item.url, bytes.NewReader(item.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderNotificationID, item.notification.ID)
+	req.Header.Set(HeaderAttempt, fmt.Sprintf("%d", item.attempt))
+	if item.secret != "" {
+		req.Header.Set(HeaderSignature, Sign(item.secret, item.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("respuesta %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign calcula la firma de un cuerpo de webhook ("sha256=" + HMAC-SHA256 en hex)
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,459 @@
+package watchlist
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Límites por defecto del registro
+const (
+	DefaultMaxSubscriptions = 100
+	DefaultMaxAddresses     = 1000
+	listenerBuffer          = 64
+)
+
+// ErrNotFound indica que la suscripción no existe
+var ErrNotFound = errors.New("suscripción no encontrada")
+
+// Subscription es un conjunto de direcciones vigiladas y el destino de sus notificaciones
+type Subscription struct {
+	ID          string    `json:"id"`
+	Addresses   []string  `json:"addresses"`
+	CallbackURL string    `json:"callbackUrl,omitempty"` // Vacío = solo por WebSocket
+	Secret      string    `json:"secret,omitempty"`      // Firma HMAC-SHA256 del cuerpo (cabecera X-Oxy-Signature)
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Store persiste las suscripciones (BlockchainDB)
+type Store interface {
+	SaveWatchlist(data []byte) error
+	GetWatchlist() ([]byte, error)
+}
+
+// Block es la vista de un bloque confirmado que se compara con el watchlist
+type Block struct {
+	Height       uint64
+	Hash         string
+	Transactions []Transaction
+}
+
+// Transaction es una transacción confirmada con sus logs
+type Transaction struct {
+	Hash  string
+	From  string
+	To    string
+	Value string
+	Logs  []Log
+}
+
+// Log es un evento emitido por un contrato
+type Log struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    []byte   `json:"data"`
+}
+
+// Tipos de notificación
+const (
+	NotificationTransaction = "transaction" // La dirección vigilada envía o recibe la transacción
+	NotificationLog         = "log"         // La dirección vigilada emite el log o aparece en sus topics
+	NotificationChainReorg  = "chain_reorg" // La cadena retrocedió: las notificaciones desde Reorg.Height dejan de valer
+)
+
+// Reorg describe un retroceso de la cadena detectado al iniciar el nodo (datos borrados o restaurados de un
+// backup anterior). Los bloques desde Height pueden volver a notificarse con otro contenido
+type Reorg struct {
+	Height    uint64 `json:"height"`    // Primera altura invalidada
+	OldHeight uint64 `json:"oldHeight"` // Último bloque confirmado antes del reinicio
+	OldHash   string `json:"oldHash"`
+	NewHeight uint64 `json:"newHeight"` // Último bloque guardado al reiniciar
+	NewHash   string `json:"newHash"`
+	Reason    string `json:"reason"`
+}
+
+// Notification es el aviso de que una dirección vigilada aparece en un bloque confirmado
+type Notification struct {
+	ID             string `json:"id"` // Estable entre reintentos, para deduplicar del lado del cliente
+	SubscriptionID string `json:"subscriptionId"`
+	Type           string `json:"type"`
+	Address        string `json:"address"`
+	Height         uint64 `json:"height"`
+	BlockHash      string `json:"blockHash"`
+	TxHash         string `json:"txHash"`
+	From           string `json:"from,omitempty"`
+	To             string `json:"to,omitempty"`
+	Value          string `json:"value,omitempty"`
+	LogIndex       int    `json:"logIndex,omitempty"`
+	Log            *Log   `json:"log,omitempty"`
+	Reorg          *Reorg `json:"reorg,omitempty"`
+}
+
+// Registry mantiene las suscripciones y reparte las notificaciones de cada bloque
+type Registry struct {
+	mu               sync.RWMutex
+	store            Store
+	dispatcher       *Dispatcher // Entrega de webhooks (nil = solo WebSocket)
+	subscriptions    map[string]*Subscription
+	byAddress        map[string]map[string]bool // dirección (minúsculas) -> IDs de suscripción
+	listeners        map[string]map[chan Notification]struct{}
+	maxSubscriptions int
+	maxAddresses     int
+}
+
+// NewRegistry crea el registro y carga las suscripciones guardadas
+func NewRegistry(store Store, dispatcher *Dispatcher) (*Registry, error) {
+	r := &Registry{
+		store:            store,
+		dispatcher:       dispatcher,
+		subscriptions:    make(map[string]*Subscription),
+		byAddress:        make(map[string]map[string]bool),
+		listeners:        make(map[string]map[chan Notification]struct{}),
+		maxSubscriptions: DefaultMaxSubscriptions,
+		maxAddresses:     DefaultMaxAddresses,
+	}
+
+	if store != nil {
+		data, err := store.GetWatchlist()
+		if err != nil {
+			return nil, fmt.Errorf("error cargando watchlist: %w", err)
+		}
+		if data != nil {
+			var subscriptions []*Subscription
+			if err := json.Unmarshal(data, &subscriptions); err != nil {
+				return nil, fmt.Errorf("watchlist guardado inválido: %w", err)
+			}
+			for _, sub := range subscriptions {
+				r.index(sub)
+			}
+		}
+	}
+	return r, nil
+}
+
+// SetLimits configura la cantidad máxima de suscripciones y de direcciones por suscripción (0 = sin cambio)
+func (r *Registry) SetLimits(maxSubscriptions, maxAddresses int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if maxSubscriptions > 0 {
+		r.maxSubscriptions = maxSubscriptions
+	}
+	if maxAddresses > 0 {
+		r.maxAddresses = maxAddresses
+	}
+}
+
+// Add valida y registra una suscripción nueva; el ID y la fecha se asignan aquí
+func (r *Registry) Add(sub Subscription) (*Subscription, error) {
+	if len(sub.Addresses) == 0 {
+		return nil, fmt.Errorf("se requiere al menos una dirección")
+	}
+	addresses := make([]string, 0, len(sub.Addresses))
+	seen := make(map[string]bool, len(sub.Addresses))
+	for _, address := range sub.Addresses {
+		if !isHexAddress(address) {
+			return nil, fmt.Errorf("dirección inválida: %q", address)
+		}
+		key := strings.ToLower(address)
+		if !seen[key] {
+			seen[key] = true
+			addresses = append(addresses, address)
+		}
+	}
+	if sub.CallbackURL != "" {
+		parsed, err := url.Parse(sub.CallbackURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("callbackUrl inválida: debe ser una URL http(s)")
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	created := &Subscription{
+		ID:          id,
+		Addresses:   addresses,
+		CallbackURL: sub.CallbackURL,
+		Secret:      sub.Secret,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(addresses) > r.maxAddresses {
+		return nil, fmt.Errorf("demasiadas direcciones (máximo %d)", r.maxAddresses)
+	}
+	if len(r.subscriptions) >= r.maxSubscriptions {
+		return nil, fmt.Errorf("límite de suscripciones alcanzado (%d)", r.maxSubscriptions)
+	}
+	r.index(created)
+	if err := r.persist(); err != nil {
+		r.unindex(created)
+		return nil, err
+	}
+	return created, nil
+}
+
+// Remove elimina una suscripción y cierra sus WebSockets
+func (r *Registry) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sub, ok := r.subscriptions[id]
+	if !ok {
+		return ErrNotFound
+	}
+	r.unindex(sub)
+	for ch := range r.listeners[id] {
+		close(ch)
+	}
+	delete(r.listeners, id)
+	return r.persist()
+}
+
+// Get retorna una suscripción por ID
+func (r *Registry) Get(id string) (*Subscription, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sub, ok := r.subscriptions[id]
+	return sub, ok
+}
+
+// List retorna todas las suscripciones
+func (r *Registry) List() []*Subscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	subscriptions := make([]*Subscription, 0, len(r.subscriptions))
+	for _, sub := range r.subscriptions {
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions
+}
+
+// Listen abre un canal con las notificaciones de una suscripción (para WebSocket)
+// El canal se cierra al llamar a la función retornada o al eliminar la suscripción.
+// Si el lector no consume a tiempo se descartan notificaciones (la entrega por webhook sí reintenta)
+func (r *Registry) Listen(id string) (<-chan Notification, func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		return nil, nil, ErrNotFound
+	}
+	ch := make(chan Notification, listenerBuffer)
+	if r.listeners[id] == nil {
+		r.listeners[id] = make(map[chan Notification]struct{})
+	}
+	r.listeners[id][ch] = struct{}{}
+
+	cancel := func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if _, ok := r.listeners[id][ch]; ok {
+			delete(r.listeners[id], ch)
+			close(ch)
+		}
+	}
+	return ch, cancel, nil
+}
+
+// Match retorna las notificaciones que genera un bloque según el watchlist actual
+func (r *Registry) Match(block *Block) []Notification {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var notifications []Notification
+	if len(r.byAddress) == 0 {
+		return notifications
+	}
+
+	for _, tx := range block.Transactions {
+		// Transacciones enviadas o recibidas por una dirección vigilada
+		for _, match := range r.matchAddresses(tx.From, tx.To) {
+			notifications = append(notifications, Notification{
+				ID:             fmt.Sprintf("%s:%s:tx:%s", match.subscription, tx.Hash, match.address),
+				SubscriptionID: match.subscription,
+				Type:           NotificationTransaction,
+				Address:        match.address,
+				Height:         block.Height,
+				BlockHash:      block.Hash,
+				TxHash:         tx.Hash,
+				From:           tx.From,
+				To:             tx.To,
+				Value:          tx.Value,
+			})
+		}
+
+		// Logs emitidos por una dirección vigilada o que la incluyen en sus topics (ej: Transfer de ERC-20)
+		for i := range tx.Logs {
+			log := tx.Logs[i]
+			candidates := append([]string{log.Address}, topicAddresses(log.Topics)...)
+			for _, match := range r.matchAddresses(candidates...) {
+				notifications = append(notifications, Notification{
+					ID:             fmt.Sprintf("%s:%s:log:%d:%s", match.subscription, tx.Hash, i, match.address),
+					SubscriptionID: match.subscription,
+					Type:           NotificationLog,
+					Address:        match.address,
+					Height:         block.Height,
+					BlockHash:      block.Hash,
+					TxHash:         tx.Hash,
+					LogIndex:       i,
+					Log:            &log,
+				})
+			}
+		}
+	}
+	return notifications
+}
+
+// Notify compara un bloque confirmado con el watchlist y entrega sus notificaciones
+// No bloquea: los webhooks se encolan en el Dispatcher
+func (r *Registry) Notify(block *Block) {
+	for _, notification := range r.Match(block) {
+		r.deliver(notification)
+	}
+}
+
+// NotifyReorg avisa a todas las suscripciones, vigilen o no las direcciones afectadas, que la cadena retrocedió
+func (r *Registry) NotifyReorg(reorg *Reorg) {
+	r.mu.RLock()
+	notifications := make([]Notification, 0, len(r.subscriptions))
+	for id := range r.subscriptions {
+		notifications = append(notifications, Notification{
+			ID:             fmt.Sprintf("%s:reorg:%d:%s", id, reorg.OldHeight, reorg.OldHash),
+			SubscriptionID: id,
+			Type:           NotificationChainReorg,
+			Height:         reorg.Height,
+			BlockHash:      reorg.NewHash,
+			Reorg:          reorg,
+		})
+	}
+	r.mu.RUnlock()
+
+	for _, notification := range notifications {
+		r.deliver(notification)
+	}
+}
+
+// deliver entrega una notificación a los WebSockets de su suscripción y encola su webhook
+func (r *Registry) deliver(notification Notification) {
+	r.mu.RLock()
+	sub := r.subscriptions[notification.SubscriptionID]
+	for ch := range r.listeners[notification.SubscriptionID] {
+		select {
+		case ch <- notification:
+		default:
+		}
+	}
+	r.mu.RUnlock()
+
+	if sub != nil && sub.CallbackURL != "" && r.dispatcher != nil {
+		r.dispatcher.Enqueue(sub.CallbackURL, sub.Secret, notification)
+	}
+}
+
+// addressMatch es una coincidencia de una dirección con una suscripción
+type addressMatch struct {
+	subscription string
+	address      string
+}
+
+// matchAddresses retorna las suscripciones que vigilan alguna de las direcciones (una vez por par)
+func (r *Registry) matchAddresses(addresses ...string) []addressMatch {
+	var matches []addressMatch
+	seen := make(map[addressMatch]bool)
+	for _, address := range addresses {
+		if address == "" {
+			continue
+		}
+		key := strings.ToLower(address)
+		for id := range r.byAddress[key] {
+			match := addressMatch{subscription: id, address: key}
+			if !seen[match] {
+				seen[match] = true
+				matches = append(matches, match)
+			}
+		}
+	}
+	return matches
+}
+
+// index agrega una suscripción a los mapas (requiere el lock)
+func (r *Registry) index(sub *Subscription) {
+	r.subscriptions[sub.ID] = sub
+	for _, address := range sub.Addresses {
+		key := strings.ToLower(address)
+		if r.byAddress[key] == nil {
+			r.byAddress[key] = make(map[string]bool)
+		}
+		r.byAddress[key][sub.ID] = true
+	}
+}
+
+// unindex quita una suscripción de los mapas (requiere el lock)
+func (r *Registry) unindex(sub *Subscription) {
+	delete(r.subscriptions, sub.ID)
+	for _, address := range sub.Addresses {
+		key := strings.ToLower(address)
+		delete(r.byAddress[key], sub.ID)
+		if len(r.byAddress[key]) == 0 {
+			delete(r.byAddress, key)
+		}
+	}
+}
+
+// persist guarda las suscripciones (requiere el lock)
+func (r *Registry) persist() error {
+	if r.store == nil {
+		return nil
+	}
+	subscriptions := make([]*Subscription, 0, len(r.subscriptions))
+	for _, sub := range r.subscriptions {
+		subscriptions = append(subscriptions, sub)
+	}
+	data, err := json.Marshal(subscriptions)
+	if err != nil {
+		return err
+	}
+	if err := r.store.SaveWatchlist(data); err != nil {
+		return fmt.Errorf("error guardando watchlist: %w", err)
+	}
+	return nil
+}
+
+// topicAddresses extrae las direcciones de los topics de 32 bytes con padding de ceros
+func topicAddresses(topics []string) []string {
+	var addresses []string
+	for _, topic := range topics {
+		topic = strings.TrimPrefix(strings.ToLower(topic), "0x")
+		if len(topic) == 64 && strings.Trim(topic[:24], "0") == "" {
+			addresses = append(addresses, "0x"+topic[24:])
+		}
+	}
+	return addresses
+}
+
+// isHexAddress verifica que la dirección tenga el formato 0x + 40 caracteres hex
+func isHexAddress(address string) bool {
+	if len(address) != 42 || !strings.HasPrefix(strings.ToLower(address), "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(address[2:])
+	return err == nil
+}
+
+// newID genera un ID aleatorio de suscripción
+func newID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generando ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,204 @@
+package watchlist
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore es un Store en memoria para tests
+type memStore struct {
+	data []byte
+}
+
+func (m *memStore) SaveWatchlist(data []byte) error { m.data = data; return nil }
+func (m *memStore) GetWatchlist() ([]byte, error)   { return m.data, nil }
+
+const (
+	watched = "0x1111111111111111111111111111111111111111"
+	other   = "0x2222222222222222222222222222222222222222"
+	token   = "0x3333333333333333333333333333333333333333"
+)
+
+// testBlock crea un bloque con una transferencia y un log Transfer de ERC-20 hacia la dirección vigilada
+func testBlock() *Block {
+	return &Block{
+		Height: 7,
+		Hash:   "0xblock",
+		Transactions: []Transaction{
+			{Hash: "0xtx1", From: other, To: "0x1111111111111111111111111111111111111111", Value: "5"},
+			{Hash: "0xtx2", From: other, To: token, Logs: []Log{{
+				Address: token,
+				Topics: []string{
+					"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+					"0x0000000000000000000000002222222222222222222222222222222222222222",
+					"0x0000000000000000000000001111111111111111111111111111111111111111",
+				},
+			}}},
+			{Hash: "0xtx3", From: other, To: token},
+		},
+	}
+}
+
+// TestRegistry_MatchAndPersist verifica coincidencias por transacción y por log, y la persistencia
+func TestRegistry_MatchAndPersist(t *testing.T) {
+	store := &memStore{}
+	registry, err := NewRegistry(store, nil)
+	if err != nil {
+		t.Fatalf("Error creando registro: %v", err)
+	}
+
+	if _, err := registry.Add(Subscription{Addresses: []string{"no-es-direccion"}}); err == nil {
+		t.Error("Una dirección inválida debería rechazarse")
+	}
+	if _, err := registry.Add(Subscription{Addresses: []string{watched}, CallbackURL: "ftp://example.com"}); err == nil {
+		t.Error("Una callbackUrl no http(s) debería rechazarse")
+	}
+
+	sub, err := registry.Add(Subscription{Addresses: []string{"0x1111111111111111111111111111111111111111", watched}})
+	if err != nil {
+		t.Fatalf("Error agregando suscripción: %v", err)
+	}
+	if len(sub.Addresses) != 1 {
+		t.Errorf("Las direcciones duplicadas deberían unificarse: %v", sub.Addresses)
+	}
+
+	notifications := registry.Match(testBlock())
+	if len(notifications) != 2 {
+		t.Fatalf("Se esperaban 2 notificaciones, hubo %d: %+v", len(notifications), notifications)
+	}
+	if notifications[0].Type != NotificationTransaction || notifications[0].TxHash != "0xtx1" || notifications[0].Value != "5" {
+		t.Errorf("Notificación de transacción inesperada: %+v", notifications[0])
+	}
+	if notifications[1].Type != NotificationLog || notifications[1].TxHash != "0xtx2" || notifications[1].Log == nil {
+		t.Errorf("Notificación de log inesperada: %+v", notifications[1])
+	}
+
+	// Las suscripciones sobreviven a un reinicio
+	reloaded, err := NewRegistry(store, nil)
+	if err != nil {
+		t.Fatalf("Error recargando registro: %v", err)
+	}
+	if _, ok := reloaded.Get(sub.ID); !ok {
+		t.Fatal("La suscripción debería persistir")
+	}
+	if err := reloaded.Remove(sub.ID); err != nil {
+		t.Fatalf("Error eliminando suscripción: %v", err)
+	}
+	if len(reloaded.Match(testBlock())) != 0 {
+		t.Error("Sin suscripciones no debería haber notificaciones")
+	}
+	if err := reloaded.Remove(sub.ID); err != ErrNotFound {
+		t.Errorf("Eliminar dos veces debería retornar ErrNotFound: %v", err)
+	}
+}
+
+// TestRegistry_Listen verifica la entrega a los canales de WebSocket
+func TestRegistry_Listen(t *testing.T) {
+	registry, _ := NewRegistry(nil, nil)
+	sub, err := registry.Add(Subscription{Addresses: []string{token}})
+	if err != nil {
+		t.Fatalf("Error agregando suscripción: %v", err)
+	}
+
+	ch, cancel, err := registry.Listen(sub.ID)
+	if err != nil {
+		t.Fatalf("Error en Listen: %v", err)
+	}
+	registry.Notify(testBlock())
+
+	// tx2 (destinatario y emisor del log) y tx3 (destinatario)
+	got := 0
+	for got < 3 {
+		select {
+		case <-ch:
+			got++
+		case <-time.After(time.Second):
+			t.Fatalf("Se esperaban 3 notificaciones, llegaron %d", got)
+		}
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Error("El canal debería cerrarse al cancelar")
+	}
+	if _, _, err := registry.Listen("desconocida"); err != ErrNotFound {
+		t.Errorf("Listen de una suscripción inexistente debería fallar: %v", err)
+	}
+}
+
+// TestRegistry_NotifyReorg verifica que el retroceso de la cadena se avise a todas las suscripciones
+func TestRegistry_NotifyReorg(t *testing.T) {
+	registry, _ := NewRegistry(nil, nil)
+	first, _ := registry.Add(Subscription{Addresses: []string{watched}})
+	second, _ := registry.Add(Subscription{Addresses: []string{other}})
+
+	reorg := &Reorg{Height: 5, OldHeight: 9, OldHash: "0xold", NewHeight: 4, NewHash: "0xnew", Reason: "altura menor"}
+	for _, sub := range []*Subscription{first, second} {
+		ch, cancel, err := registry.Listen(sub.ID)
+		if err != nil {
+			t.Fatalf("Error en Listen: %v", err)
+		}
+		defer cancel()
+		registry.NotifyReorg(reorg)
+
+		select {
+		case notification := <-ch:
+			if notification.Type != NotificationChainReorg || notification.Height != 5 || notification.Reorg != reorg {
+				t.Errorf("Notificación de reorg incorrecta: %+v", notification)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("La suscripción %s no recibió el reorg", sub.ID)
+		}
+	}
+}
+
+// TestDispatcher_RetriesWithSignature verifica los reintentos y la firma de los webhooks
+func TestDispatcher_RetriesWithSignature(t *testing.T) {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	delivered := make(chan *http.Request, 1)
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts[r.Header.Get(HeaderNotificationID)]++
+		current := attempts[r.Header.Get(HeaderNotificationID)]
+		mu.Unlock()
+		if current < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		delivered <- r
+	}))
+	defer server.Close()
+
+	dispatcher := NewDispatcher()
+	dispatcher.SetRetryPolicy(5, 10*time.Millisecond, 50*time.Millisecond)
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	registry, _ := NewRegistry(nil, dispatcher)
+	if _, err := registry.Add(Subscription{Addresses: []string{watched}, CallbackURL: server.URL, Secret: "s3cret"}); err != nil {
+		t.Fatalf("Error agregando suscripción: %v", err)
+	}
+	block := testBlock()
+	block.Transactions = block.Transactions[:1]
+	registry.Notify(block)
+
+	select {
+	case r := <-delivered:
+		if r.Header.Get(HeaderAttempt) != "3" {
+			t.Errorf("Intento inesperado: %s", r.Header.Get(HeaderAttempt))
+		}
+		if r.Header.Get(HeaderSignature) != Sign("s3cret", body) {
+			t.Error("Firma inválida")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("El webhook no se entregó tras los reintentos")
+	}
+}
@@ -0,0 +1,125 @@
+// Package wire implementa los tipos de types.proto, el formato de intercambio de transacciones, bloques,
+// receipts y queries compartido con los SDKs y el mesh bridge
+package wire
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ContentType es el media type de los mensajes protobuf en el API REST
+const ContentType = "application/x-protobuf"
+
+// field es un campo protobuf decodificado
+type field struct {
+	num    protowire.Number
+	typ    protowire.Type
+	varint uint64
+	bytes  []byte
+}
+
+// str retorna el valor de un campo string (vacío si el tipo no coincide)
+func (f field) str() string {
+	if f.typ != protowire.BytesType {
+		return ""
+	}
+	return string(f.bytes)
+}
+
+// bytesCopy retorna una copia del valor de un campo bytes (nil si el tipo no coincide)
+func (f field) bytesCopy() []byte {
+	if f.typ != protowire.BytesType || len(f.bytes) == 0 {
+		return nil
+	}
+	return append([]byte(nil), f.bytes...)
+}
+
+// uint retorna el valor de un campo varint (0 si el tipo no coincide)
+func (f field) uint() uint64 {
+	if f.typ != protowire.VarintType {
+		return 0
+	}
+	return f.varint
+}
+
+// message decodifica un submensaje en m (sin cambios si el tipo no coincide)
+func (f field) message(m interface{ Unmarshal([]byte) error }) error {
+	if f.typ != protowire.BytesType {
+		return nil
+	}
+	return m.Unmarshal(f.bytes)
+}
+
+// forEachField recorre los campos de un mensaje protobuf
+// Los campos de tipos que no se usan en el esquema se saltean para permitir extenderlo
+func forEachField(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("tag protobuf inválido: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		f := field{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("campo protobuf %d inválido: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		if err := fn(f); err != nil {
+			return fmt.Errorf("campo protobuf %d: %w", num, err)
+		}
+	}
+	return nil
+}
+
+// appendString agrega un campo string (omitido si está vacío, como en proto3)
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+// appendStrings agrega un campo repeated string (los elementos vacíos se conservan)
+func appendStrings(b []byte, num protowire.Number, values []string) []byte {
+	for _, value := range values {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendString(b, value)
+	}
+	return b
+}
+
+// appendBytes agrega un campo bytes (omitido si está vacío)
+func appendBytes(b []byte, num protowire.Number, value []byte) []byte {
+	if len(value) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
+
+// appendUint agrega un campo varint (omitido si es cero)
+func appendUint(b []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+// appendMessage agrega un submensaje ya codificado (los elementos de un repeated se agregan aunque estén vacíos)
+func appendMessage(b []byte, num protowire.Number, encoded []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, encoded)
+}
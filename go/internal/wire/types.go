@@ -0,0 +1,585 @@
+package wire
+
+import "google.golang.org/protobuf/encoding/protowire"
+
+// Campos protobuf de cada mensaje (esquema en types.proto)
+const (
+	txHash       protowire.Number = 1
+	txFrom       protowire.Number = 2
+	txTo         protowire.Number = 3
+	txValue      protowire.Number = 4
+	txData       protowire.Number = 5
+	txGasLimit   protowire.Number = 6
+	txGasPrice   protowire.Number = 7
+	txNonce      protowire.Number = 8
+	txSignature  protowire.Number = 9
+	txTimestamp  protowire.Number = 10
+	txAccessList protowire.Number = 11
+	txMultisig   protowire.Number = 12
+	txFeePayer   protowire.Number = 13
+
+	accessAddress     protowire.Number = 1
+	accessStorageKeys protowire.Number = 2
+
+	multisigThreshold  protowire.Number = 1
+	multisigOwners     protowire.Number = 2
+	multisigSignatures protowire.Number = 3
+
+	multisigSigSigner    protowire.Number = 1
+	multisigSigSignature protowire.Number = 2
+
+	feePayerAddress   protowire.Number = 1
+	feePayerSignature protowire.Number = 2
+
+	headerHeight           protowire.Number = 1
+	headerHash             protowire.Number = 2
+	headerParentHash       protowire.Number = 3
+	headerTimestamp        protowire.Number = 4
+	headerTimestampNanos   protowire.Number = 5
+	headerValidator        protowire.Number = 6
+	headerChainID          protowire.Number = 7
+	headerStateRoot        protowire.Number = 8
+	headerTransactionsRoot protowire.Number = 9
+	headerReceiptsRoot     protowire.Number = 10
+	headerGasUsed          protowire.Number = 11
+	headerGasLimit         protowire.Number = 12
+
+	blockHeader       protowire.Number = 1
+	blockTransactions protowire.Number = 2
+	blockReceipts     protowire.Number = 3
+
+	receiptTransactionHash protowire.Number = 1
+	receiptBlockHash       protowire.Number = 2
+	receiptBlockNumber     protowire.Number = 3
+	receiptGasUsed         protowire.Number = 4
+	receiptStatus          protowire.Number = 5
+	receiptLogs            protowire.Number = 6
+	receiptError           protowire.Number = 7
+
+	logAddress     protowire.Number = 1
+	logTopics      protowire.Number = 2
+	logData        protowire.Number = 3
+	logBlockNumber protowire.Number = 4
+	logTxHash      protowire.Number = 5
+
+	queryType      protowire.Number = 1
+	queryPath      protowire.Number = 2
+	queryRequestID protowire.Number = 3
+	queryFrom      protowire.Number = 4
+	queryTo        protowire.Number = 5
+
+	responseType        protowire.Number = 1
+	responseRequestID   protowire.Number = 2
+	responsePath        protowire.Number = 3
+	responseData        protowire.Number = 4
+	responseError       protowire.Number = 5
+	responseFrom        protowire.Number = 6
+	responseBlock       protowire.Number = 7
+	responseTransaction protowire.Number = 8
+)
+
+// Transaction es una transacción firmada
+type Transaction struct {
+	Hash       string
+	From       string
+	To         string
+	Value      string
+	Data       []byte
+	GasLimit   uint64
+	GasPrice   string
+	Nonce      uint64
+	Signature  []byte
+	Timestamp  int64
+	AccessList []*AccessTuple
+	Multisig   *MultisigAuth
+	FeePayer   *FeePayer
+}
+
+// AccessTuple es una entrada de access list (EIP-2930)
+type AccessTuple struct {
+	Address     string
+	StorageKeys []string
+}
+
+// MultisigAuth son los owners, el umbral y las firmas de una transacción multisig
+type MultisigAuth struct {
+	Threshold  uint64
+	Owners     []string
+	Signatures []*MultisigSignature
+}
+
+// MultisigSignature es la firma de un owner sobre el hash de una transacción multisig
+type MultisigSignature struct {
+	Signer    string
+	Signature []byte
+}
+
+// FeePayer es la cuenta que paga el gas de una transacción patrocinada y su firma
+type FeePayer struct {
+	Address   string
+	Signature []byte
+}
+
+// BlockHeader es el header de un bloque
+type BlockHeader struct {
+	Height           uint64
+	Hash             string
+	ParentHash       string
+	Timestamp        int64
+	TimestampNanos   int32
+	Validator        string
+	ChainID          string
+	StateRoot        string
+	TransactionsRoot string
+	ReceiptsRoot     string
+	GasUsed          uint64
+	GasLimit         uint64
+}
+
+// Block es un bloque con sus transacciones y receipts
+type Block struct {
+	Header       *BlockHeader
+	Transactions []*Transaction
+	Receipts     []*TransactionReceipt
+}
+
+// TransactionReceipt es el recibo de una transacción
+type TransactionReceipt struct {
+	TransactionHash string
+	BlockHash       string
+	BlockNumber     uint64
+	GasUsed         uint64
+	Status          string
+	Logs            []*Log
+	Error           string
+}
+
+// Log es un evento emitido por un contrato
+type Log struct {
+	Address     string
+	Topics      []string
+	Data        []byte
+	BlockNumber uint64
+	TxHash      string
+}
+
+// QueryRequest es una query por el mesh
+type QueryRequest struct {
+	Type      string
+	Path      string
+	RequestID string
+	From      string
+	To        string
+}
+
+// QueryResponse es la respuesta a una query por el mesh
+type QueryResponse struct {
+	Type        string
+	RequestID   string
+	Path        string
+	Data        []byte
+	Error       string
+	From        string
+	Block       *Block
+	Transaction *Transaction
+}
+
+// Marshal codifica la transacción en protobuf
+func (m *Transaction) Marshal() []byte {
+	b := make([]byte, 0, len(m.Data)+len(m.Signature)+256)
+	b = appendString(b, txHash, m.Hash)
+	b = appendString(b, txFrom, m.From)
+	b = appendString(b, txTo, m.To)
+	b = appendString(b, txValue, m.Value)
+	b = appendBytes(b, txData, m.Data)
+	b = appendUint(b, txGasLimit, m.GasLimit)
+	b = appendString(b, txGasPrice, m.GasPrice)
+	b = appendUint(b, txNonce, m.Nonce)
+	b = appendBytes(b, txSignature, m.Signature)
+	b = appendUint(b, txTimestamp, uint64(m.Timestamp))
+	for _, tuple := range m.AccessList {
+		b = appendMessage(b, txAccessList, tuple.Marshal())
+	}
+	if m.Multisig != nil {
+		b = appendMessage(b, txMultisig, m.Multisig.Marshal())
+	}
+	if m.FeePayer != nil {
+		b = appendMessage(b, txFeePayer, m.FeePayer.Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodifica una transacción en protobuf
+func (m *Transaction) Unmarshal(b []byte) error {
+	*m = Transaction{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case txHash:
+			m.Hash = f.str()
+		case txFrom:
+			m.From = f.str()
+		case txTo:
+			m.To = f.str()
+		case txValue:
+			m.Value = f.str()
+		case txData:
+			m.Data = f.bytesCopy()
+		case txGasLimit:
+			m.GasLimit = f.uint()
+		case txGasPrice:
+			m.GasPrice = f.str()
+		case txNonce:
+			m.Nonce = f.uint()
+		case txSignature:
+			m.Signature = f.bytesCopy()
+		case txTimestamp:
+			m.Timestamp = int64(f.uint())
+		case txAccessList:
+			tuple := &AccessTuple{}
+			if err := f.message(tuple); err != nil {
+				return err
+			}
+			m.AccessList = append(m.AccessList, tuple)
+		case txMultisig:
+			m.Multisig = &MultisigAuth{}
+			return f.message(m.Multisig)
+		case txFeePayer:
+			m.FeePayer = &FeePayer{}
+			return f.message(m.FeePayer)
+		}
+		return nil
+	})
+}
+
+// Marshal codifica la entrada de access list en protobuf
+func (m *AccessTuple) Marshal() []byte {
+	b := appendString(nil, accessAddress, m.Address)
+	return appendStrings(b, accessStorageKeys, m.StorageKeys)
+}
+
+// Unmarshal decodifica una entrada de access list en protobuf
+func (m *AccessTuple) Unmarshal(b []byte) error {
+	*m = AccessTuple{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case accessAddress:
+			m.Address = f.str()
+		case accessStorageKeys:
+			m.StorageKeys = append(m.StorageKeys, f.str())
+		}
+		return nil
+	})
+}
+
+// Marshal codifica la autorización multisig en protobuf
+func (m *MultisigAuth) Marshal() []byte {
+	b := appendUint(nil, multisigThreshold, m.Threshold)
+	b = appendStrings(b, multisigOwners, m.Owners)
+	for _, sig := range m.Signatures {
+		b = appendMessage(b, multisigSignatures, sig.Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodifica una autorización multisig en protobuf
+func (m *MultisigAuth) Unmarshal(b []byte) error {
+	*m = MultisigAuth{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case multisigThreshold:
+			m.Threshold = f.uint()
+		case multisigOwners:
+			m.Owners = append(m.Owners, f.str())
+		case multisigSignatures:
+			sig := &MultisigSignature{}
+			if err := f.message(sig); err != nil {
+				return err
+			}
+			m.Signatures = append(m.Signatures, sig)
+		}
+		return nil
+	})
+}
+
+// Marshal codifica la firma de un owner en protobuf
+func (m *MultisigSignature) Marshal() []byte {
+	b := appendString(nil, multisigSigSigner, m.Signer)
+	return appendBytes(b, multisigSigSignature, m.Signature)
+}
+
+// Unmarshal decodifica la firma de un owner en protobuf
+func (m *MultisigSignature) Unmarshal(b []byte) error {
+	*m = MultisigSignature{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case multisigSigSigner:
+			m.Signer = f.str()
+		case multisigSigSignature:
+			m.Signature = f.bytesCopy()
+		}
+		return nil
+	})
+}
+
+// Marshal codifica el fee payer en protobuf
+func (m *FeePayer) Marshal() []byte {
+	b := appendString(nil, feePayerAddress, m.Address)
+	return appendBytes(b, feePayerSignature, m.Signature)
+}
+
+// Unmarshal decodifica el fee payer en protobuf
+func (m *FeePayer) Unmarshal(b []byte) error {
+	*m = FeePayer{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case feePayerAddress:
+			m.Address = f.str()
+		case feePayerSignature:
+			m.Signature = f.bytesCopy()
+		}
+		return nil
+	})
+}
+
+// Marshal codifica el header en protobuf
+func (m *BlockHeader) Marshal() []byte {
+	b := make([]byte, 0, 512)
+	b = appendUint(b, headerHeight, m.Height)
+	b = appendString(b, headerHash, m.Hash)
+	b = appendString(b, headerParentHash, m.ParentHash)
+	b = appendUint(b, headerTimestamp, uint64(m.Timestamp))
+	b = appendUint(b, headerTimestampNanos, uint64(m.TimestampNanos))
+	b = appendString(b, headerValidator, m.Validator)
+	b = appendString(b, headerChainID, m.ChainID)
+	b = appendString(b, headerStateRoot, m.StateRoot)
+	b = appendString(b, headerTransactionsRoot, m.TransactionsRoot)
+	b = appendString(b, headerReceiptsRoot, m.ReceiptsRoot)
+	b = appendUint(b, headerGasUsed, m.GasUsed)
+	b = appendUint(b, headerGasLimit, m.GasLimit)
+	return b
+}
+
+// Unmarshal decodifica un header en protobuf
+func (m *BlockHeader) Unmarshal(b []byte) error {
+	*m = BlockHeader{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case headerHeight:
+			m.Height = f.uint()
+		case headerHash:
+			m.Hash = f.str()
+		case headerParentHash:
+			m.ParentHash = f.str()
+		case headerTimestamp:
+			m.Timestamp = int64(f.uint())
+		case headerTimestampNanos:
+			m.TimestampNanos = int32(f.uint())
+		case headerValidator:
+			m.Validator = f.str()
+		case headerChainID:
+			m.ChainID = f.str()
+		case headerStateRoot:
+			m.StateRoot = f.str()
+		case headerTransactionsRoot:
+			m.TransactionsRoot = f.str()
+		case headerReceiptsRoot:
+			m.ReceiptsRoot = f.str()
+		case headerGasUsed:
+			m.GasUsed = f.uint()
+		case headerGasLimit:
+			m.GasLimit = f.uint()
+		}
+		return nil
+	})
+}
+
+// Marshal codifica el bloque en protobuf
+func (m *Block) Marshal() []byte {
+	var b []byte
+	if m.Header != nil {
+		b = appendMessage(b, blockHeader, m.Header.Marshal())
+	}
+	for _, tx := range m.Transactions {
+		b = appendMessage(b, blockTransactions, tx.Marshal())
+	}
+	for _, receipt := range m.Receipts {
+		b = appendMessage(b, blockReceipts, receipt.Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodifica un bloque en protobuf
+func (m *Block) Unmarshal(b []byte) error {
+	*m = Block{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case blockHeader:
+			m.Header = &BlockHeader{}
+			return f.message(m.Header)
+		case blockTransactions:
+			tx := &Transaction{}
+			if err := f.message(tx); err != nil {
+				return err
+			}
+			m.Transactions = append(m.Transactions, tx)
+		case blockReceipts:
+			receipt := &TransactionReceipt{}
+			if err := f.message(receipt); err != nil {
+				return err
+			}
+			m.Receipts = append(m.Receipts, receipt)
+		}
+		return nil
+	})
+}
+
+// Marshal codifica el receipt en protobuf
+func (m *TransactionReceipt) Marshal() []byte {
+	var b []byte
+	b = appendString(b, receiptTransactionHash, m.TransactionHash)
+	b = appendString(b, receiptBlockHash, m.BlockHash)
+	b = appendUint(b, receiptBlockNumber, m.BlockNumber)
+	b = appendUint(b, receiptGasUsed, m.GasUsed)
+	b = appendString(b, receiptStatus, m.Status)
+	for _, log := range m.Logs {
+		b = appendMessage(b, receiptLogs, log.Marshal())
+	}
+	b = appendString(b, receiptError, m.Error)
+	return b
+}
+
+// Unmarshal decodifica un receipt en protobuf
+func (m *TransactionReceipt) Unmarshal(b []byte) error {
+	*m = TransactionReceipt{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case receiptTransactionHash:
+			m.TransactionHash = f.str()
+		case receiptBlockHash:
+			m.BlockHash = f.str()
+		case receiptBlockNumber:
+			m.BlockNumber = f.uint()
+		case receiptGasUsed:
+			m.GasUsed = f.uint()
+		case receiptStatus:
+			m.Status = f.str()
+		case receiptLogs:
+			log := &Log{}
+			if err := f.message(log); err != nil {
+				return err
+			}
+			m.Logs = append(m.Logs, log)
+		case receiptError:
+			m.Error = f.str()
+		}
+		return nil
+	})
+}
+
+// Marshal codifica el log en protobuf
+func (m *Log) Marshal() []byte {
+	var b []byte
+	b = appendString(b, logAddress, m.Address)
+	b = appendStrings(b, logTopics, m.Topics)
+	b = appendBytes(b, logData, m.Data)
+	b = appendUint(b, logBlockNumber, m.BlockNumber)
+	b = appendString(b, logTxHash, m.TxHash)
+	return b
+}
+
+// Unmarshal decodifica un log en protobuf
+func (m *Log) Unmarshal(b []byte) error {
+	*m = Log{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case logAddress:
+			m.Address = f.str()
+		case logTopics:
+			m.Topics = append(m.Topics, f.str())
+		case logData:
+			m.Data = f.bytesCopy()
+		case logBlockNumber:
+			m.BlockNumber = f.uint()
+		case logTxHash:
+			m.TxHash = f.str()
+		}
+		return nil
+	})
+}
+
+// Marshal codifica la query en protobuf
+func (m *QueryRequest) Marshal() []byte {
+	var b []byte
+	b = appendString(b, queryType, m.Type)
+	b = appendString(b, queryPath, m.Path)
+	b = appendString(b, queryRequestID, m.RequestID)
+	b = appendString(b, queryFrom, m.From)
+	b = appendString(b, queryTo, m.To)
+	return b
+}
+
+// Unmarshal decodifica una query en protobuf
+func (m *QueryRequest) Unmarshal(b []byte) error {
+	*m = QueryRequest{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case queryType:
+			m.Type = f.str()
+		case queryPath:
+			m.Path = f.str()
+		case queryRequestID:
+			m.RequestID = f.str()
+		case queryFrom:
+			m.From = f.str()
+		case queryTo:
+			m.To = f.str()
+		}
+		return nil
+	})
+}
+
+// Marshal codifica la respuesta en protobuf
+func (m *QueryResponse) Marshal() []byte {
+	b := make([]byte, 0, len(m.Data)+128)
+	b = appendString(b, responseType, m.Type)
+	b = appendString(b, responseRequestID, m.RequestID)
+	b = appendString(b, responsePath, m.Path)
+	b = appendBytes(b, responseData, m.Data)
+	b = appendString(b, responseError, m.Error)
+	b = appendString(b, responseFrom, m.From)
+	if m.Block != nil {
+		b = appendMessage(b, responseBlock, m.Block.Marshal())
+	}
+	if m.Transaction != nil {
+		b = appendMessage(b, responseTransaction, m.Transaction.Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodifica una respuesta en protobuf
+func (m *QueryResponse) Unmarshal(b []byte) error {
+	*m = QueryResponse{}
+	return forEachField(b, func(f field) error {
+		switch f.num {
+		case responseType:
+			m.Type = f.str()
+		case responseRequestID:
+			m.RequestID = f.str()
+		case responsePath:
+			m.Path = f.str()
+		case responseData:
+			m.Data = f.bytesCopy()
+		case responseError:
+			m.Error = f.str()
+		case responseFrom:
+			m.From = f.str()
+		case responseBlock:
+			m.Block = &Block{}
+			return f.message(m.Block)
+		case responseTransaction:
+			m.Transaction = &Transaction{}
+			return f.message(m.Transaction)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,149 @@
+package wire
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// newTestBlock crea un bloque con todos los campos completos
+func newTestBlock() *Block {
+	return &Block{
+		Header: &BlockHeader{
+			Height: 7, Hash: "0x07", ParentHash: "0x06", Timestamp: 1700000000, TimestampNanos: 500,
+			Validator: "ABCD", ChainID: "oxy-test", StateRoot: "0xaa", TransactionsRoot: "0xbb", ReceiptsRoot: "0xcc",
+			GasUsed: 74000, GasLimit: 30000000,
+		},
+		Transactions: []*Transaction{
+			{Hash: "0x01", From: "0x12", To: "0x34", Value: "5", GasLimit: 21000, GasPrice: "1", Signature: []byte{9}, Timestamp: 1700000000},
+			{Hash: "0x02", From: "0x12", Data: []byte{0x60, 0x00}, Value: "0", GasLimit: 100000, GasPrice: "1", Nonce: 1, Timestamp: -1,
+				AccessList: []*AccessTuple{{Address: "0xabc", StorageKeys: []string{"0x00", ""}}, {Address: "0xdef"}}},
+			{Hash: "0x03", From: "0x56", Value: "1", GasLimit: 21000, GasPrice: "1", Nonce: 2,
+				Multisig: &MultisigAuth{Threshold: 2, Owners: []string{"0x12", "0x34", "0x78"},
+					Signatures: []*MultisigSignature{{Signer: "0x12", Signature: []byte{1}}, {Signer: "0x78", Signature: []byte{2}}}}},
+			{Hash: "0x04", From: "0x12", To: "0x34", Value: "0", GasLimit: 21000, GasPrice: "1", Nonce: 3, Signature: []byte{3},
+				FeePayer: &FeePayer{Address: "0x9a", Signature: []byte{4}}},
+		},
+		Receipts: []*TransactionReceipt{
+			{TransactionHash: "0x01", BlockHash: "0x07", BlockNumber: 7, GasUsed: 21000, Status: "success"},
+			{TransactionHash: "0x02", BlockHash: "0x07", BlockNumber: 7, GasUsed: 53000, Status: "failed", Error: "revert",
+				Logs: []*Log{{Address: "0xdef", Topics: []string{"0xaa", "0xbb"}, Data: []byte{1}, BlockNumber: 7, TxHash: "0x02"}}},
+		},
+	}
+}
+
+// TestBlockRoundTrip verifica que un bloque y una respuesta de query se codifican y decodifican sin pérdidas
+func TestBlockRoundTrip(t *testing.T) {
+	block := newTestBlock()
+	var decoded Block
+	if err := decoded.Unmarshal(block.Marshal()); err != nil {
+		t.Fatalf("Error decodificando bloque: %v", err)
+	}
+	if !reflect.DeepEqual(block, &decoded) {
+		t.Errorf("Bloque decodificado distinto:\n%+v\n%+v", block, &decoded)
+	}
+
+	response := &QueryResponse{Type: "response", RequestID: "req-1", Path: "block/7", From: "peer", Block: block}
+	var decodedResponse QueryResponse
+	if err := decodedResponse.Unmarshal(response.Marshal()); err != nil {
+		t.Fatalf("Error decodificando respuesta: %v", err)
+	}
+	if !reflect.DeepEqual(response, &decodedResponse) {
+		t.Errorf("Respuesta decodificada distinta: %+v", &decodedResponse)
+	}
+
+	request := &QueryRequest{Type: "query", Path: "tx/0x01", RequestID: "req-2", From: "peer", To: "node"}
+	var decodedRequest QueryRequest
+	if err := decodedRequest.Unmarshal(request.Marshal()); err != nil || *request != decodedRequest {
+		t.Errorf("Query decodificada distinta: %+v (%v)", decodedRequest, err)
+	}
+
+	// Campos desconocidos (de versiones futuras del esquema) se ignoran
+	extended := protowire.AppendTag(block.Transactions[0].Marshal(), 99, protowire.Fixed64Type)
+	extended = protowire.AppendFixed64(extended, 42)
+	var tx Transaction
+	if err := tx.Unmarshal(extended); err != nil || !reflect.DeepEqual(&tx, block.Transactions[0]) {
+		t.Errorf("Campos desconocidos no deberían fallar: %+v (%v)", tx, err)
+	}
+
+	if err := decoded.Unmarshal(block.Marshal()[:20]); err == nil {
+		t.Error("Mensaje truncado debería fallar")
+	}
+}
+
+// TestTypesProtoSchema verifica que el codec use los mensajes y números de campo de types.proto
+func TestTypesProtoSchema(t *testing.T) {
+	schema, err := os.ReadFile("types.proto")
+	if err != nil {
+		t.Fatalf("Error leyendo esquema: %v", err)
+	}
+
+	expected := map[string]map[string]protowire.Number{
+		"Transaction": {
+			"hash": txHash, "from": txFrom, "to": txTo, "value": txValue, "data": txData, "gas_limit": txGasLimit,
+			"gas_price": txGasPrice, "nonce": txNonce, "signature": txSignature, "timestamp": txTimestamp,
+			"access_list": txAccessList, "multisig": txMultisig, "fee_payer": txFeePayer,
+		},
+		"AccessTuple":       {"address": accessAddress, "storage_keys": accessStorageKeys},
+		"MultisigAuth":      {"threshold": multisigThreshold, "owners": multisigOwners, "signatures": multisigSignatures},
+		"MultisigSignature": {"signer": multisigSigSigner, "signature": multisigSigSignature},
+		"FeePayer":          {"address": feePayerAddress, "signature": feePayerSignature},
+		"BlockHeader": {
+			"height": headerHeight, "hash": headerHash, "parent_hash": headerParentHash, "timestamp": headerTimestamp,
+			"timestamp_nanos": headerTimestampNanos, "validator": headerValidator, "chain_id": headerChainID,
+			"state_root": headerStateRoot, "transactions_root": headerTransactionsRoot,
+			"receipts_root": headerReceiptsRoot, "gas_used": headerGasUsed, "gas_limit": headerGasLimit,
+		},
+		"Block": {"header": blockHeader, "transactions": blockTransactions, "receipts": blockReceipts},
+		"TransactionReceipt": {
+			"transaction_hash": receiptTransactionHash, "block_hash": receiptBlockHash,
+			"block_number": receiptBlockNumber, "gas_used": receiptGasUsed, "status": receiptStatus,
+			"logs": receiptLogs, "error": receiptError,
+		},
+		"Log": {
+			"address": logAddress, "topics": logTopics, "data": logData, "block_number": logBlockNumber,
+			"tx_hash": logTxHash,
+		},
+		"QueryRequest": {
+			"type": queryType, "path": queryPath, "request_id": queryRequestID, "from": queryFrom, "to": queryTo,
+		},
+		"QueryResponse": {
+			"type": responseType, "request_id": responseRequestID, "path": responsePath, "data": responseData,
+			"error": responseError, "from": responseFrom, "block": responseBlock, "transaction": responseTransaction,
+		},
+	}
+
+	messageRe := regexp.MustCompile(`(?s)message\s+(\w+)\s*\{(.*?)\}`)
+	fieldRe := regexp.MustCompile(`(?m)^\s*(?:repeated\s+)?\w+\s+(\w+)\s*=\s*(\d+);`)
+	messages := messageRe.FindAllStringSubmatch(string(schema), -1)
+	if len(messages) != len(expected) {
+		t.Fatalf("El esquema define %d mensajes, el codec %d", len(messages), len(expected))
+	}
+
+	for _, message := range messages {
+		fields, ok := expected[message[1]]
+		if !ok {
+			t.Errorf("Mensaje %s del esquema no está en el codec", message[1])
+			continue
+		}
+		matches := fieldRe.FindAllStringSubmatch(message[2], -1)
+		if len(matches) != len(fields) {
+			t.Errorf("%s: el esquema define %d campos, el codec %d", message[1], len(matches), len(fields))
+		}
+		for _, m := range matches {
+			number, _ := strconv.Atoi(m[2])
+			codecNumber, ok := fields[m[1]]
+			if !ok {
+				t.Errorf("Campo %s.%s del esquema no está en el codec", message[1], m[1])
+				continue
+			}
+			if protowire.Number(number) != codecNumber {
+				t.Errorf("Campo %s.%s: esquema %d, codec %d", message[1], m[1], number, codecNumber)
+			}
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetLatestBlock retorna el último bloque del nodo
+func (c *Client) GetLatestBlock(ctx context.Context) (*Block, error) {
+	return c.getBlock(ctx, "latest")
+}
+
+// GetBlock retorna el bloque de una altura
+// Si el nodo todavía no lo tiene lo pide a la mesh: en ese caso Finalized es false
+func (c *Client) GetBlock(ctx context.Context, height uint64) (*Block, error) {
+	return c.getBlock(ctx, strconv.FormatUint(height, 10))
+}
+
+// GetBlockByHash retorna el bloque con el hash indicado
+func (c *Client) GetBlockByHash(ctx context.Context, hash string) (*Block, error) {
+	return c.getBlock(ctx, "hash/"+url.PathEscape(hash))
+}
+
+// getBlock consulta /api/v1/blocks/{ref}
+func (c *Client) getBlock(ctx context.Context, ref string) (*Block, error) {
+	var block Block
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/blocks/" + ref, retryable: true}, &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// GetTransaction retorna una transacción incluida en un bloque (ErrNotFound si todavía no se incluyó)
+func (c *Client) GetTransaction(ctx context.Context, hash string) (*Transaction, error) {
+	var tx Transaction
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/transactions/" + url.PathEscape(hash), retryable: true}, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// GetTransactionByBlockAndIndex retorna la transacción en la posición index del bloque height
+// (ErrNotFound si el bloque no existe o tiene menos transacciones)
+func (c *Client) GetTransactionByBlockAndIndex(ctx context.Context, height, index uint64) (*BlockTransaction, error) {
+	var tx BlockTransaction
+	path := "/api/v1/blocks/" + strconv.FormatUint(height, 10) + "/transactions/" + strconv.FormatUint(index, 10)
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, retryable: true}, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// GetAccount retorna el estado de una cuenta tras el último bloque confirmado
+func (c *Client) GetAccount(ctx context.Context, address string) (*Account, error) {
+	return c.getAccount(ctx, "/api/v1/accounts/"+url.PathEscape(address))
+}
+
+// GetAccountAtHeight retorna el estado de una cuenta tras el bloque height (ErrNotFound si fue podado)
+func (c *Client) GetAccountAtHeight(ctx context.Context, address string, height uint64) (*Account, error) {
+	return c.getAccount(ctx, "/api/v1/accounts/"+url.PathEscape(address)+"?height="+strconv.FormatUint(height, 10))
+}
+
+// getAccount consulta el estado de una cuenta
+func (c *Client) getAccount(ctx context.Context, path string) (*Account, error) {
+	var account Account
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, retryable: true}, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetCode retorna el code de una cuenta tras el bloque height (0 = último bloque confirmado)
+func (c *Client) GetCode(ctx context.Context, address string, height uint64) ([]byte, error) {
+	var resp struct {
+		Code string `json:"code"`
+	}
+	path := "/api/v1/accounts/" + url.PathEscape(address) + "/code" + heightQuery(height)
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, retryable: true}, &resp); err != nil {
+		return nil, err
+	}
+	code, err := hex.DecodeString(strings.TrimPrefix(resp.Code, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("code inválido en la respuesta: %w", err)
+	}
+	return code, nil
+}
+
+// GetStorageAt retorna un slot de storage (hex de 32 bytes) de una cuenta tras el bloque height
+// (0 = último bloque confirmado)
+func (c *Client) GetStorageAt(ctx context.Context, address, slot string, height uint64) (string, error) {
+	var resp struct {
+		Value string `json:"value"`
+	}
+	path := "/api/v1/accounts/" + url.PathEscape(address) + "/storage/" + url.PathEscape(slot) + heightQuery(height)
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, retryable: true}, &resp); err != nil {
+		return "", err
+	}
+	return resp.Value, nil
+}
+
+// heightQuery retorna el parámetro ?height= de una consulta de estado (vacío para el último bloque)
+func heightQuery(height uint64) string {
+	if height == 0 {
+		return ""
+	}
+	return "?height=" + strconv.FormatUint(height, 10)
+}
+
+// GetBalance retorna el balance de una cuenta en wei
+func (c *Client) GetBalance(ctx context.Context, address string) (*big.Int, error) {
+	account, err := c.GetAccount(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if account.Balance == "" {
+		return new(big.Int), nil
+	}
+	balance, ok := new(big.Int).SetString(account.Balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("balance inválido en la respuesta: %q", account.Balance)
+	}
+	return balance, nil
+}
+
+// GetMempoolSize retorna la cantidad de transacciones en el mempool del nodo (de /metrics)
+func (c *Client) GetMempoolSize(ctx context.Context) (int, error) {
+	var metrics struct {
+		MempoolSize int `json:"MempoolSize"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/metrics", retryable: true}, &metrics); err != nil {
+		return 0, err
+	}
+	return metrics.MempoolSize, nil
+}
+
+// SubmitTransaction envía una transacción firmada en el modo indicado (ModeAsync, ModeSync o ModeCommit; vacío
+// = async). Los reintentos usan la misma Idempotency-Key: el nodo no envía la transacción dos veces
+// En modo commit, una transacción incluida cuya ejecución falló retorna el resultado y un *ExecutionError
+func (c *Client) SubmitTransaction(ctx context.Context, tx *Transaction, mode string) (*SubmitResult, error) {
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+	path := "/api/v1/submit-tx"
+	if mode != "" {
+		path += "?mode=" + url.QueryEscape(mode)
+	}
+
+	var result SubmitResult
+	req := request{
+		method:    http.MethodPost,
+		path:      path,
+		body:      tx,
+		header:    http.Header{"Idempotency-Key": []string{key}},
+		retryable: true,
+	}
+	if err := c.do(ctx, req, &result); err != nil {
+		return nil, err
+	}
+	if result.TxResult != nil && !result.Success {
+		return &result, &ExecutionError{Hash: result.Hash, Result: result.TxResult}
+	}
+	return &result, nil
+}
+
+// WaitForTransaction consulta la transacción cada pollInterval (<= 0 = DefaultPollInterval) hasta que se
+// incluya en un bloque o se cancele ctx. Para acotar la espera usar un ctx con deadline
+func (c *Client) WaitForTransaction(ctx context.Context, hash string, pollInterval time.Duration) (*Transaction, error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		tx, err := c.GetTransaction(ctx, hash)
+		if err == nil {
+			return tx, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("esperando la transacción %s: %w", hash, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// SearchTransactions busca transacciones por atributos de eventos con la sintaxis de CometBFT
+// (ej: "transfer.sender='0x...' AND tx.height>10"). Retorna el resultado de tx_search tal cual (txs y
+// total_count); page y perPage <= 0 y orderBy vacío usan los valores por defecto del nodo
+func (c *Client) SearchTransactions(ctx context.Context, query string, page, perPage int, orderBy string) (json.RawMessage, error) {
+	params := url.Values{"query": []string{query}}
+	if page > 0 {
+		params.Set("page", strconv.Itoa(page))
+	}
+	if perPage > 0 {
+		params.Set("per_page", strconv.Itoa(perPage))
+	}
+	if orderBy != "" {
+		params.Set("order_by", orderBy)
+	}
+
+	var result json.RawMessage
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/search/transactions?" + params.Encode(), retryable: true}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateSubscription registra una suscripción del watchlist (requiere SetAdminToken)
+// Sin CallbackURL las notificaciones solo se reciben con StreamNotifications
+func (c *Client) CreateSubscription(ctx context.Context, sub Subscription) (*Subscription, error) {
+	var created Subscription
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/watchlist", body: sub}, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// DeleteSubscription elimina una suscripción del watchlist (requiere SetAdminToken)
+func (c *Client) DeleteSubscription(ctx context.Context, id string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/api/v1/watchlist/" + url.PathEscape(id), retryable: true}, nil)
+}
+
+// GetMultisigAddress retorna la dirección de la cuenta multisig de owners con el umbral indicado
+func (c *Client) GetMultisigAddress(ctx context.Context, owners []string, threshold uint64) (*MultisigAccount, error) {
+	body := map[string]interface{}{"owners": owners, "threshold": threshold}
+	var account MultisigAccount
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/multisig", body: body, retryable: true}, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ProposeMultisigTransaction registra en el nodo una transacción multisig para recolectar las firmas de los
+// owners; tx lleva Multisig con owners y umbral, y Hash y From se completan si están vacíos
+func (c *Client) ProposeMultisigTransaction(ctx context.Context, tx *Transaction) (*MultisigProposal, error) {
+	var proposal MultisigProposal
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/multisig/proposals", body: tx, retryable: true}, &proposal); err != nil {
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+// GetMultisigProposal obtiene una propuesta multisig pendiente (ErrNotFound si no existe o ya se confirmó)
+func (c *Client) GetMultisigProposal(ctx context.Context, hash string) (*MultisigProposal, error) {
+	var proposal MultisigProposal
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/multisig/proposals/" + url.PathEscape(hash), retryable: true}, &proposal); err != nil {
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+// AddMultisigSignature agrega la firma de un owner a una propuesta multisig y retorna la propuesta actualizada
+func (c *Client) AddMultisigSignature(ctx context.Context, hash string, sig MultisigSignature) (*MultisigProposal, error) {
+	var proposal MultisigProposal
+	path := "/api/v1/multisig/proposals/" + url.PathEscape(hash) + "/signatures"
+	if err := c.do(ctx, request{method: http.MethodPost, path: path, body: sig, retryable: true}, &proposal); err != nil {
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+// newIdempotencyKey genera una clave aleatoria para la cabecera Idempotency-Key
+func newIdempotencyKey() (string, error) {
+	var key [16]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return "", fmt.Errorf("error generando idempotency key: %w", err)
+	}
+	return hex.EncodeToString(key[:]), nil
+}
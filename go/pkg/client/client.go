@@ -0,0 +1,176 @@
+// Package client es la librería Go para integrarse con un nodo de oxy-blockchain por su API REST:
+// enviar transacciones y esperar su inclusión, consultar bloques, transacciones y cuentas, y recibir las
+// notificaciones del watchlist por WebSocket. Las peticiones se reintentan con backoff exponencial ante
+// fallas de red, rate limit (respetando Retry-After) y nodos no disponibles, y los errores del nodo se
+// retornan como *APIError, comparables con errors.Is contra ErrNotFound, ErrRateLimited, etc.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Valores por defecto del cliente
+const (
+	DefaultTimeout        = 30 * time.Second       // Timeout de cada intento HTTP
+	DefaultMaxRetries     = 3                      // Reintentos después del primer intento
+	DefaultInitialBackoff = 200 * time.Millisecond // Espera antes del primer reintento (se duplica en cada uno)
+	DefaultMaxBackoff     = 5 * time.Second
+	DefaultPollInterval   = time.Second // Intervalo de consulta de WaitForTransaction
+)
+
+// maxErrorBody limita lo que se lee del cuerpo de una respuesta de error
+const maxErrorBody = 64 * 1024
+
+// Client es un cliente de la API REST de un nodo. Es seguro para uso concurrente una vez configurado
+// (los métodos Set deben llamarse antes de usarlo)
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	adminToken string // Bearer token de la API de administración (watchlist)
+
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// NewClient crea un cliente para el nodo en baseURL (ej: "http://localhost:8080")
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		httpClient:     &http.Client{Timeout: DefaultTimeout},
+		maxRetries:     DefaultMaxRetries,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+	}
+}
+
+// SetHTTPClient establece el cliente HTTP (transporte, TLS, timeout por intento)
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	if httpClient != nil {
+		c.httpClient = httpClient
+	}
+}
+
+// SetAdminToken establece el token de la API de administración (OXY_ADMIN_TOKEN del nodo), requerido por
+// las suscripciones del watchlist
+func (c *Client) SetAdminToken(token string) {
+	c.adminToken = token
+}
+
+// SetRetryPolicy configura los reintentos: maxRetries reintentos (0 deshabilita) con backoff exponencial
+// entre initialBackoff y maxBackoff (valores <= 0 = por defecto)
+func (c *Client) SetRetryPolicy(maxRetries int, initialBackoff, maxBackoff time.Duration) {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if initialBackoff <= 0 {
+		initialBackoff = DefaultInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	if maxBackoff < initialBackoff {
+		maxBackoff = initialBackoff
+	}
+	c.maxRetries = maxRetries
+	c.initialBackoff = initialBackoff
+	c.maxBackoff = maxBackoff
+}
+
+// request describe una petición al API
+type request struct {
+	method    string
+	path      string      // Incluye el query string
+	body      interface{} // Se codifica en JSON (nil = sin body)
+	header    http.Header
+	retryable bool // La petición no tiene efectos o es idempotente (Idempotency-Key)
+}
+
+// do ejecuta una petición con reintentos y decodifica la respuesta JSON en out (si no es nil)
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("error codificando petición: %w", err)
+		}
+	}
+
+	backoff := c.initialBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.attempt(ctx, req, body, out)
+		if err == nil {
+			return nil
+		}
+		if !retry || !req.retryable || attempt >= c.maxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		wait := backoff
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// attempt ejecuta un intento de la petición y retorna si el error admite reintentos: fallas de red y
+// respuestas *APIError reintentables (rate limit, nodo no disponible, timeout)
+func (c *Client) attempt(ctx context.Context, req request, body []byte, out interface{}) (bool, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, reader)
+	if err != nil {
+		return false, fmt.Errorf("error creando petición: %w", err)
+	}
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.adminToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.adminToken)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return true, fmt.Errorf("%s %s: %w", req.method, req.path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		apiErr := newAPIError(resp, data)
+		return apiErr.Retryable(), apiErr
+	}
+	if out == nil {
+		return false, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("error decodificando respuesta de %s: %w", req.path, err)
+	}
+	return false, nil
+}
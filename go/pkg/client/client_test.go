@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestClient crea un cliente contra un servidor de prueba, con reintentos rápidos
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c := NewClient(server.URL + "/")
+	c.SetRetryPolicy(3, time.Millisecond, 5*time.Millisecond)
+	return c
+}
+
+// TestClientQueries prueba la decodificación de bloques, transacciones guardadas (también por posición en el
+// bloque), balances, code, storage y el tamaño del mempool
+func TestClientQueries(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/blocks/7":
+			w.Write([]byte(`{"header":{"Height":7,"Hash":"0x07","Timestamp":"2024-01-02T03:04:05Z"},"Transactions":[{"Hash":"0x01","Data":"YWJj","accessList":[{"address":"0xabc","storageKeys":[]}]}],"Receipts":[{"TransactionHash":"0x01","Status":"success","Logs":[]}],"finalized":true,"commit":{"height":7,"canonical":true}}`))
+		case "/api/v1/blocks/7/transactions/0":
+			w.Write([]byte(`{"Hash":"0x01","From":"0xabc","Nonce":2,"blockHash":"0x07","blockNumber":7,"transactionIndex":0}`))
+		case "/api/v1/accounts/0x1234":
+			w.Write([]byte(`{"Address":"0x1234","Balance":"1000000000000000000000","Nonce":3}`))
+		case "/api/v1/accounts/0x1234/code":
+			if r.URL.Query().Get("height") != "5" {
+				http.Error(w, "Invalid height", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"address":"0x1234","code":"0x6000","height":5}`))
+		case "/api/v1/accounts/0x1234/storage/0x01":
+			w.Write([]byte(`{"address":"0x1234","slot":"0x01","value":"0x07","height":0}`))
+		case "/metrics":
+			w.Write([]byte(`{"BlocksProcessed":12,"MempoolSize":42}`))
+		default:
+			http.Error(w, "Block not found", http.StatusNotFound)
+		}
+	})
+	ctx := context.Background()
+
+	block, err := c.GetBlock(ctx, 7)
+	if err != nil {
+		t.Fatalf("Error obteniendo bloque: %v", err)
+	}
+	if block.Header.Height != 7 || !block.Finalized || block.Commit == nil || !block.Commit.Canonical || block.Header.Timestamp.Year() != 2024 {
+		t.Errorf("Bloque mal decodificado: %+v", block)
+	}
+	if len(block.Transactions) != 1 || string(block.Transactions[0].Data) != "abc" || len(block.Transactions[0].AccessList) != 1 || block.Receipts[0].Status != "success" {
+		t.Errorf("Contenido del bloque mal decodificado: %+v", block.Transactions[0])
+	}
+
+	tx, err := c.GetTransactionByBlockAndIndex(ctx, 7, 0)
+	if err != nil || tx.Hash != "0x01" || tx.Nonce != 2 || tx.BlockHash != "0x07" || tx.BlockNumber != 7 {
+		t.Errorf("Transacción por índice mal decodificada: %+v (%v)", tx, err)
+	}
+	if _, err := c.GetTransactionByBlockAndIndex(ctx, 7, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Se esperaba ErrNotFound: %v", err)
+	}
+
+	balance, err := c.GetBalance(ctx, "0x1234")
+	if err != nil || balance.String() != "1000000000000000000000" {
+		t.Errorf("Balance incorrecto: %v (%v)", balance, err)
+	}
+
+	if code, err := c.GetCode(ctx, "0x1234", 5); err != nil || hex.EncodeToString(code) != "6000" {
+		t.Errorf("Code incorrecto: %x (%v)", code, err)
+	}
+	if value, err := c.GetStorageAt(ctx, "0x1234", "0x01", 0); err != nil || value != "0x07" {
+		t.Errorf("Slot incorrecto: %s (%v)", value, err)
+	}
+
+	if size, err := c.GetMempoolSize(ctx); err != nil || size != 42 {
+		t.Errorf("Tamaño del mempool incorrecto: %d (%v)", size, err)
+	}
+
+	if _, err := c.GetBlock(ctx, 8); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Se esperaba ErrNotFound: %v", err)
+	}
+}
+
+// TestClientRetries prueba los reintentos con la misma Idempotency-Key y los errores tipados sin reintento
+func TestClientRetries(t *testing.T) {
+	var attempts atomic.Int32
+	keys := make(chan string, 10)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get("Idempotency-Key")
+		w.Header().Set("Content-Type", "application/json")
+		switch attempts.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"success":false,"error":{"codespace":"oxy","code":12,"reason":"unavailable","message":"Consensus not available"}}`))
+		case 3:
+			w.Write([]byte(`{"success":true,"hash":"0x01","mode":"commit","height":9,"txResult":{"height":9,"code":0}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"success":false,"error":{"codespace":"oxy","code":5,"reason":"nonce_too_low","message":"Invalid transaction: nonce: too low","fields":[{"field":"nonce","message":"too low"}]}}`))
+		}
+	})
+	ctx := context.Background()
+
+	result, err := c.SubmitTransaction(ctx, &Transaction{Hash: "0x01", From: "0x02"}, ModeCommit)
+	if err != nil || !result.Success || result.Height != 9 || attempts.Load() != 3 {
+		t.Fatalf("Envío con reintentos incorrecto: %+v (%v), %d intentos", result, err, attempts.Load())
+	}
+	first := <-keys
+	if first == "" || <-keys != first || <-keys != first {
+		t.Error("Los reintentos deberían usar la misma Idempotency-Key")
+	}
+
+	_, err = c.SubmitTransaction(ctx, &Transaction{Hash: "0x01", From: "0x02"}, ModeSync)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrInvalidRequest) || apiErr.Reason != "nonce_too_low" || len(apiErr.Fields) != 1 {
+		t.Errorf("Error tipado incorrecto: %#v", err)
+	}
+	if attempts.Load() != 4 {
+		t.Errorf("Un 400 no debería reintentarse: %d intentos", attempts.Load())
+	}
+}
+
+// TestWaitForTransaction prueba que la espera consulte hasta que la transacción se incluya
+func TestWaitForTransaction(t *testing.T) {
+	var polls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if polls.Add(1) < 3 {
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Hash":"0x01","Nonce":4}`))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tx, err := c.WaitForTransaction(ctx, "0x01", time.Millisecond)
+	if err != nil || tx.Hash != "0x01" || tx.Nonce != 4 || polls.Load() != 3 {
+		t.Errorf("Espera incorrecta: %+v (%v), %d consultas", tx, err, polls.Load())
+	}
+}
+
+// TestStreamNotifications prueba la entrega de notificaciones, la reconexión y el cierre de la suscripción
+func TestStreamNotifications(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var connections atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secreto" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v1/watchlist/sub-1/ws" {
+			http.Error(w, "Subscription not found", http.StatusNotFound)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := json.Marshal(Notification{ID: "n" + string(rune('0'+connections.Add(1))), Type: NotificationTransaction, TxHash: "0x01"})
+		conn.WriteMessage(websocket.TextMessage, data)
+		if connections.Load() == 1 {
+			return // Corte de la conexión: el cliente reconecta
+		}
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "subscription removed"))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.StreamNotifications(ctx, "sub-1", func(*Notification) error { return nil }); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Sin token se esperaba ErrUnauthorized: %v", err)
+	}
+
+	c.SetAdminToken("secreto")
+	var received []string
+	err := c.StreamNotifications(ctx, "sub-1", func(n *Notification) error {
+		received = append(received, n.ID)
+		return nil
+	})
+	if !errors.Is(err, ErrSubscriptionClosed) || len(received) != 2 || received[0] != "n1" || received[1] != "n2" {
+		t.Errorf("Stream incorrecto: %v (%v)", received, err)
+	}
+
+	if err := c.StreamNotifications(ctx, "otra", func(*Notification) error { return nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Se esperaba ErrNotFound: %v", err)
+	}
+}
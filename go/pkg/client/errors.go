@@ -0,0 +1,136 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errores por clase de respuesta del nodo; se comparan con errors.Is contra un *APIError
+var (
+	ErrInvalidRequest = errors.New("petición inválida")      // 400
+	ErrUnauthorized   = errors.New("no autorizado")          // 401 y 403 (API de administración)
+	ErrNotFound       = errors.New("recurso no encontrado")  // 404
+	ErrConflict       = errors.New("conflicto")              // 409 (transacción duplicada)
+	ErrRateLimited    = errors.New("rate limit excedido")    // 429
+	ErrUnavailable    = errors.New("nodo no disponible")     // 502 y 503
+	ErrTimeout        = errors.New("timeout del nodo")       // 504
+	ErrServer         = errors.New("error interno del nodo") // Resto de los 5xx
+)
+
+// ErrSubscriptionClosed se retorna al eliminarse la suscripción de un stream de notificaciones
+var ErrSubscriptionClosed = errors.New("suscripción eliminada")
+
+// APIError es una respuesta de error del nodo
+// Las transacciones rechazadas incluyen el código del registro de errores del consenso (Code/Reason)
+type APIError struct {
+	StatusCode int
+	Code       uint32       // Código del consenso (0 si la respuesta no lo incluye)
+	Codespace  string       // "oxy" cuando Code está presente
+	Reason     string       // Razón del código (ej: "nonce_too_low", "insufficient_funds")
+	Message    string       // Mensaje del nodo
+	Fields     []FieldError // Campos inválidos de una transacción que no respeta el esquema
+	RetryAfter time.Duration
+}
+
+// FieldError describe un campo inválido del body de una petición
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implementa la interfaz error
+func (e *APIError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("oxy api: %d %s (%s): %s", e.StatusCode, http.StatusText(e.StatusCode), e.Reason, e.Message)
+	}
+	return fmt.Sprintf("oxy api: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is permite comparar con los errores por clase (errors.Is(err, client.ErrNotFound))
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrInvalidRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusBadGateway || e.StatusCode == http.StatusServiceUnavailable
+	case ErrTimeout:
+		return e.StatusCode == http.StatusGatewayTimeout
+	case ErrServer:
+		return e.StatusCode >= 500 && e.StatusCode != http.StatusBadGateway &&
+			e.StatusCode != http.StatusServiceUnavailable && e.StatusCode != http.StatusGatewayTimeout
+	}
+	return false
+}
+
+// Retryable indica si la petición puede reintentarse: rate limit, nodo no disponible, timeout o una petición
+// con la misma Idempotency-Key todavía en curso (409 sin código del consenso; una transacción duplicada lo trae)
+func (e *APIError) Retryable() bool {
+	if e.StatusCode == http.StatusConflict && e.Code == 0 {
+		return true
+	}
+	return errors.Is(e, ErrRateLimited) || errors.Is(e, ErrUnavailable) || errors.Is(e, ErrTimeout)
+}
+
+// ExecutionError se retorna al enviar en modo commit una transacción que se incluyó en un bloque pero cuya
+// ejecución falló (revert, out of gas, etc.); la transacción consumió gas y su nonce
+type ExecutionError struct {
+	Hash   string
+	Result *TxCommitResult
+}
+
+// Error implementa la interfaz error
+func (e *ExecutionError) Error() string {
+	return fmt.Sprintf("transacción %s falló en el bloque %d: %s (%s)", e.Hash, e.Result.Height, e.Result.Log, e.Result.Info)
+}
+
+// txErrorBody es el cuerpo de error de las transacciones rechazadas ({"success": false, "error": {...}})
+type txErrorBody struct {
+	Error *struct {
+		Codespace string       `json:"codespace"`
+		Code      uint32       `json:"code"`
+		Reason    string       `json:"reason"`
+		Message   string       `json:"message"`
+		Fields    []FieldError `json:"fields"`
+	} `json:"error"`
+}
+
+// newAPIError construye el error de una respuesta no exitosa
+// El resto de los errores del API son texto plano (http.Error)
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	var txErr txErrorBody
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") && json.Unmarshal(body, &txErr) == nil && txErr.Error != nil {
+		apiErr.Code = txErr.Error.Code
+		apiErr.Codespace = txErr.Error.Codespace
+		apiErr.Reason = txErr.Error.Reason
+		apiErr.Message = txErr.Error.Message
+		apiErr.Fields = txErr.Error.Fields
+	}
+	return apiErr
+}
+
+// parseRetryAfter interpreta Retry-After en segundos (0 si falta o no es un número)
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
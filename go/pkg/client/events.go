@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// StreamNotifications recibe por WebSocket las notificaciones de una suscripción del watchlist (requiere
+// SetAdminToken) y llama a handler con cada una, hasta que se cancele ctx, handler retorne un error o la
+// suscripción se elimine (ErrSubscriptionClosed). Si la conexión se corta se reconecta con el backoff de
+// SetRetryPolicy; las notificaciones de bloques confirmados durante el corte no se repiten por el stream
+// (usar CallbackURL para entrega garantizada). Notification.ID permite descartar duplicados
+func (c *Client) StreamNotifications(ctx context.Context, subscriptionID string, handler func(*Notification) error) error {
+	wsURL, err := c.websocketURL("/api/v1/watchlist/" + url.PathEscape(subscriptionID) + "/ws")
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	if c.adminToken != "" {
+		header.Set("Authorization", "Bearer "+c.adminToken)
+	}
+
+	backoff := c.initialBackoff
+	failures := 0
+	for {
+		connected, err := c.streamOnce(ctx, wsURL, header, handler)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var apiErr *APIError
+		var handlerErr *notificationHandlerError
+		switch {
+		case errors.Is(err, ErrSubscriptionClosed):
+			return err
+		case errors.As(err, &handlerErr):
+			return handlerErr.err
+		case errors.As(err, &apiErr) && !apiErr.Retryable():
+			return err
+		}
+
+		// La conexión llegó a establecerse: el backoff vuelve a empezar
+		if connected {
+			backoff = c.initialBackoff
+			failures = 0
+		}
+		if failures >= c.maxRetries {
+			return err
+		}
+		failures++
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// notificationHandlerError distingue el error del handler de los errores de la conexión
+type notificationHandlerError struct {
+	err error
+}
+
+// Error implementa la interfaz error
+func (e *notificationHandlerError) Error() string {
+	return e.err.Error()
+}
+
+// streamOnce abre una conexión y entrega sus notificaciones hasta que se cierre
+// connected indica si la conexión llegó a establecerse
+func (c *Client) streamOnce(ctx context.Context, wsURL string, header http.Header, handler func(*Notification) error) (connected bool, err error) {
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		if resp != nil {
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+			return false, newAPIError(resp, body)
+		}
+		return false, fmt.Errorf("error conectando a %s: %w", wsURL, err)
+	}
+	defer conn.Close()
+
+	// Cancelar ctx cierra la conexión y desbloquea la lectura
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	for {
+		var notification Notification
+		if err := conn.ReadJSON(&notification); err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return true, ErrSubscriptionClosed
+			}
+			return true, fmt.Errorf("error leyendo notificación: %w", err)
+		}
+		if err := handler(&notification); err != nil {
+			return true, &notificationHandlerError{err: err}
+		}
+	}
+}
+
+// websocketURL convierte un path del API en una URL ws:// o wss://
+func (c *Client) websocketURL(path string) (string, error) {
+	switch {
+	case strings.HasPrefix(c.baseURL, "https://"):
+		return "wss://" + strings.TrimPrefix(c.baseURL, "https://") + path, nil
+	case strings.HasPrefix(c.baseURL, "http://"):
+		return "ws://" + strings.TrimPrefix(c.baseURL, "http://") + path, nil
+	}
+	return "", fmt.Errorf("URL del nodo sin esquema http(s): %s", c.baseURL)
+}
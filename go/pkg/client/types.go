@@ -0,0 +1,225 @@
+package client
+
+import "time"
+
+// Modos de envío de transacciones (parámetro mode de /api/v1/submit-tx)
+const (
+	ModeAsync  = "async"  // Retorna al agregar la transacción al mempool
+	ModeSync   = "sync"   // Retorna con el resultado de CheckTx
+	ModeCommit = "commit" // Retorna cuando la transacción se incluye en un bloque (o vence el timeout del nodo)
+)
+
+// Transaction es una transacción firmada
+// Los nombres JSON son los del esquema de /api/v1/submit-tx; las transacciones guardadas usan los nombres
+// de los campos de Go, que se decodifican igual
+type Transaction struct {
+	Hash       string        `json:"hash"`
+	From       string        `json:"from"`
+	To         string        `json:"to,omitempty"` // Vacío en la creación de contratos
+	Value      string        `json:"value,omitempty"`
+	Data       []byte        `json:"data,omitempty"`
+	GasLimit   uint64        `json:"gasLimit"`
+	GasPrice   string        `json:"gasPrice,omitempty"`
+	Nonce      uint64        `json:"nonce"`
+	Signature  []byte        `json:"signature"`
+	Timestamp  int64         `json:"timestamp,omitempty"`
+	AccessList []AccessTuple `json:"accessList,omitempty"`
+	Multisig   *MultisigAuth `json:"multisig,omitempty"` // Transacción de una cuenta multisig (sin Signature)
+	FeePayer   *FeePayer     `json:"feePayer,omitempty"` // Transacción patrocinada: otra cuenta paga el gas
+}
+
+// BlockTransaction es una transacción con su posición en el bloque que la incluye
+type BlockTransaction struct {
+	Transaction
+	BlockHash        string `json:"blockHash"`
+	BlockNumber      uint64 `json:"blockNumber"`
+	TransactionIndex uint64 `json:"transactionIndex"`
+}
+
+// AccessTuple es una entrada de access list (EIP-2930)
+type AccessTuple struct {
+	Address     string   `json:"address"`
+	StorageKeys []string `json:"storageKeys"`
+}
+
+// FeePayer es la cuenta que paga el gas de una transacción patrocinada (relayer)
+// Address forma parte del hash que firma el remitente; Signature es la firma del fee payer sobre
+// keccak256("oxy-fee-payer" || hash)
+type FeePayer struct {
+	Address   string `json:"address"`
+	Signature []byte `json:"signature"`
+}
+
+// MultisigAuth autoriza la transacción de una cuenta multisig: From es la dirección derivada de Owners y
+// Threshold, y Signatures tiene las firmas de los owners sobre el hash
+type MultisigAuth struct {
+	Threshold  uint64              `json:"threshold"`
+	Owners     []string            `json:"owners"`
+	Signatures []MultisigSignature `json:"signatures"`
+}
+
+// MultisigSignature es la firma de un owner sobre el hash de una transacción multisig
+type MultisigSignature struct {
+	Signer    string `json:"signer"`
+	Signature []byte `json:"signature"`
+}
+
+// MultisigAccount es una cuenta multisig con sus owners en el orden canónico
+type MultisigAccount struct {
+	Address   string   `json:"address"`
+	Owners    []string `json:"owners"`
+	Threshold uint64   `json:"threshold"`
+}
+
+// MultisigProposal es una transacción multisig que recolecta firmas en el nodo
+type MultisigProposal struct {
+	Hash        string       `json:"hash"`
+	Address     string       `json:"address"`
+	Ready       bool         `json:"ready"` // Reúne el umbral: Transaction se envía con SubmitTransaction
+	Signatures  int          `json:"signatures"`
+	Threshold   uint64       `json:"threshold"`
+	Transaction *Transaction `json:"transaction"`
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+// BlockHeader es el header de un bloque
+type BlockHeader struct {
+	Height           uint64
+	Hash             string
+	ParentHash       string
+	Timestamp        time.Time
+	Validator        string
+	ChainID          string
+	StateRoot        string
+	TransactionsRoot string
+	ReceiptsRoot     string
+	GasUsed          uint64
+	GasLimit         uint64
+}
+
+// Block es un bloque con su estado de finalidad
+type Block struct {
+	Header       BlockHeader    `json:"header"`
+	Transactions []*Transaction `json:"Transactions"`
+	Receipts     []*Receipt     `json:"Receipts"`
+	Finalized    bool           `json:"finalized"`        // Confirmado por CometBFT en el nodo consultado
+	Commit       *CommitSummary `json:"commit,omitempty"` // Firmas del commit (no siempre disponible)
+}
+
+// CommitSummary resume las firmas del commit de un bloque
+type CommitSummary struct {
+	Height            int64  `json:"height"`
+	Round             int32  `json:"round"`
+	BlockID           string `json:"blockId"`
+	Signatures        int    `json:"signatures"`
+	Validators        int    `json:"validators"`
+	SignedVotingPower int64  `json:"signedVotingPower"`
+	TotalVotingPower  int64  `json:"totalVotingPower"`
+	Canonical         bool   `json:"canonical"` // false: commit visto por el nodo para el último bloque, todavía no el definitivo
+}
+
+// Receipt es el recibo de una transacción incluida en un bloque
+type Receipt struct {
+	TransactionHash string
+	BlockHash       string
+	BlockNumber     uint64
+	GasUsed         uint64
+	Status          string // "success" o "failed"
+	Logs            []Log
+	Error           string
+}
+
+// Log es un evento emitido por un contrato
+type Log struct {
+	Address     string
+	Topics      []string
+	Data        []byte
+	BlockNumber uint64
+	TxHash      string
+}
+
+// Account es el estado de una cuenta
+type Account struct {
+	Address  string
+	Balance  string // Wei en decimal
+	Nonce    uint64
+	CodeHash string
+	Storage  map[string]string
+}
+
+// SubmitResult es la respuesta de /api/v1/submit-tx
+type SubmitResult struct {
+	Success  bool            `json:"success"` // En modo commit refleja el resultado de la ejecución
+	Hash     string          `json:"hash"`
+	Mode     string          `json:"mode"`
+	Message  string          `json:"message"`
+	Height   uint64          `json:"height,omitempty"`   // Bloque que incluyó la transacción (modo commit)
+	CheckTx  *CheckTxResult  `json:"checkTx,omitempty"`  // sync y commit
+	TxResult *TxCommitResult `json:"txResult,omitempty"` // commit
+}
+
+// CheckTxResult es el resultado de la validación de la transacción antes de entrar al mempool
+type CheckTxResult struct {
+	Code uint32 `json:"code"`
+	Log  string `json:"log"`
+}
+
+// TxCommitResult es el resultado de ejecución de una transacción incluida en un bloque
+type TxCommitResult struct {
+	Height    uint64 `json:"height"`
+	Code      uint32 `json:"code"`
+	Codespace string `json:"codespace,omitempty"`
+	Log       string `json:"log,omitempty"`
+	Info      string `json:"info,omitempty"` // Razón del error (ej: "execution_failed")
+	GasUsed   int64  `json:"gasUsed"`
+}
+
+// Subscription es una suscripción del watchlist a la actividad de un conjunto de direcciones
+type Subscription struct {
+	ID          string    `json:"id"`
+	Addresses   []string  `json:"addresses"`
+	CallbackURL string    `json:"callbackUrl,omitempty"`
+	Secret      string    `json:"secret,omitempty"` // Solo en la respuesta de CreateSubscription
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Tipos de notificación de una suscripción
+const (
+	NotificationTransaction = "transaction" // La dirección vigilada envía o recibe la transacción
+	NotificationLog         = "log"         // La dirección vigilada emite el log o aparece en sus topics
+	NotificationChainReorg  = "chain_reorg" // La cadena retrocedió: las notificaciones desde Reorg.Height dejan de valer
+)
+
+// Notification es el aviso de que una dirección vigilada aparece en un bloque confirmado
+type Notification struct {
+	ID             string    `json:"id"` // Estable entre reintentos, para deduplicar
+	SubscriptionID string    `json:"subscriptionId"`
+	Type           string    `json:"type"`
+	Address        string    `json:"address"`
+	Height         uint64    `json:"height"`
+	BlockHash      string    `json:"blockHash"`
+	TxHash         string    `json:"txHash"`
+	From           string    `json:"from,omitempty"`
+	To             string    `json:"to,omitempty"`
+	Value          string    `json:"value,omitempty"`
+	LogIndex       int       `json:"logIndex,omitempty"`
+	Log            *WatchLog `json:"log,omitempty"`
+	Reorg          *Reorg    `json:"reorg,omitempty"`
+}
+
+// WatchLog es el log de una notificación de tipo log
+type WatchLog struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    []byte   `json:"data"`
+}
+
+// Reorg describe un retroceso de la cadena (notificación chain_reorg)
+type Reorg struct {
+	Height    uint64 `json:"height"` // Primera altura invalidada
+	OldHeight uint64 `json:"oldHeight"`
+	OldHash   string `json:"oldHash"`
+	NewHeight uint64 `json:"newHeight"`
+	NewHash   string `json:"newHash"`
+	Reason    string `json:"reason"`
+}
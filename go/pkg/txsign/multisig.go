@@ -0,0 +1,73 @@
+package txsign
+
+import (
+	"fmt"
+	"strings"
+
+	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
+	"github.com/Q-YZX0/oxy-blockchain/pkg/client"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MultisigAddress retorna la dirección de la cuenta multisig de owners con el umbral indicado (la misma que
+// POST /api/v1/multisig); no depende del orden de los owners
+func MultisigAddress(owners []string, threshold uint64) (common.Address, error) {
+	return cryptosigner.MultisigAddress(owners, threshold)
+}
+
+// SignMultisig agrega a una transacción multisig la firma de un owner y la retorna
+// tx.Multisig debe tener owners y umbral; From y Hash se completan si están vacíos y, si no, deben
+// corresponder a la multisig y a los campos. La firma no cambia el hash, así que cada owner firma por
+// separado (localmente o con /api/v1/multisig/proposals/{hash}/signatures) y las firmas se juntan después
+func SignMultisig(tx *client.Transaction, signer Signer) (client.MultisigSignature, error) {
+	if tx.Multisig == nil {
+		return client.MultisigSignature{}, fmt.Errorf("la transacción no es multisig: falta multisig (owners y threshold)")
+	}
+	address, err := MultisigAddress(tx.Multisig.Owners, tx.Multisig.Threshold)
+	if err != nil {
+		return client.MultisigSignature{}, err
+	}
+	if tx.From == "" {
+		tx.From = address.Hex()
+	} else if !strings.EqualFold(tx.From, address.Hex()) {
+		return client.MultisigSignature{}, fmt.Errorf("from %s no es la dirección de la multisig (%s)", tx.From, address.Hex())
+	}
+
+	owner := signer.Address()
+	isOwner := false
+	for _, o := range tx.Multisig.Owners {
+		if common.HexToAddress(o) == owner {
+			isOwner = true
+			break
+		}
+	}
+	if !isOwner {
+		return client.MultisigSignature{}, fmt.Errorf("%s no es owner de la multisig", owner.Hex())
+	}
+
+	hash, err := fields(tx).Hash()
+	if err != nil {
+		return client.MultisigSignature{}, err
+	}
+	if tx.Hash != "" && tx.Hash != hash.Hex() {
+		return client.MultisigSignature{}, fmt.Errorf("hash de transacción inválido: esperado %s, tiene %s", hash.Hex(), tx.Hash)
+	}
+	signature, err := signer.SignTransactionHash(hash)
+	if err != nil {
+		return client.MultisigSignature{}, err
+	}
+	if err := cryptosigner.VerifyHashSignature(hash, signature, owner); err != nil {
+		return client.MultisigSignature{}, fmt.Errorf("la firma no corresponde a la transacción: %w", err)
+	}
+
+	tx.Hash = hash.Hex()
+	sig := client.MultisigSignature{Signer: owner.Hex(), Signature: signature}
+	for i, existing := range tx.Multisig.Signatures {
+		if common.HexToAddress(existing.Signer) == owner {
+			tx.Multisig.Signatures[i] = sig
+			return sig, nil
+		}
+	}
+	tx.Multisig.Signatures = append(tx.Multisig.Signatures, sig)
+	return sig, nil
+}
@@ -0,0 +1,52 @@
+package txsign
+
+import (
+	"fmt"
+	"strings"
+
+	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
+	"github.com/Q-YZX0/oxy-blockchain/pkg/client"
+)
+
+// Transacciones patrocinadas: el remitente firma con tx.FeePayer = &client.FeePayer{Address: relayer} (la
+// dirección forma parte del hash) y el relayer agrega su firma con Sponsor antes de enviarla. El nodo cobra
+// el value al remitente y el gas usado al fee payer
+
+// Sponsor agrega la firma del fee payer a una transacción ya firmada por el remitente
+// signer debe ser la cuenta de tx.FeePayer.Address; la firma del remitente se verifica antes de firmar
+func Sponsor(tx *client.Transaction, signer Signer) error {
+	if tx.FeePayer == nil {
+		return fmt.Errorf("la transacción no tiene fee payer: el remitente debe firmarla con feePayer.address")
+	}
+	address := signer.Address()
+	if !strings.EqualFold(tx.FeePayer.Address, address.Hex()) {
+		return fmt.Errorf("la clave corresponde a %s, no al fee payer %s", address.Hex(), tx.FeePayer.Address)
+	}
+	if strings.EqualFold(tx.FeePayer.Address, tx.From) {
+		return fmt.Errorf("el fee payer no puede ser el remitente")
+	}
+
+	hash, err := fields(tx).Hash()
+	if err != nil {
+		return err
+	}
+	if tx.Hash != hash.Hex() {
+		return fmt.Errorf("hash de transacción inválido: esperado %s, tiene %s", hash.Hex(), tx.Hash)
+	}
+	if tx.Multisig == nil {
+		if _, err := Verify(tx); err != nil {
+			return fmt.Errorf("firma del remitente inválida: %w", err)
+		}
+	}
+
+	digest := cryptosigner.FeePayerDigest(hash)
+	signature, err := signer.SignTransactionHash(digest)
+	if err != nil {
+		return err
+	}
+	if err := cryptosigner.VerifyHashSignature(digest, signature, address); err != nil {
+		return fmt.Errorf("la firma no corresponde a la transacción: %w", err)
+	}
+	tx.FeePayer.Signature = signature
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package txsign construye, firma y verifica transacciones de oxy-blockchain sin conectarse a un nodo.
+//
+// El hash de una transacción es keccak256 del JSON (claves en orden alfabético) de sus campos from, to,
+// value, data (base64, null si está vacía), gasLimit, gasPrice, nonce, accessList y la dirección de
+// feePayer (estos dos solo si no están vacíos).
+// La firma es secp256k1 sobre ese hash, de 65 bytes ([R][S][V]), o sobre su digest EIP-712 (TypedData) para
+// las hardware wallets que no firman hashes arbitrarios. Es lo mismo que verifica el nodo en CheckTx, así que
+// una transacción firmada con Sign o SignWith se envía tal cual con client.SubmitTransaction.
+package txsign
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
+	"github.com/Q-YZX0/oxy-blockchain/pkg/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TransferGasLimit es el gas de una transferencia sin data
+const TransferGasLimit = 21000
+
+// NewTransfer crea una transferencia sin firmar de value wei desde from a to
+func NewTransfer(from, to string, value *big.Int, nonce uint64, gasPrice *big.Int) *client.Transaction {
+	return &client.Transaction{
+		From:     from,
+		To:       to,
+		Value:    value.String(),
+		GasLimit: TransferGasLimit,
+		GasPrice: gasPrice.String(),
+		Nonce:    nonce,
+	}
+}
+
+// Hash calcula el hash de una transacción (el valor que el nodo exige en el campo hash)
+// Los campos se hashean tal como están: la transacción debe enviarse sin modificarlos
+func Hash(tx *client.Transaction) (string, error) {
+	hash, err := fields(tx).Hash()
+	if err != nil {
+		return "", err
+	}
+	return hash.Hex(), nil
+}
+
+// Signer firma hashes de transacciones con una clave que no necesita estar en memoria (hardware wallet)
+type Signer interface {
+	// Address retorna la dirección de la clave
+	Address() common.Address
+	// SignTransactionHash retorna la firma de 65 bytes del hash, directa (como crypto.Sign) o la firma
+	// EIP-712 de TypedData(hash); el nodo acepta las dos
+	SignTransactionHash(hash common.Hash) ([]byte, error)
+}
+
+// keySigner firma con una clave privada en memoria
+type keySigner struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+// Address implementa Signer
+func (s *keySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.privateKey.PublicKey)
+}
+
+// SignTransactionHash implementa Signer
+func (s *keySigner) SignTransactionHash(hash common.Hash) ([]byte, error) {
+	signature, err := crypto.Sign(hash.Bytes(), s.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("error firmando transacción: %w", err)
+	}
+	return signature, nil
+}
+
+// NewKeySigner crea un Signer con una clave privada en memoria
+func NewKeySigner(privateKey *ecdsa.PrivateKey) Signer {
+	return &keySigner{privateKey: privateKey}
+}
+
+// Sign firma la transacción con privateKey y completa Hash y Signature
+// Si From está vacío se completa con la dirección de la clave; si no, debe coincidir con ella
+func Sign(tx *client.Transaction, privateKey *ecdsa.PrivateKey) error {
+	return SignWith(tx, NewKeySigner(privateKey))
+}
+
+// SignWith firma la transacción con signer y completa Hash y Signature, igual que Sign
+// La firma se verifica antes de retornar: un dispositivo que firma con otra cuenta se detecta acá
+func SignWith(tx *client.Transaction, signer Signer) error {
+	address := signer.Address()
+	if tx.From == "" {
+		tx.From = address.Hex()
+	} else if !strings.EqualFold(tx.From, address.Hex()) {
+		return fmt.Errorf("la clave corresponde a %s, no al remitente %s", address.Hex(), tx.From)
+	}
+
+	hash, err := fields(tx).Hash()
+	if err != nil {
+		return err
+	}
+	signature, err := signer.SignTransactionHash(hash)
+	if err != nil {
+		return err
+	}
+	if _, err := cryptosigner.VerifyTxFields(fields(tx), hash.Hex(), signature); err != nil {
+		return fmt.Errorf("la firma no corresponde a la transacción: %w", err)
+	}
+
+	tx.Hash = hash.Hex()
+	tx.Signature = signature
+	return nil
+}
+
+// TypedData retorna los 66 bytes que firma una hardware wallet como mensaje EIP-712 para el hash de una
+// transacción (0x19 0x01, separador de dominio y hash del mensaje; accounts.MimetypeTypedData)
+func TypedData(hash common.Hash) []byte {
+	return cryptosigner.TypedTransactionData(hash)
+}
+
+// Verify verifica el hash y la firma de una transacción como el nodo y retorna el remitente
+func Verify(tx *client.Transaction) (common.Address, error) {
+	return cryptosigner.VerifyTxFields(fields(tx), tx.Hash, tx.Signature)
+}
+
+// fields convierte una transacción del cliente en los campos que cubre el hash
+func fields(tx *client.Transaction) *cryptosigner.TxFields {
+	txFields := &cryptosigner.TxFields{
+		From:     tx.From,
+		To:       tx.To,
+		Value:    tx.Value,
+		Data:     tx.Data,
+		GasLimit: tx.GasLimit,
+		GasPrice: tx.GasPrice,
+		Nonce:    tx.Nonce,
+	}
+	for _, tuple := range tx.AccessList {
+		txFields.AccessList = append(txFields.AccessList, cryptosigner.AccessTuple{Address: tuple.Address, StorageKeys: tuple.StorageKeys})
+	}
+	if tx.FeePayer != nil {
+		txFields.FeePayer = tx.FeePayer.Address
+	}
+	return txFields
+}
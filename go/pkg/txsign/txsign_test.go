@@ -0,0 +1,199 @@
+package txsign
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	cryptosigner "github.com/Q-YZX0/oxy-blockchain/internal/crypto"
+	"github.com/Q-YZX0/oxy-blockchain/pkg/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TestSignMatchesNode prueba que una transacción firmada pase la verificación del nodo tras enviarse en JSON
+func TestSignMatchesNode(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Error generando clave: %v", err)
+	}
+
+	tx := NewTransfer("", "0x0000000000000000000000000000000000000001", big.NewInt(1000), 3, big.NewInt(1000000000))
+	tx.AccessList = []client.AccessTuple{{Address: "0x0000000000000000000000000000000000000002", StorageKeys: []string{}}}
+	if err := Sign(tx, key); err != nil {
+		t.Fatalf("Error firmando: %v", err)
+	}
+
+	// El nodo recibe el JSON de submit-tx y arma el mapa con sus propios tipos
+	body, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("Error codificando: %v", err)
+	}
+	var received client.Transaction
+	if err := json.Unmarshal(body, &received); err != nil {
+		t.Fatalf("Error decodificando: %v", err)
+	}
+	txMap := map[string]interface{}{
+		"hash":       received.Hash,
+		"from":       received.From,
+		"to":         received.To,
+		"value":      received.Value,
+		"data":       []byte(nil),
+		"gasLimit":   received.GasLimit,
+		"gasPrice":   received.GasPrice,
+		"nonce":      received.Nonce,
+		"signature":  received.Signature,
+		"accessList": received.AccessList,
+	}
+	recovered, err := cryptosigner.VerifyTransactionSignature(txMap)
+	if err != nil || recovered != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("Firma rechazada: %v", err)
+	}
+	expected, err := cryptosigner.CalculateTransactionHash(txMap)
+	if err != nil || expected.Hex() != received.Hash {
+		t.Fatalf("Hash distinto al del nodo: %s != %s (%v)", expected.Hex(), received.Hash, err)
+	}
+
+	if from, err := Verify(&received); err != nil || from.Hex() != tx.From {
+		t.Errorf("Verify falló: %v", err)
+	}
+
+	// Modificar un campo después de firmar invalida el hash
+	received.Value = "1001"
+	if _, err := Verify(&received); err == nil {
+		t.Error("Una transacción modificada no debería verificar")
+	}
+}
+
+// TestSignRejectsOtherSender prueba que no se firme con una clave que no corresponde al remitente
+func TestSignRejectsOtherSender(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Error generando clave: %v", err)
+	}
+	tx := NewTransfer("0x0000000000000000000000000000000000000009", "0x0000000000000000000000000000000000000001", big.NewInt(1), 0, big.NewInt(1))
+	if err := Sign(tx, key); err == nil || tx.Signature != nil {
+		t.Errorf("Se esperaba un error por remitente distinto: %v", err)
+	}
+}
+
+// typedSigner firma como una hardware wallet: el digest EIP-712 del hash, con V en 27-28
+type typedSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *typedSigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *typedSigner) SignTransactionHash(hash common.Hash) ([]byte, error) {
+	signature, err := crypto.Sign(crypto.Keccak256(TypedData(hash)), s.key)
+	if err != nil {
+		return nil, err
+	}
+	signature[64] += 27
+	return signature, nil
+}
+
+// TestSignWithTypedSigner prueba que el nodo acepte la firma EIP-712 de una hardware wallet
+func TestSignWithTypedSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Error generando clave: %v", err)
+	}
+
+	tx := NewTransfer("", "0x0000000000000000000000000000000000000001", big.NewInt(5), 0, big.NewInt(1))
+	if err := SignWith(tx, &typedSigner{key: key}); err != nil {
+		t.Fatalf("Error firmando: %v", err)
+	}
+	if from, err := Verify(tx); err != nil || from != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("Firma EIP-712 rechazada: %v", err)
+	}
+
+	// Un dispositivo que firma con otra cuenta se detecta antes de retornar
+	other, _ := crypto.GenerateKey()
+	tx = NewTransfer(crypto.PubkeyToAddress(key.PublicKey).Hex(), "0x0000000000000000000000000000000000000001", big.NewInt(5), 0, big.NewInt(1))
+	if err := SignWith(tx, &wrongAccountSigner{typedSigner{key: other}, crypto.PubkeyToAddress(key.PublicKey)}); err == nil {
+		t.Error("Se esperaba un error por firma de otra cuenta")
+	}
+}
+
+// wrongAccountSigner reporta una dirección y firma con otra clave
+type wrongAccountSigner struct {
+	typedSigner
+	address common.Address
+}
+
+func (s *wrongAccountSigner) Address() common.Address {
+	return s.address
+}
+
+// TestSignMultisig prueba que las firmas de los owners se junten sobre el mismo hash sin cambiarlo
+func TestSignMultisig(t *testing.T) {
+	keys := make([]Signer, 3)
+	owners := make([]string, 3)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("Error generando clave: %v", err)
+		}
+		keys[i] = NewKeySigner(key)
+		owners[i] = keys[i].Address().Hex()
+	}
+
+	tx := NewTransfer("", "0x0000000000000000000000000000000000000001", big.NewInt(5), 0, big.NewInt(1))
+	tx.Multisig = &client.MultisigAuth{Threshold: 2, Owners: owners}
+	if _, err := SignMultisig(tx, keys[0]); err != nil {
+		t.Fatalf("Error firmando: %v", err)
+	}
+	hash := tx.Hash
+	if _, err := SignMultisig(tx, &typedSigner{key: keys[2].(*keySigner).privateKey}); err != nil {
+		t.Fatalf("Error firmando con EIP-712: %v", err)
+	}
+	if tx.Hash != hash || len(tx.Multisig.Signatures) != 2 {
+		t.Fatalf("Firmas mal agregadas: hash %s, %d firmas", tx.Hash, len(tx.Multisig.Signatures))
+	}
+	if address, _ := MultisigAddress(owners, 2); tx.From != address.Hex() {
+		t.Errorf("From %s distinto de la multisig %s", tx.From, address.Hex())
+	}
+
+	outsider, _ := crypto.GenerateKey()
+	if _, err := SignMultisig(tx, NewKeySigner(outsider)); err == nil {
+		t.Error("Se esperaba un error por firmante que no es owner")
+	}
+	tx.Value = "6"
+	if _, err := SignMultisig(tx, keys[1]); err == nil {
+		t.Error("Se esperaba un error por transacción modificada después de firmar")
+	}
+}
+
+// TestSponsor prueba el flujo de relayer: el remitente firma con el fee payer y el relayer agrega su firma
+func TestSponsor(t *testing.T) {
+	senderKey, _ := crypto.GenerateKey()
+	relayerKey, _ := crypto.GenerateKey()
+	relayer := NewKeySigner(relayerKey)
+
+	tx := NewTransfer("", "0x0000000000000000000000000000000000000001", big.NewInt(0), 0, big.NewInt(1))
+	tx.FeePayer = &client.FeePayer{Address: relayer.Address().Hex()}
+	if err := Sign(tx, senderKey); err != nil {
+		t.Fatalf("Error firmando: %v", err)
+	}
+	if err := Sponsor(tx, NewKeySigner(senderKey)); err == nil {
+		t.Error("Se esperaba un error por clave distinta del fee payer")
+	}
+	if err := Sponsor(tx, relayer); err != nil {
+		t.Fatalf("Error patrocinando: %v", err)
+	}
+	digest := cryptosigner.FeePayerDigest(common.HexToHash(tx.Hash))
+	if err := cryptosigner.VerifyHashSignature(digest, tx.FeePayer.Signature, relayer.Address()); err != nil {
+		t.Errorf("Firma del fee payer inválida: %v", err)
+	}
+
+	// Cambiar el fee payer después de que el remitente firmó invalida su firma
+	other, _ := crypto.GenerateKey()
+	tx.FeePayer = &client.FeePayer{Address: crypto.PubkeyToAddress(other.PublicKey).Hex()}
+	if err := Sponsor(tx, NewKeySigner(other)); err == nil {
+		t.Error("Se esperaba un error por fee payer distinto del firmado")
+	}
+}